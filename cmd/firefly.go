@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/definitions"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -69,6 +70,8 @@ var cfgFile string
 
 var _utOrchestrator orchestrator.Orchestrator
 
+var definitionHandlers = make(map[string]definitions.CustomHandler)
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "f", "", "config file")
 	rootCmd.AddCommand(showConfigCommand)
@@ -81,6 +84,13 @@ func getOrchestrator() orchestrator.Orchestrator {
 	return orchestrator.NewOrchestrator()
 }
 
+// RegisterDefinitionHandler allows a program that embeds FireFly to process definitions broadcast with an application
+// specific tag, such as "myapp:config", in-line in the aggregator. It must be called before Execute, and the tag
+// is validated when the node starts.
+func RegisterDefinitionHandler(tag string, handler definitions.CustomHandler) {
+	definitionHandlers[tag] = handler
+}
+
 // Execute is called by the main method of the package
 func Execute() error {
 	apiserver.InitConfig()
@@ -156,6 +166,12 @@ func startFirefly(ctx context.Context, cancelCtx context.CancelFunc, o orchestra
 		log.L(ctx).Debugf("Debug HTTP endpoint listening on localhost:%d", debugPort)
 	}
 
+	for tag, handler := range definitionHandlers {
+		if err = o.RegisterDefinitionHandler(ctx, tag, handler); err != nil {
+			errChan <- err
+			return
+		}
+	}
 	if err = o.Init(ctx, cancelCtx); err != nil {
 		errChan <- err
 		return
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/mocks/apiservermocks"
+	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/orchestratormocks"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -64,6 +65,34 @@ func TestExecEngineInitFail(t *testing.T) {
 	assert.Regexp(t, "splutter", err)
 }
 
+func TestExecRegisterDefinitionHandler(t *testing.T) {
+	mch := &definitionsmocks.CustomHandler{}
+	RegisterDefinitionHandler("myapp:config", mch)
+	defer delete(definitionHandlers, "myapp:config")
+	o := &orchestratormocks.Orchestrator{}
+	o.On("RegisterDefinitionHandler", mock.Anything, "myapp:config", mch).Return(nil)
+	o.On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("splutter"))
+	_utOrchestrator = o
+	defer func() { _utOrchestrator = nil }()
+	os.Chdir(configDir)
+	err := Execute()
+	assert.Regexp(t, "splutter", err)
+	o.AssertExpectations(t)
+}
+
+func TestExecRegisterDefinitionHandlerFail(t *testing.T) {
+	mch := &definitionsmocks.CustomHandler{}
+	RegisterDefinitionHandler("ff_reserved", mch)
+	defer delete(definitionHandlers, "ff_reserved")
+	o := &orchestratormocks.Orchestrator{}
+	o.On("RegisterDefinitionHandler", mock.Anything, "ff_reserved", mch).Return(fmt.Errorf("FF10384"))
+	_utOrchestrator = o
+	defer func() { _utOrchestrator = nil }()
+	os.Chdir(configDir)
+	err := Execute()
+	assert.Regexp(t, "FF10384", err)
+}
+
 func TestExecEngineStartFail(t *testing.T) {
 	o := &orchestratormocks.Orchestrator{}
 	o.On("Init", mock.Anything, mock.Anything).Return(nil)
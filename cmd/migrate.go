@@ -0,0 +1,65 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var migrateCommand = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate data persisted by earlier versions of FireFly, while the node is stopped",
+}
+
+var migrateBatchesCommand = &cobra.Command{
+	Use:   "batches",
+	Short: "Rewrite the batches persisted by v0.13.x and earlier to the manifest format",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrateBatches()
+	},
+}
+
+func init() {
+	migrateCommand.AddCommand(migrateBatchesCommand)
+	rootCmd.AddCommand(migrateCommand)
+}
+
+func migrateBatches() error {
+	config.Reset()
+	err := config.ReadConfig(cfgFile)
+
+	ctx := log.WithLogField(context.Background(), "role", "migrate")
+	config.SetupLogging(ctx)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+
+	status, err := getOrchestrator().MigrateBatches(ctx)
+	if status != nil {
+		fmt.Printf("Scanned %d batches: %d migrated, %d failed\n", status.Scanned, status.Migrated, status.Failed)
+		for _, f := range status.Failures {
+			fmt.Printf("  %s: %s\n", f.Batch, f.Error)
+		}
+	}
+	return err
+}
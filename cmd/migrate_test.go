@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// resolved before any test changes directory
+var testConfigFile, _ = filepath.Abs(filepath.Join(configDir, "firefly.core.yaml"))
+
+func TestMigrateBatches(t *testing.T) {
+	o := &orchestratormocks.Orchestrator{}
+	o.On("MigrateBatches", mock.Anything).Return(&fftypes.BatchMigrationStatus{
+		Scanned:  10,
+		Migrated: 8,
+		Failed:   1,
+		Failures: []*fftypes.BatchMigrationFailure{
+			{Batch: fftypes.NewUUID(), Error: "pop"},
+		},
+	}, fmt.Errorf("pop"))
+	_utOrchestrator = o
+	defer func() { _utOrchestrator = nil }()
+
+	rootCmd.SetArgs([]string{"migrate", "batches", "-f", testConfigFile})
+	defer rootCmd.SetArgs([]string{})
+	defer func() { cfgFile = "" }()
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "pop")
+	o.AssertExpectations(t)
+}
+
+func TestMigrateBatchesBadConfig(t *testing.T) {
+	_utOrchestrator = &orchestratormocks.Orchestrator{}
+	defer func() { _utOrchestrator = nil }()
+
+	rootCmd.SetArgs([]string{"migrate", "batches", "-f", "/does/not/exist.yaml"})
+	defer rootCmd.SetArgs([]string{})
+	defer func() { cfgFile = "" }()
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10101", err)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var rebuildCommand = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Clear the state derived from the pinned batches while the node is stopped, so it is rebuilt from genesis on restart",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rebuild()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rebuildCommand)
+}
+
+func rebuild() error {
+	config.Reset()
+	err := config.ReadConfig(cfgFile)
+
+	ctx := log.WithLogField(context.Background(), "role", "rebuild")
+	config.SetupLogging(ctx)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+
+	status, err := getOrchestrator().Rebuild(ctx)
+	if status != nil {
+		fmt.Printf("Reset %d messages, cleared %d next pins and %d subscription redeliveries\n", status.Messages, status.NextPins, status.Redeliveries)
+	}
+	if err == nil {
+		fmt.Println("Start the node to replay the pins")
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRebuild(t *testing.T) {
+	o := &orchestratormocks.Orchestrator{}
+	o.On("Rebuild", mock.Anything).Return(&fftypes.RebuildStatus{
+		Messages:     10,
+		NextPins:     2,
+		Redeliveries: 1,
+	}, nil)
+	_utOrchestrator = o
+	defer func() { _utOrchestrator = nil }()
+
+	rootCmd.SetArgs([]string{"rebuild", "-f", testConfigFile})
+	defer rootCmd.SetArgs([]string{})
+	defer func() { cfgFile = "" }()
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+	o.AssertExpectations(t)
+}
+
+func TestRebuildFail(t *testing.T) {
+	o := &orchestratormocks.Orchestrator{}
+	o.On("Rebuild", mock.Anything).Return(&fftypes.RebuildStatus{}, fmt.Errorf("pop"))
+	_utOrchestrator = o
+	defer func() { _utOrchestrator = nil }()
+
+	rootCmd.SetArgs([]string{"rebuild", "-f", testConfigFile})
+	defer rootCmd.SetArgs([]string{})
+	defer func() { cfgFile = "" }()
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "pop")
+	o.AssertExpectations(t)
+}
+
+func TestRebuildBadConfig(t *testing.T) {
+	_utOrchestrator = &orchestratormocks.Orchestrator{}
+	defer func() { _utOrchestrator = nil }()
+
+	rootCmd.SetArgs([]string{"rebuild", "-f", "/does/not/exist.yaml"})
+	defer rootCmd.SetArgs([]string{})
+	defer func() { cfgFile = "" }()
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10101", err)
+}
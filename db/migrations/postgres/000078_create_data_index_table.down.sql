@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS data_index;
+ALTER TABLE datatypes DROP COLUMN indexes;
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+ALTER TABLE datatypes ADD COLUMN indexes VARCHAR(1024);
+
+CREATE TABLE data_index (
+  seq              SERIAL          PRIMARY KEY,
+  data_id          UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  path             VARCHAR(64)     NOT NULL,
+  value            VARCHAR(1024)
+);
+
+CREATE INDEX data_index_lookup ON data_index(namespace, path, value);
+CREATE INDEX data_index_data ON data_index(data_id);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE searchindex (
+  seq              SERIAL          PRIMARY KEY,
+  namespace        VARCHAR(64)     NOT NULL,
+  type             VARCHAR(64)     NOT NULL,
+  ref_id           UUID            NOT NULL,
+  content          TEXT            NOT NULL,
+  created          BIGINT
+);
+
+CREATE INDEX searchindex_content ON searchindex USING GIN (to_tsvector('simple', content));
+CREATE INDEX searchindex_namespace ON searchindex(namespace);
+CREATE INDEX searchindex_ref ON searchindex(ref_id);
+
+COMMIT;
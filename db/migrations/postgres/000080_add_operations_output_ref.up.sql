@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE operations ADD COLUMN output_ref UUID;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE contractapis DROP COLUMN query_cache;
+COMMIT;
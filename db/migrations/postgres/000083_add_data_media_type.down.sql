@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE data DROP COLUMN media_type;
+ALTER TABLE data DROP COLUMN value_original;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE data ADD COLUMN media_type VARCHAR(255);
+ALTER TABLE data ADD COLUMN value_original BYTEA;
+UPDATE data SET media_type = '';
+COMMIT;
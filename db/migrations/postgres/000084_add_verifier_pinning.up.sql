@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE verifiers ADD COLUMN pinning BOOLEAN;
+UPDATE verifiers SET pinning = false;
+COMMIT;
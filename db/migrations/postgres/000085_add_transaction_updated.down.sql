@@ -0,0 +1,5 @@
+BEGIN;
+DROP INDEX operations_updated;
+DROP INDEX transactions_updated;
+ALTER TABLE transactions DROP COLUMN updated;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE contractlisteners ADD COLUMN state VARCHAR(64);
+UPDATE contractlisteners SET state = 'active';
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+DROP TABLE IF EXISTS fees;
+ALTER TABLE transactions DROP COLUMN fee;
+ALTER TABLE operations DROP COLUMN fee;
+COMMIT;
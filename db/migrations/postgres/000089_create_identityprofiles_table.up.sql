@@ -0,0 +1,12 @@
+BEGIN;
+CREATE TABLE identityprofiles (
+  seq              SERIAL          PRIMARY KEY,
+  identity_id      UUID            NOT NULL,
+  author           VARCHAR(256)    NOT NULL,
+  message_id       UUID,
+  profile          TEXT,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX identityprofiles_identity ON identityprofiles(identity_id);
+COMMIT;
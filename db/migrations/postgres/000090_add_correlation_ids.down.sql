@@ -0,0 +1,11 @@
+BEGIN;
+DROP INDEX IF EXISTS events_correlation_id;
+DROP INDEX IF EXISTS messages_correlation_id;
+DROP INDEX IF EXISTS operations_correlation_id;
+DROP INDEX IF EXISTS transactions_correlation_id;
+
+ALTER TABLE events DROP COLUMN correlation_id;
+ALTER TABLE messages DROP COLUMN correlation_id;
+ALTER TABLE operations DROP COLUMN correlation_id;
+ALTER TABLE transactions DROP COLUMN correlation_id;
+COMMIT;
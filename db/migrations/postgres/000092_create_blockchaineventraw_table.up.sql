@@ -0,0 +1,12 @@
+BEGIN;
+CREATE TABLE blockchaineventraw (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  compressed       BOOLEAN         NOT NULL,
+  data             BYTEA           NOT NULL,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX blockchaineventraw_id ON blockchaineventraw(id);
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE groupaliases (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  group_hash       CHAR(64)        NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX groupaliases_id ON groupaliases(id);
+CREATE UNIQUE INDEX groupaliases_name ON groupaliases(namespace,name);
+COMMIT;
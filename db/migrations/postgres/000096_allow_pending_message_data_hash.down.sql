@@ -0,0 +1,4 @@
+BEGIN;
+DELETE FROM messages_data WHERE data_hash IS NULL;
+ALTER TABLE messages_data ALTER COLUMN data_hash SET NOT NULL;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE messages_data ALTER COLUMN data_hash DROP NOT NULL;
+COMMIT;
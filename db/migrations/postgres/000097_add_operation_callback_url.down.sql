@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE operations DROP COLUMN callback_url;
+COMMIT;
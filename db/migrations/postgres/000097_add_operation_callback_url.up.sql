@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE operations ADD COLUMN callback_url VARCHAR(1024) DEFAULT '';
+COMMIT;
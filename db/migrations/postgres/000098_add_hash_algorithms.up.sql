@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE batches ADD COLUMN hash_algorithm VARCHAR(64) DEFAULT '';
+ALTER TABLE blobs ADD COLUMN hash_algorithm VARCHAR(64) DEFAULT '';
+ALTER TABLE data ADD COLUMN blob_hash_algorithm VARCHAR(64) DEFAULT '';
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX nonces_context;
+CREATE INDEX nonces_context ON nonces(context);
+COMMIT;
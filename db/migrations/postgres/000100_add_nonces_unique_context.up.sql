@@ -0,0 +1,9 @@
+BEGIN;
+-- Keep only the highest nonce for any context that has been duplicated by concurrent inserts
+DELETE FROM nonces WHERE EXISTS (
+  SELECT 1 FROM nonces n2 WHERE n2.context = nonces.context AND
+    (n2.nonce > nonces.nonce OR (n2.nonce = nonces.nonce AND n2.seq > nonces.seq))
+);
+DROP INDEX nonces_context;
+CREATE UNIQUE INDEX nonces_context ON nonces(context);
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE subscriptionredeliveries (
+  seq              SERIAL          PRIMARY KEY,
+  subscription_id  UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  event_id         UUID            NOT NULL,
+  event_sequence   BIGINT          NOT NULL,
+  attempts         INTEGER         NOT NULL,
+  parked           BOOLEAN         NOT NULL,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX subscriptionredeliveries_event ON subscriptionredeliveries(subscription_id, event_id);
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE tokenpool ADD COLUMN ingest_filter TEXT;
+COMMIT;
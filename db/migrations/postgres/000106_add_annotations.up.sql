@@ -0,0 +1,19 @@
+BEGIN;
+ALTER TABLE datatypes ADD COLUMN annotations TEXT;
+ALTER TABLE ffi ADD COLUMN annotations TEXT;
+ALTER TABLE contractapis ADD COLUMN annotations TEXT;
+ALTER TABLE tokenpool ADD COLUMN annotations TEXT;
+ALTER TABLE subscriptions ADD COLUMN annotations TEXT;
+
+CREATE TABLE annotations (
+  seq              SERIAL          PRIMARY KEY,
+  resource_id      UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  path             VARCHAR(64)     NOT NULL,
+  value            VARCHAR(1024)
+);
+
+CREATE INDEX annotations_lookup ON annotations(namespace, path, value);
+CREATE INDEX annotations_resource ON annotations(resource_id);
+
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN send_at BIGINT;
+CREATE INDEX messages_send_at ON messages(state, send_at);
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+CREATE TABLE syncchanges (
+  seq              SERIAL          PRIMARY KEY,
+  namespace        VARCHAR(64)     NOT NULL,
+  ctype            VARCHAR(64)     NOT NULL,
+  ref_id           UUID            NOT NULL,
+  created          BIGINT          NOT NULL
+);
+
+CREATE INDEX syncchanges_namespace ON syncchanges(namespace,seq);
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE transactions DROP COLUMN chain_id;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE transactions ADD COLUMN chain_id BIGINT DEFAULT 0;
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE opcallbacks (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  status           VARCHAR(64)     NOT NULL,
+  attempts         INTEGER         NOT NULL,
+  next_attempt     BIGINT          NOT NULL,
+  error            TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX opcallbacks_id ON opcallbacks(id);
+CREATE INDEX opcallbacks_status_next_attempt ON opcallbacks(status, next_attempt);
+COMMIT;
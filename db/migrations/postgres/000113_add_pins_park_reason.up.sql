@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE pins ADD COLUMN park_reason VARCHAR(64) DEFAULT '';
+COMMIT;
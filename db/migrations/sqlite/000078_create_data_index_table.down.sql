@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS data_index;
+ALTER TABLE datatypes DROP COLUMN indexes;
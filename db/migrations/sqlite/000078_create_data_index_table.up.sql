@@ -0,0 +1,12 @@
+ALTER TABLE datatypes ADD COLUMN indexes VARCHAR(1024);
+
+CREATE TABLE data_index (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  data_id          UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  path             VARCHAR(64)     NOT NULL,
+  value            VARCHAR(1024)
+);
+
+CREATE INDEX data_index_lookup ON data_index(namespace, path, value);
+CREATE INDEX data_index_data ON data_index(data_id);
@@ -0,0 +1,12 @@
+-- Only the content column is added to the FTS4 full-text index
+CREATE VIRTUAL TABLE searchindex USING fts4(
+  namespace,
+  type,
+  ref_id,
+  content,
+  created,
+  notindexed=namespace,
+  notindexed=type,
+  notindexed=ref_id,
+  notindexed=created
+);
@@ -0,0 +1 @@
+ALTER TABLE operations ADD COLUMN output_ref UUID;
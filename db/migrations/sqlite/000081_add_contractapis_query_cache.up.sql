@@ -0,0 +1 @@
+ALTER TABLE contractapis ADD COLUMN query_cache TEXT;
@@ -0,0 +1,11 @@
+CREATE TABLE message_recipients (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace        VARCHAR(64)     NOT NULL,
+  message_id       UUID            NOT NULL,
+  node_id          UUID            NOT NULL,
+  status           VARCHAR(64)     NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX message_recipients_message_node ON message_recipients(message_id, node_id);
@@ -0,0 +1,2 @@
+ALTER TABLE data DROP COLUMN media_type;
+ALTER TABLE data DROP COLUMN value_original;
@@ -0,0 +1,3 @@
+ALTER TABLE data ADD COLUMN media_type VARCHAR(255);
+ALTER TABLE data ADD COLUMN value_original BLOB;
+UPDATE data SET media_type = '';
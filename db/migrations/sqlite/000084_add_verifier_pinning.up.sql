@@ -0,0 +1,2 @@
+ALTER TABLE verifiers ADD COLUMN pinning BOOLEAN;
+UPDATE verifiers SET pinning = false;
@@ -0,0 +1,3 @@
+DROP INDEX operations_updated;
+DROP INDEX transactions_updated;
+ALTER TABLE transactions DROP COLUMN updated;
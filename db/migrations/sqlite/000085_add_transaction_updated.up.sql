@@ -0,0 +1,4 @@
+ALTER TABLE transactions ADD COLUMN updated BIGINT;
+UPDATE transactions SET updated = created;
+CREATE INDEX transactions_updated ON transactions(updated);
+CREATE INDEX operations_updated ON operations(updated);
@@ -0,0 +1,2 @@
+ALTER TABLE contractlisteners ADD COLUMN state VARCHAR(64);
+UPDATE contractlisteners SET state = 'active';
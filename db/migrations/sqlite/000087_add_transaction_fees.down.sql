@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fees;
+ALTER TABLE transactions DROP COLUMN fee;
+ALTER TABLE operations DROP COLUMN fee;
@@ -0,0 +1,16 @@
+ALTER TABLE operations ADD COLUMN fee TEXT;
+ALTER TABLE transactions ADD COLUMN fee TEXT;
+
+CREATE TABLE fees (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace        VARCHAR(64)     NOT NULL,
+  signing_key      VARCHAR(1024)   NOT NULL,
+  day              VARCHAR(10)     NOT NULL,
+  tx_count         BIGINT          NOT NULL,
+  gas_used         VARCHAR(65),
+  total            VARCHAR(65),
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX fees_namespace_key_day ON fees(namespace, signing_key, day);
+CREATE INDEX fees_day ON fees(day);
@@ -0,0 +1,12 @@
+CREATE TABLE outbox (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  attempts         INTEGER         NOT NULL,
+  next_attempt     BIGINT          NOT NULL,
+  error            TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX outbox_id ON outbox(id);
+CREATE INDEX outbox_next_attempt ON outbox(next_attempt);
@@ -0,0 +1,10 @@
+CREATE TABLE identityprofiles (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  identity_id      UUID            NOT NULL,
+  author           VARCHAR(256)    NOT NULL,
+  message_id       UUID,
+  profile          TEXT,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX identityprofiles_identity ON identityprofiles(identity_id);
@@ -0,0 +1,9 @@
+ALTER TABLE transactions ADD COLUMN correlation_id VARCHAR(64) DEFAULT '';
+ALTER TABLE operations ADD COLUMN correlation_id VARCHAR(64) DEFAULT '';
+ALTER TABLE messages ADD COLUMN correlation_id VARCHAR(64) DEFAULT '';
+ALTER TABLE events ADD COLUMN correlation_id VARCHAR(64) DEFAULT '';
+
+CREATE INDEX transactions_correlation_id ON transactions(correlation_id);
+CREATE INDEX operations_correlation_id ON operations(correlation_id);
+CREATE INDEX messages_correlation_id ON messages(correlation_id);
+CREATE INDEX events_correlation_id ON events(correlation_id);
@@ -0,0 +1,9 @@
+CREATE TABLE namespacesigners (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace        VARCHAR(64)     NOT NULL,
+  author           VARCHAR(256)    NOT NULL,
+  key              VARCHAR(1024)   NOT NULL,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX namespacesigners_namespace ON namespacesigners(namespace);
@@ -0,0 +1,10 @@
+CREATE TABLE blockchaineventraw (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  compressed       BOOLEAN         NOT NULL,
+  data             BLOB            NOT NULL,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX blockchaineventraw_id ON blockchaineventraw(id);
@@ -0,0 +1,14 @@
+CREATE TABLE routingrules (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  tag              VARCHAR(256)    NOT NULL,
+  topic            VARCHAR(256)    NOT NULL,
+  labels           VARCHAR(1024)   NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX routingrules_id ON routingrules(id);
+CREATE UNIQUE INDEX routingrules_name ON routingrules(namespace,name);
@@ -0,0 +1,12 @@
+CREATE TABLE groupaliases (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  group_hash       CHAR(64)        NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX groupaliases_id ON groupaliases(id);
+CREATE UNIQUE INDEX groupaliases_name ON groupaliases(namespace,name);
@@ -0,0 +1,13 @@
+ALTER TABLE messages_data RENAME TO messages_data_old;
+CREATE TABLE messages_data (
+  seq        SERIAL   PRIMARY KEY,
+  message_id UUID     NOT NULL,
+  data_id    UUID     NOT NULL,
+  data_hash  CHAR(64) NOT NULL,
+  data_idx   INT      NOT NULL
+);
+INSERT INTO messages_data (seq, message_id, data_id, data_hash, data_idx)
+  SELECT seq, message_id, data_id, data_hash, data_idx FROM messages_data_old WHERE data_hash IS NOT NULL;
+DROP INDEX messages_data_idx;
+DROP TABLE messages_data_old;
+CREATE UNIQUE INDEX messages_data_idx ON messages_data(message_id, data_id);
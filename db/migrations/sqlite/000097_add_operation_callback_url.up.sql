@@ -0,0 +1 @@
+ALTER TABLE operations ADD COLUMN callback_url VARCHAR(1024) DEFAULT '';
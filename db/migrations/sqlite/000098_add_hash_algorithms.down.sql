@@ -0,0 +1,3 @@
+ALTER TABLE batches DROP COLUMN hash_algorithm;
+ALTER TABLE blobs DROP COLUMN hash_algorithm;
+ALTER TABLE data DROP COLUMN blob_hash_algorithm;
@@ -0,0 +1,3 @@
+ALTER TABLE batches ADD COLUMN hash_algorithm VARCHAR(64) DEFAULT '';
+ALTER TABLE blobs ADD COLUMN hash_algorithm VARCHAR(64) DEFAULT '';
+ALTER TABLE data ADD COLUMN blob_hash_algorithm VARCHAR(64) DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX nonces_context;
+CREATE INDEX nonces_context ON nonces(context);
@@ -0,0 +1 @@
+ALTER TABLE contractapis ADD COLUMN transforms TEXT;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS operations_business_key;
+DROP INDEX IF EXISTS tokentransfer_business_key;
+DROP INDEX IF EXISTS messages_business_key;
+
+ALTER TABLE operations DROP COLUMN business_key;
+ALTER TABLE tokentransfer DROP COLUMN business_key;
+ALTER TABLE messages DROP COLUMN business_key;
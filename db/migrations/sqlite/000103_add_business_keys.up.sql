@@ -0,0 +1,7 @@
+ALTER TABLE messages ADD COLUMN business_key VARCHAR(256) DEFAULT '';
+ALTER TABLE tokentransfer ADD COLUMN business_key VARCHAR(256) DEFAULT '';
+ALTER TABLE operations ADD COLUMN business_key VARCHAR(256) DEFAULT '';
+
+CREATE INDEX messages_business_key ON messages(namespace, business_key);
+CREATE INDEX tokentransfer_business_key ON tokentransfer(namespace, business_key);
+CREATE INDEX operations_business_key ON operations(namespace, business_key);
@@ -0,0 +1 @@
+ALTER TABLE tokenpool ADD COLUMN ingest_filter TEXT;
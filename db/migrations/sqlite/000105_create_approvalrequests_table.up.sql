@@ -0,0 +1,17 @@
+CREATE TABLE approvalrequests (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  rtype            VARCHAR(64)     NOT NULL,
+  state            VARCHAR(64)     NOT NULL,
+  reference        UUID,
+  request          TEXT,
+  required         INT             NOT NULL,
+  decisions        TEXT,
+  error            TEXT,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX approvalrequests_id ON approvalrequests(id);
+CREATE INDEX approvalrequests_state ON approvalrequests(namespace,state);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS annotations;
+ALTER TABLE datatypes DROP COLUMN annotations;
+ALTER TABLE ffi DROP COLUMN annotations;
+ALTER TABLE contractapis DROP COLUMN annotations;
+ALTER TABLE tokenpool DROP COLUMN annotations;
+ALTER TABLE subscriptions DROP COLUMN annotations;
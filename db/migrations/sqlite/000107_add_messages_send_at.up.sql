@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN send_at BIGINT;
+CREATE INDEX messages_send_at ON messages(state, send_at);
@@ -0,0 +1,9 @@
+CREATE TABLE syncchanges (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace        VARCHAR(64)     NOT NULL,
+  ctype            VARCHAR(64)     NOT NULL,
+  ref_id           UUID            NOT NULL,
+  created          BIGINT          NOT NULL
+);
+
+CREATE INDEX syncchanges_namespace ON syncchanges(namespace,seq);
@@ -0,0 +1,12 @@
+CREATE TABLE ffierrors (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  interface_id      UUID            NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(1024)   NOT NULL,
+  pathname          VARCHAR(1024)   NOT NULL,
+  description       TEXT            NOT NULL,
+  params            TEXT            NOT NULL
+);
+
+CREATE UNIQUE INDEX ffierrors_pathname ON ffierrors(interface_id,pathname);
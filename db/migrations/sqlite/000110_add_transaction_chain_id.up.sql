@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN chain_id BIGINT DEFAULT 0;
@@ -0,0 +1,10 @@
+CREATE TABLE operation_outputs (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  operation_id     UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  value            TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX operation_outputs_id ON operation_outputs(id);
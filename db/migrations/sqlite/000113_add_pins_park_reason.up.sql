@@ -0,0 +1 @@
+ALTER TABLE pins ADD COLUMN park_reason VARCHAR(64) DEFAULT '';
@@ -0,0 +1,138 @@
+-- Converts the messages, events, blockchainevents and pins tables to tables partitioned by month on their
+-- nanosecond timestamp column. This is NOT a migration. It is an optional step for operators of PostgreSQL
+-- 13 or later, and should be run with FireFly stopped, after all migrations have been applied.
+-- See docs/reference/partitioning.md for details, and unpartition_high_volume_tables.sql to revert.
+--
+-- The existing table is attached as the default partition without copying any rows. Unique keys cannot be
+-- enforced across partitions by PostgreSQL, so each unique key is recorded in a <table>_keys table that is
+-- maintained by triggers. An insert of a key that already exists is skipped, in the same way as the
+-- ON CONFLICT DO NOTHING used by FireFly, and the insert fails as it returns no rows.
+BEGIN;
+
+-- messages
+ALTER TABLE messages RENAME TO messages_default;
+ALTER TABLE messages_default RENAME CONSTRAINT messages_pkey TO messages_default_pkey;
+ALTER INDEX messages_id RENAME TO messages_default_id;
+ALTER INDEX messages_topics_tag RENAME TO messages_default_topics_tag;
+ALTER INDEX messages_sortorder RENAME TO messages_default_sortorder;
+ALTER INDEX messages_correlation_id RENAME TO messages_default_correlation_id;
+ALTER INDEX messages_business_key RENAME TO messages_default_business_key;
+ALTER INDEX messages_send_at RENAME TO messages_default_send_at;
+CREATE TABLE messages (LIKE messages_default INCLUDING DEFAULTS) PARTITION BY RANGE (created);
+ALTER SEQUENCE messages_seq_seq OWNED BY messages.seq;
+ALTER TABLE messages ATTACH PARTITION messages_default DEFAULT;
+CREATE INDEX messages_seq ON messages(seq);
+CREATE INDEX messages_id ON messages(id);
+CREATE INDEX messages_topics_tag ON messages(namespace,topics,tag);
+CREATE INDEX messages_sortorder ON messages(confirmed, created);
+CREATE INDEX messages_correlation_id ON messages(correlation_id);
+CREATE INDEX messages_business_key ON messages(namespace, business_key);
+CREATE INDEX messages_send_at ON messages(state, send_at);
+
+CREATE TABLE messages_keys (id UUID PRIMARY KEY);
+INSERT INTO messages_keys (id) SELECT id FROM messages_default;
+CREATE FUNCTION messages_keys_insert() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO messages_keys (id) VALUES (NEW.id) ON CONFLICT DO NOTHING;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE FUNCTION messages_keys_delete() RETURNS trigger AS $$
+BEGIN
+  DELETE FROM messages_keys WHERE id = OLD.id;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER messages_keys_insert BEFORE INSERT ON messages FOR EACH ROW EXECUTE FUNCTION messages_keys_insert();
+CREATE TRIGGER messages_keys_delete AFTER DELETE ON messages FOR EACH ROW EXECUTE FUNCTION messages_keys_delete();
+
+-- events
+ALTER TABLE events RENAME TO events_default;
+ALTER TABLE events_default RENAME CONSTRAINT events_pkey TO events_default_pkey;
+ALTER INDEX events_id RENAME TO events_default_id;
+ALTER INDEX events_created RENAME TO events_default_created;
+ALTER INDEX events_topic RENAME TO events_default_topic;
+ALTER INDEX events_correlation_id RENAME TO events_default_correlation_id;
+CREATE TABLE events (LIKE events_default INCLUDING DEFAULTS) PARTITION BY RANGE (created);
+ALTER SEQUENCE events_seq_seq OWNED BY events.seq;
+ALTER TABLE events ATTACH PARTITION events_default DEFAULT;
+CREATE INDEX events_seq ON events(seq);
+CREATE INDEX events_id ON events(id);
+CREATE INDEX events_created ON events(created);
+CREATE INDEX events_topic ON events(topic);
+CREATE INDEX events_correlation_id ON events(correlation_id);
+
+CREATE TABLE events_keys (id UUID PRIMARY KEY);
+INSERT INTO events_keys (id) SELECT id FROM events_default;
+CREATE FUNCTION events_keys_insert() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO events_keys (id) VALUES (NEW.id) ON CONFLICT DO NOTHING;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE FUNCTION events_keys_delete() RETURNS trigger AS $$
+BEGIN
+  DELETE FROM events_keys WHERE id = OLD.id;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER events_keys_insert BEFORE INSERT ON events FOR EACH ROW EXECUTE FUNCTION events_keys_insert();
+CREATE TRIGGER events_keys_delete AFTER DELETE ON events FOR EACH ROW EXECUTE FUNCTION events_keys_delete();
+
+-- blockchainevents (the id index has never been unique)
+ALTER TABLE blockchainevents RENAME TO blockchainevents_default;
+ALTER TABLE blockchainevents_default RENAME CONSTRAINT blockchainevents_pkey TO blockchainevents_default_pkey;
+ALTER INDEX blockchainevents_id RENAME TO blockchainevents_default_id;
+ALTER INDEX blockchainevents_tx RENAME TO blockchainevents_default_tx;
+ALTER INDEX blockchainevents_timestamp RENAME TO blockchainevents_default_timestamp;
+ALTER INDEX blockchainevents_listener_id RENAME TO blockchainevents_default_listener_id;
+CREATE TABLE blockchainevents (LIKE blockchainevents_default INCLUDING DEFAULTS) PARTITION BY RANGE (timestamp);
+ALTER SEQUENCE blockchainevents_seq_seq OWNED BY blockchainevents.seq;
+ALTER TABLE blockchainevents ATTACH PARTITION blockchainevents_default DEFAULT;
+CREATE INDEX blockchainevents_seq ON blockchainevents(seq);
+CREATE INDEX blockchainevents_id ON blockchainevents(id);
+CREATE INDEX blockchainevents_tx ON blockchainevents(tx_id);
+CREATE INDEX blockchainevents_timestamp ON blockchainevents(timestamp);
+CREATE INDEX blockchainevents_listener_id ON blockchainevents(listener_id);
+
+-- pins
+ALTER TABLE pins RENAME TO pins_default;
+ALTER TABLE pins_default RENAME CONSTRAINT pins_pkey TO pins_default_pkey;
+ALTER INDEX pins_pin RENAME TO pins_default_pin;
+ALTER INDEX pins_dispatched RENAME TO pins_default_dispatched;
+ALTER INDEX pins_batch RENAME TO pins_default_batch;
+CREATE TABLE pins (LIKE pins_default INCLUDING DEFAULTS) PARTITION BY RANGE (created);
+ALTER SEQUENCE pins_seq_seq OWNED BY pins.seq;
+ALTER TABLE pins ATTACH PARTITION pins_default DEFAULT;
+CREATE INDEX pins_seq ON pins(seq);
+CREATE INDEX pins_pin ON pins(hash, batch_id, idx);
+CREATE INDEX pins_dispatched ON pins(dispatched);
+CREATE INDEX pins_batch ON pins(batch_id);
+
+CREATE TABLE pins_keys (hash CHAR(64), batch_id UUID, idx BIGINT, PRIMARY KEY (hash, batch_id, idx));
+INSERT INTO pins_keys (hash, batch_id, idx) SELECT hash, batch_id, idx FROM pins_default;
+CREATE FUNCTION pins_keys_insert() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO pins_keys (hash, batch_id, idx) VALUES (NEW.hash, NEW.batch_id, NEW.idx) ON CONFLICT DO NOTHING;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE FUNCTION pins_keys_delete() RETURNS trigger AS $$
+BEGIN
+  DELETE FROM pins_keys WHERE hash = OLD.hash AND batch_id = OLD.batch_id AND idx = OLD.idx;
+  RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER pins_keys_insert BEFORE INSERT ON pins FOR EACH ROW EXECUTE FUNCTION pins_keys_insert();
+CREATE TRIGGER pins_keys_delete AFTER DELETE ON pins FOR EACH ROW EXECUTE FUNCTION pins_keys_delete();
+
+COMMIT;
@@ -0,0 +1,70 @@
+-- Reverts partition_high_volume_tables.sql. Run with FireFly stopped.
+-- Rows from the monthly partitions are copied back into the default partition, which becomes the table again,
+-- so this can take a long time if many rows have been written since the tables were partitioned.
+BEGIN;
+
+-- messages
+DROP TRIGGER messages_keys_insert ON messages;
+DROP TRIGGER messages_keys_delete ON messages;
+DROP FUNCTION messages_keys_insert();
+DROP FUNCTION messages_keys_delete();
+DROP TABLE messages_keys;
+ALTER TABLE messages DETACH PARTITION messages_default;
+INSERT INTO messages_default SELECT * FROM messages;
+ALTER SEQUENCE messages_seq_seq OWNED BY messages_default.seq;
+DROP TABLE messages CASCADE;
+ALTER TABLE messages_default RENAME TO messages;
+ALTER TABLE messages RENAME CONSTRAINT messages_default_pkey TO messages_pkey;
+ALTER INDEX messages_default_id RENAME TO messages_id;
+ALTER INDEX messages_default_topics_tag RENAME TO messages_topics_tag;
+ALTER INDEX messages_default_sortorder RENAME TO messages_sortorder;
+ALTER INDEX messages_default_correlation_id RENAME TO messages_correlation_id;
+ALTER INDEX messages_default_business_key RENAME TO messages_business_key;
+ALTER INDEX messages_default_send_at RENAME TO messages_send_at;
+
+-- events
+DROP TRIGGER events_keys_insert ON events;
+DROP TRIGGER events_keys_delete ON events;
+DROP FUNCTION events_keys_insert();
+DROP FUNCTION events_keys_delete();
+DROP TABLE events_keys;
+ALTER TABLE events DETACH PARTITION events_default;
+INSERT INTO events_default SELECT * FROM events;
+ALTER SEQUENCE events_seq_seq OWNED BY events_default.seq;
+DROP TABLE events CASCADE;
+ALTER TABLE events_default RENAME TO events;
+ALTER TABLE events RENAME CONSTRAINT events_default_pkey TO events_pkey;
+ALTER INDEX events_default_id RENAME TO events_id;
+ALTER INDEX events_default_created RENAME TO events_created;
+ALTER INDEX events_default_topic RENAME TO events_topic;
+ALTER INDEX events_default_correlation_id RENAME TO events_correlation_id;
+
+-- blockchainevents
+ALTER TABLE blockchainevents DETACH PARTITION blockchainevents_default;
+INSERT INTO blockchainevents_default SELECT * FROM blockchainevents;
+ALTER SEQUENCE blockchainevents_seq_seq OWNED BY blockchainevents_default.seq;
+DROP TABLE blockchainevents CASCADE;
+ALTER TABLE blockchainevents_default RENAME TO blockchainevents;
+ALTER TABLE blockchainevents RENAME CONSTRAINT blockchainevents_default_pkey TO blockchainevents_pkey;
+ALTER INDEX blockchainevents_default_id RENAME TO blockchainevents_id;
+ALTER INDEX blockchainevents_default_tx RENAME TO blockchainevents_tx;
+ALTER INDEX blockchainevents_default_timestamp RENAME TO blockchainevents_timestamp;
+ALTER INDEX blockchainevents_default_listener_id RENAME TO blockchainevents_listener_id;
+
+-- pins
+DROP TRIGGER pins_keys_insert ON pins;
+DROP TRIGGER pins_keys_delete ON pins;
+DROP FUNCTION pins_keys_insert();
+DROP FUNCTION pins_keys_delete();
+DROP TABLE pins_keys;
+ALTER TABLE pins DETACH PARTITION pins_default;
+INSERT INTO pins_default SELECT * FROM pins;
+ALTER SEQUENCE pins_seq_seq OWNED BY pins_default.seq;
+DROP TABLE pins CASCADE;
+ALTER TABLE pins_default RENAME TO pins;
+ALTER TABLE pins RENAME CONSTRAINT pins_default_pkey TO pins_pkey;
+ALTER INDEX pins_default_pin RENAME TO pins_pin;
+ALTER INDEX pins_default_dispatched RENAME TO pins_dispatched;
+ALTER INDEX pins_default_batch RENAME TO pins_batch;
+
+COMMIT;
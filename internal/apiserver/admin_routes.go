@@ -19,10 +19,25 @@ package apiserver
 import "github.com/hyperledger/firefly/internal/oapispec"
 
 var adminRoutes = []*oapispec.Route{
+	getBatchMigration,
 	getConfig,
 	getConfigRecord,
 	getConfigRecords,
+	getLogLevels,
+	getNamespaceSigner,
+	getPlugins,
+	getStandby,
+	getStateSnapshot,
+	postBatchMigration,
+	postPluginAction,
+	postRegisterPinKey,
 	postResetConfig,
+	postStandbyPromote,
+	postStateSnapshotRestore,
 	putConfigRecord,
+	putLogLevel,
+	putNamespaceSigner,
 	deleteConfigRecord,
+	deleteLogLevel,
+	deleteNamespaceSigner,
 }
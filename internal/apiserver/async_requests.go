@@ -0,0 +1,235 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type asyncRequestsContextKey struct{}
+
+// asyncRequests holds the mutating requests that are being processed in the background, and their outcome
+// for a retention period once complete, so clients can poll for them. Both the number of requests in flight
+// and the number held are bounded, as they are all held in memory
+type asyncRequests struct {
+	mux         sync.Mutex
+	requests    map[fftypes.UUID]*asyncRequest
+	pending     int
+	retention   time.Duration
+	timeout     time.Duration
+	maxWait     time.Duration
+	maxPending  int
+	maxRetained int
+}
+
+type asyncRequest struct {
+	fftypes.AsyncRequest
+	done chan struct{}
+}
+
+// detachedContext keeps the values of the HTTP request context (logger, correlation ID etc.), without being
+// cancelled when the HTTP response for a request processed in the background is sent
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+
+func newAsyncRequests() *asyncRequests {
+	return &asyncRequests{
+		requests:    make(map[fftypes.UUID]*asyncRequest),
+		retention:   config.GetDuration(config.APIAsyncRequestRetention),
+		timeout:     config.GetDuration(config.APIRequestMaxTimeout),
+		maxWait:     config.GetDuration(config.APIAsyncRequestMaxWait),
+		maxPending:  config.GetInt(config.APIAsyncRequestMaxPending),
+		maxRetained: config.GetInt(config.APIAsyncRequestMaxRetained),
+	}
+}
+
+func getAsyncRequests(ctx context.Context) *asyncRequests {
+	return ctx.Value(asyncRequestsContextKey{}).(*asyncRequests)
+}
+
+// isAsyncRequested checks for a "Prefer: respond-async" header (RFC 7240), or the async query parameter, on a mutating request
+func isAsyncRequested(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return false
+	}
+	if strings.EqualFold(req.URL.Query().Get("async"), "true") {
+		return true
+	}
+	for _, header := range req.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token := strings.TrimSpace(strings.Split(preference, ";")[0])
+			if strings.EqualFold(token, "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// start runs the route handler in the background, and returns the pending request to respond with.
+// The request is rejected if the limit of requests in flight has been reached
+func (ar *asyncRequests) start(r *oapispec.APIRequest, route *oapispec.Route) (*fftypes.AsyncRequest, error) {
+	req := &asyncRequest{
+		AsyncRequest: fftypes.AsyncRequest{
+			ID:      fftypes.NewUUID(),
+			Method:  r.Req.Method,
+			Path:    r.Req.URL.Path,
+			Status:  fftypes.OpStatusPending,
+			Created: fftypes.Now(),
+		},
+		done: make(chan struct{}),
+	}
+	ar.mux.Lock()
+	ar.expire()
+	if ar.pending >= ar.maxPending {
+		ar.mux.Unlock()
+		return nil, i18n.NewError(r.Ctx, i18n.MsgAsyncRequestsBusy, ar.maxPending)
+	}
+	ar.evict()
+	ar.requests[*req.ID] = req
+	ar.pending++
+	pending := req.AsyncRequest
+	ar.mux.Unlock()
+
+	// The background processing gets its own copy of the request, as the caller goes on to respond with the pending request
+	bgReq := *r
+	bgReq.QP = make(map[string]string, len(r.QP))
+	for k, v := range r.QP {
+		bgReq.QP[k] = v
+	}
+	log.L(r.Ctx).Infof("Processing %s %s in the background as request %s", req.Method, req.Path, req.ID)
+	go ar.run(req, &bgReq, route)
+	return &pending, nil
+}
+
+func (ar *asyncRequests) run(req *asyncRequest, r *oapispec.APIRequest, route *oapispec.Route) {
+	ctx, cancel := context.WithTimeout(detachedContext{r.Ctx}, ar.timeout)
+	defer cancel()
+	r.Ctx = ctx
+	r.ResponseHeaders = http.Header{}
+	output, err := route.JSONHandler(r)
+	ar.complete(ctx, req, r.SuccessStatus, output, err)
+}
+
+func (ar *asyncRequests) complete(ctx context.Context, req *asyncRequest, status int, output interface{}, err error) {
+	var jsonOutput *fftypes.JSONAny
+	if err == nil {
+		var b []byte
+		if b, err = json.Marshal(output); err == nil {
+			switch {
+			case string(b) != "null":
+				jsonOutput = fftypes.JSONAnyPtrBytes(b)
+			case status != http.StatusNoContent:
+				err = i18n.NewError(ctx, i18n.Msg404NoResult)
+			}
+		}
+	}
+	if err != nil {
+		status = errorStatusHint(err, status)
+		if status < 300 {
+			status = http.StatusInternalServerError
+		}
+		log.L(ctx).Errorf("Request %s failed [%d]: %s", req.ID, status, err)
+	} else {
+		log.L(ctx).Infof("Request %s completed [%d]", req.ID, status)
+	}
+
+	ar.mux.Lock()
+	defer ar.mux.Unlock()
+	req.StatusCode = status
+	req.Output = jsonOutput
+	req.Status = fftypes.OpStatusSucceeded
+	if err != nil {
+		req.Status = fftypes.OpStatusFailed
+		req.Error = err.Error()
+	}
+	req.Completed = fftypes.Now()
+	ar.pending--
+	close(req.done)
+}
+
+// get returns the current state of a request, waiting up to the supplied duration (capped at the maximum wait) for it to complete
+func (ar *asyncRequests) get(ctx context.Context, id *fftypes.UUID, wait time.Duration) *fftypes.AsyncRequest {
+	if wait > ar.maxWait {
+		wait = ar.maxWait
+	}
+	ar.mux.Lock()
+	ar.expire()
+	req, ok := ar.requests[*id]
+	ar.mux.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-req.done:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	ar.mux.Lock()
+	defer ar.mux.Unlock()
+	current := req.AsyncRequest
+	return &current
+}
+
+// expire must be called with the lock held
+func (ar *asyncRequests) expire() {
+	cutoff := time.Now().Add(-ar.retention)
+	for id, req := range ar.requests {
+		if req.Completed != nil && time.Time(*req.Completed).Before(cutoff) {
+			delete(ar.requests, id)
+		}
+	}
+}
+
+// evict removes the oldest completed requests until there is room for another, and must be called with the lock held.
+// Requests in flight are never evicted, so the number held can only exceed the maximum by the requests in flight
+func (ar *asyncRequests) evict() {
+	for len(ar.requests) >= ar.maxRetained {
+		var oldest *asyncRequest
+		for _, req := range ar.requests {
+			if req.Completed != nil && (oldest == nil || time.Time(*req.Completed).Before(time.Time(*oldest.Completed))) {
+				oldest = req
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		delete(ar.requests, *oldest.ID)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAsyncRequests(retention, timeout time.Duration) *asyncRequests {
+	return &asyncRequests{
+		requests:    make(map[fftypes.UUID]*asyncRequest),
+		retention:   retention,
+		timeout:     timeout,
+		maxWait:     time.Minute,
+		maxPending:  10,
+		maxRetained: 10,
+	}
+}
+
+func pollAsyncRequest(t *testing.T, r http.Handler, location string) *fftypes.AsyncRequest {
+	req := httptest.NewRequest("GET", location[strings.Index(location, "/api/v1"):]+"?wait=5s", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var asyncReq fftypes.AsyncRequest
+	err := json.NewDecoder(res.Body).Decode(&asyncReq)
+	assert.NoError(t, err)
+	return &asyncReq
+}
+
+func TestAsyncRequestRespondAsync(t *testing.T) {
+	o, r := newTestAPIServer()
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&fftypes.MessageInOut{})
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Prefer", "wait=10, respond-async")
+	res := httptest.NewRecorder()
+
+	msgID := fftypes.NewUUID()
+	mbm.On("BroadcastMessage", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.MessageInOut"), false).
+		Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: msgID}}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+	assert.Equal(t, "respond-async", res.Result().Header.Get("Preference-Applied"))
+	var pending fftypes.AsyncRequest
+	json.NewDecoder(res.Body).Decode(&pending)
+	assert.Equal(t, fftypes.OpStatusPending, pending.Status)
+	assert.Equal(t, "POST", pending.Method)
+	assert.Equal(t, "/api/v1/namespaces/ns1/messages/broadcast", pending.Path)
+	location := res.Result().Header.Get("Location")
+	assert.Equal(t, fmt.Sprintf("http://127.0.0.1:5000/api/v1/requests/%s", pending.ID), location)
+
+	completed := pollAsyncRequest(t, r, location)
+	assert.Equal(t, fftypes.OpStatusSucceeded, completed.Status)
+	assert.Equal(t, 202, completed.StatusCode)
+	assert.Contains(t, completed.Output.String(), msgID.String())
+	assert.NotNil(t, completed.Completed)
+	mbm.AssertExpectations(t)
+}
+
+func TestAsyncRequestFailed(t *testing.T) {
+	o, r := newTestAPIServer()
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&fftypes.MessageInOut{})
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast?async=true&confirm=false", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mbm.On("BroadcastMessage", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.MessageInOut"), false).
+		Return(nil, i18n.NewError(context.Background(), i18n.MsgMessageNotPinned, "id1"))
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 202, res.Result().StatusCode)
+
+	completed := pollAsyncRequest(t, r, res.Result().Header.Get("Location"))
+	assert.Equal(t, fftypes.OpStatusFailed, completed.Status)
+	assert.Equal(t, 409, completed.StatusCode)
+	assert.Regexp(t, "FF10468", completed.Error)
+	assert.Nil(t, completed.Output)
+}
+
+func TestAsyncRequestConfirm(t *testing.T) {
+	o, r := newTestAPIServer()
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&fftypes.MessageInOut{})
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast?async=true&confirm=true", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mbm.On("BroadcastMessage", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.MessageInOut"), true).
+		Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 202, res.Result().StatusCode)
+
+	completed := pollAsyncRequest(t, r, res.Result().Header.Get("Location"))
+	assert.Equal(t, fftypes.OpStatusSucceeded, completed.Status)
+	assert.Equal(t, 200, completed.StatusCode)
+	mbm.AssertExpectations(t)
+}
+
+func TestAsyncRequestTooManyPending(t *testing.T) {
+	o, as := newTestServer()
+	as.asyncRequests.maxPending = 0
+	r := as.createMuxRouter(context.Background(), o)
+	req := httptest.NewRequest("DELETE", "/api/v1/namespaces/ns1/subscriptions/sub1", nil)
+	req.Header.Set("Prefer", "respond-async")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 429, res.Result().StatusCode)
+	assert.Empty(t, as.asyncRequests.requests)
+}
+
+func TestAsyncRequestDelete(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("DELETE", "/api/v1/namespaces/ns1/subscriptions/sub1", nil)
+	req.Header.Set("Prefer", "respond-async")
+	res := httptest.NewRecorder()
+
+	o.On("DeleteSubscription", mock.Anything, "ns1", "sub1").Return(nil)
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 202, res.Result().StatusCode)
+
+	completed := pollAsyncRequest(t, r, res.Result().Header.Get("Location"))
+	assert.Equal(t, fftypes.OpStatusSucceeded, completed.Status)
+	assert.Equal(t, 204, completed.StatusCode)
+	assert.Nil(t, completed.Output)
+}
+
+func TestAsyncRequestNotFound(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/requests/%s", fftypes.NewUUID()), nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestAsyncRequestBadID(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/requests/bad", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
+
+func TestAsyncRequestCompleteErrors(t *testing.T) {
+	ar := newTestAsyncRequests(time.Hour, time.Second)
+	ctx := context.Background()
+
+	req := &asyncRequest{done: make(chan struct{})}
+	ar.complete(ctx, req, 200, nil, nil)
+	assert.Equal(t, fftypes.OpStatusFailed, req.Status)
+	assert.Equal(t, 404, req.StatusCode)
+
+	req = &asyncRequest{done: make(chan struct{})}
+	ar.complete(ctx, req, 200, map[string]interface{}{"bad": map[bool]bool{false: true}}, nil)
+	assert.Equal(t, fftypes.OpStatusFailed, req.Status)
+	assert.Equal(t, 500, req.StatusCode)
+
+	req = &asyncRequest{done: make(chan struct{})}
+	ar.complete(ctx, req, 202, nil, fmt.Errorf("pop"))
+	assert.Equal(t, 500, req.StatusCode)
+	assert.Equal(t, "pop", req.Error)
+}
+
+func TestAsyncRequestWait(t *testing.T) {
+	ar := newTestAsyncRequests(time.Hour, time.Second)
+	id := fftypes.NewUUID()
+	ar.requests[*id] = &asyncRequest{
+		AsyncRequest: fftypes.AsyncRequest{ID: id, Status: fftypes.OpStatusPending},
+		done:         make(chan struct{}),
+	}
+
+	current := ar.get(context.Background(), id, 1*time.Millisecond)
+	assert.Equal(t, fftypes.OpStatusPending, current.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	current = ar.get(ctx, id, 1*time.Minute)
+	assert.Equal(t, fftypes.OpStatusPending, current.Status)
+}
+
+func TestAsyncRequestExpire(t *testing.T) {
+	ar := newTestAsyncRequests(0, time.Second)
+	pendingID := fftypes.NewUUID()
+	ar.requests[*pendingID] = &asyncRequest{AsyncRequest: fftypes.AsyncRequest{ID: pendingID}}
+	completedID := fftypes.NewUUID()
+	completed := fftypes.FFTime(time.Now().Add(-1 * time.Second))
+	ar.requests[*completedID] = &asyncRequest{AsyncRequest: fftypes.AsyncRequest{ID: completedID, Completed: &completed}}
+
+	assert.Nil(t, ar.get(context.Background(), completedID, 0))
+	assert.NotNil(t, ar.get(context.Background(), pendingID, 0))
+}
+
+func TestAsyncRequestEvictOldestCompleted(t *testing.T) {
+	ar := newTestAsyncRequests(time.Hour, time.Second)
+	ar.maxRetained = 2
+	newRequest := func(completed *fftypes.FFTime) *fftypes.UUID {
+		id := fftypes.NewUUID()
+		ar.requests[*id] = &asyncRequest{AsyncRequest: fftypes.AsyncRequest{ID: id, Completed: completed}}
+		return id
+	}
+	pendingID := newRequest(nil)
+	older := fftypes.FFTime(time.Now().Add(-2 * time.Second))
+	olderID := newRequest(&older)
+	newer := fftypes.FFTime(time.Now().Add(-1 * time.Second))
+	newerID := newRequest(&newer)
+
+	ar.evict()
+	assert.Len(t, ar.requests, 1)
+	assert.Contains(t, ar.requests, *pendingID)
+	assert.NotContains(t, ar.requests, *olderID)
+	assert.NotContains(t, ar.requests, *newerID)
+
+	// Requests in flight are never evicted
+	newRequest(nil)
+	ar.evict()
+	assert.Len(t, ar.requests, 2)
+}
+
+func TestAsyncRequestWaitCapped(t *testing.T) {
+	ar := newTestAsyncRequests(time.Hour, time.Second)
+	ar.maxWait = 1 * time.Millisecond
+	id := fftypes.NewUUID()
+	ar.requests[*id] = &asyncRequest{
+		AsyncRequest: fftypes.AsyncRequest{ID: id, Status: fftypes.OpStatusPending},
+		done:         make(chan struct{}),
+	}
+
+	current := ar.get(context.Background(), id, 1*time.Hour)
+	assert.Equal(t, fftypes.OpStatusPending, current.Status)
+}
+
+func TestNewAsyncRequests(t *testing.T) {
+	config.Reset()
+	ar := newAsyncRequests()
+	assert.Equal(t, 100, ar.maxPending)
+	assert.Equal(t, 1000, ar.maxRetained)
+	assert.Equal(t, 60*time.Second, ar.maxWait)
+}
+
+func TestIsAsyncRequested(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v1/things", nil)
+	assert.False(t, isAsyncRequested(req))
+	req.Header.Set("Prefer", "return=minimal")
+	assert.False(t, isAsyncRequested(req))
+	req.Header.Add("Prefer", "Respond-Async; foo=bar")
+	assert.True(t, isAsyncRequested(req))
+
+	req = httptest.NewRequest("GET", "/api/v1/things?async=true", nil)
+	assert.False(t, isAsyncRequested(req))
+}
+
+func TestDetachedContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), asyncRequestsContextKey{}, "value"))
+	cancel()
+	dctx := detachedContext{ctx}
+	_, hasDeadline := dctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Nil(t, dctx.Done())
+	assert.NoError(t, dctx.Err())
+	assert.Equal(t, "value", dctx.Value(asyncRequestsContextKey{}))
+}
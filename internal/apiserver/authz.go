@@ -0,0 +1,187 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	// roleRead allows queries, and receiving events and change events, in a namespace
+	roleRead = "read"
+	// roleWrite allows every other request in a namespace
+	roleWrite = "write"
+	// roleUnmasked allows the values covered by the masking rules of a namespace to be seen
+	roleUnmasked = "unmasked"
+)
+
+// namespacePrincipal is a caller configured in the access list of a namespace, identified either by
+// a bearer token or by the common name of its TLS client certificate
+type namespacePrincipal struct {
+	name        string
+	token       string
+	certificate string
+	roles       map[string]bool
+}
+
+// authorizer enforces the access lists in the predefined namespace config. Namespaces without an
+// access list are open to every caller, as they were before access lists were introduced.
+type authorizer struct {
+	namespaces map[string][]*namespacePrincipal
+}
+
+func newAuthorizer() *authorizer {
+	az := &authorizer{
+		namespaces: make(map[string][]*namespacePrincipal),
+	}
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		if _, ok := entry["access"]; !ok {
+			continue
+		}
+		access := entry.GetObjectArray("access")
+		principals := make([]*namespacePrincipal, 0, len(access))
+		for _, a := range access {
+			p := &namespacePrincipal{
+				name:        a.GetString("name"),
+				token:       a.GetString("token"),
+				certificate: a.GetString("certificate"),
+				roles:       make(map[string]bool),
+			}
+			for _, role := range a.GetStringArray("roles") {
+				p.roles[role] = true
+			}
+			principals = append(principals, p)
+		}
+		az.namespaces[entry.GetString("name")] = principals
+	}
+	return az
+}
+
+func (p *namespacePrincipal) matches(req *http.Request) bool {
+	if p.token != "" {
+		auth := req.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") &&
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(p.token)) == 1 {
+			return true
+		}
+	}
+	if p.certificate != "" && req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return req.TLS.PeerCertificates[0].Subject.CommonName == p.certificate
+	}
+	return false
+}
+
+// authorize checks the caller has the role in the namespace, returning a 401 error if the caller could not be
+// identified, or a 403 error if it does not have the role
+func (az *authorizer) authorize(req *http.Request, ns, role string) error {
+	principals, restricted := az.namespaces[ns]
+	if !restricted {
+		return nil
+	}
+	for _, p := range principals {
+		if p.matches(req) {
+			if !p.roles[role] {
+				return i18n.NewError(req.Context(), i18n.MsgNamespaceForbidden, p.name, role, ns)
+			}
+			return nil
+		}
+	}
+	return i18n.NewError(req.Context(), i18n.MsgNamespaceUnauthorized, ns)
+}
+
+// principalName returns the name of the caller in the access list of the namespace, or an empty string if the
+// namespace has no access list, or the caller is not in it
+func (az *authorizer) principalName(req *http.Request, ns string) string {
+	for _, p := range az.namespaces[ns] {
+		if p.matches(req) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+type principalContextKey struct{}
+
+func withPrincipal(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, name)
+}
+
+func principalFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(principalContextKey{}).(string)
+	return name
+}
+
+// bindApprover makes the authenticated caller the approver of a decision. Approvers are named in the access
+// list of the namespace, so a decision cannot be made in the name of another approver.
+func bindApprover(ctx context.Context, ns string, input *fftypes.ApprovalDecisionInput) error {
+	principal := principalFromContext(ctx)
+	if principal == "" {
+		return i18n.NewError(ctx, i18n.MsgApproverNotAuthenticated, ns)
+	}
+	if input.Approver != "" && input.Approver != principal {
+		return i18n.NewError(ctx, i18n.MsgApproverNotCaller, input.Approver, principal)
+	}
+	input.Approver = principal
+	return nil
+}
+
+// hasRole returns true if the caller is identified in the access list of the namespace, and has the role
+func (az *authorizer) hasRole(req *http.Request, ns, role string) bool {
+	for _, p := range az.namespaces[ns] {
+		if p.matches(req) {
+			return p.roles[role]
+		}
+	}
+	return false
+}
+
+// authorizeRoute checks the caller can make a request to a route with a namespace in its path. GET
+// requests need the read role, and all others the write role.
+func (az *authorizer) authorizeRoute(req *http.Request, ns string) error {
+	if ns == "" {
+		return nil
+	}
+	role := roleWrite
+	if req.Method == http.MethodGet {
+		role = roleRead
+	}
+	return az.authorize(req, ns, role)
+}
+
+// namespaceReader returns a check of whether the caller can read a namespace, for connections that
+// deliver data from many namespaces. Data without a namespace, such as pins, can relate to any of them,
+// so it can only be read by a caller that can read every namespace with an access list.
+func (az *authorizer) namespaceReader(req *http.Request) func(ns string) bool {
+	return func(ns string) bool {
+		if ns != "" {
+			return az.authorize(req, ns, roleRead) == nil
+		}
+		for restricted := range az.namespaces {
+			if az.authorize(req, restricted, roleRead) != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
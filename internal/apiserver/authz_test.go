@@ -0,0 +1,125 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuthorizer() *authorizer {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "open"},
+		{
+			"name": "ns1",
+			"access": fftypes.JSONObjectArray{
+				{"name": "dashboard", "token": "token1", "roles": []interface{}{"read"}},
+				{"name": "app", "certificate": "app.example.com", "roles": []interface{}{"read", "write"}},
+			},
+		},
+	})
+	az := newAuthorizer()
+	config.Reset()
+	return az
+}
+
+func TestAuthorizeOpenNamespace(t *testing.T) {
+	az := newTestAuthorizer()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/open/messages/broadcast", nil)
+	assert.NoError(t, az.authorizeRoute(req, "open"))
+	assert.NoError(t, az.authorizeRoute(req, ""))
+}
+
+func TestAuthorizeToken(t *testing.T) {
+	az := newTestAuthorizer()
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	assert.NoError(t, az.authorizeRoute(req, "ns1"))
+
+	req = httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	assert.Regexp(t, "FF10532.*dashboard.*write", az.authorizeRoute(req, "ns1"))
+
+	req.Header.Set("Authorization", "Bearer wrong")
+	assert.Regexp(t, "FF10531", az.authorizeRoute(req, "ns1"))
+}
+
+func TestAuthorizeCertificate(t *testing.T) {
+	az := newTestAuthorizer()
+
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", nil)
+	assert.Regexp(t, "FF10531", az.authorizeRoute(req, "ns1"))
+
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "app.example.com"}}},
+	}
+	assert.NoError(t, az.authorizeRoute(req, "ns1"))
+}
+
+func TestNamespaceReader(t *testing.T) {
+	az := newTestAuthorizer()
+	req := httptest.NewRequest("GET", "/ws", nil)
+	reader := az.namespaceReader(req)
+	assert.True(t, reader("open"))
+	assert.False(t, reader("ns1"))
+	assert.False(t, reader(""))
+
+	req.Header.Set("Authorization", "Bearer token1")
+	assert.True(t, reader("ns1"))
+	assert.True(t, reader(""))
+}
+
+func TestNamespaceReaderNoAccessLists(t *testing.T) {
+	az := &authorizer{namespaces: map[string][]*namespacePrincipal{}}
+	reader := az.namespaceReader(httptest.NewRequest("GET", "/ws", nil))
+	assert.True(t, reader(""))
+}
+
+func TestRouteUnauthorized(t *testing.T) {
+	az := newTestAuthorizer()
+	o, as := newTestServer()
+	as.authz = az
+	r := as.createMuxRouter(context.Background(), o)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+}
+
+func TestRouteForbidden(t *testing.T) {
+	az := newTestAuthorizer()
+	o, as := newTestServer()
+	as.authz = az
+	r := as.createMuxRouter(context.Background(), o)
+
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/events/websockets"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// queryList returns the values of a query parameter, which can be repeated or comma separated
+func queryList(req *http.Request, key string) []string {
+	var values []string
+	for _, v := range req.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+func parseChangeEventFilter(req *http.Request, ns string) (*fftypes.ChangeEventFilter, error) {
+	filter := &fftypes.ChangeEventFilter{
+		Namespace:   ns,
+		Collections: queryList(req, "collections"),
+	}
+	for _, t := range queryList(req, "types") {
+		switch ceType := fftypes.ChangeEventType(strings.ToLower(t)); ceType {
+		case fftypes.ChangeEventTypeCreated, fftypes.ChangeEventTypeUpdated, fftypes.ChangeEventTypeDeleted:
+			filter.Types = append(filter.Types, ceType)
+		default:
+			return nil, i18n.NewError(req.Context(), i18n.MsgInvalidChangeEventFilter, "unknown type "+t)
+		}
+	}
+	return filter, nil
+}
+
+// changeEventsHandler upgrades to a WebSocket that delivers the change events of one namespace. The caller
+// has been authorized to read the namespace by the middleware, and a bad filter is returned as a normal API
+// error before the upgrade.
+func (as *apiServer) changeEventsHandler(ws *websockets.WebSockets) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		filter, err := parseChangeEventFilter(req, mux.Vars(req)["ns"])
+		if err != nil {
+			as.apiWrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+				return http.StatusBadRequest, err
+			})(res, req)
+			return
+		}
+		ws.ServeChangeEvents(res, req, filter)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events/websockets"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseChangeEventFilter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/changeevents?collections=messages,events&collections=data&types=Created,deleted", nil)
+	filter, err := parseChangeEventFilter(req, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", filter.Namespace)
+	assert.Equal(t, []string{"messages", "events", "data"}, filter.Collections)
+	assert.Equal(t, []fftypes.ChangeEventType{fftypes.ChangeEventTypeCreated, fftypes.ChangeEventTypeDeleted}, filter.Types)
+
+	req = httptest.NewRequest("GET", "/api/v1/namespaces/ns1/changeevents?types=moved", nil)
+	_, err = parseChangeEventFilter(req, "ns1")
+	assert.Regexp(t, "FF10533.*moved", err)
+}
+
+func TestChangeEventsBadFilter(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/changeevents?types=moved", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
+
+func TestChangeEventsUnauthorized(t *testing.T) {
+	az := newTestAuthorizer()
+	o, as := newTestServer()
+	as.authz = az
+	r := as.createMuxRouter(context.Background(), o)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/changeevents", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+}
+
+func TestChangeEventsDelivered(t *testing.T) {
+	az := newTestAuthorizer()
+	_, as := newTestServer()
+	as.authz = az
+
+	mcb := &eventsmocks.Callbacks{}
+	ws := &websockets.WebSockets{}
+	prefix := config.NewPluginConfig("ut.websockets")
+	ws.InitPrefix(prefix)
+	err := ws.Init(context.Background(), prefix, mcb)
+	assert.NoError(t, err)
+	started := make(chan string, 1)
+	mcb.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.MatchedBy(func(opts *fftypes.SubscriptionOptions) bool {
+		return opts.ChangeEvents
+	})).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil)
+	mcb.On("ConnnectionClosed", mock.Anything).Return(nil).Maybe()
+
+	r := mux.NewRouter()
+	r.HandleFunc(`/api/v1/namespaces/{ns}/changeevents`, as.changeEventsHandler(ws))
+	svr := httptest.NewServer(r)
+	defer svr.Close()
+
+	url := fmt.Sprintf("ws://%s/api/v1/namespaces/ns1/changeevents?collections=messages", strings.TrimPrefix(svr.URL, "http://"))
+	conn, _, err := websocket.DefaultDialer.Dial(url, map[string][]string{"Authorization": {"Bearer token1"}})
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	connID := <-started
+	ws.ChangeEvent(connID, &fftypes.ChangeEvent{Namespace: "ns2", Collection: "messages"})
+	ws.ChangeEvent(connID, &fftypes.ChangeEvent{Namespace: "ns1", Collection: "data"})
+	ws.ChangeEvent(connID, &fftypes.ChangeEvent{Namespace: "ns1", Collection: "messages", Type: fftypes.ChangeEventTypeCreated})
+
+	var notification fftypes.WSChangeNotification
+	err = conn.ReadJSON(&notification)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", notification.ChangeEvent.Namespace)
+	assert.Equal(t, "messages", notification.ChangeEvent.Collection)
+}
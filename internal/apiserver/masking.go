@@ -0,0 +1,226 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// maskedValue replaces each value covered by a masking rule
+const maskedValue = "********"
+
+// maskingRules are the JSON paths masked in the responses of a namespace. Each path is a list of keys,
+// where "*" matches every key of an object or every entry of an array.
+type maskingRules struct {
+	data       [][]string
+	operations [][]string
+}
+
+// masker applies the masking rules in the predefined namespace config to API responses, for callers
+// without the unmasked role. Hashes and other metadata are left in place, so records can still be
+// correlated and verified without the values being visible.
+type masker struct {
+	authz      *authorizer
+	namespaces map[string]*maskingRules
+}
+
+type maskingContextKey struct{}
+
+func parseMaskingPaths(ns, section string, paths []string) [][]string {
+	parsed := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		keys := strings.Split(strings.TrimPrefix(strings.TrimPrefix(path, "$"), "."), ".")
+		valid := true
+		for _, key := range keys {
+			valid = valid && key != ""
+		}
+		if !valid {
+			log.L(context.Background()).Warnf("Ignoring invalid %s masking path '%s' in namespace '%s'", section, path, ns)
+			continue
+		}
+		parsed = append(parsed, keys)
+	}
+	return parsed
+}
+
+func newMasker(az *authorizer) *masker {
+	m := &masker{
+		authz:      az,
+		namespaces: make(map[string]*maskingRules),
+	}
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		if _, ok := entry["masking"]; !ok {
+			continue
+		}
+		ns := entry.GetString("name")
+		masking := entry.GetObject("masking")
+		rules := &maskingRules{
+			data:       parseMaskingPaths(ns, "data", masking.GetStringArray("data")),
+			operations: parseMaskingPaths(ns, "operations", masking.GetStringArray("operations")),
+		}
+		if len(rules.data) > 0 || len(rules.operations) > 0 {
+			m.namespaces[ns] = rules
+		}
+	}
+	return m
+}
+
+// rulesFor returns the masking rules that apply to the caller in a namespace, or nil if nothing is masked
+func (m *masker) rulesFor(req *http.Request, ns string) *maskingRules {
+	rules := m.namespaces[ns]
+	if rules == nil || m.authz.hasRole(req, ns, roleUnmasked) {
+		return nil
+	}
+	return rules
+}
+
+// dataMasked returns true if data values are masked for the request, so routes can avoid returning
+// data in a form the masking rules cannot be applied to
+func dataMasked(ctx context.Context) bool {
+	rules, _ := ctx.Value(maskingContextKey{}).(*maskingRules)
+	return rules != nil && len(rules.data) > 0
+}
+
+func maskPath(v interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return maskedValue
+	}
+	key, remaining := keys[0], keys[1:]
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, child := range vt {
+			if key == "*" || key == k {
+				vt[k] = maskPath(child, remaining)
+			}
+		}
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		for i, child := range vt {
+			if key == "*" || (err == nil && index == i) {
+				vt[i] = maskPath(child, remaining)
+			}
+		}
+	}
+	return v
+}
+
+func maskJSON(b []byte, paths [][]string) []byte {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		// Values are always JSON, but nothing is returned that the rules cannot be applied to
+		v = maskedValue
+	}
+	for _, path := range paths {
+		v = maskPath(v, path)
+	}
+	masked, _ := json.Marshal(v)
+	return masked
+}
+
+func (rules *maskingRules) maskValue(value *fftypes.JSONAny) *fftypes.JSONAny {
+	if value == nil || len(rules.data) == 0 {
+		return value
+	}
+	return fftypes.JSONAnyPtrBytes(maskJSON(value.Bytes(), rules.data))
+}
+
+func (rules *maskingRules) maskData(d *fftypes.Data) *fftypes.Data {
+	if d == nil || len(rules.data) == 0 {
+		return d
+	}
+	masked := *d
+	masked.Value = rules.maskValue(d.Value)
+	masked.Original = nil
+	return &masked
+}
+
+func (rules *maskingRules) maskDataArray(data fftypes.DataArray) fftypes.DataArray {
+	masked := make(fftypes.DataArray, len(data))
+	for i, d := range data {
+		masked[i] = rules.maskData(d)
+	}
+	return masked
+}
+
+func (rules *maskingRules) maskMessage(msg *fftypes.MessageInOut) *fftypes.MessageInOut {
+	if msg == nil || len(rules.data) == 0 {
+		return msg
+	}
+	masked := *msg
+	masked.InlineData = make(fftypes.InlineData, len(msg.InlineData))
+	for i, d := range msg.InlineData {
+		maskedData := *d
+		maskedData.Value = rules.maskValue(d.Value)
+		masked.InlineData[i] = &maskedData
+	}
+	return &masked
+}
+
+func (rules *maskingRules) maskOperation(op *fftypes.Operation) *fftypes.Operation {
+	if op == nil || op.Input == nil || len(rules.operations) == 0 {
+		return op
+	}
+	masked := *op
+	b, _ := json.Marshal(op.Input)
+	_ = json.Unmarshal(maskJSON(b, rules.operations), &masked.Input)
+	return &masked
+}
+
+// mask returns a copy of the output of a route with the masking rules applied. The output is never
+// modified in place, as it might be held in a cache.
+func (rules *maskingRules) mask(output interface{}) interface{} {
+	switch o := output.(type) {
+	case *filterResultsWithCount:
+		masked := *o
+		masked.Items = rules.mask(o.Items)
+		return &masked
+	case *fftypes.JSONAny:
+		return rules.maskValue(o)
+	case *fftypes.Data:
+		return rules.maskData(o)
+	case fftypes.DataArray:
+		return rules.maskDataArray(o)
+	case []*fftypes.Data:
+		return []*fftypes.Data(rules.maskDataArray(o))
+	case *fftypes.MessageInOut:
+		return rules.maskMessage(o)
+	case []*fftypes.MessageInOut:
+		masked := make([]*fftypes.MessageInOut, len(o))
+		for i, msg := range o {
+			masked[i] = rules.maskMessage(msg)
+		}
+		return masked
+	case *fftypes.Operation:
+		return rules.maskOperation(o)
+	case []*fftypes.Operation:
+		masked := make([]*fftypes.Operation, len(o))
+		for i, op := range o {
+			masked[i] = rules.maskOperation(op)
+		}
+		return masked
+	default:
+		return output
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setTestMaskingConfig() {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "open"},
+		{
+			"name": "ns1",
+			"access": fftypes.JSONObjectArray{
+				{"name": "dashboard", "token": "token1", "roles": []interface{}{"read"}},
+				{"name": "auditor", "token": "token2", "roles": []interface{}{"read", "unmasked"}},
+			},
+			"masking": fftypes.JSONObject{
+				"data":       []interface{}{"customer.name", "$.accounts.*.number", "bad..path"},
+				"operations": []interface{}{"input.value.customer"},
+			},
+		},
+	})
+}
+
+func newTestMasker() *masker {
+	setTestMaskingConfig()
+	m := newMasker(newAuthorizer())
+	config.Reset()
+	return m
+}
+
+func newTestMaskingAPIServer() (*orchestratormocks.Orchestrator, *mux.Router) {
+	setTestMaskingConfig()
+	mor, as := newTestServer()
+	config.Reset()
+	return mor, as.createMuxRouter(context.Background(), mor)
+}
+
+func TestMaskingRulesFor(t *testing.T) {
+	m := newTestMasker()
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data", nil)
+	assert.Nil(t, m.rulesFor(req, "open"))
+	rules := m.rulesFor(req, "ns1")
+	assert.Equal(t, [][]string{{"customer", "name"}, {"accounts", "*", "number"}}, rules.data)
+	assert.Equal(t, [][]string{{"input", "value", "customer"}}, rules.operations)
+
+	req.Header.Set("Authorization", "Bearer token1")
+	assert.NotNil(t, m.rulesFor(req, "ns1"))
+	req.Header.Set("Authorization", "Bearer token2")
+	assert.Nil(t, m.rulesFor(req, "ns1"))
+}
+
+func TestMaskData(t *testing.T) {
+	m := newTestMasker()
+	rules := m.namespaces["ns1"]
+
+	hash := fftypes.NewRandB32()
+	data := &fftypes.Data{
+		ID:       fftypes.NewUUID(),
+		Hash:     hash,
+		Value:    fftypes.JSONAnyPtr(`{"customer":{"name":"Jane","id":12},"accounts":[{"number":"1234"},{"number":"5678","type":"savings"}]}`),
+		Original: []byte("<customer>...</customer>"),
+	}
+	masked := rules.mask(fftypes.DataArray{data}).(fftypes.DataArray)[0]
+	assert.JSONEq(t, `{"customer":{"name":"********","id":12},"accounts":[{"number":"********"},{"number":"********","type":"savings"}]}`, masked.Value.String())
+	assert.Equal(t, hash, masked.Hash)
+	assert.Nil(t, masked.Original)
+
+	// The original is not modified, as it might be cached
+	assert.Regexp(t, "Jane", data.Value.String())
+	assert.NotNil(t, data.Original)
+}
+
+func TestMaskPaths(t *testing.T) {
+	v := maskJSON([]byte(`{"a":[{"b":1},{"b":2}],"c":"d"}`), [][]string{{"a", "1", "b"}, {"missing", "x"}, {"c", "deeper"}})
+	assert.JSONEq(t, `{"a":[{"b":1},{"b":"********"}],"c":"d"}`, string(v))
+
+	v = maskJSON([]byte(`{"a":{"b":{"c":1}}}`), [][]string{{"a", "*"}})
+	assert.JSONEq(t, `{"a":{"b":"********"}}`, string(v))
+
+	v = maskJSON([]byte(`not json`), [][]string{{"a"}})
+	assert.Equal(t, `"********"`, string(v))
+}
+
+func TestMaskOutputTypes(t *testing.T) {
+	m := newTestMasker()
+	rules := m.namespaces["ns1"]
+	value := fftypes.JSONAnyPtr(`{"customer":{"name":"Jane"}}`)
+	maskedValue := `{"customer":{"name":"********"}}`
+
+	assert.JSONEq(t, maskedValue, rules.mask(value).(*fftypes.JSONAny).String())
+	assert.JSONEq(t, maskedValue, rules.mask(&fftypes.Data{Value: value}).(*fftypes.Data).Value.String())
+	assert.JSONEq(t, maskedValue, rules.mask([]*fftypes.Data{{Value: value}}).([]*fftypes.Data)[0].Value.String())
+	assert.Nil(t, rules.mask((*fftypes.Data)(nil)).(*fftypes.Data))
+
+	msg := &fftypes.MessageInOut{InlineData: fftypes.InlineData{{Value: value}, {DataRef: fftypes.DataRef{ID: fftypes.NewUUID()}}}}
+	maskedMsgs := rules.mask([]*fftypes.MessageInOut{msg}).([]*fftypes.MessageInOut)
+	assert.JSONEq(t, maskedValue, maskedMsgs[0].InlineData[0].Value.String())
+	assert.Nil(t, maskedMsgs[0].InlineData[1].Value)
+	assert.Regexp(t, "Jane", msg.InlineData[0].Value.String())
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Input: fftypes.JSONObject{"input": map[string]interface{}{"value": value}}}
+	maskedOps := rules.mask(&filterResultsWithCount{Items: []*fftypes.Operation{op, {}}}).(*filterResultsWithCount).Items.([]*fftypes.Operation)
+	assert.Equal(t, `{"input":{"value":{"customer":"********"}}}`, maskedOps[0].Input.String())
+	assert.Equal(t, op.ID, maskedOps[0].ID)
+	assert.Nil(t, maskedOps[1].Input)
+	assert.Equal(t, op.ID, rules.mask(op).(*fftypes.Operation).ID)
+
+	event := &fftypes.Event{ID: fftypes.NewUUID()}
+	assert.Equal(t, event, rules.mask(event))
+}
+
+func TestMaskingRoute(t *testing.T) {
+	o, r := newTestMaskingAPIServer()
+	o.On("GetDataByID", mock.Anything, "ns1", "abcd1234").
+		Return(&fftypes.Data{
+			Value:     fftypes.JSONAnyPtr(`{"customer":{"name":"Jane"}}`),
+			MediaType: fftypes.DataMediaTypeXML,
+			Original:  []byte("<customer><name>Jane</name></customer>"),
+		}, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data/abcd1234", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var data fftypes.Data
+	err := json.NewDecoder(res.Body).Decode(&data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"customer":{"name":"********"}}`, data.Value.String())
+	assert.Nil(t, data.Original)
+
+	// The original cannot be masked, so the masked JSON value is returned instead
+	req = httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data/abcd1234/value", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	req.Header.Set("Accept", "application/xml")
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.JSONEq(t, `{"customer":{"name":"********"}}`, string(b))
+
+	// The unmasked role sees the value
+	req = httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data/abcd1234", nil)
+	req.Header.Set("Authorization", "Bearer token2")
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	b, _ = ioutil.ReadAll(res.Body)
+	assert.Regexp(t, "Jane", string(b))
+}
+
+func TestMaskingRouteValueFilter(t *testing.T) {
+	_, r := newTestMaskingAPIServer()
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data?value.customer.name=Jane", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+	var resJSON map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&resJSON)
+	assert.Regexp(t, "FF10553", resJSON["error"])
+}
+
+func TestMaskingRouteWholeValueFilter(t *testing.T) {
+	_, r := newTestMaskingAPIServer()
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data?Value=Jane", nil)
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestMaskingRouteValueSort(t *testing.T) {
+	_, r := newTestMaskingAPIServer()
+
+	for _, sort := range []string{"value", "-value", "created,-value.customer.name"} {
+		req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/data?sort="+sort, nil)
+		req.Header.Set("Authorization", "Bearer token1")
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.Equal(t, 403, res.Result().StatusCode, sort)
+	}
+}
+
+func TestQueriesValue(t *testing.T) {
+	as := &apiServer{}
+	assert.False(t, as.queriesValue(url.Values{"validator": {"json"}, "sort": {"created,-validator"}}))
+	assert.False(t, as.queriesValue(url.Values{"value.": {"x"}}))
+	assert.True(t, as.queriesValue(url.Values{"VALUE.a": {"x"}}))
+	assert.True(t, as.queriesValue(url.Values{"Sort": {" -Value "}}))
+}
@@ -72,14 +72,54 @@ func (as *apiServer) getValues(values url.Values, key string) (results []string)
 	return results
 }
 
+// expandWildcardFields replaces any wildcard fields (such as "value.*") with the names of
+// the query parameters that match the wildcard prefix
+func (as *apiServer) expandWildcardFields(values url.Values, fields []string) []string {
+	expanded := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !strings.HasSuffix(field, database.WildcardFieldSuffix) {
+			expanded = append(expanded, field)
+			continue
+		}
+		prefix := strings.TrimSuffix(field, "*")
+		for queryName := range values {
+			if len(queryName) > len(prefix) && strings.EqualFold(queryName[0:len(prefix)], prefix) {
+				expanded = append(expanded, queryName)
+			}
+		}
+	}
+	return expanded
+}
+
+// queriesValue returns true if the query filters or sorts on the data value, either as
+// a whole or on any of the JSON paths within it
+func (as *apiServer) queriesValue(values url.Values) bool {
+	isValueField := func(field string) bool {
+		return strings.EqualFold(field, "value") || (len(field) > len("value.") && strings.EqualFold(field[0:len("value.")], "value."))
+	}
+	for queryName := range values {
+		if isValueField(queryName) {
+			return true
+		}
+	}
+	for _, sv := range as.getValues(values, "sort") {
+		for _, ssv := range strings.Split(sv, ",") {
+			if isValueField(strings.TrimPrefix(strings.TrimSpace(ssv), "-")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (database.AndFilter, error) {
 	ctx := req.Context()
 	log.L(ctx).Debugf("Query: %s", req.URL.RawQuery)
 	fb := ff.NewFilterLimit(ctx, as.defaultFilterLimit)
-	possibleFields := fb.Fields()
+	_ = req.ParseForm()
+	possibleFields := as.expandWildcardFields(req.Form, fb.Fields())
 	sort.Strings(possibleFields)
 	filter := fb.And()
-	_ = req.ParseForm()
 	for _, field := range possibleFields {
 		values := as.getValues(req.Form, field)
 		if len(values) == 1 {
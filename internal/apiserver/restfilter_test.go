@@ -128,6 +128,20 @@ func TestBuildFilterAscending(t *testing.T) {
 	assert.Equal(t, "( created == 0 ) sort=tag,sequence", fi.String())
 }
 
+func TestBuildFilterWildcardFields(t *testing.T) {
+	as := &apiServer{
+		maxFilterLimit: 250,
+	}
+
+	req := httptest.NewRequest("GET", "/things?value.orderId=order1&Value.customer.name=^cust&validator=json", nil)
+	filter, err := as.buildFilter(req, database.DataQueryFactory)
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "( Value.customer.name ^= 'cust' ) && ( validator == 'json' ) && ( value.orderId == 'order1' )", fi.String())
+}
+
 func TestBuildFilterLimitSkip(t *testing.T) {
 	as := &apiServer{
 		maxFilterSkip: 250,
@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetApprovalRequestByID(t *testing.T) {
+	o, r := newTestAPIServer()
+	mar := &approvalmocks.Manager{}
+	o.On("Approvals").Return(mar)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/approvalrequests/abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mar.On("GetApprovalRequestByID", mock.Anything, "mynamespace", "abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b").
+		Return(&fftypes.ApprovalRequest{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -18,6 +18,7 @@ package apiserver
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -33,7 +34,9 @@ var getBlockchainEventByID = &oapispec.Route{
 		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
 		{Name: "id", Description: i18n.MsgTBD},
 	},
-	QueryParams:     nil,
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "fetchraw", IsBool: true, Description: i18n.MsgFetchRawDesc},
+	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  nil,
@@ -45,6 +48,9 @@ var getBlockchainEventByID = &oapispec.Route{
 		if err != nil {
 			return nil, err
 		}
+		if strings.EqualFold(r.QP["fetchraw"], "true") {
+			return getOr(r.Ctx).GetBlockchainEventByIDWithRaw(r.Ctx, u)
+		}
 		return getOr(r.Ctx).GetBlockchainEventByID(r.Ctx, u)
 	},
 }
@@ -49,3 +49,17 @@ func TestGetBlockchainEventBadID(t *testing.T) {
 
 	assert.Equal(t, 400, res.Result().StatusCode)
 }
+
+func TestGetBlockchainEventByIDWithRaw(t *testing.T) {
+	o, r := newTestAPIServer()
+	id := fftypes.NewUUID()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/blockchainevents/"+id.String()+"?fetchraw", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetBlockchainEventByIDWithRaw", mock.Anything, id).
+		Return(&fftypes.BlockchainEvent{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -18,6 +18,7 @@ package apiserver
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -33,7 +34,9 @@ var getBlockchainEvents = &oapispec.Route{
 	PathParams: []*oapispec.PathParam{
 		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
 	},
-	QueryParams:     nil,
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "fetchraw", IsBool: true, Description: i18n.MsgFetchRawDesc},
+	},
 	FilterFactory:   database.BlockchainEventQueryFactory,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  nil,
@@ -41,6 +44,9 @@ var getBlockchainEvents = &oapispec.Route{
 	JSONOutputValue: func() interface{} { return []*fftypes.BlockchainEvent{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		if strings.EqualFold(r.QP["fetchraw"], "true") {
+			return filterResult(getOr(r.Ctx).GetBlockchainEventsWithRaw(r.Ctx, r.PP["ns"], r.Filter))
+		}
 		return filterResult(getOr(r.Ctx).GetBlockchainEvents(r.Ctx, r.PP["ns"], r.Filter))
 	},
 }
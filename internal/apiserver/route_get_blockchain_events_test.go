@@ -37,3 +37,16 @@ func TestGetBlockchainEvents(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetBlockchainEventsWithRaw(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/blockchainevents?fetchraw", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetBlockchainEventsWithRaw", mock.Anything, "mynamespace", mock.Anything).
+		Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
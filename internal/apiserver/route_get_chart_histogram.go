@@ -19,6 +19,8 @@ package apiserver
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -39,6 +41,9 @@ var getChartHistogram = &oapispec.Route{
 		{Name: "startTime", Description: i18n.MsgHistogramStartTimeParam, IsBool: false},
 		{Name: "endTime", Description: i18n.MsgHistogramEndTimeParam, IsBool: false},
 		{Name: "buckets", Description: i18n.MsgHistogramBucketsParam, IsBool: false},
+		{Name: "interval", Description: i18n.MsgHistogramIntervalParam, IsBool: false},
+		{Name: "groupBy", Description: i18n.MsgHistogramGroupByParam, IsBool: false},
+		{Name: "percentiles", Description: i18n.MsgHistogramPercentilesParam, IsBool: false},
 	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
@@ -54,10 +59,26 @@ var getChartHistogram = &oapispec.Route{
 		if err != nil {
 			return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidChartNumberParam, "endTime")
 		}
-		buckets, err := strconv.ParseInt(r.QP["buckets"], 10, 64)
-		if err != nil {
+		options := &fftypes.ChartHistogramOptions{
+			GroupBy: r.QP["groupBy"],
+		}
+		var buckets int64
+		if r.QP["interval"] != "" {
+			if options.Interval, err = fftypes.ParseDurationString(r.QP["interval"], time.Millisecond); err != nil {
+				return nil, err
+			}
+		} else if buckets, err = strconv.ParseInt(r.QP["buckets"], 10, 64); err != nil {
 			return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidChartNumberParam, "buckets")
 		}
-		return getOr(r.Ctx).GetChartHistogram(r.Ctx, r.PP["ns"], startTime.UnixNano(), endTime.UnixNano(), buckets, database.CollectionName(r.PP["collection"]))
+		if r.QP["percentiles"] != "" {
+			for _, p := range strings.Split(r.QP["percentiles"], ",") {
+				percentile, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+				if err != nil {
+					return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidChartNumberParam, "percentiles")
+				}
+				options.Percentiles = append(options.Percentiles, percentile)
+			}
+		}
+		return getOr(r.Ctx).GetChartHistogram(r.Ctx, r.PP["ns"], startTime.UnixNano(), endTime.UnixNano(), buckets, database.CollectionName(r.PP["collection"]), options)
 	},
 }
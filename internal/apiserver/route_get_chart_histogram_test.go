@@ -19,6 +19,7 @@ package apiserver
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -68,9 +69,50 @@ func TestGetChartHistogramSuccess(t *testing.T) {
 	startTime, _ := fftypes.ParseTimeString("1234567890")
 	endtime, _ := fftypes.ParseTimeString("1234567891")
 
-	o.On("GetChartHistogram", mock.Anything, "mynamespace", startTime.UnixNano(), endtime.UnixNano(), int64(30), database.CollectionName("test")).
+	o.On("GetChartHistogram", mock.Anything, "mynamespace", startTime.UnixNano(), endtime.UnixNano(), int64(30), database.CollectionName("test"), &fftypes.ChartHistogramOptions{}).
 		Return([]*fftypes.ChartHistogram{}, nil)
 	r.ServeHTTP(res, req)
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetChartHistogramOptions(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/charts/histogram/messages?startTime=1234567890&endTime=1234567891&interval=1h&groupBy=tag&percentiles=50,%2099.9", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	startTime, _ := fftypes.ParseTimeString("1234567890")
+	endtime, _ := fftypes.ParseTimeString("1234567891")
+
+	o.On("GetChartHistogram", mock.Anything, "mynamespace", startTime.UnixNano(), endtime.UnixNano(), int64(0), database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Interval:    fftypes.FFDuration(time.Hour),
+		GroupBy:     "tag",
+		Percentiles: []float64{50, 99.9},
+	}).Return([]*fftypes.ChartHistogram{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetChartHistogramBadInterval(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/charts/histogram/test?startTime=123&endTime=456&interval=abc", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
+
+func TestGetChartHistogramBadPercentiles(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/charts/histogram/messages?startTime=123&endTime=456&buckets=10&percentiles=50,abc", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
@@ -0,0 +1,69 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// acceptsMediaType returns true if the Accept header of the request explicitly lists the media type
+func acceptsMediaType(req *http.Request, mediaType string) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		if t, _, err := mime.ParseMediaType(accept); err == nil && t == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+var getDataValue = &oapispec.Route{
+	Name:   "getDataValue",
+	Path:   "namespaces/{ns}/data/{dataid}/value",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+		{Name: "dataid", Description: i18n.MsgTBD},
+	},
+	QueryParams:     nil,
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return fftypes.JSONAnyPtr("{}") },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		data, err := getOr(r.Ctx).GetDataByID(r.Ctx, r.PP["ns"], r.PP["dataid"])
+		if err != nil || data == nil {
+			return data, err
+		}
+		// The original payload is returned if the caller asks for the media type it was supplied in,
+		// otherwise the canonical JSON value is returned - which is also the only form that can be masked
+		if data.MediaType != "" && acceptsMediaType(r.Req, data.MediaType) && !dataMasked(r.Ctx) {
+			r.ResponseHeaders.Set("Content-Type", data.MediaType)
+			return ioutil.NopCloser(bytes.NewReader(data.Original)), nil
+		}
+		return data.Value, nil
+	},
+}
@@ -0,0 +1,92 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetDataValueJSON(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/data/abcd1234/value", nil)
+	req.Header.Set("Accept", "application/json")
+	res := httptest.NewRecorder()
+
+	o.On("GetDataByID", mock.Anything, "mynamespace", "abcd1234").
+		Return(&fftypes.Data{
+			Value:     fftypes.JSONAnyPtr(`{"root":"hello"}`),
+			MediaType: fftypes.DataMediaTypeXML,
+			Original:  []byte("<root>hello</root>"),
+		}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/json", res.Result().Header.Get("Content-Type"))
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.JSONEq(t, `{"root":"hello"}`, string(b))
+}
+
+func TestGetDataValueOriginal(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/data/abcd1234/value", nil)
+	req.Header.Set("Accept", "text/html, application/xml;q=0.9")
+	res := httptest.NewRecorder()
+
+	o.On("GetDataByID", mock.Anything, "mynamespace", "abcd1234").
+		Return(&fftypes.Data{
+			Value:     fftypes.JSONAnyPtr(`{"root":"hello"}`),
+			MediaType: fftypes.DataMediaTypeXML,
+			Original:  []byte("<root>hello</root>"),
+		}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/xml", res.Result().Header.Get("Content-Type"))
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "<root>hello</root>", string(b))
+}
+
+func TestGetDataValueNotFound(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/data/abcd1234/value", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetDataByID", mock.Anything, "mynamespace", "abcd1234").
+		Return(nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestGetDataValueError(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/data/abcd1234/value", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetDataByID", mock.Anything, "mynamespace", "abcd1234").
+		Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
@@ -36,6 +36,8 @@ var getMsgByID = &oapispec.Route{
 	},
 	QueryParams: []*oapispec.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: i18n.MsgFetchDataDesc},
+		{Name: "waitfor", Description: i18n.MsgWaitForDesc},
+		{Name: "timeout", Description: i18n.MsgWaitForTimeoutDesc},
 	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
@@ -43,6 +45,11 @@ var getMsgByID = &oapispec.Route{
 	JSONOutputValue: func() interface{} { return &fftypes.MessageInOut{} }, // can include full values
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		if r.QP["waitfor"] != "" {
+			if err := getOr(r.Ctx).WaitForMessageState(r.Ctx, r.PP["ns"], r.PP["msgid"], r.QP["waitfor"], r.QP["timeout"]); err != nil {
+				return nil, err
+			}
+		}
 		if strings.EqualFold(r.QP["data"], "true") || strings.EqualFold(r.QP["fetchdata"], "true") {
 			return getOr(r.Ctx).GetMessageByIDWithData(r.Ctx, r.PP["ns"], r.PP["msgid"])
 		}
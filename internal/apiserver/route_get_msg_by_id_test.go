@@ -17,9 +17,11 @@
 package apiserver
 
 import (
+	"context"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -50,3 +52,31 @@ func TestGetMessageByIDWithData(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetMessageByIDWaitFor(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?waitfor=confirmed&timeout=10s", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("WaitForMessageState", mock.Anything, "mynamespace", "abcd12345", "confirmed", "10s").
+		Return(nil)
+	o.On("GetMessageByID", mock.Anything, "mynamespace", "abcd12345").
+		Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessageByIDWaitForTimeout(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?waitfor=confirmed", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("WaitForMessageState", mock.Anything, "mynamespace", "abcd12345", "confirmed", "").
+		Return(i18n.NewError(context.Background(), i18n.MsgWaitForTimeout, "5s", "abcd12345", "confirmed"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 408, res.Result().StatusCode)
+}
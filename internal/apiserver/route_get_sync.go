@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var getSync = &oapispec.Route{
+	Name:   "getSync",
+	Path:   "namespaces/{ns}/sync",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+	},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "since", Description: i18n.MsgSyncSinceParam},
+		{Name: "limit", Description: i18n.MsgSyncLimitParam},
+	},
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return &fftypes.SyncChanges{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		var since int64
+		if r.QP["since"] != "" {
+			since, err = strconv.ParseInt(r.QP["since"], 10, 64)
+			if err != nil || since < 0 {
+				return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidSyncSince, r.QP["since"])
+			}
+		}
+		maxLimit := uint64(config.GetUint(config.APIMaxFilterLimit))
+		limit := uint64(config.GetUint(config.APIDefaultFilterLimit))
+		if r.QP["limit"] != "" {
+			limit, err = strconv.ParseUint(r.QP["limit"], 10, 64)
+			if err != nil || limit == 0 || limit > maxLimit {
+				return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidSyncLimit, r.QP["limit"], maxLimit)
+			}
+		}
+		return getOr(r.Ctx).GetChanges(r.Ctx, r.PP["ns"], since, limit)
+	},
+}
@@ -0,0 +1,104 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestApproverAPIServer() (*orchestratormocks.Orchestrator, *mux.Router) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{
+			"name": "mynamespace",
+			"access": fftypes.JSONObjectArray{
+				{"name": "approver1", "token": "token1", "roles": []interface{}{"read", "write"}},
+				{"name": "approver2", "token": "token2", "roles": []interface{}{"read", "write"}},
+			},
+		},
+	})
+	mor, as := newTestServer()
+	config.Reset()
+	return mor, as.createMuxRouter(context.Background(), mor)
+}
+
+func TestPostApprovalRequestApprove(t *testing.T) {
+	o, r := newTestApproverAPIServer()
+	mar := &approvalmocks.Manager{}
+	o.On("Approvals").Return(mar)
+	input := fftypes.ApprovalDecisionInput{
+		Comment: "ok",
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/mynamespace/approvalrequests/abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b/approve", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+
+	mar.On("Approve", mock.Anything, "mynamespace", "abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b", mock.MatchedBy(func(input *fftypes.ApprovalDecisionInput) bool {
+		return input.Approver == "approver1"
+	})).Return(&fftypes.ApprovalRequest{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestPostApprovalRequestApproveOtherApprover(t *testing.T) {
+	_, r := newTestApproverAPIServer()
+	input := fftypes.ApprovalDecisionInput{
+		Approver: "approver2",
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/mynamespace/approvalrequests/abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b/approve", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	assert.Regexp(t, "FF10563", res.Body.String())
+}
+
+func TestPostApprovalRequestApproveNoAccessList(t *testing.T) {
+	_, r := newTestAPIServer()
+	input := fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/mynamespace/approvalrequests/abf4edd8-1a4c-4e0b-8a1c-2d4c4e4f1a2b/approve", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+	assert.Regexp(t, "FF10562", res.Body.String())
+}
@@ -37,6 +37,7 @@ var postData = &oapispec.Route{
 	},
 	QueryParams: nil,
 	FormParams: []*oapispec.FormParam{
+		{Name: "id", Description: i18n.MsgTBD},
 		{Name: "autometa", Description: i18n.MsgTBD},
 		{Name: "metadata", Description: i18n.MsgTBD},
 		{Name: "validator", Description: i18n.MsgTBD},
@@ -55,6 +56,12 @@ var postData = &oapispec.Route{
 	},
 	FormUploadHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		data := &fftypes.DataRefOrValue{}
+		if r.FP["id"] != "" {
+			// A message sent with deferredData can refer to the ID before the data is uploaded
+			if data.ID, err = fftypes.ParseUUID(r.Ctx, r.FP["id"]); err != nil {
+				return nil, err
+			}
+		}
 		validator := r.FP["validator"]
 		if len(validator) > 0 {
 			data.Validator = fftypes.ValidatorType(validator)
@@ -0,0 +1,73 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/messageimportmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostMessagesImportJSON(t *testing.T) {
+	o, r := newTestAPIServer()
+	mmi := &messageimportmocks.Manager{}
+	o.On("MessageImport").Return(mmi)
+	input := []*fftypes.MessageInOut{{}, {}}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/import", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mmi.On("ImportMessages", mock.Anything, "ns1", mock.MatchedBy(func(msgs []*fftypes.MessageInOut) bool {
+		return len(msgs) == 2
+	})).Return(&fftypes.Operation{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+}
+
+func TestPostMessagesImportUpload(t *testing.T) {
+	o, r := newTestAPIServer()
+	mmi := &messageimportmocks.Manager{}
+	o.On("MessageImport").Return(mmi)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	w.WriteField("format", "csv")
+	writer, err := w.CreateFormFile("file", "messages.txt")
+	assert.NoError(t, err)
+	writer.Write([]byte("tag,value\nt1,hello\n"))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/import", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	mmi.On("ImportMessagesUpload", mock.Anything, "ns1", fftypes.MessageImportFormatCSV, mock.MatchedBy(func(upload *fftypes.Multipart) bool {
+		return upload.Filename == "messages.txt"
+	})).Return(&fftypes.Operation{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+}
@@ -38,13 +38,13 @@ var postNewContractAPI = &oapispec.Route{
 	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
-	JSONInputValue:  func() interface{} { return &fftypes.ContractAPI{} },
+	JSONInputValue:  func() interface{} { return &fftypes.ContractAPIWithListeners{} },
 	JSONInputMask:   []string{"ID", "Message", "Namespace", "URLs"},
-	JSONOutputValue: func() interface{} { return &fftypes.ContractAPI{} },
+	JSONOutputValue: func() interface{} { return &fftypes.ContractAPIWithListeners{} },
 	JSONOutputCodes: []int{http.StatusOK, http.StatusAccepted},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		waitConfirm := strings.EqualFold(r.QP["confirm"], "true")
 		r.SuccessStatus = syncRetcode(waitConfirm)
-		return getOr(r.Ctx).Contracts().BroadcastContractAPI(r.Ctx, r.APIBaseURL, r.PP["ns"], r.Input.(*fftypes.ContractAPI), waitConfirm)
+		return getOr(r.Ctx).BroadcastContractAPI(r.Ctx, r.APIBaseURL, r.PP["ns"], r.Input.(*fftypes.ContractAPIWithListeners), waitConfirm)
 	},
 }
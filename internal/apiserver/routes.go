@@ -22,28 +22,41 @@ const emptyObjectSchema = `{"type": "object"}`
 
 var routes = []*oapispec.Route{
 	deleteContractListener,
+	deleteGroupAlias,
+	deleteRoutingRule,
 	deleteSubscription,
+	getApprovalRequestByID,
+	getApprovalRequests,
+	getAsyncRequestByID,
+	getBatchesAssembly,
 	getBatchByID,
 	getBatches,
 	getBlockchainEventByID,
 	getBlockchainEvents,
+	getBusinessTransaction,
 	getChartHistogram,
+	getCheckpoint,
 	getContractAPIByName,
 	getContractAPIs,
 	getContractInterface,
 	getContractInterfaceNameVersion,
 	getContractInterfaces,
 	getContractListenerByNameOrID,
+	getContractListenerCheckpoint,
 	getContractListeners,
 	getData,
 	getDataBlob,
 	getDataByID,
 	getDataMsgs,
+	getDataValue,
 	getDatatypeByName,
 	getDatatypes,
 	getDIDDocByDID,
 	getEventByID,
 	getEvents,
+	getFees,
+	getGroupAliasByName,
+	getGroupAliases,
 	getGroupByHash,
 	getGroups,
 	getIdentities,
@@ -54,6 +67,9 @@ var routes = []*oapispec.Route{
 	getMsgByID,
 	getMsgData,
 	getMsgEvents,
+	getMsgProof,
+	getMsgRecipients,
+	getMsgStatus,
 	getMsgs,
 	getMsgTxn,
 	getNamespace,
@@ -65,11 +81,21 @@ var routes = []*oapispec.Route{
 	getNetworkOrgs,
 	getOpByID,
 	getOps,
+	getRoutingRuleByNameOrID,
+	getRoutingRules,
+	getSearch,
 	getStatus,
 	getStatusBatchManager,
+	getStatusCircuits,
+	getStatusLimits,
+	getStatusLive,
 	getStatusPins,
+	getStatusReady,
 	getSubscriptionByID,
+	getSubscriptionRedeliveries,
+	getSubscriptionStatus,
 	getSubscriptions,
+	getSync,
 	getTokenAccountPools,
 	getTokenAccounts,
 	getTokenApprovals,
@@ -86,19 +112,30 @@ var routes = []*oapispec.Route{
 	getTxnStatus,
 	getVerifierByID,
 	getVerifiers,
+	patchContractListener,
 	patchUpdateIdentity,
+	postApprovalRequestApprove,
+	postApprovalRequestReject,
+	postBatchesFlush,
+	postCheckpointDiff,
 	postContractAPIInvoke,
 	postContractAPIQuery,
+	postContractDeploy,
 	postContractInterfaceGenerate,
 	postContractInterfaceInvoke,
 	postContractInterfaceQuery,
 	postContractInvoke,
 	postContractQuery,
 	postData,
+	postDataExport,
+	postMessagesImport,
+	postMsgActivate,
 	postNewContractAPI,
 	postNewContractInterface,
 	postNewContractListener,
+	postNewCustomDefinition,
 	postNewDatatype,
+	postNewGroupAlias,
 	postNewIdentity,
 	postNewMessageBroadcast,
 	postNewMessagePrivate,
@@ -106,6 +143,7 @@ var routes = []*oapispec.Route{
 	postNewNamespace,
 	postNewOrganization,
 	postNewOrganizationSelf,
+	postNewRoutingRule,
 	postNewSubscription,
 	postNodesSelf,
 	postOpRetry,
@@ -114,6 +152,9 @@ var routes = []*oapispec.Route{
 	postTokenMint,
 	postTokenPool,
 	postTokenTransfer,
+	postTokenTransferBulk,
 	putContractAPI,
+	putGroupAlias,
+	putRoutingRule,
 	putSubscription,
 }
@@ -52,6 +52,8 @@ type orchestratorContextKey struct{}
 
 var ffcodeExtractor = regexp.MustCompile(`^(FF\d+):`)
 
+const correlationIDMaxLength = 64
+
 var (
 	adminConfigPrefix   = config.NewPluginConfig("admin")
 	apiConfigPrefix     = config.NewPluginConfig("http")
@@ -72,6 +74,9 @@ type apiServer struct {
 	apiMaxTimeout      time.Duration
 	metricsEnabled     bool
 	ffiSwaggerGen      oapiffi.FFISwaggerGen
+	asyncRequests      *asyncRequests
+	authz              *authorizer
+	masking            *masker
 }
 
 func InitConfig() {
@@ -82,6 +87,7 @@ func InitConfig() {
 }
 
 func NewAPIServer() Server {
+	az := newAuthorizer()
 	return &apiServer{
 		defaultFilterLimit: uint64(config.GetUint(config.APIDefaultFilterLimit)),
 		maxFilterLimit:     uint64(config.GetUint(config.APIMaxFilterLimit)),
@@ -89,7 +95,10 @@ func NewAPIServer() Server {
 		apiTimeout:         config.GetDuration(config.APIRequestTimeout),
 		apiMaxTimeout:      config.GetDuration(config.APIRequestMaxTimeout),
 		metricsEnabled:     config.GetBool(config.MetricsEnabled),
+		asyncRequests:      newAsyncRequests(),
 		ffiSwaggerGen:      oapiffi.NewFFISwaggerGen(),
+		authz:              az,
+		masking:            newMasker(az),
 	}
 }
 
@@ -239,15 +248,23 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 		var filter database.AndFilter
 		var status = 400 // if fail parsing input
 		var output interface{}
+		var masking *maskingRules
 		if err == nil {
 			queryParams, pathParams = as.getParams(req, route)
+			masking = as.masking.rulesFor(req, pathParams["ns"])
 			if route.FilterFactory != nil {
+				// Matching or sorting on masked values would reveal them, so neither is allowed while masked
+				if masking != nil && len(masking.data) > 0 && as.queriesValue(req.URL.Query()) {
+					return 403, i18n.NewError(req.Context(), i18n.MsgMaskedValueFilter)
+				}
 				filter, err = as.buildFilter(req, route.FilterFactory)
 			}
 		}
 
 		if err == nil {
 			rCtx := context.WithValue(req.Context(), orchestratorContextKey{}, o)
+			rCtx = context.WithValue(rCtx, asyncRequestsContextKey{}, as.asyncRequests)
+			rCtx = context.WithValue(rCtx, maskingContextKey{}, masking)
 			r := &oapispec.APIRequest{
 				Ctx:             rCtx,
 				Or:              o,
@@ -263,11 +280,20 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 			if len(route.JSONOutputCodes) > 0 {
 				r.SuccessStatus = route.JSONOutputCodes[0]
 			}
-			if multipart != nil {
+			switch {
+			case multipart != nil:
 				r.FP = multipart.formParams
 				r.Part = multipart.part
 				output, err = route.FormUploadHandler(r)
-			} else {
+			case isAsyncRequested(req):
+				// Uploads are not processed in the background, as the upload is streamed from the request
+				var pending *fftypes.AsyncRequest
+				if pending, err = as.asyncRequests.start(r, route); err == nil {
+					res.Header().Set("Location", fmt.Sprintf("%s/requests/%s", apiBaseURL, pending.ID))
+					res.Header().Set("Preference-Applied", "respond-async")
+					output, r.SuccessStatus = pending, http.StatusAccepted
+				}
+			default:
 				output, err = route.JSONHandler(r)
 			}
 			status = r.SuccessStatus // Can be updated by the route
@@ -282,6 +308,9 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 			}
 		}
 		if err == nil {
+			if masking != nil {
+				output = masking.mask(output)
+			}
 			status, err = as.handleOutput(req.Context(), res, status, output)
 		}
 		return status, err
@@ -307,7 +336,9 @@ func (as *apiServer) handleOutput(ctx context.Context, res http.ResponseWriter,
 		res.WriteHeader(204)
 	case reader != nil:
 		defer reader.Close()
-		res.Header().Add("Content-Type", "application/octet-stream")
+		if res.Header().Get("Content-Type") == "" {
+			res.Header().Add("Content-Type", "application/octet-stream")
+		}
 		res.WriteHeader(status)
 		_, marshalErr = io.Copy(res, reader)
 	default:
@@ -346,13 +377,32 @@ func (as *apiServer) getTimeout(req *http.Request) time.Duration {
 	return reqTimeout
 }
 
+// allowReadReplica returns true if the queries of a request can be served from a read replica. A request that
+// waits for an object to reach a state would not see the change until the replica caught up, so it always
+// reads from the primary - as does any request that asks for the primary
+func allowReadReplica(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.URL.Query().Get("waitfor") != "" {
+		return false
+	}
+	return !strings.EqualFold(req.Header.Get(oapispec.ReadPrimaryHeader), "true")
+}
+
 func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 
 		reqTimeout := as.getTimeout(req)
 		ctx, cancel := context.WithTimeout(req.Context(), reqTimeout)
 		httpReqID := fftypes.ShortID()
-		ctx = log.WithLogField(ctx, "httpreq", httpReqID)
+		ctx = log.WithLogField(log.WithComponent(ctx, log.ComponentAPIServer), "httpreq", httpReqID)
+		correlationID, correlationErr := getCorrelationID(ctx, req)
+		if correlationID != "" {
+			ctx = log.WithCorrelationID(ctx, correlationID)
+			res.Header().Set(oapispec.CorrelationIDHeader, correlationID)
+		}
+		if allowReadReplica(req) {
+			// Query-only requests can be served from a read replica, if the database plugin has one
+			ctx = database.WithReadReplica(ctx)
+		}
 		req = req.WithContext(ctx)
 		defer cancel()
 
@@ -360,18 +410,16 @@ func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.
 		l := log.L(ctx)
 		l.Infof("--> %s %s", req.Method, req.URL.Path)
 		startTime := time.Now()
-		status, err := handler(res, req)
+		status, err := http.StatusBadRequest, correlationErr
+		if err == nil {
+			status, err = handler(res, req)
+		}
 		durationMS := float64(time.Since(startTime)) / float64(time.Millisecond)
 		if err != nil {
 
 			// Routers don't need to tweak the status code when sending errors.
 			// .. either the FF12345 error they raise is mapped to a status hint
-			ffcodeExtract := ffcodeExtractor.FindStringSubmatch(err.Error())
-			if len(ffcodeExtract) >= 2 {
-				if statusHint, ok := i18n.GetStatusHint(ffcodeExtract[1]); ok {
-					status = statusHint
-				}
-			}
+			status = errorStatusHint(err, status)
 
 			// If the context is done, we wrap in 408
 			if status != http.StatusRequestTimeout {
@@ -400,6 +448,32 @@ func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.
 	}
 }
 
+// errorStatusHint returns the status hint of the FF error code in an error, or the supplied status if it has none
+func errorStatusHint(err error, status int) int {
+	ffcodeExtract := ffcodeExtractor.FindStringSubmatch(err.Error())
+	if len(ffcodeExtract) >= 2 {
+		if statusHint, ok := i18n.GetStatusHint(ffcodeExtract[1]); ok {
+			return statusHint
+		}
+	}
+	return status
+}
+
+// getCorrelationID returns the correlation ID supplied by the client, which is propagated to everything
+// the request creates so the activity can be tied back to the client's own tracing
+func getCorrelationID(ctx context.Context, req *http.Request) (string, error) {
+	correlationID := req.Header.Get(oapispec.CorrelationIDHeader)
+	if len(correlationID) > correlationIDMaxLength {
+		return "", i18n.NewError(ctx, i18n.MsgInvalidCorrelationID, oapispec.CorrelationIDHeader, correlationIDMaxLength)
+	}
+	for _, c := range correlationID {
+		if c <= ' ' || c > '~' {
+			return "", i18n.NewError(ctx, i18n.MsgInvalidCorrelationID, oapispec.CorrelationIDHeader, correlationIDMaxLength)
+		}
+	}
+	return correlationID, nil
+}
+
 func (as *apiServer) notFoundHandler(res http.ResponseWriter, req *http.Request) (status int, err error) {
 	res.Header().Add("Content-Type", "application/json")
 	return 404, i18n.NewError(req.Context(), i18n.Msg404NotFound)
@@ -489,6 +563,8 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 	if as.metricsEnabled {
 		r.Use(metrics.GetRestServerInstrumentation().Middleware)
 	}
+	r.Use(as.standbyMiddleware(o))
+	r.Use(as.authzMiddleware())
 
 	publicURL := as.getPublicURL(apiConfigPrefix, "")
 	apiBaseURL := fmt.Sprintf("%s/api/v1", publicURL)
@@ -511,7 +587,10 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
 
 	ws, _ := eifactory.GetPlugin(ctx, "websockets")
-	r.HandleFunc(`/ws`, ws.(*websockets.WebSockets).ServeHTTP)
+	r.HandleFunc(`/ws`, func(res http.ResponseWriter, req *http.Request) {
+		ws.(*websockets.WebSockets).ServeHTTPAuthorized(res, req, as.authz.namespaceReader(req))
+	})
+	r.HandleFunc(`/api/v1/namespaces/{ns}/changeevents`, as.changeEventsHandler(ws.(*websockets.WebSockets)))
 
 	uiPath := config.GetString(config.UIPath)
 	if uiPath != "" && config.GetBool(config.UIEnabled) {
@@ -522,6 +601,48 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 	return r
 }
 
+// standbyMiddleware only allows read-only requests while the node is in standby, as the database is a
+// read-only replica. WebSocket connections are also rejected until the node is promoted.
+func (as *apiServer) standbyMiddleware(o orchestrator.Orchestrator) mux.MiddlewareFunc {
+	rejectHandler := as.apiWrapper(func(res http.ResponseWriter, req *http.Request) (status int, err error) {
+		return http.StatusServiceUnavailable, i18n.NewError(req.Context(), i18n.MsgNodeInStandby)
+	})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if o.IsStandby() && (req.Method != http.MethodGet || req.URL.Path == "/ws" || strings.HasSuffix(req.URL.Path, "/changeevents")) {
+				rejectHandler(res, req)
+				return
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+// authzMiddleware enforces the namespace access lists on every route with a namespace in its path
+func (as *apiServer) authzMiddleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			ns := mux.Vars(req)["ns"]
+			principal := as.authz.principalName(req, ns)
+			if err := as.authz.authorizeRoute(req, ns); err != nil {
+				// A caller that is not in the access list has not been authenticated, rather than refused a role
+				status := http.StatusForbidden
+				if principal == "" {
+					status = http.StatusUnauthorized
+				}
+				as.apiWrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+					return status, err
+				})(res, req)
+				return
+			}
+			if principal != "" {
+				req = req.WithContext(withPrincipal(req.Context(), principal))
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
 func (as *apiServer) createAdminMuxRouter(o orchestrator.Orchestrator) *mux.Router {
 	r := mux.NewRouter()
 	if as.metricsEnabled {
@@ -547,7 +668,10 @@ func (as *apiServer) createMetricsMuxRouter() *mux.Router {
 	r := mux.NewRouter()
 
 	r.Path(config.GetString(config.MetricsPath)).Handler(promhttp.InstrumentMetricHandler(metrics.Registry(),
-		promhttp.HandlerFor(metrics.Registry(), promhttp.HandlerOpts{})))
+		promhttp.HandlerFor(metrics.Registry(), promhttp.HandlerOpts{
+			// Exemplars are only served in the OpenMetrics format
+			EnableOpenMetrics: config.GetBool(config.MetricsExemplarsEnabled),
+		})))
 
 	return r
 }
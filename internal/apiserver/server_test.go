@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,11 +32,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/oapispec"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/oapiffimocks"
 	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,10 +49,14 @@ const configDir = "../../test/data/config"
 func newTestServer() (*orchestratormocks.Orchestrator, *apiServer) {
 	InitConfig()
 	mor := &orchestratormocks.Orchestrator{}
+	mor.On("IsStandby").Return(false).Maybe()
 	as := &apiServer{
 		apiTimeout:    5 * time.Second,
 		ffiSwaggerGen: &oapiffimocks.FFISwaggerGen{},
+		asyncRequests: newTestAsyncRequests(time.Hour, 5*time.Second),
+		authz:         newAuthorizer(),
 	}
+	as.masking = newMasker(as.authz)
 	return mor, as
 }
 
@@ -513,3 +520,125 @@ func TestContractAPISwaggerUI(t *testing.T) {
 	b, _ := ioutil.ReadAll(res.Body)
 	assert.Regexp(t, "html", string(b))
 }
+
+func TestStandbyRejectsWrites(t *testing.T) {
+	InitConfig()
+	o := &orchestratormocks.Orchestrator{}
+	o.On("IsStandby").Return(true)
+	az := newAuthorizer()
+	as := &apiServer{apiTimeout: 5 * time.Second, authz: az, masking: newMasker(az)}
+	r := as.createMuxRouter(context.Background(), o)
+
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 503, res.Result().StatusCode)
+	var resJSON map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&resJSON)
+	assert.Regexp(t, "FF10444", resJSON["error"])
+
+	req = httptest.NewRequest("GET", "/ws", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 503, res.Result().StatusCode)
+}
+
+func TestStandbyAllowsReads(t *testing.T) {
+	InitConfig()
+	o := &orchestratormocks.Orchestrator{}
+	o.On("IsStandby").Return(true)
+	o.On("GetReadiness", mock.Anything).Return(&fftypes.NodeReadiness{Status: fftypes.ReadinessStatusStandby})
+	az := newAuthorizer()
+	as := &apiServer{apiTimeout: 5 * time.Second, authz: az, masking: newMasker(az)}
+	r := as.createMuxRouter(context.Background(), o)
+
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 503, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestCorrelationIDHeader(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	req.Header.Set("X-FireFly-Request-ID", "my-correlation-id")
+	res := httptest.NewRecorder()
+
+	o.On("GetReadiness", mock.MatchedBy(func(ctx context.Context) bool {
+		return log.CorrelationID(ctx) == "my-correlation-id"
+	})).Return(&fftypes.NodeReadiness{Status: fftypes.ReadinessStatusReady})
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "my-correlation-id", res.Result().Header.Get("X-FireFly-Request-ID"))
+}
+
+func TestGetRequestAllowsReadReplica(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReadiness", mock.MatchedBy(func(ctx context.Context) bool {
+		return database.ReadReplicaAllowed(ctx)
+	})).Return(&fftypes.NodeReadiness{Status: fftypes.ReadinessStatusReady})
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetRequestReadPrimary(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	req.Header.Set("X-FireFly-Read-Primary", "true")
+	res := httptest.NewRecorder()
+
+	o.On("GetReadiness", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReadReplicaAllowed(ctx)
+	})).Return(&fftypes.NodeReadiness{Status: fftypes.ReadinessStatusReady})
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetRequestWaitForReadsPrimary(t *testing.T) {
+	o, r := newTestAPIServer()
+	msgID := fftypes.NewUUID()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages/"+msgID.String()+"?waitfor=confirmed", nil)
+	res := httptest.NewRecorder()
+
+	o.On("WaitForMessageState", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReadReplicaAllowed(ctx)
+	}), "ns1", msgID.String(), "confirmed", "").Return(nil)
+	o.On("GetMessageByID", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReadReplicaAllowed(ctx)
+	}), "ns1", msgID.String()).Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestCorrelationIDHeaderInvalid(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	req.Header.Set("X-FireFly-Request-ID", "not valid")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Empty(t, res.Result().Header.Get("X-FireFly-Request-ID"))
+	var resJSON map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&resJSON)
+	assert.Regexp(t, "FF10449", resJSON["error"])
+}
+
+func TestCorrelationIDHeaderTooLong(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/status/ready", nil)
+	req.Header.Set("X-FireFly-Request-ID", strings.Repeat("a", 65))
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
@@ -0,0 +1,277 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Handler submits a request once it has received the required approvals. It is called within the database
+// transaction that records the request as submitted, so it must only write to the database - queuing any
+// calls to external systems as operations that run once the transaction commits.
+type Handler func(ctx context.Context, req *fftypes.ApprovalRequest) error
+
+// Manager holds sensitive requests until they have been approved by the configured approvers
+type Manager interface {
+	fftypes.Named
+
+	// Start resumes the submission of any requests that were approved, but not submitted before a restart
+	Start() error
+	// RegisterHandler sets the function that submits approved requests of a type
+	RegisterHandler(rtype fftypes.ApprovalRequestType, handler Handler)
+	// RequiresApproval returns true if a request of the type, for the given amount, must be held for approval
+	RequiresApproval(rtype fftypes.ApprovalRequestType, amount *fftypes.FFBigInt) bool
+	// RequestApproval stores a pending approval request, and emits an event to notify the approvers
+	RequestApproval(ctx context.Context, ns string, rtype fftypes.ApprovalRequestType, reference *fftypes.UUID, request interface{}) (*fftypes.ApprovalRequest, error)
+
+	GetApprovalRequests(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ApprovalRequest, *database.FilterResult, error)
+	GetApprovalRequestByID(ctx context.Context, ns, id string) (*fftypes.ApprovalRequest, error)
+	Approve(ctx context.Context, ns, id string, input *fftypes.ApprovalDecisionInput) (*fftypes.ApprovalRequest, error)
+	Reject(ctx context.Context, ns, id string, input *fftypes.ApprovalDecisionInput) (*fftypes.ApprovalRequest, error)
+}
+
+type approvalManager struct {
+	ctx           context.Context
+	database      database.Plugin
+	approvers     map[string]bool
+	required      int
+	mintThreshold *big.Int
+	handlers      map[fftypes.ApprovalRequestType]Handler
+}
+
+// NewApprovalManager creates the approval manager, checking the configured approvers can satisfy the number of approvals required
+func NewApprovalManager(ctx context.Context, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	am := &approvalManager{
+		ctx:       ctx,
+		database:  di,
+		approvers: make(map[string]bool),
+		required:  config.GetInt(config.ApprovalsRequired),
+		handlers:  make(map[fftypes.ApprovalRequestType]Handler),
+	}
+	for _, approver := range config.GetStringSlice(config.ApprovalsApprovers) {
+		am.approvers[approver] = true
+	}
+	if len(am.approvers) > 0 && (am.required < 1 || am.required > len(am.approvers)) {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidApprovalsConfig, fmt.Sprintf("required=%d approvers=%d", am.required, len(am.approvers)))
+	}
+	if threshold := config.GetString(config.ApprovalsTokenMintThreshold); threshold != "" {
+		var ok bool
+		if am.mintThreshold, ok = new(big.Int).SetString(threshold, 10); !ok {
+			return nil, i18n.NewError(ctx, i18n.MsgInvalidApprovalsConfig, fmt.Sprintf("tokenMint.threshold=%s", threshold))
+		}
+	}
+	return am, nil
+}
+
+func (am *approvalManager) Name() string {
+	return "ApprovalManager"
+}
+
+func (am *approvalManager) Start() error {
+	fb := database.ApprovalRequestQueryFactory.NewFilter(am.ctx)
+	approved, _, err := am.database.GetApprovalRequests(am.ctx, fb.And(fb.Eq("state", fftypes.ApprovalRequestStateApproved)))
+	if err != nil {
+		return err
+	}
+	for _, req := range approved {
+		log.L(am.ctx).Infof("Resuming submission of approved request %s", req.ID)
+		am.submit(req)
+	}
+	return nil
+}
+
+func (am *approvalManager) RegisterHandler(rtype fftypes.ApprovalRequestType, handler Handler) {
+	am.handlers[rtype] = handler
+}
+
+func (am *approvalManager) RequiresApproval(rtype fftypes.ApprovalRequestType, amount *fftypes.FFBigInt) bool {
+	if len(am.approvers) == 0 {
+		return false
+	}
+	switch rtype {
+	case fftypes.ApprovalRequestTypeTokenMint:
+		return am.mintThreshold != nil && amount.Int().Cmp(am.mintThreshold) > 0
+	default:
+		return false
+	}
+}
+
+func (am *approvalManager) RequestApproval(ctx context.Context, ns string, rtype fftypes.ApprovalRequestType, reference *fftypes.UUID, request interface{}) (*fftypes.ApprovalRequest, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req := &fftypes.ApprovalRequest{
+		ID:        fftypes.NewUUID(),
+		Namespace: ns,
+		Type:      rtype,
+		State:     fftypes.ApprovalRequestStatePending,
+		Reference: reference,
+		Request:   fftypes.JSONAnyPtrBytes(b),
+		Required:  am.required,
+		Decisions: fftypes.ApprovalDecisions{},
+		Created:   fftypes.Now(),
+	}
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := am.database.InsertApprovalRequest(ctx, req); err != nil {
+			return err
+		}
+		event := fftypes.NewEvent(fftypes.EventTypeApprovalRequestPending, ns, req.ID, nil, req.ID.String())
+		return am.database.InsertEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Request %s of type '%s' is held pending %d approvals", req.ID, rtype, req.Required)
+	return req, nil
+}
+
+func (am *approvalManager) GetApprovalRequests(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ApprovalRequest, *database.FilterResult, error) {
+	filter = filter.Condition(filter.Builder().Eq("namespace", ns))
+	return am.database.GetApprovalRequests(ctx, filter)
+}
+
+func (am *approvalManager) GetApprovalRequestByID(ctx context.Context, ns, id string) (*fftypes.ApprovalRequest, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req, err := am.database.GetApprovalRequestByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || req.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return req, nil
+}
+
+func (am *approvalManager) Approve(ctx context.Context, ns, id string, input *fftypes.ApprovalDecisionInput) (*fftypes.ApprovalRequest, error) {
+	return am.decide(ctx, ns, id, input, true)
+}
+
+func (am *approvalManager) Reject(ctx context.Context, ns, id string, input *fftypes.ApprovalDecisionInput) (*fftypes.ApprovalRequest, error) {
+	return am.decide(ctx, ns, id, input, false)
+}
+
+func (am *approvalManager) decide(ctx context.Context, ns, id string, input *fftypes.ApprovalDecisionInput, approved bool) (req *fftypes.ApprovalRequest, err error) {
+	if !am.approvers[input.Approver] {
+		return nil, i18n.NewError(ctx, i18n.MsgNotAnApprover, input.Approver)
+	}
+
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if req, err = am.GetApprovalRequestByID(ctx, ns, id); err != nil {
+			return err
+		}
+		if req.State != fftypes.ApprovalRequestStatePending {
+			return i18n.NewError(ctx, i18n.MsgApprovalRequestNotPending, req.ID, req.State)
+		}
+		if req.DecisionBy(input.Approver) != nil {
+			return i18n.NewError(ctx, i18n.MsgApprovalAlreadyDecided, input.Approver, req.ID)
+		}
+
+		req.Decisions = append(req.Decisions, &fftypes.ApprovalDecision{
+			Approver: input.Approver,
+			Approved: approved,
+			Comment:  input.Comment,
+			Created:  fftypes.Now(),
+		})
+		// A single rejection is final, whereas approval needs the required number of approvers
+		var eventType fftypes.EventType
+		switch {
+		case !approved:
+			req.State = fftypes.ApprovalRequestStateRejected
+			eventType = fftypes.EventTypeApprovalRequestRejected
+		case req.Approvals() >= req.Required:
+			req.State = fftypes.ApprovalRequestStateApproved
+			eventType = fftypes.EventTypeApprovalRequestApproved
+		}
+
+		update := database.ApprovalRequestQueryFactory.NewUpdate(ctx).
+			Set("decisions", req.Decisions).
+			Set("state", req.State)
+		if err := am.database.UpdateApprovalRequest(ctx, req.ID, update); err != nil {
+			return err
+		}
+		if eventType != "" {
+			event := fftypes.NewEvent(eventType, ns, req.ID, nil, req.ID.String())
+			return am.database.InsertEvent(ctx, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.State == fftypes.ApprovalRequestStateApproved {
+		am.submit(req)
+	}
+	return req, nil
+}
+
+// submit passes an approved request to its handler, in the same database transaction that moves it to
+// the submitted state, so a request that is still approved after a restart has not been submitted.
+// This runs on the context of the manager rather than the API request, as the approval has already been committed.
+// A failure is recorded on the request, as the decision that approved it cannot be rolled back.
+func (am *approvalManager) submit(req *fftypes.ApprovalRequest) {
+	ctx := am.ctx
+	err := am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		handler, ok := am.handlers[req.Type]
+		if !ok {
+			return i18n.NewError(ctx, i18n.MsgApprovalRequestNoHandler, req.Type)
+		}
+		if err := handler(ctx, req); err != nil {
+			return err
+		}
+		update := database.ApprovalRequestQueryFactory.NewUpdate(ctx).
+			Set("state", fftypes.ApprovalRequestStateSubmitted)
+		return am.database.UpdateApprovalRequest(ctx, req.ID, update)
+	})
+	if err == nil {
+		req.State = fftypes.ApprovalRequestStateSubmitted
+		return
+	}
+
+	log.L(ctx).Errorf("Failed to submit approved request %s: %s", req.ID, err)
+	req.State = fftypes.ApprovalRequestStateFailed
+	req.Error = err.Error()
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		update := database.ApprovalRequestQueryFactory.NewUpdate(ctx).
+			Set("state", req.State).
+			Set("error", req.Error)
+		if err := am.database.UpdateApprovalRequest(ctx, req.ID, update); err != nil {
+			return err
+		}
+		event := fftypes.NewEvent(fftypes.EventTypeApprovalRequestFailed, req.Namespace, req.ID, nil, req.ID.String())
+		return am.database.InsertEvent(ctx, event)
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to record failure of approval request %s: %s", req.ID, err)
+	}
+}
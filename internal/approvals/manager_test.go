@@ -0,0 +1,416 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestApprovalManager(t *testing.T) (*approvalManager, func()) {
+	config.Reset()
+	config.Set(config.ApprovalsApprovers, []string{"did:firefly:org/org1", "did:firefly:org/org2", "did:firefly:org/org3"})
+	config.Set(config.ApprovalsRequired, 2)
+	config.Set(config.ApprovalsTokenMintThreshold, "1000")
+	mdi := &databasemocks.Plugin{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	am, err := NewApprovalManager(context.Background(), mdi)
+	assert.NoError(t, err)
+	return am.(*approvalManager), func() {
+		mdi.AssertExpectations(t)
+	}
+}
+
+func newPendingRequest(decisions ...*fftypes.ApprovalDecision) *fftypes.ApprovalRequest {
+	return &fftypes.ApprovalRequest{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		State:     fftypes.ApprovalRequestStatePending,
+		Required:  2,
+		Decisions: decisions,
+	}
+}
+
+func TestNewApprovalManagerFail(t *testing.T) {
+	_, err := NewApprovalManager(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewApprovalManagerTooManyRequired(t *testing.T) {
+	config.Reset()
+	config.Set(config.ApprovalsApprovers, []string{"did:firefly:org/org1"})
+	config.Set(config.ApprovalsRequired, 2)
+	_, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10519.*required=2", err)
+}
+
+func TestNewApprovalManagerBadThreshold(t *testing.T) {
+	config.Reset()
+	config.Set(config.ApprovalsTokenMintThreshold, "lots")
+	_, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10519.*lots", err)
+}
+
+func TestName(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+	assert.Equal(t, "ApprovalManager", am.Name())
+}
+
+func TestRequiresApproval(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+	assert.True(t, am.RequiresApproval(fftypes.ApprovalRequestTypeTokenMint, fftypes.NewFFBigInt(1001)))
+	assert.False(t, am.RequiresApproval(fftypes.ApprovalRequestTypeTokenMint, fftypes.NewFFBigInt(1000)))
+	assert.False(t, am.RequiresApproval("other", fftypes.NewFFBigInt(1001)))
+}
+
+func TestRequiresApprovalNotConfigured(t *testing.T) {
+	config.Reset()
+	am, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.NoError(t, err)
+	assert.False(t, am.RequiresApproval(fftypes.ApprovalRequestTypeTokenMint, fftypes.NewFFBigInt(1001)))
+}
+
+func TestRequestApproval(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	ref := fftypes.NewUUID()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("InsertApprovalRequest", mock.Anything, mock.MatchedBy(func(req *fftypes.ApprovalRequest) bool {
+		return req.Reference == ref && req.State == fftypes.ApprovalRequestStatePending && req.Required == 2
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeApprovalRequestPending
+	})).Return(nil)
+
+	req, err := am.RequestApproval(context.Background(), "ns1", fftypes.ApprovalRequestTypeTokenMint, ref, map[string]string{"amount": "1001"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"1001"}`, req.Request.String())
+}
+
+func TestRequestApprovalBadRequest(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	_, err := am.RequestApproval(context.Background(), "ns1", fftypes.ApprovalRequestTypeTokenMint, nil, map[bool]bool{true: false})
+	assert.Error(t, err)
+}
+
+func TestRequestApprovalInsertFail(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("InsertApprovalRequest", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.RequestApproval(context.Background(), "ns1", fftypes.ApprovalRequestTypeTokenMint, nil, "{}")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetApprovalRequests(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequests", mock.Anything, mock.Anything).Return([]*fftypes.ApprovalRequest{}, nil, nil)
+
+	fb := database.ApprovalRequestQueryFactory.NewFilter(context.Background())
+	_, _, err := am.GetApprovalRequests(context.Background(), "ns1", fb.And(fb.Eq("state", "pending")))
+	assert.NoError(t, err)
+}
+
+func TestGetApprovalRequestByIDBadID(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	_, err := am.GetApprovalRequestByID(context.Background(), "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetApprovalRequestByIDFail(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.GetApprovalRequestByID(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetApprovalRequestByIDWrongNamespace(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+
+	_, err := am.GetApprovalRequestByID(context.Background(), "ns2", req.ID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestApproveFirstOfTwo(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil)
+
+	res, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+		Comment:  "looks good",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStatePending, res.State)
+	assert.Equal(t, "looks good", res.Decisions[0].Comment)
+	mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+}
+
+func TestApproveSubmits(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeApprovalRequestApproved
+	})).Return(nil)
+
+	submitted := false
+	am.RegisterHandler(fftypes.ApprovalRequestTypeTokenMint, func(ctx context.Context, r *fftypes.ApprovalRequest) error {
+		submitted = true
+		return nil
+	})
+
+	res, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStateSubmitted, res.State)
+	assert.True(t, submitted)
+}
+
+func TestStartResumesApproved(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest()
+	req.State = fftypes.ApprovalRequestStateApproved
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequests", mock.Anything, mock.Anything).Return([]*fftypes.ApprovalRequest{req}, nil, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil)
+
+	var submitted *fftypes.ApprovalRequest
+	am.RegisterHandler(fftypes.ApprovalRequestTypeTokenMint, func(ctx context.Context, r *fftypes.ApprovalRequest) error {
+		submitted = r
+		return nil
+	})
+
+	err := am.Start()
+	assert.NoError(t, err)
+	assert.Equal(t, req, submitted)
+	assert.Equal(t, fftypes.ApprovalRequestStateSubmitted, req.State)
+}
+
+func TestStartQueryFail(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequests", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := am.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestApproveSubmitUpdateFails(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil).Once()
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+
+	am.RegisterHandler(fftypes.ApprovalRequestTypeTokenMint, func(ctx context.Context, r *fftypes.ApprovalRequest) error {
+		return nil
+	})
+
+	res, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStateFailed, res.State)
+	assert.Equal(t, "pop", res.Error)
+}
+
+func TestApproveSubmitFails(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeApprovalRequestApproved
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeApprovalRequestFailed
+	})).Return(nil)
+
+	am.RegisterHandler(fftypes.ApprovalRequestTypeTokenMint, func(ctx context.Context, r *fftypes.ApprovalRequest) error {
+		return fmt.Errorf("pop")
+	})
+
+	res, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStateFailed, res.State)
+	assert.Equal(t, "pop", res.Error)
+}
+
+func TestApproveNoHandlerRecordFails(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	res, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStateFailed, res.State)
+	assert.Regexp(t, "FF10523", res.Error)
+}
+
+func TestReject(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeApprovalRequestRejected
+	})).Return(nil)
+
+	res, err := am.Reject(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org2",
+		Comment:  "amount too large",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ApprovalRequestStateRejected, res.State)
+}
+
+func TestDecideNotAnApprover(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	_, err := am.Approve(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org4",
+	})
+	assert.Regexp(t, "FF10520", err)
+}
+
+func TestDecideNotFound(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	_, err := am.Approve(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+	})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestDecideNotPending(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest()
+	req.State = fftypes.ApprovalRequestStateRejected
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+
+	_, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+	})
+	assert.Regexp(t, "FF10521", err)
+}
+
+func TestDecideAlreadyDecided(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest(&fftypes.ApprovalDecision{Approver: "did:firefly:org/org1", Approved: true})
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+
+	_, err := am.Approve(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+	})
+	assert.Regexp(t, "FF10522", err)
+}
+
+func TestDecideUpdateFail(t *testing.T) {
+	am, done := newTestApprovalManager(t)
+	defer done()
+
+	req := newPendingRequest()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetApprovalRequestByID", mock.Anything, req.ID).Return(req, nil)
+	mdi.On("UpdateApprovalRequest", mock.Anything, req.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Reject(context.Background(), "ns1", req.ID.String(), &fftypes.ApprovalDecisionInput{
+		Approver: "did:firefly:org/org1",
+	})
+	assert.EqualError(t, err, "pop")
+}
@@ -18,7 +18,9 @@ package assets
 
 import (
 	"context"
+	"sort"
 
+	"github.com/hyperledger/firefly/internal/approvals"
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -55,6 +57,7 @@ type Manager interface {
 	MintTokens(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput, waitConfirm bool) (*fftypes.TokenTransfer, error)
 	BurnTokens(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput, waitConfirm bool) (*fftypes.TokenTransfer, error)
 	TransferTokens(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput, waitConfirm bool) (*fftypes.TokenTransfer, error)
+	BulkTransferTokens(ctx context.Context, ns string, bulk *fftypes.TokenTransferBulkInput) (*fftypes.TokenTransferBulk, error)
 
 	GetTokenConnectors(ctx context.Context, ns string) ([]*fftypes.TokenConnector, error)
 
@@ -79,11 +82,13 @@ type assetManager struct {
 	tokens           map[string]tokens.Plugin
 	metrics          metrics.Manager
 	operations       operations.Manager
+	approvals        approvals.Manager
 	keyNormalization int
+	bulkTransferMax  int
 }
 
-func NewAssetManager(ctx context.Context, di database.Plugin, im identity.Manager, dm data.Manager, sa syncasync.Bridge, bm broadcast.Manager, pm privatemessaging.Manager, ti map[string]tokens.Plugin, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper) (Manager, error) {
-	if di == nil || im == nil || sa == nil || bm == nil || pm == nil || ti == nil || mm == nil || om == nil {
+func NewAssetManager(ctx context.Context, di database.Plugin, im identity.Manager, dm data.Manager, sa syncasync.Bridge, bm broadcast.Manager, pm privatemessaging.Manager, ti map[string]tokens.Plugin, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper, ap approvals.Manager) (Manager, error) {
+	if di == nil || im == nil || sa == nil || bm == nil || pm == nil || ti == nil || mm == nil || om == nil || ap == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	am := &assetManager{
@@ -97,15 +102,19 @@ func NewAssetManager(ctx context.Context, di database.Plugin, im identity.Manage
 		messaging:        pm,
 		tokens:           ti,
 		keyNormalization: identity.ParseKeyNormalizationConfig(config.GetString(config.AssetManagerKeyNormalization)),
+		bulkTransferMax:  config.GetInt(config.AssetManagerBulkTransferMax),
 		metrics:          mm,
 		operations:       om,
+		approvals:        ap,
 	}
 	om.RegisterHandler(ctx, am, []fftypes.OpType{
 		fftypes.OpTypeTokenCreatePool,
+		fftypes.OpTypeTokenDeployPool,
 		fftypes.OpTypeTokenActivatePool,
 		fftypes.OpTypeTokenTransfer,
 		fftypes.OpTypeTokenApproval,
 	})
+	ap.RegisterHandler(fftypes.ApprovalRequestTypeTokenMint, am.submitApprovedMint)
 	return am, nil
 }
 
@@ -144,27 +153,49 @@ func (am *assetManager) GetTokenConnectors(ctx context.Context, ns string) ([]*f
 	}
 
 	connectors := []*fftypes.TokenConnector{}
-	for token := range am.tokens {
-		connectors = append(
-			connectors,
-			&fftypes.TokenConnector{
-				Name: token,
-			},
-		)
+	for _, name := range am.tokenConnectorNames() {
+		connectors = append(connectors, am.describeTokenConnector(ctx, name))
 	}
 
 	return connectors, nil
 }
 
-func (am *assetManager) getTokenConnectorName(ctx context.Context, ns string) (string, error) {
-	tokenConnectors, err := am.GetTokenConnectors(ctx, ns)
+func (am *assetManager) tokenConnectorNames() []string {
+	names := make([]string, 0, len(am.tokens))
+	for name := range am.tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (am *assetManager) describeTokenConnector(ctx context.Context, name string) *fftypes.TokenConnector {
+	connector := &fftypes.TokenConnector{Name: name}
+	plugin := am.tokens[name]
+	if err := plugin.Health(ctx); err != nil {
+		connector.Error = err.Error()
+		return connector
+	}
+	capabilities, standards, err := plugin.ConnectorCapabilities(ctx)
 	if err != nil {
+		connector.Error = err.Error()
+		return connector
+	}
+	connector.Reachable = true
+	connector.Capabilities = capabilities
+	connector.Standards = standards
+	return connector
+}
+
+func (am *assetManager) getTokenConnectorName(ctx context.Context, ns string) (string, error) {
+	if err := fftypes.ValidateFFNameField(ctx, ns, "namespace"); err != nil {
 		return "", err
 	}
-	if len(tokenConnectors) != 1 {
+	names := am.tokenConnectorNames()
+	if len(names) != 1 {
 		return "", i18n.NewError(ctx, i18n.MsgFieldNotSpecified, "connector")
 	}
-	return tokenConnectors[0].Name, nil
+	return names[0], nil
 }
 
 func (am *assetManager) getTokenPoolName(ctx context.Context, ns string) (string, error) {
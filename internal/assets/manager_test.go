@@ -16,10 +16,12 @@ package assets
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -56,13 +58,16 @@ func newTestAssetsCommon(t *testing.T, metrics bool) (*assetManager, func()) {
 	mti := &tokenmocks.Plugin{}
 	mm := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
+	mam := &approvalmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mm.On("IsMetricsEnabled").Return(metrics)
 	mm.On("TransferSubmitted", mock.Anything)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
 	mti.On("Name").Return("ut").Maybe()
+	mam.On("RegisterHandler", fftypes.ApprovalRequestTypeTokenMint, mock.Anything)
+	mam.On("RequiresApproval", fftypes.ApprovalRequestTypeTokenMint, mock.Anything).Return(false).Maybe()
 	ctx, cancel := context.WithCancel(context.Background())
-	a, err := NewAssetManager(ctx, mdi, mim, mdm, msa, mbm, mpm, map[string]tokens.Plugin{"magic-tokens": mti}, mm, mom, txHelper)
+	a, err := NewAssetManager(ctx, mdi, mim, mdm, msa, mbm, mpm, map[string]tokens.Plugin{"magic-tokens": mti}, mm, mom, txHelper, mam)
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
 	rag.RunFn = func(a mock.Arguments) {
 		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
@@ -74,7 +79,7 @@ func newTestAssetsCommon(t *testing.T, metrics bool) (*assetManager, func()) {
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewAssetManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewAssetManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -124,8 +129,55 @@ func TestGetTokenConnectors(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
 
-	_, err := am.GetTokenConnectors(context.Background(), "ns1")
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	caps := &fftypes.TokenConnectorCapabilities{Approvals: true}
+	mti.On("Health", context.Background()).Return(nil)
+	mti.On("ConnectorCapabilities", context.Background()).Return(caps, []string{"ERC20"}, nil)
+
+	connectors, err := am.GetTokenConnectors(context.Background(), "ns1")
 	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.TokenConnector{{
+		Name:         "magic-tokens",
+		Reachable:    true,
+		Capabilities: caps,
+		Standards:    []string{"ERC20"},
+	}}, connectors)
+
+	mti.AssertExpectations(t)
+}
+
+func TestGetTokenConnectorsUnreachable(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mti.On("Health", context.Background()).Return(fmt.Errorf("pop"))
+
+	connectors, err := am.GetTokenConnectors(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Len(t, connectors, 1)
+	assert.False(t, connectors[0].Reachable)
+	assert.Equal(t, "pop", connectors[0].Error)
+
+	mti.AssertExpectations(t)
+}
+
+func TestGetTokenConnectorsCapabilitiesFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mti.On("Health", context.Background()).Return(nil)
+	mti.On("ConnectorCapabilities", context.Background()).Return(nil, nil, fmt.Errorf("pop"))
+
+	connectors, err := am.GetTokenConnectors(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Len(t, connectors, 1)
+	assert.False(t, connectors[0].Reachable)
+	assert.Equal(t, "pop", connectors[0].Error)
+	assert.Nil(t, connectors[0].Capabilities)
+
+	mti.AssertExpectations(t)
 }
 
 func TestGetTokenConnectorsBadNamespace(t *testing.T) {
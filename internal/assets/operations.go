@@ -29,6 +29,10 @@ type createPoolData struct {
 	Pool *fftypes.TokenPool `json:"pool"`
 }
 
+type deployPoolData struct {
+	Pool *fftypes.TokenPool `json:"pool"`
+}
+
 type activatePoolData struct {
 	Pool           *fftypes.TokenPool `json:"pool"`
 	BlockchainInfo fftypes.JSONObject `json:"blockchainInfo"`
@@ -53,6 +57,13 @@ func (am *assetManager) PrepareOperation(ctx context.Context, op *fftypes.Operat
 		}
 		return opCreatePool(op, pool), nil
 
+	case fftypes.OpTypeTokenDeployPool:
+		pool, err := txcommon.RetrieveTokenPoolCreateInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		return opDeployPool(op, pool), nil
+
 	case fftypes.OpTypeTokenActivatePool:
 		poolID, blockchainInfo, err := txcommon.RetrieveTokenPoolActivateInputs(ctx, op)
 		if err != nil {
@@ -107,6 +118,14 @@ func (am *assetManager) RunOperation(ctx context.Context, op *fftypes.PreparedOp
 		complete, err = plugin.CreateTokenPool(ctx, op.ID, data.Pool)
 		return nil, complete, err
 
+	case deployPoolData:
+		plugin, err := am.selectTokenPlugin(ctx, data.Pool.Connector)
+		if err != nil {
+			return nil, false, err
+		}
+		complete, err = plugin.DeployTokenPool(ctx, op.ID, data.Pool)
+		return nil, complete, err
+
 	case activatePoolData:
 		plugin, err := am.selectTokenPlugin(ctx, data.Pool.Connector)
 		if err != nil {
@@ -145,32 +164,51 @@ func (am *assetManager) RunOperation(ctx context.Context, op *fftypes.PreparedOp
 
 func opCreatePool(op *fftypes.Operation, pool *fftypes.TokenPool) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: createPoolData{Pool: pool},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        createPoolData{Pool: pool},
+	}
+}
+
+func opDeployPool(op *fftypes.Operation, pool *fftypes.TokenPool) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        deployPoolData{Pool: pool},
 	}
 }
 
 func opActivatePool(op *fftypes.Operation, pool *fftypes.TokenPool, blockchainInfo fftypes.JSONObject) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: activatePoolData{Pool: pool, BlockchainInfo: blockchainInfo},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        activatePoolData{Pool: pool, BlockchainInfo: blockchainInfo},
 	}
 }
 
 func opTransfer(op *fftypes.Operation, pool *fftypes.TokenPool, transfer *fftypes.TokenTransfer) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: transferData{Pool: pool, Transfer: transfer},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        transferData{Pool: pool, Transfer: transfer},
+		CallbackURL: op.CallbackURL,
 	}
 }
 
 func opApproval(op *fftypes.Operation, pool *fftypes.TokenPool, approval *fftypes.TokenApproval) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: approvalData{Pool: pool, Approval: approval},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        approvalData{Pool: pool, Approval: approval},
 	}
 }
@@ -55,6 +55,37 @@ func TestPrepareAndRunCreatePool(t *testing.T) {
 	mti.AssertExpectations(t)
 }
 
+func TestPrepareAndRunDeployPool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeTokenDeployPool,
+	}
+	pool := &fftypes.TokenPool{
+		Connector: "magic-tokens",
+		Deploy: &fftypes.TokenPoolDeploy{
+			Params: fftypes.JSONAnyPtr(`["FFC"]`),
+		},
+	}
+	err := txcommon.AddTokenPoolCreateInputs(op, pool)
+	assert.NoError(t, err)
+
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mti.On("DeployTokenPool", context.Background(), op.ID, pool).Return(false, nil)
+
+	po, err := am.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, pool, po.Data.(deployPoolData).Pool)
+
+	_, complete, err := am.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mti.AssertExpectations(t)
+}
+
 func TestPrepareAndRunActivatePool(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -188,6 +219,19 @@ func TestPrepareOperationCreatePoolBadInput(t *testing.T) {
 	assert.Regexp(t, "FF10151", err)
 }
 
+func TestPrepareOperationDeployPoolBadInput(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeTokenDeployPool,
+		Input: fftypes.JSONObject{"id": "bad"},
+	}
+
+	_, err := am.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10151", err)
+}
+
 func TestPrepareOperationActivatePoolBadInput(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -364,6 +408,19 @@ func TestRunOperationCreatePoolBadPlugin(t *testing.T) {
 	assert.Regexp(t, "FF10272", err)
 }
 
+func TestRunOperationDeployPoolBadPlugin(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	op := &fftypes.Operation{}
+	pool := &fftypes.TokenPool{}
+
+	_, complete, err := am.RunOperation(context.Background(), opDeployPool(op, pool))
+
+	assert.False(t, complete)
+	assert.Regexp(t, "FF10272", err)
+}
+
 func TestRunOperationCreatePool(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
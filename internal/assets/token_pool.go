@@ -18,6 +18,7 @@ package assets
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -32,6 +33,12 @@ func (am *assetManager) CreateTokenPool(ctx context.Context, ns string, pool *ff
 	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, pool.Name, "name"); err != nil {
 		return nil, err
 	}
+	if err := validateTokenPoolBackfill(ctx, pool); err != nil {
+		return nil, err
+	}
+	if err := validateTokenPoolIngestFilter(ctx, pool); err != nil {
+		return nil, err
+	}
 	pool.ID = fftypes.NewUUID()
 	pool.Namespace = ns
 
@@ -51,6 +58,37 @@ func (am *assetManager) CreateTokenPool(ctx context.Context, ns string, pool *ff
 	return am.createTokenPoolInternal(ctx, pool, waitConfirm)
 }
 
+func validateTokenPoolBackfill(ctx context.Context, pool *fftypes.TokenPool) error {
+	if pool.Backfill == nil {
+		return nil
+	}
+	if pool.Deploy != nil {
+		return i18n.NewError(ctx, i18n.MsgInvalidTokenPoolBackfill, "cannot be combined with deploy")
+	}
+	if _, err := strconv.ParseUint(pool.Backfill.FromBlock, 10, 64); err != nil {
+		return i18n.NewError(ctx, i18n.MsgInvalidTokenPoolBackfill, "fromBlock must be a block number")
+	}
+	// Progress is only ever reported by the connector
+	pool.Backfill.State = fftypes.TokenPoolBackfillStatePending
+	pool.Backfill.Block = ""
+	pool.Backfill.Transfers = 0
+	pool.Backfill.Updated = nil
+	return nil
+}
+
+func validateTokenPoolIngestFilter(ctx context.Context, pool *fftypes.TokenPool) error {
+	if pool.IngestFilter == nil {
+		return nil
+	}
+	if !pool.IngestFilter.Members && pool.IngestFilter.MinAmount == nil {
+		return i18n.NewError(ctx, i18n.MsgInvalidTokenPoolIngestFilter, "at least one of members or minAmount must be set")
+	}
+	if pool.IngestFilter.MinAmount != nil && pool.IngestFilter.MinAmount.Int().Sign() < 0 {
+		return i18n.NewError(ctx, i18n.MsgInvalidTokenPoolIngestFilter, "minAmount cannot be negative")
+	}
+	return nil
+}
+
 func (am *assetManager) createTokenPoolInternal(ctx context.Context, pool *fftypes.TokenPool, waitConfirm bool) (*fftypes.TokenPool, error) {
 	plugin, err := am.selectTokenPlugin(ctx, pool.Connector)
 	if err != nil {
@@ -74,11 +112,15 @@ func (am *assetManager) createTokenPoolInternal(ctx context.Context, pool *fftyp
 		pool.TX.ID = txid
 		pool.TX.Type = fftypes.TransactionTypeTokenPool
 
+		opType := fftypes.OpTypeTokenCreatePool
+		if pool.Deploy != nil {
+			opType = fftypes.OpTypeTokenDeployPool
+		}
 		op = fftypes.NewOperation(
 			plugin,
 			pool.Namespace,
 			txid,
-			fftypes.OpTypeTokenCreatePool)
+			opType)
 		if err = txcommon.AddTokenPoolCreateInputs(op, pool); err == nil {
 			err = am.database.InsertOperation(ctx, op)
 		}
@@ -88,6 +130,9 @@ func (am *assetManager) createTokenPoolInternal(ctx context.Context, pool *fftyp
 		return nil, err
 	}
 
+	if pool.Deploy != nil {
+		return pool, am.operations.RunOperation(ctx, opDeployPool(op, pool))
+	}
 	return pool, am.operations.RunOperation(ctx, opCreatePool(op, pool))
 }
 
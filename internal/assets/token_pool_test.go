@@ -344,6 +344,193 @@ func TestCreateTokenPoolAsyncSuccess(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestCreateTokenPoolDeploySuccess(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Connector: "magic-tokens",
+		Name:      "testpool",
+		Deploy: &fftypes.TokenPoolDeploy{
+			Params: fftypes.JSONAnyPtr(`["FFC","FireFly Coin"]`),
+		},
+	}
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdm := am.data.(*datamocks.Manager)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mom := am.operations.(*operationmocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenPool).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeTokenDeployPool && op.Input.GetObject("deploy")["params"] != nil
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(deployPoolData)
+		return op.Type == fftypes.OpTypeTokenDeployPool && data.Pool == pool
+	})).Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestCreateTokenPoolBackfillSuccess(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Connector: "magic-tokens",
+		Name:      "testpool",
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "1000",
+			State:     fftypes.TokenPoolBackfillStateComplete,
+			Block:     "2000",
+			Transfers: 10,
+			Updated:   fftypes.Now(),
+		},
+	}
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdm := am.data.(*datamocks.Manager)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mom := am.operations.(*operationmocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenPool).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeTokenCreatePool && op.Input.GetObject("backfill").GetString("fromBlock") == "1000"
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(createPoolData)
+		return op.Type == fftypes.OpTypeTokenCreatePool && data.Pool == pool
+	})).Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &fftypes.TokenPoolBackfill{
+		FromBlock: "1000",
+		State:     fftypes.TokenPoolBackfillStatePending,
+	}, pool.Backfill)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestCreateTokenPoolBackfillWithDeploy(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Name:     "testpool",
+		Deploy:   &fftypes.TokenPoolDeploy{},
+		Backfill: &fftypes.TokenPoolBackfill{FromBlock: "0"},
+	}
+
+	mdm := am.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.Regexp(t, "FF10501.*deploy", err)
+}
+
+func TestCreateTokenPoolBackfillBadBlock(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Name:     "testpool",
+		Backfill: &fftypes.TokenPoolBackfill{FromBlock: "latest"},
+	}
+
+	mdm := am.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.Regexp(t, "FF10501.*fromBlock", err)
+}
+
+func TestCreateTokenPoolIngestFilterSuccess(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Connector: "magic-tokens",
+		Name:      "testpool",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			MinAmount: fftypes.NewFFBigInt(1000),
+		},
+	}
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mdm := am.data.(*datamocks.Manager)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mom := am.operations.(*operationmocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenPool).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeTokenCreatePool && op.Input.GetObject("ingestFilter").GetString("minAmount") == "1000"
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.Anything).Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestCreateTokenPoolIngestFilterEmpty(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Name:         "testpool",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{},
+	}
+
+	mdm := am.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.Regexp(t, "FF10515.*at least one", err)
+}
+
+func TestCreateTokenPoolIngestFilterNegativeAmount(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Name: "testpool",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			Members:   true,
+			MinAmount: fftypes.NewFFBigInt(-1),
+		},
+	}
+
+	mdm := am.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", context.Background(), "ns1").Return(nil)
+
+	_, err := am.CreateTokenPool(context.Background(), "ns1", pool, false)
+	assert.Regexp(t, "FF10515.*minAmount", err)
+}
+
 func TestCreateTokenPoolConfirm(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -68,6 +69,9 @@ const (
 	methodSend
 	// methodSendAndWait requests that the transfer be sent and waits until it is confirmed by the blockchain
 	methodSendAndWait
+	// methodQueue requests that the transfer be written in the database transaction of the caller, with its
+	// operation queued to be sent once that transaction commits. Attached messages are not supported.
+	methodQueue
 )
 
 func (s *transferSender) Prepare(ctx context.Context) error {
@@ -101,6 +105,12 @@ func (am *assetManager) validateTransfer(ctx context.Context, ns string, transfe
 		}
 		transfer.Pool = pool
 	}
+	if err = operations.ValidateCallbackURL(ctx, transfer.CallbackURL); err != nil {
+		return err
+	}
+	if err = fftypes.ValidateBusinessKey(ctx, transfer.BusinessKey); err != nil {
+		return err
+	}
 	if transfer.Key, err = am.identity.NormalizeSigningKey(ctx, transfer.Key, am.keyNormalization); err != nil {
 		return err
 	}
@@ -118,6 +128,17 @@ func (am *assetManager) MintTokens(ctx context.Context, ns string, transfer *fft
 	if err := am.validateTransfer(ctx, ns, transfer); err != nil {
 		return nil, err
 	}
+	if am.approvals.RequiresApproval(fftypes.ApprovalRequestTypeTokenMint, &transfer.Amount) {
+		// The mint is submitted by submitApprovedMint, once the approvers have approved it
+		if transfer.Message != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgApprovalMintWithMessage)
+		}
+		transfer.LocalID = fftypes.NewUUID()
+		if _, err := am.approvals.RequestApproval(ctx, ns, fftypes.ApprovalRequestTypeTokenMint, transfer.LocalID, transfer); err != nil {
+			return nil, err
+		}
+		return &transfer.TokenTransfer, nil
+	}
 
 	sender := am.NewTransfer(ns, transfer)
 	if am.metrics.IsMetricsEnabled() {
@@ -131,6 +152,28 @@ func (am *assetManager) MintTokens(ctx context.Context, ns string, transfer *fft
 	return &transfer.TokenTransfer, err
 }
 
+func (am *assetManager) submitApprovedMint(ctx context.Context, req *fftypes.ApprovalRequest) error {
+	var transfer fftypes.TokenTransferInput
+	if err := req.Request.Unmarshal(ctx, &transfer); err != nil {
+		return err
+	}
+	if transfer.Message != nil {
+		return i18n.NewError(ctx, i18n.MsgApprovalMintWithMessage)
+	}
+
+	// Keep the local ID returned when the mint was requested, so the application can correlate the transfer
+	sender := &transferSender{
+		mgr:       am,
+		namespace: req.Namespace,
+		transfer:  &transfer,
+	}
+	transfer.LocalID = req.Reference
+	if am.metrics.IsMetricsEnabled() {
+		am.metrics.TransferSubmitted(&transfer.TokenTransfer)
+	}
+	return sender.resolveAndSend(ctx, methodQueue)
+}
+
 func (am *assetManager) BurnTokens(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput, waitConfirm bool) (out *fftypes.TokenTransfer, err error) {
 	transfer.Type = fftypes.TokenTransferTypeBurn
 	if err := am.validateTransfer(ctx, ns, transfer); err != nil {
@@ -193,6 +236,10 @@ func (s *transferSender) resolveAndSend(ctx context.Context, method sendMethod)
 func (s *transferSender) resolve(ctx context.Context) (err error) {
 	// Resolve the attached message
 	if s.transfer.Message != nil {
+		if s.transfer.Message.BusinessKey == "" {
+			// The attached message is part of the same business transaction as the transfer
+			s.transfer.Message.BusinessKey = s.transfer.BusinessKey
+		}
 		s.msgSender, err = s.buildTransferMessage(ctx, s.namespace, s.transfer.Message)
 		if err != nil {
 			return err
@@ -249,16 +296,17 @@ func (s *transferSender) sendInternal(ctx context.Context, method sendMethod) er
 			s.namespace,
 			txid,
 			fftypes.OpTypeTokenTransfer)
+		op.CallbackURL = s.transfer.CallbackURL
+		op.BusinessKey = s.transfer.BusinessKey
 		if err = txcommon.AddTokenTransferInputs(op, &s.transfer.TokenTransfer); err == nil {
 			err = s.mgr.database.InsertOperation(ctx, op)
 		}
-		if err != nil {
-			return err
+		if err == nil && method == methodQueue {
+			err = s.mgr.operations.QueueOperation(ctx, op)
 		}
-
 		return err
 	})
-	if err != nil {
+	if err != nil || method == methodQueue {
 		return err
 	}
 
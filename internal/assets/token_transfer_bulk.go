@@ -0,0 +1,177 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+)
+
+func (am *assetManager) validateBulkTransfer(ctx context.Context, ns string, bulk *fftypes.TokenTransferBulkInput) (connector string, err error) {
+	if len(bulk.Transfers) == 0 {
+		return "", i18n.NewError(ctx, i18n.MsgBulkTransferEmpty)
+	}
+	if len(bulk.Transfers) > am.bulkTransferMax {
+		return "", i18n.NewError(ctx, i18n.MsgBulkTransferTooMany, len(bulk.Transfers), am.bulkTransferMax)
+	}
+	for i, transfer := range bulk.Transfers {
+		if err := am.validateBulkTransferItem(ctx, ns, transfer); err != nil {
+			return "", i18n.NewError(ctx, i18n.MsgBulkTransferInvalid, i, err)
+		}
+		if connector == "" {
+			connector = transfer.Connector
+		} else if transfer.Connector != connector {
+			return "", i18n.NewError(ctx, i18n.MsgBulkTransferMixedConnectors, connector, transfer.Connector)
+		}
+	}
+	return connector, nil
+}
+
+func (am *assetManager) validateBulkTransferItem(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput) error {
+	if transfer.Message != nil {
+		return i18n.NewError(ctx, i18n.MsgBulkTransferMessage)
+	}
+	switch transfer.Type {
+	case "":
+		transfer.Type = fftypes.TokenTransferTypeTransfer
+	case fftypes.TokenTransferTypeMint, fftypes.TokenTransferTypeBurn, fftypes.TokenTransferTypeTransfer:
+	default:
+		return i18n.NewError(ctx, i18n.MsgUnknownFieldValue, "type", transfer.Type)
+	}
+	if err := am.validateTransfer(ctx, ns, transfer); err != nil {
+		return err
+	}
+	if transfer.Type == fftypes.TokenTransferTypeMint && am.approvals.RequiresApproval(fftypes.ApprovalRequestTypeTokenMint, &transfer.Amount) {
+		return i18n.NewError(ctx, i18n.MsgBulkTransferNeedsApproval, transfer.Amount.Int().String())
+	}
+	if transfer.Type == fftypes.TokenTransferTypeTransfer && transfer.From == transfer.To {
+		return i18n.NewError(ctx, i18n.MsgCannotTransferToSelf)
+	}
+	return nil
+}
+
+// BulkTransferTokens validates a group of mints, burns and transfers together, so either all or none of them are
+// accepted, and records them under a single transaction with an operation for each transfer. The transfers are
+// submitted in one request if the connector supports batches, and otherwise one at a time.
+func (am *assetManager) BulkTransferTokens(ctx context.Context, ns string, bulk *fftypes.TokenTransferBulkInput) (*fftypes.TokenTransferBulk, error) {
+	connector, err := am.validateBulkTransfer(ctx, ns, bulk)
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := am.selectTokenPlugin(ctx, connector)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &fftypes.TokenTransferBulk{
+		Transfers: make([]*fftypes.TokenTransfer, len(bulk.Transfers)),
+	}
+	ops := make([]*fftypes.Operation, len(bulk.Transfers))
+	pools := make([]*fftypes.TokenPool, len(bulk.Transfers))
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		poolsByName := make(map[string]*fftypes.TokenPool)
+		for i, transfer := range bulk.Transfers {
+			pool, ok := poolsByName[transfer.Pool]
+			if !ok {
+				if pool, err = am.GetTokenPoolByNameOrID(ctx, ns, transfer.Pool); err != nil {
+					return err
+				}
+				if pool.State != fftypes.TokenPoolStateConfirmed {
+					return i18n.NewError(ctx, i18n.MsgTokenPoolNotConfirmed)
+				}
+				poolsByName[transfer.Pool] = pool
+			}
+			pools[i] = pool
+		}
+
+		txid, err := am.txHelper.SubmitNewTransaction(ctx, ns, fftypes.TransactionTypeTokenTransfer)
+		if err != nil {
+			return err
+		}
+		out.TX = fftypes.TransactionRef{ID: txid, Type: fftypes.TransactionTypeTokenTransfer}
+
+		for i, transfer := range bulk.Transfers {
+			transfer.LocalID = fftypes.NewUUID()
+			transfer.TX = out.TX
+			transfer.TokenTransfer.Pool = pools[i].ID
+			ops[i] = fftypes.NewOperation(plugin, ns, txid, fftypes.OpTypeTokenTransfer)
+			ops[i].CallbackURL = transfer.CallbackURL
+			ops[i].BusinessKey = transfer.BusinessKey
+			if err = txcommon.AddTokenTransferInputs(ops[i], &transfer.TokenTransfer); err == nil {
+				err = am.database.InsertOperation(ctx, ops[i])
+			}
+			if err != nil {
+				return err
+			}
+			out.Transfers[i] = &transfer.TokenTransfer
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if am.metrics.IsMetricsEnabled() {
+		for _, transfer := range out.Transfers {
+			am.metrics.TransferSubmitted(transfer)
+		}
+	}
+
+	if caps, _, err := plugin.ConnectorCapabilities(ctx); err == nil && caps != nil && caps.BatchTransfers {
+		return out, am.submitTransferBatch(ctx, plugin, ops, pools, out.Transfers)
+	}
+
+	// Each transfer has its own operation, so a failure to submit one is recorded against that operation
+	// without preventing the others from being submitted
+	for i, op := range ops {
+		if err := am.operations.RunOperation(ctx, opTransfer(op, pools[i], out.Transfers[i])); err != nil {
+			log.L(ctx).Errorf("Failed to submit transfer %d of bulk transaction %s: %s", i, out.TX.ID, err)
+		}
+	}
+	return out, nil
+}
+
+func (am *assetManager) submitTransferBatch(ctx context.Context, plugin tokens.Plugin, ops []*fftypes.Operation, pools []*fftypes.TokenPool, transfers []*fftypes.TokenTransfer) error {
+	requests := make([]*tokens.TokenTransferRequest, len(ops))
+	for i, op := range ops {
+		requests[i] = &tokens.TokenTransferRequest{
+			OpID:           op.ID,
+			PoolProtocolID: pools[i].ProtocolID,
+			Transfer:       transfers[i],
+		}
+	}
+	err := plugin.TransferTokensBatch(ctx, requests)
+	if err != nil {
+		// None of the transfers were submitted, so every operation has failed
+		for _, op := range ops {
+			if resolveErr := am.txHelper.ResolveOperation(ctx, op.ID, fftypes.OpStatusFailed, err.Error(), nil); resolveErr != nil {
+				log.L(ctx).Errorf("Failed to update operation %s: %s", op.ID, resolveErr)
+			}
+			if op.CallbackURL != "" {
+				if cbErr := am.operations.DeliverCallback(ctx, op); cbErr != nil {
+					log.L(ctx).Errorf("Failed to record callback for operation %s: %s", op.ID, cbErr)
+				}
+			}
+		}
+	}
+	return err
+}
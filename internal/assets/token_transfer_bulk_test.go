@@ -0,0 +1,323 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/tokenmocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestBulkTransfer() *fftypes.TokenTransferBulkInput {
+	return &fftypes.TokenTransferBulkInput{
+		Transfers: []*fftypes.TokenTransferInput{
+			{
+				TokenTransfer: fftypes.TokenTransfer{
+					Type:   fftypes.TokenTransferTypeMint,
+					To:     "A",
+					Amount: *fftypes.NewFFBigInt(10),
+				},
+				Pool: "pool1",
+			},
+			{
+				TokenTransfer: fftypes.TokenTransfer{
+					From:   "A",
+					To:     "B",
+					Amount: *fftypes.NewFFBigInt(5),
+				},
+				Pool: "pool1",
+			},
+		},
+	}
+}
+
+func mockBulkTransferPrepare(am *assetManager, pool *fftypes.TokenPool) (*fftypes.UUID, *databasemocks.Plugin, *txcommonmocks.Helper) {
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	txid := fftypes.NewUUID()
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(pool, nil).Once()
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenTransfer).Return(txid, nil)
+	mdi.On("InsertOperation", context.Background(), mock.Anything).Return(nil)
+	return txid, mdi, mth
+}
+
+func TestBulkTransferTokensIndividually(t *testing.T) {
+	am, cancel := newTestAssetsWithMetrics(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	pool := &fftypes.TokenPool{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.TokenPoolStateConfirmed,
+	}
+	txid, mdi, mth := mockBulkTransferPrepare(am, pool)
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mti.On("ConnectorCapabilities", context.Background()).Return(&fftypes.TokenConnectorCapabilities{}, nil, nil)
+	mom := am.operations.(*operationmocks.Manager)
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(transferData)
+		return data.Pool == pool && data.Transfer == &bulk.Transfers[0].TokenTransfer
+	})).Return(fmt.Errorf("pop"))
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(transferData)
+		return data.Pool == pool && data.Transfer == &bulk.Transfers[1].TokenTransfer
+	})).Return(nil)
+
+	out, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.NoError(t, err)
+	assert.Equal(t, *txid, *out.TX.ID)
+	assert.Len(t, out.Transfers, 2)
+	for _, transfer := range out.Transfers {
+		assert.Equal(t, *txid, *transfer.TX.ID)
+		assert.Equal(t, pool.ID, transfer.Pool)
+		assert.Equal(t, "magic-tokens", transfer.Connector)
+		assert.NotNil(t, transfer.LocalID)
+	}
+	assert.Equal(t, fftypes.TokenTransferTypeTransfer, out.Transfers[1].Type)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestBulkTransferTokensBatch(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	pool := &fftypes.TokenPool{
+		ID:         fftypes.NewUUID(),
+		ProtocolID: "F1",
+		State:      fftypes.TokenPoolStateConfirmed,
+	}
+	_, mdi, mth := mockBulkTransferPrepare(am, pool)
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mti.On("ConnectorCapabilities", context.Background()).Return(&fftypes.TokenConnectorCapabilities{BatchTransfers: true}, nil, nil)
+	mti.On("TransferTokensBatch", context.Background(), mock.MatchedBy(func(requests []*tokens.TokenTransferRequest) bool {
+		return len(requests) == 2 &&
+			requests[0].PoolProtocolID == "F1" && requests[0].Transfer == &bulk.Transfers[0].TokenTransfer &&
+			requests[1].PoolProtocolID == "F1" && requests[1].Transfer == &bulk.Transfers[1].TokenTransfer &&
+			*requests[0].OpID != *requests[1].OpID
+	})).Return(nil)
+
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mti.AssertExpectations(t)
+}
+
+func TestBulkTransferTokensBatchFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"example.com"})
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[0].CallbackURL = "https://example.com/callback"
+	pool := &fftypes.TokenPool{
+		State: fftypes.TokenPoolStateConfirmed,
+	}
+	_, mdi, mth := mockBulkTransferPrepare(am, pool)
+	mti := am.tokens["magic-tokens"].(*tokenmocks.Plugin)
+	mom := am.operations.(*operationmocks.Manager)
+	mti.On("ConnectorCapabilities", context.Background()).Return(&fftypes.TokenConnectorCapabilities{BatchTransfers: true}, nil, nil)
+	mti.On("TransferTokensBatch", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+	mom.On("DeliverCallback", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.CallbackURL == "https://example.com/callback"
+	})).Return(fmt.Errorf("pop3")).Once()
+	mth.On("ResolveOperation", context.Background(), mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(nil).Once()
+	mth.On("ResolveOperation", context.Background(), mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(fmt.Errorf("pop2")).Once()
+
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mti.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestBulkTransferTokensEmpty(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", &fftypes.TokenTransferBulkInput{})
+	assert.Regexp(t, "FF10475", err)
+}
+
+func TestBulkTransferTokensTooMany(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	am.bulkTransferMax = 1
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.Regexp(t, "FF10476.*2.*1", err)
+}
+
+func TestBulkTransferTokensWithMessage(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[1].Message = &fftypes.MessageInOut{}
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.Regexp(t, "FF10477.*1.*FF10478", err)
+}
+
+func TestBulkTransferTokensBadType(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[0].Type = "approve"
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.Regexp(t, "FF10477.*0.*FF10132", err)
+}
+
+func TestBulkTransferTokensBadKey(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.Regexp(t, "FF10477.*0.*pop", err)
+}
+
+func TestBulkTransferTokensToSelf(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[1].To = "A"
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.Regexp(t, "FF10477.*1.*FF10280", err)
+}
+
+func TestBulkTransferTokensMintNeedsApproval(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mam := &approvalmocks.Manager{}
+	am.approvals = mam
+	mam.On("RequiresApproval", fftypes.ApprovalRequestTypeTokenMint, fftypes.NewFFBigInt(10)).Return(true)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.Regexp(t, "FF10477.*0.*FF10524", err)
+
+	mam.AssertExpectations(t)
+}
+
+func TestBulkTransferTokensMixedConnectors(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[0].Connector = "magic-tokens"
+	bulk.Transfers[1].Connector = "other-tokens"
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.Regexp(t, "FF10479", err)
+}
+
+func TestBulkTransferTokensBadConnector(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	bulk := newTestBulkTransfer()
+	bulk.Transfers[0].Connector = "bad"
+	bulk.Transfers[1].Connector = "bad"
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", bulk)
+	assert.Regexp(t, "FF10272", err)
+}
+
+func TestBulkTransferTokensPoolNotFound(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(nil, nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestBulkTransferTokensUnconfirmedPool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(&fftypes.TokenPool{State: fftypes.TokenPoolStatePending}, nil)
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.Regexp(t, "FF10293", err)
+}
+
+func TestBulkTransferTokensTransactionFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(&fftypes.TokenPool{State: fftypes.TokenPoolStateConfirmed}, nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenTransfer).Return(nil, fmt.Errorf("pop"))
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBulkTransferTokensInsertOpFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(&fftypes.TokenPool{State: fftypes.TokenPoolStateConfirmed}, nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenTransfer).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := am.BulkTransferTokens(context.Background(), "ns1", newTestBulkTransfer())
+	assert.EqualError(t, err, "pop")
+}
@@ -17,10 +17,13 @@ package assets
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -108,6 +111,143 @@ func TestMintTokensSuccess(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestMintTokensHeldForApproval(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mint := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			Amount: *fftypes.NewFFBigInt(5000),
+		},
+		Pool: "pool1",
+	}
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mam := &approvalmocks.Manager{}
+	am.approvals = mam
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mam.On("RequiresApproval", fftypes.ApprovalRequestTypeTokenMint, &mint.Amount).Return(true)
+	mam.On("RequestApproval", context.Background(), "ns1", fftypes.ApprovalRequestTypeTokenMint, mock.Anything, mint).Return(&fftypes.ApprovalRequest{}, nil)
+
+	transfer, err := am.MintTokens(context.Background(), "ns1", mint, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, transfer.LocalID)
+	assert.Nil(t, transfer.TX.ID)
+
+	mim.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestMintTokensHeldForApprovalFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mint := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			Amount: *fftypes.NewFFBigInt(5000),
+		},
+		Pool: "pool1",
+	}
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mam := &approvalmocks.Manager{}
+	am.approvals = mam
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mam.On("RequiresApproval", fftypes.ApprovalRequestTypeTokenMint, &mint.Amount).Return(true)
+	mam.On("RequestApproval", context.Background(), "ns1", fftypes.ApprovalRequestTypeTokenMint, mock.Anything, mint).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.MintTokens(context.Background(), "ns1", mint, false)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestSubmitApprovedMint(t *testing.T) {
+	am, cancel := newTestAssetsWithMetrics(t)
+	defer cancel()
+
+	localID := fftypes.NewUUID()
+	pool := &fftypes.TokenPool{
+		State: fftypes.TokenPoolStateConfirmed,
+	}
+	req := &fftypes.ApprovalRequest{
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		Reference: localID,
+		Request:   fftypes.JSONAnyPtr(`{"type":"mint","connector":"magic-tokens","pool":"pool1","key":"0x12345","to":"0x12345","amount":"5000"}`),
+	}
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mom := am.operations.(*operationmocks.Manager)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(pool, nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenTransfer).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.Anything).Return(nil)
+	mom.On("QueueOperation", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeTokenTransfer && op.Input.GetString("localId") == localID.String()
+	})).Return(nil)
+
+	err := am.submitApprovedMint(context.Background(), req)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestSubmitApprovedMintWithMessage(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	req := &fftypes.ApprovalRequest{
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		Request:   fftypes.JSONAnyPtr(`{"type":"mint","connector":"magic-tokens","pool":"pool1","message":{"header":{"type":"transfer_broadcast"}}}`),
+	}
+
+	err := am.submitApprovedMint(context.Background(), req)
+	assert.Regexp(t, "FF10561", err)
+}
+
+func TestMintTokensHeldForApprovalWithMessage(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mint := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			Amount: *fftypes.NewFFBigInt(5000),
+		},
+		Pool:    "pool1",
+		Message: &fftypes.MessageInOut{},
+	}
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mam := &approvalmocks.Manager{}
+	am.approvals = mam
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mam.On("RequiresApproval", fftypes.ApprovalRequestTypeTokenMint, &mint.Amount).Return(true)
+
+	_, err := am.MintTokens(context.Background(), "ns1", mint, false)
+	assert.Regexp(t, "FF10561", err)
+
+	mam.AssertExpectations(t)
+}
+
+func TestSubmitApprovedMintBadRequest(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	req := &fftypes.ApprovalRequest{
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		Request:   fftypes.JSONAnyPtr(`!json`),
+	}
+
+	err := am.submitApprovedMint(context.Background(), req)
+	assert.Regexp(t, "invalid character", err)
+}
+
 func TestMintTokenUnknownConnectorSuccess(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -657,6 +797,84 @@ func TestTransferTokensSuccess(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestTransferTokensWithCallback(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"example.com"})
+
+	transfer := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			From:   "A",
+			To:     "B",
+			Amount: *fftypes.NewFFBigInt(5),
+		},
+		Pool:        "pool1",
+		CallbackURL: "https://example.com/callback",
+	}
+	transfer.BusinessKey = "order-1"
+	pool := &fftypes.TokenPool{
+		State: fftypes.TokenPoolStateConfirmed,
+	}
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mom := am.operations.(*operationmocks.Manager)
+	mim.On("NormalizeSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mdi.On("GetTokenPool", context.Background(), "ns1", "pool1").Return(pool, nil)
+	mth.On("SubmitNewTransaction", context.Background(), "ns1", fftypes.TransactionTypeTokenTransfer).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", context.Background(), mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.CallbackURL == "https://example.com/callback" && op.BusinessKey == "order-1"
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		return op.CallbackURL == "https://example.com/callback"
+	})).Return(nil)
+
+	_, err := am.TransferTokens(context.Background(), "ns1", transfer, false)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestTransferTokensBadCallbackURL(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	transfer := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			From:   "A",
+			To:     "B",
+			Amount: *fftypes.NewFFBigInt(5),
+		},
+		Pool:        "pool1",
+		CallbackURL: "ftp://example.com/callback",
+	}
+
+	_, err := am.TransferTokens(context.Background(), "ns1", transfer, false)
+	assert.Regexp(t, "FF10484", err)
+}
+
+func TestTransferTokensBusinessKeyTooLong(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	transfer := &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			From:        "A",
+			To:          "B",
+			Amount:      *fftypes.NewFFBigInt(5),
+			BusinessKey: strings.Repeat("x", fftypes.BusinessKeyMaxLength+1),
+		},
+		Pool: "pool1",
+	}
+
+	_, err := am.TransferTokens(context.Background(), "ns1", transfer, false)
+	assert.Regexp(t, "FF10188.*businessKey", err)
+}
+
 func TestTransferTokensUnconfirmedPool(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -765,9 +983,10 @@ func TestTransferTokensWithBroadcastMessage(t *testing.T) {
 	hash := fftypes.NewRandB32()
 	transfer := &fftypes.TokenTransferInput{
 		TokenTransfer: fftypes.TokenTransfer{
-			From:   "A",
-			To:     "B",
-			Amount: *fftypes.NewFFBigInt(5),
+			From:        "A",
+			To:          "B",
+			Amount:      *fftypes.NewFFBigInt(5),
+			BusinessKey: "order-1",
 		},
 		Pool: "pool1",
 		Message: &fftypes.MessageInOut{
@@ -810,6 +1029,7 @@ func TestTransferTokensWithBroadcastMessage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, *msgID, *transfer.TokenTransfer.Message)
 	assert.Equal(t, *hash, *transfer.TokenTransfer.MessageHash)
+	assert.Equal(t, "order-1", transfer.Message.BusinessKey)
 
 	mbm.AssertExpectations(t)
 	mim.AssertExpectations(t)
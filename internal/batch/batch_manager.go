@@ -19,6 +19,7 @@ package batch
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -37,7 +38,11 @@ func NewBatchManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, di data
 	if di == nil || dm == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
-	pCtx, cancelCtx := context.WithCancel(log.WithLogField(ctx, "role", "batchmgr"))
+	hashAlgorithm := fftypes.HashAlgorithm(config.GetString(config.BatchHashAlgorithm)).Lower()
+	if _, err := fftypes.NewHash(ctx, hashAlgorithm); err != nil {
+		return nil, err
+	}
+	pCtx, cancelCtx := context.WithCancel(log.WithComponent(log.WithLogField(ctx, "role", "batchmgr"), log.ComponentBatchManager))
 	readPageSize := config.GetUint(config.BatchManagerReadPageSize)
 	bm := &batchManager{
 		ctx:                        pCtx,
@@ -50,11 +55,16 @@ func NewBatchManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, di data
 		readPageSize:               uint64(readPageSize),
 		minimumPollDelay:           config.GetDuration(config.BatchManagerMinimumPollDelay),
 		messagePollTimeout:         config.GetDuration(config.BatchManagerReadPollTimeout),
+		pendingDataPollInterval:    config.GetDuration(config.BatchManagerPendingDataPollInterval),
+		pendingDataTimeout:         config.GetDuration(config.BatchManagerPendingDataTimeout),
+		scheduledPollInterval:      config.GetDuration(config.BatchManagerScheduledPollInterval),
+		hashAlgorithm:              hashAlgorithm,
 		startupOffsetRetryAttempts: config.GetInt(config.OrchestratorStartupAttempts),
 		dispatcherMap:              make(map[string]*dispatcher),
 		allDispatchers:             make([]*dispatcher, 0),
 		newMessages:                make(chan int64, readPageSize),
 		inflightSequences:          make(map[int64]*batchProcessor),
+		flushOnArrival:             make(map[fftypes.UUID]bool),
 		shoulderTap:                make(chan bool, 1),
 		rewindOffset:               -1,
 		done:                       make(chan struct{}),
@@ -74,6 +84,10 @@ type Manager interface {
 	Close()
 	WaitStop()
 	Status() *ManagerStatus
+	Flush(ns string) *FlushResult
+	Assembly(ns string) *AssemblyResult
+	FlushOnArrival(msgID *fftypes.UUID)
+	CancelFlushOnArrival(msgID *fftypes.UUID)
 }
 
 type ManagerStatus struct {
@@ -86,6 +100,17 @@ type ProcessorStatus struct {
 	Status     FlushStatus `json:"status"`
 }
 
+// FlushResult is returned from a flush request, with the number of batch processors that were asked
+// to seal and dispatch their current assembly
+type FlushResult struct {
+	Processors int `json:"processors"`
+}
+
+// AssemblyResult reports the in-flight assembly state of each batch processor in a namespace
+type AssemblyResult struct {
+	Processors []*AssemblyStatus `json:"processors"`
+}
+
 type batchManager struct {
 	ctx                        context.Context
 	cancelCtx                  func()
@@ -105,21 +130,32 @@ type batchManager struct {
 	inflightMux                sync.Mutex
 	inflightSequences          map[int64]*batchProcessor
 	inflightFlushed            []int64
+	flushOnArrivalMux          sync.Mutex
+	flushOnArrival             map[fftypes.UUID]bool
 	shoulderTap                chan bool
 	readPageSize               uint64
 	minimumPollDelay           time.Duration
 	messagePollTimeout         time.Duration
+	pendingDataPollInterval    time.Duration
+	pendingDataTimeout         time.Duration
+	scheduledPollInterval      time.Duration
+	hashAlgorithm              fftypes.HashAlgorithm
 	startupOffsetRetryAttempts int
 }
 
 type DispatchHandler func(context.Context, *DispatchState) error
 
+type ProtocolNegotiator func(context.Context, *DispatchState) (uint, error)
+
 type DispatcherOptions struct {
 	BatchType      fftypes.BatchType
 	BatchMaxSize   uint
 	BatchMaxBytes  int64
 	BatchTimeout   time.Duration
 	DisposeTimeout time.Duration
+	// NegotiateProtocol is called as each batch is sealed, to determine the network protocol version supported by
+	// all the recipients of the batch. If not set, ProtocolVersion1 is used.
+	NegotiateProtocol ProtocolNegotiator
 }
 
 type dispatcher struct {
@@ -129,8 +165,10 @@ type dispatcher struct {
 	options    DispatcherOptions
 }
 
-func (bm *batchManager) getProcessorKey(namespace string, identity *fftypes.SignerRef, groupID *fftypes.Bytes32) string {
-	return fmt.Sprintf("%s|%s|%v", namespace, identity.Author, groupID)
+// getProcessorKey partitions messages so that every message in a batch shares the same
+// transaction type, author and signing key - as the batch is signed once with that key.
+func (bm *batchManager) getProcessorKey(namespace string, txType fftypes.TransactionType, identity *fftypes.SignerRef, groupID *fftypes.Bytes32) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%v", namespace, txType, identity.Author, identity.Key, groupID)
 }
 
 func (bm *batchManager) getDispatcherKey(txType fftypes.TransactionType, msgType fftypes.MessageType) string {
@@ -157,6 +195,8 @@ func (bm *batchManager) Start() error {
 	go bm.messageSequencer()
 	// We must be always ready to process DB events, or we block commits. So we have a dedicated worker for that
 	go bm.newMessageNotifier()
+	go bm.pendingDataPoller()
+	go bm.scheduledMessagePoller()
 	return nil
 }
 
@@ -164,7 +204,7 @@ func (bm *batchManager) NewMessages() chan<- int64 {
 	return bm.newMessages
 }
 
-func (bm *batchManager) getProcessor(txType fftypes.TransactionType, msgType fftypes.MessageType, group *fftypes.Bytes32, namespace string, signer *fftypes.SignerRef) (*batchProcessor, error) {
+func (bm *batchManager) getProcessor(msgID *fftypes.UUID, txType fftypes.TransactionType, msgType fftypes.MessageType, group *fftypes.Bytes32, namespace string, signer *fftypes.SignerRef) (*batchProcessor, error) {
 	bm.dispatcherMux.Lock()
 	defer bm.dispatcherMux.Unlock()
 
@@ -173,7 +213,11 @@ func (bm *batchManager) getProcessor(txType fftypes.TransactionType, msgType fft
 	if !ok {
 		return nil, i18n.NewError(bm.ctx, i18n.MsgUnregisteredBatchType, dispatcherKey)
 	}
-	name := bm.getProcessorKey(namespace, signer, group)
+	if signer.Author == "" || signer.Key == "" {
+		// Signing identity is resolved on submission, so this should never be reached
+		return nil, i18n.NewError(bm.ctx, i18n.MsgBatchSignerMissing, msgID)
+	}
+	name := bm.getProcessorKey(namespace, txType, signer, group)
 	processor, ok := dispatcher.processors[name]
 	if !ok {
 		processor = newBatchProcessor(
@@ -187,6 +231,7 @@ func (bm *batchManager) getProcessor(txType fftypes.TransactionType, msgType fft
 				signer:            *signer,
 				group:             group,
 				dispatch:          dispatcher.handler,
+				hashAlgorithm:     bm.hashAlgorithm,
 			},
 			bm.retry,
 			bm.txHelper,
@@ -313,9 +358,10 @@ func (bm *batchManager) messageSequencer() {
 				// the database store. Meaning we cannot rely on the sequence having been set.
 				msg.Sequence = entry.Sequence
 
-				processor, err := bm.getProcessor(msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.Namespace, &msg.Header.SignerRef)
+				processor, err := bm.getProcessor(msg.Header.ID, msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.Namespace, &msg.Header.SignerRef)
 				if err != nil {
 					l.Errorf("Failed to dispatch message %s: %s", msg.Header.ID, err)
+					bm.popFlushOnArrival(msg.Header.ID)
 					continue
 				}
 
@@ -400,8 +446,10 @@ func (bm *batchManager) dispatchMessage(processor *batchProcessor, msg *fftypes.
 	bm.inflightMux.Unlock()
 
 	work := &batchWork{
-		msg:  msg,
-		data: data,
+		msg:   msg,
+		data:  data,
+		flush: bm.popFlushOnArrival(msg.Header.ID),
+		added: time.Now(),
 	}
 	processor.newWork <- work
 }
@@ -456,6 +504,59 @@ func (bm *batchManager) Status() *ManagerStatus {
 	}
 }
 
+// Flush asks every batch processor for the namespace to seal and dispatch the messages it is
+// currently assembling, rather than waiting for the batch timeout
+func (bm *batchManager) Flush(ns string) *FlushResult {
+	result := &FlushResult{}
+	for _, p := range bm.getProcessors() {
+		if p.conf.namespace == ns {
+			p.requestFlush()
+			result.Processors++
+		}
+	}
+	log.L(bm.ctx).Debugf("Requested flush of %d batch processors in namespace '%s'", result.Processors, ns)
+	return result
+}
+
+// Assembly reports what each batch processor for the namespace is currently assembling, and when it
+// is due to be dispatched
+func (bm *batchManager) Assembly(ns string) *AssemblyResult {
+	now := time.Now()
+	result := &AssemblyResult{
+		Processors: make([]*AssemblyStatus, 0),
+	}
+	for _, p := range bm.getProcessors() {
+		if p.conf.namespace == ns {
+			result.Processors = append(result.Processors, p.assembly(now))
+		}
+	}
+	sort.Slice(result.Processors, func(i, j int) bool {
+		return result.Processors[i].Name < result.Processors[j].Name
+	})
+	return result
+}
+
+// FlushOnArrival requests that the batch containing the message is dispatched as soon as the message
+// is added to it. Must be called before the message is written, so it cannot arrive first.
+func (bm *batchManager) FlushOnArrival(msgID *fftypes.UUID) {
+	bm.flushOnArrivalMux.Lock()
+	defer bm.flushOnArrivalMux.Unlock()
+	bm.flushOnArrival[*msgID] = true
+}
+
+// CancelFlushOnArrival removes a flush request for a message that failed to be written
+func (bm *batchManager) CancelFlushOnArrival(msgID *fftypes.UUID) {
+	bm.popFlushOnArrival(msgID)
+}
+
+func (bm *batchManager) popFlushOnArrival(msgID *fftypes.UUID) bool {
+	bm.flushOnArrivalMux.Lock()
+	defer bm.flushOnArrivalMux.Unlock()
+	flush := bm.flushOnArrival[*msgID]
+	delete(bm.flushOnArrival, *msgID)
+	return flush
+}
+
 func (bm *batchManager) Close() {
 	bm.cancelCtx() // all processor contexts are child contexts
 }
@@ -253,7 +253,7 @@ func TestE2EDispatchPrivateUnpinned(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("( id IN ['%s'] ) && ( state == 'ready' )", msg.Header.ID.String()), fi.String())
 		return true
 	}), mock.Anything).Return(nil)
-	ugcn := mdi.On("UpsertNonceNext", mock.Anything, mock.Anything).Return(nil)
+	ugcn := mdi.On("ReserveNonces", mock.Anything, mock.Anything, int64(1)).Return(nil)
 	nextNonce := int64(12345)
 	ugcn.RunFn = func(a mock.Arguments) {
 		a[1].(*fftypes.Nonce).Nonce = nextNonce
@@ -311,6 +311,14 @@ func TestInitFailNoPersistence(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestInitFailBadHashAlgorithm(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	config.Set(config.BatchHashAlgorithm, "md5")
+	_, err := NewBatchManager(context.Background(), &sysmessagingmocks.LocalNodeInfo{}, &databasemocks.Plugin{}, &datamocks.Manager{}, nil)
+	assert.Regexp(t, "FF10489", err)
+}
+
 func TestGetInvalidBatchTypeMsg(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
@@ -319,10 +327,58 @@ func TestGetInvalidBatchTypeMsg(t *testing.T) {
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	bm, _ := NewBatchManager(context.Background(), mni, mdi, mdm, txHelper)
 	defer bm.Close()
-	_, err := bm.(*batchManager).getProcessor(fftypes.BatchTypeBroadcast, "wrong", nil, "ns1", &fftypes.SignerRef{})
+	_, err := bm.(*batchManager).getProcessor(fftypes.NewUUID(), fftypes.BatchTypeBroadcast, "wrong", nil, "ns1", &fftypes.SignerRef{})
 	assert.Regexp(t, "FF10126", err)
 }
 
+func TestGetProcessorMissingSigner(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mni := &sysmessagingmocks.LocalNodeInfo{}
+	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
+	bm, _ := NewBatchManager(context.Background(), mni, mdi, mdm, txHelper)
+	defer bm.Close()
+	bm.RegisterDispatcher("utdispatcher", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchState) error {
+			return nil
+		}, DispatcherOptions{BatchMaxSize: 1, DisposeTimeout: 0},
+	)
+	_, err := bm.(*batchManager).getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns1", &fftypes.SignerRef{Author: "org1"})
+	assert.Regexp(t, "FF10418", err)
+}
+
+func TestGetProcessorPartitionsBySigner(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mni := &sysmessagingmocks.LocalNodeInfo{}
+	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
+	bm, _ := NewBatchManager(context.Background(), mni, mdi, mdm, txHelper)
+	defer bm.Close()
+	bm.RegisterDispatcher("utdispatcher", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchState) error {
+			return nil
+		}, DispatcherOptions{BatchMaxSize: 1, DisposeTimeout: 0},
+	)
+	bm.RegisterDispatcher("utdispatcher2", fftypes.TransactionTypeUnpinned, []fftypes.MessageType{fftypes.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchState) error {
+			return nil
+		}, DispatcherOptions{BatchMaxSize: 1, DisposeTimeout: 0},
+	)
+	getProcessor := func(txType fftypes.TransactionType, author, key string) *batchProcessor {
+		p, err := bm.(*batchManager).getProcessor(fftypes.NewUUID(), txType, fftypes.MessageTypeBroadcast, nil, "ns1", &fftypes.SignerRef{Author: author, Key: key})
+		assert.NoError(t, err)
+		return p
+	}
+	p1 := getProcessor(fftypes.TransactionTypeBatchPin, "org1", "0x12345")
+	assert.Equal(t, p1, getProcessor(fftypes.TransactionTypeBatchPin, "org1", "0x12345"))
+	assert.NotEqual(t, p1, getProcessor(fftypes.TransactionTypeBatchPin, "org1", "0x23456"))
+	assert.NotEqual(t, p1, getProcessor(fftypes.TransactionTypeBatchPin, "org2", "0x12345"))
+	assert.NotEqual(t, p1, getProcessor(fftypes.TransactionTypeUnpinned, "org1", "0x12345"))
+	assert.Equal(t, "0x12345", p1.conf.signer.Key)
+}
+
 func TestMessageSequencerCancelledContext(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -357,6 +413,7 @@ func TestMessageSequencerMissingMessageData(t *testing.T) {
 			ID:        fftypes.NewUUID(),
 			Type:      fftypes.MessageTypeBroadcast,
 			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"},
 			TxType:    fftypes.TransactionTypeNone,
 		},
 		Data: []*fftypes.DataRef{
@@ -402,6 +459,7 @@ func TestMessageSequencerUpdateMessagesFail(t *testing.T) {
 			TxType:    fftypes.TransactionTypeBatchPin,
 			Type:      fftypes.MessageTypeBroadcast,
 			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"},
 		},
 		Data: []*fftypes.DataRef{
 			{ID: dataID},
@@ -457,6 +515,7 @@ func TestMessageSequencerDispatchFail(t *testing.T) {
 			TxType:    fftypes.TransactionTypeBatchPin,
 			Type:      fftypes.MessageTypeBroadcast,
 			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"},
 		},
 		Data: []*fftypes.DataRef{
 			{ID: dataID},
@@ -497,6 +556,7 @@ func TestMessageSequencerUpdateBatchFail(t *testing.T) {
 			TxType:    fftypes.TransactionTypeBatchPin,
 			Type:      fftypes.MessageTypeBroadcast,
 			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"},
 		},
 		Data: []*fftypes.DataRef{
 			{ID: dataID},
@@ -610,3 +670,69 @@ func TestDoubleTap(t *testing.T) {
 		time.Sleep(1 * time.Microsecond)
 	}
 }
+
+func TestFlush(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.RegisterDispatcher("utdispatcher", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchState) error {
+			return nil
+		}, DispatcherOptions{BatchMaxSize: 1, DisposeTimeout: 1 * time.Hour},
+	)
+	signer := &fftypes.SignerRef{Author: "org1", Key: "0x12345"}
+	p1, err := bm.getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns1", signer)
+	assert.NoError(t, err)
+	_, err = bm.getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns2", signer)
+	assert.NoError(t, err)
+
+	result := bm.Flush("ns1")
+	assert.Equal(t, 1, result.Processors)
+	assert.Len(t, p1.flushRequests, 1)
+
+	assert.Equal(t, 0, bm.Flush("ns3").Processors)
+}
+
+func TestAssembly(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.RegisterDispatcher("utdispatcher", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchState) error {
+			return nil
+		}, DispatcherOptions{BatchMaxSize: 10, BatchTimeout: 1 * time.Second, DisposeTimeout: 1 * time.Hour},
+	)
+	_, err := bm.getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns1", &fftypes.SignerRef{Author: "org2", Key: "0x12345"})
+	assert.NoError(t, err)
+	_, err = bm.getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns1", &fftypes.SignerRef{Author: "org1", Key: "0x12345"})
+	assert.NoError(t, err)
+	_, err = bm.getProcessor(fftypes.NewUUID(), fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns2", &fftypes.SignerRef{Author: "org1", Key: "0x12345"})
+	assert.NoError(t, err)
+
+	result := bm.Assembly("ns1")
+	assert.Len(t, result.Processors, 2)
+	assert.Less(t, result.Processors[0].Name, result.Processors[1].Name)
+	assert.Equal(t, "utdispatcher", result.Processors[0].Dispatcher)
+	assert.Equal(t, 0, result.Processors[0].Messages)
+	assert.Nil(t, result.Processors[0].FlushETA)
+	assert.Equal(t, uint(10), result.Processors[0].BatchMaxSize)
+	assert.Equal(t, int64(1000), result.Processors[0].BatchTimeoutMS)
+
+	assert.Empty(t, bm.Assembly("ns3").Processors)
+}
+
+func TestFlushOnArrival(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msgID1 := fftypes.NewUUID()
+	msgID2 := fftypes.NewUUID()
+	bm.FlushOnArrival(msgID1)
+	bm.FlushOnArrival(msgID2)
+	bm.CancelFlushOnArrival(msgID2)
+
+	p := &batchProcessor{conf: &batchProcessorConf{}, newWork: make(chan *batchWork, 2)}
+	bm.dispatchMessage(p, &fftypes.Message{Header: fftypes.MessageHeader{ID: msgID1}, Sequence: 1}, nil)
+	bm.dispatchMessage(p, &fftypes.Message{Header: fftypes.MessageHeader{ID: msgID2}, Sequence: 2}, nil)
+	assert.True(t, (<-p.newWork).flush)
+	assert.False(t, (<-p.newWork).flush)
+	assert.Empty(t, bm.flushOnArrival)
+}
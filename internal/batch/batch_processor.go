@@ -17,12 +17,14 @@
 package batch
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"database/sql/driver"
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -37,8 +39,10 @@ import (
 )
 
 type batchWork struct {
-	msg  *fftypes.Message
-	data fftypes.DataArray
+	msg   *fftypes.Message
+	data  fftypes.DataArray
+	flush bool
+	added time.Time
 }
 
 type batchProcessorConf struct {
@@ -50,6 +54,7 @@ type batchProcessorConf struct {
 	signer         fftypes.SignerRef
 	group          *fftypes.Bytes32
 	dispatch       DispatchHandler
+	hashAlgorithm  fftypes.HashAlgorithm
 }
 
 // FlushStatus is an object that can be returned on REST queries to understand the status
@@ -73,6 +78,30 @@ type FlushStatus struct {
 	totalFlushDuration   time.Duration
 }
 
+// AssemblyStatus is a snapshot of the batch a processor is currently assembling, along with the
+// configured limits and historical dispatch stats, to help tune the batch size and timeout
+type AssemblyStatus struct {
+	Dispatcher         string          `json:"dispatcher"`
+	Name               string          `json:"name"`
+	Messages           int             `json:"messages"`
+	Bytes              int64           `json:"bytes"`
+	OldestMessageAgeMS int64           `json:"oldestMessageAgeMS"`
+	FlushETA           *fftypes.FFTime `json:"flushETA,omitempty"`
+	BatchMaxSize       uint            `json:"batchMaxSize"`
+	BatchMaxBytes      int64           `json:"batchMaxBytes"`
+	BatchTimeoutMS     int64           `json:"batchTimeoutMS"`
+	Stats              FlushStatus     `json:"stats"`
+}
+
+// assemblyState is maintained by the assembly loop, under the status lock, so it can be reported
+// without touching the assembly queue itself
+type assemblyState struct {
+	messages      int
+	bytes         int64
+	oldest        time.Time
+	flushDeadline time.Time
+}
+
 type batchProcessor struct {
 	ctx                context.Context
 	bm                 *batchManager
@@ -84,20 +113,29 @@ type batchProcessor struct {
 	done               chan struct{}
 	quescing           chan bool
 	newWork            chan *batchWork
+	flushRequests      chan bool
 	assemblyID         *fftypes.UUID
 	assemblyQueue      []*batchWork
 	assemblyQueueBytes int64
 	statusMux          sync.Mutex
 	flushStatus        FlushStatus
+	assemblyState      assemblyState
 	retry              *retry.Retry
 	conf               *batchProcessorConf
 }
 
 type DispatchState struct {
-	Persisted fftypes.BatchPersisted
-	Messages  []*fftypes.Message
-	Data      fftypes.DataArray
-	Pins      []*fftypes.Bytes32
+	Persisted       fftypes.BatchPersisted
+	Messages        []*fftypes.Message
+	Data            fftypes.DataArray
+	Pins            []*fftypes.Bytes32
+	ProtocolVersion uint
+	// SentNodes are the nodes a private batch was sent to by an earlier attempt of the dispatch, which are
+	// not sent to again when the dispatch is retried after a send to another node failed
+	SentNodes map[fftypes.UUID]bool
+	// RefusalAudited is set once the refusal of a private batch by the data residency rules has been recorded,
+	// so it is not recorded again each time the dispatch is retried
+	RefusalAudited bool
 }
 
 const batchSizeEstimateBase = int64(512)
@@ -106,16 +144,17 @@ func newBatchProcessor(bm *batchManager, conf *batchProcessorConf, baseRetryConf
 	pCtx := log.WithLogField(log.WithLogField(bm.ctx, "d", conf.dispatcherName), "p", conf.name)
 	pCtx, cancelCtx := context.WithCancel(pCtx)
 	bp := &batchProcessor{
-		ctx:       pCtx,
-		cancelCtx: cancelCtx,
-		bm:        bm,
-		ni:        bm.ni,
-		database:  bm.database,
-		data:      bm.data,
-		txHelper:  txHelper,
-		newWork:   make(chan *batchWork, conf.BatchMaxSize),
-		quescing:  make(chan bool, 1),
-		done:      make(chan struct{}),
+		ctx:           pCtx,
+		cancelCtx:     cancelCtx,
+		bm:            bm,
+		ni:            bm.ni,
+		database:      bm.database,
+		data:          bm.data,
+		txHelper:      txHelper,
+		newWork:       make(chan *batchWork, conf.BatchMaxSize),
+		quescing:      make(chan bool, 1),
+		flushRequests: make(chan bool, 1),
+		done:          make(chan struct{}),
 		retry: &retry.Retry{
 			InitialDelay: baseRetryConf.InitialDelay,
 			MaximumDelay: baseRetryConf.MaximumDelay,
@@ -152,6 +191,54 @@ func (bp *batchProcessor) status() *ProcessorStatus {
 	}
 }
 
+func (bp *batchProcessor) assembly(now time.Time) *AssemblyStatus {
+	bp.statusMux.Lock()
+	defer bp.statusMux.Unlock()
+	as := &AssemblyStatus{
+		Dispatcher:     bp.conf.dispatcherName,
+		Name:           bp.conf.name,
+		Messages:       bp.assemblyState.messages,
+		Bytes:          bp.assemblyState.bytes,
+		BatchMaxSize:   bp.conf.BatchMaxSize,
+		BatchMaxBytes:  bp.conf.BatchMaxBytes,
+		BatchTimeoutMS: bp.conf.BatchTimeout.Milliseconds(),
+		Stats:          bp.flushStatus, // copy
+	}
+	if as.Messages > 0 {
+		as.OldestMessageAgeMS = now.Sub(bp.assemblyState.oldest).Milliseconds()
+	}
+	if !bp.assemblyState.flushDeadline.IsZero() {
+		eta := fftypes.FFTime(bp.assemblyState.flushDeadline)
+		as.FlushETA = &eta
+	}
+	return as
+}
+
+// updateAssemblyState records the size of the current assembly, and the arrival time of the oldest message in it
+func (bp *batchProcessor) updateAssemblyState() {
+	bp.statusMux.Lock()
+	defer bp.statusMux.Unlock()
+	state := &bp.assemblyState
+	state.messages = len(bp.assemblyQueue)
+	state.bytes = 0
+	state.oldest = time.Time{}
+	if state.messages > 0 {
+		state.bytes = bp.assemblyQueueBytes
+	}
+	for _, work := range bp.assemblyQueue {
+		if state.oldest.IsZero() || work.added.Before(state.oldest) {
+			state.oldest = work.added
+		}
+	}
+}
+
+// setFlushDeadline records when the batch timer will seal the current assembly - zero when idle
+func (bp *batchProcessor) setFlushDeadline(deadline time.Time) {
+	bp.statusMux.Lock()
+	defer bp.statusMux.Unlock()
+	bp.assemblyState.flushDeadline = deadline
+}
+
 func (bp *batchProcessor) newAssembly(initalWork ...*batchWork) {
 	bp.assemblyID = fftypes.NewUUID()
 	bp.assemblyQueue = append([]*batchWork{}, initalWork...)
@@ -261,6 +348,15 @@ func (bp *batchProcessor) startQuiesce() {
 	}
 }
 
+// requestFlush asks the assembly loop to seal and dispatch the current assembly without waiting
+// for the batch timeout. Non-blocking, as one pending request covers any number of callers.
+func (bp *batchProcessor) requestFlush() {
+	select {
+	case bp.flushRequests <- true:
+	default:
+	}
+}
+
 // The assemblyLoop receives new work, sorts it, and waits for the size/timer to pop before
 // flushing the batch. The newWork channel has up to one batch of slots queue length,
 // so that we can have one batch of work queuing for assembly, while we have one batch flushing.
@@ -273,7 +369,7 @@ func (bp *batchProcessor) assemblyLoop() {
 	quescing := false
 	for !quescing {
 
-		var timedout, full, overflow bool
+		var timedout, flushRequested, full, overflow bool
 		select {
 		case <-bp.ctx.Done():
 			l.Tracef("Batch processor shutting down")
@@ -287,20 +383,26 @@ func (bp *batchProcessor) assemblyLoop() {
 				// We need to flush
 				timedout = true
 			}
+		case <-bp.flushRequests:
+			l.Debugf("Batch flush requested")
+			flushRequested = true
 		case work, ok := <-bp.newWork:
 			if !ok {
 				quescing = true
 			} else {
 				full, overflow = bp.addWork(work)
+				bp.updateAssemblyState()
+				flushRequested = work.flush
 				if idle {
 					// We've hit a message while we were idle - we now need to wait for the batch to time out.
 					_ = batchTimeout.Stop()
 					batchTimeout = time.NewTimer(bp.conf.BatchTimeout)
+					bp.setFlushDeadline(time.Now().Add(bp.conf.BatchTimeout))
 					idle = false
 				}
 			}
 		}
-		if (full || timedout || quescing) && len(bp.assemblyQueue) > 0 {
+		if (full || timedout || flushRequested || quescing) && len(bp.assemblyQueue) > 0 {
 			// Let Go GC the old timer
 			_ = batchTimeout.Stop()
 
@@ -308,6 +410,9 @@ func (bp *batchProcessor) assemblyLoop() {
 			// (even though we won't check it until after).
 			if overflow {
 				batchTimeout = time.NewTimer(bp.conf.BatchTimeout)
+				bp.setFlushDeadline(time.Now().Add(bp.conf.BatchTimeout))
+			} else {
+				bp.setFlushDeadline(time.Time{})
 			}
 
 			err := bp.flush(overflow)
@@ -317,6 +422,11 @@ func (bp *batchProcessor) assemblyLoop() {
 				return
 			}
 
+			// The work that overflowed is now at the start of the next assembly, so it needs its own flush
+			if overflow && flushRequested {
+				bp.requestFlush()
+			}
+
 			// If we didn't overflow, then just go back to idle - we don't know if we have more work to come, so
 			// either we'll pop straight away (and move to the batch timeout) or wait for the dispose timeout
 			if !overflow && !quescing {
@@ -329,6 +439,7 @@ func (bp *batchProcessor) assemblyLoop() {
 
 func (bp *batchProcessor) flush(overflow bool) error {
 	id, flushWork, byteSize := bp.startFlush(overflow)
+	bp.updateAssemblyState()
 
 	log.L(bp.ctx).Debugf("Flushing batch %s", id)
 	state := bp.initFlushState(id, flushWork)
@@ -392,54 +503,89 @@ func (bp *batchProcessor) initFlushState(id *fftypes.UUID, flushWork []*batchWor
 	return state
 }
 
-func (bp *batchProcessor) maskContext(ctx context.Context, msg *fftypes.Message, topic string) (msgPinString string, contextOrPin *fftypes.Bytes32, err error) {
-
+func (bp *batchProcessor) contextHash(msg *fftypes.Message, topic string) *fftypes.Bytes32 {
 	hashBuilder := sha256.New()
 	hashBuilder.Write([]byte(topic))
 
 	// For broadcast we do not need to mask the context, which is just the hash
 	// of the topic. There would be no way to unmask it if we did, because we don't have
 	// the full list of senders to know what their next hashes should be.
-	if msg.Header.Group == nil {
-		return "", fftypes.HashResult(hashBuilder), nil
+	if msg.Header.Group != nil {
+		// For private groups, we need to make the topic specific to the group (which is
+		// a salt for the hash as it is not on chain)
+		hashBuilder.Write((*msg.Header.Group)[:])
 	}
 
-	// For private groups, we need to make the topic specific to the group (which is
-	// a salt for the hash as it is not on chain)
-	hashBuilder.Write((*msg.Header.Group)[:])
-
 	// The combination of the topic and group is the context
-	contextHash := fftypes.HashResult(hashBuilder)
-
-	// Get the next nonce for this context - we're the authority in the nextwork on this,
-	// as we are the sender.
-	gc := &fftypes.Nonce{
-		Context: contextHash,
-		Group:   msg.Header.Group,
-		Topic:   topic,
-	}
-	err = bp.database.UpsertNonceNext(ctx, gc)
-	if err != nil {
-		return "", nil, err
-	}
+	return fftypes.HashResult(hashBuilder)
+}
+
+func (bp *batchProcessor) maskContext(ctx context.Context, msg *fftypes.Message, topic string, nonce int64) (msgPinString string, pin *fftypes.Bytes32) {
+	hashBuilder := sha256.New()
+	hashBuilder.Write([]byte(topic))
+	hashBuilder.Write((*msg.Header.Group)[:])
 
 	// Now combine our sending identity, and this nonce, to produce the hash that should
 	// be expected by all members of the group as the next nonce from us on this topic.
 	// Note we use our identity DID (not signing key) for this.
 	hashBuilder.Write([]byte(msg.Header.Author))
 	nonceBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(nonceBytes, uint64(gc.Nonce))
+	binary.BigEndian.PutUint64(nonceBytes, uint64(nonce))
 	hashBuilder.Write(nonceBytes)
 
-	pin := fftypes.HashResult(hashBuilder)
-	pinStr := fmt.Sprintf("%s:%.16d", pin, gc.Nonce)
+	pin = fftypes.HashResult(hashBuilder)
+	pinStr := fmt.Sprintf("%s:%.16d", pin, nonce)
 	log.L(ctx).Debugf("Assigned pin '%s' to message %s for topic '%s'", pinStr, msg.Header.ID, topic)
-	return pinStr, pin, err
+	return pinStr, pin
+}
+
+// reserveNonces reserves every nonce the private messages in the batch need, with a single
+// reservation per context - we're the authority in the network on these, as we are the sender.
+// Contexts are reserved in the same order by every processor, so two processors sealing batches
+// on overlapping contexts queue behind each other rather than deadlocking. The reservations are
+// part of the seal transaction, so a failed seal releases them without leaving a gap.
+func (bp *batchProcessor) reserveNonces(ctx context.Context, messages []*fftypes.Message) (map[fftypes.Bytes32]*fftypes.Nonce, error) {
+	nonces := make(map[fftypes.Bytes32]*fftypes.Nonce)
+	counts := make(map[fftypes.Bytes32]int64)
+	for _, msg := range messages {
+		if msg.Header.Group == nil || len(msg.Pins) > 0 {
+			continue
+		}
+		for _, topic := range msg.Header.Topics {
+			contextHash := bp.contextHash(msg, topic)
+			if _, ok := nonces[*contextHash]; !ok {
+				nonces[*contextHash] = &fftypes.Nonce{
+					Context: contextHash,
+					Group:   msg.Header.Group,
+					Topic:   topic,
+				}
+			}
+			counts[*contextHash]++
+		}
+	}
+
+	ordered := make([]*fftypes.Nonce, 0, len(nonces))
+	for _, nonce := range nonces {
+		ordered = append(ordered, nonce)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return bytes.Compare(ordered[i].Context[:], ordered[j].Context[:]) < 0
+	})
+	for _, nonce := range ordered {
+		if err := bp.database.ReserveNonces(ctx, nonce, counts[*nonce.Context]); err != nil {
+			return nil, err
+		}
+	}
+	return nonces, nil
 }
 
 func (bp *batchProcessor) maskContexts(ctx context.Context, messages []*fftypes.Message) ([]*fftypes.Bytes32, error) {
-	// Calculate the sequence hashes
-	pinsAssigned := false
+	nonces, err := bp.reserveNonces(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate the sequence hashes, handing out the reserved nonces in batch order
 	contextsOrPins := make([]*fftypes.Bytes32, 0, len(messages))
 	for _, msg := range messages {
 		if len(msg.Pins) > 0 {
@@ -448,17 +594,18 @@ func (bp *batchProcessor) maskContexts(ctx context.Context, messages []*fftypes.
 			continue
 		}
 		for _, topic := range msg.Header.Topics {
-			pinString, contextOrPin, err := bp.maskContext(ctx, msg, topic)
-			if err != nil {
-				return nil, err
-			}
-			contextsOrPins = append(contextsOrPins, contextOrPin)
-			if msg.Header.Group != nil {
-				msg.Pins = append(msg.Pins, pinString /* contains the nonce as well as the pin hash */)
-				pinsAssigned = true
+			contextHash := bp.contextHash(msg, topic)
+			if msg.Header.Group == nil {
+				contextsOrPins = append(contextsOrPins, contextHash)
+				continue
 			}
+			nonce := nonces[*contextHash]
+			pinString, pin := bp.maskContext(ctx, msg, topic, nonce.Nonce)
+			nonce.Nonce++
+			contextsOrPins = append(contextsOrPins, pin)
+			msg.Pins = append(msg.Pins, pinString /* contains the nonce as well as the pin hash */)
 		}
-		if pinsAssigned {
+		if msg.Header.Group != nil {
 			// It's important we update the message pins at this phase, as we have "spent" a nonce
 			// on this topic from the database. So this message has grabbed a slot in our queue.
 			// If we fail the dispatch, and redo the batch sealing process, we must not allocate
@@ -489,15 +636,32 @@ func (bp *batchProcessor) sealBatch(state *DispatchState) (err error) {
 			if state.Persisted.TX.ID, err = bp.txHelper.SubmitNewTransaction(ctx, state.Persisted.Namespace, bp.conf.txType); err != nil {
 				return err
 			}
-			manifest := state.Persisted.GenManifest(state.Messages, state.Data)
+
+			state.ProtocolVersion = fftypes.ProtocolVersion1
+			if bp.conf.NegotiateProtocol != nil {
+				if state.ProtocolVersion, err = bp.conf.NegotiateProtocol(ctx, state); err != nil {
+					return err
+				}
+			}
+			manifest := state.Persisted.GenManifest(state.Messages, state.Data, state.ProtocolVersion)
 
 			// The hash of the batch, is the hash of the manifest to minimize the compute cost.
-			// Note in v0.13 and before, it was the hash of the payload - so the inbound route has a fallback to accepting the full payload hash
+			// Note in v0.13 and before, it was the hash of the payload - so the inbound route has a fallback to accepting the full payload hash.
+			// The algorithm is only recorded in the header when it is not the default, so the batch is unchanged for older members.
+			// Members before ProtocolVersion3 cannot verify any other algorithm, so we fall back to SHA-256 unless every recipient supports it
+			if !fftypes.IsDefaultHashAlgorithm(bp.conf.hashAlgorithm) {
+				if state.ProtocolVersion >= fftypes.ProtocolVersion3 {
+					state.Persisted.HashAlgorithm = bp.conf.hashAlgorithm
+				} else {
+					log.L(ctx).Warnf("Batch %s hashed with %s rather than %s, as a recipient only supports protocol version %d", state.Persisted.ID, fftypes.HashAlgorithmSHA256, bp.conf.hashAlgorithm, state.ProtocolVersion)
+				}
+			}
 			manifestString := manifest.String()
 			state.Persisted.Manifest = fftypes.JSONAnyPtr(manifestString)
-			state.Persisted.Hash = fftypes.HashString(manifestString)
+			// The algorithm was validated when the batch manager was created
+			state.Persisted.Hash, _ = fftypes.HashStringWithAlgorithm(ctx, state.Persisted.HashAlgorithm, manifestString)
 
-			log.L(ctx).Debugf("Batch %s sealed. Hash=%s", state.Persisted.ID, state.Persisted.Hash)
+			log.L(ctx).Debugf("Batch %s sealed. Hash=%s ProtocolVersion=%d", state.Persisted.ID, state.Persisted.Hash, state.ProtocolVersion)
 
 			// At this point the manifest of the batch is finalized. We write it to the database
 			return bp.database.UpsertBatch(ctx, &state.Persisted)
@@ -15,6 +15,7 @@
 package batch
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -162,6 +163,161 @@ func TestBatchSizeOverflow(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestFlushRequested(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	dispatched := make(chan *DispatchState)
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		dispatched <- state
+		return nil
+	})
+	defer cancel()
+	bp.conf.BatchTimeout = 1 * time.Hour
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeBatchPin).Return(fftypes.NewUUID(), nil)
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	bp.newWork <- &batchWork{
+		msg: &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, Sequence: 1000},
+	}
+
+	// Keep asking, as the request is ignored if it arrives before the work
+	var batch *DispatchState
+	for batch == nil {
+		bp.requestFlush()
+		select {
+		case batch = <-dispatched:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, 1, len(batch.Messages))
+
+	bp.cancelCtx()
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestFlushOnArrivalOverflow(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	dispatched := make(chan *DispatchState)
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		dispatched <- state
+		return nil
+	})
+	defer cancel()
+	bp.conf.BatchTimeout = 1 * time.Hour
+	bp.conf.BatchMaxBytes = batchSizeEstimateBase + (&fftypes.Message{}).EstimateSize(false) + 100
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeBatchPin).Return(fftypes.NewUUID(), nil)
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	// The second message overflows the first batch, and must still be flushed straight away
+	msgIDs := []*fftypes.UUID{fftypes.NewUUID(), fftypes.NewUUID()}
+	bp.newWork <- &batchWork{
+		msg: &fftypes.Message{Header: fftypes.MessageHeader{ID: msgIDs[0]}, Sequence: 1000},
+	}
+	bp.newWork <- &batchWork{
+		msg:   &fftypes.Message{Header: fftypes.MessageHeader{ID: msgIDs[1]}, Sequence: 1001},
+		flush: true,
+	}
+
+	batch1 := <-dispatched
+	batch2 := <-dispatched
+	assert.Equal(t, msgIDs[0], batch1.Messages[0].Header.ID)
+	assert.Equal(t, msgIDs[1], batch2.Messages[0].Header.ID)
+
+	bp.cancelCtx()
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestAssemblyStatus(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	dispatched := make(chan *DispatchState)
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		dispatched <- state
+		return nil
+	})
+	defer cancel()
+	bp.conf.BatchTimeout = 1 * time.Hour
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeBatchPin).Return(fftypes.NewUUID(), nil)
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	now := time.Now()
+	bp.newWork <- &batchWork{
+		msg:   &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, Sequence: 1001},
+		added: now.Add(-1 * time.Second),
+	}
+	bp.newWork <- &batchWork{
+		msg:   &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, Sequence: 1000},
+		added: now.Add(-2 * time.Second),
+	}
+
+	var as *AssemblyStatus
+	for as == nil || as.Messages < 2 {
+		time.Sleep(1 * time.Millisecond)
+		as = bp.assembly(now)
+	}
+	assert.Equal(t, int64(2000), as.OldestMessageAgeMS)
+	assert.Greater(t, as.Bytes, batchSizeEstimateBase)
+	assert.NotNil(t, as.FlushETA)
+	assert.Equal(t, int64(3600000), as.BatchTimeoutMS)
+
+	bp.requestFlush()
+	batch := <-dispatched
+	assert.Equal(t, 2, len(batch.Messages))
+
+	for as.Messages > 0 || as.FlushETA != nil {
+		time.Sleep(1 * time.Millisecond)
+		as = bp.assembly(time.Now())
+	}
+	assert.Equal(t, int64(0), as.Bytes)
+	assert.Equal(t, int64(0), as.OldestMessageAgeMS)
+
+	bp.cancelCtx()
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestRequestFlushNonBlocking(t *testing.T) {
+	bp := &batchProcessor{flushRequests: make(chan bool, 1)}
+	bp.requestFlush()
+	bp.requestFlush() // we're just checking this doesn't hang
+}
+
 func TestCloseToUnblockDispatch(t *testing.T) {
 	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
 		return fmt.Errorf("pop")
@@ -215,7 +371,7 @@ func TestCalcPinsFail(t *testing.T) {
 	defer cancel()
 	bp.cancelCtx()
 	mdi := bp.database.(*databasemocks.Plugin)
-	mdi.On("UpsertNonceNext", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdi.On("ReserveNonces", mock.Anything, mock.Anything, int64(1)).Return(fmt.Errorf("pop"))
 	mockRunAsGroupPassthrough(mdi)
 
 	gid := fftypes.NewRandB32()
@@ -239,6 +395,135 @@ func TestCalcPinsFail(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestSealBatchNegotiateProtocol(t *testing.T) {
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+	defer cancel()
+	bp.conf.txType = fftypes.TransactionTypeUnpinned
+	bp.conf.NegotiateProtocol = func(ctx context.Context, state *DispatchState) (uint, error) {
+		return fftypes.ProtocolVersion2, nil
+	}
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything).Return(nil)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeUnpinned).Return(fftypes.NewUUID(), nil)
+
+	state := &DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+	}
+	err := bp.sealBatch(state)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion2, state.ProtocolVersion)
+	var manifest fftypes.BatchManifest
+	err = state.Persisted.Manifest.Unmarshal(context.Background(), &manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ManifestVersion2, manifest.Version)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func sealBatchWithRecipients(t *testing.T, nodes []*fftypes.Identity) *DispatchState {
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+	defer cancel()
+	bp.conf.txType = fftypes.TransactionTypeUnpinned
+	bp.conf.hashAlgorithm = fftypes.HashAlgorithmSHA3256
+	bp.conf.NegotiateProtocol = func(ctx context.Context, state *DispatchState) (uint, error) {
+		return fftypes.NegotiateProtocolVersion(ctx, nodes)
+	}
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything).Return(nil)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeUnpinned).Return(fftypes.NewUUID(), nil)
+
+	state := &DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+	}
+	err := bp.sealBatch(state)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	return state
+}
+
+func newTestNode(protocolVersion interface{}) *fftypes.Identity {
+	node := &fftypes.Identity{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{}}}
+	if protocolVersion != nil {
+		node.Profile["protocolVersion"] = protocolVersion
+	}
+	return node
+}
+
+func TestSealBatchHashAlgorithm(t *testing.T) {
+	state := sealBatchWithRecipients(t, []*fftypes.Identity{
+		newTestNode(float64(fftypes.ProtocolVersion3)),
+		newTestNode(float64(fftypes.ProtocolVersion3 + 1)),
+	})
+	assert.Equal(t, fftypes.ProtocolVersion3, state.ProtocolVersion)
+	assert.Equal(t, fftypes.HashAlgorithmSHA3256, state.Persisted.HashAlgorithm)
+	expected, _ := fftypes.HashStringWithAlgorithm(context.Background(), fftypes.HashAlgorithmSHA3256, state.Persisted.Manifest.String())
+	assert.Equal(t, expected, state.Persisted.Hash)
+	assert.NotEqual(t, fftypes.HashString(state.Persisted.Manifest.String()), state.Persisted.Hash)
+}
+
+func TestSealBatchHashAlgorithmMixedVersionRecipients(t *testing.T) {
+	for _, oldNode := range []*fftypes.Identity{
+		newTestNode(float64(fftypes.ProtocolVersion2)),
+		newTestNode(nil), // registered before protocol versions were advertised
+	} {
+		state := sealBatchWithRecipients(t, []*fftypes.Identity{
+			newTestNode(float64(fftypes.ProtocolVersion3)),
+			oldNode,
+		})
+		assert.Less(t, state.ProtocolVersion, fftypes.ProtocolVersion3)
+		assert.Empty(t, state.Persisted.HashAlgorithm)
+		assert.Equal(t, fftypes.HashString(state.Persisted.Manifest.String()), state.Persisted.Hash)
+	}
+}
+
+func TestSealBatchNegotiateProtocolFail(t *testing.T) {
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+	defer cancel()
+	bp.cancelCtx()
+	bp.conf.txType = fftypes.TransactionTypeUnpinned
+	bp.conf.NegotiateProtocol = func(ctx context.Context, state *DispatchState) (uint, error) {
+		return 0, fmt.Errorf("pop")
+	}
+	mockRunAsGroupPassthrough(mdi)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeUnpinned).Return(fftypes.NewUUID(), nil)
+
+	err := bp.sealBatch(&DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.Regexp(t, "FF10158", err)
+
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+}
+
 func TestAddWorkInSort(t *testing.T) {
 	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
 		return nil
@@ -330,7 +615,7 @@ func TestMaskContextsDuplicate(t *testing.T) {
 	})
 	defer cancel()
 
-	mdi.On("UpsertNonceNext", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("ReserveNonces", mock.Anything, mock.Anything, int64(1)).Return(nil).Once()
 	mdi.On("UpdateMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
 	messages := []*fftypes.Message{
@@ -357,6 +642,68 @@ func TestMaskContextsDuplicate(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestMaskContextsReserveRanges(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+	defer cancel()
+
+	gid := fftypes.NewRandB32()
+	newMsg := func(topics ...string) *fftypes.Message {
+		return &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:     fftypes.NewUUID(),
+				Type:   fftypes.MessageTypePrivate,
+				Group:  gid,
+				Topics: topics,
+			},
+		}
+	}
+	messages := []*fftypes.Message{
+		newMsg("topic1", "topic2"),
+		newMsg("topic1"),
+		newMsg("topic1"),
+	}
+
+	var reserved []*fftypes.Bytes32
+	rn := mdi.On("ReserveNonces", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+	rn.RunFn = func(a mock.Arguments) {
+		nonce := a[1].(*fftypes.Nonce)
+		reserved = append(reserved, nonce.Context)
+		switch a[2].(int64) {
+		case 3:
+			assert.Equal(t, "topic1", nonce.Topic)
+			nonce.Nonce = 10
+		case 1:
+			assert.Equal(t, "topic2", nonce.Topic)
+			nonce.Nonce = 20
+		}
+	}
+	mdi.On("UpdateMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	pins, err := bp.maskContexts(bp.ctx, messages)
+	assert.NoError(t, err)
+	assert.Len(t, pins, 4)
+
+	// One reservation per context, in a consistent order
+	assert.Len(t, reserved, 2)
+	assert.Negative(t, bytes.Compare(reserved[0][:], reserved[1][:]))
+
+	// The nonces in each range are handed out in batch order
+	assert.Regexp(t, ":0000000000000010$", messages[0].Pins[0])
+	assert.Regexp(t, ":0000000000000020$", messages[0].Pins[1])
+	assert.Regexp(t, ":0000000000000011$", messages[1].Pins[0])
+	assert.Regexp(t, ":0000000000000012$", messages[2].Pins[0])
+
+	bp.cancelCtx()
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+}
+
 func TestMaskContextsUpdataMessageFail(t *testing.T) {
 	log.SetLevel("debug")
 	config.Reset()
@@ -368,7 +715,7 @@ func TestMaskContextsUpdataMessageFail(t *testing.T) {
 	})
 	defer cancel()
 
-	mdi.On("UpsertNonceNext", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("ReserveNonces", mock.Anything, mock.Anything, int64(1)).Return(nil).Once()
 	mdi.On("UpdateMessage", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
 
 	messages := []*fftypes.Message{
@@ -0,0 +1,117 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// pendingDataPoller periodically checks messages that were sent before all of their data was uploaded.
+// Once all the data has arrived the message is sealed and made ready, and the message sequencer picks
+// it up like any other new message. Messages still waiting after the timeout are rejected.
+func (bm *batchManager) pendingDataPoller() {
+	for {
+		select {
+		case <-time.After(bm.pendingDataPollInterval):
+			bm.checkPendingData()
+		case <-bm.ctx.Done():
+			log.L(bm.ctx).Debugf("Pending data poller exiting")
+			return
+		}
+	}
+}
+
+// checkPendingData pages through all the messages waiting for data, by sequence, as completed messages
+// leave the pending_data state while we are paging
+func (bm *batchManager) checkPendingData() {
+	lastSequence := int64(-1)
+	for {
+		fb := database.MessageQueryFactory.NewFilterLimit(bm.ctx, bm.readPageSize)
+		msgs, _, err := bm.database.GetMessages(bm.ctx, fb.And(
+			fb.Eq("state", fftypes.MessageStatePendingData),
+			fb.Gt("sequence", lastSequence),
+		).Sort("sequence").Limit(bm.readPageSize))
+		if err != nil {
+			// We will try again on the next poll
+			log.L(bm.ctx).Errorf("Failed to query messages waiting for data: %s", err)
+			return
+		}
+		for _, msg := range msgs {
+			if err := bm.checkPendingMessage(msg); err != nil {
+				log.L(bm.ctx).Errorf("Failed to process message %s waiting for data: %s", msg.Header.ID, err)
+			}
+			lastSequence = msg.Sequence
+		}
+		if len(msgs) < int(bm.readPageSize) {
+			return
+		}
+	}
+}
+
+func (bm *batchManager) checkPendingMessage(msg *fftypes.Message) error {
+	complete, err := bm.data.ResolvePendingData(bm.ctx, msg)
+	if err != nil {
+		return err
+	}
+	if !complete {
+		if time.Since(*msg.Header.Created.Time()) > bm.pendingDataTimeout {
+			return bm.rejectPendingMessage(msg, i18n.NewError(bm.ctx, i18n.MsgPendingDataTimeout, bm.pendingDataTimeout))
+		}
+		return nil
+	}
+
+	// The hashes calculated when the message was submitted did not include the data, so we seal it again
+	msg.State = fftypes.MessageStateReady
+	if err := msg.Seal(bm.ctx); err != nil {
+		return bm.rejectPendingMessage(msg, err)
+	}
+	err = bm.database.CompletePendingMessage(bm.ctx, msg)
+	if err == database.DeleteRecordNotFound {
+		// The message is no longer waiting for data
+		return nil
+	}
+	if err == nil {
+		log.L(bm.ctx).Infof("Message %s has all its data, and is ready to send", msg.Header.ID)
+	}
+	return err
+}
+
+func (bm *batchManager) rejectPendingMessage(msg *fftypes.Message, reason error) error {
+	log.L(bm.ctx).Warnf("Rejecting message %s: %s", msg.Header.ID, reason)
+	return bm.database.RunAsGroup(bm.ctx, func(ctx context.Context) error {
+		u := database.MessageQueryFactory.NewUpdate(ctx).Set("state", fftypes.MessageStateRejected)
+		if err := bm.database.UpdateMessage(ctx, msg.Header.ID, u); err != nil {
+			return err
+		}
+		// Generate one event per topic, as events cover a single topic
+		for _, topic := range msg.Header.Topics {
+			event := fftypes.NewEvent(fftypes.EventTypeMessageRejected, msg.Header.Namespace, msg.Header.ID, nil, topic)
+			event.Correlator = msg.Header.CID
+			event.CorrelationID = msg.CorrelationID
+			if err := bm.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,238 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPendingMessage() *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      fftypes.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1", "topic2"},
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"},
+			TxType:    fftypes.TransactionTypeBatchPin,
+			Created:   fftypes.Now(),
+		},
+		State: fftypes.MessageStatePendingData,
+		Data: fftypes.DataRefs{
+			{ID: fftypes.NewUUID()},
+		},
+	}
+}
+
+func mockRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+}
+
+func TestPendingDataPollerCompletesMessage(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.pendingDataPollInterval = 1 * time.Microsecond
+
+	msg := newPendingMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, err := f.Finalize()
+		assert.NoError(t, err)
+		assert.Equal(t, "( state == 'pending_data' ) && ( sequence >> -1 ) sort=sequence limit=100", fi.String())
+		return true
+	})).Return([]*fftypes.Message{msg}, nil, nil)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Run(func(args mock.Arguments) {
+		msg.Data[0].Hash = fftypes.NewRandB32()
+	}).Return(true, nil)
+	mdi.On("CompletePendingMessage", mock.Anything, mock.MatchedBy(func(m *fftypes.Message) bool {
+		return m.State == fftypes.MessageStateReady && m.Hash != nil && m.VerifyFields(context.Background()) == nil
+	})).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(nil)
+
+	bm.pendingDataPoller()
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingDataPages(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.readPageSize = 1
+
+	msg1 := newPendingMessage()
+	msg1.Sequence = 10
+	msg2 := newPendingMessage()
+	msg2.Sequence = 20
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( state == 'pending_data' ) && ( sequence >> -1 ) sort=sequence limit=1"
+	})).Return([]*fftypes.Message{msg1}, nil, nil).Once()
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( state == 'pending_data' ) && ( sequence >> 10 ) sort=sequence limit=1"
+	})).Return([]*fftypes.Message{msg2}, nil, nil).Once()
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( state == 'pending_data' ) && ( sequence >> 20 ) sort=sequence limit=1"
+	})).Return([]*fftypes.Message{}, nil, nil).Once()
+	mdm.On("ResolvePendingData", mock.Anything, msg1).Return(false, nil)
+	mdm.On("ResolvePendingData", mock.Anything, msg2).Return(false, nil)
+
+	bm.checkPendingData()
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingDataQueryFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	bm.checkPendingData()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckPendingDataResolveFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg := newPendingMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Return(false, fmt.Errorf("pop"))
+
+	bm.checkPendingData()
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingMessageStillWaiting(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg := newPendingMessage()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Return(false, nil)
+
+	err := bm.checkPendingMessage(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStatePendingData, msg.State)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingMessageTimeout(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.pendingDataTimeout = 1 * time.Minute
+
+	msg := newPendingMessage()
+	created := fftypes.FFTime(time.Now().Add(-2 * time.Minute))
+	msg.Header.Created = &created
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Return(false, nil)
+	mockRunAsGroup(mdi)
+	mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageRejected && e.Reference.Equals(msg.Header.ID)
+	})).Return(nil).Twice()
+
+	err := bm.checkPendingMessage(msg)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingMessageSealFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg := newPendingMessage()
+	msg.Data = append(msg.Data, msg.Data[0])
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Return(true, nil)
+	mockRunAsGroup(mdi)
+	mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bm.checkPendingMessage(msg)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestCheckPendingMessageAlreadyComplete(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg := newPendingMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("ResolvePendingData", mock.Anything, msg).Run(func(args mock.Arguments) {
+		msg.Data[0].Hash = fftypes.NewRandB32()
+	}).Return(true, nil)
+	mdi.On("CompletePendingMessage", mock.Anything, msg).Return(database.DeleteRecordNotFound)
+
+	err := bm.checkPendingMessage(msg)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestRejectPendingMessageUpdateFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg := newPendingMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bm.rejectPendingMessage(msg, fmt.Errorf("timeout"))
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// scheduledMessagePoller periodically checks draft messages that have been scheduled to be sent.
+// Once a message is due it is made ready, and the message sequencer picks it up like any other new message.
+func (bm *batchManager) scheduledMessagePoller() {
+	for {
+		select {
+		case <-time.After(bm.scheduledPollInterval):
+			bm.checkScheduledMessages()
+		case <-bm.ctx.Done():
+			log.L(bm.ctx).Debugf("Scheduled message poller exiting")
+			return
+		}
+	}
+}
+
+func (bm *batchManager) checkScheduledMessages() {
+	fb := database.MessageQueryFactory.NewFilterLimit(bm.ctx, bm.readPageSize)
+	msgs, _, err := bm.database.GetMessages(bm.ctx, fb.And(
+		fb.Eq("state", fftypes.MessageStateScheduled),
+		fb.Lte("sendat", fftypes.Now()),
+	).Sort("sendat").Limit(bm.readPageSize))
+	if err != nil {
+		// We will try again on the next poll
+		log.L(bm.ctx).Errorf("Failed to query scheduled messages: %s", err)
+		return
+	}
+	for _, msg := range msgs {
+		msg.State = fftypes.MessageStateReady
+		err := bm.database.ActivateDraftMessage(bm.ctx, msg)
+		switch {
+		case err == database.DeleteRecordNotFound:
+			// The message has already been activated
+		case err != nil:
+			log.L(bm.ctx).Errorf("Failed to activate scheduled message %s: %s", msg.Header.ID, err)
+		default:
+			log.L(bm.ctx).Infof("Scheduled message %s is ready to send", msg.Header.ID)
+		}
+	}
+}
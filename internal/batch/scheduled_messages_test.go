@@ -0,0 +1,89 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newScheduledMessage() *fftypes.Message {
+	msg := newPendingMessage()
+	msg.State = fftypes.MessageStateScheduled
+	msg.SendAt = fftypes.Now()
+	return msg
+}
+
+func TestScheduledMessagePollerActivatesMessage(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+	bm.scheduledPollInterval = 1 * time.Microsecond
+
+	msg := newScheduledMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, err := f.Finalize()
+		assert.NoError(t, err)
+		return strings.HasPrefix(fi.String(), "( state == 'scheduled' ) && ( sendat <= ") &&
+			strings.HasSuffix(fi.String(), " ) sort=sendat limit=100")
+	})).Return([]*fftypes.Message{msg}, nil, nil)
+	mdi.On("ActivateDraftMessage", mock.Anything, mock.MatchedBy(func(m *fftypes.Message) bool {
+		return m.Header.ID.Equals(msg.Header.ID) && m.State == fftypes.MessageStateReady
+	})).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(nil)
+
+	bm.scheduledMessagePoller()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckScheduledMessagesQueryFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	bm.checkScheduledMessages()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckScheduledMessagesActivateFail(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	msg1 := newScheduledMessage()
+	msg2 := newScheduledMessage()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg1, msg2}, nil, nil)
+	mdi.On("ActivateDraftMessage", mock.Anything, msg1).Return(fmt.Errorf("pop"))
+	mdi.On("ActivateDraftMessage", mock.Anything, msg2).Return(database.DeleteRecordNotFound)
+
+	bm.checkScheduledMessages()
+
+	mdi.AssertExpectations(t)
+}
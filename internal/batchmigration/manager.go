@@ -0,0 +1,165 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchmigration
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// maxReportedFailures limits the individual failures held in the status, while still counting them all
+const maxReportedFailures = 100
+
+// Manager rewrites the batches persisted by v0.13.x and earlier, which stored the full batch payload,
+// to the manifest format - so the aggregator no longer needs to convert them each time they are read.
+type Manager interface {
+	// Start migrates all the legacy batches in the background, returning the initial status
+	Start(ctx context.Context) (*fftypes.BatchMigrationStatus, error)
+	// Run migrates all the legacy batches, returning once complete
+	Run(ctx context.Context) (*fftypes.BatchMigrationStatus, error)
+	// Status returns the progress of the running, or most recently completed, migration
+	Status() *fftypes.BatchMigrationStatus
+}
+
+type batchMigrator struct {
+	ctx      context.Context
+	database database.Plugin
+	pageSize uint64
+	mux      sync.Mutex
+	status   fftypes.BatchMigrationStatus
+}
+
+func NewBatchMigrator(ctx context.Context, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	return &batchMigrator{
+		ctx:      log.WithLogField(ctx, "role", "batch-migration"),
+		database: di,
+		pageSize: uint64(config.GetUint(config.BatchMigrationPageSize)),
+	}, nil
+}
+
+func (bm *batchMigrator) Start(ctx context.Context) (*fftypes.BatchMigrationStatus, error) {
+	if err := bm.begin(ctx); err != nil {
+		return nil, err
+	}
+	// The migration outlives the request that started it
+	go bm.migrate(bm.ctx)
+	return bm.Status(), nil
+}
+
+func (bm *batchMigrator) Run(ctx context.Context) (*fftypes.BatchMigrationStatus, error) {
+	if err := bm.begin(ctx); err != nil {
+		return nil, err
+	}
+	err := bm.migrate(ctx)
+	return bm.Status(), err
+}
+
+func (bm *batchMigrator) Status() *fftypes.BatchMigrationStatus {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+	status := bm.status
+	status.Failures = make([]*fftypes.BatchMigrationFailure, len(bm.status.Failures))
+	copy(status.Failures, bm.status.Failures)
+	return &status
+}
+
+func (bm *batchMigrator) begin(ctx context.Context) error {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+	if bm.status.Running {
+		return i18n.NewError(ctx, i18n.MsgBatchMigrationRunning)
+	}
+	bm.status = fftypes.BatchMigrationStatus{
+		Running: true,
+		Started: fftypes.Now(),
+	}
+	return nil
+}
+
+func (bm *batchMigrator) migrate(ctx context.Context) error {
+	log.L(ctx).Infof("Batch migration started")
+	err := bm.migratePages(ctx)
+	bm.complete(ctx, err)
+	return err
+}
+
+func (bm *batchMigrator) migratePages(ctx context.Context) error {
+	for skip := uint64(0); ; skip += bm.pageSize {
+		fb := database.BatchQueryFactory.NewFilter(ctx)
+		filter := fb.And().Sort("created", "id").Skip(skip).Limit(bm.pageSize)
+		batches, _, err := bm.database.GetBatches(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, batch := range batches {
+			bm.migrateBatch(ctx, batch)
+		}
+		status := bm.Status()
+		log.L(ctx).Infof("Batch migration progress: scanned=%d migrated=%d failed=%d", status.Scanned, status.Migrated, status.Failed)
+		if uint64(len(batches)) < bm.pageSize {
+			return nil
+		}
+	}
+}
+
+func (bm *batchMigrator) migrateBatch(ctx context.Context, batch *fftypes.BatchPersisted) {
+	manifest, err := batch.MigrateManifest(ctx)
+	if err == nil && manifest != nil {
+		batch.Manifest = fftypes.JSONAnyPtr(manifest.String())
+		err = bm.database.UpsertBatch(ctx, batch)
+	}
+
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+	bm.status.Scanned++
+	switch {
+	case err != nil:
+		log.L(ctx).Errorf("Failed to migrate batch %s: %s", batch.ID, err)
+		bm.status.Failed++
+		if len(bm.status.Failures) < maxReportedFailures {
+			bm.status.Failures = append(bm.status.Failures, &fftypes.BatchMigrationFailure{
+				Batch: batch.ID,
+				Error: err.Error(),
+			})
+		}
+	case manifest != nil:
+		log.L(ctx).Debugf("Migrated batch %s", batch.ID)
+		bm.status.Migrated++
+	}
+}
+
+func (bm *batchMigrator) complete(ctx context.Context, err error) {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+	bm.status.Running = false
+	bm.status.Completed = fftypes.Now()
+	if err != nil {
+		bm.status.Error = err.Error()
+		log.L(ctx).Errorf("Batch migration failed: %s", err)
+	} else {
+		log.L(ctx).Infof("Batch migration completed: scanned=%d migrated=%d failed=%d", bm.status.Scanned, bm.status.Migrated, bm.status.Failed)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchmigration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestBatchMigrator(pageSize int) (*batchMigrator, *databasemocks.Plugin) {
+	config.Reset()
+	config.Set(config.BatchMigrationPageSize, pageSize)
+	mdi := &databasemocks.Plugin{}
+	bm, _ := NewBatchMigrator(context.Background(), mdi)
+	return bm.(*batchMigrator), mdi
+}
+
+func newLegacyBatch(t *testing.T) *fftypes.BatchPersisted {
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1"},
+		},
+	}
+	msg.Hash = msg.Header.Hash()
+	b, err := json.Marshal(&fftypes.BatchPayload{
+		Messages: []*fftypes.Message{msg},
+	})
+	assert.NoError(t, err)
+	var hash fftypes.Bytes32 = sha256.Sum256(b)
+	return &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID()},
+		Hash:        &hash,
+		Manifest:    fftypes.JSONAnyPtr(string(b)),
+	}
+}
+
+func newMigratedBatch() *fftypes.BatchPersisted {
+	return &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID()},
+		Manifest:    fftypes.JSONAnyPtr(`{"version":1}`),
+	}
+}
+
+func TestNewBatchMigratorMissingDeps(t *testing.T) {
+	_, err := NewBatchMigrator(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestRunMigratesLegacyBatches(t *testing.T) {
+	bm, mdi := newTestBatchMigrator(2)
+
+	legacy := newLegacyBatch(t)
+	badHash := newLegacyBatch(t)
+	badHash.Hash = fftypes.NewRandB32()
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{legacy, newMigratedBatch()}, nil, nil).Once()
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{badHash}, nil, nil).Once()
+	mdi.On("UpsertBatch", mock.Anything, mock.MatchedBy(func(b *fftypes.BatchPersisted) bool {
+		var manifest fftypes.BatchManifest
+		err := b.Manifest.Unmarshal(context.Background(), &manifest)
+		return err == nil && b.ID.Equals(legacy.ID) && manifest.Version == fftypes.ManifestVersion1 && len(manifest.Messages) == 1
+	})).Return(nil)
+
+	status, err := bm.Run(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status.Running)
+	assert.NotNil(t, status.Started)
+	assert.NotNil(t, status.Completed)
+	assert.Equal(t, int64(3), status.Scanned)
+	assert.Equal(t, int64(1), status.Migrated)
+	assert.Equal(t, int64(1), status.Failed)
+	assert.Equal(t, badHash.ID, status.Failures[0].Batch)
+	assert.Regexp(t, "FF10441", status.Failures[0].Error)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRunUpsertFail(t *testing.T) {
+	bm, mdi := newTestBatchMigrator(2)
+
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{newLegacyBatch(t)}, nil, nil).Once()
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	status, err := bm.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Migrated)
+	assert.Equal(t, int64(1), status.Failed)
+	assert.Equal(t, "pop", status.Failures[0].Error)
+}
+
+func TestRunReportedFailuresLimited(t *testing.T) {
+	bm, mdi := newTestBatchMigrator(200)
+
+	batches := make([]*fftypes.BatchPersisted, maxReportedFailures+1)
+	for i := range batches {
+		batches[i] = &fftypes.BatchPersisted{Manifest: fftypes.JSONAnyPtr(`!json`)}
+	}
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return(batches, nil, nil).Once()
+
+	status, err := bm.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(maxReportedFailures+1), status.Failed)
+	assert.Len(t, status.Failures, maxReportedFailures)
+}
+
+func TestRunGetBatchesFail(t *testing.T) {
+	bm, mdi := newTestBatchMigrator(2)
+
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	status, err := bm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+	assert.False(t, status.Running)
+	assert.Equal(t, "pop", status.Error)
+}
+
+func TestRunAlreadyRunning(t *testing.T) {
+	bm, _ := newTestBatchMigrator(2)
+	bm.status.Running = true
+
+	_, err := bm.Run(context.Background())
+	assert.Regexp(t, "FF10443", err)
+}
+
+func TestStart(t *testing.T) {
+	bm, mdi := newTestBatchMigrator(2)
+
+	waitGetBatches := make(chan struct{})
+	mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{}, nil, nil).Run(func(args mock.Arguments) {
+		<-waitGetBatches
+	})
+
+	status, err := bm.Start(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Running)
+
+	_, err = bm.Start(context.Background())
+	assert.Regexp(t, "FF10443", err)
+
+	close(waitGetBatches)
+	for bm.Status().Running {
+		time.Sleep(1 * time.Millisecond)
+	}
+	assert.NotNil(t, bm.Status().Completed)
+}
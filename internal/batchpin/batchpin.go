@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -33,6 +34,12 @@ type Submitter interface {
 
 	SubmitPinnedBatch(ctx context.Context, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) error
 
+	// PinAccess reports whether the org signing key is able to pin batches, when the batch pin contract restricts pinning to registered keys
+	PinAccess(ctx context.Context) (*fftypes.PinAccess, error)
+
+	// RegisterPinKey submits a transaction to register the org signing key with the batch pin contract
+	RegisterPinKey(ctx context.Context) (*fftypes.Operation, error)
+
 	// From operations.OperationHandler
 	PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error)
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (outputs fftypes.JSONObject, complete bool, err error)
@@ -44,10 +51,11 @@ type batchPinSubmitter struct {
 	blockchain blockchain.Plugin
 	metrics    metrics.Manager
 	operations operations.Manager
+	txHelper   txcommon.Helper
 }
 
-func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, bi blockchain.Plugin, mm metrics.Manager, om operations.Manager) (Submitter, error) {
-	if di == nil || im == nil || bi == nil || mm == nil || om == nil {
+func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, bi blockchain.Plugin, mm metrics.Manager, om operations.Manager, th txcommon.Helper) (Submitter, error) {
+	if di == nil || im == nil || bi == nil || mm == nil || om == nil || th == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	bp := &batchPinSubmitter{
@@ -56,9 +64,11 @@ func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.M
 		blockchain: bi,
 		metrics:    mm,
 		operations: om,
+		txHelper:   th,
 	}
 	om.RegisterHandler(ctx, bp, []fftypes.OpType{
 		fftypes.OpTypeBlockchainPinBatch,
+		fftypes.OpTypeBlockchainRegisterKey,
 	})
 	return bp, nil
 }
@@ -75,12 +85,63 @@ func (bp *batchPinSubmitter) SubmitPinnedBatch(ctx context.Context, batch *fftyp
 		batch.TX.ID,
 		fftypes.OpTypeBlockchainPinBatch)
 	addBatchPinInputs(op, batch.ID, contexts)
-	if err := bp.operations.AddOrReuseOperation(ctx, op); err != nil {
+
+	// The submission to the blockchain is performed by the outbox, once the operation is committed
+	err := bp.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := bp.operations.AddOrReuseOperation(ctx, op); err != nil {
+			return err
+		}
+		return bp.operations.QueueOperation(ctx, op)
+	})
+	if err != nil {
 		return err
 	}
 
 	if bp.metrics.IsMetricsEnabled() {
 		bp.metrics.CountBatchPin()
 	}
-	return bp.operations.RunOperation(ctx, opBatchPin(op, batch, contexts))
+	return nil
+}
+
+func (bp *batchPinSubmitter) PinAccess(ctx context.Context) (*fftypes.PinAccess, error) {
+	key, err := bp.identity.GetNodeOwnerBlockchainKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bp.blockchain.PinAccess(ctx, key.Value)
+}
+
+func (bp *batchPinSubmitter) RegisterPinKey(ctx context.Context) (op *fftypes.Operation, err error) {
+	access, err := bp.PinAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !access.Permissioned {
+		return nil, i18n.NewError(ctx, i18n.MsgPinAccessNotPermissioned, bp.blockchain.Name())
+	}
+	if access.Registered {
+		return nil, i18n.NewError(ctx, i18n.MsgPinKeyAlreadyRegistered, access.Key)
+	}
+
+	// The registration is submitted to the blockchain by the outbox, once the operation is committed
+	err = bp.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		txid, err := bp.txHelper.SubmitNewTransaction(ctx, fftypes.SystemNamespace, fftypes.TransactionTypeKeyRegistration)
+		if err != nil {
+			return err
+		}
+		op = fftypes.NewOperation(
+			bp.blockchain,
+			fftypes.SystemNamespace,
+			txid,
+			fftypes.OpTypeBlockchainRegisterKey)
+		addRegisterKeyInputs(op, access.Key)
+		if err := bp.operations.AddOrReuseOperation(ctx, op); err != nil {
+			return err
+		}
+		return bp.operations.QueueOperation(ctx, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
 }
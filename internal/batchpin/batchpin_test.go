@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -42,19 +43,26 @@ func newTestBatchPinSubmitter(t *testing.T, enableMetrics bool) *batchPinSubmitt
 	mbi := &blockchainmocks.Plugin{}
 	mmi := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
+	mth := &txcommonmocks.Helper{}
 	mmi.On("IsMetricsEnabled").Return(enableMetrics)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
 	if enableMetrics {
 		mmi.On("CountBatchPin").Return()
 	}
 	mbi.On("Name").Return("ut").Maybe()
-	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbi, mmi, mom)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbi, mmi, mom, mth)
 	assert.NoError(t, err)
 	return bps.(*batchPinSubmitter)
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewBatchPinSubmitter(context.Background(), nil, nil, nil, nil, nil)
+	_, err := NewBatchPinSubmitter(context.Background(), nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -85,16 +93,15 @@ func TestSubmitPinnedBatchOk(t *testing.T) {
 	}
 	contexts := []*fftypes.Bytes32{}
 
-	mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		assert.Equal(t, fftypes.OpTypeBlockchainPinBatch, op.Type)
 		assert.Equal(t, "ut", op.Plugin)
 		assert.Equal(t, *batch.TX.ID, *op.Transaction)
 		return true
 	})).Return(nil)
 	mmi.On("IsMetricsEnabled").Return(false)
-	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
-		data := op.Data.(batchPinData)
-		return op.Type == fftypes.OpTypeBlockchainPinBatch && data.Batch == batch
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainPinBatch && op.Input.GetString("batch") == batch.ID.String()
 	})).Return(nil)
 
 	err := bp.SubmitPinnedBatch(ctx, batch, contexts)
@@ -127,16 +134,15 @@ func TestSubmitPinnedBatchWithMetricsOk(t *testing.T) {
 	}
 	contexts := []*fftypes.Bytes32{}
 
-	mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		assert.Equal(t, fftypes.OpTypeBlockchainPinBatch, op.Type)
 		assert.Equal(t, "ut", op.Plugin)
 		assert.Equal(t, *batch.TX.ID, *op.Transaction)
 		return true
 	})).Return(nil)
 	mmi.On("IsMetricsEnabled").Return(true)
-	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
-		data := op.Data.(batchPinData)
-		return op.Type == fftypes.OpTypeBlockchainPinBatch && data.Batch == batch
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainPinBatch && op.Input.GetString("batch") == batch.ID.String()
 	})).Return(nil)
 
 	err := bp.SubmitPinnedBatch(ctx, batch, contexts)
@@ -152,7 +158,6 @@ func TestSubmitPinnedBatchOpFail(t *testing.T) {
 	ctx := context.Background()
 
 	mom := bp.operations.(*operationmocks.Manager)
-	mmi := bp.metrics.(*metricsmocks.Manager)
 
 	batch := &fftypes.BatchPersisted{
 		BatchHeader: fftypes.BatchHeader{
@@ -168,9 +173,171 @@ func TestSubmitPinnedBatchOpFail(t *testing.T) {
 	}
 	contexts := []*fftypes.Bytes32{}
 
-	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(fmt.Errorf("pop"))
-	mmi.On("IsMetricsEnabled").Return(false)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	err := bp.SubmitPinnedBatch(ctx, batch, contexts)
 	assert.Regexp(t, "pop", err)
 
+	mom.AssertExpectations(t)
+}
+
+func TestSubmitPinnedBatchQueueFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mom := bp.operations.(*operationmocks.Manager)
+
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+		TX: fftypes.TransactionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	contexts := []*fftypes.Bytes32{}
+
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("QueueOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err := bp.SubmitPinnedBatch(ctx, batch, contexts)
+	assert.Regexp(t, "pop", err)
+
+	mom.AssertExpectations(t)
+}
+
+func TestPinAccess(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{Permissioned: true, Key: "0x12345"}, nil)
+
+	access, err := bp.PinAccess(ctx)
+	assert.NoError(t, err)
+	assert.True(t, access.Permissioned)
+
+	mim.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestPinAccessNoKey(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bp.PinAccess(ctx)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
+func TestRegisterPinKey(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mom := bp.operations.(*operationmocks.Manager)
+	txID := fftypes.NewUUID()
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{Permissioned: true, Key: "0x12345"}, nil)
+	mth.On("SubmitNewTransaction", ctx, fftypes.SystemNamespace, fftypes.TransactionTypeKeyRegistration).Return(txID, nil)
+	mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainRegisterKey && op.Input.GetString("key") == "0x12345"
+	})).Return(nil)
+	mom.On("QueueOperation", ctx, mock.Anything).Return(nil)
+
+	op, err := bp.RegisterPinKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, txID, op.Transaction)
+
+	mim.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestRegisterPinKeyAccessFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(nil, fmt.Errorf("pop"))
+
+	_, err := bp.RegisterPinKey(ctx)
+	assert.EqualError(t, err, "pop")
+
+	mbi.AssertExpectations(t)
+}
+
+func TestRegisterPinKeyNotPermissioned(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{}, nil)
+
+	_, err := bp.RegisterPinKey(ctx)
+	assert.Regexp(t, "FF10517.*ut", err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestRegisterPinKeyAlreadyRegistered(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{Permissioned: true, Key: "0x12345", Registered: true}, nil)
+
+	_, err := bp.RegisterPinKey(ctx)
+	assert.Regexp(t, "FF10518.*0x12345", err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestRegisterPinKeyTXFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{Permissioned: true, Key: "0x12345"}, nil)
+	mth.On("SubmitNewTransaction", ctx, fftypes.SystemNamespace, fftypes.TransactionTypeKeyRegistration).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bp.RegisterPinKey(ctx)
+	assert.EqualError(t, err, "pop")
+
+	mth.AssertExpectations(t)
+}
+
+func TestRegisterPinKeyOpFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx := context.Background()
+
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mom := bp.operations.(*operationmocks.Manager)
+	mim.On("GetNodeOwnerBlockchainKey", ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mbi.On("PinAccess", ctx, "0x12345").Return(&fftypes.PinAccess{Permissioned: true, Key: "0x12345"}, nil)
+	mth.On("SubmitNewTransaction", ctx, fftypes.SystemNamespace, fftypes.TransactionTypeKeyRegistration).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := bp.RegisterPinKey(ctx)
+	assert.EqualError(t, err, "pop")
+
+	mom.AssertExpectations(t)
 }
@@ -29,6 +29,10 @@ type batchPinData struct {
 	Contexts []*fftypes.Bytes32      `json:"contexts"`
 }
 
+type registerKeyData struct {
+	Key string `json:"key"`
+}
+
 func addBatchPinInputs(op *fftypes.Operation, batchID *fftypes.UUID, contexts []*fftypes.Bytes32) {
 	contextStr := make([]string, len(contexts))
 	for i, c := range contexts {
@@ -56,6 +60,12 @@ func retrieveBatchPinInputs(ctx context.Context, op *fftypes.Operation) (batchID
 	return batchID, contexts, nil
 }
 
+func addRegisterKeyInputs(op *fftypes.Operation, key string) {
+	op.Input = fftypes.JSONObject{
+		"key": key,
+	}
+}
+
 func (bp *batchPinSubmitter) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	switch op.Type {
 	case fftypes.OpTypeBlockchainPinBatch:
@@ -71,6 +81,9 @@ func (bp *batchPinSubmitter) PrepareOperation(ctx context.Context, op *fftypes.O
 		}
 		return opBatchPin(op, batch, contexts), nil
 
+	case fftypes.OpTypeBlockchainRegisterKey:
+		return opRegisterKey(op, op.Input.GetString("key")), nil
+
 	default:
 		return nil, i18n.NewError(ctx, i18n.MsgOperationNotSupported, op.Type)
 	}
@@ -89,6 +102,9 @@ func (bp *batchPinSubmitter) RunOperation(ctx context.Context, op *fftypes.Prepa
 			Contexts:        data.Contexts,
 		})
 
+	case registerKeyData:
+		return nil, false, bp.blockchain.RegisterPinKey(ctx, op.ID, data.Key)
+
 	default:
 		return nil, false, i18n.NewError(ctx, i18n.MsgOperationDataIncorrect, op.Data)
 	}
@@ -96,8 +112,20 @@ func (bp *batchPinSubmitter) RunOperation(ctx context.Context, op *fftypes.Prepa
 
 func opBatchPin(op *fftypes.Operation, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: batchPinData{Batch: batch, Contexts: contexts},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        batchPinData{Batch: batch, Contexts: contexts},
+	}
+}
+
+func opRegisterKey(op *fftypes.Operation, key string) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        registerKeyData{Key: key},
 	}
 }
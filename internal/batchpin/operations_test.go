@@ -146,3 +146,27 @@ func TestPrepareOperationBatchPinNotFound(t *testing.T) {
 	_, err := bp.PrepareOperation(context.Background(), op)
 	assert.Regexp(t, "FF10109", err)
 }
+
+func TestPrepareAndRunRegisterKey(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainRegisterKey,
+		ID:   fftypes.NewUUID(),
+	}
+	addRegisterKeyInputs(op, "0x123")
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("RegisterPinKey", context.Background(), op.ID, "0x123").Return(nil)
+
+	po, err := bp.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", po.Data.(registerKeyData).Key)
+
+	_, complete, err := bp.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
@@ -21,14 +21,18 @@ import (
 
 	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
 	"github.com/hyperledger/firefly/internal/blockchain/fabric"
+	"github.com/hyperledger/firefly/internal/blockchain/ffconnector"
+	"github.com/hyperledger/firefly/internal/blockchain/memchain"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 )
 
 var pluginsByName = map[string]func() blockchain.Plugin{
-	(*ethereum.Ethereum)(nil).Name(): func() blockchain.Plugin { return &ethereum.Ethereum{} },
-	(*fabric.Fabric)(nil).Name():     func() blockchain.Plugin { return &fabric.Fabric{} },
+	(*ethereum.Ethereum)(nil).Name():       func() blockchain.Plugin { return &ethereum.Ethereum{} },
+	(*fabric.Fabric)(nil).Name():           func() blockchain.Plugin { return &fabric.Fabric{} },
+	(*ffconnector.FFConnector)(nil).Name(): func() blockchain.Plugin { return &ffconnector.FFConnector{} },
+	(*memchain.Memchain)(nil).Name():       func() blockchain.Plugin { return &memchain.Memchain{} },
 }
 
 func InitPrefix(prefix config.Prefix) {
@@ -96,3 +96,49 @@ var batchPinEventABI = ABIElementMarshaling{
 		},
 	},
 }
+
+var permissionedMethodABI = ABIElementMarshaling{
+	Name:            "permissioned",
+	Type:            "function",
+	StateMutability: "view",
+	Inputs:          []ABIArgumentMarshaling{},
+	Outputs: []ABIArgumentMarshaling{
+		{
+			InternalType: "bool",
+			Name:         "",
+			Type:         "bool",
+		},
+	},
+}
+
+var isRegisteredMethodABI = ABIElementMarshaling{
+	Name:            "isRegistered",
+	Type:            "function",
+	StateMutability: "view",
+	Inputs: []ABIArgumentMarshaling{
+		{
+			InternalType: "address",
+			Name:         "key",
+			Type:         "address",
+		},
+	},
+	Outputs: []ABIArgumentMarshaling{
+		{
+			InternalType: "bool",
+			Name:         "",
+			Type:         "bool",
+		},
+	},
+}
+
+var registerKeyMethodABI = ABIElementMarshaling{
+	Name: "registerKey",
+	Type: "function",
+	Inputs: []ABIArgumentMarshaling{
+		{
+			InternalType: "address",
+			Name:         "key",
+			Type:         "address",
+		},
+	},
+}
@@ -0,0 +1,128 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// chainProfile adapts the plugin to the finality, fee and timestamp behavior of a particular chain
+type chainProfile struct {
+	// confirmations is the default confirmation depth for BatchPin events and contract listeners, on chains where blocks
+	// can be reorganized more deeply than the connector protects against by default
+	confirmations uint64
+	// eip1559 means transactions are submitted with EIP-1559 fee fields
+	eip1559 bool
+	// maxPriorityFeePerGas is the default priority fee, on chains that reject transactions below a minimum tip
+	maxPriorityFeePerGas string
+	// sequencerTimestamps means event timestamps are assigned by an L2 sequencer, and delivered as hex quantities
+	sequencerTimestamps bool
+}
+
+var chainProfiles = map[string]*chainProfile{
+	"": {},
+	"polygon": {
+		confirmations:        128,
+		eip1559:              true,
+		maxPriorityFeePerGas: "30000000000",
+	},
+	"arbitrum": {
+		confirmations:        20,
+		eip1559:              true,
+		maxPriorityFeePerGas: "0",
+		sequencerTimestamps:  true,
+	},
+}
+
+// EthconnectGasPrice is the EIP-1559 fees for a transaction. Either can be empty for the connector to estimate it.
+type EthconnectGasPrice struct {
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+}
+
+func (e *Ethereum) initChainProfile(ctx context.Context, ethconnectConf config.Prefix) error {
+	name := ethconnectConf.GetString(EthconnectConfigChainProfile)
+	profile, ok := chainProfiles[name]
+	if !ok {
+		return i18n.NewError(ctx, i18n.MsgUnknownChainProfile, name)
+	}
+	e.profile = profile
+
+	// An explicitly configured confirmation depth takes precedence over the profile, including zero
+	e.batchPinConfirmations = profile.confirmations
+	if ethconnectConf.Get(EthconnectConfigBatchPinConfirmations) != nil {
+		e.batchPinConfirmations = uint64(ethconnectConf.GetUint(EthconnectConfigBatchPinConfirmations))
+	}
+
+	if profile.eip1559 {
+		e.gasPrice = &EthconnectGasPrice{
+			MaxFeePerGas:         ethconnectConf.GetString(EthconnectConfigFeesMaxFeePerGas),
+			MaxPriorityFeePerGas: profile.maxPriorityFeePerGas,
+		}
+		if maxPriorityFeePerGas := ethconnectConf.GetString(EthconnectConfigFeesMaxPriorityFeePerGas); maxPriorityFeePerGas != "" {
+			e.gasPrice.MaxPriorityFeePerGas = maxPriorityFeePerGas
+		}
+	}
+	return nil
+}
+
+// listenerConfirmations returns the confirmation depth for the subscription of a contract listener. A listener
+// without one uses the depth of the chain profile, which is set on the listener so that it is stored, and
+// recorded on its events in the same way as a depth set on the listener.
+func (e *Ethereum) listenerConfirmations(listener *fftypes.ContractListener) uint64 {
+	if listener.Options != nil && listener.Options.Confirmations > 0 {
+		return listener.Options.Confirmations
+	}
+	if e.profile == nil || e.profile.confirmations == 0 {
+		return 0
+	}
+	if listener.Options == nil {
+		listener.Options = &fftypes.ContractListenerOptions{}
+	}
+	listener.Options.Confirmations = e.profile.confirmations
+	return listener.Options.Confirmations
+}
+
+// normalizeEventPosition converts the block number, transaction index, log index and timestamp of an event to
+// decimal strings, on chains where the L2 sequencer can deliver them as hex quantities. This is done before the
+// event is handled, so the ProtocolID, the info and the timestamp of the event are all derived from the same
+// values as on other chains.
+func (e *Ethereum) normalizeEventPosition(msgJSON fftypes.JSONObject) {
+	if e.profile == nil || !e.profile.sequencerTimestamps {
+		return
+	}
+	for _, field := range []string{"blockNumber", "transactionIndex", "logIndex", "timestamp"} {
+		if s := msgJSON.GetString(field); strings.HasPrefix(s, "0x") {
+			if i, ok := new(big.Int).SetString(s[2:], 16); ok {
+				msgJSON[field] = i.String()
+			}
+		}
+	}
+}
+
+// eventProtocolID builds the ProtocolID of an event from its position in the chain, zero padded so the
+// ProtocolIDs of events sort in the order they occurred
+func eventProtocolID(msgJSON fftypes.JSONObject) string {
+	return fmt.Sprintf("%.12d/%.6d/%.6d", msgJSON.GetInt64("blockNumber"), msgJSON.GetInt64("transactionIndex"), msgJSON.GetInt64("logIndex"))
+}
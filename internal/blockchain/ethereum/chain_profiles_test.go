@@ -0,0 +1,298 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitUnknownChainProfile(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utEthconnectConf.Set(EthconnectConfigChainProfile, "solana")
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10500.*solana", err)
+}
+
+func TestInitChainProfileGeneric(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+
+	err := e.initChainProfile(e.ctx, utEthconnectConf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), e.batchPinConfirmations)
+	assert.Nil(t, e.gasPrice)
+}
+
+func TestInitChainProfilePolygon(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utEthconnectConf.Set(EthconnectConfigChainProfile, "polygon")
+
+	err := e.initChainProfile(e.ctx, utEthconnectConf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(128), e.batchPinConfirmations)
+	assert.Equal(t, &EthconnectGasPrice{MaxPriorityFeePerGas: "30000000000"}, e.gasPrice)
+}
+
+func TestInitChainProfileOverrides(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utEthconnectConf.Set(EthconnectConfigChainProfile, "polygon")
+	utEthconnectConf.Set(EthconnectConfigBatchPinConfirmations, 0)
+	utEthconnectConf.Set(EthconnectConfigFeesMaxFeePerGas, "500000000000")
+	utEthconnectConf.Set(EthconnectConfigFeesMaxPriorityFeePerGas, "40000000000")
+
+	err := e.initChainProfile(e.ctx, utEthconnectConf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), e.batchPinConfirmations)
+	assert.Equal(t, &EthconnectGasPrice{
+		MaxFeePerGas:         "500000000000",
+		MaxPriorityFeePerGas: "40000000000",
+	}, e.gasPrice)
+}
+
+func TestSubmitBatchPinEIP1559Fees(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.gasPrice = &EthconnectGasPrice{MaxPriorityFeePerGas: "30000000000"}
+
+	batch := &blockchain.BatchPin{
+		TransactionID: fftypes.NewUUID(),
+		BatchID:       fftypes.NewUUID(),
+		BatchHash:     fftypes.NewRandB32(),
+		Contexts:      []*fftypes.Bytes32{},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, map[string]interface{}{"maxPriorityFeePerGas": "30000000000"}, body["gasPrice"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+
+	err := e.SubmitBatchPin(context.Background(), fftypes.NewUUID(), nil, "0x123", batch)
+	assert.NoError(t, err)
+}
+
+func TestHandleMessageContractEventSequencerTimestamp(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "0x947b",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {},
+		"subId": "sub2",
+		"signature": "Changed(uint256)",
+		"logIndex": "0x32",
+		"timestamp": "0x61cccb77"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: em,
+		profile:   chainProfiles["arbitrum"],
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sub1",
+	}
+
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.Event.ProtocolID == "000000038011/000000/000050" &&
+			ev.Event.Timestamp.Equal(fftypes.UnixTime(1640811383)) &&
+			ev.Event.Info.GetString("blockNumber") == "38011" &&
+			ev.Event.Info.GetString("transactionIndex") == "0" &&
+			ev.Event.Info.GetString("logIndex") == "50" &&
+			ev.Event.Info.GetString("timestamp") == "1640811383"
+	})).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageBatchPinSequencerTimestamp(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "0x947b",
+		"transactionIndex": "0x1",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"author": "0X91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"namespace": "ns1",
+			"uuids": "0xe19af8b390604051812d7597d19adfb9847d3bfd074249efb65d3fed15f5b0a6",
+			"batchHash": "0xd71eb138d74c229a388eb0e1abc03f4c7cbb21d4fc4b839fbf0ec73e4263f6be",
+			"payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+			"contexts": []
+		},
+		"subId": "sb-1",
+		"signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+		"logIndex": "0x32",
+		"timestamp": "0x61cccb77"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks:             em,
+		profile:               chainProfiles["arbitrum"],
+		batchPinConfirmations: chainProfiles["arbitrum"].confirmations,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-1",
+	}
+
+	em.On("BatchPinComplete", mock.MatchedBy(func(batch *blockchain.BatchPin) bool {
+		return batch.Event.ProtocolID == "000000038011/000001/000050" &&
+			batch.Event.Timestamp.Equal(fftypes.UnixTime(1640811383)) &&
+			batch.Event.Info.GetString("blockNumber") == "38011" &&
+			batch.Event.Info["confirmations"] == uint64(20)
+	}), mock.Anything).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestListenerConfirmationsChainProfile(t *testing.T) {
+	e := &Ethereum{
+		profile: chainProfiles["polygon"],
+	}
+
+	listener := &fftypes.ContractListener{}
+	assert.Equal(t, uint64(128), e.listenerConfirmations(listener))
+	assert.Equal(t, uint64(128), listener.Options.Confirmations)
+
+	listener = &fftypes.ContractListener{
+		Options: &fftypes.ContractListenerOptions{Confirmations: 5},
+	}
+	assert.Equal(t, uint64(5), e.listenerConfirmations(listener))
+
+	e.profile = chainProfiles[""]
+	listener = &fftypes.ContractListener{}
+	assert.Equal(t, uint64(0), e.listenerConfirmations(listener))
+	assert.Nil(t, listener.Options)
+}
+
+func TestAddContractListenerChainProfileConfirmations(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+	e.profile = chainProfiles["polygon"]
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent: string(fftypes.SubOptsFirstEventNewest),
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, float64(128), body["confirmations"])
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(128), sub.Options.Confirmations)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestHandleMessageContractEventHexTimestampGeneric(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {},
+		"subId": "sub2",
+		"signature": "Changed(uint256)",
+		"logIndex": "50",
+		"timestamp": "0x61cccb77"
+  }
+]`)
+
+	e := &Ethereum{
+		profile: chainProfiles[""],
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sub1",
+	}
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.Regexp(t, "FF10165", err)
+}
@@ -0,0 +1,105 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type ethRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type ethRPCResponse struct {
+	Result *fftypes.FFBigInt `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// queryChainID asks the node for its chain ID with the standard eth_chainId JSON/RPC method, which
+// is available behind both ethconnect and evmconnect, unlike the chain ID in the connector status
+func (e *Ethereum) queryChainID(ctx context.Context) (int64, error) {
+	var rpcRes ethRPCResponse
+	res, err := e.rpcClient.R().
+		SetContext(ctx).
+		SetBody(&ethRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "eth_chainId",
+			Params:  []interface{}{},
+		}).
+		SetResult(&rpcRes).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return 0, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	if rpcRes.Error != nil {
+		return 0, i18n.NewError(ctx, i18n.MsgChainIDUnknown, rpcRes.Error.Message)
+	}
+	if rpcRes.Result == nil {
+		return 0, i18n.NewError(ctx, i18n.MsgChainIDUnknown, "no result")
+	}
+	return rpcRes.Result.Int().Int64(), nil
+}
+
+// checkChainID verifies the node behind the connector is on the expected chain. This is called on
+// startup, and after each reconnect of the websocket, so the node stops processing events if the
+// connector is pointed at a different network. If no chain ID is configured, the one detected on
+// startup is adopted, and later reconnects are checked against it. The node is allowed to start
+// when detection fails and there is no chain ID to check against.
+func (e *Ethereum) checkChainID(ctx context.Context) error {
+	detected, err := e.queryChainID(ctx)
+	if err != nil {
+		if e.chainID == 0 {
+			log.L(ctx).Warnf("Unable to detect the chain ID of the connector: %s", err)
+			return nil
+		}
+		return err
+	}
+	if e.chainID == 0 {
+		log.L(ctx).Infof("Detected chain ID %d", detected)
+		e.chainID = detected
+		return nil
+	}
+	if detected != e.chainID {
+		return i18n.NewError(ctx, i18n.MsgChainIDMismatch, strconv.FormatInt(detected, 10), strconv.FormatInt(e.chainID, 10))
+	}
+	log.L(ctx).Debugf("Connector is on chain ID %d", e.chainID)
+	return nil
+}
+
+// ChainID returns the configured or detected chain ID, or zero if it is unknown
+func (e *Ethereum) ChainID() int64 {
+	return e.chainID
+}
+
+// addChainID records the chain ID in the protocol specific info of events and receipts, when it is known
+func (e *Ethereum) addChainID(info fftypes.JSONObject) {
+	if e.chainID != 0 {
+		info["chainId"] = e.chainID
+	}
+}
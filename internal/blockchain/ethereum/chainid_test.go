@@ -0,0 +1,242 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckChainIDDetected(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		func(req *http.Request) (*http.Response, error) {
+			var body ethRPCRequest
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "eth_chainId", body.Method)
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x539"})(req)
+		})
+
+	err := e.checkChainID(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1337), e.ChainID())
+}
+
+func TestCheckChainIDDetectFailNotConfigured(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewStringResponder(404, "Not found"))
+
+	err := e.checkChainID(e.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), e.ChainID())
+}
+
+func TestCheckChainIDOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x539"}))
+
+	err := e.checkChainID(e.ctx)
+	assert.NoError(t, err)
+}
+
+func TestCheckChainIDMismatch(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x1"}))
+
+	err := e.checkChainID(e.ctx)
+	assert.Regexp(t, "FF10498.*chain ID 1,.*1337", err)
+}
+
+func TestCheckChainIDRPCError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "error": fftypes.JSONObject{"message": "pop"}}))
+
+	err := e.checkChainID(e.ctx)
+	assert.Regexp(t, "FF10499.*pop", err)
+}
+
+func TestCheckChainIDNoResult(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1}))
+
+	err := e.checkChainID(e.ctx)
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestCheckChainIDFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewStringResponder(404, "Not found"))
+
+	err := e.checkChainID(e.ctx)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestInitChainIDMismatch(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x1"}))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, e.client.GetClient())
+	utEthconnectConf.Set(EthconnectConfigChainID, 1337)
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10498", err)
+}
+
+func TestInitChainIDSeparateRPCEndpoint(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:8545/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x1"}))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, e.client.GetClient())
+	utEthconnectConf.Set(EthconnectConfigChainID, 1337)
+	utConfPrefix.SubPrefix(RPCConfigKey).Set(restclient.HTTPConfigURL, "http://localhost:8545")
+	utConfPrefix.SubPrefix(RPCConfigKey).Set(restclient.HTTPCustomClient, e.client.GetClient())
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10498", err)
+}
+
+func TestAfterConnectChainIDChanged(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.chainID = 1337
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"jsonrpc": "2.0", "id": 1, "result": "0x5"}))
+
+	wsm := &wsmocks.WSClient{}
+	err := e.afterConnect(e.ctx, wsm)
+	assert.Regexp(t, "FF10498", err)
+	wsm.AssertExpectations(t)
+}
+
+func TestChainIDInReceiptAndEvents(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		ctx:       context.Background(),
+		callbacks: em,
+		chainID:   1337,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sub1",
+	}
+
+	operationID := fftypes.NewUUID()
+	em.On("BlockchainOpUpdate", operationID, fftypes.OpStatusSucceeded, "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8", "", mock.MatchedBy(func(output fftypes.JSONObject) bool {
+		return output["chainId"] == int64(1337)
+	})).Return(nil)
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.Event.Info["chainId"] == int64(1337)
+	})).Return(nil)
+
+	var reply fftypes.JSONObject
+	err := json.Unmarshal([]byte(`{
+		"headers": {
+			"requestId": "`+operationID.String()+`",
+			"type": "TransactionSuccess"
+		},
+		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8"
+	}`), &reply)
+	assert.NoError(t, err)
+	err = e.handleReceipt(context.Background(), reply)
+	assert.NoError(t, err)
+
+	var events []interface{}
+	err = json.Unmarshal([]byte(`[{
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {},
+		"subId": "sub2",
+		"signature": "Changed(uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383"
+	}]`), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
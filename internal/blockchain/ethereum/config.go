@@ -32,11 +32,28 @@ const (
 	defaultAddressResolverResponseField = "address"
 	defaultAddressResolverCacheSize     = 1000
 	defaultAddressResolverCacheTTL      = "24h"
+
+	defaultEventStreamErrorHandling = "block"
+
+	defaultPinAccessCacheSize = 100
+	defaultPinAccessCacheTTL  = "30s"
+
+	defaultConnectorAPI = connectorAPIEthconnect
 )
 
 const (
 	// EthconnectConfigKey is a sub-key in the config to contain all the ethconnect specific config,
 	EthconnectConfigKey = "ethconnect"
+	// EthconnectConfigConnectorAPI selects the REST API of the connector - "ethconnect" for the legacy ethconnect API, "evmconnect" for the EVM connector API, or "auto" to detect it on startup
+	EthconnectConfigConnectorAPI = "connectorAPI"
+	// EthconnectConfigChainID is the chain ID the node must report on startup and on each reconnect, to protect against pointing a node at the wrong network (detected on startup if not set)
+	EthconnectConfigChainID = "chainId"
+	// EthconnectConfigChainProfile adapts the finality, fees and event timestamps to a particular chain - "polygon", "arbitrum", or empty for a generic EVM chain
+	EthconnectConfigChainProfile = "chainProfile"
+	// EthconnectConfigFeesMaxFeePerGas is the EIP-1559 maximum fee per gas to set on transactions, on chains where the profile uses EIP-1559 fees
+	EthconnectConfigFeesMaxFeePerGas = "fees.maxFeePerGas"
+	// EthconnectConfigFeesMaxPriorityFeePerGas is the EIP-1559 priority fee per gas to set on transactions, overriding the default of the chain profile
+	EthconnectConfigFeesMaxPriorityFeePerGas = "fees.maxPriorityFeePerGas"
 	// EthconnectConfigInstancePath is the ethereum address of the contract
 	EthconnectConfigInstancePath = "instance"
 	// EthconnectConfigTopic is the websocket listen topic that the node should register on, which is important if there are multiple
@@ -50,6 +67,30 @@ const (
 	EthconnectPrefixShort = "prefixShort"
 	// EthconnectPrefixLong is used in HTTP headers in requests to ethconnect
 	EthconnectPrefixLong = "prefixLong"
+	// EthconnectConfigBatchPinConfirmations is the number of blocks deep a BatchPin event must be before the connector delivers it, on the subscription created for BatchPin events (defaults to the finality depth of the chain profile)
+	EthconnectConfigBatchPinConfirmations = "batchPinConfirmations"
+	// EthconnectConfigPinAccessCacheTTL is how long the registration of a key with a permissioned batch pin contract is cached for, before it is queried again
+	EthconnectConfigPinAccessCacheTTL = "pinAccess.cacheTTL"
+	// EthconnectConfigEventStreams is an array of additional event streams, each with their own websocket topic, that contract listeners can be assigned to by name
+	EthconnectConfigEventStreams = "eventStreams"
+
+	// EventStreamConfigName is the name contract listeners use to select the event stream
+	EventStreamConfigName = "name"
+	// EventStreamConfigTopic is the websocket listen topic of the event stream, which must be unique
+	EventStreamConfigTopic = "topic"
+	// EventStreamConfigBatchSize is the batch size to configure on the event stream
+	EventStreamConfigBatchSize = "batchSize"
+	// EventStreamConfigBatchTimeout is the batch timeout to configure on the event stream
+	EventStreamConfigBatchTimeout = "batchTimeout"
+	// EventStreamConfigErrorHandling is the action ethconnect takes when delivery fails - "block" to retry indefinitely, or "skip" to move on after the retry timeout
+	EventStreamConfigErrorHandling = "errorHandling"
+	// EventStreamConfigRetryTimeout is how long ethconnect retries delivery of a batch before applying the error handling
+	EventStreamConfigRetryTimeout = "retryTimeout"
+	// EventStreamConfigBlockedRetryDelay is how long ethconnect waits between retries while blocked
+	EventStreamConfigBlockedRetryDelay = "blockedRetryDelay"
+
+	// RPCConfigKey is a sub-key in the config for the JSON/RPC endpoint used to query the chain ID, which defaults to the ethconnect URL
+	RPCConfigKey = "rpc"
 
 	// AddressResolverConfigKey is a sub-key in the config to contain an address resolver config.
 	AddressResolverConfigKey = "addressResolver"
@@ -72,12 +113,23 @@ const (
 func (e *Ethereum) InitPrefix(prefix config.Prefix) {
 	ethconnectConf := prefix.SubPrefix(EthconnectConfigKey)
 	wsconfig.InitPrefix(ethconnectConf)
+	ethconnectConf.AddKnownKey(EthconnectConfigConnectorAPI, defaultConnectorAPI)
+	ethconnectConf.AddKnownKey(EthconnectConfigChainID, 0)
 	ethconnectConf.AddKnownKey(EthconnectConfigInstancePath)
 	ethconnectConf.AddKnownKey(EthconnectConfigTopic)
 	ethconnectConf.AddKnownKey(EthconnectConfigBatchSize, defaultBatchSize)
 	ethconnectConf.AddKnownKey(EthconnectConfigBatchTimeout, defaultBatchTimeout)
 	ethconnectConf.AddKnownKey(EthconnectPrefixShort, defaultPrefixShort)
 	ethconnectConf.AddKnownKey(EthconnectPrefixLong, defaultPrefixLong)
+	ethconnectConf.AddKnownKey(EthconnectConfigBatchPinConfirmations)
+	ethconnectConf.AddKnownKey(EthconnectConfigChainProfile)
+	ethconnectConf.AddKnownKey(EthconnectConfigFeesMaxFeePerGas)
+	ethconnectConf.AddKnownKey(EthconnectConfigFeesMaxPriorityFeePerGas)
+	ethconnectConf.AddKnownKey(EthconnectConfigPinAccessCacheTTL, defaultPinAccessCacheTTL)
+
+	eventStreamsPrefix(ethconnectConf)
+
+	restclient.InitPrefix(prefix.SubPrefix(RPCConfigKey))
 
 	addressResolverConf := prefix.SubPrefix(AddressResolverConfigKey)
 	restclient.InitPrefix(addressResolverConf)
@@ -89,3 +141,16 @@ func (e *Ethereum) InitPrefix(prefix config.Prefix) {
 	addressResolverConf.AddKnownKey(AddressResolverCacheSize, defaultAddressResolverCacheSize)
 	addressResolverConf.AddKnownKey(AddressResolverCacheTTL, defaultAddressResolverCacheTTL)
 }
+
+// eventStreamsPrefix returns the array of additional event streams, with the defaults for each entry
+func eventStreamsPrefix(ethconnectConf config.Prefix) config.PrefixArray {
+	eventStreamsConf := ethconnectConf.SubPrefix(EthconnectConfigEventStreams).Array()
+	eventStreamsConf.AddKnownKey(EventStreamConfigName)
+	eventStreamsConf.AddKnownKey(EventStreamConfigTopic)
+	eventStreamsConf.AddKnownKey(EventStreamConfigBatchSize, defaultBatchSize)
+	eventStreamsConf.AddKnownKey(EventStreamConfigBatchTimeout, defaultBatchTimeout)
+	eventStreamsConf.AddKnownKey(EventStreamConfigErrorHandling, defaultEventStreamErrorHandling)
+	eventStreamsConf.AddKnownKey(EventStreamConfigRetryTimeout)
+	eventStreamsConf.AddKnownKey(EventStreamConfigBlockedRetryDelay)
+	return eventStreamsConf
+}
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
@@ -34,6 +36,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/karlseguin/ccache"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -55,6 +58,7 @@ type Ethereum struct {
 	capabilities *blockchain.Capabilities
 	callbacks    blockchain.Callbacks
 	client       *resty.Client
+	rpcClient    *resty.Client
 	streams      *streamManager
 	initInfo     struct {
 		stream *eventStream
@@ -62,8 +66,20 @@ type Ethereum struct {
 	}
 	wsconn          wsclient.WSClient
 	closed          chan struct{}
+	listenerStreams []*listenerStream
 	addressResolver *addressResolver
 	metrics         metrics.Manager
+	pinAccessMux    sync.Mutex
+	permissioned    *bool
+
+	pinAccessCache    *ccache.Cache
+	pinAccessCacheTTL time.Duration
+
+	batchPinConfirmations uint64
+	evmconnect            bool
+	chainID               int64
+	profile               *chainProfile
+	gasPrice              *EthconnectGasPrice
 }
 
 type eventStreamWebsocket struct {
@@ -124,11 +140,32 @@ type ABIElementMarshaling struct {
 }
 
 type EthconnectMessageRequest struct {
-	Headers EthconnectMessageHeaders `json:"headers,omitempty"`
-	To      string                   `json:"to"`
-	From    string                   `json:"from,omitempty"`
-	Method  ABIElementMarshaling     `json:"method"`
-	Params  []interface{}            `json:"params"`
+	Headers  EthconnectMessageHeaders `json:"headers,omitempty"`
+	To       string                   `json:"to"`
+	From     string                   `json:"from,omitempty"`
+	Method   ABIElementMarshaling     `json:"method"`
+	Params   []interface{}            `json:"params"`
+	GasPrice *EthconnectGasPrice      `json:"gasPrice,omitempty"`
+}
+
+type EthconnectDeployRequest struct {
+	Headers  EthconnectMessageHeaders `json:"headers,omitempty"`
+	From     string                   `json:"from,omitempty"`
+	Compiled []byte                   `json:"compiled"`
+	ABI      interface{}              `json:"abi"`
+	Params   []interface{}            `json:"params"`
+	GasPrice *EthconnectGasPrice      `json:"gasPrice,omitempty"`
+}
+
+// EVMConnectDeployRequest is the EVM connector equivalent of EthconnectDeployRequest, which takes
+// the bytecode as a hex string and the ABI as the contract definition
+type EVMConnectDeployRequest struct {
+	Headers    EthconnectMessageHeaders `json:"headers,omitempty"`
+	From       string                   `json:"from,omitempty"`
+	Contract   string                   `json:"contract"`
+	Definition interface{}              `json:"definition"`
+	Params     []interface{}            `json:"params"`
+	GasPrice   *EthconnectGasPrice      `json:"gasPrice,omitempty"`
 }
 
 type EthconnectMessageHeaders struct {
@@ -154,7 +191,7 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	ethconnectConf := prefix.SubPrefix(EthconnectConfigKey)
 	addressResolverConf := prefix.SubPrefix(AddressResolverConfigKey)
 
-	e.ctx = log.WithLogField(ctx, "proto", "ethereum")
+	e.ctx = log.WithComponent(log.WithLogField(ctx, "proto", "ethereum"), log.ComponentEthereum)
 	e.callbacks = callbacks
 	e.metrics = metrics
 
@@ -169,10 +206,25 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	}
 
 	e.client = restclient.New(e.ctx, ethconnectConf)
+	e.rpcClient = e.client
+	if rpcConf := prefix.SubPrefix(RPCConfigKey); rpcConf.GetString(restclient.HTTPConfigURL) != "" {
+		e.rpcClient = restclient.New(e.ctx, rpcConf)
+	}
+	if e.evmconnect, err = useEVMConnect(e.ctx, e.client, ethconnectConf.GetString(EthconnectConfigConnectorAPI)); err != nil {
+		return err
+	}
+	e.chainID = ethconnectConf.GetInt64(EthconnectConfigChainID)
+	if err = e.checkChainID(e.ctx); err != nil {
+		return err
+	}
 	e.capabilities = &blockchain.Capabilities{
-		GlobalSequencer: true,
+		GlobalSequencer:   true,
+		ConfirmationDepth: true,
 	}
 
+	e.pinAccessCache = ccache.New(ccache.Configure().MaxSize(defaultPinAccessCacheSize))
+	e.pinAccessCacheTTL = ethconnectConf.GetDuration(EthconnectConfigPinAccessCacheTTL)
+
 	e.instancePath = ethconnectConf.GetString(EthconnectConfigInstancePath)
 	if e.instancePath == "" {
 		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "instance", "blockchain.ethconnect")
@@ -202,6 +254,10 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	e.prefixShort = ethconnectConf.GetString(EthconnectPrefixShort)
 	e.prefixLong = ethconnectConf.GetString(EthconnectPrefixLong)
 
+	if err = e.initChainProfile(ctx, ethconnectConf); err != nil {
+		return err
+	}
+
 	wsConfig := wsconfig.GenerateConfigFromPrefix(ethconnectConf)
 
 	if wsConfig.WSKeyPath == "" {
@@ -214,33 +270,72 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	}
 
 	e.streams = &streamManager{
-		client: e.client,
+		client:     e.client,
+		evmconnect: e.evmconnect,
 	}
 	batchSize := ethconnectConf.GetUint(EthconnectConfigBatchSize)
 	batchTimeout := uint(ethconnectConf.GetDuration(EthconnectConfigBatchTimeout).Milliseconds())
-	if e.initInfo.stream, err = e.streams.ensureEventStream(e.ctx, e.topic, batchSize, batchTimeout); err != nil {
+	mainStreamOptions := &eventStreamOptions{
+		topic:         e.topic,
+		errorHandling: "block",
+		batchSize:     batchSize,
+		batchTimeout:  batchTimeout,
+	}
+	if e.initInfo.stream, err = e.streams.ensureEventStream(e.ctx, mainStreamOptions); err != nil {
 		return err
 	}
 	log.L(e.ctx).Infof("Event stream: %s (topic=%s)", e.initInfo.stream.ID, e.topic)
-	if e.initInfo.sub, err = e.streams.ensureSubscription(e.ctx, e.instancePath, e.initInfo.stream.ID, batchPinEventABI); err != nil {
+	if e.initInfo.sub, err = e.streams.ensureSubscription(e.ctx, e.instancePath, e.initInfo.stream.ID, e.batchPinConfirmations, batchPinEventABI); err != nil {
+		return err
+	}
+	if err = e.initListenerStreams(ctx, ethconnectConf, wsConfig); err != nil {
 		return err
 	}
 
 	e.closed = make(chan struct{})
 	go e.eventLoop()
+	for _, ls := range e.listenerStreams {
+		ls.closed = make(chan struct{})
+		go e.streamEventLoop(ls.wsconn, ls.options.topic, ls.closed)
+	}
 
 	return nil
 }
 
 func (e *Ethereum) Start() error {
-	return e.wsconn.Connect()
+	if err := e.wsconn.Connect(); err != nil {
+		return err
+	}
+	for _, ls := range e.listenerStreams {
+		if err := ls.wsconn.Connect(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (e *Ethereum) Capabilities() *blockchain.Capabilities {
 	return e.capabilities
 }
 
+func (e *Ethereum) Health(ctx context.Context) error {
+	if state := e.wsconn.State(); state != wsclient.WSStateConnected {
+		return i18n.NewError(ctx, i18n.MsgPluginNotConnected, e.Name(), state)
+	}
+	for _, ls := range e.listenerStreams {
+		if state := ls.wsconn.State(); state != wsclient.WSStateConnected {
+			return i18n.NewError(ctx, i18n.MsgPluginNotConnected, e.Name()+"/"+ls.name, state)
+		}
+	}
+	return nil
+}
+
 func (e *Ethereum) afterConnect(ctx context.Context, w wsclient.WSClient) error {
+	// The connector might have been pointed at a different chain while we were disconnected
+	if err := e.checkChainID(ctx); err != nil {
+		return err
+	}
+
 	// Send a subscribe to our topic after each connect/reconnect
 	b, _ := json.Marshal(&ethWSCommandPayload{
 		Type:  "listen",
@@ -266,9 +361,6 @@ func ethHexFormatB32(b *fftypes.Bytes32) string {
 func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSONObject) (err error) {
 	sBlockNumber := msgJSON.GetString("blockNumber")
 	sTransactionHash := msgJSON.GetString("transactionHash")
-	blockNumber := msgJSON.GetInt64("blockNumber")
-	txIndex := msgJSON.GetInt64("transactionIndex")
-	logIndex := msgJSON.GetInt64("logIndex")
 	dataJSON := msgJSON.GetObject("data")
 	authorAddress := dataJSON.GetString("author")
 	ns := dataJSON.GetString("namespace")
@@ -276,8 +368,7 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 	sBatchHash := dataJSON.GetString("batchHash")
 	sPayloadRef := dataJSON.GetString("payloadRef")
 	sContexts := dataJSON.GetStringArray("contexts")
-	timestampStr := msgJSON.GetString("timestamp")
-	timestamp, err := fftypes.ParseTimeString(timestampStr)
+	timestamp, err := fftypes.ParseTimeString(msgJSON.GetString("timestamp"))
 	if err != nil {
 		log.L(ctx).Errorf("BatchPin event is not valid - missing timestamp: %+v", msgJSON)
 		return nil // move on
@@ -327,6 +418,10 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 	}
 
 	delete(msgJSON, "data")
+	if e.batchPinConfirmations > 0 {
+		msgJSON["confirmations"] = e.batchPinConfirmations
+	}
+	e.addChainID(msgJSON)
 	batch := &blockchain.BatchPin{
 		Namespace:       ns,
 		TransactionID:   &txnID,
@@ -338,7 +433,7 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 			BlockchainTXID: sTransactionHash,
 			Source:         e.Name(),
 			Name:           "BatchPin",
-			ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", blockNumber, txIndex, logIndex),
+			ProtocolID:     eventProtocolID(msgJSON),
 			Output:         dataJSON,
 			Info:           msgJSON,
 			Timestamp:      timestamp,
@@ -347,6 +442,14 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 		},
 	}
 
+	// The connector redelivers an event with "removed" set, if the block containing it is orphaned by a
+	// chain reorganization. The state derived from a confirmed batch cannot be unwound, so the only protection
+	// is a confirmation depth that holds events back until they are final - all we can do here is report it.
+	if msgJSON.GetBool("removed") {
+		log.L(ctx).Errorf("BatchPin event for batch %s removed from block %s (blockHash=%s) by chain reorganization, after it was processed. Configure a deeper batchPinConfirmations", batchID, sBlockNumber, msgJSON.GetString("blockHash"))
+		return nil // move on
+	}
+
 	// If there's an error dispatching the event, we must return the error and shutdown
 	return e.callbacks.BatchPinComplete(batch, &fftypes.VerifierRef{
 		Type:  fftypes.VerifierTypeEthAddress,
@@ -356,20 +459,17 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 
 func (e *Ethereum) handleContractEvent(ctx context.Context, msgJSON fftypes.JSONObject) (err error) {
 	sTransactionHash := msgJSON.GetString("transactionHash")
-	blockNumber := msgJSON.GetInt64("blockNumber")
-	txIndex := msgJSON.GetInt64("transactionIndex")
-	logIndex := msgJSON.GetInt64("logIndex")
-	sub := msgJSON.GetString("subId")
+	sub := eventSubscriptionID(msgJSON)
 	signature := msgJSON.GetString("signature")
 	dataJSON := msgJSON.GetObject("data")
 	name := strings.SplitN(signature, "(", 2)[0]
-	timestampStr := msgJSON.GetString("timestamp")
-	timestamp, err := fftypes.ParseTimeString(timestampStr)
+	timestamp, err := fftypes.ParseTimeString(msgJSON.GetString("timestamp"))
 	if err != nil {
 		log.L(ctx).Errorf("Contract event is not valid - missing timestamp: %+v", msgJSON)
 		return err // move on
 	}
 	delete(msgJSON, "data")
+	e.addChainID(msgJSON)
 
 	event := &blockchain.EventWithSubscription{
 		Subscription: sub,
@@ -377,7 +477,7 @@ func (e *Ethereum) handleContractEvent(ctx context.Context, msgJSON fftypes.JSON
 			BlockchainTXID: sTransactionHash,
 			Source:         e.Name(),
 			Name:           name,
-			ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", blockNumber, txIndex, logIndex),
+			ProtocolID:     eventProtocolID(msgJSON),
 			Output:         dataJSON,
 			Info:           msgJSON,
 			Timestamp:      timestamp,
@@ -406,14 +506,31 @@ func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject)
 		l.Errorf("Reply cannot be processed - bad ID: %+v", reply)
 		return nil // Swallow this and move on
 	}
+	l = log.L(log.WithOperation(ctx, "", "", requestID))
 	updateType := fftypes.OpStatusSucceeded
 	if replyType != "TransactionSuccess" {
 		updateType = fftypes.OpStatusFailed
 	}
+	if updateType == fftypes.OpStatusFailed {
+		// Custom errors are decoded by the core once the operation is updated, as only it knows the errors of the method
+		if reason, ok := decodeRevertReason(ctx, extractRevertData(reply), nil); ok {
+			l.Debugf("Decoded revert reason for request=%s: %s", requestID, reason)
+			message = reason
+		}
+	}
 	l.Infof("Ethconnect '%s' reply: request=%s tx=%s message=%s", replyType, requestID, txHash, message)
+	e.addChainID(reply)
 	return e.callbacks.BlockchainOpUpdate(operationID, updateType, txHash, message, reply)
 }
 
+// eventSubscriptionID returns the subscription an event was delivered for, which the EVM connector calls the listener
+func eventSubscriptionID(msgJSON fftypes.JSONObject) string {
+	if sub := msgJSON.GetString("subId"); sub != "" {
+		return sub
+	}
+	return msgJSON.GetString("listenerId")
+}
+
 func (e *Ethereum) buildEventLocationString(msgJSON fftypes.JSONObject) string {
 	return fmt.Sprintf("address=%s", msgJSON.GetString("address"))
 }
@@ -428,11 +545,12 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{
 			return nil // Swallow this and move on
 		}
 		msgJSON := fftypes.JSONObject(msgMap)
+		e.normalizeEventPosition(msgJSON)
 
 		l1 := l.WithField("ethmsgidx", i)
 		ctx1 := log.WithLogger(ctx, l1)
 		signature := msgJSON.GetString("signature")
-		sub := msgJSON.GetString("subId")
+		sub := eventSubscriptionID(msgJSON)
 		l1.Infof("Received '%s' message", signature)
 		l1.Tracef("Message: %+v", msgJSON)
 
@@ -454,17 +572,21 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{
 }
 
 func (e *Ethereum) eventLoop() {
-	defer e.wsconn.Close()
-	defer close(e.closed)
-	l := log.L(e.ctx).WithField("role", "event-loop")
+	e.streamEventLoop(e.wsconn, e.topic, e.closed)
+}
+
+func (e *Ethereum) streamEventLoop(wsconn wsclient.WSClient, topic string, closed chan struct{}) {
+	defer wsconn.Close()
+	defer close(closed)
+	l := log.L(e.ctx).WithField("role", "event-loop").WithField("topic", topic)
 	ctx := log.WithLogger(e.ctx, l)
-	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": e.topic})
+	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": topic})
 	for {
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
-		case msgBytes, ok := <-e.wsconn.Receive():
+		case msgBytes, ok := <-wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
 				return
@@ -480,10 +602,20 @@ func (e *Ethereum) eventLoop() {
 			case []interface{}:
 				err = e.handleMessageBatch(ctx, msgTyped)
 				if err == nil {
-					err = e.wsconn.Send(ctx, ack)
+					err = wsconn.Send(ctx, ack)
 				}
 			case map[string]interface{}:
-				err = e.handleReceipt(ctx, fftypes.JSONObject(msgTyped))
+				msgJSON := fftypes.JSONObject(msgTyped)
+				if events, isBatch := msgJSON["events"].([]interface{}); isBatch {
+					// The EVM connector wraps each batch of events, and the batch number must be included in the ack
+					err = e.handleMessageBatch(ctx, events)
+					if err == nil {
+						batchAck, _ := json.Marshal(map[string]interface{}{"type": "ack", "topic": topic, "batchNumber": msgJSON.GetInt64("batchNumber")})
+						err = wsconn.Send(ctx, batchAck)
+					}
+				} else {
+					err = e.handleReceipt(ctx, msgJSON)
+				}
 			default:
 				l.Errorf("Message unexpected: %+v", msgTyped)
 				continue
@@ -528,10 +660,11 @@ func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey
 			Type: "SendTransaction",
 			ID:   requestID,
 		},
-		From:   signingKey,
-		To:     address,
-		Method: abi,
-		Params: input,
+		From:     signingKey,
+		To:       address,
+		Method:   abi,
+		Params:   input,
+		GasPrice: e.gasPrice,
 	}
 	return e.client.R().
 		SetContext(ctx).
@@ -579,7 +712,7 @@ func (e *Ethereum) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID
 	return nil
 }
 
-func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
+func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return err
@@ -595,6 +728,67 @@ func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID
 	return nil
 }
 
+func (e *Ethereum) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error {
+	compiled, err := parseBytecode(ctx, contract)
+	if err != nil {
+		return err
+	}
+	var abi interface{} = definition
+	if definition == nil {
+		// The constructor is the only part of the ABI ethconnect needs to encode the deployment
+		constructorABI, err := e.FFIMethodToABI(ctx, constructor)
+		if err != nil {
+			return err
+		}
+		constructorABI.Type = "constructor"
+		constructorABI.Name = ""
+		constructorABI.Outputs = []ABIArgumentMarshaling{}
+		abi = []ABIElementMarshaling{constructorABI}
+	}
+	headers := EthconnectMessageHeaders{
+		Type: "DeployContract",
+		ID:   operationID.String(),
+	}
+	var body interface{} = &EthconnectDeployRequest{
+		Headers:  headers,
+		From:     signingKey,
+		Compiled: compiled,
+		ABI:      abi,
+		Params:   input,
+		GasPrice: e.gasPrice,
+	}
+	if e.evmconnect {
+		body = &EVMConnectDeployRequest{
+			Headers:    headers,
+			From:       signingKey,
+			Contract:   "0x" + hex.EncodeToString(compiled),
+			Definition: abi,
+			Params:     input,
+			GasPrice:   e.gasPrice,
+		}
+	}
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetBody(body).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	return nil
+}
+
+func parseBytecode(ctx context.Context, contract *fftypes.JSONAny) ([]byte, error) {
+	var bytecode string
+	if err := json.Unmarshal(contract.Bytes(), &bytecode); err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgContractDeployInvalid, "must be a hex encoded string")
+	}
+	compiled, err := hex.DecodeString(strings.TrimPrefix(bytecode, "0x"))
+	if err != nil || len(compiled) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgContractDeployInvalid, "must be a hex encoded string")
+	}
+	return compiled, nil
+}
+
 func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
@@ -631,7 +825,7 @@ func parseContractLocation(ctx context.Context, location *fftypes.JSONAny) (*Loc
 	return &ethLocation, nil
 }
 
-func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.ContractListenerInput) error {
+func (e *Ethereum) createListenerSubscription(ctx context.Context, listener *fftypes.ContractListener, firstEvent string) error {
 	location, err := parseContractLocation(ctx, listener.Location)
 	if err != nil {
 		return err
@@ -641,8 +835,14 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.Co
 		return i18n.WrapError(ctx, err, i18n.MsgContractParamInvalid)
 	}
 
+	streamID, err := e.listenerStreamID(ctx, listener.Options)
+	if err != nil {
+		return err
+	}
+
+	confirmations := e.listenerConfirmations(listener)
 	subName := fmt.Sprintf("ff-sub-%s", listener.ID)
-	result, err := e.streams.createSubscription(ctx, location, e.initInfo.stream.ID, subName, listener.Options.FirstEvent, abi)
+	result, err := e.streams.createSubscription(ctx, location, streamID, subName, firstEvent, confirmations, abi)
 	if err != nil {
 		return err
 	}
@@ -650,8 +850,43 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.Co
 	return nil
 }
 
+func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.ContractListenerInput) error {
+	var firstEvent string
+	if listener.Options != nil {
+		firstEvent = listener.Options.FirstEvent
+	}
+	return e.createListenerSubscription(ctx, &listener.ContractListener, firstEvent)
+}
+
 func (e *Ethereum) DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
-	return e.streams.deleteSubscription(ctx, subscription.ProtocolID)
+	var streamID string
+	if e.evmconnect {
+		// Listeners are addressed through their event stream on the EVM connector
+		var err error
+		if streamID, err = e.listenerStreamID(ctx, subscription.Options); err != nil {
+			return err
+		}
+	}
+	return e.streams.deleteSubscription(ctx, streamID, subscription.ProtocolID)
+}
+
+// PauseContractListener deletes the subscription of the listener, as the connector can only suspend a whole event stream,
+// which is shared with other listeners. The subscription is recreated from the listener checkpoint when it is resumed.
+func (e *Ethereum) PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	return e.DeleteContractListener(ctx, subscription)
+}
+
+// ResumeContractListener recreates the subscription of a paused listener, starting from the block of its checkpoint if
+// it has one, and sets the new protocol ID on the listener
+func (e *Ethereum) ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	var firstEvent string
+	if subscription.Options != nil {
+		firstEvent = subscription.Options.FirstEvent
+		if subscription.Options.Checkpoint != nil && subscription.Options.Checkpoint.FirstEvent != "" {
+			firstEvent = subscription.Options.Checkpoint.FirstEvent
+		}
+	}
+	return e.createListenerSubscription(ctx, subscription, firstEvent)
 }
 
 func (e *Ethereum) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
@@ -732,8 +967,7 @@ func buildABIArgumentArray(properties map[string]*jsonschema.Schema) []ABIArgume
 }
 
 func getParamDetails(schema *jsonschema.Schema) *paramDetails {
-	ext := schema.Extensions["details"]
-	details := ext.(detailsSchema)
+	details := schema.Extensions["details"].(detailsExtension).getDetails()
 	blockchainType := details["type"].(string)
 	paramDetails := &paramDetails{
 		Type: blockchainType,
@@ -759,7 +993,9 @@ func (e *Ethereum) prepareRequest(ctx context.Context, method *fftypes.FFIMethod
 		return abi, orderedInput, err
 	}
 	for i, ffiParam := range method.Params {
-		orderedInput[i] = input[ffiParam.Name]
+		if orderedInput[i], err = scaleFixedPoint(ffiParam.Schema.JSONObjectNowarn(), input[ffiParam.Name]); err != nil {
+			return abi, orderedInput, i18n.WrapError(ctx, err, i18n.MsgFFIValidationFail, ffiParam.Name)
+		}
 	}
 	return abi, orderedInput, nil
 }
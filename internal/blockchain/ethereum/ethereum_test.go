@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
@@ -35,6 +36,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/wsclient"
 	"github.com/jarcoal/httpmock"
+	"github.com/karlseguin/ccache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -90,6 +92,9 @@ func newTestEthereum() (*Ethereum, func()) {
 		wsconn:       wsm,
 		metrics:      mm,
 	}
+	e.rpcClient = e.client
+	e.pinAccessCache = ccache.New(ccache.Configure().MaxSize(defaultPinAccessCacheSize))
+	e.pinAccessCacheTTL = time.Minute
 	return e, func() {
 		cancel()
 		if e.closed != nil {
@@ -265,6 +270,81 @@ func TestInitAllExistingStreams(t *testing.T) {
 
 }
 
+func TestInitExistingSubscriptionConfirmationsChanged(t *testing.T) {
+
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{{ID: "es12345", WebSocket: eventStreamWebsocket{Topic: "topic1"}}}))
+	httpmock.RegisterResponder("PATCH", "http://localhost:12345/eventstreams/es12345",
+		httpmock.NewJsonResponderOrPanic(200, &eventStream{ID: "es12345", WebSocket: eventStreamWebsocket{Topic: "topic1"}}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{
+			{ID: "sub12345", Stream: "es12345", Name: "BatchPin_30783132333435e3", Confirmations: 5},
+		}))
+	httpmock.RegisterResponder("DELETE", "http://localhost:12345/subscriptions/sub12345",
+		httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/subscriptions",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "BatchPin_30783132333435e3", body["name"])
+			assert.Equal(t, "0", body["fromBlock"])
+			assert.Equal(t, float64(10), body["confirmations"])
+			return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub67890"})(req)
+		})
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utEthconnectConf.Set(EthconnectConfigBatchPinConfirmations, 10)
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["DELETE http://localhost:12345/subscriptions/sub12345"])
+	assert.Equal(t, "sub67890", e.initInfo.sub.ID)
+}
+
+func TestInitExistingSubscriptionConfirmationsChangedDeleteFail(t *testing.T) {
+
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{{ID: "es12345", WebSocket: eventStreamWebsocket{Topic: "topic1"}}}))
+	httpmock.RegisterResponder("PATCH", "http://localhost:12345/eventstreams/es12345",
+		httpmock.NewJsonResponderOrPanic(200, &eventStream{ID: "es12345", WebSocket: eventStreamWebsocket{Topic: "topic1"}}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{
+			{ID: "sub12345", Stream: "es12345", Name: "BatchPin_30783132333435e3", Confirmations: 5},
+		}))
+	httpmock.RegisterResponder("DELETE", "http://localhost:12345/subscriptions/sub12345",
+		httpmock.NewStringResponder(500, "pop"))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPConfigRetryEnabled, false)
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utEthconnectConf.Set(EthconnectConfigBatchPinConfirmations, 10)
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
 func TestInitOldInstancePathContracts(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -768,6 +848,97 @@ func TestHandleMessageBatchPinOK(t *testing.T) {
 
 }
 
+func TestHandleMessageBatchPinRemoved(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"blockHash": "0x0de7e0b4ae2e5c2a0f8b0a4dc4a3d1c45a5d4b6e38d8e1b1c1c3c2a2b6e7f8a9",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"removed": true,
+		"data": {
+			"author": "0X91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"namespace": "ns1",
+			"uuids": "0xe19af8b390604051812d7597d19adfb9847d3bfd074249efb65d3fed15f5b0a6",
+			"batchHash": "0xd71eb138d74c229a388eb0e1abc03f4c7cbb21d4fc4b839fbf0ec73e4263f6be",
+			"payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+			"contexts": [
+				"0x68e4da79f805bca5b912bcda9c63d03e6e867108dabb9b944109aea541ef522a"
+			]
+    },
+		"subId": "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+		"signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+		"logIndex": "50",
+		"timestamp": "1620576488"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: em,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+	}
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageBatchPinConfirmations(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"author": "0X91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"namespace": "ns1",
+			"uuids": "0xe19af8b390604051812d7597d19adfb9847d3bfd074249efb65d3fed15f5b0a6",
+			"batchHash": "0xd71eb138d74c229a388eb0e1abc03f4c7cbb21d4fc4b839fbf0ec73e4263f6be",
+			"payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+			"contexts": [
+				"0x68e4da79f805bca5b912bcda9c63d03e6e867108dabb9b944109aea541ef522a"
+			]
+    },
+		"subId": "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+		"signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+		"logIndex": "50",
+		"timestamp": "1620576488"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks:             em,
+		batchPinConfirmations: 12,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+	}
+
+	em.On("BatchPinComplete", mock.MatchedBy(func(b *blockchain.BatchPin) bool {
+		return b.Event.Info["confirmations"] == uint64(12)
+	}), mock.Anything).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleMessageEmptyPayloadRef(t *testing.T) {
 	data := fftypes.JSONAnyPtr(`
 [
@@ -1274,6 +1445,50 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionConfirmations(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent:    string(fftypes.SubOptsFirstEventNewest),
+				Confirmations: 12,
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(12), body.Confirmations)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", sub.ProtocolID)
+}
+
 func TestAddSubscriptionBadParamDetails(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1397,6 +1612,164 @@ func TestDeleteSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPauseSubscription(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/sb-1`,
+		httpmock.NewStringResponder(204, ""))
+
+	err := e.PauseContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestPauseSubscriptionFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/sb-1`,
+		httpmock.NewStringResponder(500, ""))
+
+	err := e.PauseContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10111", err)
+}
+
+func testPausedListener() *fftypes.ContractListener {
+	return &fftypes.ContractListener{
+		ID:         fftypes.NewUUID(),
+		ProtocolID: "sb-1",
+		Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+			"address": "0x123",
+		}.String()),
+		Event: &fftypes.FFISerializedEvent{
+			FFIEventDefinition: fftypes.FFIEventDefinition{
+				Name:   "Changed",
+				Params: fftypes.FFIParams{},
+			},
+		},
+		Options: &fftypes.ContractListenerOptions{
+			FirstEvent:    string(fftypes.SubOptsFirstEventNewest),
+			Confirmations: 5,
+		},
+	}
+}
+
+func TestResumeSubscriptionFromCheckpoint(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := testPausedListener()
+	sub.Options.Checkpoint = &fftypes.ContractListenerCheckpoint{
+		FirstEvent: "1234",
+		ProtocolID: "000000001234/000002/000001",
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "1234", body.FromBlock)
+			assert.Equal(t, "es-1", body.Stream)
+			assert.Equal(t, uint64(5), body.Confirmations)
+			assert.Equal(t, "ff-sub-"+sub.ID.String(), body.Name)
+			body.ID = "sb-2"
+			return httpmock.NewJsonResponderOrPanic(200, &body)(req)
+		})
+
+	err := e.ResumeContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sb-2", sub.ProtocolID)
+}
+
+func TestResumeSubscriptionNoCheckpoint(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := testPausedListener()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "latest", body.FromBlock)
+			body.ID = "sb-2"
+			return httpmock.NewJsonResponderOrPanic(200, &body)(req)
+		})
+
+	err := e.ResumeContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sb-2", sub.ProtocolID)
+}
+
+func TestResumeSubscriptionFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := testPausedListener()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		httpmock.NewStringResponder(500, ""))
+
+	err := e.ResumeContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10111", err)
+	assert.Equal(t, "sb-1", sub.ProtocolID)
+}
+
 func TestDeleteSubscriptionFail(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1586,7 +1959,7 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, float64(2), params[1])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.NoError(t, err)
 }
 
@@ -1602,7 +1975,7 @@ func TestInvokeContractAddressNotSet(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "'address' not set", err)
 }
 
@@ -1626,7 +1999,7 @@ func TestInvokeContractEthconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10111", err)
 }
 
@@ -1653,10 +2026,95 @@ func TestInvokeContractPrepareFail(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
+	assert.Regexp(t, "invalid json", err)
+}
+
+func TestDeployContractOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	opID := fftypes.NewUUID()
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"x","type":"uint256"}]}]`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "DeployContract", headers["type"])
+			assert.Equal(t, opID.String(), headers["id"])
+			assert.Equal(t, signingKey, body["from"])
+			assert.Equal(t, "YIA=", body["compiled"])
+			assert.Equal(t, "constructor", body["abi"].([]interface{})[0].(map[string]interface{})["type"])
+			assert.Equal(t, []interface{}{float64(1)}, body["params"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), opID, signingKey, definition, fftypes.JSONAnyPtr(`"0x6080"`), nil, []interface{}{1})
+	assert.NoError(t, err)
+}
+
+func TestDeployContractFFIConstructorOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	constructor := testFFIMethod()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			abi := body["abi"].([]interface{})
+			assert.Len(t, abi, 1)
+			element := abi[0].(map[string]interface{})
+			assert.Equal(t, "constructor", element["type"])
+			assert.Nil(t, element["name"])
+			assert.Len(t, element["inputs"], 2)
+			assert.Empty(t, element["outputs"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", nil, fftypes.JSONAnyPtr(`"6080"`), constructor, []interface{}{1, 2})
+	assert.NoError(t, err)
+}
+
+func TestDeployContractFFIConstructorBadSchema(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	constructor := &fftypes.FFIMethod{
+		Name: "constructor",
+		Params: fftypes.FFIParams{
+			{
+				Schema: fftypes.JSONAnyPtr("{bad schema!"),
+			},
+		},
+	}
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", nil, fftypes.JSONAnyPtr(`"6080"`), constructor, []interface{}{1})
 	assert.Regexp(t, "invalid json", err)
 }
 
+func TestDeployContractBadBytecode(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", nil, fftypes.JSONAnyPtr(`"0xzz"`), nil, nil)
+	assert.Regexp(t, "FF10429", err)
+	err = e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", nil, fftypes.JSONAnyPtr(`{}`), nil, nil)
+	assert.Regexp(t, "FF10429", err)
+}
+
+func TestDeployContractEthconnectError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponderOrPanic(400, "")(req)
+		})
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x6080"`), nil, nil)
+	assert.Regexp(t, "FF10111", err)
+}
+
 func TestQueryContractOK(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -2382,3 +2840,15 @@ func TestGetFFIType(t *testing.T) {
 	assert.Equal(t, e.getFFIType("tuple"), "object")
 	assert.Equal(t, e.getFFIType("foobar"), "")
 }
+
+func TestHealth(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	mws := e.wsconn.(*wsmocks.WSClient)
+	mws.On("State").Return(wsclient.WSStateConnected).Once()
+	assert.NoError(t, e.Health(context.Background()))
+
+	mws.On("State").Return(wsclient.WSStateDisconnected).Once()
+	assert.Regexp(t, "FF10389.*disconnected", e.Health(context.Background()))
+}
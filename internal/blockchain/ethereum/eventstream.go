@@ -30,27 +30,55 @@ import (
 )
 
 type streamManager struct {
-	client *resty.Client
+	client     *resty.Client
+	evmconnect bool
 }
 
 type eventStream struct {
-	ID             string               `json:"id"`
-	Name           string               `json:"name"`
-	ErrorHandling  string               `json:"errorHandling"`
-	BatchSize      uint                 `json:"batchSize"`
-	BatchTimeoutMS uint                 `json:"batchTimeoutMS"`
-	Type           string               `json:"type"`
-	WebSocket      eventStreamWebsocket `json:"websocket"`
-	Timestamps     bool                 `json:"timestamps"`
+	ID                   string               `json:"id"`
+	Name                 string               `json:"name"`
+	ErrorHandling        string               `json:"errorHandling"`
+	BatchSize            uint                 `json:"batchSize"`
+	BatchTimeoutMS       uint                 `json:"batchTimeoutMS"`
+	RetryTimeoutSec      uint                 `json:"retryTimeoutSec,omitempty"`
+	BlockedRetryDelaySec uint                 `json:"blockedRetryDelaySec,omitempty"`
+	Type                 string               `json:"type"`
+	WebSocket            eventStreamWebsocket `json:"websocket"`
+	Timestamps           bool                 `json:"timestamps"`
+}
+
+// eventStreamOptions are the batching and retry settings applied to an event stream when it is created or updated
+type eventStreamOptions struct {
+	topic                string
+	errorHandling        string
+	batchSize            uint
+	batchTimeout         uint
+	retryTimeoutSec      uint
+	blockedRetryDelaySec uint
+}
+
+func (o *eventStreamOptions) toEventStream() *eventStream {
+	return &eventStream{
+		Name:                 o.topic,
+		ErrorHandling:        o.errorHandling,
+		BatchSize:            o.batchSize,
+		BatchTimeoutMS:       o.batchTimeout,
+		RetryTimeoutSec:      o.retryTimeoutSec,
+		BlockedRetryDelaySec: o.blockedRetryDelaySec,
+		Type:                 "websocket",
+		WebSocket:            eventStreamWebsocket{Topic: o.topic},
+		Timestamps:           true,
+	}
 }
 
 type subscription struct {
-	ID        string               `json:"id"`
-	Name      string               `json:"name,omitempty"`
-	Stream    string               `json:"stream"`
-	FromBlock string               `json:"fromBlock"`
-	Address   string               `json:"address"`
-	Event     ABIElementMarshaling `json:"event"`
+	ID            string               `json:"id"`
+	Name          string               `json:"name,omitempty"`
+	Stream        string               `json:"stream"`
+	FromBlock     string               `json:"fromBlock"`
+	Address       string               `json:"address"`
+	Event         ABIElementMarshaling `json:"event"`
+	Confirmations uint64               `json:"confirmations,omitempty"`
 }
 
 func (s *streamManager) getEventStreams(ctx context.Context) (streams []*eventStream, err error) {
@@ -64,66 +92,53 @@ func (s *streamManager) getEventStreams(ctx context.Context) (streams []*eventSt
 	return streams, nil
 }
 
-func (s *streamManager) createEventStream(ctx context.Context, topic string, batchSize, batchTimeout uint) (*eventStream, error) {
-	stream := eventStream{
-		Name:           topic,
-		ErrorHandling:  "block",
-		BatchSize:      batchSize,
-		BatchTimeoutMS: batchTimeout,
-		Type:           "websocket",
-		WebSocket:      eventStreamWebsocket{Topic: topic},
-		Timestamps:     true,
-	}
+func (s *streamManager) createEventStream(ctx context.Context, opts *eventStreamOptions) (*eventStream, error) {
+	stream := opts.toEventStream()
 	res, err := s.client.R().
 		SetContext(ctx).
-		SetBody(&stream).
-		SetResult(&stream).
+		SetBody(stream).
+		SetResult(stream).
 		Post("/eventstreams")
 	if err != nil || !res.IsSuccess() {
 		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
-	return &stream, nil
+	return stream, nil
 }
 
-func (s *streamManager) updateEventStream(ctx context.Context, topic string, batchSize, batchTimeout uint, eventStreamID string) (*eventStream, error) {
-	stream := eventStream{
-		Name:           topic,
-		ErrorHandling:  "block",
-		BatchSize:      batchSize,
-		BatchTimeoutMS: batchTimeout,
-		Type:           "websocket",
-		WebSocket:      eventStreamWebsocket{Topic: topic},
-		Timestamps:     true,
-	}
+func (s *streamManager) updateEventStream(ctx context.Context, opts *eventStreamOptions, eventStreamID string) (*eventStream, error) {
+	stream := opts.toEventStream()
 	res, err := s.client.R().
 		SetContext(ctx).
-		SetBody(&stream).
-		SetResult(&stream).
+		SetBody(stream).
+		SetResult(stream).
 		Patch("/eventstreams/" + eventStreamID)
 	if err != nil || !res.IsSuccess() {
 		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
-	return &stream, nil
+	return stream, nil
 }
 
-func (s *streamManager) ensureEventStream(ctx context.Context, topic string, batchSize, batchTimeout uint) (*eventStream, error) {
+func (s *streamManager) ensureEventStream(ctx context.Context, opts *eventStreamOptions) (*eventStream, error) {
 	existingStreams, err := s.getEventStreams(ctx)
 	if err != nil {
 		return nil, err
 	}
 	for _, stream := range existingStreams {
-		if stream.WebSocket.Topic == topic {
-			stream, err = s.updateEventStream(ctx, topic, batchSize, batchTimeout, stream.ID)
+		if stream.WebSocket.Topic == opts.topic {
+			stream, err = s.updateEventStream(ctx, opts, stream.ID)
 			if err != nil {
 				return nil, err
 			}
 			return stream, nil
 		}
 	}
-	return s.createEventStream(ctx, topic, batchSize, batchTimeout)
+	return s.createEventStream(ctx, opts)
 }
 
-func (s *streamManager) getSubscriptions(ctx context.Context) (subs []*subscription, err error) {
+func (s *streamManager) getSubscriptions(ctx context.Context, stream string) (subs []*subscription, err error) {
+	if s.evmconnect {
+		return s.getListeners(ctx, stream)
+	}
 	res, err := s.client.R().
 		SetContext(ctx).
 		SetResult(&subs).
@@ -134,7 +149,7 @@ func (s *streamManager) getSubscriptions(ctx context.Context) (subs []*subscript
 	return subs, nil
 }
 
-func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, abi ABIElementMarshaling) (*subscription, error) {
+func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, confirmations uint64, abi ABIElementMarshaling) (*subscription, error) {
 	// Map FireFly "firstEvent" values to Ethereum "fromBlock" values
 	switch fromBlock {
 	case string(fftypes.SubOptsFirstEventOldest):
@@ -143,11 +158,15 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 		fromBlock = "latest"
 	}
 	sub := subscription{
-		Name:      subName,
-		Stream:    stream,
-		FromBlock: fromBlock,
-		Address:   location.Address,
-		Event:     abi,
+		Name:          subName,
+		Stream:        stream,
+		FromBlock:     fromBlock,
+		Address:       location.Address,
+		Event:         abi,
+		Confirmations: confirmations,
+	}
+	if s.evmconnect {
+		return s.createListener(ctx, &sub)
 	}
 	res, err := s.client.R().
 		SetContext(ctx).
@@ -160,23 +179,27 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 	return &sub, nil
 }
 
-func (s *streamManager) deleteSubscription(ctx context.Context, subID string) error {
+func (s *streamManager) deleteSubscription(ctx context.Context, stream, subID string) error {
+	path := "/subscriptions/" + subID
+	if s.evmconnect {
+		path = "/eventstreams/" + stream + "/listeners/" + subID
+	}
 	res, err := s.client.R().
 		SetContext(ctx).
-		Delete("/subscriptions/" + subID)
+		Delete(path)
 	if err != nil || !res.IsSuccess() {
 		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
 	return nil
 }
 
-func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, stream string, abi ABIElementMarshaling) (sub *subscription, err error) {
+func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, stream string, confirmations uint64, abi ABIElementMarshaling) (sub *subscription, err error) {
 	// Include a hash of the instance path in the subscription, so if we ever point at a different
 	// contract configuration, we re-subscribe from block 0.
 	// We don't need full strength hashing, so just use the first 16 chars for readability.
 	instanceUniqueHash := hex.EncodeToString(sha256.New().Sum([]byte(instancePath)))[0:16]
 
-	existingSubs, err := s.getSubscriptions(ctx)
+	existingSubs, err := s.getSubscriptions(ctx, stream)
 	if err != nil {
 		return nil, err
 	}
@@ -197,8 +220,18 @@ func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, st
 		Address: instancePath,
 	}
 
+	// The confirmations of a subscription cannot be updated, so it is replaced when they are reconfigured.
+	// The new subscription starts from the oldest block, so the events already processed are redelivered.
+	if sub != nil && sub.Confirmations != confirmations {
+		log.L(ctx).Infof("Replacing %s subscription %s, as its confirmations changed from %d to %d", abi.Name, sub.ID, sub.Confirmations, confirmations)
+		if err = s.deleteSubscription(ctx, stream, sub.ID); err != nil {
+			return nil, err
+		}
+		sub = nil
+	}
+
 	if sub == nil {
-		if sub, err = s.createSubscription(ctx, location, stream, subName, string(fftypes.SubOptsFirstEventOldest), abi); err != nil {
+		if sub, err = s.createSubscription(ctx, location, stream, subName, string(fftypes.SubOptsFirstEventOldest), confirmations, abi); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,156 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+const (
+	connectorAPIAuto       = "auto"
+	connectorAPIEthconnect = "ethconnect"
+	connectorAPIEVMConnect = "evmconnect"
+)
+
+// connectorStatus is the response from the status API of the connector
+type connectorStatus struct {
+	OK bool `json:"ok"`
+}
+
+// listener is a subscription in the shape of the EVM connector API, where the listener
+// belongs to an event stream, and matches events using a list of filters
+type listener struct {
+	ID        string            `json:"id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Stream    string            `json:"stream,omitempty"`
+	FromBlock string            `json:"fromBlock,omitempty"`
+	Filters   []*listenerFilter `json:"filters"`
+	Options   *listenerOptions  `json:"options,omitempty"`
+}
+
+type listenerFilter struct {
+	Address string               `json:"address,omitempty"`
+	Event   ABIElementMarshaling `json:"event"`
+}
+
+type listenerOptions struct {
+	Confirmations uint64 `json:"confirmations,omitempty"`
+}
+
+// useEVMConnect returns true if the connector should be driven with the EVM connector API, rather than
+// the legacy ethconnect API. In "auto" mode, this is detected from the status API - ethconnect responds
+// with {"ok":true}, while the EVM connector has no status API and responds with a 404. Any other
+// response fails startup, rather than risking driving the connector with the wrong API.
+func useEVMConnect(ctx context.Context, client *resty.Client, connectorAPI string) (bool, error) {
+	switch connectorAPI {
+	case connectorAPIEthconnect:
+		return false, nil
+	case connectorAPIEVMConnect:
+		return true, nil
+	case connectorAPIAuto:
+	default:
+		return false, i18n.NewError(ctx, i18n.MsgInvalidConnectorAPI, connectorAPI)
+	}
+	var status connectorStatus
+	res, err := client.R().
+		SetContext(ctx).
+		SetResult(&status).
+		Get("/status")
+	var evmconnect bool
+	switch {
+	case err == nil && res.StatusCode() == http.StatusNotFound:
+		evmconnect = true
+	case err != nil || !res.IsSuccess():
+		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	case !status.OK:
+		return false, i18n.NewError(ctx, i18n.MsgConnectorAPIUndetected)
+	}
+	log.L(ctx).Infof("Detected connector API: evmconnect=%t", evmconnect)
+	return evmconnect, nil
+}
+
+func newListener(sub *subscription) *listener {
+	return &listener{
+		Name:      sub.Name,
+		Stream:    sub.Stream,
+		FromBlock: sub.FromBlock,
+		Filters: []*listenerFilter{
+			{
+				Address: sub.Address,
+				Event:   sub.Event,
+			},
+		},
+		Options: &listenerOptions{
+			Confirmations: sub.Confirmations,
+		},
+	}
+}
+
+func (l *listener) toSubscription(stream string) *subscription {
+	sub := &subscription{
+		ID:        l.ID,
+		Name:      l.Name,
+		Stream:    l.Stream,
+		FromBlock: l.FromBlock,
+	}
+	if sub.Stream == "" {
+		sub.Stream = stream
+	}
+	if len(l.Filters) > 0 {
+		sub.Address = l.Filters[0].Address
+		sub.Event = l.Filters[0].Event
+	}
+	if l.Options != nil {
+		sub.Confirmations = l.Options.Confirmations
+	}
+	return sub
+}
+
+func (s *streamManager) getListeners(ctx context.Context, stream string) ([]*subscription, error) {
+	var listeners []*listener
+	res, err := s.client.R().
+		SetContext(ctx).
+		SetResult(&listeners).
+		Get("/eventstreams/" + stream + "/listeners")
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	subs := make([]*subscription, len(listeners))
+	for i, l := range listeners {
+		subs[i] = l.toSubscription(stream)
+	}
+	return subs, nil
+}
+
+func (s *streamManager) createListener(ctx context.Context, sub *subscription) (*subscription, error) {
+	l := newListener(sub)
+	res, err := s.client.R().
+		SetContext(ctx).
+		SetBody(l).
+		SetResult(l).
+		Post("/eventstreams/" + sub.Stream + "/listeners")
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	return l.toSubscription(sub.Stream), nil
+}
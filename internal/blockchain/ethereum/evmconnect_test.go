@@ -0,0 +1,431 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEVMConnect() (*Ethereum, func()) {
+	e, cancel := newTestEthereum()
+	e.evmconnect = true
+	e.initInfo.stream = &eventStream{ID: "es-1"}
+	e.streams = &streamManager{
+		client:     e.client,
+		evmconnect: true,
+	}
+	return e, cancel
+}
+
+func TestUseEVMConnectConfigured(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	evmconnect, err := useEVMConnect(e.ctx, e.client, connectorAPIEthconnect)
+	assert.NoError(t, err)
+	assert.False(t, evmconnect)
+
+	evmconnect, err = useEVMConnect(e.ctx, e.client, connectorAPIEVMConnect)
+	assert.NoError(t, err)
+	assert.True(t, evmconnect)
+
+	_, err = useEVMConnect(e.ctx, e.client, "fabconnect")
+	assert.Regexp(t, "FF10497.*fabconnect", err)
+}
+
+func TestUseEVMConnectAutoEthconnect(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"ok": true}))
+
+	evmconnect, err := useEVMConnect(e.ctx, e.client, connectorAPIAuto)
+	assert.NoError(t, err)
+	assert.False(t, evmconnect)
+}
+
+func TestUseEVMConnectAutoEVMConnect(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(404, "Not found"))
+
+	evmconnect, err := useEVMConnect(e.ctx, e.client, connectorAPIAuto)
+	assert.NoError(t, err)
+	assert.True(t, evmconnect)
+}
+
+func TestUseEVMConnectAutoFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(500, "pop"))
+
+	_, err := useEVMConnect(e.ctx, e.client, connectorAPIAuto)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestUseEVMConnectAutoUnauthorized(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(401, "Unauthorized"))
+
+	_, err := useEVMConnect(e.ctx, e.client, connectorAPIAuto)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestUseEVMConnectAutoNotOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"ok": false}))
+
+	_, err := useEVMConnect(e.ctx, e.client, connectorAPIAuto)
+	assert.Regexp(t, "FF10577", err)
+}
+
+func TestInitBadConnectorAPI(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(EthconnectConfigConnectorAPI, "unknown")
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10497", err)
+}
+
+func TestInitEVMConnectNewListenerAndWSBatch(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	toServer, fromServer, wsURL, done := wsclient.NewTestWSServer(nil)
+	defer done()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	u, _ := url.Parse(wsURL)
+	u.Scheme = "http"
+	httpURL := u.String()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/status", httpURL),
+		httpmock.NewStringResponder(404, "Not found"))
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/eventstreams", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/eventstreams", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, eventStream{ID: "es12345"}))
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/eventstreams/es12345/listeners", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, []listener{}))
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/eventstreams/es12345/listeners", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body listener
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "0", body.FromBlock)
+			assert.Len(t, body.Filters, 1)
+			assert.Equal(t, "0x12345", body.Filters[0].Address)
+			assert.Equal(t, "BatchPin", body.Filters[0].Event.Name)
+			return httpmock.NewJsonResponderOrPanic(200, listener{ID: "l12345"})(req)
+		})
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, httpURL)
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigConnectorAPI, connectorAPIAuto)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+	assert.True(t, e.evmconnect)
+	assert.Equal(t, "l12345", e.initInfo.sub.ID)
+	assert.Equal(t, "es12345", e.initInfo.sub.Stream)
+
+	err = e.Start()
+	assert.NoError(t, err)
+
+	<-toServer // listen
+	<-toServer // listenreplies
+	fromServer <- `{"batchNumber":7,"events":[]}`
+	reply := <-toServer
+	assert.Equal(t, `{"batchNumber":7,"topic":"topic1","type":"ack"}`, reply)
+}
+
+func TestEnsureSubscriptionExistingListener(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams/es-1/listeners",
+		httpmock.NewJsonResponderOrPanic(200, []listener{
+			{
+				ID:   "l1",
+				Name: "BatchPin",
+				Filters: []*listenerFilter{
+					{Address: "0x12345", Event: batchPinEventABI},
+				},
+			},
+		}))
+
+	sub, err := e.streams.ensureSubscription(e.ctx, "0x12345", "es-1", 0, batchPinEventABI)
+	assert.NoError(t, err)
+	assert.Equal(t, "l1", sub.ID)
+	assert.Equal(t, "es-1", sub.Stream)
+	assert.Equal(t, "0x12345", sub.Address)
+}
+
+func TestEnsureSubscriptionListenersQueryFail(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams/es-1/listeners",
+		httpmock.NewStringResponder(500, "pop"))
+
+	_, err := e.streams.ensureSubscription(e.ctx, "0x12345", "es-1", 0, batchPinEventABI)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestAddContractListenerEVMConnect(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			ID:       fftypes.NewUUID(),
+			Location: fftypes.JSONAnyPtr(`{"address":"0x123"}`),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent:    string(fftypes.SubOptsFirstEventNewest),
+				Confirmations: 5,
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams/es-1/listeners",
+		func(req *http.Request) (*http.Response, error) {
+			var body listener
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "latest", body.FromBlock)
+			assert.Equal(t, "0x123", body.Filters[0].Address)
+			assert.Equal(t, "Changed", body.Filters[0].Event.Name)
+			assert.Equal(t, uint64(5), body.Options.Confirmations)
+			return httpmock.NewJsonResponderOrPanic(200, listener{ID: "l1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "l1", sub.ProtocolID)
+}
+
+func TestAddContractListenerEVMConnectFail(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			ID:       fftypes.NewUUID(),
+			Location: fftypes.JSONAnyPtr(`{"address":"0x123"}`),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams/es-1/listeners",
+		httpmock.NewStringResponder(500, "pop"))
+
+	err := e.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestDeleteContractListenerEVMConnect(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.listenerStreams = []*listenerStream{newTestListenerStream("tokens", "topic2", "es-2")}
+
+	httpmock.RegisterResponder("DELETE", "http://localhost:12345/eventstreams/es-1/listeners/l1",
+		httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("DELETE", "http://localhost:12345/eventstreams/es-2/listeners/l2",
+		httpmock.NewStringResponder(204, ""))
+
+	err := e.DeleteContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "l1"})
+	assert.NoError(t, err)
+	err = e.DeleteContractListener(context.Background(), &fftypes.ContractListener{
+		ProtocolID: "l2",
+		Options:    &fftypes.ContractListenerOptions{Stream: "tokens"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestDeleteContractListenerEVMConnectUnknownStream(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+
+	err := e.DeleteContractListener(context.Background(), &fftypes.ContractListener{
+		ProtocolID: "l1",
+		Options:    &fftypes.ContractListenerOptions{Stream: "unknown"},
+	})
+	assert.Regexp(t, "FF10415", err)
+}
+
+func TestPauseResumeContractListenerEVMConnect(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	listener := &fftypes.ContractListener{
+		ID:         fftypes.NewUUID(),
+		ProtocolID: "l1",
+		Location:   fftypes.JSONAnyPtr(`{"address":"0x123"}`),
+		Event: &fftypes.FFISerializedEvent{
+			FFIEventDefinition: fftypes.FFIEventDefinition{Name: "Changed"},
+		},
+		Options: &fftypes.ContractListenerOptions{
+			Checkpoint: &fftypes.ContractListenerCheckpoint{FirstEvent: "1234"},
+		},
+	}
+
+	httpmock.RegisterResponder("DELETE", "http://localhost:12345/eventstreams/es-1/listeners/l1",
+		httpmock.NewStringResponder(204, ""))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams/es-1/listeners",
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "1234", body.GetString("fromBlock"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"id": "l2"})(req)
+		})
+
+	err := e.PauseContractListener(context.Background(), listener)
+	assert.NoError(t, err)
+	err = e.ResumeContractListener(context.Background(), listener)
+	assert.NoError(t, err)
+	assert.Equal(t, "l2", listener.ProtocolID)
+}
+
+func TestDeployContractEVMConnect(t *testing.T) {
+	e, cancel := newTestEVMConnect()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"x","type":"uint256"}]}]`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "DeployContract", headers["type"])
+			assert.Equal(t, opID.String(), headers["id"])
+			assert.Equal(t, "0x6080", body["contract"])
+			assert.Equal(t, "constructor", body["definition"].([]interface{})[0].(map[string]interface{})["type"])
+			assert.Nil(t, body["compiled"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), opID, "0x123", definition, fftypes.JSONAnyPtr(`"6080"`), nil, []interface{}{1})
+	assert.NoError(t, err)
+}
+
+func TestHandleMessageContractEventListenerID(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"value": "1"
+		},
+		"listenerId": "l2",
+		"signature": "Changed(uint256)",
+		"logIndex": "50",
+		"timestamp": "2022-06-01T12:00:00Z"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: em,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "l1",
+	}
+
+	em.On("BlockchainEvent", mock.MatchedBy(func(ev *blockchain.EventWithSubscription) bool {
+		return ev.Subscription == "l2" && ev.Event.Name == "Changed"
+	})).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
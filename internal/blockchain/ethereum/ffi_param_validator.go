@@ -17,11 +17,14 @@
 package ethereum
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -63,6 +66,9 @@ func (v *FFIParamValidator) Compile(ctx jsonschema.CompilerContext, m map[string
 		}
 
 		if valid {
+			if isEthereumNumberType(blockchainType) {
+				return newNumberSchema(blockchainType, n), nil
+			}
 			return detailsSchema(n), nil
 		}
 		return nil, fmt.Errorf("cannot cast %v to %v", jsonType, blockchainType)
@@ -165,6 +171,16 @@ func (v *FFIParamValidator) GetMetaSchema() *jsonschema.Schema {
 				},
 				"indexed": {
 				"type": "boolean"
+				},
+				"scale": {
+				"type": "integer",
+				"minimum": 0,
+				"maximum": 77
+				},
+				"precision": {
+				"type": "integer",
+				"minimum": 1,
+				"maximum": 78
 				}
 			},
 			"required": ["type"]
@@ -182,6 +198,16 @@ func (v *FFIParamValidator) GetMetaSchema() *jsonschema.Schema {
 				"indexed": {
 				"type": "boolean"
 				},
+				"scale": {
+				"type": "integer",
+				"minimum": 0,
+				"maximum": 77
+				},
+				"precision": {
+				"type": "integer",
+				"minimum": 1,
+				"maximum": 78
+				},
 				"index": {
 				"type": "integer"
 				}
@@ -198,11 +224,114 @@ func (v *FFIParamValidator) GetExtensionName() string {
 
 type detailsSchema map[string]interface{}
 
+// detailsExtension is implemented by all the schemas compiled from details, to read back the details
+type detailsExtension interface {
+	jsonschema.ExtSchema
+	getDetails() detailsSchema
+}
+
+func (s detailsSchema) getDetails() detailsSchema {
+	return s
+}
+
 func (s detailsSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
 	// TODO: Additional validation of actual input possible in the future
 	return nil
 }
 
+// numberSchema checks a number fits the range of its Solidity integer type, before it is submitted to the chain.
+// A scale in the details declares a fixed-point value, such as a token amount with 18 decimals. It is supplied
+// in its decimal form, and submitted multiplied by 10^scale. A precision limits the number of digits submitted.
+type numberSchema struct {
+	detailsSchema
+	blockchainType string
+	min            *big.Int
+	max            *big.Int
+	scale          int
+	precision      int
+}
+
+func newNumberSchema(blockchainType string, details map[string]interface{}) *numberSchema {
+	bits, _ := strconv.Atoi(intRegex.FindStringSubmatch(blockchainType)[1])
+	s := &numberSchema{
+		detailsSchema:  details,
+		blockchainType: blockchainType,
+		scale:          detailsInt(details, "scale"),
+		precision:      detailsInt(details, "precision"),
+	}
+	if strings.HasPrefix(blockchainType, "u") {
+		s.min = big.NewInt(0)
+		s.max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	} else {
+		s.min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+		s.max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)), big.NewInt(1))
+	}
+	return s
+}
+
+func (s *numberSchema) Validate(ctx jsonschema.ValidationContext, v interface{}) error {
+	i, err := parseFixedPoint(v, s.scale)
+	if err != nil {
+		return ctx.Error("details", "%s", err)
+	}
+	if i.Cmp(s.min) < 0 || i.Cmp(s.max) > 0 {
+		return ctx.Error("details", "value %s overflows %s", i, s.blockchainType)
+	}
+	if s.precision > 0 && len(new(big.Int).Abs(i).String()) > s.precision {
+		return ctx.Error("details", "value %s has more than %d digits of precision", i, s.precision)
+	}
+	return nil
+}
+
+// detailsInt reads an optional integer from the details, which is a json.Number when compiled
+// from a schema, or a float64 when the schema is parsed into a generic map
+func detailsInt(details map[string]interface{}, key string) int {
+	switch v := details[key].(type) {
+	case json.Number:
+		i, _ := v.Int64()
+		return int(i)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// parseFixedPoint reads a number supplied as a JSON number, or as a decimal or 0x prefixed hex string, and
+// returns the integer after moving the decimal point scale places to the right. Hex values are the raw integer,
+// and are not scaled.
+func parseFixedPoint(v interface{}, scale int) (*big.Int, error) {
+	var str string
+	switch vt := v.(type) {
+	case string:
+		str = strings.TrimSpace(vt)
+	case json.Number:
+		str = vt.String()
+	case float64:
+		str = strconv.FormatFloat(vt, 'f', -1, 64)
+	case int, int32, int64, uint, uint32, uint64:
+		str = fmt.Sprint(vt)
+	default:
+		return nil, fmt.Errorf("value %v is not a number", v)
+	}
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		i, ok := new(big.Int).SetString(str[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("value '%s' is not a valid hex number", str)
+		}
+		return i, nil
+	}
+	r, ok := new(big.Rat).SetString(str)
+	if !ok || strings.Contains(str, "/") {
+		return nil, fmt.Errorf("value '%s' is not a valid number", str)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("value '%s' has more than %d decimal places", str, scale)
+	}
+	return r.Num(), nil
+}
+
 func isEthereumNumberType(input string) bool {
 	matches := intRegex.FindStringSubmatch(input)
 	if len(matches) == 2 {
@@ -229,3 +358,42 @@ func isEthereumBytesType(input string) bool {
 	}
 	return false
 }
+
+// scaleFixedPoint converts the fixed-point values in an input to the integers submitted to the chain, following
+// the scale in the details of the schema - including in the properties of tuples, and the items of arrays
+func scaleFixedPoint(schema fftypes.JSONObject, v interface{}) (interface{}, error) {
+	details := schema.GetObject("details")
+	if scale := detailsInt(details, "scale"); scale > 0 && isEthereumNumberType(details.GetString("type")) {
+		i, err := parseFixedPoint(v, scale)
+		if err != nil {
+			return nil, err
+		}
+		return i.String(), nil
+	}
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		properties := schema.GetObject("properties")
+		scaled := make(map[string]interface{}, len(vt))
+		for k, pv := range vt {
+			sv, err := scaleFixedPoint(properties.GetObject(k), pv)
+			if err != nil {
+				return nil, err
+			}
+			scaled[k] = sv
+		}
+		return scaled, nil
+	case []interface{}:
+		items := schema.GetObject("items")
+		scaled := make([]interface{}, len(vt))
+		for i, iv := range vt {
+			sv, err := scaleFixedPoint(items, iv)
+			if err != nil {
+				return nil, err
+			}
+			scaled[i] = sv
+		}
+		return scaled, nil
+	default:
+		return v, nil
+	}
+}
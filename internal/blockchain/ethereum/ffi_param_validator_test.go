@@ -217,21 +217,21 @@ func TestInputStruct(t *testing.T) {
 		"x": {
 			"type": "integer",
 			"details": {
-				"type": "uint8",
+				"type": "uint16",
 				"index": 0
 			}
 		},
 		"y": {
 			"type": "integer",
 			"details": {
-				"type": "uint8",
+				"type": "uint16",
 				"index": 1
 			}
 		},
 		"z": {
 			"type": "integer",
 			"details": {
-				"type": "uint8",
+				"type": "uint16",
 				"index": 2
 			}
 		}
@@ -328,3 +328,167 @@ func TestInputNoAdditionalProperties(t *testing.T) {
 	err = s.Validate(jsonDecode(input))
 	assert.Regexp(t, "additionalProperties 'bar' not allowed", err)
 }
+
+func TestInputIntegerOverflow(t *testing.T) {
+	s, err := NewTestSchema(`
+{
+	"type": "integer",
+	"details": {
+		"type": "uint8"
+	}
+}`)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(255))
+	assert.Regexp(t, "value 256 overflows uint8", s.Validate(256))
+	assert.Regexp(t, "value -1 overflows uint8", s.Validate(-1))
+
+	s, err = NewTestSchema(`
+{
+	"type": "integer",
+	"details": {
+		"type": "int8"
+	}
+}`)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(-128))
+	assert.NoError(t, s.Validate(127))
+	assert.Regexp(t, "value -129 overflows int8", s.Validate(-129))
+	assert.Regexp(t, "value 128 overflows int8", s.Validate(128))
+}
+
+func TestInputNumberString(t *testing.T) {
+	s, err := NewTestSchema(`
+{
+	"type": "string",
+	"details": {
+		"type": "uint256"
+	}
+}`)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate("115792089237316195423570985008687907853269984665640564039457584007913129639935"))
+	assert.NoError(t, s.Validate("0xff"))
+	assert.Regexp(t, "overflows uint256", s.Validate("115792089237316195423570985008687907853269984665640564039457584007913129639936"))
+	assert.Regexp(t, "not a valid number", s.Validate("abc"))
+	assert.Regexp(t, "not a valid number", s.Validate("1/2"))
+	assert.Regexp(t, "not a valid hex number", s.Validate("0xzz"))
+	assert.Regexp(t, "has more than 0 decimal places", s.Validate("1.5"))
+}
+
+func TestInputFixedPoint(t *testing.T) {
+	s, err := NewTestSchema(`
+{
+	"type": "string",
+	"details": {
+		"type": "uint64",
+		"scale": 18
+	}
+}`)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate("1.5"))
+	assert.NoError(t, s.Validate("18.446744073709551615"))
+	assert.Regexp(t, "value 18446744073709551616 overflows uint64", s.Validate("18.446744073709551616"))
+	assert.Regexp(t, "has more than 18 decimal places", s.Validate("1.0000000000000000001"))
+}
+
+func TestInputPrecision(t *testing.T) {
+	s, err := NewTestSchema(`
+{
+	"type": "integer",
+	"details": {
+		"type": "int256",
+		"precision": 3
+	}
+}`)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(-999))
+	assert.Regexp(t, "value -1000 has more than 3 digits of precision", s.Validate(-1000))
+}
+
+func TestSchemaInvalidScale(t *testing.T) {
+	_, err := NewTestSchema(`
+{
+	"type": "string",
+	"details": {
+		"type": "uint256",
+		"scale": 78
+	}
+}`)
+	assert.Regexp(t, "compilation failed", err)
+}
+
+func TestParseFixedPointTypes(t *testing.T) {
+	i, err := parseFixedPoint(json.Number("1.25"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "125", i.String())
+
+	i, err = parseFixedPoint(float64(2.5), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "25", i.String())
+
+	i, err = parseFixedPoint(int64(3), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", i.String())
+
+	_, err = parseFixedPoint(true, 0)
+	assert.Regexp(t, "value true is not a number", err)
+}
+
+func TestDetailsInt(t *testing.T) {
+	assert.Equal(t, 18, detailsInt(map[string]interface{}{"scale": json.Number("18")}, "scale"))
+	assert.Equal(t, 18, detailsInt(map[string]interface{}{"scale": float64(18)}, "scale"))
+	assert.Equal(t, 0, detailsInt(map[string]interface{}{}, "scale"))
+}
+
+func TestScaleFixedPoint(t *testing.T) {
+	schema := fftypes.JSONAnyPtr(`{
+		"type": "object",
+		"details": {"type": "tuple"},
+		"properties": {
+			"amount": {"type": "string", "details": {"type": "uint256", "scale": 6, "index": 0}},
+			"fees": {"type": "array", "details": {"type": "uint256[]", "index": 1}, "items": {"type": "string", "details": {"type": "uint256", "scale": 2}}},
+			"memo": {"type": "string", "details": {"type": "string", "index": 2}}
+		}
+	}`).JSONObject()
+
+	scaled, err := scaleFixedPoint(schema, map[string]interface{}{
+		"amount": "1.5",
+		"fees":   []interface{}{"0.01", float64(2)},
+		"memo":   "hello",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"amount": "1500000",
+		"fees":   []interface{}{"1", "200"},
+		"memo":   "hello",
+	}, scaled)
+
+	_, err = scaleFixedPoint(schema, map[string]interface{}{
+		"amount": "1.0000001",
+	})
+	assert.Regexp(t, "has more than 6 decimal places", err)
+
+	_, err = scaleFixedPoint(schema, map[string]interface{}{
+		"fees": []interface{}{"0.001"},
+	})
+	assert.Regexp(t, "has more than 2 decimal places", err)
+}
+
+func TestPrepareRequestScaleFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	method := &fftypes.FFIMethod{
+		Name: "transfer",
+		Params: []*fftypes.FFIParam{
+			{
+				Name:   "amount",
+				Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "uint256", "scale": 2}}`),
+			},
+		},
+	}
+	_, _, err := e.prepareRequest(context.Background(), method, map[string]interface{}{"amount": "1.001"})
+	assert.Regexp(t, "FF10331.*amount", err)
+
+	_, input, err := e.prepareRequest(context.Background(), method, map[string]interface{}{"amount": "1.01"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"101"}, input)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+)
+
+// listenerStream is an additional event stream, with its own websocket topic and connection, that
+// contract listeners can be assigned to. This isolates high volume contract events from the
+// BatchPin events on the main stream, which are on the critical path for message delivery.
+type listenerStream struct {
+	name    string
+	options *eventStreamOptions
+	stream  *eventStream
+	wsconn  wsclient.WSClient
+	closed  chan struct{}
+}
+
+func (e *Ethereum) initListenerStreams(ctx context.Context, ethconnectConf config.Prefix, wsConfig *wsclient.WSConfig) (err error) {
+	eventStreamsConf := eventStreamsPrefix(ethconnectConf)
+	names := map[string]bool{}
+	topics := map[string]bool{e.topic: true}
+	eventStreamsConfArraySize := eventStreamsConf.ArraySize()
+	for i := 0; i < eventStreamsConfArraySize; i++ {
+		conf := eventStreamsConf.ArrayEntry(i)
+		ls := &listenerStream{
+			name: conf.GetString(EventStreamConfigName),
+			options: &eventStreamOptions{
+				topic:                conf.GetString(EventStreamConfigTopic),
+				errorHandling:        conf.GetString(EventStreamConfigErrorHandling),
+				batchSize:            conf.GetUint(EventStreamConfigBatchSize),
+				batchTimeout:         uint(conf.GetDuration(EventStreamConfigBatchTimeout).Milliseconds()),
+				retryTimeoutSec:      uint(conf.GetDuration(EventStreamConfigRetryTimeout).Seconds()),
+				blockedRetryDelaySec: uint(conf.GetDuration(EventStreamConfigBlockedRetryDelay).Seconds()),
+			},
+		}
+		switch {
+		case ls.name == "":
+			return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "name", "blockchain.ethconnect.eventStreams")
+		case ls.options.topic == "":
+			return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "topic", "blockchain.ethconnect.eventStreams")
+		case names[ls.name]:
+			return i18n.NewError(ctx, i18n.MsgDuplicateEventStream, "name", ls.name)
+		case topics[ls.options.topic]:
+			return i18n.NewError(ctx, i18n.MsgDuplicateEventStream, "topic", ls.options.topic)
+		case ls.options.errorHandling != "block" && ls.options.errorHandling != "skip":
+			return i18n.NewError(ctx, i18n.MsgInvalidEventStreamErrors, ls.options.errorHandling, ls.name)
+		}
+		names[ls.name] = true
+		topics[ls.options.topic] = true
+
+		streamWSConfig := *wsConfig
+		if ls.wsconn, err = wsclient.New(ctx, &streamWSConfig, nil, ls.afterConnect); err != nil {
+			return err
+		}
+		if ls.stream, err = e.streams.ensureEventStream(e.ctx, ls.options); err != nil {
+			return err
+		}
+		log.L(e.ctx).Infof("Event stream '%s': %s (topic=%s)", ls.name, ls.stream.ID, ls.options.topic)
+		e.listenerStreams = append(e.listenerStreams, ls)
+	}
+	return nil
+}
+
+func (ls *listenerStream) afterConnect(ctx context.Context, w wsclient.WSClient) error {
+	// Receipts are only delivered on the main stream, so we just listen to our topic
+	b, _ := json.Marshal(&ethWSCommandPayload{
+		Type:  "listen",
+		Topic: ls.options.topic,
+	})
+	return w.Send(ctx, b)
+}
+
+func (e *Ethereum) getListenerStream(ctx context.Context, name string) (*listenerStream, error) {
+	for _, ls := range e.listenerStreams {
+		if ls.name == name {
+			return ls, nil
+		}
+	}
+	return nil, i18n.NewError(ctx, i18n.MsgUnknownEventStream, name)
+}
+
+// listenerStreamID returns the ID of the event stream a contract listener is assigned to
+func (e *Ethereum) listenerStreamID(ctx context.Context, options *fftypes.ContractListenerOptions) (string, error) {
+	if options == nil || options.Stream == "" {
+		return e.initInfo.stream.ID, nil
+	}
+	ls, err := e.getListenerStream(ctx, options.Stream)
+	if err != nil {
+		return "", err
+	}
+	return ls.stream.ID, nil
+}
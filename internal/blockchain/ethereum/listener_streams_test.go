@@ -0,0 +1,315 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setListenerStreamsConf(streams ...fftypes.JSONObject) {
+	// Viper only resolves array entries from loaded config, not from values set at runtime
+	conf := fftypes.JSONObject{
+		"eth_unit_tests": fftypes.JSONObject{
+			EthconnectConfigKey: fftypes.JSONObject{
+				EthconnectConfigEventStreams: streams,
+			},
+		},
+	}
+	viper.SetConfigType("json")
+	_ = viper.ReadConfig(strings.NewReader(conf.String()))
+}
+
+func newTestListenerStream(name, topic, streamID string) *listenerStream {
+	return &listenerStream{
+		name:    name,
+		options: &eventStreamOptions{topic: topic},
+		stream:  &eventStream{ID: streamID},
+		wsconn:  &wsmocks.WSClient{},
+	}
+}
+
+func TestInitListenerStreams(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	var created []*eventStream
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams",
+		func(req *http.Request) (*http.Response, error) {
+			var body eventStream
+			json.NewDecoder(req.Body).Decode(&body)
+			body.ID = fmt.Sprintf("es%d", len(created)+1)
+			created = append(created, &body)
+			return httpmock.NewJsonResponderOrPanic(200, &body)(req)
+		})
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub12345"}))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	setListenerStreamsConf(
+		fftypes.JSONObject{"name": "stream1", "topic": "topic2"},
+		fftypes.JSONObject{"name": "stream2", "topic": "topic3", "batchSize": 500, "batchTimeout": "2s", "errorHandling": "skip", "retryTimeout": "30s", "blockedRetryDelay": "1m"},
+	)
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+
+	assert.Len(t, created, 3)
+	assert.Equal(t, "topic1", created[0].WebSocket.Topic)
+	assert.Equal(t, "block", created[0].ErrorHandling)
+	assert.Equal(t, "topic2", created[1].WebSocket.Topic)
+	assert.Equal(t, "block", created[1].ErrorHandling)
+	assert.Equal(t, uint(50), created[1].BatchSize)
+	assert.Equal(t, uint(500), created[1].BatchTimeoutMS)
+	assert.Equal(t, "topic3", created[2].WebSocket.Topic)
+	assert.Equal(t, "skip", created[2].ErrorHandling)
+	assert.Equal(t, uint(500), created[2].BatchSize)
+	assert.Equal(t, uint(2000), created[2].BatchTimeoutMS)
+	assert.Equal(t, uint(30), created[2].RetryTimeoutSec)
+	assert.Equal(t, uint(60), created[2].BlockedRetryDelaySec)
+
+	assert.Len(t, e.listenerStreams, 2)
+	assert.Equal(t, "stream1", e.listenerStreams[0].name)
+	assert.Equal(t, "es2", e.listenerStreams[0].stream.ID)
+	assert.Equal(t, "stream2", e.listenerStreams[1].name)
+	assert.Equal(t, "es3", e.listenerStreams[1].stream.ID)
+}
+
+func TestInitListenerStreamsBadConfig(t *testing.T) {
+	testCases := []struct {
+		streams []fftypes.JSONObject
+		err     string
+	}{
+		{[]fftypes.JSONObject{{"topic": "topic2"}}, "FF10138.*name"},
+		{[]fftypes.JSONObject{{"name": "stream1"}}, "FF10138.*topic"},
+		{[]fftypes.JSONObject{{"name": "stream1", "topic": "topic1"}}, "FF10413.*topic1"},
+		{[]fftypes.JSONObject{{"name": "stream1", "topic": "topic2"}, {"name": "stream1", "topic": "topic3"}}, "FF10413.*stream1"},
+		{[]fftypes.JSONObject{{"name": "stream1", "topic": "topic2", "errorHandling": "ignore"}}, "FF10414.*ignore"},
+	}
+	for _, tc := range testCases {
+		e, cancel := newTestEthereum()
+		e.streams = &streamManager{client: e.client}
+		httpmock.ActivateNonDefault(e.client.GetClient())
+		httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+			httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+		httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams",
+			httpmock.NewJsonResponderOrPanic(200, eventStream{ID: "es12345"}))
+
+		resetConf()
+		utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+		setListenerStreamsConf(tc.streams...)
+		err := e.initListenerStreams(e.ctx, utEthconnectConf, wsconfig.GenerateConfigFromPrefix(utEthconnectConf))
+		assert.Regexp(t, tc.err, err)
+
+		httpmock.DeactivateAndReset()
+		cancel()
+	}
+}
+
+func TestInitListenerStreamsWSFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "!!!://")
+	setListenerStreamsConf(fftypes.JSONObject{"name": "stream1", "topic": "topic2"})
+	err := e.initListenerStreams(e.ctx, utEthconnectConf, wsconfig.GenerateConfigFromPrefix(utEthconnectConf))
+	assert.Regexp(t, "FF10162", err)
+}
+
+func TestInitListenerStreamsEnsureFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.streams = &streamManager{client: e.client}
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewStringResponder(500, `pop`))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	setListenerStreamsConf(fftypes.JSONObject{"name": "stream1", "topic": "topic2"})
+	err := e.initListenerStreams(e.ctx, utEthconnectConf, wsconfig.GenerateConfigFromPrefix(utEthconnectConf))
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
+func TestInitListenerStreamsFailAfterMain(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, eventStream{ID: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub12345"}))
+
+	resetConf()
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	setListenerStreamsConf(fftypes.JSONObject{"name": "stream1"})
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10138.*topic", err)
+}
+
+func TestListenerStreamAfterConnect(t *testing.T) {
+	ls := newTestListenerStream("stream1", "topic2", "es2")
+	wsm := ls.wsconn.(*wsmocks.WSClient)
+	wsm.On("Send", mock.Anything, []byte(`{"type":"listen","topic":"topic2"}`)).Return(nil)
+
+	err := ls.afterConnect(context.Background(), wsm)
+	assert.NoError(t, err)
+	wsm.AssertExpectations(t)
+}
+
+func TestListenerStreamEventLoopAck(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	ls := newTestListenerStream("stream1", "topic2", "es2")
+	ls.closed = make(chan struct{})
+
+	r := make(chan []byte, 1)
+	r <- []byte(`[]`)
+	close(r)
+	wsm := ls.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Send", mock.Anything, []byte(`{"topic":"topic2","type":"ack"}`)).Return(nil)
+	wsm.On("Close").Return()
+
+	e.streamEventLoop(ls.wsconn, ls.options.topic, ls.closed)
+	<-ls.closed
+	wsm.AssertExpectations(t)
+}
+
+func TestStartListenerStreams(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	ls := newTestListenerStream("stream1", "topic2", "es2")
+	e.listenerStreams = []*listenerStream{ls}
+
+	e.wsconn.(*wsmocks.WSClient).On("Connect").Return(nil)
+	ls.wsconn.(*wsmocks.WSClient).On("Connect").Return(nil).Once()
+	assert.NoError(t, e.Start())
+
+	ls.wsconn.(*wsmocks.WSClient).On("Connect").Return(fmt.Errorf("pop"))
+	assert.EqualError(t, e.Start(), "pop")
+}
+
+func TestHealthListenerStreams(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	ls := newTestListenerStream("stream1", "topic2", "es2")
+	e.listenerStreams = []*listenerStream{ls}
+
+	e.wsconn.(*wsmocks.WSClient).On("State").Return(wsclient.WSStateConnected)
+	mws := ls.wsconn.(*wsmocks.WSClient)
+	mws.On("State").Return(wsclient.WSStateConnected).Once()
+	assert.NoError(t, e.Health(context.Background()))
+
+	mws.On("State").Return(wsclient.WSStateDisconnected).Once()
+	assert.Regexp(t, "FF10389.*ethereum/stream1.*disconnected", e.Health(context.Background()))
+}
+
+func testListenerOnStream(stream string) *fftypes.ContractListenerInput {
+	return &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent: string(fftypes.SubOptsFirstEventNewest),
+				Stream:     stream,
+			},
+		},
+	}
+}
+
+func TestAddContractListenerOnStream(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.initInfo.stream = &eventStream{ID: "es1"}
+	e.listenerStreams = []*listenerStream{newTestListenerStream("stream1", "topic2", "es2")}
+	e.streams = &streamManager{client: e.client}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "es2", body["stream"])
+			return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub2"})(req)
+		})
+
+	listener := testListenerOnStream("stream1")
+	err := e.AddContractListener(context.Background(), listener)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub2", listener.ProtocolID)
+}
+
+func TestAddContractListenerUnknownStream(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.initInfo.stream = &eventStream{ID: "es1"}
+	e.listenerStreams = []*listenerStream{newTestListenerStream("stream1", "topic2", "es2")}
+
+	err := e.AddContractListener(context.Background(), testListenerOnStream("stream2"))
+	assert.Regexp(t, "FF10415.*stream2", err)
+}
@@ -0,0 +1,129 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// parseQueryBool reads the result of a query of a method that returns a single bool
+func parseQueryBool(ctx context.Context, body []byte) (bool, error) {
+	var output queryOutput
+	if err := json.Unmarshal(body, &output); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgJSONDecodeFailed)
+	}
+	switch v := output.Output.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return v == "true", nil
+	default:
+		return false, nil
+	}
+}
+
+// isMissingMethodRevert checks whether a query was rejected because the contract does not have the method.
+// With no matching function and no fallback, the call reverts without any revert data - unlike a revert
+// from the method itself, which carries a reason. Any other failure, such as an unreachable connector,
+// an authorization failure or an unavailable node, says nothing about the contract.
+func isMissingMethodRevert(res *resty.Response) bool {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(res.Body(), &body); err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(body.Error), "revert") && revertDataRegex.FindString(body.Error) == ""
+}
+
+// isPermissioned detects whether the batch pin contract is a variant that restricts pinning to registered keys.
+// The standard contract does not have the permissioned method, so the query reverts. The result is cached, as
+// the contract cannot change for the life of the plugin, but only once the connector has given an answer.
+func (e *Ethereum) isPermissioned(ctx context.Context) (bool, error) {
+	e.pinAccessMux.Lock()
+	defer e.pinAccessMux.Unlock()
+	if e.permissioned != nil {
+		return *e.permissioned, nil
+	}
+	res, err := e.queryContractMethod(ctx, e.instancePath, permissionedMethodABI, []interface{}{})
+	permissioned := false
+	switch {
+	case err == nil && res.IsSuccess():
+		if permissioned, err = parseQueryBool(ctx, res.Body()); err != nil {
+			return false, err
+		}
+	case err == nil && isMissingMethodRevert(res):
+	default:
+		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	log.L(ctx).Infof("Batch pin contract %s permissioned=%t", e.instancePath, permissioned)
+	e.permissioned = &permissioned
+	return permissioned, nil
+}
+
+// isRegistered queries whether a key is registered with a permissioned contract. The result is cached for
+// a short time, as the status API reports it on every request.
+func (e *Ethereum) isRegistered(ctx context.Context, signingKey string) (bool, error) {
+	if cached := e.pinAccessCache.Get(signingKey); cached != nil && !cached.Expired() {
+		return cached.Value().(bool), nil
+	}
+	res, err := e.queryContractMethod(ctx, e.instancePath, isRegisteredMethodABI, []interface{}{signingKey})
+	if err != nil || !res.IsSuccess() {
+		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	registered, err := parseQueryBool(ctx, res.Body())
+	if err != nil {
+		return false, err
+	}
+	e.pinAccessCache.Set(signingKey, registered, e.pinAccessCacheTTL)
+	return registered, nil
+}
+
+func (e *Ethereum) PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error) {
+	permissioned, err := e.isPermissioned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !permissioned {
+		return &fftypes.PinAccess{}, nil
+	}
+	registered, err := e.isRegistered(ctx, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &fftypes.PinAccess{
+		Permissioned: true,
+		Key:          signingKey,
+		Registered:   registered,
+	}, nil
+}
+
+func (e *Ethereum) RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error {
+	res, err := e.invokeContractMethod(ctx, e.instancePath, signingKey, registerKeyMethodABI, operationID.String(), []interface{}{signingKey})
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockPinAccessQueries(t *testing.T, permissioned, registered interface{}) {
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "Query", body["headers"].(map[string]interface{})["type"])
+			switch body["method"].(map[string]interface{})["name"] {
+			case "permissioned":
+				if permissioned == nil {
+					return httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "execution reverted"})(req)
+				}
+				return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: permissioned})(req)
+			default:
+				assert.Equal(t, []interface{}{"0x12345"}, body["params"])
+				if registered == nil {
+					return httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"})(req)
+				}
+				return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: registered})(req)
+			}
+		})
+}
+
+func TestPinAccessNotPermissioned(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	mockPinAccessQueries(t, nil, nil)
+
+	access, err := e.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.False(t, access.Permissioned)
+
+	// The detected mode is cached
+	access, err = e.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.False(t, access.Permissioned)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestPinAccessPermissionedRegistered(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	mockPinAccessQueries(t, true, "true")
+
+	access, err := e.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.True(t, access.Permissioned)
+	assert.True(t, access.Registered)
+	assert.Equal(t, "0x12345", access.Key)
+
+	// The registration is cached
+	access, err = e.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.True(t, access.Registered)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestPinAccessPermissionedNotRegistered(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	mockPinAccessQueries(t, true, 0)
+
+	access, err := e.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.True(t, access.Permissioned)
+	assert.False(t, access.Registered)
+}
+
+func TestPinAccessRegisteredQueryFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	mockPinAccessQueries(t, true, nil)
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
+func TestPinAccessRegisteredBadOutput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	permissioned := true
+	e.permissioned = &permissioned
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`, httpmock.NewStringResponder(200, "!json"))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10103", err)
+}
+
+func TestPinAccessPermissionedBadOutput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`, httpmock.NewStringResponder(200, "!json"))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10103", err)
+	assert.Nil(t, e.permissioned)
+}
+
+func TestPinAccessPermissionedServerError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10111.*pop", err)
+	assert.Nil(t, e.permissioned)
+}
+
+func TestPinAccessPermissionedUnauthorized(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`, httpmock.NewStringResponder(401, "Unauthorized"))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10111", err)
+	assert.Nil(t, e.permissioned)
+}
+
+func TestPinAccessPermissionedRevertWithReason(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{
+			"error": "execution reverted: 0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000046f6f707300000000000000000000000000000000000000000000000000000000",
+		}))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10111.*reverted", err)
+	assert.Nil(t, e.permissioned)
+}
+
+func TestPinAccessConnectorFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`, httpmock.NewErrorResponder(fmt.Errorf("pop")))
+
+	_, err := e.PinAccess(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10111.*pop", err)
+	assert.Nil(t, e.permissioned)
+}
+
+func TestRegisterPinKey(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "SendTransaction", headers["type"])
+			assert.Equal(t, opID.String(), headers["id"])
+			assert.Equal(t, "0x12345", body["from"])
+			assert.Equal(t, "registerKey", body["method"].(map[string]interface{})["name"])
+			assert.Equal(t, []interface{}{"0x12345"}, body["params"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+
+	err := e.RegisterPinKey(context.Background(), opID, "0x12345")
+	assert.NoError(t, err)
+}
+
+func TestRegisterPinKeyFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+
+	err := e.RegisterPinKey(context.Background(), fftypes.NewUUID(), "0x12345")
+	assert.Regexp(t, "FF10111.*pop", err)
+}
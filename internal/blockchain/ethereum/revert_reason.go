@@ -0,0 +1,230 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"golang.org/x/crypto/sha3"
+)
+
+const abiWordSize = 32
+
+var (
+	// Error(string) is the standard encoding used by Solidity require() and revert("reason")
+	errorStringSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// Panic(uint256) is raised by Solidity for assertion failures, overflows and the like
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	revertDataRegex = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+
+	panicReasons = map[uint64]string{
+		0x00: "generic compiler panic",
+		0x01: "assertion failed",
+		0x11: "arithmetic overflow or underflow",
+		0x12: "division or modulo by zero",
+		0x21: "invalid enum value",
+		0x22: "invalid storage byte array encoding",
+		0x31: "pop on empty array",
+		0x32: "array index out of bounds",
+		0x41: "out of memory",
+		0x51: "call to uninitialized internal function",
+	}
+)
+
+// FFIErrorDefinitionToABI converts an FFI error definition into its ABI representation
+func (e *Ethereum) FFIErrorDefinitionToABI(ctx context.Context, errorDef *fftypes.FFIErrorDefinition) (ABIElementMarshaling, error) {
+	abiElement := ABIElementMarshaling{
+		Name:   errorDef.Name,
+		Type:   "error",
+		Inputs: make([]ABIArgumentMarshaling, len(errorDef.Params)),
+	}
+	if err := e.addParamsToList(ctx, abiElement.Inputs, errorDef.Params); err != nil {
+		return abiElement, err
+	}
+	return abiElement, nil
+}
+
+// DecodeRevertReason decodes the revert data in the receipt of a failed invocation, which is stored as the output
+// of its operation. Custom errors that cannot be converted to ABI are skipped, rather than failing the decode.
+func (e *Ethereum) DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool) {
+	abiErrors := make([]ABIElementMarshaling, 0, len(errors))
+	for _, errorDef := range errors {
+		abiError, err := e.FFIErrorDefinitionToABI(ctx, errorDef)
+		if err != nil {
+			log.L(ctx).Warnf("Unable to convert error '%s' to ABI: %s", errorDef.Name, err)
+			continue
+		}
+		abiErrors = append(abiErrors, abiError)
+	}
+	return decodeRevertReason(ctx, extractRevertData(opOutput), abiErrors)
+}
+
+// extractRevertData finds the raw revert data for a failed transaction, either as a dedicated
+// field on the receipt, or embedded as a hex string within the error message
+func extractRevertData(reply fftypes.JSONObject) []byte {
+	for _, candidate := range []string{
+		reply.GetString("revertData"),
+		revertDataRegex.FindString(reply.GetString("errorMessage")),
+	} {
+		candidate = strings.TrimPrefix(candidate, "0x")
+		if len(candidate) < 8 || len(candidate)%2 != 0 {
+			continue
+		}
+		if b, err := hex.DecodeString(candidate); err == nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// decodeRevertReason turns the raw revert data of a failed transaction into a readable message,
+// using the supplied custom error definitions for selectors other than Error(string) and Panic(uint256)
+func decodeRevertReason(ctx context.Context, revertData []byte, abiErrors []ABIElementMarshaling) (string, bool) {
+	if len(revertData) < 4 {
+		return "", false
+	}
+	selector, args := revertData[0:4], revertData[4:]
+	switch {
+	case bytes.Equal(selector, errorStringSelector):
+		reason, err := decodeABIValue(args, 0, &ABIArgumentMarshaling{Type: "string"})
+		if err != nil {
+			log.L(ctx).Warnf("Failed to decode Error(string) revert reason: %s", err)
+			return "", false
+		}
+		return reason.(string), true
+	case bytes.Equal(selector, panicSelector):
+		code, err := decodeABIValue(args, 0, &ABIArgumentMarshaling{Type: "uint256"})
+		if err != nil {
+			log.L(ctx).Warnf("Failed to decode Panic(uint256) revert reason: %s", err)
+			return "", false
+		}
+		codeInt, _ := new(big.Int).SetString(code.(string), 10)
+		reason, ok := panicReasons[codeInt.Uint64()]
+		if !ok || !codeInt.IsUint64() {
+			reason = "unknown panic code"
+		}
+		return fmt.Sprintf("Panic(0x%x): %s", codeInt, reason), true
+	}
+	for i := range abiErrors {
+		abiError := &abiErrors[i]
+		if !bytes.Equal(selector, abiSelector(abiError)) {
+			continue
+		}
+		decoded := make([]string, len(abiError.Inputs))
+		for j := range abiError.Inputs {
+			input := &abiError.Inputs[j]
+			value, err := decodeABIValue(args, j*abiWordSize, input)
+			if err != nil {
+				log.L(ctx).Warnf("Failed to decode argument '%s' of error '%s': %s", input.Name, abiError.Name, err)
+				return fmt.Sprintf("%s(0x%s)", abiError.Name, hex.EncodeToString(args)), true
+			}
+			decoded[j] = fmt.Sprintf("%s=%v", input.Name, value)
+		}
+		return fmt.Sprintf("%s(%s)", abiError.Name, strings.Join(decoded, ", ")), true
+	}
+	return "", false
+}
+
+func abiSelector(element *ABIElementMarshaling) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(abiSignature(element.Name, element.Inputs)))
+	return hash.Sum(nil)[0:4]
+}
+
+func abiSignature(name string, args []ABIArgumentMarshaling) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = abiCanonicalType(&arg)
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(types, ","))
+}
+
+func abiCanonicalType(arg *ABIArgumentMarshaling) string {
+	if strings.HasPrefix(arg.Type, "tuple") {
+		return abiSignature("", arg.Components) + strings.TrimPrefix(arg.Type, "tuple")
+	}
+	return arg.Type
+}
+
+func abiWord(data []byte, offset int) ([]byte, error) {
+	if offset < 0 || offset+abiWordSize > len(data) {
+		return nil, fmt.Errorf("insufficient data at offset %d", offset)
+	}
+	return data[offset : offset+abiWordSize], nil
+}
+
+// decodeABIValue decodes an elementary ABI type, or a string/bytes value, from the head slot
+// at the given offset. Arrays and tuples are not supported.
+func decodeABIValue(data []byte, offset int, arg *ABIArgumentMarshaling) (interface{}, error) {
+	word, err := abiWord(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	switch t := arg.Type; {
+	case strings.Contains(t, "[") || strings.HasPrefix(t, "tuple"):
+		return nil, fmt.Errorf("unsupported type %s", t)
+	case t == "string" || t == "bytes":
+		start := new(big.Int).SetBytes(word)
+		if !start.IsInt64() || start.Int64() > int64(len(data)) {
+			return nil, fmt.Errorf("invalid offset for %s", t)
+		}
+		lengthWord, err := abiWord(data, int(start.Int64()))
+		if err != nil {
+			return nil, err
+		}
+		length := new(big.Int).SetBytes(lengthWord)
+		begin := int(start.Int64()) + abiWordSize
+		if !length.IsInt64() || length.Int64() > int64(len(data)-begin) {
+			return nil, fmt.Errorf("invalid length for %s", t)
+		}
+		value := data[begin : begin+int(length.Int64())]
+		if t == "string" {
+			return string(value), nil
+		}
+		return "0x" + hex.EncodeToString(value), nil
+	case t == "address":
+		return "0x" + hex.EncodeToString(word[12:]), nil
+	case t == "bool":
+		return new(big.Int).SetBytes(word).Sign() != 0, nil
+	case strings.HasPrefix(t, "uint"):
+		return new(big.Int).SetBytes(word).String(), nil
+	case strings.HasPrefix(t, "int"):
+		value := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), abiWordSize*8))
+		}
+		return value.String(), nil
+	case strings.HasPrefix(t, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(t, "bytes"))
+		if err != nil || size < 1 || size > abiWordSize {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		return "0x" + hex.EncodeToString(word[0:size]), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
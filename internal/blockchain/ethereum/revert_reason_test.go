@@ -0,0 +1,284 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func abiEncodeWord(v int64) []byte {
+	b := make([]byte, abiWordSize)
+	i := big.NewInt(v)
+	if v < 0 {
+		i.Add(i, new(big.Int).Lsh(big.NewInt(1), abiWordSize*8))
+	}
+	i.FillBytes(b)
+	return b
+}
+
+func abiEncodeErrorString(reason string) []byte {
+	data := append([]byte{}, errorStringSelector...)
+	data = append(data, abiEncodeWord(abiWordSize)...)
+	data = append(data, abiEncodeWord(int64(len(reason)))...)
+	padded := make([]byte, (len(reason)+abiWordSize-1)/abiWordSize*abiWordSize)
+	copy(padded, reason)
+	return append(data, padded...)
+}
+
+func testFFIErrors() []*fftypes.FFIErrorDefinition {
+	return []*fftypes.FFIErrorDefinition{
+		{
+			Name: "InsufficientBalance",
+			Params: fftypes.FFIParams{
+				{
+					Name:   "available",
+					Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+				},
+				{
+					Name:   "required",
+					Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "int256"}}`),
+				},
+			},
+		},
+	}
+}
+
+func TestABISelector(t *testing.T) {
+	transfer := &ABIElementMarshaling{
+		Name: "transfer",
+		Inputs: []ABIArgumentMarshaling{
+			{Name: "to", Type: "address"},
+			{Name: "value", Type: "uint256"},
+		},
+	}
+	assert.Equal(t, "a9059cbb", hex.EncodeToString(abiSelector(transfer)))
+}
+
+func TestABISignatureTuple(t *testing.T) {
+	assert.Equal(t, "Failed((uint256,address)[],bool)", abiSignature("Failed", []ABIArgumentMarshaling{
+		{Type: "tuple[]", Components: []ABIArgumentMarshaling{{Type: "uint256"}, {Type: "address"}}},
+		{Type: "bool"},
+	}))
+}
+
+func TestDecodeRevertReasonErrorString(t *testing.T) {
+	reason, ok := decodeRevertReason(context.Background(), abiEncodeErrorString("Not enough tokens"), nil)
+	assert.True(t, ok)
+	assert.Equal(t, "Not enough tokens", reason)
+}
+
+func TestDecodeRevertReasonErrorStringBad(t *testing.T) {
+	_, ok := decodeRevertReason(context.Background(), append(errorStringSelector, abiEncodeWord(999)...), nil)
+	assert.False(t, ok)
+}
+
+func TestDecodeRevertReasonPanic(t *testing.T) {
+	reason, ok := decodeRevertReason(context.Background(), append(panicSelector, abiEncodeWord(0x11)...), nil)
+	assert.True(t, ok)
+	assert.Equal(t, "Panic(0x11): arithmetic overflow or underflow", reason)
+
+	reason, ok = decodeRevertReason(context.Background(), append(panicSelector, abiEncodeWord(0x99)...), nil)
+	assert.True(t, ok)
+	assert.Equal(t, "Panic(0x99): unknown panic code", reason)
+}
+
+func TestDecodeRevertReasonPanicBad(t *testing.T) {
+	_, ok := decodeRevertReason(context.Background(), panicSelector, nil)
+	assert.False(t, ok)
+}
+
+func TestDecodeRevertReasonTooShort(t *testing.T) {
+	_, ok := decodeRevertReason(context.Background(), []byte{0x01}, nil)
+	assert.False(t, ok)
+}
+
+func TestDecodeRevertReasonCustomError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	abiError, err := e.FFIErrorDefinitionToABI(context.Background(), testFFIErrors()[0])
+	assert.NoError(t, err)
+
+	data := append(abiSelector(&abiError), abiEncodeWord(5)...)
+	data = append(data, abiEncodeWord(-10)...)
+	reason, ok := decodeRevertReason(context.Background(), data, []ABIElementMarshaling{abiError})
+	assert.True(t, ok)
+	assert.Equal(t, "InsufficientBalance(available=5, required=-10)", reason)
+}
+
+func TestDecodeRevertReasonCustomErrorTruncated(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	abiError, err := e.FFIErrorDefinitionToABI(context.Background(), testFFIErrors()[0])
+	assert.NoError(t, err)
+
+	data := append(abiSelector(&abiError), abiEncodeWord(5)...)
+	reason, ok := decodeRevertReason(context.Background(), data, []ABIElementMarshaling{abiError})
+	assert.True(t, ok)
+	assert.Equal(t, "InsufficientBalance(0x0000000000000000000000000000000000000000000000000000000000000005)", reason)
+}
+
+func TestDecodeRevertReasonUnknownSelector(t *testing.T) {
+	_, ok := decodeRevertReason(context.Background(), []byte{0x01, 0x02, 0x03, 0x04}, []ABIElementMarshaling{
+		{Name: "Unauthorized", Type: "error"},
+	})
+	assert.False(t, ok)
+}
+
+func TestDecodeABIValueTypes(t *testing.T) {
+	word := make([]byte, abiWordSize)
+	word[31] = 0x01
+	word[0] = 0xaa
+
+	v, err := decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "address"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0000000000000000000000000000000000000001", v)
+
+	v, err = decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "bool"})
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	v, err = decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "bytes2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0xaa00", v)
+
+	_, err = decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "bytes33"})
+	assert.Regexp(t, "unsupported type", err)
+
+	_, err = decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "uint256[]"})
+	assert.Regexp(t, "unsupported type", err)
+
+	_, err = decodeABIValue(word, 0, &ABIArgumentMarshaling{Type: "function"})
+	assert.Regexp(t, "unsupported type", err)
+
+	data := append(abiEncodeWord(abiWordSize), abiEncodeWord(2)...)
+	data = append(data, word...)
+	v, err = decodeABIValue(data, 0, &ABIArgumentMarshaling{Type: "bytes"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0xaa00", v)
+
+	_, err = decodeABIValue(abiEncodeWord(abiWordSize), 0, &ABIArgumentMarshaling{Type: "bytes"})
+	assert.Regexp(t, "insufficient data", err)
+
+	_, err = decodeABIValue(append(abiEncodeWord(abiWordSize), abiEncodeWord(1000)...), 0, &ABIArgumentMarshaling{Type: "bytes"})
+	assert.Regexp(t, "invalid length", err)
+
+	_, err = decodeABIValue(abiEncodeWord(-1), 0, &ABIArgumentMarshaling{Type: "string"})
+	assert.Regexp(t, "invalid offset", err)
+}
+
+func TestExtractRevertData(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x02, 0x03, 0x04}, extractRevertData(fftypes.JSONObject{
+		"revertData": "0x01020304",
+	}))
+	assert.Equal(t, []byte{0x01, 0x02, 0x03, 0x04}, extractRevertData(fftypes.JSONObject{
+		"errorMessage": "execution reverted: 0x01020304",
+	}))
+	assert.Nil(t, extractRevertData(fftypes.JSONObject{
+		"revertData":   "0x010203045",
+		"errorMessage": "execution reverted",
+	}))
+}
+
+func TestDecodeRevertReasonFromOpOutput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	abiError, err := e.FFIErrorDefinitionToABI(context.Background(), testFFIErrors()[0])
+	assert.NoError(t, err)
+	revertData := append(abiSelector(&abiError), abiEncodeWord(1)...)
+	revertData = append(revertData, abiEncodeWord(2)...)
+	opOutput := fftypes.JSONObject{
+		"errorMessage": "execution reverted",
+		"revertData":   "0x" + hex.EncodeToString(revertData),
+	}
+
+	badError := &fftypes.FFIErrorDefinition{
+		Name: "Bad",
+		Params: fftypes.FFIParams{
+			{Name: "x", Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`)},
+		},
+	}
+	reason, ok := e.DecodeRevertReason(context.Background(), opOutput, append([]*fftypes.FFIErrorDefinition{badError}, testFFIErrors()...))
+	assert.True(t, ok)
+	assert.Equal(t, "InsufficientBalance(available=1, required=2)", reason)
+
+	_, ok = e.DecodeRevertReason(context.Background(), opOutput, nil)
+	assert.False(t, ok)
+}
+
+func TestHandleReceiptCustomErrorUndecoded(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	abiError, err := e.FFIErrorDefinitionToABI(context.Background(), testFFIErrors()[0])
+	assert.NoError(t, err)
+	revertData := append(abiSelector(&abiError), abiEncodeWord(1)...)
+	revertData = append(revertData, abiEncodeWord(2)...)
+
+	operationID := fftypes.NewUUID()
+	var reply fftypes.JSONObject
+	err = json.Unmarshal([]byte(`{
+		"headers": {
+			"requestId": "`+operationID.String()+`",
+			"type": "TransactionFailure"
+		},
+		"transactionHash": "0x123",
+		"errorMessage": "execution reverted",
+		"revertData": "0x`+hex.EncodeToString(revertData)+`"
+	}`), &reply)
+	assert.NoError(t, err)
+
+	// Custom errors are left for the core to decode, with the errors stored on the operation
+	em := e.callbacks.(*blockchainmocks.Callbacks)
+	em.On("BlockchainOpUpdate", operationID, fftypes.OpStatusFailed, "0x123", "execution reverted", mock.Anything).Return(nil)
+
+	err = e.handleReceipt(context.Background(), reply)
+	assert.NoError(t, err)
+	em.AssertExpectations(t)
+}
+
+func TestHandleReceiptErrorString(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	operationID := fftypes.NewUUID()
+	var reply fftypes.JSONObject
+	err := json.Unmarshal([]byte(`{
+		"headers": {
+			"requestId": "`+operationID.String()+`",
+			"type": "TransactionFailure"
+		},
+		"errorMessage": "execution reverted: 0x`+hex.EncodeToString(abiEncodeErrorString("Not allowed"))+`"
+	}`), &reply)
+	assert.NoError(t, err)
+
+	em := e.callbacks.(*blockchainmocks.Callbacks)
+	em.On("BlockchainOpUpdate", operationID, fftypes.OpStatusFailed, "", "Not allowed", mock.Anything).Return(nil)
+
+	err = e.handleReceipt(context.Background(), reply)
+	assert.NoError(t, err)
+	em.AssertExpectations(t)
+}
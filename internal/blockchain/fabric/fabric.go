@@ -155,6 +155,10 @@ func (f *Fabric) VerifierType() fftypes.VerifierType {
 	return fftypes.VerifierTypeMSPIdentity
 }
 
+func (f *Fabric) ChainID() int64 {
+	return 0
+}
+
 func (f *Fabric) Init(ctx context.Context, prefix config.Prefix, callbacks blockchain.Callbacks, metrics metrics.Manager) (err error) {
 	fabconnectConf := prefix.SubPrefix(FabconnectConfigKey)
 
@@ -229,6 +233,13 @@ func (f *Fabric) Capabilities() *blockchain.Capabilities {
 	return f.capabilities
 }
 
+func (f *Fabric) Health(ctx context.Context) error {
+	if state := f.wsconn.State(); state != wsclient.WSStateConnected {
+		return i18n.NewError(ctx, i18n.MsgPluginNotConnected, f.Name(), state)
+	}
+	return nil
+}
+
 func (f *Fabric) afterConnect(ctx context.Context, w wsclient.WSClient) error {
 	// Send a subscribe to our topic after each connect/reconnect
 	b, _ := json.Marshal(&fabWSCommandPayload{
@@ -571,7 +582,7 @@ func (f *Fabric) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID,
 	return nil
 }
 
-func (f *Fabric) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
+func (f *Fabric) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error {
 	// All arguments must be JSON serialized
 	args, err := jsonEncodeInput(input)
 	if err != nil {
@@ -709,11 +720,39 @@ func (f *Fabric) DeleteContractListener(ctx context.Context, subscription *fftyp
 	return f.streams.deleteSubscription(ctx, subscription.ProtocolID)
 }
 
+func (f *Fabric) PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	// Fabconnect does not provide a way to suspend an individual subscription
+	return i18n.NewError(ctx, i18n.MsgContractListenerPauseUnsup)
+}
+
+func (f *Fabric) ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	return i18n.NewError(ctx, i18n.MsgContractListenerPauseUnsup)
+}
+
 func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	// Fabconnect does not require any additional validation beyond "JSON Schema correctness" at this time
 	return nil, nil
 }
 
+// DecodeRevertReason is not supported, as chaincode reports its errors as readable messages
+func (f *Fabric) DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool) {
+	return "", false
+}
+
+func (f *Fabric) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error {
+	// Chaincode is installed and approved through the Fabric lifecycle, rather than deployed by a transaction
+	return i18n.NewError(ctx, i18n.MsgContractDeployUnsupported)
+}
+
 func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationUnsupported)
 }
+
+func (f *Fabric) PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error) {
+	// Access to the chaincode is controlled by the channel membership, rather than by the chaincode itself
+	return &fftypes.PinAccess{}, nil
+}
+
+func (f *Fabric) RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error {
+	return i18n.NewError(ctx, i18n.MsgPinAccessNotPermissioned, f.Name())
+}
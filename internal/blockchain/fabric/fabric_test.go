@@ -1197,6 +1197,20 @@ func TestDeleteSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPauseResumeSubscriptionUnsupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	err := e.PauseContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10412", err)
+	err = e.ResumeContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10412", err)
+}
+
 func TestDeleteSubscriptionFail(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -1377,7 +1391,7 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, "test", body["args"].(map[string]interface{})["description"])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.NoError(t, err)
 }
 
@@ -1408,7 +1422,7 @@ func TestInvokeContractBadSchema(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10151", err)
 }
 
@@ -1424,7 +1438,7 @@ func TestInvokeContractChaincodeNotSet(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10310", err)
 }
 
@@ -1449,7 +1463,7 @@ func TestInvokeContractFabconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10284", err)
 }
 
@@ -1623,7 +1637,7 @@ func TestInvokeJSONEncodeParamsError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10151", err)
 }
 
@@ -1634,6 +1648,12 @@ func TestGetFFIParamValidator(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeployContract(t *testing.T) {
+	e, _ := newTestFabric()
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "signer", nil, fftypes.JSONAnyPtr(`"chaincode"`), nil, nil)
+	assert.Regexp(t, "FF10428", err)
+}
+
 func TestGenerateFFI(t *testing.T) {
 	e, _ := newTestFabric()
 	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
@@ -1644,3 +1664,35 @@ func TestGenerateFFI(t *testing.T) {
 	})
 	assert.Regexp(t, "FF10347", err)
 }
+
+func TestDecodeRevertReasonUnsupported(t *testing.T) {
+	e, _ := newTestFabric()
+	_, ok := e.DecodeRevertReason(context.Background(), fftypes.JSONObject{}, []*fftypes.FFIErrorDefinition{{Name: "Unauthorized"}})
+	assert.False(t, ok)
+}
+
+func TestChainIDNotApplicable(t *testing.T) {
+	e, _ := newTestFabric()
+	assert.Equal(t, int64(0), e.ChainID())
+}
+
+func TestHealth(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	mws := e.wsconn.(*wsmocks.WSClient)
+	mws.On("State").Return(wsclient.WSStateConnected).Once()
+	assert.NoError(t, e.Health(context.Background()))
+
+	mws.On("State").Return(wsclient.WSStateDisconnected).Once()
+	assert.Regexp(t, "FF10389.*disconnected", e.Health(context.Background()))
+}
+
+func TestPinAccess(t *testing.T) {
+	e, _ := newTestFabric()
+	access, err := e.PinAccess(context.Background(), "signer")
+	assert.NoError(t, err)
+	assert.False(t, access.Permissioned)
+	err = e.RegisterPinKey(context.Background(), fftypes.NewUUID(), "signer")
+	assert.Regexp(t, "FF10517", err)
+}
@@ -0,0 +1,39 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffconnector
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+)
+
+const (
+	// FFConnectorConfigKey is a sub-key in the config to contain all the ffconnector specific config
+	FFConnectorConfigKey = "ffconnector"
+
+	// FFConnectorConfigVerifierType is the type of signing key the connector resolves and signs with, such as ethereum_address
+	FFConnectorConfigVerifierType = "verifierType"
+	// FFConnectorConfigGlobalSequencer is whether the connector's chain is visible to all participants, so can be used to sequence batch pins
+	FFConnectorConfigGlobalSequencer = "globalSequencer"
+)
+
+func (c *FFConnector) InitPrefix(prefix config.Prefix) {
+	connectorConf := prefix.SubPrefix(FFConnectorConfigKey)
+	wsconfig.InitPrefix(connectorConf)
+	connectorConf.AddKnownKey(FFConnectorConfigVerifierType)
+	connectorConf.AddKnownKey(FFConnectorConfigGlobalSequencer, true)
+}
@@ -0,0 +1,516 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffconnector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+)
+
+// FFConnector is a blockchain plugin that delegates all chain specific logic to a connector
+// microservice, via the REST and WebSocket contract documented in docs/reference/ffconnector.md.
+// This allows a new chain to be supported without adding Go code to the core.
+type FFConnector struct {
+	ctx          context.Context
+	capabilities *blockchain.Capabilities
+	callbacks    blockchain.Callbacks
+	verifierType fftypes.VerifierType
+	client       *resty.Client
+	wsconn       wsclient.WSClient
+	metrics      metrics.Manager
+}
+
+type wsEvent struct {
+	Event msgType            `json:"event"`
+	ID    string             `json:"id"`
+	Data  fftypes.JSONObject `json:"data"`
+}
+
+type msgType string
+
+const (
+	messageReceipt       msgType = "receipt"
+	messageBatchPin      msgType = "batchpin"
+	messageContractEvent msgType = "contract-event"
+)
+
+type resolveKey struct {
+	Key string `json:"key"`
+}
+
+type submitBatchPin struct {
+	RequestID     string             `json:"requestId"`
+	Signer        string             `json:"signer"`
+	Ledger        *fftypes.UUID      `json:"ledger,omitempty"`
+	Namespace     string             `json:"namespace"`
+	TransactionID *fftypes.UUID      `json:"transactionId"`
+	BatchID       *fftypes.UUID      `json:"batchId"`
+	BatchHash     *fftypes.Bytes32   `json:"batchHash"`
+	PayloadRef    string             `json:"payloadRef"`
+	Contexts      []*fftypes.Bytes32 `json:"contexts"`
+}
+
+type invokeContract struct {
+	RequestID string                        `json:"requestId"`
+	Signer    string                        `json:"signer"`
+	Location  *fftypes.JSONAny              `json:"location"`
+	Method    *fftypes.FFIMethod            `json:"method"`
+	Params    map[string]interface{}        `json:"params"`
+	Errors    []*fftypes.FFIErrorDefinition `json:"errors,omitempty"`
+}
+
+type deployContract struct {
+	RequestID   string             `json:"requestId"`
+	Signer      string             `json:"signer"`
+	Definition  *fftypes.JSONAny   `json:"definition,omitempty"`
+	Contract    *fftypes.JSONAny   `json:"contract"`
+	Constructor *fftypes.FFIMethod `json:"constructor,omitempty"`
+	Params      []interface{}      `json:"params"`
+}
+
+type queryContract struct {
+	Location *fftypes.JSONAny       `json:"location"`
+	Method   *fftypes.FFIMethod     `json:"method"`
+	Params   map[string]interface{} `json:"params"`
+}
+
+type queryResult struct {
+	Result interface{} `json:"result"`
+}
+
+type addListener struct {
+	Namespace  string                      `json:"namespace"`
+	Name       string                      `json:"name,omitempty"`
+	Location   *fftypes.JSONAny            `json:"location,omitempty"`
+	Event      *fftypes.FFISerializedEvent `json:"event"`
+	FirstEvent string                      `json:"firstEvent,omitempty"`
+}
+
+type listenerResult struct {
+	ID string `json:"id"`
+}
+
+func (c *FFConnector) Name() string {
+	return "ffconnector"
+}
+
+func (c *FFConnector) VerifierType() fftypes.VerifierType {
+	return c.verifierType
+}
+
+func (c *FFConnector) ChainID() int64 {
+	return 0
+}
+
+func (c *FFConnector) Init(ctx context.Context, prefix config.Prefix, callbacks blockchain.Callbacks, metrics metrics.Manager) (err error) {
+	connectorConf := prefix.SubPrefix(FFConnectorConfigKey)
+
+	c.ctx = log.WithLogField(ctx, "proto", "ffconnector")
+	c.callbacks = callbacks
+	c.metrics = metrics
+
+	if connectorConf.GetString(restclient.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "blockchain.ffconnector")
+	}
+	verifierType := connectorConf.GetString(FFConnectorConfigVerifierType)
+	if verifierType == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "verifierType", "blockchain.ffconnector")
+	}
+	c.verifierType = fftypes.FFEnum(verifierType).Lower()
+	if !validVerifierType(c.verifierType) {
+		return i18n.NewError(ctx, i18n.MsgInvalidVerifierTypeConfig, verifierType, "blockchain.ffconnector")
+	}
+
+	c.client = restclient.New(c.ctx, connectorConf)
+	c.capabilities = &blockchain.Capabilities{
+		GlobalSequencer: connectorConf.GetBool(FFConnectorConfigGlobalSequencer),
+	}
+
+	wsConfig := wsconfig.GenerateConfigFromPrefix(connectorConf)
+
+	if wsConfig.WSKeyPath == "" {
+		wsConfig.WSKeyPath = "/api/ws"
+	}
+
+	c.wsconn, err = wsclient.New(ctx, wsConfig, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	go c.eventLoop()
+
+	return nil
+}
+
+func validVerifierType(verifierType fftypes.VerifierType) bool {
+	for _, v := range fftypes.FFEnumValues("verifiertype") {
+		if v == verifierType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FFConnector) Start() error {
+	return c.wsconn.Connect()
+}
+
+func (c *FFConnector) Capabilities() *blockchain.Capabilities {
+	return c.capabilities
+}
+
+func (c *FFConnector) Health(ctx context.Context) error {
+	if state := c.wsconn.State(); state != wsclient.WSStateConnected {
+		return i18n.NewError(ctx, i18n.MsgPluginNotConnected, c.Name(), state)
+	}
+	return nil
+}
+
+func parseEvent(ctx context.Context, source string, data fftypes.JSONObject) blockchain.Event {
+	timestampStr := data.GetString("timestamp")
+	timestamp, err := fftypes.ParseTimeString(timestampStr)
+	if err != nil {
+		log.L(ctx).Warnf("Event has invalid timestamp '%s' - using current time", timestampStr)
+		timestamp = fftypes.Now()
+	}
+	return blockchain.Event{
+		BlockchainTXID: data.GetString("transactionHash"),
+		Source:         source,
+		Name:           data.GetString("name"),
+		ProtocolID:     data.GetString("protocolId"),
+		Output:         data.GetObject("output"),
+		Info:           data.GetObject("info"),
+		Timestamp:      timestamp,
+		Location:       data.GetString("location"),
+		Signature:      data.GetString("signature"),
+	}
+}
+
+func (c *FFConnector) handleReceipt(ctx context.Context, data fftypes.JSONObject) error {
+	l := log.L(ctx)
+
+	requestID := data.GetString("id")
+	success := data.GetBool("success")
+	message := data.GetString("message")
+	transactionHash := data.GetString("transactionHash")
+	if requestID == "" {
+		l.Errorf("Reply cannot be processed - missing fields: %+v", data)
+		return nil // Swallow this and move on
+	}
+	opID, err := fftypes.ParseUUID(ctx, requestID)
+	if err != nil {
+		l.Errorf("Reply cannot be processed - bad ID: %+v", data)
+		return nil // Swallow this and move on
+	}
+	replyType := fftypes.OpStatusSucceeded
+	if !success {
+		replyType = fftypes.OpStatusFailed
+	}
+	l.Infof("Connector '%s' reply: request=%s tx=%s message=%s", replyType, requestID, transactionHash, message)
+	return c.callbacks.BlockchainOpUpdate(opID, replyType, transactionHash, message, data)
+}
+
+func (c *FFConnector) handleBatchPin(ctx context.Context, data fftypes.JSONObject) error {
+	l := log.L(ctx)
+
+	var txnID, batchID fftypes.UUID
+	var batchHash fftypes.Bytes32
+	if err := txnID.UnmarshalText([]byte(data.GetString("transactionId"))); err != nil {
+		l.Errorf("BatchPin event is not valid - bad transactionId: %+v", data)
+		return nil // move on
+	}
+	if err := batchID.UnmarshalText([]byte(data.GetString("batchId"))); err != nil {
+		l.Errorf("BatchPin event is not valid - bad batchId: %+v", data)
+		return nil // move on
+	}
+	if err := batchHash.UnmarshalText([]byte(data.GetString("batchHash"))); err != nil {
+		l.Errorf("BatchPin event is not valid - bad batchHash: %+v", data)
+		return nil // move on
+	}
+	sContexts := data.GetStringArray("contexts")
+	contexts := make([]*fftypes.Bytes32, len(sContexts))
+	for i, sHash := range sContexts {
+		var hash fftypes.Bytes32
+		if err := hash.UnmarshalText([]byte(sHash)); err != nil {
+			l.Errorf("BatchPin event is not valid - bad pin %d (%s): %s", i, sHash, err)
+			return nil // move on
+		}
+		contexts[i] = &hash
+	}
+	signer := data.GetString("signer")
+	if signer == "" {
+		l.Errorf("BatchPin event is not valid - missing signer: %+v", data)
+		return nil // move on
+	}
+
+	batch := &blockchain.BatchPin{
+		Namespace:       data.GetString("namespace"),
+		TransactionID:   &txnID,
+		BatchID:         &batchID,
+		BatchHash:       &batchHash,
+		BatchPayloadRef: data.GetString("payloadRef"),
+		Contexts:        contexts,
+		Event:           parseEvent(ctx, c.Name(), data.GetObject("blockchainEvent")),
+	}
+
+	// If there's an error dispatching the event, we must return the error and shutdown
+	return c.callbacks.BatchPinComplete(batch, &fftypes.VerifierRef{
+		Type:  c.verifierType,
+		Value: signer,
+	})
+}
+
+func (c *FFConnector) handleContractEvent(ctx context.Context, data fftypes.JSONObject) error {
+	listenerID := data.GetString("listenerId")
+	if listenerID == "" {
+		log.L(ctx).Errorf("Contract event is not valid - missing listenerId: %+v", data)
+		return nil // move on
+	}
+	return c.callbacks.BlockchainEvent(&blockchain.EventWithSubscription{
+		Subscription: listenerID,
+		Event:        parseEvent(ctx, c.Name(), data.GetObject("blockchainEvent")),
+	})
+}
+
+func (c *FFConnector) eventLoop() {
+	defer c.wsconn.Close()
+	l := log.L(c.ctx).WithField("role", "event-loop")
+	ctx := log.WithLogger(c.ctx, l)
+	for {
+		select {
+		case <-ctx.Done():
+			l.Debugf("Event loop exiting (context cancelled)")
+			return
+		case msgBytes, ok := <-c.wsconn.Receive():
+			if !ok {
+				l.Debugf("Event loop exiting (receive channel closed)")
+				return
+			}
+
+			var msg wsEvent
+			err := json.Unmarshal(msgBytes, &msg)
+			if err != nil {
+				l.Errorf("Message cannot be parsed as JSON: %s\n%s", err, string(msgBytes))
+				continue // Swallow this and move on
+			}
+			l.Debugf("Received %s event %s", msg.Event, msg.ID)
+			switch msg.Event {
+			case messageReceipt:
+				err = c.handleReceipt(ctx, msg.Data)
+			case messageBatchPin:
+				err = c.handleBatchPin(ctx, msg.Data)
+			case messageContractEvent:
+				err = c.handleContractEvent(ctx, msg.Data)
+			default:
+				l.Errorf("Message unexpected: %s", msg.Event)
+			}
+
+			if err == nil && msg.Event != messageReceipt && msg.ID != "" {
+				l.Debugf("Sending ack %s", msg.ID)
+				ack, _ := json.Marshal(fftypes.JSONObject{
+					"event": "ack",
+					"data": fftypes.JSONObject{
+						"id": msg.ID,
+					},
+				})
+				err = c.wsconn.Send(ctx, ack)
+			}
+
+			if err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
+		}
+	}
+}
+
+func (c *FFConnector) NormalizeSigningKey(ctx context.Context, keyRef string) (string, error) {
+	var resolved resolveKey
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(&resolveKey{Key: keyRef}).
+		SetResult(&resolved).
+		Post("/api/v1/resolvekey")
+	if err != nil || !res.IsSuccess() {
+		return "", restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return resolved.Key, nil
+}
+
+func (c *FFConnector) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID, ledgerID *fftypes.UUID, signingKey string, batch *blockchain.BatchPin) error {
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(&submitBatchPin{
+			RequestID:     operationID.String(),
+			Signer:        signingKey,
+			Ledger:        ledgerID,
+			Namespace:     batch.Namespace,
+			TransactionID: batch.TransactionID,
+			BatchID:       batch.BatchID,
+			BatchHash:     batch.BatchHash,
+			PayloadRef:    batch.BatchPayloadRef,
+			Contexts:      batch.Contexts,
+		}).
+		Post("/api/v1/batchpin")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+func (c *FFConnector) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error {
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(&invokeContract{
+			RequestID: operationID.String(),
+			Signer:    signingKey,
+			Location:  location,
+			Method:    method,
+			Params:    input,
+			Errors:    errors,
+		}).
+		Post("/api/v1/invoke")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+// DecodeRevertReason is not needed, as the connector decodes the failure of an invocation using the errors supplied with it
+func (c *FFConnector) DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool) {
+	return "", false
+}
+
+func (c *FFConnector) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error {
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(&deployContract{
+			RequestID:   operationID.String(),
+			Signer:      signingKey,
+			Definition:  definition,
+			Contract:    contract,
+			Constructor: constructor,
+			Params:      input,
+		}).
+		Post("/api/v1/deploy")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+func (c *FFConnector) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
+	var result queryResult
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(&queryContract{
+			Location: location,
+			Method:   method,
+			Params:   input,
+		}).
+		SetResult(&result).
+		Post("/api/v1/query")
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return result.Result, nil
+}
+
+func (c *FFConnector) AddContractListener(ctx context.Context, listener *fftypes.ContractListenerInput) error {
+	body := &addListener{
+		Namespace: listener.Namespace,
+		Name:      listener.Name,
+		Location:  listener.Location,
+		Event:     listener.Event,
+	}
+	if listener.Options != nil {
+		body.FirstEvent = listener.Options.FirstEvent
+	}
+	var result listenerResult
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(body).
+		SetResult(&result).
+		Post("/api/v1/listeners")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	listener.ProtocolID = result.ID
+	return nil
+}
+
+func (c *FFConnector) DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	res, err := c.client.R().SetContext(ctx).
+		Delete(fmt.Sprintf("/api/v1/listeners/%s", subscription.ProtocolID))
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+func (c *FFConnector) PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	res, err := c.client.R().SetContext(ctx).
+		Post(fmt.Sprintf("/api/v1/listeners/%s/pause", subscription.ProtocolID))
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+func (c *FFConnector) ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	res, err := c.client.R().SetContext(ctx).
+		Post(fmt.Sprintf("/api/v1/listeners/%s/resume", subscription.ProtocolID))
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return nil
+}
+
+func (c *FFConnector) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
+	// The connector validates params against the method schema on each request
+	return nil, nil
+}
+
+func (c *FFConnector) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
+	var ffi fftypes.FFI
+	res, err := c.client.R().SetContext(ctx).
+		SetBody(generationRequest).
+		SetResult(&ffi).
+		Post("/api/v1/generateffi")
+	if err != nil || !res.IsSuccess() {
+		if res != nil && res.StatusCode() == 404 {
+			return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationUnsupported)
+		}
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgFFConnectorRESTErr)
+	}
+	return &ffi, nil
+}
+
+func (c *FFConnector) PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error) {
+	// The connector API does not expose key registration for batch pinning
+	return &fftypes.PinAccess{}, nil
+}
+
+func (c *FFConnector) RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error {
+	return i18n.NewError(ctx, i18n.MsgPinAccessNotPermissioned, c.Name())
+}
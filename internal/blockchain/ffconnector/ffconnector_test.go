@@ -0,0 +1,673 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffconnector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("ffconnector_unit_tests")
+var utConnectorConf = utConfPrefix.SubPrefix(FFConnectorConfigKey)
+
+func resetConf(c *FFConnector) {
+	config.Reset()
+	c.InitPrefix(utConfPrefix)
+}
+
+func newTestFFConnector(t *testing.T) (c *FFConnector, toServer, fromServer chan string, httpURL string, done func()) {
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+
+	toServer, fromServer, wsURL, cancel := wsclient.NewTestWSServer(nil)
+
+	u, _ := url.Parse(wsURL)
+	u.Scheme = "http"
+	httpURL = u.String()
+
+	c = &FFConnector{}
+	resetConf(c)
+	utConnectorConf.Set(restclient.HTTPConfigURL, httpURL)
+	utConnectorConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utConnectorConf.Set(FFConnectorConfigVerifierType, "Ethereum_Address")
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := c.Init(ctx, utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ffconnector", c.Name())
+	assert.Equal(t, fftypes.VerifierTypeEthAddress, c.VerifierType())
+	assert.True(t, c.Capabilities().GlobalSequencer)
+	return c, toServer, fromServer, httpURL, func() {
+		cancelCtx()
+		cancel()
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func TestInitMissingURL(t *testing.T) {
+	c := &FFConnector{}
+	resetConf(c)
+	err := c.Init(context.Background(), utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestInitMissingVerifierType(t *testing.T) {
+	c := &FFConnector{}
+	resetConf(c)
+	utConnectorConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	err := c.Init(context.Background(), utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10138.*verifierType", err)
+}
+
+func TestInitBadVerifierType(t *testing.T) {
+	c := &FFConnector{}
+	resetConf(c)
+	utConnectorConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConnectorConf.Set(FFConnectorConfigVerifierType, "wrong")
+	err := c.Init(context.Background(), utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10406.*wrong", err)
+}
+
+func TestInitBadURL(t *testing.T) {
+	c := &FFConnector{}
+	resetConf(c)
+	utConnectorConf.Set(restclient.HTTPConfigURL, "::::////")
+	utConnectorConf.Set(FFConnectorConfigVerifierType, "ethereum_address")
+	err := c.Init(context.Background(), utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.Regexp(t, "FF10162", err)
+}
+
+func TestHealth(t *testing.T) {
+	c, _, _, _, done := newTestFFConnector(t)
+	defer done()
+
+	err := c.Health(context.Background())
+	assert.Regexp(t, "FF10389", err)
+
+	err = c.Start()
+	assert.NoError(t, err)
+	err = c.Health(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestNormalizeSigningKey(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/resolvekey", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "Key1", body.GetString("key"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"key": "key1"})(req)
+		})
+
+	key, err := c.NormalizeSigningKey(context.Background(), "Key1")
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", key)
+}
+
+func TestNormalizeSigningKeyFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/resolvekey", httpURL),
+		httpmock.NewJsonResponderOrPanic(400, fftypes.JSONObject{"error": "bad key"}))
+
+	_, err := c.NormalizeSigningKey(context.Background(), "wrong")
+	assert.Regexp(t, "FF10405.*bad key", err)
+}
+
+func TestSubmitBatchPin(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	opID := fftypes.NewUUID()
+	ledgerID := fftypes.NewUUID()
+	batch := &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Contexts:        []*fftypes.Bytes32{fftypes.NewRandB32()},
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/batchpin", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, fftypes.JSONObject{
+				"requestId":     opID.String(),
+				"signer":        "0x123",
+				"ledger":        ledgerID.String(),
+				"namespace":     "ns1",
+				"transactionId": batch.TransactionID.String(),
+				"batchId":       batch.BatchID.String(),
+				"batchHash":     batch.BatchHash.String(),
+				"payloadRef":    batch.BatchPayloadRef,
+				"contexts":      []interface{}{batch.Contexts[0].String()},
+			}, body)
+			return httpmock.NewStringResponse(202, ""), nil
+		})
+
+	err := c.SubmitBatchPin(context.Background(), opID, ledgerID, "0x123", batch)
+	assert.NoError(t, err)
+}
+
+func TestSubmitBatchPinFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/batchpin", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.SubmitBatchPin(context.Background(), fftypes.NewUUID(), nil, "0x123", &blockchain.BatchPin{})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestInvokeContract(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	opID := fftypes.NewUUID()
+	location := fftypes.JSONAnyPtr(`{"address":"0x12345"}`)
+	method := &fftypes.FFIMethod{Name: "set"}
+	errors := []*fftypes.FFIErrorDefinition{{Name: "CustomError"}}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/invoke", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, opID.String(), body.GetString("requestId"))
+			assert.Equal(t, "0x123", body.GetString("signer"))
+			assert.Equal(t, "0x12345", body.GetObject("location").GetString("address"))
+			assert.Equal(t, "set", body.GetObject("method").GetString("name"))
+			assert.Equal(t, "1", body.GetObject("params").GetString("x"))
+			assert.Equal(t, "CustomError", body.GetObjectArray("errors")[0].GetString("name"))
+			return httpmock.NewStringResponse(202, ""), nil
+		})
+
+	err := c.InvokeContract(context.Background(), opID, "0x123", location, method, map[string]interface{}{"x": "1"}, errors)
+	assert.NoError(t, err)
+}
+
+func TestInvokeContractFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/invoke", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.InvokeContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr("{}"), &fftypes.FFIMethod{}, nil, nil)
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestDeployContract(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	opID := fftypes.NewUUID()
+	constructor := &fftypes.FFIMethod{Name: "constructor"}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/deploy", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, opID.String(), body.GetString("requestId"))
+			assert.Equal(t, "0x123", body.GetString("signer"))
+			assert.Equal(t, "0x6080", body.GetString("contract"))
+			assert.Equal(t, "constructor", body.GetObject("constructor").GetString("name"))
+			assert.Nil(t, body["definition"])
+			assert.Equal(t, []interface{}{"1"}, body["params"])
+			return httpmock.NewStringResponse(202, ""), nil
+		})
+
+	err := c.DeployContract(context.Background(), opID, "0x123", nil, fftypes.JSONAnyPtr(`"0x6080"`), constructor, []interface{}{"1"})
+	assert.NoError(t, err)
+}
+
+func TestDeployContractFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/deploy", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr("[]"), fftypes.JSONAnyPtr(`"0x6080"`), nil, nil)
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestQueryContract(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/query", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "get", body.GetObject("method").GetString("name"))
+			assert.Equal(t, "0x12345", body.GetObject("location").GetString("address"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"result": "3"})(req)
+		})
+
+	result, err := c.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{"address":"0x12345"}`), &fftypes.FFIMethod{Name: "get"}, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", result)
+}
+
+func TestQueryContractFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/query", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	_, err := c.QueryContract(context.Background(), fftypes.JSONAnyPtr("{}"), &fftypes.FFIMethod{}, nil)
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestAddContractListener(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	listener := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Namespace: "ns1",
+			Name:      "listener1",
+			Location:  fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{Name: "Changed"},
+			},
+			Options: &fftypes.ContractListenerOptions{FirstEvent: "newest"},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "ns1", body.GetString("namespace"))
+			assert.Equal(t, "listener1", body.GetString("name"))
+			assert.Equal(t, "Changed", body.GetObject("event").GetString("name"))
+			assert.Equal(t, "newest", body.GetString("firstEvent"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"id": "sub1"})(req)
+		})
+
+	err := c.AddContractListener(context.Background(), listener)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", listener.ProtocolID)
+}
+
+func TestAddContractListenerFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.AddContractListener(context.Background(), &fftypes.ContractListenerInput{})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestDeleteContractListener(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/listeners/sub1", httpURL),
+		httpmock.NewStringResponder(204, ""))
+
+	err := c.DeleteContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.NoError(t, err)
+}
+
+func TestDeleteContractListenerFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/listeners/sub1", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.DeleteContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestPauseContractListener(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners/sub1/pause", httpURL),
+		httpmock.NewStringResponder(204, ""))
+
+	err := c.PauseContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.NoError(t, err)
+}
+
+func TestPauseContractListenerFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners/sub1/pause", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.PauseContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestResumeContractListener(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners/sub1/resume", httpURL),
+		httpmock.NewStringResponder(204, ""))
+
+	err := c.ResumeContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.NoError(t, err)
+}
+
+func TestResumeContractListenerFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/listeners/sub1/resume", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	err := c.ResumeContractListener(context.Background(), &fftypes.ContractListener{ProtocolID: "sub1"})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestGetFFIParamValidator(t *testing.T) {
+	c, _, _, _, done := newTestFFConnector(t)
+	defer done()
+
+	v, err := c.GetFFIParamValidator(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestGenerateFFI(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/generateffi", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "iface1", body.GetString("name"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"name": "iface1", "version": "1.0"})(req)
+		})
+
+	ffi, err := c.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{Name: "iface1", Version: "1.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "iface1", ffi.Name)
+	assert.Equal(t, "1.0", ffi.Version)
+}
+
+func TestDecodeRevertReasonUnsupported(t *testing.T) {
+	c, _, _, _, done := newTestFFConnector(t)
+	defer done()
+
+	_, ok := c.DecodeRevertReason(context.Background(), fftypes.JSONObject{}, []*fftypes.FFIErrorDefinition{{Name: "Unauthorized"}})
+	assert.False(t, ok)
+}
+
+func TestChainIDNotApplicable(t *testing.T) {
+	c, _, _, _, done := newTestFFConnector(t)
+	defer done()
+
+	assert.Equal(t, int64(0), c.ChainID())
+}
+
+func TestGenerateFFIUnsupported(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/generateffi", httpURL),
+		httpmock.NewStringResponder(404, `{"error":"not found"}`))
+
+	_, err := c.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{})
+	assert.Regexp(t, "FF10347", err)
+}
+
+func TestGenerateFFIFail(t *testing.T) {
+	c, _, _, httpURL, done := newTestFFConnector(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/generateffi", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	_, err := c.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{})
+	assert.Regexp(t, "FF10405.*pop", err)
+}
+
+func TestEvents(t *testing.T) {
+	c, toServer, fromServer, _, done := newTestFFConnector(t)
+	defer done()
+
+	err := c.Start()
+	assert.NoError(t, err)
+
+	fromServer <- `!}`         // ignored
+	fromServer <- `{}`         // ignored
+	fromServer <- `{"id":"1"}` // ignored but acked
+	msg := <-toServer
+	assert.Equal(t, `{"data":{"id":"1"},"event":"ack"}`, string(msg))
+
+	mcb := c.callbacks.(*blockchainmocks.Callbacks)
+	opID := fftypes.NewUUID()
+
+	// receipt: bad ID - passed through
+	fromServer <- `{"id":"2","event":"receipt","data":{"id":"abc"}}`
+	// receipt: missing ID - passed through
+	fromServer <- `{"id":"3","event":"receipt","data":{}}`
+
+	// receipt: success
+	mcb.On("BlockchainOpUpdate", opID, fftypes.OpStatusSucceeded, "0xffffeeee", "", mock.Anything).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{
+		"id":    "4",
+		"event": "receipt",
+		"data": fftypes.JSONObject{
+			"id":              opID.String(),
+			"success":         true,
+			"transactionHash": "0xffffeeee",
+		},
+	}.String()
+
+	// receipt: failure
+	mcb.On("BlockchainOpUpdate", opID, fftypes.OpStatusFailed, "", "reverted", mock.Anything).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{
+		"id":    "5",
+		"event": "receipt",
+		"data": fftypes.JSONObject{
+			"id":      opID.String(),
+			"success": false,
+			"message": "reverted",
+		},
+	}.String()
+
+	txID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	batchHash := fftypes.NewRandB32()
+	pin := fftypes.NewRandB32()
+	batchPinData := func() fftypes.JSONObject {
+		return fftypes.JSONObject{
+			"namespace":     "ns1",
+			"transactionId": txID.String(),
+			"batchId":       batchID.String(),
+			"batchHash":     batchHash.String(),
+			"payloadRef":    "ref1",
+			"contexts":      []interface{}{pin.String()},
+			"signer":        "0x123",
+			"blockchainEvent": fftypes.JSONObject{
+				"name":            "BatchPin",
+				"protocolId":      "000000000001/000000/000000",
+				"transactionHash": "0xffffeeee",
+				"location":        "address=0x12345",
+				"signature":       "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+				"output":          fftypes.JSONObject{"author": "0x123"},
+				"info":            fftypes.JSONObject{"blockNumber": "1"},
+				"timestamp":       "2022-05-01T00:00:00Z",
+			},
+		}
+	}
+
+	// batchpin: invalid fields - acked but not dispatched
+	for i, field := range []string{"transactionId", "batchId", "batchHash", "contexts", "signer"} {
+		data := batchPinData()
+		switch field {
+		case "contexts":
+			data[field] = []interface{}{"bad"}
+		case "signer":
+			delete(data, field)
+		default:
+			data[field] = "bad"
+		}
+		id := fmt.Sprintf("6.%d", i)
+		fromServer <- fftypes.JSONObject{"id": id, "event": "batchpin", "data": data}.String()
+		msg := <-toServer
+		assert.Equal(t, `{"data":{"id":"`+id+`"},"event":"ack"}`, string(msg))
+	}
+
+	// batchpin: success
+	mcb.On("BatchPinComplete", mock.MatchedBy(func(b *blockchain.BatchPin) bool {
+		return b.Namespace == "ns1" &&
+			*b.TransactionID == *txID &&
+			*b.BatchID == *batchID &&
+			*b.BatchHash == *batchHash &&
+			b.BatchPayloadRef == "ref1" &&
+			*b.Contexts[0] == *pin &&
+			b.Event.Source == "ffconnector" &&
+			b.Event.Name == "BatchPin" &&
+			b.Event.ProtocolID == "000000000001/000000/000000" &&
+			b.Event.BlockchainTXID == "0xffffeeee" &&
+			b.Event.Location == "address=0x12345" &&
+			b.Event.Output.GetString("author") == "0x123" &&
+			b.Event.Info.GetString("blockNumber") == "1" &&
+			b.Event.Timestamp.String() == "2022-05-01T00:00:00Z"
+	}), &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x123",
+	}).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{"id": "7", "event": "batchpin", "data": batchPinData()}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"7"},"event":"ack"}`, string(msg))
+
+	// contract-event: missing listener - acked but not dispatched
+	fromServer <- `{"id":"8","event":"contract-event","data":{}}`
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"8"},"event":"ack"}`, string(msg))
+
+	// contract-event: success, with bad timestamp
+	mcb.On("BlockchainEvent", mock.MatchedBy(func(e *blockchain.EventWithSubscription) bool {
+		return e.Subscription == "sub1" &&
+			e.Name == "Changed" &&
+			e.Source == "ffconnector" &&
+			e.Timestamp != nil
+	})).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{
+		"id":    "9",
+		"event": "contract-event",
+		"data": fftypes.JSONObject{
+			"listenerId": "sub1",
+			"blockchainEvent": fftypes.JSONObject{
+				"name":      "Changed",
+				"timestamp": "bad",
+			},
+		},
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"9"},"event":"ack"}`, string(msg))
+
+	mcb.AssertExpectations(t)
+}
+
+func TestEventLoopCallbackError(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	receive := make(chan []byte, 1)
+	var rc <-chan []byte = receive
+	wsm.On("Receive").Return(rc)
+	wsm.On("Close").Return()
+	mcb := &blockchainmocks.Callbacks{}
+	c := &FFConnector{ctx: context.Background(), wsconn: wsm, callbacks: mcb}
+
+	mcb.On("BlockchainEvent", mock.Anything).Return(fmt.Errorf("pop"))
+
+	receive <- []byte(`{"id":"1","event":"contract-event","data":{"listenerId":"sub1"}}`)
+	c.eventLoop() // exits on error
+
+	mcb.AssertExpectations(t)
+	wsm.AssertExpectations(t)
+}
+
+func TestEventLoopReceiveClosed(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	receive := make(chan []byte)
+	close(receive)
+	var rc <-chan []byte = receive
+	wsm.On("Receive").Return(rc)
+	wsm.On("Close").Return()
+	c := &FFConnector{ctx: context.Background(), wsconn: wsm}
+	c.eventLoop() // exits as receive channel is closed
+	wsm.AssertExpectations(t)
+}
+
+func TestEventLoopContextCancelled(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	receive := make(chan []byte)
+	var rc <-chan []byte = receive
+	wsm.On("Receive").Return(rc)
+	wsm.On("Close").Return()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &FFConnector{ctx: ctx, wsconn: wsm}
+	c.eventLoop() // exits as context is cancelled
+	wsm.AssertExpectations(t)
+}
+
+func TestPinAccess(t *testing.T) {
+	c, _, _, _, done := newTestFFConnector(t)
+	defer done()
+
+	access, err := c.PinAccess(context.Background(), "0x12345")
+	assert.NoError(t, err)
+	assert.False(t, access.Permissioned)
+	err = c.RegisterPinKey(context.Background(), fftypes.NewUUID(), "0x12345")
+	assert.Regexp(t, "FF10517", err)
+}
@@ -0,0 +1,43 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memchain
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+)
+
+const (
+	// MemchainConfigKey is a sub-key in the config to contain all the memchain specific config
+	MemchainConfigKey = "memchain"
+
+	// MemchainConfigChain is the name of the chain - plugins in the same process with the same chain name share a ledger
+	MemchainConfigChain = "chain"
+	// MemchainConfigJournal is an optional file shared by the members of a local stack, that records the blocks of the chain
+	MemchainConfigJournal = "journal"
+	// MemchainConfigLatency is the artificial delay between a transaction being submitted, and being mined
+	MemchainConfigLatency = "latency"
+	// MemchainConfigPollingInterval is how often the journal is checked for new blocks
+	MemchainConfigPollingInterval = "pollingInterval"
+)
+
+func (m *Memchain) InitPrefix(prefix config.Prefix) {
+	memchainConf := prefix.SubPrefix(MemchainConfigKey)
+	memchainConf.AddKnownKey(MemchainConfigChain, "default")
+	memchainConf.AddKnownKey(MemchainConfigJournal)
+	memchainConf.AddKnownKey(MemchainConfigLatency, "0")
+	memchainConf.AddKnownKey(MemchainConfigPollingInterval, "100ms")
+}
@@ -0,0 +1,164 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memchain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// block is a single mined transaction - memchain puts exactly one transaction in each block, so block
+// numbers are allocated in the order transactions are submitted
+type block struct {
+	Number        int64              `json:"-"`
+	OperationID   *fftypes.UUID      `json:"operationId,omitempty"`
+	Signer        string             `json:"signer"`
+	Namespace     string             `json:"namespace"`
+	TransactionID *fftypes.UUID      `json:"transactionId"`
+	BatchID       *fftypes.UUID      `json:"batchId"`
+	BatchHash     *fftypes.Bytes32   `json:"batchHash"`
+	PayloadRef    string             `json:"payloadRef,omitempty"`
+	Contexts      []*fftypes.Bytes32 `json:"contexts"`
+	Mined         *fftypes.FFTime    `json:"mined"`
+}
+
+// ledger is the ordered list of blocks of a chain, as seen by one plugin
+type ledger interface {
+	// append adds a block to the end of the chain
+	append(ctx context.Context, b *block) error
+
+	// read returns the blocks after the given block number, in order
+	read(ctx context.Context, after int64) ([]*block, error)
+
+	// changed returns a channel that is closed when the next block is appended, or nil if the ledger must be polled
+	changed() <-chan struct{}
+}
+
+var (
+	chainsMux sync.Mutex
+	chains    = map[string]*memLedger{}
+)
+
+// memLedger is a chain held in memory, shared by all the plugins in the process that use the same chain name
+type memLedger struct {
+	mux    sync.Mutex
+	blocks []*block
+	notify chan struct{}
+}
+
+func getMemLedger(name string) *memLedger {
+	chainsMux.Lock()
+	defer chainsMux.Unlock()
+	l, ok := chains[name]
+	if !ok {
+		l = &memLedger{notify: make(chan struct{})}
+		chains[name] = l
+	}
+	return l
+}
+
+func (l *memLedger) append(ctx context.Context, b *block) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	b.Number = int64(len(l.blocks)) + 1
+	l.blocks = append(l.blocks, b)
+	close(l.notify)
+	l.notify = make(chan struct{})
+	return nil
+}
+
+func (l *memLedger) read(ctx context.Context, after int64) ([]*block, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if after >= int64(len(l.blocks)) {
+		return nil, nil
+	}
+	return append([]*block{}, l.blocks[after:]...), nil
+}
+
+func (l *memLedger) changed() <-chan struct{} {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.notify
+}
+
+// journalLedger is a chain recorded in a file, with one JSON block per line, so that the members of a stack
+// running in separate processes on the same machine see the same blocks in the same order.
+// Each line is written with a single append, and the block number is the line number.
+type journalLedger struct {
+	path   string
+	offset int64
+	count  int64
+}
+
+func (l *journalLedger) append(ctx context.Context, b *block) error {
+	line, _ := json.Marshal(b)
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+		_ = f.Close()
+	}
+	if err != nil {
+		return i18n.NewError(ctx, i18n.MsgMemchainJournalError, l.path, err)
+	}
+	return nil
+}
+
+func (l *journalLedger) read(ctx context.Context, after int64) ([]*block, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgMemchainJournalError, l.path, err)
+	}
+	defer f.Close()
+
+	var blocks []*block
+	r := bufio.NewReader(io.NewSectionReader(f, l.offset, math.MaxInt64-l.offset))
+	for {
+		// A line without a newline is still being written, and is read on the next poll
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return blocks, nil
+		}
+		l.offset += int64(len(line))
+		l.count++
+		var b block
+		if err := json.Unmarshal(line, &b); err != nil {
+			log.L(ctx).Errorf("Skipping invalid block %d in journal '%s': %s", l.count, l.path, err)
+			continue
+		}
+		b.Number = l.count
+		if b.Number > after {
+			blocks = append(blocks, &b)
+		}
+	}
+}
+
+func (l *journalLedger) changed() <-chan struct{} {
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memchain
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemLedger(t *testing.T) {
+	l := getMemLedger(fftypes.NewUUID().String())
+	ctx := context.Background()
+
+	changed := l.changed()
+	err := l.append(ctx, &block{Namespace: "ns1"})
+	assert.NoError(t, err)
+	<-changed
+	err = l.append(ctx, &block{Namespace: "ns2"})
+	assert.NoError(t, err)
+
+	blocks, err := l.read(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, int64(1), blocks[0].Number)
+	assert.Equal(t, int64(2), blocks[1].Number)
+
+	blocks, err = l.read(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, "ns2", blocks[0].Namespace)
+
+	blocks, err = l.read(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+}
+
+func TestJournalLedger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memchain")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+	ctx := context.Background()
+
+	writer := &journalLedger{path: path}
+	reader := &journalLedger{path: path}
+	assert.Nil(t, reader.changed())
+
+	blocks, err := reader.read(ctx, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	txID := fftypes.NewUUID()
+	err = writer.append(ctx, &block{Namespace: "ns1", TransactionID: txID, Mined: fftypes.Now()})
+	assert.NoError(t, err)
+	err = writer.append(ctx, &block{Namespace: "ns2", Mined: fftypes.Now()})
+	assert.NoError(t, err)
+
+	// A corrupt line is skipped, and a line that is still being written is left for the next read
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("!corrupt\n{\"namespace\":\"ns4\""))
+	assert.NoError(t, err)
+
+	blocks, err = reader.read(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, int64(2), blocks[0].Number)
+	assert.Equal(t, "ns2", blocks[0].Namespace)
+
+	_, err = f.Write([]byte(",\"mined\":\"2022-05-01T00:00:00Z\"}\n"))
+	assert.NoError(t, err)
+	f.Close()
+
+	blocks, err = reader.read(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, int64(4), blocks[0].Number)
+	assert.Equal(t, "ns4", blocks[0].Namespace)
+
+	// A new reader sees the same block numbers
+	blocks, err = (&journalLedger{path: path}).read(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 3)
+	assert.Equal(t, txID, blocks[0].TransactionID)
+	assert.Equal(t, int64(1), blocks[0].Number)
+}
+
+func TestJournalLedgerAppendFail(t *testing.T) {
+	l := &journalLedger{path: "/does/not/exist/journal"}
+	err := l.append(context.Background(), &block{})
+	assert.Regexp(t, "FF10455", err)
+}
@@ -0,0 +1,297 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memchain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Memchain is a blockchain plugin for development and CI, that simulates the ordering of batch pins and the
+// receipts for their transactions without a blockchain node or connector.
+//
+// Plugins in the same process with the same chain name share a chain in memory. Members of a stack running
+// as separate processes on the same machine share a chain by configuring the same journal file.
+type Memchain struct {
+	ctx             context.Context
+	callbacks       blockchain.Callbacks
+	metrics         metrics.Manager
+	chain           string
+	ledger          ledger
+	latency         time.Duration
+	pollingInterval time.Duration
+	lastBlock       int64
+	mux             sync.Mutex
+	pending         map[fftypes.UUID]bool
+	readErr         error
+}
+
+var addressVerify = regexp.MustCompile("^[0-9a-f]{40}$")
+
+func (m *Memchain) Name() string {
+	return "memchain"
+}
+
+func (m *Memchain) VerifierType() fftypes.VerifierType {
+	return fftypes.VerifierTypeEthAddress
+}
+
+func (m *Memchain) ChainID() int64 {
+	return 0
+}
+
+func (m *Memchain) Init(ctx context.Context, prefix config.Prefix, callbacks blockchain.Callbacks, metrics metrics.Manager) (err error) {
+	memchainConf := prefix.SubPrefix(MemchainConfigKey)
+
+	m.ctx = log.WithLogField(ctx, "proto", "memchain")
+	m.callbacks = callbacks
+	m.metrics = metrics
+	m.chain = memchainConf.GetString(MemchainConfigChain)
+	m.latency = memchainConf.GetDuration(MemchainConfigLatency)
+	m.pollingInterval = memchainConf.GetDuration(MemchainConfigPollingInterval)
+	m.pending = make(map[fftypes.UUID]bool)
+
+	if journal := memchainConf.GetString(MemchainConfigJournal); journal != "" {
+		m.ledger = &journalLedger{path: journal}
+		log.L(m.ctx).Infof("Memchain '%s' using journal '%s'", m.chain, journal)
+	} else {
+		m.ledger = getMemLedger(m.chain)
+		log.L(m.ctx).Infof("Memchain '%s' held in memory", m.chain)
+	}
+	return nil
+}
+
+func (m *Memchain) Start() error {
+	go m.eventLoop()
+	return nil
+}
+
+func (m *Memchain) Capabilities() *blockchain.Capabilities {
+	return &blockchain.Capabilities{
+		GlobalSequencer: true,
+	}
+}
+
+func (m *Memchain) Health(ctx context.Context) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.readErr
+}
+
+func (m *Memchain) NormalizeSigningKey(ctx context.Context, key string) (string, error) {
+	keyNoHexPrefix := strings.TrimPrefix(strings.ToLower(key), "0x")
+	if addressVerify.MatchString(keyNoHexPrefix) {
+		return "0x" + keyNoHexPrefix, nil
+	}
+	return "", i18n.NewError(ctx, i18n.MsgInvalidEthAddress)
+}
+
+func (m *Memchain) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID, ledgerID *fftypes.UUID, signingKey string, batch *blockchain.BatchPin) error {
+	mined := fftypes.FFTime(time.Now().Add(m.latency))
+	m.setPending(operationID, true)
+	err := m.ledger.append(ctx, &block{
+		OperationID:   operationID,
+		Signer:        signingKey,
+		Namespace:     batch.Namespace,
+		TransactionID: batch.TransactionID,
+		BatchID:       batch.BatchID,
+		BatchHash:     batch.BatchHash,
+		PayloadRef:    batch.BatchPayloadRef,
+		Contexts:      batch.Contexts,
+		Mined:         &mined,
+	})
+	if err != nil {
+		m.setPending(operationID, false)
+	}
+	return err
+}
+
+func (m *Memchain) setPending(operationID *fftypes.UUID, pending bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if pending {
+		m.pending[*operationID] = true
+	} else {
+		delete(m.pending, *operationID)
+	}
+}
+
+// popPending returns true if the operation was submitted by this plugin, and is waiting for a receipt
+func (m *Memchain) popPending(operationID *fftypes.UUID) bool {
+	if operationID == nil {
+		return false
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	pending := m.pending[*operationID]
+	delete(m.pending, *operationID)
+	return pending
+}
+
+func (m *Memchain) setReadError(err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.readErr = err
+}
+
+func (m *Memchain) transactionHash(b *block) string {
+	return "0x" + fftypes.HashString(fmt.Sprintf("%s/%d/%s", m.chain, b.Number, b.TransactionID)).String()
+}
+
+func (m *Memchain) dispatchBlock(ctx context.Context, b *block) error {
+	// Blocks are not delivered until they have been mined
+	if wait := time.Until(*b.Mined.Time()); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return i18n.NewError(ctx, i18n.MsgContextCanceled)
+		}
+	}
+
+	txHash := m.transactionHash(b)
+	info := fftypes.JSONObject{
+		"blockNumber":     strconv.FormatInt(b.Number, 10),
+		"transactionHash": txHash,
+	}
+	if m.popPending(b.OperationID) {
+		log.L(ctx).Infof("Memchain receipt: request=%s tx=%s block=%d", b.OperationID, txHash, b.Number)
+		if err := m.callbacks.BlockchainOpUpdate(b.OperationID, fftypes.OpStatusSucceeded, txHash, "", info); err != nil {
+			return err
+		}
+	}
+
+	batch := &blockchain.BatchPin{
+		Namespace:       b.Namespace,
+		TransactionID:   b.TransactionID,
+		BatchID:         b.BatchID,
+		BatchHash:       b.BatchHash,
+		BatchPayloadRef: b.PayloadRef,
+		Contexts:        b.Contexts,
+		Event: blockchain.Event{
+			BlockchainTXID: txHash,
+			Source:         m.Name(),
+			Name:           "BatchPin",
+			ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", b.Number, 0, 0),
+			Output: fftypes.JSONObject{
+				"namespace":     b.Namespace,
+				"transactionId": b.TransactionID.String(),
+				"batchId":       b.BatchID.String(),
+				"batchHash":     b.BatchHash.String(),
+				"payloadRef":    b.PayloadRef,
+			},
+			Info:      info,
+			Timestamp: b.Mined,
+			Location:  m.chain,
+			Signature: "BatchPin",
+		},
+	}
+
+	// If there's an error dispatching the event, we must return the error and shutdown
+	return m.callbacks.BatchPinComplete(batch, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: b.Signer,
+	})
+}
+
+func (m *Memchain) eventLoop() {
+	l := log.L(m.ctx).WithField("role", "event-loop")
+	ctx := log.WithLogger(m.ctx, l)
+	for {
+		changed := m.ledger.changed()
+		blocks, err := m.ledger.read(ctx, m.lastBlock)
+		m.setReadError(err)
+		if err != nil {
+			l.Errorf("Failed to read blocks: %s", err)
+		}
+		for _, b := range blocks {
+			if err := m.dispatchBlock(ctx, b); err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
+			m.lastBlock = b.Number
+		}
+		select {
+		case <-ctx.Done():
+			l.Debugf("Event loop exiting (context cancelled)")
+			return
+		case <-changed:
+		case <-time.After(m.pollingInterval):
+		}
+	}
+}
+
+func (m *Memchain) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool) {
+	return "", false
+}
+
+func (m *Memchain) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
+	return nil, i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) AddContractListener(ctx context.Context, subscription *fftypes.ContractListenerInput) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	return i18n.NewError(ctx, i18n.MsgMemchainContractsUnsupported)
+}
+
+func (m *Memchain) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
+	// Memchain does not execute contracts, so there is nothing to validate beyond "JSON Schema correctness"
+	return nil, nil
+}
+
+func (m *Memchain) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
+	return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationUnsupported)
+}
+
+func (m *Memchain) PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error) {
+	// Any key can pin to the in-memory chain
+	return &fftypes.PinAccess{}, nil
+}
+
+func (m *Memchain) RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error {
+	return i18n.NewError(ctx, i18n.MsgPinAccessNotPermissioned, m.Name())
+}
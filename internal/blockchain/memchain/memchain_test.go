@@ -0,0 +1,298 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memchain
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("memchain_unit_tests")
+var utMemchainConf = utConfPrefix.SubPrefix(MemchainConfigKey)
+
+func resetConf(m *Memchain) {
+	config.Reset()
+	m.InitPrefix(utConfPrefix)
+}
+
+func newTestMemchain(t *testing.T, chain string) (*Memchain, *blockchainmocks.Callbacks, func()) {
+	m := &Memchain{}
+	resetConf(m)
+	utMemchainConf.Set(MemchainConfigChain, chain)
+	utMemchainConf.Set(MemchainConfigPollingInterval, "1ms")
+	mcb := &blockchainmocks.Callbacks{}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := m.Init(ctx, utConfPrefix, mcb, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+	return m, mcb, cancel
+}
+
+func testBatchPin() *blockchain.BatchPin {
+	return &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Contexts:        []*fftypes.Bytes32{fftypes.NewRandB32()},
+	}
+}
+
+func TestInit(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "init")
+	defer cancel()
+	assert.Equal(t, "memchain", m.Name())
+	assert.Equal(t, fftypes.VerifierTypeEthAddress, m.VerifierType())
+	assert.True(t, m.Capabilities().GlobalSequencer)
+	assert.NoError(t, m.Health(context.Background()))
+	assert.Equal(t, getMemLedger("init"), m.ledger)
+}
+
+func TestInitJournal(t *testing.T) {
+	m := &Memchain{}
+	resetConf(m)
+	utMemchainConf.Set(MemchainConfigJournal, "/tmp/memchain.journal")
+	err := m.Init(context.Background(), utConfPrefix, &blockchainmocks.Callbacks{}, &metricsmocks.Manager{})
+	assert.NoError(t, err)
+	assert.Equal(t, &journalLedger{path: "/tmp/memchain.journal"}, m.ledger)
+}
+
+func TestNormalizeSigningKey(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "keys")
+	defer cancel()
+
+	key, err := m.NormalizeSigningKey(context.Background(), "0x2A7C9D5248681CE6C393117E641AD037F5C079F6")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2a7c9d5248681ce6c393117e641ad037f5c079f6", key)
+
+	key, err = m.NormalizeSigningKey(context.Background(), "2a7c9d5248681ce6c393117e641ad037f5c079f6")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2a7c9d5248681ce6c393117e641ad037f5c079f6", key)
+
+	_, err = m.NormalizeSigningKey(context.Background(), "0xbad")
+	assert.Regexp(t, "FF10141", err)
+}
+
+func TestDecodeRevertReasonUnsupported(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "keys")
+	defer cancel()
+
+	_, ok := m.DecodeRevertReason(context.Background(), fftypes.JSONObject{}, []*fftypes.FFIErrorDefinition{{Name: "Unauthorized"}})
+	assert.False(t, ok)
+}
+
+func TestChainIDNotApplicable(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "keys")
+	defer cancel()
+
+	assert.Equal(t, int64(0), m.ChainID())
+}
+
+func TestSubmitBatchPinSharedChain(t *testing.T) {
+	chain := fftypes.NewUUID().String()
+	m1, mcb1, cancel1 := newTestMemchain(t, chain)
+	defer cancel1()
+	m2, mcb2, cancel2 := newTestMemchain(t, chain)
+	defer cancel2()
+
+	opID := fftypes.NewUUID()
+	batch := testBatchPin()
+	signer := "0x2a7c9d5248681ce6c393117e641ad037f5c079f6"
+
+	receipt := make(chan bool)
+	mcb1.On("BlockchainOpUpdate", opID, fftypes.OpStatusSucceeded, mock.MatchedBy(func(txHash string) bool {
+		return len(txHash) == 66
+	}), "", mock.MatchedBy(func(info fftypes.JSONObject) bool {
+		return info.GetString("blockNumber") == "1"
+	})).Run(func(args mock.Arguments) {
+		close(receipt)
+	}).Return(nil)
+
+	pins := make(chan *blockchain.BatchPin, 2)
+	matchSigner := mock.MatchedBy(func(v *fftypes.VerifierRef) bool {
+		return v.Type == fftypes.VerifierTypeEthAddress && v.Value == signer
+	})
+	for _, mcb := range []*blockchainmocks.Callbacks{mcb1, mcb2} {
+		mcb.On("BatchPinComplete", mock.Anything, matchSigner).Run(func(args mock.Arguments) {
+			pins <- args[0].(*blockchain.BatchPin)
+		}).Return(nil)
+	}
+
+	err := m1.Start()
+	assert.NoError(t, err)
+	err = m2.Start()
+	assert.NoError(t, err)
+
+	err = m1.SubmitBatchPin(context.Background(), opID, nil, signer, batch)
+	assert.NoError(t, err)
+
+	<-receipt
+	for i := 0; i < 2; i++ {
+		pin := <-pins
+		assert.Equal(t, "ns1", pin.Namespace)
+		assert.Equal(t, batch.TransactionID, pin.TransactionID)
+		assert.Equal(t, batch.BatchID, pin.BatchID)
+		assert.Equal(t, batch.BatchHash, pin.BatchHash)
+		assert.Equal(t, batch.BatchPayloadRef, pin.BatchPayloadRef)
+		assert.Equal(t, batch.Contexts, pin.Contexts)
+		assert.Equal(t, "memchain", pin.Event.Source)
+		assert.Equal(t, "BatchPin", pin.Event.Name)
+		assert.Equal(t, "000000000001/000000/000000", pin.Event.ProtocolID)
+		assert.Equal(t, chain, pin.Event.Location)
+		assert.Equal(t, batch.BatchID.String(), pin.Event.Output.GetString("batchId"))
+	}
+
+	mcb1.AssertExpectations(t)
+	mcb2.AssertExpectations(t)
+	mcb2.AssertNotCalled(t, "BlockchainOpUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubmitBatchPinJournalFail(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "journalfail")
+	defer cancel()
+	m.ledger = &journalLedger{path: "/does/not/exist/journal"}
+
+	opID := fftypes.NewUUID()
+	err := m.SubmitBatchPin(context.Background(), opID, nil, "0x12345", testBatchPin())
+	assert.Regexp(t, "FF10455", err)
+	assert.Empty(t, m.pending)
+}
+
+func TestDispatchBlockLatency(t *testing.T) {
+	m, mcb, cancel := newTestMemchain(t, "latency")
+	defer cancel()
+
+	mined := fftypes.FFTime(time.Now().Add(10 * time.Millisecond))
+	mcb.On("BatchPinComplete", mock.Anything, mock.Anything).Return(nil)
+
+	err := m.dispatchBlock(context.Background(), &block{Number: 1, Mined: &mined})
+	assert.NoError(t, err)
+	assert.False(t, time.Now().Before(time.Time(mined)))
+	mcb.AssertExpectations(t)
+}
+
+func TestDispatchBlockLatencyCancelled(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "latencycancelled")
+	defer cancel()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancelCtx()
+	mined := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	err := m.dispatchBlock(ctx, &block{Number: 1, Mined: &mined})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestDispatchBlockReceiptFail(t *testing.T) {
+	m, mcb, cancel := newTestMemchain(t, "receiptfail")
+	defer cancel()
+
+	opID := fftypes.NewUUID()
+	m.setPending(opID, true)
+	mcb.On("BlockchainOpUpdate", opID, fftypes.OpStatusSucceeded, mock.Anything, "", mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := m.dispatchBlock(context.Background(), &block{Number: 1, OperationID: opID, Mined: fftypes.Now()})
+	assert.EqualError(t, err, "pop")
+	mcb.AssertExpectations(t)
+}
+
+func TestEventLoopDispatchFail(t *testing.T) {
+	m, mcb, cancel := newTestMemchain(t, fftypes.NewUUID().String())
+	defer cancel()
+
+	err := m.ledger.append(context.Background(), &block{Mined: fftypes.Now()})
+	assert.NoError(t, err)
+	mcb.On("BatchPinComplete", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	m.eventLoop()
+	assert.Equal(t, int64(0), m.lastBlock)
+	mcb.AssertExpectations(t)
+}
+
+func TestEventLoopJournalReadError(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "readerror")
+
+	dir, err := ioutil.TempDir("", "memchain")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	notADir := filepath.Join(dir, "file")
+	err = ioutil.WriteFile(notADir, []byte{}, 0600)
+	assert.NoError(t, err)
+	m.ledger = &journalLedger{path: filepath.Join(notADir, "journal")}
+
+	done := make(chan struct{})
+	go func() {
+		m.eventLoop()
+		close(done)
+	}()
+	for m.Health(context.Background()) == nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	assert.Regexp(t, "FF10455", m.Health(context.Background()))
+	cancel()
+	<-done
+}
+
+func TestContractsUnsupported(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "contracts")
+	defer cancel()
+	ctx := context.Background()
+
+	err := m.InvokeContract(ctx, fftypes.NewUUID(), "0x12345", nil, nil, nil, nil)
+	assert.Regexp(t, "FF10456", err)
+	err = m.DeployContract(ctx, fftypes.NewUUID(), "0x12345", nil, nil, nil, nil)
+	assert.Regexp(t, "FF10456", err)
+	_, err = m.QueryContract(ctx, nil, nil, nil)
+	assert.Regexp(t, "FF10456", err)
+	err = m.AddContractListener(ctx, &fftypes.ContractListenerInput{})
+	assert.Regexp(t, "FF10456", err)
+	err = m.DeleteContractListener(ctx, &fftypes.ContractListener{})
+	assert.Regexp(t, "FF10456", err)
+	err = m.PauseContractListener(ctx, &fftypes.ContractListener{})
+	assert.Regexp(t, "FF10456", err)
+	err = m.ResumeContractListener(ctx, &fftypes.ContractListener{})
+	assert.Regexp(t, "FF10456", err)
+	_, err = m.GenerateFFI(ctx, &fftypes.FFIGenerationRequest{})
+	assert.Regexp(t, "FF10347", err)
+	v, err := m.GetFFIParamValidator(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestPinAccess(t *testing.T) {
+	m, _, cancel := newTestMemchain(t, "pinaccess")
+	defer cancel()
+	ctx := context.Background()
+
+	access, err := m.PinAccess(ctx, "0x12345")
+	assert.NoError(t, err)
+	assert.False(t, access.Permissioned)
+	err = m.RegisterPinKey(ctx, fftypes.NewUUID(), "0x12345")
+	assert.Regexp(t, "FF10517", err)
+}
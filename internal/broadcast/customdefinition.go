@@ -0,0 +1,41 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broadcast
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// BroadcastCustomDefinition broadcasts a definition with an application specific tag, to be processed on every node
+// by the custom definition handler registered for that tag
+func (bm *broadcastManager) BroadcastCustomDefinition(ctx context.Context, ns string, def *fftypes.CustomDefinition, waitConfirm bool) (*fftypes.Message, error) {
+	def.Namespace = ns
+	if err := def.Validate(ctx); err != nil {
+		return nil, err
+	}
+	if err := bm.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+
+	msg, err := bm.BroadcastDefinitionAsNode(ctx, ns, def, def.Tag, waitConfirm)
+	if msg != nil {
+		def.Message = msg.Header.ID
+	}
+	return msg, err
+}
@@ -0,0 +1,82 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBroadcastCustomDefinitionOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(nil)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.MatchedBy(func(newMsg *data.NewMessage) bool {
+		header := newMsg.Message.Header
+		return header.Tag == "myapp:config" &&
+			header.Type == fftypes.MessageTypeDefinition &&
+			header.Topics[0] == (&fftypes.CustomDefinition{Namespace: "ns1", Tag: "myapp:config"}).Topic()
+	}), mock.Anything).Return(nil)
+
+	def := &fftypes.CustomDefinition{
+		Tag:   "myapp:config",
+		Value: fftypes.JSONAnyPtr(`{"some": "config"}`),
+	}
+	msg, err := bm.BroadcastCustomDefinition(context.Background(), "ns1", def, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", def.Namespace)
+	assert.Equal(t, msg.Header.ID, def.Message)
+
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastCustomDefinitionReservedTag(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	_, err := bm.BroadcastCustomDefinition(context.Background(), "ns1", &fftypes.CustomDefinition{
+		Tag:   fftypes.SystemTagDefineDatatype,
+		Value: fftypes.JSONAnyPtr(`{}`),
+	}, false)
+	assert.Regexp(t, "FF10384", err)
+}
+
+func TestBroadcastCustomDefinitionNSGetFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+
+	_, err := bm.BroadcastCustomDefinition(context.Background(), "ns1", &fftypes.CustomDefinition{
+		Tag:   "myapp:config",
+		Value: fftypes.JSONAnyPtr(`{}`),
+	}, false)
+	assert.EqualError(t, err, "pop")
+}
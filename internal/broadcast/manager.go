@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -44,6 +45,7 @@ type Manager interface {
 
 	NewBroadcast(ns string, in *fftypes.MessageInOut) sysmessaging.MessageSender
 	BroadcastDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype, waitConfirm bool) (msg *fftypes.Message, err error)
+	BroadcastCustomDefinition(ctx context.Context, ns string, def *fftypes.CustomDefinition, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastNamespace(ctx context.Context, ns *fftypes.Namespace, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error)
 	BroadcastDefinitionAsNode(ctx context.Context, ns string, def fftypes.Definition, tag string, waitConfirm bool) (msg *fftypes.Message, err error)
@@ -72,6 +74,9 @@ type broadcastManager struct {
 	maxBatchPayloadLength int64
 	metrics               metrics.Manager
 	operations            operations.Manager
+	hybrid                bool
+	hybridMaxAttempts     int
+	hybridRetry           retry.Retry
 }
 
 func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Manager, dm data.Manager, bi blockchain.Plugin, dx dataexchange.Plugin, si sharedstorage.Plugin, ba batch.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager) (Manager, error) {
@@ -92,6 +97,13 @@ func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Ma
 		maxBatchPayloadLength: config.GetByteSize(config.BroadcastBatchPayloadLimit),
 		metrics:               mm,
 		operations:            om,
+		hybrid:                config.GetBool(config.BroadcastHybridEnabled),
+		hybridMaxAttempts:     config.GetInt(config.BroadcastHybridRetryMaxAttempts),
+		hybridRetry: retry.Retry{
+			InitialDelay: config.GetDuration(config.BroadcastHybridRetryInitDelay),
+			MaximumDelay: config.GetDuration(config.BroadcastHybridRetryMaxDelay),
+			Factor:       config.GetFloat64(config.BroadcastHybridRetryFactor),
+		},
 	}
 
 	bo := batch.DispatcherOptions{
@@ -101,6 +113,7 @@ func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Ma
 		BatchTimeout:   config.GetDuration(config.BroadcastBatchTimeout),
 		DisposeTimeout: config.GetDuration(config.BroadcastBatchAgentTimeout),
 	}
+	bo.NegotiateProtocol = bm.negotiateProtocol
 
 	ba.RegisterDispatcher(broadcastDispatcherName,
 		fftypes.TransactionTypeBatchPin,
@@ -113,6 +126,7 @@ func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Ma
 	om.RegisterHandler(ctx, bm, []fftypes.OpType{
 		fftypes.OpTypeSharedStorageUploadBatch,
 		fftypes.OpTypeSharedStorageUploadBlob,
+		fftypes.OpTypeDataExchangeSendBroadcastBatch,
 	})
 
 	return bm, nil
@@ -140,6 +154,7 @@ func (bm *broadcastManager) dispatchBatch(ctx context.Context, state *batch.Disp
 		return err
 	}
 	batch := state.Persisted.GenInflight(state.Messages, state.Data)
+	batch.ProtocolVersion = state.ProtocolVersion
 
 	// We are in an (indefinite) retry cycle from the batch processor to dispatch this batch, that is only
 	// termianted with shutdown. So we leave the operation pending on failure, as it is still being retried.
@@ -147,8 +162,108 @@ func (bm *broadcastManager) dispatchBatch(ctx context.Context, state *batch.Disp
 	if err := bm.operations.RunOperation(ctx, opUploadBatch(op, batch, &state.Persisted), operations.RemainPendingOnFailure); err != nil {
 		return err
 	}
+
+	// In hybrid mode the other nodes also receive the batch directly, so they do not depend on shared storage
+	var sends []*fftypes.PreparedOperation
+	if bm.hybrid {
+		var err error
+		if sends, err = bm.prepareNodeSends(ctx, batch); err != nil {
+			return err
+		}
+	}
+
 	log.L(ctx).Infof("Pinning broadcast batch %s with author=%s key=%s payload=%s", batch.ID, batch.Author, batch.Key, state.Persisted.PayloadRef)
-	return bm.batchpin.SubmitPinnedBatch(ctx, &state.Persisted, state.Pins)
+	if err := bm.batchpin.SubmitPinnedBatch(ctx, &state.Persisted, state.Pins); err != nil {
+		return err
+	}
+
+	// The sends run in parallel in the background, so a slow or unavailable node does not hold up the pin, or the
+	// next batch. Confirming the pin takes long enough that the batch normally arrives first.
+	for _, op := range sends {
+		go bm.sendBatchToNode(op)
+	}
+	return nil
+}
+
+// prepareNodeSends records an operation for the send of the batch over data exchange to every node in the network
+// that is not owned by the local org.
+func (bm *broadcastManager) prepareNodeSends(ctx context.Context, batch *fftypes.Batch) ([]*fftypes.PreparedOperation, error) {
+	nodes, err := bm.getNetworkNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	localOrg, err := bm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sends := make([]*fftypes.PreparedOperation, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Parent.Equals(localOrg.ID) {
+			continue
+		}
+		op := fftypes.NewOperation(
+			bm.exchange,
+			batch.Namespace,
+			batch.Payload.TX.ID,
+			fftypes.OpTypeDataExchangeSendBroadcastBatch)
+		addSendBroadcastBatchInputs(op, node.ID, batch.ID)
+		if err := bm.operations.AddOrReuseOperation(ctx, op); err != nil {
+			return nil, err
+		}
+		sends = append(sends, opSendBroadcastBatch(op, node, batch))
+	}
+	return sends, nil
+}
+
+// sendBatchToNode sends the batch to one node, retrying a failed send up to the configured number of attempts.
+// The operation is only marked failed after the last attempt. A failed send does not fail the broadcast, as the
+// node can still download the batch from shared storage once it has been pinned.
+func (bm *broadcastManager) sendBatchToNode(op *fftypes.PreparedOperation) {
+	data := op.Data.(sendBroadcastBatchData)
+	_ = bm.hybridRetry.Do(bm.ctx, "send broadcast batch", func(attempt int) (retry bool, err error) {
+		log.L(bm.ctx).Debugf("Sending broadcast batch %s to node=%s (%s) attempt=%d", data.Batch.ID, data.Node.Name, data.Node.ID, attempt)
+		if attempt >= bm.hybridMaxAttempts {
+			return false, bm.operations.RunOperation(bm.ctx, op)
+		}
+		return true, bm.operations.RunOperation(bm.ctx, op, operations.RemainPendingOnFailure)
+	})
+}
+
+func (bm *broadcastManager) getNetworkNodes(ctx context.Context) ([]*fftypes.Identity, error) {
+	fb := database.IdentityQueryFactory.NewFilter(ctx)
+	nodes, _, err := bm.database.GetIdentities(ctx, fb.And(
+		fb.Eq("type", fftypes.IdentityTypeNode),
+		fb.Eq("namespace", fftypes.SystemNamespace),
+	))
+	return nodes, err
+}
+
+// negotiateProtocol determines the newest network protocol version supported by every node in the network,
+// as all of them will download the broadcast batch from shared storage.
+// A misconfigured node must not prevent broadcasts to the rest of the network, so rather than failing the dispatch
+// we record an event for each node with a version we cannot determine, and fall back to ProtocolVersion1.
+func (bm *broadcastManager) negotiateProtocol(ctx context.Context, state *batch.DispatchState) (uint, error) {
+	nodes, err := bm.getNetworkNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	version := fftypes.ProtocolVersionLatest
+	for _, node := range nodes {
+		nodeVersion, err := node.ProtocolVersion(ctx)
+		if err != nil {
+			log.L(ctx).Errorf("Incompatible peer detected for broadcast batch %s: %s", state.Persisted.ID, err)
+			event := fftypes.NewEvent(fftypes.EventTypeProtocolIncompatible, state.Persisted.Namespace, node.ID, nil, node.DID)
+			event.Correlator = state.Persisted.ID
+			if err := bm.database.InsertEvent(ctx, event); err != nil {
+				return 0, err
+			}
+			nodeVersion = fftypes.ProtocolVersion1
+		}
+		if nodeVersion < version {
+			version = nodeVersion
+		}
+	}
+	return version, nil
 }
 
 func (bm *broadcastManager) uploadBlobs(ctx context.Context, tx *fftypes.UUID, data fftypes.DataArray) error {
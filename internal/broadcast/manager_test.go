@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/config"
@@ -57,6 +58,7 @@ func newTestBroadcastCommon(t *testing.T, metricsEnabled bool) (*broadcastManage
 	mmi.On("IsMetricsEnabled").Return(metricsEnabled)
 	mbi.On("Name").Return("ut_blockchain").Maybe()
 	mpi.On("Name").Return("ut_sharedstorage").Maybe()
+	mdx.On("Name").Return("ut_dataexchange").Maybe()
 	mba.On("RegisterDispatcher",
 		broadcastDispatcherName,
 		fftypes.TransactionTypeBatchPin,
@@ -295,6 +297,289 @@ func TestDispatchBatchSubmitBroadcastFail(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestDispatchBatchHybrid(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.hybrid = true
+	bm.hybridMaxAttempts = 2
+	bm.hybridRetry.InitialDelay = time.Microsecond
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+		Pins: []*fftypes.Bytes32{fftypes.NewRandB32()},
+	}
+	localOrg := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	localNode := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Parent: localOrg.ID}}
+	remoteNode1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Parent: fftypes.NewUUID()}}
+	remoteNode2 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Parent: fftypes.NewUUID()}}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mbp := bm.batchpin.(*batchpinmocks.Submitter)
+	mom := bm.operations.(*operationmocks.Manager)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{localNode, remoteNode1, remoteNode2}, nil, nil)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(localOrg, nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		return op.Type == fftypes.OpTypeSharedStorageUploadBatch
+	}), operations.RemainPendingOnFailure).Return(nil)
+	pinned := make(chan struct{})
+	sent := make(chan *fftypes.Identity, 3)
+	isSendTo := func(node *fftypes.Identity) interface{} {
+		return mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+			data, ok := op.Data.(sendBroadcastBatchData)
+			return ok && data.Node == node && data.Batch.ID.Equals(state.Persisted.ID)
+		})
+	}
+	recordSend := func(a mock.Arguments) {
+		<-pinned // sends only start once the batch is pinned
+		sent <- a[1].(*fftypes.PreparedOperation).Data.(sendBroadcastBatchData).Node
+	}
+	// Retried while pending, and fails the operation on the last attempt - without failing the broadcast
+	mom.On("RunOperation", mock.Anything, isSendTo(remoteNode1), operations.RemainPendingOnFailure).Return(fmt.Errorf("pop")).Run(recordSend).Once()
+	mom.On("RunOperation", mock.Anything, isSendTo(remoteNode1)).Return(fmt.Errorf("pop")).Run(recordSend).Once()
+	mom.On("RunOperation", mock.Anything, isSendTo(remoteNode2), operations.RemainPendingOnFailure).Return(nil).Run(recordSend).Once()
+	mbp.On("SubmitPinnedBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		close(pinned)
+	})
+
+	err := bm.dispatchBatch(context.Background(), state)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		<-sent
+	}
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mbp.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestDispatchBatchHybridGetNodesFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.hybrid = true
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything, operations.RemainPendingOnFailure).Return(nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := bm.dispatchBatch(context.Background(), state)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestDispatchBatchHybridGetOrgFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.hybrid = true
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything, operations.RemainPendingOnFailure).Return(nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := bm.dispatchBatch(context.Background(), state)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestDispatchBatchHybridAddOpFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.hybrid = true
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+	remoteNode := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Parent: fftypes.NewUUID()}}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeSharedStorageUploadBatch
+	})).Return(nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeDataExchangeSendBroadcastBatch
+	})).Return(fmt.Errorf("pop"))
+	mom.On("RunOperation", mock.Anything, mock.Anything, operations.RemainPendingOnFailure).Return(nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{remoteNode}, nil, nil)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}, nil)
+
+	err := bm.dispatchBatch(context.Background(), state)
+	assert.EqualError(t, err, "pop")
+
+	mom.AssertExpectations(t)
+}
+
+func TestDispatchBatchHybridPinFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.hybrid = true
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+	remoteNode := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Parent: fftypes.NewUUID()}}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mbp := bm.batchpin.(*batchpinmocks.Submitter)
+	mom := bm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything, operations.RemainPendingOnFailure).Return(nil).Once() // upload only
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{remoteNode}, nil, nil)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}, nil)
+	mbp.On("SubmitPinnedBatch", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	// Nothing is sent until the batch is pinned
+	err := bm.dispatchBatch(context.Background(), state)
+	assert.EqualError(t, err, "pop")
+
+	mom.AssertExpectations(t)
+	mbp.AssertExpectations(t)
+}
+
+func TestNegotiateProtocolOK(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": float64(2)}}},
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": float64(3)}}},
+	}, nil, nil)
+
+	version, err := bm.negotiateProtocol(bm.ctx, &batch.DispatchState{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion2, version)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+}
+
+func TestNegotiateProtocolIncompatibleNode(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	badNode := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:  fftypes.NewUUID(),
+			DID: "did:firefly:node/node2",
+		},
+		IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": "bad"}},
+	}
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": float64(2)}}},
+		badNode,
+	}, nil, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeProtocolIncompatible &&
+			event.Namespace == "ns1" &&
+			event.Reference.Equals(badNode.ID) &&
+			event.Topic == badNode.DID &&
+			event.Correlator.Equals(state.Persisted.ID)
+	})).Return(nil).Once()
+
+	version, err := bm.negotiateProtocol(bm.ctx, state)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion1, version)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestNegotiateProtocolIncompatibleNodeEventFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": "bad"}}},
+	}, nil, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := bm.negotiateProtocol(bm.ctx, &batch.DispatchState{})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestNegotiateProtocolLegacyNode(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"protocolVersion": float64(2)}}},
+		{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"id": "peer2"}}},
+	}, nil, nil)
+
+	version, err := bm.negotiateProtocol(bm.ctx, &batch.DispatchState{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion1, version)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestNegotiateProtocolFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := bm.negotiateProtocol(bm.ctx, &batch.DispatchState{})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
 func TestUploadBlobsPublishFail(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -43,7 +43,7 @@ func (bm *broadcastManager) BroadcastMessage(ctx context.Context, ns string, in
 	if bm.metrics.IsMetricsEnabled() {
 		bm.metrics.MessageSubmitted(&in.Message)
 	}
-	if waitConfirm {
+	if waitConfirm && !in.Draft {
 		err = broadcast.SendAndWait(ctx)
 	} else {
 		err = broadcast.Send(ctx)
@@ -88,6 +88,10 @@ func (s *broadcastSender) setDefaults() {
 	msg.Header.ID = fftypes.NewUUID()
 	msg.Header.Namespace = s.namespace
 	msg.State = fftypes.MessageStateReady
+	if msg.Draft {
+		// A draft is stored along with its data, but is not picked up for batching until it is activated
+		msg.State = fftypes.MessageStateDraft
+	}
 	if msg.Header.Type == "" {
 		msg.Header.Type = fftypes.MessageTypeBroadcast
 	}
@@ -143,8 +147,16 @@ func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (
 		return nil
 	}
 
+	// Register for an immediate flush before the write, so the batch manager cannot see the message first
+	if s.msg.Message.FlushImmediately {
+		s.mgr.batch.FlushOnArrival(msg.Header.ID)
+	}
+
 	// Write the message
 	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
+		if s.msg.Message.FlushImmediately {
+			s.mgr.batch.CancelFlushOnArrival(msg.Header.ID)
+		}
 		return err
 	}
 	log.L(ctx).Infof("Sent broadcast message %s:%s sequence=%d datacount=%d", msg.Header.Namespace, msg.Header.ID, msg.Sequence, len(s.msg.AllData))
@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
@@ -62,6 +63,88 @@ func TestBroadcastMessageOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestBroadcastMessageDraft(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.MatchedBy(func(newMsg *data.NewMessage) bool {
+		return newMsg.Message.State == fftypes.MessageStateDraft
+	})).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	// A draft is not waited for, even if confirmation is requested
+	msg, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+		Draft: true,
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateDraft, msg.State)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastMessageFlushImmediately(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mba := bm.batch.(*batchmocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+	mba.On("FlushOnArrival", mock.Anything).Return()
+
+	msg, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+		FlushImmediately: true,
+	}, false)
+	assert.NoError(t, err)
+	mba.AssertCalled(t, "FlushOnArrival", msg.Header.ID)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mba.AssertExpectations(t)
+}
+
+func TestBroadcastMessageFlushImmediatelyWriteFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mba := bm.batch.(*batchmocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+	mba.On("FlushOnArrival", mock.Anything).Return()
+	mba.On("CancelFlushOnArrival", mock.Anything).Return()
+
+	msg, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+		FlushImmediately: true,
+	}, false)
+	assert.EqualError(t, err, "pop")
+	mba.AssertCalled(t, "CancelFlushOnArrival", msg.Header.ID)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mba.AssertExpectations(t)
+}
+
 func TestBroadcastMessageWaitConfirmOk(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
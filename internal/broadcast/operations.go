@@ -19,7 +19,6 @@ package broadcast
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -37,6 +36,11 @@ type uploadBlobData struct {
 	Blob *fftypes.Blob `json:"batch"`
 }
 
+type sendBroadcastBatchData struct {
+	Node  *fftypes.Identity `json:"node"`
+	Batch *fftypes.Batch    `json:"batch"`
+}
+
 func addUploadBatchInputs(op *fftypes.Operation, batchID *fftypes.UUID) {
 	op.Input = fftypes.JSONObject{
 		"id": batchID.String(),
@@ -61,6 +65,13 @@ func getUploadBlobOutputs(payloadRef string) fftypes.JSONObject {
 	}
 }
 
+func addSendBroadcastBatchInputs(op *fftypes.Operation, nodeID *fftypes.UUID, batchID *fftypes.UUID) {
+	op.Input = fftypes.JSONObject{
+		"node":  nodeID.String(),
+		"batch": batchID.String(),
+	}
+}
+
 func retrieveUploadBatchInputs(ctx context.Context, op *fftypes.Operation) (*fftypes.UUID, error) {
 	return fftypes.ParseUUID(ctx, op.Input.GetString("id"))
 }
@@ -69,6 +80,14 @@ func retrieveUploadBlobInputs(ctx context.Context, op *fftypes.Operation) (*ffty
 	return fftypes.ParseUUID(ctx, op.Input.GetString("dataId"))
 }
 
+func retrieveSendBroadcastBatchInputs(ctx context.Context, op *fftypes.Operation) (nodeID *fftypes.UUID, batchID *fftypes.UUID, err error) {
+	nodeID, err = fftypes.ParseUUID(ctx, op.Input.GetString("node"))
+	if err == nil {
+		batchID, err = fftypes.ParseUUID(ctx, op.Input.GetString("batch"))
+	}
+	return nodeID, batchID, err
+}
+
 func (bm *broadcastManager) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	switch op.Type {
 	case fftypes.OpTypeSharedStorageUploadBatch:
@@ -107,6 +126,29 @@ func (bm *broadcastManager) PrepareOperation(ctx context.Context, op *fftypes.Op
 		}
 		return opUploadBlob(op, d, blob), nil
 
+	case fftypes.OpTypeDataExchangeSendBroadcastBatch:
+		nodeID, batchID, err := retrieveSendBroadcastBatchInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		node, err := bm.database.GetIdentityByID(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		} else if node == nil {
+			return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		bp, err := bm.database.GetBatchByID(ctx, batchID)
+		if err != nil {
+			return nil, err
+		} else if bp == nil {
+			return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		batch, err := bm.data.HydrateBatch(ctx, bp)
+		if err != nil {
+			return nil, err
+		}
+		return opSendBroadcastBatch(op, node, batch), nil
+
 	default:
 		return nil, i18n.NewError(ctx, i18n.MsgOperationNotSupported, op.Type)
 	}
@@ -118,6 +160,8 @@ func (bm *broadcastManager) RunOperation(ctx context.Context, op *fftypes.Prepar
 		return bm.uploadBatch(ctx, data)
 	case uploadBlobData:
 		return bm.uploadBlob(ctx, data)
+	case sendBroadcastBatchData:
+		return nil, false, bm.sendBroadcastBatch(ctx, op.ID, data)
 	default:
 		return nil, false, i18n.NewError(ctx, i18n.MsgOperationDataIncorrect, op.Data)
 	}
@@ -126,9 +170,9 @@ func (bm *broadcastManager) RunOperation(ctx context.Context, op *fftypes.Prepar
 // uploadBatch uploads the serialized batch to public storage
 func (bm *broadcastManager) uploadBatch(ctx context.Context, data uploadBatchData) (outputs fftypes.JSONObject, complete bool, err error) {
 	// Serialize the full payload, which has already been sealed for us by the BatchManager
-	payload, err := json.Marshal(data.Batch)
+	payload, err := fftypes.SerializeTransportPayload(ctx, data.Batch.ProtocolVersion, data.Batch)
 	if err != nil {
-		return nil, false, i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
+		return nil, false, err
 	}
 
 	// Write it to IPFS to get a payload reference
@@ -170,18 +214,42 @@ func (bm *broadcastManager) uploadBlob(ctx context.Context, data uploadBlobData)
 	return getUploadBlobOutputs(data.Data.Blob.Public), true, nil
 }
 
+// sendBroadcastBatch sends the serialized batch to another node over data exchange, in the same transport
+// wrapper as a private batch. The receiver tells them apart by the batch type.
+func (bm *broadcastManager) sendBroadcastBatch(ctx context.Context, opID *fftypes.UUID, data sendBroadcastBatchData) error {
+	payload, err := fftypes.SerializeTransportPayload(ctx, data.Batch.ProtocolVersion, &fftypes.TransportWrapper{Batch: data.Batch})
+	if err != nil {
+		return err
+	}
+	return bm.exchange.SendMessage(ctx, opID, data.Node.Profile.GetString("id"), payload)
+}
+
 func opUploadBatch(op *fftypes.Operation, batch *fftypes.Batch, batchPersisted *fftypes.BatchPersisted) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: uploadBatchData{Batch: batch, BatchPersisted: batchPersisted},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        uploadBatchData{Batch: batch, BatchPersisted: batchPersisted},
 	}
 }
 
 func opUploadBlob(op *fftypes.Operation, data *fftypes.Data, blob *fftypes.Blob) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: uploadBlobData{Data: data, Blob: blob},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        uploadBlobData{Data: data, Blob: blob},
+	}
+}
+
+func opSendBroadcastBatch(op *fftypes.Operation, node *fftypes.Identity, batch *fftypes.Batch) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        sendBroadcastBatchData{Node: node, Batch: batch},
 	}
 }
@@ -17,6 +17,7 @@ package broadcast
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -106,6 +107,194 @@ func TestPrepareAndRunBatchBroadcastHydrateFail(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestPrepareAndRunSendBroadcastBatch(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	node := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID: fftypes.NewUUID(),
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{"id": "peer1"},
+		},
+	}
+	bp := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.BatchTypeBroadcast,
+		},
+	}
+	batch := &fftypes.Batch{
+		BatchHeader:     bp.BatchHeader,
+		ProtocolVersion: fftypes.ProtocolVersion1,
+	}
+	addSendBroadcastBatchInputs(op, node.ID, bp.ID)
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdx := bm.exchange.(*dataexchangemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), node.ID).Return(node, nil)
+	mdi.On("GetBatchByID", context.Background(), bp.ID).Return(bp, nil)
+	mdm.On("HydrateBatch", context.Background(), bp).Return(batch, nil)
+	mdx.On("SendMessage", context.Background(), op.ID, "peer1", mock.MatchedBy(func(payload []byte) bool {
+		var tw *fftypes.TransportWrapper
+		_, err := fftypes.DeserializeTransportPayload(context.Background(), payload, 1024*1024, &tw)
+		return err == nil && tw.Batch.ID.Equals(bp.ID) && tw.Batch.Type == fftypes.BatchTypeBroadcast
+	})).Return(nil)
+
+	po, err := bm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, node, po.Data.(sendBroadcastBatchData).Node)
+	assert.Equal(t, batch, po.Data.(sendBroadcastBatchData).Batch)
+
+	_, complete, err := bm.RunOperation(context.Background(), po)
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestPrepareSendBroadcastBatchBadInput(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeDataExchangeSendBroadcastBatch,
+		Input: fftypes.JSONObject{"node": "bad"},
+	}
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestPrepareSendBroadcastBatchNodeFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	nodeID := fftypes.NewUUID()
+	addSendBroadcastBatchInputs(op, nodeID, fftypes.NewUUID())
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareSendBroadcastBatchNodeNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	nodeID := fftypes.NewUUID()
+	addSendBroadcastBatchInputs(op, nodeID, fftypes.NewUUID())
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, nil)
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareSendBroadcastBatchGetBatchFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	nodeID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	addSendBroadcastBatchInputs(op, nodeID, batchID)
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(&fftypes.Identity{}, nil)
+	mdi.On("GetBatchByID", context.Background(), batchID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareSendBroadcastBatchNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	nodeID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	addSendBroadcastBatchInputs(op, nodeID, batchID)
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(&fftypes.Identity{}, nil)
+	mdi.On("GetBatchByID", context.Background(), batchID).Return(nil, nil)
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareSendBroadcastBatchHydrateFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendBroadcastBatch,
+	}
+	nodeID := fftypes.NewUUID()
+	bp := &fftypes.BatchPersisted{BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID()}}
+	addSendBroadcastBatchInputs(op, nodeID, bp.ID)
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(&fftypes.Identity{}, nil)
+	mdi.On("GetBatchByID", context.Background(), bp.ID).Return(bp, nil)
+	mdm.On("HydrateBatch", context.Background(), bp).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestRunSendBroadcastBatchSendFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeSendBroadcastBatch}
+	node := &fftypes.Identity{IdentityProfile: fftypes.IdentityProfile{Profile: fftypes.JSONObject{"id": "peer1"}}}
+	batch := &fftypes.Batch{ProtocolVersion: fftypes.ProtocolVersion2}
+
+	mdx := bm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("SendMessage", context.Background(), op.ID, "peer1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, _, err := bm.RunOperation(context.Background(), opSendBroadcastBatch(op, node, batch))
+	assert.EqualError(t, err, "pop")
+
+	mdx.AssertExpectations(t)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -248,6 +437,41 @@ func TestRunOperationBatchBroadcast(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestRunOperationBatchBroadcastCompressed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &fftypes.Operation{}
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+		ProtocolVersion: fftypes.ProtocolVersion2,
+	}
+
+	mps := bm.sharedstorage.(*sharedstoragemocks.Plugin)
+	mdi := bm.database.(*databasemocks.Plugin)
+	var uploaded []byte
+	mps.On("UploadData", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+		uploaded, _ = ioutil.ReadAll(args[1].(io.Reader))
+	}).Return("123", nil)
+	mdi.On("UpdateBatch", context.Background(), batch.ID, mock.Anything).Return(nil)
+
+	bp := &fftypes.BatchPersisted{}
+	_, complete, err := bm.RunOperation(context.Background(), opUploadBatch(op, batch, bp))
+	assert.True(t, complete)
+	assert.NoError(t, err)
+
+	var received *fftypes.Batch
+	version, err := fftypes.DeserializeTransportPayload(context.Background(), uploaded, 1024*1024, &received)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion2, version)
+	assert.Equal(t, batch.ID, received.ID)
+
+	mps.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
 func TestPrepareAndRunUploadBlob(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -0,0 +1,202 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Listener is notified each time a circuit opens, or closes again
+type Listener func(status *fftypes.CircuitStatus)
+
+// Breaker stops calls being made to a plugin after a number of consecutive failures.
+// Once the reset timeout has passed, a single probe call is allowed through (half-open),
+// and the circuit closes again if that call succeeds.
+type Breaker struct {
+	id           *fftypes.UUID
+	name         string
+	threshold    int
+	resetTimeout time.Duration
+
+	mux         sync.Mutex
+	state       fftypes.CircuitState
+	failures    int
+	probing     bool
+	lastError   string
+	lastFailure *fftypes.FFTime
+	opened      *fftypes.FFTime
+}
+
+var (
+	registryMux sync.Mutex
+	breakers    = map[string]*Breaker{}
+	listeners   []Listener
+)
+
+// NewBreaker creates a circuit breaker, and registers it so its status is reported
+func NewBreaker(name string, failureThreshold int, resetTimeout time.Duration) *Breaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	b := &Breaker{
+		id:           fftypes.NewUUID(),
+		name:         name,
+		threshold:    failureThreshold,
+		resetTimeout: resetTimeout,
+		state:        fftypes.CircuitStateClosed,
+	}
+	registryMux.Lock()
+	breakers[name] = b
+	registryMux.Unlock()
+	return b
+}
+
+// AddListener registers a listener for circuits opening and closing
+func AddListener(l Listener) {
+	registryMux.Lock()
+	listeners = append(listeners, l)
+	registryMux.Unlock()
+}
+
+// Statuses returns the status of all registered circuit breakers, sorted by name
+func Statuses() []*fftypes.CircuitStatus {
+	registryMux.Lock()
+	all := make([]*Breaker, 0, len(breakers))
+	for _, b := range breakers {
+		all = append(all, b)
+	}
+	registryMux.Unlock()
+
+	statuses := make([]*fftypes.CircuitStatus, len(all))
+	for i, b := range all {
+		statuses[i] = b.Status()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Reset removes all registered circuit breakers and listeners
+func Reset() {
+	registryMux.Lock()
+	breakers = map[string]*Breaker{}
+	listeners = nil
+	registryMux.Unlock()
+}
+
+func notify(status *fftypes.CircuitStatus) {
+	registryMux.Lock()
+	toNotify := make([]Listener, len(listeners))
+	copy(toNotify, listeners)
+	registryMux.Unlock()
+
+	for _, l := range toNotify {
+		l(status)
+	}
+}
+
+// Allow returns an error if the call must not be attempted, because the circuit is open
+func (b *Breaker) Allow(ctx context.Context) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	switch b.state {
+	case fftypes.CircuitStateOpen:
+		retryAt := fftypes.FFTime(b.opened.Time().Add(b.resetTimeout))
+		if time.Now().Before(*retryAt.Time()) {
+			return i18n.NewError(ctx, i18n.MsgCircuitOpen, b.name, b.failures, retryAt.String())
+		}
+		log.L(ctx).Infof("Circuit '%s' half-open - probing after %d consecutive failures", b.name, b.failures)
+		b.state = fftypes.CircuitStateHalfOpen
+		b.probing = true
+		return nil
+	case fftypes.CircuitStateHalfOpen:
+		if b.probing {
+			return i18n.NewError(ctx, i18n.MsgCircuitOpen, b.name, b.failures, "the current probe completes")
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call, closing the circuit if it was open
+func (b *Breaker) Success(ctx context.Context) {
+	b.mux.Lock()
+	closed := b.state != fftypes.CircuitStateClosed
+	b.state = fftypes.CircuitStateClosed
+	b.failures = 0
+	b.probing = false
+	b.opened = nil
+	status := b.statusLocked()
+	b.mux.Unlock()
+
+	if closed {
+		log.L(ctx).Infof("Circuit '%s' closed", b.name)
+		notify(status)
+	}
+}
+
+// Failure records a failed call, opening the circuit once the failure threshold is reached,
+// or immediately if the failure was the probe of a half-open circuit
+func (b *Breaker) Failure(ctx context.Context, reason string) {
+	b.mux.Lock()
+	b.failures++
+	b.lastError = reason
+	b.lastFailure = fftypes.Now()
+	b.probing = false
+	opened := b.state == fftypes.CircuitStateHalfOpen ||
+		(b.state == fftypes.CircuitStateClosed && b.failures >= b.threshold)
+	if opened {
+		b.state = fftypes.CircuitStateOpen
+		b.opened = b.lastFailure
+	}
+	status := b.statusLocked()
+	b.mux.Unlock()
+
+	if opened {
+		log.L(ctx).Errorf("Circuit '%s' opened after %d consecutive failures: %s", b.name, status.ConsecutiveFailures, reason)
+		notify(status)
+	}
+}
+
+// Status returns the current status of the circuit
+func (b *Breaker) Status() *fftypes.CircuitStatus {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.statusLocked()
+}
+
+func (b *Breaker) statusLocked() *fftypes.CircuitStatus {
+	return &fftypes.CircuitStatus{
+		ID:                  b.id,
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.threshold,
+		LastError:           b.lastError,
+		LastFailure:         b.lastFailure,
+		Opened:              b.opened,
+	}
+}
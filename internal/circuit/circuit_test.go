@@ -0,0 +1,111 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerOpenProbeClose(t *testing.T) {
+	Reset()
+	defer Reset()
+	ctx := context.Background()
+
+	var changes []*fftypes.CircuitStatus
+	AddListener(func(status *fftypes.CircuitStatus) {
+		changes = append(changes, status)
+	})
+
+	b := NewBreaker("ut", 2, 1*time.Hour)
+	assert.NoError(t, b.Allow(ctx))
+	b.Failure(ctx, "pop1")
+	assert.NoError(t, b.Allow(ctx))
+	assert.Equal(t, fftypes.CircuitStateClosed, b.Status().State)
+	assert.Empty(t, changes)
+
+	b.Failure(ctx, "pop2")
+	assert.Equal(t, fftypes.CircuitStateOpen, b.Status().State)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, fftypes.CircuitStateOpen, changes[0].State)
+	assert.Equal(t, 2, changes[0].ConsecutiveFailures)
+	assert.Equal(t, "pop2", changes[0].LastError)
+	assert.NotNil(t, changes[0].Opened)
+
+	err := b.Allow(ctx)
+	assert.Regexp(t, "FF10388.*ut", err)
+
+	// Late failures from calls already in flight do not re-notify
+	b.Failure(ctx, "pop3")
+	assert.Len(t, changes, 1)
+
+	// Move past the reset timeout, to allow a single probe
+	b.resetTimeout = 0
+	assert.NoError(t, b.Allow(ctx))
+	assert.Equal(t, fftypes.CircuitStateHalfOpen, b.Status().State)
+	err = b.Allow(ctx)
+	assert.Regexp(t, "FF10388", err)
+
+	b.Success(ctx)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, fftypes.CircuitStateClosed, changes[1].State)
+	assert.Zero(t, changes[1].ConsecutiveFailures)
+	assert.Nil(t, changes[1].Opened)
+
+	// Successes on a closed circuit do not notify
+	b.Success(ctx)
+	assert.Len(t, changes, 2)
+}
+
+func TestBreakerProbeFails(t *testing.T) {
+	Reset()
+	defer Reset()
+	ctx := context.Background()
+
+	b := NewBreaker("ut", 0, 0)
+	b.Failure(ctx, "pop")
+	assert.Equal(t, fftypes.CircuitStateOpen, b.Status().State)
+
+	assert.NoError(t, b.Allow(ctx))
+	assert.Equal(t, fftypes.CircuitStateHalfOpen, b.Status().State)
+	b.Failure(ctx, "pop again")
+	assert.Equal(t, fftypes.CircuitStateOpen, b.Status().State)
+
+	// The next probe can go through once the circuit is half-open again
+	assert.NoError(t, b.Allow(ctx))
+	b.probing = false
+	assert.NoError(t, b.Allow(ctx))
+}
+
+func TestStatuses(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	NewBreaker("plugin2", 5, time.Second)
+	NewBreaker("plugin1", 5, time.Second)
+
+	statuses := Statuses()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "plugin1", statuses[0].Name)
+	assert.Equal(t, "plugin2", statuses[1].Name)
+	assert.Equal(t, 5, statuses[0].FailureThreshold)
+	assert.Equal(t, fftypes.CircuitStateClosed, statuses[0].State)
+}
@@ -48,34 +48,76 @@ var (
 	APIMaxFilterLimit = rootKey("api.maxFilterLimit")
 	// APIMaxFilterSkip is the maximum skip value that can be specified on the API
 	APIMaxFilterSkip = rootKey("api.maxFilterLimit")
+	// APIAsyncRequestRetention is how long the outcome of a request processed in the background is kept for polling, after it completes
+	APIAsyncRequestRetention = rootKey("api.asyncRequestRetention")
+	// APIAsyncRequestMaxPending is the maximum number of requests processed in the background at the same time
+	APIAsyncRequestMaxPending = rootKey("api.asyncRequestMaxPending")
+	// APIAsyncRequestMaxRetained is the maximum number of requests held for polling, with the oldest completed requests evicted first
+	APIAsyncRequestMaxRetained = rootKey("api.asyncRequestMaxRetained")
+	// APIAsyncRequestMaxWait is the maximum wait a poll for the outcome of a request can ask for
+	APIAsyncRequestMaxWait = rootKey("api.asyncRequestMaxWait")
 	// APIRequestTimeout is the server side timeout for API calls (context timeout), to avoid the server continuing processing when the client gives up
 	APIRequestTimeout = rootKey("api.requestTimeout")
 	// APIRequestMaxTimeout is the maximum timeout an application can set using a Request-Timeout header
 	APIRequestMaxTimeout = rootKey("api.requestMaxTimeout")
 	// APIShutdownTimeout is the amount of time to wait for any in-flight requests to finish before killing the HTTP server
 	APIShutdownTimeout = rootKey("api.shutdownTimeout")
+	// ApprovalsApprovers is the list of callers, by their name in the namespace access list, that can approve or reject the requests held for approval
+	ApprovalsApprovers = rootKey("approvals.approvers")
+	// ApprovalsRequired is the number of approvals a request needs before it is submitted
+	ApprovalsRequired = rootKey("approvals.required")
+	// ApprovalsTokenMintThreshold is the amount above which a token mint is held for approval. Mints are not held if empty
+	ApprovalsTokenMintThreshold = rootKey("approvals.tokenMint.threshold")
 	// BatchCacheSize
 	BatchCacheSize = rootKey("batch.cache.size")
 	// BatchCacheSize
 	BatchCacheTTL = rootKey("batch.cache.ttl")
+	// BatchHashAlgorithm is the algorithm used to hash the manifest of each batch sealed by this node
+	BatchHashAlgorithm = rootKey("batch.hashAlgorithm")
 	// BatchManagerReadPageSize is the size of each page of messages read from the database into memory when assembling batches
 	BatchManagerReadPageSize = rootKey("batch.manager.readPageSize")
 	// BatchManagerReadPollTimeout is how long without any notifications of new messages to wait, before doing a page query
 	BatchManagerReadPollTimeout = rootKey("batch.manager.pollTimeout")
 	// BatchManagerMinimumPollDelay is the minimum time the batch manager waits between polls on the DB - to prevent thrashing
 	BatchManagerMinimumPollDelay = rootKey("batch.manager.minimumPollDelay")
+	// BatchManagerPendingDataPollInterval is how often messages waiting for deferred data are checked, to see if their data has arrived
+	BatchManagerPendingDataPollInterval = rootKey("batch.manager.pendingDataPollInterval")
+	// BatchManagerPendingDataTimeout is how long a message can wait for deferred data to be uploaded, before it is rejected
+	BatchManagerPendingDataTimeout = rootKey("batch.manager.pendingDataTimeout")
+	// BatchManagerScheduledPollInterval is how often scheduled draft messages are checked, to see if they are due to be sent
+	BatchManagerScheduledPollInterval = rootKey("batch.manager.scheduledPollInterval")
+	// BatchMigrationPageSize is the number of batches read from the database at a time, when migrating batches persisted by v0.13.x and earlier
+	BatchMigrationPageSize = rootKey("batch.migration.pageSize")
 	// BatchRetryFactor is the retry backoff factor for database operations performed by the batch manager
 	BatchRetryFactor = rootKey("batch.retry.factor")
 	// BatchRetryInitDelay is the retry initial delay for database operations
 	BatchRetryInitDelay = rootKey("batch.retry.initDelay")
 	// BatchRetryMaxDelay is the maximum delay between retry attempts
 	BatchRetryMaxDelay = rootKey("batch.retry.maxDelay")
+	// BlobHashAlgorithm is the algorithm used to hash blobs uploaded to this node
+	BlobHashAlgorithm = rootKey("blob.hashAlgorithm")
+	// BlobAdditionalHashAlgorithms are algorithms, other than SHA-256, that blobs received from other members are also hashed with, so they can be matched to data that refers to them by that hash
+	BlobAdditionalHashAlgorithms = rootKey("blob.additionalHashAlgorithms")
 	// BlockchainEventCacheSize size of cache for blockchain events
 	BlockchainEventCacheSize = rootKey("blockchainevent.cache.size")
 	// BlockchainEventCacheTTL time to live of cache for blockchain events
 	BlockchainEventCacheTTL = rootKey("blockchainevent.cache.ttl")
+	// BlockchainEventInfoMaxSize is the largest raw info stored with a blockchain event, above which it is replaced with a summary. Zero means no limit
+	BlockchainEventInfoMaxSize = rootKey("blockchainevent.info.maxSize")
+	// BlockchainEventRawCompress whether to compress the full raw info retained for listeners with the storeRaw option
+	BlockchainEventRawCompress = rootKey("blockchainevent.raw.compress")
 	// BlockchainType is the name of the blockchain interface plugin being used by this firefly node
 	BlockchainType = rootKey("blockchain.type")
+	// BootstrapDirectory is a directory of YAML/JSON files containing definitions to apply at startup, if they do not already exist
+	BootstrapDirectory = rootKey("bootstrap.directory")
+	// BootstrapUpdate re-applies bootstrap contract APIs and subscriptions that already exist, when their definition has changed
+	BootstrapUpdate = rootKey("bootstrap.update")
+	// BootstrapRetryFactor the backoff factor to use for retrying the bootstrap definitions
+	BootstrapRetryFactor = rootKey("bootstrap.retry.factor")
+	// BootstrapRetryInitDelay the initial delay to use for retrying the bootstrap definitions
+	BootstrapRetryInitDelay = rootKey("bootstrap.retry.initDelay")
+	// BootstrapRetryMaxDelay the maximum delay to use for retrying the bootstrap definitions
+	BootstrapRetryMaxDelay = rootKey("bootstrap.retry.maxDelay")
 	// BroadcastBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
 	BroadcastBatchAgentTimeout = rootKey("broadcast.batch.agentTimeout")
 	// BroadcastBatchSize is the maximum number of messages that can be packed into a batch
@@ -84,6 +126,20 @@ var (
 	BroadcastBatchPayloadLimit = rootKey("broadcast.batch.payloadLimit")
 	// BroadcastBatchTimeout is the timeout to wait for a batch to fill, before sending
 	BroadcastBatchTimeout = rootKey("broadcast.batch.timeout")
+	// BroadcastHybridEnabled sends each broadcast batch to the other nodes in the network over data exchange, as well as uploading it to shared storage
+	BroadcastHybridEnabled = rootKey("broadcast.hybrid.enabled")
+	// BroadcastHybridRetryMaxAttempts is the maximum number of attempts to send a broadcast batch to each node in hybrid mode
+	BroadcastHybridRetryMaxAttempts = rootKey("broadcast.hybrid.retry.maxAttempts")
+	// BroadcastHybridRetryInitDelay is the initial retry delay for a send to a node in hybrid mode
+	BroadcastHybridRetryInitDelay = rootKey("broadcast.hybrid.retry.initDelay")
+	// BroadcastHybridRetryMaxDelay is the maximum retry delay for a send to a node in hybrid mode
+	BroadcastHybridRetryMaxDelay = rootKey("broadcast.hybrid.retry.maxDelay")
+	// BroadcastHybridRetryFactor is the backoff factor for retries of a send to a node in hybrid mode
+	BroadcastHybridRetryFactor = rootKey("broadcast.hybrid.retry.factor")
+	// BusinessLinkedNamespaces is a list of namespaces whose records are included in each other's business transaction lookups
+	BusinessLinkedNamespaces = rootKey("business.linkedNamespaces")
+	// BusinessMaxResults is the maximum number of records of each type returned by a business transaction lookup
+	BusinessMaxResults = rootKey("business.maxResults")
 	// DownloadWorkerCount is the number of download workers created to pull data from shared storage to the local DX
 	DownloadWorkerCount = rootKey("download.worker.count")
 	// DownloadWorkerQueueLength is the length of the work queue in the channel to the workers - defaults to 2x the worker count
@@ -104,6 +160,10 @@ var (
 	PrivateMessagingBatchPayloadLimit = rootKey("privatemessaging.batch.payloadLimit")
 	// PrivateMessagingBatchTimeout is the timeout to wait for a batch to fill, before sending
 	PrivateMessagingBatchTimeout = rootKey("privatemessaging.batch.timeout")
+	// PrivateMessagingDeliveryAcksEnabled if true, recipients acknowledge processing of private messages back to the sending node, and senders track the status of each recipient
+	PrivateMessagingDeliveryAcksEnabled = rootKey("privatemessaging.deliveryAcks.enabled")
+	// PrivateMessagingDeliveryAcksSigningKeyFile is a PEM encoded PKCS#8 ed25519 private key, used to sign the delivery acknowledgements sent by this node
+	PrivateMessagingDeliveryAcksSigningKeyFile = rootKey("privatemessaging.deliveryAcks.signingKeyFile")
 	// PrivateMessagingOpCorrelationRetries how many times to correlate an event for an operation (such as tx submission) back to an operation.
 	// Needed because the operation update might come back before we are finished persisting the ID of the request
 	PrivateMessagingOpCorrelationRetries = rootKey("privatemessaging.opCorrelationRetries")
@@ -113,6 +173,14 @@ var (
 	PrivateMessagingRetryInitDelay = rootKey("privatemessaging.retry.initDelay")
 	// PrivateMessagingRetryMaxDelay the maximum delay to use for retry of data base operations
 	PrivateMessagingRetryMaxDelay = rootKey("privatemessaging.retry.maxDelay")
+	// ContractsQueryCacheSize the maximum number of query results to cache, for contract APIs with query caching enabled
+	ContractsQueryCacheSize = rootKey("contracts.queryCache.size")
+	// ContractsQueryCacheTTL the default time to live for cached query results, when not set on the contract API
+	ContractsQueryCacheTTL = rootKey("contracts.queryCache.ttl")
+	// ContractsErrorCacheSize the maximum number of interfaces to cache the custom errors of, for decoding the failures of invocations
+	ContractsErrorCacheSize = rootKey("contracts.errorCache.size")
+	// ContractsErrorCacheTTL how long the custom errors of an interface are cached for
+	ContractsErrorCacheTTL = rootKey("contracts.errorCache.ttl")
 	// CorsAllowCredentials CORS setting to control whether a browser allows credentials to be sent to this API
 	CorsAllowCredentials = rootKey("cors.credentials")
 	// CorsAllowedHeaders CORS setting to control the allowed headers
@@ -135,6 +203,22 @@ var (
 	TokensList = rootKey("tokens")
 	// DebugPort a HTTP port on which to enable the go debugger
 	DebugPort = rootKey("debug.port")
+	// DevIdentitiesEnabled derives the org key, and a set of custom identities, from a seed and registers them at startup
+	DevIdentitiesEnabled = rootKey("devIdentities.enabled")
+	// DevIdentitiesSeed is the seed from which all the development keys are derived
+	DevIdentitiesSeed = rootKey("devIdentities.seed")
+	// DevIdentitiesNamespace is the namespace in which the custom identities are registered
+	DevIdentitiesNamespace = rootKey("devIdentities.namespace")
+	// DevIdentitiesNames is the list of custom identities to register, as children of the node's org
+	DevIdentitiesNames = rootKey("devIdentities.identities")
+	// DevIdentitiesKeyFile is a file to which the derived keys are written, so they can be loaded into a signer
+	DevIdentitiesKeyFile = rootKey("devIdentities.keyFile")
+	// DevIdentitiesRetryFactor the backoff factor to use for retrying the registrations
+	DevIdentitiesRetryFactor = rootKey("devIdentities.retry.factor")
+	// DevIdentitiesRetryInitDelay the initial delay to use for retrying the registrations
+	DevIdentitiesRetryInitDelay = rootKey("devIdentities.retry.initDelay")
+	// DevIdentitiesRetryMaxDelay the maximum delay to use for retrying the registrations
+	DevIdentitiesRetryMaxDelay = rootKey("devIdentities.retry.maxDelay")
 	// EventTransportsDefault the default event transport for new subscriptions
 	EventTransportsDefault = rootKey("event.transports.default")
 	// EventTransportsEnabled which event interface plugins are enabled
@@ -145,6 +229,8 @@ var (
 	EventAggregatorBatchSize = rootKey("event.aggregator.batchSize")
 	// EventAggregatorBatchTimeout how long to wait for new events to arrive before performing aggregation on a page of events
 	EventAggregatorBatchTimeout = rootKey("event.aggregator.batchTimeout")
+	// EventAggregatorLegacyManifests whether to convert batches persisted by v0.13.x and earlier each time they are read - disable once they have been migrated
+	EventAggregatorLegacyManifests = rootKey("event.aggregator.legacyManifests")
 	// EventAggregatorOpCorrelationRetries how many times to correlate an event for an operation (such as tx submission) back to an operation.
 	// Needed because the operation update might come back before we are finished persisting the ID of the request
 	EventAggregatorOpCorrelationRetries = rootKey("event.aggregator.opCorrelationRetries")
@@ -156,6 +242,18 @@ var (
 	EventAggregatorRetryInitDelay = rootKey("event.aggregator.retry.initDelay")
 	// EventAggregatorRetryMaxDelay the maximum delay to use for retry of data base operations
 	EventAggregatorRetryMaxDelay = rootKey("event.aggregator.retry.maxDelay")
+	// EventAggregatorPayloadRefVerifyEnabled whether to re-verify broadcast batch payloads in shared storage against the on-chain hash, before confirming messages
+	EventAggregatorPayloadRefVerifyEnabled = rootKey("event.aggregator.payloadRefVerify.enabled")
+	// EventAggregatorPayloadRefVerifyCacheSize the number of batch verification results to cache
+	EventAggregatorPayloadRefVerifyCacheSize = rootKey("event.aggregator.payloadRefVerify.cache.size")
+	// EventAggregatorPayloadRefVerifyCacheTTL how long to cache batch verification results
+	EventAggregatorPayloadRefVerifyCacheTTL = rootKey("event.aggregator.payloadRefVerify.cache.ttl")
+	// EventAggregatorWatchdogEnabled whether to watch for the aggregator making no progress while there are pins waiting to be processed
+	EventAggregatorWatchdogEnabled = rootKey("event.aggregator.watchdog.enabled")
+	// EventAggregatorWatchdogStallTimeout how long the aggregator can make no progress with pins waiting, before it is reported as stalled
+	EventAggregatorWatchdogStallTimeout = rootKey("event.aggregator.watchdog.stallTimeout")
+	// EventAggregatorWatchdogRestart whether to restart the aggregator event loop when it is detected as stalled, once the stalled loop has exited
+	EventAggregatorWatchdogRestart = rootKey("event.aggregator.watchdog.restart")
 	// EventDispatcherPollTimeout the time to wait without a notification of new events, before trying a select on the table
 	EventDispatcherPollTimeout = rootKey("event.dispatcher.pollTimeout")
 	// EventDispatcherBufferLength the number of events + attachments an individual dispatcher should hold in memory ready for delivery to the subscription
@@ -174,6 +272,14 @@ var (
 	EventListenerTopicCacheSize = rootKey("event.listenerTopic.cache.size")
 	// EventListenerTopicCacheTTL cache time-to-live for private group addresses
 	EventListenerTopicCacheTTL = rootKey("event.listenerTopic.cache.ttl")
+	// ExportDirectory is the directory where data exports to the local disk are written
+	ExportDirectory = rootKey("export.directory")
+	// ExportPageSize is the number of records read from the database at a time by a data export, and written to each Parquet row group
+	ExportPageSize = rootKey("export.pageSize")
+	// ExportSharedStorageEnabled allows exports to be uploaded to the shared storage plugin, which may publish them beyond this node
+	ExportSharedStorageEnabled = rootKey("export.sharedStorage.enabled")
+	// FaultsScenario is the path to a fault injection scenario file - only supported by builds with the "faults" tag
+	FaultsScenario = rootKey("faults.scenario")
 	// GroupCacheSize cache size for private group addresses
 	GroupCacheSize = rootKey("group.cache.size")
 	// GroupCacheTTL cache time-to-live for private group addresses
@@ -214,6 +320,20 @@ var (
 	MessageCacheSize = rootKey("message.cache.size")
 	// MessageCacheTTL
 	MessageCacheTTL = rootKey("message.cache.ttl")
+	// MessageLimitsMaxMessageBytes is the maximum size of a message, including its in-line data values, that can be submitted to a namespace
+	MessageLimitsMaxMessageBytes = rootKey("message.limits.maxMessageBytes")
+	// MessageLimitsMaxDataItems is the maximum number of data items that can be attached to a message
+	MessageLimitsMaxDataItems = rootKey("message.limits.maxDataItems")
+	// MessageLimitsMaxBlobBytes is the maximum size of a blob that can be uploaded
+	MessageLimitsMaxBlobBytes = rootKey("message.limits.maxBlobBytes")
+	// MessageImportConcurrency is the number of messages from a bulk import that are submitted in parallel - set to 1 to preserve the order of the import
+	MessageImportConcurrency = rootKey("message.import.concurrency")
+	// MessageImportMaxMessages is the maximum number of messages that can be submitted in a single bulk import
+	MessageImportMaxMessages = rootKey("message.import.maxMessages")
+	// MessageImportPageSize is the number of messages read from a bulk import at a time, with the progress checkpointed on the operation after each page
+	MessageImportPageSize = rootKey("message.import.pageSize")
+	// MessageImportProgressInterval is how often the progress of a bulk import is written to its operation
+	MessageImportProgressInterval = rootKey("message.import.progressInterval")
 	// MessageWriterCount
 	MessageWriterCount = rootKey("message.writer.count")
 	// MessageWriterBatchTimeout
@@ -224,8 +344,14 @@ var (
 	MetricsEnabled = rootKey("metrics.enabled")
 	// MetricsPath determines what path to serve the Prometheus metrics from
 	MetricsPath = rootKey("metrics.path")
+	// MetricsExemplarsEnabled determines whether latency histograms are observed with exemplars of the correlation ID and transaction ID, and served in OpenMetrics format
+	MetricsExemplarsEnabled = rootKey("metrics.exemplars.enabled")
 	// NamespacesDefault is the default namespace - must be in the predefines list
 	NamespacesDefault = rootKey("namespaces.default")
+	// NamespacesEphemeralInterval is how often the old records of ephemeral namespaces are deleted
+	NamespacesEphemeralInterval = rootKey("namespaces.ephemeral.interval")
+	// NamespacesEphemeralMaxAge is the age after which the records of an ephemeral namespace are deleted
+	NamespacesEphemeralMaxAge = rootKey("namespaces.ephemeral.maxAge")
 	// NamespacesPredefined is a list of namespaces to ensure exists, without requiring a broadcast from the network
 	NamespacesPredefined = rootKey("namespaces.predefined")
 	// NodeName is a description for the node
@@ -242,14 +368,54 @@ var (
 	OrgDescription = rootKey("org.description")
 	// OrchestratorStartupAttempts is how many time to attempt to connect to core infrastructure on startup
 	OrchestratorStartupAttempts = rootKey("orchestrator.startupAttempts")
+	// OperationsOutputMaxInlineSize is the largest operation output stored inline - larger outputs are stored separately, referenced from the operation
+	OperationsOutputMaxInlineSize = rootKey("operations.output.maxInlineSize")
+	// OperationsCallbackAllowedHosts is the list of hosts that operation callbacks can be delivered to, where "*.example.com" allows any subdomain of example.com - callbacks are rejected if not set
+	OperationsCallbackAllowedHosts = rootKey("operations.callbacks.allowedHosts")
+	// OperationsCallbackBatchSize is the maximum number of callbacks delivered in each pass of the callback dispatcher
+	OperationsCallbackBatchSize = rootKey("operations.callbacks.batchSize")
+	// OperationsCallbackPollInterval is how often callbacks are checked for deliveries that are due, when the dispatcher has not been notified of new ones
+	OperationsCallbackPollInterval = rootKey("operations.callbacks.pollInterval")
+	// OperationsCallbackRequestTimeout is the timeout for each attempt to deliver an operation callback
+	OperationsCallbackRequestTimeout = rootKey("operations.callbacks.requestTimeout")
+	// OperationsCallbackRetryMaxAttempts is the maximum number of attempts to deliver an operation callback
+	OperationsCallbackRetryMaxAttempts = rootKey("operations.callbacks.retry.maxAttempts")
+	// OperationsCallbackRetryInitDelay is the initial retry delay
+	OperationsCallbackRetryInitDelay = rootKey("operations.callbacks.retry.initialDelay")
+	// OperationsCallbackRetryMaxDelay is the maximum retry delay
+	OperationsCallbackRetryMaxDelay = rootKey("operations.callbacks.retry.maxDelay")
+	// OperationsCallbackRetryFactor is the backoff factor to use for retries
+	OperationsCallbackRetryFactor = rootKey("operations.callbacks.retry.factor")
+	// OperationsCallbackSigningSecret is the secret used to sign operation callbacks with an HMAC-SHA256 signature header - callbacks are unsigned if not set
+	OperationsCallbackSigningSecret = rootKey("operations.callbacks.signingSecret")
+	// OperationsOutboxBatchSize is the maximum number of queued operations submitted in each pass of the outbox dispatcher
+	OperationsOutboxBatchSize = rootKey("operations.outbox.batchSize")
+	// OperationsOutboxPollInterval is how often the outbox is checked for operations that are due, when it has not been notified of new ones
+	OperationsOutboxPollInterval = rootKey("operations.outbox.pollInterval")
+	// OperationsOutboxRetryMaxAttempts is the maximum number of attempts to submit a queued operation before failing the operation
+	OperationsOutboxRetryMaxAttempts = rootKey("operations.outbox.retry.maxAttempts")
+	// OperationsOutboxRetryInitDelay is the initial retry delay
+	OperationsOutboxRetryInitDelay = rootKey("operations.outbox.retry.initialDelay")
+	// OperationsOutboxRetryMaxDelay is the maximum retry delay
+	OperationsOutboxRetryMaxDelay = rootKey("operations.outbox.retry.maxDelay")
+	// OperationsOutboxRetryFactor is the backoff factor to use for retries
+	OperationsOutboxRetryFactor = rootKey("operations.outbox.retry.factor")
+	// RebuildPageSize is the number of records read from the database at a time, when clearing derived state to rebuild the node
+	RebuildPageSize = rootKey("rebuild.pageSize")
 	// SharedStorageType specifies which shared storage interface plugin to use
 	SharedStorageType = rootKey("sharedstorage.type")
 	// PublicStorageType specifies which shared storage interface plugin to use - deprecated in favor of SharedStorageType
 	PublicStorageType = rootKey("publicstorage.type")
+	// StandbyEnabled starts the node in standby mode, attached to a replicated database, until it is promoted through the admin API
+	StandbyEnabled = rootKey("standby.enabled")
+	// StandbyPromoteQuiesceTime how long the latest sequences in the database must be unchanged, before a standby node can be promoted
+	StandbyPromoteQuiesceTime = rootKey("standby.promote.quiesceTime")
 	// SubscriptionDefaultsReadAhead default read ahead to enable for subscriptions that do not explicitly configure readahead
 	SubscriptionDefaultsReadAhead = rootKey("subscription.defaults.batchSize")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
 	SubscriptionMax = rootKey("subscription.max")
+	// SubscriptionMetricsInterval how often the lag and delivery rate of each durable subscription is recalculated for metrics, which is also the window the delivery rate is measured over
+	SubscriptionMetricsInterval = rootKey("subscription.metrics.interval")
 	// SubscriptionsRetryInitialDelay is the initial retry delay
 	SubscriptionsRetryInitialDelay = rootKey("subscription.retry.initDelay")
 	// SubscriptionsRetryMaxDelay is the initial retry delay
@@ -262,6 +428,8 @@ var (
 	TransactionCacheTTL = rootKey("transaction.cache.ttl")
 	// AssetManagerKeyNormalization mechanism to normalize keys before using them. Valid options: "blockchain_plugin" - use blockchain plugin (default), "none" - do not attempt normalization
 	AssetManagerKeyNormalization = rootKey("asset.manager.keyNormalization")
+	// AssetManagerBulkTransferMax is the maximum number of transfers that can be submitted in a single bulk transfer request
+	AssetManagerBulkTransferMax = rootKey("asset.manager.bulkTransferMax")
 	// UIEnabled set to false to disable the UI (default is true, so UI will be enabled if ui.path is valid)
 	UIEnabled = rootKey("ui.enabled")
 	// UIPath the path on which to serve the UI
@@ -270,6 +438,10 @@ var (
 	ValidatorCacheSize = rootKey("validator.cache.size")
 	// ValidatorCacheTTL
 	ValidatorCacheTTL = rootKey("validator.cache.ttl")
+	// ValidatorJSONAssertFormat whether the "format" keyword is asserted for JSON schemas of draft 2019-09 and later
+	ValidatorJSONAssertFormat = rootKey("validator.json.assertFormat")
+	// ValidatorJSONDefaultDraft the JSON schema draft used for datatypes that do not declare a $schema
+	ValidatorJSONDefaultDraft = rootKey("validator.json.defaultDraft")
 )
 
 type KeySet interface {
@@ -319,8 +491,13 @@ func Reset() {
 	defer keysMutex.Unlock()
 
 	viper.Reset()
+	secretValues = map[string]string{}
 
 	// Set defaults
+	viper.SetDefault(string(APIAsyncRequestRetention), "1h")
+	viper.SetDefault(string(APIAsyncRequestMaxPending), 100)
+	viper.SetDefault(string(APIAsyncRequestMaxRetained), 1000)
+	viper.SetDefault(string(APIAsyncRequestMaxWait), "60s")
 	viper.SetDefault(string(APIDefaultFilterLimit), 25)
 	viper.SetDefault(string(APIRequestTimeout), "120s")
 	viper.SetDefault(string(APIRequestMaxTimeout), "10m")
@@ -329,21 +506,46 @@ func Reset() {
 	viper.SetDefault(string(APIRequestTimeout), "120s")
 	viper.SetDefault(string(APIShutdownTimeout), "10s")
 	viper.SetDefault(string(AssetManagerKeyNormalization), "blockchain_plugin")
+	viper.SetDefault(string(AssetManagerBulkTransferMax), 1000)
 	viper.SetDefault(string(BatchCacheSize), "1Mb")
 	viper.SetDefault(string(BatchCacheTTL), "5m")
+	viper.SetDefault(string(BatchHashAlgorithm), "sha256")
 	viper.SetDefault(string(BatchManagerReadPageSize), 100)
 	viper.SetDefault(string(BatchManagerReadPollTimeout), "30s")
 	viper.SetDefault(string(BatchManagerMinimumPollDelay), "100ms")
+	viper.SetDefault(string(BatchManagerPendingDataPollInterval), "5s")
+	viper.SetDefault(string(BatchManagerPendingDataTimeout), "10m")
+	viper.SetDefault(string(BatchManagerScheduledPollInterval), "1s")
+	viper.SetDefault(string(BatchMigrationPageSize), 100)
 	viper.SetDefault(string(BatchRetryFactor), 2.0)
 	viper.SetDefault(string(BatchRetryFactor), 2.0)
 	viper.SetDefault(string(BatchRetryInitDelay), "250ms")
 	viper.SetDefault(string(BatchRetryInitDelay), "250ms")
 	viper.SetDefault(string(BatchRetryMaxDelay), "30s")
 	viper.SetDefault(string(BatchRetryMaxDelay), "30s")
+	viper.SetDefault(string(BlobHashAlgorithm), "sha256")
+	viper.SetDefault(string(BlobAdditionalHashAlgorithms), []string{})
+	viper.SetDefault(string(BootstrapUpdate), false)
+	viper.SetDefault(string(BootstrapRetryFactor), 2.0)
+	viper.SetDefault(string(BootstrapRetryInitDelay), "1s")
+	viper.SetDefault(string(BootstrapRetryMaxDelay), "30s")
 	viper.SetDefault(string(BroadcastBatchAgentTimeout), "2m")
 	viper.SetDefault(string(BroadcastBatchSize), 200)
 	viper.SetDefault(string(BroadcastBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(BroadcastBatchTimeout), "1s")
+	viper.SetDefault(string(BroadcastHybridEnabled), false)
+	viper.SetDefault(string(BroadcastHybridRetryMaxAttempts), 5)
+	viper.SetDefault(string(BroadcastHybridRetryInitDelay), "250ms")
+	viper.SetDefault(string(BroadcastHybridRetryMaxDelay), "10s")
+	viper.SetDefault(string(BroadcastHybridRetryFactor), 2.0)
+	viper.SetDefault(string(ApprovalsApprovers), []string{})
+	viper.SetDefault(string(ApprovalsRequired), 1)
+	viper.SetDefault(string(BusinessLinkedNamespaces), []string{})
+	viper.SetDefault(string(BusinessMaxResults), 1000)
+	viper.SetDefault(string(ContractsQueryCacheSize), 1000)
+	viper.SetDefault(string(ContractsQueryCacheTTL), "30s")
+	viper.SetDefault(string(ContractsErrorCacheSize), 100)
+	viper.SetDefault(string(ContractsErrorCacheTTL), "1h")
 	viper.SetDefault(string(CorsAllowCredentials), true)
 	viper.SetDefault(string(CorsAllowedHeaders), []string{"*"})
 	viper.SetDefault(string(CorsAllowedMethods), []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete})
@@ -352,6 +554,12 @@ func Reset() {
 	viper.SetDefault(string(CorsMaxAge), 600)
 	viper.SetDefault(string(DataexchangeType), "ffdx")
 	viper.SetDefault(string(DebugPort), -1)
+	viper.SetDefault(string(DevIdentitiesEnabled), false)
+	viper.SetDefault(string(DevIdentitiesSeed), "firefly")
+	viper.SetDefault(string(DevIdentitiesNames), []string{})
+	viper.SetDefault(string(DevIdentitiesRetryFactor), 2.0)
+	viper.SetDefault(string(DevIdentitiesRetryInitDelay), "1s")
+	viper.SetDefault(string(DevIdentitiesRetryMaxDelay), "30s")
 	viper.SetDefault(string(DownloadWorkerCount), 10)
 	viper.SetDefault(string(DownloadRetryMaxAttempts), 100)
 	viper.SetDefault(string(DownloadRetryInitDelay), "100ms")
@@ -364,7 +572,14 @@ func Reset() {
 	viper.SetDefault(string(EventAggregatorRetryFactor), 2.0)
 	viper.SetDefault(string(EventAggregatorRetryInitDelay), "100ms")
 	viper.SetDefault(string(EventAggregatorRetryMaxDelay), "30s")
+	viper.SetDefault(string(EventAggregatorPayloadRefVerifyEnabled), false)
+	viper.SetDefault(string(EventAggregatorPayloadRefVerifyCacheSize), 1000)
+	viper.SetDefault(string(EventAggregatorPayloadRefVerifyCacheTTL), "1h")
+	viper.SetDefault(string(EventAggregatorWatchdogEnabled), true)
+	viper.SetDefault(string(EventAggregatorWatchdogStallTimeout), "5m")
+	viper.SetDefault(string(EventAggregatorWatchdogRestart), false)
 	viper.SetDefault(string(EventAggregatorOpCorrelationRetries), 3)
+	viper.SetDefault(string(EventAggregatorLegacyManifests), true)
 	viper.SetDefault(string(EventDBEventsBufferSize), 100)
 	viper.SetDefault(string(EventDispatcherBufferLength), 5)
 	viper.SetDefault(string(EventDispatcherBatchTimeout), "250ms")
@@ -372,7 +587,12 @@ func Reset() {
 	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks"})
 	viper.SetDefault(string(EventTransportsDefault), "websockets")
 	viper.SetDefault(string(EventListenerTopicCacheSize), "100Kb")
+	viper.SetDefault(string(ExportDirectory), "exports")
+	viper.SetDefault(string(ExportPageSize), 1000)
+	viper.SetDefault(string(ExportSharedStorageEnabled), false)
 	viper.SetDefault(string(EventListenerTopicCacheTTL), "5m")
+	viper.SetDefault(string(BlockchainEventInfoMaxSize), "0")
+	viper.SetDefault(string(BlockchainEventRawCompress), true)
 	viper.SetDefault(string(GroupCacheSize), "1Mb")
 	viper.SetDefault(string(GroupCacheTTL), "1h")
 	viper.SetDefault(string(AdminEnabled), false)
@@ -385,11 +605,21 @@ func Reset() {
 	viper.SetDefault(string(LogMaxAge), "24h")
 	viper.SetDefault(string(LogMaxBackups), 2)
 	viper.SetDefault(string(MessageCacheSize), "50Mb")
+	viper.SetDefault(string(MessageLimitsMaxMessageBytes), "0")
+	viper.SetDefault(string(MessageLimitsMaxDataItems), 0)
+	viper.SetDefault(string(MessageLimitsMaxBlobBytes), "0")
 	viper.SetDefault(string(MessageCacheTTL), "5m")
+	viper.SetDefault(string(MessageImportConcurrency), 10)
+	viper.SetDefault(string(MessageImportMaxMessages), 100000)
+	viper.SetDefault(string(MessageImportPageSize), 100)
+	viper.SetDefault(string(MessageImportProgressInterval), "1s")
 	viper.SetDefault(string(MessageWriterBatchMaxInserts), 200)
 	viper.SetDefault(string(MessageWriterBatchTimeout), "10ms")
 	viper.SetDefault(string(MessageWriterCount), 5)
+	viper.SetDefault(string(MetricsExemplarsEnabled), false)
 	viper.SetDefault(string(NamespacesDefault), "default")
+	viper.SetDefault(string(NamespacesEphemeralInterval), "10m")
+	viper.SetDefault(string(NamespacesEphemeralMaxAge), "24h")
 	viper.SetDefault(string(NamespacesPredefined), fftypes.JSONObjectArray{{"name": "default", "description": "Default predefined namespace"}})
 	viper.SetDefault(string(OrchestratorStartupAttempts), 5)
 	viper.SetDefault(string(PrivateMessagingRetryFactor), 2.0)
@@ -400,16 +630,38 @@ func Reset() {
 	viper.SetDefault(string(PrivateMessagingBatchSize), 200)
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
+	viper.SetDefault(string(PrivateMessagingDeliveryAcksEnabled), false)
+	viper.SetDefault(string(RebuildPageSize), 100)
+	viper.SetDefault(string(StandbyEnabled), false)
+	viper.SetDefault(string(StandbyPromoteQuiesceTime), "5s")
 	viper.SetDefault(string(SubscriptionDefaultsReadAhead), 0)
 	viper.SetDefault(string(SubscriptionMax), 500)
+	viper.SetDefault(string(SubscriptionMetricsInterval), "10s")
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
 	viper.SetDefault(string(SubscriptionsRetryFactor), 2.0)
+	viper.SetDefault(string(OperationsOutputMaxInlineSize), "64Kb")
+	viper.SetDefault(string(OperationsCallbackAllowedHosts), []string{})
+	viper.SetDefault(string(OperationsCallbackBatchSize), 50)
+	viper.SetDefault(string(OperationsCallbackPollInterval), "1s")
+	viper.SetDefault(string(OperationsCallbackRequestTimeout), "30s")
+	viper.SetDefault(string(OperationsCallbackRetryMaxAttempts), 5)
+	viper.SetDefault(string(OperationsCallbackRetryInitDelay), "1s")
+	viper.SetDefault(string(OperationsCallbackRetryMaxDelay), "1m")
+	viper.SetDefault(string(OperationsCallbackRetryFactor), 2.0)
+	viper.SetDefault(string(OperationsOutboxBatchSize), 50)
+	viper.SetDefault(string(OperationsOutboxPollInterval), "1s")
+	viper.SetDefault(string(OperationsOutboxRetryMaxAttempts), 10)
+	viper.SetDefault(string(OperationsOutboxRetryInitDelay), "250ms")
+	viper.SetDefault(string(OperationsOutboxRetryMaxDelay), "1m")
+	viper.SetDefault(string(OperationsOutboxRetryFactor), 2.0)
 	viper.SetDefault(string(TransactionCacheSize), "1Mb")
 	viper.SetDefault(string(TransactionCacheTTL), "5m")
 	viper.SetDefault(string(UIEnabled), true)
 	viper.SetDefault(string(ValidatorCacheSize), "1Mb")
 	viper.SetDefault(string(ValidatorCacheTTL), "1h")
+	viper.SetDefault(string(ValidatorJSONAssertFormat), true)
+	viper.SetDefault(string(ValidatorJSONDefaultDraft), "2020-12")
 	viper.SetDefault(string(IdentityManagerCacheLimit), 100 /* items */)
 	viper.SetDefault(string(IdentityManagerCacheTTL), "1h")
 
@@ -555,8 +807,32 @@ func (c *configPrefixArray) ArraySize() int {
 	return 0
 }
 
+// lowerCaseEntryKeys is required because Viper lower cases the keys it looks up, and the keys of
+// nested maps it loads, but not the keys of maps that are inside an array
+func (c *configPrefixArray) lowerCaseEntryKeys() {
+	entries, ok := viper.Get(c.base).([]interface{})
+	if !ok {
+		return
+	}
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case map[interface{}]interface{}:
+			for k, v := range entry {
+				if ks, ok := k.(string); ok {
+					entry[strings.ToLower(ks)] = v
+				}
+			}
+		case map[string]interface{}:
+			for k, v := range entry {
+				entry[strings.ToLower(k)] = v
+			}
+		}
+	}
+}
+
 // ArrayEntry must only be called after the config has been loaded
 func (c *configPrefixArray) ArrayEntry(i int) Prefix {
+	c.lowerCaseEntryKeys()
 	cp := &configPrefix{
 		prefix: c.base + fmt.Sprintf(".%d.", i),
 	}
@@ -607,6 +883,11 @@ func GetConfig() fftypes.JSONObject {
 
 	conf := fftypes.JSONObject{}
 	_ = viper.Unmarshal(&conf)
+	if len(secretValues) > 0 {
+		for k, v := range conf {
+			conf[k] = redactSecrets(k, v)
+		}
+	}
 	return conf
 }
 
@@ -146,6 +146,28 @@ tokens:
 	assert.Equal(t, []string{"arr1", "arr2"}, sally.GetStringSlice("key2"))
 }
 
+func TestArrayOfPluginsMixedCaseKeys(t *testing.T) {
+	defer Reset()
+
+	tokPlugins := NewPluginConfig("tokens").Array()
+	tokPlugins.AddKnownKey("name")
+	tokPlugins.AddKnownKey("batchSize", 10)
+	for _, configType := range []string{"yaml", "json"} {
+		viper.SetConfigType(configType)
+		err := viper.ReadConfig(strings.NewReader(`{"tokens": [{"name": "bob", "batchSize": 20}, {"name": "sally", "batchSize": 30}]}`))
+		assert.NoError(t, err)
+		size := tokPlugins.ArraySize()
+		assert.Equal(t, 2, size)
+		bob := tokPlugins.ArrayEntry(0)
+		assert.Equal(t, "bob", bob.GetString("name"))
+		assert.Equal(t, 20, bob.GetInt("batchSize"))
+		sally := tokPlugins.ArrayEntry(1)
+		assert.Equal(t, "sally", sally.GetString("name"))
+		assert.Equal(t, 30, sally.GetInt("batchSize"))
+		Reset()
+	}
+}
+
 func TestMapOfAdminOverridePlugins(t *testing.T) {
 	defer Reset()
 
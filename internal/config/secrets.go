@@ -0,0 +1,144 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+const redactedSecret = "***"
+
+// SecretResolver returns the secret referenced by a config value, or false if the value is not a secret reference
+type SecretResolver func(ctx context.Context, key, value string) (secret string, isRef bool, err error)
+
+var secretValues = map[string]string{} // resolved secrets, by the full path of the config value they replaced
+
+// ResolveSecrets replaces every secret reference in the config with the secret it refers to,
+// remembering where each secret was placed so it can be redacted by GetConfig
+func ResolveSecrets(ctx context.Context, resolve SecretResolver) error {
+	keysMutex.Lock()
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = viper.Get(k)
+	}
+	resolved := make(map[string]string, len(secretValues))
+	for k, v := range secretValues {
+		resolved[k] = v
+	}
+	// Release the lock while resolving, as resolvers can read their own config
+	keysMutex.Unlock()
+
+	for i, k := range keys {
+		newValue, changed, err := resolveSecretValue(ctx, k, values[i], resolved, resolve)
+		if err != nil {
+			return err
+		}
+		if changed {
+			keysMutex.Lock()
+			viper.Set(k, newValue)
+			keysMutex.Unlock()
+		}
+	}
+
+	keysMutex.Lock()
+	secretValues = resolved
+	keysMutex.Unlock()
+	return nil
+}
+
+func resolveSecretValue(ctx context.Context, path string, value interface{}, resolved map[string]string, resolve SecretResolver) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		if secret, ok := resolved[path]; ok && secret == v {
+			// Already resolved on a previous pass
+			return v, false, nil
+		}
+		secret, isRef, err := resolve(ctx, path, v)
+		if err != nil || !isRef {
+			return v, false, err
+		}
+		resolved[path] = secret
+		return secret, true, nil
+	case []interface{}:
+		newValues := make([]interface{}, len(v))
+		changed := false
+		for i, e := range v {
+			newValue, c, err := resolveSecretValue(ctx, fmt.Sprintf("%s.%d", path, i), e, resolved, resolve)
+			if err != nil {
+				return v, false, err
+			}
+			newValues[i] = newValue
+			changed = changed || c
+		}
+		return newValues, changed, nil
+	case map[string]interface{}, map[interface{}]interface{}:
+		newValues := make(map[string]interface{})
+		changed := false
+		for k, e := range stringKeyed(v) {
+			newValue, c, err := resolveSecretValue(ctx, path+"."+k, e, resolved, resolve)
+			if err != nil {
+				return v, false, err
+			}
+			newValues[k] = newValue
+			changed = changed || c
+		}
+		return newValues, changed, nil
+	default:
+		return v, false, nil
+	}
+}
+
+// stringKeyed returns a map keyed by string, including the maps with interface{} keys that are parsed from YAML arrays
+func stringKeyed(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	m := make(map[string]interface{})
+	for k, e := range value.(map[interface{}]interface{}) {
+		m[fmt.Sprintf("%v", k)] = e
+	}
+	return m
+}
+
+// redactSecrets returns a copy of a config value, with any resolved secrets replaced
+func redactSecrets(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if _, ok := secretValues[path]; ok {
+			return redactedSecret
+		}
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, e := range v {
+			redacted[i] = redactSecrets(fmt.Sprintf("%s.%d", path, i), e)
+		}
+		return redacted
+	case map[string]interface{}, map[interface{}]interface{}:
+		redacted := make(map[string]interface{})
+		for k, e := range stringKeyed(v) {
+			redacted[k] = redactSecrets(path+"."+k, e)
+		}
+		return redacted
+	}
+	return value
+}
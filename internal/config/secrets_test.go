@@ -0,0 +1,127 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const secretsYAML = `
+database:
+  postgres:
+    url: ref:dburl
+tokens:
+- name: erc1155
+  fftokens:
+    url: http://tokens
+    auth:
+      password: ref:tokenpass
+`
+
+func testSecretResolver(calls *int) SecretResolver {
+	return func(ctx context.Context, key, value string) (string, bool, error) {
+		if !strings.HasPrefix(value, "ref:") {
+			return value, false, nil
+		}
+		*calls++
+		if value == "ref:bad" {
+			return "", true, fmt.Errorf("pop")
+		}
+		// Resolve to something that still looks like a reference, to check it is not resolved again
+		return "ref:resolved-" + strings.TrimPrefix(value, "ref:"), true, nil
+	}
+}
+
+func readSecretsYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cfgFile := path.Join(dir, "firefly.core.yaml")
+	err = ioutil.WriteFile(cfgFile, []byte(secretsYAML), 0664)
+	assert.NoError(t, err)
+	Reset()
+	err = ReadConfig(cfgFile)
+	assert.NoError(t, err)
+}
+
+func TestResolveSecretsAndRedact(t *testing.T) {
+	readSecretsYAML(t)
+
+	calls := 0
+	err := ResolveSecrets(context.Background(), testSecretResolver(&calls))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	assert.Equal(t, "ref:resolved-dburl", viper.GetString("database.postgres.url"))
+	tokens := GetObjectArray(RootKey("tokens"))
+	assert.Equal(t, "ref:resolved-tokenpass", tokens[0].GetObject("fftokens").GetObject("auth").GetString("password"))
+
+	conf := GetConfig()
+	assert.Equal(t, "***", conf.GetObject("database").GetObject("postgres").GetString("url"))
+	tokenConf := conf.GetObjectArray("tokens")[0]
+	assert.Equal(t, "erc1155", tokenConf.GetString("name"))
+	assert.Equal(t, "http://tokens", tokenConf.GetObject("fftokens").GetString("url"))
+	assert.Equal(t, "***", tokenConf.GetObject("fftokens").GetObject("auth").GetString("password"))
+
+	// A second pass does not resolve the secrets again
+	err = ResolveSecrets(context.Background(), testSecretResolver(&calls))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	Reset()
+	assert.Empty(t, secretValues)
+}
+
+func TestResolveSecretsFail(t *testing.T) {
+	Reset()
+	viper.Set("database.postgres.url", "ref:bad")
+	calls := 0
+	err := ResolveSecrets(context.Background(), testSecretResolver(&calls))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveSecretValueNestedFail(t *testing.T) {
+	calls := 0
+	_, _, err := resolveSecretValue(context.Background(), "key", []interface{}{"ref:bad"}, map[string]string{}, testSecretResolver(&calls))
+	assert.EqualError(t, err, "pop")
+	_, _, err = resolveSecretValue(context.Background(), "key", map[string]interface{}{"a": "ref:bad"}, map[string]string{}, testSecretResolver(&calls))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveSecretValueInterfaceKeyedMap(t *testing.T) {
+	calls := 0
+	resolved := map[string]string{}
+	v, changed, err := resolveSecretValue(context.Background(), "key", map[interface{}]interface{}{"a": "ref:a", 1: 2}, resolved, testSecretResolver(&calls))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]interface{}{"a": "ref:resolved-a", "1": 2}, v)
+	assert.Equal(t, "ref:resolved-a", resolved["key.a"])
+
+	secretValues = resolved
+	defer Reset()
+	redacted := redactSecrets("key", map[interface{}]interface{}{"a": "ref:resolved-a", "b": "c"})
+	assert.Equal(t, map[string]interface{}{"a": "***", "b": "c"}, redacted)
+}
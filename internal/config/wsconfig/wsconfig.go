@@ -41,6 +41,8 @@ const (
 	WSConfigKeyPath = "ws.path"
 	// WSConfigHeartbeatInterval is the frequency of ping/pong requests, and also used for the timeout to receive a response to the heartbeat
 	WSConfigHeartbeatInterval = "ws.heartbeatInterval"
+	// WSConfigHeartbeatTimeout is how long to wait for a pong after sending a ping, before the connection is considered dead (defaults to the heartbeat interval)
+	WSConfigHeartbeatTimeout = "ws.heartbeatTimeout"
 )
 
 // InitPrefix ensures the prefix is initialized for HTTP too, as WS and HTTP
@@ -52,6 +54,7 @@ func InitPrefix(prefix config.KeySet) {
 	prefix.AddKnownKey(WSConfigKeyInitialConnectAttempts, defaultIntialConnectAttempts)
 	prefix.AddKnownKey(WSConfigKeyPath)
 	prefix.AddKnownKey(WSConfigHeartbeatInterval, defaultHeartbeatInterval)
+	prefix.AddKnownKey(WSConfigHeartbeatTimeout)
 }
 
 func GenerateConfigFromPrefix(prefix config.Prefix) *wsclient.WSConfig {
@@ -67,5 +70,8 @@ func GenerateConfigFromPrefix(prefix config.Prefix) *wsclient.WSConfig {
 		AuthUsername:           prefix.GetString(restclient.HTTPConfigAuthUsername),
 		AuthPassword:           prefix.GetString(restclient.HTTPConfigAuthPassword),
 		HeartbeatInterval:      prefix.GetDuration(WSConfigHeartbeatInterval),
+		HeartbeatTimeout:       prefix.GetDuration(WSConfigHeartbeatTimeout),
+		FaultTarget:            restclient.SectionName(prefix),
+		AuthProvider:           restclient.NewCredentialProvider(prefix),
 	}
 }
@@ -31,6 +31,7 @@ func TestWSConfigGeneration(t *testing.T) {
 	utConfPrefix.Set(WSConfigKeyWriteBufferSize, 1024)
 	utConfPrefix.Set(WSConfigKeyInitialConnectAttempts, 1)
 	utConfPrefix.Set(WSConfigKeyPath, "/websocket")
+	utConfPrefix.Set(WSConfigHeartbeatTimeout, "5s")
 
 	wsConfig := GenerateConfigFromPrefix(utConfPrefix)
 
@@ -44,4 +45,18 @@ func TestWSConfigGeneration(t *testing.T) {
 	assert.Equal(t, "custom value", wsConfig.HTTPHeaders.GetString("custom-header"))
 	assert.Equal(t, 1024, wsConfig.ReadBufferSize)
 	assert.Equal(t, 1024, wsConfig.WriteBufferSize)
+	assert.Equal(t, 30*time.Second, wsConfig.HeartbeatInterval)
+	assert.Equal(t, 5*time.Second, wsConfig.HeartbeatTimeout)
+	assert.Equal(t, "ws", wsConfig.FaultTarget)
+	assert.Nil(t, wsConfig.AuthProvider)
+}
+
+func TestWSConfigGenerationAuthProvider(t *testing.T) {
+	resetConf()
+
+	utConfPrefix.Set(restclient.HTTPConfigURL, "http://test:12345")
+	utConfPrefix.Set(restclient.HTTPConfigAuthTokenFile, "/run/secrets/token")
+
+	wsConfig := GenerateConfigFromPrefix(utConfPrefix)
+	assert.NotNil(t, wsConfig.AuthProvider)
 }
@@ -0,0 +1,156 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// maxTransformDecimals matches the largest scale of a fixed-point number on an EVM chain
+const maxTransformDecimals = 77
+
+func (cm *contractManager) validateContractAPITransforms(ctx context.Context, ns string, api *fftypes.ContractAPI) error {
+	for methodPath, transforms := range api.Transforms {
+		method, err := cm.database.GetFFIMethod(ctx, ns, api.Interface.ID, methodPath)
+		if err != nil {
+			return err
+		} else if method == nil {
+			return i18n.NewError(ctx, i18n.MsgContractTransformUnknownMeth, methodPath)
+		}
+		for field, t := range transforms {
+			if err := validateInputTransform(ctx, methodPath, field, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateInputTransform(ctx context.Context, methodPath, field string, t *fftypes.ContractInputTransform) error {
+	if t == nil {
+		return i18n.NewError(ctx, i18n.MsgContractTransformInvalid, field, methodPath, "no transform specified")
+	}
+	switch t.Type {
+	case fftypes.ContractInputTransformTypeDecimal:
+		if t.Decimals < 0 || t.Decimals > maxTransformDecimals {
+			return i18n.NewError(ctx, i18n.MsgContractTransformInvalid, field, methodPath, fmt.Sprintf("decimals must be between 0 and %d", maxTransformDecimals))
+		}
+	case fftypes.ContractInputTransformTypeTimestamp:
+	case fftypes.ContractInputTransformTypeTemplate:
+		if t.Template == "" {
+			return i18n.NewError(ctx, i18n.MsgContractTransformInvalid, field, methodPath, "template must be set")
+		}
+		if _, err := template.New(field).Parse(t.Template); err != nil {
+			return i18n.NewError(ctx, i18n.MsgContractTransformInvalid, field, methodPath, err)
+		}
+	default:
+		return i18n.NewError(ctx, i18n.MsgContractTransformInvalid, field, methodPath, fmt.Sprintf("unknown type '%s'", t.Type))
+	}
+	return nil
+}
+
+// applyInputTransforms rewrites the input of a contract API request, using the transforms configured
+// on the API for the method. Every transform sees the input exactly as it was supplied by the caller.
+func applyInputTransforms(ctx context.Context, methodPath string, transforms map[string]*fftypes.ContractInputTransform, req *fftypes.ContractCallRequest) error {
+	if len(transforms) == 0 {
+		return nil
+	}
+	original := make(map[string]interface{}, len(req.Input))
+	for k, v := range req.Input {
+		original[k] = v
+	}
+	if req.Input == nil {
+		req.Input = make(map[string]interface{})
+	}
+	for field, t := range transforms {
+		if err := validateInputTransform(ctx, methodPath, field, t); err != nil {
+			return err
+		}
+		value, set, err := transformInput(field, t, original)
+		if err != nil {
+			return i18n.NewError(ctx, i18n.MsgContractTransformFailed, field, err)
+		}
+		if set {
+			req.Input[field] = value
+		}
+	}
+	return nil
+}
+
+func transformInput(field string, t *fftypes.ContractInputTransform, input map[string]interface{}) (interface{}, bool, error) {
+	if t.Type == fftypes.ContractInputTransformTypeTemplate {
+		tmpl := template.Must(template.New(field).Option("missingkey=error").Parse(t.Template))
+		var buff strings.Builder
+		if err := tmpl.Execute(&buff, input); err != nil {
+			return nil, false, err
+		}
+		return buff.String(), true, nil
+	}
+
+	v, ok := input[field]
+	if !ok {
+		// Leave it to the FFI validation to report missing inputs
+		return nil, false, nil
+	}
+	if t.Type == fftypes.ContractInputTransformTypeDecimal {
+		res, err := shiftDecimal(v, t.Decimals)
+		return res, err == nil, err
+	}
+	// Numbers are passed through, as they are already unix seconds
+	if s, ok := v.(string); ok {
+		ts, err := fftypes.ParseTimeString(s)
+		if err != nil {
+			return nil, false, err
+		}
+		return ts.Time().Unix(), true, nil
+	}
+	return v, true, nil
+}
+
+// shiftDecimal converts a decimal amount to the integer string representation of
+// that amount multiplied by 10^decimals, without any loss of precision
+func shiftDecimal(v interface{}, decimals int) (string, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return "", fmt.Errorf("is not a number")
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || strings.Contains(s, "/") {
+		return "", fmt.Errorf("'%s' is not a valid decimal number", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !r.IsInt() {
+		return "", fmt.Errorf("'%s' has more than %d decimal places", s, decimals)
+	}
+	return r.Num().String(), nil
+}
@@ -0,0 +1,244 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestTransformsAPI(transforms fftypes.ContractAPITransforms) *fftypes.ContractAPI {
+	return &fftypes.ContractAPI{
+		Namespace: "ns1",
+		Name:      "banana",
+		Interface: &fftypes.FFIReference{
+			ID: fftypes.NewUUID(),
+		},
+		Transforms: transforms,
+	}
+}
+
+func TestBroadcastContractAPITransforms(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+	mbm := cm.broadcast.(*broadcastmocks.Manager)
+
+	api := newTestTransformsAPI(fftypes.ContractAPITransforms{
+		"transfer": {
+			"amount": {Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 18},
+			"expiry": {Type: fftypes.ContractInputTransformTypeTimestamp},
+			"memo":   {Type: fftypes.ContractInputTransformTypeTemplate, Template: "{{.from}}->{{.to}}"},
+		},
+	})
+	msg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(nil, nil)
+	mdb.On("GetFFIByID", mock.Anything, api.Interface.ID).Return(&fftypes.FFI{}, nil)
+	mdb.On("GetFFIMethod", mock.Anything, "ns1", api.Interface.ID, "transfer").Return(&fftypes.FFIMethod{Name: "transfer"}, nil)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, "ns1", api, fftypes.SystemTagDefineContractAPI, false).Return(msg, nil)
+
+	_, err := cm.BroadcastContractAPI(context.Background(), "http://localhost/api", "ns1", api, false)
+	assert.NoError(t, err)
+
+	mdb.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPITransformsUnknownMethod(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+
+	api := newTestTransformsAPI(fftypes.ContractAPITransforms{
+		"missing": {
+			"amount": {Type: fftypes.ContractInputTransformTypeDecimal},
+		},
+	})
+	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(nil, nil)
+	mdb.On("GetFFIByID", mock.Anything, api.Interface.ID).Return(&fftypes.FFI{}, nil)
+	mdb.On("GetFFIMethod", mock.Anything, "ns1", api.Interface.ID, "missing").Return(nil, nil)
+
+	_, err := cm.BroadcastContractAPI(context.Background(), "http://localhost/api", "ns1", api, false)
+	assert.Regexp(t, "FF10511.*missing", err)
+
+	mdb.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPITransformsMethodFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+
+	api := newTestTransformsAPI(fftypes.ContractAPITransforms{
+		"transfer": {
+			"amount": {Type: fftypes.ContractInputTransformTypeDecimal},
+		},
+	})
+	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(nil, nil)
+	mdb.On("GetFFIByID", mock.Anything, api.Interface.ID).Return(&fftypes.FFI{}, nil)
+	mdb.On("GetFFIMethod", mock.Anything, "ns1", api.Interface.ID, "transfer").Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.BroadcastContractAPI(context.Background(), "http://localhost/api", "ns1", api, false)
+	assert.EqualError(t, err, "pop")
+
+	mdb.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPITransformsInvalid(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+
+	api := newTestTransformsAPI(fftypes.ContractAPITransforms{
+		"transfer": {
+			"amount": {Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 78},
+		},
+	})
+	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(nil, nil)
+	mdb.On("GetFFIByID", mock.Anything, api.Interface.ID).Return(&fftypes.FFI{}, nil)
+	mdb.On("GetFFIMethod", mock.Anything, "ns1", api.Interface.ID, "transfer").Return(&fftypes.FFIMethod{Name: "transfer"}, nil)
+
+	_, err := cm.BroadcastContractAPI(context.Background(), "http://localhost/api", "ns1", api, false)
+	assert.Regexp(t, "FF10510.*amount.*transfer.*decimals", err)
+
+	mdb.AssertExpectations(t)
+}
+
+func TestValidateInputTransformErrors(t *testing.T) {
+	ctx := context.Background()
+	assert.Regexp(t, "FF10510.*no transform", validateInputTransform(ctx, "m", "f", nil))
+	assert.Regexp(t, "FF10510.*decimals", validateInputTransform(ctx, "m", "f", &fftypes.ContractInputTransform{
+		Type: fftypes.ContractInputTransformTypeDecimal, Decimals: -1,
+	}))
+	assert.Regexp(t, "FF10510.*template must be set", validateInputTransform(ctx, "m", "f", &fftypes.ContractInputTransform{
+		Type: fftypes.ContractInputTransformTypeTemplate,
+	}))
+	assert.Regexp(t, "FF10510.*unclosed action", validateInputTransform(ctx, "m", "f", &fftypes.ContractInputTransform{
+		Type: fftypes.ContractInputTransformTypeTemplate, Template: "{{.a",
+	}))
+	assert.Regexp(t, "FF10510.*unknown type 'wrong'", validateInputTransform(ctx, "m", "f", &fftypes.ContractInputTransform{
+		Type: "wrong",
+	}))
+}
+
+func TestApplyInputTransforms(t *testing.T) {
+	req := &fftypes.ContractCallRequest{
+		Input: map[string]interface{}{
+			"amount":   "1.5",
+			"fee":      0.25,
+			"rebate":   json.Number("-3"),
+			"expiry":   "2022-05-01T00:00:00Z",
+			"deadline": float64(1651363200),
+			"from":     "alice",
+			"to":       "bob",
+		},
+	}
+	err := applyInputTransforms(context.Background(), "transfer", map[string]*fftypes.ContractInputTransform{
+		"amount":   {Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 18},
+		"fee":      {Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 2},
+		"rebate":   {Type: fftypes.ContractInputTransformTypeDecimal},
+		"missing":  {Type: fftypes.ContractInputTransformTypeDecimal},
+		"expiry":   {Type: fftypes.ContractInputTransformTypeTimestamp},
+		"deadline": {Type: fftypes.ContractInputTransformTypeTimestamp},
+		"memo":     {Type: fftypes.ContractInputTransformTypeTemplate, Template: "{{.from}}->{{.to}}"},
+		"from":     {Type: fftypes.ContractInputTransformTypeTemplate, Template: "0x{{.from}}"},
+	}, req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"amount":   "1500000000000000000",
+		"fee":      "25",
+		"rebate":   "-3",
+		"expiry":   int64(1651363200),
+		"deadline": float64(1651363200),
+		"from":     "0xalice",
+		"to":       "bob",
+		"memo":     "alice->bob",
+	}, req.Input)
+}
+
+func TestApplyInputTransformsNone(t *testing.T) {
+	req := &fftypes.ContractCallRequest{}
+	err := applyInputTransforms(context.Background(), "transfer", nil, req)
+	assert.NoError(t, err)
+	assert.Nil(t, req.Input)
+}
+
+func TestApplyInputTransformsNilInput(t *testing.T) {
+	req := &fftypes.ContractCallRequest{}
+	err := applyInputTransforms(context.Background(), "transfer", map[string]*fftypes.ContractInputTransform{
+		"memo": {Type: fftypes.ContractInputTransformTypeTemplate, Template: "fixed"},
+	}, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "fixed", req.Input["memo"])
+}
+
+func TestApplyInputTransformsInvalid(t *testing.T) {
+	req := &fftypes.ContractCallRequest{}
+	err := applyInputTransforms(context.Background(), "transfer", map[string]*fftypes.ContractInputTransform{
+		"memo": {Type: fftypes.ContractInputTransformTypeTemplate},
+	}, req)
+	assert.Regexp(t, "FF10510", err)
+}
+
+func TestApplyInputTransformsFail(t *testing.T) {
+	cases := []struct {
+		value     interface{}
+		transform *fftypes.ContractInputTransform
+		err       string
+	}{
+		{"1.234", &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 2}, "more than 2 decimal places"},
+		{"abc", &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeDecimal}, "'abc' is not a valid decimal number"},
+		{"1/3", &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeDecimal}, "'1/3' is not a valid decimal number"},
+		{true, &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeDecimal}, "is not a number"},
+		{"tomorrow", &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeTimestamp}, "FF10165"},
+		{"x", &fftypes.ContractInputTransform{Type: fftypes.ContractInputTransformTypeTemplate, Template: "{{.other}}"}, "other"},
+	}
+	for _, c := range cases {
+		req := &fftypes.ContractCallRequest{
+			Input: map[string]interface{}{"value": c.value},
+		}
+		err := applyInputTransforms(context.Background(), "m", map[string]*fftypes.ContractInputTransform{
+			"value": c.transform,
+		}, req)
+		assert.Regexp(t, "FF10512.*value.*"+c.err, err)
+	}
+}
+
+func TestInvokeContractAPITransformFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+
+	api := newTestTransformsAPI(fftypes.ContractAPITransforms{
+		"transfer": {
+			"amount": {Type: fftypes.ContractInputTransformTypeDecimal},
+		},
+	})
+	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(api, nil)
+
+	req := &fftypes.ContractCallRequest{
+		Type:  fftypes.CallTypeInvoke,
+		Input: map[string]interface{}{"amount": "1.5"},
+	}
+	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "banana", "transfer", req)
+	assert.Regexp(t, "FF10512.*amount", err)
+
+	mdb.AssertExpectations(t)
+}
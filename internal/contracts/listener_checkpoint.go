@@ -0,0 +1,101 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// checkpointBlock extracts the block number from a blockchain event protocol ID, which the blockchain
+// plugins format as block/transaction/log
+func checkpointBlock(ctx context.Context, protocolID string) (string, error) {
+	block := strings.SplitN(protocolID, "/", 2)[0]
+	blockNumber, err := strconv.ParseUint(block, 10, 64)
+	if err != nil {
+		return "", i18n.NewError(ctx, i18n.MsgInvalidListenerCheckpoint, "protocolId '"+protocolID+"' does not start with a block number")
+	}
+	return strconv.FormatUint(blockNumber, 10), nil
+}
+
+// GetContractListenerCheckpoint returns the position the listener has reached, from the last blockchain event it
+// recorded. A listener that has not yet recorded any events reports the position it was created with.
+func (cm *contractManager) GetContractListenerCheckpoint(ctx context.Context, ns, nameOrID string) (*fftypes.ContractListenerCheckpoint, error) {
+	listener, err := cm.GetContractListenerByNameOrID(ctx, ns, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	return cm.listenerCheckpoint(ctx, listener)
+}
+
+func (cm *contractManager) listenerCheckpoint(ctx context.Context, listener *fftypes.ContractListener) (*fftypes.ContractListenerCheckpoint, error) {
+	checkpoint := &fftypes.ContractListenerCheckpoint{
+		Listener: listener.ID,
+	}
+	if listener.Options != nil {
+		checkpoint.FirstEvent = listener.Options.FirstEvent
+		if listener.Options.Checkpoint != nil {
+			checkpoint.ProtocolID = listener.Options.Checkpoint.ProtocolID
+		}
+	}
+
+	fb := database.BlockchainEventQueryFactory.NewFilterLimit(ctx, 1)
+	filter := fb.And(
+		fb.Eq("namespace", listener.Namespace),
+		fb.Eq("listener", listener.ID),
+	).Sort("protocolid").Descending()
+	events, _, err := cm.database.GetBlockchainEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		checkpoint.ProtocolID = events[0].ProtocolID
+		if checkpoint.FirstEvent, err = checkpointBlock(ctx, checkpoint.ProtocolID); err != nil {
+			return nil, err
+		}
+	}
+	return checkpoint, nil
+}
+
+// applyListenerCheckpoint starts a new listener from an imported checkpoint. The listener starts from the block of
+// the checkpoint, as the connector cannot start part way through a block, and the events up to and including the
+// checkpoint are ignored as they arrive.
+func applyListenerCheckpoint(ctx context.Context, options *fftypes.ContractListenerOptions) error {
+	checkpoint := options.Checkpoint
+	if checkpoint.FirstEvent == "" {
+		return i18n.NewError(ctx, i18n.MsgInvalidListenerCheckpoint, "firstEvent must be set")
+	}
+	if options.FirstEvent != "" && options.FirstEvent != checkpoint.FirstEvent {
+		return i18n.NewError(ctx, i18n.MsgInvalidListenerCheckpoint, "cannot be combined with a different firstEvent")
+	}
+	if checkpoint.ProtocolID != "" {
+		block, err := checkpointBlock(ctx, checkpoint.ProtocolID)
+		if err != nil {
+			return err
+		}
+		if block != checkpoint.FirstEvent {
+			return i18n.NewError(ctx, i18n.MsgInvalidListenerCheckpoint, "firstEvent must be the block of protocolId")
+		}
+	}
+	options.FirstEvent = checkpoint.FirstEvent
+	return nil
+}
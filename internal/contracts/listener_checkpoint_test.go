@@ -0,0 +1,212 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newCheckpointListenerInput(options *fftypes.ContractListenerOptions) *fftypes.ContractListenerInput {
+	return &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "changed",
+				},
+			},
+			Options: options,
+		},
+	}
+}
+
+func TestGetContractListenerCheckpoint(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{
+			FirstEvent: "newest",
+		},
+	}
+	mdi.On("GetContractListenerByID", context.Background(), listener.ID).Return(listener, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{
+		{ProtocolID: "000000000123/000004/000005"},
+	}, nil, nil)
+
+	checkpoint, err := cm.GetContractListenerCheckpoint(context.Background(), "ns", listener.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, listener.ID, checkpoint.Listener)
+	assert.Equal(t, "123", checkpoint.FirstEvent)
+	assert.Equal(t, "000000000123/000004/000005", checkpoint.ProtocolID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerCheckpointNoEvents(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{
+			FirstEvent: "100",
+			Checkpoint: &fftypes.ContractListenerCheckpoint{
+				FirstEvent: "100",
+				ProtocolID: "000000000100/000001/000000",
+			},
+		},
+	}
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(listener, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+
+	checkpoint, err := cm.GetContractListenerCheckpoint(context.Background(), "ns", "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", checkpoint.FirstEvent)
+	assert.Equal(t, "000000000100/000001/000000", checkpoint.ProtocolID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerCheckpointNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(nil, nil)
+
+	_, err := cm.GetContractListenerCheckpoint(context.Background(), "ns", "sub1")
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerCheckpointEventsFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(&fftypes.ContractListener{ID: fftypes.NewUUID()}, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.GetContractListenerCheckpoint(context.Background(), "ns", "sub1")
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerCheckpointBadProtocolID(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(&fftypes.ContractListener{ID: fftypes.NewUUID()}, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{
+		{ProtocolID: "tx1"},
+	}, nil, nil)
+
+	_, err := cm.GetContractListenerCheckpoint(context.Background(), "ns", "sub1")
+	assert.Regexp(t, "FF10516.*tx1", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFromCheckpoint(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := newCheckpointListenerInput(&fftypes.ContractListenerOptions{
+		Checkpoint: &fftypes.ContractListenerCheckpoint{
+			FirstEvent: "123",
+			ProtocolID: "000000000123/000004/000005",
+		},
+	})
+
+	mbi.On("AddContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", result.Options.FirstEvent)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerCheckpointMissingFirstEvent(t *testing.T) {
+	cm := newTestContractManager()
+
+	sub := newCheckpointListenerInput(&fftypes.ContractListenerOptions{
+		Checkpoint: &fftypes.ContractListenerCheckpoint{
+			ProtocolID: "000000000123/000004/000005",
+		},
+	})
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10516.*firstEvent", err)
+}
+
+func TestAddContractListenerCheckpointConflictingFirstEvent(t *testing.T) {
+	cm := newTestContractManager()
+
+	sub := newCheckpointListenerInput(&fftypes.ContractListenerOptions{
+		FirstEvent: "oldest",
+		Checkpoint: &fftypes.ContractListenerCheckpoint{
+			FirstEvent: "123",
+		},
+	})
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10516.*firstEvent", err)
+}
+
+func TestAddContractListenerCheckpointBlockMismatch(t *testing.T) {
+	cm := newTestContractManager()
+
+	sub := newCheckpointListenerInput(&fftypes.ContractListenerOptions{
+		Checkpoint: &fftypes.ContractListenerCheckpoint{
+			FirstEvent: "100",
+			ProtocolID: "000000000123/000004/000005",
+		},
+	})
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10516.*protocolId", err)
+}
+
+func TestAddContractListenerCheckpointBadProtocolID(t *testing.T) {
+	cm := newTestContractManager()
+
+	sub := newCheckpointListenerInput(&fftypes.ContractListenerOptions{
+		Checkpoint: &fftypes.ContractListenerCheckpoint{
+			FirstEvent: "100",
+			ProtocolID: "bad",
+		},
+	})
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10516.*bad", err)
+}
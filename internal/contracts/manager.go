@@ -20,15 +20,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/karlseguin/ccache"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -43,6 +47,7 @@ type Manager interface {
 
 	InvokeContract(ctx context.Context, ns string, req *fftypes.ContractCallRequest) (interface{}, error)
 	InvokeContractAPI(ctx context.Context, ns, apiName, methodPath string, req *fftypes.ContractCallRequest) (interface{}, error)
+	DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.Operation, error)
 	GetContractAPI(ctx context.Context, httpServerURL, ns, apiName string) (*fftypes.ContractAPI, error)
 	GetContractAPIs(ctx context.Context, httpServerURL, ns string, filter database.AndFilter) ([]*fftypes.ContractAPI, *database.FilterResult, error)
 	BroadcastContractAPI(ctx context.Context, httpServerURL, ns string, api *fftypes.ContractAPI, waitConfirm bool) (output *fftypes.ContractAPI, err error)
@@ -51,10 +56,15 @@ type Manager interface {
 
 	AddContractListener(ctx context.Context, ns string, listener *fftypes.ContractListenerInput) (output *fftypes.ContractListener, err error)
 	GetContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (*fftypes.ContractListener, error)
+	GetContractListenerCheckpoint(ctx context.Context, ns, nameOrID string) (*fftypes.ContractListenerCheckpoint, error)
 	GetContractListeners(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ContractListener, *database.FilterResult, error)
 	DeleteContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) error
+	UpdateContractListenerByNameOrID(ctx context.Context, ns, nameOrID string, update *fftypes.ContractListenerUpdateDTO) (*fftypes.ContractListener, error)
 	GenerateFFI(ctx context.Context, ns string, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error)
 
+	// InvalidateQueryCache discards cached query results for a contract location, when an event is received from it
+	InvalidateQueryCache(ns string, location *fftypes.JSONAny)
+
 	// From operations.OperationHandler
 	PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error)
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (outputs fftypes.JSONObject, complete bool, err error)
@@ -68,6 +78,10 @@ type contractManager struct {
 	blockchain        blockchain.Plugin
 	ffiParamValidator fftypes.FFIParamValidator
 	operations        operations.Manager
+	queryCache        *ccache.LayeredCache
+	queryCacheTTL     time.Duration
+	errorCache        *ccache.Cache
+	errorCacheTTL     time.Duration
 }
 
 func NewContractManager(ctx context.Context, di database.Plugin, bm broadcast.Manager, im identity.Manager, bi blockchain.Plugin, om operations.Manager, txHelper txcommon.Helper) (Manager, error) {
@@ -87,10 +101,15 @@ func NewContractManager(ctx context.Context, di database.Plugin, bm broadcast.Ma
 		blockchain:        bi,
 		ffiParamValidator: v,
 		operations:        om,
+		queryCache:        ccache.Layered(ccache.Configure().MaxSize(config.GetInt64(config.ContractsQueryCacheSize))),
+		queryCacheTTL:     config.GetDuration(config.ContractsQueryCacheTTL),
+		errorCache:        ccache.New(ccache.Configure().MaxSize(config.GetInt64(config.ContractsErrorCacheSize))),
+		errorCacheTTL:     config.GetDuration(config.ContractsErrorCacheTTL),
 	}
 
 	om.RegisterHandler(ctx, cm, []fftypes.OpType{
 		fftypes.OpTypeBlockchainInvoke,
+		fftypes.OpTypeBlockchainContractDeploy,
 	})
 
 	return cm, nil
@@ -123,6 +142,9 @@ func (cm *contractManager) BroadcastFFI(ctx context.Context, ns string, ffi *fft
 	for _, event := range ffi.Events {
 		event.ID = fftypes.NewUUID()
 	}
+	for _, errorDef := range ffi.Errors {
+		errorDef.ID = fftypes.NewUUID()
+	}
 	if err := cm.ValidateFFIAndSetPathnames(ctx, ffi); err != nil {
 		return nil, err
 	}
@@ -166,6 +188,12 @@ func (cm *contractManager) GetFFIByIDWithChildren(ctx context.Context, id *fftyp
 		if err != nil {
 			return err
 		}
+
+		erfb := database.FFIErrorQueryFactory.NewFilter(ctx)
+		ffi.Errors, _, err = cm.database.GetFFIErrors(ctx, erfb.Eq("interface", id))
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 	return ffi, err
@@ -187,6 +215,8 @@ func (cm *contractManager) writeInvokeTransaction(ctx context.Context, ns string
 		ns,
 		txid,
 		fftypes.OpTypeBlockchainInvoke)
+	op.CallbackURL = req.CallbackURL
+	op.BusinessKey = req.BusinessKey
 	if err = addBlockchainInvokeInputs(op, req); err == nil {
 		err = cm.database.InsertOperation(ctx, op)
 	}
@@ -208,10 +238,16 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *f
 			return err
 		}
 		if req.Type == fftypes.CallTypeInvoke {
+			// The errors are stored on the operation, so the failure can be decoded whenever the receipt arrives
+			if req.Errors, err = cm.resolveInvokeErrors(ctx, req); err != nil {
+				return err
+			}
 			op, err = cm.writeInvokeTransaction(ctx, ns, req)
 			if err != nil {
 				return err
 			}
+			// The invocation is submitted to the blockchain by the outbox, once the operation is committed
+			return cm.operations.QueueOperation(ctx, op)
 		}
 		return nil
 	})
@@ -221,8 +257,7 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *f
 
 	switch req.Type {
 	case fftypes.CallTypeInvoke:
-		res = &fftypes.ContractCallResponse{ID: op.ID}
-		return res, cm.operations.RunOperation(ctx, opBlockchainInvoke(op, req))
+		return &fftypes.ContractCallResponse{ID: op.ID}, nil
 	case fftypes.CallTypeQuery:
 		return cm.blockchain.QueryContract(ctx, req.Location, req.Method, req.Input)
 	default:
@@ -244,9 +279,79 @@ func (cm *contractManager) InvokeContractAPI(ctx context.Context, ns, apiName, m
 	if api.Location != nil {
 		req.Location = api.Location
 	}
+	if err := applyInputTransforms(ctx, methodPath, api.Transforms[methodPath], req); err != nil {
+		return nil, err
+	}
+	if req.Type == fftypes.CallTypeQuery && api.QueryCache != nil && api.QueryCache.Enabled {
+		return cm.queryContractAPICached(ctx, ns, api, methodPath, req)
+	}
 	return cm.InvokeContract(ctx, ns, req)
 }
 
+func (cm *contractManager) DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (op *fftypes.Operation, err error) {
+	if req.Contract == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgContractDeployNoContract)
+	}
+	req.Key, err = cm.identity.NormalizeSigningKey(ctx, req.Key, identity.KeyNormalizationBlockchainPlugin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		if _, err = cm.resolveDeployConstructor(ctx, ns, req); err != nil {
+			return err
+		}
+		txid, err := cm.txHelper.SubmitNewTransaction(ctx, ns, fftypes.TransactionTypeContractDeploy)
+		if err != nil {
+			return err
+		}
+		op = fftypes.NewOperation(
+			cm.blockchain,
+			ns,
+			txid,
+			fftypes.OpTypeBlockchainContractDeploy)
+		if err = addBlockchainDeployInputs(op, req); err == nil {
+			err = cm.database.InsertOperation(ctx, op)
+		}
+		if err != nil {
+			return err
+		}
+		// The deployment is submitted to the blockchain by the outbox, once the operation is committed
+		return cm.operations.QueueOperation(ctx, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// resolveDeployConstructor returns the constructor from the referenced interface, or nil if the request
+// includes a blockchain specific definition of the contract
+func (cm *contractManager) resolveDeployConstructor(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.FFIMethod, error) {
+	if req.Definition != nil {
+		return nil, nil
+	}
+	if req.Interface == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgContractDeployNoDefinition)
+	}
+	ffi, err := cm.database.GetFFIByID(ctx, req.Interface)
+	if err != nil {
+		return nil, err
+	} else if ffi == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgContractInterfaceNotFound, req.Interface)
+	}
+	constructor, err := cm.database.GetFFIMethod(ctx, ns, req.Interface, "constructor")
+	if err != nil || constructor != nil {
+		return constructor, err
+	}
+	// An interface without a constructor describes a contract that is deployed without arguments
+	return &fftypes.FFIMethod{
+		Name:    "constructor",
+		Params:  fftypes.FFIParams{},
+		Returns: fftypes.FFIParams{},
+	}, nil
+}
+
 func (cm *contractManager) resolveInvokeContractRequest(ctx context.Context, ns string, req *fftypes.ContractCallRequest) (method *fftypes.FFIMethod, err error) {
 	if req.Method == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgContractMethodNotSet)
@@ -270,6 +375,53 @@ func (cm *contractManager) resolveInvokeContractRequest(ctx context.Context, ns
 	return method, nil
 }
 
+// getFFIErrors returns the custom errors declared by an interface. An interface cannot change once it is
+// defined, so the errors are cached by interface ID - but only once the interface itself has been confirmed
+func (cm *contractManager) getFFIErrors(ctx context.Context, interfaceID *fftypes.UUID) ([]*fftypes.FFIErrorDefinition, error) {
+	if cached := cm.errorCache.Get(interfaceID.String()); cached != nil {
+		cached.Extend(cm.errorCacheTTL)
+		return cached.Value().([]*fftypes.FFIErrorDefinition), nil
+	}
+	ffi, err := cm.database.GetFFIByID(ctx, interfaceID)
+	if err != nil || ffi == nil {
+		return nil, err
+	}
+	fb := database.FFIErrorQueryFactory.NewFilter(ctx)
+	ffiErrors, _, err := cm.database.GetFFIErrors(ctx, fb.Eq("interface", interfaceID))
+	if err != nil {
+		return nil, err
+	}
+	errorDefs := make([]*fftypes.FFIErrorDefinition, len(ffiErrors))
+	for i, e := range ffiErrors {
+		errorDefs[i] = &e.FFIErrorDefinition
+	}
+	cm.errorCache.Set(interfaceID.String(), errorDefs, cm.errorCacheTTL)
+	return errorDefs, nil
+}
+
+// resolveInvokeErrors combines the errors supplied on the request with those declared by the interface it
+// invokes, with the request taking precedence for an error of the same name
+func (cm *contractManager) resolveInvokeErrors(ctx context.Context, req *fftypes.ContractCallRequest) ([]*fftypes.FFIErrorDefinition, error) {
+	if req.Interface == nil {
+		return req.Errors, nil
+	}
+	ffiErrors, err := cm.getFFIErrors(ctx, req.Interface)
+	if err != nil {
+		return nil, err
+	}
+	errors := req.Errors
+	supplied := make(map[string]bool, len(req.Errors))
+	for _, e := range req.Errors {
+		supplied[e.Name] = true
+	}
+	for _, e := range ffiErrors {
+		if !supplied[e.Name] {
+			errors = append(errors, e)
+		}
+	}
+	return errors, nil
+}
+
 func (cm *contractManager) addContractURLs(httpServerURL string, api *fftypes.ContractAPI) {
 	if api != nil {
 		// These URLs must match the actual routes in apiserver.createMuxRouter()!
@@ -338,7 +490,7 @@ func (cm *contractManager) BroadcastContractAPI(ctx context.Context, httpServerU
 		if err := cm.resolveFFIReference(ctx, ns, api.Interface); err != nil {
 			return err
 		}
-		return nil
+		return cm.validateContractAPITransforms(ctx, ns, api)
 	})
 	if err != nil {
 		return nil, err
@@ -389,6 +541,16 @@ func (cm *contractManager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *
 			return err
 		}
 	}
+
+	errorPathNames := map[string]bool{}
+	for _, errorDef := range ffi.Errors {
+		errorDef.Contract = ffi.ID
+		errorDef.Namespace = ffi.Namespace
+		errorDef.Pathname = cm.uniquePathName(errorDef.Name, errorPathNames)
+		if err := cm.validateFFIError(ctx, &errorDef.FFIErrorDefinition); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -432,10 +594,33 @@ func (cm *contractManager) validateFFIEvent(ctx context.Context, event *fftypes.
 	return nil
 }
 
+func (cm *contractManager) validateFFIError(ctx context.Context, errorDef *fftypes.FFIErrorDefinition) error {
+	if errorDef.Name == "" {
+		return i18n.NewError(ctx, i18n.MsgErrorNameMustBeSet)
+	}
+	for _, param := range errorDef.Params {
+		if err := cm.validateFFIParam(ctx, param); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, req *fftypes.ContractCallRequest) error {
 	if err := cm.validateFFIMethod(ctx, req.Method); err != nil {
 		return err
 	}
+	if err := operations.ValidateCallbackURL(ctx, req.CallbackURL); err != nil {
+		return err
+	}
+	if err := fftypes.ValidateBusinessKey(ctx, req.BusinessKey); err != nil {
+		return err
+	}
+	for _, errorDef := range req.Errors {
+		if err := cm.validateFFIError(ctx, errorDef); err != nil {
+			return err
+		}
+	}
 
 	for _, param := range req.Method.Params {
 		value, ok := req.Input[param.Name]
@@ -460,9 +645,16 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 
 	if listener.Options == nil {
 		listener.Options = cm.getDefaultContractListenerOptions()
+	} else if listener.Options.Checkpoint != nil {
+		if err := applyListenerCheckpoint(ctx, listener.Options); err != nil {
+			return nil, err
+		}
 	} else if listener.Options.FirstEvent == "" {
 		listener.Options.FirstEvent = cm.getDefaultContractListenerOptions().FirstEvent
 	}
+	if listener.Options.Confirmations > 0 && !cm.blockchain.Capabilities().ConfirmationDepth {
+		return nil, i18n.NewError(ctx, i18n.MsgConfirmationsNotSupported, cm.blockchain.Name())
+	}
 
 	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
 		if listener.Name != "" {
@@ -525,7 +717,12 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 	if listener.Name == "" {
 		listener.Name = listener.ProtocolID
 	}
+	listener.State = fftypes.ContractListenerStateActive
 	if err = cm.database.UpsertContractListener(ctx, &listener.ContractListener); err != nil {
+		// Remove the subscription from the connector, so it is not left behind without a listener
+		if delErr := cm.blockchain.DeleteContractListener(ctx, &listener.ContractListener); delErr != nil {
+			log.L(ctx).Errorf("Failed to remove the subscription of contract listener %s: %s", listener.ID, delErr)
+		}
 		return nil, err
 	}
 
@@ -560,16 +757,70 @@ func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context,
 		if err != nil {
 			return err
 		}
-		if err = cm.blockchain.DeleteContractListener(ctx, listener); err != nil {
-			return err
+		// A paused listener has no subscription in the connector
+		if listener.State != fftypes.ContractListenerStatePaused {
+			if err = cm.blockchain.DeleteContractListener(ctx, listener); err != nil {
+				return err
+			}
 		}
 		return cm.database.DeleteContractListenerByID(ctx, listener.ID)
 	})
 }
 
+// UpdateContractListenerByNameOrID pauses or resumes a contract listener. The blockchain plugin removes the
+// underlying subscription while paused, and recreates it from the checkpoint of the listener when it is resumed.
+// Events the connector redelivers up to and including the checkpoint are ignored, so none are duplicated.
+func (cm *contractManager) UpdateContractListenerByNameOrID(ctx context.Context, ns, nameOrID string, update *fftypes.ContractListenerUpdateDTO) (listener *fftypes.ContractListener, err error) {
+	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		listener, err = cm.GetContractListenerByNameOrID(ctx, ns, nameOrID)
+		if err != nil {
+			return err
+		}
+		current := listener.State
+		if current == "" {
+			current = fftypes.ContractListenerStateActive
+		}
+		state := update.State.Lower()
+		switch {
+		case state == current:
+			return nil
+		case state == fftypes.ContractListenerStatePaused:
+			err = cm.blockchain.PauseContractListener(ctx, listener)
+		case state == fftypes.ContractListenerStateActive:
+			err = cm.resumeContractListener(ctx, listener)
+		default:
+			return i18n.NewError(ctx, i18n.MsgInvalidContractListenerState, update.State)
+		}
+		if err != nil {
+			return err
+		}
+		listener.State = state
+		return cm.database.UpsertContractListener(ctx, listener)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (cm *contractManager) resumeContractListener(ctx context.Context, listener *fftypes.ContractListener) error {
+	checkpoint, err := cm.listenerCheckpoint(ctx, listener)
+	if err != nil {
+		return err
+	}
+	if checkpoint.ProtocolID != "" {
+		if listener.Options == nil {
+			listener.Options = &fftypes.ContractListenerOptions{}
+		}
+		listener.Options.Checkpoint = checkpoint
+	}
+	return cm.blockchain.ResumeContractListener(ctx, listener)
+}
+
 func (cm *contractManager) checkParamSchema(ctx context.Context, input interface{}, param *fftypes.FFIParam) error {
 	// TODO: Cache the compiled schema?
-	c := jsonschema.NewCompiler()
+	// The blockchain plugin validates the input against the details too, such as the range of its numeric types
+	c := cm.newFFISchemaCompiler()
 	err := c.AddResource(param.Name, strings.NewReader(param.Schema.String()))
 	if err != nil {
 		return i18n.WrapError(ctx, err, i18n.MsgFFISchemaParseFail, param.Name)
@@ -19,9 +19,11 @@ package contracts
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -31,6 +33,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -39,6 +42,7 @@ import (
 )
 
 func newTestContractManager() *contractManager {
+	config.Reset()
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
 	mbm := &broadcastmocks.Manager{}
@@ -342,6 +346,30 @@ func TestValidateInvokeContractRequestInvalidParam(t *testing.T) {
 	assert.Regexp(t, "does not validate", err)
 }
 
+func TestValidateInvokeContractRequestOverflow(t *testing.T) {
+	cm := newTestContractManager()
+	cm.ffiParamValidator = &ethereum.FFIParamValidator{}
+	req := &fftypes.ContractCallRequest{
+		Type: fftypes.CallTypeInvoke,
+		Method: &fftypes.FFIMethod{
+			Name: "transfer",
+			Params: []*fftypes.FFIParam{
+				{
+					Name:   "amount",
+					Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "uint64", "scale": 18}}`),
+				},
+			},
+			Returns: []*fftypes.FFIParam{},
+		},
+		Input: map[string]interface{}{
+			"amount": "18.446744073709551616",
+		},
+	}
+
+	err := cm.validateInvokeContractRequest(context.Background(), req)
+	assert.Regexp(t, "FF10331.*amount.*overflows uint64", err)
+}
+
 func TestValidateFFI(t *testing.T) {
 	cm := newTestContractManager()
 	ffi := &fftypes.FFI{
@@ -406,6 +434,58 @@ func TestValidateFFI(t *testing.T) {
 	assert.Equal(t, "sum_1", ffi.Events[1].Pathname)
 }
 
+func TestValidateFFIErrors(t *testing.T) {
+	cm := newTestContractManager()
+	ffi := &fftypes.FFI{
+		ID:        fftypes.NewUUID(),
+		Name:      "math",
+		Version:   "1.0.0",
+		Namespace: "default",
+		Errors: []*fftypes.FFIError{
+			{
+				FFIErrorDefinition: fftypes.FFIErrorDefinition{
+					Name: "Overflow",
+					Params: []*fftypes.FFIParam{
+						{
+							Name:   "z",
+							Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+						},
+					},
+				},
+			},
+			{
+				FFIErrorDefinition: fftypes.FFIErrorDefinition{
+					Name:   "Overflow",
+					Params: []*fftypes.FFIParam{},
+				},
+			},
+		},
+	}
+
+	err := cm.ValidateFFIAndSetPathnames(context.Background(), ffi)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Overflow", ffi.Errors[0].Pathname)
+	assert.Equal(t, "Overflow_1", ffi.Errors[1].Pathname)
+	assert.Equal(t, ffi.ID, ffi.Errors[0].Contract)
+	assert.Equal(t, "default", ffi.Errors[1].Namespace)
+}
+
+func TestValidateFFIBadError(t *testing.T) {
+	cm := newTestContractManager()
+	ffi := &fftypes.FFI{
+		Name:      "math",
+		Version:   "1.0.0",
+		Namespace: "default",
+		Errors: []*fftypes.FFIError{
+			{},
+		},
+	}
+
+	err := cm.ValidateFFIAndSetPathnames(context.Background(), ffi)
+	assert.Regexp(t, "FF10", err)
+}
+
 func TestValidateFFIFail(t *testing.T) {
 	cm := newTestContractManager()
 	ffi := &fftypes.FFI{
@@ -592,6 +672,59 @@ func TestAddContractListenerInline(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestAddContractListenerConfirmations(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				Confirmations: 12,
+			},
+		},
+	}
+
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{ConfirmationDepth: true})
+	mbi.On("AddContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), result.Options.Confirmations)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerConfirmationsNotSupported(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Options: &fftypes.ContractListenerOptions{
+				Confirmations: 12,
+			},
+		},
+	}
+
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{})
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10469.*mockblockchain", err)
+
+	mbi.AssertExpectations(t)
+}
+
 func TestAddContractListenerByRef(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -1013,6 +1146,7 @@ func TestAddContractListenerUpsertSubFail(t *testing.T) {
 	}
 
 	mbi.On("AddContractListener", context.Background(), sub).Return(nil)
+	mbi.On("DeleteContractListener", context.Background(), &sub.ContractListener).Return(fmt.Errorf("pop2"))
 	mdi.On("UpsertContractListener", context.Background(), &sub.ContractListener).Return(fmt.Errorf("pop"))
 
 	_, err := cm.AddContractListener(context.Background(), "ns", sub)
@@ -1053,6 +1187,9 @@ func TestGetFFIByIDWithChildren(t *testing.T) {
 	mdb.On("GetFFIEvents", mock.Anything, mock.Anything).Return([]*fftypes.FFIEvent{
 		{ID: fftypes.NewUUID(), FFIEventDefinition: fftypes.FFIEventDefinition{Name: "event1"}},
 	}, nil, nil)
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*fftypes.FFIError{
+		{ID: fftypes.NewUUID(), FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "error1"}},
+	}, nil, nil)
 
 	ffi, err := cm.GetFFIByIDWithChildren(context.Background(), cid)
 
@@ -1061,6 +1198,25 @@ func TestGetFFIByIDWithChildren(t *testing.T) {
 
 	assert.Equal(t, "method1", ffi.Methods[0].Name)
 	assert.Equal(t, "event1", ffi.Events[0].Name)
+	assert.Equal(t, "error1", ffi.Errors[0].Name)
+}
+
+func TestGetFFIByIDWithChildrenErrorsFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+
+	cid := fftypes.NewUUID()
+	mdb.On("GetFFIByID", mock.Anything, cid).Return(&fftypes.FFI{
+		ID: cid,
+	}, nil)
+	mdb.On("GetFFIMethods", mock.Anything, mock.Anything).Return([]*fftypes.FFIMethod{}, nil, nil)
+	mdb.On("GetFFIEvents", mock.Anything, mock.Anything).Return([]*fftypes.FFIEvent{}, nil, nil)
+	mdb.On("GetFFIErrors", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.GetFFIByIDWithChildren(context.Background(), cid)
+
+	assert.EqualError(t, err, "pop")
+	mdb.AssertExpectations(t)
 }
 
 func TestGetFFIByIDWithChildrenEventsFail(t *testing.T) {
@@ -1152,21 +1308,40 @@ func TestInvokeContract(t *testing.T) {
 			Params:  fftypes.FFIParams{},
 			Returns: fftypes.FFIParams{},
 		},
+		Errors: []*fftypes.FFIErrorDefinition{
+			{
+				Name: "Unauthorized",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "caller",
+						Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address"}}`),
+					},
+				},
+			},
+		},
 	}
 
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(&fftypes.FFI{ID: req.Interface}, nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "Unauthorized"}},
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "InsufficientFunds"}},
+	}, nil, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
-	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
-		data := op.Data.(blockchainInvokeData)
-		return op.Type == fftypes.OpTypeBlockchainInvoke && data.Request == req
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainInvoke
 	})).Return(nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
 	assert.NoError(t, err)
+	assert.Len(t, req.Errors, 2)
+	assert.Equal(t, "Unauthorized", req.Errors[0].Name)
+	assert.Len(t, req.Errors[0].Params, 1)
+	assert.Equal(t, "InsufficientFunds", req.Errors[1].Name)
 
 	mth.AssertExpectations(t)
 	mim.AssertExpectations(t)
@@ -1174,12 +1349,88 @@ func TestInvokeContract(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
-func TestInvokeContractFail(t *testing.T) {
+func TestResolveInvokeErrorsCached(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	mdi.On("GetFFIByID", mock.Anything, interfaceID).Return(&fftypes.FFI{ID: interfaceID}, nil).Once()
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return([]*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "Unauthorized"}},
+	}, nil, nil).Once()
+
+	req := &fftypes.ContractCallRequest{Interface: interfaceID}
+	errors, err := cm.resolveInvokeErrors(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, errors, 1)
+
+	// The second lookup is served from the cache
+	errors, err = cm.resolveInvokeErrors(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, errors, 1)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveInvokeErrorsNoInterface(t *testing.T) {
+	cm := newTestContractManager()
+
+	req := &fftypes.ContractCallRequest{
+		Errors: []*fftypes.FFIErrorDefinition{{Name: "Unauthorized"}},
+	}
+	errors, err := cm.resolveInvokeErrors(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, req.Errors, errors)
+}
+
+func TestResolveInvokeErrorsInterfaceNotFoundNotCached(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	mdi.On("GetFFIByID", mock.Anything, interfaceID).Return(nil, nil).Twice()
+
+	req := &fftypes.ContractCallRequest{Interface: interfaceID}
+	for i := 0; i < 2; i++ {
+		errors, err := cm.resolveInvokeErrors(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Empty(t, errors)
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveInvokeErrorsGetFFIFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	mdi.On("GetFFIByID", mock.Anything, interfaceID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.resolveInvokeErrors(context.Background(), &fftypes.ContractCallRequest{Interface: interfaceID})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveInvokeErrorsGetErrorsFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	mdi.On("GetFFIByID", mock.Anything, interfaceID).Return(&fftypes.FFI{ID: interfaceID}, nil)
+	mdi.On("GetFFIErrors", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.resolveInvokeErrors(context.Background(), &fftypes.ContractCallRequest{Interface: interfaceID})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestInvokeContractResolveErrorsFail(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
 	mdi := cm.database.(*databasemocks.Plugin)
-	mth := cm.txHelper.(*txcommonmocks.Helper)
-	mom := cm.operations.(*operationmocks.Manager)
 
 	req := &fftypes.ContractCallRequest{
 		Type:      fftypes.CallTypeInvoke,
@@ -1194,47 +1445,58 @@ func TestInvokeContractFail(t *testing.T) {
 		},
 	}
 
-	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
-		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
-	})).Return(nil)
-	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
-		data := op.Data.(blockchainInvokeData)
-		return op.Type == fftypes.OpTypeBlockchainInvoke && data.Request == req
-	})).Return(fmt.Errorf("pop"))
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, fmt.Errorf("pop"))
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
 	assert.EqualError(t, err, "pop")
-
-	mim.AssertExpectations(t)
 	mdi.AssertExpectations(t)
-	mth.AssertExpectations(t)
-	mom.AssertExpectations(t)
 }
 
-func TestInvokeContractFailNormalizeSigningKey(t *testing.T) {
+func TestInvokeContractWithCallback(t *testing.T) {
 	cm := newTestContractManager()
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"example.com"})
 	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
 
 	req := &fftypes.ContractCallRequest{
 		Type:      fftypes.CallTypeInvoke,
 		Interface: fftypes.NewUUID(),
 		Ledger:    fftypes.JSONAnyPtr(""),
 		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+		CallbackURL: "https://example.com/callback",
+		BusinessKey: "order-1",
 	}
 
-	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.CallbackURL == "https://example.com/callback" && op.BusinessKey == "order-1"
+	})).Return(nil)
+	mom.On("QueueOperation", mock.Anything, mock.Anything).Return(nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
-	assert.Regexp(t, "pop", err)
+	assert.NoError(t, err)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
 }
 
-func TestInvokeContractFailResolve(t *testing.T) {
+func TestInvokeContractBadCallbackURL(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
 	mim := cm.identity.(*identitymanagermocks.Manager)
 
 	req := &fftypes.ContractCallRequest{
@@ -1242,20 +1504,26 @@ func TestInvokeContractFailResolve(t *testing.T) {
 		Interface: fftypes.NewUUID(),
 		Ledger:    fftypes.JSONAnyPtr(""),
 		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+		CallbackURL: "not a url",
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input).Return(nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10484", err)
 
-	assert.Regexp(t, "FF10313", err)
+	mim.AssertExpectations(t)
 }
 
-func TestInvokeContractTXFail(t *testing.T) {
+func TestInvokeContractBusinessKeyTooLong(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
-	mth := cm.txHelper.(*txcommonmocks.Helper)
 
 	req := &fftypes.ContractCallRequest{
 		Type:      fftypes.CallTypeInvoke,
@@ -1268,57 +1536,325 @@ func TestInvokeContractTXFail(t *testing.T) {
 			Params:  fftypes.FFIParams{},
 			Returns: fftypes.FFIParams{},
 		},
+		BusinessKey: strings.Repeat("x", fftypes.BusinessKeyMaxLength+1),
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(nil, fmt.Errorf("pop"))
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10188.*businessKey", err)
 
-	assert.EqualError(t, err, "pop")
+	mim.AssertExpectations(t)
 }
 
-func TestInvokeContractNoMethodSignature(t *testing.T) {
+func TestDeployContract(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
 
-	req := &fftypes.ContractCallRequest{
-		Type:     fftypes.CallTypeInvoke,
-		Ledger:   fftypes.JSONAnyPtr(""),
-		Location: fftypes.JSONAnyPtr(""),
-		Method: &fftypes.FFIMethod{
-			Name: "sum",
-		},
+	req := &fftypes.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+		Input:      []interface{}{},
 	}
 
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainContractDeploy && op.Input.GetString("key") == "key-resolved"
+	})).Return(nil)
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainContractDeploy
+	})).Return(nil)
 
-	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	op, err := cm.DeployContract(context.Background(), "ns1", req)
 
-	assert.Regexp(t, "FF10314", err)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpTypeBlockchainContractDeploy, op.Type)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
 }
 
-func TestInvokeContractMethodNotFound(t *testing.T) {
+func TestDeployContractInterfaceNoConstructor(t *testing.T) {
 	cm := newTestContractManager()
-	mdb := cm.database.(*databasemocks.Plugin)
-	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
 
-	req := &fftypes.ContractCallRequest{
-		Type:      fftypes.CallTypeInvoke,
+	req := &fftypes.ContractDeployRequest{
 		Interface: fftypes.NewUUID(),
-		Ledger:    fftypes.JSONAnyPtr(""),
-		Location:  fftypes.JSONAnyPtr(""),
-		Method: &fftypes.FFIMethod{
-			Name: "sum",
-		},
 	}
 
-	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mdb.On("GetFFIMethod", mock.Anything, "ns1", req.Interface, req.Method.Name).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(&fftypes.FFI{}, nil)
+	mdi.On("GetFFIMethod", mock.Anything, "ns1", req.Interface, "constructor").Return(nil, nil)
 
-	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	constructor, err := cm.resolveDeployConstructor(context.Background(), "ns1", req)
 
-	assert.Regexp(t, "FF10315", err)
+	assert.NoError(t, err)
+	assert.Equal(t, "constructor", constructor.Name)
+	assert.Empty(t, constructor.Params)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeployContractNoContract(t *testing.T) {
+	cm := newTestContractManager()
+
+	_, err := cm.DeployContract(context.Background(), "ns1", &fftypes.ContractDeployRequest{})
+
+	assert.Regexp(t, "FF10426", err)
+}
+
+func TestDeployContractBadKey(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractDeployRequest{
+		Contract: fftypes.JSONAnyPtr(`"0x6080"`),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
+func TestDeployContractInterfaceNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	req := &fftypes.ContractDeployRequest{
+		Interface: fftypes.NewUUID(),
+		Contract:  fftypes.JSONAnyPtr(`"0x6080"`),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+
+	assert.Regexp(t, "FF10303", err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestDeployContractInterfaceFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	req := &fftypes.ContractDeployRequest{
+		Interface: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.resolveDeployConstructor(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeployContractTXFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+
+	req := &fftypes.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(nil, fmt.Errorf("pop"))
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestDeployContractInsertOpFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+
+	req := &fftypes.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestInvokeContractFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
+	})).Return(nil)
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainInvoke
+	})).Return(fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestInvokeContractFailNormalizeSigningKey(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.Regexp(t, "pop", err)
+}
+
+func TestInvokeContractFailResolve(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input, req.Errors).Return(nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.Regexp(t, "FF10313", err)
+}
+
+func TestInvokeContractTXFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInvokeContractNoMethodSignature(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:     fftypes.CallTypeInvoke,
+		Ledger:   fftypes.JSONAnyPtr(""),
+		Location: fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name: "sum",
+		},
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.Regexp(t, "FF10314", err)
+}
+
+func TestInvokeContractMethodNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mdb := cm.database.(*databasemocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name: "sum",
+		},
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdb.On("GetFFIMethod", mock.Anything, "ns1", req.Interface, req.Method.Name).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.Regexp(t, "FF10315", err)
 }
 
 func TestInvokeContractMethodBadInput(t *testing.T) {
@@ -1357,6 +1893,62 @@ func TestInvokeContractMethodBadInput(t *testing.T) {
 	assert.Regexp(t, "FF10304", err)
 }
 
+func TestInvokeContractErrorNoName(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+		Errors: []*fftypes.FFIErrorDefinition{
+			{Params: fftypes.FFIParams{}},
+		},
+	}
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10387", err)
+}
+
+func TestInvokeContractErrorBadParam(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+		Errors: []*fftypes.FFIErrorDefinition{
+			{
+				Name: "Unauthorized",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "x",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer"`),
+					},
+				},
+			},
+		},
+	}
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10332", err)
+}
+
 func TestQueryContract(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -1522,6 +2114,26 @@ func TestDeleteContractListenerBlockchainFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestDeleteContractListenerPaused(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.ContractListenerStatePaused,
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("DeleteContractListenerByID", context.Background(), sub.ID).Return(nil)
+
+	err := cm.DeleteContractListenerByNameOrID(context.Background(), "ns", "sub1")
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
 func TestDeleteContractListenerNotFound(t *testing.T) {
 	cm := newTestContractManager()
 	mdi := cm.database.(*databasemocks.Plugin)
@@ -1532,6 +2144,172 @@ func TestDeleteContractListenerNotFound(t *testing.T) {
 	assert.Regexp(t, "FF10109", err)
 }
 
+func TestUpdateContractListenerPause(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mbi.On("PauseContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), sub).Return(nil)
+
+	listener, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: "Paused",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ContractListenerStatePaused, listener.State)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateContractListenerResume(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns",
+		State:     fftypes.ContractListenerStatePaused,
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{
+		{ProtocolID: "000000001234/000002/000001"},
+	}, nil, nil)
+	mbi.On("ResumeContractListener", context.Background(), mock.MatchedBy(func(l *fftypes.ContractListener) bool {
+		return l.Options.Checkpoint.FirstEvent == "1234" && l.Options.Checkpoint.ProtocolID == "000000001234/000002/000001"
+	})).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), sub).Return(nil)
+
+	listener, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStateActive,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ContractListenerStateActive, listener.State)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateContractListenerResumeNoEvents(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns",
+		State:     fftypes.ContractListenerStatePaused,
+		Options:   &fftypes.ContractListenerOptions{FirstEvent: "oldest"},
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	mbi.On("ResumeContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), sub).Return(nil)
+
+	_, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStateActive,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, sub.Options.Checkpoint)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateContractListenerResumeCheckpointFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns",
+		State:     fftypes.ContractListenerStatePaused,
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStateActive,
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateContractListenerNoChange(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+
+	listener, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStateActive,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sub, listener)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateContractListenerBadState(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+
+	_, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: "deleted",
+	})
+	assert.Regexp(t, "FF10411", err)
+}
+
+func TestUpdateContractListenerBlockchainFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mbi.On("PauseContractListener", context.Background(), sub).Return(fmt.Errorf("pop"))
+
+	_, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStatePaused,
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateContractListenerNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(nil, nil)
+
+	_, err := cm.UpdateContractListenerByNameOrID(context.Background(), "ns", "sub1", &fftypes.ContractListenerUpdateDTO{
+		State: fftypes.ContractListenerStatePaused,
+	})
+	assert.Regexp(t, "FF10109", err)
+}
+
 func TestInvokeContractAPI(t *testing.T) {
 	cm := newTestContractManager()
 	mdb := cm.database.(*databasemocks.Plugin)
@@ -1561,12 +2339,12 @@ func TestInvokeContractAPI(t *testing.T) {
 	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(api, nil)
 	mdb.On("GetFFIMethod", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(&fftypes.FFIMethod{Name: "peel"}, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
+	mdi.On("GetFFIByID", mock.Anything, mock.Anything).Return(nil, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
-	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
-		data := op.Data.(blockchainInvokeData)
-		return op.Type == fftypes.OpTypeBlockchainInvoke && data.Request == req
+	mom.On("QueueOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainInvoke
 	})).Return(nil)
 
 	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "banana", "peel", req)
@@ -28,6 +28,11 @@ type blockchainInvokeData struct {
 	Request *fftypes.ContractCallRequest `json:"request"`
 }
 
+type blockchainDeployData struct {
+	Request     *fftypes.ContractDeployRequest `json:"request"`
+	Constructor *fftypes.FFIMethod             `json:"constructor,omitempty"`
+}
+
 func addBlockchainInvokeInputs(op *fftypes.Operation, req *fftypes.ContractCallRequest) (err error) {
 	var reqJSON []byte
 	if reqJSON, err = json.Marshal(req); err == nil {
@@ -45,6 +50,23 @@ func retrieveBlockchainInvokeInputs(ctx context.Context, op *fftypes.Operation)
 	return &req, nil
 }
 
+func addBlockchainDeployInputs(op *fftypes.Operation, req *fftypes.ContractDeployRequest) (err error) {
+	var reqJSON []byte
+	if reqJSON, err = json.Marshal(req); err == nil {
+		err = json.Unmarshal(reqJSON, &op.Input)
+	}
+	return err
+}
+
+func retrieveBlockchainDeployInputs(ctx context.Context, op *fftypes.Operation) (*fftypes.ContractDeployRequest, error) {
+	var req fftypes.ContractDeployRequest
+	s := op.Input.String()
+	if err := json.Unmarshal([]byte(s), &req); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, s)
+	}
+	return &req, nil
+}
+
 func (cm *contractManager) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	switch op.Type {
 	case fftypes.OpTypeBlockchainInvoke:
@@ -54,6 +76,17 @@ func (cm *contractManager) PrepareOperation(ctx context.Context, op *fftypes.Ope
 		}
 		return opBlockchainInvoke(op, req), nil
 
+	case fftypes.OpTypeBlockchainContractDeploy:
+		req, err := retrieveBlockchainDeployInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		constructor, err := cm.resolveDeployConstructor(ctx, op.Namespace, req)
+		if err != nil {
+			return nil, err
+		}
+		return opBlockchainDeploy(op, req, constructor), nil
+
 	default:
 		return nil, i18n.NewError(ctx, i18n.MsgOperationNotSupported, op.Type)
 	}
@@ -63,7 +96,11 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *fftypes.Prepare
 	switch data := op.Data.(type) {
 	case blockchainInvokeData:
 		req := data.Request
-		return nil, false, cm.blockchain.InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input)
+		return nil, false, cm.blockchain.InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input, req.Errors)
+
+	case blockchainDeployData:
+		req := data.Request
+		return nil, false, cm.blockchain.DeployContract(ctx, op.ID, req.Key, req.Definition, req.Contract, data.Constructor, req.Input)
 
 	default:
 		return nil, false, i18n.NewError(ctx, i18n.MsgOperationDataIncorrect, op.Data)
@@ -72,8 +109,21 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *fftypes.Prepare
 
 func opBlockchainInvoke(op *fftypes.Operation, req *fftypes.ContractCallRequest) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: blockchainInvokeData{Request: req},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        blockchainInvokeData{Request: req},
+		CallbackURL: op.CallbackURL,
+	}
+}
+
+func opBlockchainDeploy(op *fftypes.Operation, req *fftypes.ContractDeployRequest, constructor *fftypes.FFIMethod) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        blockchainDeployData{Request: req, Constructor: constructor},
 	}
 }
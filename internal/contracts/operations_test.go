@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,6 +38,9 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 		Method: &fftypes.FFIMethod{
 			Name: "set",
 		},
+		Errors: []*fftypes.FFIErrorDefinition{
+			{Name: "Unauthorized"},
+		},
 		Input: map[string]interface{}{
 			"value": "1",
 		},
@@ -49,7 +53,9 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 		return loc.String() == req.Location.String()
 	}), mock.MatchedBy(func(method *fftypes.FFIMethod) bool {
 		return method.Name == req.Method.Name
-	}), req.Input).Return(nil)
+	}), req.Input, mock.MatchedBy(func(errors []*fftypes.FFIErrorDefinition) bool {
+		return len(errors) == 1 && errors[0].Name == "Unauthorized"
+	})).Return(nil)
 
 	po, err := cm.PrepareOperation(context.Background(), op)
 	assert.NoError(t, err)
@@ -63,6 +69,69 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 	mbi.AssertExpectations(t)
 }
 
+func TestPrepareAndRunBlockchainDeploy(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &fftypes.Operation{
+		Type:      fftypes.OpTypeBlockchainContractDeploy,
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	req := &fftypes.ContractDeployRequest{
+		Key:       "0x123",
+		Interface: fftypes.NewUUID(),
+		Contract:  fftypes.JSONAnyPtr(`"0x6080"`),
+		Input:     []interface{}{"1"},
+	}
+	err := addBlockchainDeployInputs(op, req)
+	assert.NoError(t, err)
+
+	constructor := &fftypes.FFIMethod{Name: "constructor"}
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIByID", context.Background(), req.Interface).Return(&fftypes.FFI{}, nil)
+	mdi.On("GetFFIMethod", context.Background(), "ns1", req.Interface, "constructor").Return(constructor, nil)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("DeployContract", context.Background(), op.ID, "0x123", (*fftypes.JSONAny)(nil), mock.MatchedBy(func(contract *fftypes.JSONAny) bool {
+		return contract.String() == `"0x6080"`
+	}), constructor, []interface{}{"1"}).Return(nil)
+
+	po, err := cm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, constructor, po.Data.(blockchainDeployData).Constructor)
+
+	_, complete, err := cm.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestPrepareOperationBlockchainDeployBadInput(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeBlockchainContractDeploy,
+		Input: fftypes.JSONObject{"interface": "bad"},
+	}
+
+	_, err := cm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10151", err)
+}
+
+func TestPrepareOperationBlockchainDeployNoDefinition(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeBlockchainContractDeploy,
+		Input: fftypes.JSONObject{"contract": "0x6080"},
+	}
+
+	_, err := cm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10427", err)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
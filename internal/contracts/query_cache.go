@@ -0,0 +1,89 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// queryCacheLocationKey is the primary key for cached query results, so that all results for a
+// contract location can be invalidated together when an event is received from that location
+func queryCacheLocationKey(ns string, location *fftypes.JSONAny) string {
+	canonical := location.String()
+	var parsed interface{}
+	if err := json.Unmarshal(location.Bytes(), &parsed); err == nil {
+		b, _ := json.Marshal(parsed)
+		canonical = string(b)
+	}
+	return fmt.Sprintf("%s:%s", ns, canonical)
+}
+
+func queryCacheRequestKey(apiName, methodPath string, input map[string]interface{}) (string, error) {
+	inputBytes, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(apiName))
+	hash.Write([]byte{0})
+	hash.Write([]byte(methodPath))
+	hash.Write([]byte{0})
+	hash.Write(inputBytes)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (cm *contractManager) queryContractAPICached(ctx context.Context, ns string, api *fftypes.ContractAPI, methodPath string, req *fftypes.ContractCallRequest) (interface{}, error) {
+	primary := queryCacheLocationKey(ns, req.Location)
+	secondary, err := queryCacheRequestKey(api.Name, methodPath, req.Input)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to cache query for API '%s': %s", api.Name, err)
+		return cm.InvokeContract(ctx, ns, req)
+	}
+
+	if cached := cm.queryCache.Get(primary, secondary); cached != nil && !cached.Expired() {
+		log.L(ctx).Debugf("Query cache hit for API '%s' method '%s'", api.Name, methodPath)
+		return cached.Value(), nil
+	}
+
+	res, err := cm.InvokeContract(ctx, ns, req)
+	if err != nil {
+		return nil, err
+	}
+	ttl := cm.queryCacheTTL
+	if api.QueryCache.TTL != nil {
+		ttl = time.Duration(*api.QueryCache.TTL)
+	}
+	cm.queryCache.Set(primary, secondary, res, ttl)
+	return res, nil
+}
+
+func (cm *contractManager) InvalidateQueryCache(ns string, location *fftypes.JSONAny) {
+	if location == nil {
+		// The listener covers every contract, so we cannot tell which results are affected
+		cm.queryCache.Clear()
+		return
+	}
+	cm.queryCache.DeleteAll(queryCacheLocationKey(ns, location))
+}
@@ -0,0 +1,187 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestQueryCacheAPI(cm *contractManager, queryCache *fftypes.ContractAPIQueryCache) *fftypes.ContractAPI {
+	mdi := cm.database.(*databasemocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	api := &fftypes.ContractAPI{
+		Name: "prices",
+		Interface: &fftypes.FFIReference{
+			ID: fftypes.NewUUID(),
+		},
+		Location:   fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+		QueryCache: queryCache,
+	}
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "prices").Return(api, nil)
+	mdi.On("GetFFIMethod", mock.Anything, "ns1", mock.Anything, "getPrice").Return(&fftypes.FFIMethod{
+		Name:    "getPrice",
+		Params:  fftypes.FFIParams{},
+		Returns: fftypes.FFIParams{},
+	}, nil)
+	return api
+}
+
+func newTestQueryRequest() *fftypes.ContractCallRequest {
+	return &fftypes.ContractCallRequest{
+		Type: fftypes.CallTypeQuery,
+		Input: map[string]interface{}{
+			"symbol": "ABC",
+		},
+	}
+}
+
+func TestQueryContractAPICached(t *testing.T) {
+	cm := newTestContractManager()
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: true})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Once()
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("101", nil).Once()
+
+	res, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, "100", res)
+
+	// Served from the cache
+	res, err = cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, "100", res)
+
+	// An event from the contract invalidates the cached result
+	cm.InvalidateQueryCache("ns1", fftypes.JSONAnyPtr(`{ "address": "0x12345" }`))
+	res, err = cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, "101", res)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAPICacheDifferentInput(t *testing.T) {
+	cm := newTestContractManager()
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: true})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Twice()
+
+	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+
+	req := newTestQueryRequest()
+	req.Input["symbol"] = "XYZ"
+	_, err = cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", req)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAPICacheTTL(t *testing.T) {
+	cm := newTestContractManager()
+	ttl := fftypes.FFDuration(1 * time.Millisecond)
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: true, TTL: &ttl})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Twice()
+
+	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAPICacheDisabled(t *testing.T) {
+	cm := newTestContractManager()
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: false})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Twice()
+
+	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	_, err = cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAPICacheErrorNotCached(t *testing.T) {
+	cm := newTestContractManager()
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: true})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Once()
+
+	_, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.EqualError(t, err, "pop")
+	res, err := cm.InvokeContractAPI(context.Background(), "ns1", "prices", "getPrice", newTestQueryRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, "100", res)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAPICacheBadInput(t *testing.T) {
+	cm := newTestContractManager()
+	api := newTestQueryCacheAPI(cm, &fftypes.ContractAPIQueryCache{Enabled: true})
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("QueryContract", mock.Anything, api.Location, mock.Anything, mock.Anything).Return("100", nil).Once()
+
+	req := newTestQueryRequest()
+	req.Input["bad"] = map[bool]bool{true: false}
+	req.Location = api.Location
+	req.Interface = api.Interface.ID
+	req.Method = &fftypes.FFIMethod{Pathname: "getPrice"}
+	_, err := cm.queryContractAPICached(context.Background(), "ns1", api, "getPrice", req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cm.queryCache.ItemCount())
+
+	mbi.AssertExpectations(t)
+}
+
+func TestInvalidateQueryCacheAllLocations(t *testing.T) {
+	cm := newTestContractManager()
+	cm.queryCache.Set(queryCacheLocationKey("ns1", fftypes.JSONAnyPtr(`{"address":"0x12345"}`)), "key1", "100", time.Minute)
+	cm.InvalidateQueryCache("ns1", nil)
+	assert.Equal(t, 0, cm.queryCache.ItemCount())
+}
+
+func TestQueryCacheLocationKey(t *testing.T) {
+	assert.Equal(t,
+		queryCacheLocationKey("ns1", fftypes.JSONAnyPtr(`{"channel":"firefly","chaincode":"simplestorage"}`)),
+		queryCacheLocationKey("ns1", fftypes.JSONAnyPtr(`{ "chaincode": "simplestorage", "channel": "firefly" }`)),
+	)
+	assert.NotEqual(t,
+		queryCacheLocationKey("ns1", fftypes.JSONAnyPtr(`{"address":"0x12345"}`)),
+		queryCacheLocationKey("ns2", fftypes.JSONAnyPtr(`{"address":"0x12345"}`)),
+	)
+	assert.Equal(t, "ns1:!json", queryCacheLocationKey("ns1", fftypes.JSONAnyPtr(`!json`)))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"hash"
 	"io"
 
 	"github.com/docker/go-units"
@@ -32,29 +33,44 @@ import (
 )
 
 type blobStore struct {
-	dm            *dataManager
-	sharedstorage sharedstorage.Plugin
-	database      database.Plugin
-	exchange      dataexchange.Plugin
+	dm                       *dataManager
+	sharedstorage            sharedstorage.Plugin
+	database                 database.Plugin
+	exchange                 dataexchange.Plugin
+	hashAlgorithm            fftypes.HashAlgorithm
+	additionalHashAlgorithms []fftypes.HashAlgorithm
 }
 
-func (bs *blobStore) uploadVerifyBLOB(ctx context.Context, ns string, id *fftypes.UUID, reader io.Reader) (hash *fftypes.Bytes32, written int64, payloadRef string, err error) {
+// uploadVerifyBLOB streams the blob to data exchange, verifying the SHA-256 hash calculated by data exchange.
+// The hash returned is calculated with the configured algorithm, which might be different.
+func (bs *blobStore) uploadVerifyBLOB(ctx context.Context, ns string, id *fftypes.UUID, reader *blobLimitReader) (blobHash *fftypes.Bytes32, written int64, payloadRef string, err error) {
 	hashCalc := sha256.New()
 	dxReader, dx := io.Pipe()
-	storeAndHash := io.MultiWriter(hashCalc, dx)
+	writers := []io.Writer{hashCalc, dx}
+	var algorithmHashCalc hash.Hash
+	if !fftypes.IsDefaultHashAlgorithm(bs.hashAlgorithm) {
+		// The algorithm was validated when the data manager was created
+		algorithmHashCalc, _ = fftypes.NewHash(ctx, bs.hashAlgorithm)
+		writers = append(writers, algorithmHashCalc)
+	}
+	storeAndHash := io.MultiWriter(writers...)
 
 	copyDone := make(chan error, 1)
 	go func() {
 		var err error
 		written, err = io.Copy(storeAndHash, reader)
 		log.L(ctx).Debugf("Upload BLOB streamed %d bytes (err=%v)", written, err)
-		_ = dx.Close()
+		// A blob over the limit must not be stored by DX, so it is told the upload failed
+		_ = dx.CloseWithError(reader.exceeded)
 		copyDone <- err
 	}()
 
 	payloadRef, uploadHash, uploadSize, dxErr := bs.exchange.UploadBLOB(ctx, ns, *id, dxReader)
 	dxReader.Close()
 	copyErr := <-copyDone
+	if reader.exceeded != nil {
+		return nil, -1, "", reader.exceeded
+	}
 	if dxErr != nil {
 		return nil, -1, "", dxErr
 	}
@@ -62,40 +78,49 @@ func (bs *blobStore) uploadVerifyBLOB(ctx context.Context, ns string, id *fftype
 		return nil, -1, "", i18n.WrapError(ctx, copyErr, i18n.MsgBlobStreamingFailed)
 	}
 
-	hash = fftypes.HashResult(hashCalc)
-	log.L(ctx).Debugf("Upload BLOB size=%d hashes: calculated=%s upload=%s (expected=%v) size=%d", written, hash, uploadHash, uploadSize, written)
+	blobHash = fftypes.HashResult(hashCalc)
+	log.L(ctx).Debugf("Upload BLOB size=%d hashes: calculated=%s upload=%s (expected=%v) size=%d", written, blobHash, uploadHash, uploadSize, written)
 
-	if !uploadHash.Equals(hash) {
-		return nil, -1, "", i18n.NewError(ctx, i18n.MsgDXBadHash, uploadHash, hash)
+	if !uploadHash.Equals(blobHash) {
+		return nil, -1, "", i18n.NewError(ctx, i18n.MsgDXBadHash, uploadHash, blobHash)
 	}
 	if uploadSize > 0 && uploadSize != written {
 		return nil, -1, "", i18n.NewError(ctx, i18n.MsgDXBadSize, uploadSize, written)
 	}
 
-	return hash, written, payloadRef, nil
+	if algorithmHashCalc != nil {
+		blobHash = fftypes.HashResult(algorithmHashCalc)
+		log.L(ctx).Debugf("Upload BLOB %s hash=%s", bs.hashAlgorithm, blobHash)
+	}
+	return blobHash, written, payloadRef, nil
 
 }
 
 func (bs *blobStore) UploadBLOB(ctx context.Context, ns string, inData *fftypes.DataRefOrValue, mpart *fftypes.Multipart, autoMeta bool) (*fftypes.Data, error) {
 
 	data := &fftypes.Data{
-		ID:        fftypes.NewUUID(),
+		ID:        inData.ID,
 		Namespace: ns,
 		Created:   fftypes.Now(),
 		Validator: inData.Validator,
 		Datatype:  inData.Datatype,
 		Value:     inData.Value,
 	}
+	if data.ID == nil {
+		data.ID = fftypes.NewUUID()
+	} else if err := bs.dm.checkDataIDUnused(ctx, data.ID); err != nil {
+		return nil, err
+	}
 
-	data.ID = fftypes.NewUUID()
-	data.Namespace = ns
-	data.Created = fftypes.Now()
-
-	hash, blobSize, payloadRef, err := bs.uploadVerifyBLOB(ctx, ns, data.ID, mpart.Data)
+	hash, blobSize, payloadRef, err := bs.uploadVerifyBLOB(ctx, ns, data.ID, bs.dm.limits.limitBlobReader(ctx, ns, mpart.Data))
 	if err != nil {
 		return nil, err
 	}
-	data.Blob = &fftypes.BlobRef{Hash: hash}
+	var hashAlgorithm fftypes.HashAlgorithm
+	if !fftypes.IsDefaultHashAlgorithm(bs.hashAlgorithm) {
+		hashAlgorithm = bs.hashAlgorithm
+	}
+	data.Blob = &fftypes.BlobRef{Hash: hash, HashAlgorithm: hashAlgorithm}
 
 	// autoMeta will create/update JSON metadata with the upload details
 	if autoMeta {
@@ -110,13 +135,14 @@ func (bs *blobStore) UploadBLOB(ctx context.Context, ns string, inData *fftypes.
 	}
 
 	blob := &fftypes.Blob{
-		Hash:       hash,
-		Size:       blobSize,
-		PayloadRef: payloadRef,
-		Created:    fftypes.Now(),
+		Hash:          hash,
+		HashAlgorithm: hashAlgorithm,
+		Size:          blobSize,
+		PayloadRef:    payloadRef,
+		Created:       fftypes.Now(),
 	}
 
-	err = bs.dm.checkValidation(ctx, ns, data.Validator, data.Datatype, data.Value)
+	err = bs.dm.checkValidation(ctx, ns, data)
 	if err == nil {
 		err = data.Seal(ctx, blob)
 	}
@@ -171,3 +197,39 @@ func (bs *blobStore) DownloadBLOB(ctx context.Context, ns, dataID string) (*ffty
 	reader, err := bs.exchange.DownloadBLOB(ctx, blob.PayloadRef)
 	return blob, reader, err
 }
+
+// AlternateBlobs streams a blob received from another member back out of data exchange, and returns a copy of the
+// blob record for each additional hash algorithm that is configured. Data exchange only reports a SHA-256 hash,
+// so this allows the blob to be matched to data that refers to it by a hash calculated with another algorithm.
+func (bs *blobStore) AlternateBlobs(ctx context.Context, blob *fftypes.Blob) ([]*fftypes.Blob, error) {
+	if len(bs.additionalHashAlgorithms) == 0 {
+		return nil, nil
+	}
+
+	hashCalcs := make([]hash.Hash, len(bs.additionalHashAlgorithms))
+	writers := make([]io.Writer, len(bs.additionalHashAlgorithms))
+	for i, algorithm := range bs.additionalHashAlgorithms {
+		// The algorithms were validated when the data manager was created
+		hashCalcs[i], _ = fftypes.NewHash(ctx, algorithm)
+		writers[i] = hashCalcs[i]
+	}
+
+	reader, err := bs.exchange.DownloadBLOB(ctx, blob.PayloadRef)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if _, err := io.Copy(io.MultiWriter(writers...), reader); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgBlobStreamingFailed)
+	}
+
+	alternates := make([]*fftypes.Blob, len(hashCalcs))
+	for i, hashCalc := range hashCalcs {
+		alternate := *blob
+		alternate.Hash = fftypes.HashResult(hashCalc)
+		alternate.HashAlgorithm = bs.additionalHashAlgorithms[i]
+		alternates[i] = &alternate
+		log.L(ctx).Debugf("Blob '%s' has %s hash %s", blob.Hash, alternate.HashAlgorithm, alternate.Hash)
+	}
+	return alternates, nil
+}
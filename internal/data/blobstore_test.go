@@ -82,6 +82,64 @@ func TestUploadBlobOk(t *testing.T) {
 
 }
 
+func TestUploadBlobHashAlgorithm(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.hashAlgorithm = fftypes.HashAlgorithmSHA3256
+
+	b := []byte(`some data`)
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("InsertBlob", mock.Anything, mock.MatchedBy(func(blob *fftypes.Blob) bool {
+		return blob.HashAlgorithm == fftypes.HashAlgorithmSHA3256
+	})).Return(nil)
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBLOB", ctx, "ns1", mock.Anything, mock.Anything)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		_, err := ioutil.ReadAll(a[3].(io.Reader))
+		assert.Nil(t, err)
+		var hash fftypes.Bytes32 = sha256.Sum256(b)
+		dxUpload.ReturnArguments = mock.Arguments{"ns1/blob1", &hash, int64(len(b)), err}
+	}
+
+	data, err := dm.UploadBLOB(ctx, "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{Data: bytes.NewReader(b)}, false)
+	assert.NoError(t, err)
+
+	expected, _ := fftypes.HashStringWithAlgorithm(ctx, fftypes.HashAlgorithmSHA3256, string(b))
+	assert.Equal(t, expected, data.Blob.Hash)
+	assert.Equal(t, fftypes.HashAlgorithmSHA3256, data.Blob.HashAlgorithm)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+
+}
+
+func TestUploadBlobExistingID(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", ctx, dataID, false).Return(&fftypes.Data{ID: dataID}, nil)
+
+	_, err := dm.UploadBLOB(ctx, "ns1", &fftypes.DataRefOrValue{
+		DataRef: fftypes.DataRef{ID: dataID},
+	}, &fftypes.Multipart{Data: bytes.NewReader([]byte(`hello`))}, false)
+	assert.Regexp(t, "FF10564", err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestUploadBlobAutoMetaOk(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -109,14 +167,19 @@ func TestUploadBlobAutoMetaOk(t *testing.T) {
 		dxUpload.ReturnArguments = mock.Arguments{fmt.Sprintf("ns1/%s", uuid), &hash, int64(len(readBytes)), err}
 	}
 
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", ctx, dataID, false).Return(nil, nil)
 	data, err := dm.UploadBLOB(ctx, "ns1", &fftypes.DataRefOrValue{
-		Value: fftypes.JSONAnyPtr(`{"custom": "value1"}`),
+		DataRef: fftypes.DataRef{ID: dataID},
+		Value:   fftypes.JSONAnyPtr(`{"custom": "value1"}`),
 	}, &fftypes.Multipart{
 		Data:     bytes.NewReader([]byte(`hello`)),
 		Filename: "myfile.csv",
 		Mimetype: "text/csv",
 	}, true)
 	assert.NoError(t, err)
+	assert.Equal(t, dataID, data.ID)
+	assert.Equal(t, *dataID, <-dxID)
 	assert.Equal(t, "myfile.csv", data.Value.JSONObject().GetString("filename"))
 	assert.Equal(t, "text/csv", data.Value.JSONObject().GetString("mimetype"))
 	assert.Equal(t, "value1", data.Value.JSONObject().GetString("custom"))
@@ -401,3 +464,61 @@ func TestDownloadBlobBadID(t *testing.T) {
 	assert.Regexp(t, "FF10142", err)
 
 }
+
+func TestAlternateBlobsNone(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	alternates, err := dm.AlternateBlobs(ctx, &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ref1"})
+	assert.NoError(t, err)
+	assert.Empty(t, alternates)
+}
+
+func TestAlternateBlobs(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.additionalHashAlgorithms = []fftypes.HashAlgorithm{fftypes.HashAlgorithmSHA3256, fftypes.HashAlgorithmBLAKE2b256}
+
+	b := []byte(`some data`)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ref1").Return(ioutil.NopCloser(bytes.NewReader(b)), nil)
+
+	blob := &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ref1", Peer: "peer1", Size: int64(len(b))}
+	alternates, err := dm.AlternateBlobs(ctx, blob)
+	assert.NoError(t, err)
+	assert.Len(t, alternates, 2)
+	sha3Hash, _ := fftypes.HashStringWithAlgorithm(ctx, fftypes.HashAlgorithmSHA3256, string(b))
+	assert.Equal(t, sha3Hash, alternates[0].Hash)
+	assert.Equal(t, fftypes.HashAlgorithmSHA3256, alternates[0].HashAlgorithm)
+	blake2bHash, _ := fftypes.HashStringWithAlgorithm(ctx, fftypes.HashAlgorithmBLAKE2b256, string(b))
+	assert.Equal(t, blake2bHash, alternates[1].Hash)
+	assert.Equal(t, fftypes.HashAlgorithmBLAKE2b256, alternates[1].HashAlgorithm)
+	assert.Equal(t, "peer1", alternates[1].Peer)
+	assert.Equal(t, "ref1", alternates[1].PayloadRef)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestAlternateBlobsDownloadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.additionalHashAlgorithms = []fftypes.HashAlgorithm{fftypes.HashAlgorithmSHA3256}
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ref1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := dm.AlternateBlobs(ctx, &fftypes.Blob{PayloadRef: "ref1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAlternateBlobsReadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.additionalHashAlgorithms = []fftypes.HashAlgorithm{fftypes.HashAlgorithmSHA3256}
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ref1").Return(ioutil.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), nil)
+
+	_, err := dm.AlternateBlobs(ctx, &fftypes.Blob{PayloadRef: "ref1"})
+	assert.Regexp(t, "FF10217", err)
+}
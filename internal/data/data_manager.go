@@ -35,6 +35,7 @@ import (
 type Manager interface {
 	CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error
 	ValidateAll(ctx context.Context, data fftypes.DataArray) (valid bool, err error)
+	ValidateValue(ctx context.Context, ns string, datatype *fftypes.DatatypeRef, value *fftypes.JSONAny) (retry bool, err error)
 	GetMessageWithDataCached(ctx context.Context, msgID *fftypes.UUID, options ...CacheReadOption) (msg *fftypes.Message, data fftypes.DataArray, foundAllData bool, err error)
 	GetMessageDataCached(ctx context.Context, msg *fftypes.Message, options ...CacheReadOption) (data fftypes.DataArray, foundAll bool, err error)
 	PeekMessageCache(ctx context.Context, id *fftypes.UUID, options ...CacheReadOption) (msg *fftypes.Message, data fftypes.DataArray)
@@ -42,13 +43,17 @@ type Manager interface {
 	UpdateMessageIfCached(ctx context.Context, msg *fftypes.Message)
 	UpdateMessageStateIfCached(ctx context.Context, id *fftypes.UUID, state fftypes.MessageState, confirmed *fftypes.FFTime)
 	ResolveInlineData(ctx context.Context, msg *NewMessage) error
+	ResolvePendingData(ctx context.Context, msg *fftypes.Message) (complete bool, err error)
 	WriteNewMessage(ctx context.Context, newMsg *NewMessage) error
 	VerifyNamespaceExists(ctx context.Context, ns string) error
 
 	UploadJSON(ctx context.Context, ns string, inData *fftypes.DataRefOrValue) (*fftypes.Data, error)
 	UploadBLOB(ctx context.Context, ns string, inData *fftypes.DataRefOrValue, blob *fftypes.Multipart, autoMeta bool) (*fftypes.Data, error)
 	DownloadBLOB(ctx context.Context, ns, dataID string) (*fftypes.Blob, io.ReadCloser, error)
+	AlternateBlobs(ctx context.Context, blob *fftypes.Blob) ([]*fftypes.Blob, error)
 	HydrateBatch(ctx context.Context, persistedBatch *fftypes.BatchPersisted) (*fftypes.Batch, error)
+	Limits(ns string) *fftypes.MessageLimits
+	LimitsStatus() *fftypes.LimitsStatus
 	WaitStop()
 }
 
@@ -58,9 +63,11 @@ type dataManager struct {
 	exchange          dataexchange.Plugin
 	validatorCache    *ccache.Cache
 	validatorCacheTTL time.Duration
+	jsonValidatorConf *jsonValidatorConf
 	messageCache      *ccache.Cache
 	messageCacheTTL   time.Duration
 	messageWriter     *messageWriter
+	limits            *messageLimits
 }
 
 type messageCacheEntry struct {
@@ -101,11 +108,32 @@ func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Pl
 		validatorCacheTTL: config.GetDuration(config.ValidatorCacheTTL),
 		messageCacheTTL:   config.GetDuration(config.MessageCacheTTL),
 	}
+	jsonValidatorConf, err := newJSONValidatorConf(ctx, di.GetDatatypeByName)
+	if err != nil {
+		return nil, err
+	}
+	dm.jsonValidatorConf = jsonValidatorConf
+	if dm.limits, err = newMessageLimits(ctx); err != nil {
+		return nil, err
+	}
 	dm.blobStore = blobStore{
 		dm:            dm,
 		database:      di,
 		sharedstorage: pi,
 		exchange:      dx,
+		hashAlgorithm: fftypes.HashAlgorithm(config.GetString(config.BlobHashAlgorithm)).Lower(),
+	}
+	if _, err := fftypes.NewHash(ctx, dm.blobStore.hashAlgorithm); err != nil {
+		return nil, err
+	}
+	for _, algorithm := range config.GetStringSlice(config.BlobAdditionalHashAlgorithms) {
+		algorithm := fftypes.HashAlgorithm(algorithm).Lower()
+		if _, err := fftypes.NewHash(ctx, algorithm); err != nil {
+			return nil, err
+		}
+		if !fftypes.IsDefaultHashAlgorithm(algorithm) {
+			dm.blobStore.additionalHashAlgorithms = append(dm.blobStore.additionalHashAlgorithms, algorithm)
+		}
 	}
 	dm.validatorCache = ccache.New(
 		// We use a LRU cache with a size-aware max
@@ -126,8 +154,16 @@ func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Pl
 	return dm, nil
 }
 
+// newValidator creates the validator for the schema in a datatype, according to its validator type
+func (dm *dataManager) newValidator(ctx context.Context, ns string, datatype *fftypes.Datatype) (Validator, error) {
+	if datatype.Validator == fftypes.ValidatorTypeXSD {
+		return newXSDValidator(ctx, ns, datatype)
+	}
+	return newJSONValidator(ctx, ns, datatype, dm.jsonValidatorConf)
+}
+
 func (dm *dataManager) CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error {
-	_, err := newJSONValidator(ctx, ns, datatype)
+	_, err := dm.newValidator(ctx, ns, datatype)
 	return err
 }
 
@@ -170,7 +206,11 @@ func (dm *dataManager) getValidatorForDatatype(ctx context.Context, ns string, v
 	if datatype == nil {
 		return nil, nil
 	}
-	v, err := newJSONValidator(ctx, ns, datatype)
+	if datatype.Validator != validator && (datatype.Validator != "" || validator != fftypes.ValidatorTypeJSON) {
+		log.L(ctx).Warnf("Datatype '%s:%s' has validator '%s', not '%s'", ns, datatypeRef, datatype.Validator, validator)
+		return nil, nil
+	}
+	v, err := dm.newValidator(ctx, ns, datatype)
 	if err != nil {
 		log.L(ctx).Errorf("Invalid validator stored for '%s:%s:%s': %s", validator, ns, datatypeRef, err)
 		return nil, nil
@@ -319,7 +359,7 @@ func (dm *dataManager) ValidateAll(ctx context.Context, data fftypes.DataArray)
 				log.L(ctx).Errorf("Datatype %s:%s:%s not found", d.Validator, d.Namespace, d.Datatype)
 				return false, err
 			}
-			err = v.ValidateValue(ctx, d.Value, d.Hash)
+			err = v.Validate(ctx, d)
 			if err != nil {
 				return false, err
 			}
@@ -328,6 +368,19 @@ func (dm *dataManager) ValidateAll(ctx context.Context, data fftypes.DataArray)
 	return true, nil
 }
 
+// ValidateValue validates a JSON value that is not itself a data record, such as an identity profile, against a datatype.
+// Failing to load the datatype is reported as retryable, as distinct from the value being invalid.
+func (dm *dataManager) ValidateValue(ctx context.Context, ns string, datatype *fftypes.DatatypeRef, value *fftypes.JSONAny) (retry bool, err error) {
+	v, err := dm.getValidatorForDatatype(ctx, ns, fftypes.ValidatorTypeJSON, datatype)
+	if err != nil {
+		return true, err
+	}
+	if v == nil {
+		return true, i18n.NewError(ctx, i18n.MsgDatatypeNotFound, datatype)
+	}
+	return false, v.ValidateValue(ctx, value, nil)
+}
+
 func (dm *dataManager) resolveRef(ctx context.Context, ns string, dataRef *fftypes.DataRef) (*fftypes.Data, error) {
 	if dataRef == nil || dataRef.ID == nil {
 		log.L(ctx).Warnf("data is nil")
@@ -363,7 +416,10 @@ func (dm *dataManager) resolveBlob(ctx context.Context, blobRef *fftypes.BlobRef
 	return nil, nil
 }
 
-func (dm *dataManager) checkValidation(ctx context.Context, ns string, validator fftypes.ValidatorType, datatype *fftypes.DatatypeRef, value *fftypes.JSONAny) error {
+// checkValidation validates new data against its datatype, before it is sealed
+func (dm *dataManager) checkValidation(ctx context.Context, ns string, data *fftypes.Data) error {
+	validator := data.Validator
+	datatype := data.Datatype
 	if validator == "" {
 		validator = fftypes.ValidatorTypeJSON
 	}
@@ -383,7 +439,7 @@ func (dm *dataManager) checkValidation(ctx context.Context, ns string, validator
 			if v == nil {
 				return i18n.NewError(ctx, i18n.MsgDatatypeNotFound, datatype)
 			}
-			err = v.ValidateValue(ctx, value, nil)
+			err = v.Validate(ctx, data)
 			if err != nil {
 				return err
 			}
@@ -392,6 +448,22 @@ func (dm *dataManager) checkValidation(ctx context.Context, ns string, validator
 	return nil
 }
 
+func (dm *dataManager) resolveMediaType(ctx context.Context, mediaType string, value *fftypes.JSONAny) (string, []byte, *fftypes.JSONAny, error) {
+	hasOriginal, err := fftypes.IsOriginalMediaType(ctx, mediaType)
+	if err != nil || !hasOriginal {
+		return "", nil, value, err
+	}
+	original, err := fftypes.DecodeOriginalValue(ctx, mediaType, value)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	canonical, err := fftypes.CanonicalJSON(ctx, mediaType, original)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return mediaType, original, canonical, nil
+}
+
 func (dm *dataManager) validateInputData(ctx context.Context, ns string, inData *fftypes.DataRefOrValue) (data *fftypes.Data, err error) {
 
 	validator := inData.Validator
@@ -399,23 +471,33 @@ func (dm *dataManager) validateInputData(ctx context.Context, ns string, inData
 	value := inData.Value
 	blobRef := inData.Blob
 
-	if err := dm.checkValidation(ctx, ns, validator, datatype, value); err != nil {
-		return nil, err
-	}
-
-	blob, err := dm.resolveBlob(ctx, blobRef)
+	// Values in other media types are hashed in their canonical JSON form. They are validated in that form
+	// by a JSON schema, or in their original form by an XSD.
+	mediaType, original, value, err := dm.resolveMediaType(ctx, inData.MediaType, value)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ok, we're good to generate the full data payload and save it
 	data = &fftypes.Data{
+		ID:        inData.ID,
 		Validator: validator,
 		Datatype:  datatype,
 		Namespace: ns,
 		Value:     value,
+		MediaType: mediaType,
+		Original:  original,
 		Blob:      blobRef,
 	}
+	if err := dm.checkValidation(ctx, ns, data); err != nil {
+		return nil, err
+	}
+
+	blob, err := dm.resolveBlob(ctx, blobRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ok, we're good to generate the full data payload and save it
 	err = data.Seal(ctx, blob)
 	if err != nil {
 		return nil, err
@@ -423,7 +505,26 @@ func (dm *dataManager) validateInputData(ctx context.Context, ns string, inData
 	return data, nil
 }
 
+// checkDataIDUnused checks an ID chosen by the caller for new data does not belong to existing data,
+// which would otherwise be overwritten
+func (dm *dataManager) checkDataIDUnused(ctx context.Context, id *fftypes.UUID) error {
+	if id == nil {
+		return nil
+	}
+	existing, err := dm.database.GetDataByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return i18n.NewError(ctx, i18n.MsgDataIDExists, id)
+	}
+	return nil
+}
+
 func (dm *dataManager) UploadJSON(ctx context.Context, ns string, inData *fftypes.DataRefOrValue) (*fftypes.Data, error) {
+	if err := dm.checkDataIDUnused(ctx, inData.ID); err != nil {
+		return nil, err
+	}
 	data, err := dm.validateInputData(ctx, ns, inData)
 	if err != nil {
 		return nil, err
@@ -446,7 +547,14 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 
 	inData := newMessage.Message.InlineData
 	msg := newMessage.Message
-	newMessage.AllData = make(fftypes.DataArray, len(newMessage.Message.InlineData))
+	if msg.Draft && msg.DeferredData {
+		return i18n.NewError(ctx, i18n.MsgDraftDeferredData)
+	}
+	if err := dm.limits.checkDataItems(ctx, msg.Header.Namespace, len(inData)); err != nil {
+		return err
+	}
+	newMessage.AllData = make(fftypes.DataArray, 0, len(newMessage.Message.InlineData))
+	refs := make(fftypes.DataRefs, len(newMessage.Message.InlineData))
 	for i, dataOrValue := range inData {
 		var d *fftypes.Data
 		switch {
@@ -456,6 +564,13 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 			if err != nil {
 				return err
 			}
+			if d == nil && msg.DeferredData {
+				// The data will be uploaded later, and the message is held until it arrives
+				log.L(ctx).Infof("Message %s waiting for data %s", msg.Header.ID, dataOrValue.ID)
+				msg.State = fftypes.MessageStatePendingData
+				refs[i] = &fftypes.DataRef{ID: dataOrValue.ID, Hash: dataOrValue.Hash}
+				continue
+			}
 			if d == nil {
 				return i18n.NewError(ctx, i18n.MsgDataReferenceUnresolvable, i)
 			}
@@ -472,11 +587,26 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 			// We have nothing - this must be a mistake
 			return i18n.NewError(ctx, i18n.MsgDataMissing, i)
 		}
-		newMessage.AllData[i] = d
+		newMessage.AllData = append(newMessage.AllData, d)
+		refs[i] = &fftypes.DataRef{ID: d.ID, Hash: d.Hash, ValueSize: d.ValueSize}
 
 	}
-	newMessage.Message.Data = newMessage.AllData.Refs()
-	return nil
+	newMessage.Message.Data = refs
+	return dm.limits.checkMessageSize(ctx, msg.Header.Namespace, msg.EstimateSize(true))
+}
+
+// ResolvePendingData fills in the data references of a message that was sent before all of its data had been uploaded.
+// Returns false if any of the data has still not arrived
+func (dm *dataManager) ResolvePendingData(ctx context.Context, msg *fftypes.Message) (complete bool, err error) {
+	for _, dataRef := range msg.Data {
+		d, err := dm.resolveRef(ctx, msg.Header.Namespace, dataRef)
+		if err != nil || d == nil {
+			return false, err
+		}
+		dataRef.Hash = d.Hash
+		dataRef.ValueSize = d.ValueSize
+	}
+	return true, nil
 }
 
 // HydrateBatch fetches the full messages for a persisted batch, ready for transmission
@@ -489,6 +619,7 @@ func (dm *dataManager) HydrateBatch(ctx context.Context, persistedBatch *fftypes
 	}
 
 	batch := persistedBatch.GenInflight(make([]*fftypes.Message, len(manifest.Messages)), make(fftypes.DataArray, len(manifest.Data)))
+	batch.ProtocolVersion = manifest.Version
 
 	for i, mr := range manifest.Messages {
 		m, err := dm.database.GetMessageByID(ctx, mr.ID)
@@ -520,10 +651,17 @@ func (dm *dataManager) WriteNewMessage(ctx context.Context, newMsg *NewMessage)
 		return i18n.NewError(ctx, i18n.MsgNilOrNullObject)
 	}
 
+	// The message might be written on a background worker, so take the correlation ID from the context now
+	if newMsg.Message.CorrelationID == "" {
+		newMsg.Message.CorrelationID = log.CorrelationID(ctx)
+	}
+
 	// We add the message to the cache before we write it, because the batch aggregator might
 	// pick up our message from the message-writer before we return. The batch processor
 	// writes a more authoritative cache entry, with pings/batchID etc.
-	dm.UpdateMessageCache(&newMsg.Message.Message, newMsg.AllData)
+	if newMsg.Message.State != fftypes.MessageStatePendingData {
+		dm.UpdateMessageCache(&newMsg.Message.Message, newMsg.AllData)
+	}
 
 	err := dm.messageWriter.WriteNewMessage(ctx, newMsg)
 	if err != nil {
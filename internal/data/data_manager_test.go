@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
@@ -118,6 +119,65 @@ func TestValidateE2E(t *testing.T) {
 
 }
 
+func TestValidateValue(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	dt := &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Value: fftypes.JSONAnyPtr(`{
+			"properties": {
+				"field1": {
+					"type": "string"
+				}
+			},
+			"additionalProperties": false
+		}`),
+		Namespace: "ns1",
+		Name:      "profile",
+		Version:   "0.0.1",
+	}
+	ref := &fftypes.DatatypeRef{Name: "profile", Version: "0.0.1"}
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "profile", "0.0.1").Return(dt, nil)
+
+	retry, err := dm.ValidateValue(ctx, "ns1", ref, fftypes.JSONAnyPtr(`{"field1":"value1"}`))
+	assert.NoError(t, err)
+	assert.False(t, retry)
+
+	retry, err = dm.ValidateValue(ctx, "ns1", ref, fftypes.JSONAnyPtr(`{"field2":"value2"}`))
+	assert.Regexp(t, "FF10198", err)
+	assert.False(t, retry)
+
+}
+
+func TestValidateValueDatatypeNotFound(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "profile", "0.0.1").Return(nil, nil)
+
+	retry, err := dm.ValidateValue(ctx, "ns1", &fftypes.DatatypeRef{Name: "profile", Version: "0.0.1"}, fftypes.JSONAnyPtr(`{}`))
+	assert.Regexp(t, "FF10195", err)
+	assert.True(t, retry)
+
+}
+
+func TestValidateValueLookupError(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "profile", "0.0.1").Return(nil, fmt.Errorf("pop"))
+
+	retry, err := dm.ValidateValue(ctx, "ns1", &fftypes.DatatypeRef{Name: "profile", Version: "0.0.1"}, fftypes.JSONAnyPtr(`{}`))
+	assert.Regexp(t, "pop", err)
+	assert.True(t, retry)
+
+}
+
 func TestWriteNewMessageE2E(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -215,6 +275,38 @@ func TestInitBadDeps(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitBadJSONSchemaDraft(t *testing.T) {
+	config.Reset()
+	config.Set(config.ValidatorJSONDefaultDraft, "draft-99")
+	_, err := NewDataManager(context.Background(), &databasemocks.Plugin{}, &sharedstoragemocks.Plugin{}, &dataexchangemocks.Plugin{})
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestInitBadBlobHashAlgorithm(t *testing.T) {
+	config.Reset()
+	config.Set(config.BlobHashAlgorithm, "md5")
+	_, err := NewDataManager(context.Background(), &databasemocks.Plugin{}, &sharedstoragemocks.Plugin{}, &dataexchangemocks.Plugin{})
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestInitBadAdditionalBlobHashAlgorithm(t *testing.T) {
+	config.Reset()
+	config.Set(config.BlobAdditionalHashAlgorithms, []string{"sha3-256", "md5"})
+	_, err := NewDataManager(context.Background(), &databasemocks.Plugin{}, &sharedstoragemocks.Plugin{}, &dataexchangemocks.Plugin{})
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestInitAdditionalBlobHashAlgorithms(t *testing.T) {
+	config.Reset()
+	config.Set(config.BlobAdditionalHashAlgorithms, []string{"SHA3-256", "sha256"})
+	mdi := &databasemocks.Plugin{}
+	mdi.On("Capabilities").Return(&database.Capabilities{})
+	dm, err := NewDataManager(context.Background(), mdi, &sharedstoragemocks.Plugin{}, &dataexchangemocks.Plugin{})
+	assert.NoError(t, err)
+	assert.Equal(t, []fftypes.HashAlgorithm{fftypes.HashAlgorithmSHA3256}, dm.(*dataManager).additionalHashAlgorithms)
+	dm.WaitStop()
+}
+
 func TestValidatorLookupCached(t *testing.T) {
 
 	config.Reset()
@@ -426,6 +518,78 @@ func TestResolveInlineDataRefIDOnlyOK(t *testing.T) {
 	assert.Empty(t, newMsg.NewData)
 }
 
+func TestResolveInlineDataDeferred(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	pendingID := fftypes.NewUUID()
+	newMsg.Message.DeferredData = true
+	newMsg.Message.InlineData = append(newMsg.Message.InlineData, &fftypes.DataRefOrValue{
+		DataRef: fftypes.DataRef{ID: pendingID},
+	})
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+	}, nil)
+	mdi.On("GetDataByID", ctx, pendingID, true).Return(nil, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStatePendingData, newMsg.Message.State)
+	assert.Len(t, newMsg.AllData, 1)
+	assert.Len(t, newMsg.Message.Data, 2)
+	assert.Equal(t, dataHash, newMsg.Message.Data[0].Hash)
+	assert.Equal(t, pendingID, newMsg.Message.Data[1].ID)
+	assert.Nil(t, newMsg.Message.Data[1].Hash)
+}
+
+func TestResolvePendingData(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	data1 := &fftypes.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), ValueSize: 12345}
+	data2ID := fftypes.NewUUID()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+		Data: fftypes.DataRefs{
+			{ID: data1.ID},
+			{ID: data2ID},
+		},
+	}
+
+	mdi.On("GetDataByID", ctx, data1.ID, true).Return(data1, nil)
+	mdi.On("GetDataByID", ctx, data2ID, true).Return(nil, nil).Once()
+	complete, err := dm.ResolvePendingData(ctx, msg)
+	assert.NoError(t, err)
+	assert.False(t, complete)
+
+	data2 := &fftypes.Data{ID: data2ID, Namespace: "ns1", Hash: fftypes.NewRandB32()}
+	mdi.On("GetDataByID", ctx, data2ID, true).Return(data2, nil)
+	complete, err = dm.ResolvePendingData(ctx, msg)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, data1.Hash, msg.Data[0].Hash)
+	assert.Equal(t, int64(12345), msg.Data[0].ValueSize)
+	assert.Equal(t, data2.Hash, msg.Data[1].Hash)
+}
+
+func TestResolvePendingDataFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataByID", ctx, mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+	_, err := dm.ResolvePendingData(ctx, &fftypes.Message{
+		Data: fftypes.DataRefs{{ID: fftypes.NewUUID()}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestResolveInlineDataDataToPublish(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -512,6 +676,18 @@ func TestResolveInlineDataRefBadHash(t *testing.T) {
 	assert.Regexp(t, "FF10204", err)
 }
 
+func TestResolveInlineDataDeferredDraft(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.DeferredData = true
+	newMsg.Message.Draft = true
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10538", err)
+}
+
 func TestResolveInlineDataNilMsg(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -635,6 +811,34 @@ func TestUploadJSONLoadDatatypeFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestUploadJSONExistingID(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", ctx, dataID, false).Return(&fftypes.Data{ID: dataID}, nil)
+	_, err := dm.UploadJSON(ctx, "ns1", &fftypes.DataRefOrValue{
+		DataRef: fftypes.DataRef{ID: dataID},
+		Value:   fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.Regexp(t, "FF10564", err)
+}
+
+func TestUploadJSONExistingIDFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", ctx, dataID, false).Return(nil, fmt.Errorf("pop"))
+	_, err := dm.UploadJSON(ctx, "ns1", &fftypes.DataRefOrValue{
+		DataRef: fftypes.DataRef{ID: dataID},
+		Value:   fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestUploadJSONLoadInsertDataFail(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -645,6 +849,174 @@ func TestUploadJSONLoadInsertDataFail(t *testing.T) {
 	assert.Regexp(t, "FF10158", err)
 }
 
+func TestValidateInputDataXML(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&fftypes.Datatype{
+		Name:    "customer",
+		Version: "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"properties": {
+				"customer": {
+					"properties": { "@id": { "type": "string", "format": "uuid" } },
+					"required": ["@id"]
+				}
+			}
+		}`),
+	}, nil)
+
+	data, err := dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		Validator: fftypes.ValidatorTypeJSON,
+		Datatype: &fftypes.DatatypeRef{
+			Name:    "customer",
+			Version: "0.0.1",
+		},
+		MediaType: fftypes.DataMediaTypeXML,
+		Value:     fftypes.JSONAnyPtr(`"<customer id=\"e8c4b3c6-7e4c-4b8c-9a6b-2f0b5b5a4a2d\"><name>Acme</name></customer>"`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.DataMediaTypeXML, data.MediaType)
+	assert.Equal(t, `<customer id="e8c4b3c6-7e4c-4b8c-9a6b-2f0b5b5a4a2d"><name>Acme</name></customer>`, string(data.Original))
+	assert.Equal(t, `{"customer":{"@id":"e8c4b3c6-7e4c-4b8c-9a6b-2f0b5b5a4a2d","name":"Acme"}}`, data.Value.String())
+	assert.Equal(t, data.Value.Hash(), data.Hash)
+
+	_, err = dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		Validator: fftypes.ValidatorTypeJSON,
+		Datatype: &fftypes.DatatypeRef{
+			Name:    "customer",
+			Version: "0.0.1",
+		},
+		MediaType: fftypes.DataMediaTypeXML,
+		Value:     fftypes.JSONAnyPtr(`"<customer id=\"12345\"/>"`),
+	})
+	assert.Regexp(t, "FF10198", err)
+}
+
+const testCustomerXSD = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+	<xs:element name="customer">
+		<xs:complexType>
+			<xs:sequence>
+				<xs:element name="name" type="xs:string"/>
+			</xs:sequence>
+			<xs:attribute name="id" type="xs:int" use="required"/>
+		</xs:complexType>
+	</xs:element>
+</xs:schema>`
+
+func TestValidateInputDataXSD(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "purchaseOrder", "0.0.1").Return(newTestXSDDatatype(testCustomerXSD), nil)
+
+	datatype := &fftypes.DatatypeRef{Name: "purchaseOrder", Version: "0.0.1"}
+	data, err := dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		Validator: fftypes.ValidatorTypeXSD,
+		Datatype:  datatype,
+		MediaType: fftypes.DataMediaTypeXML,
+		Value:     fftypes.JSONAnyPtr(`"<customer id=\"12345\"><name>Acme</name></customer>"`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"customer":{"@id":"12345","name":"Acme"}}`, data.Value.String())
+
+	valid, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		Validator: fftypes.ValidatorTypeXSD,
+		Datatype:  datatype,
+		MediaType: fftypes.DataMediaTypeXML,
+		Value:     fftypes.JSONAnyPtr(`"<customer id=\"abc\"><name>Acme</name></customer>"`),
+	})
+	assert.Regexp(t, "FF10575.*/customer/@id: 'abc' is not a valid int", err)
+
+	_, err = dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		Validator: fftypes.ValidatorTypeXSD,
+		Datatype:  datatype,
+		Value:     fftypes.JSONAnyPtr(`{"customer":{"@id":"12345","name":"Acme"}}`),
+	})
+	assert.Regexp(t, "FF10574", err)
+}
+
+func TestValidateAllValidatorMismatch(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "purchaseOrder", "0.0.1").Return(newTestXSDDatatype(testCustomerXSD), nil)
+
+	data := &fftypes.Data{
+		Namespace: "ns1",
+		Validator: fftypes.ValidatorTypeJSON,
+		Datatype:  &fftypes.DatatypeRef{Name: "purchaseOrder", Version: "0.0.1"},
+		Value:     fftypes.JSONAnyPtr(`{}`),
+	}
+	data.Seal(ctx, nil)
+	valid, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestCheckDatatypeXSD(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	err := dm.CheckDatatype(ctx, "ns1", newTestXSDDatatype(testCustomerXSD))
+	assert.NoError(t, err)
+	err = dm.CheckDatatype(ctx, "ns1", newTestXSDDatatype(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`))
+	assert.Regexp(t, "FF10196.*no global elements", err)
+}
+
+func TestValidateInputDataCBOR(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data, err := dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		MediaType: fftypes.DataMediaTypeCBOR,
+		Value:     fftypes.JSONAnyPtr(`"oWFhgwECAw=="`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.DataMediaTypeCBOR, data.MediaType)
+	assert.Equal(t, []byte{0xa1, 0x61, 0x61, 0x83, 0x01, 0x02, 0x03}, data.Original)
+	assert.Equal(t, `{"a":[1,2,3]}`, data.Value.String())
+}
+
+func TestValidateInputDataJSONMediaType(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data, err := dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		MediaType: fftypes.DataMediaTypeJSON,
+		Value:     fftypes.JSONAnyPtr(`{"a":1}`),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, data.MediaType)
+	assert.Nil(t, data.Original)
+}
+
+func TestValidateInputDataMediaTypeErrors(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		MediaType: "text/plain",
+		Value:     fftypes.JSONAnyPtr(`"hello"`),
+	})
+	assert.Regexp(t, "FF10393", err)
+
+	_, err = dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		MediaType: fftypes.DataMediaTypeCBOR,
+		Value:     fftypes.JSONAnyPtr(`{"a":1}`),
+	})
+	assert.Regexp(t, "FF10396", err)
+
+	_, err = dm.validateInputData(ctx, "ns1", &fftypes.DataRefOrValue{
+		MediaType: fftypes.DataMediaTypeXML,
+		Value:     fftypes.JSONAnyPtr(`"<a>"`),
+	})
+	assert.Regexp(t, "FF10394", err)
+}
+
 func TestValidateAndStoreLoadNilRef(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -1158,3 +1530,37 @@ func TestWriteNewMessageFailClosed(t *testing.T) {
 	})
 	assert.Regexp(t, "FF10158", err)
 }
+
+func TestWriteNewMessagePendingDataNotCached(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.messageWriter.close()
+
+	msg := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+			State:  fftypes.MessageStatePendingData,
+		},
+	}
+	err := dm.WriteNewMessage(ctx, &NewMessage{
+		Message: msg,
+	})
+	assert.Regexp(t, "FF10158", err)
+	cached, _ := dm.PeekMessageCache(ctx, msg.Header.ID)
+	assert.Nil(t, cached)
+}
+
+func TestWriteNewMessageCorrelationID(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.messageWriter.close()
+
+	msg := &fftypes.MessageInOut{}
+	err := dm.WriteNewMessage(log.WithCorrelationID(ctx, "corr1"), &NewMessage{
+		Message: msg,
+	})
+	assert.Regexp(t, "FF10158", err)
+	assert.Equal(t, "corr1", msg.CorrelationID)
+}
@@ -19,14 +19,39 @@ package data
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
 	"strings"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
+const datatypeURLPrefix = "ff://datatypes/"
+
+var jsonSchemaDrafts = map[string]*jsonschema.Draft{
+	"draft-04": jsonschema.Draft4,
+	"draft-06": jsonschema.Draft6,
+	"draft-07": jsonschema.Draft7,
+	"2019-09":  jsonschema.Draft2019,
+	"2020-12":  jsonschema.Draft2020,
+}
+
+// jsonValidatorConf controls how JSON schemas are compiled.
+// Schemas that declare a "$schema" are always compiled with the semantics of that draft, so datatypes
+// written for an older draft keep their behavior. Those that do not declare one use defaultDraft.
+// Note "format" is always asserted for drafts before 2019-09, and only when assertFormat is set after that.
+type jsonValidatorConf struct {
+	defaultDraft *jsonschema.Draft
+	assertFormat bool
+	getDatatype  func(ctx context.Context, ns, name, version string) (*fftypes.Datatype, error)
+}
+
 type jsonValidator struct {
 	id       *fftypes.UUID
 	size     int64
@@ -35,7 +60,44 @@ type jsonValidator struct {
 	schema   *jsonschema.Schema
 }
 
-func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype) (*jsonValidator, error) {
+func newJSONValidatorConf(ctx context.Context, getDatatype func(ctx context.Context, ns, name, version string) (*fftypes.Datatype, error)) (*jsonValidatorConf, error) {
+	draftName := config.GetString(config.ValidatorJSONDefaultDraft)
+	draft, ok := jsonSchemaDrafts[draftName]
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgUnknownJSONSchemaDraft, draftName)
+	}
+	return &jsonValidatorConf{
+		defaultDraft: draft,
+		assertFormat: config.GetBool(config.ValidatorJSONAssertFormat),
+		getDatatype:  getDatatype,
+	}, nil
+}
+
+func datatypeURL(name, version string) string {
+	return fmt.Sprintf("%s%s/%s", datatypeURLPrefix, name, version)
+}
+
+// loadDatatypeRef resolves a "$ref" to another datatype registered in the same namespace
+func (conf *jsonValidatorConf) loadDatatypeRef(ctx context.Context, ns, ref string) (io.ReadCloser, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "ff" || u.Host != "datatypes" {
+		return nil, i18n.NewError(ctx, i18n.MsgSchemaRefUnsupported, ref)
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) != 2 {
+		return nil, i18n.NewError(ctx, i18n.MsgSchemaRefUnsupported, ref)
+	}
+	datatype, err := conf.getDatatype(ctx, ns, parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if datatype == nil || datatype.Value == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgSchemaRefNotFound, ref, ns)
+	}
+	return ioutil.NopCloser(strings.NewReader(datatype.Value.String())), nil
+}
+
+func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype, conf *jsonValidatorConf) (*jsonValidator, error) {
 	jv := &jsonValidator{
 		id: datatype.ID,
 		ns: ns,
@@ -50,12 +112,17 @@ func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype
 		schemaBytes = []byte(*datatype.Value)
 	}
 	c := jsonschema.NewCompiler()
-	c.Draft = jsonschema.Draft2020
-	err := c.AddResource(datatype.Name, strings.NewReader(datatype.Value.String()))
+	c.Draft = conf.defaultDraft
+	c.AssertFormat = conf.assertFormat
+	c.LoadURL = func(ref string) (io.ReadCloser, error) {
+		return conf.loadDatatypeRef(ctx, ns, ref)
+	}
+	schemaURL := datatypeURL(datatype.Name, datatype.Version)
+	err := c.AddResource(schemaURL, strings.NewReader(datatype.Value.String()))
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgSchemaLoadFailed, jv.datatype)
 	}
-	schema, err := c.Compile(datatype.Name)
+	schema, err := c.Compile(schemaURL)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgSchemaLoadFailed, jv.datatype)
 	}
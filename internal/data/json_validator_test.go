@@ -18,12 +18,25 @@ package data
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestJSONValidatorConf(t *testing.T, mdi *databasemocks.Plugin) *jsonValidatorConf {
+	config.Reset()
+	if mdi == nil {
+		mdi = &databasemocks.Plugin{}
+	}
+	conf, err := newJSONValidatorConf(context.Background(), mdi.GetDatatypeByName)
+	assert.NoError(t, err)
+	return conf
+}
+
 func TestJSONValidator(t *testing.T) {
 
 	schemaBinary := []byte(`{
@@ -42,7 +55,7 @@ func TestJSONValidator(t *testing.T) {
 		Value:     fftypes.JSONAnyPtrBytes(schemaBinary),
 	}
 
-	jv, err := newJSONValidator(context.Background(), "ns1", dt)
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, nil))
 	assert.NoError(t, err)
 
 	err = jv.validateJSONString(context.Background(), `{}`)
@@ -67,7 +80,7 @@ func TestJSONValidatorParseSchemaFail(t *testing.T) {
 		Value:     fftypes.JSONAnyPtr(`{!json`),
 	}
 
-	_, err := newJSONValidator(context.Background(), "ns1", dt)
+	_, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, nil))
 	assert.Regexp(t, "FF10196", err)
 
 }
@@ -79,3 +92,167 @@ func TestJSONValidatorNilData(t *testing.T) {
 	assert.Regexp(t, "FF10199", err)
 
 }
+
+func TestJSONValidatorConfBadDraft(t *testing.T) {
+	config.Reset()
+	config.Set(config.ValidatorJSONDefaultDraft, "draft-99")
+	_, err := newJSONValidatorConf(context.Background(), nil)
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestJSONValidatorFormatAssertion(t *testing.T) {
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": {
+				"id": { "type": "string", "format": "uuid" },
+				"email": { "type": "string", "format": "email" },
+				"created": { "type": "string", "format": "date-time" }
+			}
+		}`),
+	}
+
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, nil))
+	assert.NoError(t, err)
+
+	err = jv.validateJSONString(context.Background(), `{"id": "e8c4b3c6-7e4c-4b8c-9a6b-2f0b5b5a4a2d", "email": "a@example.com", "created": "2022-05-01T10:00:00Z"}`)
+	assert.NoError(t, err)
+	err = jv.validateJSONString(context.Background(), `{"id": "not-a-uuid"}`)
+	assert.Regexp(t, "FF10198.*uuid", err)
+	err = jv.validateJSONString(context.Background(), `{"email": "not-an-email"}`)
+	assert.Regexp(t, "FF10198.*email", err)
+	err = jv.validateJSONString(context.Background(), `{"created": "yesterday"}`)
+	assert.Regexp(t, "FF10198.*date-time", err)
+
+	conf := newTestJSONValidatorConf(t, nil)
+	conf.assertFormat = false
+	jv, err = newJSONValidator(context.Background(), "ns1", dt, conf)
+	assert.NoError(t, err)
+	err = jv.validateJSONString(context.Background(), `{"id": "not-a-uuid"}`)
+	assert.NoError(t, err)
+
+}
+
+func TestJSONValidatorDeclaredDraft(t *testing.T) {
+
+	// Draft-07 schemas always assert format, and use draft-07 semantics for keywords like "items"
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "array",
+			"items": [{ "type": "string", "format": "email" }]
+		}`),
+	}
+
+	conf := newTestJSONValidatorConf(t, nil)
+	conf.assertFormat = false
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, conf)
+	assert.NoError(t, err)
+
+	err = jv.validateJSONString(context.Background(), `["a@example.com", 12345]`)
+	assert.NoError(t, err)
+	err = jv.validateJSONString(context.Background(), `["not-an-email"]`)
+	assert.Regexp(t, "FF10198.*email", err)
+
+}
+
+func TestJSONValidatorDatatypeRef(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdi.On("GetDatatypeByName", context.Background(), "ns1", "address", "1.0").Return(&fftypes.Datatype{
+		Name:    "address",
+		Version: "1.0",
+		Value: fftypes.JSONAnyPtr(`{
+			"type": "object",
+			"properties": { "postcode": { "$ref": "../postcode/1.0" } },
+			"required": ["postcode"]
+		}`),
+	}, nil)
+	mdi.On("GetDatatypeByName", context.Background(), "ns1", "postcode", "1.0").Return(&fftypes.Datatype{
+		Name:    "postcode",
+		Version: "1.0",
+		Value:   fftypes.JSONAnyPtr(`{ "type": "string", "maxLength": 8 }`),
+	}, nil)
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"properties": { "address": { "$ref": "ff://datatypes/address/1.0" } }
+		}`),
+	}
+
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, mdi))
+	assert.NoError(t, err)
+
+	err = jv.validateJSONString(context.Background(), `{"address": {"postcode": "AB1 2CD"}}`)
+	assert.NoError(t, err)
+	err = jv.validateJSONString(context.Background(), `{"address": {}}`)
+	assert.Regexp(t, "FF10198.*postcode", err)
+	err = jv.validateJSONString(context.Background(), `{"address": {"postcode": "too long for a postcode"}}`)
+	assert.Regexp(t, "FF10198", err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestJSONValidatorDatatypeRefNotFound(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdi.On("GetDatatypeByName", context.Background(), "ns1", "address", "1.0").Return(nil, nil)
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{ "$ref": "ff://datatypes/address/1.0" }`),
+	}
+
+	_, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, mdi))
+	assert.Regexp(t, "FF10196.*FF10390", err)
+
+}
+
+func TestJSONValidatorDatatypeRefLookupFail(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdi.On("GetDatatypeByName", context.Background(), "ns1", "address", "1.0").Return(nil, fmt.Errorf("pop"))
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{ "$ref": "ff://datatypes/address/1.0" }`),
+	}
+
+	_, err := newJSONValidator(context.Background(), "ns1", dt, newTestJSONValidatorConf(t, mdi))
+	assert.Regexp(t, "FF10196.*pop", err)
+
+}
+
+func TestJSONValidatorRefUnsupported(t *testing.T) {
+
+	conf := newTestJSONValidatorConf(t, nil)
+	for _, ref := range []string{"https://example.com/schema.json", "ff://datatypes/address", "ff://other/address/1.0"} {
+		dt := &fftypes.Datatype{
+			Validator: fftypes.ValidatorTypeJSON,
+			Name:      "customer",
+			Version:   "0.0.1",
+			Value:     fftypes.JSONAnyPtr(fmt.Sprintf(`{ "$ref": "%s" }`, ref)),
+		}
+		_, err := newJSONValidator(context.Background(), "ns1", dt, conf)
+		assert.Regexp(t, "FF10196.*FF10391", err)
+	}
+
+	_, err := conf.loadDatatypeRef(context.Background(), "ns1", "ff://datatypes/%zz")
+	assert.Regexp(t, "FF10391", err)
+
+}
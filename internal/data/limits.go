@@ -0,0 +1,162 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// messageLimits holds the default limits, and the overrides configured for individual namespaces
+// under the "limits" section of each entry in namespaces.predefined
+type messageLimits struct {
+	defaults   fftypes.MessageLimits
+	namespaces map[string]*fftypes.MessageLimits
+}
+
+func newMessageLimits(ctx context.Context) (*messageLimits, error) {
+	ml := &messageLimits{
+		defaults: fftypes.MessageLimits{
+			MaxMessageBytes: config.GetByteSize(config.MessageLimitsMaxMessageBytes),
+			MaxDataItems:    config.GetInt(config.MessageLimitsMaxDataItems),
+			MaxBlobBytes:    config.GetByteSize(config.MessageLimitsMaxBlobBytes),
+		},
+		namespaces: make(map[string]*fftypes.MessageLimits),
+	}
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		obj, ok := entry.GetObjectOk("limits")
+		if !ok {
+			continue
+		}
+		ns := entry.GetString("name")
+		limits, err := ml.parseNamespaceLimits(obj)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgInvalidNamespaceLimits, ns, err)
+		}
+		ml.namespaces[ns] = limits
+	}
+	return ml, nil
+}
+
+// parseNamespaceLimits starts from the defaults, so a namespace only needs to set the limits it overrides
+func (ml *messageLimits) parseNamespaceLimits(obj fftypes.JSONObject) (*fftypes.MessageLimits, error) {
+	limits := ml.defaults // copy
+	if v, ok := obj["maxMessageBytes"]; ok {
+		limits.MaxMessageBytes = fftypes.ParseToByteSize(fmt.Sprint(v))
+	}
+	if v, ok := obj["maxDataItems"]; ok {
+		maxDataItems, err := strconv.Atoi(fmt.Sprint(v))
+		if err != nil {
+			return nil, err
+		}
+		limits.MaxDataItems = maxDataItems
+	}
+	if v, ok := obj["maxBlobBytes"]; ok {
+		limits.MaxBlobBytes = fftypes.ParseToByteSize(fmt.Sprint(v))
+	}
+	return &limits, nil
+}
+
+func (ml *messageLimits) forNamespace(ns string) *fftypes.MessageLimits {
+	if limits, ok := ml.namespaces[ns]; ok {
+		return limits
+	}
+	return &ml.defaults
+}
+
+func (ml *messageLimits) status() *fftypes.LimitsStatus {
+	status := &fftypes.LimitsStatus{
+		Defaults:   ml.defaults,
+		Namespaces: make([]*fftypes.NamespaceLimits, 0, len(ml.namespaces)),
+	}
+	for ns, limits := range ml.namespaces {
+		status.Namespaces = append(status.Namespaces, &fftypes.NamespaceLimits{
+			Namespace:     ns,
+			MessageLimits: *limits,
+		})
+	}
+	sort.Slice(status.Namespaces, func(i, j int) bool {
+		return status.Namespaces[i].Namespace < status.Namespaces[j].Namespace
+	})
+	return status
+}
+
+func (ml *messageLimits) checkDataItems(ctx context.Context, ns string, count int) error {
+	if max := ml.forNamespace(ns).MaxDataItems; max > 0 && count > max {
+		return i18n.NewError(ctx, i18n.MsgTooManyDataItems, count, max, ns)
+	}
+	return nil
+}
+
+func (ml *messageLimits) checkMessageSize(ctx context.Context, ns string, size int64) error {
+	if max := ml.forNamespace(ns).MaxMessageBytes; max > 0 && size > max {
+		return i18n.NewError(ctx, i18n.MsgMessageTooLarge, size, max, ns)
+	}
+	return nil
+}
+
+// blobLimitReader fails the upload of a blob as soon as it exceeds the limit for the namespace,
+// rather than streaming the whole blob before rejecting it
+type blobLimitReader struct {
+	ctx       context.Context
+	reader    io.Reader
+	ns        string
+	max       int64
+	remaining int64
+	exceeded  error
+}
+
+func (ml *messageLimits) limitBlobReader(ctx context.Context, ns string, reader io.Reader) *blobLimitReader {
+	max := ml.forNamespace(ns).MaxBlobBytes
+	return &blobLimitReader{ctx: ctx, reader: reader, ns: ns, max: max, remaining: max}
+}
+
+func (lr *blobLimitReader) Read(p []byte) (int, error) {
+	if lr.max <= 0 {
+		return lr.reader.Read(p)
+	}
+	if lr.remaining < 0 {
+		return 0, lr.exceeded
+	}
+	// Read one byte beyond the limit, so we can tell a blob of exactly the limit from one that is too large
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[0 : lr.remaining+1]
+	}
+	n, err := lr.reader.Read(p)
+	lr.remaining -= int64(n)
+	if lr.remaining < 0 {
+		lr.exceeded = i18n.NewError(lr.ctx, i18n.MsgBlobTooLarge, lr.max, lr.ns)
+		return 0, lr.exceeded
+	}
+	return n, err
+}
+
+func (dm *dataManager) Limits(ns string) *fftypes.MessageLimits {
+	limits := *dm.limits.forNamespace(ns)
+	return &limits
+}
+
+func (dm *dataManager) LimitsStatus() *fftypes.LimitsStatus {
+	return dm.limits.status()
+}
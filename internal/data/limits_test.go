@@ -0,0 +1,147 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewMessageLimits(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	config.Set(config.MessageLimitsMaxMessageBytes, "1Mb")
+	config.Set(config.MessageLimitsMaxDataItems, 5)
+	config.Set(config.NamespacesPredefined, []interface{}{
+		map[string]interface{}{"name": "ns2", "limits": map[string]interface{}{"maxBlobBytes": 1024}},
+		map[string]interface{}{"name": "ns1", "limits": map[string]interface{}{"maxMessageBytes": "2kb", "maxDataItems": "2"}},
+		map[string]interface{}{"name": "ns3"},
+	})
+
+	ml, err := newMessageLimits(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, &fftypes.MessageLimits{MaxMessageBytes: 2048, MaxDataItems: 2}, ml.forNamespace("ns1"))
+	assert.Equal(t, &fftypes.MessageLimits{MaxMessageBytes: 1048576, MaxDataItems: 5, MaxBlobBytes: 1024}, ml.forNamespace("ns2"))
+	assert.Equal(t, &fftypes.MessageLimits{MaxMessageBytes: 1048576, MaxDataItems: 5}, ml.forNamespace("ns3"))
+
+	status := ml.status()
+	assert.Equal(t, int64(1048576), status.Defaults.MaxMessageBytes)
+	assert.Len(t, status.Namespaces, 2)
+	assert.Equal(t, "ns1", status.Namespaces[0].Namespace)
+	assert.Equal(t, 2, status.Namespaces[0].MaxDataItems)
+	assert.Equal(t, "ns2", status.Namespaces[1].Namespace)
+}
+
+func TestNewDataManagerBadLimits(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	config.Set(config.NamespacesPredefined, []interface{}{
+		map[string]interface{}{"name": "ns1", "limits": map[string]interface{}{"maxDataItems": "lots"}},
+	})
+	mdi := &databasemocks.Plugin{}
+	mdi.On("Capabilities").Return(&database.Capabilities{}).Maybe()
+	_, err := NewDataManager(context.Background(), mdi, &sharedstoragemocks.Plugin{}, &dataexchangemocks.Plugin{})
+	assert.Regexp(t, "FF10496.*ns1", err)
+}
+
+func TestLimits(t *testing.T) {
+	dm, _, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.limits.namespaces["ns1"] = &fftypes.MessageLimits{MaxDataItems: 1}
+
+	limits := dm.Limits("ns1")
+	limits.MaxDataItems = 10 // a copy
+	assert.Equal(t, 1, dm.Limits("ns1").MaxDataItems)
+	assert.Equal(t, 0, dm.Limits("ns2").MaxDataItems)
+	assert.Len(t, dm.LimitsStatus().Namespaces, 1)
+}
+
+func TestResolveInlineDataTooManyItems(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.limits.defaults.MaxDataItems = 1
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.InlineData = append(newMsg.Message.InlineData, newMsg.Message.InlineData[0])
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10493.*2.*1.*ns1", err)
+}
+
+func TestResolveInlineDataTooLarge(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.limits.defaults.MaxMessageBytes = 100
+
+	newMsg := &NewMessage{
+		Message: &fftypes.MessageInOut{
+			Message: fftypes.Message{
+				Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+			},
+			InlineData: fftypes.InlineData{
+				{Value: fftypes.JSONAnyPtr(`"some value"`)},
+			},
+		},
+	}
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10494.*100 bytes.*ns1", err)
+}
+
+func TestUploadBlobTooLarge(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.limits.defaults.MaxBlobBytes = 100
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBLOB", ctx, "ns1", mock.Anything, mock.Anything)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		_, err := ioutil.ReadAll(a[3].(io.Reader))
+		assert.Regexp(t, "FF10495", err)
+		dxUpload.ReturnArguments = mock.Arguments{"", nil, int64(-1), err}
+	}
+
+	_, err := dm.UploadBLOB(ctx, "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{Data: bytes.NewReader(make([]byte, 101))}, false)
+	assert.Regexp(t, "FF10495.*100 bytes.*ns1", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestBlobLimitReaderAtLimit(t *testing.T) {
+	ml := &messageLimits{defaults: fftypes.MessageLimits{MaxBlobBytes: 100}}
+	lr := ml.limitBlobReader(context.Background(), "ns1", bytes.NewReader(make([]byte, 100)))
+	b, err := ioutil.ReadAll(lr)
+	assert.NoError(t, err)
+	assert.Len(t, b, 100)
+
+	lr = ml.limitBlobReader(context.Background(), "ns1", bytes.NewReader(make([]byte, 1000)))
+	_, err = ioutil.ReadAll(lr)
+	assert.Regexp(t, "FF10495", err)
+	_, err = lr.Read(make([]byte, 10))
+	assert.Regexp(t, "FF10495", err)
+}
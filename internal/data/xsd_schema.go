@@ -0,0 +1,658 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The XSD support is a subset of XML Schema 1.0, covering the structures used to describe business documents:
+// - Global and local elements, with minOccurs/maxOccurs, and refs to global elements
+// - Named and anonymous complex types, with sequence, choice and all groups, attributes, mixed content,
+//   and simple content extending a simple type
+// - Named and anonymous simple types, restricting a built-in or named simple type with the enumeration,
+//   pattern, length, minLength, maxLength, minInclusive, maxInclusive, minExclusive and maxExclusive facets
+// - The common built-in types
+// A schema that uses anything else fails to load, rather than being partially enforced.
+// Elements are matched by local name, and imports and includes are not supported.
+
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+const xsdUnbounded = -1
+
+// xmlNode is a parsed XML element, used for both schemas and the documents they validate
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+	// prefixes are the namespace declarations in scope, used to resolve the QNames in schema attributes
+	prefixes map[string]string
+}
+
+func (n *xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func parseXMLTree(b []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("multiple root elements")
+			}
+			n := &xmlNode{name: t.Name, prefixes: make(map[string]string)}
+			if len(stack) > 0 {
+				for k, v := range stack[len(stack)-1].prefixes {
+					n.prefixes[k] = v
+				}
+			}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					n.prefixes[a.Name.Local] = a.Value
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					n.prefixes[""] = a.Value
+				default:
+					n.attrs = append(n.attrs, a)
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+type xsdSchema struct {
+	elements     map[string]*xsdElement
+	complexTypes map[string]*xsdComplexType
+	simpleTypes  map[string]*xsdSimpleType
+}
+
+type xsdElement struct {
+	name      string
+	minOccurs int
+	maxOccurs int
+	ref       string
+	typeName  *xml.Name
+	complex   *xsdComplexType
+	simple    *xsdSimpleType
+}
+
+// xsdGroup is a sequence, choice or all group of particles
+type xsdGroup struct {
+	kind      string
+	minOccurs int
+	maxOccurs int
+	particles []*xsdParticle
+}
+
+type xsdParticle struct {
+	element *xsdElement
+	group   *xsdGroup
+}
+
+type xsdAttribute struct {
+	name     string
+	required bool
+	fixed    *string
+	typeName *xml.Name
+	simple   *xsdSimpleType
+}
+
+type xsdComplexType struct {
+	mixed      bool
+	content    *xsdGroup
+	simpleBase *xml.Name
+	attributes []*xsdAttribute
+}
+
+type xsdSimpleType struct {
+	base         *xml.Name
+	baseType     *xsdSimpleType
+	enumeration  []string
+	patterns     []*regexp.Regexp
+	length       *int
+	minLength    *int
+	maxLength    *int
+	minInclusive *big.Float
+	maxInclusive *big.Float
+	minExclusive *big.Float
+	maxExclusive *big.Float
+}
+
+func xsdUnsupported(n *xmlNode) error {
+	return fmt.Errorf("unsupported XSD construct '%s'", n.name.Local)
+}
+
+// xsdChildren returns the XSD children of a schema node, ignoring annotations
+func xsdChildren(n *xmlNode) ([]*xmlNode, error) {
+	children := make([]*xmlNode, 0, len(n.children))
+	for _, c := range n.children {
+		if c.name.Space != xsdNamespace {
+			return nil, fmt.Errorf("element '%s' in namespace '%s' is not part of XSD", c.name.Local, c.name.Space)
+		}
+		if c.name.Local != "annotation" {
+			children = append(children, c)
+		}
+	}
+	return children, nil
+}
+
+// resolveQName resolves a QName in a schema attribute, such as type="xs:string", using the namespace declarations in scope
+func resolveQName(n *xmlNode, qname string) (*xml.Name, error) {
+	prefix, local := "", qname
+	if i := strings.Index(qname, ":"); i >= 0 {
+		prefix, local = qname[:i], qname[i+1:]
+	}
+	space, ok := n.prefixes[prefix]
+	if !ok && prefix != "" {
+		return nil, fmt.Errorf("undeclared namespace prefix '%s' in '%s'", prefix, qname)
+	}
+	return &xml.Name{Space: space, Local: local}, nil
+}
+
+func parseOccurs(n *xmlNode) (min int, max int, err error) {
+	min, max = 1, 1
+	if v, ok := n.attr("minOccurs"); ok {
+		if min, err = strconv.Atoi(v); err != nil || min < 0 {
+			return 0, 0, fmt.Errorf("invalid minOccurs '%s'", v)
+		}
+	}
+	if v, ok := n.attr("maxOccurs"); ok {
+		if v == "unbounded" {
+			max = xsdUnbounded
+		} else if max, err = strconv.Atoi(v); err != nil || max < min {
+			return 0, 0, fmt.Errorf("invalid maxOccurs '%s'", v)
+		}
+	}
+	return min, max, nil
+}
+
+func parseXSD(b []byte) (*xsdSchema, error) {
+	root, err := parseXMLTree(b)
+	if err != nil {
+		return nil, err
+	}
+	if root.name.Space != xsdNamespace || root.name.Local != "schema" {
+		return nil, fmt.Errorf("root element must be 'schema' in namespace '%s'", xsdNamespace)
+	}
+	schema := &xsdSchema{
+		elements:     make(map[string]*xsdElement),
+		complexTypes: make(map[string]*xsdComplexType),
+		simpleTypes:  make(map[string]*xsdSimpleType),
+	}
+	children, err := xsdChildren(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		name, _ := c.attr("name")
+		if name == "" {
+			return nil, fmt.Errorf("global '%s' must have a name", c.name.Local)
+		}
+		switch c.name.Local {
+		case "element":
+			if schema.elements[name], err = parseElement(c); err == nil && schema.elements[name].ref != "" {
+				err = fmt.Errorf("global element '%s' cannot have a ref", name)
+			}
+		case "complexType":
+			schema.complexTypes[name], err = parseComplexType(c)
+		case "simpleType":
+			schema.simpleTypes[name], err = parseSimpleType(c)
+		default:
+			err = xsdUnsupported(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(schema.elements) == 0 {
+		return nil, fmt.Errorf("no global elements")
+	}
+	if err := schema.resolve(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func parseElement(n *xmlNode) (e *xsdElement, err error) {
+	e = &xsdElement{}
+	e.name, _ = n.attr("name")
+	e.ref, _ = n.attr("ref")
+	if e.minOccurs, e.maxOccurs, err = parseOccurs(n); err != nil {
+		return nil, err
+	}
+	if e.ref != "" {
+		ref, err := resolveQName(n, e.ref)
+		if err != nil {
+			return nil, err
+		}
+		e.ref = ref.Local
+	} else if e.name == "" {
+		return nil, fmt.Errorf("element must have a name or a ref")
+	}
+	if t, ok := n.attr("type"); ok {
+		if e.typeName, err = resolveQName(n, t); err != nil {
+			return nil, err
+		}
+	}
+	children, err := xsdChildren(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		switch c.name.Local {
+		case "complexType":
+			e.complex, err = parseComplexType(c)
+		case "simpleType":
+			e.simple, err = parseSimpleType(c)
+		default:
+			err = xsdUnsupported(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func parseGroup(n *xmlNode) (g *xsdGroup, err error) {
+	g = &xsdGroup{kind: n.name.Local}
+	if g.minOccurs, g.maxOccurs, err = parseOccurs(n); err != nil {
+		return nil, err
+	}
+	children, err := xsdChildren(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		p := &xsdParticle{}
+		switch {
+		case c.name.Local == "element":
+			p.element, err = parseElement(c)
+			if err == nil && g.kind == "all" && (p.element.maxOccurs == xsdUnbounded || p.element.maxOccurs > 1) {
+				err = fmt.Errorf("elements in an 'all' group can occur at most once")
+			}
+		case (c.name.Local == "sequence" || c.name.Local == "choice") && g.kind != "all":
+			p.group, err = parseGroup(c)
+		default:
+			err = xsdUnsupported(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		g.particles = append(g.particles, p)
+	}
+	return g, nil
+}
+
+func parseAttribute(n *xmlNode) (a *xsdAttribute, err error) {
+	a = &xsdAttribute{}
+	if a.name, _ = n.attr("name"); a.name == "" {
+		return nil, fmt.Errorf("attribute must have a name")
+	}
+	use, _ := n.attr("use")
+	switch use {
+	case "", "optional":
+	case "required":
+		a.required = true
+	default:
+		return nil, fmt.Errorf("unsupported use '%s' of attribute '%s'", use, a.name)
+	}
+	if fixed, ok := n.attr("fixed"); ok {
+		a.fixed = &fixed
+	}
+	if t, ok := n.attr("type"); ok {
+		if a.typeName, err = resolveQName(n, t); err != nil {
+			return nil, err
+		}
+	}
+	children, err := xsdChildren(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		if c.name.Local != "simpleType" {
+			return nil, xsdUnsupported(c)
+		}
+		if a.simple, err = parseSimpleType(c); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func parseComplexType(n *xmlNode) (ct *xsdComplexType, err error) {
+	ct = &xsdComplexType{}
+	if mixed, ok := n.attr("mixed"); ok {
+		ct.mixed = mixed == "true" || mixed == "1"
+	}
+	children, err := xsdChildren(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		switch c.name.Local {
+		case "sequence", "choice", "all":
+			if ct.content != nil || ct.simpleBase != nil {
+				return nil, fmt.Errorf("complex type can only have one content model")
+			}
+			ct.content, err = parseGroup(c)
+		case "attribute":
+			var a *xsdAttribute
+			if a, err = parseAttribute(c); err == nil {
+				ct.attributes = append(ct.attributes, a)
+			}
+		case "simpleContent":
+			if ct.content != nil || ct.simpleBase != nil {
+				return nil, fmt.Errorf("complex type can only have one content model")
+			}
+			err = parseSimpleContent(c, ct)
+		default:
+			err = xsdUnsupported(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ct, nil
+}
+
+func parseSimpleContent(n *xmlNode, ct *xsdComplexType) error {
+	children, err := xsdChildren(n)
+	if err != nil {
+		return err
+	}
+	if len(children) != 1 || children[0].name.Local != "extension" {
+		return fmt.Errorf("simpleContent must contain a single extension")
+	}
+	ext := children[0]
+	base, ok := ext.attr("base")
+	if !ok {
+		return fmt.Errorf("extension must have a base")
+	}
+	if ct.simpleBase, err = resolveQName(ext, base); err != nil {
+		return err
+	}
+	attrs, err := xsdChildren(ext)
+	if err != nil {
+		return err
+	}
+	for _, c := range attrs {
+		if c.name.Local != "attribute" {
+			return xsdUnsupported(c)
+		}
+		a, err := parseAttribute(c)
+		if err != nil {
+			return err
+		}
+		ct.attributes = append(ct.attributes, a)
+	}
+	return nil
+}
+
+func parseFacetInt(n *xmlNode, value string) (*int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return nil, fmt.Errorf("invalid %s '%s'", n.name.Local, value)
+	}
+	return &i, nil
+}
+
+func parseFacetNumber(n *xmlNode, value string) (*big.Float, error) {
+	f, ok := new(big.Float).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s '%s'", n.name.Local, value)
+	}
+	return f, nil
+}
+
+func parseSimpleType(n *xmlNode) (st *xsdSimpleType, err error) {
+	children, err := xsdChildren(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != 1 || children[0].name.Local != "restriction" {
+		return nil, fmt.Errorf("simpleType must contain a single restriction")
+	}
+	r := children[0]
+	base, ok := r.attr("base")
+	if !ok {
+		return nil, fmt.Errorf("restriction must have a base")
+	}
+	st = &xsdSimpleType{}
+	if st.base, err = resolveQName(r, base); err != nil {
+		return nil, err
+	}
+	facets, err := xsdChildren(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range facets {
+		value, ok := f.attr("value")
+		if !ok {
+			return nil, fmt.Errorf("facet '%s' must have a value", f.name.Local)
+		}
+		switch f.name.Local {
+		case "enumeration":
+			st.enumeration = append(st.enumeration, value)
+		case "pattern":
+			// XSD patterns always match the whole value
+			var re *regexp.Regexp
+			if re, err = regexp.Compile(`^(?:` + value + `)$`); err == nil {
+				st.patterns = append(st.patterns, re)
+			}
+		case "length":
+			st.length, err = parseFacetInt(f, value)
+		case "minLength":
+			st.minLength, err = parseFacetInt(f, value)
+		case "maxLength":
+			st.maxLength, err = parseFacetInt(f, value)
+		case "minInclusive":
+			st.minInclusive, err = parseFacetNumber(f, value)
+		case "maxInclusive":
+			st.maxInclusive, err = parseFacetNumber(f, value)
+		case "minExclusive":
+			st.minExclusive, err = parseFacetNumber(f, value)
+		case "maxExclusive":
+			st.maxExclusive, err = parseFacetNumber(f, value)
+		case "whiteSpace":
+			// Whitespace is handled according to the built-in base type
+		default:
+			err = xsdUnsupported(f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// resolve checks every reference in the schema can be resolved, so that a schema with a missing type
+// fails to load instead of failing when a document uses it
+func (s *xsdSchema) resolve() error {
+	for _, st := range s.simpleTypes {
+		if err := s.resolveSimpleType(st, 0); err != nil {
+			return err
+		}
+	}
+	for _, ct := range s.complexTypes {
+		if err := s.resolveComplexType(ct); err != nil {
+			return err
+		}
+	}
+	for _, e := range s.elements {
+		if err := s.resolveElement(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const xsdMaxDerivation = 32
+
+func (s *xsdSchema) resolveSimpleType(st *xsdSimpleType, depth int) error {
+	if depth > xsdMaxDerivation {
+		return fmt.Errorf("simple type derivation is too deep, or circular")
+	}
+	if isXSDBuiltin(st.base) {
+		return nil
+	}
+	base, ok := s.simpleTypes[st.base.Local]
+	if !ok || st.base.Space == xsdNamespace {
+		return fmt.Errorf("unknown simple type '%s'", st.base.Local)
+	}
+	st.baseType = base
+	return s.resolveSimpleType(base, depth+1)
+}
+
+// simpleTypeByName resolves a built-in or named simple type
+func (s *xsdSchema) simpleTypeByName(name *xml.Name) (*xsdSimpleType, bool) {
+	if isXSDBuiltin(name) {
+		return &xsdSimpleType{base: name}, true
+	}
+	if name.Space == xsdNamespace {
+		return nil, false
+	}
+	st, ok := s.simpleTypes[name.Local]
+	return st, ok
+}
+
+func (s *xsdSchema) resolveAttributes(attrs []*xsdAttribute) error {
+	for _, a := range attrs {
+		switch {
+		case a.simple != nil:
+			if err := s.resolveSimpleType(a.simple, 0); err != nil {
+				return err
+			}
+		case a.typeName != nil:
+			st, ok := s.simpleTypeByName(a.typeName)
+			if !ok {
+				return fmt.Errorf("unknown simple type '%s' of attribute '%s'", a.typeName.Local, a.name)
+			}
+			a.simple = st
+		default:
+			a.simple = &xsdSimpleType{base: &xml.Name{Space: xsdNamespace, Local: "anySimpleType"}}
+		}
+	}
+	return nil
+}
+
+func (s *xsdSchema) resolveComplexType(ct *xsdComplexType) error {
+	if ct.simpleBase != nil {
+		if _, ok := s.simpleTypeByName(ct.simpleBase); !ok {
+			return fmt.Errorf("unknown simple type '%s'", ct.simpleBase.Local)
+		}
+	}
+	if err := s.resolveAttributes(ct.attributes); err != nil {
+		return err
+	}
+	if ct.content != nil {
+		return s.resolveGroup(ct.content, make(map[string]*xsdElement))
+	}
+	return nil
+}
+
+// resolveGroup resolves the elements of a content model. Elements with the same name in a content model must have
+// the same type in XSD, so each child of a document can be validated against the declaration with its name.
+func (s *xsdSchema) resolveGroup(g *xsdGroup, names map[string]*xsdElement) error {
+	for _, p := range g.particles {
+		if p.group != nil {
+			if err := s.resolveGroup(p.group, names); err != nil {
+				return err
+			}
+			continue
+		}
+		e := p.element
+		if e.ref != "" {
+			global, ok := s.elements[e.ref]
+			if !ok {
+				return fmt.Errorf("unknown element '%s'", e.ref)
+			}
+			e.name, e.typeName, e.complex, e.simple = global.name, global.typeName, global.complex, global.simple
+		} else if err := s.resolveElement(e); err != nil {
+			return err
+		}
+		if existing, ok := names[e.name]; ok && (existing.complex != e.complex || existing.simple != e.simple || !sameTypeName(existing.typeName, e.typeName)) {
+			return fmt.Errorf("element '%s' is declared with different types in the same content model", e.name)
+		}
+		names[e.name] = e
+	}
+	return nil
+}
+
+func sameTypeName(a, b *xml.Name) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func (s *xsdSchema) resolveElement(e *xsdElement) error {
+	switch {
+	case e.complex != nil:
+		return s.resolveComplexType(e.complex)
+	case e.simple != nil:
+		return s.resolveSimpleType(e.simple, 0)
+	case e.typeName != nil:
+		if e.typeName.Space == xsdNamespace && e.typeName.Local == "anyType" {
+			return nil
+		}
+		if _, ok := s.simpleTypeByName(e.typeName); ok {
+			return nil
+		}
+		if _, ok := s.complexTypes[e.typeName.Local]; !ok || e.typeName.Space == xsdNamespace {
+			return fmt.Errorf("unknown type '%s' of element '%s'", e.typeName.Local, e.name)
+		}
+	}
+	return nil
+}
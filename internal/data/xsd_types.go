@@ -0,0 +1,272 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	xsdDecimalRegex  = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	xsdIntegerRegex  = regexp.MustCompile(`^[+-]?\d+$`)
+	xsdDateRegex     = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(Z|[+-]\d{2}:\d{2})?$`)
+	xsdDateTimeRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})T(\d{2}:\d{2}:\d{2})(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`)
+	xsdTimeRegex     = regexp.MustCompile(`^(\d{2}:\d{2}:\d{2})(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`)
+	xsdNameRegex     = regexp.MustCompile(`^[\pL_:][\pL\pN._:-]*$`)
+	xsdNCNameRegex   = regexp.MustCompile(`^[\pL_][\pL\pN._-]*$`)
+	xsdNMTokenRegex  = regexp.MustCompile(`^[\pL\pN._:-]+$`)
+	xsdLanguageRegex = regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`)
+)
+
+// xsdIntegerRange is the range of a built-in integer type, with nil for no limit
+type xsdIntegerRange struct {
+	min *big.Int
+	max *big.Int
+}
+
+func xsdBigInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 10)
+	return i
+}
+
+var xsdIntegerTypes = map[string]xsdIntegerRange{
+	"integer":            {},
+	"long":               {xsdBigInt("-9223372036854775808"), xsdBigInt("9223372036854775807")},
+	"int":                {xsdBigInt("-2147483648"), xsdBigInt("2147483647")},
+	"short":              {xsdBigInt("-32768"), xsdBigInt("32767")},
+	"byte":               {xsdBigInt("-128"), xsdBigInt("127")},
+	"nonNegativeInteger": {xsdBigInt("0"), nil},
+	"positiveInteger":    {xsdBigInt("1"), nil},
+	"nonPositiveInteger": {nil, xsdBigInt("0")},
+	"negativeInteger":    {nil, xsdBigInt("-1")},
+	"unsignedLong":       {xsdBigInt("0"), xsdBigInt("18446744073709551615")},
+	"unsignedInt":        {xsdBigInt("0"), xsdBigInt("4294967295")},
+	"unsignedShort":      {xsdBigInt("0"), xsdBigInt("65535")},
+	"unsignedByte":       {xsdBigInt("0"), xsdBigInt("255")},
+}
+
+// xsdStringTypes are the built-in types that are strings, with any constraint on their lexical form
+var xsdStringTypes = map[string]*regexp.Regexp{
+	"string":           nil,
+	"normalizedString": nil,
+	"token":            nil,
+	"anyURI":           nil,
+	"anySimpleType":    nil,
+	"language":         xsdLanguageRegex,
+	"Name":             xsdNameRegex,
+	"NCName":           xsdNCNameRegex,
+	"ID":               xsdNCNameRegex,
+	"IDREF":            xsdNCNameRegex,
+	"NMTOKEN":          xsdNMTokenRegex,
+}
+
+var xsdOtherTypes = map[string]bool{
+	"boolean":      true,
+	"decimal":      true,
+	"float":        true,
+	"double":       true,
+	"date":         true,
+	"dateTime":     true,
+	"time":         true,
+	"base64Binary": true,
+	"hexBinary":    true,
+}
+
+func isXSDBuiltin(name *xml.Name) bool {
+	if name.Space != xsdNamespace {
+		return false
+	}
+	_, isInteger := xsdIntegerTypes[name.Local]
+	_, isString := xsdStringTypes[name.Local]
+	return isInteger || isString || xsdOtherTypes[name.Local]
+}
+
+// xsdWhitespace applies the whitespace handling of a built-in type to a value
+func xsdWhitespace(builtin, value string) string {
+	switch builtin {
+	case "string", "anySimpleType":
+		return value
+	case "normalizedString":
+		return strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, value)
+	default:
+		return strings.Join(strings.Fields(value), " ")
+	}
+}
+
+func checkXSDTime(layout, value string) error {
+	if _, err := time.Parse(layout, value); err != nil {
+		return fmt.Errorf("'%s' is out of range", value)
+	}
+	return nil
+}
+
+// checkXSDBuiltin checks a value is in the lexical space of a built-in type, returning its numeric value for the
+// numeric types, for the range facets
+func checkXSDBuiltin(builtin, value string) (number *big.Float, err error) {
+	if r, ok := xsdIntegerTypes[builtin]; ok {
+		if !xsdIntegerRegex.MatchString(value) {
+			return nil, fmt.Errorf("'%s' is not a valid %s", value, builtin)
+		}
+		i := xsdBigInt(strings.TrimPrefix(value, "+"))
+		if (r.min != nil && i.Cmp(r.min) < 0) || (r.max != nil && i.Cmp(r.max) > 0) {
+			return nil, fmt.Errorf("'%s' is out of range for %s", value, builtin)
+		}
+		return new(big.Float).SetInt(i), nil
+	}
+	if re, ok := xsdStringTypes[builtin]; ok {
+		if re != nil && !re.MatchString(value) {
+			return nil, fmt.Errorf("'%s' is not a valid %s", value, builtin)
+		}
+		return nil, nil
+	}
+	switch builtin {
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return nil, fmt.Errorf("'%s' is not a valid boolean", value)
+		}
+	case "decimal":
+		if !xsdDecimalRegex.MatchString(value) {
+			return nil, fmt.Errorf("'%s' is not a valid decimal", value)
+		}
+		number, _ = new(big.Float).SetString(value)
+	case "float", "double":
+		switch value {
+		case "INF", "-INF", "NaN":
+			return nil, nil
+		}
+		bitSize := 64
+		if builtin == "float" {
+			bitSize = 32
+		}
+		f, err := strconv.ParseFloat(value, bitSize)
+		if err != nil || strings.ContainsAny(value, "xXpP_") || strings.EqualFold(strings.TrimLeft(value, "+-"), "inf") || strings.EqualFold(strings.TrimLeft(value, "+-"), "infinity") {
+			return nil, fmt.Errorf("'%s' is not a valid %s", value, builtin)
+		}
+		number = big.NewFloat(f)
+	case "date":
+		m := xsdDateRegex.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("'%s' is not a valid date", value)
+		}
+		return nil, checkXSDTime("2006-01-02", m[1])
+	case "dateTime":
+		m := xsdDateTimeRegex.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("'%s' is not a valid dateTime", value)
+		}
+		return nil, checkXSDTime("2006-01-02T15:04:05", m[1]+"T"+m[2])
+	case "time":
+		m := xsdTimeRegex.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("'%s' is not a valid time", value)
+		}
+		return nil, checkXSDTime("15:04:05", m[1])
+	case "base64Binary":
+		if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid base64Binary", value)
+		}
+	case "hexBinary":
+		if _, err := hex.DecodeString(value); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid hexBinary", value)
+		}
+	}
+	return number, nil
+}
+
+// builtin returns the built-in type a simple type is ultimately derived from
+func (st *xsdSimpleType) builtin() string {
+	for st.baseType != nil {
+		st = st.baseType
+	}
+	return st.base.Local
+}
+
+// validate checks a value against a simple type, and all the types it is derived from
+func (st *xsdSimpleType) validate(value string) error {
+	value = xsdWhitespace(st.builtin(), value)
+	var number *big.Float
+	var err error
+	if st.baseType != nil {
+		err = st.baseType.validate(value)
+		if err == nil {
+			number, _ = checkXSDBuiltin(st.builtin(), value)
+		}
+	} else {
+		number, err = checkXSDBuiltin(st.base.Local, value)
+	}
+	if err != nil {
+		return err
+	}
+	return st.checkFacets(value, number)
+}
+
+func (st *xsdSimpleType) checkFacets(value string, number *big.Float) error {
+	if len(st.enumeration) > 0 {
+		found := false
+		for _, e := range st.enumeration {
+			found = found || e == value
+		}
+		if !found {
+			return fmt.Errorf("'%s' is not one of the allowed values %v", value, st.enumeration)
+		}
+	}
+	for _, re := range st.patterns {
+		if !re.MatchString(value) {
+			return fmt.Errorf("'%s' does not match the pattern '%s'", value, re.String())
+		}
+	}
+	length := utf8.RuneCountInString(value)
+	switch {
+	case st.length != nil && length != *st.length:
+		return fmt.Errorf("'%s' must have a length of %d", value, *st.length)
+	case st.minLength != nil && length < *st.minLength:
+		return fmt.Errorf("'%s' must have a length of at least %d", value, *st.minLength)
+	case st.maxLength != nil && length > *st.maxLength:
+		return fmt.Errorf("'%s' must have a length of at most %d", value, *st.maxLength)
+	}
+	if st.minInclusive == nil && st.maxInclusive == nil && st.minExclusive == nil && st.maxExclusive == nil {
+		return nil
+	}
+	if number == nil {
+		return fmt.Errorf("'%s' is not a number, so cannot be checked against a range", value)
+	}
+	switch {
+	case st.minInclusive != nil && number.Cmp(st.minInclusive) < 0:
+		return fmt.Errorf("'%s' must be at least %s", value, st.minInclusive.String())
+	case st.maxInclusive != nil && number.Cmp(st.maxInclusive) > 0:
+		return fmt.Errorf("'%s' must be at most %s", value, st.maxInclusive.String())
+	case st.minExclusive != nil && number.Cmp(st.minExclusive) <= 0:
+		return fmt.Errorf("'%s' must be greater than %s", value, st.minExclusive.String())
+	case st.maxExclusive != nil && number.Cmp(st.maxExclusive) >= 0:
+		return fmt.Errorf("'%s' must be less than %s", value, st.maxExclusive.String())
+	}
+	return nil
+}
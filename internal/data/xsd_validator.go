@@ -0,0 +1,348 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// xsdValidator validates the original payload of XML data against an XML Schema (XSD), supplied as the
+// value of the datatype in a JSON string
+type xsdValidator struct {
+	id       *fftypes.UUID
+	size     int64
+	ns       string
+	datatype *fftypes.DatatypeRef
+	schema   *xsdSchema
+}
+
+func newXSDValidator(ctx context.Context, ns string, datatype *fftypes.Datatype) (*xsdValidator, error) {
+	xv := &xsdValidator{
+		id: datatype.ID,
+		ns: ns,
+		datatype: &fftypes.DatatypeRef{
+			Name:    datatype.Name,
+			Version: datatype.Version,
+		},
+	}
+
+	var xsd string
+	if datatype.Value == nil || json.Unmarshal(datatype.Value.Bytes(), &xsd) != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgXSDNotString, xv.datatype)
+	}
+	schema, err := parseXSD([]byte(xsd))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgSchemaLoadFailed, xv.datatype)
+	}
+	xv.schema = schema
+	xv.size = int64(len(xsd))
+
+	log.L(ctx).Debugf("Found XSD validator for xsd:%s:%s: %v", xv.ns, datatype, xv.id)
+	return xv, nil
+}
+
+func (xv *xsdValidator) Validate(ctx context.Context, data *fftypes.Data) error {
+	if data.MediaType != fftypes.DataMediaTypeXML {
+		return i18n.NewError(ctx, i18n.MsgXSDRequiresXML, xv.datatype, fftypes.DataMediaTypeXML)
+	}
+	return xv.validateXML(ctx, data.Original)
+}
+
+// ValidateValue cannot validate a JSON value, as the XSD applies to the original XML
+func (xv *xsdValidator) ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
+	return i18n.NewError(ctx, i18n.MsgXSDRequiresXML, xv.datatype, fftypes.DataMediaTypeXML)
+}
+
+func (xv *xsdValidator) validateXML(ctx context.Context, original []byte) error {
+	doc, err := parseXMLTree(original)
+	if err == nil {
+		err = xv.schema.validateDocument(doc)
+	}
+	if err != nil {
+		log.L(ctx).Warnf("XSD %s [%v] validation failed: %s", xv.datatype, xv.id, err)
+		return i18n.NewError(ctx, i18n.MsgXMLDataInvalidPerSchema, xv.datatype, err)
+	}
+	return nil
+}
+
+func (xv *xsdValidator) Size() int64 {
+	return xv.size
+}
+
+func (s *xsdSchema) validateDocument(doc *xmlNode) error {
+	decl, ok := s.elements[doc.name.Local]
+	if !ok {
+		return fmt.Errorf("root element '%s' is not declared in the schema", doc.name.Local)
+	}
+	return s.validateElement(doc, decl, "/"+doc.name.Local)
+}
+
+func (s *xsdSchema) validateElement(n *xmlNode, decl *xsdElement, path string) error {
+	switch {
+	case decl.complex != nil:
+		return s.validateComplex(n, decl.complex, path)
+	case decl.simple != nil:
+		return s.validateSimple(n, decl.simple, path)
+	case decl.typeName == nil || (decl.typeName.Space == xsdNamespace && decl.typeName.Local == "anyType"):
+		return nil
+	}
+	if st, ok := s.simpleTypeByName(decl.typeName); ok {
+		return s.validateSimple(n, st, path)
+	}
+	return s.validateComplex(n, s.complexTypes[decl.typeName.Local], path)
+}
+
+// documentAttrs returns the attributes of an element that are subject to validation, excluding those from the
+// XML Schema instance namespace, such as xsi:schemaLocation
+func documentAttrs(n *xmlNode) map[string]string {
+	attrs := make(map[string]string, len(n.attrs))
+	for _, a := range n.attrs {
+		if a.Name.Space != xsiNamespace {
+			attrs[a.Name.Local] = a.Value
+		}
+	}
+	return attrs
+}
+
+func (s *xsdSchema) validateSimple(n *xmlNode, st *xsdSimpleType, path string) error {
+	if attrs := sortedKeys(documentAttrs(n)); len(attrs) > 0 {
+		return fmt.Errorf("%s: attribute '%s' is not allowed, as the element has a simple type", path, attrs[0])
+	}
+	return s.validateText(n, st, path)
+}
+
+// validateText checks the content of an element with a simple type, or simple content
+func (s *xsdSchema) validateText(n *xmlNode, st *xsdSimpleType, path string) error {
+	if len(n.children) > 0 {
+		return fmt.Errorf("%s: element '%s' is not allowed, as the element has a simple type", path, n.children[0].name.Local)
+	}
+	if err := st.validate(n.text.String()); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	return nil
+}
+
+func (s *xsdSchema) validateAttributes(n *xmlNode, ct *xsdComplexType, path string) error {
+	attrs := documentAttrs(n)
+	for _, a := range ct.attributes {
+		value, ok := attrs[a.name]
+		if !ok {
+			if a.required {
+				return fmt.Errorf("%s: attribute '%s' is required", path, a.name)
+			}
+			continue
+		}
+		delete(attrs, a.name)
+		if a.fixed != nil && xsdWhitespace(a.simple.builtin(), value) != *a.fixed {
+			return fmt.Errorf("%s/@%s: must be '%s'", path, a.name, *a.fixed)
+		}
+		if err := a.simple.validate(value); err != nil {
+			return fmt.Errorf("%s/@%s: %s", path, a.name, err)
+		}
+	}
+	if undeclared := sortedKeys(attrs); len(undeclared) > 0 {
+		return fmt.Errorf("%s: attribute '%s' is not declared", path, undeclared[0])
+	}
+	return nil
+}
+
+func (s *xsdSchema) validateComplex(n *xmlNode, ct *xsdComplexType, path string) error {
+	if err := s.validateAttributes(n, ct, path); err != nil {
+		return err
+	}
+	if ct.simpleBase != nil {
+		st, _ := s.simpleTypeByName(ct.simpleBase)
+		return s.validateText(n, st, path)
+	}
+	if !ct.mixed && strings.TrimSpace(n.text.String()) != "" {
+		return fmt.Errorf("%s: text is not allowed, as the element only has child elements", path)
+	}
+	if ct.content == nil {
+		if len(n.children) > 0 {
+			return fmt.Errorf("%s: element '%s' is not allowed, as the element is empty", path, n.children[0].name.Local)
+		}
+		return nil
+	}
+
+	decls := make(map[string]*xsdElement)
+	collectElements(ct.content, decls)
+	for _, c := range n.children {
+		if _, ok := decls[c.name.Local]; !ok {
+			return fmt.Errorf("%s: element '%s' is not allowed", path, c.name.Local)
+		}
+	}
+	if !matchesGroup(ct.content, n.children) {
+		names := make([]string, len(n.children))
+		for i, c := range n.children {
+			names[i] = c.name.Local
+		}
+		return fmt.Errorf("%s: the elements [%s] do not match the %s in the schema", path, strings.Join(names, ","), describeGroup(ct.content))
+	}
+	counts := make(map[string]int)
+	for _, c := range n.children {
+		counts[c.name.Local]++
+		if err := s.validateElement(c, decls[c.name.Local], fmt.Sprintf("%s/%s[%d]", path, c.name.Local, counts[c.name.Local])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectElements(g *xsdGroup, decls map[string]*xsdElement) {
+	for _, p := range g.particles {
+		if p.group != nil {
+			collectElements(p.group, decls)
+		} else {
+			decls[p.element.name] = p.element
+		}
+	}
+}
+
+func describeGroup(g *xsdGroup) string {
+	parts := make([]string, len(g.particles))
+	for i, p := range g.particles {
+		if p.group != nil {
+			parts[i] = describeGroup(p.group)
+		} else {
+			parts[i] = p.element.name
+		}
+	}
+	return fmt.Sprintf("%s(%s)", g.kind, strings.Join(parts, ","))
+}
+
+// positions is a set of positions in the children of an element, that a part of a content model can end at
+type positions map[int]bool
+
+func matchesGroup(g *xsdGroup, children []*xmlNode) bool {
+	return matchRepeated(positions{0: true}, g.minOccurs, g.maxOccurs, children, func(start int) positions {
+		return matchGroupOnce(g, children, start)
+	})[len(children)]
+}
+
+// matchRepeated matches a particle between min and max times, starting from each of a set of positions
+func matchRepeated(starts positions, min, max int, children []*xmlNode, once func(start int) positions) positions {
+	result := positions{}
+	if min == 0 {
+		for p := range starts {
+			result[p] = true
+		}
+	}
+	current := starts
+	for count := 1; (max == xsdUnbounded || count <= max) && len(current) > 0; count++ {
+		next := positions{}
+		for start := range current {
+			for end := range once(start) {
+				// A repetition that consumes nothing cannot lead anywhere new
+				if end > start || count <= min {
+					next[end] = true
+				}
+			}
+		}
+		if count >= min {
+			for p := range next {
+				result[p] = true
+			}
+		}
+		current = next
+	}
+	return result
+}
+
+func matchParticle(p *xsdParticle, children []*xmlNode, start int) positions {
+	if p.group != nil {
+		return matchRepeated(positions{start: true}, p.group.minOccurs, p.group.maxOccurs, children, func(start int) positions {
+			return matchGroupOnce(p.group, children, start)
+		})
+	}
+	return matchRepeated(positions{start: true}, p.element.minOccurs, p.element.maxOccurs, children, func(start int) positions {
+		if start < len(children) && children[start].name.Local == p.element.name {
+			return positions{start + 1: true}
+		}
+		return positions{}
+	})
+}
+
+func matchGroupOnce(g *xsdGroup, children []*xmlNode, start int) positions {
+	switch g.kind {
+	case "sequence":
+		current := positions{start: true}
+		for _, p := range g.particles {
+			next := positions{}
+			for s := range current {
+				for e := range matchParticle(p, children, s) {
+					next[e] = true
+				}
+			}
+			current = next
+		}
+		return current
+	case "choice":
+		result := positions{}
+		for _, p := range g.particles {
+			for e := range matchParticle(p, children, start) {
+				result[e] = true
+			}
+		}
+		return result
+	default:
+		return matchAll(g, children, start)
+	}
+}
+
+// matchAll matches an all group, where each element occurs at most once, in any order
+func matchAll(g *xsdGroup, children []*xmlNode, start int) positions {
+	seen := make(map[string]bool)
+	end := start
+	for end < len(children) {
+		name := children[end].name.Local
+		found := false
+		for _, p := range g.particles {
+			found = found || p.element.name == name
+		}
+		if !found || seen[name] {
+			break
+		}
+		seen[name] = true
+		end++
+	}
+	for _, p := range g.particles {
+		if p.element.minOccurs > 0 && !seen[p.element.name] {
+			return positions{}
+		}
+	}
+	return positions{end: true}
+}
+
+// sortedKeys is used to give a stable order to errors that report one of a set of names
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,457 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPurchaseOrderXSD = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:po="urn:po" targetNamespace="urn:po">
+	<xs:annotation><xs:documentation>Purchase order</xs:documentation></xs:annotation>
+	<xs:element name="purchaseOrder" type="po:PurchaseOrderType"/>
+	<xs:element name="comment" type="xs:string"/>
+	<xs:complexType name="PurchaseOrderType">
+		<xs:sequence>
+			<xs:element name="shipTo" type="po:Address"/>
+			<xs:element ref="po:comment" minOccurs="0"/>
+			<xs:element name="items">
+				<xs:complexType>
+					<xs:sequence>
+						<xs:element name="item" minOccurs="1" maxOccurs="unbounded">
+							<xs:complexType>
+								<xs:sequence>
+									<xs:element name="productName" type="xs:string"/>
+									<xs:element name="quantity">
+										<xs:simpleType>
+											<xs:restriction base="xs:positiveInteger">
+												<xs:maxExclusive value="100"/>
+											</xs:restriction>
+										</xs:simpleType>
+									</xs:element>
+									<xs:element name="price" type="po:Price"/>
+									<xs:element name="shipDate" type="xs:date" minOccurs="0"/>
+								</xs:sequence>
+								<xs:attribute name="partNum" type="po:SKU" use="required"/>
+							</xs:complexType>
+						</xs:element>
+					</xs:sequence>
+				</xs:complexType>
+			</xs:element>
+		</xs:sequence>
+		<xs:attribute name="orderDate" type="xs:date"/>
+		<xs:attribute name="version" type="xs:string" fixed="1.0"/>
+	</xs:complexType>
+	<xs:complexType name="Address">
+		<xs:all>
+			<xs:element name="name" type="xs:string"/>
+			<xs:element name="street" type="xs:string"/>
+			<xs:element name="zip" type="xs:decimal" minOccurs="0"/>
+		</xs:all>
+		<xs:attribute name="country" type="xs:NMTOKEN" fixed="US"/>
+	</xs:complexType>
+	<xs:complexType name="Price">
+		<xs:simpleContent>
+			<xs:extension base="po:Amount">
+				<xs:attribute name="currency" use="required">
+					<xs:simpleType>
+						<xs:restriction base="xs:string">
+							<xs:enumeration value="USD"/>
+							<xs:enumeration value="EUR"/>
+						</xs:restriction>
+					</xs:simpleType>
+				</xs:attribute>
+			</xs:extension>
+		</xs:simpleContent>
+	</xs:complexType>
+	<xs:simpleType name="Amount">
+		<xs:restriction base="xs:decimal">
+			<xs:minInclusive value="0"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="SKU">
+		<xs:restriction base="xs:string">
+			<xs:pattern value="\d{3}-[A-Z]{2}"/>
+		</xs:restriction>
+	</xs:simpleType>
+</xs:schema>`
+
+const testPurchaseOrder = `<?xml version="1.0"?>
+<purchaseOrder xmlns="urn:po" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="urn:po po.xsd" orderDate="1999-10-20">
+	<shipTo country="US">
+		<street>123 Maple Street</street>
+		<name>Alice Smith</name>
+	</shipTo>
+	<comment>Hurry, my lawn is going wild</comment>
+	<items>
+		<item partNum="872-AA">
+			<productName>Lawnmower</productName>
+			<quantity>1</quantity>
+			<price currency="USD">148.95</price>
+		</item>
+		<item partNum="926-AA">
+			<productName>Baby Monitor</productName>
+			<quantity> 1 </quantity>
+			<price currency="EUR">39.98</price>
+			<shipDate>1999-05-21</shipDate>
+		</item>
+	</items>
+</purchaseOrder>`
+
+func newTestXSDDatatype(xsd string) *fftypes.Datatype {
+	b, _ := json.Marshal(xsd)
+	return &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeXSD,
+		Name:      "purchaseOrder",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtrBytes(b),
+	}
+}
+
+func newTestXSDValidator(t *testing.T, xsd string) *xsdValidator {
+	xv, err := newXSDValidator(context.Background(), "ns1", newTestXSDDatatype(xsd))
+	assert.NoError(t, err)
+	return xv
+}
+
+func TestXSDValidatorPurchaseOrder(t *testing.T) {
+	xv := newTestXSDValidator(t, testPurchaseOrderXSD)
+	assert.Equal(t, int64(len(testPurchaseOrderXSD)), xv.Size())
+
+	err := xv.Validate(context.Background(), &fftypes.Data{
+		MediaType: fftypes.DataMediaTypeXML,
+		Original:  []byte(testPurchaseOrder),
+	})
+	assert.NoError(t, err)
+}
+
+func TestXSDValidatorPurchaseOrderInvalid(t *testing.T) {
+	xv := newTestXSDValidator(t, testPurchaseOrderXSD)
+
+	tests := []struct {
+		name     string
+		original string
+		err      string
+	}{
+		{"root", `<order/>`, "root element 'order' is not declared"},
+		{"malformed", `<purchaseOrder>`, "EOF"},
+		{"missingRequired", `<purchaseOrder><shipTo><name/><street/></shipTo></purchaseOrder>`, `the elements \[shipTo\] do not match the sequence\(shipTo,comment,items\)`},
+		{"undeclaredChild", `<purchaseOrder><billTo/></purchaseOrder>`, "/purchaseOrder: element 'billTo' is not allowed"},
+		{"undeclaredAttr", `<purchaseOrder status="new"/>`, "/purchaseOrder: attribute 'status' is not declared"},
+		{"fixedAttr", `<purchaseOrder version="2.0"/>`, "/purchaseOrder/@version: must be '1.0'"},
+		{"attrType", `<purchaseOrder orderDate="yesterday"/>`, "/purchaseOrder/@orderDate: 'yesterday' is not a valid date"},
+		{"text", `<purchaseOrder>hello</purchaseOrder>`, "/purchaseOrder: text is not allowed"},
+		{"allMissing", `<purchaseOrder><shipTo><name/></shipTo><items/></purchaseOrder>`, `/purchaseOrder/shipTo\[1\]: the elements \[name\] do not match the all\(name,street,zip\)`},
+		{"allRepeated", `<purchaseOrder><shipTo><name/><street/><name/></shipTo><items/></purchaseOrder>`, "do not match the all"},
+		{"allDecimal", `<purchaseOrder><shipTo><name/><street/><zip>ABC</zip></shipTo><items><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="USD">1</price></item></items></purchaseOrder>`, `/purchaseOrder/shipTo\[1\]/zip\[1\]: 'ABC' is not a valid decimal`},
+		{"noItems", `<purchaseOrder><shipTo><name/><street/></shipTo><items/></purchaseOrder>`, `/purchaseOrder/items\[1\]: the elements \[\] do not match`},
+		{"pattern", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-aa"/></items></purchaseOrder>`, `/purchaseOrder/items\[1\]/item\[1\]/@partNum: '872-aa' does not match the pattern`},
+		{"requiredAttr", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item/></items></purchaseOrder>`, `/purchaseOrder/items\[1\]/item\[1\]: attribute 'partNum' is required`},
+		{"range", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>100</quantity><price currency="USD">1</price></item></items></purchaseOrder>`, `item\[1\]/quantity\[1\]: '100' must be less than 100`},
+		{"builtinRange", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>0</quantity><price currency="USD">1</price></item></items></purchaseOrder>`, `'0' is out of range for positiveInteger`},
+		{"enum", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="GBP">1</price></item></items></purchaseOrder>`, `price\[1\]/@currency: 'GBP' is not one of the allowed values`},
+		{"simpleContent", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="USD">-1</price></item></items></purchaseOrder>`, `price\[1\]: '-1' must be at least 0`},
+		{"simpleContentChild", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="USD"><amount/></price></item></items></purchaseOrder>`, `price\[1\]: element 'amount' is not allowed, as the element has a simple type`},
+		{"simpleAttr", `<purchaseOrder><shipTo><name/><street/></shipTo><comment lang="en"/><items/></purchaseOrder>`, `comment\[1\]: attribute 'lang' is not allowed, as the element has a simple type`},
+		{"secondItem", `<purchaseOrder><shipTo><name/><street/></shipTo><items><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="USD">1</price></item><item partNum="872-AA"><productName/><quantity>1</quantity><price currency="USD">1</price><shipDate>1999-02-30</shipDate></item></items></purchaseOrder>`, `item\[2\]/shipDate\[1\]: '1999-02-30' is out of range`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := xv.Validate(context.Background(), &fftypes.Data{
+				MediaType: fftypes.DataMediaTypeXML,
+				Original:  []byte(test.original),
+			})
+			assert.Regexp(t, "FF10575.*"+test.err, err)
+		})
+	}
+}
+
+func TestXSDValidatorNotXML(t *testing.T) {
+	xv := newTestXSDValidator(t, testPurchaseOrderXSD)
+	err := xv.Validate(context.Background(), &fftypes.Data{
+		Value: fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.Regexp(t, "FF10574", err)
+	err = xv.ValidateValue(context.Background(), fftypes.JSONAnyPtr(`{}`), nil)
+	assert.Regexp(t, "FF10574", err)
+}
+
+func TestXSDValidatorNotString(t *testing.T) {
+	_, err := newXSDValidator(context.Background(), "ns1", &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeXSD,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.Regexp(t, "FF10573", err)
+
+	_, err = newXSDValidator(context.Background(), "ns1", &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeXSD,
+		Name:      "customer",
+		Version:   "0.0.1",
+	})
+	assert.Regexp(t, "FF10573", err)
+}
+
+func xsdSchemaWith(body string) string {
+	return `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">` + body + `</xs:schema>`
+}
+
+func TestXSDValidatorLoadFail(t *testing.T) {
+	tests := []struct {
+		name string
+		xsd  string
+		err  string
+	}{
+		{"malformed", `<xs:schema`, "EOF"},
+		{"notSchema", `<schema/>`, "root element must be 'schema'"},
+		{"multipleRoots", `<a/><b/>`, "multiple root elements"},
+		{"empty", ` `, "no root element"},
+		{"noElements", xsdSchemaWith(`<xs:simpleType name="a"><xs:restriction base="xs:string"/></xs:simpleType>`), "no global elements"},
+		{"foreignChild", xsdSchemaWith(`<element name="a"/>`), "element 'element' in namespace '' is not part of XSD"},
+		{"globalNoName", xsdSchemaWith(`<xs:element type="xs:string"/>`), "global 'element' must have a name"},
+		{"globalRef", xsdSchemaWith(`<xs:element name="a" ref="b"/>`), "global element 'a' cannot have a ref"},
+		{"import", xsdSchemaWith(`<xs:import name="a"/>`), "unsupported XSD construct 'import'"},
+		{"elementNoName", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element/></xs:sequence></xs:complexType></xs:element>`), "element must have a name or a ref"},
+		{"elementBadRef", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element ref="x:b"/></xs:sequence></xs:complexType></xs:element>`), "undeclared namespace prefix 'x' in 'x:b'"},
+		{"elementBadType", xsdSchemaWith(`<xs:element name="a" type="x:b"/>`), "undeclared namespace prefix 'x'"},
+		{"elementUnknownRef", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element ref="b"/></xs:sequence></xs:complexType></xs:element>`), "unknown element 'b'"},
+		{"elementUnknownType", xsdSchemaWith(`<xs:element name="a" type="b"/>`), "unknown type 'b' of element 'a'"},
+		{"elementUnknownBuiltin", xsdSchemaWith(`<xs:element name="a" type="xs:duration"/>`), "unknown type 'duration' of element 'a'"},
+		{"elementKey", xsdSchemaWith(`<xs:element name="a"><xs:key name="k"/></xs:element>`), "unsupported XSD construct 'key'"},
+		{"elementComplexFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:any/></xs:complexType></xs:element>`), "unsupported XSD construct 'any'"},
+		{"elementChildFail", xsdSchemaWith(`<xs:element name="a"><element/></xs:element>`), "not part of XSD"},
+		{"minOccurs", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" minOccurs="-1"/></xs:sequence></xs:complexType></xs:element>`), "invalid minOccurs '-1'"},
+		{"maxOccurs", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element name="b" minOccurs="2" maxOccurs="1"/></xs:sequence></xs:complexType></xs:element>`), "invalid maxOccurs '1'"},
+		{"groupOccurs", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence maxOccurs="x"/></xs:complexType></xs:element>`), "invalid maxOccurs 'x'"},
+		{"groupChildFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><element/></xs:sequence></xs:complexType></xs:element>`), "not part of XSD"},
+		{"groupElementFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:element/></xs:sequence></xs:complexType></xs:element>`), "element must have a name or a ref"},
+		{"allMax", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:all><xs:element name="b" maxOccurs="2"/></xs:all></xs:complexType></xs:element>`), "elements in an 'all' group can occur at most once"},
+		{"allNested", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:all><xs:sequence/></xs:all></xs:complexType></xs:element>`), "unsupported XSD construct 'sequence'"},
+		{"groupRef", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:group ref="g"/></xs:sequence></xs:complexType></xs:element>`), "unsupported XSD construct 'group'"},
+		{"twoModels", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence/><xs:choice/></xs:complexType></xs:element>`), "complex type can only have one content model"},
+		{"twoModelsSimple", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence/><xs:simpleContent/></xs:complexType></xs:element>`), "complex type can only have one content model"},
+		{"complexChildFail", xsdSchemaWith(`<xs:complexType name="t"><element/></xs:complexType><xs:element name="a"/>`), "not part of XSD"},
+		{"attrNoName", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute/></xs:complexType></xs:element>`), "attribute must have a name"},
+		{"attrUse", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b" use="prohibited"/></xs:complexType></xs:element>`), "unsupported use 'prohibited' of attribute 'b'"},
+		{"attrType", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b" type="x:c"/></xs:complexType></xs:element>`), "undeclared namespace prefix 'x'"},
+		{"attrUnknownType", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b" type="c"/></xs:complexType></xs:element>`), "unknown simple type 'c' of attribute 'b'"},
+		{"attrChild", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b"><xs:complexType/></xs:attribute></xs:complexType></xs:element>`), "unsupported XSD construct 'complexType'"},
+		{"attrChildFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b"><simpleType/></xs:attribute></xs:complexType></xs:element>`), "not part of XSD"},
+		{"attrSimpleFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b"><xs:simpleType/></xs:attribute></xs:complexType></xs:element>`), "simpleType must contain a single restriction"},
+		{"attrSimpleUnknown", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:attribute name="b"><xs:simpleType><xs:restriction base="c"/></xs:simpleType></xs:attribute></xs:complexType></xs:element>`), "unknown simple type 'c'"},
+		{"simpleContentEmpty", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent/></xs:complexType></xs:element>`), "simpleContent must contain a single extension"},
+		{"simpleContentChildFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><extension/></xs:simpleContent></xs:complexType></xs:element>`), "not part of XSD"},
+		{"extensionNoBase", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension/></xs:simpleContent></xs:complexType></xs:element>`), "extension must have a base"},
+		{"extensionBadBase", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension base="x:b"/></xs:simpleContent></xs:complexType></xs:element>`), "undeclared namespace prefix 'x'"},
+		{"extensionUnknownBase", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension base="b"/></xs:simpleContent></xs:complexType></xs:element>`), "unknown simple type 'b'"},
+		{"extensionChildFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension base="xs:string"><attribute/></xs:extension></xs:simpleContent></xs:complexType></xs:element>`), "not part of XSD"},
+		{"extensionChild", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension base="xs:string"><xs:sequence/></xs:extension></xs:simpleContent></xs:complexType></xs:element>`), "unsupported XSD construct 'sequence'"},
+		{"extensionAttrFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:simpleContent><xs:extension base="xs:string"><xs:attribute/></xs:extension></xs:simpleContent></xs:complexType></xs:element>`), "attribute must have a name"},
+		{"simpleChildFail", xsdSchemaWith(`<xs:simpleType name="t"><restriction/></xs:simpleType><xs:element name="a"/>`), "not part of XSD"},
+		{"simpleList", xsdSchemaWith(`<xs:simpleType name="t"><xs:list itemType="xs:int"/></xs:simpleType><xs:element name="a"/>`), "simpleType must contain a single restriction"},
+		{"restrictionNoBase", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction/></xs:simpleType><xs:element name="a"/>`), "restriction must have a base"},
+		{"restrictionBadBase", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="x:y"/></xs:simpleType><xs:element name="a"/>`), "undeclared namespace prefix 'x'"},
+		{"restrictionChildFail", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:string"><length/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "not part of XSD"},
+		{"facetNoValue", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:string"><xs:length/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "facet 'length' must have a value"},
+		{"facetLength", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:string"><xs:length value="-1"/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "invalid length '-1'"},
+		{"facetNumber", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:int"><xs:minInclusive value="x"/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "invalid minInclusive 'x'"},
+		{"facetPattern", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:string"><xs:pattern value="("/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "missing closing"},
+		{"facetUnsupported", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:decimal"><xs:totalDigits value="3"/></xs:restriction></xs:simpleType><xs:element name="a"/>`), "unsupported XSD construct 'totalDigits'"},
+		{"simpleUnknown", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="u"/></xs:simpleType><xs:element name="a"/>`), "unknown simple type 'u'"},
+		{"simpleUnknownBuiltin", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="xs:t"/></xs:simpleType><xs:element name="a"/>`), "unknown simple type 't'"},
+		{"simpleCircular", xsdSchemaWith(`<xs:simpleType name="t"><xs:restriction base="u"/></xs:simpleType><xs:simpleType name="u"><xs:restriction base="t"/></xs:simpleType><xs:element name="a"/>`), "too deep, or circular"},
+		{"complexUnknown", xsdSchemaWith(`<xs:complexType name="t"><xs:sequence><xs:element name="b" type="u"/></xs:sequence></xs:complexType><xs:element name="a"/>`), "unknown type 'u' of element 'b'"},
+		{"complexAttrUnknown", xsdSchemaWith(`<xs:complexType name="t"><xs:attribute name="b" type="u"/></xs:complexType><xs:element name="a"/>`), "unknown simple type 'u' of attribute 'b'"},
+		{"elementSimpleUnknown", xsdSchemaWith(`<xs:element name="a"><xs:simpleType><xs:restriction base="u"/></xs:simpleType></xs:element>`), "unknown simple type 'u'"},
+		{"nestedGroupFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:choice><xs:element name="b" type="u"/></xs:choice></xs:sequence></xs:complexType></xs:element>`), "unknown type 'u' of element 'b'"},
+		{"nestedGroupParseFail", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:sequence><xs:choice minOccurs="x"/></xs:sequence></xs:complexType></xs:element>`), "invalid minOccurs 'x'"},
+		{"differentTypes", xsdSchemaWith(`<xs:element name="a"><xs:complexType><xs:choice><xs:element name="b" type="xs:int"/><xs:element name="b" type="xs:string"/></xs:choice></xs:complexType></xs:element>`), "element 'b' is declared with different types in the same content model"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newXSDValidator(context.Background(), "ns1", newTestXSDDatatype(test.xsd))
+			assert.Regexp(t, "FF10196.*"+test.err, err)
+		})
+	}
+}
+
+func TestXSDValidatorContentModels(t *testing.T) {
+	xv := newTestXSDValidator(t, xsdSchemaWith(`
+		<xs:element name="root">
+			<xs:complexType mixed="true">
+				<xs:sequence>
+					<xs:choice minOccurs="0" maxOccurs="unbounded">
+						<xs:element name="a" type="xs:anyType"/>
+						<xs:sequence>
+							<xs:element name="b"/>
+							<xs:element name="c" minOccurs="0" maxOccurs="2"/>
+						</xs:sequence>
+					</xs:choice>
+					<xs:element name="empty" minOccurs="0">
+						<xs:complexType>
+							<xs:attribute name="any"/>
+						</xs:complexType>
+					</xs:element>
+					<xs:element ref="last" minOccurs="0"/>
+				</xs:sequence>
+			</xs:complexType>
+		</xs:element>
+		<xs:element name="last" type="xs:string"/>
+	`))
+
+	valid := []string{
+		`<root/>`,
+		`<root>text <a><anything x="1">goes</anything></a> more text</root>`,
+		`<root><b/><a/><b/><c/><c/><a/><empty/></root>`,
+		`<root><empty any="thing"></empty><last>done</last></root>`,
+		`<root><last>done</last></root>`,
+	}
+	for _, original := range valid {
+		assert.NoError(t, xv.validateXML(context.Background(), []byte(original)), original)
+	}
+
+	invalid := map[string]string{
+		`<root><c/></root>`:                            `the elements \[c\] do not match the sequence\(choice\(a,sequence\(b,c\)\),empty,last\)`,
+		`<root><b/><c/><c/><c/></root>`:                `do not match`,
+		`<root><last/><empty/></root>`:                 `do not match`,
+		`<root><empty>text</empty></root>`:             `/root/empty\[1\]: text is not allowed, as the element only has child elements`,
+		`<root><empty><a/></empty></root>`:             `/root/empty\[1\]: element 'a' is not allowed, as the element is empty`,
+		`<root><empty x="1"/></root>`:                  `/root/empty\[1\]: attribute 'x' is not declared`,
+		`<root><last>x<b/></last></root>`:              `/root/last\[1\]: element 'b' is not allowed, as the element has a simple type`,
+		`<root><b/><a/><last x="1">done</last></root>`: `/root/last\[1\]: attribute 'x' is not allowed, as the element has a simple type`,
+	}
+	for original, expected := range invalid {
+		assert.Regexp(t, expected, xv.validateXML(context.Background(), []byte(original)), original)
+	}
+}
+
+func TestXSDValidatorBuiltinTypes(t *testing.T) {
+	tests := []struct {
+		builtin string
+		valid   []string
+		invalid []string
+	}{
+		{"integer", []string{"0", "+123", "-99999999999999999999999"}, []string{"1.0", "", "1e3"}},
+		{"long", []string{"9223372036854775807"}, []string{"9223372036854775808"}},
+		{"int", []string{"-2147483648"}, []string{"-2147483649"}},
+		{"short", []string{"32767"}, []string{"32768"}},
+		{"byte", []string{"-128"}, []string{"128"}},
+		{"unsignedByte", []string{"255"}, []string{"-1", "256"}},
+		{"nonPositiveInteger", []string{"0", "-1"}, []string{"1"}},
+		{"negativeInteger", []string{"-1"}, []string{"0"}},
+		{"string", []string{"", " any thing "}, nil},
+		{"normalizedString", []string{"a\tb"}, nil},
+		{"token", []string{" a  b "}, nil},
+		{"language", []string{"en-GB"}, []string{"toolongtag"}},
+		{"Name", []string{"a:b"}, []string{"1a"}},
+		{"NCName", []string{"a_b"}, []string{"a:b"}},
+		{"NMTOKEN", []string{"123"}, []string{"a b"}},
+		{"boolean", []string{"true", "false", "1", "0", " true "}, []string{"yes", "TRUE"}},
+		{"decimal", []string{"1", "-1.5", ".5", "+2."}, []string{"1e3", ".", "one"}},
+		{"float", []string{"1.5", "1e3", "INF", "-INF", "NaN", "-0"}, []string{"inf", "+INF", "0x1p3", "1_000", "Infinity", "1e40"}},
+		{"double", []string{"1e300"}, []string{"1e400"}},
+		{"date", []string{"2022-02-28", "2022-02-28Z", "2022-02-28+05:00"}, []string{"2022-2-28", "2022-02-30"}},
+		{"dateTime", []string{"2022-02-28T12:00:00", "2022-02-28T12:00:00.123Z"}, []string{"2022-02-28 12:00:00", "2022-02-28T25:00:00"}},
+		{"time", []string{"12:00:00", "23:59:59.9-08:00"}, []string{"12:00", "24:00:01"}},
+		{"base64Binary", []string{"aGVsbG8=", "aGVs bG8="}, []string{"hello!"}},
+		{"hexBinary", []string{"0fA9", ""}, []string{"0g", "abc"}},
+	}
+	for _, test := range tests {
+		st := &xsdSimpleType{base: &xml.Name{Space: xsdNamespace, Local: test.builtin}}
+		for _, v := range test.valid {
+			assert.NoError(t, st.validate(v), "%s '%s'", test.builtin, v)
+		}
+		for _, v := range test.invalid {
+			assert.Error(t, st.validate(v), "%s '%s'", test.builtin, v)
+		}
+	}
+}
+
+func TestXSDValidatorFacets(t *testing.T) {
+	xv := newTestXSDValidator(t, xsdSchemaWith(`
+		<xs:element name="root">
+			<xs:complexType>
+				<xs:sequence>
+					<xs:element name="code" type="Code" minOccurs="0"/>
+					<xs:element name="exact" minOccurs="0">
+						<xs:simpleType>
+							<xs:restriction base="xs:token">
+								<xs:whiteSpace value="collapse"/>
+								<xs:length value="3"/>
+							</xs:restriction>
+						</xs:simpleType>
+					</xs:element>
+					<xs:element name="score" minOccurs="0">
+						<xs:simpleType>
+							<xs:restriction base="xs:decimal">
+								<xs:minExclusive value="0"/>
+								<xs:maxInclusive value="10.5"/>
+							</xs:restriction>
+						</xs:simpleType>
+					</xs:element>
+					<xs:element name="ranged" minOccurs="0">
+						<xs:simpleType>
+							<xs:restriction base="xs:string">
+								<xs:maxInclusive value="10"/>
+							</xs:restriction>
+						</xs:simpleType>
+					</xs:element>
+				</xs:sequence>
+			</xs:complexType>
+		</xs:element>
+		<xs:simpleType name="Code">
+			<xs:restriction base="ShortCode">
+				<xs:pattern value="[A-Z]+"/>
+			</xs:restriction>
+		</xs:simpleType>
+		<xs:simpleType name="ShortCode">
+			<xs:restriction base="xs:string">
+				<xs:minLength value="2"/>
+				<xs:maxLength value="4"/>
+			</xs:restriction>
+		</xs:simpleType>
+	`))
+
+	valid := []string{
+		`<root><code>AB</code></root>`,
+		`<root><code>ABCD</code><exact> a  b </exact><score>10.5</score></root>`,
+	}
+	for _, original := range valid {
+		assert.NoError(t, xv.validateXML(context.Background(), []byte(original)), original)
+	}
+
+	invalid := map[string]string{
+		`<root><code>A</code></root>`:      `'A' must have a length of at least 2`,
+		`<root><code>ABCDE</code></root>`:  `'ABCDE' must have a length of at most 4`,
+		`<root><code>ab</code></root>`:     `'ab' does not match the pattern`,
+		`<root><exact>abcd</exact></root>`: `'abcd' must have a length of 3`,
+		`<root><score>0</score></root>`:    `'0' must be greater than 0`,
+		`<root><score>10.6</score></root>`: `'10.6' must be at most 10.5`,
+		`<root><ranged>5</ranged></root>`:  `'5' is not a number, so cannot be checked against a range`,
+	}
+	for original, expected := range invalid {
+		assert.Regexp(t, expected, xv.validateXML(context.Background(), []byte(original)), original)
+	}
+}
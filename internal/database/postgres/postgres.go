@@ -58,6 +58,15 @@ func (psql *Postgres) Features() sqlcommon.SQLFeatures {
 		return fmt.Sprintf(`LOCK TABLE "%s" IN EXCLUSIVE MODE;`, table)
 	}
 	features.MultiRowInsert = true
+	features.FullTextSearch = func(query string) (sq.Sqlizer, sq.Sqlizer) {
+		return sq.Expr("to_tsvector('simple', content) @@ plainto_tsquery('simple', ?)", query),
+			sq.Expr("ts_rank(to_tsvector('simple', content), plainto_tsquery('simple', ?))", query)
+	}
+	// A replica that has replayed all the WAL it has received is current, however long ago the last transaction was
+	features.ReplicaLagSQL = `SELECT COALESCE(CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0)`
+	features.CreatePartitionSQL = func(table, partition string, from, to int64) string {
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" PARTITION OF "%s" FOR VALUES FROM (%d) TO (%d);`, partition, table, from, to)
+	}
 	return features
 }
 
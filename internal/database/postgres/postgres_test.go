@@ -41,6 +41,8 @@ func TestPostgresProvider(t *testing.T) {
 	assert.Equal(t, "postgres", psql.Name())
 	assert.Equal(t, sq.Dollar, psql.Features().PlaceholderFormat)
 	assert.Equal(t, `LOCK TABLE "events" IN EXCLUSIVE MODE;`, psql.Features().ExclusiveTableLockSQL("events"))
+	assert.Contains(t, psql.Features().ReplicaLagSQL, "pg_last_xact_replay_timestamp()")
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "events_p202202" PARTITION OF "events" FOR VALUES FROM (1) TO (2);`, psql.Features().CreatePartitionSQL("events", "events_p202202", 1, 2))
 
 	insert := sq.Insert("test").Columns("col1").Values("val1")
 	insert, query := psql.ApplyInsertQueryCustomizations(insert, true)
@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// updateAnnotations stores each annotation on a resource in the annotations table, so the resource
+// can be efficiently queried with an "annotations.<key>" filter
+func (s *SQLCommon) updateAnnotations(ctx context.Context, tx *txWrapper, id *fftypes.UUID, ns string, annotations fftypes.Annotations, recreate bool) error {
+
+	if recreate {
+		// Delete all the existing annotations, to replace them with new ones below
+		if err := s.deleteAnnotations(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := s.insertTx(ctx, tx,
+			sq.Insert("annotations").
+				Columns(
+					"resource_id",
+					"namespace",
+					"path",
+					"value",
+				).
+				Values(
+					id,
+					ns,
+					k,
+					annotations[k],
+				),
+			nil, // no change event
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLCommon) deleteAnnotations(ctx context.Context, tx *txWrapper, id *fftypes.UUID) error {
+	err := s.deleteTx(ctx, tx,
+		sq.Delete("annotations").
+			Where(sq.Eq{"resource_id": id}),
+		nil, // no change event
+	)
+	if err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	approvalRequestColumns = []string{
+		"id",
+		"namespace",
+		"rtype",
+		"state",
+		"reference",
+		"request",
+		"required",
+		"decisions",
+		"error",
+		"created",
+		"updated",
+	}
+	approvalRequestFilterFieldMap = map[string]string{
+		"type": "rtype",
+	}
+)
+
+func (s *SQLCommon) InsertApprovalRequest(ctx context.Context, req *fftypes.ApprovalRequest) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("approvalrequests").
+			Columns(approvalRequestColumns...).
+			Values(
+				req.ID,
+				req.Namespace,
+				req.Type,
+				req.State,
+				req.Reference,
+				req.Request,
+				req.Required,
+				req.Decisions,
+				req.Error,
+				req.Created,
+				req.Updated,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionApprovalRequests, fftypes.ChangeEventTypeCreated, req.Namespace, req.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) approvalRequestResult(ctx context.Context, row *sql.Rows) (*fftypes.ApprovalRequest, error) {
+	req := fftypes.ApprovalRequest{}
+	err := row.Scan(
+		&req.ID,
+		&req.Namespace,
+		&req.Type,
+		&req.State,
+		&req.Reference,
+		&req.Request,
+		&req.Required,
+		&req.Decisions,
+		&req.Error,
+		&req.Created,
+		&req.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "approvalrequests")
+	}
+	return &req, nil
+}
+
+func (s *SQLCommon) GetApprovalRequestByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ApprovalRequest, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(approvalRequestColumns...).
+			From("approvalrequests").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Approval request '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.approvalRequestResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetApprovalRequests(ctx context.Context, filter database.Filter) ([]*fftypes.ApprovalRequest, *database.FilterResult, error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(approvalRequestColumns...).From("approvalrequests"), filter, approvalRequestFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	reqs := []*fftypes.ApprovalRequest{}
+	for rows.Next() {
+		req, err := s.approvalRequestResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, s.queryRes(ctx, tx, "approvalrequests", fop, fi), err
+}
+
+func (s *SQLCommon) UpdateApprovalRequest(ctx context.Context, id *fftypes.UUID, update database.Update) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.buildUpdate(sq.Update("approvalrequests"), update, approvalRequestFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Set("updated", fftypes.Now())
+	query = query.Where(sq.Eq{"id": id})
+
+	_, err = s.updateTx(ctx, tx, query, nil /* no change events for filter based updates */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
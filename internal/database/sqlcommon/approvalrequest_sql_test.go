@@ -0,0 +1,191 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalRequestsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new approval request
+	req := &fftypes.ApprovalRequest{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		State:     fftypes.ApprovalRequestStatePending,
+		Reference: fftypes.NewUUID(),
+		Request:   fftypes.JSONAnyPtr(`{"amount":"100"}`),
+		Required:  2,
+		Decisions: fftypes.ApprovalDecisions{},
+		Created:   fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionApprovalRequests, fftypes.ChangeEventTypeCreated, "ns1", req.ID).Return()
+
+	err := s.InsertApprovalRequest(ctx, req)
+	assert.NoError(t, err)
+
+	// Check we get the exact same request back
+	reqRead, err := s.GetApprovalRequestByID(ctx, req.ID)
+	assert.NoError(t, err)
+	reqJson, _ := json.Marshal(&req)
+	reqReadJson, _ := json.Marshal(&reqRead)
+	assert.Equal(t, string(reqJson), string(reqReadJson))
+
+	// Record a decision
+	decisions := fftypes.ApprovalDecisions{
+		{Approver: "did:firefly:org/org1", Approved: true, Created: fftypes.Now()},
+	}
+	up := database.ApprovalRequestQueryFactory.NewUpdate(ctx).
+		Set("decisions", decisions).
+		Set("state", fftypes.ApprovalRequestStateApproved)
+	err = s.UpdateApprovalRequest(ctx, req.ID, up)
+	assert.NoError(t, err)
+
+	// Query back the request
+	fb := database.ApprovalRequestQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("type", fftypes.ApprovalRequestTypeTokenMint),
+		fb.Eq("state", fftypes.ApprovalRequestStateApproved),
+	)
+	reqs, res, err := s.GetApprovalRequests(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reqs))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, "did:firefly:org/org1", reqs[0].Decisions[0].Approver)
+	assert.NotNil(t, reqs[0].Updated)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertApprovalRequestFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertApprovalRequest(context.Background(), &fftypes.ApprovalRequest{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertApprovalRequestFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertApprovalRequest(context.Background(), &fftypes.ApprovalRequest{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertApprovalRequestFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertApprovalRequest(context.Background(), &fftypes.ApprovalRequest{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetApprovalRequestByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetApprovalRequestByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetApprovalRequestByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(approvalRequestColumns))
+	req, err := s.GetApprovalRequestByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, req)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetApprovalRequestByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetApprovalRequestByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetApprovalRequestsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.ApprovalRequestQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetApprovalRequests(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetApprovalRequestsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.ApprovalRequestQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetApprovalRequests(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetApprovalRequestsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.ApprovalRequestQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetApprovalRequests(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApprovalRequestUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.ApprovalRequestQueryFactory.NewUpdate(context.Background()).Set("state", "approved")
+	err := s.UpdateApprovalRequest(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestApprovalRequestUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.ApprovalRequestQueryFactory.NewUpdate(context.Background()).Set("state", map[bool]bool{true: false})
+	err := s.UpdateApprovalRequest(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestApprovalRequestUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.ApprovalRequestQueryFactory.NewUpdate(context.Background()).Set("state", "approved")
+	err := s.UpdateApprovalRequest(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
@@ -43,6 +43,7 @@ var (
 		"tx_type",
 		"tx_id",
 		"node_id",
+		"hash_algorithm",
 	}
 	batchFilterFieldMap = map[string]string{
 		"type":       "btype",
@@ -86,22 +87,26 @@ func (s *SQLCommon) UpsertBatch(ctx context.Context, batch *fftypes.BatchPersist
 	if existing {
 
 		// Update the batch
-		if _, err = s.updateTx(ctx, tx,
-			sq.Update("batches").
-				Set("btype", string(batch.Type)).
-				Set("namespace", batch.Namespace).
-				Set("author", batch.Author).
-				Set("key", batch.Key).
-				Set("group_hash", batch.Group).
-				Set("created", batch.Created).
-				Set("hash", batch.Hash).
-				Set("manifest", batch.Manifest).
-				Set("payload_ref", batch.PayloadRef).
-				Set("confirmed", batch.Confirmed).
-				Set("tx_type", batch.TX.Type).
-				Set("tx_id", batch.TX.ID).
-				Set("node_id", batch.Node).
-				Where(sq.Eq{"id": batch.ID}),
+		update := sq.Update("batches").
+			Set("btype", string(batch.Type)).
+			Set("namespace", batch.Namespace).
+			Set("author", batch.Author).
+			Set("key", batch.Key).
+			Set("group_hash", batch.Group).
+			Set("created", batch.Created).
+			Set("hash", batch.Hash).
+			Set("manifest", batch.Manifest).
+			Set("confirmed", batch.Confirmed).
+			Set("tx_type", batch.TX.Type).
+			Set("tx_id", batch.TX.ID).
+			Set("node_id", batch.Node).
+			Set("hash_algorithm", batch.HashAlgorithm).
+			Where(sq.Eq{"id": batch.ID})
+		if batch.PayloadRef != "" {
+			// A batch received over data exchange has no reference, and must not clear one recorded by a download
+			update = update.Set("payload_ref", batch.PayloadRef)
+		}
+		if _, err = s.updateTx(ctx, tx, update,
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionBatches, fftypes.ChangeEventTypeUpdated, batch.Namespace, batch.ID)
 			},
@@ -128,6 +133,7 @@ func (s *SQLCommon) UpsertBatch(ctx context.Context, batch *fftypes.BatchPersist
 					batch.TX.Type,
 					batch.TX.ID,
 					batch.Node,
+					batch.HashAlgorithm,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionBatches, fftypes.ChangeEventTypeCreated, batch.Namespace, batch.ID)
@@ -157,6 +163,7 @@ func (s *SQLCommon) batchResult(ctx context.Context, row *sql.Rows) (*fftypes.Ba
 		&batch.TX.Type,
 		&batch.TX.ID,
 		&batch.Node,
+		&batch.HashAlgorithm,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "batches")
@@ -88,9 +88,10 @@ func TestBatch2EWithDB(t *testing.T) {
 				Key:    "0x12345",
 				Author: "did:firefly:org/abcd",
 			},
-			Namespace: "ns1",
-			Node:      fftypes.NewUUID(),
-			Created:   fftypes.Now(),
+			Namespace:     "ns1",
+			Node:          fftypes.NewUUID(),
+			Created:       fftypes.Now(),
+			HashAlgorithm: fftypes.HashAlgorithmSHA3256,
 		},
 		Hash: fftypes.NewRandB32(),
 		TX: fftypes.TransactionRef{
@@ -146,6 +147,15 @@ func TestBatch2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(batches))
 
+	// Upsert without a payload ref does not clear the stored one
+	batchNoRef := *batchUpdated
+	batchNoRef.PayloadRef = ""
+	err = s.UpsertBatch(context.Background(), &batchNoRef)
+	assert.NoError(t, err)
+	batchRead, err = s.GetBatchByID(ctx, batchID)
+	assert.NoError(t, err)
+	assert.Equal(t, payloadRef, batchRead.PayloadRef)
+
 	// Update
 	author2 := "0x222222"
 	up := database.BatchQueryFactory.NewUpdate(ctx).Set("author", author2)
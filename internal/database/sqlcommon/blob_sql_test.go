@@ -38,11 +38,12 @@ func TestBlobsE2EWithDB(t *testing.T) {
 
 	// Create a new blob entry
 	blob := &fftypes.Blob{
-		Hash:       fftypes.NewRandB32(),
-		Size:       12345,
-		PayloadRef: fftypes.NewRandB32().String(),
-		Peer:       "peer1",
-		Created:    fftypes.Now(),
+		Hash:          fftypes.NewRandB32(),
+		HashAlgorithm: fftypes.HashAlgorithmBLAKE2b256,
+		Size:          12345,
+		PayloadRef:    fftypes.NewRandB32().String(),
+		Peer:          "peer1",
+		Created:       fftypes.Now(),
 	}
 	err := s.InsertBlob(ctx, blob)
 	assert.NoError(t, err)
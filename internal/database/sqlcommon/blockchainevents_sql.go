@@ -47,6 +47,13 @@ var (
 		"tx.type":    "tx_type",
 		"tx.id":      "tx_id",
 	}
+	blockchainEventRawColumns = []string{
+		"id",
+		"namespace",
+		"compressed",
+		"data",
+		"created",
+	}
 )
 
 func (s *SQLCommon) InsertBlockchainEvent(ctx context.Context, event *fftypes.BlockchainEvent) (err error) {
@@ -79,6 +86,14 @@ func (s *SQLCommon) InsertBlockchainEvent(ctx context.Context, event *fftypes.Bl
 		return err
 	}
 
+	content := []string{event.Name}
+	if event.Output != nil {
+		content = append(content, event.Output.String())
+	}
+	if err = s.indexForSearch(ctx, tx, event.Namespace, fftypes.SearchResultTypeBlockchainEvent, event.ID, event.Timestamp, content...); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -164,3 +179,57 @@ func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, filter database.Fil
 
 	return events, s.queryRes(ctx, tx, "blockchainevents", fop, fi), err
 }
+
+func (s *SQLCommon) InsertBlockchainEventRaw(ctx context.Context, raw *fftypes.BlockchainEventRaw) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("blockchaineventraw").
+			Columns(blockchainEventRawColumns...).
+			Values(
+				raw.ID,
+				raw.Namespace,
+				raw.Compressed,
+				raw.Data,
+				raw.Created,
+			),
+		nil, // no change events for raw blockchain event information
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) GetBlockchainEventRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEventRaw, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(blockchainEventRawColumns...).
+			From("blockchaineventraw").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Raw information for blockchain event '%s' not found", id)
+		return nil, nil
+	}
+
+	var raw fftypes.BlockchainEventRaw
+	if err = rows.Scan(
+		&raw.ID,
+		&raw.Namespace,
+		&raw.Compressed,
+		&raw.Data,
+		&raw.Created,
+	); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "blockchaineventraw")
+	}
+	return &raw, nil
+}
@@ -149,3 +149,65 @@ func TestGetBlockchainEventsScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBlockchainEventRawE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	event := &fftypes.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns",
+	}
+	raw, err := fftypes.NewBlockchainEventRaw(event, fftypes.JSONObject{"logs": "some very large log"}, true)
+	assert.NoError(t, err)
+
+	err = s.InsertBlockchainEventRaw(ctx, raw)
+	assert.NoError(t, err)
+
+	rawRead, err := s.GetBlockchainEventRaw(ctx, event.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, raw.Data, rawRead.Data)
+	assert.True(t, rawRead.Compressed)
+	info, err := rawRead.Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "some very large log", info.GetString("logs"))
+
+	rawRead, err = s.GetBlockchainEventRaw(ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, rawRead)
+}
+
+func TestInsertBlockchainEventRawFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertBlockchainEventRaw(context.Background(), &fftypes.BlockchainEventRaw{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertBlockchainEventRawFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertBlockchainEventRaw(context.Background(), &fftypes.BlockchainEventRaw{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainEventRawSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetBlockchainEventRaw(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainEventRawScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetBlockchainEventRaw(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 
 	sq "github.com/Masterminds/squirrel"
@@ -67,32 +69,77 @@ func (s *SQLCommon) getCaseQueriesByType(ns string, dataTypes []string, interval
 	return caseQueries
 }
 
-func (s *SQLCommon) getTableNameFromCollection(ctx context.Context, collection database.CollectionName) (tableName string, fieldMap map[string]string, err error) {
+// chartGroupByColumns are the fields each collection can be grouped by in a histogram, and their columns.
+// Only fields with a small number of distinct values are included, as each value is counted separately.
+var chartGroupByColumns = map[string]map[string]string{
+	"messages": {
+		"type":   "mtype",
+		"txtype": "tx_type",
+		"tag":    "tag",
+		"state":  "state",
+	},
+	"transactions": {
+		"type": "ttype",
+	},
+	"operations": {
+		"type":   "optype",
+		"status": "opstatus",
+		"plugin": "plugin",
+	},
+	"events": {
+		"type": "etype",
+	},
+	"tokentransfer": {
+		"type":      "type",
+		"connector": "connector",
+	},
+	"blockchainevents": {},
+}
+
+func (s *SQLCommon) getTableNameFromCollection(ctx context.Context, collection database.CollectionName) (tableName string, err error) {
 	switch collection {
 	case database.CollectionName(database.CollectionMessages):
-		return "messages", msgFilterFieldMap, nil
+		return "messages", nil
 	case database.CollectionName(database.CollectionTransactions):
-		return "transactions", transactionFilterFieldMap, nil
+		return "transactions", nil
 	case database.CollectionName(database.CollectionOperations):
-		return "operations", opFilterFieldMap, nil
+		return "operations", nil
 	case database.CollectionName(database.CollectionEvents):
-		return "events", eventFilterFieldMap, nil
+		return "events", nil
 	case database.CollectionName(database.CollectionTokenTransfers):
-		return "tokentransfer", tokenTransferFilterFieldMap, nil
+		return "tokentransfer", nil
 	case database.CollectionName(database.CollectionBlockchainEvents):
-		return "blockchainevents", blockchainEventFilterFieldMap, nil
+		return "blockchainevents", nil
 	default:
-		return "", nil, i18n.NewError(ctx, i18n.MsgUnsupportedCollection, collection)
+		return "", i18n.NewError(ctx, i18n.MsgUnsupportedCollection, collection)
+	}
+}
+
+func (s *SQLCommon) getGroupByColumn(ctx context.Context, collection database.CollectionName, tableName string, groupBy string) (string, error) {
+	columns := chartGroupByColumns[tableName]
+	if groupBy == "" {
+		// Group by type by default, where the collection has one
+		return columns["type"], nil
 	}
+	column, ok := columns[groupBy]
+	if !ok {
+		fields := make([]string, 0, len(columns))
+		for field := range columns {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		return "", i18n.NewError(ctx, i18n.MsgHistogramInvalidGroupBy, collection, groupBy, fields)
+	}
+	return column, nil
 }
 
-func (s *SQLCommon) getDistinctTypesFromTable(ctx context.Context, tableName string, fieldMap map[string]string) ([]string, error) {
-	if _, ok := fieldMap["type"]; !ok {
+func (s *SQLCommon) getDistinctTypesFromTable(ctx context.Context, ns string, tableName string, typeColName string) ([]string, error) {
+	if typeColName == "" {
 		return []string{}, nil
 	}
-	qb := sq.Select(fieldMap["type"]).Distinct().From(tableName)
+	qb := sq.Select(typeColName).Distinct().From(tableName).Where(sq.Eq{"namespace": ns})
 
-	rows, _, err := s.query(ctx, qb.From(tableName))
+	rows, _, err := s.query(ctx, qb)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +156,9 @@ func (s *SQLCommon) getDistinctTypesFromTable(ctx context.Context, tableName str
 	}
 	rows.Close()
 
+	if len(dataTypes) > fftypes.ChartHistogramMaxGroups {
+		return nil, i18n.NewError(ctx, i18n.MsgHistogramTooManyGroups, typeColName, fftypes.ChartHistogramMaxGroups)
+	}
 	return dataTypes, nil
 }
 
@@ -181,12 +231,12 @@ func (s *SQLCommon) getHistogramNoTypes(ctx context.Context, ns string, interval
 	return s.histogramResultNoType(ctx, rows, histogramList, tableName)
 }
 
-func (s *SQLCommon) getHistogramWithTypes(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, dataTypes []string, fieldMap map[string]string, tableName string) (histogramList []*fftypes.ChartHistogram, err error) {
+func (s *SQLCommon) getHistogramWithTypes(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, dataTypes []string, typeColName string, tableName string) (histogramList []*fftypes.ChartHistogram, err error) {
 	for _, interval := range intervals {
 		qb := sq.Select()
 		histogramTypes := make([]*fftypes.ChartHistogramType, 0)
 
-		for i, caseQuery := range s.getCaseQueriesByType(ns, dataTypes, interval, fieldMap["type"]) {
+		for i, caseQuery := range s.getCaseQueriesByType(ns, dataTypes, interval, typeColName) {
 			query, args, _ := caseQuery.ToSql()
 			histogramTypes = append(histogramTypes, &fftypes.ChartHistogramType{
 				Count: "",
@@ -231,19 +281,24 @@ func (s *SQLCommon) getHistogramWithTypes(ctx context.Context, ns string, interv
 	return histogramList, nil
 }
 
-func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName) (histogramList []*fftypes.ChartHistogram, err error) {
-	tableName, fieldMap, err := s.getTableNameFromCollection(ctx, collection)
+func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName, groupBy string) (histogramList []*fftypes.ChartHistogram, err error) {
+	tableName, err := s.getTableNameFromCollection(ctx, collection)
 	if err != nil {
 		return nil, err
 	}
 
-	dataTypes, err := s.getDistinctTypesFromTable(ctx, tableName, fieldMap)
+	typeColName, err := s.getGroupByColumn(ctx, collection, tableName, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	dataTypes, err := s.getDistinctTypesFromTable(ctx, ns, tableName, typeColName)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(dataTypes) > 0 {
-		histogramList, err = s.getHistogramWithTypes(ctx, ns, intervals, dataTypes, fieldMap, tableName)
+		histogramList, err = s.getHistogramWithTypes(ctx, ns, intervals, dataTypes, typeColName, tableName)
 		if err != nil {
 			return nil, err
 		}
@@ -258,3 +313,71 @@ func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals
 
 	return histogramList, nil
 }
+
+// latencyRank returns the rank, from one, of the latency at or below which the percentile of the latencies fall,
+// using the nearest rank. The percentile is greater than zero, so the rank is always at least one.
+func latencyRank(count int64, percentile float64) int64 {
+	return int64(math.Ceil(percentile / 100 * float64(count)))
+}
+
+// latencyAtRank returns the latency of the message at a rank in the order of latency. The database does the
+// sorting, so only one row is returned however many messages are in the interval.
+func (s *SQLCommon) latencyAtRank(ctx context.Context, where sq.Sqlizer, rank int64) (latency int64, err error) {
+	qb := sq.Select("confirmed - created").
+		From("messages").
+		Where(where).
+		OrderBy("confirmed - created").
+		Offset(uint64(rank - 1)).
+		Limit(1)
+
+	rows, _, err := s.query(ctx, qb)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&latency); err != nil {
+			return 0, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "messages")
+		}
+	}
+	return latency, nil
+}
+
+func (s *SQLCommon) GetChartLatencyHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, percentiles []float64) (histogramList []*fftypes.ChartHistogram, err error) {
+	for _, interval := range intervals {
+		// Messages are counted in the bucket they were created in, once they are confirmed
+		where := sq.And{
+			sq.Eq{"namespace": ns},
+			sq.GtOrEq{"created": interval.StartTime},
+			sq.Lt{"created": interval.EndTime},
+			sq.NotEq{"confirmed": nil},
+		}
+		count, err := s.countQuery(ctx, nil, "messages", where, "")
+		if err != nil {
+			return nil, err
+		}
+
+		bucket := &fftypes.ChartHistogram{
+			Count:     strconv.FormatInt(count, 10),
+			Timestamp: interval.StartTime,
+			Types:     make([]*fftypes.ChartHistogramType, 0),
+			Latencies: make([]*fftypes.ChartHistogramLatency, 0, len(percentiles)),
+		}
+		if count > 0 {
+			for _, percentile := range percentiles {
+				latency, err := s.latencyAtRank(ctx, where, latencyRank(count, percentile))
+				if err != nil {
+					return nil, err
+				}
+				ffLatency := fftypes.FFDuration(latency)
+				bucket.Latencies = append(bucket.Latencies, &fftypes.ChartHistogramLatency{
+					Percentile: percentile,
+					Latency:    &ffLatency,
+				})
+			}
+		}
+		histogramList = append(histogramList, bucket)
+	}
+
+	return histogramList, nil
+}
@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var (
@@ -80,7 +82,7 @@ var (
 func TestGetChartHistogramInvalidCollectionName(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
-	_, err := s.GetChartHistogram(context.Background(), "ns1", []fftypes.ChartHistogramInterval{}, database.CollectionName("abc"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", []fftypes.ChartHistogramInterval{}, database.CollectionName("abc"), "")
 	assert.Regexp(t, "FF10301", err)
 }
 
@@ -90,7 +92,7 @@ func TestGetChartHistogramValidCollectionNameWithTypes(t *testing.T) {
 		mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}).AddRow("5", "5"))
 
-		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsWithTypes[i]))
+		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsWithTypes[i]), "")
 
 		assert.NoError(t, err)
 		assert.Equal(t, histogram, expectedHistogramResult)
@@ -103,7 +105,7 @@ func TestGetChartHistogramValidCollectionNameNoTypes(t *testing.T) {
 		s, mock := newMockProvider().init()
 		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0"}).AddRow("10"))
 
-		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsNoTypes[i]))
+		histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(validCollectionsNoTypes[i]), "")
 		assert.NoError(t, err)
 		assert.Equal(t, expectedHistogramResultNoTypes, histogram)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -115,7 +117,7 @@ func TestGetChartHistogramsQueryFail(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 	mock.ExpectQuery("SELECT *").WillReturnError(fmt.Errorf("pop"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Regexp(t, "FF10115", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -124,7 +126,7 @@ func TestGetChartHistogramsQueryFailNoTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT *").WillReturnError(fmt.Errorf("pop"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"), "")
 	assert.Regexp(t, "FF10115", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -133,7 +135,7 @@ func TestGetChartHistogramQueryFailBadDistinctTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnError(fmt.Errorf("pop"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Regexp(t, "FF10115", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -142,7 +144,7 @@ func TestGetChartHistogramScanFailInvalidRowType(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow(nil).AddRow("typeB"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -152,7 +154,7 @@ func TestGetChartHistogramScanFailTooManyCols(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1", "unexpected_col"}).AddRow("one", "two", "three"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -161,7 +163,7 @@ func TestGetChartHistogramScanFailTooManyColsNoTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "unexpected"}).AddRow("10", "abc"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"), "")
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -171,7 +173,7 @@ func TestGetChartHistogramFailStringToIntConversion(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}).AddRow("5", "NotInt"))
 
-	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -181,7 +183,7 @@ func TestGetChartHistogramSuccessNoRows(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}))
 
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 	assert.NoError(t, err)
 	assert.Equal(t, emptyHistogramResult, histogram)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -191,7 +193,7 @@ func TestGetChartHistogramSuccessNoRowsNoTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}))
 
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"), "")
 	assert.NoError(t, err)
 
 	assert.Equal(t, emptyHistogramResult, histogram)
@@ -202,7 +204,7 @@ func TestGetChartHistogramSuccessNoTypes(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0"}).AddRow("10"))
 
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"))
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("blockchainevents"), "")
 	assert.NoError(t, err)
 	assert.Equal(t, expectedHistogramResultNoTypes, histogram)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -213,9 +215,128 @@ func TestGetChartHistogramSuccess(t *testing.T) {
 	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("typeA").AddRow("typeB"))
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}).AddRow("5", "5"))
 
-	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"))
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedHistogramResult, histogram)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetChartHistogramGroupBy(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT DISTINCT tag FROM messages WHERE namespace = .*").WillReturnRows(sqlmock.NewRows([]string{"tag"}).AddRow("typeA").AddRow("typeB"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"case_0", "case_1"}).AddRow("5", "5"))
+
+	histogram, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "tag")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHistogramResult, histogram)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChartHistogramGroupByInvalid(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "id")
+	assert.Regexp(t, "FF10544.*\\[state tag txtype type\\]", err)
+}
+
+func TestGetChartHistogramGroupByTooManyGroups(t *testing.T) {
+	s, mock := newMockProvider().init()
+	rows := sqlmock.NewRows([]string{"tag"})
+	for i := 0; i <= fftypes.ChartHistogramMaxGroups; i++ {
+		rows.AddRow(fmt.Sprintf("tag%d", i))
+	}
+	mock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(rows)
+
+	_, err := s.GetChartHistogram(context.Background(), "ns1", mockHistogramInterval, database.CollectionName("messages"), "tag")
+	assert.Regexp(t, "FF10545", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChartLatencyHistogram(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	insertMessage := func(created, confirmed int64) {
+		msg := &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+				Created:   fftypes.UnixTime(created),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash: fftypes.NewRandB32(),
+		}
+		if confirmed > 0 {
+			msg.Confirmed = fftypes.UnixTime(confirmed)
+		}
+		err := s.InsertMessages(ctx, []*fftypes.Message{msg})
+		assert.NoError(t, err)
+	}
+	insertMessage(1000000000, 1000000400)
+	insertMessage(1000000000, 1000000100)
+	insertMessage(1000000000, 0)
+	insertMessage(1000000001, 1000000301)
+	insertMessage(1000000001, 1000000201)
+	insertMessage(1000000001, 1000000101)
+
+	histogram, err := s.GetChartLatencyHistogram(ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: fftypes.UnixTime(1000000000), EndTime: fftypes.UnixTime(1000000001)},
+		{StartTime: fftypes.UnixTime(1000000001), EndTime: fftypes.UnixTime(1000000002)},
+		{StartTime: fftypes.UnixTime(1000000002), EndTime: fftypes.UnixTime(1000000003)},
+	}, []float64{50, 99})
+	assert.NoError(t, err)
+	assert.Len(t, histogram, 3)
+
+	latencies := func(bucket *fftypes.ChartHistogram) (l []time.Duration) {
+		for _, latency := range bucket.Latencies {
+			l = append(l, time.Duration(*latency.Latency))
+		}
+		return l
+	}
+	assert.Equal(t, "2", histogram[0].Count)
+	assert.Equal(t, []time.Duration{100 * time.Second, 400 * time.Second}, latencies(histogram[0]))
+	assert.Equal(t, "3", histogram[1].Count)
+	assert.Equal(t, []time.Duration{200 * time.Second, 300 * time.Second}, latencies(histogram[1]))
+	assert.Equal(t, float64(99), histogram[1].Latencies[1].Percentile)
+	assert.Equal(t, "0", histogram[2].Count)
+	assert.Empty(t, histogram[2].Latencies)
+}
+
+func TestGetChartLatencyHistogramNoIntervals(t *testing.T) {
+	s, _ := newMockProvider().init()
+	histogram, err := s.GetChartLatencyHistogram(context.Background(), "ns1", []fftypes.ChartHistogramInterval{}, []float64{50})
+	assert.NoError(t, err)
+	assert.Empty(t, histogram)
+}
+
+func TestGetChartLatencyHistogramCountFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := s.GetChartLatencyHistogram(context.Background(), "ns1", mockHistogramInterval, []float64{50})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChartLatencyHistogramQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT confirmed - created .*").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := s.GetChartLatencyHistogram(context.Background(), "ns1", mockHistogramInterval, []float64{50})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChartLatencyHistogramScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT confirmed - created .*").WillReturnRows(sqlmock.NewRows([]string{"latency"}).AddRow("!int"))
+
+	_, err := s.GetChartLatencyHistogram(context.Background(), "ns1", mockHistogramInterval, []float64{50})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -37,6 +37,20 @@ const (
 	SQLConfMaxIdleConns = "maxIdleConns"
 	// SQLConfMaxConnLifetime maximum connections to the database
 	SQLConfMaxConnLifetime = "maxConnLifetime"
+	// SQLConfFullTextSearchEnabled maintains a full-text search index on insert, if supported by the database. Can be disabled for write-heavy deployments
+	SQLConfFullTextSearchEnabled = "fullTextSearch.enabled"
+	// SQLConfPartitioningEnabled creates monthly partitions ahead of time for the high-volume tables, if supported by the database
+	SQLConfPartitioningEnabled = "partitioning.enabled"
+	// SQLConfPartitioningPremake is the number of future monthly partitions to keep created
+	SQLConfPartitioningPremake = "partitioning.premake"
+	// SQLConfPartitioningCheckInterval is how often to check for monthly partitions that need to be created
+	SQLConfPartitioningCheckInterval = "partitioning.checkInterval"
+	// SQLConfReadReplicaURL is the datasource connection URL string of a read-only replica, used for reads that can tolerate stale data
+	SQLConfReadReplicaURL = "readReplica.url"
+	// SQLConfReadReplicaMaxStaleness is how far the replica can lag behind the primary before reads fall back to the primary
+	SQLConfReadReplicaMaxStaleness = "readReplica.maxStaleness"
+	// SQLConfReadReplicaLagCheckInterval is how often the replication lag of the replica is checked
+	SQLConfReadReplicaLagCheckInterval = "readReplica.lagCheckInterval"
 )
 
 const (
@@ -51,4 +65,11 @@ func (s *SQLCommon) InitPrefix(provider Provider, prefix config.Prefix) {
 	prefix.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
 	prefix.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
 	prefix.AddKnownKey(SQLConfMaxConnLifetime)
+	prefix.AddKnownKey(SQLConfFullTextSearchEnabled, true)
+	prefix.AddKnownKey(SQLConfPartitioningEnabled, false)
+	prefix.AddKnownKey(SQLConfPartitioningPremake, 3)
+	prefix.AddKnownKey(SQLConfPartitioningCheckInterval, "24h")
+	prefix.AddKnownKey(SQLConfReadReplicaURL)
+	prefix.AddKnownKey(SQLConfReadReplicaMaxStaleness, "5s")
+	prefix.AddKnownKey(SQLConfReadReplicaLagCheckInterval, "1s")
 }
@@ -36,10 +36,14 @@ var (
 		"name",
 		"namespace",
 		"message_id",
+		"query_cache",
+		"transforms",
+		"annotations",
 	}
 	contractAPIsFilterFieldMap = map[string]string{
-		"interface": "interface_id",
-		"message":   "message_id",
+		"interface":     "interface_id",
+		"message":       "message_id",
+		"annotations.*": "annotations",
 	}
 )
 
@@ -70,7 +74,10 @@ func (s *SQLCommon) UpsertContractAPI(ctx context.Context, api *fftypes.Contract
 				Set("location", api.Location).
 				Set("name", api.Name).
 				Set("namespace", api.Namespace).
-				Set("message_id", api.Message),
+				Set("message_id", api.Message).
+				Set("query_cache", api.QueryCache).
+				Set("transforms", api.Transforms).
+				Set("annotations", api.Annotations),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionContractAPIs, fftypes.ChangeEventTypeUpdated, api.Namespace, api.ID)
 			},
@@ -89,6 +96,9 @@ func (s *SQLCommon) UpsertContractAPI(ctx context.Context, api *fftypes.Contract
 					api.Name,
 					api.Namespace,
 					api.Message,
+					api.QueryCache,
+					api.Transforms,
+					api.Annotations,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionContractAPIs, fftypes.ChangeEventTypeCreated, api.Namespace, api.ID)
@@ -98,6 +108,10 @@ func (s *SQLCommon) UpsertContractAPI(ctx context.Context, api *fftypes.Contract
 		}
 	}
 
+	if err = s.updateAnnotations(ctx, tx, api.ID, api.Namespace, api.Annotations, existing); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -113,6 +127,9 @@ func (s *SQLCommon) contractAPIResult(ctx context.Context, row *sql.Rows) (*ffty
 		&api.Name,
 		&api.Namespace,
 		&api.Message,
+		&api.QueryCache,
+		&api.Transforms,
+		&api.Annotations,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "contract")
@@ -50,6 +50,15 @@ func TestContractAPIE2EWithDB(t *testing.T) {
 			Version: "v1.0.0",
 		},
 		Message: fftypes.NewUUID(),
+		QueryCache: &fftypes.ContractAPIQueryCache{
+			Enabled: true,
+		},
+		Transforms: fftypes.ContractAPITransforms{
+			"transfer": {
+				"amount": {Type: fftypes.ContractInputTransformTypeDecimal, Decimals: 18},
+			},
+		},
+		Annotations: fftypes.Annotations{"owner": "team-a"},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionContractAPIs, fftypes.ChangeEventTypeCreated, "ns1", apiID, mock.Anything).Return()
@@ -63,6 +72,9 @@ func TestContractAPIE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, dataRead)
 	assert.Equal(t, *apiID, *dataRead.ID)
+	assert.True(t, dataRead.QueryCache.Enabled)
+	assert.Equal(t, contractAPI.Transforms, dataRead.Transforms)
+	assert.Equal(t, contractAPI.Annotations, dataRead.Annotations)
 
 	contractAPI.Interface.Version = "v1.1.0"
 
@@ -74,6 +86,32 @@ func TestContractAPIE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, dataRead)
 	assert.Equal(t, *apiID, *dataRead.ID)
+
+	// Query back by annotation
+	fb := database.ContractAPIQueryFactory.NewFilter(ctx)
+	apis, _, err := s.GetContractAPIs(ctx, "ns1", fb.And(fb.Eq("annotations.owner", "team-a")))
+	assert.NoError(t, err)
+	assert.Len(t, apis, 1)
+	apis, _, err = s.GetContractAPIs(ctx, "ns1", fb.And(fb.Eq("annotations.owner", "team-b")))
+	assert.NoError(t, err)
+	assert.Len(t, apis, 0)
+}
+
+func TestContractAPIDBFailAnnotations(t *testing.T) {
+	rows := sqlmock.NewRows([]string{"id"}).AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2")
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	api := &fftypes.ContractAPI{
+		ID:        fftypes.NewUUID(),
+		Interface: &fftypes.FFIReference{},
+	}
+	err := s.UpsertContractAPI(context.Background(), api)
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestContractAPIDBFailBeginTransaction(t *testing.T) {
@@ -94,7 +132,7 @@ func TestContractAPIDBFailSelect(t *testing.T) {
 }
 
 func TestContractAPIDBFailInsert(t *testing.T) {
-	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"})
+	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"})
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
@@ -108,8 +146,8 @@ func TestContractAPIDBFailInsert(t *testing.T) {
 }
 
 func TestContractAPIDBFailUpdate(t *testing.T) {
-	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"}).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"}).
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil, nil, nil)
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
@@ -142,7 +180,7 @@ func TestContractAPIDBSelectFail(t *testing.T) {
 func TestContractAPIDBNoRows(t *testing.T) {
 	s, mock := newMockProvider().init()
 	apiID := fftypes.NewUUID()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"}))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"}))
 	_, err := s.GetContractAPIByID(context.Background(), apiID)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -151,8 +189,8 @@ func TestContractAPIDBNoRows(t *testing.T) {
 func TestGetContractAPIs(t *testing.T) {
 	fb := database.ContractAPIQueryFactory.NewFilter(context.Background())
 	s, mock := newMockProvider().init()
-	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"}).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"}).
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil, nil, nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	_, _, err := s.GetContractAPIs(context.Background(), "ns1", fb.And())
 	assert.NoError(t, err)
@@ -178,9 +216,9 @@ func TestGetContractAPIsQueryFail(t *testing.T) {
 func TestGetContractAPIsQueryResultFail(t *testing.T) {
 	fb := database.ContractAPIQueryFactory.NewFilter(context.Background())
 	s, mock := newMockProvider().init()
-	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"}).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "apple", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f").
-		AddRow("69851ca3-e9f9-489b-8731-dc6a7d990291", "4db4952e-4669-4243-a387-8f0f609e92bd", nil, nil, "orange", nil, "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"}).
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "apple", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil, nil, nil).
+		AddRow("69851ca3-e9f9-489b-8731-dc6a7d990291", "4db4952e-4669-4243-a387-8f0f609e92bd", nil, nil, "orange", nil, "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil, nil, nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	_, _, err := s.GetContractAPIs(context.Background(), "ns1", fb.And())
 	assert.Regexp(t, "FF10121", err)
@@ -189,8 +227,8 @@ func TestGetContractAPIsQueryResultFail(t *testing.T) {
 
 func TestGetContractAPIByName(t *testing.T) {
 	s, mock := newMockProvider().init()
-	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id"}).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+	rows := sqlmock.NewRows([]string{"id", "interface_id", "ledger", "location", "name", "namespace", "message_id", "query_cache", "transforms", "annotations"}).
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "8fcc4938-7d8b-4c00-a71b-1b46837c8ab1", nil, nil, "banana", "ns1", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil, nil, nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	api, err := s.GetContractAPIByName(context.Background(), "ns1", "banana")
 	assert.NotNil(t, api)
@@ -39,6 +39,7 @@ var (
 		"location",
 		"topic",
 		"options",
+		"state",
 		"created",
 	}
 	contractListenerFilterFieldMap = map[string]string{
@@ -81,6 +82,7 @@ func (s *SQLCommon) UpsertContractListener(ctx context.Context, sub *fftypes.Con
 				Set("location", sub.Location).
 				Set("topic", sub.Topic).
 				Set("options", sub.Options).
+				Set("state", sub.State).
 				Where(sq.Eq{"protocol_id": sub.ProtocolID}),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionContractListeners, fftypes.ChangeEventTypeUpdated, sub.Namespace, sub.ID)
@@ -103,6 +105,7 @@ func (s *SQLCommon) UpsertContractListener(ctx context.Context, sub *fftypes.Con
 					sub.Location,
 					sub.Topic,
 					sub.Options,
+					sub.State,
 					sub.Created,
 				),
 			func() {
@@ -130,6 +133,7 @@ func (s *SQLCommon) contractListenerResult(ctx context.Context, row *sql.Rows) (
 		&sub.Location,
 		&sub.Topic,
 		&sub.Options,
+		&sub.State,
 		&sub.Created,
 	)
 	if err != nil {
@@ -54,6 +54,7 @@ func TestContractListenerE2EWithDB(t *testing.T) {
 		Options: &fftypes.ContractListenerOptions{
 			FirstEvent: "0",
 		},
+		State: fftypes.ContractListenerStateActive,
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionContractListeners, fftypes.ChangeEventTypeCreated, "ns", sub.ID).Return()
@@ -97,6 +98,7 @@ func TestContractListenerE2EWithDB(t *testing.T) {
 
 	// Update the listener
 	sub.Location = fftypes.JSONAnyPtr("{}")
+	sub.State = fftypes.ContractListenerStatePaused
 	subJson, _ = json.Marshal(&sub)
 	err = s.UpsertContractListener(ctx, sub)
 	assert.NoError(t, err)
@@ -231,7 +233,7 @@ func TestContractListenerDeleteFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(contractListenerColumns).AddRow(
-		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "topic1", nil, fftypes.Now()),
+		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "topic1", nil, "active", fftypes.Now()),
 	)
 	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
 	err := s.DeleteContractListenerByID(context.Background(), fftypes.NewUUID())
@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -27,6 +28,8 @@ import (
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
+const dataIndexBackfillPageSize = 100
+
 var (
 	dataColumnsNoValue = []string{
 		"id",
@@ -40,17 +43,22 @@ var (
 		"blob_public",
 		"blob_name",
 		"blob_size",
+		"blob_hash_algorithm",
 		"value_size",
+		"media_type",
 	}
-	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value")
+	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value", "value_original")
 	dataFilterFieldMap   = map[string]string{
-		"validator":        "validator",
-		"datatype.name":    "datatype_name",
-		"datatype.version": "datatype_version",
-		"blob.hash":        "blob_hash",
-		"blob.public":      "blob_public",
-		"blob.name":        "blob_name",
-		"blob.size":        "blob_size",
+		"validator":          "validator",
+		"datatype.name":      "datatype_name",
+		"datatype.version":   "datatype_version",
+		"blob.hash":          "blob_hash",
+		"blob.public":        "blob_public",
+		"blob.name":          "blob_name",
+		"blob.size":          "blob_size",
+		"blob.hashalgorithm": "blob_hash_algorithm",
+		"mediatype":          "media_type",
+		"value.*":            "data_index",
 	}
 )
 
@@ -75,8 +83,11 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *txWrapper, data *
 			Set("blob_public", blob.Public).
 			Set("blob_name", blob.Name).
 			Set("blob_size", blob.Size).
+			Set("blob_hash_algorithm", blob.HashAlgorithm).
 			Set("value_size", data.ValueSize).
+			Set("media_type", data.MediaType).
 			Set("value", data.Value).
+			Set("value_original", data.Original).
 			Where(sq.Eq{
 				"id":   data.ID,
 				"hash": data.Hash,
@@ -107,8 +118,11 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *fftypes.Da
 		blob.Public,
 		blob.Name,
 		blob.Size,
+		blob.HashAlgorithm,
 		data.ValueSize,
+		data.MediaType,
 		data.Value,
+		data.Original,
 	)
 }
 
@@ -120,6 +134,202 @@ func (s *SQLCommon) attemptDataInsert(ctx context.Context, tx *txWrapper, data *
 		}, requestConflictEmptyResult)
 }
 
+func (s *SQLCommon) indexDataForSearch(ctx context.Context, tx *txWrapper, data *fftypes.Data) error {
+	if data.Value == nil {
+		return nil
+	}
+	return s.indexForSearch(ctx, tx, data.Namespace, fftypes.SearchResultTypeData, data.ID, data.Created, data.Value.String())
+}
+
+// getDatatypeIndexes returns the JSON paths configured as indexes on the datatype of a data item,
+// using the supplied cache (if non-nil) to avoid repeated lookups when processing arrays of data
+func (s *SQLCommon) getDatatypeIndexes(ctx context.Context, tx *txWrapper, data *fftypes.Data, cache map[string]fftypes.FFStringArray) (fftypes.FFStringArray, error) {
+	cacheKey := fmt.Sprintf("%s:%s:%s", data.Namespace, data.Datatype.Name, data.Datatype.Version)
+	if indexes, ok := cache[cacheKey]; ok {
+		return indexes, nil
+	}
+	rows, _, err := s.queryTx(ctx, tx,
+		sq.Select("indexes").
+			From("datatypes").
+			Where(sq.Eq{
+				"namespace": data.Namespace,
+				"name":      data.Datatype.Name,
+				"version":   data.Datatype.Version,
+			}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes fftypes.FFStringArray
+	if rows.Next() {
+		if err = rows.Scan(&indexes); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "datatypes")
+		}
+	}
+	if cache != nil {
+		cache[cacheKey] = indexes
+	}
+	return indexes, nil
+}
+
+// updateDataIndexes extracts the value at each JSON path configured as an index on the datatype,
+// and stores it in the data_index table so it can be efficiently queried with a "value.<path>" filter
+func (s *SQLCommon) updateDataIndexes(ctx context.Context, tx *txWrapper, data *fftypes.Data, cache map[string]fftypes.FFStringArray, recreate bool) error {
+
+	if recreate {
+		// Delete all the existing index entries, to replace them with new ones below
+		if err := s.deleteTx(ctx, tx,
+			sq.Delete("data_index").
+				Where(sq.Eq{"data_id": data.ID}),
+			nil, // no change event
+		); err != nil && err != database.DeleteRecordNotFound {
+			return err
+		}
+	}
+
+	if data.Datatype == nil || data.Value == nil {
+		return nil
+	}
+	indexes, err := s.getDatatypeIndexes(ctx, tx, data, cache)
+	if err != nil || len(indexes) == 0 {
+		return err
+	}
+
+	value := data.Value.JSONObjectNowarn()
+	for _, path := range indexes {
+		v, ok := value.GetPathStringOk(path)
+		if !ok {
+			continue
+		}
+		if len(v) > fftypes.FFStringArrayStandardMax {
+			log.L(ctx).Debugf("Skipping index '%s' on data '%s' as value length %d is too long", path, data.ID, len(v))
+			continue
+		}
+		if _, err := s.insertTx(ctx, tx,
+			sq.Insert("data_index").
+				Columns(
+					"data_id",
+					"namespace",
+					"path",
+					"value",
+				).
+				Values(
+					data.ID,
+					data.Namespace,
+					path,
+					v,
+				),
+			nil, // no change event
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillDataIndexes rebuilds the index entries of all the data of a datatype, when the indexes of the datatype
+// are created or changed - so data stored before then can be queried with a "value.<path>" filter.
+// The data is read in pages, as each page must be read in full before the index entries are written.
+func (s *SQLCommon) backfillDataIndexes(ctx context.Context, tx *txWrapper, datatype *fftypes.Datatype) error {
+	ref := &fftypes.DatatypeRef{Name: datatype.Name, Version: datatype.Version}
+	cache := map[string]fftypes.FFStringArray{
+		fmt.Sprintf("%s:%s:%s", datatype.Namespace, datatype.Name, datatype.Version): datatype.Indexes,
+	}
+	lastSequence := int64(-1)
+	count := 0
+	for {
+		rows, _, err := s.queryTx(ctx, tx,
+			sq.Select(sequenceColumn, "id", "value").
+				From("data").
+				Where(sq.And{
+					sq.Eq{
+						"namespace":        datatype.Namespace,
+						"datatype_name":    datatype.Name,
+						"datatype_version": datatype.Version,
+					},
+					sq.Gt{sequenceColumn: lastSequence},
+				}).
+				OrderBy(sequenceColumn).
+				Limit(dataIndexBackfillPageSize),
+		)
+		if err != nil {
+			return err
+		}
+		page := make([]*fftypes.Data, 0, dataIndexBackfillPageSize)
+		for rows.Next() {
+			data := &fftypes.Data{Namespace: datatype.Namespace, Datatype: ref}
+			if err := rows.Scan(&lastSequence, &data.ID, &data.Value); err != nil {
+				rows.Close()
+				return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "data")
+			}
+			page = append(page, data)
+		}
+		rows.Close()
+
+		for _, data := range page {
+			if err := s.updateDataIndexes(ctx, tx, data, cache, true); err != nil {
+				return err
+			}
+		}
+		count += len(page)
+		if len(page) < dataIndexBackfillPageSize {
+			log.L(ctx).Infof("Rebuilt the value indexes of %d data items of datatype %s:%s", count, datatype.Name, datatype.Version)
+			return nil
+		}
+	}
+}
+
+// checkDataIndexedPaths rejects a filter on a "value.<path>" that is not an index of any datatype, as
+// the filter could only ever match the data stored before an index was removed (rather than none)
+func (s *SQLCommon) checkDataIndexedPaths(ctx context.Context, filter database.Filter) error {
+	fi, err := filter.Finalize()
+	if err != nil {
+		return err
+	}
+	paths := s.dataIndexedPaths(fi, nil)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	rows, _, err := s.query(ctx,
+		sq.Select("indexes").
+			From("datatypes").
+			Where(sq.NotEq{"indexes": ""}),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	indexed := make(map[string]bool)
+	for rows.Next() {
+		var indexes fftypes.FFStringArray
+		if err := rows.Scan(&indexes); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "datatypes")
+		}
+		for _, path := range indexes {
+			indexed[path] = true
+		}
+	}
+	for _, path := range paths {
+		if !indexed[path] {
+			return i18n.NewError(ctx, i18n.MsgDataValuePathNotIndexed, path)
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) dataIndexedPaths(fi *database.FilterInfo, paths []string) []string {
+	if _, path, ok := s.indexedField(fi.Field, dataFilterFieldMap); ok {
+		paths = append(paths, path)
+	}
+	for _, child := range fi.Children {
+		paths = s.dataIndexedPaths(child, paths)
+	}
+	return paths
+}
+
 func (s *SQLCommon) UpsertData(ctx context.Context, data *fftypes.Data, optimization database.UpsertOptimization) (err error) {
 	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
 	if err != nil {
@@ -133,12 +343,14 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *fftypes.Data, optimiza
 	// The expectation is that the optimization will hit almost all of the time,
 	// as only recovery paths require us to go down the un-optimized route.
 	optimized := false
+	existing := false
 	if optimization == database.UpsertOptimizationNew {
 		_, opErr := s.attemptDataInsert(ctx, tx, data, true /* we want a failure here we can progress past */)
 		optimized = opErr == nil
 	} else if optimization == database.UpsertOptimizationExisting {
 		rowsAffected, opErr := s.attemptDataUpdate(ctx, tx, data)
 		optimized = opErr == nil && rowsAffected == 1
+		existing = optimized
 	}
 
 	if !optimized {
@@ -152,7 +364,7 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *fftypes.Data, optimiza
 			return err
 		}
 
-		existing := dataRows.Next()
+		existing = dataRows.Next()
 		if existing {
 			var hash *fftypes.Bytes32
 			_ = dataRows.Scan(&hash)
@@ -175,6 +387,15 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *fftypes.Data, optimiza
 		}
 	}
 
+	if !existing {
+		if err = s.indexDataForSearch(ctx, tx, data); err != nil {
+			return err
+		}
+	}
+	if err = s.updateDataIndexes(ctx, tx, data, nil, existing); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -186,6 +407,7 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray fftypes.DataA
 	}
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
+	indexCache := make(map[string]fftypes.FFStringArray)
 	if s.features.MultiRowInsert {
 		query := sq.Insert("data").Columns(dataColumnsWithValue...)
 		for _, data := range dataArray {
@@ -200,6 +422,14 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray fftypes.DataA
 		if err != nil {
 			return err
 		}
+		for _, data := range dataArray {
+			if err = s.indexDataForSearch(ctx, tx, data); err != nil {
+				return err
+			}
+			if err = s.updateDataIndexes(ctx, tx, data, indexCache, false); err != nil {
+				return err
+			}
+		}
 	} else {
 		// Fall back to individual inserts grouped in a TX
 		for _, data := range dataArray {
@@ -207,6 +437,12 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray fftypes.DataA
 			if err != nil {
 				return err
 			}
+			if err = s.indexDataForSearch(ctx, tx, data); err != nil {
+				return err
+			}
+			if err = s.updateDataIndexes(ctx, tx, data, indexCache, false); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -231,10 +467,12 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.Blob.Public,
 		&data.Blob.Name,
 		&data.Blob.Size,
+		&data.Blob.HashAlgorithm,
 		&data.ValueSize,
+		&data.MediaType,
 	}
 	if withValue {
-		results = append(results, &data.Value)
+		results = append(results, &data.Value, &data.Original)
 	}
 	err := row.Scan(results...)
 	if data.Blob.Hash == nil && data.Blob.Public == "" {
@@ -282,6 +520,10 @@ func (s *SQLCommon) GetDataByID(ctx context.Context, id *fftypes.UUID, withValue
 
 func (s *SQLCommon) GetData(ctx context.Context, filter database.Filter) (message fftypes.DataArray, res *database.FilterResult, err error) {
 
+	if err := s.checkDataIndexedPaths(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+
 	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(dataColumnsWithValue...).From("data"), filter, dataFilterFieldMap, []interface{}{"sequence"})
 	if err != nil {
 		return nil, nil, err
@@ -308,6 +550,10 @@ func (s *SQLCommon) GetData(ctx context.Context, filter database.Filter) (messag
 
 func (s *SQLCommon) GetDataRefs(ctx context.Context, filter database.Filter) (message fftypes.DataRefs, res *database.FilterResult, err error) {
 
+	if err := s.checkDataIndexedPaths(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+
 	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select("id", "hash").From("data"), filter, dataFilterFieldMap, []interface{}{"sequence"})
 	if err != nil {
 		return nil, nil, err
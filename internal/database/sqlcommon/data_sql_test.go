@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -92,14 +93,17 @@ func TestDataE2EWithDB(t *testing.T) {
 			Name:    "customer",
 			Version: "0.0.1",
 		},
-		Hash:    fftypes.NewRandB32(),
-		Created: fftypes.Now(),
-		Value:   fftypes.JSONAnyPtr(val2.String()),
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(val2.String()),
+		MediaType: fftypes.DataMediaTypeXML,
+		Original:  []byte("<another>set</another>"),
 		Blob: &fftypes.BlobRef{
-			Hash:   fftypes.NewRandB32(),
-			Public: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
-			Name:   "path/to/myfile.ext",
-			Size:   12345,
+			Hash:          fftypes.NewRandB32(),
+			HashAlgorithm: fftypes.HashAlgorithmSHA3256,
+			Public:        "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+			Name:          "path/to/myfile.ext",
+			Size:          12345,
 		},
 	}
 
@@ -133,6 +137,7 @@ func TestDataE2EWithDB(t *testing.T) {
 		fb.Eq("datatype.name", dataUpdated.Datatype.Name),
 		fb.Eq("datatype.version", dataUpdated.Datatype.Version),
 		fb.Eq("hash", dataUpdated.Hash),
+		fb.Eq("mediatype", dataUpdated.MediaType),
 		fb.Gt("created", 0),
 	)
 	dataRes, _, err := s.GetData(ctx, filter)
@@ -166,6 +171,229 @@ func TestDataE2EWithDB(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestDataIndexesE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	// Create a datatype with some indexes
+	datatype := &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Message:   fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "order",
+		Version:   "0.0.1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{}`),
+		Indexes:   fftypes.FFStringArray{"orderId", "customer.name"},
+	}
+	err := s.UpsertDatatype(ctx, datatype, false)
+	assert.NoError(t, err)
+
+	newOrder := func(orderID, customer string) *fftypes.Data {
+		val := fftypes.JSONObject{
+			"orderId": orderID,
+			"customer": map[string]interface{}{
+				"name": customer,
+			},
+		}
+		return &fftypes.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: fftypes.ValidatorTypeJSON,
+			Namespace: "ns1",
+			Datatype: &fftypes.DatatypeRef{
+				Name:    "order",
+				Version: "0.0.1",
+			},
+			Hash:    fftypes.NewRandB32(),
+			Created: fftypes.Now(),
+			Value:   fftypes.JSONAnyPtr(val.String()),
+		}
+	}
+
+	data1 := newOrder("order1", "acme")
+	err = s.UpsertData(ctx, data1, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	data2 := newOrder("order2", "acme")
+	data3 := newOrder("order3", "widgets")
+	err = s.InsertDataArray(ctx, fftypes.DataArray{data2, data3})
+	assert.NoError(t, err)
+
+	// Query on the indexed values
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	dataRes, _, err := s.GetData(ctx, fb.Eq("value.orderId", "order1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dataRes))
+	assert.Equal(t, *data1.ID, *dataRes[0].ID)
+
+	dataRes, _, err = s.GetData(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("value.customer.name", "acme"),
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(dataRes))
+	assert.Equal(t, *data2.ID, *dataRes[0].ID)
+	assert.Equal(t, *data1.ID, *dataRes[1].ID)
+
+	// Update the value, and check the index is replaced
+	data3.Value = newOrder("order3", "acme").Value
+	err = s.UpsertData(ctx, data3, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, fb.Eq("value.customer.name", "acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(dataRes))
+	dataRes, _, err = s.GetData(ctx, fb.Eq("value.customer.name", "widgets"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(dataRes))
+
+	// Non-indexed paths are rejected
+	_, _, err = s.GetData(ctx, fb.Eq("value.other", "order1"))
+	assert.Regexp(t, "FF10580.*other", err)
+	_, _, err = s.GetDataRefs(ctx, fb.Or(fb.Eq("value.orderId", "order1"), fb.Eq("value.other", "order1")))
+	assert.Regexp(t, "FF10580.*other", err)
+
+	// Data stored before its datatype is indexed when the datatype arrives
+	invoice := newOrder("order4", "acme")
+	invoice.Datatype.Name = "invoice"
+	err = s.UpsertData(ctx, invoice, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	invoiceType := &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Message:   fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "invoice",
+		Version:   "0.0.1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{}`),
+		Indexes:   fftypes.FFStringArray{"orderId"},
+	}
+	err = s.UpsertDatatype(ctx, invoiceType, false)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, fb.Eq("value.orderId", "order4"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dataRes))
+	assert.Equal(t, *invoice.ID, *dataRes[0].ID)
+
+	// Changing the indexes of a datatype rebuilds the indexes of its data
+	datatype.Indexes = fftypes.FFStringArray{"customer.name"}
+	err = s.UpsertDatatype(ctx, datatype, true)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, fb.Eq("value.orderId", "order1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(dataRes))
+	dataRes, _, err = s.GetData(ctx, fb.Eq("value.customer.name", "acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(dataRes))
+}
+
+func TestBackfillDataIndexesPaged(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	data := make(fftypes.DataArray, dataIndexBackfillPageSize+1)
+	for i := range data {
+		data[i] = &fftypes.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: fftypes.ValidatorTypeJSON,
+			Namespace: "ns1",
+			Datatype:  &fftypes.DatatypeRef{Name: "widget", Version: "1"},
+			Hash:      fftypes.NewRandB32(),
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(fmt.Sprintf(`{"idx":"%d"}`, i)),
+		}
+	}
+	err := s.InsertDataArray(ctx, data)
+	assert.NoError(t, err)
+
+	err = s.UpsertDatatype(ctx, &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Message:   fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "widget",
+		Version:   "1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{}`),
+		Indexes:   fftypes.FFStringArray{"idx"},
+	}, false)
+	assert.NoError(t, err)
+
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	dataRes, _, err := s.GetData(ctx, fb.Eq("value.idx", fmt.Sprintf("%d", dataIndexBackfillPageSize)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dataRes))
+	assert.Equal(t, *data[dataIndexBackfillPageSize].ID, *dataRes[0].ID)
+}
+
+func TestBackfillDataIndexesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	tx, err := s.db.Begin()
+	assert.NoError(t, err)
+	err = s.backfillDataIndexes(context.Background(), &txWrapper{sqlTX: tx}, &fftypes.Datatype{})
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestBackfillDataIndexesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(1))
+	tx, err := s.db.Begin()
+	assert.NoError(t, err)
+	err = s.backfillDataIndexes(context.Background(), &txWrapper{sqlTX: tx}, &fftypes.Datatype{})
+	assert.Regexp(t, "FF10121", err)
+}
+
+func TestBackfillDataIndexesUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq", "id", "value"}).AddRow(1, fftypes.NewUUID().String(), `{}`))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	tx, err := s.db.Begin()
+	assert.NoError(t, err)
+	err = s.backfillDataIndexes(context.Background(), &txWrapper{sqlTX: tx}, &fftypes.Datatype{})
+	assert.Regexp(t, "FF10118", err)
+}
+
+func TestCheckDataIndexedPathsBadFilter(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("value.a", map[bool]bool{true: false})
+	_, _, err := s.GetData(context.Background(), f)
+	assert.Regexp(t, "FF10149", err)
+}
+
+func TestCheckDataIndexedPathsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("value.a", "b")
+	_, _, err := s.GetData(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckDataIndexedPathsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes", "other"}).AddRow("a", "b"))
+	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("value.a", "b")
+	_, _, err := s.GetDataRefs(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertDataFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -222,6 +450,83 @@ func TestUpsertDataFailCommit(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertDataFailIndexDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	dataID := fftypes.NewUUID()
+	dataHash := fftypes.NewRandB32()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(dataHash.String()))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), &fftypes.Data{ID: dataID, Hash: dataHash}, database.UpsertOptimizationSkip)
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDataFailIndexLookup(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), &fftypes.Data{
+		ID:       fftypes.NewUUID(),
+		Datatype: &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:    fftypes.JSONAnyPtr(`{"orderId":"order1"}`),
+	}, database.UpsertOptimizationNew)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDataFailIndexScan(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes", "extra"}).AddRow("orderId", "extra"))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), &fftypes.Data{
+		ID:       fftypes.NewUUID(),
+		Datatype: &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:    fftypes.JSONAnyPtr(`{"orderId":"order1"}`),
+	}, database.UpsertOptimizationNew)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDataFailIndexInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes"}).AddRow("orderId,missing"))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), &fftypes.Data{
+		ID:       fftypes.NewUUID(),
+		Datatype: &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:    fftypes.JSONAnyPtr(`{"orderId":"order1"}`),
+	}, database.UpsertOptimizationNew)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDataIndexValueTooLong(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes"}).AddRow("orderId"))
+	mock.ExpectCommit()
+	dataID := fftypes.NewUUID()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, fftypes.ChangeEventTypeCreated, "", dataID).Return()
+	err := s.UpsertData(context.Background(), &fftypes.Data{
+		ID:       dataID,
+		Datatype: &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:    fftypes.JSONAnyPtr(fmt.Sprintf(`{"orderId":"%s"}`, strings.Repeat("a", 1025))),
+	}, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestInsertDataArrayBeginFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -277,6 +582,40 @@ func TestInsertDataArraySingleRowFail(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestInsertDataArrayMultiRowIndexFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.features.MultiRowInsert = true
+	s.fakePSQLInsert = true
+	data1 := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Datatype:  &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:     fftypes.JSONAnyPtr(`{"orderId":"order1"}`),
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT.*").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}).AddRow(int64(1001)))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDataArray(context.Background(), fftypes.DataArray{data1})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDataArraySingleRowIndexFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	data1 := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Datatype:  &fftypes.DatatypeRef{Name: "order", Version: "0.0.1"},
+		Value:     fftypes.JSONAnyPtr(`{"orderId":"order1"}`),
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDataArray(context.Background(), fftypes.DataArray{data1})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetDataByIDSelectFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	dataID := fftypes.NewUUID()
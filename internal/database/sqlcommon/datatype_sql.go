@@ -39,9 +39,12 @@ var (
 		"hash",
 		"created",
 		"value",
+		"indexes",
+		"annotations",
 	}
 	datatypeFilterFieldMap = map[string]string{
-		"message": "message_id",
+		"message":       "message_id",
+		"annotations.*": "annotations",
 	}
 )
 
@@ -53,10 +56,11 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *fftypes.Dataty
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
 	existing := false
+	var existingIndexes fftypes.FFStringArray
 	if allowExisting {
 		// Do a select within the transaction to detemine if the UUID already exists
 		datatypeRows, _, err := s.queryTx(ctx, tx,
-			sq.Select("id").
+			sq.Select("indexes").
 				From("datatypes").
 				Where(sq.Eq{"id": datatype.ID}),
 		)
@@ -64,7 +68,13 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *fftypes.Dataty
 			return err
 		}
 		existing = datatypeRows.Next()
+		if existing {
+			err = datatypeRows.Scan(&existingIndexes)
+		}
 		datatypeRows.Close()
+		if err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "datatypes")
+		}
 	}
 
 	if existing {
@@ -80,6 +90,8 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *fftypes.Dataty
 				Set("hash", datatype.Hash).
 				Set("created", datatype.Created).
 				Set("value", datatype.Value).
+				Set("indexes", datatype.Indexes).
+				Set("annotations", datatype.Annotations).
 				Where(sq.Eq{"id": datatype.ID}),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionDataTypes, fftypes.ChangeEventTypeUpdated, datatype.Namespace, datatype.ID)
@@ -101,6 +113,8 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *fftypes.Dataty
 					datatype.Hash,
 					datatype.Created,
 					datatype.Value,
+					datatype.Indexes,
+					datatype.Annotations,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionDataTypes, fftypes.ChangeEventTypeCreated, datatype.Namespace, datatype.ID)
@@ -110,6 +124,17 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *fftypes.Dataty
 		}
 	}
 
+	if err = s.updateAnnotations(ctx, tx, datatype.ID, datatype.Namespace, datatype.Annotations, existing); err != nil {
+		return err
+	}
+
+	// Data of the datatype might already be stored, so needs indexing with any new or changed indexes
+	if existingIndexes.String() != datatype.Indexes.String() {
+		if err = s.backfillDataIndexes(ctx, tx, datatype); err != nil {
+			return err
+		}
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -125,6 +150,8 @@ func (s *SQLCommon) datatypeResult(ctx context.Context, row *sql.Rows) (*fftypes
 		&datatype.Hash,
 		&datatype.Created,
 		&datatype.Value,
+		&datatype.Indexes,
+		&datatype.Annotations,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "datatypes")
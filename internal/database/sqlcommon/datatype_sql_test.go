@@ -54,6 +54,10 @@ func TestDatatypeE2EWithDB(t *testing.T) {
 		Hash:      randB32,
 		Created:   fftypes.Now(),
 		Value:     fftypes.JSONAnyPtr(val.String()),
+		Annotations: fftypes.Annotations{
+			"owner": "team-b",
+			"env":   "dev",
+		},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDataTypes, fftypes.ChangeEventTypeCreated, "ns1", datatypeID, mock.Anything).Return()
@@ -88,6 +92,10 @@ func TestDatatypeE2EWithDB(t *testing.T) {
 		Hash:      randB32,
 		Created:   fftypes.Now(),
 		Value:     fftypes.JSONAnyPtr(val2.String()),
+		Indexes:   fftypes.FFStringArray{"some.field"},
+		Annotations: fftypes.Annotations{
+			"owner": "team-a",
+		},
 	}
 	err = s.UpsertDatatype(context.Background(), datatypeUpdated, true)
 	assert.NoError(t, err)
@@ -108,6 +116,7 @@ func TestDatatypeE2EWithDB(t *testing.T) {
 		fb.Eq("name", datatypeUpdated.Name),
 		fb.Eq("version", datatypeUpdated.Version),
 		fb.Gt("created", "0"),
+		fb.Eq("annotations.owner", "team-a"),
 	)
 	datatypes, res, err := s.GetDatatypes(ctx, filter.Count(true))
 	assert.NoError(t, err)
@@ -116,6 +125,15 @@ func TestDatatypeE2EWithDB(t *testing.T) {
 	datatypeReadJson, _ = json.Marshal(datatypes[0])
 	assert.Equal(t, string(datatypeJson), string(datatypeReadJson))
 
+	// The annotations from before the update no longer match
+	filter = fb.Or(
+		fb.Eq("annotations.owner", "team-b"),
+		fb.Eq("annotations.env", "dev"),
+	)
+	datatypes, _, err = s.GetDatatypes(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(datatypes))
+
 	// Update
 	v2 := "2.0.0"
 	up := database.DatatypeQueryFactory.NewUpdate(ctx).Set("version", v2)
@@ -177,6 +195,41 @@ func TestUpsertDatatypeFailUpdate(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertDatatypeFailAnnotations(t *testing.T) {
+	s, mock := newMockProvider().init()
+	datatypeID := fftypes.NewUUID()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(datatypeID.String()))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertDatatype(context.Background(), &fftypes.Datatype{ID: datatypeID}, true)
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDatatypeFailScanExisting(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes", "other"}).AddRow("a", "b"))
+	mock.ExpectRollback()
+	err := s.UpsertDatatype(context.Background(), &fftypes.Datatype{ID: fftypes.NewUUID()}, true)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDatatypeFailBackfill(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"indexes"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertDatatype(context.Background(), &fftypes.Datatype{ID: fftypes.NewUUID(), Indexes: fftypes.FFStringArray{"a"}}, true)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertDatatypeFailCommit(t *testing.T) {
 	s, mock := newMockProvider().init()
 	datatypeID := fftypes.NewUUID()
@@ -37,12 +37,14 @@ var (
 		"tx_id",
 		"topic",
 		"created",
+		"correlation_id",
 	}
 	eventFilterFieldMap = map[string]string{
-		"type":       "etype",
-		"reference":  "ref",
-		"correlator": "cid",
-		"tx":         "tx_id",
+		"type":          "etype",
+		"reference":     "ref",
+		"correlator":    "cid",
+		"tx":            "tx_id",
+		"correlationid": "correlation_id",
 	}
 )
 
@@ -65,7 +67,11 @@ func (s *SQLCommon) InsertEvent(ctx context.Context, event *fftypes.Event) (err
 		return err
 	}
 	event.Sequence = -1 // the sequence is not allocated until the post-commit callback
+	if event.CorrelationID == "" {
+		event.CorrelationID = log.CorrelationID(ctx)
+	}
 	s.addPreCommitEvent(tx, event)
+	s.addPreCommitSyncChange(tx, fftypes.SyncChangeTypeEvent, "events", event.ID)
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -79,6 +85,7 @@ func (s *SQLCommon) setEventInsertValues(query sq.InsertBuilder, event *fftypes.
 		event.Transaction,
 		event.Topic,
 		event.Created,
+		event.CorrelationID,
 	)
 }
 
@@ -138,6 +145,7 @@ func (s *SQLCommon) eventResult(ctx context.Context, row *sql.Rows) (*fftypes.Ev
 		&event.Transaction,
 		&event.Topic,
 		&event.Created,
+		&event.CorrelationID,
 		// Must be added to the list of columns in all selects
 		&event.Sequence,
 	)
@@ -223,3 +231,24 @@ func (s *SQLCommon) UpdateEvent(ctx context.Context, id *fftypes.UUID, update da
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteEvents(ctx context.Context, filter database.Filter) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.filterDelete(ctx, "", sq.Delete("events"), filter, eventFilterFieldMap)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteTx(ctx, tx, query, nil /* no change events on filter based delete */)
+	if err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,7 @@ func TestEventE2EWithDB(t *testing.T) {
 
 	s, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
-	ctx := context.Background()
+	ctx := log.WithCorrelationID(context.Background(), "corr1")
 
 	// Create a new event entry
 	eventID := fftypes.NewUUID()
@@ -68,6 +69,7 @@ func TestEventE2EWithDB(t *testing.T) {
 	filter := fb.And(
 		fb.Eq("id", eventRead.ID.String()),
 		fb.Eq("reference", eventRead.Reference.String()),
+		fb.Eq("correlationid", "corr1"),
 	)
 	events, res, err := s.GetEvents(ctx, filter.Count(true))
 	assert.NoError(t, err)
@@ -101,6 +103,17 @@ func TestEventE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(events))
 
+	// Delete by filter
+	err = s.DeleteEvents(ctx, fb.And(fb.Eq("reference", newUUID)))
+	assert.NoError(t, err)
+	events, _, err = s.GetEvents(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(events))
+
+	// Nothing to delete
+	err = s.DeleteEvents(ctx, fb.And(fb.Eq("reference", newUUID)))
+	assert.NoError(t, err)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -141,6 +154,8 @@ func TestInsertEventFailCommit(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec("LOCK .*").WillReturnResult(driver.ResultNoRows)
 	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("LOCK .*").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT INTO syncchanges .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
 	err := s.InsertEvent(context.Background(), &fftypes.Event{ID: eventID})
 	assert.Regexp(t, "FF10119", err)
@@ -283,3 +298,29 @@ func TestEventUpdateFail(t *testing.T) {
 	err := s.UpdateEvent(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestDeleteEventsBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	err := s.DeleteEvents(context.Background(), fb.And())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteEventsBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	err := s.DeleteEvents(context.Background(), fb.And(fb.Eq("id", map[bool]bool{true: false})))
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestDeleteEventsFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	err := s.DeleteEvents(context.Background(), fb.And(fb.Eq("type", fftypes.EventTypeMessageConfirmed)))
+	assert.Regexp(t, "FF10118", err)
+}
@@ -0,0 +1,157 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	feeColumns = []string{
+		"namespace",
+		"signing_key",
+		"day",
+		"tx_count",
+		"gas_used",
+		"total",
+		"updated",
+	}
+	feeFilterFieldMap = map[string]string{
+		"key":          "signing_key",
+		"transactions": "tx_count",
+	}
+)
+
+func (s *SQLCommon) UpsertFeeSummary(ctx context.Context, summary *fftypes.FeeSummary) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if there is already a summary for the day
+	feeRows, _, err := s.queryTx(ctx, tx,
+		sq.Select(sequenceColumn, "tx_count", "gas_used", "total").
+			From("fees").
+			Where(sq.Eq{
+				"namespace":   summary.Namespace,
+				"signing_key": summary.Key,
+				"day":         summary.Day,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := feeRows.Next()
+	var sequence, txCount int64
+	var gasUsed, total fftypes.FFBigInt
+	if existing {
+		err = feeRows.Scan(&sequence, &txCount, &gasUsed, &total)
+	}
+	feeRows.Close()
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "fees")
+	}
+
+	summary.Updated = fftypes.Now()
+	if existing {
+		existingFee := &fftypes.TransactionFee{GasUsed: &gasUsed, Total: &total}
+		sum := existingFee.Add(&fftypes.TransactionFee{GasUsed: summary.GasUsed, Total: summary.Total})
+		summary.Transactions += txCount
+		summary.GasUsed = sum.GasUsed
+		summary.Total = sum.Total
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("fees").
+				Set("tx_count", summary.Transactions).
+				Set("gas_used", summary.GasUsed).
+				Set("total", summary.Total).
+				Set("updated", summary.Updated).
+				Where(sq.Eq{sequenceColumn: sequence}),
+			nil, // no change events for fees
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("fees").
+				Columns(feeColumns...).
+				Values(
+					summary.Namespace,
+					summary.Key,
+					summary.Day,
+					summary.Transactions,
+					summary.GasUsed,
+					summary.Total,
+					summary.Updated,
+				),
+			nil, // no change events for fees
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) feeResult(ctx context.Context, row *sql.Rows) (*fftypes.FeeSummary, error) {
+	summary := fftypes.FeeSummary{}
+	err := row.Scan(
+		&summary.Namespace,
+		&summary.Key,
+		&summary.Day,
+		&summary.Transactions,
+		&summary.GasUsed,
+		&summary.Total,
+		&summary.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "fees")
+	}
+	return &summary, nil
+}
+
+func (s *SQLCommon) GetFeeSummaries(ctx context.Context, filter database.Filter) (summaries []*fftypes.FeeSummary, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(feeColumns...).From("fees"), filter, feeFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	summaries = []*fftypes.FeeSummary{}
+	for rows.Next() {
+		fs, err := s.feeResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		summaries = append(summaries, fs)
+	}
+
+	return summaries, s.queryRes(ctx, tx, "fees", fop, fi), err
+
+}
@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeSummariesE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Record a fee for the day
+	summary := &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-01",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(21000),
+		Total:        fftypes.NewFFBigInt(42000),
+	}
+	err := s.UpsertFeeSummary(ctx, summary)
+	assert.NoError(t, err)
+	assert.NotNil(t, summary.Updated)
+
+	// A second fee for the same key and day accumulates
+	err = s.UpsertFeeSummary(ctx, &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-01",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(50000),
+		Total:        fftypes.NewFFBigInt(100000),
+	})
+	assert.NoError(t, err)
+
+	// A fee on a different day is separate
+	err = s.UpsertFeeSummary(ctx, &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-02",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(1),
+		Total:        fftypes.NewFFBigInt(1),
+	})
+	assert.NoError(t, err)
+
+	// Query back the summaries
+	fb := database.FeeSummaryQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("key", "0x12345"),
+	)
+	summaries, res, err := s.GetFeeSummaries(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(summaries))
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	filter = fb.And(
+		fb.Eq("day", "2022-05-01"),
+		fb.Eq("transactions", 2),
+	)
+	summaries, _, err = s.GetFeeSummaries(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, "0x12345", summaries[0].Key)
+	assert.Equal(t, int64(71000), summaries[0].GasUsed.Int().Int64())
+	assert.Equal(t, int64(142000), summaries[0].Total.Int().Int64())
+}
+
+func TestUpsertFeeSummaryFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertFeeSummary(context.Background(), &fftypes.FeeSummary{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertFeeSummaryFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertFeeSummary(context.Background(), &fftypes.FeeSummary{})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertFeeSummaryFailScan(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}).AddRow())
+	mock.ExpectRollback()
+	err := s.UpsertFeeSummary(context.Background(), &fftypes.FeeSummary{})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertFeeSummaryFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertFeeSummary(context.Background(), &fftypes.FeeSummary{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertFeeSummaryFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence", "tx_count", "gas_used", "total"}).AddRow(int64(12345), int64(1), "100", "200"))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertFeeSummary(context.Background(), &fftypes.FeeSummary{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFeeSummariesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.FeeSummaryQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetFeeSummaries(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFeeSummariesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.FeeSummaryQueryFactory.NewFilter(context.Background()).Eq("namespace", map[bool]bool{true: false})
+	_, _, err := s.GetFeeSummaries(context.Background(), f)
+	assert.Regexp(t, "FF10149.*namespace", err)
+}
+
+func TestGetFeeSummariesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	f := database.FeeSummaryQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetFeeSummaries(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
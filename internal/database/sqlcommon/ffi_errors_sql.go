@@ -0,0 +1,135 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	ffiErrorsColumns = []string{
+		"id",
+		"interface_id",
+		"namespace",
+		"name",
+		"pathname",
+		"description",
+		"params",
+	}
+	ffiErrorFilterFieldMap = map[string]string{
+		"interface": "interface_id",
+	}
+)
+
+func (s *SQLCommon) UpsertFFIError(ctx context.Context, errorDef *fftypes.FFIError) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id").
+			From("ffierrors").
+			Where(sq.And{sq.Eq{"interface_id": errorDef.Contract}, sq.Eq{"namespace": errorDef.Namespace}, sq.Eq{"pathname": errorDef.Pathname}}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := rows.Next()
+	rows.Close()
+
+	if existing {
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("ffierrors").
+				Set("params", errorDef.Params).
+				Where(sq.And{sq.Eq{"interface_id": errorDef.Contract}, sq.Eq{"namespace": errorDef.Namespace}, sq.Eq{"pathname": errorDef.Pathname}}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIErrors, fftypes.ChangeEventTypeUpdated, errorDef.Namespace, errorDef.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("ffierrors").
+				Columns(ffiErrorsColumns...).
+				Values(
+					errorDef.ID,
+					errorDef.Contract,
+					errorDef.Namespace,
+					errorDef.Name,
+					errorDef.Pathname,
+					errorDef.Description,
+					errorDef.Params,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIErrors, fftypes.ChangeEventTypeCreated, errorDef.Namespace, errorDef.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) ffiErrorResult(ctx context.Context, row *sql.Rows) (*fftypes.FFIError, error) {
+	errorDef := fftypes.FFIError{}
+	err := row.Scan(
+		&errorDef.ID,
+		&errorDef.Contract,
+		&errorDef.Namespace,
+		&errorDef.Name,
+		&errorDef.Pathname,
+		&errorDef.Description,
+		&errorDef.Params,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "ffierrors")
+	}
+	return &errorDef, nil
+}
+
+func (s *SQLCommon) GetFFIErrors(ctx context.Context, filter database.Filter) (errors []*fftypes.FFIError, res *database.FilterResult, err error) {
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(ffiErrorsColumns...).From("ffierrors"), filter, ffiErrorFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := s.ffiErrorResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		errors = append(errors, e)
+	}
+
+	return errors, s.queryRes(ctx, tx, "ffierrors", fop, fi), err
+}
@@ -35,9 +35,11 @@ var (
 		"version",
 		"description",
 		"message_id",
+		"annotations",
 	}
 	ffiFilterFieldMap = map[string]string{
-		"message": "message_id",
+		"message":       "message_id",
+		"annotations.*": "annotations",
 	}
 )
 
@@ -66,7 +68,8 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *fftypes.FFI) (err error)
 				Set("name", ffi.Name).
 				Set("version", ffi.Version).
 				Set("description", ffi.Description).
-				Set("message_id", ffi.Message),
+				Set("message_id", ffi.Message).
+				Set("annotations", ffi.Annotations),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIs, fftypes.ChangeEventTypeUpdated, ffi.Namespace, ffi.ID)
 			},
@@ -84,6 +87,7 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *fftypes.FFI) (err error)
 					ffi.Version,
 					ffi.Description,
 					ffi.Message,
+					ffi.Annotations,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIs, fftypes.ChangeEventTypeCreated, ffi.Namespace, ffi.ID)
@@ -93,6 +97,10 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *fftypes.FFI) (err error)
 		}
 	}
 
+	if err = s.updateAnnotations(ctx, tx, ffi.ID, ffi.Namespace, ffi.Annotations, existing); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -105,6 +113,7 @@ func (s *SQLCommon) ffiResult(ctx context.Context, row *sql.Rows) (*fftypes.FFI,
 		&ffi.Version,
 		&ffi.Description,
 		&ffi.Message,
+		&ffi.Annotations,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "ffi")
@@ -66,6 +66,7 @@ func TestFFIE2EWithDB(t *testing.T) {
 				},
 			},
 		},
+		Annotations: fftypes.Annotations{"owner": "team-a"},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionFFIs, fftypes.ChangeEventTypeCreated, "ns1", ffi.ID).Return()
@@ -83,6 +84,7 @@ func TestFFIE2EWithDB(t *testing.T) {
 	assert.Equal(t, ffi.Name, dataRead.Name)
 	assert.Equal(t, ffi.Version, dataRead.Version)
 	assert.Equal(t, ffi.Message, dataRead.Message)
+	assert.Equal(t, ffi.Annotations, dataRead.Annotations)
 
 	ffi.Version = "v1.1.0"
 
@@ -98,6 +100,15 @@ func TestFFIE2EWithDB(t *testing.T) {
 	assert.Equal(t, ffi.Name, dataRead.Name)
 	assert.Equal(t, ffi.Version, dataRead.Version)
 	assert.Equal(t, ffi.Message, dataRead.Message)
+
+	// Query back by annotation
+	fb := database.FFIQueryFactory.NewFilter(ctx)
+	ffis, _, err := s.GetFFIs(ctx, "ns1", fb.Eq("annotations.owner", "team-a"))
+	assert.NoError(t, err)
+	assert.Len(t, ffis, 1)
+	ffis, _, err = s.GetFFIs(ctx, "ns1", fb.Eq("annotations.owner", "team-b"))
+	assert.NoError(t, err)
+	assert.Len(t, ffis, 0)
 }
 
 func TestFFIDBFailBeginTransaction(t *testing.T) {
@@ -117,6 +128,22 @@ func TestFFIDBFailSelect(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestFFIDBFailAnnotations(t *testing.T) {
+	rows := sqlmock.NewRows([]string{"id"}).AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2")
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	ffi := &fftypes.FFI{
+		ID: fftypes.NewUUID(),
+	}
+	err := s.UpsertFFI(context.Background(), ffi)
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestFFIDBFailInsert(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "namespace", "name", "version"})
 	s, mock := newMockProvider().init()
@@ -175,7 +202,7 @@ func TestGetFFIs(t *testing.T) {
 	fb := database.FFIQueryFactory.NewFilter(context.Background())
 	s, mock := newMockProvider().init()
 	rows := sqlmock.NewRows(ffiColumns).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	_, _, err := s.GetFFIs(context.Background(), "ns1", fb.And())
 	assert.NoError(t, err)
@@ -213,7 +240,7 @@ func TestGetFFIsQueryResultFail(t *testing.T) {
 func TestGetFFI(t *testing.T) {
 	s, mock := newMockProvider().init()
 	rows := sqlmock.NewRows(ffiColumns).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	ffi, err := s.GetFFI(context.Background(), "ns1", "math", "v1.0.0")
 	assert.NoError(t, err)
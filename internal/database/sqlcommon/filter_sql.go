@@ -113,6 +113,18 @@ func (s *SQLCommon) filterUpdate(ctx context.Context, tableName string, update s
 	return update.Where(fop), nil
 }
 
+func (s *SQLCommon) filterDelete(ctx context.Context, tableName string, del sq.DeleteBuilder, filter database.Filter, typeMap map[string]string) (sq.DeleteBuilder, error) {
+	fi, err := filter.Finalize()
+	var fop sq.Sqlizer
+	if err == nil {
+		fop, err = s.filterOp(ctx, tableName, fi, typeMap)
+	}
+	if err != nil {
+		return del, err
+	}
+	return del.Where(fop), nil
+}
+
 func (s *SQLCommon) escapeLike(value database.FieldSerialization) string {
 	v, _ := value.Value()
 	vs, _ := v.(string)
@@ -157,7 +169,48 @@ func (s *SQLCommon) newNotILike(field, value string) sq.Sqlizer {
 	return sq.NotLike{fmt.Sprintf("lower(%s)", field): strings.ToLower(value)}
 }
 
+// indexTableIDColumns are the columns in each index table that reference the row in the main table
+var indexTableIDColumns = map[string]string{
+	"data_index":  "data_id",
+	"annotations": "resource_id",
+}
+
+// indexedField checks if the field matches a wildcard entry in the type map, such as "value.*",
+// which maps to a separate index table of path/value pairs
+func (s *SQLCommon) indexedField(fieldName string, tm map[string]string) (indexTable, path string, ok bool) {
+	for k, v := range tm {
+		if strings.HasSuffix(k, database.WildcardFieldSuffix) {
+			prefix := strings.TrimSuffix(k, "*")
+			if len(fieldName) > len(prefix) && strings.EqualFold(fieldName[0:len(prefix)], prefix) {
+				return v, fieldName[len(prefix):], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// filterIndexedField performs the filter against the value stored for the path in the index table,
+// matching any rows in the main table that have an entry in the index table matching the filter
+func (s *SQLCommon) filterIndexedField(ctx context.Context, tableName, indexTable, path string, op *database.FilterInfo) (sq.Sqlizer, error) {
+	valueOp := *op
+	valueOp.Field = "value"
+	valueFilter, err := s.filterOp(ctx, indexTable, &valueOp, nil)
+	if err != nil {
+		return nil, err
+	}
+	subQuery := sq.Select(fmt.Sprintf("%s.%s", indexTable, indexTableIDColumns[indexTable])).
+		From(indexTable).
+		Where(sq.And{
+			sq.Eq{fmt.Sprintf("%s.path", indexTable): path},
+			valueFilter,
+		})
+	return sq.Expr(fmt.Sprintf("%s IN (?)", s.mapField(tableName, "id", nil)), subQuery), nil
+}
+
 func (s *SQLCommon) filterOp(ctx context.Context, tableName string, op *database.FilterInfo, tm map[string]string) (sq.Sqlizer, error) {
+	if indexTable, path, ok := s.indexedField(op.Field, tm); ok {
+		return s.filterIndexedField(ctx, tableName, indexTable, path, op)
+	}
 	switch op.Op {
 	case database.FilterOpOr:
 		return s.filterOr(ctx, tableName, op, tm)
@@ -182,7 +235,7 @@ func (s *SQLCommon) filterOp(ctx context.Context, tableName string, op *database
 	case database.FilterOpICont:
 		return s.newILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpNotICont:
-		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%s%%", s.escapeLike(op.Value))), nil
+		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s%%", s.escapeLike(op.Value))), nil
 	case database.FilterOpStartsWith:
 		return sq.Like{s.mapField(tableName, op.Field, tm): fmt.Sprintf("%s%%", s.escapeLike(op.Value))}, nil
 	case database.FilterOpNotStartsWith:
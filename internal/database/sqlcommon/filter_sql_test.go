@@ -61,6 +61,34 @@ func TestSQLQueryFactory(t *testing.T) {
 	assert.Equal(t, int64(12345), args[3])
 }
 
+func TestSQLQueryFactoryIndexedField(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.DataQueryFactory.NewFilter(context.Background())
+	f := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("value.orderId", "order1"),
+		fb.IContains("Value.customer.name", "acme"),
+	)
+
+	sel := squirrel.Select("*").From("data")
+	sel, _, _, err := s.filterSelect(context.Background(), "", sel, f, dataFilterFieldMap, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM data WHERE (namespace = ? AND id IN (SELECT data_index.data_id FROM data_index WHERE (data_index.path = ? AND data_index.value = ?)) AND id IN (SELECT data_index.data_id FROM data_index WHERE (data_index.path = ? AND data_index.value ILIKE ?))) ORDER BY seq DESC", sqlFilter)
+	assert.Equal(t, []interface{}{"ns1", "orderId", "order1", "customer.name", "%acme%"}, args)
+}
+
+func TestSQLQueryFactoryIndexedFieldBadOp(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, err := s.filterOp(context.Background(), "", &database.FilterInfo{
+		Op:    database.FilterOp("wrong"),
+		Field: "value.orderId",
+	}, dataFilterFieldMap)
+	assert.Regexp(t, "FF10150.*wrong", err)
+}
+
 func TestSQLQueryFactoryExtraOps(t *testing.T) {
 
 	s, _ := newMockProvider().init()
@@ -0,0 +1,211 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	groupAliasColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"group_hash",
+		"created",
+		"updated",
+	}
+	groupAliasFilterFieldMap = map[string]string{
+		"group": "group_hash",
+	}
+)
+
+func (s *SQLCommon) UpsertGroupAlias(ctx context.Context, alias *fftypes.GroupAlias, allowExisting bool) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	existing := false
+	if allowExisting {
+		// Do a select within the transaction to detemine if the name already exists
+		aliasRows, _, err := s.queryTx(ctx, tx,
+			sq.Select("id").
+				From("groupaliases").
+				Where(sq.Eq{
+					"namespace": alias.Namespace,
+					"name":      alias.Name,
+				}),
+		)
+		if err != nil {
+			return err
+		}
+
+		existing = aliasRows.Next()
+		if existing {
+			var id fftypes.UUID
+			_ = aliasRows.Scan(&id)
+			if alias.ID != nil && *alias.ID != id {
+				aliasRows.Close()
+				return database.IDMismatch
+			}
+			alias.ID = &id // Update on returned object
+		}
+		aliasRows.Close()
+	}
+
+	if existing {
+		// Update the group alias
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("groupaliases").
+				// Note we do not update ID or created
+				Set("group_hash", alias.Group).
+				Set("updated", alias.Updated).
+				Where(sq.Eq{"id": alias.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionGroupAliases, fftypes.ChangeEventTypeUpdated, alias.Namespace, alias.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		if alias.ID == nil {
+			alias.ID = fftypes.NewUUID()
+		}
+
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("groupaliases").
+				Columns(groupAliasColumns...).
+				Values(
+					alias.ID,
+					alias.Namespace,
+					alias.Name,
+					alias.Group,
+					alias.Created,
+					alias.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionGroupAliases, fftypes.ChangeEventTypeCreated, alias.Namespace, alias.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) groupAliasResult(ctx context.Context, row *sql.Rows) (*fftypes.GroupAlias, error) {
+	alias := fftypes.GroupAlias{}
+	err := row.Scan(
+		&alias.ID,
+		&alias.Namespace,
+		&alias.Name,
+		&alias.Group,
+		&alias.Created,
+		&alias.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "groupaliases")
+	}
+	return &alias, nil
+}
+
+func (s *SQLCommon) getGroupAliasEq(ctx context.Context, eq sq.Eq, textName string) (*fftypes.GroupAlias, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(groupAliasColumns...).
+			From("groupaliases").
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Group alias '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.groupAliasResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetGroupAliasByID(ctx context.Context, id *fftypes.UUID) (*fftypes.GroupAlias, error) {
+	return s.getGroupAliasEq(ctx, sq.Eq{"id": id}, id.String())
+}
+
+func (s *SQLCommon) GetGroupAliasByName(ctx context.Context, ns, name string) (*fftypes.GroupAlias, error) {
+	return s.getGroupAliasEq(ctx, sq.Eq{"namespace": ns, "name": name}, fmt.Sprintf("%s:%s", ns, name))
+}
+
+func (s *SQLCommon) GetGroupAliases(ctx context.Context, filter database.Filter) ([]*fftypes.GroupAlias, *database.FilterResult, error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(groupAliasColumns...).From("groupaliases"), filter, groupAliasFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	aliases := []*fftypes.GroupAlias{}
+	for rows.Next() {
+		alias, err := s.groupAliasResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, s.queryRes(ctx, tx, "groupaliases", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteGroupAliasByID(ctx context.Context, id *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	alias, err := s.GetGroupAliasByID(ctx, id)
+	if err == nil && alias != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("groupaliases").Where(sq.Eq{
+			"id": id,
+		}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionGroupAliases, fftypes.ChangeEventTypeDeleted, alias.Namespace, alias.ID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
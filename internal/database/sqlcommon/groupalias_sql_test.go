@@ -0,0 +1,225 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGroupAliasesE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new group alias
+	alias := &fftypes.GroupAlias{
+		Namespace: "ns1",
+		Name:      "alias1",
+		Group:     fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionGroupAliases, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+
+	err := s.UpsertGroupAlias(ctx, alias, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, alias.ID)
+
+	// Check we get the exact same alias back
+	aliasRead, err := s.GetGroupAliasByName(ctx, alias.Namespace, alias.Name)
+	assert.NoError(t, err)
+	aliasJson, _ := json.Marshal(&alias)
+	aliasReadJson, _ := json.Marshal(&aliasRead)
+	assert.Equal(t, string(aliasJson), string(aliasReadJson))
+
+	// Update the alias by name
+	aliasUpdated := &fftypes.GroupAlias{
+		ID:        fftypes.NewUUID(), // will fail with us trying to update this
+		Namespace: "ns1",
+		Name:      "alias1",
+		Group:     fftypes.NewRandB32(),
+		Created:   alias.Created,
+		Updated:   fftypes.Now(),
+	}
+
+	// Rejects attempt to update ID
+	err = s.UpsertGroupAlias(context.Background(), aliasUpdated, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	// Blank out the ID and retry
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionGroupAliases, fftypes.ChangeEventTypeUpdated, "ns1", alias.ID).Return()
+	aliasUpdated.ID = nil
+	err = s.UpsertGroupAlias(context.Background(), aliasUpdated, true)
+	assert.NoError(t, err)
+	assert.Equal(t, alias.ID, aliasUpdated.ID)
+
+	aliasRead, err = s.GetGroupAliasByID(ctx, alias.ID)
+	assert.NoError(t, err)
+	aliasJson, _ = json.Marshal(&aliasUpdated)
+	aliasReadJson, _ = json.Marshal(&aliasRead)
+	assert.Equal(t, string(aliasJson), string(aliasReadJson))
+
+	// Query back the alias
+	fb := database.GroupAliasQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("group", aliasUpdated.Group),
+	)
+	aliases, res, err := s.GetGroupAliases(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(aliases))
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	// Test delete, and refind no return
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionGroupAliases, fftypes.ChangeEventTypeDeleted, "ns1", alias.ID).Return()
+	err = s.DeleteGroupAliasByID(ctx, alias.ID)
+	assert.NoError(t, err)
+	aliases, _, err = s.GetGroupAliases(ctx, database.GroupAliasQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(aliases))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertGroupAliasFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertGroupAlias(context.Background(), &fftypes.GroupAlias{}, true)
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertGroupAliasFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertGroupAlias(context.Background(), &fftypes.GroupAlias{Name: "name1"}, true)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertGroupAliasFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertGroupAlias(context.Background(), &fftypes.GroupAlias{Name: "name1"}, false)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertGroupAliasFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).
+		AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertGroupAlias(context.Background(), &fftypes.GroupAlias{Name: "name1"}, true)
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertGroupAliasFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertGroupAlias(context.Background(), &fftypes.GroupAlias{Name: "name1"}, true)
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGroupAliasByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetGroupAliasByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGroupAliasByNameNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(groupAliasColumns))
+	alias, err := s.GetGroupAliasByName(context.Background(), "ns1", "name1")
+	assert.NoError(t, err)
+	assert.Nil(t, alias)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGroupAliasByNameScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetGroupAliasByName(context.Background(), "ns1", "name1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGroupAliasesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.GroupAliasQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetGroupAliases(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGroupAliasesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.GroupAliasQueryFactory.NewFilter(context.Background()).Eq("name", map[bool]bool{true: false})
+	_, _, err := s.GetGroupAliases(context.Background(), f)
+	assert.Regexp(t, "FF10149.*type", err)
+}
+
+func TestGetGroupAliasesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.GroupAliasQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetGroupAliases(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupAliasDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteGroupAliasByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestGroupAliasDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(groupAliasColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "alias1", fftypes.NewRandB32(), fftypes.Now(), fftypes.Now()),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteGroupAliasByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
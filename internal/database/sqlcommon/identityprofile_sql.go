@@ -0,0 +1,125 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	identityProfileColumns = []string{
+		"identity_id",
+		"author",
+		"message_id",
+		"profile",
+		"updated",
+	}
+)
+
+func (s *SQLCommon) UpsertIdentityPrivateProfile(ctx context.Context, profile *fftypes.IdentityPrivateProfile) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the identity already has private fields
+	profileRows, _, err := s.queryTx(ctx, tx,
+		sq.Select(sequenceColumn).
+			From("identityprofiles").
+			Where(sq.Eq{"identity_id": profile.Identity}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := profileRows.Next()
+	profileRows.Close()
+
+	profile.Updated = fftypes.Now()
+	if existing {
+		// Update the private fields
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("identityprofiles").
+				Set("author", profile.Author).
+				Set("message_id", profile.Message).
+				Set("profile", profile.Profile).
+				Set("updated", profile.Updated).
+				Where(sq.Eq{"identity_id": profile.Identity}),
+			nil, // no change events for private profiles
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("identityprofiles").
+				Columns(identityProfileColumns...).
+				Values(
+					profile.Identity,
+					profile.Author,
+					profile.Message,
+					profile.Profile,
+					profile.Updated,
+				),
+			nil, // no change events for private profiles
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) identityProfileResult(ctx context.Context, row *sql.Rows) (*fftypes.IdentityPrivateProfile, error) {
+	profile := fftypes.IdentityPrivateProfile{}
+	err := row.Scan(
+		&profile.Identity,
+		&profile.Author,
+		&profile.Message,
+		&profile.Profile,
+		&profile.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "identityprofiles")
+	}
+	return &profile, nil
+}
+
+func (s *SQLCommon) GetIdentityPrivateProfile(ctx context.Context, identity *fftypes.UUID) (profile *fftypes.IdentityPrivateProfile, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(identityProfileColumns...).
+			From("identityprofiles").
+			Where(sq.Eq{"identity_id": identity}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Private profile for identity '%s' not found", identity)
+		return nil, nil
+	}
+
+	return s.identityProfileResult(ctx, rows)
+}
@@ -0,0 +1,129 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityPrivateProfileE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Store the private fields for an identity
+	profile := &fftypes.IdentityPrivateProfile{
+		Identity: fftypes.NewUUID(),
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Profile:  fftypes.JSONObject{"phone": "555-1234"},
+	}
+	err := s.UpsertIdentityPrivateProfile(ctx, profile)
+	assert.NoError(t, err)
+	assert.NotNil(t, profile.Updated)
+
+	profileRead, err := s.GetIdentityPrivateProfile(ctx, profile.Identity)
+	assert.NoError(t, err)
+	assert.Equal(t, profile.Author, profileRead.Author)
+	assert.Equal(t, *profile.Message, *profileRead.Message)
+	assert.Equal(t, "555-1234", profileRead.Profile.GetString("phone"))
+
+	// Replace the fields
+	profileUpdated := &fftypes.IdentityPrivateProfile{
+		Identity: profile.Identity,
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Profile:  fftypes.JSONObject{"email": "org1@example.com"},
+	}
+	err = s.UpsertIdentityPrivateProfile(ctx, profileUpdated)
+	assert.NoError(t, err)
+
+	profileRead, err = s.GetIdentityPrivateProfile(ctx, profile.Identity)
+	assert.NoError(t, err)
+	assert.Equal(t, *profileUpdated.Message, *profileRead.Message)
+	assert.Equal(t, fftypes.JSONObject{"email": "org1@example.com"}, profileRead.Profile)
+
+	// Identities without private fields
+	profileRead, err = s.GetIdentityPrivateProfile(ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, profileRead)
+}
+
+func TestUpsertIdentityPrivateProfileFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertIdentityPrivateProfile(context.Background(), &fftypes.IdentityPrivateProfile{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertIdentityPrivateProfileFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertIdentityPrivateProfile(context.Background(), &fftypes.IdentityPrivateProfile{Identity: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertIdentityPrivateProfileFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertIdentityPrivateProfile(context.Background(), &fftypes.IdentityPrivateProfile{Identity: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertIdentityPrivateProfileFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(12345)))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertIdentityPrivateProfile(context.Background(), &fftypes.IdentityPrivateProfile{Identity: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentityPrivateProfileSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetIdentityPrivateProfile(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentityPrivateProfileScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"identity_id"}).AddRow("only one"))
+	_, err := s.GetIdentityPrivateProfile(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,148 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	messageRecipientColumns = []string{
+		"namespace",
+		"message_id",
+		"node_id",
+		"status",
+		"created",
+		"updated",
+	}
+	messageRecipientFilterFieldMap = map[string]string{
+		"message": "message_id",
+		"node":    "node_id",
+	}
+)
+
+func (s *SQLCommon) UpsertMessageRecipient(ctx context.Context, recipient *fftypes.MessageRecipient) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the recipient already exists
+	recipientRows, _, err := s.queryTx(ctx, tx,
+		sq.Select(sequenceColumn).
+			From("message_recipients").
+			Where(sq.Eq{
+				"message_id": recipient.Message,
+				"node_id":    recipient.Node,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := recipientRows.Next()
+	var sequence int64
+	if existing {
+		err = recipientRows.Scan(&sequence)
+	}
+	recipientRows.Close()
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "message_recipients")
+	}
+
+	recipient.Updated = fftypes.Now()
+	if existing {
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("message_recipients").
+				Set("status", recipient.Status).
+				Set("updated", recipient.Updated).
+				Where(sq.Eq{sequenceColumn: sequence}),
+			nil, // no change events for message recipients
+		); err != nil {
+			return err
+		}
+	} else {
+		if recipient.Created == nil {
+			recipient.Created = recipient.Updated
+		}
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("message_recipients").
+				Columns(messageRecipientColumns...).
+				Values(
+					recipient.Namespace,
+					recipient.Message,
+					recipient.Node,
+					recipient.Status,
+					recipient.Created,
+					recipient.Updated,
+				),
+			nil, // no change events for message recipients
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) messageRecipientResult(ctx context.Context, row *sql.Rows) (*fftypes.MessageRecipient, error) {
+	recipient := fftypes.MessageRecipient{}
+	err := row.Scan(
+		&recipient.Namespace,
+		&recipient.Message,
+		&recipient.Node,
+		&recipient.Status,
+		&recipient.Created,
+		&recipient.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "message_recipients")
+	}
+	return &recipient, nil
+}
+
+func (s *SQLCommon) GetMessageRecipients(ctx context.Context, filter database.Filter) (recipients []*fftypes.MessageRecipient, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(messageRecipientColumns...).From("message_recipients"), filter, messageRecipientFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	recipients = []*fftypes.MessageRecipient{}
+	for rows.Next() {
+		r, err := s.messageRecipientResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		recipients = append(recipients, r)
+	}
+
+	return recipients, s.queryRes(ctx, tx, "message_recipients", fop, fi), err
+
+}
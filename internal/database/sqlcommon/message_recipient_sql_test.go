@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageRecipientsE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Record a pending recipient
+	recipient := &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   fftypes.NewUUID(),
+		Node:      fftypes.NewUUID(),
+		Status:    fftypes.MessageRecipientStatusPending,
+	}
+	err := s.UpsertMessageRecipient(ctx, recipient)
+	assert.NoError(t, err)
+	assert.NotNil(t, recipient.Created)
+	assert.Equal(t, recipient.Created, recipient.Updated)
+
+	// A second recipient of the same message
+	other := &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   recipient.Message,
+		Node:      fftypes.NewUUID(),
+		Status:    fftypes.MessageRecipientStatusPending,
+	}
+	err = s.UpsertMessageRecipient(ctx, other)
+	assert.NoError(t, err)
+
+	// Acknowledge the first
+	updated := &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   recipient.Message,
+		Node:      recipient.Node,
+		Status:    fftypes.MessageRecipientStatusConfirmed,
+	}
+	err = s.UpsertMessageRecipient(ctx, updated)
+	assert.NoError(t, err)
+	updated.Created = recipient.Created
+
+	// Query back the recipients
+	fb := database.MessageRecipientQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("message", recipient.Message),
+	)
+	recipients, res, err := s.GetMessageRecipients(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recipients))
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	filter = fb.And(
+		fb.Eq("message", recipient.Message),
+		fb.Eq("node", recipient.Node),
+		fb.Eq("status", fftypes.MessageRecipientStatusConfirmed),
+	)
+	recipients, _, err = s.GetMessageRecipients(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(recipients))
+	updatedJson, _ := json.Marshal(&updated)
+	readJson, _ := json.Marshal(recipients[0])
+	assert.Equal(t, string(updatedJson), string(readJson))
+}
+
+func TestUpsertMessageRecipientFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertMessageRecipient(context.Background(), &fftypes.MessageRecipient{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageRecipientFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageRecipient(context.Background(), &fftypes.MessageRecipient{Message: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageRecipientFailScan(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}).AddRow())
+	mock.ExpectRollback()
+	err := s.UpsertMessageRecipient(context.Background(), &fftypes.MessageRecipient{Message: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageRecipientFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageRecipient(context.Background(), &fftypes.MessageRecipient{Message: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageRecipientFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(12345)))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageRecipient(context.Background(), &fftypes.MessageRecipient{Message: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageRecipientsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.MessageRecipientQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetMessageRecipients(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageRecipientsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.MessageRecipientQueryFactory.NewFilter(context.Background()).Eq("namespace", map[bool]bool{true: false})
+	_, _, err := s.GetMessageRecipients(context.Background(), f)
+	assert.Regexp(t, "FF10149.*namespace", err)
+}
+
+func TestGetMessageRecipientsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	f := database.MessageRecipientQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetMessageRecipients(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
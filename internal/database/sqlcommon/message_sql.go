@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -47,12 +48,18 @@ var (
 		"confirmed",
 		"tx_type",
 		"batch_id",
+		"correlation_id",
+		"business_key",
+		"send_at",
 	}
 	msgFilterFieldMap = map[string]string{
-		"type":   "mtype",
-		"txtype": "tx_type",
-		"batch":  "batch_id",
-		"group":  "group_hash",
+		"type":          "mtype",
+		"txtype":        "tx_type",
+		"batch":         "batch_id",
+		"group":         "group_hash",
+		"correlationid": "correlation_id",
+		"businesskey":   "business_key",
+		"sendat":        "send_at",
 	}
 )
 
@@ -75,6 +82,7 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *txWrapper, mes
 			Set("confirmed", message.Confirmed).
 			Set("tx_type", message.Header.TxType).
 			Set("batch_id", message.BatchID).
+			Set("send_at", message.SendAt).
 			Where(sq.Eq{
 				"id":   message.Header.ID,
 				"hash": message.Hash,
@@ -103,6 +111,9 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *ffty
 		message.Confirmed,
 		message.Header.TxType,
 		message.BatchID,
+		message.CorrelationID,
+		message.BusinessKey,
+		message.SendAt,
 	)
 }
 
@@ -115,6 +126,11 @@ func (s *SQLCommon) attemptMessageInsert(ctx context.Context, tx *txWrapper, mes
 	return err
 }
 
+func (s *SQLCommon) indexMessageForSearch(ctx context.Context, tx *txWrapper, message *fftypes.Message) error {
+	return s.indexForSearch(ctx, tx, message.Header.Namespace, fftypes.SearchResultTypeMessage, message.Header.ID, message.Header.Created,
+		message.Header.Tag, strings.Join(message.Header.Topics, " "))
+}
+
 func (s *SQLCommon) UpsertMessage(ctx context.Context, message *fftypes.Message, optimization database.UpsertOptimization) (err error) {
 	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
 	if err != nil {
@@ -128,10 +144,12 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *fftypes.Message,
 	// The expectation is that the optimization will hit almost all of the time,
 	// as only recovery paths require us to go down the un-optimized route.
 	optimized := false
+	inserted := false
 	recreateDatarefs := false
 	if optimization == database.UpsertOptimizationNew {
 		opErr := s.attemptMessageInsert(ctx, tx, message, true /* we want a failure here we can progress past */)
 		optimized = opErr == nil
+		inserted = optimized
 	} else if optimization == database.UpsertOptimizationExisting {
 		rowsAffected, opErr := s.attemptMessageUpdate(ctx, tx, message)
 		optimized = opErr == nil && rowsAffected == 1
@@ -170,6 +188,13 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *fftypes.Message,
 			if err = s.attemptMessageInsert(ctx, tx, message, false); err != nil {
 				return err
 			}
+			inserted = true
+		}
+	}
+
+	if inserted {
+		if err = s.indexMessageForSearch(ctx, tx, message); err != nil {
+			return err
 		}
 	}
 
@@ -221,6 +246,11 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*fftypes.Mess
 		if err != nil {
 			return err
 		}
+		for _, message := range messages {
+			if err = s.indexMessageForSearch(ctx, tx, message); err != nil {
+				return err
+			}
+		}
 
 		// Use a single multi-row insert for the data refs
 		if dataRefCount > 0 {
@@ -237,6 +267,10 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*fftypes.Mess
 			if err != nil {
 				return err
 			}
+			err = s.indexMessageForSearch(ctx, tx, message)
+			if err != nil {
+				return err
+			}
 			err = s.updateMessageDataRefs(ctx, tx, message, false)
 			if err != nil {
 				return err
@@ -276,6 +310,90 @@ func (s *SQLCommon) ReplaceMessage(ctx context.Context, message *fftypes.Message
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
+func (s *SQLCommon) CompletePendingMessage(ctx context.Context, message *fftypes.Message) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if err := s.deleteTx(ctx, tx,
+		sq.Delete("messages").
+			Where(sq.Eq{
+				"id":    message.Header.ID,
+				"state": fftypes.MessageStatePendingData,
+			}),
+		nil, // no change event
+	); err != nil {
+		return err
+	}
+
+	if err = s.attemptMessageInsert(ctx, tx, message, false); err != nil {
+		return err
+	}
+
+	// Unlike ReplaceMessage, the data refs change - as the hashes are now known
+	if err = s.updateMessageDataRefs(ctx, tx, message, true); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) ActivateDraftMessage(ctx context.Context, message *fftypes.Message) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if err := s.deleteTx(ctx, tx,
+		sq.Delete("messages").
+			Where(sq.Eq{
+				"id":    message.Header.ID,
+				"state": []fftypes.MessageState{fftypes.MessageStateDraft, fftypes.MessageStateScheduled},
+			}),
+		nil, // no change event
+	); err != nil {
+		return err
+	}
+
+	if err = s.attemptMessageInsert(ctx, tx, message, false); err != nil {
+		return err
+	}
+
+	// The data refs are unchanged, as the message was sealed when the draft was created
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) ScheduleDraftMessage(ctx context.Context, msgID *fftypes.UUID, sendAt *fftypes.FFTime) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	updated, err := s.updateTx(ctx, tx,
+		sq.Update("messages").
+			Set("state", fftypes.MessageStateScheduled).
+			Set("send_at", sendAt).
+			Where(sq.Eq{
+				"id":    msgID,
+				"state": []fftypes.MessageState{fftypes.MessageStateDraft, fftypes.MessageStateScheduled},
+			}),
+		nil, // no change event
+	)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return database.DeleteRecordNotFound
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) updateMessageDataRefs(ctx context.Context, tx *txWrapper, message *fftypes.Message, recreateDatarefs bool) error {
 
 	if recreateDatarefs {
@@ -295,7 +413,8 @@ func (s *SQLCommon) updateMessageDataRefs(ctx context.Context, tx *txWrapper, me
 		if msgDataRef.ID == nil {
 			return i18n.NewError(ctx, i18n.MsgNullDataReferenceID, msgDataRefIDx)
 		}
-		if msgDataRef.Hash == nil {
+		if msgDataRef.Hash == nil && message.State != fftypes.MessageStatePendingData {
+			// Only a message that is waiting for its data to be uploaded can refer to data without a hash
 			return i18n.NewError(ctx, i18n.MsgMissingDataHashIndex, msgDataRefIDx)
 		}
 		// Add the linkage
@@ -356,7 +475,7 @@ func (s *SQLCommon) loadDataRefs(ctx context.Context, msgs []*fftypes.Message) e
 	for existingRefs.Next() {
 		var msgID fftypes.UUID
 		var dataID fftypes.UUID
-		var dataHash fftypes.Bytes32
+		var dataHash *fftypes.Bytes32 // nil for a message that is still waiting for the data to be uploaded
 		var dataIDx int
 		if err = existingRefs.Scan(&msgID, &dataID, &dataHash, &dataIDx); err != nil {
 			return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "messages_data")
@@ -365,7 +484,7 @@ func (s *SQLCommon) loadDataRefs(ctx context.Context, msgs []*fftypes.Message) e
 			if *m.Header.ID == msgID {
 				m.Data = append(m.Data, &fftypes.DataRef{
 					ID:   &dataID,
-					Hash: &dataHash,
+					Hash: dataHash,
 				})
 			}
 		}
@@ -400,6 +519,9 @@ func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*fftypes.Mess
 		&msg.Confirmed,
 		&msg.Header.TxType,
 		&msg.BatchID,
+		&msg.CorrelationID,
+		&msg.BusinessKey,
+		&msg.SendAt,
 		// Must be added to the list of columns in all selects
 		&msg.Sequence,
 	)
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly/internal/log"
@@ -60,9 +61,11 @@ func TestUpsertE2EWithDB(t *testing.T) {
 			DataHash:  fftypes.NewRandB32(),
 			TxType:    fftypes.TransactionTypeUnpinned,
 		},
-		Hash:      fftypes.NewRandB32(),
-		State:     fftypes.MessageStateStaged,
-		Confirmed: nil,
+		Hash:          fftypes.NewRandB32(),
+		State:         fftypes.MessageStateStaged,
+		Confirmed:     nil,
+		CorrelationID: "corr1",
+		BusinessKey:   "order-1",
 		Data: []*fftypes.DataRef{
 			{ID: dataID1, Hash: rand1},
 			{ID: dataID2, Hash: rand2},
@@ -107,11 +110,13 @@ func TestUpsertE2EWithDB(t *testing.T) {
 			DataHash:  fftypes.NewRandB32(),
 			TxType:    fftypes.TransactionTypeBatchPin,
 		},
-		Hash:      fftypes.NewRandB32(),
-		Pins:      []string{fftypes.NewRandB32().String(), fftypes.NewRandB32().String()},
-		State:     fftypes.MessageStateRejected,
-		Confirmed: fftypes.Now(),
-		BatchID:   bid,
+		Hash:          fftypes.NewRandB32(),
+		Pins:          []string{fftypes.NewRandB32().String(), fftypes.NewRandB32().String()},
+		CorrelationID: "corr1",
+		BusinessKey:   "order-1",
+		State:         fftypes.MessageStateRejected,
+		Confirmed:     fftypes.Now(),
+		BatchID:       bid,
 		Data: []*fftypes.DataRef{
 			{ID: dataID1, Hash: rand1},
 			{ID: dataID2, Hash: rand2}, // Note the data refs cannot change, as it would affect the hash, and the hash is immutable
@@ -149,6 +154,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("topics", msgUpdated.Header.Topics),
 		fb.Eq("group", msgUpdated.Header.Group),
 		fb.Eq("cid", msgUpdated.Header.CID),
+		fb.Eq("correlationid", "corr1"),
 		fb.Gt("created", "0"),
 		fb.Gt("confirmed", "0"),
 	)
@@ -401,6 +407,180 @@ func TestReplaceMessageFailInsert(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCompletePendingMessageE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	dataID := fftypes.NewUUID()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1"},
+			TxType:    fftypes.TransactionTypeBatchPin,
+			Created:   fftypes.Now(),
+		},
+		State: fftypes.MessageStatePendingData,
+		Data:  fftypes.DataRefs{{ID: dataID}},
+	}
+	err := msg.Seal(ctx)
+	assert.NoError(t, err)
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, fftypes.ChangeEventTypeCreated, "ns1", msg.Header.ID, mock.Anything).Return()
+	err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	pendingSeq := msg.Sequence
+
+	msgRead, err := s.GetMessageByID(ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStatePendingData, msgRead.State)
+	assert.Nil(t, msgRead.Data[0].Hash)
+
+	msg.Data[0].Hash = fftypes.NewRandB32()
+	msg.State = fftypes.MessageStateReady
+	err = msg.Seal(ctx)
+	assert.NoError(t, err)
+	err = s.CompletePendingMessage(ctx, msg)
+	assert.NoError(t, err)
+	assert.Greater(t, msg.Sequence, pendingSeq)
+
+	msgRead, err = s.GetMessageByID(ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateReady, msgRead.State)
+	assert.Equal(t, msg.Hash, msgRead.Hash)
+	assert.Equal(t, msg.Data[0].Hash, msgRead.Data[0].Hash)
+
+	// A message that is no longer pending cannot be completed again
+	err = s.CompletePendingMessage(ctx, msg)
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+}
+
+func TestCompletePendingMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.CompletePendingMessage(context.Background(), &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletePendingMessageFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CompletePendingMessage(context.Background(), &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletePendingMessageFailDataRefs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CompletePendingMessage(context.Background(), &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActivateDraftMessageE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1"},
+			TxType:    fftypes.TransactionTypeBatchPin,
+			Created:   fftypes.Now(),
+		},
+		State: fftypes.MessageStateDraft,
+		Data:  fftypes.DataRefs{{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}},
+	}
+	err := msg.Seal(ctx)
+	assert.NoError(t, err)
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, fftypes.ChangeEventTypeCreated, "ns1", msg.Header.ID, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, fftypes.ChangeEventTypeUpdated, "ns1", msg.Header.ID, mock.Anything).Return()
+	err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	draftSeq := msg.Sequence
+
+	// Schedule the draft
+	sendAt := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	err = s.ScheduleDraftMessage(ctx, msg.Header.ID, &sendAt)
+	assert.NoError(t, err)
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := s.GetMessages(ctx, fb.And(
+		fb.Eq("state", fftypes.MessageStateScheduled),
+		fb.Lt("sendat", sendAt.UnixNano()+1),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, sendAt.UnixNano(), msgs[0].SendAt.UnixNano())
+
+	msg.State = fftypes.MessageStateReady
+	err = s.ActivateDraftMessage(ctx, msg)
+	assert.NoError(t, err)
+	assert.Greater(t, msg.Sequence, draftSeq)
+
+	msgRead, err := s.GetMessageByID(ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateReady, msgRead.State)
+	assert.Equal(t, msg.Hash, msgRead.Hash)
+	assert.Len(t, msgRead.Data, 1)
+
+	// A message that is no longer a draft cannot be activated or scheduled again
+	err = s.ActivateDraftMessage(ctx, msg)
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+	err = s.ScheduleDraftMessage(ctx, msg.Header.ID, &sendAt)
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+	msgRead, err = s.GetMessageByID(ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateReady, msgRead.State)
+}
+
+func TestScheduleDraftMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.ScheduleDraftMessage(context.Background(), fftypes.NewUUID(), fftypes.Now())
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDraftMessageFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ScheduleDraftMessage(context.Background(), fftypes.NewUUID(), fftypes.Now())
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActivateDraftMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.ActivateDraftMessage(context.Background(), &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActivateDraftMessageFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ActivateDraftMessage(context.Background(), &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateMessageDataRefsNilID(t *testing.T) {
 	s, mock := newMockProvider().init()
 	msgID := fftypes.NewUUID()
@@ -528,7 +708,7 @@ func TestGetMessageByIDLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, 0))
+		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, "", "", nil, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	_, err := s.GetMessageByID(context.Background(), msgID)
 	assert.Regexp(t, "FF10115", err)
@@ -575,7 +755,7 @@ func TestGetMessagesLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, 0))
+		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, "", "", nil, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Gt("confirmed", "0")
 	_, _, err := s.GetMessages(context.Background(), f)
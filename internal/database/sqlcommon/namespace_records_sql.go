@@ -0,0 +1,196 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// namespaceRecordsBatchSize is the most rows deleted from a table in each transaction
+const namespaceRecordsBatchSize = 500
+
+// namespaceRecordTable is a table cleared of the old records of a namespace that are complete. A table that
+// depends on another, such as a table without a namespace column that refers to a parent table, must come
+// before the table it depends on in the list, as the rows to delete are found from the rows of that table.
+type namespaceRecordTable struct {
+	table string
+	// key is the column used to select each batch of rows to delete, which defaults to the sequence. A table
+	// without a usable sequence in all databases is batched by another column, so a batch can hold more rows.
+	key   string
+	where func(ns string, before *fftypes.FFTime) sq.Sqlizer
+}
+
+// completeMessages are messages that have been confirmed or rejected, so will not be processed further
+func completeMessages(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"namespace": ns},
+		sq.Lt{"created": before},
+		sq.Eq{"state": []fftypes.MessageState{fftypes.MessageStateConfirmed, fftypes.MessageStateRejected}},
+	}
+}
+
+// unusedData is data that is not referenced by any message, once the references of the complete messages
+// have been deleted
+func unusedData(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"namespace": ns},
+		sq.Lt{"created": before},
+		sq.Expr("NOT EXISTS (SELECT 1 FROM messages_data WHERE messages_data.data_id = data.id)"),
+	}
+}
+
+// completeBatches are batches that have been confirmed
+func completeBatches(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"namespace": ns},
+		sq.Lt{"created": before},
+		sq.NotEq{"confirmed": nil},
+	}
+}
+
+func oldRecords(column string) func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			sq.Eq{"namespace": ns},
+			sq.Lt{column: before},
+		}
+	}
+}
+
+func childRecords(key, parent string, parentWhere func(ns string, before *fftypes.FFTime) sq.Sqlizer) func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.Expr(key+" IN (?)", sq.Select("id").From(parent).Where(parentWhere(ns, before)))
+	}
+}
+
+func searchRecords(resultType fftypes.SearchResultType, parent string, parentWhere func(ns string, before *fftypes.FFTime) sq.Sqlizer) func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+	return func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			sq.Eq{"type": resultType},
+			childRecords("ref_id", parent, parentWhere)(ns, before),
+		}
+	}
+}
+
+var namespaceRecordTables = []*namespaceRecordTable{
+	{table: "messages_data", key: "message_id", where: childRecords("message_id", "messages", completeMessages)},
+	{table: "message_recipients", where: childRecords("message_id", "messages", completeMessages)},
+	{table: "searchindex", key: "ref_id", where: searchRecords(fftypes.SearchResultTypeMessage, "messages", completeMessages)},
+	{table: "messages", where: completeMessages},
+	{table: "data_index", where: childRecords("data_id", "data", unusedData)},
+	{table: "searchindex", key: "ref_id", where: searchRecords(fftypes.SearchResultTypeData, "data", unusedData)},
+	// A blob can be shared by data in other namespaces, or by data that is still in use
+	{table: "blobs", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			sq.Expr("hash IN (?)", sq.Select("blob_hash").From("data").Where(unusedData(ns, before))),
+			sq.Expr("NOT EXISTS (?)", sq.Select("1").From("data").Where(sq.And{
+				sq.Expr("data.blob_hash = blobs.hash"),
+				sq.Expr("NOT (?)", unusedData(ns, before)),
+			})),
+		}
+	}},
+	{table: "data", where: unusedData},
+	{table: "pins", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			sq.Eq{"dispatched": true},
+			childRecords("batch_id", "batches", completeBatches)(ns, before),
+		}
+	}},
+	{table: "batches", where: completeBatches},
+	{table: "events", where: oldRecords("created")},
+	{table: "operations", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			oldRecords("created")(ns, before),
+			sq.NotEq{"opstatus": fftypes.OpStatusPending},
+		}
+	}},
+	{table: "tokentransfer", where: oldRecords("created")},
+	{table: "tokenapproval", where: oldRecords("created")},
+	{table: "searchindex", key: "ref_id", where: searchRecords(fftypes.SearchResultTypeBlockchainEvent, "blockchainevents", oldRecords("timestamp"))},
+	{table: "blockchainevents", where: oldRecords("timestamp")},
+	{table: "blockchaineventraw", where: oldRecords("created")},
+	// A transaction is kept while any of its operations are still pending
+	{table: "transactions", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			oldRecords("created")(ns, before),
+			sq.Expr("NOT EXISTS (SELECT 1 FROM operations WHERE operations.tx_id = transactions.id)"),
+		}
+	}},
+	{table: "syncchanges", where: oldRecords("created")},
+	{table: "approvalrequests", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		return sq.And{
+			oldRecords("created")(ns, before),
+			sq.Eq{"state": []fftypes.ApprovalRequestState{
+				fftypes.ApprovalRequestStateSubmitted,
+				fftypes.ApprovalRequestStateRejected,
+				fftypes.ApprovalRequestStateFailed,
+			}},
+		}
+	}},
+	// Annotations are kept with the definitions they belong to, so only those left behind are deleted
+	{table: "annotations", where: func(ns string, before *fftypes.FFTime) sq.Sqlizer {
+		conditions := sq.And{sq.Eq{"namespace": ns}}
+		for _, t := range []string{"datatypes", "ffi", "contractapis", "tokenpool", "subscriptions"} {
+			conditions = append(conditions, sq.Expr(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s.id = annotations.resource_id)", t, t)))
+		}
+		return conditions
+	}},
+}
+
+func (s *SQLCommon) DeleteNamespaceRecords(ctx context.Context, ns string, before *fftypes.FFTime) (deleted int64, err error) {
+	// Each batch of rows is deleted in its own transaction, so a large namespace does not hold one long transaction.
+	// A failure part way through is completed by the next call, as the records that others depend on are deleted last.
+	for _, t := range namespaceRecordTables {
+		for {
+			count, err := s.deleteNamespaceRecords(ctx, t, ns, before)
+			if err != nil {
+				return deleted, err
+			}
+			deleted += count
+			if count < namespaceRecordsBatchSize {
+				break
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func (s *SQLCommon) deleteNamespaceRecords(ctx context.Context, t *namespaceRecordTable, ns string, before *fftypes.FFTime) (int64, error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	key := t.key
+	if key == "" {
+		key = "seq"
+	}
+	query := sq.Delete(t.table).Where(sq.Expr(
+		key+" IN (?)",
+		sq.Select(key).From(t.table).Where(t.where(ns, before)).Limit(namespaceRecordsBatchSize),
+	))
+	count, err := s.deleteManyTx(ctx, tx, query)
+	if err != nil {
+		return 0, err
+	}
+	return count, s.commitTx(ctx, tx, autoCommit)
+}
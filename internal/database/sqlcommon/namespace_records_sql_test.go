@@ -0,0 +1,200 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteNamespaceRecordsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedCollectionEvent", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	old := fftypes.FFTime(time.Now().Add(-2 * time.Hour))
+	cutoff := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+
+	newRecords := func(ns string, created *fftypes.FFTime, state fftypes.MessageState, opStatus fftypes.OpStatus) (*fftypes.Message, *fftypes.Blob) {
+		blob := &fftypes.Blob{
+			Hash:       fftypes.NewRandB32(),
+			PayloadRef: "blob",
+			Created:    created,
+		}
+		err := s.InsertBlob(ctx, blob)
+		assert.NoError(t, err)
+		data := &fftypes.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Hash:      fftypes.NewRandB32(),
+			Created:   created,
+			Value:     fftypes.JSONAnyPtr(`{"some":"value"}`),
+			Blob:      &fftypes.BlobRef{Hash: blob.Hash},
+		}
+		err = s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		msg := &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: ns,
+				Type:      fftypes.MessageTypeBroadcast,
+				Created:   created,
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:  fftypes.NewRandB32(),
+			State: state,
+			Data:  fftypes.DataRefs{{ID: data.ID, Hash: data.Hash}},
+		}
+		err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		err = s.InsertOperation(ctx, &fftypes.Operation{
+			ID:          fftypes.NewUUID(),
+			Namespace:   ns,
+			Type:        fftypes.OpTypeBlockchainPinBatch,
+			Transaction: fftypes.NewUUID(),
+			Status:      opStatus,
+			Created:     created,
+		})
+		assert.NoError(t, err)
+		return msg, blob
+	}
+	oldMsg, oldBlob := newRecords("ns1", &old, fftypes.MessageStateConfirmed, fftypes.OpStatusSucceeded)
+	pendingMsg, pendingBlob := newRecords("ns1", &old, fftypes.MessageStatePending, fftypes.OpStatusPending)
+	newMsg, _ := newRecords("ns1", fftypes.Now(), fftypes.MessageStateConfirmed, fftypes.OpStatusSucceeded)
+	otherMsg, _ := newRecords("ns2", &old, fftypes.MessageStateConfirmed, fftypes.OpStatusSucceeded)
+
+	confirmedBatch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old},
+		Hash:        fftypes.NewRandB32(),
+		Manifest:    fftypes.JSONAnyPtr("{}"),
+		Confirmed:   &old,
+	}
+	err := s.UpsertBatch(ctx, confirmedBatch)
+	assert.NoError(t, err)
+	err = s.InsertPins(ctx, []*fftypes.Pin{{Hash: fftypes.NewRandB32(), Batch: confirmedBatch.ID, Dispatched: true, Created: &old}})
+	assert.NoError(t, err)
+
+	approved := &fftypes.ApprovalRequest{ID: fftypes.NewUUID(), Namespace: "ns1", State: fftypes.ApprovalRequestStateSubmitted, Created: &old}
+	pendingApproval := &fftypes.ApprovalRequest{ID: fftypes.NewUUID(), Namespace: "ns1", State: fftypes.ApprovalRequestStatePending, Created: &old}
+	assert.NoError(t, s.InsertApprovalRequest(ctx, approved))
+	assert.NoError(t, s.InsertApprovalRequest(ctx, pendingApproval))
+
+	tx, err := s.db.Begin()
+	assert.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO annotations (resource_id, namespace, path, value) VALUES (?, 'ns1', 'a', 'b')`, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+
+	deleted, err := s.DeleteNamespaceRecords(ctx, "ns1", &cutoff)
+	assert.NoError(t, err)
+	// data ref, message, search index entry, blob, data, pin, batch, operation, approval request, annotation
+	assert.Equal(t, int64(10), deleted)
+
+	msg, err := s.GetMessageByID(ctx, oldMsg.Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+	for _, kept := range []*fftypes.Message{pendingMsg, newMsg, otherMsg} {
+		msg, err = s.GetMessageByID(ctx, kept.Header.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, msg)
+	}
+	data, err := s.GetDataByID(ctx, pendingMsg.Data[0].ID, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, data)
+
+	blob, err := s.GetBlobMatchingHash(ctx, oldBlob.Hash)
+	assert.NoError(t, err)
+	assert.Nil(t, blob)
+	blob, err = s.GetBlobMatchingHash(ctx, pendingBlob.Hash)
+	assert.NoError(t, err)
+	assert.NotNil(t, blob)
+
+	batch, err := s.GetBatchByID(ctx, confirmedBatch.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, batch)
+	pins, _, err := s.GetPins(ctx, database.PinQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Empty(t, pins)
+
+	req, err := s.GetApprovalRequestByID(ctx, approved.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, req)
+	req, err = s.GetApprovalRequestByID(ctx, pendingApproval.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, req)
+
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := s.GetOperations(ctx, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+
+	// Nothing left to delete
+	deleted, err = s.DeleteNamespaceRecords(ctx, "ns1", &cutoff)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
+
+func TestDeleteNamespaceRecordsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.DeleteNamespaceRecords(context.Background(), "ns1", fftypes.Now())
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteNamespaceRecordsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	deleted, err := s.DeleteNamespaceRecords(context.Background(), "ns1", fftypes.Now())
+	assert.Regexp(t, "FF10118", err)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteNamespaceRecordsBatches(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM messages_data WHERE message_id IN \\(SELECT message_id FROM messages_data .* LIMIT 500\\)").WillReturnResult(sqlmock.NewResult(0, 500))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM messages_data .*").WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM message_recipients .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	deleted, err := s.DeleteNamespaceRecords(context.Background(), "ns1", fftypes.Now())
+	assert.Regexp(t, "FF10118", err)
+	assert.Equal(t, int64(510), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
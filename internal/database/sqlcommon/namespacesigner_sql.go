@@ -0,0 +1,137 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	namespaceSignerColumns = []string{
+		"namespace",
+		"author",
+		"key",
+		"updated",
+	}
+)
+
+func (s *SQLCommon) UpsertNamespaceSigner(ctx context.Context, signer *fftypes.NamespaceSigner) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the namespace already has a signer
+	signerRows, _, err := s.queryTx(ctx, tx,
+		sq.Select(sequenceColumn).
+			From("namespacesigners").
+			Where(sq.Eq{"namespace": signer.Namespace}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := signerRows.Next()
+	signerRows.Close()
+
+	signer.Updated = fftypes.Now()
+	if existing {
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("namespacesigners").
+				Set("author", signer.Author).
+				Set("key", signer.Key).
+				Set("updated", signer.Updated).
+				Where(sq.Eq{"namespace": signer.Namespace}),
+			nil, // no change events for namespace signers
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("namespacesigners").
+				Columns(namespaceSignerColumns...).
+				Values(
+					signer.Namespace,
+					signer.Author,
+					signer.Key,
+					signer.Updated,
+				),
+			nil, // no change events for namespace signers
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) namespaceSignerResult(ctx context.Context, row *sql.Rows) (*fftypes.NamespaceSigner, error) {
+	signer := fftypes.NamespaceSigner{}
+	err := row.Scan(
+		&signer.Namespace,
+		&signer.Author,
+		&signer.Key,
+		&signer.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "namespacesigners")
+	}
+	return &signer, nil
+}
+
+func (s *SQLCommon) GetNamespaceSigner(ctx context.Context, ns string) (signer *fftypes.NamespaceSigner, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(namespaceSignerColumns...).
+			From("namespacesigners").
+			Where(sq.Eq{"namespace": ns}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Default signer for namespace '%s' not found", ns)
+		return nil, nil
+	}
+
+	return s.namespaceSignerResult(ctx, rows)
+}
+
+func (s *SQLCommon) DeleteNamespaceSigner(ctx context.Context, ns string) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("namespacesigners").Where(sq.Eq{
+		"namespace": ns,
+	}), nil /* no change events for namespace signers */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,150 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceSignerE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Set the default signer for a namespace
+	signer := &fftypes.NamespaceSigner{
+		Namespace: "ns1",
+		SignerRef: fftypes.SignerRef{
+			Author: "did:firefly:org/org1",
+			Key:    "0x12345",
+		},
+	}
+	err := s.UpsertNamespaceSigner(ctx, signer)
+	assert.NoError(t, err)
+	assert.NotNil(t, signer.Updated)
+
+	signerRead, err := s.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, signer.SignerRef, signerRead.SignerRef)
+	assert.Equal(t, signer.Updated.String(), signerRead.Updated.String())
+
+	// Replace it
+	signerUpdated := &fftypes.NamespaceSigner{
+		Namespace: "ns1",
+		SignerRef: fftypes.SignerRef{
+			Author: "did:firefly:org/org2",
+			Key:    "0x67890",
+		},
+	}
+	err = s.UpsertNamespaceSigner(ctx, signerUpdated)
+	assert.NoError(t, err)
+
+	signerRead, err = s.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, signerUpdated.SignerRef, signerRead.SignerRef)
+
+	// Delete it
+	err = s.DeleteNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	signerRead, err = s.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, signerRead)
+
+	err = s.DeleteNamespaceSigner(ctx, "ns1")
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+}
+
+func TestUpsertNamespaceSignerFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertNamespaceSigner(context.Background(), &fftypes.NamespaceSigner{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertNamespaceSignerFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertNamespaceSigner(context.Background(), &fftypes.NamespaceSigner{Namespace: "ns1"})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertNamespaceSignerFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertNamespaceSigner(context.Background(), &fftypes.NamespaceSigner{Namespace: "ns1"})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertNamespaceSignerFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(12345)))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertNamespaceSigner(context.Background(), &fftypes.NamespaceSigner{Namespace: "ns1"})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetNamespaceSignerSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetNamespaceSigner(context.Background(), "ns1")
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetNamespaceSignerScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetNamespaceSigner(context.Background(), "ns1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteNamespaceSignerFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteNamespaceSigner(context.Background(), "ns1")
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteNamespaceSignerFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteNamespaceSigner(context.Background(), "ns1")
+	assert.Regexp(t, "FF10118", err)
+}
@@ -39,49 +39,86 @@ var (
 	}
 )
 
-func (s *SQLCommon) UpsertNonceNext(ctx context.Context, nonce *fftypes.Nonce) (err error) {
+func (s *SQLCommon) ReserveNonces(ctx context.Context, nonce *fftypes.Nonce, count int64) (err error) {
 	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
 	if err != nil {
 		return err
 	}
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
-	existing := false
-	// Do a select within the transaction to detemine if the UUID already exists
-	nonceRows, _, err := s.queryTx(ctx, tx,
-		sq.Select("nonce", sequenceColumn).
-			From("nonces").
-			Where(
-				sq.Eq{"context": nonce.Context}),
+	// Increment first, so we hold the write lock on the row from the start of the reservation.
+	// A select-then-update would take a read lock and upgrade it, which deadlocks (or assigns
+	// the same nonce twice) when multiple batch processors reserve on the same context.
+	updated, err := s.updateTx(ctx, tx,
+		sq.Update("nonces").
+			Set("nonce", sq.Expr("nonce + ?", count)).
+			Where(sq.Eq{"context": nonce.Context}),
+		nil, // no change events for nonces
 	)
 	if err != nil {
 		return err
 	}
-	existing = nonceRows.Next()
 
-	if existing {
-		var existingNonce, sequence int64
-		err := nonceRows.Scan(&existingNonce, &sequence)
+	if updated > 0 {
+		nonceRows, _, err := s.queryTx(ctx, tx,
+			sq.Select("nonce").
+				From("nonces").
+				Where(sq.Eq{"context": nonce.Context}),
+		)
 		if err != nil {
+			return err
+		}
+		if !nonceRows.Next() {
 			nonceRows.Close()
-			return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "nonces")
+			return i18n.NewError(ctx, i18n.MsgDBReadErr, "nonces")
 		}
-		nonce.Nonce = existingNonce + 1
+		var lastNonce int64
+		err = nonceRows.Scan(&lastNonce)
 		nonceRows.Close()
-
-		// Update the nonce
-		if _, err = s.updateTx(ctx, tx,
-			sq.Update("nonces").
-				Set("nonce", nonce.Nonce).
-				Where(sq.Eq{sequenceColumn: sequence}),
+		if err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "nonces")
+		}
+		nonce.Nonce = lastNonce - count + 1
+	} else {
+		// The unique index on context means a concurrent insert fails, rather than creating a
+		// second row - the caller retries, and will find the row on the next attempt
+		nonce.Nonce = 0
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("nonces").
+				Columns(nonceColumns...).
+				Values(
+					nonce.Context,
+					count-1,
+					nonce.Group,
+					nonce.Topic,
+				),
 			nil, // no change events for nonces
 		); err != nil {
 			return err
 		}
-	} else {
-		nonceRows.Close()
+	}
 
-		nonce.Nonce = 0
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) UpsertNonce(ctx context.Context, nonce *fftypes.Nonce) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	updated, err := s.updateTx(ctx, tx,
+		sq.Update("nonces").
+			Set("nonce", nonce.Nonce).
+			Where(sq.Eq{"context": nonce.Context}),
+		nil, // no change events for nonces
+	)
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
 		if _, err = s.insertTx(ctx, tx,
 			sq.Insert("nonces").
 				Columns(nonceColumns...).
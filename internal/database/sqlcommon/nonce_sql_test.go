@@ -42,7 +42,7 @@ func TestNoncesE2EWithDB(t *testing.T) {
 		Group:   fftypes.NewRandB32(),
 		Topic:   "topic12345",
 	}
-	err := s.UpsertNonceNext(ctx, nonceZero)
+	err := s.ReserveNonces(ctx, nonceZero, 1)
 	assert.NoError(t, err)
 
 	// Check we get the exact same nonce back
@@ -58,13 +58,13 @@ func TestNoncesE2EWithDB(t *testing.T) {
 	var nonceUpdated fftypes.Nonce
 	nonceUpdated = *nonceZero
 
-	// Increment a couple of times
-	err = s.UpsertNonceNext(context.Background(), &nonceUpdated)
+	// Reserve a range, and then a single nonce after it
+	err = s.ReserveNonces(context.Background(), &nonceUpdated, 2)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), nonceUpdated.Nonce)
-	err = s.UpsertNonceNext(context.Background(), &nonceUpdated)
+	err = s.ReserveNonces(context.Background(), &nonceUpdated, 1)
 	assert.NoError(t, err)
-	assert.Equal(t, int64(2), nonceUpdated.Nonce)
+	assert.Equal(t, int64(3), nonceUpdated.Nonce)
 
 	// Check we get the exact same data back
 	nonceRead, err = s.GetNonce(ctx, nonceUpdated.Context)
@@ -97,56 +97,137 @@ func TestNoncesE2EWithDB(t *testing.T) {
 
 }
 
-func TestUpsertNonceFailBegin(t *testing.T) {
+func TestReserveNoncesFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
-	err := s.UpsertNonceNext(context.Background(), &fftypes.Nonce{})
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{}, 1)
 	assert.Regexp(t, "FF10114", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpsertNonceFailSelect(t *testing.T) {
+func TestReserveNoncesFailUpdate(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()}, 1)
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveNoncesFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
-	err := s.UpsertNonceNext(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()}, 1)
 	assert.Regexp(t, "FF10115", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpsertNonceFailInsert(t *testing.T) {
+func TestReserveNoncesSelectNotFound(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
-	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"nonce"}))
 	mock.ExpectRollback()
-	err := s.UpsertNonceNext(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
-	assert.Regexp(t, "FF10116", err)
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()}, 1)
+	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpsertNonceFailScan(t *testing.T) {
+func TestReserveNoncesFailScan(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}).AddRow())
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"nonce"}).AddRow("bad"))
 	mock.ExpectRollback()
-	err := s.UpsertNonceNext(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()}, 1)
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestReserveNoncesRange(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"nonce"}).AddRow(int64(12345)))
+	mock.ExpectCommit()
+	nonce := &fftypes.Nonce{Context: fftypes.NewRandB32()}
+	err := s.ReserveNonces(context.Background(), nonce, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12341), nonce.Nonce)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveNoncesFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ReserveNonces(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()}, 1)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertNonceWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Set a nonce on a new context
+	nonce := &fftypes.Nonce{
+		Context: fftypes.NewRandB32(),
+		Group:   fftypes.NewRandB32(),
+		Topic:   "topic12345",
+		Nonce:   10,
+	}
+	err := s.UpsertNonce(ctx, nonce)
+	assert.NoError(t, err)
+	nonceRead, err := s.GetNonce(ctx, nonce.Context)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), nonceRead.Nonce)
+
+	// Set it again, and the next reservation follows on
+	nonce.Nonce = 20
+	err = s.UpsertNonce(ctx, nonce)
+	assert.NoError(t, err)
+	err = s.ReserveNonces(ctx, nonce, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(21), nonce.Nonce)
+}
+
+func TestUpsertNonceFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertNonce(context.Background(), &fftypes.Nonce{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertNonceFailUpdate(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"nonce", "sequence"}).AddRow(int64(12345), int64(11111)))
 	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
-	err := s.UpsertNonceNext(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
+	err := s.UpsertNonce(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
 	assert.Regexp(t, "FF10117", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertNonceFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertNonce(context.Background(), &fftypes.Nonce{Context: fftypes.NewRandB32()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetNonceSelectFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
@@ -0,0 +1,160 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	opCallbackColumns = []string{
+		"id",
+		"namespace",
+		"status",
+		"attempts",
+		"next_attempt",
+		"error",
+		"created",
+	}
+	opCallbackFilterFieldMap = map[string]string{
+		"next": "next_attempt",
+	}
+)
+
+func (s *SQLCommon) InsertOpCallback(ctx context.Context, callback *fftypes.OpCallback, hooks ...database.PostCompletionHook) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	callback.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("opcallbacks").
+			Columns(opCallbackColumns...).
+			Values(
+				callback.ID,
+				callback.Namespace,
+				callback.Status,
+				callback.Attempts,
+				callback.NextAttempt,
+				callback.Error,
+				callback.Created,
+			),
+		func() {
+			for _, hook := range hooks {
+				hook()
+			}
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) opCallbackResult(ctx context.Context, row *sql.Rows) (*fftypes.OpCallback, error) {
+	callback := fftypes.OpCallback{}
+	err := row.Scan(
+		&callback.ID,
+		&callback.Namespace,
+		&callback.Status,
+		&callback.Attempts,
+		&callback.NextAttempt,
+		&callback.Error,
+		&callback.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "opcallbacks")
+	}
+	return &callback, nil
+}
+
+func (s *SQLCommon) GetOpCallbackByID(ctx context.Context, id *fftypes.UUID) (callback *fftypes.OpCallback, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(opCallbackColumns...).
+			From("opcallbacks").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Operation callback '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.opCallbackResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetOpCallbacks(ctx context.Context, filter database.Filter) (callbacks []*fftypes.OpCallback, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(opCallbackColumns...).From("opcallbacks"), filter, opCallbackFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	callbacks = []*fftypes.OpCallback{}
+	for rows.Next() {
+		callback, err := s.opCallbackResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		callbacks = append(callbacks, callback)
+	}
+
+	return callbacks, s.queryRes(ctx, tx, "opcallbacks", fop, fi), err
+
+}
+
+func (s *SQLCommon) UpdateOpCallback(ctx context.Context, id *fftypes.UUID, update database.Update) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.buildUpdate(sq.Update("opcallbacks"), update, opCallbackFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Where(sq.Eq{"id": id})
+
+	_, err = s.updateTx(ctx, tx, query, nil /* no change events for operation callbacks */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
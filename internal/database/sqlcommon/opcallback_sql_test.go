@@ -0,0 +1,188 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpCallbackE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Record a callback
+	callback := &fftypes.OpCallback{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Status:      fftypes.OpCallbackStatusPending,
+		NextAttempt: fftypes.Now(),
+	}
+	hookCalled := false
+	err := s.InsertOpCallback(ctx, callback, func() {
+		hookCalled = true
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	assert.NotNil(t, callback.Created)
+
+	// An operation only has one callback
+	err = s.InsertOpCallback(ctx, callback)
+	assert.Regexp(t, "FF10116", err)
+
+	// Lookup by ID
+	callbackRead, err := s.GetOpCallbackByID(ctx, callback.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *callback.ID, *callbackRead.ID)
+	assert.Equal(t, "ns1", callbackRead.Namespace)
+	assert.Equal(t, fftypes.OpCallbackStatusPending, callbackRead.Status)
+	assert.Equal(t, callback.NextAttempt.String(), callbackRead.NextAttempt.String())
+
+	// Record a failed attempt
+	next := fftypes.FFTime(callback.NextAttempt.Time().Add(1000000000))
+	up := database.OpCallbackQueryFactory.NewUpdate(ctx).
+		Set("attempts", 1).
+		Set("next", &next).
+		Set("error", "pop")
+	err = s.UpdateOpCallback(ctx, callback.ID, up)
+	assert.NoError(t, err)
+
+	// Query the callbacks that are due
+	fb := database.OpCallbackQueryFactory.NewFilter(ctx)
+	callbacks, res, err := s.GetOpCallbacks(ctx, fb.And(fb.Eq("status", fftypes.OpCallbackStatusPending), fb.Lte("next", callback.NextAttempt)).Count(true))
+	assert.NoError(t, err)
+	assert.Empty(t, callbacks)
+	assert.Equal(t, int64(0), *res.TotalCount)
+	callbacks, _, err = s.GetOpCallbacks(ctx, fb.And(fb.Eq("status", fftypes.OpCallbackStatusPending), fb.Lte("next", &next)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(callbacks))
+	assert.Equal(t, 1, callbacks[0].Attempts)
+	assert.Equal(t, "pop", callbacks[0].Error)
+
+	// Record the delivery
+	up = database.OpCallbackQueryFactory.NewUpdate(ctx).
+		Set("status", fftypes.OpCallbackStatusDelivered)
+	err = s.UpdateOpCallback(ctx, callback.ID, up)
+	assert.NoError(t, err)
+	callbacks, _, err = s.GetOpCallbacks(ctx, fb.And(fb.Eq("status", fftypes.OpCallbackStatusPending)))
+	assert.NoError(t, err)
+	assert.Empty(t, callbacks)
+}
+
+func TestInsertOpCallbackFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertOpCallback(context.Background(), &fftypes.OpCallback{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertOpCallbackFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertOpCallback(context.Background(), &fftypes.OpCallback{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpCallbackByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetOpCallbackByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpCallbackByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(opCallbackColumns))
+	callback, err := s.GetOpCallbackByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, callback)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpCallbackByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetOpCallbackByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpCallbacksQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.OpCallbackQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetOpCallbacks(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpCallbacksBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.OpCallbackQueryFactory.NewFilter(context.Background()).Eq("namespace", map[bool]bool{true: false})
+	_, _, err := s.GetOpCallbacks(context.Background(), f)
+	assert.Regexp(t, "FF10149.*namespace", err)
+}
+
+func TestGetOpCallbacksReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.OpCallbackQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetOpCallbacks(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpCallbackUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.OpCallbackQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateOpCallback(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestOpCallbackUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.OpCallbackQueryFactory.NewUpdate(context.Background()).Set("attempts", map[bool]bool{true: false})
+	err := s.UpdateOpCallback(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*attempts", err)
+}
+
+func TestOpCallbackUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.OpCallbackQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateOpCallback(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
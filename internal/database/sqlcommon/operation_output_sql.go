@@ -0,0 +1,118 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	operationOutputColumns = []string{
+		"id",
+		"operation_id",
+		"namespace",
+		"value",
+		"created",
+	}
+)
+
+func (s *SQLCommon) UpsertOperationOutput(ctx context.Context, output *fftypes.OperationOutput) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the UUID already exists
+	outputRows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id").
+			From("operation_outputs").
+			Where(sq.Eq{"id": output.ID}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := outputRows.Next()
+	outputRows.Close()
+
+	output.Created = fftypes.Now()
+	if existing {
+		// No change events are emitted for operation outputs, as they are not part of the data of the namespace
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("operation_outputs").
+				Set("value", output.Value).
+				Set("created", output.Created).
+				Where(sq.Eq{"id": output.ID}),
+			nil,
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("operation_outputs").
+				Columns(operationOutputColumns...).
+				Values(
+					output.ID,
+					output.Operation,
+					output.Namespace,
+					output.Value,
+					output.Created,
+				),
+			nil,
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) GetOperationOutputByID(ctx context.Context, id *fftypes.UUID) (output *fftypes.OperationOutput, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(operationOutputColumns...).
+			From("operation_outputs").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Operation output '%s' not found", id)
+		return nil, nil
+	}
+
+	output = &fftypes.OperationOutput{}
+	err = rows.Scan(
+		&output.ID,
+		&output.Operation,
+		&output.Namespace,
+		&output.Value,
+		&output.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "operation_outputs")
+	}
+	return output, nil
+}
@@ -0,0 +1,162 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOperationOutputE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create an operation with an inline output
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        fftypes.OpTypeBlockchainPinBatch,
+		Transaction: fftypes.NewUUID(),
+		Created:     fftypes.Now(),
+		Status:      fftypes.OpStatusPending,
+		Output:      fftypes.JSONObject{"some": "output"},
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, fftypes.ChangeEventTypeCreated, "ns1", op.ID).Return()
+	err := s.InsertOperation(ctx, op)
+	assert.NoError(t, err)
+
+	// Move the output into the overflow store
+	output := &fftypes.OperationOutput{
+		ID:        fftypes.NewUUID(),
+		Operation: op.ID,
+		Namespace: "ns1",
+		Value:     fftypes.JSONAnyPtr(`{"some":"output"}`),
+	}
+	err = s.UpsertOperationOutput(ctx, output)
+	assert.NoError(t, err)
+	up := database.OperationQueryFactory.NewUpdate(ctx).
+		Set("output", nil).
+		Set("outputref", output.ID)
+	err = s.UpdateOperation(ctx, op.ID, up)
+	assert.NoError(t, err)
+
+	opRead, err := s.GetOperationByID(ctx, op.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, opRead.Output)
+	assert.Equal(t, *output.ID, *opRead.OutputRef)
+
+	outputRead, err := s.GetOperationOutputByID(ctx, output.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *op.ID, *outputRead.Operation)
+	assert.Equal(t, "ns1", outputRead.Namespace)
+	assert.Equal(t, "output", outputRead.Value.JSONObject().GetString("some"))
+
+	// Replace the output
+	output.Value = fftypes.JSONAnyPtr(`{"some":"other"}`)
+	err = s.UpsertOperationOutput(ctx, output)
+	assert.NoError(t, err)
+	outputRead, err = s.GetOperationOutputByID(ctx, output.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", outputRead.Value.JSONObject().GetString("some"))
+
+	// The output is not visible as data in the namespace
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	data, _, err := s.GetData(ctx, fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+	dataRead, err := s.GetDataByID(ctx, output.ID, true)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead)
+	s.callbacks.AssertNotCalled(t, "UUIDCollectionNSEvent", database.CollectionData, mock.Anything, mock.Anything, mock.Anything)
+	s.callbacks.AssertNotCalled(t, "UUIDCollectionNSEvent", database.CollectionData, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpsertOperationOutputFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertOperationOutput(context.Background(), &fftypes.OperationOutput{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOperationOutputFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertOperationOutput(context.Background(), &fftypes.OperationOutput{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOperationOutputFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertOperationOutput(context.Background(), &fftypes.OperationOutput{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertOperationOutputFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("id1"))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertOperationOutput(context.Background(), &fftypes.OperationOutput{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOperationOutputByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetOperationOutputByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOperationOutputByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(operationOutputColumns))
+	output, err := s.GetOperationOutputByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, output)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOperationOutputByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetOperationOutputByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -41,12 +41,20 @@ var (
 		"input",
 		"output",
 		"retry_id",
+		"output_ref",
+		"fee",
+		"correlation_id",
+		"callback_url",
+		"business_key",
 	}
 	opFilterFieldMap = map[string]string{
-		"tx":     "tx_id",
-		"type":   "optype",
-		"status": "opstatus",
-		"retry":  "retry_id",
+		"tx":            "tx_id",
+		"type":          "optype",
+		"status":        "opstatus",
+		"retry":         "retry_id",
+		"outputref":     "output_ref",
+		"correlationid": "correlation_id",
+		"businesskey":   "business_key",
 	}
 )
 
@@ -57,6 +65,9 @@ func (s *SQLCommon) InsertOperation(ctx context.Context, operation *fftypes.Oper
 	}
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
+	if operation.CorrelationID == "" {
+		operation.CorrelationID = log.CorrelationID(ctx)
+	}
 	if _, err = s.insertTx(ctx, tx,
 		sq.Insert("operations").
 			Columns(opColumns...).
@@ -73,6 +84,11 @@ func (s *SQLCommon) InsertOperation(ctx context.Context, operation *fftypes.Oper
 				operation.Input,
 				operation.Output,
 				operation.Retry,
+				operation.OutputRef,
+				operation.Fee,
+				operation.CorrelationID,
+				operation.CallbackURL,
+				operation.BusinessKey,
 			),
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionOperations, fftypes.ChangeEventTypeCreated, operation.Namespace, operation.ID)
@@ -83,6 +99,7 @@ func (s *SQLCommon) InsertOperation(ctx context.Context, operation *fftypes.Oper
 	); err != nil {
 		return err
 	}
+	s.addPreCommitSyncChange(tx, fftypes.SyncChangeTypeOperation, "operations", operation.ID)
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
@@ -102,6 +119,11 @@ func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*fftypes.Opera
 		&op.Input,
 		&op.Output,
 		&op.Retry,
+		&op.OutputRef,
+		&op.Fee,
+		&op.CorrelationID,
+		&op.CallbackURL,
+		&op.BusinessKey,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "operations")
@@ -174,10 +196,13 @@ func (s *SQLCommon) UpdateOperation(ctx context.Context, id *fftypes.UUID, updat
 	query = query.Set("updated", fftypes.Now())
 	query = query.Where(sq.Eq{"id": id})
 
-	_, err = s.updateTx(ctx, tx, query, nil /* no change events for filter based updates */)
+	updated, err := s.updateTx(ctx, tx, query, nil /* no change events for filter based updates */)
 	if err != nil {
 		return err
 	}
+	if updated > 0 {
+		s.addPreCommitSyncChange(tx, fftypes.SyncChangeTypeOperation, "operations", id)
+	}
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
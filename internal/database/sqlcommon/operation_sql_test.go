@@ -18,11 +18,14 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -32,7 +35,7 @@ func TestOperationE2EWithDB(t *testing.T) {
 
 	s, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
-	ctx := context.Background()
+	ctx := log.WithCorrelationID(context.Background(), "corr1")
 
 	// Create a new operation entry
 	operationID := fftypes.NewUUID()
@@ -46,6 +49,10 @@ func TestOperationE2EWithDB(t *testing.T) {
 		Error:       "pop",
 		Input:       fftypes.JSONObject{"some": "input-info"},
 		Output:      fftypes.JSONObject{"some": "output-info"},
+		OutputRef:   fftypes.NewUUID(),
+		Fee:         fftypes.NewTransactionFee(big.NewInt(21000), big.NewInt(2)),
+		CallbackURL: "https://example.com/callback",
+		BusinessKey: "order-1",
 		Created:     fftypes.Now(),
 		Updated:     fftypes.Now(),
 	}
@@ -73,6 +80,8 @@ func TestOperationE2EWithDB(t *testing.T) {
 		fb.Eq("status", operation.Status),
 		fb.Eq("error", operation.Error),
 		fb.Eq("plugin", operation.Plugin),
+		fb.Eq("outputref", operation.OutputRef),
+		fb.Eq("correlationid", "corr1"),
 		fb.Gt("created", 0),
 		fb.Gt("updated", 0),
 	)
@@ -134,6 +143,8 @@ func TestInsertOperationFailCommit(t *testing.T) {
 	operationID := fftypes.NewUUID()
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("LOCK .*").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT INTO syncchanges .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
 	err := s.InsertOperation(context.Background(), &fftypes.Operation{ID: operationID})
 	assert.Regexp(t, "FF10119", err)
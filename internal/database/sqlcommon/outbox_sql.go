@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	outboxColumns = []string{
+		"id",
+		"namespace",
+		"attempts",
+		"next_attempt",
+		"error",
+		"created",
+	}
+	outboxFilterFieldMap = map[string]string{
+		"next": "next_attempt",
+	}
+)
+
+func (s *SQLCommon) InsertOutboxEntry(ctx context.Context, entry *fftypes.OutboxEntry, hooks ...database.PostCompletionHook) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	entry.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("outbox").
+			Columns(outboxColumns...).
+			Values(
+				entry.ID,
+				entry.Namespace,
+				entry.Attempts,
+				entry.NextAttempt,
+				entry.Error,
+				entry.Created,
+			),
+		func() {
+			for _, hook := range hooks {
+				hook()
+			}
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) outboxResult(ctx context.Context, row *sql.Rows) (*fftypes.OutboxEntry, error) {
+	entry := fftypes.OutboxEntry{}
+	err := row.Scan(
+		&entry.ID,
+		&entry.Namespace,
+		&entry.Attempts,
+		&entry.NextAttempt,
+		&entry.Error,
+		&entry.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "outbox")
+	}
+	return &entry, nil
+}
+
+func (s *SQLCommon) GetOutboxEntryByID(ctx context.Context, id *fftypes.UUID) (entry *fftypes.OutboxEntry, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(outboxColumns...).
+			From("outbox").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Outbox entry '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.outboxResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetOutboxEntries(ctx context.Context, filter database.Filter) (entries []*fftypes.OutboxEntry, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(outboxColumns...).From("outbox"), filter, outboxFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	entries = []*fftypes.OutboxEntry{}
+	for rows.Next() {
+		entry, err := s.outboxResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, s.queryRes(ctx, tx, "outbox", fop, fi), err
+
+}
+
+func (s *SQLCommon) UpdateOutboxEntry(ctx context.Context, id *fftypes.UUID, update database.Update) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.buildUpdate(sq.Update("outbox"), update, outboxFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Where(sq.Eq{"id": id})
+
+	_, err = s.updateTx(ctx, tx, query, nil /* no change events for the outbox */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteOutboxEntry(ctx context.Context, id *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("outbox").Where(sq.Eq{
+		"id": id,
+	}), nil /* no change events for the outbox */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
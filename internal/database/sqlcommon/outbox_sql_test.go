@@ -0,0 +1,191 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Queue an operation
+	entry := &fftypes.OutboxEntry{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		NextAttempt: fftypes.Now(),
+	}
+	hookCalled := false
+	err := s.InsertOutboxEntry(ctx, entry, func() {
+		hookCalled = true
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	assert.NotNil(t, entry.Created)
+
+	// The operation can only be queued once
+	err = s.InsertOutboxEntry(ctx, entry)
+	assert.Regexp(t, "FF10116", err)
+
+	// Lookup by ID
+	entryRead, err := s.GetOutboxEntryByID(ctx, entry.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *entry.ID, *entryRead.ID)
+	assert.Equal(t, "ns1", entryRead.Namespace)
+	assert.Equal(t, entry.NextAttempt.String(), entryRead.NextAttempt.String())
+
+	// Record a failed attempt
+	next := fftypes.FFTime(entry.NextAttempt.Time().Add(1000000000))
+	up := database.OutboxQueryFactory.NewUpdate(ctx).
+		Set("attempts", 1).
+		Set("next", &next).
+		Set("error", "pop")
+	err = s.UpdateOutboxEntry(ctx, entry.ID, up)
+	assert.NoError(t, err)
+
+	// Query the entries that are due
+	fb := database.OutboxQueryFactory.NewFilter(ctx)
+	entries, res, err := s.GetOutboxEntries(ctx, fb.And(fb.Lte("next", entry.NextAttempt)).Count(true))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, int64(0), *res.TotalCount)
+	entries, _, err = s.GetOutboxEntries(ctx, fb.And(fb.Lte("next", &next)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.Equal(t, "pop", entries[0].Error)
+
+	// Remove the entry once submitted
+	err = s.DeleteOutboxEntry(ctx, entry.ID)
+	assert.NoError(t, err)
+	entryRead, err = s.GetOutboxEntryByID(ctx, entry.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, entryRead)
+}
+
+func TestInsertOutboxEntryFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertOutboxEntry(context.Background(), &fftypes.OutboxEntry{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertOutboxEntryFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertOutboxEntry(context.Background(), &fftypes.OutboxEntry{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOutboxEntryByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetOutboxEntryByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOutboxEntryByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetOutboxEntryByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOutboxEntriesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.OutboxQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetOutboxEntries(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOutboxEntriesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.OutboxQueryFactory.NewFilter(context.Background()).Eq("namespace", map[bool]bool{true: false})
+	_, _, err := s.GetOutboxEntries(context.Background(), f)
+	assert.Regexp(t, "FF10149.*namespace", err)
+}
+
+func TestGetOutboxEntriesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.OutboxQueryFactory.NewFilter(context.Background()).Eq("namespace", "")
+	_, _, err := s.GetOutboxEntries(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxEntryUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.OutboxQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateOutboxEntry(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestOutboxEntryUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.OutboxQueryFactory.NewUpdate(context.Background()).Set("attempts", map[bool]bool{true: false})
+	err := s.UpdateOutboxEntry(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*attempts", err)
+}
+
+func TestOutboxEntryUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.OutboxQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateOutboxEntry(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
+
+func TestOutboxEntryDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteOutboxEntry(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestOutboxEntryDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteOutboxEntry(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
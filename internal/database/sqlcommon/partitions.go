@@ -0,0 +1,92 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+// partitionedTable is a high-volume table that an operator can convert to a table partitioned by range on a
+// nanosecond timestamp column, with the pre-existing rows held in a default partition. The conversion is an
+// optional script run outside of the migrations (db/scripts/postgres), so partitioning is only enabled once it has been run.
+type partitionedTable struct {
+	table  string
+	column string
+}
+
+var partitionedTables = []partitionedTable{
+	{table: "messages", column: "created"},
+	{table: "events", column: "created"},
+	{table: "blockchainevents", column: "timestamp"},
+	{table: "pins", column: "created"},
+}
+
+type partitioningConf struct {
+	premake       int
+	checkInterval time.Duration
+}
+
+func (s *SQLCommon) initPartitioning(ctx context.Context, prefix config.Prefix) {
+	if s.features.CreatePartitionSQL == nil {
+		log.L(ctx).Warnf("Partitioning is not supported by the '%s' database provider", s.provider.Name())
+		return
+	}
+	s.partitioning = partitioningConf{
+		premake:       prefix.GetInt(SQLConfPartitioningPremake),
+		checkInterval: prefix.GetDuration(SQLConfPartitioningCheckInterval),
+	}
+	s.createPartitions(ctx, time.Now())
+	go s.partitionLoop(ctx)
+}
+
+func (s *SQLCommon) partitionLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.partitioning.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.createPartitions(ctx, time.Now())
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Partition manager exiting")
+			return
+		}
+	}
+}
+
+// createPartitions makes sure the configured number of monthly partitions exist after the current month.
+// The current month is never created, as rows for it might already have been written to the default partition.
+// Failures are logged and retried on the next check, as rows are written to the default partition in the meantime.
+func (s *SQLCommon) createPartitions(ctx context.Context, now time.Time) {
+	monthStart := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, pt := range partitionedTables {
+		for i := 1; i <= s.partitioning.premake; i++ {
+			from := monthStart.AddDate(0, i, 0)
+			to := from.AddDate(0, 1, 0)
+			partition := fmt.Sprintf("%s_p%s", pt.table, from.Format("200601"))
+			sqlQuery := s.features.CreatePartitionSQL(pt.table, partition, from.UnixNano(), to.UnixNano())
+			log.L(ctx).Debugf(`SQL-> partition: %s`, sqlQuery)
+			if _, err := s.db.ExecContext(ctx, sqlQuery); err != nil {
+				log.L(ctx).Errorf("Failed to create partition '%s' of '%s' by %s: %s", partition, pt.table, pt.column, err)
+			}
+		}
+	}
+}
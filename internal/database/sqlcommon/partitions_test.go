@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitPartitioningNotSupported(t *testing.T) {
+	mp := newMockProvider()
+	mp.prefix.Set(SQLConfPartitioningEnabled, true)
+	_, mdb := mp.init()
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestInitPartitioning(t *testing.T) {
+	mp := newMockProvider()
+	mp.supportsPartitioning = true
+	mp.prefix.Set(SQLConfPartitioningEnabled, true)
+	mp.prefix.Set(SQLConfPartitioningPremake, 1)
+	mp.prefix.Set(SQLConfPartitioningCheckInterval, "1ms")
+	mp.mdb.MatchExpectationsInOrder(false)
+	for i := 0; i < 3; i++ {
+		for _, pt := range partitionedTables {
+			mp.mdb.ExpectExec(fmt.Sprintf(`CREATE PARTITION "%s_p`, pt.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := mp.Init(ctx, mp, mp.prefix, mp.callbacks, mp.capabilities)
+	assert.NoError(t, err)
+	for mp.mdb.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	cancel()
+}
+
+func TestCreatePartitions(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	s.features.CreatePartitionSQL = func(table, partition string, from, to int64) string {
+		return fmt.Sprintf(`CREATE PARTITION "%s" OF "%s" (%d,%d);`, partition, table, from, to)
+	}
+	s.partitioning.premake = 2
+	jan := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, pt := range partitionedTables {
+		mdb.ExpectExec(fmt.Sprintf(`CREATE PARTITION "%s_p202202" OF "%s" \(%d,%d\);`, pt.table, pt.table, feb.UnixNano(), mar.UnixNano())).
+			WillReturnError(fmt.Errorf("pop"))
+		mdb.ExpectExec(fmt.Sprintf(`CREATE PARTITION "%s_p202203" OF "%s" \(%d,%d\);`, pt.table, pt.table, mar.UnixNano(), apr.UnixNano())).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	s.createPartitions(context.Background(), jan.Add(15*24*time.Hour))
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
@@ -36,12 +36,14 @@ var (
 		"idx",
 		"signer",
 		"dispatched",
+		"park_reason",
 		"created",
 	}
 	pinFilterFieldMap = map[string]string{
-		"batch":     "batch_id",
-		"batchhash": "batch_hash",
-		"index":     "idx",
+		"batch":      "batch_id",
+		"batchhash":  "batch_hash",
+		"index":      "idx",
+		"parkreason": "park_reason",
 	}
 )
 
@@ -105,6 +107,7 @@ func (s *SQLCommon) setPinInsertValues(query sq.InsertBuilder, pin *fftypes.Pin)
 		pin.Index,
 		pin.Signer,
 		pin.Dispatched,
+		pin.ParkReason,
 		pin.Created,
 	)
 }
@@ -153,6 +156,7 @@ func (s *SQLCommon) pinResult(ctx context.Context, row *sql.Rows) (*fftypes.Pin,
 		&pin.Index,
 		&pin.Signer,
 		&pin.Dispatched,
+		&pin.ParkReason,
 		&pin.Created,
 		&pin.Sequence,
 	)
@@ -67,6 +67,13 @@ func TestPinsE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(pinRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
 
+	// Record why it is parked
+	err = s.UpdatePins(ctx, database.PinQueryFactory.NewFilter(ctx).Eq("sequence", pin.Sequence), database.PinQueryFactory.NewUpdate(ctx).Set("parkreason", fftypes.PinParkReasonBatchUnavailable))
+	assert.NoError(t, err)
+	pinRes, _, err = s.GetPins(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.PinParkReasonBatchUnavailable, pinRes[0].ParkReason)
+
 	// Set it dispatched
 	err = s.UpdatePins(ctx, database.PinQueryFactory.NewFilter(ctx).Eq("sequence", pin.Sequence), database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true))
 	assert.NoError(t, err)
@@ -32,6 +32,15 @@ type SQLFeatures struct {
 	MultiRowInsert        bool
 	PlaceholderFormat     sq.PlaceholderFormat
 	ExclusiveTableLockSQL func(table string) string
+	// FullTextSearch returns the provider specific condition to match a full-text query against the
+	// searchindex table, and the expression to rank the matches. Nil if full-text search is not supported.
+	FullTextSearch func(query string) (match sq.Sqlizer, rank sq.Sqlizer)
+	// CreatePartitionSQL returns the provider specific DDL to create a partition of a range partitioned table, covering
+	// values of the partition column from 'from' (inclusive) to 'to' (exclusive). Nil if partitioning is not supported.
+	CreatePartitionSQL func(table, partition string, from, to int64) string
+	// ReplicaLagSQL is the provider specific query that returns the replication lag of a read replica, as a
+	// number of seconds. Empty if lag cannot be measured, in which case the replica is always considered current.
+	ReplicaLagSQL string
 }
 
 func DefaultSQLProviderFeatures() SQLFeatures {
@@ -39,10 +39,16 @@ type mockProvider struct {
 	mockDB *sql.DB
 	mdb    sqlmock.Sqlmock
 
+	replicaDB *sql.DB
+	rdb       sqlmock.Sqlmock
+
 	fakePSQLInsert          bool
 	openError               error
 	getMigrationDriverError error
 	individualSort          bool
+	supportsPartitioning    bool
+	replicaLagSQL           string
+	replicaOpenError        error
 }
 
 func newMockProvider() *mockProvider {
@@ -55,6 +61,7 @@ func newMockProvider() *mockProvider {
 	mp.SQLCommon.InitPrefix(mp, mp.prefix)
 	mp.prefix.Set(SQLConfMaxConnections, 10)
 	mp.mockDB, mp.mdb, _ = sqlmock.New()
+	mp.replicaDB, mp.rdb, _ = sqlmock.New()
 	return mp
 }
 
@@ -78,6 +85,12 @@ func (psql *mockProvider) Features() SQLFeatures {
 	features.ExclusiveTableLockSQL = func(table string) string {
 		return fmt.Sprintf(`LOCK TABLE "%s" IN EXCLUSIVE MODE;`, table)
 	}
+	if psql.supportsPartitioning {
+		features.CreatePartitionSQL = func(table, partition string, from, to int64) string {
+			return fmt.Sprintf(`CREATE PARTITION "%s" OF "%s" (%d,%d);`, partition, table, from, to)
+		}
+	}
+	features.ReplicaLagSQL = psql.replicaLagSQL
 	return features
 }
 
@@ -89,6 +102,9 @@ func (mp *mockProvider) ApplyInsertQueryCustomizations(insert sq.InsertBuilder,
 }
 
 func (mp *mockProvider) Open(url string) (*sql.DB, error) {
+	if url != "" && url == mp.prefix.GetString(SQLConfReadReplicaURL) {
+		return mp.replicaDB, mp.replicaOpenError
+	}
 	return mp.mockDB, mp.openError
 }
 
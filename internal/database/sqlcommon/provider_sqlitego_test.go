@@ -82,6 +82,7 @@ func (psql *sqliteGoTestProvider) Features() SQLFeatures {
 	features := DefaultSQLProviderFeatures()
 	features.PlaceholderFormat = sq.Dollar
 	features.UseILIKE = false // Not supported
+	features.FullTextSearch = SQLiteFullTextSearch
 	return features
 }
 
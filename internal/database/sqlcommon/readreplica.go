@@ -0,0 +1,116 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// readReplica is an optional read-only connection, used for queries made with a context marked by
+// database.WithReadReplica while the replica is within the configured staleness of the primary
+type readReplica struct {
+	db            *sql.DB
+	lagSQL        string
+	maxStaleness  time.Duration
+	checkInterval time.Duration
+	mux           sync.Mutex
+	stale         bool
+}
+
+func (s *SQLCommon) initReadReplica(ctx context.Context, prefix config.Prefix) (err error) {
+	rr := &readReplica{
+		lagSQL:        s.features.ReplicaLagSQL,
+		maxStaleness:  prefix.GetDuration(SQLConfReadReplicaMaxStaleness),
+		checkInterval: prefix.GetDuration(SQLConfReadReplicaLagCheckInterval),
+	}
+	if rr.db, err = s.provider.Open(prefix.GetString(SQLConfReadReplicaURL)); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBInitFailed)
+	}
+	if connLimit := prefix.GetInt(SQLConfMaxConnections); connLimit > 0 {
+		rr.db.SetMaxOpenConns(connLimit)
+		rr.db.SetConnMaxIdleTime(prefix.GetDuration(SQLConfMaxConnIdleTime))
+		rr.db.SetConnMaxLifetime(prefix.GetDuration(SQLConfMaxConnLifetime))
+	}
+	s.replica = rr
+	if rr.lagSQL == "" {
+		log.L(ctx).Warnf("Replication lag cannot be measured by the '%s' database provider - the read replica is assumed to be current", s.provider.Name())
+		return nil
+	}
+	rr.checkLag(ctx)
+	go rr.lagCheckLoop(ctx)
+	return nil
+}
+
+func (rr *readReplica) lagCheckLoop(ctx context.Context) {
+	ticker := time.NewTicker(rr.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rr.checkLag(ctx)
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Read replica lag checker exiting")
+			return
+		}
+	}
+}
+
+func (rr *readReplica) checkLag(ctx context.Context) {
+	var lagSeconds float64
+	err := rr.db.QueryRowContext(ctx, rr.lagSQL).Scan(&lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	stale := err != nil || lag > rr.maxStaleness
+
+	rr.mux.Lock()
+	changed := stale != rr.stale
+	rr.stale = stale
+	rr.mux.Unlock()
+
+	l := log.L(ctx)
+	switch {
+	case !changed:
+		l.Tracef("Read replica lag: %s (err=%v)", lag, err)
+	case err != nil:
+		l.Warnf("Read replica lag check failed - using primary for all reads: %s", err)
+	case stale:
+		l.Warnf("Read replica lag %s exceeds %s - using primary for all reads", lag, rr.maxStaleness)
+	default:
+		l.Infof("Read replica lag %s is within %s - using read replica", lag, rr.maxStaleness)
+	}
+}
+
+// readDB returns the connection to use for a query outside of a transaction
+func (s *SQLCommon) readDB(ctx context.Context) *sql.DB {
+	rr := s.replica
+	if rr == nil || !database.ReadReplicaAllowed(ctx) {
+		return s.db
+	}
+	rr.mux.Lock()
+	defer rr.mux.Unlock()
+	if rr.stale {
+		return s.db
+	}
+	return rr.db
+}
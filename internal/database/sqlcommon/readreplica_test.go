@@ -0,0 +1,127 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockReplicaProvider() *mockProvider {
+	mp := newMockProvider()
+	mp.prefix.Set(SQLConfReadReplicaURL, "replica")
+	mp.prefix.Set(SQLConfReadReplicaMaxStaleness, "1s")
+	mp.prefix.Set(SQLConfReadReplicaLagCheckInterval, "1h")
+	mp.replicaLagSQL = "SELECT LAG"
+	return mp
+}
+
+func TestReadReplicaRouting(t *testing.T) {
+	mp := newMockReplicaProvider()
+	mp.rdb.ExpectQuery("SELECT LAG").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.5))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := mp.Init(ctx, mp, mp.prefix, mp.callbacks, mp.capabilities)
+	assert.NoError(t, err)
+
+	mp.rdb.ExpectQuery("SELECT.*replica").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	mp.rdb.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mp.mdb.ExpectQuery("SELECT.*primary").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	mp.mdb.ExpectBegin()
+	mp.mdb.ExpectQuery("SELECT.*intx").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	mp.mdb.ExpectCommit()
+
+	rctx := database.WithReadReplica(context.Background())
+	_, _, err = mp.query(rctx, sq.Select("seq").From("replica"))
+	assert.NoError(t, err)
+	_, err = mp.countQuery(rctx, nil, "table1", sq.Eq{"a": 1}, "")
+	assert.NoError(t, err)
+	_, _, err = mp.query(context.Background(), sq.Select("seq").From("primary"))
+	assert.NoError(t, err)
+	err = mp.RunAsGroup(rctx, func(ctx context.Context) error {
+		_, _, err := mp.query(ctx, sq.Select("seq").From("intx"))
+		return err
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mp.rdb.ExpectationsWereMet())
+	assert.NoError(t, mp.mdb.ExpectationsWereMet())
+
+	mp.mdb.ExpectClose()
+	mp.rdb.ExpectClose()
+	mp.Close()
+}
+
+func TestReadReplicaStale(t *testing.T) {
+	mp := newMockReplicaProvider()
+	mp.rdb.ExpectQuery("SELECT LAG").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(5))
+	mp.init()
+
+	rctx := database.WithReadReplica(context.Background())
+	assert.Equal(t, mp.mockDB, mp.readDB(rctx))
+
+	mp.rdb.ExpectQuery("SELECT LAG").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(5))
+	mp.replica.checkLag(rctx)
+	assert.Equal(t, mp.mockDB, mp.readDB(rctx))
+
+	mp.rdb.ExpectQuery("SELECT LAG").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	mp.replica.checkLag(rctx)
+	assert.Equal(t, mp.replicaDB, mp.readDB(rctx))
+
+	mp.rdb.ExpectQuery("SELECT LAG").WillReturnError(fmt.Errorf("pop"))
+	mp.replica.checkLag(rctx)
+	assert.Equal(t, mp.mockDB, mp.readDB(rctx))
+
+	assert.NoError(t, mp.rdb.ExpectationsWereMet())
+}
+
+func TestReadReplicaLagCheckLoop(t *testing.T) {
+	mp := newMockReplicaProvider()
+	mp.prefix.Set(SQLConfReadReplicaLagCheckInterval, "1ms")
+	for i := 0; i < 3; i++ {
+		mp.rdb.ExpectQuery("SELECT LAG").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := mp.Init(ctx, mp, mp.prefix, mp.callbacks, mp.capabilities)
+	assert.NoError(t, err)
+	for mp.rdb.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	cancel()
+}
+
+func TestReadReplicaNoLagSQL(t *testing.T) {
+	mp := newMockReplicaProvider()
+	mp.replicaLagSQL = ""
+	mp.init()
+	assert.Equal(t, mp.replicaDB, mp.readDB(database.WithReadReplica(context.Background())))
+	assert.NoError(t, mp.rdb.ExpectationsWereMet())
+}
+
+func TestReadReplicaOpenFail(t *testing.T) {
+	mp := newMockReplicaProvider()
+	mp.replicaOpenError = fmt.Errorf("pop")
+	err := mp.Init(context.Background(), mp, mp.prefix, mp.callbacks, mp.capabilities)
+	assert.Regexp(t, "FF10112.*pop", err)
+}
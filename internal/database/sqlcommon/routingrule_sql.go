@@ -0,0 +1,217 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	routingRuleColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"tag",
+		"topic",
+		"labels",
+		"created",
+		"updated",
+	}
+	routingRuleFilterFieldMap = map[string]string{}
+)
+
+func (s *SQLCommon) UpsertRoutingRule(ctx context.Context, rule *fftypes.RoutingRule, allowExisting bool) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	existing := false
+	if allowExisting {
+		// Do a select within the transaction to detemine if the name already exists
+		ruleRows, _, err := s.queryTx(ctx, tx,
+			sq.Select("id").
+				From("routingrules").
+				Where(sq.Eq{
+					"namespace": rule.Namespace,
+					"name":      rule.Name,
+				}),
+		)
+		if err != nil {
+			return err
+		}
+
+		existing = ruleRows.Next()
+		if existing {
+			var id fftypes.UUID
+			_ = ruleRows.Scan(&id)
+			if rule.ID != nil && *rule.ID != id {
+				ruleRows.Close()
+				return database.IDMismatch
+			}
+			rule.ID = &id // Update on returned object
+		}
+		ruleRows.Close()
+	}
+
+	if existing {
+		// Update the routing rule
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("routingrules").
+				// Note we do not update ID or created
+				Set("tag", rule.Tag).
+				Set("topic", rule.Topic).
+				Set("labels", rule.Labels).
+				Set("updated", rule.Updated).
+				Where(sq.Eq{"id": rule.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionRoutingRules, fftypes.ChangeEventTypeUpdated, rule.Namespace, rule.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		if rule.ID == nil {
+			rule.ID = fftypes.NewUUID()
+		}
+
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("routingrules").
+				Columns(routingRuleColumns...).
+				Values(
+					rule.ID,
+					rule.Namespace,
+					rule.Name,
+					rule.Tag,
+					rule.Topic,
+					rule.Labels,
+					rule.Created,
+					rule.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionRoutingRules, fftypes.ChangeEventTypeCreated, rule.Namespace, rule.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) routingRuleResult(ctx context.Context, row *sql.Rows) (*fftypes.RoutingRule, error) {
+	rule := fftypes.RoutingRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.Namespace,
+		&rule.Name,
+		&rule.Tag,
+		&rule.Topic,
+		&rule.Labels,
+		&rule.Created,
+		&rule.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "routingrules")
+	}
+	return &rule, nil
+}
+
+func (s *SQLCommon) getRoutingRuleEq(ctx context.Context, eq sq.Eq, textName string) (*fftypes.RoutingRule, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(routingRuleColumns...).
+			From("routingrules").
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Routing rule '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.routingRuleResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetRoutingRuleByID(ctx context.Context, id *fftypes.UUID) (*fftypes.RoutingRule, error) {
+	return s.getRoutingRuleEq(ctx, sq.Eq{"id": id}, id.String())
+}
+
+func (s *SQLCommon) GetRoutingRuleByName(ctx context.Context, ns, name string) (*fftypes.RoutingRule, error) {
+	return s.getRoutingRuleEq(ctx, sq.Eq{"namespace": ns, "name": name}, fmt.Sprintf("%s:%s", ns, name))
+}
+
+func (s *SQLCommon) GetRoutingRules(ctx context.Context, filter database.Filter) ([]*fftypes.RoutingRule, *database.FilterResult, error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(routingRuleColumns...).From("routingrules"), filter, routingRuleFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	rules := []*fftypes.RoutingRule{}
+	for rows.Next() {
+		rule, err := s.routingRuleResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, s.queryRes(ctx, tx, "routingrules", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteRoutingRuleByID(ctx context.Context, id *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	rule, err := s.GetRoutingRuleByID(ctx, id)
+	if err == nil && rule != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("routingrules").Where(sq.Eq{
+			"id": id,
+		}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionRoutingRules, fftypes.ChangeEventTypeDeleted, rule.Namespace, rule.ID)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,227 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRoutingRulesE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new routing rule
+	rule := &fftypes.RoutingRule{
+		Namespace: "ns1",
+		Name:      "rule1",
+		Tag:       "^order_.*",
+		Labels:    fftypes.FFStringArray{"orders"},
+		Created:   fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionRoutingRules, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+
+	err := s.UpsertRoutingRule(ctx, rule, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, rule.ID)
+
+	// Check we get the exact same rule back
+	ruleRead, err := s.GetRoutingRuleByName(ctx, rule.Namespace, rule.Name)
+	assert.NoError(t, err)
+	ruleJson, _ := json.Marshal(&rule)
+	ruleReadJson, _ := json.Marshal(&ruleRead)
+	assert.Equal(t, string(ruleJson), string(ruleReadJson))
+
+	// Update the rule by name
+	ruleUpdated := &fftypes.RoutingRule{
+		ID:        fftypes.NewUUID(), // will fail with us trying to update this
+		Namespace: "ns1",
+		Name:      "rule1",
+		Topic:     "payments",
+		Labels:    fftypes.FFStringArray{"finance", "orders"},
+		Created:   rule.Created,
+		Updated:   fftypes.Now(),
+	}
+
+	// Rejects attempt to update ID
+	err = s.UpsertRoutingRule(context.Background(), ruleUpdated, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	// Blank out the ID and retry
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionRoutingRules, fftypes.ChangeEventTypeUpdated, "ns1", rule.ID).Return()
+	ruleUpdated.ID = nil
+	err = s.UpsertRoutingRule(context.Background(), ruleUpdated, true)
+	assert.NoError(t, err)
+	assert.Equal(t, rule.ID, ruleUpdated.ID)
+
+	ruleRead, err = s.GetRoutingRuleByID(ctx, rule.ID)
+	assert.NoError(t, err)
+	ruleJson, _ = json.Marshal(&ruleUpdated)
+	ruleReadJson, _ = json.Marshal(&ruleRead)
+	assert.Equal(t, string(ruleJson), string(ruleReadJson))
+
+	// Query back the rule
+	fb := database.RoutingRuleQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Contains("labels", "finance"),
+	)
+	rules, res, err := s.GetRoutingRules(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rules))
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	// Test delete, and refind no return
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionRoutingRules, fftypes.ChangeEventTypeDeleted, "ns1", rule.ID).Return()
+	err = s.DeleteRoutingRuleByID(ctx, rule.ID)
+	assert.NoError(t, err)
+	rules, _, err = s.GetRoutingRules(ctx, database.RoutingRuleQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(rules))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertRoutingRuleFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertRoutingRule(context.Background(), &fftypes.RoutingRule{}, true)
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertRoutingRuleFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertRoutingRule(context.Background(), &fftypes.RoutingRule{Name: "name1"}, true)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertRoutingRuleFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertRoutingRule(context.Background(), &fftypes.RoutingRule{Name: "name1"}, false)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertRoutingRuleFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).
+		AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertRoutingRule(context.Background(), &fftypes.RoutingRule{Name: "name1"}, true)
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertRoutingRuleFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertRoutingRule(context.Background(), &fftypes.RoutingRule{Name: "name1"}, true)
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoutingRuleByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetRoutingRuleByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoutingRuleByNameNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(routingRuleColumns))
+	rule, err := s.GetRoutingRuleByName(context.Background(), "ns1", "name1")
+	assert.NoError(t, err)
+	assert.Nil(t, rule)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoutingRuleByNameScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetRoutingRuleByName(context.Background(), "ns1", "name1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoutingRulesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.RoutingRuleQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetRoutingRules(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoutingRulesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.RoutingRuleQueryFactory.NewFilter(context.Background()).Eq("name", map[bool]bool{true: false})
+	_, _, err := s.GetRoutingRules(context.Background(), f)
+	assert.Regexp(t, "FF10149.*type", err)
+}
+
+func TestGetRoutingRulesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.RoutingRuleQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetRoutingRules(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoutingRuleDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteRoutingRuleByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestRoutingRuleDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(routingRuleColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "rule1", "", "", "label1", fftypes.Now(), fftypes.Now()),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteRoutingRuleByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
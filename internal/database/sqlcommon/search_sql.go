@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	searchColumns = []string{
+		"namespace",
+		"type",
+		"ref_id",
+		"content",
+		"created",
+	}
+	searchFilterFieldMap = map[string]string{
+		"id": "ref_id",
+	}
+)
+
+// SQLiteFullTextSearch uses an FTS4 MATCH on the searchindex virtual table, with each term of the query
+// quoted so it cannot be interpreted as FTS query syntax. The rank is the number of matching terms
+// found, as computed from the offsets() auxiliary function that returns four integers per match.
+func SQLiteFullTextSearch(query string) (sq.Sqlizer, sq.Sqlizer) {
+	terms := strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	for i, term := range terms {
+		terms[i] = fmt.Sprintf(`"%s"`, term)
+	}
+	return sq.Expr("searchindex MATCH ?", strings.Join(terms, " ")),
+		sq.Expr("(length(offsets(searchindex)) - length(replace(offsets(searchindex), ' ', '')) + 1) / 4")
+}
+
+// indexForSearch adds an entry to the full-text search index, within the same transaction as the
+// insert of the object itself. It is a no-op if full-text search is disabled.
+func (s *SQLCommon) indexForSearch(ctx context.Context, tx *txWrapper, ns string, resultType fftypes.SearchResultType, id *fftypes.UUID, created *fftypes.FFTime, content ...string) error {
+	if !s.capabilities.FullTextSearch {
+		return nil
+	}
+	text := strings.TrimSpace(strings.Join(content, " "))
+	if text == "" {
+		return nil
+	}
+	_, err := s.insertTx(ctx, tx,
+		sq.Insert("searchindex").
+			Columns(searchColumns...).
+			Values(
+				ns,
+				resultType,
+				id,
+				text,
+				created,
+			),
+		nil, // no change event
+	)
+	return err
+}
+
+func (s *SQLCommon) Search(ctx context.Context, query string, filter database.Filter) ([]*fftypes.SearchResult, *database.FilterResult, error) {
+	if !s.capabilities.FullTextSearch {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgFullTextSearchDisabled)
+	}
+
+	match, rank := s.features.FullTextSearch(query)
+	sel := sq.Select("type", "ref_id", "created").
+		Column(sq.Alias(rank, "score")).
+		From("searchindex")
+	sel, fop, fi, err := s.filterSelect(ctx, "", sel, filter, searchFilterFieldMap, []interface{}{"score"}, match)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, sel)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	results := []*fftypes.SearchResult{}
+	for rows.Next() {
+		var result fftypes.SearchResult
+		if err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Created,
+			&result.Score,
+		); err != nil {
+			return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "searchindex")
+		}
+		results = append(results, &result)
+	}
+
+	return results, s.queryRes(ctx, tx, "searchindex", fop, fi), err
+}
@@ -0,0 +1,214 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Tag:       "shipment",
+			Topics:    fftypes.FFStringArray{"widgets", "orders"},
+			Created:   fftypes.Now(),
+			DataHash:  fftypes.NewRandB32(),
+		},
+		Hash: fftypes.NewRandB32(),
+	}
+	err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	data := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"description":"urgent shipment of widgets, more widgets to follow"}`),
+	}
+	err = s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	event := &fftypes.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "WidgetsShipped",
+		Output:    fftypes.JSONObject{"status": "delivered"},
+		Timestamp: fftypes.Now(),
+	}
+	err = s.InsertBlockchainEvent(ctx, event)
+	assert.NoError(t, err)
+
+	otherNS := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns2",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`"widgets"`),
+	}
+	err = s.InsertDataArray(ctx, fftypes.DataArray{otherNS})
+	assert.NoError(t, err)
+
+	// Data has the most hits, so ranks first
+	fb := database.SearchQueryFactory.NewFilter(ctx)
+	results, res, err := s.Search(ctx, "widgets", fb.Eq("namespace", "ns1").Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *res.TotalCount)
+	assert.Len(t, results, 2)
+	assert.Equal(t, fftypes.SearchResultTypeData, results[0].Type)
+	assert.Equal(t, *data.ID, *results[0].ID)
+	assert.Equal(t, fftypes.SearchResultTypeMessage, results[1].Type)
+	assert.Equal(t, *msg.Header.ID, *results[1].ID)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	// Multiple terms must all match, and query syntax is escaped
+	results, _, err = s.Search(ctx, `"delivered" OR`, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+	results, _, err = s.Search(ctx, `"delivered"`, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, fftypes.SearchResultTypeBlockchainEvent, results[0].Type)
+	assert.Equal(t, *event.ID, *results[0].ID)
+
+	// Filter by type
+	results, _, err = s.Search(ctx, "widgets", fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("type", fftypes.SearchResultTypeMessage),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, *msg.Header.ID, *results[0].ID)
+}
+
+func TestSearchDisabled(t *testing.T) {
+	s, _ := newMockProvider().init()
+	_, _, err := s.Search(context.Background(), "widgets", database.SearchQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "FF10383", err)
+}
+
+func newMockSearchProvider() (*mockProvider, sqlmock.Sqlmock) {
+	s, mock := newMockProvider().init()
+	s.capabilities.FullTextSearch = true
+	s.features.FullTextSearch = func(query string) (sq.Sqlizer, sq.Sqlizer) {
+		return sq.Expr("content MATCH ?", query), sq.Expr("1")
+	}
+	return s, mock
+}
+
+func TestSearchQueryFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.Search(context.Background(), "widgets", database.SearchQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchBuildQueryFail(t *testing.T) {
+	s, _ := newMockSearchProvider()
+	f := database.SearchQueryFactory.NewFilter(context.Background()).Eq("type", map[bool]bool{true: false})
+	_, _, err := s.Search(context.Background(), "widgets", f)
+	assert.Regexp(t, "FF10149.*type", err)
+}
+
+func TestSearchScanFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("only one"))
+	_, _, err := s.Search(context.Background(), "widgets", database.SearchQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexForSearchEmpty(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	err := s.indexForSearch(context.Background(), nil, "ns1", fftypes.SearchResultTypeData, fftypes.NewUUID(), fftypes.Now(), " ", "")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageIndexForSearchFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*messages").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*searchindex").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessage(context.Background(), &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Tag: "tag1"},
+	}, database.UpsertOptimizationNew)
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertMessagesIndexForSearchFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	s.features.MultiRowInsert = true
+	s.fakePSQLInsert = true
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT .*messages").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}).AddRow(int64(1001)))
+	mock.ExpectQuery("INSERT .*searchindex").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertMessages(context.Background(), []*fftypes.Message{
+		{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Tag: "tag1"}},
+	})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDataArrayIndexForSearchFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	s.features.MultiRowInsert = true
+	s.fakePSQLInsert = true
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT .*data").WillReturnRows(sqlmock.NewRows([]string{sequenceColumn}).AddRow(int64(1001)))
+	mock.ExpectQuery("INSERT .*searchindex").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDataArray(context.Background(), fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
+	})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertBlockchainEventIndexForSearchFail(t *testing.T) {
+	s, mock := newMockSearchProvider()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*blockchainevents").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*searchindex").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertBlockchainEvent(context.Background(), &fftypes.BlockchainEvent{
+		ID:   fftypes.NewUUID(),
+		Name: "Changed",
+	})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -25,6 +25,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -41,15 +42,19 @@ type SQLCommon struct {
 	callbacks    database.Callbacks
 	provider     Provider
 	features     SQLFeatures
+	faultTarget  string
+	partitioning partitioningConf
+	replica      *readReplica
 }
 
 type txContextKey struct{}
 
 type txWrapper struct {
-	sqlTX           *sql.Tx
-	preCommitEvents []*fftypes.Event
-	postCommit      []func()
-	tableLocks      []string
+	sqlTX                *sql.Tx
+	preCommitEvents      []*fftypes.Event
+	preCommitSyncChanges []*syncChange
+	postCommit           []func()
+	tableLocks           []string
 }
 
 // shortenSQL grabs the first three words of a SQL statement, for minimal debug logging (SQL statements can be huge
@@ -81,6 +86,9 @@ func (s *SQLCommon) Init(ctx context.Context, provider Provider, prefix config.P
 		return i18n.NewError(ctx, i18n.MsgDBInitFailed)
 	}
 
+	// Injected faults are matched against the name of the config section
+	s.faultTarget = strings.TrimSuffix(prefix.Resolve(SQLConfDatasourceURL), "."+SQLConfDatasourceURL)
+
 	if s.db, err = provider.Open(prefix.GetString(SQLConfDatasourceURL)); err != nil {
 		return i18n.WrapError(ctx, err, i18n.MsgDBInitFailed)
 	}
@@ -99,6 +107,7 @@ func (s *SQLCommon) Init(ctx context.Context, provider Provider, prefix config.P
 	if connLimit > 1 {
 		capabilities.Concurrency = true
 	}
+	capabilities.FullTextSearch = s.features.FullTextSearch != nil && prefix.GetBool(SQLConfFullTextSearchEnabled)
 
 	if prefix.GetBool(SQLConfMigrationsAuto) {
 		if err = s.applyDBMigrations(ctx, prefix, provider); err != nil {
@@ -106,6 +115,16 @@ func (s *SQLCommon) Init(ctx context.Context, provider Provider, prefix config.P
 		}
 	}
 
+	if prefix.GetString(SQLConfReadReplicaURL) != "" {
+		if err = s.initReadReplica(ctx, prefix); err != nil {
+			return err
+		}
+	}
+
+	if prefix.GetBool(SQLConfPartitioningEnabled) {
+		s.initPartitioning(ctx, prefix)
+	}
+
 	return nil
 }
 
@@ -170,6 +189,9 @@ func (s *SQLCommon) beginOrUseTx(ctx context.Context) (ctx1 context.Context, tx
 	l := log.L(ctx).WithField("dbtx", fftypes.ShortID())
 	ctx1 = log.WithLogger(ctx, l)
 	l.Debugf("SQL-> begin")
+	if err := faults.Inject(ctx1, s.faultTarget, "BEGIN"); err != nil {
+		return ctx1, nil, false, i18n.WrapError(ctx1, err, i18n.MsgDBBeginFailed)
+	}
 	sqlTX, err := s.db.Begin()
 	if err != nil {
 		return ctx1, nil, false, i18n.WrapError(ctx1, err, i18n.MsgDBBeginFailed)
@@ -196,11 +218,14 @@ func (s *SQLCommon) queryTx(ctx context.Context, tx *txWrapper, q sq.SelectBuild
 	}
 	l.Debugf(`SQL-> query: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> query args: %+v`, args)
+	if err := faults.Inject(ctx, s.faultTarget, sqlQuery); err != nil {
+		return nil, tx, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
 	var rows *sql.Rows
 	if tx != nil {
 		rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
 	} else {
-		rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+		rows, err = s.readDB(ctx).QueryContext(ctx, sqlQuery, args...)
 	}
 	if err != nil {
 		l.Errorf(`SQL query failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -236,7 +261,7 @@ func (s *SQLCommon) countQuery(ctx context.Context, tx *txWrapper, tableName str
 	if tx != nil {
 		rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
 	} else {
-		rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+		rows, err = s.readDB(ctx).QueryContext(ctx, sqlQuery, args...)
 	}
 	if err != nil {
 		l.Errorf(`SQL count query failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -285,6 +310,9 @@ func (s *SQLCommon) insertTxRows(ctx context.Context, tx *txWrapper, q sq.Insert
 	}
 	l.Debugf(`SQL-> insert %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> insert query: %s (args: %+v)`, sqlQuery, args)
+	if err := faults.Inject(ctx, s.faultTarget, sqlQuery); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBInsertFailed)
+	}
 	if useQuery {
 		result, err := tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
 		for i := 0; i < len(sequences) && err == nil; i++ {
@@ -325,28 +353,40 @@ func (s *SQLCommon) insertTxRows(ctx context.Context, tx *txWrapper, q sq.Insert
 }
 
 func (s *SQLCommon) deleteTx(ctx context.Context, tx *txWrapper, q sq.DeleteBuilder, postCommit func()) error {
+	ra, err := s.deleteManyTx(ctx, tx, q)
+	if err != nil {
+		return err
+	}
+	if ra < 1 {
+		return database.DeleteRecordNotFound
+	}
+
+	if postCommit != nil {
+		s.postCommitEvent(tx, postCommit)
+	}
+	return nil
+}
+
+// deleteManyTx performs a delete that can match any number of rows, returning the number deleted
+func (s *SQLCommon) deleteManyTx(ctx context.Context, tx *txWrapper, q sq.DeleteBuilder) (int64, error) {
 	l := log.L(ctx)
 	sqlQuery, args, err := q.PlaceholderFormat(s.features.PlaceholderFormat).ToSql()
 	if err != nil {
-		return i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
 	}
 	l.Debugf(`SQL-> delete: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> delete query: %s args: %+v`, sqlQuery, args)
+	if err := faults.Inject(ctx, s.faultTarget, sqlQuery); err != nil {
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBDeleteFailed)
+	}
 	res, err := tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
 		l.Errorf(`SQL delete failed: %s sql=[ %s ]: %s`, err, sqlQuery, err)
-		return i18n.WrapError(ctx, err, i18n.MsgDBDeleteFailed)
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBDeleteFailed)
 	}
 	ra, _ := res.RowsAffected()
 	l.Debugf(`SQL<- delete affected=%d`, ra)
-	if ra < 1 {
-		return database.DeleteRecordNotFound
-	}
-
-	if postCommit != nil {
-		s.postCommitEvent(tx, postCommit)
-	}
-	return nil
+	return ra, nil
 }
 
 func (s *SQLCommon) updateTx(ctx context.Context, tx *txWrapper, q sq.UpdateBuilder, postCommit func()) (int64, error) {
@@ -357,6 +397,9 @@ func (s *SQLCommon) updateTx(ctx context.Context, tx *txWrapper, q sq.UpdateBuil
 	}
 	l.Debugf(`SQL-> update: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> update query: %s (args: %+v)`, sqlQuery, args)
+	if err := faults.Inject(ctx, s.faultTarget, sqlQuery); err != nil {
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBUpdateFailed)
+	}
 	res, err := tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
 		l.Errorf(`SQL update failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -437,8 +480,18 @@ func (s *SQLCommon) commitTx(ctx context.Context, tx *txWrapper, autoCommit bool
 			return err
 		}
 	}
+	if len(tx.preCommitSyncChanges) > 0 {
+		if err := s.insertSyncChangesPreCommit(ctx, tx, tx.preCommitSyncChanges); err != nil {
+			s.rollbackTx(ctx, tx, false)
+			return err
+		}
+	}
 
 	l.Debugf(`SQL-> commit`)
+	if err := faults.Inject(ctx, s.faultTarget, "COMMIT"); err != nil {
+		s.rollbackTx(ctx, tx, false)
+		return i18n.WrapError(ctx, err, i18n.MsgDBCommitFailed)
+	}
 	err := tx.sqlTX.Commit()
 	if err != nil {
 		l.Errorf(`SQL commit failed: %s`, err)
@@ -465,4 +518,8 @@ func (s *SQLCommon) Close() {
 		err := s.db.Close()
 		log.L(context.Background()).Debugf("Database closed (err=%v)", err)
 	}
+	if s.replica != nil {
+		err := s.replica.db.Close()
+		log.L(context.Background()).Debugf("Read replica closed (err=%v)", err)
+	}
 }
@@ -0,0 +1,91 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/faults"
+	"github.com/stretchr/testify/assert"
+)
+
+func injectFault(t *testing.T, operation string) func() {
+	scenario, err := faults.ParseScenario(context.Background(), "ut", []byte(fmt.Sprintf(`{"rules":[{"operation":"%s","error":"pop"}]}`, operation)))
+	assert.NoError(t, err)
+	faults.Activate(context.Background(), scenario)
+	return func() {
+		faults.Activate(context.Background(), nil)
+	}
+}
+
+func TestInjectedFaultBegin(t *testing.T) {
+	defer injectFault(t, "^BEGIN$")()
+	s, mock := newMockProvider().init()
+	_, _, _, err := s.beginOrUseTx(context.Background())
+	assert.Regexp(t, "FF10114.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInjectedFaultQuery(t *testing.T) {
+	defer injectFault(t, "^SELECT")()
+	s, mock := newMockProvider().init()
+	_, _, err := s.query(context.Background(), sq.Select("*").From("table1"))
+	assert.Regexp(t, "FF10115.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInjectedFaultInsert(t *testing.T) {
+	defer injectFault(t, "^INSERT")()
+	s, mock := newMockProvider().init()
+	_, err := s.insertTx(context.Background(), nil, sq.Insert("table1").Columns("col1").Values("val1"), nil)
+	assert.Regexp(t, "FF10116.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInjectedFaultUpdate(t *testing.T) {
+	defer injectFault(t, "^UPDATE")()
+	s, mock := newMockProvider().init()
+	_, err := s.updateTx(context.Background(), nil, sq.Update("table1").Set("col1", "val1"), nil)
+	assert.Regexp(t, "FF10117.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInjectedFaultDelete(t *testing.T) {
+	defer injectFault(t, "^DELETE")()
+	s, mock := newMockProvider().init()
+	err := s.deleteTx(context.Background(), nil, sq.Delete("table1"), nil)
+	assert.Regexp(t, "FF10118.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInjectedFaultCommit(t *testing.T) {
+	defer injectFault(t, "^COMMIT$")()
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := s.RunAsGroup(context.Background(), func(ctx context.Context) (err error) {
+		return
+	})
+	assert.Regexp(t, "FF10119.*FF10453", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
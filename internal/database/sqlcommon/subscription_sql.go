@@ -38,8 +38,11 @@ var (
 		"options",
 		"created",
 		"updated",
+		"annotations",
+	}
+	subscriptionFilterFieldMap = map[string]string{
+		"annotations.*": "annotations",
 	}
-	subscriptionFilterFieldMap = map[string]string{}
 )
 
 func (s *SQLCommon) UpsertSubscription(ctx context.Context, subscription *fftypes.Subscription, allowExisting bool) (err error) {
@@ -91,6 +94,7 @@ func (s *SQLCommon) UpsertSubscription(ctx context.Context, subscription *fftype
 				Set("options", subscription.Options).
 				Set("created", subscription.Created).
 				Set("updated", subscription.Updated).
+				Set("annotations", subscription.Annotations).
 				Where(sq.Eq{
 					"namespace": subscription.Namespace,
 					"name":      subscription.Name,
@@ -118,6 +122,7 @@ func (s *SQLCommon) UpsertSubscription(ctx context.Context, subscription *fftype
 					subscription.Options,
 					subscription.Created,
 					subscription.Updated,
+					subscription.Annotations,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionSubscriptions, fftypes.ChangeEventTypeCreated, subscription.Namespace, subscription.ID)
@@ -128,6 +133,10 @@ func (s *SQLCommon) UpsertSubscription(ctx context.Context, subscription *fftype
 
 	}
 
+	if err = s.updateAnnotations(ctx, tx, subscription.ID, subscription.Namespace, subscription.Annotations, existing); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -142,6 +151,7 @@ func (s *SQLCommon) subscriptionResult(ctx context.Context, row *sql.Rows) (*fft
 		&subscription.Options,
 		&subscription.Created,
 		&subscription.Updated,
+		&subscription.Annotations,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "subscriptions")
@@ -260,6 +270,9 @@ func (s *SQLCommon) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID
 		if err != nil {
 			return err
 		}
+		if err = s.deleteAnnotations(ctx, tx, id); err != nil {
+			return err
+		}
 	}
 
 	return s.commitTx(ctx, tx, autoCommit)
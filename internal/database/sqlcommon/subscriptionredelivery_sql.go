@@ -0,0 +1,187 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	subscriptionRedeliveryColumns = []string{
+		"subscription_id",
+		"namespace",
+		"event_id",
+		"event_sequence",
+		"attempts",
+		"parked",
+		"updated",
+	}
+	subscriptionRedeliveryFilterFieldMap = map[string]string{
+		"subscription": "subscription_id",
+		"event":        "event_id",
+	}
+)
+
+func (s *SQLCommon) UpsertSubscriptionRedelivery(ctx context.Context, redelivery *fftypes.SubscriptionRedelivery) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the event already has a record
+	redeliveryRows, _, err := s.queryTx(ctx, tx,
+		sq.Select(sequenceColumn).
+			From("subscriptionredeliveries").
+			Where(sq.Eq{
+				"subscription_id": redelivery.Subscription,
+				"event_id":        redelivery.Event,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := redeliveryRows.Next()
+	redeliveryRows.Close()
+
+	redelivery.Updated = fftypes.Now()
+	if existing {
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("subscriptionredeliveries").
+				Set("attempts", redelivery.Attempts).
+				Set("parked", redelivery.Parked).
+				Set("updated", redelivery.Updated).
+				Where(sq.Eq{
+					"subscription_id": redelivery.Subscription,
+					"event_id":        redelivery.Event,
+				}),
+			nil, // no change events for subscription redeliveries
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("subscriptionredeliveries").
+				Columns(subscriptionRedeliveryColumns...).
+				Values(
+					redelivery.Subscription,
+					redelivery.Namespace,
+					redelivery.Event,
+					redelivery.Sequence,
+					redelivery.Attempts,
+					redelivery.Parked,
+					redelivery.Updated,
+				),
+			nil, // no change events for subscription redeliveries
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) subscriptionRedeliveryResult(ctx context.Context, row *sql.Rows) (*fftypes.SubscriptionRedelivery, error) {
+	redelivery := fftypes.SubscriptionRedelivery{}
+	err := row.Scan(
+		&redelivery.Subscription,
+		&redelivery.Namespace,
+		&redelivery.Event,
+		&redelivery.Sequence,
+		&redelivery.Attempts,
+		&redelivery.Parked,
+		&redelivery.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "subscriptionredeliveries")
+	}
+	return &redelivery, nil
+}
+
+func (s *SQLCommon) GetSubscriptionRedelivery(ctx context.Context, subscription, event *fftypes.UUID) (redelivery *fftypes.SubscriptionRedelivery, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(subscriptionRedeliveryColumns...).
+			From("subscriptionredeliveries").
+			Where(sq.Eq{
+				"subscription_id": subscription,
+				"event_id":        event,
+			}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Redelivery of event '%s' on subscription '%s' not found", event, subscription)
+		return nil, nil
+	}
+
+	return s.subscriptionRedeliveryResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetSubscriptionRedeliveries(ctx context.Context, filter database.Filter) (redeliveries []*fftypes.SubscriptionRedelivery, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(subscriptionRedeliveryColumns...).From("subscriptionredeliveries"), filter, subscriptionRedeliveryFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	redeliveries = []*fftypes.SubscriptionRedelivery{}
+	for rows.Next() {
+		redelivery, err := s.subscriptionRedeliveryResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		redeliveries = append(redeliveries, redelivery)
+	}
+
+	return redeliveries, s.queryRes(ctx, tx, "subscriptionredeliveries", fop, fi), err
+
+}
+
+func (s *SQLCommon) DeleteSubscriptionRedelivery(ctx context.Context, subscription, event *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("subscriptionredeliveries").Where(sq.Eq{
+		"subscription_id": subscription,
+		"event_id":        event,
+	}), nil /* no change events for subscription redeliveries */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
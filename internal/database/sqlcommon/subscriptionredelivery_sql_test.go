@@ -0,0 +1,185 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionRedeliveryE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Record the first rejection of an event
+	redelivery := &fftypes.SubscriptionRedelivery{
+		Subscription: fftypes.NewUUID(),
+		Namespace:    "ns1",
+		Event:        fftypes.NewUUID(),
+		Sequence:     12345,
+		Attempts:     1,
+	}
+	err := s.UpsertSubscriptionRedelivery(ctx, redelivery)
+	assert.NoError(t, err)
+	assert.NotNil(t, redelivery.Updated)
+
+	redeliveryRead, err := s.GetSubscriptionRedelivery(ctx, redelivery.Subscription, redelivery.Event)
+	assert.NoError(t, err)
+	redeliveryJson, _ := json.Marshal(&redelivery)
+	redeliveryReadJson, _ := json.Marshal(&redeliveryRead)
+	assert.Equal(t, string(redeliveryJson), string(redeliveryReadJson))
+
+	// Park it after another attempt
+	redelivery.Attempts = 2
+	redelivery.Parked = true
+	err = s.UpsertSubscriptionRedelivery(ctx, redelivery)
+	assert.NoError(t, err)
+
+	redeliveryRead, err = s.GetSubscriptionRedelivery(ctx, redelivery.Subscription, redelivery.Event)
+	assert.NoError(t, err)
+	redeliveryJson, _ = json.Marshal(&redelivery)
+	redeliveryReadJson, _ = json.Marshal(&redeliveryRead)
+	assert.Equal(t, string(redeliveryJson), string(redeliveryReadJson))
+
+	// Query it back
+	fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("subscription", redelivery.Subscription),
+		fb.Eq("parked", true),
+		fb.Eq("attempts", 2),
+	)
+	redeliveries, res, err := s.GetSubscriptionRedeliveries(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, redeliveries, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	redeliveryReadJson, _ = json.Marshal(redeliveries[0])
+	assert.Equal(t, string(redeliveryJson), string(redeliveryReadJson))
+
+	// Delete it
+	err = s.DeleteSubscriptionRedelivery(ctx, redelivery.Subscription, redelivery.Event)
+	assert.NoError(t, err)
+	redeliveryRead, err = s.GetSubscriptionRedelivery(ctx, redelivery.Subscription, redelivery.Event)
+	assert.NoError(t, err)
+	assert.Nil(t, redeliveryRead)
+}
+
+func TestUpsertSubscriptionRedeliveryFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertSubscriptionRedelivery(context.Background(), &fftypes.SubscriptionRedelivery{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSubscriptionRedeliveryFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSubscriptionRedelivery(context.Background(), &fftypes.SubscriptionRedelivery{})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSubscriptionRedeliveryFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSubscriptionRedelivery(context.Background(), &fftypes.SubscriptionRedelivery{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSubscriptionRedeliveryFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(int64(12345)))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSubscriptionRedelivery(context.Background(), &fftypes.SubscriptionRedelivery{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSubscriptionRedeliverySelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetSubscriptionRedelivery(context.Background(), fftypes.NewUUID(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSubscriptionRedeliveryScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"subscription_id"}).AddRow("only one"))
+	_, err := s.GetSubscriptionRedelivery(context.Background(), fftypes.NewUUID(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSubscriptionRedeliveriesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background()).Eq("subscription", map[bool]bool{true: false})
+	_, _, err := s.GetSubscriptionRedeliveries(context.Background(), f)
+	assert.Regexp(t, "FF10149.*subscription", err)
+}
+
+func TestGetSubscriptionRedeliveriesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background()).Eq("parked", true)
+	_, _, err := s.GetSubscriptionRedeliveries(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSubscriptionRedeliveriesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"subscription_id"}).AddRow("only one"))
+	f := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background()).Eq("parked", true)
+	_, _, err := s.GetSubscriptionRedeliveries(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSubscriptionRedeliveryFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteSubscriptionRedelivery(context.Background(), fftypes.NewUUID(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteSubscriptionRedeliveryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteSubscriptionRedelivery(context.Background(), fftypes.NewUUID(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
@@ -0,0 +1,133 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	syncChangeColumns = []string{
+		"namespace",
+		"ctype",
+		"ref_id",
+		"created",
+	}
+	syncChangeFilterFieldMap = map[string]string{
+		"type":      "ctype",
+		"reference": "ref_id",
+		"changed":   "created",
+	}
+)
+
+// syncChange is a change to an object that is recorded in the change log when the transaction commits
+type syncChange struct {
+	changeType fftypes.SyncChangeType
+	table      string
+	id         *fftypes.UUID
+}
+
+// addPreCommitSyncChange records a change to an object, to be written to the change log at the end of the
+// transaction. The namespace is read from the object itself, as an update might not have it to hand.
+func (s *SQLCommon) addPreCommitSyncChange(tx *txWrapper, changeType fftypes.SyncChangeType, table string, id *fftypes.UUID) {
+	for _, change := range tx.preCommitSyncChanges {
+		if change.changeType == changeType && change.id.Equals(id) {
+			return
+		}
+	}
+	tx.preCommitSyncChanges = append(tx.preCommitSyncChanges, &syncChange{
+		changeType: changeType,
+		table:      table,
+		id:         id,
+	})
+}
+
+func (s *SQLCommon) insertSyncChangesPreCommit(ctx context.Context, tx *txWrapper, changes []*syncChange) (err error) {
+
+	// As with events, we take a full table lock so the sequence of the change log always increases in the
+	// order that the changes are committed - so a reader never skips a change by passing its sequence
+	if err = s.lockTableExclusiveTx(ctx, tx, "syncchanges"); err != nil {
+		return err
+	}
+
+	now := fftypes.Now()
+	for _, change := range changes {
+		_, err = s.insertTx(ctx, tx,
+			sq.Insert("syncchanges").
+				Columns(syncChangeColumns...).
+				Select(sq.Select("namespace").
+					Column("? AS ctype", change.changeType).
+					Column("id").
+					Column("? AS created", now).
+					From(change.table).
+					Where(sq.Eq{"id": change.id})),
+			nil, // no change event
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) syncChangeResult(ctx context.Context, row *sql.Rows) (*fftypes.SyncChangeRef, error) {
+	var change fftypes.SyncChangeRef
+	err := row.Scan(
+		&change.Namespace,
+		&change.Type,
+		&change.Reference,
+		&change.Changed,
+		&change.Sequence,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "syncchanges")
+	}
+	return &change, nil
+}
+
+func (s *SQLCommon) GetSyncChanges(ctx context.Context, filter database.Filter) ([]*fftypes.SyncChangeRef, *database.FilterResult, error) {
+
+	cols := append([]string{}, syncChangeColumns...)
+	cols = append(cols, sequenceColumn)
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(cols...).From("syncchanges"), filter, syncChangeFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	changes := []*fftypes.SyncChangeRef{}
+	for rows.Next() {
+		change, err := s.syncChangeResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, s.queryRes(ctx, tx, "syncchanges", fop, fi), err
+}
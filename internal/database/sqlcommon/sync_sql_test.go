@@ -0,0 +1,143 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSyncChangesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	tx := &fftypes.Transaction{ID: fftypes.NewUUID(), Namespace: "ns1", Type: fftypes.TransactionTypeBatchPin}
+	err := s.InsertTransaction(ctx, tx)
+	assert.NoError(t, err)
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: tx.ID, Type: fftypes.OpTypeBlockchainPinBatch, Status: fftypes.OpStatusPending, Created: fftypes.Now()}
+	err = s.InsertOperation(ctx, op)
+	assert.NoError(t, err)
+	event := &fftypes.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Type: fftypes.EventTypeTransactionSubmitted, Created: fftypes.Now()}
+	err = s.InsertEvent(ctx, event)
+	assert.NoError(t, err)
+	other := &fftypes.Transaction{ID: fftypes.NewUUID(), Namespace: "ns2", Type: fftypes.TransactionTypeBatchPin}
+	err = s.InsertTransaction(ctx, other)
+	assert.NoError(t, err)
+
+	// Updating the operation twice in one transaction records one change
+	err = s.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := s.ResolveOperation(ctx, op.ID, fftypes.OpStatusSucceeded, "", nil); err != nil {
+			return err
+		}
+		return s.UpdateOperation(ctx, op.ID, database.OperationQueryFactory.NewUpdate(ctx).Set("output", fftypes.JSONObject{"done": true}))
+	})
+	assert.NoError(t, err)
+
+	// Updating an operation that does not exist records nothing
+	err = s.UpdateOperation(ctx, fftypes.NewUUID(), database.OperationQueryFactory.NewUpdate(ctx).Set("error", "pop"))
+	assert.NoError(t, err)
+	err = s.UpdateTransaction(ctx, tx.ID, database.TransactionQueryFactory.NewUpdate(ctx).Set("blockchainids", fftypes.FFStringArray{"0x12345"}))
+	assert.NoError(t, err)
+
+	fb := database.SyncChangeQueryFactory.NewFilter(ctx)
+	changes, _, err := s.GetSyncChanges(ctx, fb.Eq("namespace", "ns1").Sort("sequence"))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 5)
+	expected := []struct {
+		changeType fftypes.SyncChangeType
+		id         *fftypes.UUID
+	}{
+		{fftypes.SyncChangeTypeTransaction, tx.ID},
+		{fftypes.SyncChangeTypeOperation, op.ID},
+		{fftypes.SyncChangeTypeEvent, event.ID},
+		{fftypes.SyncChangeTypeOperation, op.ID},
+		{fftypes.SyncChangeTypeTransaction, tx.ID},
+	}
+	for i, change := range changes {
+		assert.Equal(t, expected[i].changeType, change.Type)
+		assert.Equal(t, expected[i].id, change.Reference)
+		assert.Equal(t, "ns1", change.Namespace)
+		assert.NotNil(t, change.Changed)
+		if i > 0 {
+			assert.Greater(t, change.Sequence, changes[i-1].Sequence)
+		}
+	}
+
+	changes, _, err = s.GetSyncChanges(ctx, fb.And(fb.Eq("namespace", "ns1"), fb.Gt("sequence", changes[3].Sequence)))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestInsertSyncChangesPreCommitLockFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("LOCK .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertTransaction(context.Background(), &fftypes.Transaction{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10345", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertSyncChangesPreCommitInsertFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("LOCK .*").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT INTO syncchanges .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertTransaction(context.Background(), &fftypes.Transaction{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSyncChangesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.SyncChangeQueryFactory.NewFilter(context.Background()).Eq("namespace", map[bool]bool{true: false})
+	_, _, err := s.GetSyncChanges(context.Background(), f)
+	assert.Regexp(t, "FF10149.*namespace", err)
+}
+
+func TestGetSyncChangesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.SyncChangeQueryFactory.NewFilter(context.Background()).Eq("namespace", "ns1")
+	_, _, err := s.GetSyncChanges(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSyncChangesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("ns1"))
+	f := database.SyncChangeQueryFactory.NewFilter(context.Background()).Eq("namespace", "ns1")
+	_, _, err := s.GetSyncChanges(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
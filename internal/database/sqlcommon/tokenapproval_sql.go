@@ -94,7 +94,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *fftypes.T
 				Where(sq.Eq{"protocol_id": approval.ProtocolID}).
 				Where(sq.Eq{"pool_id": approval.Pool}),
 			func() {
-				s.callbacks.UUIDCollectionEvent(database.CollectionTokenApprovals, fftypes.ChangeEventTypeUpdated, approval.LocalID)
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenApprovals, fftypes.ChangeEventTypeUpdated, approval.Namespace, approval.LocalID)
 			},
 		); err != nil {
 			return err
@@ -120,7 +120,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *fftypes.T
 					approval.Created,
 				),
 			func() {
-				s.callbacks.UUIDCollectionEvent(database.CollectionTokenApprovals, fftypes.ChangeEventTypeCreated, approval.LocalID)
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenApprovals, fftypes.ChangeEventTypeCreated, approval.Namespace, approval.LocalID)
 			},
 		); err != nil {
 			return err
@@ -50,9 +50,9 @@ func TestApprovalE2EWithDB(t *testing.T) {
 		BlockchainEvent: fftypes.NewUUID(),
 	}
 
-	s.callbacks.On("UUIDCollectionEvent", database.CollectionTokenApprovals, fftypes.ChangeEventTypeCreated, approval.LocalID, mock.Anything).
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenApprovals, fftypes.ChangeEventTypeCreated, "ns1", approval.LocalID, mock.Anything).
 		Return().Once()
-	s.callbacks.On("UUIDCollectionEvent", database.CollectionTokenApprovals, fftypes.ChangeEventTypeUpdated, approval.LocalID, mock.Anything).
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenApprovals, fftypes.ChangeEventTypeUpdated, "ns1", approval.LocalID, mock.Anything).
 		Return().Once()
 
 	err := s.UpsertTokenApproval(ctx, approval)
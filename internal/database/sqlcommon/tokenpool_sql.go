@@ -43,12 +43,16 @@ var (
 		"tx_type",
 		"tx_id",
 		"info",
+		"backfill",
+		"ingest_filter",
+		"annotations",
 	}
 	tokenPoolFilterFieldMap = map[string]string{
-		"protocolid": "protocol_id",
-		"message":    "message_id",
-		"tx.type":    "tx_type",
-		"tx.id":      "tx_id",
+		"protocolid":    "protocol_id",
+		"message":       "message_id",
+		"tx.type":       "tx_type",
+		"tx.id":         "tx_id",
+		"annotations.*": "annotations",
 	}
 )
 
@@ -95,6 +99,9 @@ func (s *SQLCommon) UpsertTokenPool(ctx context.Context, pool *fftypes.TokenPool
 				Set("tx_type", pool.TX.Type).
 				Set("tx_id", pool.TX.ID).
 				Set("info", pool.Info).
+				Set("backfill", pool.Backfill).
+				Set("ingest_filter", pool.IngestFilter).
+				Set("annotations", pool.Annotations).
 				Where(sq.Eq{"id": pool.ID}),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenPools, fftypes.ChangeEventTypeUpdated, pool.Namespace, pool.ID)
@@ -122,6 +129,9 @@ func (s *SQLCommon) UpsertTokenPool(ctx context.Context, pool *fftypes.TokenPool
 					pool.TX.Type,
 					pool.TX.ID,
 					pool.Info,
+					pool.Backfill,
+					pool.IngestFilter,
+					pool.Annotations,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenPools, fftypes.ChangeEventTypeCreated, pool.Namespace, pool.ID)
@@ -131,6 +141,10 @@ func (s *SQLCommon) UpsertTokenPool(ctx context.Context, pool *fftypes.TokenPool
 		}
 	}
 
+	if err = s.updateAnnotations(ctx, tx, pool.ID, pool.Namespace, pool.Annotations, existing); err != nil {
+		return err
+	}
+
 	return s.commitTx(ctx, tx, autoCommit)
 }
 
@@ -151,6 +165,9 @@ func (s *SQLCommon) tokenPoolResult(ctx context.Context, row *sql.Rows) (*fftype
 		&pool.TX.Type,
 		&pool.TX.ID,
 		&pool.Info,
+		&pool.Backfill,
+		&pool.IngestFilter,
+		&pool.Annotations,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "tokenpool")
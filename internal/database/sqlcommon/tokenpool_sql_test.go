@@ -55,6 +55,17 @@ func TestTokenPoolE2EWithDB(t *testing.T) {
 		Info: fftypes.JSONObject{
 			"pool": "info",
 		},
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "100",
+			State:     fftypes.TokenPoolBackfillStatePending,
+		},
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			Members:   true,
+			MinAmount: fftypes.NewFFBigInt(1000),
+		},
+		Annotations: fftypes.Annotations{
+			"owner": "team-a",
+		},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenPools, fftypes.ChangeEventTypeCreated, "ns1", poolID, mock.Anything).
@@ -98,6 +109,7 @@ func TestTokenPoolE2EWithDB(t *testing.T) {
 		fb.Eq("protocolid", pool.ProtocolID),
 		fb.Eq("message", pool.Message),
 		fb.Eq("created", pool.Created),
+		fb.Eq("annotations.owner", "team-a"),
 	)
 	pools, res, err := s.GetTokenPools(ctx, filter.Count(true))
 	assert.NoError(t, err)
@@ -109,6 +121,9 @@ func TestTokenPoolE2EWithDB(t *testing.T) {
 	// Update the token pool
 	pool.ProtocolID = "67890"
 	pool.Type = fftypes.TokenTypeNonFungible
+	pool.Backfill.State = fftypes.TokenPoolBackfillStateRunning
+	pool.Backfill.Block = "150"
+	pool.Backfill.Transfers = 10
 	err = s.UpsertTokenPool(ctx, pool)
 	assert.NoError(t, err)
 
@@ -160,6 +175,18 @@ func TestUpsertTokenPoolFailUpdate(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertTokenPoolFailAnnotations(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertTokenPool(context.Background(), &fftypes.TokenPool{})
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertTokenPoolFailCommit(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
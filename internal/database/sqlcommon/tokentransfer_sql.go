@@ -47,6 +47,7 @@ var (
 		"tx_id",
 		"blockchain_event",
 		"created",
+		"business_key",
 	}
 	tokenTransferFilterFieldMap = map[string]string{
 		"type":            "type",
@@ -61,6 +62,7 @@ var (
 		"tx.type":         "tx_type",
 		"tx.id":           "tx_id",
 		"blockchainevent": "blockchain_event",
+		"businesskey":     "business_key",
 	}
 )
 
@@ -101,9 +103,10 @@ func (s *SQLCommon) UpsertTokenTransfer(ctx context.Context, transfer *fftypes.T
 				Set("tx_type", transfer.TX.Type).
 				Set("tx_id", transfer.TX.ID).
 				Set("blockchain_event", transfer.BlockchainEvent).
+				Set("business_key", transfer.BusinessKey).
 				Where(sq.Eq{"protocol_id": transfer.ProtocolID}),
 			func() {
-				s.callbacks.UUIDCollectionEvent(database.CollectionTokenTransfers, fftypes.ChangeEventTypeUpdated, transfer.LocalID)
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenTransfers, fftypes.ChangeEventTypeUpdated, transfer.Namespace, transfer.LocalID)
 			},
 		); err != nil {
 			return err
@@ -132,9 +135,10 @@ func (s *SQLCommon) UpsertTokenTransfer(ctx context.Context, transfer *fftypes.T
 					transfer.TX.ID,
 					transfer.BlockchainEvent,
 					transfer.Created,
+					transfer.BusinessKey,
 				),
 			func() {
-				s.callbacks.UUIDCollectionEvent(database.CollectionTokenTransfers, fftypes.ChangeEventTypeCreated, transfer.LocalID)
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenTransfers, fftypes.ChangeEventTypeCreated, transfer.Namespace, transfer.LocalID)
 			},
 		); err != nil {
 			return err
@@ -165,6 +169,7 @@ func (s *SQLCommon) tokenTransferResult(ctx context.Context, row *sql.Rows) (*ff
 		&transfer.TX.ID,
 		&transfer.BlockchainEvent,
 		&transfer.Created,
+		&transfer.BusinessKey,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "tokentransfer")
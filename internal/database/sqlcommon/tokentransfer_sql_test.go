@@ -53,12 +53,13 @@ func TestTokenTransferE2EWithDB(t *testing.T) {
 			ID:   fftypes.NewUUID(),
 		},
 		BlockchainEvent: fftypes.NewUUID(),
+		BusinessKey:     "order-1",
 	}
 	transfer.Amount.Int().SetInt64(10)
 
-	s.callbacks.On("UUIDCollectionEvent", database.CollectionTokenTransfers, fftypes.ChangeEventTypeCreated, transfer.LocalID, mock.Anything).
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenTransfers, fftypes.ChangeEventTypeCreated, "ns1", transfer.LocalID, mock.Anything).
 		Return().Once()
-	s.callbacks.On("UUIDCollectionEvent", database.CollectionTokenTransfers, fftypes.ChangeEventTypeUpdated, transfer.LocalID, mock.Anything).
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenTransfers, fftypes.ChangeEventTypeUpdated, "ns1", transfer.LocalID, mock.Anything).
 		Return().Once()
 
 	err := s.UpsertTokenTransfer(ctx, transfer)
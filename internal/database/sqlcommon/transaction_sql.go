@@ -33,11 +33,17 @@ var (
 		"ttype",
 		"namespace",
 		"created",
+		"updated",
 		"blockchain_ids",
+		"fee",
+		"correlation_id",
+		"chain_id",
 	}
 	transactionFilterFieldMap = map[string]string{
 		"type":          "ttype",
 		"blockchainids": "blockchain_ids",
+		"correlationid": "correlation_id",
+		"chainid":       "chain_id",
 	}
 )
 
@@ -49,6 +55,10 @@ func (s *SQLCommon) InsertTransaction(ctx context.Context, transaction *fftypes.
 	defer s.rollbackTx(ctx, tx, autoCommit)
 
 	transaction.Created = fftypes.Now()
+	transaction.Updated = transaction.Created
+	if transaction.CorrelationID == "" {
+		transaction.CorrelationID = log.CorrelationID(ctx)
+	}
 	if _, err = s.insertTx(ctx, tx,
 		sq.Insert("transactions").
 			Columns(transactionColumns...).
@@ -57,7 +67,11 @@ func (s *SQLCommon) InsertTransaction(ctx context.Context, transaction *fftypes.
 				string(transaction.Type),
 				transaction.Namespace,
 				transaction.Created,
+				transaction.Updated,
 				transaction.BlockchainIDs,
+				transaction.Fee,
+				transaction.CorrelationID,
+				transaction.ChainID,
 			),
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionTransactions, fftypes.ChangeEventTypeCreated, transaction.Namespace, transaction.ID)
@@ -65,6 +79,7 @@ func (s *SQLCommon) InsertTransaction(ctx context.Context, transaction *fftypes.
 	); err != nil {
 		return err
 	}
+	s.addPreCommitSyncChange(tx, fftypes.SyncChangeTypeTransaction, "transactions", transaction.ID)
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
@@ -76,7 +91,11 @@ func (s *SQLCommon) transactionResult(ctx context.Context, row *sql.Rows) (*ffty
 		&transaction.Type,
 		&transaction.Namespace,
 		&transaction.Created,
+		&transaction.Updated,
 		&transaction.BlockchainIDs,
+		&transaction.Fee,
+		&transaction.CorrelationID,
+		&transaction.ChainID,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "transactions")
@@ -147,12 +166,16 @@ func (s *SQLCommon) UpdateTransaction(ctx context.Context, id *fftypes.UUID, upd
 	if err != nil {
 		return err
 	}
+	query = query.Set("updated", fftypes.Now())
 	query = query.Where(sq.Eq{"id": id})
 
-	_, err = s.updateTx(ctx, tx, query, nil /* no change evnents for filter based updates */)
+	updated, err := s.updateTx(ctx, tx, query, nil /* no change evnents for filter based updates */)
 	if err != nil {
 		return err
 	}
+	if updated > 0 {
+		s.addPreCommitSyncChange(tx, fftypes.SyncChangeTypeTransaction, "transactions", id)
+	}
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
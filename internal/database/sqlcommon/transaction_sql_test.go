@@ -18,11 +18,13 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -33,7 +35,7 @@ func TestTransactionE2EWithDB(t *testing.T) {
 
 	s, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
-	ctx := context.Background()
+	ctx := log.WithCorrelationID(context.Background(), "corr1")
 
 	// Create a new transaction entry
 	transactionID := fftypes.NewUUID()
@@ -42,6 +44,7 @@ func TestTransactionE2EWithDB(t *testing.T) {
 		Type:          fftypes.TransactionTypeBatchPin,
 		Namespace:     "ns1",
 		BlockchainIDs: fftypes.FFStringArray{"tx1"},
+		ChainID:       1337,
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTransactions, fftypes.ChangeEventTypeCreated, "ns1", transactionID, mock.Anything).Return()
@@ -62,6 +65,8 @@ func TestTransactionE2EWithDB(t *testing.T) {
 	fb := database.TransactionQueryFactory.NewFilter(ctx)
 	filter := fb.And(
 		fb.Eq("id", transaction.ID.String()),
+		fb.Eq("correlationid", "corr1"),
+		fb.Eq("chainid", 1337),
 		fb.Gt("created", "0"),
 	)
 	transactions, res, err := s.GetTransactions(ctx, filter.Count(true))
@@ -81,8 +86,10 @@ func TestTransactionE2EWithDB(t *testing.T) {
 	assert.Equal(t, 0, len(transactions))
 
 	// Update
+	fee := &fftypes.TransactionFee{GasUsed: fftypes.NewFFBigInt(21000), Total: fftypes.NewFFBigInt(42000)}
 	up := database.TransactionQueryFactory.NewUpdate(ctx).
-		Set("blockchainids", fftypes.FFStringArray{"0x12345", "0x23456"})
+		Set("blockchainids", fftypes.FFStringArray{"0x12345", "0x23456"}).
+		Set("fee", fee)
 	err = s.UpdateTransaction(ctx, transaction.ID, up)
 	assert.NoError(t, err)
 
@@ -90,10 +97,13 @@ func TestTransactionE2EWithDB(t *testing.T) {
 	filter = fb.And(
 		fb.Eq("id", transaction.ID.String()),
 		fb.Eq("blockchainids", "0x12345,0x23456"),
+		fb.Gt("updated", transaction.Created),
 	)
 	transactions, _, err = s.GetTransactions(ctx, filter)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(transactions))
+	assert.Equal(t, transaction.Created.String(), transactions[0].Created.String())
+	assert.Equal(t, int64(42000), transactions[0].Fee.Total.Int().Int64())
 }
 
 func TestInsertTransactionFailBegin(t *testing.T) {
@@ -120,6 +130,8 @@ func TestInsertTransactionFailCommit(t *testing.T) {
 	transactionID := fftypes.NewUUID()
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("LOCK .*").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT INTO syncchanges .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
 	err := s.InsertTransaction(context.Background(), &fftypes.Transaction{ID: transactionID})
 	assert.Regexp(t, "FF10119", err)
@@ -34,6 +34,7 @@ var (
 		"vtype",
 		"namespace",
 		"value",
+		"pinning",
 		"created",
 	}
 	verifierFilterFieldMap = map[string]string{
@@ -48,6 +49,7 @@ func (s *SQLCommon) attemptVerifierUpdate(ctx context.Context, tx *txWrapper, ve
 			Set("vtype", verifier.Type).
 			Set("namespace", verifier.Namespace).
 			Set("value", verifier.Value).
+			Set("pinning", verifier.Pinning).
 			Where(sq.Eq{
 				"hash": verifier.Hash,
 			}),
@@ -67,6 +69,7 @@ func (s *SQLCommon) attemptVerifierInsert(ctx context.Context, tx *txWrapper, ve
 				verifier.Type,
 				verifier.Namespace,
 				verifier.Value,
+				verifier.Pinning,
 				verifier.Created,
 			),
 		func() {
@@ -127,6 +130,7 @@ func (s *SQLCommon) verifierResult(ctx context.Context, row *sql.Rows) (*fftypes
 		&verifier.Type,
 		&verifier.Namespace,
 		&verifier.Value,
+		&verifier.Pinning,
 		&verifier.Created,
 	)
 	if err != nil {
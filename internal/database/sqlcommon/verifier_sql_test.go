@@ -71,6 +71,7 @@ func TestVerifiersE2EWithDB(t *testing.T) {
 			Type:  fftypes.VerifierTypeEthAddress,
 			Value: "0x12345",
 		},
+		Pinning: true,
 	}
 	verifierUpdated.Seal()
 	err = s.UpsertVerifier(context.Background(), verifierUpdated, database.UpsertOptimizationExisting)
@@ -88,6 +89,7 @@ func TestVerifiersE2EWithDB(t *testing.T) {
 	filter := fb.And(
 		fb.Eq("value", string(verifierUpdated.Value)),
 		fb.Eq("namespace", verifierUpdated.Namespace),
+		fb.Eq("pinning", true),
 	)
 	verifierRes, res, err := s.GetVerifiers(ctx, filter.Count(true))
 	assert.NoError(t, err)
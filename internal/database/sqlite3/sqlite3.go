@@ -70,6 +70,7 @@ func (sqlite *SQLite3) Features() sqlcommon.SQLFeatures {
 	features := sqlcommon.DefaultSQLProviderFeatures()
 	features.PlaceholderFormat = sq.Dollar
 	features.UseILIKE = false // Not supported
+	features.FullTextSearch = sqlcommon.SQLiteFullTextSearch
 	return features
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/dataexchange/ffdx"
+	"github.com/hyperledger/firefly/internal/dataexchange/loopback"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 )
@@ -31,7 +32,8 @@ var (
 )
 
 var pluginsByName = map[string]func() dataexchange.Plugin{
-	NewFFDXPluginName: func() dataexchange.Plugin { return &ffdx.FFDX{} },
+	NewFFDXPluginName:                func() dataexchange.Plugin { return &ffdx.FFDX{} },
+	(*loopback.Loopback)(nil).Name(): func() dataexchange.Plugin { return &loopback.Loopback{} },
 }
 
 func InitPrefix(prefix config.Prefix) {
@@ -26,10 +26,19 @@ const (
 	DataExchangeManifestEnabled = "manifestEnabled"
 	// DataExchangeInitEnabled instructs FireFly to always post all current nodes to the /init API before connecting or reconnecting to the connector
 	DataExchangeInitEnabled = "initEnabled"
+	// DataExchangeTransferMaxConcurrentPerPeer limits how many BLOB transfers are handed to the connector at once for each peer - further transfers are queued (0 is unlimited)
+	DataExchangeTransferMaxConcurrentPerPeer = "transfers.maxConcurrentPerPeer"
+	// DataExchangeTransformCompression compresses BLOBs before they are transferred to peers that support it (empty, or "gzip")
+	DataExchangeTransformCompression = "transforms.compression"
+	// DataExchangeTransformEncryptionKey is a hex encoded X25519 private key, used to encrypt BLOBs for each recipient that publishes a key
+	DataExchangeTransformEncryptionKey = "transforms.encryptionKey"
 )
 
 func (h *FFDX) InitPrefix(prefix config.Prefix) {
 	wsconfig.InitPrefix(prefix)
 	prefix.AddKnownKey(DataExchangeManifestEnabled, false)
 	prefix.AddKnownKey(DataExchangeInitEnabled, false)
+	prefix.AddKnownKey(DataExchangeTransferMaxConcurrentPerPeer, 0)
+	prefix.AddKnownKey(DataExchangeTransformCompression, "")
+	prefix.AddKnownKey(DataExchangeTransformEncryptionKey, "")
 }
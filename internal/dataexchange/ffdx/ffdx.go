@@ -46,6 +46,8 @@ type FFDX struct {
 	initialized  bool
 	initMutex    sync.Mutex
 	nodes        []fftypes.JSONObject
+	transfers    *blobTransfers
+	transformer  *blobTransformer
 }
 
 type wsEvent struct {
@@ -57,9 +59,11 @@ type wsEvent struct {
 	Message   string             `json:"message"`
 	Hash      string             `json:"hash"`
 	Size      int64              `json:"size"`
+	Progress  int64              `json:"progress"`
 	Error     string             `json:"error"`
 	Manifest  string             `json:"manifest"`
 	Info      fftypes.JSONObject `json:"info"`
+	Metadata  *transferMetadata  `json:"metadata"`
 }
 
 const (
@@ -78,6 +82,7 @@ const (
 	blobDelivered       msgType = "blob-delivered"
 	blobAcknowledged    msgType = "blob-acknowledged"
 	blobFailed          msgType = "blob-failed"
+	blobProgress        msgType = "blob-progress"
 )
 
 type responseWithRequestID struct {
@@ -97,9 +102,11 @@ type sendMessage struct {
 }
 
 type transferBlob struct {
-	Path      string `json:"path"`
-	Recipient string `json:"recipient"`
-	RequestID string `json:"requestId"`
+	Path      string            `json:"path"`
+	Recipient string            `json:"recipient"`
+	RequestID string            `json:"requestId"`
+	Resume    bool              `json:"resume,omitempty"`
+	Metadata  *transferMetadata `json:"metadata,omitempty"`
 }
 
 type wsAck struct {
@@ -125,7 +132,14 @@ func (h *FFDX) Init(ctx context.Context, prefix config.Prefix, nodes []fftypes.J
 		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "dataexchange.ffdx")
 	}
 
+	h.transformer, err = newBlobTransformer(ctx, prefix.GetString(DataExchangeTransformCompression), prefix.GetString(DataExchangeTransformEncryptionKey))
+	if err != nil {
+		return err
+	}
 	h.nodes = nodes
+	for _, node := range nodes {
+		h.transformer.addPeer(ctx, node)
+	}
 
 	h.client = restclient.New(h.ctx, prefix)
 	h.capabilities = &dataexchange.Capabilities{
@@ -134,10 +148,13 @@ func (h *FFDX) Init(ctx context.Context, prefix config.Prefix, nodes []fftypes.J
 
 	wsConfig := wsconfig.GenerateConfigFromPrefix(prefix)
 
+	h.transfers = newBlobTransfers(prefix.GetInt(DataExchangeTransferMaxConcurrentPerPeer))
+
 	h.wsconn, err = wsclient.New(ctx, wsConfig, h.beforeConnect, nil)
 	if err != nil {
 		return err
 	}
+	h.wsconn.AddAfterReconnectHook(h.resumeTransfers)
 	go h.eventLoop()
 	return nil
 }
@@ -150,6 +167,13 @@ func (h *FFDX) Capabilities() *dataexchange.Capabilities {
 	return h.capabilities
 }
 
+func (h *FFDX) Health(ctx context.Context) error {
+	if state := h.wsconn.State(); state != wsclient.WSStateConnected {
+		return i18n.NewError(ctx, i18n.MsgPluginNotConnected, h.Name(), state)
+	}
+	return nil
+}
+
 func (h *FFDX) beforeConnect(ctx context.Context) error {
 	h.initMutex.Lock()
 	defer h.initMutex.Unlock()
@@ -198,6 +222,7 @@ func (h *FFDX) GetEndpointInfo(ctx context.Context) (peer fftypes.JSONObject, er
 		log.L(ctx).Errorf("Invalid DX info: %s", peer.String())
 		return nil, i18n.NewError(ctx, i18n.MsgDXInfoMissingID)
 	}
+	h.transformer.advertise(peer)
 	h.nodes = append(h.nodes, peer)
 	return peer, nil
 }
@@ -213,24 +238,31 @@ func (h *FFDX) AddPeer(ctx context.Context, peer fftypes.JSONObject) (err error)
 	if err != nil || !res.IsSuccess() {
 		return restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
 	}
+	h.transformer.addPeer(ctx, peer)
 	return nil
 }
 
 func (h *FFDX) UploadBLOB(ctx context.Context, ns string, id fftypes.UUID, content io.Reader) (payloadRef string, hash *fftypes.Bytes32, size int64, err error) {
 	payloadRef = fmt.Sprintf("%s/%s", ns, &id)
+	if hash, size, err = h.putBLOB(ctx, payloadRef, id.String(), content); err != nil {
+		return "", nil, -1, err
+	}
+	return payloadRef, hash, size, nil
+}
+
+func (h *FFDX) putBLOB(ctx context.Context, payloadRef, name string, content io.Reader) (hash *fftypes.Bytes32, size int64, err error) {
 	var upload uploadBlob
 	res, err := h.client.R().SetContext(ctx).
-		SetFileReader("file", id.String(), content).
+		SetFileReader("file", name, content).
 		SetResult(&upload).
 		Put(fmt.Sprintf("/api/v1/blobs/%s", payloadRef))
 	if err != nil || !res.IsSuccess() {
-		err = restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
-		return "", nil, -1, err
+		return nil, -1, restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
 	}
 	if hash, err = fftypes.ParseBytes32(ctx, upload.Hash); err != nil {
-		return "", nil, -1, i18n.WrapError(ctx, err, i18n.MsgDXBadResponse, "hash", upload.Hash)
+		return nil, -1, i18n.WrapError(ctx, err, i18n.MsgDXBadResponse, "hash", upload.Hash)
 	}
-	return payloadRef, hash, upload.Size, nil
+	return hash, upload.Size, nil
 }
 
 func (h *FFDX) DownloadBLOB(ctx context.Context, payloadRef string) (content io.ReadCloser, err error) {
@@ -271,17 +303,24 @@ func (h *FFDX) TransferBLOB(ctx context.Context, opID *fftypes.UUID, peerID, pay
 		return err
 	}
 
-	var responseData responseWithRequestID
-	res, err := h.client.R().SetContext(ctx).
-		SetBody(&transferBlob{
-			Path:      fmt.Sprintf("/%s", payloadRef),
-			Recipient: peerID,
-			RequestID: opID.String(),
-		}).
-		SetResult(&responseData).
-		Post("/api/v1/transfers")
-	if err != nil || !res.IsSuccess() {
-		return restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	transfer := &transferBlob{
+		Path:      fmt.Sprintf("/%s", payloadRef),
+		Recipient: peerID,
+		RequestID: opID.String(),
+	}
+	if err := h.transformTransfer(ctx, transfer, payloadRef); err != nil {
+		return err
+	}
+	if !h.transfers.reserve(transfer) {
+		log.L(ctx).Infof("Transfer %s to %s queued, as the maximum concurrent transfers to the peer has been reached", transfer.RequestID, peerID)
+		return nil
+	}
+	if err := h.postTransfer(ctx, transfer); err != nil {
+		// Nothing was started, so there is nothing for the next queued transfer to wait for
+		_ = h.transferFinished(ctx, transfer.RequestID)
+		h.transformer.completeTransfer(transfer.RequestID)
+		h.transferOver(ctx, transfer.RequestID)
+		return err
 	}
 	return nil
 }
@@ -360,6 +399,11 @@ func (h *FFDX) eventLoop() {
 					Error: msg.Error,
 					Info:  msg.Info,
 				})
+				if err == nil {
+					h.transformer.completeTransfer(msg.RequestID)
+					h.transferOver(ctx, msg.RequestID)
+					err = h.transferFinished(ctx, msg.RequestID)
+				}
 			case blobDelivered:
 				status := fftypes.OpStatusSucceeded
 				if h.capabilities.Manifest {
@@ -368,7 +412,20 @@ func (h *FFDX) eventLoop() {
 				err = h.callbacks.TransferResult(msg.RequestID, status, fftypes.TransportStatusUpdate{
 					Info: msg.Info,
 				})
+				if err == nil {
+					h.transferOver(ctx, msg.RequestID)
+					err = h.transferFinished(ctx, msg.RequestID)
+				}
+			case blobProgress:
+				err = h.callbacks.TransferResult(msg.RequestID, fftypes.OpStatusPending, fftypes.TransportStatusUpdate{
+					Progress: transferProgress(msg.Progress, msg.Size),
+					Info:     msg.Info,
+				})
 			case blobReceived:
+				if msg.Metadata != nil && len(msg.Metadata.Transforms) > 0 {
+					err = h.transformedBLOBReceived(ctx, &msg)
+					break
+				}
 				var hash *fftypes.Bytes32
 				hash, err = fftypes.ParseBytes32(ctx, msg.Hash)
 				if err != nil {
@@ -379,7 +436,7 @@ func (h *FFDX) eventLoop() {
 				}
 			case blobAcknowledged:
 				err = h.callbacks.TransferResult(msg.RequestID, fftypes.OpStatusSucceeded, fftypes.TransportStatusUpdate{
-					Hash: msg.Hash,
+					Hash: h.acknowledgedHash(msg.RequestID, msg.Hash),
 					Info: msg.Info,
 				})
 			default:
@@ -633,3 +633,14 @@ func TestDXUninitialized(t *testing.T) {
 	err = h.SendMessage(context.Background(), fftypes.NewUUID(), "peer1", []byte(`some data`))
 	assert.Regexp(t, "FF10342", err)
 }
+
+func TestHealth(t *testing.T) {
+	h, _, _, _, done := newTestFFDX(t, false)
+	defer done()
+
+	assert.Regexp(t, "FF10389", h.Health(context.Background()))
+
+	err := h.Start()
+	assert.NoError(t, err)
+	assert.NoError(t, h.Health(context.Background()))
+}
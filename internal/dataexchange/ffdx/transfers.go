@@ -0,0 +1,161 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+)
+
+// blobTransfers tracks the BLOB transfers that have been handed to DX and not yet completed,
+// so they can be resumed if DX restarts, and queues transfers beyond the per-peer concurrency limit
+type blobTransfers struct {
+	mux         sync.Mutex
+	maxPerPeer  int
+	active      map[string]*transferBlob
+	activeCount map[string]int
+	queued      map[string][]*transferBlob
+}
+
+func newBlobTransfers(maxPerPeer int) *blobTransfers {
+	return &blobTransfers{
+		maxPerPeer:  maxPerPeer,
+		active:      make(map[string]*transferBlob),
+		activeCount: make(map[string]int),
+		queued:      make(map[string][]*transferBlob),
+	}
+}
+
+// reserve returns true if the transfer can start immediately, or false if it has been queued
+func (bt *blobTransfers) reserve(transfer *transferBlob) bool {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+
+	if bt.maxPerPeer > 0 && bt.activeCount[transfer.Recipient] >= bt.maxPerPeer {
+		bt.queued[transfer.Recipient] = append(bt.queued[transfer.Recipient], transfer)
+		return false
+	}
+	bt.active[transfer.RequestID] = transfer
+	bt.activeCount[transfer.Recipient]++
+	return true
+}
+
+// release removes a finished transfer, and returns the next queued transfer for the same peer (if any)
+func (bt *blobTransfers) release(requestID string) *transferBlob {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+
+	transfer, ok := bt.active[requestID]
+	if !ok {
+		return nil
+	}
+	delete(bt.active, requestID)
+	peer := transfer.Recipient
+	bt.activeCount[peer]--
+	if bt.activeCount[peer] <= 0 {
+		delete(bt.activeCount, peer)
+	}
+
+	queue := bt.queued[peer]
+	if len(queue) == 0 {
+		return nil
+	}
+	next := queue[0]
+	if len(queue) == 1 {
+		delete(bt.queued, peer)
+	} else {
+		bt.queued[peer] = queue[1:]
+	}
+	bt.active[next.RequestID] = next
+	bt.activeCount[peer]++
+	return next
+}
+
+func (bt *blobTransfers) inFlight() []*transferBlob {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+
+	transfers := make([]*transferBlob, 0, len(bt.active))
+	for _, transfer := range bt.active {
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
+
+func (h *FFDX) postTransfer(ctx context.Context, transfer *transferBlob) error {
+	var responseData responseWithRequestID
+	res, err := h.client.R().SetContext(ctx).
+		SetBody(transfer).
+		SetResult(&responseData).
+		Post("/api/v1/transfers")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	}
+	return nil
+}
+
+// transferFinished releases the concurrency slot of a completed transfer, and starts the next
+// queued transfer to the same peer. Queued transfers that DX rejects are reported as failed.
+func (h *FFDX) transferFinished(ctx context.Context, requestID string) error {
+	for next := h.transfers.release(requestID); next != nil; next = h.transfers.release(next.RequestID) {
+		err := h.postTransfer(ctx, next)
+		if err == nil {
+			log.L(ctx).Debugf("Started queued transfer %s to %s", next.RequestID, next.Recipient)
+			return nil
+		}
+		log.L(ctx).Errorf("Queued transfer %s to %s failed to start: %s", next.RequestID, next.Recipient, err)
+		h.transferOver(ctx, next.RequestID)
+		if err := h.callbacks.TransferResult(next.RequestID, fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{
+			Error: err.Error(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resumeTransfers is called each time the websocket reconnects, as DX might have restarted.
+// Each in-flight transfer is re-submitted with its original request ID, so DX can resume it from
+// where it left off (or ignore it, if it already knows about the transfer).
+func (h *FFDX) resumeTransfers(ctx context.Context, w wsclient.WSClient) error {
+	for _, transfer := range h.transfers.inFlight() {
+		resume := *transfer
+		resume.Resume = true
+		log.L(ctx).Infof("Resuming transfer %s to %s", resume.RequestID, resume.Recipient)
+		if err := h.postTransfer(ctx, &resume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func transferProgress(transferred, total int64) *fftypes.TransferProgress {
+	progress := &fftypes.TransferProgress{
+		Transferred: transferred,
+		Total:       total,
+	}
+	if total > 0 {
+		progress.Percent = float64(transferred) * 100 / float64(total)
+	}
+	return progress
+}
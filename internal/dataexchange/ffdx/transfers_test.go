@@ -0,0 +1,208 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func registerTransferResponder(httpURL string, status int, requests chan<- *transferBlob) {
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/transfers", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			var transfer transferBlob
+			_ = json.NewDecoder(req.Body).Decode(&transfer)
+			if requests != nil {
+				requests <- &transfer
+			}
+			return httpmock.NewJsonResponse(status, fftypes.JSONObject{})
+		})
+}
+
+func TestTransferBLOBQueuedUntilDelivered(t *testing.T) {
+
+	h, toServer, fromServer, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	h.transfers = newBlobTransfers(1)
+
+	requests := make(chan *transferBlob, 3)
+	registerTransferResponder(httpURL, 200, requests)
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	op1 := fftypes.NewUUID()
+	op2 := fftypes.NewUUID()
+	op3 := fftypes.NewUUID()
+	err = h.TransferBLOB(context.Background(), op1, "peer1", "ns1/id1")
+	assert.NoError(t, err)
+	err = h.TransferBLOB(context.Background(), op2, "peer1", "ns1/id2")
+	assert.NoError(t, err)
+	err = h.TransferBLOB(context.Background(), op3, "peer2", "ns1/id3")
+	assert.NoError(t, err)
+
+	assert.Equal(t, op1.String(), (<-requests).RequestID)
+	assert.Equal(t, op3.String(), (<-requests).RequestID)
+	assert.Len(t, h.transfers.queued["peer1"], 1)
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", op1.String(), fftypes.OpStatusSucceeded, mock.Anything).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-delivered","requestID":"%s"}`, op1)
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+
+	assert.Equal(t, op2.String(), (<-requests).RequestID)
+	assert.Empty(t, h.transfers.queued)
+	assert.Equal(t, 1, h.transfers.activeCount["peer1"])
+
+	mcb.AssertExpectations(t)
+}
+
+func TestTransferBLOBQueuedStartFails(t *testing.T) {
+
+	h, toServer, fromServer, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	h.transfers = newBlobTransfers(1)
+
+	op1 := fftypes.NewUUID()
+	op2 := fftypes.NewUUID()
+	op3 := fftypes.NewUUID()
+	h.transfers.reserve(&transferBlob{RequestID: op1.String(), Recipient: "peer1"})
+	h.transfers.reserve(&transferBlob{RequestID: op2.String(), Recipient: "peer1"})
+	h.transfers.reserve(&transferBlob{RequestID: op3.String(), Recipient: "peer1"})
+	registerTransferResponder(httpURL, 500, nil)
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", op1.String(), fftypes.OpStatusFailed, mock.Anything).Return(nil)
+	mcb.On("TransferResult", op2.String(), fftypes.OpStatusFailed, mock.MatchedBy(func(ts fftypes.TransportStatusUpdate) bool {
+		return ts.Error != ""
+	})).Return(nil)
+	mcb.On("TransferResult", op3.String(), fftypes.OpStatusFailed, mock.Anything).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-failed","requestID":"%s","error":"pop"}`, op1)
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+
+	assert.Empty(t, h.transfers.active)
+	assert.Empty(t, h.transfers.queued)
+	mcb.AssertExpectations(t)
+}
+
+func TestTransferFinishedCallbackFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	h.transfers = newBlobTransfers(1)
+
+	h.transfers.reserve(&transferBlob{RequestID: "tx1", Recipient: "peer1"})
+	h.transfers.reserve(&transferBlob{RequestID: "tx2", Recipient: "peer1"})
+	registerTransferResponder(httpURL, 500, nil)
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", "tx2", fftypes.OpStatusFailed, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := h.transferFinished(context.Background(), "tx1")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestTransferBLOBErrorReleasesSlot(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	h.transfers = newBlobTransfers(1)
+
+	registerTransferResponder(httpURL, 500, nil)
+
+	err := h.TransferBLOB(context.Background(), fftypes.NewUUID(), "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10229", err)
+	assert.Empty(t, h.transfers.active)
+	assert.Empty(t, h.transfers.activeCount)
+}
+
+func TestResumeTransfers(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	requests := make(chan *transferBlob, 1)
+	registerTransferResponder(httpURL, 200, requests)
+
+	opID := fftypes.NewUUID()
+	err := h.TransferBLOB(context.Background(), opID, "peer1", "ns1/id1")
+	assert.NoError(t, err)
+	first := <-requests
+	assert.False(t, first.Resume)
+
+	err = h.resumeTransfers(context.Background(), h.wsconn)
+	assert.NoError(t, err)
+	resumed := <-requests
+	assert.True(t, resumed.Resume)
+	assert.Equal(t, opID.String(), resumed.RequestID)
+	assert.Equal(t, "/ns1/id1", resumed.Path)
+	assert.Equal(t, "peer1", resumed.Recipient)
+
+	// The tracked transfer is not modified by the resume
+	assert.False(t, h.transfers.active[opID.String()].Resume)
+}
+
+func TestResumeTransfersFail(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	h.transfers.reserve(&transferBlob{RequestID: "tx1", Recipient: "peer1"})
+	registerTransferResponder(httpURL, 500, nil)
+
+	err := h.resumeTransfers(context.Background(), h.wsconn)
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestEventsBlobProgress(t *testing.T) {
+
+	h, toServer, fromServer, _, done := newTestFFDX(t, false)
+	defer done()
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", "tx12345", fftypes.OpStatusPending, mock.MatchedBy(func(ts fftypes.TransportStatusUpdate) bool {
+		return ts.Progress.Transferred == 256 && ts.Progress.Total == 1024 && ts.Progress.Percent == 25
+	})).Return(nil)
+	fromServer <- `{"type":"blob-progress","requestID":"tx12345","progress":256,"size":1024}`
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+
+	mcb.AssertExpectations(t)
+}
+
+func TestTransferProgressUnknownSize(t *testing.T) {
+	progress := transferProgress(100, 0)
+	assert.Equal(t, int64(100), progress.Transferred)
+	assert.Equal(t, float64(0), progress.Percent)
+}
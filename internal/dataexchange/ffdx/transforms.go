@@ -0,0 +1,486 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+type blobTransform string
+
+const (
+	// transformGzip compresses the BLOB
+	transformGzip blobTransform = "gzip"
+	// transformBox encrypts the BLOB for the recipient with a NaCl box (X25519, XSalsa20 and Poly1305)
+	transformBox blobTransform = "nacl-box"
+)
+
+const (
+	peerInfoTransforms    = "transforms"
+	peerInfoSupported     = "supported"
+	peerInfoEncryptionKey = "encryptionKey"
+	boxNonceLength        = 24
+)
+
+// transferMetadata is passed to DX with a transformed BLOB transfer, and delivered back to FireFly in the blob-received
+// event on the recipient. The hashes of both the raw and the transformed BLOB are included, so the recipient can verify
+// what it received, and report the raw hash to FireFly - which is the hash that is pinned.
+type transferMetadata struct {
+	Transforms      []blobTransform `json:"transforms"`
+	Hash            string          `json:"hash"`
+	Size            int64           `json:"size"`
+	TransformedHash string          `json:"transformedHash"`
+}
+
+type peerTransformSupport struct {
+	supported map[blobTransform]bool
+	key       *[32]byte
+}
+
+// blobTransformer negotiates the transforms to apply to each BLOB transfer, based on the transforms each peer publishes
+// in its endpoint info, and applies/reverses them
+type blobTransformer struct {
+	mux           sync.Mutex
+	compression   blobTransform
+	privateKey    *[32]byte
+	publicKey     *[32]byte
+	peers         map[string]*peerTransformSupport
+	transfers     map[string]*transferMetadata
+	intermediates map[string]string
+}
+
+func newBlobTransformer(ctx context.Context, compression, encryptionKey string) (*blobTransformer, error) {
+	bt := &blobTransformer{
+		peers:         make(map[string]*peerTransformSupport),
+		transfers:     make(map[string]*transferMetadata),
+		intermediates: make(map[string]string),
+	}
+	switch blobTransform(strings.ToLower(compression)) {
+	case "":
+	case transformGzip:
+		bt.compression = transformGzip
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgDXUnsupportedTransform, compression)
+	}
+	if encryptionKey != "" {
+		privateKey, err := parseTransformKey(ctx, encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		// Cannot fail when multiplying by the base point
+		publicKey, _ := curve25519.X25519(privateKey[:], curve25519.Basepoint)
+		bt.privateKey = privateKey
+		bt.publicKey = new([32]byte)
+		copy(bt.publicKey[:], publicKey)
+	}
+	return bt, nil
+}
+
+func parseTransformKey(ctx context.Context, keyString string) (*[32]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(keyString, "0x"))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDXBadEncryptionKey, err)
+	}
+	if len(b) != 32 {
+		return nil, i18n.NewError(ctx, i18n.MsgDXBadEncryptionKey, fmt.Sprintf("expected 32 bytes, got %d", len(b)))
+	}
+	var key [32]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+// advertise adds the transforms this node can reverse to its endpoint info, so they are published to the other members
+// of the network. Nothing is added unless transforms are configured, so the endpoint info is unchanged by default.
+func (bt *blobTransformer) advertise(peer fftypes.JSONObject) {
+	if bt.compression == "" && bt.privateKey == nil {
+		return
+	}
+	info := fftypes.JSONObject{
+		peerInfoSupported: []string{string(transformGzip)},
+	}
+	if bt.privateKey != nil {
+		info[peerInfoSupported] = []string{string(transformGzip), string(transformBox)}
+		info[peerInfoEncryptionKey] = hex.EncodeToString(bt.publicKey[:])
+	}
+	peer[peerInfoTransforms] = info
+}
+
+// addPeer records the transforms published by a peer
+func (bt *blobTransformer) addPeer(ctx context.Context, peer fftypes.JSONObject) {
+	info, ok := peer.GetObjectOk(peerInfoTransforms)
+	if !ok {
+		return
+	}
+	peerID := peer.GetString("id")
+	support := &peerTransformSupport{
+		supported: make(map[blobTransform]bool),
+	}
+	for _, t := range info.GetStringArray(peerInfoSupported) {
+		support.supported[blobTransform(t)] = true
+	}
+	if keyString := info.GetString(peerInfoEncryptionKey); keyString != "" {
+		key, err := parseTransformKey(ctx, keyString)
+		if err != nil {
+			log.L(ctx).Warnf("Ignoring encryption key of peer '%s': %s", peerID, err)
+		} else {
+			support.key = key
+		}
+	}
+
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+	bt.peers[peerID] = support
+}
+
+// negotiate returns the transforms to apply to a transfer to the peer, and the key of the peer if it is to be encrypted
+func (bt *blobTransformer) negotiate(peerID string) (transforms []blobTransform, key *[32]byte) {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+
+	peer := bt.peers[peerID]
+	if peer == nil {
+		return nil, nil
+	}
+	if bt.compression != "" && peer.supported[bt.compression] {
+		transforms = append(transforms, bt.compression)
+	}
+	if bt.privateKey != nil && peer.key != nil && peer.supported[transformBox] {
+		transforms = append(transforms, transformBox)
+		key = peer.key
+	}
+	return transforms, key
+}
+
+func (bt *blobTransformer) peerKey(peerID string) *[32]byte {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+
+	if peer := bt.peers[peerID]; peer != nil {
+		return peer.key
+	}
+	return nil
+}
+
+// apply returns a reader of the BLOB with the transforms applied. Compression is streamed, but a NaCl box seals the
+// whole BLOB, so the input is read into memory when it is encrypted. The returned function must be called once the
+// reader is finished with, to stop any compression that is still in progress.
+func (bt *blobTransformer) apply(ctx context.Context, transforms []blobTransform, key *[32]byte, r io.Reader) (io.Reader, func(), error) {
+	var pipes []*io.PipeReader
+	done := func() {
+		for _, pr := range pipes {
+			_ = pr.Close()
+		}
+	}
+	for _, t := range transforms {
+		switch t {
+		case transformGzip:
+			pr, pw := io.Pipe()
+			go func(in io.Reader) {
+				zw := gzip.NewWriter(pw)
+				_, err := io.Copy(zw, in)
+				if err == nil {
+					err = zw.Close()
+				}
+				pw.CloseWithError(err)
+			}(r)
+			pipes = append(pipes, pr)
+			r = pr
+		case transformBox:
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				done()
+				return nil, nil, i18n.WrapError(ctx, err, i18n.MsgBlobStreamingFailed)
+			}
+			var nonce [boxNonceLength]byte
+			_, _ = rand.Read(nonce[:])
+			r = bytes.NewReader(box.Seal(nonce[:], data, &nonce, key, bt.privateKey))
+		}
+	}
+	return r, done, nil
+}
+
+// maxTransformedSize is the largest a transformed BLOB can be, for a raw BLOB of the given size. It allows for gzip
+// expanding data that cannot be compressed, and the nonce and authenticator of a NaCl box.
+func maxTransformedSize(size int64) int64 {
+	return size + size/100 + 1024
+}
+
+// reverse returns a reader that undoes the transforms applied by the sender, in the reverse order they were applied.
+// Decompression is streamed, and fails as soon as the output is larger than the size of the raw BLOB declared by the
+// sender. A NaCl box can only be opened whole, so an encrypted BLOB is read into memory, up to the size it can be.
+func (bt *blobTransformer) reverse(ctx context.Context, sender string, metadata *transferMetadata, r io.Reader) (io.Reader, error) {
+	transforms := metadata.Transforms
+	for i := len(transforms) - 1; i >= 0; i-- {
+		t := transforms[i]
+		switch t {
+		case transformGzip:
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, i18n.WrapError(ctx, err, i18n.MsgDXTransformFailed, t, sender)
+			}
+			r = &transformReader{ctx: ctx, r: zr, transform: t, sender: sender}
+		case transformBox:
+			key := bt.peerKey(sender)
+			if bt.privateKey == nil || key == nil {
+				return nil, i18n.NewError(ctx, i18n.MsgDXTransformFailed, t, sender)
+			}
+			maxSize := maxTransformedSize(metadata.Size)
+			data, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+			if err != nil {
+				return nil, i18n.WrapError(ctx, err, i18n.MsgDXTransformFailed, t, sender)
+			}
+			if int64(len(data)) > maxSize {
+				return nil, i18n.NewError(ctx, i18n.MsgDXTransformTooLarge, sender, metadata.Size)
+			}
+			if len(data) < boxNonceLength {
+				return nil, i18n.NewError(ctx, i18n.MsgDXTransformFailed, t, sender)
+			}
+			var nonce [boxNonceLength]byte
+			copy(nonce[:], data)
+			opened, ok := box.Open(nil, data[boxNonceLength:], &nonce, key, bt.privateKey)
+			if !ok {
+				return nil, i18n.NewError(ctx, i18n.MsgDXTransformFailed, t, sender)
+			}
+			r = bytes.NewReader(opened)
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgDXUnsupportedTransform, t)
+		}
+	}
+	return &declaredSizeReader{ctx: ctx, r: r, sender: sender, size: metadata.Size}, nil
+}
+
+// transformReader reports the errors of a transform that is being reversed as it is read
+type transformReader struct {
+	ctx       context.Context
+	r         io.Reader
+	transform blobTransform
+	sender    string
+}
+
+func (tr *transformReader) Read(p []byte) (int, error) {
+	n, err := tr.r.Read(p)
+	if err != nil && err != io.EOF {
+		return n, i18n.WrapError(tr.ctx, err, i18n.MsgDXTransformFailed, tr.transform, tr.sender)
+	}
+	return n, err
+}
+
+// declaredSizeReader fails the read once more than the declared size of the raw BLOB has been read, rather than
+// letting a small transfer expand without limit
+type declaredSizeReader struct {
+	ctx       context.Context
+	r         io.Reader
+	sender    string
+	size      int64
+	bytesRead int64
+}
+
+func (dr *declaredSizeReader) Read(p []byte) (int, error) {
+	n, err := dr.r.Read(p)
+	dr.bytesRead += int64(n)
+	if dr.bytesRead > dr.size {
+		return n, i18n.NewError(dr.ctx, i18n.MsgDXTransformTooLarge, dr.sender, dr.size)
+	}
+	return n, err
+}
+
+// trackTransfer keeps the metadata of a transformed transfer until it is acknowledged, as the receiving DX might
+// acknowledge it with the hash of the transformed BLOB
+func (bt *blobTransformer) trackTransfer(requestID string, metadata *transferMetadata) {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+	bt.transfers[requestID] = metadata
+}
+
+func (bt *blobTransformer) completeTransfer(requestID string) *transferMetadata {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+	metadata := bt.transfers[requestID]
+	delete(bt.transfers, requestID)
+	return metadata
+}
+
+// acknowledgedHash maps the hash in an acknowledgement of a transformed transfer back to the hash of the raw BLOB
+func (h *FFDX) acknowledgedHash(requestID, hash string) string {
+	metadata := h.transformer.completeTransfer(requestID)
+	if metadata != nil && strings.EqualFold(hash, metadata.TransformedHash) {
+		return metadata.Hash
+	}
+	return hash
+}
+
+// trackIntermediate records the transformed BLOB stored for a transfer, so it can be deleted once the transfer is over
+func (bt *blobTransformer) trackIntermediate(requestID, payloadRef string) {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+	bt.intermediates[requestID] = payloadRef
+}
+
+func (bt *blobTransformer) completeIntermediate(requestID string) string {
+	bt.mux.Lock()
+	defer bt.mux.Unlock()
+	payloadRef := bt.intermediates[requestID]
+	delete(bt.intermediates, requestID)
+	return payloadRef
+}
+
+// deleteBLOB removes an intermediate BLOB from DX. Failure is only logged, as it just leaves the BLOB behind.
+func (h *FFDX) deleteBLOB(ctx context.Context, payloadRef string) {
+	res, err := h.client.R().SetContext(ctx).
+		Delete(fmt.Sprintf("/api/v1/blobs/%s", payloadRef))
+	if err != nil || !res.IsSuccess() {
+		log.L(ctx).Warnf("Unable to delete intermediate blob '%s': %s", payloadRef, restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr))
+	}
+}
+
+// transferOver deletes the transformed BLOB of a transfer that has been delivered, or has failed
+func (h *FFDX) transferOver(ctx context.Context, requestID string) {
+	if payloadRef := h.transformer.completeIntermediate(requestID); payloadRef != "" {
+		h.deleteBLOB(ctx, payloadRef)
+	}
+}
+
+// hashingReader calculates the SHA-256 hash and size of a BLOB as it is streamed
+type hashingReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+func newHashingReader(r io.Reader) *hashingReader {
+	return &hashingReader{r: r, hash: sha256.New()}
+}
+
+func (hr *hashingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.hash.Write(p[0:n])
+	hr.size += int64(n)
+	return n, err
+}
+
+func (hr *hashingReader) result() *fftypes.Bytes32 {
+	var b fftypes.Bytes32
+	copy(b[:], hr.hash.Sum(nil))
+	return &b
+}
+
+// transformTransfer applies the transforms negotiated with the recipient to the BLOB. The result is stored in DX under
+// a path unique to the transfer, as it differs for each recipient, and the transfer is updated to send it instead.
+func (h *FFDX) transformTransfer(ctx context.Context, transfer *transferBlob, payloadRef string) error {
+	transforms, key := h.transformer.negotiate(transfer.Recipient)
+	if len(transforms) == 0 {
+		return nil
+	}
+	src, err := h.DownloadBLOB(ctx, payloadRef)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	raw := newHashingReader(src)
+	transformed, done, err := h.transformer.apply(ctx, transforms, key, raw)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	transformedRef := fmt.Sprintf("transforms/%s", transfer.RequestID)
+	hr := newHashingReader(transformed)
+	hash, _, err := h.putBLOB(ctx, transformedRef, transfer.RequestID, hr)
+	if err != nil {
+		return err
+	}
+	transformedHash := hr.result()
+	if *hash != *transformedHash {
+		h.deleteBLOB(ctx, transformedRef)
+		return i18n.NewError(ctx, i18n.MsgDXBadHash, hash, transformedHash)
+	}
+	h.transformer.trackIntermediate(transfer.RequestID, transformedRef)
+	log.L(ctx).Debugf("Transformed blob %s for transfer %s to %s with %v", payloadRef, transfer.RequestID, transfer.Recipient, transforms)
+
+	transfer.Path = fmt.Sprintf("/%s", transformedRef)
+	transfer.Metadata = &transferMetadata{
+		Transforms:      transforms,
+		Hash:            raw.result().String(),
+		Size:            raw.size,
+		TransformedHash: transformedHash.String(),
+	}
+	if h.capabilities.Manifest {
+		h.transformer.trackTransfer(transfer.RequestID, transfer.Metadata)
+	}
+	return nil
+}
+
+// transformedBLOBReceived reverses the transforms applied by the sender, storing the raw BLOB alongside the transformed
+// BLOB stored by DX, before FireFly is notified of the raw BLOB. The transformed BLOB is deleted once FireFly has been
+// notified. A BLOB that cannot be verified or reversed fails the event, so it is not acknowledged to DX.
+func (h *FFDX) transformedBLOBReceived(ctx context.Context, msg *wsEvent) error {
+	hash, size, rawRef, err := h.reverseTransforms(ctx, msg)
+	if err != nil {
+		log.L(ctx).Errorf("Unable to reverse transforms of blob '%s' received from '%s': %s", msg.Path, msg.Sender, err)
+		return err
+	}
+	if err := h.callbacks.PrivateBLOBReceived(msg.Sender, *hash, size, rawRef); err != nil {
+		return err
+	}
+	h.deleteBLOB(ctx, msg.Path)
+	return nil
+}
+
+func (h *FFDX) reverseTransforms(ctx context.Context, msg *wsEvent) (*fftypes.Bytes32, int64, string, error) {
+	metadata := msg.Metadata
+	if !strings.EqualFold(msg.Hash, metadata.TransformedHash) {
+		return nil, -1, "", i18n.NewError(ctx, i18n.MsgDXBadResponse, "hash", msg.Hash)
+	}
+	src, err := h.DownloadBLOB(ctx, msg.Path)
+	if err != nil {
+		return nil, -1, "", err
+	}
+	defer src.Close()
+	raw, err := h.transformer.reverse(ctx, msg.Sender, metadata, src)
+	if err != nil {
+		return nil, -1, "", err
+	}
+	rawRef := fmt.Sprintf("%s.raw", msg.Path)
+	hash, size, err := h.putBLOB(ctx, rawRef, path.Base(rawRef), raw)
+	if err != nil {
+		return nil, -1, "", err
+	}
+	if !strings.EqualFold(hash.String(), metadata.Hash) {
+		h.deleteBLOB(ctx, rawRef)
+		return nil, -1, "", i18n.NewError(ctx, i18n.MsgDXBadHash, hash, metadata.Hash)
+	}
+	return hash, size, rawRef, nil
+}
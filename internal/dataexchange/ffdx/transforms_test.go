@@ -0,0 +1,577 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testTransformKey = "0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+
+// registerBlobStore emulates the BLOB storage of DX, returning the SHA-256 hash of each upload
+func registerBlobStore(httpURL string) map[string][]byte {
+	var mux sync.Mutex
+	blobs := make(map[string][]byte)
+	re := regexp.MustCompile(fmt.Sprintf("^%s/api/v1/blobs/(.*)$", regexp.QuoteMeta(httpURL)))
+	httpmock.RegisterRegexpResponder("GET", re, func(req *http.Request) (*http.Response, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		data, ok := blobs[re.FindStringSubmatch(req.URL.String())[1]]
+		if !ok {
+			return httpmock.NewJsonResponse(404, fftypes.JSONObject{})
+		}
+		return httpmock.NewBytesResponse(200, data), nil
+	})
+	httpmock.RegisterRegexpResponder("DELETE", re, func(req *http.Request) (*http.Response, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		delete(blobs, re.FindStringSubmatch(req.URL.String())[1])
+		return httpmock.NewJsonResponse(204, fftypes.JSONObject{})
+	})
+	httpmock.RegisterRegexpResponder("PUT", re, func(req *http.Request) (*http.Response, error) {
+		file, _, err := req.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		data, _ := ioutil.ReadAll(file)
+		mux.Lock()
+		defer mux.Unlock()
+		blobs[re.FindStringSubmatch(req.URL.String())[1]] = data
+		hash := sha256.Sum256(data)
+		return httpmock.NewJsonResponse(200, fftypes.JSONObject{
+			"hash": hex.EncodeToString(hash[:]),
+			"size": len(data),
+		})
+	})
+	return blobs
+}
+
+// newTestTransformer configures transforms, with peer1 sharing the key pair of this node
+func newTestTransformer(t *testing.T, h *FFDX, compression string) {
+	var err error
+	h.transformer, err = newBlobTransformer(context.Background(), compression, testTransformKey)
+	assert.NoError(t, err)
+	peer := fftypes.JSONObject{"id": "peer1"}
+	h.transformer.advertise(peer)
+	h.transformer.addPeer(context.Background(), peer)
+}
+
+func TestInitBadTransformCompression(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	h := &FFDX{}
+	h.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(DataExchangeTransformCompression, "zip")
+	err := h.Init(context.Background(), utConfPrefix, []fftypes.JSONObject{}, &dataexchangemocks.Callbacks{})
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestInitTransformPeers(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	h := &FFDX{}
+	h.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(DataExchangeTransformCompression, "GZIP")
+	err := h.Init(context.Background(), utConfPrefix, []fftypes.JSONObject{
+		{"id": "peer1", "transforms": map[string]interface{}{"supported": []interface{}{"gzip"}}},
+		{"id": "peer2"},
+	}, &dataexchangemocks.Callbacks{})
+	assert.NoError(t, err)
+
+	transforms, key := h.transformer.negotiate("peer1")
+	assert.Equal(t, []blobTransform{transformGzip}, transforms)
+	assert.Nil(t, key)
+	transforms, _ = h.transformer.negotiate("peer2")
+	assert.Empty(t, transforms)
+}
+
+func TestNewBlobTransformerBadKey(t *testing.T) {
+	_, err := newBlobTransformer(context.Background(), "", "!hex")
+	assert.Regexp(t, "FF10490", err)
+
+	_, err = newBlobTransformer(context.Background(), "", "0x0102")
+	assert.Regexp(t, "FF10490.*expected 32 bytes", err)
+}
+
+func TestGetEndpointInfoAdvertisesTransforms(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "")
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/id", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"id": "peer1",
+		}))
+
+	peer, err := h.GetEndpointInfo(context.Background())
+	assert.NoError(t, err)
+	info := peer.GetObject("transforms")
+	assert.Equal(t, []string{"gzip", "nacl-box"}, info.GetStringArray("supported"))
+	assert.Equal(t, hex.EncodeToString(h.transformer.publicKey[:]), info.GetString("encryptionKey"))
+
+	h.transformer, _ = newBlobTransformer(context.Background(), "gzip", "")
+	peer = fftypes.JSONObject{}
+	h.transformer.advertise(peer)
+	assert.Equal(t, fftypes.JSONObject{"supported": []string{"gzip"}}, peer.GetObject("transforms"))
+}
+
+func TestAddPeerTransforms(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/peers/peer2", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+
+	err := h.AddPeer(context.Background(), fftypes.JSONObject{
+		"id": "peer2",
+		"transforms": fftypes.JSONObject{
+			"supported":     []string{"gzip", "nacl-box"},
+			"encryptionKey": "!bad key",
+		},
+	})
+	assert.NoError(t, err)
+
+	transforms, key := h.transformer.negotiate("peer2")
+	assert.Equal(t, []blobTransform{transformGzip}, transforms)
+	assert.Nil(t, key)
+
+	transforms, key = h.transformer.negotiate("peer1")
+	assert.Equal(t, []blobTransform{transformGzip, transformBox}, transforms)
+	assert.NotNil(t, key)
+}
+
+func TestTransferBLOBTransformed(t *testing.T) {
+
+	h, toServer, fromServer, httpURL, done := newTestFFDX(t, true)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	blobs := registerBlobStore(httpURL)
+	raw := []byte("some data that is transformed")
+	blobs["ns1/id1"] = raw
+	rawHash := fftypes.Bytes32(sha256.Sum256(raw))
+	requests := make(chan *transferBlob, 1)
+	registerTransferResponder(httpURL, 200, requests)
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	opID := fftypes.NewUUID()
+	err = h.TransferBLOB(context.Background(), opID, "peer1", "ns1/id1")
+	assert.NoError(t, err)
+
+	transfer := <-requests
+	assert.Equal(t, fmt.Sprintf("/transforms/%s", opID), transfer.Path)
+	assert.Equal(t, []blobTransform{transformGzip, transformBox}, transfer.Metadata.Transforms)
+	assert.Equal(t, rawHash.String(), transfer.Metadata.Hash)
+	assert.Equal(t, int64(len(raw)), transfer.Metadata.Size)
+	transformedRef := fmt.Sprintf("transforms/%s", opID)
+	assert.NotEqual(t, raw, blobs[transformedRef])
+
+	// Receiving the transfer stores the raw BLOB, and deletes the transformed BLOB
+	rawRef := transformedRef + ".raw"
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("PrivateBLOBReceived", "peer1", rawHash, int64(len(raw)), rawRef).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-received","sender":"peer1","path":"%s","hash":"%s","metadata":%s}`,
+		transformedRef, transfer.Metadata.TransformedHash, fftypes.JSONObject{
+			"transforms":      transfer.Metadata.Transforms,
+			"hash":            transfer.Metadata.Hash,
+			"size":            transfer.Metadata.Size,
+			"transformedHash": transfer.Metadata.TransformedHash,
+		})
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+	assert.Equal(t, raw, blobs[rawRef])
+	assert.NotContains(t, blobs, transformedRef)
+
+	// The acknowledgement is mapped back to the raw hash
+	mcb.On("TransferResult", opID.String(), fftypes.OpStatusSucceeded, mock.MatchedBy(func(ts fftypes.TransportStatusUpdate) bool {
+		return ts.Hash == rawHash.String()
+	})).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-acknowledged","requestID":"%s","hash":"%s"}`, opID, transfer.Metadata.TransformedHash)
+	msg = <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+	assert.Empty(t, h.transformer.transfers)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestTransferBLOBTransformedFailed(t *testing.T) {
+
+	h, toServer, fromServer, httpURL, done := newTestFFDX(t, true)
+	defer done()
+	newTestTransformer(t, h, "")
+
+	blobs := registerBlobStore(httpURL)
+	blobs["ns1/id1"] = []byte("some data")
+	registerTransferResponder(httpURL, 200, nil)
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	opID := fftypes.NewUUID()
+	err = h.TransferBLOB(context.Background(), opID, "peer1", "ns1/id1")
+	assert.NoError(t, err)
+	assert.Len(t, h.transformer.transfers, 1)
+	transformedRef := fmt.Sprintf("transforms/%s", opID)
+	assert.Contains(t, blobs, transformedRef)
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", opID.String(), fftypes.OpStatusFailed, mock.Anything).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-failed","requestID":"%s","error":"pop"}`, opID)
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+	assert.Empty(t, h.transformer.transfers)
+	assert.Empty(t, h.transformer.intermediates)
+	assert.NotContains(t, blobs, transformedRef)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestTransferBLOBTransformPostFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, true)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	blobs := registerBlobStore(httpURL)
+	blobs["ns1/id1"] = []byte("some data")
+	registerTransferResponder(httpURL, 500, nil)
+
+	err := h.TransferBLOB(context.Background(), fftypes.NewUUID(), "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10229", err)
+	assert.Empty(t, h.transformer.transfers)
+}
+
+func TestTransferBLOBTransformDownloadFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	registerBlobStore(httpURL)
+
+	err := h.TransferBLOB(context.Background(), fftypes.NewUUID(), "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestTransferBLOBTransformReadFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))),
+			}, nil
+		})
+
+	err := h.TransferBLOB(context.Background(), fftypes.NewUUID(), "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10217", err)
+}
+
+func TestTransferBLOBTransformUploadFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1", httpURL),
+		httpmock.NewBytesResponder(200, []byte("some data")))
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/blobs/transforms/%s", httpURL, opID),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	err := h.TransferBLOB(context.Background(), opID, "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestTransferBLOBTransformUploadBadHash(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1", httpURL),
+		httpmock.NewBytesResponder(200, []byte("some data")))
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/blobs/transforms/%s", httpURL, opID),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"hash": fftypes.NewRandB32().String(),
+		}))
+
+	err := h.TransferBLOB(context.Background(), opID, "peer1", "ns1/id1")
+	assert.Regexp(t, "FF10238", err)
+}
+
+func TestTransferBLOBTransformedDeliveredDeleteFails(t *testing.T) {
+
+	h, toServer, fromServer, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	opID := fftypes.NewUUID()
+	h.transformer.trackIntermediate(opID.String(), "transforms/id1")
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/blobs/transforms/id1", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	// The failed delete is only logged
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("TransferResult", opID.String(), fftypes.OpStatusSucceeded, mock.Anything).Return(nil)
+	fromServer <- fmt.Sprintf(`{"type":"blob-delivered","requestID":"%s"}`, opID)
+	msg := <-toServer
+	assert.Equal(t, `{"action":"commit"}`, string(msg))
+	assert.Empty(t, h.transformer.intermediates)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestTransformedBLOBReceivedFailure(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	registerBlobStore(httpURL)
+
+	// The event is not acknowledged, and the event loop exits
+	r := make(chan []byte, 1)
+	r <- []byte(`{"type":"blob-received","sender":"peer1","path":"transforms/id1","hash":"abcd","metadata":{"transforms":["gzip"],"transformedHash":"abcd"}}`)
+	wsm := &wsmocks.WSClient{}
+	wsm.On("Close").Return()
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	h.wsconn = wsm
+	h.eventLoop()
+
+	wsm.AssertExpectations(t)
+}
+
+func TestTransformedBLOBReceivedCallbackFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "")
+
+	blobs := registerBlobStore(httpURL)
+	raw := []byte("some data")
+	blobs["transforms/id1"] = raw
+	rawHash := fftypes.Bytes32(sha256.Sum256(raw))
+
+	mcb := h.callbacks.(*dataexchangemocks.Callbacks)
+	mcb.On("PrivateBLOBReceived", "peer1", rawHash, int64(len(raw)), "transforms/id1.raw").Return(fmt.Errorf("pop"))
+
+	// The transformed BLOB is kept, so the event can be processed again
+	err := h.transformedBLOBReceived(context.Background(), &wsEvent{
+		Sender: "peer1",
+		Path:   "transforms/id1",
+		Hash:   "abcd",
+		Metadata: &transferMetadata{
+			Transforms:      []blobTransform{},
+			Hash:            rawHash.String(),
+			Size:            int64(len(raw)),
+			TransformedHash: "abcd",
+		},
+	})
+	assert.EqualError(t, err, "pop")
+	assert.Contains(t, blobs, "transforms/id1")
+
+	mcb.AssertExpectations(t)
+}
+
+func TestReverseTransformsHashMismatch(t *testing.T) {
+
+	h, _, _, _, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Hash:     "abcd",
+		Metadata: &transferMetadata{TransformedHash: "1234"},
+	})
+	assert.Regexp(t, "FF10237", err)
+}
+
+func TestReverseTransformsBadTransform(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	blobs := registerBlobStore(httpURL)
+	blobs["transforms/id1"] = []byte("some data")
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Sender:   "peer1",
+		Path:     "transforms/id1",
+		Metadata: &transferMetadata{Transforms: []blobTransform{"rot13"}},
+	})
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestReverseTransformsUploadFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/transforms/id1", httpURL),
+		httpmock.NewBytesResponder(200, []byte("some data")))
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/blobs/transforms/id1.raw", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Sender:   "peer1",
+		Path:     "transforms/id1",
+		Metadata: &transferMetadata{Size: 9},
+	})
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestReverseTransformsRawHashMismatch(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	blobs := registerBlobStore(httpURL)
+	blobs["transforms/id1"] = []byte("some data")
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Sender:   "peer1",
+		Path:     "transforms/id1",
+		Metadata: &transferMetadata{Hash: fftypes.NewRandB32().String(), Size: 9},
+	})
+	assert.Regexp(t, "FF10238", err)
+	assert.NotContains(t, blobs, "transforms/id1.raw")
+}
+
+func TestReverseTransformsDownloadFails(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	registerBlobStore(httpURL)
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Sender:   "peer1",
+		Path:     "transforms/id1",
+		Metadata: &transferMetadata{},
+	})
+	assert.Regexp(t, "FF10229", err)
+}
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestReverseTransformsLargerThanDeclared(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+	newTestTransformer(t, h, "gzip")
+
+	// A small transfer that decompresses to much more than is declared
+	blobs := registerBlobStore(httpURL)
+	blobs["transforms/id1"] = gzipBytes(make([]byte, 1024*1024))
+
+	_, _, _, err := h.reverseTransforms(context.Background(), &wsEvent{
+		Sender: "peer1",
+		Path:   "transforms/id1",
+		Metadata: &transferMetadata{
+			Transforms: []blobTransform{transformGzip},
+			Size:       10,
+		},
+	})
+	assert.Regexp(t, "FF10565", err)
+	assert.NotContains(t, blobs, "transforms/id1.raw")
+}
+
+func readAllReversed(bt *blobTransformer, transforms []blobTransform, size int64, data []byte) ([]byte, error) {
+	r, err := bt.reverse(context.Background(), "peer1", &transferMetadata{Transforms: transforms, Size: size}, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestReverseTransformFailures(t *testing.T) {
+	ctx := context.Background()
+	bt, err := newBlobTransformer(ctx, "gzip", testTransformKey)
+	assert.NoError(t, err)
+
+	_, err = readAllReversed(bt, []blobTransform{transformGzip}, 100, []byte("not gzip"))
+	assert.Regexp(t, "FF10492.*gzip", err)
+
+	zipped := gzipBytes([]byte("some data"))
+	_, err = readAllReversed(bt, []blobTransform{transformGzip}, 100, zipped[0:len(zipped)-4])
+	assert.Regexp(t, "FF10492.*gzip", err)
+
+	// No key for the sender
+	_, err = readAllReversed(bt, []blobTransform{transformBox}, 100, make([]byte, 64))
+	assert.Regexp(t, "FF10492.*nacl-box", err)
+
+	peer := fftypes.JSONObject{"id": "peer1"}
+	bt.advertise(peer)
+	bt.addPeer(ctx, peer)
+
+	_, err = readAllReversed(bt, []blobTransform{transformBox}, 100, make([]byte, 10))
+	assert.Regexp(t, "FF10492.*nacl-box", err)
+
+	_, err = readAllReversed(bt, []blobTransform{transformBox}, 100, make([]byte, 64))
+	assert.Regexp(t, "FF10492.*nacl-box", err)
+
+	// Larger than a box of the declared size could be
+	_, err = readAllReversed(bt, []blobTransform{transformBox}, 10, make([]byte, 2048))
+	assert.Regexp(t, "FF10565", err)
+
+	_, err = bt.reverse(ctx, "peer1", &transferMetadata{Transforms: []blobTransform{transformBox}}, iotest.ErrReader(fmt.Errorf("pop")))
+	assert.Regexp(t, "FF10492.*nacl-box.*pop", err)
+}
@@ -0,0 +1,274 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopback
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	hubsMux sync.Mutex
+	hubs    = map[string]*hub{}
+)
+
+// hub connects the loopback plugins in the process with the same hub name, by their peer IDs
+type hub struct {
+	name  string
+	mux   sync.Mutex
+	peers map[string]*Loopback
+}
+
+// Loopback is a data exchange plugin for development and CI, that stores blobs in memory and delivers messages
+// and blobs directly to the other loopback plugins in the same process, instead of using a data exchange connector
+type Loopback struct {
+	ctx       context.Context
+	callbacks dataexchange.Callbacks
+	peerID    string
+	hub       *hub
+	mux       sync.Mutex
+	blobs     map[string]*blob
+	events    []func() error
+	notify    chan struct{}
+}
+
+type blob struct {
+	data []byte
+	hash fftypes.Bytes32
+}
+
+func getHub(name string) *hub {
+	hubsMux.Lock()
+	defer hubsMux.Unlock()
+	h, ok := hubs[name]
+	if !ok {
+		h = &hub{
+			name:  name,
+			peers: make(map[string]*Loopback),
+		}
+		hubs[name] = h
+	}
+	return h
+}
+
+func (h *hub) attach(l *Loopback) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.peers[l.peerID] = l
+}
+
+func (h *hub) detach(l *Loopback) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if h.peers[l.peerID] == l {
+		delete(h.peers, l.peerID)
+	}
+}
+
+func (h *hub) peer(peerID string) *Loopback {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return h.peers[peerID]
+}
+
+func (l *Loopback) Name() string {
+	return "loopback"
+}
+
+func (l *Loopback) Init(ctx context.Context, prefix config.Prefix, nodes []fftypes.JSONObject, callbacks dataexchange.Callbacks) (err error) {
+	l.ctx = log.WithLogField(ctx, "dx", "loopback")
+	l.callbacks = callbacks
+	l.peerID = prefix.GetString(DataExchangePeerID)
+	if l.peerID == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "peerID", "dataexchange.loopback")
+	}
+	l.blobs = make(map[string]*blob)
+	l.notify = make(chan struct{}, 1)
+	l.hub = getHub(prefix.GetString(DataExchangeHub))
+	l.hub.attach(l)
+	return nil
+}
+
+func (l *Loopback) Start() error {
+	go l.eventLoop()
+	return nil
+}
+
+func (l *Loopback) Capabilities() *dataexchange.Capabilities {
+	return &dataexchange.Capabilities{
+		Manifest: true,
+	}
+}
+
+func (l *Loopback) Health(ctx context.Context) error {
+	return nil
+}
+
+func (l *Loopback) GetEndpointInfo(ctx context.Context) (peer fftypes.JSONObject, err error) {
+	return fftypes.JSONObject{
+		"id":       l.peerID,
+		"endpoint": fmt.Sprintf("loopback://%s/%s", l.hub.name, l.peerID),
+	}, nil
+}
+
+func (l *Loopback) AddPeer(ctx context.Context, peer fftypes.JSONObject) (err error) {
+	// Peers are found on the hub by ID when data is sent to them
+	return nil
+}
+
+func (l *Loopback) queue(event func() error) {
+	l.mux.Lock()
+	l.events = append(l.events, event)
+	l.mux.Unlock()
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Loopback) eventLoop() {
+	defer l.hub.detach(l)
+	logger := log.L(l.ctx).WithField("role", "event-loop")
+	for {
+		l.mux.Lock()
+		events := l.events
+		l.events = nil
+		l.mux.Unlock()
+		for _, event := range events {
+			if err := event(); err != nil {
+				logger.Errorf("Event loop exiting: %s", err)
+				return
+			}
+		}
+		select {
+		case <-l.ctx.Done():
+			logger.Debugf("Event loop exiting (context cancelled)")
+			return
+		case <-l.notify:
+		}
+	}
+}
+
+func (l *Loopback) storeBLOB(payloadRef string, data []byte) *blob {
+	b := &blob{
+		data: data,
+		hash: sha256.Sum256(data),
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.blobs[payloadRef] = b
+	return b
+}
+
+func (l *Loopback) getBLOB(payloadRef string) *blob {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.blobs[payloadRef]
+}
+
+func (l *Loopback) UploadBLOB(ctx context.Context, ns string, id fftypes.UUID, content io.Reader) (payloadRef string, hash *fftypes.Bytes32, size int64, err error) {
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return "", nil, -1, err
+	}
+	payloadRef = fmt.Sprintf("%s/%s", ns, &id)
+	b := l.storeBLOB(payloadRef, data)
+	return payloadRef, &b.hash, int64(len(data)), nil
+}
+
+func (l *Loopback) DownloadBLOB(ctx context.Context, payloadRef string) (content io.ReadCloser, err error) {
+	b := l.getBLOB(payloadRef)
+	if b == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgLoopbackBlobNotFound, payloadRef)
+	}
+	return ioutil.NopCloser(bytes.NewReader(b.data)), nil
+}
+
+func (l *Loopback) CheckBLOBReceived(ctx context.Context, peerID, ns string, id fftypes.UUID) (hash *fftypes.Bytes32, size int64, err error) {
+	b := l.getBLOB(fmt.Sprintf("%s/%s/%s", peerID, ns, &id))
+	if b == nil {
+		return nil, -1, nil
+	}
+	return &b.hash, int64(len(b.data)), nil
+}
+
+func (l *Loopback) transferFailed(ctx context.Context, opID *fftypes.UUID, peerID string) {
+	err := i18n.NewError(ctx, i18n.MsgLoopbackPeerNotFound, peerID, l.hub.name)
+	l.queue(func() error {
+		return l.callbacks.TransferResult(opID.String(), fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{
+			Error: err.Error(),
+		})
+	})
+}
+
+func (l *Loopback) SendMessage(ctx context.Context, opID *fftypes.UUID, peerID string, data []byte) (err error) {
+	recipient := l.hub.peer(peerID)
+	if recipient == nil {
+		l.transferFailed(ctx, opID, peerID)
+		return nil
+	}
+	recipient.queue(func() error {
+		manifest, err := recipient.callbacks.MessageReceived(l.peerID, data)
+		if err != nil {
+			return err
+		}
+		l.queue(func() error {
+			return l.callbacks.TransferResult(opID.String(), fftypes.OpStatusSucceeded, fftypes.TransportStatusUpdate{
+				Manifest: manifest,
+			})
+		})
+		return nil
+	})
+	return nil
+}
+
+func (l *Loopback) TransferBLOB(ctx context.Context, opID *fftypes.UUID, peerID string, payloadRef string) (err error) {
+	b := l.getBLOB(payloadRef)
+	if b == nil {
+		return i18n.NewError(ctx, i18n.MsgLoopbackBlobNotFound, payloadRef)
+	}
+	recipient := l.hub.peer(peerID)
+	if recipient == nil {
+		l.transferFailed(ctx, opID, peerID)
+		return nil
+	}
+	recipient.queue(func() error {
+		receivedRef := fmt.Sprintf("%s/%s", l.peerID, payloadRef)
+		recipient.storeBLOB(receivedRef, b.data)
+		if err := recipient.callbacks.PrivateBLOBReceived(l.peerID, b.hash, int64(len(b.data)), receivedRef); err != nil {
+			return err
+		}
+		l.queue(func() error {
+			return l.callbacks.TransferResult(opID.String(), fftypes.OpStatusSucceeded, fftypes.TransportStatusUpdate{
+				Hash: b.hash.String(),
+			})
+		})
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopback
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("dataexchange").SubPrefix("loopback")
+
+type testPeer struct {
+	*Loopback
+	mcb    *dataexchangemocks.Callbacks
+	cancel func()
+}
+
+func newTestHub(t *testing.T, peerIDs ...string) []*testPeer {
+	hubName := fftypes.NewUUID().String()
+	peers := make([]*testPeer, len(peerIDs))
+	for i, peerID := range peerIDs {
+		config.Reset()
+		(&Loopback{}).InitPrefix(utConfPrefix)
+		utConfPrefix.Set(DataExchangeHub, hubName)
+		utConfPrefix.Set(DataExchangePeerID, peerID)
+		ctx, cancel := context.WithCancel(context.Background())
+		tp := &testPeer{
+			Loopback: &Loopback{},
+			mcb:      &dataexchangemocks.Callbacks{},
+			cancel:   cancel,
+		}
+		err := tp.Init(ctx, utConfPrefix, nil, tp.mcb)
+		assert.NoError(t, err)
+		peers[i] = tp
+	}
+	return peers
+}
+
+func (tp *testPeer) done() {
+	tp.cancel()
+}
+
+func TestInit(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+
+	assert.Equal(t, "loopback", peer.Name())
+	assert.True(t, peer.Capabilities().Manifest)
+	assert.NoError(t, peer.Health(context.Background()))
+	assert.NoError(t, peer.AddPeer(context.Background(), fftypes.JSONObject{"id": "peer2"}))
+
+	info, err := peer.GetEndpointInfo(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "peer1", info.GetString("id"))
+	assert.Equal(t, fmt.Sprintf("loopback://%s/peer1", peer.hub.name), info.GetString("endpoint"))
+}
+
+func TestInitMissingPeerID(t *testing.T) {
+	config.Reset()
+	l := &Loopback{}
+	l.InitPrefix(utConfPrefix)
+	err := l.Init(context.Background(), utConfPrefix, nil, &dataexchangemocks.Callbacks{})
+	assert.Regexp(t, "FF10138.*peerID", err)
+}
+
+func TestUploadDownloadBLOB(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+	ctx := context.Background()
+
+	id := fftypes.NewUUID()
+	payloadRef, hash, size, err := peer.UploadBLOB(ctx, "ns1", *id, strings.NewReader("some data"))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("ns1/%s", id), payloadRef)
+	assert.Equal(t, fftypes.Bytes32(sha256.Sum256([]byte("some data"))), *hash)
+	assert.Equal(t, int64(9), size)
+
+	reader, err := peer.DownloadBLOB(ctx, payloadRef)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "some data", string(data))
+
+	_, err = peer.DownloadBLOB(ctx, "ns1/unknown")
+	assert.Regexp(t, "FF10461", err)
+}
+
+func TestUploadBLOBReadFail(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+
+	_, _, _, err := peer.UploadBLOB(context.Background(), "ns1", *fftypes.NewUUID(), iotest{})
+	assert.EqualError(t, err, "pop")
+}
+
+type iotest struct{}
+
+func (iotest) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func TestSendMessage(t *testing.T) {
+	peers := newTestHub(t, "peer1", "peer2")
+	peer1, peer2 := peers[0], peers[1]
+	defer peer1.done()
+	defer peer2.done()
+
+	opID := fftypes.NewUUID()
+	result := make(chan fftypes.TransportStatusUpdate)
+	peer2.mcb.On("MessageReceived", "peer1", []byte("hello")).Return("manifest1", nil)
+	peer1.mcb.On("TransferResult", opID.String(), fftypes.OpStatusSucceeded, mock.Anything).Run(func(args mock.Arguments) {
+		result <- args[2].(fftypes.TransportStatusUpdate)
+	}).Return(nil)
+
+	// Nothing is delivered until the recipient starts
+	err := peer1.SendMessage(context.Background(), opID, "peer2", []byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, peer1.Start())
+	assert.NoError(t, peer2.Start())
+
+	update := <-result
+	assert.Equal(t, "manifest1", update.Manifest)
+	peer1.mcb.AssertExpectations(t)
+	peer2.mcb.AssertExpectations(t)
+}
+
+func TestSendMessagePeerNotFound(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+
+	opID := fftypes.NewUUID()
+	result := make(chan fftypes.TransportStatusUpdate)
+	peer.mcb.On("TransferResult", opID.String(), fftypes.OpStatusFailed, mock.Anything).Run(func(args mock.Arguments) {
+		result <- args[2].(fftypes.TransportStatusUpdate)
+	}).Return(nil)
+
+	err := peer.SendMessage(context.Background(), opID, "peer2", []byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, peer.Start())
+	assert.Regexp(t, "FF10462.*peer2", (<-result).Error)
+}
+
+func TestSendMessageReceiveFail(t *testing.T) {
+	peers := newTestHub(t, "peer1", "peer2")
+	peer1, peer2 := peers[0], peers[1]
+	defer peer1.done()
+	defer peer2.done()
+
+	peer2.mcb.On("MessageReceived", "peer1", []byte("hello")).Return("", fmt.Errorf("pop"))
+
+	err := peer1.SendMessage(context.Background(), fftypes.NewUUID(), "peer2", []byte("hello"))
+	assert.NoError(t, err)
+	peer2.eventLoop()
+	peer2.mcb.AssertExpectations(t)
+	assert.Nil(t, peer2.hub.peer("peer2"))
+}
+
+func TestTransferBLOB(t *testing.T) {
+	peers := newTestHub(t, "peer1", "peer2")
+	peer1, peer2 := peers[0], peers[1]
+	defer peer1.done()
+	defer peer2.done()
+	ctx := context.Background()
+
+	id := fftypes.NewUUID()
+	payloadRef, hash, _, err := peer1.UploadBLOB(ctx, "ns1", *id, strings.NewReader("some data"))
+	assert.NoError(t, err)
+
+	opID := fftypes.NewUUID()
+	result := make(chan fftypes.TransportStatusUpdate)
+	receivedRef := fmt.Sprintf("peer1/ns1/%s", id)
+	peer2.mcb.On("PrivateBLOBReceived", "peer1", *hash, int64(9), receivedRef).Return(nil)
+	peer1.mcb.On("TransferResult", opID.String(), fftypes.OpStatusSucceeded, mock.Anything).Run(func(args mock.Arguments) {
+		result <- args[2].(fftypes.TransportStatusUpdate)
+	}).Return(nil)
+
+	assert.NoError(t, peer1.Start())
+	assert.NoError(t, peer2.Start())
+	err = peer1.TransferBLOB(ctx, opID, "peer2", payloadRef)
+	assert.NoError(t, err)
+
+	update := <-result
+	assert.Equal(t, hash.String(), update.Hash)
+
+	receivedHash, size, err := peer2.CheckBLOBReceived(ctx, "peer1", "ns1", *id)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, receivedHash)
+	assert.Equal(t, int64(9), size)
+
+	receivedHash, _, err = peer2.CheckBLOBReceived(ctx, "peer3", "ns1", *id)
+	assert.NoError(t, err)
+	assert.Nil(t, receivedHash)
+	peer2.mcb.AssertExpectations(t)
+}
+
+func TestTransferBLOBNotFound(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+
+	err := peer.TransferBLOB(context.Background(), fftypes.NewUUID(), "peer2", "ns1/unknown")
+	assert.Regexp(t, "FF10461", err)
+}
+
+func TestTransferBLOBPeerNotFound(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+	ctx := context.Background()
+
+	payloadRef, _, _, err := peer.UploadBLOB(ctx, "ns1", *fftypes.NewUUID(), strings.NewReader("some data"))
+	assert.NoError(t, err)
+
+	opID := fftypes.NewUUID()
+	peer.mcb.On("TransferResult", opID.String(), fftypes.OpStatusFailed, mock.Anything).Return(nil)
+	err = peer.TransferBLOB(ctx, opID, "peer2", payloadRef)
+	assert.NoError(t, err)
+
+	peer.cancel()
+	peer.eventLoop()
+	peer.mcb.AssertExpectations(t)
+}
+
+func TestTransferBLOBReceiveFail(t *testing.T) {
+	peers := newTestHub(t, "peer1", "peer2")
+	peer1, peer2 := peers[0], peers[1]
+	defer peer1.done()
+	defer peer2.done()
+	ctx := context.Background()
+
+	payloadRef, _, _, err := peer1.UploadBLOB(ctx, "ns1", *fftypes.NewUUID(), strings.NewReader("some data"))
+	assert.NoError(t, err)
+
+	peer2.mcb.On("PrivateBLOBReceived", "peer1", mock.Anything, int64(9), mock.Anything).Return(fmt.Errorf("pop"))
+	err = peer1.TransferBLOB(ctx, fftypes.NewUUID(), "peer2", payloadRef)
+	assert.NoError(t, err)
+
+	peer2.eventLoop()
+	peer2.mcb.AssertExpectations(t)
+}
+
+func TestDetachReplacedPeer(t *testing.T) {
+	peer := newTestHub(t, "peer1")[0]
+	defer peer.done()
+
+	replacement := &Loopback{peerID: "peer1"}
+	peer.hub.attach(replacement)
+	peer.hub.detach(peer.Loopback)
+	assert.Equal(t, replacement, peer.hub.peer("peer1"))
+}
@@ -0,0 +1,222 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type columnType int
+
+const (
+	columnTypeString columnType = iota
+	columnTypeInt64
+	columnTypeTimestamp
+)
+
+type exportColumn struct {
+	name       string
+	columnType columnType
+}
+
+// exportCollection describes how to page through the records of a collection in time order,
+// and flatten each record into a row. Values are a string, int64 or *fftypes.FFTime - or nil.
+type exportCollection struct {
+	columns   []*exportColumn
+	timeField string
+	sort      []string
+	newFilter func(ctx context.Context) database.FilterBuilder
+	fetch     func(ctx context.Context, di database.Plugin, filter database.Filter) ([][]interface{}, error)
+}
+
+func str(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+func stringer(v interface{ String() string }, isNil bool) interface{} {
+	if isNil {
+		return nil
+	}
+	return str(v.String())
+}
+
+func uuid(v *fftypes.UUID) interface{} {
+	return stringer(v, v == nil)
+}
+
+func bytes32(v *fftypes.Bytes32) interface{} {
+	return stringer(v, v == nil)
+}
+
+func timestamp(v *fftypes.FFTime) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v
+}
+
+func jsonObject(v fftypes.JSONObject) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.String()
+}
+
+var collections = map[fftypes.DataExportCollection]*exportCollection{
+	fftypes.DataExportCollectionEvents: {
+		columns: []*exportColumn{
+			{"sequence", columnTypeInt64},
+			{"id", columnTypeString},
+			{"type", columnTypeString},
+			{"namespace", columnTypeString},
+			{"reference", columnTypeString},
+			{"correlator", columnTypeString},
+			{"tx", columnTypeString},
+			{"topic", columnTypeString},
+			{"created", columnTypeTimestamp},
+			{"correlationId", columnTypeString},
+		},
+		timeField: "created",
+		sort:      []string{"sequence"},
+		newFilter: database.EventQueryFactory.NewFilter,
+		fetch: func(ctx context.Context, di database.Plugin, filter database.Filter) ([][]interface{}, error) {
+			events, _, err := di.GetEvents(ctx, filter)
+			rows := make([][]interface{}, len(events))
+			for i, e := range events {
+				rows[i] = []interface{}{
+					e.Sequence, uuid(e.ID), str(string(e.Type)), str(e.Namespace), uuid(e.Reference),
+					uuid(e.Correlator), uuid(e.Transaction), str(e.Topic), timestamp(e.Created), str(e.CorrelationID),
+				}
+			}
+			return rows, err
+		},
+	},
+	fftypes.DataExportCollectionMessages: {
+		columns: []*exportColumn{
+			{"sequence", columnTypeInt64},
+			{"id", columnTypeString},
+			{"cid", columnTypeString},
+			{"type", columnTypeString},
+			{"txtype", columnTypeString},
+			{"author", columnTypeString},
+			{"key", columnTypeString},
+			{"created", columnTypeTimestamp},
+			{"namespace", columnTypeString},
+			{"group", columnTypeString},
+			{"topics", columnTypeString},
+			{"tag", columnTypeString},
+			{"datahash", columnTypeString},
+			{"hash", columnTypeString},
+			{"batch", columnTypeString},
+			{"state", columnTypeString},
+			{"confirmed", columnTypeTimestamp},
+			{"data", columnTypeInt64},
+			{"correlationId", columnTypeString},
+		},
+		timeField: "created",
+		sort:      []string{"sequence"},
+		newFilter: database.MessageQueryFactory.NewFilter,
+		fetch: func(ctx context.Context, di database.Plugin, filter database.Filter) ([][]interface{}, error) {
+			msgs, _, err := di.GetMessages(ctx, filter)
+			rows := make([][]interface{}, len(msgs))
+			for i, m := range msgs {
+				h := &m.Header
+				rows[i] = []interface{}{
+					m.Sequence, uuid(h.ID), uuid(h.CID), str(string(h.Type)), str(string(h.TxType)),
+					str(h.Author), str(h.Key), timestamp(h.Created), str(h.Namespace), bytes32(h.Group),
+					str(strings.Join(h.Topics, ",")), str(h.Tag), bytes32(h.DataHash), bytes32(m.Hash), uuid(m.BatchID),
+					str(string(m.State)), timestamp(m.Confirmed), int64(len(m.Data)), str(m.CorrelationID),
+				}
+			}
+			return rows, err
+		},
+	},
+	fftypes.DataExportCollectionBlockchainEvents: {
+		columns: []*exportColumn{
+			{"id", columnTypeString},
+			{"source", columnTypeString},
+			{"namespace", columnTypeString},
+			{"name", columnTypeString},
+			{"listener", columnTypeString},
+			{"protocolId", columnTypeString},
+			{"output", columnTypeString},
+			{"info", columnTypeString},
+			{"timestamp", columnTypeTimestamp},
+			{"tx.type", columnTypeString},
+			{"tx.id", columnTypeString},
+		},
+		timeField: "timestamp",
+		sort:      []string{"timestamp", "id"},
+		newFilter: database.BlockchainEventQueryFactory.NewFilter,
+		fetch: func(ctx context.Context, di database.Plugin, filter database.Filter) ([][]interface{}, error) {
+			events, _, err := di.GetBlockchainEvents(ctx, filter)
+			rows := make([][]interface{}, len(events))
+			for i, e := range events {
+				rows[i] = []interface{}{
+					uuid(e.ID), str(e.Source), str(e.Namespace), str(e.Name), uuid(e.Listener), str(e.ProtocolID),
+					jsonObject(e.Output), jsonObject(e.Info), timestamp(e.Timestamp), str(string(e.TX.Type)), uuid(e.TX.ID),
+				}
+			}
+			return rows, err
+		},
+	},
+	fftypes.DataExportCollectionTokenTransfers: {
+		columns: []*exportColumn{
+			{"localId", columnTypeString},
+			{"type", columnTypeString},
+			{"pool", columnTypeString},
+			{"tokenIndex", columnTypeString},
+			{"uri", columnTypeString},
+			{"connector", columnTypeString},
+			{"namespace", columnTypeString},
+			{"key", columnTypeString},
+			{"from", columnTypeString},
+			{"to", columnTypeString},
+			{"amount", columnTypeString},
+			{"protocolId", columnTypeString},
+			{"message", columnTypeString},
+			{"messageHash", columnTypeString},
+			{"created", columnTypeTimestamp},
+			{"tx.type", columnTypeString},
+			{"tx.id", columnTypeString},
+			{"blockchainEvent", columnTypeString},
+		},
+		timeField: "created",
+		sort:      []string{"created", "localid"},
+		newFilter: database.TokenTransferQueryFactory.NewFilter,
+		fetch: func(ctx context.Context, di database.Plugin, filter database.Filter) ([][]interface{}, error) {
+			transfers, _, err := di.GetTokenTransfers(ctx, filter)
+			rows := make([][]interface{}, len(transfers))
+			for i, t := range transfers {
+				rows[i] = []interface{}{
+					uuid(t.LocalID), str(string(t.Type)), uuid(t.Pool), str(t.TokenIndex), str(t.URI), str(t.Connector),
+					str(t.Namespace), str(t.Key), str(t.From), str(t.To), str(t.Amount.Int().String()), str(t.ProtocolID),
+					uuid(t.Message), bytes32(t.MessageHash), timestamp(t.Created), str(string(t.TX.Type)), uuid(t.TX.ID),
+					uuid(t.BlockchainEvent),
+				}
+			}
+			return rows, err
+		},
+	},
+}
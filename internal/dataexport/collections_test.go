@@ -0,0 +1,122 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func fetchTestRows(t *testing.T, c fftypes.DataExportCollection, mdi *databasemocks.Plugin) [][]interface{} {
+	coll := collections[c]
+	fb := coll.newFilter(context.Background())
+	rows, err := coll.fetch(context.Background(), mdi, fb.And())
+	assert.NoError(t, err)
+	for _, row := range rows {
+		assert.Len(t, row, len(coll.columns))
+	}
+	return rows
+}
+
+func TestFetchEvents(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Sequence:  12345,
+		Type:      fftypes.EventTypeMessageConfirmed,
+		Namespace: "ns1",
+		Reference: fftypes.NewUUID(),
+		Created:   fftypes.Now(),
+	}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{event}, nil, nil)
+
+	rows := fetchTestRows(t, fftypes.DataExportCollectionEvents, mdi)
+	assert.Equal(t, []interface{}{
+		int64(12345), event.ID.String(), "message_confirmed", "ns1", event.Reference.String(),
+		nil, nil, nil, event.Created, nil,
+	}, rows[0])
+}
+
+func TestFetchMessages(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      fftypes.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1", "topic2"},
+			DataHash:  fftypes.NewRandB32(),
+		},
+		State:    fftypes.MessageStateConfirmed,
+		Data:     fftypes.DataRefs{{ID: fftypes.NewUUID()}},
+		Sequence: 10,
+	}
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+
+	rows := fetchTestRows(t, fftypes.DataExportCollectionMessages, mdi)
+	assert.Equal(t, int64(10), rows[0][0])
+	assert.Equal(t, "topic1,topic2", rows[0][10])
+	assert.Equal(t, msg.Header.DataHash.String(), rows[0][12])
+	assert.Nil(t, rows[0][13])
+	assert.Equal(t, "confirmed", rows[0][15])
+	assert.Equal(t, int64(1), rows[0][17])
+}
+
+func TestFetchBlockchainEvents(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	event := &fftypes.BlockchainEvent{
+		ID:     fftypes.NewUUID(),
+		Source: "ethereum",
+		Name:   "Changed",
+		Output: fftypes.JSONObject{"value": "1"},
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeContractInvoke,
+			ID:   fftypes.NewUUID(),
+		},
+	}
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{event}, nil, nil)
+
+	rows := fetchTestRows(t, fftypes.DataExportCollectionBlockchainEvents, mdi)
+	assert.Equal(t, `{"value":"1"}`, rows[0][6])
+	assert.Nil(t, rows[0][7])
+	assert.Nil(t, rows[0][8])
+	assert.Equal(t, "contract_invoke", rows[0][9])
+	assert.Equal(t, event.TX.ID.String(), rows[0][10])
+}
+
+func TestFetchTokenTransfers(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	transfer := &fftypes.TokenTransfer{
+		LocalID: fftypes.NewUUID(),
+		Type:    fftypes.TokenTransferTypeMint,
+		To:      "0x12345",
+		Amount:  *fftypes.NewFFBigInt(1000),
+	}
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{transfer}, nil, nil)
+
+	rows := fetchTestRows(t, fftypes.DataExportCollectionTokenTransfers, mdi)
+	assert.Equal(t, transfer.LocalID.String(), rows[0][0])
+	assert.Equal(t, "mint", rows[0][1])
+	assert.Nil(t, rows[0][8])
+	assert.Equal(t, "0x12345", rows[0][9])
+	assert.Equal(t, "1000", rows[0][10])
+}
@@ -0,0 +1,286 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/sharedstorage"
+)
+
+// allCollections is the order the collections are exported in, when the request does not specify them
+var allCollections = []fftypes.DataExportCollection{
+	fftypes.DataExportCollectionEvents,
+	fftypes.DataExportCollectionMessages,
+	fftypes.DataExportCollectionBlockchainEvents,
+	fftypes.DataExportCollectionTokenTransfers,
+}
+
+// Manager writes historical records for a time range to files, reporting the progress through an operation
+type Manager interface {
+	fftypes.Named
+
+	// Start fails any exports left pending by a previous run of the node, as exports are not resumable
+	Start() error
+
+	// ExportData checks the request, then writes a file for each collection in the background - returning the operation that reports progress
+	ExportData(ctx context.Context, ns string, req *fftypes.DataExportRequest) (*fftypes.Operation, error)
+}
+
+type exportManager struct {
+	ctx           context.Context
+	database      database.Plugin
+	sharedstorage sharedstorage.Plugin
+	txHelper      txcommon.Helper
+	directory     string
+	pageSize      int
+	sharedUpload  bool
+}
+
+// NewExportManager creates the manager for data exports. Exports run on the supplied context, as they outlive the request that starts them
+func NewExportManager(ctx context.Context, di database.Plugin, ss sharedstorage.Plugin, txHelper txcommon.Helper) (Manager, error) {
+	if di == nil || ss == nil || txHelper == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	em := &exportManager{
+		ctx:           ctx,
+		database:      di,
+		sharedstorage: ss,
+		txHelper:      txHelper,
+		directory:     config.GetString(config.ExportDirectory),
+		pageSize:      config.GetInt(config.ExportPageSize),
+		sharedUpload:  config.GetBool(config.ExportSharedStorageEnabled),
+	}
+	if em.pageSize < 1 {
+		em.pageSize = 1
+	}
+	return em, nil
+}
+
+func (em *exportManager) Name() string {
+	return "DataExportManager"
+}
+
+func (em *exportManager) Start() error {
+	startupTime := fftypes.Now()
+	interrupted := i18n.NewError(em.ctx, i18n.MsgDataExportInterrupted).Error()
+	for {
+		// Each failed operation leaves the pending set, so the first page is always the next one
+		fb := database.OperationQueryFactory.NewFilter(em.ctx)
+		filter := fb.And(
+			fb.Eq("type", fftypes.OpTypeDataExport),
+			fb.Eq("status", fftypes.OpStatusPending),
+			fb.Lt("created", startupTime),
+		).Sort("created").Limit(uint64(em.pageSize))
+		pendingOps, _, err := em.database.GetOperations(em.ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, op := range pendingOps {
+			log.L(em.ctx).Warnf("Failing data export %s interrupted by restart", op.ID)
+			if err := em.txHelper.ResolveOperation(em.ctx, op.ID, fftypes.OpStatusFailed, interrupted, op.Output); err != nil {
+				return err
+			}
+		}
+		if len(pendingOps) < em.pageSize {
+			return nil
+		}
+	}
+}
+
+func (em *exportManager) validateRequest(ctx context.Context, req *fftypes.DataExportRequest) error {
+	if len(req.Collections) == 0 {
+		req.Collections = allCollections
+	}
+	for _, c := range req.Collections {
+		if _, ok := collections[c]; !ok {
+			return i18n.NewError(ctx, i18n.MsgDataExportInvalid, fmt.Sprintf("unknown collection '%s'", c))
+		}
+	}
+	switch req.Format {
+	case "":
+		req.Format = fftypes.DataExportFormatCSV
+	case fftypes.DataExportFormatCSV, fftypes.DataExportFormatParquet:
+	default:
+		return i18n.NewError(ctx, i18n.MsgDataExportInvalid, fmt.Sprintf("unknown format '%s'", req.Format))
+	}
+	switch req.Destination {
+	case "":
+		req.Destination = fftypes.DataExportDestinationLocal
+	case fftypes.DataExportDestinationLocal:
+	case fftypes.DataExportDestinationSharedStorage:
+		// Shared storage such as IPFS can make the files readable by other members, so uploads must be enabled explicitly
+		if !em.sharedUpload {
+			return i18n.NewError(ctx, i18n.MsgDataExportInvalid, "the sharedstorage destination is not enabled")
+		}
+	default:
+		return i18n.NewError(ctx, i18n.MsgDataExportInvalid, fmt.Sprintf("unknown destination '%s'", req.Destination))
+	}
+	// Fix the end of the range when the export starts, so records that arrive while it runs are not included
+	if req.Until == nil {
+		req.Until = fftypes.Now()
+	}
+	if req.Since != nil && req.Since.UnixNano() >= req.Until.UnixNano() {
+		return i18n.NewError(ctx, i18n.MsgDataExportInvalid, "since must be before until")
+	}
+	return nil
+}
+
+func (em *exportManager) ExportData(ctx context.Context, ns string, req *fftypes.DataExportRequest) (op *fftypes.Operation, err error) {
+	if err := em.validateRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	err = em.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		txid, err := em.txHelper.SubmitNewTransaction(ctx, ns, fftypes.TransactionTypeDataExport)
+		if err != nil {
+			return err
+		}
+		op = fftypes.NewOperation(em, ns, txid, fftypes.OpTypeDataExport)
+		b, _ := json.Marshal(req)
+		op.Input = fftypes.JSONAnyPtrBytes(b).JSONObject()
+		return em.database.InsertOperation(ctx, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go em.exportData(log.WithLogField(em.ctx, "opid", op.ID.String()), ns, op.ID, req)
+	return op, nil
+}
+
+func statusOutput(status *fftypes.DataExportStatus) fftypes.JSONObject {
+	b, _ := json.Marshal(status)
+	return fftypes.JSONAnyPtrBytes(b).JSONObject()
+}
+
+func (em *exportManager) exportData(ctx context.Context, ns string, opID *fftypes.UUID, req *fftypes.DataExportRequest) {
+	status := &fftypes.DataExportStatus{}
+	for _, c := range req.Collections {
+		status.Collections = append(status.Collections, &fftypes.DataExportCollectionStatus{Collection: c})
+	}
+	l := log.L(ctx)
+	l.Infof("Exporting %v from namespace '%s' as %s", req.Collections, ns, req.Format)
+
+	opStatus := fftypes.OpStatusSucceeded
+	errMsg := ""
+	for _, cs := range status.Collections {
+		if err := em.exportCollection(ctx, ns, opID, req, status, cs); err != nil {
+			opStatus = fftypes.OpStatusFailed
+			errMsg = i18n.NewError(ctx, i18n.MsgDataExportFailed, cs.Collection, err).Error()
+			break
+		}
+	}
+	l.Infof("Data export complete: status=%s", opStatus)
+	if err := em.txHelper.ResolveOperation(ctx, opID, opStatus, errMsg, statusOutput(status)); err != nil {
+		l.Errorf("Failed to record result of data export: %s", err)
+	}
+}
+
+func (em *exportManager) createFile(ns string, opID *fftypes.UUID, req *fftypes.DataExportRequest, c fftypes.DataExportCollection) (*os.File, error) {
+	name := fmt.Sprintf("%s_%s_%s.%s", ns, c, opID, req.Format)
+	if req.Destination == fftypes.DataExportDestinationSharedStorage {
+		return os.CreateTemp("", "*_"+name)
+	}
+	if err := os.MkdirAll(em.directory, 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(em.directory, name))
+}
+
+func (em *exportManager) exportCollection(ctx context.Context, ns string, opID *fftypes.UUID, req *fftypes.DataExportRequest, status *fftypes.DataExportStatus, cs *fftypes.DataExportCollectionStatus) error {
+	file, err := em.createFile(ns, opID, req, cs.Collection)
+	if err != nil {
+		return err
+	}
+	if req.Destination == fftypes.DataExportDestinationSharedStorage {
+		defer os.Remove(file.Name())
+	}
+	err = em.writeCollection(ctx, file, ns, opID, req, status, cs)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	if req.Destination == fftypes.DataExportDestinationSharedStorage {
+		if cs.Location, err = em.uploadFile(ctx, file.Name()); err != nil {
+			return err
+		}
+	} else {
+		cs.Location = file.Name()
+	}
+	cs.Complete = true
+	log.L(ctx).Infof("Exported %d %s to %s", cs.Rows, cs.Collection, cs.Location)
+	return nil
+}
+
+// writeCollection pages through the records of the collection in the time range, writing each page to the output
+func (em *exportManager) writeCollection(ctx context.Context, out io.Writer, ns string, opID *fftypes.UUID, req *fftypes.DataExportRequest, status *fftypes.DataExportStatus, cs *fftypes.DataExportCollectionStatus) error {
+	coll := collections[cs.Collection]
+	var w rowWriter
+	if req.Format == fftypes.DataExportFormatParquet {
+		w = newParquetWriter(out, coll.columns, em.pageSize)
+	} else {
+		w = newCSVWriter(out, coll.columns)
+	}
+
+	for skip := 0; ; skip += em.pageSize {
+		fb := coll.newFilter(ctx)
+		conditions := []database.Filter{fb.Eq("namespace", ns), fb.Lt(coll.timeField, req.Until)}
+		if req.Since != nil {
+			conditions = append(conditions, fb.Gte(coll.timeField, req.Since))
+		}
+		filter := fb.And(conditions...).Sort(coll.sort...).Skip(uint64(skip)).Limit(uint64(em.pageSize))
+		rows, err := coll.fetch(ctx, em.database, filter)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := w.writeRow(row); err != nil {
+				return err
+			}
+		}
+		cs.Rows += int64(len(rows))
+		if len(rows) < em.pageSize {
+			break
+		}
+		if err := em.txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusPending, "", statusOutput(status)); err != nil {
+			log.L(ctx).Warnf("Failed to record progress of data export: %s", err)
+		}
+	}
+	return w.close()
+}
+
+func (em *exportManager) uploadFile(ctx context.Context, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return em.sharedstorage.UploadData(ctx, file)
+}
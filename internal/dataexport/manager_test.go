@@ -0,0 +1,352 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestExportManager(t *testing.T) (*exportManager, func()) {
+	config.Reset()
+	config.Set(config.ExportDirectory, t.TempDir())
+	config.Set(config.ExportPageSize, 0)
+	mdi := &databasemocks.Plugin{}
+	mss := &sharedstoragemocks.Plugin{}
+	mth := &txcommonmocks.Helper{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	em, err := NewExportManager(ctx, mdi, mss, mth)
+	assert.NoError(t, err)
+	return em.(*exportManager), func() {
+		cancel()
+		mdi.AssertExpectations(t)
+		mss.AssertExpectations(t)
+		mth.AssertExpectations(t)
+	}
+}
+
+func newTestExportRequest(format fftypes.DataExportFormat, destination fftypes.DataExportDestination) *fftypes.DataExportRequest {
+	return &fftypes.DataExportRequest{
+		Collections: []fftypes.DataExportCollection{fftypes.DataExportCollectionEvents},
+		Format:      format,
+		Destination: destination,
+		Since:       fftypes.UnixTime(1000),
+		Until:       fftypes.UnixTime(2000),
+	}
+}
+
+func testEvents(count int) []*fftypes.Event {
+	events := make([]*fftypes.Event, count)
+	for i := range events {
+		events[i] = &fftypes.Event{
+			ID:        fftypes.NewUUID(),
+			Sequence:  int64(i),
+			Type:      fftypes.EventTypeMessageConfirmed,
+			Namespace: "ns1",
+			Created:   fftypes.UnixTime(1500),
+		}
+	}
+	return events
+}
+
+func TestNewExportManagerFail(t *testing.T) {
+	_, err := NewExportManager(context.Background(), nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestName(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	assert.Equal(t, "DataExportManager", em.Name())
+	assert.Equal(t, 1, em.pageSize)
+}
+
+func TestStartFailsInterruptedExports(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	em.pageSize = 2
+
+	ops := []*fftypes.Operation{
+		{ID: fftypes.NewUUID(), Output: fftypes.JSONObject{"collections": []interface{}{}}},
+		{ID: fftypes.NewUUID()},
+	}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(ops, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil).Once()
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, ops[0].ID, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return errMsg == "FF10569: Data export was interrupted by a restart of the node"
+	}), ops[0].Output).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, ops[1].ID, fftypes.OpStatusFailed, mock.Anything, fftypes.JSONObject(nil)).Return(nil)
+
+	err := em.Start()
+	assert.NoError(t, err)
+}
+
+func TestStartQueryFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := em.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStartResolveFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{{ID: fftypes.NewUUID()}}, nil, nil)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportDataLocalCSV(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	em.pageSize = 2
+
+	txid := fftypes.NewUUID()
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeDataExport).Return(txid, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeDataExport && op.Transaction.Equals(txid) && op.Input.GetString("format") == "csv"
+	})).Return(nil)
+	events := testEvents(3)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(events[0:2], nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(events[2:], nil, nil).Once()
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).Return(fmt.Errorf("pop"))
+	completed := make(chan fftypes.JSONObject)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusSucceeded, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			completed <- args[4].(fftypes.JSONObject)
+		}).
+		Return(fmt.Errorf("pop"))
+
+	req := &fftypes.DataExportRequest{
+		Collections: []fftypes.DataExportCollection{fftypes.DataExportCollectionEvents},
+	}
+	op, err := em.ExportData(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpTypeDataExport, op.Type)
+	assert.Equal(t, fftypes.DataExportFormatCSV, req.Format)
+	assert.Equal(t, fftypes.DataExportDestinationLocal, req.Destination)
+	assert.NotNil(t, req.Until)
+
+	output := <-completed
+	collection := output.GetObjectArray("collections")[0]
+	assert.Equal(t, int64(3), collection.GetInt64("rows"))
+	assert.True(t, collection.GetBool("complete"))
+	location := collection.GetString("location")
+	assert.Equal(t, filepath.Join(em.directory, fmt.Sprintf("ns1_events_%s.csv", op.ID)), location)
+	b, err := ioutil.ReadFile(location)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^sequence,id,type.*\n0,.*\n1,.*\n2,.*\n$", string(b))
+}
+
+func TestExportDataAllCollectionsParquetSharedStorage(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	em.pageSize = 10
+	em.sharedUpload = true
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(testEvents(1), nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mss := em.sharedstorage.(*sharedstoragemocks.Plugin)
+	mss.On("UploadData", mock.Anything, mock.MatchedBy(func(r io.Reader) bool {
+		b, _ := ioutil.ReadAll(r)
+		return string(b[0:4]) == "PAR1"
+	})).Return("Qm12345", nil)
+	var output fftypes.JSONObject
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusSucceeded, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			output = args[4].(fftypes.JSONObject)
+		}).
+		Return(nil)
+
+	req := &fftypes.DataExportRequest{
+		Format:      fftypes.DataExportFormatParquet,
+		Destination: fftypes.DataExportDestinationSharedStorage,
+		Since:       fftypes.UnixTime(1000),
+	}
+	err := em.validateRequest(context.Background(), req)
+	assert.NoError(t, err)
+	em.exportData(context.Background(), "ns1", fftypes.NewUUID(), req)
+
+	collections := output.GetObjectArray("collections")
+	assert.Len(t, collections, 4)
+	assert.Equal(t, "events", collections[0].GetString("collection"))
+	assert.Equal(t, int64(1), collections[0].GetInt64("rows"))
+	for _, c := range collections {
+		assert.Equal(t, "Qm12345", c.GetString("location"))
+	}
+	tmpFiles, _ := filepath.Glob(filepath.Join(os.TempDir(), "*_ns1_*.parquet"))
+	assert.Empty(t, tmpFiles)
+}
+
+func TestExportDataInvalid(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	_, err := em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{
+		Collections: []fftypes.DataExportCollection{"wrong"},
+	})
+	assert.Regexp(t, "FF10513.*collection 'wrong'", err)
+
+	_, err = em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{Format: "xml"})
+	assert.Regexp(t, "FF10513.*format 'xml'", err)
+
+	_, err = em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{Destination: "ftp"})
+	assert.Regexp(t, "FF10513.*destination 'ftp'", err)
+
+	_, err = em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{Destination: fftypes.DataExportDestinationSharedStorage})
+	assert.Regexp(t, "FF10513.*sharedstorage destination is not enabled", err)
+
+	_, err = em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{
+		Since: fftypes.UnixTime(2000),
+		Until: fftypes.UnixTime(1000),
+	})
+	assert.Regexp(t, "FF10513.*since", err)
+}
+
+func TestExportDataTransactionFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeDataExport).Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.ExportData(context.Background(), "ns1", &fftypes.DataExportRequest{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportDataFetchFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return errMsg == "FF10514: Failed to export events: pop"
+	}), mock.Anything).Return(nil)
+
+	em.exportData(context.Background(), "ns1", fftypes.NewUUID(), newTestExportRequest(fftypes.DataExportFormatCSV, fftypes.DataExportDestinationLocal))
+}
+
+func TestExportDataCreateFileFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	em.directory = filepath.Join(em.directory, "file")
+	err := ioutil.WriteFile(em.directory, []byte{}, 0644)
+	assert.NoError(t, err)
+
+	cs := &fftypes.DataExportCollectionStatus{Collection: fftypes.DataExportCollectionEvents}
+	err = em.exportCollection(context.Background(), "ns1", fftypes.NewUUID(), newTestExportRequest(fftypes.DataExportFormatCSV, fftypes.DataExportDestinationLocal), &fftypes.DataExportStatus{}, cs)
+	assert.Error(t, err)
+}
+
+func TestExportDataUploadFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mss := em.sharedstorage.(*sharedstoragemocks.Plugin)
+	mss.On("UploadData", mock.Anything, mock.Anything).Return("", fmt.Errorf("pop"))
+
+	cs := &fftypes.DataExportCollectionStatus{Collection: fftypes.DataExportCollectionEvents}
+	err := em.exportCollection(context.Background(), "ns1", fftypes.NewUUID(), newTestExportRequest(fftypes.DataExportFormatCSV, fftypes.DataExportDestinationSharedStorage), &fftypes.DataExportStatus{}, cs)
+	assert.EqualError(t, err, "pop")
+	assert.False(t, cs.Complete)
+}
+
+func TestUploadFileMissing(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	_, err := em.uploadFile(context.Background(), filepath.Join(em.directory, "missing"))
+	assert.Error(t, err)
+}
+
+func TestWriteCollectionRowFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(testEvents(1), nil, nil)
+
+	cs := &fftypes.DataExportCollectionStatus{Collection: fftypes.DataExportCollectionEvents}
+	err := em.writeCollection(context.Background(), &errorWriter{}, "ns1", fftypes.NewUUID(), newTestExportRequest(fftypes.DataExportFormatParquet, fftypes.DataExportDestinationLocal), &fftypes.DataExportStatus{}, cs)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestWriteCollectionCloseFail(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+	em.pageSize = 10
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(testEvents(1), nil, nil)
+
+	cs := &fftypes.DataExportCollectionStatus{Collection: fftypes.DataExportCollectionEvents}
+	err := em.writeCollection(context.Background(), &errorWriter{}, "ns1", fftypes.NewUUID(), newTestExportRequest(fftypes.DataExportFormatCSV, fftypes.DataExportDestinationLocal), &fftypes.DataExportStatus{}, cs)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestValidateRequestDefaultUntil(t *testing.T) {
+	em, done := newTestExportManager(t)
+	defer done()
+
+	before := time.Now()
+	req := &fftypes.DataExportRequest{}
+	err := em.validateRequest(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, allCollections, req.Collections)
+	assert.False(t, req.Until.Time().Before(before))
+}
@@ -0,0 +1,343 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// The subset of the Apache Parquet format needed to write flat files of nullable columns:
+// uncompressed, PLAIN encoded values with RLE/bit-packed definition levels, in V1 data pages.
+// The file metadata is encoded with the Thrift compact protocol, as required by the specification.
+// See https://github.com/apache/parquet-format
+
+var parquetMagic = []byte("PAR1")
+
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRepetitionOptional = 1
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageTypeData      = 0
+
+	parquetCreatedBy = "hyperledger-firefly"
+)
+
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol
+type thriftWriter struct {
+	buf       bytes.Buffer
+	lastField []int16
+}
+
+func (tw *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	tw.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (tw *thriftWriter) zigzag(v int64) {
+	tw.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (tw *thriftWriter) fieldHeader(id int16, t byte) {
+	last := tw.lastField[len(tw.lastField)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		tw.buf.WriteByte(byte(delta)<<4 | t)
+	} else {
+		tw.buf.WriteByte(t)
+		tw.zigzag(int64(id))
+	}
+	tw.lastField[len(tw.lastField)-1] = id
+}
+
+func (tw *thriftWriter) structBegin() {
+	tw.lastField = append(tw.lastField, 0)
+}
+
+func (tw *thriftWriter) structEnd() {
+	tw.buf.WriteByte(0)
+	tw.lastField = tw.lastField[:len(tw.lastField)-1]
+}
+
+func (tw *thriftWriter) fieldStruct(id int16) {
+	tw.fieldHeader(id, thriftTypeStruct)
+	tw.structBegin()
+}
+
+func (tw *thriftWriter) fieldI32(id int16, v int32) {
+	tw.fieldHeader(id, thriftTypeI32)
+	tw.zigzag(int64(v))
+}
+
+func (tw *thriftWriter) fieldI64(id int16, v int64) {
+	tw.fieldHeader(id, thriftTypeI64)
+	tw.zigzag(v)
+}
+
+func (tw *thriftWriter) binary(v string) {
+	tw.varint(uint64(len(v)))
+	tw.buf.WriteString(v)
+}
+
+func (tw *thriftWriter) fieldBinary(id int16, v string) {
+	tw.fieldHeader(id, thriftTypeBinary)
+	tw.binary(v)
+}
+
+func (tw *thriftWriter) fieldList(id int16, elemType byte, size int) {
+	tw.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		tw.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		tw.buf.WriteByte(0xf0 | elemType)
+		tw.varint(uint64(size))
+	}
+}
+
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type parquetRowGroup struct {
+	columns []*parquetColumnChunk
+	numRows int64
+}
+
+// parquetWriter buffers rows into row groups, writing each group to the output once it is full
+type parquetWriter struct {
+	out          io.Writer
+	offset       int64
+	columns      []*exportColumn
+	rowGroupSize int
+	rows         [][]interface{}
+	rowGroups    []*parquetRowGroup
+	numRows      int64
+}
+
+func newParquetWriter(out io.Writer, columns []*exportColumn, rowGroupSize int) *parquetWriter {
+	return &parquetWriter{
+		out:          out,
+		columns:      columns,
+		rowGroupSize: rowGroupSize,
+	}
+}
+
+func (pw *parquetWriter) write(b []byte) error {
+	n, err := pw.out.Write(b)
+	pw.offset += int64(n)
+	return err
+}
+
+func (pw *parquetWriter) writeRow(values []interface{}) error {
+	pw.rows = append(pw.rows, values)
+	if len(pw.rows) >= pw.rowGroupSize {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+func (pw *parquetWriter) flushRowGroup() error {
+	if pw.offset == 0 {
+		if err := pw.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+	if len(pw.rows) == 0 {
+		return nil
+	}
+	rg := &parquetRowGroup{numRows: int64(len(pw.rows))}
+	for i, col := range pw.columns {
+		chunk, err := pw.writeColumnChunk(i, col)
+		if err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, chunk)
+	}
+	pw.rowGroups = append(pw.rowGroups, rg)
+	pw.numRows += rg.numRows
+	pw.rows = pw.rows[:0]
+	return nil
+}
+
+// writeColumnChunk writes all the values of a column in the buffered rows as a single data page
+func (pw *parquetWriter) writeColumnChunk(idx int, col *exportColumn) (*parquetColumnChunk, error) {
+	var values bytes.Buffer
+	levels := make([]byte, (len(pw.rows)+7)/8)
+	for r, row := range pw.rows {
+		v := row[idx]
+		if v == nil {
+			continue
+		}
+		levels[r/8] |= 1 << (r % 8)
+		switch col.columnType {
+		case columnTypeInt64, columnTypeTimestamp:
+			_ = binary.Write(&values, binary.LittleEndian, parquetInt64(v))
+		default:
+			s := v.(string)
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(s)))
+			values.WriteString(s)
+		}
+	}
+
+	// A single bit-packed run of definition levels, prefixed with its length
+	var run thriftWriter
+	run.varint(uint64(len(levels))<<1 | 1)
+	run.buf.Write(levels)
+	var page bytes.Buffer
+	_ = binary.Write(&page, binary.LittleEndian, uint32(run.buf.Len()))
+	page.Write(run.buf.Bytes())
+	page.Write(values.Bytes())
+
+	header := &thriftWriter{}
+	header.structBegin()
+	header.fieldI32(1, parquetPageTypeData)
+	header.fieldI32(2, int32(page.Len()))
+	header.fieldI32(3, int32(page.Len()))
+	header.fieldStruct(5)
+	header.fieldI32(1, int32(len(pw.rows)))
+	header.fieldI32(2, parquetEncodingPlain)
+	header.fieldI32(3, parquetEncodingRLE)
+	header.fieldI32(4, parquetEncodingRLE)
+	header.structEnd()
+	header.structEnd()
+
+	chunk := &parquetColumnChunk{
+		offset:    pw.offset,
+		size:      int64(header.buf.Len() + page.Len()),
+		numValues: int64(len(pw.rows)),
+	}
+	if err := pw.write(header.buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return chunk, pw.write(page.Bytes())
+}
+
+func parquetInt64(v interface{}) int64 {
+	if t, ok := v.(*fftypes.FFTime); ok {
+		return t.UnixNano() / 1000
+	}
+	return v.(int64)
+}
+
+func (pw *parquetWriter) close() error {
+	if err := pw.flushRowGroup(); err != nil {
+		return err
+	}
+	footer := pw.fileMetadata()
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if err := pw.write(length[:]); err != nil {
+		return err
+	}
+	return pw.write(parquetMagic)
+}
+
+func (pw *parquetWriter) fileMetadata() []byte {
+	tw := &thriftWriter{}
+	tw.structBegin()
+	tw.fieldI32(1, 1)
+
+	tw.fieldList(2, thriftTypeStruct, len(pw.columns)+1)
+	tw.structBegin()
+	tw.fieldBinary(4, "schema")
+	tw.fieldI32(5, int32(len(pw.columns)))
+	tw.structEnd()
+	for _, col := range pw.columns {
+		tw.structBegin()
+		switch col.columnType {
+		case columnTypeInt64:
+			tw.fieldI32(1, parquetTypeInt64)
+			tw.fieldI32(3, parquetRepetitionOptional)
+			tw.fieldBinary(4, col.name)
+		case columnTypeTimestamp:
+			tw.fieldI32(1, parquetTypeInt64)
+			tw.fieldI32(3, parquetRepetitionOptional)
+			tw.fieldBinary(4, col.name)
+			tw.fieldI32(6, parquetConvertedTimestampMicros)
+		default:
+			tw.fieldI32(1, parquetTypeByteArray)
+			tw.fieldI32(3, parquetRepetitionOptional)
+			tw.fieldBinary(4, col.name)
+			tw.fieldI32(6, parquetConvertedUTF8)
+		}
+		tw.structEnd()
+	}
+
+	tw.fieldI64(3, pw.numRows)
+
+	tw.fieldList(4, thriftTypeStruct, len(pw.rowGroups))
+	for _, rg := range pw.rowGroups {
+		tw.structBegin()
+		tw.fieldList(1, thriftTypeStruct, len(rg.columns))
+		var totalSize int64
+		for i, chunk := range rg.columns {
+			col := pw.columns[i]
+			totalSize += chunk.size
+			tw.structBegin()
+			tw.fieldI64(2, chunk.offset)
+			tw.fieldStruct(3)
+			if col.columnType == columnTypeString {
+				tw.fieldI32(1, parquetTypeByteArray)
+			} else {
+				tw.fieldI32(1, parquetTypeInt64)
+			}
+			tw.fieldList(2, thriftTypeI32, 2)
+			tw.zigzag(parquetEncodingPlain)
+			tw.zigzag(parquetEncodingRLE)
+			tw.fieldList(3, thriftTypeBinary, 1)
+			tw.binary(col.name)
+			tw.fieldI32(4, parquetCodecUncompressed)
+			tw.fieldI64(5, chunk.numValues)
+			tw.fieldI64(6, chunk.size)
+			tw.fieldI64(7, chunk.size)
+			tw.fieldI64(9, chunk.offset)
+			tw.structEnd()
+			tw.structEnd()
+		}
+		tw.fieldI64(2, totalSize)
+		tw.fieldI64(3, rg.numRows)
+		tw.structEnd()
+	}
+
+	tw.fieldBinary(6, parquetCreatedBy)
+	tw.structEnd()
+	return tw.buf.Bytes()
+}
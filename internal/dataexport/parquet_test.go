@@ -0,0 +1,312 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+type errorWriter struct {
+	okWrites int
+}
+
+func (ew *errorWriter) Write(b []byte) (int, error) {
+	if ew.okWrites == 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	ew.okWrites--
+	return len(b), nil
+}
+
+var testColumns = []*exportColumn{
+	{"sequence", columnTypeInt64},
+	{"name", columnTypeString},
+	{"created", columnTypeTimestamp},
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buff bytes.Buffer
+	pw := newParquetWriter(&buff, testColumns, 2)
+	for i := 0; i < 3; i++ {
+		var name interface{} = fmt.Sprintf("name%d", i)
+		if i == 1 {
+			name = nil
+		}
+		err := pw.writeRow([]interface{}{int64(i), name, fftypes.UnixTime(int64(i))})
+		assert.NoError(t, err)
+	}
+	err := pw.close()
+	assert.NoError(t, err)
+
+	b := buff.Bytes()
+	assert.Equal(t, parquetMagic, b[0:4])
+	assert.Equal(t, parquetMagic, b[len(b)-4:])
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	assert.Equal(t, int64(len(b)-8-footerLen), pw.offset-int64(8+footerLen))
+	footer := b[len(b)-8-footerLen : len(b)-8]
+	assert.Contains(t, string(footer), "sequence")
+	assert.Contains(t, string(footer), parquetCreatedBy)
+	assert.Contains(t, string(b), "name0")
+	assert.Contains(t, string(b), "name2")
+	assert.Len(t, pw.rowGroups, 2)
+	assert.Equal(t, int64(3), pw.numRows)
+
+	// The first value of the first column chunk, after the page header and definition levels
+	first := pw.rowGroups[0].columns[0]
+	page := b[first.offset : first.offset+first.size]
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}, page[len(page)-16:])
+}
+
+func TestParquetWriterEmpty(t *testing.T) {
+	var buff bytes.Buffer
+	pw := newParquetWriter(&buff, testColumns, 2)
+	err := pw.close()
+	assert.NoError(t, err)
+	b := buff.Bytes()
+	assert.Equal(t, parquetMagic, b[0:4])
+	assert.Equal(t, parquetMagic, b[len(b)-4:])
+	assert.Empty(t, pw.rowGroups)
+}
+
+func TestParquetWriterManyColumns(t *testing.T) {
+	columns := make([]*exportColumn, 20)
+	row := make([]interface{}, 20)
+	for i := range columns {
+		columns[i] = &exportColumn{fmt.Sprintf("col%d", i), columnTypeString}
+		row[i] = "value"
+	}
+	var buff bytes.Buffer
+	pw := newParquetWriter(&buff, columns, 10)
+	err := pw.writeRow(row)
+	assert.NoError(t, err)
+	err = pw.close()
+	assert.NoError(t, err)
+	assert.Contains(t, buff.String(), "col19")
+}
+
+func TestParquetWriterFieldIDJump(t *testing.T) {
+	tw := &thriftWriter{}
+	tw.structBegin()
+	tw.fieldI32(20, 1)
+	tw.structEnd()
+	assert.Equal(t, []byte{thriftTypeI32, 40, 2, 0}, tw.buf.Bytes())
+}
+
+func TestParquetWriterFailMagic(t *testing.T) {
+	pw := newParquetWriter(&errorWriter{}, testColumns, 1)
+	err := pw.writeRow([]interface{}{int64(1), "a", nil})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestParquetWriterFailPageHeader(t *testing.T) {
+	pw := newParquetWriter(&errorWriter{okWrites: 1}, testColumns, 1)
+	err := pw.writeRow([]interface{}{int64(1), "a", nil})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestParquetWriterFailCloseFlush(t *testing.T) {
+	pw := newParquetWriter(&errorWriter{}, testColumns, 2)
+	err := pw.close()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestParquetWriterFailFooter(t *testing.T) {
+	pw := newParquetWriter(&errorWriter{okWrites: 1}, testColumns, 2)
+	err := pw.close()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestParquetWriterFailFooterLength(t *testing.T) {
+	pw := newParquetWriter(&errorWriter{okWrites: 2}, testColumns, 2)
+	err := pw.close()
+	assert.EqualError(t, err, "pop")
+}
+
+// thriftReader decodes the Thrift compact protocol independently of the writer, so the tests check
+// the files against the specification rather than against the writer itself
+type thriftReader struct {
+	t *testing.T
+	b []byte
+}
+
+func (tr *thriftReader) byte() byte {
+	v := tr.b[0]
+	tr.b = tr.b[1:]
+	return v
+}
+
+func (tr *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(tr.b)
+	assert.Greater(tr.t, n, 0)
+	tr.b = tr.b[n:]
+	return v
+}
+
+func (tr *thriftReader) zigzag() int64 {
+	v := tr.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (tr *thriftReader) value(t byte) interface{} {
+	switch t {
+	case thriftTypeI32, thriftTypeI64:
+		return tr.zigzag()
+	case thriftTypeBinary:
+		l := tr.varint()
+		v := string(tr.b[:l])
+		tr.b = tr.b[l:]
+		return v
+	case thriftTypeList:
+		h := tr.byte()
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(tr.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = tr.value(h & 0x0f)
+		}
+		return list
+	case thriftTypeStruct:
+		return tr.structValue()
+	}
+	tr.t.Fatalf("unexpected thrift type %d", t)
+	return nil
+}
+
+func (tr *thriftReader) structValue() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		h := tr.byte()
+		if h == 0 {
+			return fields
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(tr.zigzag())
+		}
+		fields[id] = tr.value(h & 0x0f)
+		last = id
+	}
+}
+
+func field(t *testing.T, s interface{}, ids ...int16) interface{} {
+	for _, id := range ids {
+		v, ok := s.(map[int16]interface{})[id]
+		assert.True(t, ok, "missing field %d", id)
+		s = v
+	}
+	return s
+}
+
+// readParquet decodes a file written by the writer back into its rows, checking the metadata along the way
+func readParquet(t *testing.T, b []byte, columns []*exportColumn) [][]interface{} {
+	assert.Equal(t, parquetMagic, b[0:4])
+	assert.Equal(t, parquetMagic, b[len(b)-4:])
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	meta := (&thriftReader{t: t, b: b[len(b)-8-footerLen : len(b)-8]}).structValue()
+
+	assert.Equal(t, int64(1), field(t, meta, 1))
+	schema := field(t, meta, 2).([]interface{})
+	assert.Len(t, schema, len(columns)+1)
+	assert.Equal(t, int64(len(columns)), field(t, schema[0], 5))
+	for i, col := range columns {
+		assert.Equal(t, col.name, field(t, schema[i+1], 4))
+		assert.Equal(t, int64(parquetRepetitionOptional), field(t, schema[i+1], 3))
+	}
+
+	var rows [][]interface{}
+	for _, rg := range field(t, meta, 4).([]interface{}) {
+		numRows := int(field(t, rg, 3).(int64))
+		groupRows := make([][]interface{}, numRows)
+		for r := range groupRows {
+			groupRows[r] = make([]interface{}, len(columns))
+		}
+		chunks := field(t, rg, 1).([]interface{})
+		assert.Len(t, chunks, len(columns))
+		for c, chunk := range chunks {
+			cm := field(t, chunk, 3)
+			assert.Equal(t, []interface{}{columns[c].name}, field(t, cm, 3))
+			assert.Equal(t, int64(parquetCodecUncompressed), field(t, cm, 4))
+			assert.Equal(t, int64(numRows), field(t, cm, 5))
+			offset := field(t, cm, 9).(int64)
+			size := field(t, cm, 7).(int64)
+
+			tr := &thriftReader{t: t, b: b[offset : offset+size]}
+			header := tr.structValue()
+			assert.Equal(t, int64(parquetPageTypeData), field(t, header, 1))
+			assert.Equal(t, int64(len(tr.b)), field(t, header, 3))
+			assert.Equal(t, int64(numRows), field(t, header, 5, 1))
+			assert.Equal(t, int64(parquetEncodingPlain), field(t, header, 5, 2))
+
+			// Definition levels are a length prefixed RLE/bit-packed hybrid, with a bit width of 1
+			levelsLen := binary.LittleEndian.Uint32(tr.b)
+			levels := &thriftReader{t: t, b: tr.b[4 : 4+levelsLen]}
+			values := tr.b[4+levelsLen:]
+			runHeader := levels.varint()
+			assert.Equal(t, uint64(1), runHeader&1, "expected a bit-packed run")
+			assert.Equal(t, int((runHeader>>1)*8), (numRows+7)/8*8)
+			for r := 0; r < numRows; r++ {
+				if levels.b[r/8]&(1<<(r%8)) == 0 {
+					continue
+				}
+				switch field(t, cm, 1) {
+				case int64(parquetTypeInt64):
+					groupRows[r][c] = int64(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case int64(parquetTypeByteArray):
+					l := binary.LittleEndian.Uint32(values)
+					groupRows[r][c] = string(values[4 : 4+l])
+					values = values[4+l:]
+				}
+			}
+			assert.Empty(t, values)
+		}
+		rows = append(rows, groupRows...)
+	}
+	assert.Equal(t, int64(len(rows)), field(t, meta, 3))
+	return rows
+}
+
+func TestParquetWriterReadBack(t *testing.T) {
+	var buff bytes.Buffer
+	columns := append(testColumns, &exportColumn{"topics", columnTypeString})
+	pw := newParquetWriter(&buff, columns, 7)
+	var expected [][]interface{}
+	for i := 0; i < 20; i++ {
+		var name interface{} = fmt.Sprintf("name%d", i)
+		if i%3 == 0 {
+			name = nil
+		}
+		created := fftypes.UnixTime(int64(1000 + i))
+		err := pw.writeRow([]interface{}{int64(i - 5), name, created, ""})
+		assert.NoError(t, err)
+		expected = append(expected, []interface{}{int64(i - 5), name, created.UnixNano() / 1000, ""})
+	}
+	err := pw.close()
+	assert.NoError(t, err)
+
+	rows := readParquet(t, buff.Bytes(), columns)
+	assert.Equal(t, expected, rows)
+}
@@ -0,0 +1,70 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// rowWriter writes the rows of a collection to a file in one of the export formats
+type rowWriter interface {
+	writeRow(values []interface{}) error
+	close() error
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(out io.Writer, columns []*exportColumn) *csvWriter {
+	cw := &csvWriter{
+		w:      csv.NewWriter(out),
+		record: make([]string, len(columns)),
+	}
+	for i, col := range columns {
+		cw.record[i] = col.name
+	}
+	// The header is buffered, so any error writing it is returned by close
+	_ = cw.w.Write(cw.record)
+	return cw
+}
+
+func (cw *csvWriter) writeRow(values []interface{}) error {
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			cw.record[i] = ""
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case *fftypes.FFTime:
+			cw.record[i] = v.String()
+		default:
+			cw.record[i] = v.(string)
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,43 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buff bytes.Buffer
+	cw := newCSVWriter(&buff, testColumns)
+	err := cw.writeRow([]interface{}{int64(1), "a,b", fftypes.UnixTime(0)})
+	assert.NoError(t, err)
+	err = cw.writeRow([]interface{}{int64(2), nil, nil})
+	assert.NoError(t, err)
+	err = cw.close()
+	assert.NoError(t, err)
+	assert.Equal(t, "sequence,name,created\n1,\"a,b\",1970-01-01T00:00:00Z\n2,,\n", buff.String())
+}
+
+func TestCSVWriterFail(t *testing.T) {
+	cw := newCSVWriter(&errorWriter{}, testColumns)
+	err := cw.close()
+	assert.EqualError(t, err, "pop")
+}
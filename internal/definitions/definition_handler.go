@@ -19,6 +19,7 @@ package definitions
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
 	"github.com/hyperledger/firefly/internal/assets"
 	"github.com/hyperledger/firefly/internal/broadcast"
@@ -30,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
+	ffdefinitions "github.com/hyperledger/firefly/pkg/definitions"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -38,61 +40,26 @@ type DefinitionHandlers interface {
 	privatemessaging.GroupManager
 
 	HandleDefinitionBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error)
-	SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut)
+	HandleIdentityPrivateProfile(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray) (HandlerResult, error)
+	SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut, replyTo *fftypes.ReplyRouting)
+	RegisterCustomHandler(ctx context.Context, tag string, handler CustomHandler) error
 }
 
-type HandlerResult struct {
-	Action           DefinitionMessageAction
-	CustomCorrelator *fftypes.UUID
-}
-
-// DefinitionMessageAction is the action to be taken on an individual definition message
-type DefinitionMessageAction int
+// The types shared with custom definition handlers are defined in pkg/definitions, so they can be implemented by extensions
+type (
+	HandlerResult           = ffdefinitions.HandlerResult
+	DefinitionMessageAction = ffdefinitions.DefinitionMessageAction
+	DefinitionBatchState    = ffdefinitions.DefinitionBatchState
+	CustomHandler           = ffdefinitions.CustomHandler
+)
 
 const (
-	// ActionReject the message was successfully processed, but was malformed/invalid and should be marked as rejected
-	ActionReject DefinitionMessageAction = iota
-
-	// ActionConfirm the message was valid and should be confirmed
-	ActionConfirm
-
-	// ActionRetry a recoverable error was encountered - batch should be halted and then re-processed from the start
-	ActionRetry
-
-	// ActionWait the message is still awaiting further pieces for aggregation and should be held in pending state
-	ActionWait
+	ActionReject  = ffdefinitions.ActionReject
+	ActionConfirm = ffdefinitions.ActionConfirm
+	ActionRetry   = ffdefinitions.ActionRetry
+	ActionWait    = ffdefinitions.ActionWait
 )
 
-func (dma DefinitionMessageAction) String() string {
-	switch dma {
-	case ActionReject:
-		return "reject"
-	case ActionConfirm:
-		return "confirm"
-	case ActionRetry:
-		return "retry"
-	case ActionWait:
-		return "wait"
-	default:
-		return "unknown"
-	}
-}
-
-// DefinitionBatchState tracks the state between definition handlers that run in-line on the pin processing route in the
-// aggregator as part of a batch of pins. They might have complex API calls, and interdependencies, that need to be managed via this state.
-// The actions to be taken at the end of a definition batch.
-// See further notes on "batchState" in the event aggregator
-type DefinitionBatchState interface {
-	// PreFinalize may perform a blocking action (possibly to an external connector) that should execute outside database RunAsGroup
-	AddPreFinalize(func(ctx context.Context) error)
-
-	// Finalize may perform final, non-idempotent database operations (such as inserting Events)
-	AddFinalize(func(ctx context.Context) error)
-
-	// GetPendingConfirm returns a map of messages are that pending confirmation after already being processed in this batch
-	GetPendingConfirm() map[fftypes.UUID]*fftypes.Message
-}
-
 type definitionHandlers struct {
 	database   database.Plugin
 	blockchain blockchain.Plugin
@@ -103,19 +70,23 @@ type definitionHandlers struct {
 	messaging  privatemessaging.Manager
 	assets     assets.Manager
 	contracts  contracts.Manager
+
+	customLock     sync.RWMutex
+	customHandlers map[string]CustomHandler
 }
 
 func NewDefinitionHandlers(di database.Plugin, bi blockchain.Plugin, dx dataexchange.Plugin, dm data.Manager, im identity.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, cm contracts.Manager) DefinitionHandlers {
 	return &definitionHandlers{
-		database:   di,
-		blockchain: bi,
-		exchange:   dx,
-		data:       dm,
-		identity:   im,
-		broadcast:  bm,
-		messaging:  pm,
-		assets:     am,
-		contracts:  cm,
+		database:       di,
+		blockchain:     bi,
+		exchange:       dx,
+		data:           dm,
+		identity:       im,
+		broadcast:      bm,
+		messaging:      pm,
+		assets:         am,
+		contracts:      cm,
+		customHandlers: make(map[string]CustomHandler),
 	}
 }
 
@@ -160,6 +131,9 @@ func (dh *definitionHandlers) HandleDefinitionBroadcast(ctx context.Context, sta
 	case fftypes.SystemTagDefineContractAPI:
 		return dh.handleContractAPIBroadcast(ctx, state, msg, data, tx)
 	default:
+		if handler := dh.getCustomHandler(msg.Header.Tag); handler != nil {
+			return handler.HandleDefinition(ctx, state, msg, data, tx)
+		}
 		l.Warnf("Unknown SystemTag '%s' for definition ID '%s'", msg.Header.Tag, msg.Header.ID)
 		return HandlerResult{Action: ActionReject}, nil
 	}
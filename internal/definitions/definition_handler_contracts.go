@@ -48,6 +48,13 @@ func (dh *definitionHandlers) persistFFI(ctx context.Context, ffi *fftypes.FFI)
 		}
 	}
 
+	for _, errorDef := range ffi.Errors {
+		err := dh.database.UpsertFFIError(ctx, errorDef)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	return true, nil
 }
 
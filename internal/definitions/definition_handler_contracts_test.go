@@ -125,6 +125,25 @@ func TestPersistFFIValidateFFIFail(t *testing.T) {
 	mcm.AssertExpectations(t)
 }
 
+func TestPersistFFIUpsertFFIErrorFail(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mcm := dh.contracts.(*contractmocks.Manager)
+	mcm.On("ValidateFFIAndSetPathnames", mock.Anything, mock.Anything).Return(nil)
+	ffi := testFFI()
+	ffi.Errors = []*fftypes.FFIError{
+		{ID: fftypes.NewUUID(), FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "Unauthorized"}},
+	}
+	_, err := dh.persistFFI(context.Background(), ffi)
+	assert.EqualError(t, err, "pop")
+	mbi.AssertExpectations(t)
+	mcm.AssertExpectations(t)
+}
+
 func TestHandleFFIBroadcastReject(t *testing.T) {
 	dh, bs := newTestDefinitionHandlers(t)
 	mbi := dh.database.(*databasemocks.Plugin)
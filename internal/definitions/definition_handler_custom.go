@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// RegisterCustomHandler registers a handler for definition broadcasts with the given tag, such as "myapp:config".
+// Handlers are registered by the orchestrator before the event manager is started, so that no definitions are
+// rejected as unknown while the node is catching up.
+func (dh *definitionHandlers) RegisterCustomHandler(ctx context.Context, tag string, handler CustomHandler) error {
+	if err := fftypes.ValidateCustomDefinitionTag(ctx, tag); err != nil {
+		return err
+	}
+
+	dh.customLock.Lock()
+	defer dh.customLock.Unlock()
+	if _, exists := dh.customHandlers[tag]; exists {
+		return i18n.NewError(ctx, i18n.MsgDefinitionHandlerRegistered, tag)
+	}
+	dh.customHandlers[tag] = handler
+	return nil
+}
+
+func (dh *definitionHandlers) getCustomHandler(tag string) CustomHandler {
+	dh.customLock.RLock()
+	defer dh.customLock.RUnlock()
+	return dh.customHandlers[tag]
+}
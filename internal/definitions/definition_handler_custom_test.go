@@ -0,0 +1,98 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCustomHandler struct {
+	result HandlerResult
+	err    error
+	called []*fftypes.Message
+}
+
+func (ch *testCustomHandler) HandleDefinition(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error) {
+	ch.called = append(ch.called, msg)
+	return ch.result, ch.err
+}
+
+func TestHandleDefinitionBroadcastCustom(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	ch := &testCustomHandler{result: HandlerResult{Action: ActionWait}}
+	err := dh.RegisterCustomHandler(context.Background(), "myapp:config", ch)
+	assert.NoError(t, err)
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:  fftypes.NewUUID(),
+			Tag: "myapp:config",
+		},
+	}
+	data := fftypes.DataArray{{ID: fftypes.NewUUID()}}
+	tx := fftypes.NewUUID()
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, tx)
+	assert.Equal(t, HandlerResult{Action: ActionWait}, action)
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.Message{msg}, ch.called)
+}
+
+func TestHandleDefinitionBroadcastCustomRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	ch := &testCustomHandler{result: HandlerResult{Action: ActionRetry}, err: fmt.Errorf("pop")}
+	err := dh.RegisterCustomHandler(context.Background(), "myapp:config", ch)
+	assert.NoError(t, err)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: "myapp:config",
+		},
+	}, fftypes.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+	assert.Len(t, ch.called, 1)
+}
+
+func TestRegisterCustomHandlerInvalidTag(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	err := dh.RegisterCustomHandler(context.Background(), "", &testCustomHandler{})
+	assert.Regexp(t, "FF10131", err)
+	err = dh.RegisterCustomHandler(context.Background(), "myapp:config:v1", &testCustomHandler{})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestRegisterCustomHandlerReservedTag(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	err := dh.RegisterCustomHandler(context.Background(), fftypes.SystemTagDefineDatatype, &testCustomHandler{})
+	assert.Regexp(t, "FF10384", err)
+}
+
+func TestRegisterCustomHandlerDuplicate(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	err := dh.RegisterCustomHandler(context.Background(), "myapp:config", &testCustomHandler{})
+	assert.NoError(t, err)
+	err = dh.RegisterCustomHandler(context.Background(), "myapp:config", &testCustomHandler{})
+	assert.Regexp(t, "FF10385", err)
+}
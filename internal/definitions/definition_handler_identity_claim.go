@@ -60,7 +60,8 @@ func (dh *definitionHandlers) verifyClaimSignature(ctx context.Context, msg *fft
 	return valid
 }
 
-func (dh *definitionHandlers) getClaimVerifier(msg *fftypes.Message, identity *fftypes.Identity) *fftypes.Verifier {
+func (dh *definitionHandlers) getClaimVerifier(msg *fftypes.Message, identityClaim *fftypes.IdentityClaim) *fftypes.Verifier {
+	identity := identityClaim.Identity
 	verifier := &fftypes.Verifier{
 		Identity:  identity.ID,
 		Namespace: identity.Namespace,
@@ -72,6 +73,8 @@ func (dh *definitionHandlers) getClaimVerifier(msg *fftypes.Message, identity *f
 	default:
 		verifier.VerifierRef.Type = dh.blockchain.VerifierType()
 		verifier.VerifierRef.Value = msg.Header.Key
+		// Only child identities can be authorized to pin on behalf of a parent
+		verifier.Pinning = identityClaim.Pinning && identity.Parent != nil
 	}
 	verifier.Seal()
 	return verifier
@@ -147,6 +150,14 @@ func (dh *definitionHandlers) handleIdentityClaim(ctx context.Context, state Def
 		return HandlerResult{Action: ActionReject}, nil
 	}
 
+	if retry, err := dh.identity.ValidateProfile(ctx, identity); err != nil {
+		if retry {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		l.Warnf("Unable to process identity claim %s: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
 	existingIdentity, err := dh.database.GetIdentityByName(ctx, identity.Type, identity.Namespace, identity.Name)
 	if err == nil && existingIdentity == nil {
 		existingIdentity, err = dh.database.GetIdentityByID(ctx, identity.ID)
@@ -161,7 +172,7 @@ func (dh *definitionHandlers) handleIdentityClaim(ctx context.Context, state Def
 	}
 
 	// Check uniquness of verifier
-	verifier := dh.getClaimVerifier(msg, identity)
+	verifier := dh.getClaimVerifier(msg, identityClaim)
 	existingVerifier, err := dh.database.GetVerifierByValue(ctx, verifier.Type, identity.Namespace, verifier.Value)
 	if err != nil {
 		return HandlerResult{Action: ActionRetry}, err // retry database errors
@@ -144,6 +144,7 @@ func TestHandleDefinitionIdentityClaimCustomWithExistingParentVerificationOk(t *
 	custom1, org1, claimMsg, claimData, verifyMsg, verifyData := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -194,6 +195,7 @@ func TestHandleDefinitionIdentityClaimIdempotentReplay(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, verifyData := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -239,6 +241,7 @@ func TestHandleDefinitionIdentityClaimFailInsertIdentity(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, verifyData := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -271,6 +274,7 @@ func TestHandleDefinitionIdentityClaimVerificationDataFail(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -301,6 +305,7 @@ func TestHandleDefinitionIdentityClaimVerificationMissingData(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -331,6 +336,7 @@ func TestHandleDefinitionIdentityClaimFailInsertVerifier(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, verifyData := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -362,6 +368,7 @@ func TestHandleDefinitionIdentityClaimCustomMissingParentVerificationOk(t *testi
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -386,6 +393,7 @@ func TestHandleDefinitionIdentityClaimCustomParentVerificationFail(t *testing.T)
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -410,6 +418,7 @@ func TestHandleDefinitionIdentityClaimVerifierClash(t *testing.T) {
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -435,6 +444,7 @@ func TestHandleDefinitionIdentityClaimVerifierError(t *testing.T) {
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -458,6 +468,7 @@ func TestHandleDefinitionIdentityClaimIdentityClash(t *testing.T) {
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -483,6 +494,7 @@ func TestHandleDefinitionIdentityClaimIdentityError(t *testing.T) {
 	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -498,6 +510,42 @@ func TestHandleDefinitionIdentityClaimIdentityError(t *testing.T) {
 	bs.assertNoFinalizers()
 }
 
+func TestHandleDefinitionIdentityClaimInvalidProfile(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, fmt.Errorf("pop"))
+	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, claimMsg, fftypes.DataArray{claimData}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDefinitionIdentityClaimValidateProfileRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	custom1, org1, claimMsg, claimData, _, _ := testCustomClaimAndVerification(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(true, fmt.Errorf("pop"))
+	mim.On("VerifyIdentityChain", ctx, custom1).Return(org1, false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, claimMsg, fftypes.DataArray{claimData}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
 func TestHandleDefinitionIdentityMissingAuthor(t *testing.T) {
 	dh, bs := newTestDefinitionHandlers(t)
 	ctx := context.Background()
@@ -583,3 +631,17 @@ func TestHandleDefinitionIdentityClaimBadData(t *testing.T) {
 
 	bs.assertNoFinalizers()
 }
+
+func TestGetClaimVerifierPinning(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+
+	custom1, org1, claimMsg, _, _, _ := testCustomClaimAndVerification(t)
+
+	verifier := dh.getClaimVerifier(claimMsg, &fftypes.IdentityClaim{Identity: custom1, Pinning: true})
+	assert.True(t, verifier.Pinning)
+	assert.Equal(t, "0x12345", verifier.Value)
+
+	// Root orgs cannot authorize themselves
+	verifier = dh.getClaimVerifier(claimMsg, &fftypes.IdentityClaim{Identity: org1, Pinning: true})
+	assert.False(t, verifier.Pinning)
+}
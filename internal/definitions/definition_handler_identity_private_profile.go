@@ -0,0 +1,65 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// HandleIdentityPrivateProfile stores the private profile fields an identity has shared with this node.
+// The identity might not yet be confirmed on this node, as the claim is sequenced separately to the private
+// message - so the author is recorded, and checked against the identity when the fields are merged on read.
+func (dh *definitionHandlers) HandleIdentityPrivateProfile(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray) (HandlerResult, error) {
+	l := log.L(ctx)
+	if len(data) != 1 || data[0].Value == nil {
+		l.Warnf("Unable to process private profile %s - expecting 1 attachment, found %d", msg.Header.ID, len(data))
+		return HandlerResult{Action: ActionReject}, nil
+	}
+	var private fftypes.IdentityPrivateProfile
+	if err := json.Unmarshal(data[0].Value.Bytes(), &private); err != nil || private.Identity == nil {
+		l.Warnf("Unable to process private profile %s - invalid payload: %v", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	identity, err := dh.identity.CachedIdentityLookupByID(ctx, private.Identity)
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+	if identity != nil && identity.DID != msg.Header.Author {
+		l.Warnf("Unable to process private profile %s - wrong author: %s", msg.Header.ID, msg.Header.Author)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	private.Author = msg.Header.Author
+	private.Message = msg.Header.ID
+	private.Updated = fftypes.Now()
+	if err = dh.database.UpsertIdentityPrivateProfile(ctx, &private); err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+
+	if identity != nil {
+		state.AddFinalize(func(ctx context.Context) error {
+			event := fftypes.NewEvent(fftypes.EventTypeIdentityUpdated, identity.Namespace, identity.ID, nil, fftypes.SystemTopicDefinitions)
+			return dh.database.InsertEvent(ctx, event)
+		})
+	}
+	return HandlerResult{Action: ActionConfirm}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testPrivateProfile(t *testing.T) (*fftypes.Identity, *fftypes.Message, *fftypes.Data) {
+	org1 := testOrgIdentity(t, "org1")
+
+	b, err := json.Marshal(&fftypes.IdentityPrivateProfile{
+		Identity: org1.ID,
+		Profile:  fftypes.JSONObject{"email": "org1@example.com"},
+	})
+	assert.NoError(t, err)
+	data := &fftypes.Data{
+		ID:    fftypes.NewUUID(),
+		Value: fftypes.JSONAnyPtrBytes(b),
+	}
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.MessageTypePrivate,
+			Tag:  fftypes.SystemTagIdentityPrivateProfile,
+			SignerRef: fftypes.SignerRef{
+				Author: org1.DID,
+				Key:    "0x12345",
+			},
+		},
+	}
+
+	return org1, msg, data
+}
+
+func TestHandleIdentityPrivateProfileOk(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, msg, data := testPrivateProfile(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpsertIdentityPrivateProfile", ctx, mock.MatchedBy(func(private *fftypes.IdentityPrivateProfile) bool {
+		return private.Identity.Equals(org1.ID) &&
+			private.Author == org1.DID &&
+			private.Message.Equals(msg.Header.ID) &&
+			private.Profile.GetString("email") == "org1@example.com"
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeIdentityUpdated && event.Reference.Equals(org1.ID)
+	})).Return(nil)
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{data})
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+
+	err = bs.finalizers[0](ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityPrivateProfileIdentityNotYetConfirmed(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, msg, data := testPrivateProfile(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(nil, nil)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpsertIdentityPrivateProfile", ctx, mock.Anything).Return(nil)
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{data})
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityPrivateProfileUpsertFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, msg, data := testPrivateProfile(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpsertIdentityPrivateProfile", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{data})
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityPrivateProfileWrongAuthor(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, msg, data := testPrivateProfile(t)
+	msg.Header.Author = "did:firefly:org/org2"
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{data})
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityPrivateProfileLookupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, msg, data := testPrivateProfile(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{data})
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityPrivateProfileBadPayload(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	_, msg, _ := testPrivateProfile(t)
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`{"profile":{}}`)},
+	})
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityPrivateProfileMissingData(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	_, msg, _ := testPrivateProfile(t)
+
+	action, err := dh.HandleIdentityPrivateProfile(ctx, bs, msg, fftypes.DataArray{})
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	bs.assertNoFinalizers()
+}
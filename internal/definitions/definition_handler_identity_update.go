@@ -54,6 +54,17 @@ func (dh *definitionHandlers) handleIdentityUpdateBroadcast(ctx context.Context,
 		return HandlerResult{Action: ActionReject}, nil
 	}
 
+	// Check the updated profile against any schema for the namespace, before changing the cached identity
+	updated := *identity
+	updated.IdentityProfile = update.Updates
+	if retry, err := dh.identity.ValidateProfile(ctx, &updated); err != nil {
+		if retry {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		log.L(ctx).Warnf("Invalid identity update message %s: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
 	// Update the profile
 	identity.IdentityProfile = update.Updates
 	identity.Messages.Update = msg.Header.ID
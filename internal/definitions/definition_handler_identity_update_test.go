@@ -73,6 +73,7 @@ func TestHandleDefinitionIdentityUpdateOk(t *testing.T) {
 	org1, updateMsg, updateData, iu := testIdentityUpdate(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -104,6 +105,7 @@ func TestHandleDefinitionIdentityUpdateUpsertFail(t *testing.T) {
 	org1, updateMsg, updateData, _ := testIdentityUpdate(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
@@ -118,6 +120,44 @@ func TestHandleDefinitionIdentityUpdateUpsertFail(t *testing.T) {
 	bs.assertNoFinalizers()
 }
 
+func TestHandleDefinitionIdentityUpdateInvalidProfile(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, updateMsg, updateData, _ := testIdentityUpdate(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, fmt.Errorf("pop"))
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, updateMsg, fftypes.DataArray{updateData}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	assert.Equal(t, "profiledata", org1.Profile.GetString("some"))
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDefinitionIdentityUpdateValidateProfileRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	org1, updateMsg, updateData, _ := testIdentityUpdate(t)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(true, fmt.Errorf("pop"))
+	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, updateMsg, fftypes.DataArray{updateData}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, "profiledata", org1.Profile.GetString("some"))
+
+	mim.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
 func TestHandleDefinitionIdentityInvalidIdentity(t *testing.T) {
 	dh, bs := newTestDefinitionHandlers(t)
 	ctx := context.Background()
@@ -38,6 +38,7 @@ func TestHandleDefinitionIdentityVerificationWithExistingClaimOk(t *testing.T) {
 	custom1, org1, claimMsg, claimData, verifyMsg, verifyData := testCustomClaimAndVerification(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
 	mim.On("VerifyIdentityChain", ctx, mock.Anything).Return(custom1, false, nil)
 
@@ -102,6 +102,7 @@ func TestHandleDeprecatedNodeDefinitionOK(t *testing.T) {
 	parent, _, _ := testDeprecatedRootOrg(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("FindIdentityForVerifier", ctx, []fftypes.IdentityType{fftypes.IdentityTypeOrg}, fftypes.SystemNamespace, &fftypes.VerifierRef{
 		Type:  fftypes.VerifierTypeEthAddress,
 		Value: node.Owner,
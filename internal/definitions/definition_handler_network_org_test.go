@@ -89,6 +89,7 @@ func TestHandleDeprecatedOrgDefinitionOK(t *testing.T) {
 	org, msg, data := testDeprecatedRootOrg(t)
 
 	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", ctx, mock.Anything).Return(nil, false, nil)
 
 	mdi := dh.database.(*databasemocks.Plugin)
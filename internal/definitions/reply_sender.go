@@ -19,13 +19,18 @@ package definitions
 import (
 	"context"
 
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (dh *definitionHandlers) SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut) {
+func (dh *definitionHandlers) SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut, replyTo *fftypes.ReplyRouting) {
+	if err := applyReplyRouting(ctx, reply, replyTo); err != nil {
+		log.L(ctx).Errorf("Failed to send reply to event '%s': %s", event.ID, err)
+		return
+	}
 	var err error
-	if reply.Header.Group != nil {
+	if reply.Header.Group != nil || reply.Group != nil {
 		err = dh.messaging.NewMessage(event.Namespace, reply).Send(ctx)
 	} else {
 		err = dh.broadcast.NewBroadcast(event.Namespace, reply).Send(ctx)
@@ -36,3 +41,32 @@ func (dh *definitionHandlers) SendReply(ctx context.Context, event *fftypes.Even
 		log.L(ctx).Infof("Sent reply %s:%s (%s) cid=%s to event '%s'", reply.Header.Namespace, reply.Header.ID, reply.Header.Type, reply.Header.CID, event.ID)
 	}
 }
+
+// applyReplyRouting overrides the recipients, tag and topics of a reply. Routing to a group or to members
+// always makes the reply private, even if the reply was built as a broadcast.
+func applyReplyRouting(ctx context.Context, reply *fftypes.MessageInOut, replyTo *fftypes.ReplyRouting) error {
+	if replyTo == nil {
+		return nil
+	}
+	if replyTo.Group != nil && len(replyTo.Members) > 0 {
+		return i18n.NewError(ctx, i18n.MsgReplyGroupAndMembers)
+	}
+	switch {
+	case replyTo.Group != nil:
+		reply.Header.Group = replyTo.Group
+		reply.Group = nil
+	case len(replyTo.Members) > 0:
+		reply.Header.Group = nil
+		reply.Group = &fftypes.InputGroup{Members: replyTo.Members}
+	}
+	if reply.Header.Type == fftypes.MessageTypeBroadcast && (replyTo.Group != nil || len(replyTo.Members) > 0) {
+		reply.Header.Type = fftypes.MessageTypePrivate
+	}
+	if replyTo.Tag != "" {
+		reply.Header.Tag = replyTo.Tag
+	}
+	if len(replyTo.Topics) > 0 {
+		reply.Header.Topics = replyTo.Topics
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -38,7 +39,7 @@ func TestSendReplyBroadcastFail(t *testing.T) {
 	dh.SendReply(context.Background(), &fftypes.Event{
 		ID:        fftypes.NewUUID(),
 		Namespace: "ns1",
-	}, &fftypes.MessageInOut{})
+	}, &fftypes.MessageInOut{}, nil)
 
 	mbm.AssertExpectations(t)
 	mms.AssertExpectations(t)
@@ -60,7 +61,7 @@ func TestSendReplyPrivateFail(t *testing.T) {
 				Group: fftypes.NewRandB32(),
 			},
 		},
-	})
+	}, nil)
 
 	mpm.AssertExpectations(t)
 	mms.AssertExpectations(t)
@@ -85,8 +86,90 @@ func TestSendReplyPrivateOk(t *testing.T) {
 		Namespace: "ns1",
 	}, &fftypes.MessageInOut{
 		Message: *msg,
+	}, nil)
+
+	mpm.AssertExpectations(t)
+	mms.AssertExpectations(t)
+}
+
+func TestSendReplyRoutedToGroup(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	group := fftypes.NewRandB32()
+
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := dh.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", "ns1", mock.MatchedBy(func(reply *fftypes.MessageInOut) bool {
+		return *reply.Header.Group == *group &&
+			reply.Header.Type == fftypes.MessageTypePrivate &&
+			reply.Header.Tag == "routedtag" &&
+			reply.Header.Topics.String() == "topic2"
+	})).Return(mms)
+	mms.On("Send", context.Background()).Return(nil)
+
+	dh.SendReply(context.Background(), &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Type:   fftypes.MessageTypeBroadcast,
+				Tag:    "replytag",
+				Topics: fftypes.FFStringArray{"topic1"},
+			},
+		},
+	}, &fftypes.ReplyRouting{
+		Group:  group,
+		Tag:    "routedtag",
+		Topics: fftypes.FFStringArray{"topic2"},
+	})
+
+	mpm.AssertExpectations(t)
+	mms.AssertExpectations(t)
+}
+
+func TestSendReplyRoutedToMembers(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+	members := []fftypes.MemberInput{{Identity: "org1"}, {Identity: "org2"}}
+
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := dh.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", "ns1", mock.MatchedBy(func(reply *fftypes.MessageInOut) bool {
+		return reply.Header.Group == nil &&
+			assert.Equal(t, members, reply.Group.Members) &&
+			reply.Header.Tag == "replytag"
+	})).Return(mms)
+	mms.On("Send", context.Background()).Return(nil)
+
+	dh.SendReply(context.Background(), &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: fftypes.NewRandB32(),
+				Tag:   "replytag",
+			},
+		},
+	}, &fftypes.ReplyRouting{
+		Members: members,
 	})
 
 	mpm.AssertExpectations(t)
 	mms.AssertExpectations(t)
 }
+
+func TestSendReplyRoutedToGroupAndMembers(t *testing.T) {
+	dh, _ := newTestDefinitionHandlers(t)
+
+	// The reply is not sent, as the routing is invalid
+	dh.SendReply(context.Background(), &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}, &fftypes.MessageInOut{}, &fftypes.ReplyRouting{
+		Group:   fftypes.NewRandB32(),
+		Members: []fftypes.MemberInput{{Identity: "org1"}},
+	})
+
+	dh.messaging.(*privatemessagingmocks.Manager).AssertNotCalled(t, "NewMessage", mock.Anything, mock.Anything)
+	dh.broadcast.(*broadcastmocks.Manager).AssertNotCalled(t, "NewBroadcast", mock.Anything, mock.Anything)
+}
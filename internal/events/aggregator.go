@@ -30,31 +30,43 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/sharedstorage"
 	"github.com/karlseguin/ccache"
 )
 
 const (
-	aggregatorOffsetName = "ff_aggregator"
+	// AggregatorOffsetName is the name of the offset stored by the aggregator, for the last pin it processed
+	AggregatorOffsetName = "ff_aggregator"
 )
 
 type aggregator struct {
-	ctx           context.Context
-	database      database.Plugin
-	definitions   definitions.DefinitionHandlers
-	identity      identity.Manager
-	data          data.Manager
-	eventPoller   *eventPoller
-	verifierType  fftypes.VerifierType
-	rewindBatches chan fftypes.UUID
-	queuedRewinds chan fftypes.UUID
-	retry         *retry.Retry
-	metrics       metrics.Manager
-	batchCache    *ccache.Cache
-	batchCacheTTL time.Duration
+	ctx             context.Context
+	database        database.Plugin
+	definitions     definitions.DefinitionHandlers
+	identity        identity.Manager
+	data            data.Manager
+	messaging       privatemessaging.Manager
+	eventPoller     *eventPoller
+	verifierType    fftypes.VerifierType
+	rewindBatches   chan fftypes.UUID
+	queuedRewinds   chan fftypes.UUID
+	retry           *retry.Retry
+	metrics         metrics.Manager
+	batchCache      *ccache.Cache
+	batchCacheTTL   time.Duration
+	legacyManifests bool
+
+	sharedstorage      sharedstorage.Plugin
+	verifyPayloadRef   bool
+	verifyCache        *ccache.Cache
+	verifyCacheTTL     time.Duration
+	verifyPayloadLimit int64
+	deliveryAcks       bool
 }
 
 type batchCacheEntry struct {
@@ -62,25 +74,37 @@ type batchCacheEntry struct {
 	manifest *fftypes.BatchManifest
 }
 
-func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin, sh definitions.DefinitionHandlers, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager) *aggregator {
+func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin, si sharedstorage.Plugin, sh definitions.DefinitionHandlers, im identity.Manager, dm data.Manager, pm privatemessaging.Manager, en *eventNotifier, mm metrics.Manager) *aggregator {
 	batchSize := config.GetInt(config.EventAggregatorBatchSize)
 	ag := &aggregator{
-		ctx:           log.WithLogField(ctx, "role", "aggregator"),
-		database:      di,
-		definitions:   sh,
-		identity:      im,
-		data:          dm,
-		verifierType:  bi.VerifierType(),
-		rewindBatches: make(chan fftypes.UUID, 1), // hops to queuedRewinds with a shouldertab on the event poller
-		queuedRewinds: make(chan fftypes.UUID, batchSize),
-		metrics:       mm,
-		batchCacheTTL: config.GetDuration(config.BatchCacheTTL),
+		ctx:             log.WithComponent(log.WithLogField(ctx, "role", "aggregator"), log.ComponentAggregator),
+		database:        di,
+		definitions:     sh,
+		identity:        im,
+		data:            dm,
+		messaging:       pm,
+		verifierType:    bi.VerifierType(),
+		rewindBatches:   make(chan fftypes.UUID, 1), // hops to queuedRewinds with a shouldertab on the event poller
+		queuedRewinds:   make(chan fftypes.UUID, batchSize),
+		metrics:         mm,
+		batchCacheTTL:   config.GetDuration(config.BatchCacheTTL),
+		legacyManifests: config.GetBool(config.EventAggregatorLegacyManifests),
+
+		sharedstorage:      si,
+		verifyPayloadRef:   config.GetBool(config.EventAggregatorPayloadRefVerifyEnabled),
+		verifyCacheTTL:     config.GetDuration(config.EventAggregatorPayloadRefVerifyCacheTTL),
+		verifyPayloadLimit: config.GetByteSize(config.BroadcastBatchPayloadLimit),
+		deliveryAcks:       config.GetBool(config.PrivateMessagingDeliveryAcksEnabled),
 	}
 	ag.batchCache = ccache.New(
 		// We use a LRU cache with a size-aware max
 		ccache.Configure().
 			MaxSize(config.GetByteSize(config.BatchCacheSize)),
 	)
+	ag.verifyCache = ccache.New(
+		ccache.Configure().
+			MaxSize(config.GetInt64(config.EventAggregatorPayloadRefVerifyCacheSize)),
+	)
 	firstEvent := fftypes.SubOptsFirstEvent(config.GetString(config.EventAggregatorFirstEvent))
 	ag.eventPoller = newEventPoller(ctx, di, en, &eventPollerConf{
 		eventBatchSize:             batchSize,
@@ -95,7 +119,7 @@ func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin
 		firstEvent:       &firstEvent,
 		namespace:        fftypes.SystemNamespace,
 		offsetType:       fftypes.OffsetTypeAggregator,
-		offsetName:       aggregatorOffsetName,
+		offsetName:       AggregatorOffsetName,
 		newEventsHandler: ag.processPinsEventsHandler,
 		getItems:         ag.getPins,
 		queryFactory:     database.PinQueryFactory,
@@ -104,10 +128,29 @@ func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin
 		},
 		maybeRewind: ag.rewindOffchainBatches,
 	})
+	if config.GetBool(config.EventAggregatorWatchdogEnabled) {
+		ag.eventPoller.conf.watchdog = &eventPollerWatchdogConf{
+			stallTimeout: config.GetDuration(config.EventAggregatorWatchdogStallTimeout),
+			restart:      config.GetBool(config.EventAggregatorWatchdogRestart),
+			stalled:      ag.stalled,
+		}
+	}
 	ag.retry = &ag.eventPoller.conf.retry
 	return ag
 }
 
+// stalled is called by the watchdog when pins are waiting, but the aggregator has not made any progress
+// within the stall timeout. We record an event in the system namespace, as well as a metric.
+func (ag *aggregator) stalled(stalledFor time.Duration) {
+	if ag.metrics.IsMetricsEnabled() {
+		ag.metrics.EventLoopStalled(AggregatorOffsetName)
+	}
+	event := fftypes.NewEvent(fftypes.EventTypeEventLoopStalled, fftypes.SystemNamespace, nil, nil, AggregatorOffsetName)
+	if err := ag.database.InsertEvent(ag.ctx, event); err != nil {
+		log.L(ag.ctx).Errorf("Failed to record %s event after %s: %s", event.Type, stalledFor, err)
+	}
+}
+
 func (ag *aggregator) start() {
 	go ag.batchRewindListener()
 	ag.eventPoller.start()
@@ -236,7 +279,7 @@ func (ag *aggregator) migrateManifest(ctx context.Context, persistedBatch *fftyp
 		return nil
 	}
 
-	return persistedBatch.GenManifest(fullPayload.Messages, fullPayload.Data)
+	return persistedBatch.GenManifest(fullPayload.Messages, fullPayload.Data, fftypes.ProtocolVersion1)
 }
 
 func (ag *aggregator) extractManifest(ctx context.Context, batch *fftypes.BatchPersisted) *fftypes.BatchManifest {
@@ -249,11 +292,15 @@ func (ag *aggregator) extractManifest(ctx context.Context, batch *fftypes.BatchP
 	}
 	switch manifest.Version {
 	case fftypes.ManifestVersionUnset:
+		if !ag.legacyManifests {
+			log.L(ctx).Errorf("Batch %s was persisted by v0.13.x or earlier, and legacy manifests are disabled. Run the batch migration to convert it", batch.ID)
+			return nil
+		}
 		return ag.migrateManifest(ctx, batch)
-	case fftypes.ManifestVersion1:
+	case fftypes.ManifestVersion1, fftypes.ManifestVersion2:
 		return &manifest
 	default:
-		log.L(ctx).Errorf("Invalid manifest version: %d", manifest.Version)
+		log.L(ctx).Errorf("Incompatible manifest version %d in batch %s. Latest supported network protocol version is %d", manifest.Version, batch.ID, fftypes.ProtocolVersionLatest)
 		return nil
 	}
 }
@@ -300,6 +347,21 @@ func (ag *aggregator) cacheBatch(cacheKey string, batch *fftypes.BatchPersisted,
 	log.L(ag.ctx).Debugf("Cached batch %s", cacheKey)
 }
 
+// parkPin records why a pin remains parked, so it is visible on the pin itself. The pin is only updated
+// when the reason changes, as parked pins are re-evaluated each time the aggregator rewinds over them.
+func (ag *aggregator) parkPin(ctx context.Context, pin *fftypes.Pin, reason fftypes.PinParkReason) error {
+	if pin.ParkReason == reason {
+		return nil
+	}
+	fb := database.PinQueryFactory.NewFilter(ctx)
+	update := database.PinQueryFactory.NewUpdate(ctx).Set("parkreason", reason)
+	if err := ag.database.UpdatePins(ctx, fb.Eq("sequence", pin.Sequence), update); err != nil {
+		return err
+	}
+	pin.ParkReason = reason
+	return nil
+}
+
 func (ag *aggregator) processPins(ctx context.Context, pins []*fftypes.Pin, state *batchState) (err error) {
 	l := log.L(ctx)
 
@@ -317,7 +379,24 @@ func (ag *aggregator) processPins(ctx context.Context, pins []*fftypes.Pin, stat
 				return err
 			}
 			if batch == nil {
-				l.Debugf("Pin %.10d batch unavailable: batch=%s pinIndex=%d hash=%s masked=%t", pin.Sequence, pin.Batch, pin.Index, pin.Hash, pin.Masked)
+				l.Debugf("Pin %.10d parked (%s): batch=%s pinIndex=%d hash=%s masked=%t", pin.Sequence, fftypes.PinParkReasonBatchUnavailable, pin.Batch, pin.Index, pin.Hash, pin.Masked)
+				if err := ag.parkPin(ctx, pin, fftypes.PinParkReasonBatchUnavailable); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if ag.verifyPayloadRef && !pin.Masked {
+			verified, err := ag.verifyBatchPayloadRef(ctx, pin, batch)
+			if err != nil {
+				return err
+			}
+			if !verified {
+				l.Errorf("Pin %.10d parked (%s): batch=%s payloadRef=%s pinIndex=%d hash=%s", pin.Sequence, fftypes.PinParkReasonPayloadRefMismatch, pin.Batch, batch.PayloadRef, pin.Index, pin.Hash)
+				if err := ag.parkPin(ctx, pin, fftypes.PinParkReasonPayloadRefMismatch); err != nil {
+					return err
+				}
 				continue
 			}
 		}
@@ -325,7 +404,10 @@ func (ag *aggregator) processPins(ctx context.Context, pins []*fftypes.Pin, stat
 		// Extract the message from the batch - where the index is of a topic within a message
 		batchPinCount, msgEntry, msgBaseIndex := ag.extractBatchMessagePin(manifest, pin.Index)
 		if msgEntry == nil {
-			l.Errorf("Pin %.10d outside of range: batch=%s pinCount=%d pinIndex=%d hash=%s masked=%t", pin.Sequence, pin.Batch, batchPinCount, pin.Index, pin.Hash, pin.Masked)
+			l.Errorf("Pin %.10d parked (%s): batch=%s pinCount=%d pinIndex=%d hash=%s masked=%t", pin.Sequence, fftypes.PinParkReasonOutOfRange, pin.Batch, batchPinCount, pin.Index, pin.Hash, pin.Masked)
+			if err := ag.parkPin(ctx, pin, fftypes.PinParkReasonOutOfRange); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -342,6 +424,9 @@ func (ag *aggregator) processPins(ctx context.Context, pins []*fftypes.Pin, stat
 		}
 	}
 
+	if ag.metrics.IsMetricsEnabled() {
+		ag.metrics.AggregatorPinsProcessed(len(pins))
+	}
 	ag.eventPoller.commitOffset(pins[len(pins)-1].Sequence)
 	return nil
 }
@@ -351,10 +436,10 @@ func (ag *aggregator) checkOnchainConsistency(ctx context.Context, msg *fftypes.
 
 	verifierRef := &fftypes.VerifierRef{
 		Type:  ag.verifierType,
-		Value: pin.Signer,
+		Value: msg.Header.Key,
 	}
 
-	if msg.Header.Key == "" || msg.Header.Key != pin.Signer {
+	if msg.Header.Key == "" || pin.Signer == "" {
 		l.Errorf("Invalid message '%s'. Key '%s' does not match the signer of the pin: %s", msg.Header.ID, msg.Header.Key, pin.Signer)
 		return false, nil // This is not retryable. skip this message
 	}
@@ -368,6 +453,11 @@ func (ag *aggregator) checkOnchainConsistency(ctx context.Context, msg *fftypes.
 		return false, err
 	}
 	if resolvedAuthor == nil {
+		if msg.Header.Key != pin.Signer {
+			// Pinning keys are only authorized for registered identities
+			l.Errorf("Invalid message '%s'. Key '%s' does not match the signer of the pin: %s", msg.Header.ID, msg.Header.Key, pin.Signer)
+			return false, nil // This is not retryable. skip this message
+		}
 		if msg.Header.Type == fftypes.MessageTypeDefinition &&
 			(msg.Header.Tag == fftypes.SystemTagIdentityClaim || msg.Header.Tag == fftypes.DeprecatedSystemTagDefineNode || msg.Header.Tag == fftypes.DeprecatedSystemTagDefineOrganization) {
 			// We defer detailed checking of this identity to the system handler
@@ -381,11 +471,45 @@ func (ag *aggregator) checkOnchainConsistency(ctx context.Context, msg *fftypes.
 		l.Errorf("Invalid message '%s'. Author '%s' does not match identity registered to %s: %s (%s)", msg.Header.ID, msg.Header.Author, verifierRef.Value, resolvedAuthor.DID, resolvedAuthor.ID)
 		return false, nil // This is not retryable. skip this batch
 
+	} else if msg.Header.Key != pin.Signer {
+		return ag.checkPinningKey(ctx, msg, resolvedAuthor, pin.Signer)
 	}
 
 	return true, nil
 }
 
+// checkPinningKey allows the batch to be pinned by a different key to the one that signed the message,
+// as long as that key is registered as a pinning key of the author, or of a child identity of the author.
+// This allows an org to segregate the keys used to pin batches for different workloads.
+func (ag *aggregator) checkPinningKey(ctx context.Context, msg *fftypes.Message, author *fftypes.Identity, signer string) (valid bool, err error) {
+	l := log.L(ctx)
+
+	verifier, err := ag.identity.CachedVerifierLookup(ctx, ag.verifierType, msg.Header.Namespace, signer)
+	if err == nil && verifier == nil && msg.Header.Namespace != fftypes.SystemNamespace {
+		verifier, err = ag.identity.CachedVerifierLookup(ctx, ag.verifierType, fftypes.SystemNamespace, signer)
+	}
+	if err != nil {
+		return false, err
+	}
+	if verifier == nil || !verifier.Pinning {
+		l.Errorf("Invalid message '%s'. Key '%s' does not match the signer of the pin, which is not a registered pinning key: %s", msg.Header.ID, msg.Header.Key, signer)
+		return false, nil // This is not retryable. skip this message
+	}
+	if verifier.Identity.Equals(author.ID) {
+		return true, nil
+	}
+
+	pinner, err := ag.identity.CachedIdentityLookupByID(ctx, verifier.Identity)
+	if err != nil {
+		return false, err
+	}
+	if pinner == nil || !pinner.Parent.Equals(author.ID) {
+		l.Errorf("Invalid message '%s'. Pinning key '%s' is not authorized for author '%s'", msg.Header.ID, signer, author.DID)
+		return false, nil // This is not retryable. skip this message
+	}
+	return true, nil
+}
+
 func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.BatchManifest, pin *fftypes.Pin, msgBaseIndex int64, msgEntry *fftypes.MessageManifestEntry, state *batchState) (err error) {
 	l := log.L(ctx)
 
@@ -403,6 +527,8 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 		l.Errorf("Message '%s' in batch '%s' is missing data", msgEntry.ID, manifest.ID)
 		return nil
 	}
+	ctx = log.WithCorrelationID(ctx, msg.CorrelationID)
+	l = log.L(ctx)
 
 	// Check if it's ready to be processed
 	unmaskedContexts := make([]*fftypes.Bytes32, 0, len(msg.Header.Topics))
@@ -450,7 +576,15 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 
 	dispatched := false
 	var newState fftypes.MessageState
-	if dataAvailable {
+	switch {
+	case msg.Header.Type == fftypes.MessageTypeDefinition &&
+		(msg.State == fftypes.MessageStateConfirmed || msg.State == fftypes.MessageStateRejected):
+		// Definitions are preserved when the node is rebuilt, so are not processed a second time
+		// as the pins are replayed - we just move past them in the sequence
+		l.Debugf("Definition msg=%s already %s", msg.Header.ID, msg.State)
+		newState = msg.State
+		dispatched = true
+	case dataAvailable:
 		l.Debugf("Attempt dispatch msg=%s broadcastContexts=%v privatePins=%v", msg.Header.ID, unmaskedContexts, msg.Pins)
 		newState, dispatched, err = ag.attemptMessageDispatch(ctx, msg, data, manifest.TX.ID, state, pin)
 		if err != nil {
@@ -466,6 +600,11 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 			np.IncrementNextPin(ctx)
 		}
 		state.MarkMessageDispatched(ctx, manifest.ID, msg, msgBaseIndex, newState)
+		if pin.Masked && ag.deliveryAcks {
+			state.AddFinalize(func(ctx context.Context) error {
+				return ag.messaging.SendDeliveryAck(ctx, manifest.ID, msg, newState)
+			})
+		}
 	} else {
 		for _, unmaskedContext := range unmaskedContexts {
 			state.SetContextBlockedBy(ctx, *unmaskedContext, pin.Sequence)
@@ -523,6 +662,15 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 	case msg.Header.Type == fftypes.MessageTypeGroupInit:
 		// Already handled as part of resolving the context - do nothing.
 
+	case msg.Header.Type == fftypes.MessageTypePrivate && msg.Header.Tag == fftypes.SystemTagIdentityPrivateProfile:
+		// Private identity profile fields are stored in-line, in the same way as definitions
+		handlerResult, err := ag.definitions.HandleIdentityPrivateProfile(ctx, state, msg, data)
+		log.L(ctx).Infof("Result of private profile '%s': %s", msg.Header.ID, handlerResult.Action)
+		if handlerResult.Action == definitions.ActionRetry {
+			return "", false, err
+		}
+		valid = handlerResult.Action == definitions.ActionConfirm
+
 	case len(msg.Data) > 0:
 		valid, err = ag.data.ValidateAll(ctx, data)
 		if err != nil {
@@ -544,6 +692,7 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 		for _, topic := range msg.Header.Topics {
 			event := fftypes.NewEvent(eventType, msg.Header.Namespace, msg.Header.ID, tx, topic)
 			event.Correlator = msg.Header.CID
+			event.CorrelationID = msg.CorrelationID
 			if customCorrelator != nil {
 				// Definition handlers can set a custom event correlator (such as a token pool ID)
 				event.Correlator = customCorrelator
@@ -555,7 +704,7 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 		return nil
 	})
 	if ag.metrics.IsMetricsEnabled() {
-		ag.metrics.MessageConfirmed(msg, eventType)
+		ag.metrics.MessageConfirmed(msg, tx, eventType)
 	}
 
 	return newState, true, nil
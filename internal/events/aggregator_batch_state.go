@@ -298,7 +298,9 @@ func (bs *batchState) flushPins(ctx context.Context) error {
 				fb.In("index", indexes),
 			))
 		}
-		update := database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true)
+		update := database.PinQueryFactory.NewUpdate(ctx).
+			Set("dispatched", true).
+			Set("parkreason", "")
 		if err := bs.database.UpdatePins(ctx, filter, update); err != nil {
 			return err
 		}
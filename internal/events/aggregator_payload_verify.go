@@ -0,0 +1,124 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// verifyBatchPayloadRef checks the batch payload held in shared storage, at the reference it was downloaded from,
+// still matches the on-chain hash of the batch before we confirm any broadcast messages from it.
+// The result is cached per batch, as there are commonly many pins for each batch.
+// An error is returned only if shared storage could not be read, which is retryable.
+func (ag *aggregator) verifyBatchPayloadRef(ctx context.Context, pin *fftypes.Pin, batch *fftypes.BatchPersisted) (bool, error) {
+	cacheKey := ag.getBatchCacheKey(pin.Batch, pin.BatchHash)
+	cached := ag.verifyCache.Get(cacheKey)
+	if cached != nil {
+		cached.Extend(ag.verifyCacheTTL)
+		return cached.Value().(bool), nil
+	}
+
+	if batch.PayloadRef == "" {
+		// Received directly over data exchange in hybrid mode, so there is no shared storage copy we downloaded.
+		// Its content was verified against the batch hash on receipt, and the batch hash matches the pin.
+		log.L(ctx).Debugf("Batch %s was received over data exchange - no shared storage payload to verify", batch.ID)
+		return true, nil
+	}
+
+	reader, err := ag.sharedstorage.DownloadData(ctx, batch.PayloadRef)
+	if err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgDownloadSharedFailed, batch.PayloadRef)
+	}
+	defer reader.Close()
+
+	maxReadLimit := ag.verifyPayloadLimit + 1024
+	payload, err := ioutil.ReadAll(io.LimitReader(reader, maxReadLimit))
+	if err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgDownloadSharedFailed, batch.PayloadRef)
+	}
+
+	verified := len(payload) < int(maxReadLimit) && ag.checkBatchPayload(ctx, pin, batch, payload)
+	ag.verifyCache.Set(cacheKey, verified, ag.verifyCacheTTL)
+	return verified, nil
+}
+
+func (ag *aggregator) checkBatchPayload(ctx context.Context, pin *fftypes.Pin, persisted *fftypes.BatchPersisted, payload []byte) bool {
+	l := log.L(ctx)
+
+	var batch *fftypes.Batch
+	protocolVersion, err := fftypes.DeserializeTransportPayload(ctx, payload, ag.verifyPayloadLimit+1024, &batch)
+	if err != nil || batch == nil {
+		l.Errorf("Batch %s payload in shared storage '%s' is invalid: %v", pin.Batch, persisted.PayloadRef, err)
+		return false
+	}
+	if !batch.ID.Equals(pin.Batch) {
+		l.Errorf("Batch %s payload in shared storage '%s' has mismatched ID %s", pin.Batch, persisted.PayloadRef, batch.ID)
+		return false
+	}
+
+	// The manifest only contains the hashes of the messages and data, so we need to check every
+	// entry in the payload genuinely matches its hash - not just that the manifest matches.
+	for i, data := range batch.Payload.Data {
+		if data == nil {
+			l.Errorf("Batch %s payload in shared storage has null data entry %d", pin.Batch, i)
+			return false
+		}
+		hash, err := data.CalcHash(ctx)
+		if err != nil || !hash.Equals(data.Hash) {
+			l.Errorf("Batch %s payload in shared storage has invalid data entry %d: Hash=%v Expected=%v", pin.Batch, i, data.Hash, hash)
+			return false
+		}
+	}
+	for i, msg := range batch.Payload.Messages {
+		if msg == nil {
+			l.Errorf("Batch %s payload in shared storage has null message entry %d", pin.Batch, i)
+			return false
+		}
+		if err := msg.Verify(ctx); err != nil {
+			l.Errorf("Batch %s payload in shared storage has invalid message entry %d: %s", pin.Batch, i, err)
+			return false
+		}
+	}
+
+	manifest := batch.Payload.Manifest(batch.ID, protocolVersion).String()
+	manifestHash, err := fftypes.HashStringWithAlgorithm(ctx, persisted.HashAlgorithm, manifest)
+	if err != nil {
+		l.Errorf("Batch %s payload in shared storage is invalid: %s", pin.Batch, err)
+		return false
+	}
+	if manifestHash.Equals(pin.BatchHash) {
+		// The manifest we persisted on receipt must be identical to the one from shared storage
+		if manifest != persisted.Manifest.String() {
+			l.Errorf("Batch %s payload in shared storage does not match the persisted manifest", pin.Batch)
+			return false
+		}
+		return true
+	}
+
+	// Batches written by v0.13 and older environments are hashed on the whole payload
+	if batch.Payload.Hash().Equals(pin.BatchHash) {
+		return true
+	}
+	l.Errorf("Batch %s payload in shared storage '%s' does not match the on-chain hash %s", pin.Batch, persisted.PayloadRef, pin.BatchHash)
+	return false
+}
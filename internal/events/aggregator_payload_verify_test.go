@@ -0,0 +1,319 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestVerifyBatch(t *testing.T) (*fftypes.Batch, *fftypes.BatchPersisted, *fftypes.Pin) {
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)},
+	})
+	batch.PayloadRef = "ref1"
+	bp, _ := batch.Confirmed()
+	pin := &fftypes.Pin{
+		Sequence:  12345,
+		Batch:     batch.ID,
+		BatchHash: batch.Hash,
+	}
+	return batch, bp, pin
+}
+
+func mockDownload(t *testing.T, mpi *sharedstoragemocks.Plugin, payloadRef string, payload interface{}) {
+	b, ok := payload.([]byte)
+	if !ok {
+		var err error
+		b, err = json.Marshal(payload)
+		assert.NoError(t, err)
+	}
+	mpi.On("DownloadData", mock.Anything, payloadRef).Return(ioutil.NopCloser(bytes.NewReader(b)), nil).Once()
+}
+
+func TestVerifyBatchPayloadRefOkCached(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", batch)
+
+	verified, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	// Second call served from the cache
+	verified, err = ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	mpi.AssertExpectations(t)
+}
+
+func TestVerifyBatchPayloadRefCompressed(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, _, pin := newTestVerifyBatch(t)
+	batch.ProtocolVersion = fftypes.ProtocolVersion2
+	bp, manifest := batch.Confirmed()
+	pin.BatchHash = fftypes.HashString(manifest.String())
+	b, err := fftypes.SerializeTransportPayload(ag.ctx, fftypes.ProtocolVersion2, batch)
+	assert.NoError(t, err)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", b)
+
+	verified, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestVerifyBatchPayloadRefMigrated(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	pin.BatchHash = batch.Payload.Hash()
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", batch)
+
+	verified, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestVerifyBatchPayloadRefReceivedOverDX(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	_, bp, pin := newTestVerifyBatch(t)
+	bp.PayloadRef = ""
+
+	// Nothing is downloaded from shared storage
+	verified, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestVerifyBatchPayloadRefDownloadFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	_, bp, pin := newTestVerifyBatch(t)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("DownloadData", mock.Anything, "ref1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.Regexp(t, "FF10376.*pop", err)
+}
+
+func TestVerifyBatchPayloadRefReadFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	_, bp, pin := newTestVerifyBatch(t)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("DownloadData", mock.Anything, "ref1").Return(ioutil.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), nil)
+
+	_, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.Regexp(t, "FF10376.*pop", err)
+}
+
+func TestVerifyBatchPayloadRefTooLarge(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	_, bp, pin := newTestVerifyBatch(t)
+	ag.verifyPayloadLimit = 0
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", make([]byte, 2048))
+
+	verified, err := ag.verifyBatchPayloadRef(ag.ctx, pin, bp)
+	assert.NoError(t, err)
+	assert.False(t, verified)
+}
+
+func TestCheckBatchPayloadInvalid(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	_, bp, pin := newTestVerifyBatch(t)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, []byte("!json")))
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, []byte("null")))
+}
+
+func TestCheckBatchPayloadWrongID(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.ID = fftypes.NewUUID()
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadNullData(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Data = append(batch.Payload.Data, nil)
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadTamperedData(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Data[0].Value = fftypes.JSONAnyPtr(`"tampered"`)
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadNullMessage(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Messages = append(batch.Payload.Messages, nil)
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadTamperedMessage(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Messages[0].Header.Topics = fftypes.FFStringArray{"tampered"}
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadManifestMismatch(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	bp.Manifest = fftypes.JSONAnyPtr(`{}`)
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadHashAlgorithm(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, _, pin := newTestVerifyBatch(t)
+	batch.HashAlgorithm = fftypes.HashAlgorithmSHA3256
+	bp, _ := batch.Confirmed()
+	pin.BatchHash, _ = fftypes.HashStringWithAlgorithm(ag.ctx, fftypes.HashAlgorithmSHA3256, bp.Manifest.String())
+	b, _ := json.Marshal(batch)
+	assert.True(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadUnsupportedHashAlgorithm(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	bp.HashAlgorithm = "md5"
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestCheckBatchPayloadHashMismatch(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	pin.BatchHash = fftypes.NewRandB32()
+	b, _ := json.Marshal(batch)
+	assert.False(t, ag.checkBatchPayload(ag.ctx, pin, bp, b))
+}
+
+func TestProcessPinsPayloadRefMismatchParked(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	ag.verifyPayloadRef = true
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Data[0].Value = fftypes.JSONAnyPtr(`"tampered"`)
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, batch.ID).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonPayloadRefMismatch)).Return(nil)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", batch)
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{pin}, bs)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.PinParkReasonPayloadRefMismatch, pin.ParkReason)
+
+	// Confirm the offset moves on, with the pin left parked
+	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
+
+	mdi.AssertExpectations(t)
+	mpi.AssertExpectations(t)
+}
+
+func TestProcessPinsPayloadRefMismatchParkFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	ag.verifyPayloadRef = true
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	batch.Payload.Data[0].Value = fftypes.JSONAnyPtr(`"tampered"`)
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, batch.ID).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mockDownload(t, mpi, "ref1", batch)
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{pin}, bs)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessPinsPayloadRefDownloadFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	ag.verifyPayloadRef = true
+
+	batch, bp, pin := newTestVerifyBatch(t)
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, batch.ID).Return(bp, nil)
+	mpi := ag.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("DownloadData", mock.Anything, "ref1").Return(nil, fmt.Errorf("pop"))
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{pin}, bs)
+	assert.Regexp(t, "FF10376", err)
+}
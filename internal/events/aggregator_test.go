@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -33,6 +34,8 @@ import (
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -47,13 +50,16 @@ func newTestAggregatorCommon(metrics bool) (*aggregator, func()) {
 	mim := &identitymanagermocks.Manager{}
 	mmi := &metricsmocks.Manager{}
 	mbi := &blockchainmocks.Plugin{}
+	mpi := &sharedstoragemocks.Plugin{}
+	mpm := &privatemessagingmocks.Manager{}
 	if metrics {
-		mmi.On("MessageConfirmed", mock.Anything, fftypes.EventTypeMessageConfirmed).Return()
+		mmi.On("MessageConfirmed", mock.Anything, mock.Anything, fftypes.EventTypeMessageConfirmed).Return()
+		mmi.On("AggregatorPinsProcessed", mock.Anything).Return()
 	}
 	mmi.On("IsMetricsEnabled").Return(metrics)
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress)
 	ctx, cancel := context.WithCancel(context.Background())
-	ag := newAggregator(ctx, mdi, mbi, msh, mim, mdm, newEventNotifier(ctx, "ut"), mmi)
+	ag := newAggregator(ctx, mdi, mbi, mpi, msh, mim, mdm, mpm, newEventNotifier(ctx, "ut"), mmi)
 	return ag, cancel
 }
 
@@ -378,6 +384,129 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestAggregationMaskedSendsDeliveryAck(t *testing.T) {
+	log.SetLevel("debug")
+
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.deliveryAcks = true
+
+	// Generate some pin data
+	member1org := newTestOrg("org1")
+	member2org := newTestOrg("org2")
+	member2key := "0x12345"
+	topic := "some-topic"
+	batchID := fftypes.NewUUID()
+	groupID := fftypes.NewRandB32()
+	msgID := fftypes.NewUUID()
+	h := sha256.New()
+	h.Write([]byte(topic))
+	h.Write((*groupID)[:])
+	contextUnmasked := fftypes.HashResult(h)
+	initNPG := &nextPinGroupState{topic: topic, groupID: groupID}
+	member1Nonce100 := initNPG.calcPinHash(member1org.DID, 100)
+	member2Nonce500 := initNPG.calcPinHash(member2org.DID, 500)
+	member2Nonce501 := initNPG.calcPinHash(member2org.DID, 501)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdm := ag.data.(*datamocks.Manager)
+	mpm := ag.messaging.(*privatemessagingmocks.Manager)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, []fftypes.IdentityType{fftypes.IdentityTypeOrg, fftypes.IdentityTypeCustom}, "ns1", &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: member2key,
+	}).Return(member2org, nil)
+
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{
+			ID: batchID,
+		},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{
+					Header: fftypes.MessageHeader{
+						ID:        msgID,
+						Group:     groupID,
+						Namespace: "ns1",
+						Topics:    []string{topic},
+						SignerRef: fftypes.SignerRef{
+							Author: member2org.DID,
+							Key:    member2key,
+						},
+					},
+					Pins: []string{member2Nonce500.String()},
+					Data: fftypes.DataRefs{
+						{ID: fftypes.NewUUID()},
+					},
+				},
+			},
+		},
+	}
+	bp, _ := batch.Confirmed()
+
+	// Get the batch
+	mdi.On("GetBatchByID", ag.ctx, batchID).Return(bp, nil)
+	// Look for existing nextpins - none found, first on context
+	mdi.On("GetNextPins", ag.ctx, mock.Anything).Return([]*fftypes.NextPin{
+		{Context: contextUnmasked, Identity: member1org.DID, Hash: member1Nonce100, Nonce: 100, Sequence: 929},
+		{Context: contextUnmasked, Identity: member2org.DID, Hash: member2Nonce500, Nonce: 500, Sequence: 424},
+	}, nil, nil).Once()
+	// Validate the message is ok
+	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePins).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("UpdateMessageStateIfCached", ag.ctx, mock.Anything, fftypes.MessageStateConfirmed, mock.Anything).Return()
+	// Insert the confirmed event
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return *e.Reference == *msgID && e.Type == fftypes.EventTypeMessageConfirmed
+	})).Return(nil)
+	// Update member2 to nonce 1
+	mdi.On("UpdateNextPin", ag.ctx, mock.MatchedBy(func(seq int64) bool {
+		return seq == 424
+	}), mock.MatchedBy(func(update database.Update) bool {
+		ui, _ := update.Finalize()
+		assert.Equal(t, "nonce", ui.SetOperations[0].Field)
+		v, _ := ui.SetOperations[0].Value.Value()
+		assert.Equal(t, int64(501), v.(int64))
+		assert.Equal(t, "hash", ui.SetOperations[1].Field)
+		v, _ = ui.SetOperations[1].Value.Value()
+		assert.Equal(t, member2Nonce501.String(), v)
+		return true
+	})).Return(nil)
+	// Set the pin to dispatched
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	// Update the message
+	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+
+	// Acknowledge the message back to the sender
+	mpm.On("SendDeliveryAck", ag.ctx, batchID, batch.Payload.Messages[0], fftypes.MessageStateConfirmed).Return(nil)
+
+	_, err := ag.processPinsEventsHandler([]fftypes.LocallySequenced{
+		&fftypes.Pin{
+			Sequence:   10001,
+			Masked:     true,
+			Hash:       member2Nonce500,
+			Batch:      batchID,
+			Index:      0,
+			Signer:     member2key,
+			Dispatched: false,
+		},
+	})
+	assert.NoError(t, err)
+
+	// Confirm the offset
+	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
 func TestAggregationBroadcast(t *testing.T) {
 
 	ag, cancel := newTestAggregator()
@@ -467,6 +596,68 @@ func TestAggregationBroadcast(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestAggregationProcessedDefinitionSkipped(t *testing.T) {
+
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	topic := "some-topic"
+	batchID := fftypes.NewUUID()
+	h := sha256.New()
+	h.Write([]byte(topic))
+	contextUnmasked := fftypes.HashResult(h)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdm := ag.data.(*datamocks.Manager)
+
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{
+			ID: batchID,
+		},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{
+					Header: fftypes.MessageHeader{
+						ID:        fftypes.NewUUID(),
+						Type:      fftypes.MessageTypeDefinition,
+						Topics:    []string{topic},
+						Namespace: "ns1",
+					},
+					State: fftypes.MessageStateRejected,
+				},
+			},
+		},
+	}
+	bp, _ := batch.Confirmed()
+
+	mdi.On("GetBatchByID", ag.ctx, batchID).Return(bp, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
+	// The state is kept, and the pin moves on - without the definition being handled again, or a new event
+	mdm.On("UpdateMessageStateIfCached", ag.ctx, mock.Anything, fftypes.MessageStateRejected, mock.Anything).Return()
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{
+		{
+			Sequence: 10001,
+			Hash:     contextUnmasked,
+			Batch:    batchID,
+			Index:    0,
+		},
+	}, bs)
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestAggregationMigratedBroadcast(t *testing.T) {
 
 	ag, cancel := newTestAggregator()
@@ -607,6 +798,7 @@ func TestAggregationMigratedBroadcastNilMessageID(t *testing.T) {
 	}
 
 	mdi.On("GetBatchByID", ag.ctx, batchID).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonOutOfRange)).Return(nil)
 
 	err = ag.processPins(ag.ctx, []*fftypes.Pin{
 		{
@@ -673,6 +865,7 @@ func TestAggregationMigratedBroadcastInvalid(t *testing.T) {
 	}
 
 	mdi.On("GetBatchByID", ag.ctx, batchID).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonBatchUnavailable)).Return(nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
 		{
@@ -699,9 +892,9 @@ func TestAggregationMigratedBroadcastInvalid(t *testing.T) {
 func TestShutdownOnCancel(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	mdi := ag.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, aggregatorOffsetName).Return(&fftypes.Offset{
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, AggregatorOffsetName).Return(&fftypes.Offset{
 		Type:    fftypes.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
@@ -748,24 +941,72 @@ func TestGetPins(t *testing.T) {
 	assert.Equal(t, int64(12345), lc[0].LocalSequence())
 }
 
+func matchParkReason(reason fftypes.PinParkReason) interface{} {
+	return mock.MatchedBy(func(update database.Update) bool {
+		info, _ := update.Finalize()
+		if len(info.SetOperations) != 1 {
+			return false
+		}
+		v, _ := info.SetOperations[0].Value.Value()
+		return info.SetOperations[0].Field == "parkreason" && v == reason.String()
+	})
+}
+
 func TestProcessPinsMissingBatch(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
 	bs := newBatchState(ag)
 
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonBatchUnavailable)).Return(nil)
+
+	pin := &fftypes.Pin{Sequence: 12345, Batch: fftypes.NewUUID()}
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{pin}, bs)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.PinParkReasonBatchUnavailable, pin.ParkReason)
+	mdi.AssertExpectations(t)
+
+	// Confirm the offset
+	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
+
+}
+
+func TestProcessPinsMissingBatchAlreadyParked(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(nil, nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
-		{Sequence: 12345, Batch: fftypes.NewUUID()},
+		{Sequence: 12345, Batch: fftypes.NewUUID(), ParkReason: fftypes.PinParkReasonBatchUnavailable},
 	}, bs)
 	assert.NoError(t, err)
+	mdi.AssertNotCalled(t, "UpdatePins", mock.Anything, mock.Anything, mock.Anything)
 
 	// Confirm the offset
 	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
 
 }
 
+func TestProcessPinsMissingBatchParkFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{
+		{Sequence: 12345, Batch: fftypes.NewUUID()},
+	}, bs)
+	assert.EqualError(t, err, "pop")
+
+}
+
 func TestProcessPinsMissingNoMsg(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -785,6 +1026,7 @@ func TestProcessPinsMissingNoMsg(t *testing.T) {
 
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonOutOfRange)).Return(nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
 		{Sequence: 12345, Batch: fftypes.NewUUID(), Index: 25},
@@ -797,6 +1039,34 @@ func TestProcessPinsMissingNoMsg(t *testing.T) {
 
 }
 
+func TestProcessPinsMissingNoMsgParkFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+			},
+		},
+	}
+	bp, _ := batch.Confirmed()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.processPins(ag.ctx, []*fftypes.Pin{
+		{Sequence: 12345, Batch: fftypes.NewUUID(), Index: 25},
+	}, bs)
+	assert.EqualError(t, err, "pop")
+
+}
+
 func TestProcessPinsBadMsgHeader(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -819,6 +1089,7 @@ func TestProcessPinsBadMsgHeader(t *testing.T) {
 
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBatchByID", ag.ctx, mock.Anything).Return(bp, nil)
+	mdi.On("UpdatePins", ag.ctx, mock.Anything, matchParkReason(fftypes.PinParkReasonOutOfRange)).Return(nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
 		{Sequence: 12345, Batch: fftypes.NewUUID(), Index: 0},
@@ -1760,10 +2031,170 @@ func TestDefinitionBroadcastRejectBadSigner(t *testing.T) {
 	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeDefinition, nil)
 	msg1.Header.SignerRef = fftypes.SignerRef{Key: "0x23456", Author: org1.DID}
 
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x12345").Return(nil, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, fftypes.SystemNamespace, "0x12345").Return(nil, nil)
+
+	_, valid, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, &batchState{}, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	mim.AssertExpectations(t)
+}
+
+func TestDefinitionBroadcastRejectBadSignerUnregistered(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeDefinition, nil)
+	msg1.Header.SignerRef = fftypes.SignerRef{Key: "0x23456", Author: org1.DID}
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
 	_, valid, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, &batchState{}, &fftypes.Pin{Signer: "0x12345"})
 	assert.NoError(t, err)
 	assert.False(t, valid)
 
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyNoPinSigner(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, _, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	valid, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestCheckOnchainConsistencyPinningKeyOfAuthor(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x12345",
+	}).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(nil, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, fftypes.SystemNamespace, "0x23456").Return(&fftypes.Verifier{
+		Identity: org1.ID,
+		Pinning:  true,
+	}, nil)
+
+	valid, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyPinningKeyOfChild(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	child := newTestOrg("child1")
+	child.Parent = org1.ID
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(&fftypes.Verifier{
+		Identity: child.ID,
+		Pinning:  true,
+	}, nil)
+	mim.On("CachedIdentityLookupByID", ag.ctx, child.ID).Return(child, nil)
+
+	valid, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyPinningKeyOfOtherIdentity(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	other := newTestOrg("other1")
+	other.Parent = fftypes.NewUUID()
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(&fftypes.Verifier{
+		Identity: other.ID,
+		Pinning:  true,
+	}, nil)
+	mim.On("CachedIdentityLookupByID", ag.ctx, other.ID).Return(other, nil)
+
+	valid, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyNotPinningKey(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(&fftypes.Verifier{
+		Identity: org1.ID,
+	}, nil)
+
+	valid, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyPinningKeyLookupFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
+func TestCheckOnchainConsistencyPinningIdentityLookupFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	childID := fftypes.NewUUID()
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, "any", mock.Anything).Return(org1, nil)
+	mim.On("CachedVerifierLookup", ag.ctx, fftypes.VerifierTypeEthAddress, "any", "0x23456").Return(&fftypes.Verifier{
+		Identity: childID,
+		Pinning:  true,
+	}, nil)
+	mim.On("CachedIdentityLookupByID", ag.ctx, childID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.checkOnchainConsistency(ag.ctx, msg1, &fftypes.Pin{Signer: "0x23456"})
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
 }
 
 func TestDefinitionBroadcastRejectUnregisteredSignerIdentityClaim(t *testing.T) {
@@ -1877,6 +2308,61 @@ func TestAttemptMessageDispatchGroupInit(t *testing.T) {
 
 }
 
+func TestAttemptMessageDispatchPrivateProfile(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	org1 := newTestOrg("org1")
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	msh := ag.definitions.(*definitionsmocks.DefinitionHandlers)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	msh.On("HandleIdentityPrivateProfile", ag.ctx, bs, mock.Anything, mock.Anything).Return(definitions.HandlerResult{Action: definitions.ActionConfirm}, nil)
+	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      fftypes.MessageTypePrivate,
+			Tag:       fftypes.SystemTagIdentityPrivateProfile,
+			SignerRef: fftypes.SignerRef{Key: "0x12345", Author: org1.DID},
+		},
+	}
+	newState, _, err := ag.attemptMessageDispatch(ag.ctx, msg, fftypes.DataArray{}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateConfirmed, newState)
+	assert.Equal(t, msg, bs.pendingConfirms[*msg.Header.ID])
+
+	msh.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchPrivateProfileRetry(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	org1 := newTestOrg("org1")
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	msh := ag.definitions.(*definitionsmocks.DefinitionHandlers)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	msh.On("HandleIdentityPrivateProfile", ag.ctx, bs, mock.Anything, mock.Anything).Return(definitions.HandlerResult{Action: definitions.ActionRetry}, fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      fftypes.MessageTypePrivate,
+			Tag:       fftypes.SystemTagIdentityPrivateProfile,
+			SignerRef: fftypes.SignerRef{Key: "0x12345", Author: org1.DID},
+		},
+	}, fftypes.DataArray{}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.EqualError(t, err, "pop")
+
+	msh.AssertExpectations(t)
+}
+
 func TestRewindOffchainBatchesNoBatches(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -2111,6 +2597,18 @@ func TestExtractManifestBadVersion(t *testing.T) {
 	assert.Nil(t, manifest)
 }
 
+func TestExtractManifestLegacyDisabled(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.legacyManifests = false
+
+	manifest := ag.extractManifest(ag.ctx, &fftypes.BatchPersisted{
+		Manifest: fftypes.JSONAnyPtr(`{"messages":[]}`),
+	})
+
+	assert.Nil(t, manifest)
+}
+
 func TestMigrateManifestFail(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -2171,3 +2669,136 @@ func TestGetBatchForPinHashMismatch(t *testing.T) {
 	assert.Nil(t, err)
 
 }
+
+func TestAggregatorWatchdogConfig(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	assert.NotNil(t, ag.eventPoller.conf.watchdog)
+	assert.Equal(t, 5*time.Minute, ag.eventPoller.conf.watchdog.stallTimeout)
+	assert.False(t, ag.eventPoller.conf.watchdog.restart)
+
+	config.Set(config.EventAggregatorWatchdogEnabled, false)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress)
+	ag = newAggregator(ag.ctx, ag.database, mbi, ag.sharedstorage, ag.definitions, ag.identity, ag.data, ag.messaging, ag.eventPoller.eventNotifier, ag.metrics)
+	assert.Nil(t, ag.eventPoller.conf.watchdog)
+}
+
+func TestAggregatorStalled(t *testing.T) {
+	ag, cancel := newTestAggregatorWithMetrics()
+	defer cancel()
+
+	mmi := ag.metrics.(*metricsmocks.Manager)
+	mmi.On("EventLoopStalled", AggregatorOffsetName).Return()
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeEventLoopStalled && e.Namespace == fftypes.SystemNamespace && e.Reference == nil
+	})).Return(nil)
+
+	ag.eventPoller.conf.watchdog.stalled(10 * time.Minute)
+
+	mmi.AssertCalled(t, "EventLoopStalled", AggregatorOffsetName)
+	mdi.AssertExpectations(t)
+}
+
+func TestAggregatorStalledInsertFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ag.stalled(10 * time.Minute)
+
+	mdi.AssertExpectations(t)
+}
+
+func newTestAggregatorCustomDefinition(t *testing.T) (*aggregator, *definitionsmocks.CustomHandler, *fftypes.Message, func()) {
+	ag, cancel := newTestAggregator()
+	ag.definitions = definitions.NewDefinitionHandlers(ag.database, nil, nil, ag.data, ag.identity, nil, nil, nil, nil)
+	mch := &definitionsmocks.CustomHandler{}
+	err := ag.definitions.RegisterCustomHandler(ag.ctx, "myapp:config", mch)
+	assert.NoError(t, err)
+
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeDefinition, nil)
+	msg1.Header.Tag = "myapp:config"
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	return ag, mch, msg1, cancel
+}
+
+func TestAttemptMessageDispatchCustomDefinitionConfirm(t *testing.T) {
+	ag, mch, msg1, cancel := newTestAggregatorCustomDefinition(t)
+	defer cancel()
+	bs := newBatchState(ag)
+
+	correlator := fftypes.NewUUID()
+	mch.On("HandleDefinition", ag.ctx, bs, msg1, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: definitions.ActionConfirm, CustomCorrelator: correlator}, nil)
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageConfirmed && event.Correlator.Equals(correlator)
+	})).Return(nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateConfirmed, newState)
+	assert.Equal(t, msg1, bs.GetPendingConfirm()[*msg1.Header.ID])
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mch.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchCustomDefinitionRetry(t *testing.T) {
+	ag, mch, msg1, cancel := newTestAggregatorCustomDefinition(t)
+	defer cancel()
+	bs := newBatchState(ag)
+
+	mch.On("HandleDefinition", ag.ctx, bs, msg1, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: definitions.ActionRetry}, fmt.Errorf("pop"))
+
+	_, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.EqualError(t, err, "pop")
+	assert.False(t, dispatched)
+	assert.Empty(t, bs.GetPendingConfirm())
+
+	mch.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchCustomDefinitionWait(t *testing.T) {
+	ag, mch, msg1, cancel := newTestAggregatorCustomDefinition(t)
+	defer cancel()
+	bs := newBatchState(ag)
+
+	mch.On("HandleDefinition", ag.ctx, bs, msg1, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: definitions.ActionWait}, nil)
+
+	_, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.False(t, dispatched)
+	assert.Empty(t, bs.GetPendingConfirm())
+
+	mch.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchCustomDefinitionReject(t *testing.T) {
+	ag, mch, msg1, cancel := newTestAggregatorCustomDefinition(t)
+	defer cancel()
+	bs := newBatchState(ag)
+
+	mch.On("HandleDefinition", ag.ctx, bs, msg1, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: definitions.ActionReject}, nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, nil, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateRejected, newState)
+	assert.Empty(t, bs.GetPendingConfirm())
+
+	mch.AssertExpectations(t)
+}
@@ -69,7 +69,7 @@ func (em *eventManager) BatchPinComplete(bi blockchain.Plugin, batchPin *blockch
 			}
 			// Kick off a download for broadcast batches
 			if !private {
-				if err := em.sharedDownload.InitiateDownloadBatch(ctx, batchPin.Namespace, batchPin.TransactionID, batchPin.BatchPayloadRef); err != nil {
+				if err := em.downloadBroadcastBatch(ctx, batchPin); err != nil {
 					return err
 				}
 			}
@@ -79,6 +79,21 @@ func (em *eventManager) BatchPinComplete(bi blockchain.Plugin, batchPin *blockch
 	})
 }
 
+// downloadBroadcastBatch initiates the download of a broadcast batch from shared storage, unless a batch with the
+// pinned hash has already been received over data exchange in hybrid mode. The batch then keeps an empty shared
+// storage reference, which tells the aggregator it did not come from shared storage.
+func (em *eventManager) downloadBroadcastBatch(ctx context.Context, batchPin *blockchain.BatchPin) error {
+	batch, err := em.database.GetBatchByID(ctx, batchPin.BatchID)
+	if err != nil {
+		return err
+	}
+	if batch == nil || !batch.Hash.Equals(batchPin.BatchHash) {
+		return em.sharedDownload.InitiateDownloadBatch(ctx, batchPin.Namespace, batchPin.TransactionID, batchPin.BatchPayloadRef)
+	}
+	log.L(ctx).Infof("Broadcast batch %s already received - skipping download of '%s'", batch.ID, batchPin.BatchPayloadRef)
+	return nil
+}
+
 func (em *eventManager) persistBatchTransaction(ctx context.Context, batchPin *blockchain.BatchPin) error {
 	_, err := em.txHelper.PersistTransaction(ctx, batchPin.Namespace, batchPin.TransactionID, fftypes.TransactionTypeBatchPin, batchPin.Event.BlockchainTXID)
 	return err
@@ -131,6 +131,7 @@ func TestBatchPinCompleteOkBroadcast(t *testing.T) {
 		return e.Type == fftypes.EventTypeBlockchainEventReceived
 	})).Return(nil).Times(1)
 	mdi.On("InsertPins", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(nil, nil)
 	msd := em.sharedDownload.(*shareddownloadmocks.Manager)
 	msd.On("InitiateDownloadBatch", mock.Anything, "ns1", batchPin.TransactionID, batchPin.BatchPayloadRef).Return(nil)
 	mbi := &blockchainmocks.Plugin{}
@@ -246,6 +247,7 @@ func TestSequencedBroadcastInitiateDownloadFail(t *testing.T) {
 	mth.On("InsertBlockchainEvent", mock.Anything, mock.Anything).Return(nil)
 	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
 	mdi.On("InsertPins", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetBatchByID", mock.Anything, batchPin.BatchID).Return(nil, nil)
 	msd := em.sharedDownload.(*shareddownloadmocks.Manager)
 	msd.On("InitiateDownloadBatch", mock.Anything, "ns1", batchPin.TransactionID, batchPin.BatchPayloadRef).Return(fmt.Errorf("pop"))
 
@@ -414,6 +416,38 @@ func TestPersistBatchBadHash(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPersistBatchHashAlgorithm(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	batch.HashAlgorithm = fftypes.HashAlgorithmBLAKE2b256
+	bp, _ := batch.Confirmed()
+	batch.Hash, _ = fftypes.HashStringWithAlgorithm(context.Background(), fftypes.HashAlgorithmBLAKE2b256, bp.Manifest.String())
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("UpsertBatch", mock.Anything, mock.MatchedBy(func(bp *fftypes.BatchPersisted) bool {
+		return bp.HashAlgorithm == fftypes.HashAlgorithmBLAKE2b256
+	})).Return(fmt.Errorf("pop"))
+
+	_, _, err := em.persistBatch(context.Background(), batch)
+	assert.EqualError(t, err, "pop") // Confirms we got to upserting the batch
+	mdi.AssertExpectations(t)
+}
+
+func TestPersistBatchUnsupportedHashAlgorithm(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	batch.HashAlgorithm = "md5"
+
+	bp, valid, err := em.persistBatch(context.Background(), batch)
+	assert.False(t, valid)
+	assert.Nil(t, bp)
+	assert.NoError(t, err)
+}
+
 func TestPersistBatchNoData(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -708,3 +742,75 @@ func TestPersistBatchMessageOK(t *testing.T) {
 	valid := em.validateBatchMessage(context.Background(), batch, 0, batch.Payload.Messages[0])
 	assert.True(t, valid)
 }
+
+func TestDownloadBroadcastBatchAlreadyReceived(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batchPin := &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+	}
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: batchPin.BatchID},
+		Hash:        batchPin.BatchHash,
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchPin.BatchID).Return(batch, nil)
+
+	// No download, and the reference is not recorded
+	err := em.downloadBroadcastBatch(em.ctx, batchPin)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDownloadBroadcastBatchHashMismatch(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batchPin := &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+	}
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: batchPin.BatchID},
+		Hash:        fftypes.NewRandB32(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchPin.BatchID).Return(batch, nil)
+	msd := em.sharedDownload.(*shareddownloadmocks.Manager)
+	msd.On("InitiateDownloadBatch", mock.Anything, "ns1", batchPin.TransactionID, batchPin.BatchPayloadRef).Return(nil)
+
+	err := em.downloadBroadcastBatch(em.ctx, batchPin)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	msd.AssertExpectations(t)
+}
+
+func TestDownloadBroadcastBatchGetBatchFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batchPin := &blockchain.BatchPin{
+		Namespace: "ns1",
+		BatchID:   fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchPin.BatchID).Return(nil, fmt.Errorf("pop"))
+
+	err := em.downloadBroadcastBatch(em.ctx, batchPin)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
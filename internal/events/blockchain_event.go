@@ -87,6 +87,8 @@ func (em *eventManager) getTopicForChainListener(ctx context.Context, listenerID
 }
 
 func (em *eventManager) persistBlockchainEvent(ctx context.Context, chainEvent *fftypes.BlockchainEvent) error {
+	info := chainEvent.Info
+	em.truncateBlockchainEventInfo(ctx, chainEvent)
 	if err := em.txHelper.InsertBlockchainEvent(ctx, chainEvent); err != nil {
 		return err
 	}
@@ -94,6 +96,9 @@ func (em *eventManager) persistBlockchainEvent(ctx context.Context, chainEvent *
 	if err != nil {
 		return err
 	}
+	if err := em.storeBlockchainEventRaw(ctx, chainEvent, info); err != nil {
+		return err
+	}
 	ffEvent := fftypes.NewEvent(fftypes.EventTypeBlockchainEventReceived, chainEvent.Namespace, chainEvent.ID, chainEvent.TX.ID, topic)
 	if err := em.database.InsertEvent(ctx, ffEvent); err != nil {
 		return err
@@ -118,11 +123,24 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 				log.L(ctx).Warnf("Event received from unknown subscription %s", event.Subscription)
 				return nil // no retry
 			}
+			if sub.Options != nil && sub.Options.Checkpoint != nil && event.ProtocolID <= sub.Options.Checkpoint.ProtocolID {
+				// The listener was started from an imported checkpoint, and this event was already processed before it
+				log.L(ctx).Debugf("Ignoring event %s at or before the checkpoint %s of listener %s", event.ProtocolID, sub.Options.Checkpoint.ProtocolID, sub.ID)
+				return nil
+			}
 
 			chainEvent := buildBlockchainEvent(sub.Namespace, sub.ID, &event.Event, nil)
+			if sub.Options != nil && sub.Options.Confirmations > 0 {
+				// Record the depth the connector waited for, before delivering the event
+				if chainEvent.Info == nil {
+					chainEvent.Info = fftypes.JSONObject{}
+				}
+				chainEvent.Info["confirmations"] = sub.Options.Confirmations
+			}
 			if err := em.persistBlockchainEvent(ctx, chainEvent); err != nil {
 				return err
 			}
+			em.contracts.InvalidateQueryCache(sub.Namespace, sub.Location)
 			em.emitBlockchainEventMetric(&event.Event)
 			return nil
 		})
@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// truncateBlockchainEventInfo replaces the raw info of an event with a summary, if it is larger than configured.
+// The parsed output is never truncated, as that is what is delivered to applications.
+func (em *eventManager) truncateBlockchainEventInfo(ctx context.Context, chainEvent *fftypes.BlockchainEvent) {
+	if em.eventInfoMaxSize <= 0 || chainEvent.Info == nil {
+		return
+	}
+	size := int64(len(chainEvent.Info.String()))
+	if size > em.eventInfoMaxSize {
+		log.L(ctx).Debugf("Truncating info of blockchain event '%s' (%d bytes)", chainEvent.ID, size)
+		chainEvent.Info = fftypes.JSONObject{
+			"truncated": true,
+			"size":      size,
+		}
+	}
+}
+
+// storeBlockchainEventRaw retains the full raw info of an event in a separate table, if the listener asked for it
+func (em *eventManager) storeBlockchainEventRaw(ctx context.Context, chainEvent *fftypes.BlockchainEvent, info fftypes.JSONObject) error {
+	if chainEvent.Listener == nil || info == nil {
+		return nil
+	}
+	listener, err := em.getChainListenerByIDCached(ctx, chainEvent.Listener)
+	if err != nil {
+		return err
+	}
+	if listener == nil || listener.Options == nil || !listener.Options.StoreRaw {
+		return nil
+	}
+	raw, err := fftypes.NewBlockchainEventRaw(chainEvent, info, em.eventRawCompress)
+	if err != nil {
+		return err
+	}
+	return em.database.InsertBlockchainEventRaw(ctx, raw)
+}
@@ -0,0 +1,158 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPersistBlockchainEventTruncateAndStoreRaw(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	em.eventInfoMaxSize = 20
+
+	listener := &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Options:   &fftypes.ContractListenerOptions{StoreRaw: true},
+	}
+	ev := &fftypes.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Listener:  listener.ID,
+		Info: fftypes.JSONObject{
+			"logs": "a large amount of raw log data",
+		},
+	}
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.BlockchainEvent) bool {
+		return e.Info.GetBool("truncated") && e.Info["size"] == int64(len(`{"logs":"a large amount of raw log data"}`))
+	})).Return(nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, listener.ID).Return(listener, nil)
+	mdi.On("InsertBlockchainEventRaw", mock.Anything, mock.MatchedBy(func(raw *fftypes.BlockchainEventRaw) bool {
+		info, err := raw.Info(context.Background())
+		return err == nil && raw.ID == ev.ID && raw.Compressed && info.GetString("logs") == "a large amount of raw log data"
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+
+	err := em.persistBlockchainEvent(em.ctx, ev)
+	assert.NoError(t, err)
+
+	mth.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestPersistBlockchainEventStoreRawFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	listener := &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Options:   &fftypes.ContractListenerOptions{StoreRaw: true},
+	}
+	ev := &fftypes.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Listener:  listener.ID,
+		Info:      fftypes.JSONObject{"blockNumber": "10"},
+	}
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.BlockchainEvent) bool {
+		return e.Info.GetString("blockNumber") == "10"
+	})).Return(nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, listener.ID).Return(listener, nil)
+	mdi.On("InsertBlockchainEventRaw", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.persistBlockchainEvent(em.ctx, ev)
+	assert.EqualError(t, err, "pop")
+
+	mth.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestStoreBlockchainEventRawNotRequested(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	listener := &fftypes.ContractListener{ID: fftypes.NewUUID()}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, listener.ID).Return(listener, nil)
+
+	err := em.storeBlockchainEventRaw(em.ctx, &fftypes.BlockchainEvent{Listener: listener.ID}, fftypes.JSONObject{})
+	assert.NoError(t, err)
+
+	err = em.storeBlockchainEventRaw(em.ctx, &fftypes.BlockchainEvent{}, fftypes.JSONObject{})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStoreBlockchainEventRawListenerLookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	listenerID := fftypes.NewUUID()
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, listenerID).Return(nil, fmt.Errorf("pop"))
+
+	err := em.storeBlockchainEventRaw(em.ctx, &fftypes.BlockchainEvent{Listener: listenerID}, fftypes.JSONObject{})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStoreBlockchainEventRawSerializeFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	listener := &fftypes.ContractListener{
+		ID:      fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{StoreRaw: true},
+	}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, listener.ID).Return(listener, nil)
+
+	err := em.storeBlockchainEventRaw(em.ctx, &fftypes.BlockchainEvent{Listener: listener.ID}, fftypes.JSONObject{
+		"bad": map[bool]bool{true: false},
+	})
+	assert.Error(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTruncateBlockchainEventInfoWithinLimit(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	em.eventInfoMaxSize = 1024
+
+	ev := &fftypes.BlockchainEvent{Info: fftypes.JSONObject{"blockNumber": "10"}}
+	em.truncateBlockchainEventInfo(em.ctx, ev)
+	assert.Equal(t, "10", ev.Info.GetString("blockNumber"))
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
@@ -50,6 +51,7 @@ func TestContractEventWithRetries(t *testing.T) {
 		Namespace: "ns",
 		ID:        fftypes.NewUUID(),
 		Topic:     "topic1",
+		Location:  fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
 	}
 	var eventID *fftypes.UUID
 
@@ -67,10 +69,107 @@ func TestContractEventWithRetries(t *testing.T) {
 	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeBlockchainEventReceived && e.Reference != nil && e.Reference == eventID && e.Topic == "topic1"
 	})).Return(nil).Once()
+	mcm := em.contracts.(*contractmocks.Manager)
+	mcm.On("InvalidateQueryCache", "ns", sub.Location).Return().Once()
 
 	err := em.BlockchainEvent(ev)
 	assert.NoError(t, err)
 
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mcm.AssertExpectations(t)
+}
+
+func TestContractEventWithConfirmations(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			Name:           "Changed",
+			Output: fftypes.JSONObject{
+				"value": "1",
+			},
+		},
+	}
+	sub := &fftypes.ContractListener{
+		Namespace: "ns",
+		ID:        fftypes.NewUUID(),
+		Topic:     "topic1",
+		Location:  fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+		Options: &fftypes.ContractListenerOptions{
+			Confirmations: 12,
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(sub, nil)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.BlockchainEvent) bool {
+		return e.Info["confirmations"] == uint64(12)
+	})).Return(nil)
+	mdi.On("GetContractListenerByID", mock.Anything, sub.ID).Return(sub, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+	mcm := em.contracts.(*contractmocks.Manager)
+	mcm.On("InvalidateQueryCache", "ns", sub.Location).Return()
+
+	err := em.BlockchainEvent(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestContractEventBeforeCheckpoint(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	sub := &fftypes.ContractListener{
+		Namespace: "ns",
+		ID:        fftypes.NewUUID(),
+		Location:  fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+		Options: &fftypes.ContractListenerOptions{
+			Checkpoint: &fftypes.ContractListenerCheckpoint{
+				FirstEvent: "100",
+				ProtocolID: "000000000100/000002/000001",
+			},
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(sub, nil)
+
+	for _, protocolID := range []string{"000000000100/000001/000005", "000000000100/000002/000001"} {
+		err := em.BlockchainEvent(&blockchain.EventWithSubscription{
+			Subscription: "sb-1",
+			Event: blockchain.Event{
+				ProtocolID: protocolID,
+				Name:       "Changed",
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.BlockchainEvent) bool {
+		return e.ProtocolID == "000000000100/000002/000002"
+	})).Return(nil)
+	mdi.On("GetContractListenerByID", mock.Anything, sub.ID).Return(sub, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+	mcm := em.contracts.(*contractmocks.Manager)
+	mcm.On("InvalidateQueryCache", "ns", sub.Location).Return()
+
+	err := em.BlockchainEvent(&blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			ProtocolID: "000000000100/000002/000002",
+			Name:       "Changed",
+		},
+	})
+	assert.NoError(t, err)
+
 	mdi.AssertExpectations(t)
 	mth.AssertExpectations(t)
 }
@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// circuitStateChanged records an event in the system namespace each time the circuit breaker
+// of a plugin opens or closes. The topic of the event is the name of the circuit.
+func (em *eventManager) circuitStateChanged(status *fftypes.CircuitStatus) {
+	eventType := fftypes.EventTypeCircuitClosed
+	if status.State == fftypes.CircuitStateOpen {
+		eventType = fftypes.EventTypeCircuitOpened
+	}
+	event := fftypes.NewEvent(eventType, fftypes.SystemNamespace, status.ID, nil, status.Name)
+	if err := em.database.InsertEvent(em.ctx, event); err != nil {
+		log.L(em.ctx).Errorf("Failed to record %s event for circuit '%s': %s", eventType, status.Name, err)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitStateChanged(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	circuitID := fftypes.NewUUID()
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeCircuitOpened && e.Namespace == fftypes.SystemNamespace &&
+			e.Reference.Equals(circuitID) && e.Topic == "ethconnect"
+	})).Return(nil).Once()
+	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeCircuitClosed
+	})).Return(fmt.Errorf("pop")).Once()
+
+	em.circuitStateChanged(&fftypes.CircuitStatus{ID: circuitID, Name: "ethconnect", State: fftypes.CircuitStateOpen})
+	em.circuitStateChanged(&fftypes.CircuitStatus{ID: circuitID, Name: "ethconnect", State: fftypes.CircuitStateClosed})
+
+	mdi.AssertExpectations(t)
+}
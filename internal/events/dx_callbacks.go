@@ -19,7 +19,6 @@ package events
 import (
 	"context"
 	"database/sql/driver"
-	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -34,16 +33,31 @@ func (em *eventManager) MessageReceived(dx dataexchange.Plugin, peerID string, d
 
 	// De-serializae the transport wrapper
 	var wrapper *fftypes.TransportWrapper
-	err = json.Unmarshal(data, &wrapper)
+	protocolVersion, err := fftypes.DeserializeTransportPayload(em.ctx, data, em.dxPayloadLimit, &wrapper)
 	if err != nil {
 		l.Errorf("Invalid transmission from %s peer '%s': %s", dx.Name(), peerID, err)
 		return "", nil
 	}
+	if wrapper.Ack != nil {
+		return "", em.deliveryAckReceived(dx, peerID, wrapper.Ack)
+	}
 	if wrapper.Batch == nil {
 		l.Errorf("Invalid transmission: nil batch")
 		return "", nil
 	}
-	l.Infof("Private batch received from %s peer '%s' (len=%d)", dx.Name(), peerID, len(data))
+	wrapper.Batch.ProtocolVersion = protocolVersion
+
+	if wrapper.Batch.Type == fftypes.BatchTypeBroadcast {
+		// A broadcast batch sent directly in hybrid mode, which is processed exactly as if it had been downloaded
+		// from shared storage. The aggregator only confirms it once a pin with the same hash arrives.
+		if wrapper.Batch.Payload.TX.Type != fftypes.TransactionTypeBatchPin {
+			l.Errorf("Invalid transmission: broadcast batch with transaction type %s", wrapper.Batch.Payload.TX.Type)
+			return "", nil
+		}
+		l.Infof("Broadcast batch received from %s peer '%s' (len=%d,protocolVersion=%d)", dx.Name(), peerID, len(data), protocolVersion)
+		return em.offchainBatchReceived(peerID, wrapper.Batch)
+	}
+	l.Infof("Private batch received from %s peer '%s' (len=%d,protocolVersion=%d)", dx.Name(), peerID, len(data), protocolVersion)
 
 	if wrapper.Batch.Payload.TX.Type == fftypes.TransactionTypeUnpinned {
 		valid, err := em.definitions.EnsureLocalGroup(em.ctx, wrapper.Group)
@@ -56,7 +70,7 @@ func (em *eventManager) MessageReceived(dx dataexchange.Plugin, peerID string, d
 		}
 	}
 
-	manifestString, err := em.privateBatchReceived(peerID, wrapper.Batch)
+	manifestString, err := em.offchainBatchReceived(peerID, wrapper.Batch)
 	return manifestString, err
 }
 
@@ -109,10 +123,10 @@ func (em *eventManager) checkReceivedOffchainIdentity(ctx context.Context, peerI
 	return node, nil
 }
 
-func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch) (manifest string, err error) {
+func (em *eventManager) offchainBatchReceived(peerID string, batch *fftypes.Batch) (manifest string, err error) {
 
 	// Retry for persistence errors (not validation errors)
-	err = em.retry.Do(em.ctx, "private batch received", func(attempt int) (bool, error) {
+	err = em.retry.Do(em.ctx, "offchain batch received", func(attempt int) (bool, error) {
 		return true, em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
 			l := log.L(ctx)
 
@@ -125,6 +139,19 @@ func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch
 				return nil
 			}
 
+			if batch.Type == fftypes.BatchTypeBroadcast {
+				// A broadcast batch might already have been downloaded from shared storage, if the pin arrived first
+				existing, err := em.database.GetBatchByID(ctx, batch.ID)
+				if err != nil {
+					return err
+				}
+				if existing != nil && existing.Hash.Equals(batch.Hash) {
+					l.Infof("Broadcast batch %s has already been received", batch.ID)
+					manifest = existing.Manifest.String()
+					return nil
+				}
+			}
+
 			persistedBatch, valid, err := em.persistBatch(ctx, batch)
 			if err != nil || !valid {
 				l.Errorf("Batch received from org=%s node=%s processing failed valid=%t: %s", node.Parent, node.Name, valid, err)
@@ -143,7 +170,7 @@ func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch
 	})
 	// Poke the aggregator to do its stuff - after we have committed the transaction so the pins are visible
 	if err == nil && batch.Payload.TX.Type == fftypes.TransactionTypeBatchPin {
-		log.L(em.ctx).Debugf("Rewinding for persisted private batch %s", batch.ID)
+		log.L(em.ctx).Debugf("Rewinding for persisted %s batch %s", batch.Type, batch.ID)
 		em.aggregator.rewindBatches <- *batch.ID
 	}
 	return manifest, err
@@ -181,11 +208,65 @@ func (em *eventManager) markUnpinnedMessagesConfirmed(ctx context.Context, batch
 				return err
 			}
 		}
+		if em.deliveryAcks {
+			if err := em.messaging.SendDeliveryAck(ctx, batch.ID, msg, fftypes.MessageStateConfirmed); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// deliveryAckReceived updates the status of a recipient of a private message sent by this node. Only the
+// node that owns the data exchange peer the acknowledgement came from is updated, only when the acknowledgement
+// is signed by the key that node advertises in its profile, and only for messages that were tracked as sent to that node.
+func (em *eventManager) deliveryAckReceived(dx dataexchange.Plugin, peerID string, ack *fftypes.DeliveryAck) error {
+	l := log.L(em.ctx)
+
+	status, valid := ack.RecipientStatus()
+	if !valid || ack.Message == nil {
+		l.Errorf("Invalid delivery acknowledgement from %s peer '%s': message=%s state=%s", dx.Name(), peerID, ack.Message, ack.State)
+		return nil
+	}
+
+	return em.retry.Do(em.ctx, "delivery ack received", func(attempt int) (bool, error) {
+		node, err := em.identity.FindIdentityForVerifier(em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+			Type:  fftypes.VerifierTypeFFDXPeerID,
+			Value: peerID,
+		})
+		if err != nil {
+			return true, err
+		}
+		if node == nil {
+			l.Errorf("Delivery acknowledgement for message %s received from unknown %s peer '%s'", ack.Message, dx.Name(), peerID)
+			return false, nil
+		}
+		if !ack.VerifySignature(node.Profile.GetString(fftypes.DeliveryAckKeyProfileKey)) {
+			l.Errorf("Delivery acknowledgement for message %s from node %s has a missing or invalid signature", ack.Message, node.ID)
+			return false, nil
+		}
+
+		fb := database.MessageRecipientQueryFactory.NewFilter(em.ctx)
+		recipients, _, err := em.database.GetMessageRecipients(em.ctx, fb.And(
+			fb.Eq("message", ack.Message),
+			fb.Eq("node", node.ID),
+		))
+		if err != nil {
+			return true, err
+		}
+		if len(recipients) == 0 {
+			l.Warnf("Delivery acknowledgement received from node %s for untracked message %s", node.ID, ack.Message)
+			return false, nil
+		}
+
+		l.Infof("Message %s %s by node %s", ack.Message, status, node.ID)
+		recipient := recipients[0]
+		recipient.Status = status
+		return true, em.database.UpsertMessageRecipient(em.ctx, recipient)
+	})
+}
+
 func (em *eventManager) PrivateBLOBReceived(dx dataexchange.Plugin, peerID string, hash fftypes.Bytes32, size int64, payloadRef string) error {
 	l := log.L(em.ctx)
 	l.Infof("Blob received event from data exchange %s: Peer='%s' Hash='%v' PayloadRef='%s'", dx.Name(), peerID, &hash, payloadRef)
@@ -202,19 +283,29 @@ func (em *eventManager) blobReceivedCommon(peerID string, hash fftypes.Bytes32,
 	// We process the event in a retry loop (which will break only if the context is closed), so that
 	// we only confirm consumption of the event to the plugin once we've processed it.
 	return em.retry.Do(em.ctx, "blob reference insert", func(attempt int) (retry bool, err error) {
+		blob := &fftypes.Blob{
+			Peer:       peerID,
+			PayloadRef: payloadRef,
+			Hash:       &hash,
+			Size:       size,
+			Created:    fftypes.Now(),
+		}
+		// Data can refer to the blob by a hash calculated with a different algorithm to the one used by data exchange
+		alternates, err := em.data.AlternateBlobs(em.ctx, blob)
+		if err != nil {
+			return true, err
+		}
 		return true, em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
 			// Insert the blob into the detabase
-			err := em.database.InsertBlob(ctx, &fftypes.Blob{
-				Peer:       peerID,
-				PayloadRef: payloadRef,
-				Hash:       &hash,
-				Size:       size,
-				Created:    fftypes.Now(),
-			})
-			if err != nil {
-				return err
+			for _, b := range append([]*fftypes.Blob{blob}, alternates...) {
+				if err := em.database.InsertBlob(ctx, b); err != nil {
+					return err
+				}
+				if err := em.aggregator.rewindForBlobArrival(ctx, b.Hash); err != nil {
+					return err
+				}
 			}
-			return em.aggregator.rewindForBlobArrival(ctx, &hash)
+			return nil
 		})
 	})
 }
@@ -253,7 +344,7 @@ func (em *eventManager) TransferResult(dx dataexchange.Plugin, trackingID string
 		op := operations[0]
 		if status == fftypes.OpStatusSucceeded && dx.Capabilities().Manifest {
 			switch op.Type {
-			case fftypes.OpTypeDataExchangeSendBatch:
+			case fftypes.OpTypeDataExchangeSendBatch, fftypes.OpTypeDataExchangeSendBroadcastBatch:
 				batchID, _ := fftypes.ParseUUID(em.ctx, op.Input.GetString("batch"))
 				expectedManifest := ""
 				if batchID != nil {
@@ -284,9 +375,22 @@ func (em *eventManager) TransferResult(dx dataexchange.Plugin, trackingID string
 			}
 		}
 
+		// Progress is only of interest while the transfer is still in flight, and must not move
+		// an operation back to pending if the final result overtook it
+		if update.Progress != nil {
+			if op.Status != fftypes.OpStatusPending {
+				log.L(em.ctx).Debugf("Ignoring progress for %s transfer %s in status %s", dx.Name(), trackingID, op.Status)
+				return false, nil
+			}
+			if update.Info == nil {
+				update.Info = fftypes.JSONObject{}
+			}
+			update.Info["progress"] = update.Progress
+		}
+
 		// Resolve the operation
 		// Note that we don't need the manifest to be kept here, as it's already in the input
-		if err := em.database.ResolveOperation(em.ctx, op.ID, status, update.Error, update.Info); err != nil {
+		if err := em.txHelper.ResolveOperation(em.ctx, op.ID, status, update.Error, update.Info); err != nil {
 			return true, err // this is always retryable
 		}
 		return false, nil
@@ -18,6 +18,8 @@ package events
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -28,6 +30,8 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -113,6 +117,186 @@ func TestPinnedReceiveOK(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestPinnedReceiveCompressedOK(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypePrivate, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	batch.ProtocolVersion = fftypes.ProtocolVersion2
+	bp, _ := batch.Confirmed()
+	batch.Hash = fftypes.HashString(bp.Manifest.String())
+	b, err := fftypes.SerializeTransportPayload(em.ctx, fftypes.ProtocolVersion2, &fftypes.TransportWrapper{Batch: batch})
+	assert.NoError(t, err)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mdx.On("Name").Return("utdx").Maybe()
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Contains(t, m, `"version":2`)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastReceiveOK(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	b, _ := json.Marshal(&fftypes.TransportWrapper{Batch: batch})
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetBatchByID", em.ctx, batch.ID).Return(nil, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mdx.On("Name").Return("utdx").Maybe()
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m)
+	assert.Equal(t, *batch.ID, <-em.aggregator.rewindBatches)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastReceiveAlreadyStored(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	b, _ := json.Marshal(&fftypes.TransportWrapper{Batch: batch})
+	bp, _ := batch.Confirmed()
+	bp.PayloadRef = "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD"
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	// Checked in the same transaction as the write, and the stored batch is left as it is
+	mdi.On("GetBatchByID", em.ctx, batch.ID).Return(bp, nil)
+	mdx.On("Name").Return("utdx").Maybe()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Equal(t, bp.Manifest.String(), m)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "UpsertBatch", mock.Anything, mock.Anything)
+}
+
+func TestBroadcastReceiveLookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid retry
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	b, _ := json.Marshal(&fftypes.TransportWrapper{Batch: batch})
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetBatchByID", em.ctx, batch.ID).Return(nil, fmt.Errorf("pop"))
+	mdx.On("Name").Return("utdx").Maybe()
+
+	_, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBroadcastReceiveUnpinnedIgnored(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeUnpinned, fftypes.DataArray{data})
+	b, _ := json.Marshal(&fftypes.TransportWrapper{Batch: batch})
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+}
+
+func TestPinnedReceiveProtocolMismatchIgnored(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	// Sealed with a v2 manifest, but sent uncompressed
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypePrivate, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	batch.ProtocolVersion = fftypes.ProtocolVersion2
+	bp, _ := batch.Confirmed()
+	batch.Hash = fftypes.HashString(bp.Manifest.String())
+	b, err := fftypes.SerializeTransportPayload(em.ctx, fftypes.ProtocolVersion1, &fftypes.TransportWrapper{Batch: batch})
+	assert.NoError(t, err)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdx.On("Name").Return("utdx").Maybe()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdx.AssertExpectations(t)
+}
+
 func TestMessageReceiveOkBadBatchIgnored(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -185,6 +369,24 @@ func TestMessageReceivedBadData(t *testing.T) {
 
 }
 
+func TestMessageReceivedPayloadTooLarge(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	em.dxPayloadLimit = 100
+
+	b, err := fftypes.SerializeTransportPayload(em.ctx, fftypes.ProtocolVersion2, &fftypes.TransportWrapper{
+		Batch: &fftypes.Batch{BatchHeader: fftypes.BatchHeader{Namespace: strings.Repeat("a", 1000)}},
+	})
+	assert.NoError(t, err)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+}
+
 func TestMessageReceivedUnknownType(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -348,6 +550,9 @@ func TestPrivateBLOBReceivedTriggersRewindOk(t *testing.T) {
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, nil)
+
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{
@@ -366,6 +571,55 @@ func TestPrivateBLOBReceivedTriggersRewindOk(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestPrivateBLOBReceivedAlternateHashes(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	hash := fftypes.NewRandB32()
+	altHash := fftypes.NewRandB32()
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.MatchedBy(func(blob *fftypes.Blob) bool {
+		return blob.Hash.Equals(hash) && blob.PayloadRef == "ns1/path1"
+	})).Return([]*fftypes.Blob{
+		{Hash: altHash, HashAlgorithm: fftypes.HashAlgorithmSHA3256, PayloadRef: "ns1/path1"},
+	}, nil)
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertBlob", em.ctx, mock.MatchedBy(func(blob *fftypes.Blob) bool {
+		return blob.Hash.Equals(hash) && blob.HashAlgorithm == ""
+	})).Return(nil)
+	mdi.On("InsertBlob", em.ctx, mock.MatchedBy(func(blob *fftypes.Blob) bool {
+		return blob.Hash.Equals(altHash) && blob.HashAlgorithm == fftypes.HashAlgorithmSHA3256
+	})).Return(nil)
+	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{}, nil, nil).Twice()
+
+	err := em.PrivateBLOBReceived(mdx, "peer1", *hash, 12345, "ns1/path1")
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPrivateBLOBReceivedAlternateBlobsFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error
+	hash := fftypes.NewRandB32()
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := em.PrivateBLOBReceived(mdx, "peer1", *hash, 12345, "ns1/path1")
+	assert.Regexp(t, "FF10158", err)
+
+	mdm.AssertExpectations(t)
+}
+
 func TestPrivateBLOBReceivedBadEvent(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -386,6 +640,9 @@ func TestPrivateBLOBReceivedGetMessagesFail(t *testing.T) {
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, nil)
+
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{
@@ -407,6 +664,9 @@ func TestPrivateBLOBReceivedGetDataRefsFail(t *testing.T) {
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, nil)
+
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -425,6 +685,9 @@ func TestPrivateBLOBReceivedInsertBlobFails(t *testing.T) {
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, nil)
+
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(fmt.Errorf("pop"))
 
@@ -439,13 +702,14 @@ func TestTransferResultOk(t *testing.T) {
 	defer cancel()
 
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	id := fftypes.NewUUID()
 	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
 		{
 			ID: id,
 		},
 	}, nil, nil)
-	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, "error info", fftypes.JSONObject{
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, "error info", fftypes.JSONObject{
 		"extra": "info",
 	}).Return(nil)
 
@@ -458,11 +722,63 @@ func TestTransferResultOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTransferResultProgress(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	id := fftypes.NewUUID()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{
+			ID:     id,
+			Status: fftypes.OpStatusPending,
+		},
+	}, nil, nil)
+	progress := &fftypes.TransferProgress{Transferred: 50, Total: 200, Percent: 25}
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusPending, "", fftypes.JSONObject{
+		"progress": progress,
+	}).Return(nil)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+	err := em.TransferResult(mdx, id.String(), fftypes.OpStatusPending, fftypes.TransportStatusUpdate{
+		Progress: progress,
+	})
+	assert.NoError(t, err)
+
+	mth.AssertExpectations(t)
+}
+
+func TestTransferResultProgressAfterComplete(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	id := fftypes.NewUUID()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{
+			ID:     id,
+			Status: fftypes.OpStatusSucceeded,
+		},
+	}, nil, nil)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+	err := em.TransferResult(mdx, id.String(), fftypes.OpStatusPending, fftypes.TransportStatusUpdate{
+		Progress: &fftypes.TransferProgress{Transferred: 50, Total: 200, Percent: 25},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestTransferResultManifestMismatch(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
 
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	id := fftypes.NewUUID()
 	mdi.On("GetBatchByID", mock.Anything, mock.Anything).Return(&fftypes.BatchPersisted{
 		Manifest: fftypes.JSONAnyPtr("my-manifest"),
@@ -476,7 +792,7 @@ func TestTransferResultManifestMismatch(t *testing.T) {
 			},
 		},
 	}, nil, nil)
-	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
 		return strings.Contains(errorMsg, "FF10329")
 	}), fftypes.JSONObject{
 		"extra": "info",
@@ -500,6 +816,7 @@ func TestTransferResultManifestFamil(t *testing.T) {
 	cancel()
 
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	id := fftypes.NewUUID()
 	mdi.On("GetBatchByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
 	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
@@ -511,7 +828,7 @@ func TestTransferResultManifestFamil(t *testing.T) {
 			},
 		},
 	}, nil, nil)
-	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
 		return strings.Contains(errorMsg, "FF10329")
 	}), fftypes.JSONObject{
 		"extra": "info",
@@ -535,6 +852,7 @@ func TestTransferResultHashtMismatch(t *testing.T) {
 	defer cancel()
 
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	id := fftypes.NewUUID()
 	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
 		{
@@ -545,7 +863,7 @@ func TestTransferResultHashtMismatch(t *testing.T) {
 			},
 		},
 	}, nil, nil)
-	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
 		return strings.Contains(errorMsg, "FF10348")
 	}), fftypes.JSONObject{
 		"extra": "info",
@@ -620,13 +938,14 @@ func TestTransferUpdateFail(t *testing.T) {
 	cancel() // retryable error
 
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	id := fftypes.NewUUID()
 	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
 		{
 			ID: id,
 		},
 	}, nil, nil)
-	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, "error info", fftypes.JSONObject{
+	mth.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, "error info", fftypes.JSONObject{
 		"extra": "info",
 	}).Return(fmt.Errorf("pop"))
 
@@ -842,6 +1161,46 @@ func TestMessageReceiveUnpinnedBatchOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestMessageReceiveUnpinnedBatchAckFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid infinite retry
+	em.deliveryAcks = true
+
+	_, b := sampleBatchTransfer(t, fftypes.TransactionTypeUnpinned)
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+
+	msh := em.definitions.(*definitionsmocks.DefinitionHandlers)
+	msh.On("EnsureLocalGroup", em.ctx, mock.Anything).Return(true, nil)
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookupMustExist", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+	mpm := em.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendDeliveryAck", em.ctx, mock.Anything, mock.Anything, fftypes.MessageStateConfirmed).Return(fmt.Errorf("pop"))
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
 func TestMessageReceiveUnpinnedBatchConfirmMessagesFail(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	cancel() // to avoid infinite retry
@@ -958,3 +1317,187 @@ func TestMessageReceiveMessageEnsureLocalGroupReject(t *testing.T) {
 	mdi.AssertExpectations(t)
 	mdx.AssertExpectations(t)
 }
+
+func deliveryAckTransfer(t *testing.T, ack *fftypes.DeliveryAck) []byte {
+	b, err := json.Marshal(&fftypes.TransportWrapper{Ack: ack})
+	assert.NoError(t, err)
+	return b
+}
+
+func newTestAckSigningNode(t *testing.T, node *fftypes.Identity) ed25519.PrivateKey {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	node.Profile[fftypes.DeliveryAckKeyProfileKey] = base64.StdEncoding.EncodeToString(pub)
+	return priv
+}
+
+func TestDeliveryAckReceivedOk(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	key := newTestAckSigningNode(t, node1)
+	recipient := &fftypes.MessageRecipient{
+		Message: fftypes.NewUUID(),
+		Node:    node1.ID,
+		Status:  fftypes.MessageRecipientStatusPending,
+	}
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageRecipients", em.ctx, mock.Anything).Return([]*fftypes.MessageRecipient{recipient}, nil, nil)
+	mdi.On("UpsertMessageRecipient", em.ctx, mock.MatchedBy(func(r *fftypes.MessageRecipient) bool {
+		return r == recipient && r.Status == fftypes.MessageRecipientStatusRejected
+	})).Return(nil)
+
+	ack := &fftypes.DeliveryAck{
+		Message: recipient.Message,
+		State:   fftypes.MessageStateRejected,
+	}
+	ack.Sign(key)
+	m, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, ack))
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestDeliveryAckReceivedBadSignature(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+	newTestAckSigningNode(t, node1)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+
+	ack := &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStateConfirmed,
+	}
+	ack.Sign(otherKey)
+	_, err = em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, ack))
+	assert.NoError(t, err)
+
+	ack.Signature = ""
+	_, err = em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, ack))
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestDeliveryAckReceivedInvalid(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+
+	m, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStatePending,
+	}))
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	m, err = em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, &fftypes.DeliveryAck{
+		State: fftypes.MessageStateConfirmed,
+	}))
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+}
+
+func TestDeliveryAckReceivedNodeLookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid infinite retry
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, fftypes.SystemNamespace, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStateConfirmed,
+	}))
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestDeliveryAckReceivedUnknownNode(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, fftypes.SystemNamespace, mock.Anything).Return(nil, nil)
+
+	_, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStateConfirmed,
+	}))
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestDeliveryAckReceivedGetRecipientsFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid infinite retry
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+	key := newTestAckSigningNode(t, node1)
+	ack := &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStateConfirmed,
+	}
+	ack.Sign(key)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageRecipients", em.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, ack))
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestDeliveryAckReceivedUntracked(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+	key := newTestAckSigningNode(t, node1)
+	ack := &fftypes.DeliveryAck{
+		Message: fftypes.NewUUID(),
+		State:   fftypes.MessageStateConfirmed,
+	}
+	ack.Sign(key)
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx").Maybe()
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageRecipients", em.ctx, mock.Anything).Return([]*fftypes.MessageRecipient{}, nil, nil)
+
+	_, err := em.MessageReceived(mdx, "peer1", deliveryAckTransfer(t, ack))
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -56,10 +57,13 @@ type eventDispatcher struct {
 	elected       bool
 	eventPoller   *eventPoller
 	inflight      map[fftypes.UUID]*fftypes.Event
+	inflightKeys  map[fftypes.UUID]string
 	eventDelivery chan *fftypes.EventDelivery
 	mux           sync.Mutex
 	namespace     string
+	parked        map[fftypes.UUID]int64
 	readAhead     int
+	redeliveries  map[fftypes.UUID]bool
 	subscription  *subscription
 	cel           *changeEventListener
 	changeEvents  chan *fftypes.ChangeEvent
@@ -88,9 +92,12 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 		subscription:  sub,
 		namespace:     sub.definition.Namespace,
 		inflight:      make(map[fftypes.UUID]*fftypes.Event),
+		inflightKeys:  make(map[fftypes.UUID]string),
 		eventDelivery: make(chan *fftypes.EventDelivery, readAhead+1),
 		changeEvents:  make(chan *fftypes.ChangeEvent),
+		parked:        make(map[fftypes.UUID]int64),
 		readAhead:     int(readAhead),
+		redeliveries:  make(map[fftypes.UUID]bool),
 		acksNacks:     make(chan ackNack),
 		closed:        make(chan struct{}),
 		cel:           cel,
@@ -145,6 +152,7 @@ func (ed *eventDispatcher) electAndStart() {
 	}
 	// We're ready to go - not
 	ed.elected = true
+	ed.loadRedeliveries()
 	ed.eventPoller.start()
 	go ed.deliverEvents()
 	// Wait until the event poller closes
@@ -180,6 +188,10 @@ func (ed *eventDispatcher) enrichEvents(events []fftypes.LocallySequenced) ([]*f
 func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*fftypes.EventDelivery {
 	matchingEvents := make([]*fftypes.EventDelivery, 0, len(candidates))
 	for _, event := range candidates {
+		if _, parked := ed.parked[*event.ID]; parked {
+			// Parked while an earlier event was incomplete, so the offset could not move past it
+			continue
+		}
 		filter := ed.subscription
 		if filter.eventMatcher != nil && !filter.eventMatcher.MatchString(string(event.Type)) {
 			continue
@@ -223,6 +235,17 @@ func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*
 			}
 		}
 
+		if filter.topicSetFilter != nil && !filter.topicSetFilter.matches(topic) {
+			continue
+		}
+
+		if filter.routingRules != nil {
+			event.Labels = filter.routingRules.labels(event.Namespace, tag, topic)
+		}
+		if filter.labelFilter != nil && !labelsMatch(filter.labelFilter, event.Labels) {
+			continue
+		}
+
 		if filter.messageFilter != nil {
 			if filter.messageFilter.tagFilter != nil && !filter.messageFilter.tagFilter.MatchString(tag) {
 				continue
@@ -255,6 +278,15 @@ func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*
 	return matchingEvents
 }
 
+func labelsMatch(labelFilter map[string]bool, labels []string) bool {
+	for _, label := range labels {
+		if labelFilter[label] {
+			return true
+		}
+	}
+	return false
+}
+
 func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (bool, error) {
 	// At this point, the page of messages we've been given are loaded from the DB into memory,
 	// but we can only make them in-flight and push them to the client up to the maximum
@@ -266,8 +298,11 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 	highestOffset := events[len(events)-1].LocalSequence()
 	var lastAck int64
 	var nacks int
+	var rewound int64
+	var redeliveryDelay time.Duration
 
 	l := log.L(ed.ctx)
+	ed.pruneParked()
 	candidates, err := ed.enrichEvents(events)
 	if err != nil {
 		return false, err
@@ -275,6 +310,7 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 
 	matching := ed.filterEvents(candidates)
 	matchCount := len(matching)
+	ordered := ed.subscription.definition.Options.OrderedBy != ""
 	dispatched := 0
 
 	// We stay here blocked until we've consumed all the messages in the buffer,
@@ -284,7 +320,9 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 		var disapatchable []*fftypes.EventDelivery
 		inflightCount := len(ed.inflight)
 		maxDispatch := 1 + ed.readAhead - inflightCount
-		if maxDispatch >= len(matching) {
+		if ordered {
+			disapatchable, matching = ed.orderedDispatchable(matching, maxDispatch)
+		} else if maxDispatch >= len(matching) {
 			disapatchable = matching
 			matching = nil
 		} else if maxDispatch > 0 {
@@ -299,10 +337,14 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 		for _, event := range disapatchable {
 			ed.mux.Lock()
 			ed.inflight[*event.ID] = &event.Event
+			if ordered {
+				ed.inflightKeys[*event.ID] = ed.orderingKey(event)
+			}
 			inflightCount = len(ed.inflight)
 			ed.mux.Unlock()
 
 			dispatched++
+			ed.subscription.stats.dispatched(event.Sequence)
 			ed.eventDelivery <- event
 		}
 
@@ -316,18 +358,69 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 		case <-ed.ctx.Done():
 			return false, i18n.NewError(ed.ctx, i18n.MsgDispatcherClosing)
 		case an := <-ed.acksNacks:
-			if an.isNack {
+			if an.isNack && ordered {
 				nacks++
+				parkOffset := ed.parkedCommitOffset(an, matching, rewound)
+				// Events on other keys are still being delivered, so are left in-flight
+				matching = ed.handleOrderedNackOffsetUpdate(an, matching)
+				parked := false
+				if ed.subscription.definition.Options.Redelivery != nil {
+					var delay time.Duration
+					delay, parked, err = ed.recordRejection(an)
+					if err != nil {
+						return false, err
+					}
+					if parked && parkOffset > ed.eventPoller.getPollingOffset() {
+						// Move past the event if we can, without going back over events on other keys
+						ed.eventPoller.commitOffset(parkOffset)
+					}
+					redeliveryDelay = delay
+				}
+				if !parked && (rewound == 0 || an.offset < rewound) {
+					rewound = an.offset
+				}
+			} else if an.isNack {
+				nacks++
+				parkOffset := ed.parkedCommitOffset(an, matching, 0)
 				ed.handleNackOffsetUpdate(an)
-			} else if nacks == 0 {
-				ed.handleAckOffsetUpdate(an)
-				lastAck = an.offset
+				if ed.subscription.definition.Options.Redelivery != nil {
+					delay, parked, err := ed.recordRejection(an)
+					if err != nil {
+						return false, err
+					}
+					if parked {
+						// Move past the event if we can, so it is not redelivered
+						ed.eventPoller.commitOffset(parkOffset)
+					}
+					// Hold back the rest of the page, as it will all be redelivered after the delay
+					matching = nil
+					redeliveryDelay = delay
+				}
+			} else {
+				ed.clearRejections(an)
+				if nacks == 0 {
+					ed.handleAckOffsetUpdate(an)
+					lastAck = an.offset
+				} else if ordered {
+					// Already rewound for redelivery, so we just stop tracking the event
+					ed.mux.Lock()
+					delete(ed.inflight, an.id)
+					delete(ed.inflightKeys, an.id)
+					ed.mux.Unlock()
+				}
 			}
 		}
 	}
 	if nacks == 0 && lastAck != highestOffset {
 		ed.eventPoller.commitOffset(highestOffset)
 	}
+	if redeliveryDelay > 0 {
+		select {
+		case <-time.After(redeliveryDelay):
+		case <-ed.ctx.Done():
+			return false, i18n.NewError(ed.ctx, i18n.MsgDispatcherClosing)
+		}
+	}
 	return true, nil // poll again straight away for more messages
 }
 
@@ -342,12 +435,14 @@ func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 		ed.eventPoller.rewindPollingOffset(nack.offset - 1)
 	}
 	ed.inflight = map[fftypes.UUID]*fftypes.Event{}
+	ed.inflightKeys = map[fftypes.UUID]string{}
 }
 
 func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
 	delete(ed.inflight, ack.id)
+	delete(ed.inflightKeys, ack.id)
 	lowestInflight := int64(-1)
 	for _, inflight := range ed.inflight {
 		if lowestInflight < 0 || inflight.Sequence < lowestInflight {
@@ -377,23 +472,19 @@ func (ed *eventDispatcher) deliverEvents() {
 		defer ed.cel.removeDispatcher(*ed.subscription.definition.ID)
 	}
 	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	ordered := ed.subscription.definition.Options.OrderedBy != ""
 	for {
 		select {
 		case event, ok := <-ed.eventDelivery:
 			if !ok {
 				return
 			}
-			log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
-			var data []*fftypes.Data
-			var err error
-			if withData && event.Message != nil {
-				data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
-			}
-			if err == nil {
-				err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
-			}
-			if err != nil {
-				ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID, Rejected: true})
+			if ordered {
+				// Only one event per key is ever in-flight, so events on different keys
+				// can be delivered in parallel without breaking the order within a key
+				go ed.deliverEvent(event, withData)
+			} else {
+				ed.deliverEvent(event, withData)
 			}
 		case changeEvent := <-ed.changeEvents:
 			ws, ok := ed.transport.(events.ChangeEventListener)
@@ -407,6 +498,21 @@ func (ed *eventDispatcher) deliverEvents() {
 		}
 	}
 }
+func (ed *eventDispatcher) deliverEvent(event *fftypes.EventDelivery, withData bool) {
+	log.L(log.WithCorrelationID(ed.ctx, event.CorrelationID)).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
+	var data []*fftypes.Data
+	var err error
+	if withData && event.Message != nil {
+		data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
+	}
+	if err == nil {
+		err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
+	}
+	if err != nil {
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID, Rejected: true})
+	}
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
@@ -425,11 +531,14 @@ func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryRespo
 		l.Warnf("Response for event not in flight: %s rejected=%t info='%s' (likely previous reject)", response.ID, response.Rejected, response.Info)
 		return
 	}
+	ctx := log.WithCorrelationID(ed.ctx, event.CorrelationID)
+	l = log.L(ctx)
 
 	// We might have a message to send, do that before we dispatch the ack
 	// Note a failure to send the reply does not invalidate the ack
+	// The reply carries the same correlation ID as the event it responds to
 	if response.Reply != nil {
-		ed.definitions.SendReply(ed.ctx, event, response.Reply)
+		ed.definitions.SendReply(ctx, event, response.Reply, response.ReplyTo)
 	}
 
 	l.Debugf("Response for %s event: %.10d/%s [%s]: ref=%s/%s rejected=%t info='%s'", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference, response.Rejected, response.Info)
@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// handleOrderedNackOffsetUpdate rewinds to redeliver a rejected event, for a subscription with the
+// orderedBy option set. Unlike handleNackOffsetUpdate it leaves the other events in-flight, as they are
+// on different keys and are still being delivered. It returns the held events that come before the
+// rejected event, as the rewind will not redeliver them
+func (ed *eventDispatcher) handleOrderedNackOffsetUpdate(nack ackNack, held []*fftypes.EventDelivery) []*fftypes.EventDelivery {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	delete(ed.inflight, nack.id)
+	delete(ed.inflightKeys, nack.id)
+	if ed.eventPoller.pollingOffset > nack.offset {
+		ed.eventPoller.rewindPollingOffset(nack.offset - 1)
+	}
+	remaining := make([]*fftypes.EventDelivery, 0, len(held))
+	for _, event := range held {
+		if event.Sequence < nack.offset {
+			remaining = append(remaining, event)
+		}
+	}
+	return remaining
+}
+
+// orderingKey returns the key within which events must be delivered in order,
+// for a subscription with the orderedBy option set
+func (ed *eventDispatcher) orderingKey(event *fftypes.EventDelivery) string {
+	if ed.subscription.definition.Options.OrderedBy == fftypes.SubOptsOrderedByGroup {
+		if event.Message == nil || event.Message.Header.Group == nil {
+			return ""
+		}
+		return event.Message.Header.Group.String()
+	}
+	return event.Topic
+}
+
+// orderedDispatchable splits the matching events into those that can be dispatched now, and those
+// that must be held. An event is held if an earlier event with the same key is in-flight or held.
+// Must be called with the lock held.
+func (ed *eventDispatcher) orderedDispatchable(matching []*fftypes.EventDelivery, maxDispatch int) (dispatchable, held []*fftypes.EventDelivery) {
+	busy := make(map[string]bool)
+	for _, key := range ed.inflightKeys {
+		busy[key] = true
+	}
+	for _, event := range matching {
+		key := ed.orderingKey(event)
+		if len(dispatchable) < maxDispatch && !busy[key] {
+			dispatchable = append(dispatchable, event)
+		} else {
+			held = append(held, event)
+		}
+		busy[key] = true
+	}
+	return dispatchable, held
+}
@@ -0,0 +1,320 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestOrderedSubscription(orderedBy fftypes.SubOptsOrderedBy) *subscription {
+	five := uint16(5)
+	return &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Ephemeral:       true,
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					ReadAhead: &five,
+					OrderedBy: orderedBy,
+				},
+			},
+		},
+	}
+}
+
+func newTestOrderedDeliveries(ed *eventDispatcher) chan *fftypes.EventDelivery {
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	deliveries := make(chan *fftypes.EventDelivery, 10)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		deliveries <- a.Get(2).(*fftypes.EventDelivery)
+	}
+	return deliveries
+}
+
+func assertNoDelivery(t *testing.T, deliveries chan *fftypes.EventDelivery) {
+	select {
+	case event := <-deliveries:
+		assert.Fail(t, "unexpected delivery", "event %d", event.Sequence)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestBufferedDeliveryOrderedByTopic(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestOrderedSubscription(fftypes.SubOptsOrderedByTopic))
+	defer cancel()
+	go ed.deliverEvents()
+	deliveries := newTestOrderedDeliveries(ed)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ev3 := fftypes.NewUUID()
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 100001, Topic: "topicA"},
+			&fftypes.Event{ID: ev2, Sequence: 100002, Topic: "topicA"},
+			&fftypes.Event{ID: ev3, Sequence: 100003, Topic: "topicB"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// The first event on each topic is delivered in parallel, but the second on topicA is held
+	delivered := map[int64]bool{}
+	delivered[(<-deliveries).Sequence] = true
+	delivered[(<-deliveries).Sequence] = true
+	assert.Equal(t, map[int64]bool{100001: true, 100003: true}, delivered)
+	assertNoDelivery(t, deliveries)
+
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev3})
+	assertNoDelivery(t, deliveries)
+
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	event2 := <-deliveries
+	assert.Equal(t, *ev2, *event2.ID)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2})
+
+	<-bdDone
+	assert.Equal(t, int64(100003), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.inflightKeys)
+}
+
+func TestBufferedDeliveryOrderedNack(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestOrderedSubscription(fftypes.SubOptsOrderedByTopic))
+	defer cancel()
+	go ed.deliverEvents()
+	deliveries := newTestOrderedDeliveries(ed)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ev3 := fftypes.NewUUID()
+	ev4 := fftypes.NewUUID()
+	ed.eventPoller.pollingOffset = 100050
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 100001, Topic: "topicA"},
+			&fftypes.Event{ID: ev2, Sequence: 100002, Topic: "topicA"},
+			&fftypes.Event{ID: ev3, Sequence: 100003, Topic: "topicB"},
+			&fftypes.Event{ID: ev4, Sequence: 100004, Topic: "topicB"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	<-deliveries
+	<-deliveries
+
+	// Rejecting the event on topicB drops the event held behind it, which will be redelivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev3, Rejected: true})
+	assertNoDelivery(t, deliveries)
+
+	// The event held on topicA is before the rejected event, so is still delivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	event2 := <-deliveries
+	assert.Equal(t, *ev2, *event2.ID)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2})
+
+	<-bdDone
+	assert.Equal(t, int64(100002), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.inflight)
+	assert.Empty(t, ed.inflightKeys)
+}
+
+func TestBufferedDeliveryOrderedRedeliveryPark(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 3)))
+	defer cancel()
+	ed.subscription.definition.Options.OrderedBy = fftypes.SubOptsOrderedByTopic
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(&fftypes.SubscriptionRedelivery{
+		Subscription: ed.subscription.definition.ID,
+		Event:        ev1,
+		Attempts:     2,
+	}, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.MatchedBy(func(r *fftypes.SubscriptionRedelivery) bool {
+		return r.Attempts == 3 && r.Parked
+	})).Return(nil)
+
+	ed.eventPoller.pollingOffset = 100050
+	repoll, err := nackFirstDelivery(t, ed, ev1)
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryOrderedRedeliveryParkEarlierInflight(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 1)))
+	defer cancel()
+	ed.subscription.definition.Options.OrderedBy = fftypes.SubOptsOrderedByTopic
+	ed.readAhead = 5
+	go ed.deliverEvents()
+	deliveries := newTestOrderedDeliveries(ed)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev2).Return(nil, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.MatchedBy(func(r *fftypes.SubscriptionRedelivery) bool {
+		return r.Event.Equals(ev2) && r.Parked
+	})).Return(nil)
+
+	ed.eventPoller.pollingOffset = 100000
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 100001, Topic: "topicA"},
+			&fftypes.Event{ID: ev2, Sequence: 100002, Topic: "topicB"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	<-deliveries
+	<-deliveries
+	// The event on the other key is still in-flight, so the offset cannot move past it
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2, Rejected: true})
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	<-bdDone
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Equal(t, int64(100002), ed.parked[*ev2])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryOrderedRedeliveryParkAfterRewind(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Millisecond, 3)))
+	defer cancel()
+	ed.subscription.definition.Options.OrderedBy = fftypes.SubOptsOrderedByTopic
+	ed.readAhead = 5
+	go ed.deliverEvents()
+	deliveries := newTestOrderedDeliveries(ed)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, nil)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev2).Return(&fftypes.SubscriptionRedelivery{
+		Subscription: ed.subscription.definition.ID,
+		Event:        ev2,
+		Attempts:     2,
+	}, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.Anything).Return(nil)
+
+	ed.eventPoller.pollingOffset = 100000
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 100001, Topic: "topicA"},
+			&fftypes.Event{ID: ev2, Sequence: 100002, Topic: "topicB"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	<-deliveries
+	<-deliveries
+	// The earlier event is rewound for redelivery, so the offset cannot move past it
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1, Rejected: true})
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2, Rejected: true})
+	<-bdDone
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Equal(t, int64(100002), ed.parked[*ev2])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryOrderedRedeliveryGetFail(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 3)))
+	defer cancel()
+	ed.subscription.definition.Options.OrderedBy = fftypes.SubOptsOrderedByTopic
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, fmt.Errorf("pop"))
+
+	ed.eventPoller.pollingOffset = 100050
+	_, err := nackFirstDelivery(t, ed, ev1)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOrderingKey(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestOrderedSubscription(fftypes.SubOptsOrderedByTopic))
+	defer cancel()
+
+	group := fftypes.NewRandB32()
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{Topic: "topic1"},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{Group: group},
+			},
+		},
+	}
+	assert.Equal(t, "topic1", ed.orderingKey(event))
+
+	ed.subscription.definition.Options.OrderedBy = fftypes.SubOptsOrderedByGroup
+	assert.Equal(t, group.String(), ed.orderingKey(event))
+
+	event.Message.Header.Group = nil
+	assert.Equal(t, "", ed.orderingKey(event))
+
+	event.Message = nil
+	assert.Equal(t, "", ed.orderingKey(event))
+}
+
+func TestOrderedDispatchable(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestOrderedSubscription(fftypes.SubOptsOrderedByTopic))
+	defer cancel()
+
+	ed.inflightKeys[*fftypes.NewUUID()] = "topicA"
+	matching := []*fftypes.EventDelivery{
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 1, Topic: "topicA"}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 2, Topic: "topicB"}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 3, Topic: "topicB"}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 4, Topic: "topicC"}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 5, Topic: "topicD"}}},
+	}
+
+	dispatchable, held := ed.orderedDispatchable(matching, 2)
+	assert.Equal(t, []*fftypes.EventDelivery{matching[1], matching[3]}, dispatchable)
+	assert.Equal(t, []*fftypes.EventDelivery{matching[0], matching[2], matching[4]}, held)
+
+	dispatchable, held = ed.orderedDispatchable(matching, 0)
+	assert.Empty(t, dispatchable)
+	assert.Equal(t, matching, held)
+}
@@ -0,0 +1,143 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"math"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// loadRedeliveries picks up the events that were part way through their redelivery attempts when
+// the last dispatcher for the subscription stopped, so their counts are cleared once they are accepted
+func (ed *eventDispatcher) loadRedeliveries() {
+	if ed.subscription.definition.Options.Redelivery == nil {
+		return
+	}
+	fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(ed.ctx)
+	redeliveries, _, err := ed.database.GetSubscriptionRedeliveries(ed.ctx, fb.And(
+		fb.Eq("subscription", ed.subscription.definition.ID),
+		fb.Eq("parked", false),
+	))
+	if err != nil {
+		// The counts are still correct, we just might leave some behind after the events are accepted
+		log.L(ed.ctx).Warnf("Failed to load redeliveries: %s", err)
+		return
+	}
+	for _, redelivery := range redeliveries {
+		ed.redeliveries[*redelivery.Event] = true
+	}
+}
+
+// recordRejection counts a rejected delivery against the event, for subscriptions with a redelivery policy.
+// It returns how long to wait before redelivering, or that the event has reached the maximum attempts and is parked
+func (ed *eventDispatcher) recordRejection(nack ackNack) (delay time.Duration, parked bool, err error) {
+	sub := ed.subscription.definition
+	policy := sub.Options.Redelivery
+	redelivery, err := ed.database.GetSubscriptionRedelivery(ed.ctx, sub.ID, &nack.id)
+	if err != nil {
+		return 0, false, err
+	}
+	if redelivery == nil {
+		redelivery = &fftypes.SubscriptionRedelivery{
+			Subscription: sub.ID,
+			Namespace:    sub.Namespace,
+			Event:        &nack.id,
+			Sequence:     nack.offset,
+		}
+	}
+	redelivery.Attempts++
+	redelivery.Parked = policy.MaxAttempts > 0 && redelivery.Attempts >= policy.MaxAttempts
+	if err = ed.database.UpsertSubscriptionRedelivery(ed.ctx, redelivery); err != nil {
+		return 0, false, err
+	}
+
+	if redelivery.Parked {
+		log.L(ed.ctx).Errorf("Event %.10d/%s parked after %d rejected deliveries", nack.offset, &nack.id, redelivery.Attempts)
+		delete(ed.redeliveries, nack.id)
+		ed.parked[nack.id] = nack.offset
+		return 0, true, nil
+	}
+	ed.redeliveries[nack.id] = true
+	delay = redeliveryDelay(policy, redelivery.Attempts)
+	log.L(ed.ctx).Infof("Event %.10d/%s rejected %d times - redelivering after %s", nack.offset, &nack.id, redelivery.Attempts, delay)
+	return delay, false, nil
+}
+
+// parkedCommitOffset returns the offset to commit when a rejected event is parked. That is the offset of the
+// parked event, unless an earlier event is still in-flight, held, or rewound for redelivery - in which case we can
+// only move up to just before the earliest of those, and the parked event is skipped when it is polled again.
+// Must be called before the nack is handled, while the earlier events are still in-flight
+func (ed *eventDispatcher) parkedCommitOffset(nack ackNack, held []*fftypes.EventDelivery, rewound int64) int64 {
+	offset := nack.offset
+	if rewound > 0 && rewound <= offset {
+		offset = rewound - 1
+	}
+	ed.mux.Lock()
+	for id, event := range ed.inflight {
+		if id != nack.id && event.Sequence <= offset {
+			offset = event.Sequence - 1
+		}
+	}
+	ed.mux.Unlock()
+	for _, event := range held {
+		if event.Sequence <= offset {
+			offset = event.Sequence - 1
+		}
+	}
+	return offset
+}
+
+// pruneParked stops skipping parked events once the offset has moved past them.
+// If we restart before that, the parked event is delivered once more - and parked again if it is rejected
+func (ed *eventDispatcher) pruneParked() {
+	offset := ed.eventPoller.getPollingOffset()
+	for id, sequence := range ed.parked {
+		if sequence <= offset {
+			delete(ed.parked, id)
+		}
+	}
+}
+
+// clearRejections removes the count of rejected deliveries, once a redelivered event is accepted
+func (ed *eventDispatcher) clearRejections(ack ackNack) {
+	if !ed.redeliveries[ack.id] {
+		return
+	}
+	delete(ed.redeliveries, ack.id)
+	if err := ed.database.DeleteSubscriptionRedelivery(ed.ctx, ed.subscription.definition.ID, &ack.id); err != nil {
+		log.L(ed.ctx).Warnf("Failed to clear redelivery of event %s: %s", &ack.id, err)
+	}
+}
+
+func redeliveryDelay(policy *fftypes.SubOptsRedelivery, attempts int) time.Duration {
+	switch policy.Policy {
+	case fftypes.SubOptsRedeliveryFixed:
+		return time.Duration(*policy.Delay)
+	case fftypes.SubOptsRedeliveryExponential:
+		delay := float64(*policy.Delay) * math.Pow(policy.Factor, float64(attempts-1))
+		if delay > float64(*policy.MaxDelay) {
+			return time.Duration(*policy.MaxDelay)
+		}
+		return time.Duration(delay)
+	default:
+		return 0
+	}
+}
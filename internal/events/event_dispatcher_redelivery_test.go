@@ -0,0 +1,312 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRedeliverySubscription(redelivery *fftypes.SubOptsRedelivery) *subscription {
+	return &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					Redelivery: redelivery,
+				},
+			},
+		},
+	}
+}
+
+func fixedRedelivery(delay time.Duration, maxAttempts int) *fftypes.SubOptsRedelivery {
+	ffd := fftypes.FFDuration(delay)
+	return &fftypes.SubOptsRedelivery{
+		Policy:      fftypes.SubOptsRedeliveryFixed,
+		Delay:       &ffd,
+		MaxAttempts: maxAttempts,
+	}
+}
+
+func nackFirstDelivery(t *testing.T, ed *eventDispatcher, ev1 *fftypes.UUID) (repoll bool, err error) {
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	delivered := make(chan struct{}, 2)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		delivered <- struct{}{}
+	}
+
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err = ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 100001},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 100002},
+		})
+		close(bdDone)
+	}()
+
+	<-delivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{
+		ID:       ev1,
+		Rejected: true,
+	})
+	<-bdDone
+	return repoll, err
+}
+
+func TestBufferedDeliveryRedeliveryDelay(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(10*time.Millisecond, 3)))
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.MatchedBy(func(r *fftypes.SubscriptionRedelivery) bool {
+		return r.Event.Equals(ev1) && r.Sequence == 100001 && r.Attempts == 1 && !r.Parked && r.Namespace == "ns1"
+	})).Return(nil)
+
+	ed.eventPoller.pollingOffset = 100050
+	start := time.Now()
+	repoll, err := nackFirstDelivery(t, ed, ev1)
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.True(t, ed.redeliveries[*ev1])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryRedeliveryPark(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 3)))
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	ed.redeliveries[*ev1] = true
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(&fftypes.SubscriptionRedelivery{
+		Subscription: ed.subscription.definition.ID,
+		Event:        ev1,
+		Attempts:     2,
+	}, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.MatchedBy(func(r *fftypes.SubscriptionRedelivery) bool {
+		return r.Attempts == 3 && r.Parked
+	})).Return(nil)
+
+	ed.eventPoller.pollingOffset = 100050
+	repoll, err := nackFirstDelivery(t, ed, ev1)
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+	assert.False(t, ed.redeliveries[*ev1])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryRedeliveryParkEarlierInflight(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 1)))
+	defer cancel()
+	ed.readAhead = 10
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev2).Return(nil, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.MatchedBy(func(r *fftypes.SubscriptionRedelivery) bool {
+		return r.Event.Equals(ev2) && r.Parked
+	})).Return(nil)
+
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	delivered := make(chan *fftypes.EventDelivery, 2)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		delivered <- a[2].(*fftypes.EventDelivery)
+	}
+
+	page := []fftypes.LocallySequenced{
+		&fftypes.Event{ID: ev1, Sequence: 100001},
+		&fftypes.Event{ID: ev2, Sequence: 100002},
+	}
+	ed.eventPoller.pollingOffset = 100000
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery(page)
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// Park the second event, while the first is still in-flight
+	<-delivered
+	<-delivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2, Rejected: true})
+	<-bdDone
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Equal(t, int64(100002), ed.parked[*ev2])
+
+	// The first event is redelivered, but the parked event is skipped
+	bdDone = make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery(page)
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+	event := <-delivered
+	assert.Equal(t, *ev1, *event.ID)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	<-bdDone
+	assert.Equal(t, int64(100002), ed.eventPoller.pollingOffset)
+
+	ed.pruneParked()
+	assert.Empty(t, ed.parked)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryRedeliveryGetFail(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 3)))
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, fmt.Errorf("pop"))
+
+	ed.eventPoller.pollingOffset = 100050
+	_, err := nackFirstDelivery(t, ed, ev1)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryRedeliveryUpsertFail(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 3)))
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, nil)
+	mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ed.eventPoller.pollingOffset = 100050
+	_, err := nackFirstDelivery(t, ed, ev1)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryRedeliveryClosed(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 0)))
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil, nil)
+	upsert := mdi.On("UpsertSubscriptionRedelivery", mock.Anything, mock.Anything).Return(nil)
+	upsert.RunFn = func(a mock.Arguments) {
+		// Close while we wait for the delay
+		ed.cancelCtx()
+	}
+
+	ed.eventPoller.pollingOffset = 100050
+	_, err := nackFirstDelivery(t, ed, ev1)
+	assert.Regexp(t, "FF10182", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestClearRejections(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 0)))
+	defer cancel()
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ed.redeliveries[*ev1] = true
+	ed.redeliveries[*ev2] = true
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("DeleteSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev1).Return(nil)
+	mdi.On("DeleteSubscriptionRedelivery", mock.Anything, ed.subscription.definition.ID, ev2).Return(fmt.Errorf("pop"))
+
+	ed.clearRejections(ackNack{id: *ev1})
+	ed.clearRejections(ackNack{id: *ev2})
+	ed.clearRejections(ackNack{id: *fftypes.NewUUID()}) // not redelivered
+	assert.Empty(t, ed.redeliveries)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestLoadRedeliveries(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(fixedRedelivery(time.Hour, 0)))
+	defer cancel()
+
+	ev1 := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{
+		{Event: ev1},
+	}, nil, nil).Once()
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+
+	ed.loadRedeliveries()
+	assert.True(t, ed.redeliveries[*ev1])
+
+	ed.redeliveries = make(map[fftypes.UUID]bool)
+	ed.loadRedeliveries()
+	assert.Empty(t, ed.redeliveries)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestLoadRedeliveriesNoPolicy(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(newTestRedeliverySubscription(nil))
+	defer cancel()
+
+	ed.loadRedeliveries()
+	assert.Empty(t, ed.redeliveries)
+}
+
+func TestRedeliveryDelay(t *testing.T) {
+	delay := fftypes.FFDuration(time.Second)
+	maxDelay := fftypes.FFDuration(5 * time.Second)
+
+	assert.Equal(t, time.Duration(0), redeliveryDelay(&fftypes.SubOptsRedelivery{Policy: fftypes.SubOptsRedeliveryImmediate}, 3))
+	assert.Equal(t, time.Second, redeliveryDelay(fixedRedelivery(time.Second, 0), 3))
+
+	exponential := &fftypes.SubOptsRedelivery{
+		Policy:   fftypes.SubOptsRedeliveryExponential,
+		Delay:    &delay,
+		MaxDelay: &maxDelay,
+		Factor:   2,
+	}
+	assert.Equal(t, 1*time.Second, redeliveryDelay(exponential, 1))
+	assert.Equal(t, 2*time.Second, redeliveryDelay(exponential, 2))
+	assert.Equal(t, 4*time.Second, redeliveryDelay(exponential, 3))
+	assert.Equal(t, 5*time.Second, redeliveryDelay(exponential, 4))
+}
@@ -589,6 +589,16 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
 
+	ed.subscription.topicFilter = nil
+	ed.subscription.topicSetFilter, _ = newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Include: []string{"topic*"},
+		Exclude: []string{"topic1"},
+	})
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+	ed.subscription.topicSetFilter = nil
+
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile("tag2")
 	matched = ed.filterEvents(events)
@@ -636,6 +646,57 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id6, *matched[0].ID)
 }
 
+func TestFilterEventsRoutingRuleLabels(t *testing.T) {
+
+	rs := newRoutingRules(context.Background(), nil, nil)
+	rs.byNamespace["ns1"] = []*routingRule{
+		{
+			definition: &fftypes.RoutingRule{Labels: fftypes.FFStringArray{"finance"}},
+			tagFilter:  regexp.MustCompile("^invoice"),
+		},
+		{
+			definition:  &fftypes.RoutingRule{Labels: fftypes.FFStringArray{"orders"}},
+			topicFilter: regexp.MustCompile("^orders$"),
+		},
+	}
+	sub := &subscription{
+		definition:   &fftypes.Subscription{},
+		routingRules: rs,
+		labelFilter:  map[string]bool{"finance": true},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	events := []*fftypes.EventDelivery{
+		{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{ID: id1, Namespace: "ns1", Topic: "orders"},
+				Message: &fftypes.Message{
+					Header: fftypes.MessageHeader{Tag: "invoice1"},
+				},
+			},
+		},
+		{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{ID: id2, Namespace: "ns1", Topic: "orders"},
+			},
+		},
+	}
+
+	matched := ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+	assert.Equal(t, []string{"finance", "orders"}, matched[0].Labels)
+
+	// Without a label filter, all events are delivered with their labels
+	ed.subscription.labelFilter = nil
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, []string{"orders"}, matched[1].Labels)
+}
+
 func TestEnrichTransactionEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -1082,7 +1143,7 @@ func TestEventDispatcherWithReply(t *testing.T) {
 	cancel()
 	ed.acksNacks = make(chan ackNack, 2)
 	msh := ed.definitions.(*definitionsmocks.DefinitionHandlers)
-	msh.On("SendReply", ed.ctx, mock.Anything, mock.Anything).Return(&fftypes.Message{}, nil)
+	msh.On("SendReply", ed.ctx, mock.Anything, mock.Anything, mock.Anything).Return(&fftypes.Message{}, nil)
 
 	event1 := fftypes.NewUUID()
 	event2 := fftypes.NewUUID()
@@ -25,7 +25,9 @@ import (
 
 	"github.com/hyperledger/firefly/internal/assets"
 	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/circuit"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/contracts"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/definitions"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
@@ -34,6 +36,7 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/shareddownload"
@@ -42,6 +45,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 	"github.com/hyperledger/firefly/pkg/tokens"
@@ -54,9 +58,13 @@ type EventManager interface {
 	NewSubscriptions() chan<- *fftypes.UUID
 	SubscriptionUpdates() chan<- *fftypes.UUID
 	DeletedSubscriptions() chan<- *fftypes.UUID
+	RoutingRuleChanges() chan<- string
 	ChangeEvents() chan<- *fftypes.ChangeEvent
 	DeleteDurableSubscription(ctx context.Context, subDef *fftypes.Subscription) (err error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, mustNew bool) (err error)
+	GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error)
+	Transports() map[string]events.Plugin
+	WaitForCondition(ctx context.Context, check func() (bool, error)) error
 	Start() error
 	WaitStop()
 
@@ -78,6 +86,7 @@ type EventManager interface {
 	TokenPoolCreated(ti tokens.Plugin, pool *tokens.TokenPool) error
 	TokensTransferred(ti tokens.Plugin, transfer *tokens.TokenTransfer) error
 	TokensApproved(ti tokens.Plugin, approval *tokens.TokenApproval) error
+	TokenPoolBackfillProgress(ti tokens.Plugin, progress *tokens.TokenPoolBackfillProgress) error
 
 	// Internal events
 	sysmessaging.SystemEvents
@@ -98,7 +107,9 @@ type eventManager struct {
 	broadcast             broadcast.Manager
 	messaging             privatemessaging.Manager
 	assets                assets.Manager
+	contracts             contracts.Manager
 	sharedDownload        shareddownload.Manager
+	operations            operations.Manager
 	newEventNotifier      *eventNotifier
 	newPinNotifier        *eventNotifier
 	opCorrelationRetries  int
@@ -107,10 +118,16 @@ type eventManager struct {
 	metrics               metrics.Manager
 	chainListenerCache    *ccache.Cache
 	chainListenerCacheTTL time.Duration
+	deliveryAcks          bool
+	eventInfoMaxSize      int64
+	eventRawCompress      bool
+	verifierType          fftypes.VerifierType
+	broadcastPayloadLimit int64
+	dxPayloadLimit        int64
 }
 
-func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si sharedstorage.Plugin, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.DefinitionHandlers, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, sd shareddownload.Manager, mm metrics.Manager, txHelper txcommon.Helper) (EventManager, error) {
-	if ni == nil || si == nil || di == nil || bi == nil || im == nil || dh == nil || dm == nil || bm == nil || pm == nil || am == nil {
+func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si sharedstorage.Plugin, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.DefinitionHandlers, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, cm contracts.Manager, sd shareddownload.Manager, om operations.Manager, mm metrics.Manager, txHelper txcommon.Helper) (EventManager, error) {
+	if ni == nil || si == nil || di == nil || bi == nil || im == nil || dh == nil || dm == nil || bm == nil || pm == nil || am == nil || cm == nil || om == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	newPinNotifier := newEventNotifier(ctx, "pins")
@@ -127,7 +144,9 @@ func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si shar
 		broadcast:      bm,
 		messaging:      pm,
 		assets:         am,
+		contracts:      cm,
 		sharedDownload: sd,
+		operations:     om,
 		retry: retry.Retry{
 			InitialDelay: config.GetDuration(config.EventAggregatorRetryInitDelay),
 			MaximumDelay: config.GetDuration(config.EventAggregatorRetryMaxDelay),
@@ -137,16 +156,27 @@ func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si shar
 		opCorrelationRetries:  config.GetInt(config.EventAggregatorOpCorrelationRetries),
 		newEventNotifier:      newEventNotifier,
 		newPinNotifier:        newPinNotifier,
-		aggregator:            newAggregator(ctx, di, bi, dh, im, dm, newPinNotifier, mm),
+		aggregator:            newAggregator(ctx, di, bi, si, dh, im, dm, pm, newPinNotifier, mm),
 		metrics:               mm,
 		chainListenerCache:    ccache.New(ccache.Configure().MaxSize(config.GetByteSize(config.EventListenerTopicCacheSize))),
 		chainListenerCacheTTL: config.GetDuration(config.EventListenerTopicCacheTTL),
+		deliveryAcks:          config.GetBool(config.PrivateMessagingDeliveryAcksEnabled),
+		eventInfoMaxSize:      config.GetByteSize(config.BlockchainEventInfoMaxSize),
+		eventRawCompress:      config.GetBool(config.BlockchainEventRawCompress),
+		verifierType:          bi.VerifierType(),
+	}
+	// Payloads are limited to the configured batch sizes, with the same allowance for overhead as shared storage downloads
+	em.broadcastPayloadLimit = config.GetByteSize(config.BroadcastBatchPayloadLimit) + 1024
+	em.dxPayloadLimit = config.GetByteSize(config.PrivateMessagingBatchPayloadLimit) + 1024
+	if em.broadcastPayloadLimit > em.dxPayloadLimit {
+		// broadcast batches can also arrive over data exchange in hybrid mode
+		em.dxPayloadLimit = em.broadcastPayloadLimit
 	}
 	ie, _ := eifactory.GetPlugin(ctx, system.SystemEventsTransport)
 	em.internalEvents = ie.(*system.Events)
 
 	var err error
-	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, dh, txHelper); err != nil {
+	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, dh, txHelper, mm); err != nil {
 		return nil, err
 	}
 
@@ -154,6 +184,7 @@ func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si shar
 }
 
 func (em *eventManager) Start() (err error) {
+	circuit.AddListener(em.circuitStateChanged)
 	err = em.subManager.start()
 	if err == nil {
 		em.aggregator.start()
@@ -161,6 +192,25 @@ func (em *eventManager) Start() (err error) {
 	return err
 }
 
+func (em *eventManager) Transports() map[string]events.Plugin {
+	return em.subManager.transports
+}
+
+// WaitForCondition calls the check function, and then again each time new events are committed,
+// until it returns true, returns an error, or the context is done
+func (em *eventManager) WaitForCondition(ctx context.Context, check func() (bool, error)) error {
+	for {
+		seq := em.newEventNotifier.currentSequence()
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if err := em.newEventNotifier.waitNextContext(ctx, seq); err != nil {
+			return err
+		}
+	}
+}
+
 func (em *eventManager) NewEvents() chan<- int64 {
 	return em.newEventNotifier.newEvents
 }
@@ -181,6 +231,10 @@ func (em *eventManager) DeletedSubscriptions() chan<- *fftypes.UUID {
 	return em.subManager.deletedSubscriptions
 }
 
+func (em *eventManager) RoutingRuleChanges() chan<- string {
+	return em.subManager.routingRuleChanges
+}
+
 func (em *eventManager) ChangeEvents() chan<- *fftypes.ChangeEvent {
 	return em.subManager.cel.changeEvents
 }
@@ -242,6 +296,10 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *f
 	return em.database.DeleteSubscriptionByID(ctx, subDef.ID)
 }
 
+func (em *eventManager) GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	return em.subManager.getSubscriptionStatus(ctx, subDef)
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
@@ -27,12 +27,14 @@ import (
 	"github.com/hyperledger/firefly/mocks/assetmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/shareddownloadmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
@@ -66,8 +68,10 @@ func newTestEventManagerCommon(t *testing.T, metrics bool) (*eventManager, func(
 	mbm := &broadcastmocks.Manager{}
 	mpm := &privatemessagingmocks.Manager{}
 	mam := &assetmocks.Manager{}
+	mcm := &contractmocks.Manager{}
 	mni := &sysmessagingmocks.LocalNodeInfo{}
 	mdd := &shareddownloadmocks.Manager{}
+	mom := &operationmocks.Manager{}
 	mmi := &metricsmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mmi.On("IsMetricsEnabled").Return(metrics)
@@ -77,7 +81,7 @@ func newTestEventManagerCommon(t *testing.T, metrics bool) (*eventManager, func(
 	mni.On("GetNodeUUID", mock.Anything).Return(testNodeID).Maybe()
 	met.On("Name").Return("ut").Maybe()
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
-	emi, err := NewEventManager(ctx, mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mdd, mmi, txHelper)
+	emi, err := NewEventManager(ctx, mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mcm, mdd, mom, mmi, txHelper)
 	em := emi.(*eventManager)
 	em.txHelper = &txcommonmocks.Helper{}
 	rag := mdi.On("RunAsGroup", em.ctx, mock.Anything).Maybe()
@@ -91,24 +95,73 @@ func newTestEventManagerCommon(t *testing.T, metrics bool) (*eventManager, func(
 func TestStartStop(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	mdi := em.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, aggregatorOffsetName).Return(&fftypes.Offset{
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, AggregatorOffsetName).Return(&fftypes.Offset{
 		Type:    fftypes.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
 	mdi.On("GetPins", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 	assert.NoError(t, em.Start())
 	em.NewEvents() <- 12345
 	em.NewPins() <- 12345
 	assert.Equal(t, chan<- *fftypes.ChangeEvent(em.subManager.cel.changeEvents), em.ChangeEvents())
+	assert.Equal(t, em.subManager.transports, em.Transports())
 	cancel()
 	em.WaitStop()
 }
 
+func TestWaitForCondition(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	checks := 0
+	waited := make(chan error)
+	go func() {
+		waited <- em.WaitForCondition(em.ctx, func() (bool, error) {
+			checks++
+			return checks == 2, nil
+		})
+	}()
+	var err error
+	for seq := int64(12345); ; seq++ {
+		select {
+		case em.NewEvents() <- seq:
+			continue
+		case err = <-waited:
+		}
+		break
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, 2, checks)
+}
+
+func TestWaitForConditionCheckFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	err := em.WaitForCondition(em.ctx, func() (bool, error) {
+		return false, fmt.Errorf("pop")
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestWaitForConditionTimeout(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ctx, cancelCtx := context.WithCancel(em.ctx)
+	cancelCtx()
+	err := em.WaitForCondition(ctx, func() (bool, error) {
+		return false, nil
+	})
+	assert.Regexp(t, "FF10158", err)
+}
+
 func TestStartStopBadDependencies(t *testing.T) {
-	_, err := NewEventManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewEventManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 
 }
@@ -126,24 +179,27 @@ func TestStartStopBadTransports(t *testing.T) {
 	mpm := &privatemessagingmocks.Manager{}
 	mni := &sysmessagingmocks.LocalNodeInfo{}
 	mam := &assetmocks.Manager{}
+	mcm := &contractmocks.Manager{}
 	msd := &shareddownloadmocks.Manager{}
+	mom := &operationmocks.Manager{}
 	mm := &metricsmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress)
-	_, err := NewEventManager(context.Background(), mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, msd, mm, txHelper)
+	_, err := NewEventManager(context.Background(), mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mcm, msd, mom, mm, txHelper)
 	assert.Regexp(t, "FF10172", err)
 }
 
 func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	mdi := em.database.(*databasemocks.Plugin)
-	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, aggregatorOffsetName).Return(&fftypes.Offset{
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, AggregatorOffsetName).Return(&fftypes.Offset{
 		Type:    fftypes.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		Current: 12345,
 		RowID:   333333,
 	}, nil)
 	mdi.On("GetPins", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 
 	getSubCallReady := make(chan bool, 1)
@@ -174,6 +230,33 @@ func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	<-delOffsetCalled
 }
 
+func TestRoutingRuleChanges(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, AggregatorOffsetName).Return(&fftypes.Offset{
+		Type:    fftypes.OffsetTypeAggregator,
+		Name:    AggregatorOffsetName,
+		Current: 12345,
+		RowID:   333333,
+	}, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil).Once()
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+
+	reloaded := make(chan bool)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{
+		{Namespace: "ns1", Name: "rule1", Labels: fftypes.FFStringArray{"label1"}},
+	}, nil, nil).Run(func(a mock.Arguments) {
+		close(reloaded)
+	})
+
+	assert.NoError(t, em.Start())
+	defer cancel()
+
+	em.RoutingRuleChanges() <- "ns1"
+	<-reloaded
+}
+
 func TestCreateDurableSubscriptionBadSub(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -371,6 +454,18 @@ func TestCreateDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetSubscriptionStatus(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 10}}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub.ID.String()).Return(&fftypes.Offset{Current: 4}, nil)
+	status, err := em.GetSubscriptionStatus(em.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), status.Lag)
+}
+
 func TestAddInternalListener(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	ie := &system.Events{}
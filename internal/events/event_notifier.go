@@ -62,6 +62,41 @@ func (en *eventNotifier) waitNext(lastSequence int64) error {
 	return nil
 }
 
+func (en *eventNotifier) currentSequence() int64 {
+	en.cond.L.Lock()
+	defer en.cond.L.Unlock()
+	return en.latestSequence
+}
+
+// waitNextContext is a variant of waitNext, that also returns when the supplied context is done
+func (en *eventNotifier) waitNextContext(ctx context.Context, lastSequence int64) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			en.cond.L.Lock()
+			en.cond.Broadcast()
+			en.cond.L.Unlock()
+		case <-done:
+		}
+	}()
+
+	en.cond.L.Lock()
+	for en.latestSequence <= lastSequence && !en.closed && ctx.Err() == nil {
+		en.cond.Wait()
+	}
+	closed := en.closed
+	en.cond.L.Unlock()
+	switch {
+	case closed:
+		return i18n.NewError(en.ctx, i18n.MsgEventListenerClosing)
+	case ctx.Err() != nil:
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	}
+	return nil
+}
+
 func (en *eventNotifier) close() {
 	en.cond.L.Lock()
 	en.closed = true
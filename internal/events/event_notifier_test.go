@@ -19,6 +19,8 @@ package events
 import (
 	"context"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEventNotifier(t *testing.T) {
@@ -71,3 +73,29 @@ func TestEventNotifierClosedChannel(t *testing.T) {
 	close(en.newEvents)
 	<-events
 }
+
+func TestEventNotifierWaitNextContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	en := newEventNotifier(ctx, "ut")
+	assert.Equal(t, int64(-1), en.currentSequence())
+
+	waitCtx, waitCancel := context.WithCancel(context.Background())
+	waited := make(chan error)
+	go func() {
+		waited <- en.waitNextContext(waitCtx, -1)
+	}()
+	en.newEvents <- 1000001
+	assert.NoError(t, <-waited)
+	assert.Equal(t, int64(1000001), en.currentSequence())
+
+	go func() {
+		waited <- en.waitNextContext(waitCtx, 1000001)
+	}()
+	waitCancel()
+	assert.Regexp(t, "FF10158", <-waited)
+
+	cancel()
+	err := en.waitNextContext(context.Background(), 1000001)
+	assert.Regexp(t, "FF10186", err)
+}
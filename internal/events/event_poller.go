@@ -39,6 +39,12 @@ type eventPoller struct {
 	pollingOffset   int64
 	mux             sync.Mutex
 	conf            *eventPollerConf
+	restart         chan bool
+	stopped         bool
+	abandoned       bool
+	backlog         bool
+	lastProgress    time.Time
+	stallReported   bool
 }
 
 type newEventsHandler func(events []fftypes.LocallySequenced) (bool, error)
@@ -59,6 +65,7 @@ type eventPollerConf struct {
 	offsetType                 fftypes.OffsetType
 	retry                      retry.Retry
 	startupOffsetRetryAttempts int
+	watchdog                   *eventPollerWatchdogConf
 }
 
 func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier, conf *eventPollerConf) *eventPoller {
@@ -70,6 +77,8 @@ func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier,
 		eventNotifier:   en,
 		closed:          make(chan struct{}),
 		conf:            conf,
+		restart:         make(chan bool, 1),
+		lastProgress:    time.Now(),
 	}
 	if ep.conf.maybeRewind == nil {
 		ep.conf.maybeRewind = func() (bool, int64) { return false, -1 }
@@ -124,6 +133,9 @@ func (ep *eventPoller) start() {
 	go ep.newEventNotifications()
 	go ep.eventLoop()
 	go ep.offsetCommitLoop()
+	if ep.conf.watchdog != nil {
+		go ep.watchdogLoop()
+	}
 }
 
 func (ep *eventPoller) rewindPollingOffset(offset int64) int64 {
@@ -145,11 +157,19 @@ func (ep *eventPoller) getPollingOffset() int64 {
 func (ep *eventPoller) commitOffset(offset int64) {
 	// Next polling cycle should start one higher than this offset
 	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	if ep.abandoned {
+		// A loop abandoned by a watchdog restart is still winding down. The new loop starts from the last offset
+		// committed before the restart, so it can re-read these events, without moving the offset under it.
+		log.L(ep.ctx).Warnf("Ignoring offset %d committed by abandoned event loop", offset)
+		return
+	}
 	ep.pollingOffset = offset
-	ep.mux.Unlock()
+	ep.lastProgress = time.Now()
+	ep.stallReported = false
 
-	// No persistence for ephemeral (non-durable) subscriptions
-	if !ep.conf.ephemeral {
+	// No persistence for ephemeral (non-durable) subscriptions, or once stopped
+	if !ep.conf.ephemeral && !ep.stopped {
 		// We do this in the background, as it is an expensive full DB commit
 		select {
 		case ep.offsetCommitted <- offset:
@@ -158,7 +178,7 @@ func (ep *eventPoller) commitOffset(offset int64) {
 	}
 }
 
-func (ep *eventPoller) readPage() ([]fftypes.LocallySequenced, error) {
+func (ep *eventPoller) readPage(ctx context.Context) ([]fftypes.LocallySequenced, error) {
 
 	var items []fftypes.LocallySequenced
 
@@ -172,13 +192,13 @@ func (ep *eventPoller) readPage() ([]fftypes.LocallySequenced, error) {
 		pollingOffset = ep.getPollingOffset()
 	}
 
-	err := ep.conf.retry.Do(ep.ctx, "retrieve events", func(attempt int) (retry bool, err error) {
+	err := ep.conf.retry.Do(ctx, "retrieve events", func(attempt int) (retry bool, err error) {
 		fb := ep.conf.queryFactory.NewFilter(ep.ctx)
 		filter := fb.And(
 			fb.Gt("sequence", pollingOffset),
 		)
 		filter = ep.conf.addCriteria(filter)
-		items, err = ep.conf.getItems(ep.ctx, filter.Sort("sequence").Limit(uint64(ep.conf.eventBatchSize)), pollingOffset)
+		items, err = ep.conf.getItems(ctx, filter.Sort("sequence").Limit(uint64(ep.conf.eventBatchSize)), pollingOffset)
 		if err != nil {
 			return true, err // Retry indefinitely, until context cancelled
 		}
@@ -191,25 +211,65 @@ func (ep *eventPoller) eventLoop() {
 	l := log.L(ep.ctx)
 	l.Debugf("Started event detector")
 	defer func() {
-		close(ep.closed)
+		ep.mux.Lock()
+		ep.stopped = true
 		close(ep.offsetCommitted)
+		ep.mux.Unlock()
+		close(ep.closed)
 	}()
 
+	for {
+		// The poll loop runs in its own context, so the watchdog can abandon it if it stalls
+		loopCtx, cancelLoop := context.WithCancel(ep.ctx)
+		loopDone := make(chan struct{})
+		go func() {
+			defer close(loopDone)
+			ep.pollLoop(loopCtx)
+		}()
+		select {
+		case <-loopDone:
+			cancelLoop()
+			return
+		case <-ep.restart:
+			ep.mux.Lock()
+			ep.abandoned = true
+			ep.mux.Unlock()
+			cancelLoop()
+			// Only one loop can process events at a time, so we wait for the abandoned loop to notice it has
+			// been cancelled. If it is deadlocked it will never exit, and there is nothing safe we can do.
+			l.Warnf("Waiting for stalled event loop to exit")
+			select {
+			case <-loopDone:
+			case <-ep.ctx.Done():
+				return
+			}
+			ep.mux.Lock()
+			ep.abandoned = false
+			pollingOffset := ep.pollingOffset
+			ep.mux.Unlock()
+			l.Warnf("Restarting stalled event loop from offset %d", pollingOffset)
+		}
+	}
+}
+
+func (ep *eventPoller) pollLoop(ctx context.Context) {
+	l := log.L(ep.ctx)
 	for {
 		// Read messages from the DB - in an error condition we retry until success, or a closed context
-		events, err := ep.readPage()
+		events, err := ep.readPage(ctx)
 		if err != nil {
 			l.Debugf("Exiting: %s", err)
 			return
 		}
 
 		eventCount := len(events)
+		ep.updateBacklog(eventCount)
 		repoll := false
 		if eventCount > 0 {
 			// We process all the events in the page in a single database run group, and
 			// keep retrying on all retryable errors, indefinitely ().
 			var err error
-			repoll, err = ep.dispatchEventsRetry(events)
+			repoll, err = ep.dispatchEventsRetry(ctx, events)
 			if err != nil {
 				l.Debugf("Exiting: %s", err)
 				return
@@ -218,7 +278,7 @@ func (ep *eventPoller) eventLoop() {
 
 		// Once we run out of events, wait to be woken
 		if !repoll {
-			if ok := ep.waitForShoulderTapOrPollTimeout(eventCount); !ok {
+			if ok := ep.waitForShoulderTapOrPollTimeout(ctx, eventCount); !ok {
 				return
 			}
 		}
@@ -242,8 +302,8 @@ func (ep *eventPoller) offsetCommitLoop() {
 	}
 }
 
-func (ep *eventPoller) dispatchEventsRetry(events []fftypes.LocallySequenced) (repoll bool, err error) {
-	err = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
+func (ep *eventPoller) dispatchEventsRetry(ctx context.Context, events []fftypes.LocallySequenced) (repoll bool, err error) {
+	err = ep.conf.retry.Do(ctx, "process events", func(attempt int) (retry bool, err error) {
 		repoll, err = ep.conf.newEventsHandler(events)
 		return err != nil, err // always retry (retry will end on cancelled context)
 	})
@@ -279,7 +339,7 @@ func (ep *eventPoller) shoulderTap() {
 	}
 }
 
-func (ep *eventPoller) waitForShoulderTapOrPollTimeout(lastEventCount int) bool {
+func (ep *eventPoller) waitForShoulderTapOrPollTimeout(ctx context.Context, lastEventCount int) bool {
 	l := log.L(ep.ctx)
 	longTimeoutDuration := ep.conf.eventPollTimeout
 	// For throughput optimized environments, we can set an eventBatchingTimeout to allow messages to arrive
@@ -289,7 +349,7 @@ func (ep *eventPoller) waitForShoulderTapOrPollTimeout(lastEventCount int) bool
 		select {
 		case <-shortTimeout.C:
 			l.Tracef("Woken after batch timeout")
-		case <-ep.ctx.Done():
+		case <-ctx.Done():
 			l.Debugf("Exiting due to cancelled context")
 			return false
 		}
@@ -302,7 +362,7 @@ func (ep *eventPoller) waitForShoulderTapOrPollTimeout(lastEventCount int) bool
 		l.Debugf("Woken after poll timeout")
 	case <-ep.shoulderTaps:
 		l.Debug("Woken for trigger on event")
-	case <-ep.ctx.Done():
+	case <-ctx.Done():
 		l.Debugf("Exiting due to cancelled context")
 		return false
 	}
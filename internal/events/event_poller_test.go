@@ -66,7 +66,7 @@ func TestStartStopEventPoller(t *testing.T) {
 	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
 	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, "test").Return(&fftypes.Offset{
 		Type:    fftypes.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
+		Name:    AggregatorOffsetName,
 		RowID:   3333333,
 		Current: 12345,
 	}, nil)
@@ -275,7 +275,7 @@ func TestWaitForShoulderTapOrExitCloseBatch(t *testing.T) {
 	cancel()
 	ep.conf.eventBatchTimeout = 1 * time.Minute
 	ep.conf.eventBatchSize = 50
-	assert.False(t, ep.waitForShoulderTapOrPollTimeout(1))
+	assert.False(t, ep.waitForShoulderTapOrPollTimeout(ep.ctx, 1))
 }
 
 func TestWaitForShoulderTapOrExitClosePoll(t *testing.T) {
@@ -284,7 +284,7 @@ func TestWaitForShoulderTapOrExitClosePoll(t *testing.T) {
 	cancel()
 	ep.conf.eventBatchTimeout = 1 * time.Minute
 	ep.conf.eventBatchSize = 1
-	assert.False(t, ep.waitForShoulderTapOrPollTimeout(1))
+	assert.False(t, ep.waitForShoulderTapOrPollTimeout(ep.ctx, 1))
 }
 
 func TestWaitForShoulderTapOrPollTimeoutBatchAndPoll(t *testing.T) {
@@ -294,7 +294,7 @@ func TestWaitForShoulderTapOrPollTimeoutBatchAndPoll(t *testing.T) {
 	ep.conf.eventBatchTimeout = 1 * time.Microsecond
 	ep.conf.eventPollTimeout = 1 * time.Microsecond
 	ep.conf.eventBatchSize = 50
-	assert.True(t, ep.waitForShoulderTapOrPollTimeout(1))
+	assert.True(t, ep.waitForShoulderTapOrPollTimeout(ep.ctx, 1))
 }
 
 func TestWaitForShoulderTapOrPollTimeoutTap(t *testing.T) {
@@ -302,7 +302,7 @@ func TestWaitForShoulderTapOrPollTimeoutTap(t *testing.T) {
 	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
 	defer cancel()
 	ep.shoulderTap()
-	assert.True(t, ep.waitForShoulderTapOrPollTimeout(ep.conf.eventBatchSize))
+	assert.True(t, ep.waitForShoulderTapOrPollTimeout(ep.ctx, ep.conf.eventBatchSize))
 }
 
 func TestDoubleTap(t *testing.T) {
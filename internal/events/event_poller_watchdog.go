@@ -0,0 +1,91 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+// eventPollerWatchdogConf enables detection of an event poller that has events waiting to be
+// processed, but has not committed any progress within the stall timeout. This catches deadlocks
+// and indefinite retries, that would otherwise only be visible as processing silently stopping.
+type eventPollerWatchdogConf struct {
+	stallTimeout time.Duration
+	restart      bool
+	stalled      func(stalledFor time.Duration)
+}
+
+// updateBacklog records whether the last page read had events waiting. The stall timer starts
+// from the point a backlog is first seen, and an empty page counts as progress.
+func (ep *eventPoller) updateBacklog(eventCount int) {
+	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	if eventCount == 0 || !ep.backlog {
+		ep.lastProgress = time.Now()
+		ep.stallReported = false
+	}
+	ep.backlog = eventCount > 0
+}
+
+func (ep *eventPoller) watchdogLoop() {
+	interval := ep.conf.watchdog.stallTimeout / 4
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ep.watchdogCheck()
+		case <-ep.ctx.Done():
+			log.L(ep.ctx).Debugf("Watchdog exiting")
+			return
+		}
+	}
+}
+
+func (ep *eventPoller) watchdogCheck() {
+	ep.mux.Lock()
+	stalledFor := time.Since(ep.lastProgress)
+	stalled := ep.backlog && !ep.stallReported && stalledFor >= ep.conf.watchdog.stallTimeout
+	if stalled {
+		// Only report once per stall, unless we restart the loop below
+		ep.stallReported = true
+	}
+	pollingOffset := ep.pollingOffset
+	ep.mux.Unlock()
+	if !stalled {
+		return
+	}
+
+	log.L(ep.ctx).Errorf("CRITICAL: Event loop stalled - no progress for %s with events waiting after offset %d", stalledFor.Round(time.Second), pollingOffset)
+	if ep.conf.watchdog.restart {
+		// Signal the restart before notifying, in case the notification blocks on the same problem
+		ep.mux.Lock()
+		ep.backlog = false
+		ep.lastProgress = time.Now()
+		ep.stallReported = false
+		ep.mux.Unlock()
+		select {
+		case ep.restart <- true:
+		default:
+		}
+	}
+	if ep.conf.watchdog.stalled != nil {
+		ep.conf.watchdog.stalled(stalledFor)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWatchdogRestartsStalledLoop(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	release := make(chan struct{})
+	processed := make(chan bool, 1)
+	calls := 0
+	var ep *eventPoller
+	ep, cancel := newTestEventPoller(t, mdi, func(events []fftypes.LocallySequenced) (bool, error) {
+		calls++
+		if calls == 1 {
+			<-release // simulate a stall in the first loop
+			ep.commitOffset(12345)
+			return false, nil
+		}
+		processed <- true
+		return false, nil
+	}, nil)
+	stalled := make(chan time.Duration, 1)
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Millisecond,
+		restart:      true,
+		stalled:      func(stalledFor time.Duration) { stalled <- stalledFor },
+	}
+	ev1 := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, "test").Return(&fftypes.Offset{RowID: 1, Current: 0}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{ev1}, nil, nil).Twice()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	ep.start()
+
+	assert.GreaterOrEqual(t, <-stalled, 1*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		ep.mux.Lock()
+		defer ep.mux.Unlock()
+		return ep.abandoned
+	}, 5*time.Second, 1*time.Millisecond)
+	close(release)
+	<-processed
+
+	// The offset committed by the abandoned loop is ignored
+	assert.Equal(t, int64(0), ep.getPollingOffset())
+
+	cancel()
+	<-ep.closed
+	ep.commitOffset(12345) // committing after close must not panic
+}
+
+func TestWatchdogRestartClosedWaitingForLoop(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	release := make(chan struct{})
+	defer close(release)
+	ep, cancel := newTestEventPoller(t, mdi, func(events []fftypes.LocallySequenced) (bool, error) {
+		<-release // simulate a deadlock that ignores the context
+		return false, nil
+	}, nil)
+	stalled := make(chan time.Duration, 1)
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Millisecond,
+		restart:      true,
+		stalled:      func(stalledFor time.Duration) { stalled <- stalledFor },
+	}
+	ev1 := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, "test").Return(&fftypes.Offset{RowID: 1, Current: 0}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{ev1}, nil, nil)
+	ep.start()
+
+	<-stalled
+	cancel()
+	<-ep.closed
+}
+
+func TestWatchdogCheckNoBacklog(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	defer cancel()
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Millisecond,
+		stalled:      func(stalledFor time.Duration) { assert.Fail(t, "should not be called") },
+	}
+	ep.lastProgress = time.Now().Add(-1 * time.Hour)
+	ep.watchdogCheck()
+}
+
+func TestWatchdogCheckReportOnce(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	defer cancel()
+	reports := 0
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Minute,
+		stalled:      func(stalledFor time.Duration) { reports++ },
+	}
+	ep.updateBacklog(1)
+	ep.watchdogCheck() // not yet stalled
+	assert.Equal(t, 0, reports)
+
+	ep.lastProgress = time.Now().Add(-1 * time.Hour)
+	ep.updateBacklog(1) // still a backlog - does not reset the timer
+	ep.watchdogCheck()
+	ep.watchdogCheck()
+	assert.Equal(t, 1, reports)
+
+	ep.commitOffset(1) // progress
+	ep.lastProgress = time.Now().Add(-1 * time.Hour)
+	ep.watchdogCheck()
+	assert.Equal(t, 2, reports)
+
+	ep.updateBacklog(0) // caught up
+	assert.False(t, ep.backlog)
+	ep.watchdogCheck()
+	assert.Equal(t, 2, reports)
+}
+
+func TestWatchdogCheckRestartNoHandler(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	defer cancel()
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Millisecond,
+		restart:      true,
+	}
+	ep.backlog = true
+	ep.lastProgress = time.Now().Add(-1 * time.Hour)
+	ep.watchdogCheck()
+	assert.False(t, ep.backlog)
+	ep.backlog = true
+	ep.lastProgress = time.Now().Add(-1 * time.Hour)
+	ep.watchdogCheck() // does not block with a restart already pending
+	assert.True(t, <-ep.restart)
+}
+
+func TestWatchdogLoopExit(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	ep.conf.watchdog = &eventPollerWatchdogConf{
+		stallTimeout: 1 * time.Minute,
+	}
+	cancel()
+	ep.watchdogLoop()
+}
@@ -18,23 +18,81 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+// blockchainOpTypes are the operations that submit a blockchain transaction, whose latency is observed in metrics
+var blockchainOpTypes = map[fftypes.OpType]bool{
+	fftypes.OpTypeBlockchainPinBatch:       true,
+	fftypes.OpTypeBlockchainInvoke:         true,
+	fftypes.OpTypeBlockchainContractDeploy: true,
+	fftypes.OpTypeBlockchainRegisterKey:    true,
+}
+
+func isTerminalOpStatus(status fftypes.OpStatus) bool {
+	return status == fftypes.OpStatusSucceeded || status == fftypes.OpStatusFailed
+}
+
+// decodeInvokeFailure uses the custom errors stored on a contract invocation operation to decode why it failed,
+// falling back to the error message reported by the plugin
+func (em *eventManager) decodeInvokeFailure(ctx context.Context, plugin fftypes.Named, op *fftypes.Operation, errorMessage string, opOutput fftypes.JSONObject) string {
+	bi, ok := plugin.(blockchain.Plugin)
+	if !ok {
+		return errorMessage
+	}
+	var input struct {
+		Errors []*fftypes.FFIErrorDefinition `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(op.Input.String()), &input); err != nil || len(input.Errors) == 0 {
+		return errorMessage
+	}
+	if reason, ok := bi.DecodeRevertReason(ctx, opOutput, input.Errors); ok {
+		log.L(ctx).Debugf("Decoded failure of operation %s: %s", op.ID, reason)
+		return reason
+	}
+	return errorMessage
+}
+
+func (em *eventManager) operationUpdateCtx(ctx context.Context, plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
 	op, err := em.database.GetOperationByID(ctx, operationID)
 	if err != nil || op == nil {
 		log.L(ctx).Warnf("Operation update '%s' ignored, as it was not submitted by this node", operationID)
 		return nil
 	}
+	// Anything resulting from the update is correlated with the request that submitted the operation
+	ctx = log.WithCorrelationID(ctx, op.CorrelationID)
+
+	if op.Type == fftypes.OpTypeBlockchainInvoke && txState == fftypes.OpStatusFailed {
+		errorMessage = em.decodeInvokeFailure(ctx, plugin, op, errorMessage, opOutput)
+	}
+
+	// The status before this update, so a redelivered update for a completed operation is not observed twice
+	completing := isTerminalOpStatus(txState) && !isTerminalOpStatus(op.Status)
+
+	if err := em.txHelper.ResolveOperation(ctx, op.ID, txState, errorMessage, opOutput); err != nil {
+		return err
+	}
 
-	if err := em.database.ResolveOperation(ctx, op.ID, txState, errorMessage, opOutput); err != nil {
+	// Failed transactions still pay fees, so these are recorded whatever the outcome
+	if err := em.txHelper.RecordOperationFee(ctx, op, opOutput); err != nil {
 		return err
 	}
 
+	if completing && blockchainOpTypes[op.Type] && em.metrics.IsMetricsEnabled() {
+		em.metrics.BlockchainOperationCompleted(op, txState)
+	}
+
+	if op.CallbackURL != "" && isTerminalOpStatus(txState) {
+		if err := em.operations.DeliverCallback(ctx, op); err != nil {
+			return err
+		}
+	}
+
 	// Special handling for OpTypeTokenTransfer, which writes an event when it fails
 	if op.Type == fftypes.OpTypeTokenTransfer && txState == fftypes.OpStatusFailed {
 		tokenTransfer, err := txcommon.RetrieveTokenTransferInputs(ctx, op)
@@ -74,11 +132,45 @@ func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fft
 		}
 	}
 
+	// Special handling for OpTypeBlockchainContractDeploy, which records the address of the new contract as a blockchain event
+	if op.Type == fftypes.OpTypeBlockchainContractDeploy && txState == fftypes.OpStatusSucceeded {
+		if err := em.contractDeployed(ctx, op, blockchainTXID, opOutput); err != nil {
+			return err
+		}
+	}
+
 	return em.txHelper.AddBlockchainTX(ctx, op.Transaction, blockchainTXID)
 }
 
+func (em *eventManager) contractDeployed(ctx context.Context, op *fftypes.Operation, blockchainTXID string, opOutput fftypes.JSONObject) error {
+	address := opOutput.GetString("contractAddress")
+	if address == "" {
+		log.L(ctx).Warnf("Contract deployment operation '%s' succeeded without a contract address", op.ID)
+		return nil
+	}
+	chainEvent := &fftypes.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  op.Namespace,
+		Source:     op.Plugin,
+		Name:       "ContractDeployed",
+		ProtocolID: blockchainTXID,
+		Output:     fftypes.JSONObject{"address": address},
+		Info:       opOutput,
+		Timestamp:  fftypes.Now(),
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeContractDeploy,
+			ID:   op.Transaction,
+		},
+	}
+	if err := em.txHelper.InsertBlockchainEvent(ctx, chainEvent); err != nil {
+		return err
+	}
+	event := fftypes.NewEvent(fftypes.EventTypeBlockchainEventReceived, op.Namespace, chainEvent.ID, op.Transaction, fftypes.SystemContractDeployTopic)
+	return em.database.InsertEvent(ctx, event)
+}
+
 func (em *eventManager) OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
 	return em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
-		return em.operationUpdateCtx(ctx, operationID, txState, blockchainTXID, errorMessage, opOutput)
+		return em.operationUpdateCtx(ctx, plugin, operationID, txState, blockchainTXID, errorMessage, opOutput)
 	})
 }
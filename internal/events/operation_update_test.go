@@ -21,8 +21,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -43,16 +46,139 @@ func TestOperationUpdateSuccess(t *testing.T) {
 		args[1].(func(ctx context.Context) error)(em.ctx)
 	}).Return(nil)
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
-	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
 
 	err := em.OperationUpdate(mdi, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
+func TestOperationUpdateBlockchainMetrics(t *testing.T) {
+	em, cancel := newTestEventManagerWithMetrics(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mmi := em.metrics.(*metricsmocks.Manager)
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainInvoke, Created: fftypes.Now()}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", mock.Anything).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, mock.Anything).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+	mmi.On("BlockchainOperationCompleted", op, fftypes.OpStatusSucceeded).Return()
+
+	err := em.OperationUpdate(mdi, op.ID, fftypes.OpStatusSucceeded, "0x12345", "", fftypes.JSONObject{})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mmi.AssertCalled(t, "BlockchainOperationCompleted", op, fftypes.OpStatusSucceeded)
+}
+
+func TestOperationUpdateBlockchainMetricsNotCompleting(t *testing.T) {
+	tests := []struct {
+		name   string
+		opType fftypes.OpType
+		status fftypes.OpStatus
+	}{
+		{"redelivered", fftypes.OpTypeBlockchainInvoke, fftypes.OpStatusSucceeded},
+		{"not blockchain", fftypes.OpTypeTokenTransfer, fftypes.OpStatusPending},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			em, cancel := newTestEventManagerWithMetrics(t)
+			defer cancel()
+			mdi := em.database.(*databasemocks.Plugin)
+			mth := em.txHelper.(*txcommonmocks.Helper)
+			mmi := em.metrics.(*metricsmocks.Manager)
+
+			op := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), Type: test.opType, Status: test.status, Created: fftypes.Now()}
+			mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+			mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", mock.Anything).Return(nil)
+			mth.On("RecordOperationFee", mock.Anything, op, mock.Anything).Return(nil)
+			mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+			err := em.OperationUpdate(mdi, op.ID, fftypes.OpStatusSucceeded, "0x12345", "", fftypes.JSONObject{})
+			assert.NoError(t, err)
+
+			mth.AssertExpectations(t)
+			mmi.AssertNotCalled(t, "BlockchainOperationCompleted", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOperationUpdateCallback(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mom := em.operations.(*operationmocks.Manager)
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), CallbackURL: "https://example.com/callback"}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+	mom.On("DeliverCallback", mock.Anything, op).Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusSucceeded, "0x12345", "", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestOperationUpdateCallbackFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mom := em.operations.(*operationmocks.Manager)
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), CallbackURL: "https://example.com/callback"}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mom.On("DeliverCallback", mock.Anything, op).Return(fmt.Errorf("pop"))
+
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusSucceeded, "0x12345", "", info)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestOperationUpdateCallbackPending(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mom := em.operations.(*operationmocks.Manager)
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), CallbackURL: "https://example.com/callback"}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusPending, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusPending, "0x12345", "", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
 func TestOperationUpdateNotFound(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -63,7 +189,7 @@ func TestOperationUpdateNotFound(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(nil, fmt.Errorf("pop"))
 
 	info := fftypes.JSONObject{"some": "info"}
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusFailed, "", "some error", info)
 	assert.NoError(t, err) // swallowed after logging
 
 	mdi.AssertExpectations(t)
@@ -74,21 +200,45 @@ func TestOperationUpdateError(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	mbi := &blockchainmocks.Plugin{}
 
 	opID := fftypes.NewUUID()
 	txid := fftypes.NewUUID()
 	info := fftypes.JSONObject{"some": "info"}
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
-	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(fmt.Errorf("pop"))
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
+func TestOperationUpdateRecordFeeError(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	opID := fftypes.NewUUID()
+	txid := fftypes.NewUUID()
+	info := fftypes.JSONObject{"gasUsed": "21000"}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.ID.Equals(opID)
+	}), info).Return(fmt.Errorf("pop"))
+
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusSucceeded, "0x12345", "", info)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
 func TestOperationTXUpdateError(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -100,13 +250,15 @@ func TestOperationTXUpdateError(t *testing.T) {
 	txid := fftypes.NewUUID()
 	info := fftypes.JSONObject{"some": "info"}
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
-	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -126,19 +278,51 @@ func TestOperationUpdateTransferFailBadData(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeTransferOpFailed && e.Namespace == "ns1"
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
+func TestOperationUpdateCorrelationID(t *testing.T) {
+	em, cancel := newTestEventManagerWithMetrics(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := &fftypes.Operation{
+		ID:            fftypes.NewUUID(),
+		Type:          fftypes.OpTypeTokenTransfer,
+		Namespace:     "ns1",
+		Transaction:   fftypes.NewUUID(),
+		CorrelationID: "corr1",
+	}
+	info := fftypes.JSONObject{"some": "info"}
+
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
+	mdi.On("InsertEvent", mock.MatchedBy(func(ctx context.Context) bool {
+		return log.CorrelationID(ctx) == "corr1"
+	}), mock.Anything).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
 func TestOperationUpdateTransferFail(t *testing.T) {
 	em, cancel := newTestEventManagerWithMetrics(t)
 	defer cancel()
@@ -160,16 +344,18 @@ func TestOperationUpdateTransferFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeTransferOpFailed && e.Namespace == "ns1" && e.Correlator.Equals(localID)
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -189,14 +375,16 @@ func TestOperationUpdateTransferTransactionFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -204,6 +392,7 @@ func TestOperationUpdateTransferEventFail(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	mbi := &blockchainmocks.Plugin{}
 
 	op := &fftypes.Operation{
@@ -214,15 +403,17 @@ func TestOperationUpdateTransferEventFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeTransferOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -242,16 +433,18 @@ func TestOperationUpdateApprovalFailBadInput(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeApprovalOpFailed && e.Namespace == "ns1"
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -275,16 +468,18 @@ func TestOperationUpdateApprovalFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeApprovalOpFailed && e.Namespace == "ns1" && e.Correlator.Equals(localID)
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 func TestOperationUpdateApprovalTransactionFail(t *testing.T) {
@@ -303,14 +498,16 @@ func TestOperationUpdateApprovalTransactionFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
 
@@ -318,6 +515,7 @@ func TestOperationUpdateApprovalEventFail(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
 	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
 	mbi := &blockchainmocks.Plugin{}
 
 	op := &fftypes.Operation{
@@ -328,14 +526,227 @@ func TestOperationUpdateApprovalEventFail(t *testing.T) {
 	info := fftypes.JSONObject{"some": "info"}
 
 	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
-	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, mock.Anything, info).Return(nil)
 	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return e.Type == fftypes.EventTypeApprovalOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestOperationUpdateContractDeployed(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	opID := fftypes.NewUUID()
+	txid := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		ID:          opID,
+		Namespace:   "ns1",
+		Plugin:      "ethereum",
+		Transaction: txid,
+		Type:        fftypes.OpTypeBlockchainContractDeploy,
+	}
+	info := fftypes.JSONObject{"contractAddress": "0x23456"}
+	var chainEventID *fftypes.UUID
+	mdi.On("GetOperationByID", em.ctx, opID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.BlockchainEvent) bool {
+		chainEventID = event.ID
+		return event.Namespace == "ns1" && event.Source == "ethereum" && event.Name == "ContractDeployed" &&
+			event.ProtocolID == "0x12345" && event.Output.GetString("address") == "0x23456" &&
+			event.TX.Type == fftypes.TransactionTypeContractDeploy && *event.TX.ID == *txid
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeBlockchainEventReceived && event.Topic == fftypes.SystemContractDeployTopic &&
+			*event.Reference == *chainEventID && *event.Transaction == *txid
+	})).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusSucceeded, "0x12345", "", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestOperationUpdateContractDeployedNoAddress(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	opID := fftypes.NewUUID()
+	txid := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		ID:          opID,
+		Transaction: txid,
+		Type:        fftypes.OpTypeBlockchainContractDeploy,
+	}
+	info := fftypes.JSONObject{}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusSucceeded, "0x12345", "", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestOperationUpdateContractDeployedInsertFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	opID := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		ID:          opID,
+		Transaction: fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainContractDeploy,
+	}
+	info := fftypes.JSONObject{"contractAddress": "0x23456"}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("InsertBlockchainEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.operationUpdateCtx(em.ctx, nil, opID, fftypes.OpStatusSucceeded, "0x12345", "", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailedDecodesCustomError(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mbi := &blockchainmocks.Plugin{}
+
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainInvoke,
+		Transaction: fftypes.NewUUID(),
+		Input: fftypes.JSONObject{
+			"errors": []interface{}{
+				map[string]interface{}{"name": "CustomError"},
+			},
+		},
+	}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mbi.On("DecodeRevertReason", mock.Anything, info, mock.MatchedBy(func(errors []*fftypes.FFIErrorDefinition) bool {
+		return len(errors) == 1 && errors[0].Name == "CustomError"
+	})).Return("CustomError(1)", true)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "CustomError(1)", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, mbi, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
+
+func TestOperationUpdateInvokeFailedUndecoded(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mbi := &blockchainmocks.Plugin{}
+
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainInvoke,
+		Transaction: fftypes.NewUUID(),
+		Input: fftypes.JSONObject{
+			"errors": []interface{}{
+				map[string]interface{}{"name": "CustomError"},
+			},
+		},
+	}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mbi.On("DecodeRevertReason", mock.Anything, info, mock.Anything).Return("", false)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, mbi, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailedNoErrors(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mbi := &blockchainmocks.Plugin{}
+
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainInvoke,
+		Transaction: fftypes.NewUUID(),
+	}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, mbi, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailedNotBlockchainPlugin(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainInvoke,
+		Transaction: fftypes.NewUUID(),
+		Input: fftypes.JSONObject{
+			"errors": []interface{}{
+				map[string]interface{}{"name": "CustomError"},
+			},
+		},
+	}
+	info := fftypes.JSONObject{"some": "info"}
+	mdi.On("GetOperationByID", em.ctx, op.ID).Return(op, nil)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "some error", info).Return(nil)
+	mth.On("RecordOperationFee", mock.Anything, op, info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, nil, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
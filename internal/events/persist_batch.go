@@ -54,7 +54,11 @@ func (em *eventManager) persistBatch(ctx context.Context, batch *fftypes.Batch)
 
 	// Set confirmed on the batch (the messages should not be confirmed at this point - that's the aggregator's job)
 	persistedBatch, manifest := batch.Confirmed()
-	manifestHash := fftypes.HashString(persistedBatch.Manifest.String())
+	manifestHash, err := fftypes.HashStringWithAlgorithm(ctx, batch.HashAlgorithm, persistedBatch.Manifest.String())
+	if err != nil {
+		l.Errorf("Invalid batch '%s'. %s", batch.ID, err)
+		return nil, false, nil // This is not retryable. skip this batch
+	}
 
 	// Verify the hash calculation.
 	if !manifestHash.Equals(batch.Hash) {
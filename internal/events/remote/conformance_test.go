@@ -0,0 +1,315 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/events/conformance"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMessage is declared separately to remoteMessage, so the stub transport is written against the
+// wire contract in docs/reference/remote_event_transport.md rather than the Go types of the plugin
+type stubMessage struct {
+	Type          string                 `json:"type"`
+	ID            string                 `json:"id,omitempty"`
+	Version       int                    `json:"version,omitempty"`
+	ConnID        string                 `json:"connId,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Schema        json.RawMessage        `json:"schema,omitempty"`
+	Options       map[string]interface{} `json:"options,omitempty"`
+	Subscriptions []map[string]string    `json:"subscriptions,omitempty"`
+	Event         json.RawMessage        `json:"event,omitempty"`
+	Response      map[string]interface{} `json:"response,omitempty"`
+}
+
+// stubAppAction is the JSON an application sends to the stub transport, in the same form as the websockets plugin
+type stubAppAction struct {
+	Type         string                 `json:"type"`
+	Namespace    string                 `json:"namespace,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	ID           string                 `json:"id,omitempty"`
+	Subscription map[string]interface{} `json:"subscription,omitempty"`
+	Rejected     bool                   `json:"rejected,omitempty"`
+}
+
+// stubTransport is a minimal transport, run in a child process, that accepts the FireFly connection
+// on /firefly and application connections on /app
+type stubTransport struct {
+	mux      sync.Mutex
+	upgrader websocket.Upgrader
+	firefly  *websocket.Conn
+	apps     map[string]*websocket.Conn
+	nextConn int
+}
+
+func (st *stubTransport) write(conn *websocket.Conn, msg interface{}) {
+	b, _ := json.Marshal(msg)
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	if conn != nil {
+		_ = conn.WriteMessage(websocket.TextMessage, b)
+	}
+}
+
+func (st *stubTransport) toFireFly(msg *stubMessage) {
+	st.mux.Lock()
+	conn := st.firefly
+	st.mux.Unlock()
+	st.write(conn, msg)
+}
+
+func (st *stubTransport) serveFireFly(res http.ResponseWriter, req *http.Request) {
+	conn, err := st.upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var hello stubMessage
+	if err := conn.ReadJSON(&hello); err != nil || hello.Type != "hello" || hello.Version != 1 {
+		return
+	}
+	st.mux.Lock()
+	st.firefly = conn
+	st.mux.Unlock()
+	st.toFireFly(&stubMessage{
+		Type:    "options_schema",
+		Version: 1,
+		Schema:  json.RawMessage(`{"type":"object","properties":{"withData":{"const":false}}}`),
+	})
+
+	for {
+		var msg stubMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case "validate_options":
+			result := &stubMessage{Type: "validate_options_result", ID: msg.ID, Options: msg.Options}
+			if msg.Options["withData"] == true {
+				result.Error = "withData is not supported"
+			}
+			st.toFireFly(result)
+		case "delivery_request":
+			st.mux.Lock()
+			app := st.apps[msg.ConnID]
+			st.mux.Unlock()
+			st.write(app, msg.Event)
+		}
+	}
+}
+
+func (st *stubTransport) serveApp(res http.ResponseWriter, req *http.Request) {
+	conn, err := st.upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		return
+	}
+	st.mux.Lock()
+	st.nextConn++
+	connID := fmt.Sprintf("app%d", st.nextConn)
+	st.apps[connID] = conn
+	st.mux.Unlock()
+	defer func() {
+		st.mux.Lock()
+		delete(st.apps, connID)
+		st.mux.Unlock()
+		conn.Close()
+		st.toFireFly(&stubMessage{Type: "connection_closed", ConnID: connID})
+	}()
+
+	for {
+		var action stubAppAction
+		if err := conn.ReadJSON(&action); err != nil {
+			return
+		}
+		switch action.Type {
+		case "start":
+			st.toFireFly(&stubMessage{
+				Type:          "register_connection",
+				ConnID:        connID,
+				Subscriptions: []map[string]string{{"namespace": action.Namespace, "name": action.Name}},
+			})
+		case "ack":
+			st.toFireFly(&stubMessage{
+				Type:   "delivery_response",
+				ConnID: connID,
+				Response: map[string]interface{}{
+					"id":           action.ID,
+					"rejected":     action.Rejected,
+					"subscription": action.Subscription,
+				},
+			})
+		}
+	}
+}
+
+// runStubTransport writes the address it is listening on to stdout, and serves until stdin is closed
+func runStubTransport() error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	st := &stubTransport{apps: make(map[string]*websocket.Conn)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/firefly", st.serveFireFly)
+	mux.HandleFunc("/app", st.serveApp)
+	go func() { _ = http.Serve(l, mux) }()
+	fmt.Println(l.Addr().String())
+	_, err = io.Copy(ioutil.Discard, os.Stdin)
+	return err
+}
+
+func TestHelperStubTransport(t *testing.T) {
+	if os.Getenv("FF_REMOTE_STUB_TRANSPORT") != "true" {
+		t.Skip("only run as the stub transport process of TestConformanceRemote")
+	}
+	err := runStubTransport()
+	assert.NoError(t, err)
+}
+
+func startStubTransport(t *testing.T) (string, func()) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperStubTransport$")
+	cmd.Env = append(os.Environ(), "FF_REMOTE_STUB_TRANSPORT=true")
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	assert.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	err = cmd.Start()
+	assert.NoError(t, err)
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	assert.NoError(t, err)
+	return strings.TrimSpace(addr), func() {
+		stdin.Close()
+		_ = cmd.Wait()
+	}
+}
+
+type stubClient struct {
+	url string
+}
+
+type stubConnection struct {
+	conn     *websocket.Conn
+	received chan *fftypes.EventDelivery
+}
+
+func (c *stubClient) ValidOptions() []*fftypes.SubscriptionOptions {
+	return []*fftypes.SubscriptionOptions{{}}
+}
+
+func (c *stubClient) InvalidOptions() []*fftypes.SubscriptionOptions {
+	withData := true
+	return []*fftypes.SubscriptionOptions{
+		{SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{WithData: &withData}},
+	}
+}
+
+func (c *stubClient) Connect(t *testing.T, sub *fftypes.SubscriptionRef) conformance.Connection {
+	conn, _, err := websocket.DefaultDialer.Dial(c.url, nil)
+	assert.NoError(t, err)
+	sc := &stubConnection{conn: conn, received: make(chan *fftypes.EventDelivery, 100)}
+	go func() {
+		defer close(sc.received)
+		for {
+			var event fftypes.EventDelivery
+			if err := conn.ReadJSON(&event); err != nil {
+				return
+			}
+			sc.received <- &event
+		}
+	}()
+	err = conn.WriteJSON(&stubAppAction{Type: "start", Namespace: sub.Namespace, Name: sub.Name})
+	assert.NoError(t, err)
+	return sc
+}
+
+func (c *stubConnection) Receive(t *testing.T, timeout time.Duration) *fftypes.EventDelivery {
+	select {
+	case event := <-c.received:
+		return event
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func (c *stubConnection) ack(t *testing.T, event *fftypes.EventDelivery, rejected bool) {
+	err := c.conn.WriteJSON(&stubAppAction{
+		Type:     "ack",
+		ID:       event.ID.String(),
+		Rejected: rejected,
+		Subscription: map[string]interface{}{
+			"id":        event.Subscription.ID,
+			"namespace": event.Subscription.Namespace,
+			"name":      event.Subscription.Name,
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func (c *stubConnection) Ack(t *testing.T, event *fftypes.EventDelivery) {
+	c.ack(t, event, false)
+}
+
+func (c *stubConnection) Nack(t *testing.T, event *fftypes.EventDelivery) {
+	c.ack(t, event, true)
+}
+
+func (c *stubConnection) Close(t *testing.T) {
+	c.conn.Close()
+}
+
+func TestConformanceRemote(t *testing.T) {
+	conformance.Run(t, func(t *testing.T, callbacks events.Callbacks) (events.Plugin, conformance.Client, func()) {
+		addr, stop := startStubTransport(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &Remote{}
+		prefix := config.NewPluginConfig("unittest.conformance.remote")
+		r.InitPrefix(prefix)
+		prefix.Set(restclient.HTTPConfigURL, fmt.Sprintf("http://%s", addr))
+		prefix.Set(wsconfig.WSConfigKeyPath, "/firefly")
+		err := r.Init(ctx, prefix, callbacks)
+		assert.NoError(t, err)
+		assert.Eventually(t, r.isReady, 5*time.Second, 10*time.Millisecond)
+		client := &stubClient{url: fmt.Sprintf("ws://%s/app", addr)}
+		return r, client, func() {
+			cancel()
+			stop()
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// The remote transport protocol mirrors the events.Plugin interface, as JSON messages exchanged over
+// a WebSocket connection that FireFly makes to the external transport process. The contract is
+// published in docs/reference/remote_event_transport.md, and any change to it that is not
+// backwards compatible must increment remoteProtocolVersion.
+//
+// Each time it connects, FireFly sends a hello, and the transport must answer with an options_schema
+// declaring the same version before FireFly processes any other message from it.
+//
+// FireFly sends:
+// - hello: {version} - the first message on every connection
+// - validate_options: {id, options} - a subscription is being created, and must receive a validate_options_result with the same id
+// - delivery_request: {connId, subscription, event, data} - an event to deliver on a connection the transport registered
+//
+// The transport sends:
+// - options_schema: {version, schema} - the protocol version, and JSON schema of the transport specific subscription options
+// - validate_options_result: {id, options, error} - the (optionally modified) options, or an error to reject them
+// - register_connection: {connId, subscriptions} - dispatch durable subscriptions to the connection, all if no subscriptions are listed
+// - ephemeral_subscription: {connId, namespace, filter, options} - create a non-durable subscription for the connection
+// - connection_closed: {connId} - the connection has gone, and its subscriptions should be re-allocated
+// - delivery_response: {connId, response} - acknowledge or reject an event delivery, with an optional reply message
+//
+// All connections registered by the transport are closed if the WebSocket disconnects, and must be registered again on reconnect.
+const remoteProtocolVersion = 1
+
+const (
+	messageHello                 = "hello"
+	messageValidateOptions       = "validate_options"
+	messageDeliveryRequest       = "delivery_request"
+	messageOptionsSchema         = "options_schema"
+	messageValidateOptionsResult = "validate_options_result"
+	messageRegisterConnection    = "register_connection"
+	messageEphemeralSubscription = "ephemeral_subscription"
+	messageConnectionClosed      = "connection_closed"
+	messageDeliveryResponse      = "delivery_response"
+)
+
+type remoteMessage struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Version       int                            `json:"version,omitempty"`
+	ConnID        string                         `json:"connId,omitempty"`
+	Error         string                         `json:"error,omitempty"`
+	Schema        *fftypes.JSONAny               `json:"schema,omitempty"`
+	Options       *fftypes.SubscriptionOptions   `json:"options,omitempty"`
+	Subscriptions []*fftypes.SubscriptionRef     `json:"subscriptions,omitempty"`
+	Namespace     string                         `json:"namespace,omitempty"`
+	Filter        *fftypes.SubscriptionFilter    `json:"filter,omitempty"`
+	Subscription  *fftypes.Subscription          `json:"subscription,omitempty"`
+	Event         *fftypes.EventDelivery         `json:"event,omitempty"`
+	Data          fftypes.DataArray              `json:"data,omitempty"`
+	Response      *fftypes.EventDeliveryResponse `json:"response,omitempty"`
+}
@@ -0,0 +1,307 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+)
+
+// Remote is an event transport plugin that proxies each call of the plugin interface to
+// a transport implementation running in a separate process, so new transports can be
+// built and deployed without rebuilding FireFly.
+type Remote struct {
+	ctx            context.Context
+	capabilities   *events.Capabilities
+	callbacks      events.Callbacks
+	wsconn         wsclient.WSClient
+	requestTimeout time.Duration
+	mux            sync.Mutex
+	optionsSchema  string
+	ready          bool
+	pending        map[string]chan *remoteMessage
+	connections    map[string]bool
+}
+
+func (r *Remote) Name() string { return "remote" }
+
+func (r *Remote) Init(ctx context.Context, prefix config.Prefix, callbacks events.Callbacks) (err error) {
+	*r = Remote{
+		ctx:            log.WithLogField(ctx, "proto", "remote"),
+		capabilities:   &events.Capabilities{},
+		callbacks:      callbacks,
+		requestTimeout: prefix.GetDuration(RemoteConfigRequestTimeout),
+		pending:        make(map[string]chan *remoteMessage),
+		connections:    make(map[string]bool),
+	}
+
+	if prefix.GetString(restclient.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "events.remote")
+	}
+
+	wsConfig := wsconfig.GenerateConfigFromPrefix(prefix)
+	r.wsconn, err = wsclient.New(ctx, wsConfig, nil, r.afterConnect)
+	if err != nil {
+		return err
+	}
+	r.wsconn.OnStateChange(r.stateChange)
+
+	if err = r.wsconn.Connect(); err != nil {
+		return err
+	}
+
+	go r.eventLoop()
+
+	return nil
+}
+
+func (r *Remote) Capabilities() *events.Capabilities {
+	return r.capabilities
+}
+
+func (r *Remote) GetOptionsSchema(ctx context.Context) string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.optionsSchema == "" {
+		return `{}` // the transport has not told us its options yet
+	}
+	return r.optionsSchema
+}
+
+func (r *Remote) ValidateOptions(options *fftypes.SubscriptionOptions) error {
+	if r.wsconn.State() != wsclient.WSStateConnected {
+		return i18n.NewError(r.ctx, i18n.MsgPluginNotConnected, r.Name(), r.wsconn.State())
+	}
+	if !r.isReady() {
+		return i18n.NewError(r.ctx, i18n.MsgRemoteTransportNotReady, remoteProtocolVersion)
+	}
+	// Redelivery is enforced by the dispatcher, so we check it here rather than relying on the transport
+	if err := options.Redelivery.Validate(r.ctx); err != nil {
+		return err
+	}
+
+	id := fftypes.NewUUID().String()
+	replyChan := make(chan *remoteMessage, 1)
+	r.mux.Lock()
+	r.pending[id] = replyChan
+	r.mux.Unlock()
+	defer func() {
+		r.mux.Lock()
+		delete(r.pending, id)
+		r.mux.Unlock()
+	}()
+
+	err := r.send(&remoteMessage{
+		Type:    messageValidateOptions,
+		ID:      id,
+		Options: options,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case reply := <-replyChan:
+		if reply.Error != "" {
+			return i18n.NewError(r.ctx, i18n.MsgRemoteTransportError, reply.Error)
+		}
+		if reply.Options != nil {
+			// The transport can modify the core options, as a local plugin would
+			*options = *reply.Options
+		}
+		return nil
+	case <-time.After(r.requestTimeout):
+		return i18n.NewError(r.ctx, i18n.MsgRemoteTransportTimeout, messageValidateOptions)
+	}
+}
+
+func (r *Remote) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	r.mux.Lock()
+	active := r.connections[connID]
+	r.mux.Unlock()
+	if !active {
+		return i18n.NewError(r.ctx, i18n.MsgWSConnectionNotActive, connID)
+	}
+	return r.send(&remoteMessage{
+		Type:         messageDeliveryRequest,
+		ConnID:       connID,
+		Subscription: sub,
+		Event:        event,
+		Data:         data,
+	})
+}
+
+func (r *Remote) afterConnect(ctx context.Context, w wsclient.WSClient) error {
+	b, _ := json.Marshal(&remoteMessage{Type: messageHello, Version: remoteProtocolVersion})
+	return w.Send(ctx, b)
+}
+
+func (r *Remote) isReady() bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.ready
+}
+
+func (r *Remote) send(msg *remoteMessage) error {
+	b, _ := json.Marshal(msg)
+	return r.wsconn.Send(r.ctx, b)
+}
+
+func (r *Remote) stateChange(ctx context.Context, state wsclient.WSConnectionState) {
+	if state != wsclient.WSStateDisconnected && state != wsclient.WSStateClosed {
+		return
+	}
+	// Every connection was owned by the remote process, so they all go with the WebSocket
+	r.mux.Lock()
+	r.ready = false
+	closed := make([]string, 0, len(r.connections))
+	for connID := range r.connections {
+		closed = append(closed, connID)
+	}
+	r.connections = make(map[string]bool)
+	r.mux.Unlock()
+	// Drop lock before calling back
+	for _, connID := range closed {
+		r.callbacks.ConnnectionClosed(connID)
+	}
+}
+
+func (r *Remote) optionsSchemaReceived(msg *remoteMessage) error {
+	if msg.Version != remoteProtocolVersion {
+		return i18n.NewError(r.ctx, i18n.MsgRemoteTransportVersion, msg.Version, remoteProtocolVersion)
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.ready = true
+	if msg.Schema != nil {
+		r.optionsSchema = msg.Schema.String()
+	}
+	return nil
+}
+
+func (r *Remote) registerConnection(msg *remoteMessage) error {
+	r.mux.Lock()
+	r.connections[msg.ConnID] = true
+	r.mux.Unlock()
+	subs := msg.Subscriptions
+	return r.callbacks.RegisterConnection(msg.ConnID, func(sr fftypes.SubscriptionRef) bool {
+		if len(subs) == 0 {
+			return true
+		}
+		for _, s := range subs {
+			if s.Namespace == sr.Namespace && s.Name == sr.Name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (r *Remote) ephemeralSubscription(msg *remoteMessage) error {
+	r.mux.Lock()
+	r.connections[msg.ConnID] = true
+	r.mux.Unlock()
+	filter := msg.Filter
+	if filter == nil {
+		filter = &fftypes.SubscriptionFilter{}
+	}
+	options := msg.Options
+	if options == nil {
+		options = &fftypes.SubscriptionOptions{}
+	}
+	return r.callbacks.EphemeralSubscription(msg.ConnID, msg.Namespace, filter, options)
+}
+
+func (r *Remote) connectionClosed(msg *remoteMessage) {
+	r.mux.Lock()
+	delete(r.connections, msg.ConnID)
+	r.mux.Unlock()
+	r.callbacks.ConnnectionClosed(msg.ConnID)
+}
+
+func (r *Remote) validateOptionsResult(msg *remoteMessage) {
+	r.mux.Lock()
+	replyChan, ok := r.pending[msg.ID]
+	r.mux.Unlock()
+	if !ok {
+		log.L(r.ctx).Warnf("Received result for unknown request '%s'", msg.ID)
+		return
+	}
+	replyChan <- msg
+}
+
+func (r *Remote) eventLoop() {
+	defer r.wsconn.Close()
+	l := log.L(r.ctx).WithField("role", "event-loop")
+	ctx := log.WithLogger(r.ctx, l)
+	for {
+		select {
+		case <-ctx.Done():
+			l.Debugf("Event loop exiting (context cancelled)")
+			return
+		case msgBytes, ok := <-r.wsconn.Receive():
+			if !ok {
+				l.Debugf("Event loop exiting (receive channel closed)")
+				return
+			}
+
+			var msg remoteMessage
+			err := json.Unmarshal(msgBytes, &msg)
+			if err != nil {
+				l.Errorf("Message cannot be parsed as JSON: %s\n%s", err, string(msgBytes))
+				continue // Swallow this and move on
+			}
+			l.Debugf("Received %s message", msg.Type)
+			if msg.Type != messageOptionsSchema && !r.isReady() {
+				l.Errorf("Message %s received before the transport declared protocol version %d", msg.Type, remoteProtocolVersion)
+				continue
+			}
+			switch msg.Type {
+			case messageOptionsSchema:
+				err = r.optionsSchemaReceived(&msg)
+			case messageValidateOptionsResult:
+				r.validateOptionsResult(&msg)
+			case messageRegisterConnection:
+				err = r.registerConnection(&msg)
+			case messageEphemeralSubscription:
+				err = r.ephemeralSubscription(&msg)
+			case messageConnectionClosed:
+				r.connectionClosed(&msg)
+			case messageDeliveryResponse:
+				if msg.Response != nil {
+					r.callbacks.DeliveryResponse(msg.ConnID, msg.Response)
+				}
+			default:
+				l.Errorf("Message unexpected: %s", msg.Type)
+			}
+			if err != nil {
+				l.Errorf("Failed to process %s message for connection '%s': %s", msg.Type, msg.ConnID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("ut.remote")
+
+func newTestRemote(t *testing.T) (r *Remote, cbs *eventsmocks.Callbacks, toServer, fromServer chan string, cancel func()) {
+	toServer, fromServer, wsURL, done := wsclient.NewTestWSServer(nil)
+
+	u, _ := url.Parse(wsURL)
+	u.Scheme = "http"
+
+	config.Reset()
+	r = &Remote{}
+	r.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(restclient.HTTPConfigURL, u.String())
+	utConfPrefix.Set(RemoteConfigRequestTimeout, "5s")
+
+	cbs = &eventsmocks.Callbacks{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := r.Init(ctx, utConfPrefix, cbs)
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", r.Name())
+	assert.NotNil(t, r.Capabilities())
+
+	var hello remoteMessage
+	err = json.Unmarshal([]byte(<-toServer), &hello)
+	assert.NoError(t, err)
+	assert.Equal(t, messageHello, hello.Type)
+	assert.Equal(t, remoteProtocolVersion, hello.Version)
+	sendMsg(fromServer, &remoteMessage{Type: messageOptionsSchema, Version: remoteProtocolVersion})
+	assert.Eventually(t, r.isReady, 5*time.Second, 10*time.Millisecond)
+	return r, cbs, toServer, fromServer, func() {
+		cancelCtx()
+		done()
+	}
+}
+
+func sendMsg(fromServer chan string, msg *remoteMessage) {
+	b, _ := json.Marshal(msg)
+	fromServer <- string(b)
+}
+
+func TestInitMissingURL(t *testing.T) {
+	config.Reset()
+	r := &Remote{}
+	r.InitPrefix(utConfPrefix)
+	err := r.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestInitBadURL(t *testing.T) {
+	config.Reset()
+	r := &Remote{}
+	r.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(restclient.HTTPConfigURL, "::::")
+	err := r.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10162", err)
+}
+
+func TestInitConnectFail(t *testing.T) {
+	_, _, wsURL, done := wsclient.NewTestWSServer(nil)
+	done()
+	u, _ := url.Parse(wsURL)
+	u.Scheme = "http"
+
+	config.Reset()
+	r := &Remote{}
+	r.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(restclient.HTTPConfigURL, u.String())
+	utConfPrefix.Set(wsconfig.WSConfigKeyInitialConnectAttempts, 1)
+	err := r.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10161", err)
+}
+
+func TestOptionsSchema(t *testing.T) {
+	r, _, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	assert.Equal(t, "{}", r.GetOptionsSchema(r.ctx))
+
+	sendMsg(fromServer, &remoteMessage{
+		Type:    messageOptionsSchema,
+		Version: 2,
+		Schema:  fftypes.JSONAnyPtr(`{"type":"array"}`),
+	}) // rejected
+	sendMsg(fromServer, &remoteMessage{
+		Type:    messageOptionsSchema,
+		Version: remoteProtocolVersion,
+		Schema:  fftypes.JSONAnyPtr(`{"type":"object"}`),
+	})
+	assert.Eventually(t, func() bool {
+		return r.GetOptionsSchema(r.ctx) == `{"type":"object"}`
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestValidateOptionsOk(t *testing.T) {
+	r, _, toServer, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	go func() {
+		var req remoteMessage
+		err := json.Unmarshal([]byte(<-toServer), &req)
+		assert.NoError(t, err)
+		assert.Equal(t, messageValidateOptions, req.Type)
+		assert.Equal(t, "value1", req.Options.TransportOptions()["opt1"])
+		sendMsg(fromServer, &remoteMessage{Type: messageValidateOptionsResult, ID: "unknown"}) // ignored
+		withData := true
+		req.Options.WithData = &withData
+		sendMsg(fromServer, &remoteMessage{
+			Type:    messageValidateOptionsResult,
+			ID:      req.ID,
+			Options: req.Options,
+		})
+	}()
+
+	options := &fftypes.SubscriptionOptions{}
+	options.TransportOptions()["opt1"] = "value1"
+	err := r.ValidateOptions(options)
+	assert.NoError(t, err)
+	assert.True(t, *options.WithData)
+}
+
+func TestValidateOptionsNoOptionsReturned(t *testing.T) {
+	r, _, toServer, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	go func() {
+		var req remoteMessage
+		_ = json.Unmarshal([]byte(<-toServer), &req)
+		sendMsg(fromServer, &remoteMessage{Type: messageValidateOptionsResult, ID: req.ID})
+	}()
+
+	options := &fftypes.SubscriptionOptions{}
+	err := r.ValidateOptions(options)
+	assert.NoError(t, err)
+	assert.Nil(t, options.WithData)
+}
+
+func TestValidateOptionsRejected(t *testing.T) {
+	r, _, toServer, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	go func() {
+		var req remoteMessage
+		_ = json.Unmarshal([]byte(<-toServer), &req)
+		sendMsg(fromServer, &remoteMessage{
+			Type:  messageValidateOptionsResult,
+			ID:    req.ID,
+			Error: "pop",
+		})
+	}()
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{})
+	assert.Regexp(t, "FF10404.*pop", err)
+}
+
+func TestValidateOptionsBadRedelivery(t *testing.T) {
+	r, _, _, _, cancel := newTestRemote(t)
+	defer cancel()
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			Redelivery: &fftypes.SubOptsRedelivery{Policy: fftypes.SubOptsRedeliveryFixed},
+		},
+	})
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestValidateOptionsTimeout(t *testing.T) {
+	r, _, toServer, _, cancel := newTestRemote(t)
+	defer cancel()
+	r.requestTimeout = 1 * time.Millisecond
+
+	go func() { <-toServer }()
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{})
+	assert.Regexp(t, "FF10403", err)
+}
+
+func TestValidateOptionsSendFail(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	r := &Remote{
+		ctx:     context.Background(),
+		wsconn:  wsm,
+		ready:   true,
+		pending: make(map[string]chan *remoteMessage),
+	}
+	wsm.On("State").Return(wsclient.WSStateConnected)
+	wsm.On("Send", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{})
+	assert.Regexp(t, "pop", err)
+	wsm.AssertExpectations(t)
+}
+
+func TestValidateOptionsNotConnected(t *testing.T) {
+	r, _, _, _, cancel := newTestRemote(t)
+	cancel()
+	r.wsconn.Close()
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{})
+	assert.Regexp(t, "FF10389", err)
+}
+
+func TestValidateOptionsNotReady(t *testing.T) {
+	r, cbs, _, _, cancel := newTestRemote(t)
+	defer cancel()
+	cbs.On("ConnnectionClosed", mock.Anything).Maybe() // on close of the WebSocket
+	r.mux.Lock()
+	r.ready = false
+	r.mux.Unlock()
+
+	err := r.ValidateOptions(&fftypes.SubscriptionOptions{})
+	assert.Regexp(t, "FF10578", err)
+}
+
+func TestMessagesIgnoredBeforeVersionDeclared(t *testing.T) {
+	r, cbs, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+	cbs.On("ConnnectionClosed", mock.Anything).Maybe() // on close of the WebSocket
+	r.stateChange(r.ctx, wsclient.WSStateDisconnected)
+	assert.False(t, r.isReady())
+
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn1"}) // ignored
+	sendMsg(fromServer, &remoteMessage{Type: messageOptionsSchema, Version: 2})           // rejected
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn2"}) // ignored
+	sendMsg(fromServer, &remoteMessage{Type: messageOptionsSchema, Version: remoteProtocolVersion})
+
+	registered := make(chan struct{})
+	cbs.On("RegisterConnection", "conn3", mock.Anything).Return(nil).Run(func(a mock.Arguments) { close(registered) })
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn3"})
+	<-registered
+
+	cbs.AssertExpectations(t)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	assert.Equal(t, map[string]bool{"conn3": true}, r.connections)
+}
+
+func TestConnectionLifecycle(t *testing.T) {
+	r, cbs, toServer, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	registered := make(chan struct{})
+	rc := cbs.On("RegisterConnection", "conn1", mock.Anything).Return(fmt.Errorf("pop")) // error is logged
+	rc.RunFn = func(a mock.Arguments) {
+		matcher := a[1].(events.SubscriptionMatcher)
+		assert.True(t, matcher(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}))
+		assert.False(t, matcher(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub2"}))
+		close(registered)
+	}
+	sendMsg(fromServer, &remoteMessage{
+		Type:   messageRegisterConnection,
+		ConnID: "conn1",
+		Subscriptions: []*fftypes.SubscriptionRef{
+			{Namespace: "ns1", Name: "sub1"},
+		},
+	})
+	<-registered
+
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}}
+	event := &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}}}
+	err := r.DeliveryRequest("conn1", sub, event, fftypes.DataArray{})
+	assert.NoError(t, err)
+	var req remoteMessage
+	err = json.Unmarshal([]byte(<-toServer), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, messageDeliveryRequest, req.Type)
+	assert.Equal(t, "conn1", req.ConnID)
+	assert.Equal(t, *event.ID, *req.Event.ID)
+	assert.Equal(t, "sub1", req.Subscription.Name)
+
+	responded := make(chan struct{})
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(edr *fftypes.EventDeliveryResponse) bool {
+		return *edr.ID == *event.ID
+	})).Run(func(a mock.Arguments) { close(responded) })
+	sendMsg(fromServer, &remoteMessage{Type: messageDeliveryResponse, ConnID: "conn1"}) // ignored
+	sendMsg(fromServer, &remoteMessage{
+		Type:     messageDeliveryResponse,
+		ConnID:   "conn1",
+		Response: &fftypes.EventDeliveryResponse{ID: event.ID},
+	})
+	<-responded
+
+	closed := make(chan struct{})
+	cbs.On("ConnnectionClosed", "conn1").Run(func(a mock.Arguments) { close(closed) })
+	sendMsg(fromServer, &remoteMessage{Type: messageConnectionClosed, ConnID: "conn1"})
+	<-closed
+
+	err = r.DeliveryRequest("conn1", sub, event, fftypes.DataArray{})
+	assert.Regexp(t, "FF10173", err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestRegisterConnectionAllSubscriptions(t *testing.T) {
+	r, cbs, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+	cbs.On("ConnnectionClosed", mock.Anything).Maybe() // on close of the WebSocket
+
+	registered := make(chan struct{})
+	rc := cbs.On("RegisterConnection", "conn1", mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.True(t, a[1].(events.SubscriptionMatcher)(fftypes.SubscriptionRef{Namespace: "ns1", Name: "any"}))
+		close(registered)
+	}
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn1"})
+	<-registered
+
+	cbs.AssertExpectations(t)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	assert.True(t, r.connections["conn1"])
+}
+
+func TestEphemeralSubscription(t *testing.T) {
+	_, cbs, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+	cbs.On("ConnnectionClosed", mock.Anything).Maybe() // on close of the WebSocket
+
+	subscribed := make(chan struct{}, 2)
+	cbs.On("EphemeralSubscription", "conn1", "ns1", &fftypes.SubscriptionFilter{}, &fftypes.SubscriptionOptions{}).
+		Return(nil).Run(func(a mock.Arguments) { subscribed <- struct{}{} })
+	cbs.On("EphemeralSubscription", "conn2", "ns1", &fftypes.SubscriptionFilter{Topic: "topic1"}, mock.MatchedBy(func(o *fftypes.SubscriptionOptions) bool {
+		return o.FirstEvent != nil && *o.FirstEvent == fftypes.SubOptsFirstEventOldest
+	})).Return(nil).Run(func(a mock.Arguments) { subscribed <- struct{}{} })
+
+	sendMsg(fromServer, &remoteMessage{Type: messageEphemeralSubscription, ConnID: "conn1", Namespace: "ns1"})
+	firstEvent := fftypes.SubOptsFirstEventOldest
+	sendMsg(fromServer, &remoteMessage{
+		Type:      messageEphemeralSubscription,
+		ConnID:    "conn2",
+		Namespace: "ns1",
+		Filter:    &fftypes.SubscriptionFilter{Topic: "topic1"},
+		Options: &fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{FirstEvent: &firstEvent},
+		},
+	})
+	<-subscribed
+	<-subscribed
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDisconnectClosesConnections(t *testing.T) {
+	r, cbs, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+
+	registered := make(chan struct{})
+	cbs.On("RegisterConnection", "conn1", mock.Anything).Return(nil).Run(func(a mock.Arguments) { close(registered) })
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn1"})
+	<-registered
+
+	closed := make(chan struct{})
+	cbs.On("ConnnectionClosed", "conn1").Run(func(a mock.Arguments) { close(closed) })
+	r.stateChange(r.ctx, wsclient.WSStateConnecting) // ignored
+	r.stateChange(r.ctx, wsclient.WSStateDisconnected)
+	<-closed
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	assert.Empty(t, r.connections)
+	cbs.AssertExpectations(t)
+}
+
+func TestEventLoopBadMessages(t *testing.T) {
+	_, cbs, _, fromServer, cancel := newTestRemote(t)
+	defer cancel()
+	cbs.On("ConnnectionClosed", mock.Anything).Maybe() // on close of the WebSocket
+
+	fromServer <- `!json`
+	fromServer <- `{"type":"unknown"}`
+
+	// Use a round trip to ensure the bad messages have been processed
+	registered := make(chan struct{})
+	cbs.On("RegisterConnection", "conn1", mock.Anything).Return(nil).Run(func(a mock.Arguments) { close(registered) })
+	sendMsg(fromServer, &remoteMessage{Type: messageRegisterConnection, ConnID: "conn1"})
+	<-registered
+	cbs.AssertExpectations(t)
+}
+
+func TestEventLoopReceiveClosed(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	receive := make(chan []byte)
+	close(receive)
+	var rc <-chan []byte = receive
+	wsm.On("Receive").Return(rc)
+	wsm.On("Close").Return()
+	r := &Remote{ctx: context.Background(), wsconn: wsm}
+	r.eventLoop() // exits as receive channel is closed
+	wsm.AssertExpectations(t)
+}
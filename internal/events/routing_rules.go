@@ -0,0 +1,147 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// routingRule is the compiled form of a fftypes.RoutingRule
+type routingRule struct {
+	definition  *fftypes.RoutingRule
+	tagFilter   *regexp.Regexp
+	topicFilter *regexp.Regexp
+}
+
+// routingRules holds the compiled routing rules for every namespace. Each namespace is reloaded in
+// full from the database whenever one of its rules changes, so dispatchers always see a consistent set.
+type routingRules struct {
+	ctx         context.Context
+	database    database.Plugin
+	retry       *retry.Retry
+	mux         sync.RWMutex
+	byNamespace map[string][]*routingRule
+}
+
+func newRoutingRules(ctx context.Context, di database.Plugin, rt *retry.Retry) *routingRules {
+	return &routingRules{
+		ctx:         ctx,
+		database:    di,
+		retry:       rt,
+		byNamespace: make(map[string][]*routingRule),
+	}
+}
+
+func compileRoutingRule(ctx context.Context, def *fftypes.RoutingRule) (rr *routingRule, err error) {
+	if err = def.Validate(ctx); err != nil {
+		return nil, err
+	}
+	rr = &routingRule{definition: def}
+	if def.Tag != "" {
+		rr.tagFilter = regexp.MustCompile(def.Tag)
+	}
+	if def.Topic != "" {
+		rr.topicFilter = regexp.MustCompile(def.Topic)
+	}
+	return rr, nil
+}
+
+func (rs *routingRules) compile(defs []*fftypes.RoutingRule) map[string][]*routingRule {
+	byNamespace := make(map[string][]*routingRule)
+	for _, def := range defs {
+		rr, err := compileRoutingRule(rs.ctx, def)
+		if err != nil {
+			// Warn and continue, as the rule is simply invalid
+			log.L(rs.ctx).Warnf("Failed to load routing rule %s:%s [%s]: %s", def.Namespace, def.Name, def.ID, err)
+			continue
+		}
+		byNamespace[def.Namespace] = append(byNamespace[def.Namespace], rr)
+	}
+	return byNamespace
+}
+
+func (rs *routingRules) loadAll() error {
+	fb := database.RoutingRuleQueryFactory.NewFilter(rs.ctx)
+	defs, _, err := rs.database.GetRoutingRules(rs.ctx, fb.And())
+	if err != nil {
+		return err
+	}
+	byNamespace := rs.compile(defs)
+	rs.mux.Lock()
+	rs.byNamespace = byNamespace
+	rs.mux.Unlock()
+	log.L(rs.ctx).Infof("Loaded %d routing rules", len(defs))
+	return nil
+}
+
+func (rs *routingRules) reload(ns string) {
+	var defs []*fftypes.RoutingRule
+	err := rs.retry.Do(rs.ctx, "retrieve routing rules", func(attempt int) (retry bool, err error) {
+		fb := database.RoutingRuleQueryFactory.NewFilter(rs.ctx)
+		defs, _, err = rs.database.GetRoutingRules(rs.ctx, fb.Eq("namespace", ns))
+		return err != nil, err // indefinite retry
+	})
+	if err != nil {
+		// The context was cancelled, so we're closing
+		log.L(rs.ctx).Infof("Unable to reload routing rules for namespace '%s' (%v)", ns, err)
+		return
+	}
+	rules := rs.compile(defs)[ns]
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	if len(rules) == 0 {
+		delete(rs.byNamespace, ns)
+	} else {
+		rs.byNamespace[ns] = rules
+	}
+	log.L(rs.ctx).Infof("Reloaded %d routing rules for namespace '%s'", len(rules), ns)
+}
+
+// labels returns the sorted, de-duplicated labels of every rule in the namespace that matches the tag and topic
+func (rs *routingRules) labels(ns, tag, topic string) []string {
+	rs.mux.RLock()
+	defer rs.mux.RUnlock()
+	unique := make(map[string]bool)
+	for _, rr := range rs.byNamespace[ns] {
+		if rr.tagFilter != nil && !rr.tagFilter.MatchString(tag) {
+			continue
+		}
+		if rr.topicFilter != nil && !rr.topicFilter.MatchString(topic) {
+			continue
+		}
+		for _, label := range rr.definition.Labels {
+			unique[label] = true
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(unique))
+	for label := range unique {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
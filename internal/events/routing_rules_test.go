@@ -0,0 +1,108 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRoutingRules() (*routingRules, *databasemocks.Plugin, func()) {
+	mdi := &databasemocks.Plugin{}
+	ctx, cancel := context.WithCancel(context.Background())
+	return newRoutingRules(ctx, mdi, &retry.Retry{}), mdi, cancel
+}
+
+func TestRoutingRulesLoadAllAndMatch(t *testing.T) {
+	rs, mdi, cancel := newTestRoutingRules()
+	defer cancel()
+
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{
+		{Namespace: "ns1", Name: "invoices", Tag: "^invoice", Labels: fftypes.FFStringArray{"finance", "billing"}},
+		{Namespace: "ns1", Name: "orders", Topic: "^orders$", Labels: fftypes.FFStringArray{"finance", "orders"}},
+		{Namespace: "ns1", Name: "invalid", Tag: "[", Labels: fftypes.FFStringArray{"never"}},
+		{Namespace: "ns2", Name: "everything", Labels: fftypes.FFStringArray{"all"}},
+	}, nil, nil)
+
+	err := rs.loadAll()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"billing", "finance", "orders"}, rs.labels("ns1", "invoice1", "orders"))
+	assert.Equal(t, []string{"billing", "finance"}, rs.labels("ns1", "invoice1", "other"))
+	assert.Nil(t, rs.labels("ns1", "other", "other"))
+	assert.Equal(t, []string{"all"}, rs.labels("ns2", "", ""))
+	assert.Nil(t, rs.labels("ns3", "invoice1", "orders"))
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRoutingRulesLoadAllFail(t *testing.T) {
+	rs, mdi, cancel := newTestRoutingRules()
+	defer cancel()
+
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := rs.loadAll()
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRoutingRulesReload(t *testing.T) {
+	rs, mdi, cancel := newTestRoutingRules()
+	defer cancel()
+
+	rs.byNamespace["ns1"] = []*routingRule{
+		{definition: &fftypes.RoutingRule{Labels: fftypes.FFStringArray{"old"}}},
+	}
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{
+		{Namespace: "ns1", Name: "rule1", Labels: fftypes.FFStringArray{"new"}},
+	}, nil, nil).Once()
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil).Once()
+
+	rs.reload("ns1")
+	assert.Equal(t, []string{"new"}, rs.labels("ns1", "", ""))
+
+	rs.reload("ns1")
+	assert.Nil(t, rs.labels("ns1", "", ""))
+	assert.NotContains(t, rs.byNamespace, "ns1")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRoutingRulesReloadClosed(t *testing.T) {
+	rs, mdi, cancel := newTestRoutingRules()
+	cancel()
+
+	rs.byNamespace["ns1"] = []*routingRule{
+		{definition: &fftypes.RoutingRule{Labels: fftypes.FFStringArray{"old"}}},
+	}
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	rs.reload("ns1")
+	assert.Equal(t, []string{"old"}, rs.labels("ns1", "", ""))
+
+	mdi.AssertExpectations(t)
+}
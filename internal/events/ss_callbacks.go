@@ -18,7 +18,6 @@ package events
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -31,12 +30,14 @@ func (em *eventManager) SharedStorageBatchDownloaded(ss sharedstorage.Plugin, ns
 
 	// De-serializae the batch
 	var batch *fftypes.Batch
-	err := json.Unmarshal(data, &batch)
+	protocolVersion, err := fftypes.DeserializeTransportPayload(em.ctx, data, em.broadcastPayloadLimit, &batch)
 	if err != nil {
 		l.Errorf("Invalid batch downloaded from %s '%s': %s", ss.Name(), payloadRef, err)
 		return nil, nil
 	}
-	l.Infof("Shared storage batch downloaded from %s '%s' id=%s (len=%d)", ss.Name(), payloadRef, batch.ID, len(data))
+	batch.ProtocolVersion = protocolVersion
+	batch.PayloadRef = payloadRef
+	l.Infof("Shared storage batch downloaded from %s '%s' id=%s (len=%d,protocolVersion=%d)", ss.Name(), payloadRef, batch.ID, len(data), protocolVersion)
 
 	if batch.Namespace != ns {
 		l.Errorf("Invalid batch '%s'. Namespace in batch '%s' does not match pin namespace '%s'", batch.ID, batch.Namespace, ns)
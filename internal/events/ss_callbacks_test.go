@@ -38,6 +38,42 @@ func TestSharedStorageBatchDownloadedOk(t *testing.T) {
 	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
 	b, _ := json.Marshal(&batch)
 
+	mdi := em.database.(*databasemocks.Plugin)
+	mss := em.sharedstorage.(*sharedstoragemocks.Plugin)
+	mdi.On("UpsertBatch", em.ctx, mock.MatchedBy(func(bp *fftypes.BatchPersisted) bool {
+		return bp.PayloadRef == "payload1"
+	})).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mss.On("Name").Return("utdx").Maybe()
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+
+	bid, err := em.SharedStorageBatchDownloaded(mss, batch.Namespace, "payload1", b)
+	assert.NoError(t, err)
+	assert.Equal(t, batch.ID, bid)
+
+	assert.Equal(t, *batch.ID, <-em.aggregator.rewindBatches)
+
+	mdi.AssertExpectations(t)
+	mss.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+
+}
+
+func TestSharedStorageBatchDownloadedCompressedOk(t *testing.T) {
+
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypeBroadcast, fftypes.TransactionTypeBatchPin, fftypes.DataArray{data})
+	batch.ProtocolVersion = fftypes.ProtocolVersion2
+	bp, _ := batch.Confirmed()
+	batch.Hash = fftypes.HashString(bp.Manifest.String())
+	b, err := fftypes.SerializeTransportPayload(em.ctx, fftypes.ProtocolVersion2, &batch)
+	assert.NoError(t, err)
+
 	mdi := em.database.(*databasemocks.Plugin)
 	mss := em.sharedstorage.(*sharedstoragemocks.Plugin)
 	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
@@ -123,6 +159,8 @@ func TestSharedStorageBLOBDownloadedOk(t *testing.T) {
 	dataID := fftypes.NewUUID()
 	batchID := fftypes.NewUUID()
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("AlternateBlobs", em.ctx, mock.Anything).Return(nil, nil)
 	mdi := em.database.(*databasemocks.Plugin)
 	mss := em.sharedstorage.(*sharedstoragemocks.Plugin)
 	mss.On("Name").Return("utsd")
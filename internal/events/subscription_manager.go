@@ -20,6 +20,7 @@ import (
 	"context"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -28,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -39,11 +41,15 @@ type subscription struct {
 	definition *fftypes.Subscription
 
 	dispatcherElection chan bool
+	stats              *subscriptionStats
 	eventMatcher       *regexp.Regexp
 	messageFilter      *messageFilter
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
 	topicFilter        *regexp.Regexp
+	topicSetFilter     *topicSetFilter
+	routingRules       *routingRules
+	labelFilter        map[string]bool
 }
 
 type messageFilter struct {
@@ -74,6 +80,8 @@ type subscriptionManager struct {
 	database                  database.Plugin
 	data                      data.Manager
 	txHelper                  txcommon.Helper
+	metrics                   metrics.Manager
+	metricsInterval           time.Duration
 	eventNotifier             *eventNotifier
 	definitions               definitions.DefinitionHandlers
 	transports                map[string]events.Plugin
@@ -84,11 +92,13 @@ type subscriptionManager struct {
 	cancelCtx                 func()
 	newOrUpdatedSubscriptions chan *fftypes.UUID
 	deletedSubscriptions      chan *fftypes.UUID
+	routingRuleChanges        chan string
+	routingRules              *routingRules
 	cel                       *changeEventListener
 	retry                     retry.Retry
 }
 
-func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, sh definitions.DefinitionHandlers, txHelper txcommon.Helper) (*subscriptionManager, error) {
+func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, sh definitions.DefinitionHandlers, txHelper txcommon.Helper, mm metrics.Manager) (*subscriptionManager, error) {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	sm := &subscriptionManager{
 		ctx:                       ctx,
@@ -99,11 +109,14 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 		durableSubs:               make(map[fftypes.UUID]*subscription),
 		newOrUpdatedSubscriptions: make(chan *fftypes.UUID),
 		deletedSubscriptions:      make(chan *fftypes.UUID),
+		routingRuleChanges:        make(chan string),
 		maxSubs:                   uint64(config.GetUint(config.SubscriptionMax)),
 		cancelCtx:                 cancelCtx,
 		eventNotifier:             en,
 		definitions:               sh,
 		txHelper:                  txHelper,
+		metrics:                   mm,
+		metricsInterval:           config.GetDuration(config.SubscriptionMetricsInterval),
 		retry: retry.Retry{
 			InitialDelay: config.GetDuration(config.SubscriptionsRetryInitialDelay),
 			MaximumDelay: config.GetDuration(config.SubscriptionsRetryMaxDelay),
@@ -111,6 +124,7 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 		},
 	}
 	sm.cel = newChangeEventListener(ctx)
+	sm.routingRules = newRoutingRules(ctx, di, &sm.retry)
 
 	err := sm.loadTransports()
 	if err == nil {
@@ -157,6 +171,9 @@ func (sm *subscriptionManager) start() error {
 	if err != nil {
 		return err
 	}
+	if err := sm.routingRules.loadAll(); err != nil {
+		return err
+	}
 	sm.mux.Lock()
 	defer sm.mux.Unlock()
 	for _, subDef := range persistedSubs {
@@ -166,6 +183,7 @@ func (sm *subscriptionManager) start() error {
 			log.L(sm.ctx).Warnf("Failed to reload subscription %s:%s [%s]: %s", subDef.Namespace, subDef.Name, subDef.ID, err)
 			continue
 		}
+		newSub.stats = newSubscriptionStats(sm.metrics, subDef, sm.metricsInterval)
 		sm.durableSubs[*subDef.ID] = newSub
 		for _, conn := range sm.connections {
 			sm.matchSubToConnLocked(conn, newSub)
@@ -174,6 +192,9 @@ func (sm *subscriptionManager) start() error {
 	log.L(sm.ctx).Infof("Subscription manager started - loaded %d durable subscriptions", len(sm.durableSubs))
 	go sm.subscriptionEventListener()
 	go sm.cel.changeEventListener()
+	if sm.metrics.IsMetricsEnabled() {
+		go sm.subscriptionMetricsLoop()
+	}
 	return nil
 }
 
@@ -184,6 +205,8 @@ func (sm *subscriptionManager) subscriptionEventListener() {
 			go sm.newOrUpdatedDurableSubscription(id)
 		case id := <-sm.deletedSubscriptions:
 			go sm.deletedDurableSubscription(id)
+		case ns := <-sm.routingRuleChanges:
+			go sm.routingRules.reload(ns)
 		case <-sm.ctx.Done():
 			return
 		}
@@ -215,11 +238,14 @@ func (sm *subscriptionManager) newOrUpdatedDurableSubscription(id *fftypes.UUID)
 	// in-memory table, and creating any missing dispatchers
 	sm.mux.Lock()
 	defer sm.mux.Unlock()
+	newSub.stats = newSubscriptionStats(sm.metrics, subDef, sm.metricsInterval)
 	if existingSub, ok := sm.durableSubs[*subDef.ID]; ok {
 		if existingSub.definition.Updated.Equal(newSub.definition.Updated) {
 			log.L(sm.ctx).Infof("Subscription already active")
 			return
 		}
+		// Carry the delivery stats over to the updated definition
+		newSub.stats = existingSub.stats
 		// Need to close the old one
 		loaded, dispatchers := sm.closeDurabeSubscriptionLocked(subDef.ID)
 		if loaded {
@@ -257,9 +283,14 @@ func (sm *subscriptionManager) closeDurabeSubscriptionLocked(id *fftypes.UUID) (
 
 func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	sm.mux.Lock()
+	existingSub := sm.durableSubs[*id]
 	loaded, dispatchers := sm.closeDurabeSubscriptionLocked(id)
 	sm.mux.Unlock()
 
+	if loaded && sm.metrics.IsMetricsEnabled() {
+		sm.metrics.SubscriptionDeleted(existingSub.definition.Namespace, existingSub.definition.Name)
+	}
+
 	log.L(sm.ctx).Infof("Cleaning up subscription %s loaded=%t dispatchers=%d", id, loaded, len(dispatchers))
 
 	// Outside the lock, close out the active dispatchers
@@ -343,6 +374,11 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		}
 	}
 
+	topicSetFilter, err := newTopicSetFilter(ctx, "filter.topicset", filter.TopicSet)
+	if err != nil {
+		return nil, err
+	}
+
 	var authorFilter *regexp.Regexp
 	if filter.DeprecatedAuthor != "" {
 		log.L(ctx).Warnf("Your subscription filter uses the deprecated 'author' key - please change to 'message.author' instead")
@@ -365,6 +401,8 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		definition:         subDef,
 		eventMatcher:       eventFilter,
 		topicFilter:        topicFilter,
+		topicSetFilter:     topicSetFilter,
+		routingRules:       sm.routingRules,
 		messageFilter: &messageFilter{
 			tagFilter:    tagFilter,
 			groupFilter:  groupFilter,
@@ -372,6 +410,13 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		},
 	}
 
+	if len(filter.Labels) > 0 {
+		sub.labelFilter = make(map[string]bool)
+		for _, label := range filter.Labels {
+			sub.labelFilter[label] = true
+		}
+	}
+
 	if (filter.BlockchainEvent != fftypes.BlockchainEventFilter{}) {
 		var nameFilter *regexp.Regexp
 		if filter.BlockchainEvent.Name != "" {
@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -49,7 +50,9 @@ func newTestSubManager(t *testing.T, mei *eventsmocks.PluginAll) (*subscriptionM
 	mei.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil).Maybe()
 	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(&fftypes.Offset{RowID: 3333333, Current: 0}, nil).Maybe()
-	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), msh, txHelper)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false).Maybe()
+	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), msh, txHelper, mmi)
 	assert.NoError(t, err)
 	sm.transports = map[string]events.Plugin{
 		"ut": mei,
@@ -72,6 +75,7 @@ func TestRegisterDurableSubscriptions(t *testing.T) {
 	defer cancel()
 
 	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{
 		{SubscriptionRef: fftypes.SubscriptionRef{
 			ID: sub1,
@@ -118,6 +122,7 @@ func TestRegisterEphemeralSubscriptions(t *testing.T) {
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
 
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 	mei.On("ValidateOptions", mock.Anything).Return(nil)
 
@@ -147,6 +152,7 @@ func TestRegisterEphemeralSubscriptionsFail(t *testing.T) {
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
 
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 	mei.On("ValidateOptions", mock.Anything).Return(nil)
 	err := sm.start()
@@ -169,7 +175,7 @@ func TestSubManagerBadPlugin(t *testing.T) {
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	config.Reset()
 	config.Set(config.EventTransportsEnabled, []string{"!unknown!"})
-	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), nil, txHelper)
+	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), nil, txHelper, &metricsmocks.Manager{})
 	assert.Regexp(t, "FF10172", err)
 }
 
@@ -197,12 +203,25 @@ func TestStartSubRestoreFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestStartRoutingRulesFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := sm.start()
+	assert.EqualError(t, err, "pop")
+}
+
 func TestStartSubRestoreOkSubsFail(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
 
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{
 		{SubscriptionRef: fftypes.SubscriptionRef{
 			ID: fftypes.NewUUID(),
@@ -221,6 +240,7 @@ func TestStartSubRestoreOkSubsOK(t *testing.T) {
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
 
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{
 		{SubscriptionRef: fftypes.SubscriptionRef{
 			ID: fftypes.NewUUID(),
@@ -465,6 +485,57 @@ func TestCreateSubscriptionSuccessMessageFilter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateSubscriptionBadTopicSetFilter(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			TopicSet: &fftypes.TopicSetFilter{
+				Include: []string{"topic1", "topic**"},
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10421.*filter.topicset.include\\[1\\]", err)
+}
+
+func TestCreateSubscriptionSuccessTopicSetFilter(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			TopicSet: &fftypes.TopicSetFilter{
+				Include: []string{"orders.*"},
+				Exclude: []string{"orders.test*"},
+			},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.True(t, sub.topicSetFilter.matches("orders.123"))
+	assert.False(t, sub.topicSetFilter.matches("orders.test1"))
+}
+
+func TestCreateSubscriptionSuccessLabelFilter(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Labels: fftypes.FFStringArray{"finance", "orders"},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"finance": true, "orders": true}, sub.labelFilter)
+	assert.Equal(t, sm.routingRules, sub.routingRules)
+}
+
 func TestCreateSubscriptionSuccessTxFilter(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -521,6 +592,7 @@ func TestDispatchDeliveryResponseOK(t *testing.T) {
 	sm, cancel := newTestSubManager(t, mei)
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 	mei.On("ValidateOptions", mock.Anything).Return(nil)
 	err := sm.start()
@@ -551,6 +623,7 @@ func TestDispatchDeliveryResponseInvalidSubscription(t *testing.T) {
 	sm, cancel := newTestSubManager(t, mei)
 	defer cancel()
 	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
 	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
 	err := sm.start()
 	assert.NoError(t, err)
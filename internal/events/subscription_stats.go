@@ -0,0 +1,202 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// subscriptionStats tracks deliveries for a durable subscription, across every dispatcher
+// that is elected to deliver it while this node is running.
+// Ephemeral subscriptions have no stats, so all methods are safe to call on a nil receiver.
+type subscriptionStats struct {
+	mux          sync.Mutex
+	metrics      metrics.Manager
+	namespace    string
+	name         string
+	window       time.Duration
+	highest      int64
+	delivered    int64
+	redelivered  int64
+	lastDelivery *fftypes.FFTime
+	windowStart  time.Time
+	windowCount  int64
+	rate         float64
+}
+
+func newSubscriptionStats(mm metrics.Manager, subDef *fftypes.Subscription, window time.Duration) *subscriptionStats {
+	return &subscriptionStats{
+		metrics:     mm,
+		namespace:   subDef.Namespace,
+		name:        subDef.Name,
+		window:      window,
+		highest:     -1,
+		windowStart: time.Now(),
+	}
+}
+
+// dispatched records an event being passed to the transport. Any event at or below the highest
+// sequence already dispatched is a redelivery, due to a rejection or a change of connection.
+func (ss *subscriptionStats) dispatched(sequence int64) {
+	if ss == nil {
+		return
+	}
+	ss.mux.Lock()
+	redelivery := sequence <= ss.highest
+	if redelivery {
+		ss.redelivered++
+	} else {
+		ss.highest = sequence
+		ss.delivered++
+		ss.rollWindowLocked(time.Now())
+		ss.windowCount++
+	}
+	ss.lastDelivery = fftypes.Now()
+	ss.mux.Unlock()
+
+	if ss.metrics.IsMetricsEnabled() {
+		if redelivery {
+			ss.metrics.SubscriptionRedelivered(ss.namespace, ss.name)
+		} else {
+			ss.metrics.SubscriptionDelivered(ss.namespace, ss.name)
+		}
+	}
+}
+
+// rollWindowLocked calculates the delivery rate from the window that has just finished, if
+// it has run for long enough
+func (ss *subscriptionStats) rollWindowLocked(now time.Time) {
+	elapsed := now.Sub(ss.windowStart)
+	if elapsed >= ss.window {
+		ss.rate = float64(ss.windowCount) / elapsed.Seconds()
+		ss.windowStart = now
+		ss.windowCount = 0
+	}
+}
+
+func (ss *subscriptionStats) fillStatus(status *fftypes.SubscriptionStatus) {
+	if ss == nil {
+		return
+	}
+	ss.mux.Lock()
+	defer ss.mux.Unlock()
+	ss.rollWindowLocked(time.Now())
+	status.Delivered = ss.delivered
+	status.Redelivered = ss.redelivered
+	status.DeliveryRate = ss.rate
+	status.LastDelivery = ss.lastDelivery
+}
+
+func (sm *subscriptionManager) latestEventSequence(ctx context.Context, ns string) (int64, error) {
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	events, _, err := sm.database.GetEvents(ctx, fb.And(fb.Eq("namespace", ns)).Sort("sequence").Descending().Limit(1))
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	return events[0].Sequence, nil
+}
+
+// subscriptionStatus combines the committed offset from the database with the in-memory delivery
+// stats. The offset is read even when no dispatcher is active, as a consumer that has disconnected
+// is exactly the case that needs to show up as lag.
+func (sm *subscriptionManager) subscriptionStatus(ctx context.Context, subDef *fftypes.Subscription, latest int64) (*fftypes.SubscriptionStatus, error) {
+	status := &fftypes.SubscriptionStatus{
+		SubscriptionRef: subDef.SubscriptionRef,
+		LatestSequence:  latest,
+	}
+	offset, err := sm.database.GetOffset(ctx, fftypes.OffsetTypeSubscription, subDef.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	if offset != nil {
+		status.Offset = offset.Current
+	}
+	if latest > status.Offset {
+		status.Lag = latest - status.Offset
+	}
+
+	sm.mux.Lock()
+	sub := sm.durableSubs[*subDef.ID]
+	for _, conn := range sm.connections {
+		if _, ok := conn.dispatchers[*subDef.ID]; ok {
+			status.Connections++
+		}
+	}
+	sm.mux.Unlock()
+	if sub != nil {
+		sub.stats.fillStatus(status)
+	}
+	return status, nil
+}
+
+func (sm *subscriptionManager) getSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	latest, err := sm.latestEventSequence(ctx, subDef.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return sm.subscriptionStatus(ctx, subDef, latest)
+}
+
+func (sm *subscriptionManager) subscriptionMetricsLoop() {
+	ticker := time.NewTicker(sm.metricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sm.updateSubscriptionMetrics()
+		case <-sm.ctx.Done():
+			log.L(sm.ctx).Debugf("Subscription metrics loop exiting")
+			return
+		}
+	}
+}
+
+func (sm *subscriptionManager) updateSubscriptionMetrics() {
+	sm.mux.Lock()
+	subDefs := make([]*fftypes.Subscription, 0, len(sm.durableSubs))
+	for _, sub := range sm.durableSubs {
+		subDefs = append(subDefs, sub.definition)
+	}
+	sm.mux.Unlock()
+
+	// Only query the latest sequence once per namespace
+	latest := make(map[string]int64)
+	for _, subDef := range subDefs {
+		seq, ok := latest[subDef.Namespace]
+		if !ok {
+			var err error
+			if seq, err = sm.latestEventSequence(sm.ctx, subDef.Namespace); err != nil {
+				log.L(sm.ctx).Warnf("Failed to update subscription metrics: %s", err)
+				return
+			}
+			latest[subDef.Namespace] = seq
+		}
+		status, err := sm.subscriptionStatus(sm.ctx, subDef, seq)
+		if err != nil {
+			log.L(sm.ctx).Warnf("Failed to update subscription metrics: %s", err)
+			return
+		}
+		sm.metrics.SubscriptionStatus(status)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestSubStatusDef() *fftypes.Subscription {
+	return &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+}
+
+func TestSubscriptionStatsNil(t *testing.T) {
+	var ss *subscriptionStats
+	ss.dispatched(1)
+	status := &fftypes.SubscriptionStatus{}
+	ss.fillStatus(status)
+	assert.Zero(t, status.Delivered)
+}
+
+func TestSubscriptionStatsDispatched(t *testing.T) {
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDelivered", "ns1", "sub1").Return().Twice()
+	mmi.On("SubscriptionRedelivered", "ns1", "sub1").Return().Once()
+
+	ss := newSubscriptionStats(mmi, newTestSubStatusDef(), time.Minute)
+	ss.dispatched(10)
+	ss.dispatched(11)
+	ss.dispatched(10)
+
+	status := &fftypes.SubscriptionStatus{}
+	ss.fillStatus(status)
+	assert.Equal(t, int64(2), status.Delivered)
+	assert.Equal(t, int64(1), status.Redelivered)
+	assert.Equal(t, float64(0), status.DeliveryRate)
+	assert.NotNil(t, status.LastDelivery)
+
+	mmi.AssertExpectations(t)
+}
+
+func TestSubscriptionStatsRate(t *testing.T) {
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
+
+	ss := newSubscriptionStats(mmi, newTestSubStatusDef(), time.Second)
+	ss.windowStart = time.Now().Add(-2 * time.Second)
+	ss.windowCount = 10
+	ss.dispatched(1)
+
+	status := &fftypes.SubscriptionStatus{}
+	ss.fillStatus(status)
+	assert.Equal(t, int64(1), status.Delivered)
+	assert.InDelta(t, 5, status.DeliveryRate, 0.1)
+
+	// Once a full window has passed with no deliveries, the rate drops to zero
+	ss.windowStart = time.Now().Add(-2 * time.Second)
+	ss.windowCount = 0
+	ss.fillStatus(status)
+	assert.Equal(t, float64(0), status.DeliveryRate)
+}
+
+func TestGetSubscriptionStatusLagAndConnections(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	subDef := newTestSubStatusDef()
+	sub := &subscription{
+		definition: subDef,
+		stats:      newSubscriptionStats(sm.metrics, subDef, time.Minute),
+	}
+	sm.durableSubs[*subDef.ID] = sub
+	sm.connections["conn1"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subDef.ID: {},
+		},
+	}
+	sm.connections["conn2"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{},
+	}
+	sub.stats.delivered = 5
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 100}}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(&fftypes.Offset{Current: 60}, nil)
+
+	status, err := sm.getSubscriptionStatus(context.Background(), subDef)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", status.Name)
+	assert.Equal(t, int64(100), status.LatestSequence)
+	assert.Equal(t, int64(60), status.Offset)
+	assert.Equal(t, int64(40), status.Lag)
+	assert.Equal(t, int64(5), status.Delivered)
+	assert.Equal(t, 1, status.Connections)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusNoOffsetNoEvents(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	subDef := newTestSubStatusDef()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(nil, nil)
+
+	status, err := sm.getSubscriptionStatus(context.Background(), subDef)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Lag)
+	assert.Equal(t, 0, status.Connections)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusEventsFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := sm.getSubscriptionStatus(context.Background(), newTestSubStatusDef())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetSubscriptionStatusOffsetFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := sm.getSubscriptionStatus(context.Background(), newTestSubStatusDef())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestSubscriptionMetricsLoop(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	mmi := &metricsmocks.Manager{}
+	sm.database = mdi
+	sm.metrics = mmi
+	sm.metricsInterval = time.Millisecond
+	sub1 := newTestSubStatusDef()
+	sub2 := newTestSubStatusDef()
+	sub2.Name = "sub2"
+	sm.durableSubs[*sub1.ID] = &subscription{definition: sub1}
+	sm.durableSubs[*sub2.ID] = &subscription{definition: sub2}
+
+	updated := make(chan bool)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 10}}, nil, nil).Once()
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, mock.Anything).Return(&fftypes.Offset{Current: 5}, nil).Twice()
+	mmi.On("SubscriptionStatus", mock.MatchedBy(func(status *fftypes.SubscriptionStatus) bool {
+		return status.Lag == 5
+	})).Return().Twice()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+		close(updated)
+	}).Once()
+	// The ticker can fire again before the loop sees the context is cancelled
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Maybe()
+
+	go sm.subscriptionMetricsLoop()
+	<-updated
+
+	mdi.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestUpdateSubscriptionMetricsOffsetFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	subDef := newTestSubStatusDef()
+	sm.durableSubs[*subDef.ID] = &subscription{definition: subDef}
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	sm.updateSubscriptionMetrics()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStartSubscriptionMetricsEnabled(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	cancel()
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+	mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	mmi.On("IsMetricsEnabled").Return(true)
+
+	err := sm.start()
+	assert.NoError(t, err)
+}
+
+func TestDeletedDurableSubscriptionMetrics(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+	subDef := newTestSubStatusDef()
+	sm.durableSubs[*subDef.ID] = &subscription{definition: subDef}
+
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionDeleted", "ns1", "sub1").Return()
+	mdi.On("DeleteOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(nil)
+
+	sm.deletedDurableSubscription(subDef.ID)
+
+	mmi.AssertExpectations(t)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// ingestTransfer checks a transfer against the ingest filter of its pool. A transfer that does not pass
+// is not stored, but it still moves tokens between accounts, so the balances are updated before it is
+// dropped. Drops are only counted in metrics, as writing to the pool for each one would cost more than
+// storing the transfer.
+func (em *eventManager) ingestTransfer(ctx context.Context, pool *fftypes.TokenPool, transfer *fftypes.TokenTransfer) (bool, error) {
+	pass, err := em.passesIngestFilter(ctx, pool, transfer)
+	if err != nil || pass {
+		return pass, err
+	}
+
+	log.L(ctx).Debugf("Token transfer '%s' dropped by the ingest filter of pool '%s'", transfer.ProtocolID, pool.ID)
+	if err := em.database.UpdateTokenBalances(ctx, transfer); err != nil {
+		log.L(ctx).Errorf("Failed to update accounts %s -> %s for dropped token transfer '%s': %s", transfer.From, transfer.To, transfer.ProtocolID, err)
+		return false, err
+	}
+	if em.metrics.IsMetricsEnabled() {
+		em.metrics.TransferDropped(pool)
+	}
+	return false, nil
+}
+
+func (em *eventManager) passesIngestFilter(ctx context.Context, pool *fftypes.TokenPool, transfer *fftypes.TokenTransfer) (bool, error) {
+	filter := pool.IngestFilter
+	if filter.MinAmount != nil && transfer.Amount.Int().Cmp(filter.MinAmount.Int()) < 0 {
+		return false, nil
+	}
+	if filter.Members {
+		return em.involvesMember(ctx, pool.Namespace, transfer)
+	}
+	return true, nil
+}
+
+// involvesMember checks whether either side of a transfer is a key registered to an identity,
+// such as the key of a member org. Mints and burns only have one side.
+func (em *eventManager) involvesMember(ctx context.Context, ns string, transfer *fftypes.TokenTransfer) (bool, error) {
+	for _, key := range []string{transfer.From, transfer.To} {
+		if key == "" {
+			continue
+		}
+		identity, err := em.identity.FindIdentityForVerifier(ctx, []fftypes.IdentityType{
+			fftypes.IdentityTypeOrg,
+			fftypes.IdentityTypeCustom,
+		}, ns, &fftypes.VerifierRef{
+			Type:  em.verifierType,
+			Value: key,
+		})
+		if err != nil || identity != nil {
+			return identity != nil, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,201 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTokensTransferredDroppedByIngestFilter(t *testing.T) {
+	em, cancel := newTestEventManagerWithMetrics(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mmi := em.metrics.(*metricsmocks.Manager)
+
+	transfer := newTransfer()
+	transfer.TX = fftypes.TransactionRef{}
+	pool := &fftypes.TokenPool{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			MinAmount: fftypes.NewFFBigInt(10),
+		},
+	}
+
+	mdi.On("GetTokenTransferByProtocolID", em.ctx, "erc1155", "123").Return(nil, nil)
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(pool, nil)
+	mdi.On("UpdateTokenBalances", em.ctx, mock.MatchedBy(func(t *fftypes.TokenTransfer) bool {
+		return t.Pool.Equals(pool.ID) && t.Namespace == "ns1"
+	})).Return(nil)
+	mmi.On("TransferDropped", pool).Return()
+
+	valid, err := em.persistTokenTransfer(em.ctx, transfer)
+	assert.False(t, valid)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "UpsertTokenPool", mock.Anything, mock.Anything)
+	mdi.AssertNotCalled(t, "UpsertTokenTransfer", mock.Anything, mock.Anything)
+	mmi.AssertCalled(t, "TransferDropped", pool)
+	mmi.AssertNotCalled(t, "TransferConfirmed", mock.Anything)
+}
+
+func TestTokensTransferredIngestFilterSkippedForFireFlyTransfer(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace: "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			MinAmount: fftypes.NewFFBigInt(10),
+		},
+	}
+	transfer := newTransfer()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetTokenTransferByProtocolID", em.ctx, "erc1155", "123").Return(nil, nil)
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(pool, nil)
+	mdi.On("GetOperations", em.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	valid, err := em.persistTokenTransfer(em.ctx, transfer)
+	assert.False(t, valid)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestIngestTransferDropFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace: "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			MinAmount: fftypes.NewFFBigInt(10),
+		},
+	}
+	transfer := &fftypes.TokenTransfer{Amount: *fftypes.NewFFBigInt(1)}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("UpdateTokenBalances", em.ctx, transfer).Return(fmt.Errorf("pop"))
+
+	ingest, err := em.ingestTransfer(em.ctx, pool, transfer)
+	assert.False(t, ingest)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestIngestTransferAboveMinAmount(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace: "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{
+			MinAmount: fftypes.NewFFBigInt(10),
+		},
+	}
+	transfer := &fftypes.TokenTransfer{Amount: *fftypes.NewFFBigInt(10)}
+
+	ingest, err := em.ingestTransfer(em.ctx, pool, transfer)
+	assert.True(t, ingest)
+	assert.NoError(t, err)
+}
+
+func TestIngestTransferMemberRecipient(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace:    "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{Members: true},
+	}
+	transfer := &fftypes.TokenTransfer{From: "0x1", To: "0x2"}
+
+	mim := em.identity.(*identitymanagermocks.Manager)
+	identityTypes := []fftypes.IdentityType{fftypes.IdentityTypeOrg, fftypes.IdentityTypeCustom}
+	mim.On("FindIdentityForVerifier", em.ctx, identityTypes, "ns1", &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x1",
+	}).Return(nil, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, identityTypes, "ns1", &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x2",
+	}).Return(&fftypes.Identity{}, nil)
+
+	ingest, err := em.ingestTransfer(em.ctx, pool, transfer)
+	assert.True(t, ingest)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestIngestTransferMintToNonMember(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace:    "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{Members: true},
+	}
+	transfer := &fftypes.TokenTransfer{To: "0x2"}
+
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, "ns1", &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x2",
+	}).Return(nil, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("UpdateTokenBalances", em.ctx, transfer).Return(nil)
+
+	ingest, err := em.ingestTransfer(em.ctx, pool, transfer)
+	assert.False(t, ingest)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestIngestTransferMemberLookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	pool := &fftypes.TokenPool{
+		Namespace:    "ns1",
+		IngestFilter: &fftypes.TokenPoolIngestFilter{Members: true},
+	}
+	transfer := &fftypes.TokenTransfer{From: "0x1", To: "0x2"}
+
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, mock.Anything, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	ingest, err := em.ingestTransfer(em.ctx, pool, transfer)
+	assert.False(t, ingest)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
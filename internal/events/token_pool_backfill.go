@@ -0,0 +1,53 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+)
+
+func (em *eventManager) persistTokenPoolBackfillProgress(ctx context.Context, progress *tokens.TokenPoolBackfillProgress) error {
+	pool, err := em.database.GetTokenPoolByProtocolID(ctx, progress.Connector, progress.PoolProtocolID)
+	if err != nil {
+		return err
+	}
+	if pool == nil || pool.Backfill == nil {
+		log.L(ctx).Warnf("Backfill progress received for pool '%s', which is not being backfilled - ignoring", progress.PoolProtocolID)
+		return nil
+	}
+
+	pool.Backfill.State = fftypes.TokenPoolBackfillStateRunning
+	if progress.Complete {
+		pool.Backfill.State = fftypes.TokenPoolBackfillStateComplete
+	}
+	pool.Backfill.Block = progress.Block
+	pool.Backfill.Transfers = progress.Transfers
+	pool.Backfill.Updated = fftypes.Now()
+	log.L(ctx).Infof("Token pool backfill id=%s state=%s block=%s transfers=%d", pool.ID, pool.Backfill.State, pool.Backfill.Block, pool.Backfill.Transfers)
+	return em.database.UpsertTokenPool(ctx, pool)
+}
+
+func (em *eventManager) TokenPoolBackfillProgress(ti tokens.Plugin, progress *tokens.TokenPoolBackfillProgress) error {
+	return em.retry.Do(em.ctx, "persist token pool backfill progress", func(attempt int) (bool, error) {
+		err := em.persistTokenPoolBackfillProgress(em.ctx, progress)
+		return err != nil, err // retry indefinitely (until context closes)
+	})
+}
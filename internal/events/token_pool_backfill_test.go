@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/tokenmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTokenPoolBackfillProgressRunning(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mti := &tokenmocks.Plugin{}
+
+	pool := &fftypes.TokenPool{
+		ID: fftypes.NewUUID(),
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "100",
+			State:     fftypes.TokenPoolBackfillStatePending,
+		},
+	}
+	progress := &tokens.TokenPoolBackfillProgress{
+		PoolProtocolID: "F1",
+		Connector:      "erc1155",
+		Block:          "150",
+		Transfers:      12,
+	}
+
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(pool, nil).Once()
+	mdi.On("UpsertTokenPool", em.ctx, mock.MatchedBy(func(p *fftypes.TokenPool) bool {
+		return p.Backfill.State == fftypes.TokenPoolBackfillStateRunning &&
+			p.Backfill.Block == "150" &&
+			p.Backfill.Transfers == 12 &&
+			p.Backfill.Updated != nil
+	})).Return(nil)
+
+	err := em.TokenPoolBackfillProgress(mti, progress)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTokenPoolBackfillProgressComplete(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mti := &tokenmocks.Plugin{}
+
+	pool := &fftypes.TokenPool{
+		ID: fftypes.NewUUID(),
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "100",
+			State:     fftypes.TokenPoolBackfillStateRunning,
+		},
+	}
+	progress := &tokens.TokenPoolBackfillProgress{
+		PoolProtocolID: "F1",
+		Connector:      "erc1155",
+		Block:          "200",
+		Transfers:      20,
+		Complete:       true,
+	}
+
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(pool, nil)
+	mdi.On("UpsertTokenPool", em.ctx, mock.MatchedBy(func(p *fftypes.TokenPool) bool {
+		return p.Backfill.State == fftypes.TokenPoolBackfillStateComplete
+	})).Return(nil)
+
+	err := em.TokenPoolBackfillProgress(mti, progress)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTokenPoolBackfillProgressNotBackfilling(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mti := &tokenmocks.Plugin{}
+
+	progress := &tokens.TokenPoolBackfillProgress{
+		PoolProtocolID: "F1",
+		Connector:      "erc1155",
+	}
+
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "F1").Return(&fftypes.TokenPool{}, nil)
+
+	err := em.TokenPoolBackfillProgress(mti, progress)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
@@ -111,6 +111,10 @@ func (em *eventManager) shouldConfirm(ctx context.Context, pool *tokens.TokenPoo
 
 func (em *eventManager) shouldAnnounce(ctx context.Context, pool *tokens.TokenPool) (announcePool *fftypes.TokenPool, err error) {
 	op, err := em.findTXOperation(ctx, pool.TX.ID, fftypes.OpTypeTokenCreatePool)
+	if err == nil && op == nil {
+		// The pool might have been created along with a newly deployed token contract
+		op, err = em.findTXOperation(ctx, pool.TX.ID, fftypes.OpTypeTokenDeployPool)
+	}
 	if err != nil {
 		return nil, err
 	} else if op == nil {
@@ -127,9 +131,26 @@ func (em *eventManager) shouldAnnounce(ctx context.Context, pool *tokens.TokenPo
 		log.L(ctx).Errorf("Error processing pool for transaction '%s' (%s) - ignoring", pool.TX.ID, err)
 		return nil, nil
 	}
+	if op.Type == fftypes.OpTypeTokenDeployPool {
+		addDeployedAddress(announcePool, op)
+	}
+	announcePool.Deploy = nil
 	return announcePool, nil
 }
 
+// addDeployedAddress records the address of a deployed token contract in the pool info,
+// if the connector reported it in the receipt for the deployment but not on the pool itself
+func addDeployedAddress(pool *fftypes.TokenPool, op *fftypes.Operation) {
+	address := op.Output.GetString("address")
+	if address == "" || pool.Info.GetString("address") != "" {
+		return
+	}
+	if pool.Info == nil {
+		pool.Info = fftypes.JSONObject{}
+	}
+	pool.Info["address"] = address
+}
+
 // It is expected that this method might be invoked twice for each pool, depending on the behavior of the connector.
 // It will be at least invoked on the submitter when the pool is first created, to trigger the submitter to announce it.
 // It will be invoked on every node (including the submitter) after the pool is announced+activated, to trigger confirmation of the pool.
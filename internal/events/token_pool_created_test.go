@@ -544,6 +544,73 @@ func TestTokenPoolCreatedAnnounce(t *testing.T) {
 	mbm.AssertExpectations(t)
 }
 
+func TestTokenPoolCreatedAnnounceDeployed(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mti := &tokenmocks.Plugin{}
+	mbm := em.broadcast.(*broadcastmocks.Manager)
+
+	poolID := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	operations := []*fftypes.Operation{
+		{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.OpTypeTokenDeployPool,
+			Input: fftypes.JSONObject{
+				"id":        poolID.String(),
+				"namespace": "test-ns",
+				"name":      "my-pool",
+				"deploy": fftypes.JSONObject{
+					"params": []interface{}{"FFC"},
+				},
+			},
+			Output: fftypes.JSONObject{
+				"address": "0x12345",
+			},
+		},
+	}
+	pool := &tokens.TokenPool{
+		Type:       fftypes.TokenTypeFungible,
+		ProtocolID: "123",
+		TX: fftypes.TransactionRef{
+			ID:   txID,
+			Type: fftypes.TransactionTypeTokenPool,
+		},
+		Connector: "erc1155",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xffffeeee",
+			ProtocolID:     "tx1",
+		},
+	}
+
+	mdi.On("GetTokenPoolByProtocolID", em.ctx, "erc1155", "123").Return(nil, nil)
+	mdi.On("GetOperations", em.ctx, mock.Anything).Return([]*fftypes.Operation{}, nil, nil).Once()
+	mdi.On("GetOperations", em.ctx, mock.Anything).Return(operations, nil, nil).Once()
+	mbm.On("BroadcastTokenPool", em.ctx, "test-ns", mock.MatchedBy(func(pool *fftypes.TokenPoolAnnouncement) bool {
+		return *pool.Pool.ID == *poolID && pool.Pool.Deploy == nil && pool.Pool.Info.GetString("address") == "0x12345"
+	}), false).Return(nil, nil)
+
+	err := em.TokenPoolCreated(mti, pool)
+	assert.NoError(t, err)
+
+	mti.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestAddDeployedAddress(t *testing.T) {
+	pool := &fftypes.TokenPool{
+		Info: fftypes.JSONObject{"address": "0x23456"},
+	}
+	addDeployedAddress(pool, &fftypes.Operation{Output: fftypes.JSONObject{"address": "0x12345"}})
+	assert.Equal(t, "0x23456", pool.Info.GetString("address"))
+
+	pool = &fftypes.TokenPool{}
+	addDeployedAddress(pool, &fftypes.Operation{})
+	assert.Nil(t, pool.Info)
+}
+
 func TestTokenPoolCreatedAnnounceBadOpInputID(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
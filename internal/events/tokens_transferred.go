@@ -40,6 +40,7 @@ func (em *eventManager) loadTransferOperation(ctx context.Context, tx *fftypes.U
 		return err
 	}
 	if len(operations) > 0 {
+		transfer.BusinessKey = operations[0].BusinessKey
 		if origTransfer, err := txcommon.RetrieveTokenTransferInputs(ctx, operations[0]); err != nil {
 			log.L(ctx).Warnf("Failed to read operation inputs for token transfer '%s': %s", transfer.ProtocolID, err)
 		} else if origTransfer != nil {
@@ -76,6 +77,13 @@ func (em *eventManager) persistTokenTransfer(ctx context.Context, transfer *toke
 	transfer.Namespace = pool.Namespace
 	transfer.Pool = pool.ID
 
+	// Transfers submitted through FireFly are always recorded, so the ingest filter only applies to the others
+	if transfer.TX.ID == nil && pool.IngestFilter != nil {
+		if ingest, err := em.ingestTransfer(ctx, pool, &transfer.TokenTransfer); err != nil || !ingest {
+			return false, err
+		}
+	}
+
 	if transfer.TX.ID != nil {
 		if err := em.loadTransferOperation(ctx, transfer.TX.ID, &transfer.TokenTransfer); err != nil {
 			return false, err
@@ -279,6 +279,7 @@ func TestTokensTransferredWithTransactionRegenerateLocalID(t *testing.T) {
 		Input: fftypes.JSONObject{
 			"localId": localID.String(),
 		},
+		BusinessKey: "order-1",
 	}}
 
 	mdi.On("GetTokenTransferByProtocolID", em.ctx, "erc1155", "123").Return(nil, nil)
@@ -300,6 +301,7 @@ func TestTokensTransferredWithTransactionRegenerateLocalID(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.NotEqual(t, *localID, *transfer.LocalID)
+	assert.Equal(t, "order-1", transfer.BusinessKey)
 
 	mdi.AssertExpectations(t)
 	mti.AssertExpectations(t)
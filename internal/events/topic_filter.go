@@ -0,0 +1,139 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// topicSetFilter is the compiled form of a fftypes.TopicSetFilter
+type topicSetFilter struct {
+	include *topicIndex
+	exclude *topicIndex
+}
+
+// topicIndex holds exact topics in a map, and indexes wildcard patterns by the literal prefix
+// before their first '*'. So matching a topic costs a map lookup for each of its prefixes (up to
+// the longest indexed prefix), and only the patterns sharing a prefix need to be evaluated.
+type topicIndex struct {
+	exact     map[string]bool
+	wildcards map[string][]string
+	maxPrefix int
+}
+
+func newTopicSetFilter(ctx context.Context, fieldName string, filter *fftypes.TopicSetFilter) (tf *topicSetFilter, err error) {
+	if filter == nil || (len(filter.Include) == 0 && len(filter.Exclude) == 0) {
+		return nil, nil
+	}
+	tf = &topicSetFilter{}
+	if tf.include, err = newTopicIndex(ctx, fieldName+".include", filter.Include); err != nil {
+		return nil, err
+	}
+	if tf.exclude, err = newTopicIndex(ctx, fieldName+".exclude", filter.Exclude); err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+func validateTopicPattern(ctx context.Context, fieldName, pattern string) error {
+	// A wildcard stands in for at least one character of a valid topic name
+	if strings.Contains(pattern, "**") || fftypes.ValidateFFNameField(ctx, strings.ReplaceAll(pattern, "*", "x"), fieldName) != nil {
+		return i18n.NewError(ctx, i18n.MsgInvalidTopicPattern, pattern, fieldName)
+	}
+	return nil
+}
+
+func newTopicIndex(ctx context.Context, fieldName string, patterns []string) (*topicIndex, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	ti := &topicIndex{
+		exact:     make(map[string]bool),
+		wildcards: make(map[string][]string),
+	}
+	for i, pattern := range patterns {
+		if err := validateTopicPattern(ctx, fmt.Sprintf("%s[%d]", fieldName, i), pattern); err != nil {
+			return nil, err
+		}
+		star := strings.IndexByte(pattern, '*')
+		if star < 0 {
+			ti.exact[pattern] = true
+			continue
+		}
+		prefix := pattern[0:star]
+		ti.wildcards[prefix] = append(ti.wildcards[prefix], pattern)
+		if star > ti.maxPrefix {
+			ti.maxPrefix = star
+		}
+	}
+	return ti, nil
+}
+
+func (ti *topicIndex) matches(topic string) bool {
+	if ti.exact[topic] {
+		return true
+	}
+	if len(ti.wildcards) == 0 {
+		return false
+	}
+	for i := 0; i <= len(topic) && i <= ti.maxPrefix; i++ {
+		for _, pattern := range ti.wildcards[topic[0:i]] {
+			if wildcardMatch(pattern[i:], topic[i:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches a string against a pattern, where '*' matches any sequence of characters
+func wildcardMatch(pattern, s string) bool {
+	p, t := 0, 0
+	starP, starT := -1, 0
+	for t < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starT = p, t
+			p++
+		case p < len(pattern) && pattern[p] == s[t]:
+			p++
+			t++
+		case starP >= 0:
+			// Backtrack, and let the last wildcard consume one more character
+			starT++
+			p, t = starP+1, starT
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func (tf *topicSetFilter) matches(topic string) bool {
+	if tf.include != nil && !tf.include.matches(topic) {
+		return false
+	}
+	return tf.exclude == nil || !tf.exclude.matches(topic)
+}
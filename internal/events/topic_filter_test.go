@@ -0,0 +1,125 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicSetFilterEmpty(t *testing.T) {
+	tf, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{})
+	assert.NoError(t, err)
+	assert.Nil(t, tf)
+	tf, err = newTopicSetFilter(context.Background(), "filter.topicset", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, tf)
+}
+
+func TestTopicSetFilterMatching(t *testing.T) {
+	tf, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Include: []string{"topic1", "orders.*", "*.audit", "a*b*c", "*"},
+		Exclude: []string{"orders.test*", "secret"},
+	})
+	assert.NoError(t, err)
+
+	for topic, expected := range map[string]bool{
+		"topic1":        true,
+		"anything":      true,
+		"orders.123":    true,
+		"orders.test":   false,
+		"orders.test.1": false,
+		"secret":        false,
+		"secrets":       true,
+	} {
+		assert.Equal(t, expected, tf.matches(topic), topic)
+	}
+}
+
+func TestTopicSetFilterIncludeOnly(t *testing.T) {
+	tf, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Include: []string{"topic1", "orders.*", "*.audit", "a*b*c", "ab*bc"},
+	})
+	assert.NoError(t, err)
+
+	for topic, expected := range map[string]bool{
+		"topic1":      true,
+		"topic2":      false,
+		"orders.1":    true,
+		"orders":      false,
+		"x.audit":     true,
+		"x.audit.y":   false,
+		"abc":         true,
+		"ac":          false,
+		"axbxc":       true,
+		"axxbxxbxxc":  true,
+		"axbxcx":      false,
+		"abbc":        true,
+		"":            false,
+		"unrelated.a": false,
+	} {
+		assert.Equal(t, expected, tf.matches(topic), topic)
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	assert.True(t, wildcardMatch("a*c", "abc"))
+	assert.True(t, wildcardMatch("a**", "a"))
+	assert.False(t, wildcardMatch("ab", "ac"))
+	assert.False(t, wildcardMatch("a*c", "abd"))
+}
+
+func TestTopicSetFilterExactOnly(t *testing.T) {
+	tf, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Exclude: []string{"topic1"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, tf.matches("topic1"))
+	assert.True(t, tf.matches("topic2"))
+}
+
+func TestTopicSetFilterLargeSet(t *testing.T) {
+	include := make([]string, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		include = append(include, fmt.Sprintf("topic%d", i))
+		include = append(include, fmt.Sprintf("prefix%d.*", i))
+	}
+	tf, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Include: include,
+	})
+	assert.NoError(t, err)
+	assert.True(t, tf.matches("topic9999"))
+	assert.True(t, tf.matches("prefix5000.abc"))
+	assert.False(t, tf.matches("prefix10000.abc"))
+}
+
+func TestTopicSetFilterInvalid(t *testing.T) {
+	for _, pattern := range []string{"", "**", "a**", "-a", "a*-", "bad!"} {
+		_, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+			Include: []string{pattern},
+		})
+		assert.Regexp(t, "FF10421.*filter.topicset.include", err, pattern)
+	}
+	_, err := newTopicSetFilter(context.Background(), "filter.topicset", &fftypes.TopicSetFilter{
+		Exclude: []string{"a**"},
+	})
+	assert.Regexp(t, "FF10421.*filter.topicset.exclude", err)
+}
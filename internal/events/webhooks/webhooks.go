@@ -19,13 +19,18 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
@@ -44,13 +49,17 @@ type WebHooks struct {
 	connID       string
 }
 
+const defaultSignatureHeader = "X-FireFly-Signature"
+
 type whRequest struct {
-	r         *resty.Request
-	url       string
-	method    string
-	body      fftypes.JSONObject
-	forceJSON bool
-	replyTx   string
+	r               *resty.Request
+	url             string
+	method          string
+	body            fftypes.JSONObject
+	forceJSON       bool
+	replyTx         string
+	signingSecrets  []string
+	signatureHeader string
 }
 
 type whResponse struct {
@@ -108,6 +117,24 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 				"type": "string",
 				"description": "%s"
 			},
+			"replygroup": {
+				"type": "string",
+				"description": "%s"
+			},
+			"replymembers": {
+				"type": "array",
+				"description": "%s",
+				"items": {
+					"type": "string"
+				}
+			},
+			"replytopics": {
+				"type": "array",
+				"description": "%s",
+				"items": {
+					"type": "string"
+				}
+			},
 			"headers": {
 				"type": "object",
 				"description": "%s",
@@ -122,6 +149,27 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 					"type": "string"
 				}
 			},
+			"signing": {
+				"type": "object",
+				"description": "%s",
+				"properties": {
+					"secret": {
+						"type": "string",
+						"description": "%s"
+					},
+					"previousSecrets": {
+						"type": "array",
+						"description": "%s",
+						"items": {
+							"type": "string"
+						}
+					},
+					"header": {
+						"type": "string",
+						"description": "%s"
+					}
+				}
+			},
 			"input": {
 				"type": "object",
 				"description": "%s",
@@ -157,8 +205,15 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 		i18n.Expand(ctx, i18n.MsgWebhooksOptReply),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyTag),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyTx),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyGroup),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyMembers),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyTopics),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptHeaders),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptQuery),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigning),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigningSecret),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigningPrevious),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigningHeader),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInput),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInputQuery),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInputHeaders),
@@ -202,6 +257,9 @@ func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.J
 		}
 		_ = req.r.SetQueryParam(q, s)
 	}
+	if err := wh.buildSigning(req, options.GetObject("signing")); err != nil {
+		return nil, err
+	}
 	if firstData != nil {
 		// Options on how to process the input
 		input := options.GetObject("input")
@@ -252,11 +310,86 @@ func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.J
 	return req, err
 }
 
+func (wh *WebHooks) buildSigning(req *whRequest, signing fftypes.JSONObject) error {
+	if len(signing) == 0 {
+		return nil
+	}
+	secret := signing.GetString("secret")
+	if secret == "" {
+		return i18n.NewError(wh.ctx, i18n.MsgWebhookSigningSecretEmpty)
+	}
+	req.signingSecrets = []string{secret}
+	if previous, ok := signing["previousSecrets"]; ok {
+		previousSecrets, ok := previous.([]interface{})
+		if !ok {
+			return i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidStringArray, "signing.previousSecrets")
+		}
+		for _, p := range previousSecrets {
+			s, ok := p.(string)
+			if !ok || s == "" {
+				return i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidStringArray, "signing.previousSecrets")
+			}
+			req.signingSecrets = append(req.signingSecrets, s)
+		}
+	}
+	req.signatureHeader = signing.GetString("header")
+	if req.signatureHeader == "" {
+		req.signatureHeader = defaultSignatureHeader
+	}
+	return nil
+}
+
+// sign sets a header containing the current timestamp, and an HMAC-SHA256 signature of "<timestamp>.<body>"
+// for each secret - so the receiver can authenticate the delivery, reject old deliveries to prevent replay,
+// and rotate secrets without failing deliveries. For example:
+// X-FireFly-Signature: t=1651234567,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+func (req *whRequest) sign(body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	parts := []string{"t=" + timestamp}
+	for _, secret := range req.signingSecrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		parts = append(parts, "v1="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	req.r.SetHeader(req.signatureHeader, strings.Join(parts, ","))
+}
+
+// RedactOptions removes the signing secrets from the options, so they are not returned through the API.
+// A subscription that is updated must supply its signing secrets again.
+func (wh *WebHooks) RedactOptions(options fftypes.JSONObject) {
+	if signing, ok := options.GetObjectOk("signing"); ok {
+		delete(signing, "secret")
+		delete(signing, "previousSecrets")
+	}
+}
+
 func (wh *WebHooks) ValidateOptions(options *fftypes.SubscriptionOptions) error {
 	if options.WithData == nil {
 		defaultTrue := true
 		options.WithData = &defaultTrue
 	}
+	if err := options.Redelivery.Validate(wh.ctx); err != nil {
+		return err
+	}
+	switch options.OrderedBy {
+	case "", fftypes.SubOptsOrderedByTopic, fftypes.SubOptsOrderedByGroup:
+	default:
+		return i18n.NewError(wh.ctx, i18n.MsgInvalidOrderedBy, fmt.Sprintf("unknown key '%s'", options.OrderedBy))
+	}
+	if options.OrderedBy != "" && options.TransportOptions().GetBool("fastack") {
+		// In fastack mode events are acknowledged before they are delivered, so the order cannot be kept
+		return i18n.NewError(wh.ctx, i18n.MsgInvalidOrderedBy, "cannot be combined with fastack")
+	}
+	transportOptions := options.TransportOptions()
+	if group := transportOptions.GetString("replygroup"); group != "" {
+		if _, err := fftypes.ParseBytes32(wh.ctx, group); err != nil {
+			return err
+		}
+		if len(transportOptions.GetStringArray("replymembers")) > 0 {
+			return i18n.NewError(wh.ctx, i18n.MsgReplyGroupAndMembers)
+		}
+	}
 	_, err := wh.buildRequest(options.TransportOptions(), fftypes.JSONObject{})
 	return err
 }
@@ -291,22 +424,35 @@ func (wh *WebHooks) attemptRequest(sub *fftypes.Subscription, event *fftypes.Eve
 		return nil, nil, err
 	}
 
+	var body interface{}
 	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
 		switch {
 		case !withData:
 			// We are just sending the event itself
-			req.r.SetBody(event)
+			body = event
 		case req.body != nil:
 			// We might have been told to extract a body from the first data record
-			req.r.SetBody(req.body)
+			body = req.body
 		case len(allData) > 1:
 			// We've got an array of data to POST
-			req.r.SetBody(allData)
+			body = allData
 		default:
 			// Otherwise just send the first object directly
-			req.r.SetBody(firstData)
+			body = firstData
 		}
 	}
+	if len(req.signingSecrets) > 0 {
+		// We serialize the body ourselves, so the signature covers exactly the bytes that are sent
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+			body = b
+		}
+		req.sign(b)
+	}
+	if body != nil {
+		req.r.SetBody(body)
+	}
 
 	resp, err := req.r.Execute(req.method, req.url)
 	if err != nil {
@@ -380,6 +526,7 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *fftypes.Subscript
 			ID:           event.ID,
 			Rejected:     false,
 			Subscription: event.Subscription,
+			ReplyTo:      wh.replyRouting(sub.Options.TransportOptions()),
 			Reply: &fftypes.MessageInOut{
 				Message: fftypes.Message{
 					Header: fftypes.MessageHeader{
@@ -400,6 +547,26 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *fftypes.Subscript
 	return nil
 }
 
+// replyRouting builds the routing for the reply from the subscription options, or returns nil to send the reply to the
+// group of the event (or to broadcast it, if the event was a broadcast)
+func (wh *WebHooks) replyRouting(options fftypes.JSONObject) *fftypes.ReplyRouting {
+	replyTo := &fftypes.ReplyRouting{}
+	if topics := options.GetStringArray("replytopics"); len(topics) > 0 {
+		replyTo.Topics = topics
+	}
+	if group := options.GetString("replygroup"); group != "" {
+		// An invalid group hash is rejected when the subscription is created
+		replyTo.Group, _ = fftypes.ParseBytes32(wh.ctx, group)
+	}
+	for _, member := range options.GetStringArray("replymembers") {
+		replyTo.Members = append(replyTo.Members, fftypes.MemberInput{Identity: member})
+	}
+	if replyTo.Group == nil && len(replyTo.Members) == 0 && len(replyTo.Topics) == 0 {
+		return nil
+	}
+	return replyTo
+}
+
 func (wh *WebHooks) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
 	if event.Message == nil && sub.Options.WithData != nil && *sub.Options.WithData {
 		log.L(wh.ctx).Debugf("Webhook withData=true subscription called with non-message event '%s'", event.ID)
@@ -18,11 +18,18 @@ package webhooks
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/firefly/internal/config"
@@ -79,6 +86,43 @@ func TestValidateOptionsWithDataDefaulTrue(t *testing.T) {
 	assert.True(t, *opts.WithData)
 }
 
+func TestValidateOptionsBadRedelivery(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			Redelivery: &fftypes.SubOptsRedelivery{Policy: fftypes.SubOptsRedeliveryFixed},
+		},
+	}
+	opts.TransportOptions()["url"] = "/anything"
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestValidateOptionsOrderedBy(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			OrderedBy: fftypes.SubOptsOrderedByGroup,
+		},
+	}
+	opts.TransportOptions()["url"] = "/anything"
+	err := wh.ValidateOptions(opts)
+	assert.NoError(t, err)
+
+	opts.OrderedBy = "tag"
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10526.*tag", err)
+
+	opts.OrderedBy = fftypes.SubOptsOrderedByTopic
+	opts.TransportOptions()["fastack"] = true
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10526.*fastack", err)
+}
+
 func TestValidateOptionsBadURL(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -117,6 +161,195 @@ func TestValidateOptionsBadQuery(t *testing.T) {
 	assert.Regexp(t, "FF10243.*query", err)
 }
 
+func TestValidateOptionsReplyGroup(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["replygroup"] = "!hash"
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10232", err)
+
+	opts.TransportOptions()["replygroup"] = fftypes.NewRandB32().String()
+	opts.TransportOptions()["replymembers"] = []interface{}{"org1"}
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10554", err)
+
+	delete(opts.TransportOptions(), "replymembers")
+	err = wh.ValidateOptions(opts)
+	assert.NoError(t, err)
+}
+
+func TestValidateOptionsSigningNoSecret(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{
+		"header": "X-Signature",
+	}
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10401", err)
+}
+
+func TestValidateOptionsSigningBadPreviousSecrets(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{
+		"secret":          "secret1",
+		"previousSecrets": "secret0",
+	}
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10402", err)
+
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{
+		"secret":          "secret1",
+		"previousSecrets": []interface{}{""},
+	}
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10402", err)
+}
+
+func TestRedactOptions(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := fftypes.JSONObject{
+		"url": "/anything",
+		"signing": fftypes.JSONObject{
+			"secret":          "secret1",
+			"previousSecrets": []interface{}{"secret0"},
+			"header":          "X-Signature",
+		},
+	}
+	wh.RedactOptions(opts)
+	assert.Equal(t, fftypes.JSONObject{
+		"url": "/anything",
+		"signing": fftypes.JSONObject{
+			"header": "X-Signature",
+		},
+	}, opts)
+
+	// Options without signing are unchanged
+	opts = fftypes.JSONObject{"url": "/anything"}
+	wh.RedactOptions(opts)
+	assert.Equal(t, fftypes.JSONObject{"url": "/anything"}, opts)
+}
+
+func checkSignature(t *testing.T, header string, body []byte, secrets ...string) {
+	parts := strings.Split(header, ",")
+	assert.Len(t, parts, len(secrets)+1)
+	assert.True(t, strings.HasPrefix(parts[0], "t="))
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), timestamp, 60)
+	for i, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+		mac.Write(body)
+		assert.Equal(t, "v1="+hex.EncodeToString(mac.Sum(nil)), parts[i+1])
+	}
+}
+
+func TestRequestSignedWithRotation(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"inputfield":"inputvalue"}`, string(body))
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		checkSignature(t, req.Header.Get("X-FireFly-Signature"), body, "secret2", "secret1")
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &fftypes.Subscription{
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["signing"] = fftypes.JSONObject{
+		"secret":          "secret2",
+		"previousSecrets": []interface{}{"secret1"},
+	}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+	}
+	data := &fftypes.Data{
+		ID:    fftypes.NewUUID(),
+		Value: fftypes.JSONAnyPtr(`{"inputfield": "inputvalue"}`),
+	}
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestRequestSignedNoBodyCustomHeader(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		checkSignature(t, req.Header.Get("X-Signature"), []byte{}, "secret1")
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["method"] = http.MethodGet
+	to["signing"] = fftypes.JSONObject{
+		"secret": "secret1",
+		"header": "X-Signature",
+	}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+	}
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
 func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -446,6 +679,66 @@ func TestRequestReplyEmptyData(t *testing.T) {
 	assert.True(t, called)
 }
 
+func TestRequestReplyRouting(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(200)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &fftypes.Subscription{
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["reply"] = true
+	to["replymembers"] = []interface{}{"org1", "org2"}
+	to["replytopics"] = []interface{}{"replies"}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID:   fftypes.NewUUID(),
+					Type: fftypes.MessageTypeBroadcast,
+				},
+			},
+		},
+	}
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return assert.Equal(t, &fftypes.ReplyRouting{
+			Members: []fftypes.MemberInput{{Identity: "org1"}, {Identity: "org2"}},
+			Topics:  fftypes.FFStringArray{"replies"},
+		}, response.ReplyTo)
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{})
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+}
+
+func TestReplyRoutingGroup(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	group := fftypes.NewRandB32()
+	assert.Equal(t, &fftypes.ReplyRouting{Group: group}, wh.replyRouting(fftypes.JSONObject{"replygroup": group.String()}))
+	assert.Nil(t, wh.replyRouting(fftypes.JSONObject{"replytag": "mytag"}))
+}
+
 func TestRequestReplyBadJSON(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
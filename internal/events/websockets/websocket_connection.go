@@ -34,6 +34,7 @@ type websocketStartedSub struct {
 	ephemeral bool
 	name      string
 	namespace string
+	group     string
 }
 
 type websocketConnection struct {
@@ -51,21 +52,26 @@ type websocketConnection struct {
 	mux                sync.Mutex
 	closed             bool
 	changeEventMatcher *regexp.Regexp
+	changeEventFilter  *fftypes.ChangeEventFilter
+	// authorized is set when the API server restricts the namespaces the caller can read
+	authorized func(ns string) bool
 }
 
-func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn) *websocketConnection {
+func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn, authorized func(ns string) bool, changeEventFilter *fftypes.ChangeEventFilter) *websocketConnection {
 	connID := fftypes.NewUUID().String()
 	ctx := log.WithLogField(pCtx, "websocket", connID)
 	ctx, cancelCtx := context.WithCancel(ctx)
 	wc := &websocketConnection{
-		ctx:          ctx,
-		ws:           ws,
-		wsConn:       wsConn,
-		cancelCtx:    cancelCtx,
-		connID:       connID,
-		sendMessages: make(chan interface{}),
-		senderDone:   make(chan struct{}),
-		receiverDone: make(chan struct{}),
+		ctx:               ctx,
+		ws:                ws,
+		wsConn:            wsConn,
+		cancelCtx:         cancelCtx,
+		connID:            connID,
+		sendMessages:      make(chan interface{}),
+		senderDone:        make(chan struct{}),
+		receiverDone:      make(chan struct{}),
+		authorized:        authorized,
+		changeEventFilter: changeEventFilter,
 	}
 	go wc.sendLoop()
 	go wc.receiveLoop()
@@ -89,6 +95,7 @@ func (wc *websocketConnection) processAutoStart(req *http.Request) {
 			Name:         query.Get("name"),
 			Filter:       filter,
 			ChangeEvents: query.Get("changeevents"),
+			Group:        query.Get("group"),
 		})
 		if err != nil {
 			wc.protocolError(err)
@@ -170,7 +177,14 @@ func (wc *websocketConnection) receiveLoop() {
 }
 
 func (wc *websocketConnection) dispatchChangeEvent(ce *fftypes.ChangeEvent) error {
-	if wc.changeEventMatcher == nil || !wc.changeEventMatcher.MatchString(ce.Collection) {
+	if wc.changeEventFilter != nil {
+		if !wc.changeEventFilter.Matches(ce) {
+			return nil
+		}
+	} else if wc.changeEventMatcher == nil || !wc.changeEventMatcher.MatchString(ce.Collection) {
+		return nil
+	}
+	if wc.authorized != nil && !wc.authorized(ce.Namespace) {
 		return nil
 	}
 	// Change events do *NOT* require an ack
@@ -188,12 +202,19 @@ func (wc *websocketConnection) dispatch(event *fftypes.EventDelivery) error {
 		Subscription: event.Subscription,
 	}
 
+	if wc.changeEventFilter != nil {
+		// The connection only delivers change events, so the events of its subscription are discarded
+		wc.ws.ack(wc.connID, inflight)
+		return nil
+	}
+
 	var autoAck bool
 	wc.mux.Lock()
 	autoAck = wc.autoAck
 	if !autoAck {
 		wc.inflight = append(wc.inflight, inflight)
 	}
+	ackConnID := wc.ackConnIDLocked(inflight.Subscription)
 	wc.mux.Unlock()
 
 	err := wc.send(event)
@@ -202,7 +223,7 @@ func (wc *websocketConnection) dispatch(event *fftypes.EventDelivery) error {
 	}
 
 	if autoAck {
-		wc.ws.ack(wc.connID, inflight)
+		wc.ws.ack(ackConnID, inflight)
 	}
 
 	return nil
@@ -232,6 +253,10 @@ func (wc *websocketConnection) send(msg interface{}) error {
 }
 
 func (wc *websocketConnection) handleStart(start *fftypes.WSClientActionStartPayload) (err error) {
+	if wc.authorized != nil && !wc.authorized(start.Namespace) {
+		return i18n.NewError(wc.ctx, i18n.MsgNamespaceUnauthorized, start.Namespace)
+	}
+
 	wc.mux.Lock()
 	if start.AutoAck != nil {
 		if *start.AutoAck != wc.autoAck && len(wc.started) > 0 {
@@ -255,6 +280,7 @@ func (wc *websocketConnection) handleStart(start *fftypes.WSClientActionStartPay
 		ephemeral: start.Ephemeral,
 		namespace: start.Namespace,
 		name:      start.Name,
+		group:     start.Group,
 	})
 	wc.mux.Unlock()
 	err = wc.ws.start(wc, start)
@@ -268,21 +294,32 @@ func (wc *websocketConnection) durableSubMatcher(sr fftypes.SubscriptionRef) boo
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
 	for _, startedSub := range wc.started {
-		if !startedSub.ephemeral && startedSub.namespace == sr.Namespace && startedSub.name == sr.Name {
+		if !startedSub.ephemeral && startedSub.group == "" && startedSub.namespace == sr.Namespace && startedSub.name == sr.Name {
 			return true
 		}
 	}
 	return false
 }
 
-func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (*fftypes.EventDeliveryResponse, error) {
+// ackConnIDLocked returns the connection ID acks must be delivered to for a subscription, which is the
+// group rather than this connection if the subscription was started as a member of a group
+func (wc *websocketConnection) ackConnIDLocked(sr fftypes.SubscriptionRef) string {
+	for _, startedSub := range wc.started {
+		if startedSub.group != "" && startedSub.namespace == sr.Namespace && startedSub.name == sr.Name {
+			return groupConnID(startedSub.namespace, startedSub.name, startedSub.group)
+		}
+	}
+	return wc.connID
+}
+
+func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (string, *fftypes.EventDeliveryResponse, error) {
 	l := log.L(wc.ctx)
 	var inflight *fftypes.EventDeliveryResponse
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
 
 	if wc.autoAck {
-		return nil, i18n.NewError(wc.ctx, i18n.MsgWSAutoAckEnabled)
+		return "", nil, i18n.NewError(wc.ctx, i18n.MsgWSAutoAckEnabled)
 	}
 
 	if ack.ID != nil {
@@ -300,7 +337,7 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 					// If there's more than one started subscription, that's a problem
 					if len(wc.started) != 1 {
 						l.Errorf("No subscription specified on ack, and there is not exactly one started subscription")
-						return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
+						return "", nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
 					}
 					match = true
 				}
@@ -323,36 +360,46 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 		}
 	}
 	if inflight == nil {
-		return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
+		return "", nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
 	}
-	return inflight, nil
+	inflight.Rejected = ack.Rejected
+	inflight.Info = ack.Info
+	inflight.Reply = ack.Reply
+	inflight.ReplyTo = ack.ReplyTo
+	return wc.ackConnIDLocked(inflight.Subscription), inflight, nil
 }
 
 func (wc *websocketConnection) handleAck(ack *fftypes.WSClientActionAckPayload) error {
 	// Perform a locked set of check
-	inflight, err := wc.checkAck(ack)
+	ackConnID, inflight, err := wc.checkAck(ack)
 	if err != nil {
 		return err
 	}
 
 	// Deliver the ack to the core, now we're unlocked
-	wc.ws.ack(wc.connID, inflight)
+	wc.ws.ack(ackConnID, inflight)
 	return nil
 }
 
 func (wc *websocketConnection) close() {
 	var didClosed bool
+	orphaned := make(map[string][]*fftypes.EventDeliveryResponse)
 	wc.mux.Lock()
 	if !wc.closed {
 		didClosed = true
 		wc.closed = true
 		_ = wc.wsConn.Close()
 		wc.cancelCtx()
+		for _, inflight := range wc.inflight {
+			if ackConnID := wc.ackConnIDLocked(inflight.Subscription); ackConnID != wc.connID {
+				orphaned[ackConnID] = append(orphaned[ackConnID], inflight)
+			}
+		}
 	}
 	wc.mux.Unlock()
 	// Drop lock before callback
 	if didClosed {
-		wc.ws.connClosed(wc.connID)
+		wc.ws.connClosed(wc.connID, orphaned)
 	}
 }
 
@@ -18,6 +18,7 @@ package websockets
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
 
@@ -34,6 +35,7 @@ type WebSockets struct {
 	capabilities *events.Capabilities
 	callbacks    events.Callbacks
 	connections  map[string]*websocketConnection
+	groups       map[string]*websocketGroup
 	connMux      sync.Mutex
 	upgrader     websocket.Upgrader
 }
@@ -44,6 +46,7 @@ func (ws *WebSockets) Init(ctx context.Context, prefix config.Prefix, callbacks
 	*ws = WebSockets{
 		ctx:         ctx,
 		connections: make(map[string]*websocketConnection),
+		groups:      make(map[string]*websocketGroup),
 		capabilities: &events.Capabilities{
 			ChangeEvents: true,
 		},
@@ -75,12 +78,19 @@ func (ws *WebSockets) ValidateOptions(options *fftypes.SubscriptionOptions) erro
 	}
 	forceFalse := false
 	options.WithData = &forceFalse
-	return nil
+	if options.OrderedBy != "" {
+		return i18n.NewError(ws.ctx, i18n.MsgInvalidOrderedBy, "only supported on webhook subscriptions")
+	}
+	return options.Redelivery.Validate(ws.ctx)
 }
 
 func (ws *WebSockets) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
 	ws.connMux.Lock()
 	conn, ok := ws.connections[connID]
+	if group, isGroup := ws.groups[connID]; isGroup {
+		conn = group.nextMember()
+		ok = conn != nil
+	}
 	ws.connMux.Unlock()
 	if !ok {
 		return i18n.NewError(ws.ctx, i18n.MsgWSConnectionNotActive, connID)
@@ -101,18 +111,51 @@ func (ws *WebSockets) ChangeEvent(connID string, ce *fftypes.ChangeEvent) {
 }
 
 func (ws *WebSockets) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	ws.ServeHTTPAuthorized(res, req, nil)
+}
+
+// ServeHTTPAuthorized serves a connection that can only start subscriptions, and receive change events,
+// in the namespaces the caller is authorized to read
+func (ws *WebSockets) ServeHTTPAuthorized(res http.ResponseWriter, req *http.Request, authorized func(ns string) bool) {
+	wc := ws.upgrade(res, req, authorized, nil)
+	if wc != nil {
+		wc.processAutoStart(req)
+	}
+}
+
+// ServeChangeEvents serves a connection that only receives the change events of one namespace, filtered
+// before they are sent so that clients only pay for the collections and event types they need
+func (ws *WebSockets) ServeChangeEvents(res http.ResponseWriter, req *http.Request, filter *fftypes.ChangeEventFilter) {
+	wc := ws.upgrade(res, req, nil, filter)
+	if wc == nil {
+		return
+	}
+	autoAck := true
+	err := wc.handleStart(&fftypes.WSClientActionStartPayload{
+		AutoAck:   &autoAck,
+		Ephemeral: true,
+		Namespace: filter.Namespace,
+		Options: fftypes.SubscriptionOptions{
+			ChangeEvents: true,
+		},
+	})
+	if err != nil {
+		wc.protocolError(err)
+	}
+}
+
+func (ws *WebSockets) upgrade(res http.ResponseWriter, req *http.Request, authorized func(ns string) bool, filter *fftypes.ChangeEventFilter) *websocketConnection {
 	wsConn, err := ws.upgrader.Upgrade(res, req, nil)
 	if err != nil {
 		log.L(ws.ctx).Errorf("WebSocket upgrade failed: %s", err)
-		return
+		return nil
 	}
 
 	ws.connMux.Lock()
-	wc := newConnection(ws.ctx, ws, wsConn)
+	wc := newConnection(ws.ctx, ws, wsConn, authorized, filter)
 	ws.connections[wc.connID] = wc
 	ws.connMux.Unlock()
-
-	wc.processAutoStart(req)
+	return wc
 }
 
 func (ws *WebSockets) ack(connID string, inflight *fftypes.EventDeliveryResponse) {
@@ -124,20 +167,68 @@ func (ws *WebSockets) start(wc *websocketConnection, start *fftypes.WSClientActi
 		return i18n.NewError(ws.ctx, i18n.MsgWSInvalidStartAction)
 	}
 	if start.Ephemeral {
+		if start.Group != "" {
+			return i18n.NewError(ws.ctx, i18n.MsgWSGroupEphemeral)
+		}
 		return ws.callbacks.EphemeralSubscription(wc.connID, start.Namespace, &start.Filter, &start.Options)
 	}
+	if start.Group != "" {
+		return ws.joinGroup(wc, start)
+	}
 	// We can have multiple subscriptions on a single
 	return ws.callbacks.RegisterConnection(wc.connID, func(sr fftypes.SubscriptionRef) bool {
 		return wc.durableSubMatcher(sr)
 	})
 }
 
-func (ws *WebSockets) connClosed(connID string) {
+// joinGroup adds the connection to a group sharing a durable subscription. The group is registered with the
+// core as a single connection, so has a single dispatcher and offset, the first time a member joins
+func (ws *WebSockets) joinGroup(wc *websocketConnection, start *fftypes.WSClientActionStartPayload) error {
+	groupID := groupConnID(start.Namespace, start.Name, start.Group)
+	ws.connMux.Lock()
+	group, exists := ws.groups[groupID]
+	if !exists {
+		group = &websocketGroup{
+			connID:    groupID,
+			namespace: start.Namespace,
+			name:      start.Name,
+		}
+		ws.groups[groupID] = group
+	}
+	group.members = append(group.members, wc)
+	ws.connMux.Unlock()
+	if exists {
+		return nil
+	}
+	return ws.callbacks.RegisterConnection(groupID, func(sr fftypes.SubscriptionRef) bool {
+		return sr.Namespace == group.namespace && sr.Name == group.name
+	})
+}
+
+func (ws *WebSockets) connClosed(connID string, orphaned map[string][]*fftypes.EventDeliveryResponse) {
 	ws.connMux.Lock()
 	delete(ws.connections, connID)
+	var emptyGroups []string
+	for groupID, group := range ws.groups {
+		if group.removeMember(connID) && len(group.members) == 0 {
+			delete(ws.groups, groupID)
+			emptyGroups = append(emptyGroups, groupID)
+		}
+	}
 	ws.connMux.Unlock()
 	// Drop lock before calling back
 	ws.callbacks.ConnnectionClosed(connID)
+	// Reject anything in flight to this connection on behalf of a group, so it is redelivered to the remaining members
+	for groupID, inflight := range orphaned {
+		for _, response := range inflight {
+			response.Rejected = true
+			response.Info = fmt.Sprintf("Connection '%s' closed", connID)
+			ws.ack(groupID, response)
+		}
+	}
+	for _, groupID := range emptyGroups {
+		ws.callbacks.ConnnectionClosed(groupID)
+	}
 }
 
 func (ws *WebSockets) WaitClosed() {
@@ -151,3 +242,34 @@ func (ws *WebSockets) WaitClosed() {
 		ws.waitClose()
 	}
 }
+
+func groupConnID(namespace, name, group string) string {
+	return fmt.Sprintf("group/%s/%s/%s", namespace, name, group)
+}
+
+// websocketGroup is a set of connections acting as competing consumers on a durable subscription
+type websocketGroup struct {
+	connID    string
+	namespace string
+	name      string
+	members   []*websocketConnection
+	next      int
+}
+
+func (g *websocketGroup) nextMember() *websocketConnection {
+	if len(g.members) == 0 {
+		return nil
+	}
+	g.next = (g.next + 1) % len(g.members)
+	return g.members[g.next]
+}
+
+func (g *websocketGroup) removeMember(connID string) bool {
+	for i, member := range g.members {
+		if member.connID == connID {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
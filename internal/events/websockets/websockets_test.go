@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -93,6 +94,32 @@ func TestValidateOptionsFail(t *testing.T) {
 	assert.Regexp(t, "FF10244", err)
 }
 
+func TestValidateOptionsBadRedelivery(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, _, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+
+	err := ws.ValidateOptions(&fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			Redelivery: &fftypes.SubOptsRedelivery{Policy: fftypes.SubOptsRedeliveryFixed},
+		},
+	})
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestValidateOptionsOrderedBy(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, _, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+
+	err := ws.ValidateOptions(&fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			OrderedBy: fftypes.SubOptsOrderedByTopic,
+		},
+	})
+	assert.Regexp(t, "FF10526", err)
+}
+
 func TestValidateOptionsOk(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	ws, _, cancel := newTestWebsockets(t, cbs)
@@ -514,6 +541,38 @@ func TestHandleAckMultipleStartedNoSubSingleMatch(t *testing.T) {
 
 }
 
+func TestHandleAckWithReply(t *testing.T) {
+	eventUUID := fftypes.NewUUID()
+	group := fftypes.NewRandB32()
+	reply := &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{{Value: fftypes.JSONAnyPtr(`"my reply"`)}},
+	}
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Reply == reply && *response.ReplyTo.Group == *group && response.ReplyTo.Tag == "replytag"
+	})).Return(nil)
+	wsc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws: &WebSockets{
+			ctx:       context.Background(),
+			callbacks: cbs,
+		},
+		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
+		sendMessages: make(chan interface{}, 1),
+		inflight: []*fftypes.EventDeliveryResponse{
+			{ID: eventUUID},
+		},
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		ID:      eventUUID,
+		Reply:   reply,
+		ReplyTo: &fftypes.ReplyRouting{Group: group, Tag: "replytag"},
+	})
+	assert.NoError(t, err)
+	cbs.AssertExpectations(t)
+}
+
 func TestHandleAckNoneInflight(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
@@ -640,3 +699,187 @@ func TestWebsocketSendAfterClose(t *testing.T) {
 	err = connection.send(map[string]string{"foo": "bar"})
 	assert.Regexp(t, "FF10290", err)
 }
+
+func TestStartGroupEphemeralFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"group":"group1"}`))
+	assert.NoError(t, err)
+	b := <-wsc.Receive()
+	var res fftypes.WSProtocolErrorPayload
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.WSProtocolErrorEventType, res.Type)
+	assert.Regexp(t, "FF10434", res.Error)
+}
+
+func TestAutoStartGroupNackAndRedeliverOnClose(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	groupID := "group/ns1/sub1/group1"
+	waitSubscribed := make(chan struct{})
+	cbs.On("RegisterConnection", groupID, mock.MatchedBy(func(subMatch events.SubscriptionMatcher) bool {
+		return subMatch(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}) &&
+			!subMatch(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub2"})
+	})).Return(nil).Run(func(args mock.Arguments) {
+		close(waitSubscribed)
+	})
+	responses := make(chan *fftypes.EventDeliveryResponse, 2)
+	cbs.On("DeliveryResponse", groupID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		responses <- args[1].(*fftypes.EventDeliveryResponse)
+	})
+
+	ws, wsc, cancel := newTestWebsockets(t, cbs, "namespace=ns1", "name=sub1", "group=group1")
+	defer cancel()
+
+	<-waitSubscribed
+	subRef := fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}
+	event1 := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}},
+		Subscription:  subRef,
+	}
+	err := ws.DeliveryRequest(groupID, nil, event1, nil)
+	assert.NoError(t, err)
+
+	b := <-wsc.Receive()
+	var res fftypes.EventDelivery
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Equal(t, *event1.ID, *res.ID)
+
+	err = wsc.Send(context.Background(), []byte(fmt.Sprintf(`{"type":"ack","id":"%s","rejected":true,"info":"try later"}`, res.ID)))
+	assert.NoError(t, err)
+	nack := <-responses
+	assert.Equal(t, *event1.ID, *nack.ID)
+	assert.True(t, nack.Rejected)
+	assert.Equal(t, "try later", nack.Info)
+
+	// Leave an event in flight, and close the connection
+	event2 := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}},
+		Subscription:  subRef,
+	}
+	err = ws.DeliveryRequest(groupID, nil, event2, nil)
+	assert.NoError(t, err)
+	<-wsc.Receive()
+	wsc.Close()
+
+	redeliver := <-responses
+	assert.Equal(t, *event2.ID, *redeliver.ID)
+	assert.True(t, redeliver.Rejected)
+	assert.Regexp(t, "closed", redeliver.Info)
+	ws.connMux.Lock()
+	assert.Empty(t, ws.groups)
+	ws.connMux.Unlock()
+
+	cbs.AssertExpectations(t)
+}
+
+func TestGroupLoadBalance(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws := &WebSockets{
+		ctx:         context.Background(),
+		callbacks:   cbs,
+		connections: make(map[string]*websocketConnection),
+		groups:      make(map[string]*websocketGroup),
+	}
+	groupID := groupConnID("ns1", "sub1", "group1")
+	cbs.On("RegisterConnection", groupID, mock.Anything).Return(nil).Once()
+
+	start := &fftypes.WSClientActionStartPayload{Namespace: "ns1", Name: "sub1", Group: "group1"}
+	members := make([]*websocketConnection, 2)
+	for i := range members {
+		members[i] = &websocketConnection{
+			ctx:          context.Background(),
+			connID:       fftypes.NewUUID().String(),
+			ws:           ws,
+			started:      []*websocketStartedSub{{name: "sub1", namespace: "ns1", group: "group1"}},
+			sendMessages: make(chan interface{}, 1),
+		}
+		assert.False(t, members[i].durableSubMatcher(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}))
+		err := ws.joinGroup(members[i], start)
+		assert.NoError(t, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		err := ws.DeliveryRequest(groupID, nil, &fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}},
+			Subscription:  fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+		}, nil)
+		assert.NoError(t, err)
+	}
+	for _, member := range members {
+		assert.Len(t, member.sendMessages, 1)
+		assert.Len(t, member.inflight, 1)
+	}
+
+	// Closing an unrelated connection leaves the group intact
+	cbs.On("ConnnectionClosed", "other").Return(nil)
+	ws.connClosed("other", nil)
+	assert.Len(t, ws.groups[groupID].members, 2)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestGroupNoMembers(t *testing.T) {
+	ws := &WebSockets{
+		ctx:         context.Background(),
+		connections: make(map[string]*websocketConnection),
+		groups: map[string]*websocketGroup{
+			"group/ns1/sub1/group1": {connID: "group/ns1/sub1/group1"},
+		},
+	}
+	err := ws.DeliveryRequest("group/ns1/sub1/group1", nil, &fftypes.EventDelivery{}, nil)
+	assert.Regexp(t, "FF10173", err)
+}
+
+func TestHandleStartUnauthorizedNamespace(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:        context.Background(),
+		authorized: func(ns string) bool { return ns == "ns1" },
+	}
+	err := wsc.handleStart(&fftypes.WSClientActionStartPayload{
+		Namespace: "ns2",
+		Ephemeral: true,
+	})
+	assert.Regexp(t, "FF10531", err)
+}
+
+func TestChangeEventsUnauthorizedNamespaceSkipped(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:                context.Background(),
+		sendMessages:       make(chan interface{}, 2),
+		changeEventMatcher: regexp.MustCompile(".*"),
+		authorized:         func(ns string) bool { return ns == "ns1" },
+	}
+	err := wsc.dispatchChangeEvent(&fftypes.ChangeEvent{Namespace: "ns2", Collection: "messages"})
+	assert.NoError(t, err)
+	err = wsc.dispatchChangeEvent(&fftypes.ChangeEvent{Namespace: "ns1", Collection: "messages"})
+	assert.NoError(t, err)
+	err = wsc.dispatchChangeEvent(&fftypes.ChangeEvent{Collection: "pins"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", (<-wsc.sendMessages).(*fftypes.WSChangeNotification).ChangeEvent.Namespace)
+	assert.Empty(t, wsc.sendMessages)
+}
+
+func TestChangeEventsOnlyDiscardsEvents(t *testing.T) {
+	mcb := &eventsmocks.Callbacks{}
+	wsc := &websocketConnection{
+		ctx:               context.Background(),
+		connID:            "conn1",
+		changeEventFilter: &fftypes.ChangeEventFilter{Namespace: "ns1"},
+		ws: &WebSockets{
+			callbacks: mcb,
+		},
+	}
+	eventID := fftypes.NewUUID()
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.ID.Equals(eventID)
+	})).Return(nil)
+	err := wsc.dispatch(&fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: eventID}},
+	})
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+}
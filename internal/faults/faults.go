@@ -0,0 +1,178 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Rule injects a fault into the plugin calls that match its target and operation.
+//
+// The target is matched against the config section of the plugin (such as "blockchain.ethereum.ethconnect",
+// "dataexchange.ffdx" or "database.postgres"), and the operation against the call being made - the method and path
+// of a REST call, the SQL statement of a database call, or "send" for a websocket.
+type Rule struct {
+	Target      string              `json:"target,omitempty"`
+	Operation   string              `json:"operation,omitempty"`
+	Latency     *fftypes.FFDuration `json:"latency,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Disconnect  bool                `json:"disconnect,omitempty"`
+	Probability float64             `json:"probability,omitempty"`
+	After       int                 `json:"after,omitempty"`
+	Count       int                 `json:"count,omitempty"`
+
+	target    *regexp.Regexp
+	operation *regexp.Regexp
+	matched   int
+	injected  int
+}
+
+// Scenario is a list of rules, checked in order - only the first rule that matches a call is applied
+type Scenario struct {
+	Name  string  `json:"name,omitempty"`
+	Rules []*Rule `json:"rules"`
+}
+
+var (
+	mux    sync.Mutex
+	active *Scenario
+	// enabled is set while a scenario is active, so calls are not serialized on the lock when it is not
+	enabled int32
+	chance  = rand.Float64
+)
+
+// Load activates the scenario file configured in faults.scenario, if there is one
+func Load(ctx context.Context) error {
+	path := config.GetString(config.FaultsScenario)
+	if path == "" {
+		return nil
+	}
+	if !buildEnabled {
+		return i18n.NewError(ctx, i18n.MsgFaultsNotBuilt, path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return i18n.NewError(ctx, i18n.MsgFaultsScenarioInvalid, path, err)
+	}
+	scenario, err := ParseScenario(ctx, path, b)
+	if err != nil {
+		return err
+	}
+	Activate(ctx, scenario)
+	return nil
+}
+
+// ParseScenario parses a YAML or JSON scenario, and compiles the expressions in its rules
+func ParseScenario(ctx context.Context, name string, b []byte) (*Scenario, error) {
+	var scenario Scenario
+	err := yaml.Unmarshal(b, &scenario)
+	for _, rule := range scenario.Rules {
+		if err == nil {
+			rule.target, err = regexp.Compile(rule.Target)
+		}
+		if err == nil {
+			rule.operation, err = regexp.Compile(rule.Operation)
+		}
+		if rule.Probability <= 0 {
+			rule.Probability = 1
+		}
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgFaultsScenarioInvalid, name, err)
+	}
+	return &scenario, nil
+}
+
+// Activate replaces the active scenario - nil disables fault injection
+func Activate(ctx context.Context, scenario *Scenario) {
+	mux.Lock()
+	defer mux.Unlock()
+	active = scenario
+	if scenario == nil {
+		atomic.StoreInt32(&enabled, 0)
+	} else {
+		atomic.StoreInt32(&enabled, 1)
+		log.L(ctx).Warnf("Fault injection scenario '%s' active, with %d rules", scenario.Name, len(scenario.Rules))
+	}
+}
+
+func (r *Rule) matches(target, operation string) bool {
+	if !r.target.MatchString(target) || !r.operation.MatchString(operation) {
+		return false
+	}
+	r.matched++
+	if r.matched <= r.After || (r.Count > 0 && r.injected >= r.Count) || chance() >= r.Probability {
+		return false
+	}
+	r.injected++
+	return true
+}
+
+func match(target, operation string) *Rule {
+	mux.Lock()
+	defer mux.Unlock()
+	// The scenario might have been deactivated since the enabled flag was checked
+	if active == nil {
+		return nil
+	}
+	for _, rule := range active.Rules {
+		if rule.matches(target, operation) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Inject applies the first rule of the active scenario that matches the call, returning an error if the
+// rule injects one. Any latency is applied first, and is cut short if the context is cancelled.
+func Inject(ctx context.Context, target, operation string) error {
+	if !buildEnabled || atomic.LoadInt32(&enabled) == 0 {
+		return nil
+	}
+	rule := match(target, operation)
+	if rule == nil {
+		return nil
+	}
+	log.L(ctx).Warnf("Injecting fault into %s: %s", target, operation)
+	if rule.Latency != nil {
+		select {
+		case <-time.After(time.Duration(*rule.Latency)):
+		case <-ctx.Done():
+			return i18n.NewError(ctx, i18n.MsgContextCanceled)
+		}
+	}
+	switch {
+	case rule.Disconnect:
+		return i18n.NewError(ctx, i18n.MsgFaultDisconnect)
+	case rule.Error != "":
+		return i18n.NewError(ctx, i18n.MsgFaultInjected, rule.Error)
+	default:
+		return nil
+	}
+}
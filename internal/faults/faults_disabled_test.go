@@ -0,0 +1,39 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !faults
+// +build !faults
+
+package faults
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadNotBuilt(t *testing.T) {
+	config.Reset()
+	config.Set(config.FaultsScenario, "scenario.yaml")
+	assert.Regexp(t, "FF10451", Load(context.Background()))
+}
+
+func TestInjectCompiledOut(t *testing.T) {
+	defer newTestScenario(t)()
+	assert.NoError(t, Inject(context.Background(), "tokens.0", "send"))
+}
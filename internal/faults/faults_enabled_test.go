@@ -0,0 +1,118 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package faults
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectNoScenario(t *testing.T) {
+	assert.NoError(t, Inject(context.Background(), "blockchain", "POST /api/v1/mint"))
+}
+
+func TestInjectError(t *testing.T) {
+	defer newTestScenario(t)()
+	ctx := context.Background()
+
+	assert.NoError(t, Inject(ctx, "blockchain.ethereum", "POST /api/v1/mint")) // after
+	assert.Regexp(t, "FF10453.*pop", Inject(ctx, "blockchain.ethereum", "POST /api/v1/mint"))
+	assert.NoError(t, Inject(ctx, "blockchain.ethereum", "POST /api/v1/mint")) // count
+	assert.NoError(t, Inject(ctx, "blockchain.ethereum", "POST /api/v1/burn"))
+}
+
+func TestInjectDisconnect(t *testing.T) {
+	defer newTestScenario(t)()
+	assert.Regexp(t, "FF10454", Inject(context.Background(), "tokens.0", "send"))
+}
+
+func TestInjectLatency(t *testing.T) {
+	defer newTestScenario(t)()
+	start := time.Now()
+	assert.NoError(t, Inject(context.Background(), "database.postgres", "BEGIN"))
+	assert.GreaterOrEqual(t, time.Since(start), 1*time.Millisecond)
+}
+
+func TestInjectLatencyCancelled(t *testing.T) {
+	scenario, err := ParseScenario(context.Background(), "ut", []byte(`{"rules":[{"latency":"1h"}]}`))
+	assert.NoError(t, err)
+	Activate(context.Background(), scenario)
+	defer Activate(context.Background(), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Regexp(t, "FF10158", Inject(ctx, "anything", "anything"))
+}
+
+func TestInjectProbability(t *testing.T) {
+	scenario, err := ParseScenario(context.Background(), "ut", []byte(`{"rules":[{"error":"pop","probability":0.5}]}`))
+	assert.NoError(t, err)
+	Activate(context.Background(), scenario)
+	defer Activate(context.Background(), nil)
+	defer func() { chance = rand.Float64 }()
+
+	chance = func() float64 { return 0.6 }
+	assert.NoError(t, Inject(context.Background(), "blockchain", "send"))
+	chance = func() float64 { return 0.4 }
+	assert.Regexp(t, "FF10453", Inject(context.Background(), "blockchain", "send"))
+}
+
+func TestLoadOK(t *testing.T) {
+	config.Reset()
+	scenarioFile := path.Join(t.TempDir(), "scenario.yaml")
+	err := ioutil.WriteFile(scenarioFile, []byte(testScenario), 0644)
+	assert.NoError(t, err)
+	config.Set(config.FaultsScenario, scenarioFile)
+	defer Activate(context.Background(), nil)
+
+	assert.NoError(t, Load(context.Background()))
+	assert.Equal(t, "ut", active.Name)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	config.Reset()
+	config.Set(config.FaultsScenario, path.Join(t.TempDir(), "missing.yaml"))
+
+	assert.Regexp(t, "FF10452", Load(context.Background()))
+}
+
+func TestLoadBadScenario(t *testing.T) {
+	config.Reset()
+	scenarioFile := path.Join(t.TempDir(), "scenario.yaml")
+	err := ioutil.WriteFile(scenarioFile, []byte(`!wrong`), 0644)
+	assert.NoError(t, err)
+	config.Set(config.FaultsScenario, scenarioFile)
+
+	assert.Regexp(t, "FF10452", Load(context.Background()))
+}
+
+func TestInjectDeactivatedWhileMatching(t *testing.T) {
+	atomic.StoreInt32(&enabled, 1)
+	defer atomic.StoreInt32(&enabled, 0)
+	assert.NoError(t, Inject(context.Background(), "tokens.0", "send"))
+}
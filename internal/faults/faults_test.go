@@ -0,0 +1,68 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScenario = `
+name: ut
+rules:
+- target: ^blockchain
+  operation: ^POST /api/v1/mint
+  error: pop
+  after: 1
+  count: 1
+- target: ^tokens
+  disconnect: true
+- target: ^database
+  latency: 1ms
+`
+
+func newTestScenario(t *testing.T) func() {
+	scenario, err := ParseScenario(context.Background(), "ut", []byte(testScenario))
+	assert.NoError(t, err)
+	Activate(context.Background(), scenario)
+	return func() {
+		Activate(context.Background(), nil)
+	}
+}
+
+func TestParseScenarioBadYAML(t *testing.T) {
+	_, err := ParseScenario(context.Background(), "ut", []byte(`!wrong`))
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestParseScenarioBadTarget(t *testing.T) {
+	_, err := ParseScenario(context.Background(), "ut", []byte(`{"rules":[{"target":"["}]}`))
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestParseScenarioBadOperation(t *testing.T) {
+	_, err := ParseScenario(context.Background(), "ut", []byte(`{"rules":[{"operation":"["}]}`))
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestLoadNotConfigured(t *testing.T) {
+	config.Reset()
+	assert.NoError(t, Load(context.Background()))
+}
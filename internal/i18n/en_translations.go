@@ -295,4 +295,203 @@ var (
 	MsgDownloadBatchMaxBytes        = ffm("FF10377", "Error downloading batch with reference '%s' from shared storage - maximum size limit reached")
 	MsgOperationDataIncorrect       = ffm("FF10378", "Operation data type incorrect: %T", 400)
 	MsgDataMissingBlobHash          = ffm("FF10379", "Blob for data %s cannot be transferred as it is missing a hash", 500)
+	MsgInvalidTransportEncoding     = ffm("FF10380", "Invalid transport encoding for received payload")
+	MsgInvalidProtocolVersion       = ffm("FF10381", "Node '%s' advertises an invalid network protocol version '%v'. This node supports versions %d to %d")
+	MsgSearchQueryParam             = ffm("FF10382", "Full-text search terms to match against message tags and topics, data values, and blockchain events")
+	MsgFullTextSearchDisabled       = ffm("FF10383", "Full-text search is not enabled for this database", 400)
+	MsgDefinitionTagReserved        = ffm("FF10384", "Definition tag '%s' is invalid - tags with the '%s' prefix are reserved for system definitions", 400)
+	MsgDefinitionHandlerRegistered  = ffm("FF10385", "A definition handler is already registered for tag '%s'")
+	MsgFetchOperationOutput         = ffm("FF10386", "When set, outputs that were too large to store inline on the operation are fetched from the separate store they were written to")
+	MsgErrorNameMustBeSet           = ffm("FF10387", "Error name must be set", 400)
+	MsgCircuitOpen                  = ffm("FF10388", "Circuit '%s' is open after %d consecutive failures - calls are rejected until %s", 503)
+	MsgPluginNotConnected           = ffm("FF10389", "Plugin '%s' is not connected to its connector (state=%s)")
+	MsgSchemaRefNotFound            = ffm("FF10390", "Datatype '%s' referenced by JSON schema not found in namespace '%s'", 400)
+	MsgSchemaRefUnsupported         = ffm("FF10391", "JSON schema reference '%s' is not supported - only datatypes in the same namespace can be referenced, as 'ff://datatypes/<name>/<version>'", 400)
+	MsgUnknownJSONSchemaDraft       = ffm("FF10392", "Unknown JSON schema draft '%s'")
+	MsgDataMediaTypeUnsupported     = ffm("FF10393", "Unsupported data media type '%s'", 400)
+	MsgDataDecodeFailed             = ffm("FF10394", "Failed to decode '%s' data value: %s", 400)
+	MsgDataOriginalMismatch         = ffm("FF10395", "Data value does not match the canonical JSON form of the original '%s' payload", 400)
+	MsgDataEncodedValueNotString    = ffm("FF10396", "The value of '%s' data must be supplied as a JSON string", 400)
+	MsgWebhooksOptSigning           = ffm("FF10397", "Sign each delivery with an HMAC-SHA256 of the timestamp and body, so the receiver can authenticate it")
+	MsgWebhooksOptSigningSecret     = ffm("FF10398", "The secret used to compute the signature")
+	MsgWebhooksOptSigningPrevious   = ffm("FF10399", "Previous secrets that are still accepted by the receiver, during key rotation. A signature is included for each")
+	MsgWebhooksOptSigningHeader     = ffm("FF10400", "The header in which to send the timestamp and signatures - default 'X-FireFly-Signature'")
+	MsgWebhookSigningSecretEmpty    = ffm("FF10401", "Webhook subscription option 'signing.secret' cannot be empty when signing is enabled", 400)
+	MsgWebhookInvalidStringArray    = ffm("FF10402", "Webhook subscription option '%s' must be an array of strings", 400)
+	MsgRemoteTransportTimeout       = ffm("FF10403", "Timed out waiting for remote event transport to respond to '%s' request", 504)
+	MsgRemoteTransportError         = ffm("FF10404", "Remote event transport returned error: %s", 400)
+	MsgFFConnectorRESTErr           = ffm("FF10405", "Error from blockchain connector: %s")
+	MsgInvalidVerifierTypeConfig    = ffm("FF10406", "Invalid verifier type '%s' configured for %s")
+	MsgPinningKeyNotAllowed         = ffm("FF10407", "Pinning keys can only be registered for child identities that are not nodes", 400)
+	MsgSyncSinceParam               = ffm("FF10408", "Return changes made after this sequence of the change log - pass the 'next' value from the previous response to continue")
+	MsgSyncLimitParam               = ffm("FF10409", "The maximum number of changes to return")
+	MsgInvalidSyncLimit             = ffm("FF10410", "Invalid limit '%s'. Must be a number between 1 and %d", 400)
+	MsgInvalidContractListenerState = ffm("FF10411", "Invalid contract listener state '%s'", 400)
+	MsgContractListenerPauseUnsup   = ffm("FF10412", "Pausing contract listeners is not supported by this blockchain plugin", 400)
+	MsgDuplicateEventStream         = ffm("FF10413", "Duplicate event stream %s '%s'")
+	MsgInvalidEventStreamErrors     = ffm("FF10414", "Invalid errorHandling '%s' for event stream '%s' - must be 'block' or 'skip'")
+	MsgUnknownEventStream           = ffm("FF10415", "Unknown event stream '%s'", 400)
+	MsgPluginActionNotSupported     = ffm("FF10416", "Diagnostic action '%s' is not supported by %s plugin '%s'", 400)
+	MsgKeyNotRegisteredToAuthor     = ffm("FF10417", "Key '%s' is not a registered verifier of author '%s'", 400)
+	MsgBatchSignerMissing           = ffm("FF10418", "Message '%s' cannot be batched without a resolved signing key and author")
+	MsgListenerTemplateNoEvent      = ffm("FF10419", "Listener template %d must specify the name of an event in the interface", 400)
+	MsgListenerTemplateNoTransport  = ffm("FF10420", "Subscription for listener template %d must specify a transport", 400)
+	MsgInvalidTopicPattern          = ffm("FF10421", "Invalid topic pattern '%s' in %s - must be a valid topic name, in which '*' matches any sequence of characters", 400)
+	MsgInvalidWaitFor               = ffm("FF10422", "Invalid waitfor '%s' - must be one of: %s", 400)
+	MsgWaitForTimeout               = ffm("FF10423", "Timed out after %s waiting for message '%s' to be %s", 408)
+	MsgWaitForDesc                  = ffm("FF10424", "Wait for the message to reach this state before returning it - 'confirmed' or 'rejected'")
+	MsgWaitForTimeoutDesc           = ffm("FF10425", "Maximum time to wait when using waitfor (default 5s, or set a custom suffix like 500ms)")
+	MsgContractDeployNoContract     = ffm("FF10426", "The contract to deploy must be supplied", 400)
+	MsgContractDeployNoDefinition   = ffm("FF10427", "A definition or interface must be supplied to describe the contract constructor", 400)
+	MsgContractDeployUnsupported    = ffm("FF10428", "Deploying contracts is not supported by this blockchain plugin", 400)
+	MsgContractDeployInvalid        = ffm("FF10429", "Invalid contract to deploy: %s", 400)
+	MsgIdentityProfileInvalid       = ffm("FF10431", "Profile of identity '%s' is invalid: %s", 400)
+	MsgPrivateProfileNeedsConfirm   = ffm("FF10432", "Private profile fields can only be set when registering an identity if waiting for confirmation", 400)
+	MsgPrivateProfileNodeIdentity   = ffm("FF10433", "Private profile fields are not supported for node identities", 400)
+	MsgWSGroupEphemeral             = ffm("FF10434", "A group can only be joined for a durable subscription")
+	MsgSecretEnvNotSet              = ffm("FF10435", "Environment variable '%s' is not set")
+	MsgSecretFileReadFailed         = ffm("FF10436", "Failed to read secret file '%s'")
+	MsgVaultRESTErr                 = ffm("FF10437", "Error from Vault: %s")
+	MsgVaultSecretFieldMissing      = ffm("FF10438", "Vault secret '%s' does not contain a string field '%s'")
+	MsgSecretResolveFailed          = ffm("FF10439", "Failed to resolve the secret referenced by config key '%s'")
+	MsgBatchMigrationNoPayload      = ffm("FF10440", "Batch '%s' has neither a manifest nor a legacy payload")
+	MsgBatchMigrationHashMismatch   = ffm("FF10441", "Legacy payload of batch '%s' does not match the batch hash '%s'")
+	MsgBatchMigrationMessageHash    = ffm("FF10442", "Legacy payload of batch '%s' contains a message that does not match its hash")
+	MsgBatchMigrationRunning        = ffm("FF10443", "A batch migration is already running", 409)
+	MsgNodeInStandby                = ffm("FF10444", "The node is in standby mode, and only accepts read requests until it is promoted", 503)
+	MsgNodeNotInStandby             = ffm("FF10445", "The node is not in standby mode", 409)
+	MsgStandbyStillReplicating      = ffm("FF10446", "The latest %s sequence changed from %d to %d while checking for promotion - the primary might still be active", 409)
+	MsgStandbyOffsetAhead           = ffm("FF10447", "The %s offset '%s' is at %d, beyond the latest %s sequence %d - the replica is missing data", 409)
+	MsgStandbyBehind                = ffm("FF10448", "The latest %s sequence is %d, behind the required minimum of %d", 409)
+	MsgInvalidCorrelationID         = ffm("FF10449", "Invalid correlation ID in the %s header - must be up to %d printable characters, without spaces", 400)
+	MsgCorrelationIDDesc            = ffm("FF10450", "Correlation ID recorded on the transactions, operations, messages and events resulting from the request")
+	MsgFaultsNotBuilt               = ffm("FF10451", "Fault injection scenario '%s' is configured, but this build does not support fault injection (build with -tags faults)")
+	MsgFaultsScenarioInvalid        = ffm("FF10452", "Invalid fault injection scenario '%s': %s")
+	MsgFaultInjected                = ffm("FF10453", "Injected fault: %s")
+	MsgFaultDisconnect              = ffm("FF10454", "Injected disconnect")
+	MsgMemchainJournalError         = ffm("FF10455", "Failed to access memchain journal '%s': %s")
+	MsgMemchainContractsUnsupported = ffm("FF10456", "Custom smart contracts are not supported by the memchain blockchain plugin", 400)
+	MsgLoopbackPoolNotFound         = ffm("FF10457", "Token pool '%s' not found on loopback hub '%s'", 404)
+	MsgLoopbackInsufficientBalance  = ffm("FF10458", "Insufficient balance of token '%s' in pool '%s' for '%s'")
+	MsgLoopbackNotApproved          = ffm("FF10459", "'%s' is not approved to transfer tokens in pool '%s' for '%s'")
+	MsgLoopbackNonFungibleAmount    = ffm("FF10460", "Non-fungible tokens must be minted one at a time, with an amount of 1")
+	MsgLoopbackBlobNotFound         = ffm("FF10461", "Blob '%s' not found", 404)
+	MsgLoopbackPeerNotFound         = ffm("FF10462", "Peer '%s' not found on loopback hub '%s'")
+	MsgBootstrapDirReadFailed       = ffm("FF10463", "Failed to read bootstrap directory '%s': %s")
+	MsgBootstrapFileInvalid         = ffm("FF10464", "Invalid bootstrap definitions file '%s': %s")
+	MsgNamespaceSignerEmpty         = ffm("FF10465", "An author or key must be supplied for the default signer of a namespace", 400)
+	MsgFetchRawDesc                 = ffm("FF10466", "Fetch the full raw information for blockchain events that were received by a listener with the storeRaw option", 400)
+	MsgBlockchainEventRawInvalid    = ffm("FF10467", "Invalid raw information stored for blockchain event '%s'")
+	MsgMessageNotPinned             = ffm("FF10468", "Message '%s' has not been confirmed in a batch pinned to the blockchain", 409)
+	MsgConfirmationsNotSupported    = ffm("FF10469", "The blockchain plugin '%s' does not support a confirmation depth for listeners", 400)
+	MsgPreferHeaderDesc             = ffm("FF10470", "Set to respond-async to process the request in the background, returning 202 Accepted with a request resource that can be polled for the outcome")
+	MsgAsyncQueryParam              = ffm("FF10471", "When true the request is processed in the background, in the same way as a 'Prefer: respond-async' header")
+	MsgAsyncRequestWaitDesc         = ffm("FF10472", "How long to wait for the request to complete before returning its current state, such as 30s. Capped at api.asyncRequestMaxWait, and limited by the request timeout")
+	MsgRoutingRuleNoLabels          = ffm("FF10473", "A routing rule must attach at least one label", 400)
+	MsgRoutingRuleInvalidPattern    = ffm("FF10474", "Invalid regular expression for '%s' in routing rule: '%s'", 400)
+	MsgBulkTransferEmpty            = ffm("FF10475", "At least one transfer must be supplied", 400)
+	MsgBulkTransferTooMany          = ffm("FF10476", "Too many transfers in a single request: %d (maximum %d)", 400)
+	MsgBulkTransferInvalid          = ffm("FF10477", "Transfer %d is invalid: %s", 400)
+	MsgBulkTransferMessage          = ffm("FF10478", "Transfers submitted in bulk cannot include a message", 400)
+	MsgBulkTransferMixedConnectors  = ffm("FF10479", "All transfers must use the same token connector: '%s' and '%s' were both requested", 400)
+	MsgGroupAliasTarget             = ffm("FF10480", "A group alias must refer to either a group hash or a list of members", 400)
+	MsgGroupAliasNotFound           = ffm("FF10481", "Group alias '%s' not found", 404)
+	MsgGroupAliasWithMembers        = ffm("FF10482", "A group alias cannot be combined with a list of members", 400)
+	MsgPendingDataTimeout           = ffm("FF10483", "Data for the message was not uploaded within %s")
+	MsgInvalidCallbackURL           = ffm("FF10484", "Invalid callback URL '%s' - must be an absolute http or https URL", 400)
+	MsgCallbackDeliveryFailed       = ffm("FF10485", "Callback delivery failed with status %d")
+	MsgOperationNotResolved         = ffm("FF10486", "Operation %s has not been resolved")
+	MsgCheckpointSequenceParam      = ffm("FF10487", "Highest pin sequence to include in the checkpoint - defaults to the latest pin")
+	MsgInvalidCheckpointSequence    = ffm("FF10488", "Invalid checkpoint sequence '%s' - must be a positive integer", 400)
+	MsgUnsupportedHashAlgorithm     = ffm("FF10489", "Unsupported hash algorithm '%s'", 400)
+	MsgDXBadEncryptionKey           = ffm("FF10490", "Invalid data exchange encryption key: %s")
+	MsgDXUnsupportedTransform       = ffm("FF10491", "Unsupported data exchange blob transform '%s'")
+	MsgDXTransformFailed            = ffm("FF10492", "Failed to reverse data exchange blob transform '%s' for blob from '%s'")
+	MsgTooManyDataItems             = ffm("FF10493", "Message has %d data items, which exceeds the limit of %d for namespace '%s'", 400)
+	MsgMessageTooLarge              = ffm("FF10494", "Message size of %d bytes exceeds the limit of %d bytes for namespace '%s'", 413)
+	MsgBlobTooLarge                 = ffm("FF10495", "Blob exceeds the limit of %d bytes for namespace '%s'", 413)
+	MsgInvalidNamespaceLimits       = ffm("FF10496", "Invalid limits for namespace '%s': %s")
+	MsgInvalidConnectorAPI          = ffm("FF10497", "Invalid connectorAPI '%s' for blockchain.ethconnect - must be 'auto', 'ethconnect' or 'evmconnect'")
+	MsgChainIDMismatch              = ffm("FF10498", "Connector is on chain ID %s, but the expected chain ID is %s")
+	MsgChainIDUnknown               = ffm("FF10499", "Failed to query the chain ID with eth_chainId: %s")
+	MsgUnknownChainProfile          = ffm("FF10500", "Unknown chainProfile '%s' for blockchain.ethconnect - must be 'polygon' or 'arbitrum', or empty for a generic EVM chain")
+	MsgInvalidTokenPoolBackfill     = ffm("FF10501", "Invalid backfill for token pool: %s", 400)
+	MsgInvalidRedelivery            = ffm("FF10502", "Invalid redelivery options for subscription: %s", 400)
+	MsgMessageImportUnknownFormat   = ffm("FF10503", "Unable to determine the format of message import '%s' - set format to 'ndjson' or 'csv'", 400)
+	MsgMessageImportInvalidRow      = ffm("FF10504", "Invalid message at row %d of the import: %s", 400)
+	MsgMessageImportUnknownColumn   = ffm("FF10505", "Unknown column '%s' in the CSV message import", 400)
+	MsgMessageImportEmpty           = ffm("FF10506", "The message import does not contain any messages", 400)
+	MsgMessageImportTooLarge        = ffm("FF10507", "The message import contains more than the maximum of %d messages", 413)
+	MsgMessageImportFailed          = ffm("FF10508", "%d of %d messages failed to import")
+	MsgContractTransformInvalid     = ffm("FF10510", "Invalid input transform for '%s' on method '%s': %s", 400)
+	MsgContractTransformUnknownMeth = ffm("FF10511", "Input transforms are defined for method '%s', which is not in the contract interface", 400)
+	MsgContractTransformFailed      = ffm("FF10512", "Failed to transform input '%s': %s", 400)
+	MsgDataExportInvalid            = ffm("FF10513", "Invalid data export request: %s", 400)
+	MsgDataExportFailed             = ffm("FF10514", "Failed to export %s: %s")
+	MsgInvalidTokenPoolIngestFilter = ffm("FF10515", "Invalid ingest filter for token pool: %s", 400)
+	MsgInvalidListenerCheckpoint    = ffm("FF10516", "Invalid checkpoint for contract listener: %s", 400)
+	MsgPinAccessNotPermissioned     = ffm("FF10517", "The batch pin contract of blockchain plugin '%s' does not restrict pinning to registered keys", 400)
+	MsgPinKeyAlreadyRegistered      = ffm("FF10518", "Key '%s' is already registered with the batch pin contract", 409)
+	MsgInvalidApprovalsConfig       = ffm("FF10519", "Invalid approvals configuration: %s")
+	MsgNotAnApprover                = ffm("FF10520", "'%s' is not one of the configured approvers", 403)
+	MsgApprovalRequestNotPending    = ffm("FF10521", "Approval request %s is %s, and can no longer be decided", 409)
+	MsgApprovalAlreadyDecided       = ffm("FF10522", "'%s' has already decided approval request %s", 409)
+	MsgApprovalRequestNoHandler     = ffm("FF10523", "No handler is registered for approval requests of type '%s'")
+	MsgBulkTransferNeedsApproval    = ffm("FF10524", "A mint of %s requires approval, and must be submitted on its own rather than in a bulk transfer", 400)
+	MsgPrivateDataOrgNotAllowed     = ffm("FF10525", "Namespace '%s' is not allowed to exchange private data with '%s'", 403)
+	MsgInvalidOrderedBy             = ffm("FF10526", "Invalid orderedBy option for subscription: %s", 400)
+	MsgUnknownLogComponent          = ffm("FF10527", "Unknown log component '%s'", 404)
+	MsgInvalidLogLevel              = ffm("FF10528", "Invalid log level '%s' - must be one of error, warn, info, debug or trace", 400)
+	MsgDevIdentitiesNoOrgName       = ffm("FF10529", "org.name must be configured to derive development identities")
+	MsgDevIdentitiesKeyFileFailed   = ffm("FF10530", "Failed to write development keys to '%s': %s")
+	MsgNamespaceUnauthorized        = ffm("FF10531", "Credentials are required to access namespace '%s'", 401)
+	MsgNamespaceForbidden           = ffm("FF10532", "'%s' does not have the '%s' role in namespace '%s'", 403)
+	MsgInvalidChangeEventFilter     = ffm("FF10533", "Invalid change event filter: %s", 400)
+	MsgCredentialsFileReadFailed    = ffm("FF10534", "Failed to read credentials file '%s'")
+	MsgCredentialsFileEmpty         = ffm("FF10535", "Credentials file '%s' is empty")
+	MsgCredentialsOAuth2Failed      = ffm("FF10536", "Failed to obtain OAuth2 access token: %s")
+	MsgCredentialsOAuth2NoToken     = ffm("FF10537", "No access token was returned by OAuth2 token endpoint %s")
+	MsgDraftDeferredData            = ffm("FF10538", "A draft message cannot refer to data that has not been uploaded", 400)
+	MsgMessageNotDraft              = ffm("FF10539", "Message %s is %s, and is not a draft", 409)
+	MsgRequestCannotBeDraft         = ffm("FF10540", "Request messages cannot be drafts", 400)
+	MsgHistogramIntervalParam       = ffm("FF10541", "Length of each bucket, such as 1h, instead of a number of buckets")
+	MsgHistogramGroupByParam        = ffm("FF10542", "Field to count each bucket by, instead of the type")
+	MsgHistogramPercentilesParam    = ffm("FF10543", "Comma separated latency percentiles to calculate for each bucket, such as 50,90,99 (messages only)")
+	MsgHistogramInvalidGroupBy      = ffm("FF10544", "Cannot group the %s collection by '%s' - allowed fields are %s", 400)
+	MsgHistogramTooManyGroups       = ffm("FF10545", "Field '%s' has more than %d distinct values to group by", 400)
+	MsgHistogramLatencyCollection   = ffm("FF10546", "Latency percentiles are only available for the messages collection, and cannot be grouped", 400)
+	MsgHistogramInvalidPercentile   = ffm("FF10547", "Invalid percentile %v. Must be greater than 0 and no more than 100", 400)
+	MsgStateSnapshotVersion         = ffm("FF10548", "Unsupported state snapshot version %d. This node supports version %d", 400)
+	MsgTokensProtocolVersionInvalid = ffm("FF10549", "Token connector '%s' reports an invalid protocol version '%s' - assuming %s")
+	MsgTokensProtocolIncompatible   = ffm("FF10550", "Token connector '%s' reports protocol version %s, which is not compatible with protocol version %s supported by FireFly")
+	MsgTokensProtocolDegraded       = ffm("FF10551", "Token connector '%s' reports protocol version %s - features that require a later version are disabled: %s")
+	MsgTokensFeatureNotSupported    = ffm("FF10552", "Token connector '%s' does not support %s - protocol version %s or later is required, and the connector reports version %s", 400)
+	MsgMaskedValueFilter            = ffm("FF10553", "Data values are masked in this namespace, so cannot be used in filters", 403)
+	MsgReplyGroupAndMembers         = ffm("FF10554", "A reply cannot be routed to both a group and a list of members", 400)
+	MsgWebhooksOptReplyGroup        = ffm("FF10555", "The hash of an existing private group to send the reply message to, instead of the group of the event")
+	MsgWebhooksOptReplyMembers      = ffm("FF10556", "The identities to send the reply message to privately, instead of the group of the event")
+	MsgWebhooksOptReplyTopics       = ffm("FF10557", "The topics to set on the reply message, instead of the topics of the event")
+	MsgDeliveryAckSigningKeyMissing = ffm("FF10558", "A signing key file must be configured in privatemessaging.deliveryAcks.signingKeyFile when delivery acknowledgements are enabled")
+	MsgDeliveryAckSigningKeyInvalid = ffm("FF10559", "Invalid delivery acknowledgement signing key file '%s': %s")
+	MsgTransportPayloadTooLarge     = ffm("FF10560", "Received payload exceeds the maximum size of %d bytes when decompressed")
+	MsgApprovalMintWithMessage      = ffm("FF10561", "A token mint that is held for approval cannot include a message", 400)
+	MsgApproverNotAuthenticated     = ffm("FF10562", "Approval decisions must be made by a caller authenticated by the access list of namespace '%s'", 401)
+	MsgApproverNotCaller            = ffm("FF10563", "Approver '%s' does not match the authenticated caller '%s'", 403)
+	MsgDataIDExists                 = ffm("FF10564", "Data with ID '%s' already exists", 409)
+	MsgDXTransformTooLarge          = ffm("FF10565", "Blob from '%s' is larger than its declared size of %d bytes once its transforms are reversed")
+	MsgStateSnapshotOffsetAhead     = ffm("FF10566", "Offset '%s:%s' in the snapshot is at %d, beyond the latest sequence %d in the database", 400)
+	MsgInvalidCheckpointBatch       = ffm("FF10567", "Invalid checkpoint batch at index %d - an id and root are required", 400)
+	MsgInvalidSyncSince             = ffm("FF10568", "Invalid since '%s' - must be a sequence of the change log", 400)
+	MsgDataExportInterrupted        = ffm("FF10569", "Data export was interrupted by a restart of the node")
+	MsgAsyncRequestsBusy            = ffm("FF10570", "Too many requests are being processed in the background (maximum %d)", 429)
+	MsgChainIDChanged               = ffm("FF10571", "Blockchain is on chain ID %s, but the most recent transaction %s was recorded on chain ID %s")
+	MsgPrivateBatchOrgNotAllowed    = ffm("FF10572", "Batch %s cannot be dispatched, as namespace '%s' is no longer allowed to exchange private data with '%s'")
+	MsgXSDNotString                 = ffm("FF10573", "The value of datatype '%s' must be the XSD document, as a JSON string", 400)
+	MsgXSDRequiresXML               = ffm("FF10574", "Data validated by the XSD of datatype '%s' must have a media type of '%s'", 400)
+	MsgXMLDataInvalidPerSchema      = ffm("FF10575", "Data does not conform to the XSD of datatype '%s': %s", 400)
+	MsgCallbackHostNotAllowed       = ffm("FF10576", "Callback URL '%s' is not allowed - its host must be listed in operations.callbacks.allowedHosts", 400)
+	MsgConnectorAPIUndetected       = ffm("FF10577", "Unable to detect the connector API from the status response of the connector - set blockchain.ethconnect.connectorAPI to 'ethconnect' or 'evmconnect'")
+	MsgRemoteTransportNotReady      = ffm("FF10578", "Remote event transport has not declared protocol version %d", 503)
+	MsgRemoteTransportVersion       = ffm("FF10579", "Remote event transport declared protocol version %d, but version %d is required")
+	MsgDataValuePathNotIndexed      = ffm("FF10580", "Cannot filter on 'value.%s' as it is not an index of any datatype", 400)
 )
@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/sha3"
+)
+
+// DevKey is a deterministic secp256k1 key pair, derived from a seed and a name, so that development
+// and test environments get the same Ethereum addresses every time they are created.
+// These keys are derived from public information, and must never be used to hold real value.
+type DevKey struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey"`
+}
+
+func keccak256(parts ...[]byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	for _, p := range parts {
+		hash.Write(p)
+	}
+	return hash.Sum(nil)
+}
+
+// DeriveDevKey derives the private key for a name as keccak256(seed/name), re-hashing in the
+// (vanishingly unlikely) case that the result is not a valid secp256k1 scalar
+func DeriveDevKey(seed, name string) *DevKey {
+	d := new(big.Int).SetBytes(keccak256([]byte(seed), []byte("/"), []byte(name)))
+	for d.Sign() == 0 || d.Cmp(secp256k1.S256().N) >= 0 {
+		d.SetBytes(keccak256(d.Bytes()))
+	}
+	privKey := secp256k1.PrivKeyFromBytes(d.FillBytes(make([]byte, 32)))
+	return &DevKey{
+		Name:       name,
+		Address:    devKeyAddress(privKey.PubKey()),
+		PrivateKey: hex.EncodeToString(privKey.Serialize()),
+	}
+}
+
+// devKeyAddress is the Ethereum address of a public key - the last 20 bytes of the keccak256
+// hash of the uncompressed public key, without its 0x04 prefix
+func devKeyAddress(pubKey *secp256k1.PublicKey) string {
+	return "0x" + hex.EncodeToString(keccak256(pubKey.SerializeUncompressed()[1:])[12:])
+}
@@ -0,0 +1,52 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPubKey(d byte) *secp256k1.PublicKey {
+	b := make([]byte, 32)
+	b[31] = d
+	return secp256k1.PrivKeyFromBytes(b).PubKey()
+}
+
+func TestDevKeyAddressKnownKeys(t *testing.T) {
+	assert.Equal(t, "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf", devKeyAddress(testPubKey(1)))
+	assert.Equal(t, "0x2b5ad5c4795c026514f8317c7a215e218dccd6cf", devKeyAddress(testPubKey(2)))
+	assert.Equal(t, "0x6813eb9362372eef6200f3b1dbc3f819671cba69", devKeyAddress(testPubKey(3)))
+}
+
+func TestDeriveDevKeyDeterministic(t *testing.T) {
+	k1 := DeriveDevKey("firefly", "org_0")
+	k2 := DeriveDevKey("firefly", "org_0")
+	assert.Equal(t, k1, k2)
+	assert.Equal(t, "org_0", k1.Name)
+	assert.Regexp(t, "^0x[0-9a-f]{40}$", k1.Address)
+	assert.Regexp(t, "^[0-9a-f]{64}$", k1.PrivateKey)
+
+	d, _ := hex.DecodeString(k1.PrivateKey)
+	assert.Equal(t, k1.Address, devKeyAddress(secp256k1.PrivKeyFromBytes(d).PubKey()))
+
+	assert.NotEqual(t, k1.Address, DeriveDevKey("firefly", "org_1").Address)
+	assert.NotEqual(t, k1.Address, DeriveDevKey("other", "org_0").Address)
+}
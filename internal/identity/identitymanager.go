@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -51,6 +52,10 @@ type Manager interface {
 	GetNodeOwnerBlockchainKey(ctx context.Context) (*fftypes.VerifierRef, error)
 	GetNodeOwnerOrg(ctx context.Context) (*fftypes.Identity, error)
 	VerifyIdentityChain(ctx context.Context, identity *fftypes.Identity) (immediateParent *fftypes.Identity, retryable bool, err error)
+	ValidateProfile(ctx context.Context, identity *fftypes.Identity) (retry bool, err error)
+	GetNamespaceSigner(ctx context.Context, namespace string) (*fftypes.NamespaceSigner, error)
+	SetNamespaceSigner(ctx context.Context, namespace string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error)
+	DeleteNamespaceSigner(ctx context.Context, namespace string) error
 }
 
 type identityManager struct {
@@ -65,6 +70,9 @@ type identityManager struct {
 	identityCache          *ccache.Cache
 	signingKeyCacheTTL     time.Duration
 	signingKeyCache        *ccache.Cache
+	configSigners          map[string]*fftypes.SignerRef
+	namespaceSigners       map[string]*fftypes.NamespaceSigner
+	namespaceSignersMux    sync.Mutex
 }
 
 func NewIdentityManager(ctx context.Context, di database.Plugin, ii identity.Plugin, bi blockchain.Plugin, dm data.Manager) (Manager, error) {
@@ -78,6 +86,18 @@ func NewIdentityManager(ctx context.Context, di database.Plugin, ii identity.Plu
 		data:               dm,
 		identityCacheTTL:   config.GetDuration(config.IdentityManagerCacheTTL),
 		signingKeyCacheTTL: config.GetDuration(config.IdentityManagerCacheTTL),
+		configSigners:      make(map[string]*fftypes.SignerRef),
+		namespaceSigners:   make(map[string]*fftypes.NamespaceSigner),
+	}
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		defaultSigner := entry.GetObject("defaultSigner")
+		signerRef := &fftypes.SignerRef{
+			Author: defaultSigner.GetString("author"),
+			Key:    defaultSigner.GetString("key"),
+		}
+		if signerRef.Author != "" || signerRef.Key != "" {
+			im.configSigners[entry.GetString("name")] = signerRef
+		}
 	}
 	// For the identity and signingkey caches, we just treat them all equally sized and the max items
 	im.identityCache = ccache.New(
@@ -128,6 +148,18 @@ func (im *identityManager) NormalizeSigningKey(ctx context.Context, inputKey str
 func (im *identityManager) ResolveInputSigningIdentity(ctx context.Context, namespace string, msgSignerRef *fftypes.SignerRef) (err error) {
 	log.L(ctx).Debugf("Resolving identity input: key='%s' author='%s'", msgSignerRef.Key, msgSignerRef.Author)
 
+	if msgSignerRef.Author == "" && msgSignerRef.Key == "" {
+		// Use the default signer for the namespace if there is one, otherwise the node owner below
+		defaultSigner, err := im.GetNamespaceSigner(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		if defaultSigner != nil {
+			msgSignerRef.Author = defaultSigner.Author
+			msgSignerRef.Key = defaultSigner.Key
+		}
+	}
+
 	var verifier *fftypes.VerifierRef
 	switch {
 	case msgSignerRef.Author == "" && msgSignerRef.Key == "":
@@ -158,11 +190,12 @@ func (im *identityManager) ResolveInputSigningIdentity(ctx context.Context, name
 				return i18n.NewError(ctx, i18n.MsgAuthorRegistrationMismatch, verifier.Value, msgSignerRef.Author, identity.DID)
 			}
 		case msgSignerRef.Author != "":
+			// The key is not registered to any identity, so cannot be used to sign on behalf of the author
 			identity, _, err := im.CachedIdentityLookupMustExist(ctx, msgSignerRef.Author)
 			if err != nil {
 				return err
 			}
-			msgSignerRef.Author = identity.DID
+			return i18n.NewError(ctx, i18n.MsgKeyNotRegisteredToAuthor, verifier.Value, identity.DID)
 		default:
 			return i18n.NewError(ctx, i18n.MsgAuthorMissingForKey, msgSignerRef.Key)
 		}
@@ -482,3 +515,30 @@ func (im *identityManager) CachedVerifierLookup(ctx context.Context, vType fftyp
 	}
 	return verifier, nil
 }
+
+// ValidateProfile checks the profile of an identity against the latest version of the profile datatype broadcast to its
+// namespace, if there is one. The schema comes from the network rather than local config, so every node reaches the same
+// outcome. Node profiles contain the data exchange peer information, so are never subject to a schema.
+// A retryable error is returned if the schema could not be checked - any other error means the profile is invalid.
+func (im *identityManager) ValidateProfile(ctx context.Context, identity *fftypes.Identity) (retry bool, err error) {
+	if identity.Type == fftypes.IdentityTypeNode {
+		return false, nil
+	}
+	fb := database.DatatypeQueryFactory.NewFilter(ctx)
+	datatypes, _, err := im.database.GetDatatypes(ctx, fb.And(
+		fb.Eq("namespace", identity.Namespace),
+		fb.Eq("name", fftypes.IdentityProfileDatatypeName),
+	).Sort("created").Descending().Limit(1))
+	if err != nil {
+		return true, err
+	}
+	if len(datatypes) == 0 {
+		return false, nil
+	}
+	datatype := &fftypes.DatatypeRef{Name: datatypes[0].Name, Version: datatypes[0].Version}
+	retry, err = im.data.ValidateValue(ctx, identity.Namespace, datatype, fftypes.JSONAnyPtr(identity.Profile.String()))
+	if err != nil && !retry {
+		return false, i18n.NewError(ctx, i18n.MsgIdentityProfileInvalid, identity.DID, err)
+	}
+	return retry, err
+}
@@ -53,9 +53,116 @@ func TestNewIdentityManagerMissingDeps(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestValidateProfileNoSchema(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDatatypes", ctx, mock.Anything).Return([]*fftypes.Datatype{}, nil, nil)
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: "ns1"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, retry)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestValidateProfileNode(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: fftypes.SystemNamespace, Type: fftypes.IdentityTypeNode},
+	})
+	assert.NoError(t, err)
+	assert.False(t, retry)
+}
+
+func TestValidateProfileOk(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdm := im.data.(*datamocks.Manager)
+
+	mdi.On("GetDatatypes", ctx, mock.Anything).Return([]*fftypes.Datatype{
+		{Namespace: "ns1", Name: fftypes.IdentityProfileDatatypeName, Version: "2.0"},
+	}, nil, nil)
+	mdm.On("ValidateValue", ctx, "ns1", &fftypes.DatatypeRef{Name: fftypes.IdentityProfileDatatypeName, Version: "2.0"}, mock.MatchedBy(func(value *fftypes.JSONAny) bool {
+		return value.JSONObject().GetString("name") == "org1"
+	})).Return(false, nil)
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: "ns1"},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{"name": "org1"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.False(t, retry)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestValidateProfileInvalid(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdm := im.data.(*datamocks.Manager)
+
+	mdi.On("GetDatatypes", ctx, mock.Anything).Return([]*fftypes.Datatype{
+		{Namespace: "ns1", Name: fftypes.IdentityProfileDatatypeName, Version: "1.0"},
+	}, nil, nil)
+	mdm.On("ValidateValue", ctx, "ns1", mock.Anything, mock.Anything).Return(false, fmt.Errorf("pop"))
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: "ns1", DID: "did:firefly:org/org1"},
+	})
+	assert.Regexp(t, "FF10431.*org1.*pop", err)
+	assert.False(t, retry)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestValidateProfileDatatypeLookupFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDatatypes", ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: "ns1"},
+	})
+	assert.Regexp(t, "pop", err)
+	assert.True(t, retry)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestValidateProfileValidatorRetry(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdm := im.data.(*datamocks.Manager)
+
+	mdi.On("GetDatatypes", ctx, mock.Anything).Return([]*fftypes.Datatype{
+		{Namespace: "ns1", Name: fftypes.IdentityProfileDatatypeName, Version: "1.0"},
+	}, nil, nil)
+	mdm.On("ValidateValue", ctx, "ns1", mock.Anything, mock.Anything).Return(true, fmt.Errorf("pop"))
+
+	retry, err := im.ValidateProfile(ctx, &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{Namespace: "ns1"},
+	})
+	assert.EqualError(t, err, "pop")
+	assert.True(t, retry)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestResolveInputSigningIdentityNoOrgKey(t *testing.T) {
 
 	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(nil, nil)
 
 	msgIdentity := &fftypes.SignerRef{}
 	err := im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
@@ -94,6 +201,7 @@ func TestResolveInputSigningIdentityOrgFallbackOk(t *testing.T) {
 				Type:      fftypes.IdentityTypeOrg,
 			},
 		}, nil)
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(nil, nil)
 
 	msgIdentity := &fftypes.SignerRef{}
 	err := im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
@@ -149,7 +257,7 @@ func TestResolveInputSigningIdentityByKeyOk(t *testing.T) {
 
 }
 
-func TestResolveInputSigningIdentityAnonymousKeyWithAuthorOk(t *testing.T) {
+func TestResolveInputSigningIdentityAnonymousKeyWithAuthorFail(t *testing.T) {
 
 	ctx, im := newTestIdentityManager(t)
 
@@ -177,9 +285,7 @@ func TestResolveInputSigningIdentityAnonymousKeyWithAuthorOk(t *testing.T) {
 		Author: "did:firefly:ns/ns1/myid",
 	}
 	err := im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
-	assert.NoError(t, err)
-	assert.Equal(t, "did:firefly:ns/ns1/myid", msgIdentity.Author)
-	assert.Equal(t, "fullkey123", msgIdentity.Key)
+	assert.Regexp(t, "FF10417.*fullkey123.*did:firefly:ns/ns1/myid", err)
 
 	mbi.AssertExpectations(t)
 	mdi.AssertExpectations(t)
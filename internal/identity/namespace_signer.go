@@ -0,0 +1,94 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// GetNamespaceSigner returns the signer used for requests in the namespace that do not specify one.
+// A signer set through the API takes precedence over one in the predefined namespace config.
+// Returns nil if the namespace uses the node owner.
+func (im *identityManager) GetNamespaceSigner(ctx context.Context, namespace string) (*fftypes.NamespaceSigner, error) {
+	im.namespaceSignersMux.Lock()
+	signer, cached := im.namespaceSigners[namespace]
+	im.namespaceSignersMux.Unlock()
+	if cached {
+		return signer, nil
+	}
+
+	signer, err := im.database.GetNamespaceSigner(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		if configSigner, ok := im.configSigners[namespace]; ok {
+			signer = &fftypes.NamespaceSigner{
+				Namespace: namespace,
+				SignerRef: *configSigner,
+			}
+		}
+	}
+
+	im.namespaceSignersMux.Lock()
+	im.namespaceSigners[namespace] = signer
+	im.namespaceSignersMux.Unlock()
+	return signer, nil
+}
+
+// SetNamespaceSigner resolves the supplied author and/or key in the same way as a request would,
+// and stores the result as the default signer for the namespace
+func (im *identityManager) SetNamespaceSigner(ctx context.Context, namespace string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error) {
+	if err := im.data.VerifyNamespaceExists(ctx, namespace); err != nil {
+		return nil, err
+	}
+	if signerRef.Author == "" && signerRef.Key == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgNamespaceSignerEmpty)
+	}
+	signer := &fftypes.NamespaceSigner{
+		Namespace: namespace,
+		SignerRef: *signerRef,
+	}
+	if err := im.ResolveInputSigningIdentity(ctx, namespace, &signer.SignerRef); err != nil {
+		return nil, err
+	}
+	if err := im.database.UpsertNamespaceSigner(ctx, signer); err != nil {
+		return nil, err
+	}
+	im.clearNamespaceSigner(namespace)
+	log.L(ctx).Infof("Default signer for namespace '%s' set: key='%s' author='%s'", namespace, signer.Key, signer.Author)
+	return signer, nil
+}
+
+// DeleteNamespaceSigner removes the signer set through the API, reverting to the config (if any)
+func (im *identityManager) DeleteNamespaceSigner(ctx context.Context, namespace string) error {
+	if err := im.database.DeleteNamespaceSigner(ctx, namespace); err != nil {
+		return err
+	}
+	im.clearNamespaceSigner(namespace)
+	return nil
+}
+
+func (im *identityManager) clearNamespaceSigner(namespace string) {
+	im.namespaceSignersMux.Lock()
+	delete(im.namespaceSigners, namespace)
+	im.namespaceSignersMux.Unlock()
+}
@@ -0,0 +1,230 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockResolveKey(ctx context.Context, im *identityManager, key, did string) {
+	mbi := im.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("NormalizeSigningKey", ctx, key).Return("full"+key, nil)
+
+	idID := fftypes.NewUUID()
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetVerifierByValue", ctx, fftypes.VerifierTypeEthAddress, fftypes.SystemNamespace, "full"+key).
+		Return((&fftypes.Verifier{
+			Identity:  idID,
+			Namespace: "ns1",
+			VerifierRef: fftypes.VerifierRef{
+				Type:  fftypes.VerifierTypeEthAddress,
+				Value: "full" + key,
+			},
+		}).Seal(), nil)
+	mdi.On("GetIdentityByID", ctx, idID).
+		Return(&fftypes.Identity{
+			IdentityBase: fftypes.IdentityBase{
+				ID:        idID,
+				DID:       did,
+				Namespace: "ns1",
+				Name:      "myid",
+				Type:      fftypes.IdentityTypeCustom,
+			},
+		}, nil)
+}
+
+func TestNamespaceSignerFromConfig(t *testing.T) {
+
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "defaultSigner": map[string]interface{}{"key": "key1"}},
+		{"name": "ns2"},
+	})
+
+	mdi := &databasemocks.Plugin{}
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
+	ctx := context.Background()
+	i, err := NewIdentityManager(ctx, mdi, &identitymocks.Plugin{}, mbi, &datamocks.Manager{})
+	assert.NoError(t, err)
+	im := i.(*identityManager)
+
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(nil, nil).Once()
+	mockResolveKey(ctx, im, "key1", "did:firefly:ns/ns1/myid")
+
+	msgIdentity := &fftypes.SignerRef{}
+	err = im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:ns/ns1/myid", msgIdentity.Author)
+	assert.Equal(t, "fullkey1", msgIdentity.Key)
+
+	// Second lookup is served from the cache
+	signer, err := im.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", signer.Key)
+	assert.Nil(t, signer.Updated)
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestNamespaceSignerFromDB(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(&fftypes.NamespaceSigner{
+		Namespace: "ns1",
+		SignerRef: fftypes.SignerRef{
+			Author: "did:firefly:ns/ns1/myid",
+			Key:    "key1",
+		},
+		Updated: fftypes.Now(),
+	}, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		signer, err := im.GetNamespaceSigner(ctx, "ns1")
+		assert.NoError(t, err)
+		assert.Equal(t, "did:firefly:ns/ns1/myid", signer.Author)
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveInputSigningIdentityNamespaceSignerFail(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	err := im.ResolveInputSigningIdentity(ctx, "ns1", &fftypes.SignerRef{})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestSetNamespaceSignerOk(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdm := im.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", ctx, "ns1").Return(nil)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(nil, nil).Once()
+	mdi.On("UpsertNamespaceSigner", ctx, mock.MatchedBy(func(signer *fftypes.NamespaceSigner) bool {
+		return signer.Namespace == "ns1" && signer.Key == "fullkey1" && signer.Author == "did:firefly:ns/ns1/myid"
+	})).Return(nil)
+	mockResolveKey(ctx, im, "key1", "did:firefly:ns/ns1/myid")
+
+	signer, err := im.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, signer)
+
+	signerRef := &fftypes.SignerRef{Key: "key1"}
+	signer, err = im.SetNamespaceSigner(ctx, "ns1", signerRef)
+	assert.NoError(t, err)
+	assert.Equal(t, "fullkey1", signer.Key)
+	assert.Empty(t, signerRef.Author)
+
+	// The cache is cleared, so the next lookup goes to the DB
+	mdi.On("GetNamespaceSigner", ctx, "ns1").Return(signer, nil).Once()
+	cached, err := im.GetNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, signer, cached)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestSetNamespaceSignerBadNamespace(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdm := im.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", ctx, "ns1").Return(fmt.Errorf("pop"))
+
+	_, err := im.SetNamespaceSigner(ctx, "ns1", &fftypes.SignerRef{Key: "key1"})
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSetNamespaceSignerEmpty(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdm := im.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", ctx, "ns1").Return(nil)
+
+	_, err := im.SetNamespaceSigner(ctx, "ns1", &fftypes.SignerRef{})
+	assert.Regexp(t, "FF10465", err)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSetNamespaceSignerResolveFail(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdm := im.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", ctx, "ns1").Return(nil)
+	mbi := im.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("NormalizeSigningKey", ctx, "key1").Return("", fmt.Errorf("pop"))
+
+	_, err := im.SetNamespaceSigner(ctx, "ns1", &fftypes.SignerRef{Key: "key1"})
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestSetNamespaceSignerUpsertFail(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdm := im.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", ctx, "ns1").Return(nil)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("UpsertNamespaceSigner", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mockResolveKey(ctx, im, "key1", "did:firefly:ns/ns1/myid")
+
+	_, err := im.SetNamespaceSigner(ctx, "ns1", &fftypes.SignerRef{Key: "key1"})
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestDeleteNamespaceSigner(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("DeleteNamespaceSigner", ctx, "ns1").Return(nil).Once()
+	mdi.On("DeleteNamespaceSigner", ctx, "ns1").Return(fmt.Errorf("pop")).Once()
+
+	err := im.DeleteNamespaceSigner(ctx, "ns1")
+	assert.NoError(t, err)
+	err = im.DeleteNamespaceSigner(ctx, "ns1")
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
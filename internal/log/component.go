@@ -0,0 +1,149 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Components that have their own log level, which can be changed at runtime through the admin API
+const (
+	ComponentAggregator   = "aggregator"
+	ComponentBatchManager = "batchmanager"
+	ComponentEthereum     = "ethereum"
+	ComponentFFTokens     = "fftokens"
+	ComponentAPIServer    = "apiserver"
+)
+
+// Components is the list of all components that have their own log level
+var Components = []string{
+	ComponentAggregator,
+	ComponentAPIServer,
+	ComponentBatchManager,
+	ComponentEthereum,
+	ComponentFFTokens,
+}
+
+type component struct {
+	logger   *logrus.Logger
+	override bool
+}
+
+var (
+	componentsMux sync.Mutex
+	components    = map[string]*component{}
+)
+
+// stdFormatter and stdWriter pass through to the standard logger at the time of each log line,
+// so formatting and output configured after a component logger is created still apply to it
+type stdFormatter struct{}
+
+func (stdFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	return logrus.StandardLogger().Formatter.Format(e)
+}
+
+type stdWriter struct{}
+
+func (stdWriter) Write(b []byte) (int, error) {
+	return logrus.StandardLogger().Out.Write(b)
+}
+
+func getComponent(name string) *component {
+	componentsMux.Lock()
+	defer componentsMux.Unlock()
+	c, ok := components[name]
+	if !ok {
+		c = &component{
+			logger: &logrus.Logger{
+				Out:       stdWriter{},
+				Formatter: stdFormatter{},
+				Hooks:     logrus.StandardLogger().Hooks,
+				Level:     logrus.GetLevel(),
+				ExitFunc:  logrus.StandardLogger().ExitFunc,
+			},
+		}
+		components[name] = c
+	}
+	return c
+}
+
+// WithComponent switches the logger in the context to the logger of the named component, keeping
+// the existing fields. Everything logged on the context from then on is subject to the log level
+// of the component.
+func WithComponent(ctx context.Context, name string) context.Context {
+	entry := loggerFromContext(ctx)
+	return WithLogger(ctx, logrus.NewEntry(getComponent(name).logger).WithFields(entry.Data))
+}
+
+// ValidLevel returns true if the level can be set on a component
+func ValidLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "error", "warn", "info", "debug", "trace":
+		return true
+	default:
+		return false
+	}
+}
+
+// SetComponentLevel overrides the log level of a component, leaving the level of everything else unchanged.
+// The level must be valid according to ValidLevel.
+func SetComponentLevel(name, level string) {
+	c := getComponent(name)
+	l, _ := logrus.ParseLevel(strings.ToLower(level))
+	componentsMux.Lock()
+	defer componentsMux.Unlock()
+	c.override = true
+	c.logger.SetLevel(l)
+}
+
+// ResetComponentLevel removes the override from a component, so it follows the global log level again
+func ResetComponentLevel(name string) {
+	c := getComponent(name)
+	componentsMux.Lock()
+	defer componentsMux.Unlock()
+	c.override = false
+	c.logger.SetLevel(logrus.GetLevel())
+}
+
+// ComponentLevel returns the current log level of a component, and whether it is overridden
+func ComponentLevel(name string) (level string, override bool) {
+	c := getComponent(name)
+	componentsMux.Lock()
+	defer componentsMux.Unlock()
+	return levelName(c.logger.GetLevel()), c.override
+}
+
+func levelName(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "warn"
+	}
+	return level.String()
+}
+
+func updateComponentLevels() {
+	componentsMux.Lock()
+	defer componentsMux.Unlock()
+	for _, c := range components {
+		if !c.override {
+			c.logger.SetLevel(logrus.GetLevel())
+		}
+	}
+}
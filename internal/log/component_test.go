@@ -0,0 +1,80 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentLevelOverride(t *testing.T) {
+	var out bytes.Buffer
+	defer logrus.SetOutput(logrus.StandardLogger().Out)
+	logrus.SetOutput(&out)
+	SetLevel("info")
+	defer ResetComponentLevel("ut1")
+
+	ctx := WithComponent(WithLogField(context.Background(), "role", "ut"), "ut1")
+	assert.Equal(t, "ut", L(ctx).Data["role"])
+
+	L(ctx).Debugf("hidden")
+	assert.NotContains(t, out.String(), "hidden")
+	level, override := ComponentLevel("ut1")
+	assert.Equal(t, "info", level)
+	assert.False(t, override)
+
+	SetComponentLevel("ut1", "DEBUG")
+	L(ctx).Debugf("shown")
+	L(context.Background()).Debugf("root")
+	assert.Contains(t, out.String(), "shown")
+	assert.NotContains(t, out.String(), "root")
+	level, override = ComponentLevel("ut1")
+	assert.Equal(t, "debug", level)
+	assert.True(t, override)
+
+	// The override stays when the global level changes
+	SetLevel("error")
+	level, _ = ComponentLevel("ut1")
+	assert.Equal(t, "debug", level)
+
+	ResetComponentLevel("ut1")
+	level, override = ComponentLevel("ut1")
+	assert.Equal(t, "error", level)
+	assert.False(t, override)
+
+	SetLevel("info")
+	level, _ = ComponentLevel("ut1")
+	assert.Equal(t, "info", level)
+}
+
+func TestComponentLevelWarn(t *testing.T) {
+	defer ResetComponentLevel("ut2")
+	SetComponentLevel("ut2", "warn")
+	level, _ := ComponentLevel("ut2")
+	assert.Equal(t, "warn", level)
+}
+
+func TestValidLevel(t *testing.T) {
+	assert.True(t, ValidLevel("Trace"))
+	assert.True(t, ValidLevel("warn"))
+	assert.False(t, ValidLevel("panic"))
+	assert.False(t, ValidLevel(""))
+}
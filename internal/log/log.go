@@ -32,7 +32,8 @@ var (
 )
 
 type (
-	ctxLogKey struct{}
+	ctxLogKey           struct{}
+	ctxCorrelationIDKey struct{}
 )
 
 // WithLogger adds the specified logger to the context
@@ -48,6 +49,23 @@ func WithLogField(ctx context.Context, key, value string) context.Context {
 	return WithLogger(ctx, loggerFromContext(ctx).WithField(key, value))
 }
 
+// WithCorrelationID adds a correlation ID to the context, which is included in all logging on the context,
+// and is recorded on any transactions, operations, messages and events created on the context.
+// An empty ID leaves the context unchanged.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if correlationID == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, ctxCorrelationIDKey{}, correlationID)
+	return WithLogger(ctx, loggerFromContext(ctx).WithField("correlation", correlationID))
+}
+
+// CorrelationID returns the correlation ID in the context, or an empty string if there is none
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(ctxCorrelationIDKey{}).(string)
+	return correlationID
+}
+
 // LoggerFromContext returns the logger for the current context, or no logger if there is no context
 func loggerFromContext(ctx context.Context) *logrus.Entry {
 	logger := ctx.Value(ctxLogKey{})
@@ -57,6 +75,26 @@ func loggerFromContext(ctx context.Context) *logrus.Entry {
 	return logger.(*logrus.Entry)
 }
 
+// WithOperation adds the namespace, transaction ID and operation ID fields to the logger in the context,
+// so all log lines for an operation can be found regardless of the plugin that writes them.
+// Empty values are not added.
+func WithOperation(ctx context.Context, namespace, txID, opID string) context.Context {
+	fields := logrus.Fields{}
+	if namespace != "" {
+		fields["namespace"] = namespace
+	}
+	if txID != "" {
+		fields["txid"] = txID
+	}
+	if opID != "" {
+		fields["opid"] = opID
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+	return WithLogger(ctx, loggerFromContext(ctx).WithFields(fields))
+}
+
 func SetLevel(level string) {
 	switch strings.ToLower(level) {
 	case "error":
@@ -68,6 +106,7 @@ func SetLevel(level string) {
 	default:
 		logrus.SetLevel(logrus.InfoLevel)
 	}
+	updateComponentLevels()
 }
 
 type Formatting struct {
@@ -34,6 +34,18 @@ func TestLogContextLimited(t *testing.T) {
 	assert.Equal(t, "0123456789012345678901234567890123456789012345678901234567890...", L(ctx).Data["myfield"])
 }
 
+func TestCorrelationID(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "my-correlation-id")
+	assert.Equal(t, "my-correlation-id", CorrelationID(ctx))
+	assert.Equal(t, "my-correlation-id", L(ctx).Data["correlation"])
+}
+
+func TestCorrelationIDEmpty(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithCorrelationID(ctx, ""))
+	assert.Equal(t, "", CorrelationID(ctx))
+}
+
 func TestSettingErrorLevel(t *testing.T) {
 	SetLevel("eRrOr")
 	assert.Equal(t, logrus.ErrorLevel, logrus.GetLevel())
@@ -66,3 +78,18 @@ func TestSetFormatting(t *testing.T) {
 	})
 	L(context.Background()).Infof("time in UTC")
 }
+
+func TestWithOperation(t *testing.T) {
+	ctx := WithOperation(context.Background(), "ns1", "tx1", "op1")
+	assert.Equal(t, "ns1", L(ctx).Data["namespace"])
+	assert.Equal(t, "tx1", L(ctx).Data["txid"])
+	assert.Equal(t, "op1", L(ctx).Data["opid"])
+
+	ctx = WithOperation(context.Background(), "", "", "op1")
+	assert.Equal(t, logrus.Fields{"opid": "op1"}, L(ctx).Data)
+}
+
+func TestWithOperationEmpty(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithOperation(ctx, "", "", ""))
+}
@@ -0,0 +1,438 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package messageimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// maxReportedFailures bounds the size of the operation output, for an import where many messages fail
+const maxReportedFailures = 100
+
+// Manager submits many messages from a single request, reporting the progress through an operation
+type Manager interface {
+	fftypes.Named
+
+	// Start resumes the imports that were running when the node stopped, from the last checkpoint of each
+	Start() error
+	// ImportMessages checks the import is within limits, then submits the messages in the background - returning the operation that reports progress
+	ImportMessages(ctx context.Context, ns string, msgs []*fftypes.MessageInOut) (*fftypes.Operation, error)
+	// ImportMessagesUpload parses an NDJSON or CSV upload of messages, then imports them as for ImportMessages
+	ImportMessagesUpload(ctx context.Context, ns string, format fftypes.MessageImportFormat, upload *fftypes.Multipart) (*fftypes.Operation, error)
+}
+
+type importManager struct {
+	ctx              context.Context
+	database         database.Plugin
+	data             data.Manager
+	txHelper         txcommon.Helper
+	broadcast        broadcast.Manager
+	messaging        privatemessaging.Manager
+	concurrency      int
+	maxMessages      int
+	pageSize         int
+	progressInterval time.Duration
+}
+
+// NewImportManager creates the manager for bulk message imports. Imports run on the supplied context, as they outlive the request that starts them
+func NewImportManager(ctx context.Context, di database.Plugin, dm data.Manager, txHelper txcommon.Helper, bm broadcast.Manager, pm privatemessaging.Manager) (Manager, error) {
+	if di == nil || dm == nil || txHelper == nil || bm == nil || pm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	im := &importManager{
+		ctx:              ctx,
+		database:         di,
+		data:             dm,
+		txHelper:         txHelper,
+		broadcast:        bm,
+		messaging:        pm,
+		concurrency:      config.GetInt(config.MessageImportConcurrency),
+		maxMessages:      config.GetInt(config.MessageImportMaxMessages),
+		pageSize:         config.GetInt(config.MessageImportPageSize),
+		progressInterval: config.GetDuration(config.MessageImportProgressInterval),
+	}
+	if im.concurrency < 1 {
+		im.concurrency = 1
+	}
+	if im.pageSize < 1 {
+		im.pageSize = 1
+	}
+	return im, nil
+}
+
+func (im *importManager) Name() string {
+	return "MessageImportManager"
+}
+
+func (im *importManager) Start() error {
+	startupTime := fftypes.Now()
+	var pendingOps []*fftypes.Operation
+	for {
+		fb := database.OperationQueryFactory.NewFilter(im.ctx)
+		filter := fb.And(
+			fb.Eq("type", fftypes.OpTypeMessageImport),
+			fb.Eq("status", fftypes.OpStatusPending),
+			fb.Lt("created", startupTime),
+		).Sort("created").Skip(uint64(len(pendingOps))).Limit(uint64(im.pageSize))
+		page, _, err := im.database.GetOperations(im.ctx, filter)
+		if err != nil {
+			return err
+		}
+		pendingOps = append(pendingOps, page...)
+		if len(page) < im.pageSize {
+			break
+		}
+	}
+	for _, op := range pendingOps {
+		log.L(im.ctx).Infof("Resuming message import %s", op.ID)
+		go im.importMessages(log.WithLogField(im.ctx, "opid", op.ID.String()), op)
+	}
+	return nil
+}
+
+func (im *importManager) ImportMessagesUpload(ctx context.Context, ns string, format fftypes.MessageImportFormat, upload *fftypes.Multipart) (*fftypes.Operation, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(upload.Filename)) {
+		case ".csv":
+			format = fftypes.MessageImportFormatCSV
+		case ".ndjson", ".jsonl", ".json":
+			format = fftypes.MessageImportFormatNDJSON
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgMessageImportUnknownFormat, upload.Filename)
+		}
+	}
+	format = fftypes.MessageImportFormat(strings.ToLower(string(format)))
+	if format != fftypes.MessageImportFormatCSV && format != fftypes.MessageImportFormatNDJSON {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportUnknownFormat, format)
+	}
+	return im.startImport(ctx, ns, format, upload)
+}
+
+func (im *importManager) ImportMessages(ctx context.Context, ns string, msgs []*fftypes.MessageInOut) (op *fftypes.Operation, err error) {
+	if len(msgs) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportEmpty)
+	}
+	if len(msgs) > im.maxMessages {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportTooLarge, im.maxMessages)
+	}
+
+	// The messages are stored in the same way as an NDJSON upload, so the import can be resumed after a restart
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, msg := range msgs {
+		_ = encoder.Encode(msg)
+	}
+	return im.startImport(ctx, ns, fftypes.MessageImportFormatNDJSON, &fftypes.Multipart{
+		Data:     &buf,
+		Filename: "messages.ndjson",
+		Mimetype: "application/x-ndjson",
+	})
+}
+
+// startImport stores the upload as a blob that the import streams from, and that it can restart from if the node stops.
+// The messages are counted as the upload is stored, so an upload that cannot be parsed is rejected before any are submitted.
+func (im *importManager) startImport(ctx context.Context, ns string, format fftypes.MessageImportFormat, upload *fftypes.Multipart) (op *fftypes.Operation, err error) {
+	pr, pw := io.Pipe()
+	counted := make(chan error, 1)
+	var total int
+	go func() {
+		var err error
+		total, err = countMessages(ctx, format, pr, im.maxMessages)
+		// Drain the rest of the upload after a parsing error, so storing it is not blocked
+		_, _ = io.Copy(io.Discard, pr)
+		counted <- err
+	}()
+	stored, err := im.data.UploadBLOB(ctx, ns, &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Data:     io.TeeReader(upload.Data, pw),
+		Filename: upload.Filename,
+		Mimetype: upload.Mimetype,
+	}, true)
+	pw.CloseWithError(err)
+	if countErr := <-counted; err == nil {
+		err = countErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = im.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		txid, err := im.txHelper.SubmitNewTransaction(ctx, ns, fftypes.TransactionTypeMessageImport)
+		if err != nil {
+			return err
+		}
+		op = fftypes.NewOperation(im, ns, txid, fftypes.OpTypeMessageImport)
+		b, _ := json.Marshal(&importInput{
+			Messages: int64(total),
+			Format:   format,
+			Data:     stored.ID,
+		})
+		op.Input = fftypes.JSONAnyPtrBytes(b).JSONObject()
+		return im.database.InsertOperation(ctx, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go im.importMessages(log.WithLogField(im.ctx, "opid", op.ID.String()), op)
+	return op, nil
+}
+
+// importInput is the input of the operation, which has everything needed to resume the import
+type importInput struct {
+	Messages int64                       `json:"messages"`
+	Format   fftypes.MessageImportFormat `json:"format"`
+	Data     *fftypes.UUID               `json:"data"`
+}
+
+func parseImportInput(op *fftypes.Operation) *importInput {
+	var input importInput
+	b, _ := json.Marshal(op.Input)
+	_ = json.Unmarshal(b, &input)
+	return &input
+}
+
+// importTracker accumulates the results from the workers submitting messages
+type importTracker struct {
+	mux     sync.Mutex
+	saveMux sync.Mutex
+	status  fftypes.MessageImportStatus
+}
+
+// newImportTracker restores the counts from the checkpoint of an import that is being resumed.
+// Failures after the checkpoint are dropped, as those rows are submitted again.
+func newImportTracker(op *fftypes.Operation, input *importInput) *importTracker {
+	it := &importTracker{}
+	if op.Output != nil {
+		b, _ := json.Marshal(op.Output)
+		_ = json.Unmarshal(b, &it.status)
+	}
+	it.status.Total = input.Messages
+	cp := it.status.Checkpoint
+	if cp == nil {
+		cp = &fftypes.MessageImportCheckpoint{}
+	}
+	it.status.Submitted = cp.Submitted
+	it.status.Failed = cp.Failed
+	failures := make([]*fftypes.MessageImportFailure, 0, len(it.status.Failures))
+	for _, f := range it.status.Failures {
+		if f.Row <= cp.Row {
+			failures = append(failures, f)
+		}
+	}
+	it.status.Failures = failures
+	return it
+}
+
+func (it *importTracker) record(row int, err error) {
+	it.mux.Lock()
+	defer it.mux.Unlock()
+	if err == nil {
+		it.status.Submitted++
+		return
+	}
+	it.status.Failed++
+	if len(it.status.Failures) < maxReportedFailures {
+		it.status.Failures = append(it.status.Failures, &fftypes.MessageImportFailure{
+			Row:   int64(row),
+			Error: err.Error(),
+		})
+	}
+}
+
+// resumeRow is the number of rows completed at the last checkpoint
+func (it *importTracker) resumeRow() int {
+	it.mux.Lock()
+	defer it.mux.Unlock()
+	if it.status.Checkpoint == nil {
+		return 0
+	}
+	return int(it.status.Checkpoint.Row)
+}
+
+func (it *importTracker) checkpoint(row int) {
+	it.mux.Lock()
+	defer it.mux.Unlock()
+	it.status.Checkpoint = &fftypes.MessageImportCheckpoint{
+		Row:       int64(row),
+		Submitted: it.status.Submitted,
+		Failed:    it.status.Failed,
+	}
+}
+
+func (it *importTracker) output() fftypes.JSONObject {
+	it.mux.Lock()
+	defer it.mux.Unlock()
+	b, _ := json.Marshal(&it.status)
+	return fftypes.JSONAnyPtrBytes(b).JSONObject()
+}
+
+// save writes the progress to the operation. Saves are serialized, so a checkpoint is never overwritten by older progress.
+func (it *importTracker) save(ctx context.Context, txHelper txcommon.Helper, opID *fftypes.UUID, status fftypes.OpStatus, errMsg string) error {
+	it.saveMux.Lock()
+	defer it.saveMux.Unlock()
+	return txHelper.ResolveOperation(ctx, opID, status, errMsg, it.output())
+}
+
+func (im *importManager) importMessages(ctx context.Context, op *fftypes.Operation) {
+	input := parseImportInput(op)
+	tracker := newImportTracker(op, input)
+	l := log.L(ctx)
+	row := tracker.resumeRow()
+	l.Infof("Importing %d messages into namespace '%s' from row %d", tracker.status.Total, op.Namespace, row+1)
+
+	done := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		ticker := time.NewTicker(im.progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := tracker.save(ctx, im.txHelper, op.ID, fftypes.OpStatusPending, ""); err != nil {
+					l.Warnf("Failed to record progress of message import: %s", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := im.submitPages(ctx, op, input, tracker, row)
+	close(done)
+	<-progressDone
+	if ctx.Err() != nil {
+		// The import resumes from the last checkpoint when the node restarts
+		l.Infof("Message import stopped before completion")
+		return
+	}
+	im.completeImport(ctx, op.ID, tracker, err)
+}
+
+// submitPages streams the stored import a page at a time, and checkpoints the operation after each page
+func (im *importManager) submitPages(ctx context.Context, op *fftypes.Operation, input *importInput, tracker *importTracker, row int) error {
+	_, reader, err := im.data.DownloadBLOB(ctx, op.Namespace, input.Data.String())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	mr, err := newMessageReader(ctx, input.Format, reader)
+	if err != nil {
+		return err
+	}
+	for skipped := 0; skipped < row; skipped++ {
+		if _, err := mr.next(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		page := make([]*fftypes.MessageInOut, 0, im.pageSize)
+		for len(page) < im.pageSize {
+			msg, err := mr.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			page = append(page, msg)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		im.submitPage(ctx, op.Namespace, row, page, tracker)
+		if ctx.Err() != nil {
+			// Submissions fail once the context is cancelled, so the page is not checkpointed
+			return ctx.Err()
+		}
+		row += len(page)
+		tracker.checkpoint(row)
+		if err := tracker.save(ctx, im.txHelper, op.ID, fftypes.OpStatusPending, ""); err != nil {
+			log.L(ctx).Warnf("Failed to checkpoint message import: %s", err)
+		}
+	}
+}
+
+// submitPage submits the messages of a page in parallel, returning when they are all complete
+func (im *importManager) submitPage(ctx context.Context, ns string, firstRow int, page []*fftypes.MessageInOut, tracker *importTracker) {
+	rows := make(chan int, len(page))
+	for i := range page {
+		rows <- i
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for i := 0; i < im.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rows {
+				if ctx.Err() != nil {
+					return
+				}
+				tracker.record(firstRow+i+1, im.submitMessage(ctx, ns, page[i]))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (im *importManager) completeImport(ctx context.Context, opID *fftypes.UUID, tracker *importTracker, err error) {
+	status := fftypes.OpStatusSucceeded
+	errMsg := ""
+	s := &tracker.status
+	switch {
+	case err != nil:
+		status = fftypes.OpStatusFailed
+		errMsg = err.Error()
+	case s.Failed > 0:
+		status = fftypes.OpStatusFailed
+		errMsg = i18n.NewError(ctx, i18n.MsgMessageImportFailed, s.Failed, s.Total).Error()
+	}
+	log.L(ctx).Infof("Message import complete: submitted=%d failed=%d total=%d", s.Submitted, s.Failed, s.Total)
+	if err := tracker.save(ctx, im.txHelper, opID, status, errMsg); err != nil {
+		log.L(ctx).Errorf("Failed to record result of message import: %s", err)
+	}
+}
+
+// submitMessage sends each message through the same path as the broadcast and private message APIs,
+// so it is validated and batched in exactly the same way. Messages for a group are private, and all others are broadcast.
+func (im *importManager) submitMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) (err error) {
+	if in.Header.Type == fftypes.MessageTypePrivate || in.Header.Group != nil || in.Group != nil {
+		_, err = im.messaging.SendMessage(ctx, ns, in, false)
+	} else {
+		_, err = im.broadcast.BroadcastMessage(ctx, ns, in, false)
+	}
+	return err
+}
@@ -0,0 +1,434 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package messageimport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestImportManager(t *testing.T) (*importManager, func()) {
+	config.Reset()
+	config.Set(config.MessageImportConcurrency, 0)
+	config.Set(config.MessageImportPageSize, 0)
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mth := &txcommonmocks.Helper{}
+	mbm := &broadcastmocks.Manager{}
+	mpm := &privatemessagingmocks.Manager{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	im, err := NewImportManager(ctx, mdi, mdm, mth, mbm, mpm)
+	assert.NoError(t, err)
+	return im.(*importManager), func() {
+		cancel()
+		mdi.AssertExpectations(t)
+		mdm.AssertExpectations(t)
+		mth.AssertExpectations(t)
+		mbm.AssertExpectations(t)
+		mpm.AssertExpectations(t)
+	}
+}
+
+// mockBlobStore keeps the uploaded import in memory, and returns it for each download
+func mockBlobStore(im *importManager) *fftypes.UUID {
+	mdm := im.data.(*datamocks.Manager)
+	dataID := fftypes.NewUUID()
+	var stored []byte
+	mdm.On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.Anything, true).
+		Run(func(args mock.Arguments) {
+			stored, _ = io.ReadAll(args[3].(*fftypes.Multipart).Data)
+		}).
+		Return(&fftypes.Data{ID: dataID}, nil).Maybe()
+	mdm.On("DownloadBLOB", mock.Anything, "ns1", dataID.String()).
+		Return(&fftypes.Blob{}, func(ctx context.Context, ns, dataID string) io.ReadCloser {
+			return io.NopCloser(bytes.NewReader(stored))
+		}, nil).Maybe()
+	return dataID
+}
+
+func newTestImportOp(dataID *fftypes.UUID, format fftypes.MessageImportFormat, messages int) *fftypes.Operation {
+	op := fftypes.NewOperation(&importManager{}, "ns1", fftypes.NewUUID(), fftypes.OpTypeMessageImport)
+	op.Input = fftypes.JSONObject{
+		"messages": messages,
+		"format":   format,
+		"data":     dataID.String(),
+	}
+	return op
+}
+
+func TestNewImportManagerFail(t *testing.T) {
+	_, err := NewImportManager(context.Background(), nil, nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestName(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	assert.Equal(t, "MessageImportManager", im.Name())
+	assert.Equal(t, 1, im.concurrency)
+	assert.Equal(t, 1, im.pageSize)
+}
+
+func TestImportMessagesBroadcastAndPrivate(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.concurrency = 2
+	im.pageSize = 10
+	mockBlobStore(im)
+
+	txid := fftypes.NewUUID()
+	mth := im.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeMessageImport).Return(txid, nil)
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeMessageImport && op.Transaction.Equals(txid) && op.Status == fftypes.OpStatusPending &&
+			op.Input.GetInt64("messages") == 2 && op.Input.GetString("format") == "ndjson"
+	})).Return(nil)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).Return(nil).Maybe()
+	completed := make(chan fftypes.JSONObject)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusSucceeded, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			completed <- args[4].(fftypes.JSONObject)
+		}).
+		Return(nil)
+
+	im.broadcast.(*broadcastmocks.Manager).On("BroadcastMessage", mock.Anything, "ns1", mock.MatchedBy(func(msg *fftypes.MessageInOut) bool {
+		return msg.Header.Tag == "t1"
+	}), false).Return(&fftypes.Message{}, nil)
+	im.messaging.(*privatemessagingmocks.Manager).On("SendMessage", mock.Anything, "ns1", mock.MatchedBy(func(msg *fftypes.MessageInOut) bool {
+		return msg.Header.Tag == "t2" && msg.Group != nil
+	}), false).Return(&fftypes.Message{}, nil)
+
+	op, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{
+		{Message: fftypes.Message{Header: fftypes.MessageHeader{Tag: "t1"}}},
+		{Message: fftypes.Message{Header: fftypes.MessageHeader{Tag: "t2"}}, Group: &fftypes.InputGroup{}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpTypeMessageImport, op.Type)
+
+	output := <-completed
+	assert.Equal(t, int64(2), output.GetInt64("total"))
+	assert.Equal(t, int64(2), output.GetInt64("submitted"))
+	assert.Equal(t, int64(0), output.GetInt64("failed"))
+	assert.Equal(t, int64(2), output.GetObject("checkpoint").GetInt64("row"))
+}
+
+func TestImportMessagesProgressAndFailures(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.progressInterval = 1
+	mockBlobStore(im)
+
+	mth := im.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeMessageImport).Return(fftypes.NewUUID(), nil)
+	im.database.(*databasemocks.Plugin).On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	progressed := make(chan struct{})
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			select {
+			case <-progressed:
+			default:
+				close(progressed)
+			}
+		}).
+		Return(fmt.Errorf("pop"))
+	completed := make(chan fftypes.JSONObject)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return strings.Contains(errMsg, "FF10508")
+	}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			completed <- args[4].(fftypes.JSONObject)
+		}).
+		Return(nil)
+
+	im.broadcast.(*broadcastmocks.Manager).On("BroadcastMessage", mock.Anything, "ns1", mock.Anything, false).
+		Run(func(args mock.Arguments) {
+			<-progressed
+		}).
+		Return(nil, fmt.Errorf("pop"))
+
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{{}})
+	assert.NoError(t, err)
+
+	output := <-completed
+	assert.Equal(t, int64(1), output.GetInt64("failed"))
+	failures := output.GetObjectArray("failures")
+	assert.Len(t, failures, 1)
+	assert.Equal(t, int64(1), failures[0].GetInt64("row"))
+	assert.Equal(t, "pop", failures[0].GetString("error"))
+}
+
+func TestImportMessagesStoppedLeavesPending(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := mockBlobStore(im)
+	_, err := im.data.UploadBLOB(context.Background(), "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Data: strings.NewReader("{}\n{}\n{}\n{}\n{}\n"),
+	}, true)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mth := im.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.MatchedBy(func(output fftypes.JSONObject) bool {
+		return output.GetObject("checkpoint").GetInt64("row") == 3
+	})).Return(nil).Once()
+	calls := 0
+	im.broadcast.(*broadcastmocks.Manager).On("BroadcastMessage", mock.Anything, "ns1", mock.Anything, false).
+		Run(func(args mock.Arguments) {
+			if calls++; calls == 3 {
+				cancel()
+			}
+		}).
+		Return(&fftypes.Message{}, nil).Times(3)
+
+	// The second page is abandoned after its first message, and is replayed from row 4 on restart
+	im.pageSize = 2
+	op := newTestImportOp(dataID, fftypes.MessageImportFormatNDJSON, 5)
+	op.Output = fftypes.JSONObject{"checkpoint": fftypes.JSONObject{"row": 1}}
+	im.importMessages(ctx, op)
+}
+
+func TestImportMessagesResumeFromCheckpoint(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.pageSize = 10
+	dataID := mockBlobStore(im)
+	_, err := im.data.UploadBLOB(context.Background(), "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Data: strings.NewReader("tag\nt1\nt2\nt3\n"),
+	}, true)
+	assert.NoError(t, err)
+
+	op := newTestImportOp(dataID, fftypes.MessageImportFormatCSV, 3)
+	op.Output = fftypes.JSONObject{
+		"total":     3,
+		"submitted": 1,
+		"failed":    2,
+		"failures": []interface{}{
+			fftypes.JSONObject{"row": 1, "error": "pop"},
+			fftypes.JSONObject{"row": 2, "error": "pop"},
+		},
+		"checkpoint": fftypes.JSONObject{"row": 1, "submitted": 0, "failed": 1},
+	}
+
+	mth := im.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusPending, "", mock.Anything).Return(nil).Maybe()
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return strings.Contains(errMsg, "FF10508")
+	}), mock.MatchedBy(func(output fftypes.JSONObject) bool {
+		return output.GetInt64("submitted") == 2 && output.GetInt64("failed") == 1 && len(output.GetObjectArray("failures")) == 1
+	})).Return(nil)
+	im.broadcast.(*broadcastmocks.Manager).On("BroadcastMessage", mock.Anything, "ns1", mock.MatchedBy(func(msg *fftypes.MessageInOut) bool {
+		return msg.Header.Tag == "t2" || msg.Header.Tag == "t3"
+	}), false).Return(&fftypes.Message{}, nil).Twice()
+
+	im.importMessages(im.ctx, op)
+}
+
+func TestImportMessagesDownloadFail(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := fftypes.NewUUID()
+	im.data.(*datamocks.Manager).On("DownloadBLOB", mock.Anything, "ns1", dataID.String()).Return(nil, nil, fmt.Errorf("pop"))
+	im.txHelper.(*txcommonmocks.Helper).On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, "pop", mock.Anything).Return(fmt.Errorf("pop"))
+
+	im.importMessages(im.ctx, newTestImportOp(dataID, fftypes.MessageImportFormatCSV, 1))
+}
+
+func TestImportMessagesBadFormat(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := mockBlobStore(im)
+	im.txHelper.(*txcommonmocks.Helper).On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return strings.Contains(errMsg, "FF10503")
+	}), mock.Anything).Return(nil)
+
+	im.importMessages(im.ctx, newTestImportOp(dataID, "xml", 1))
+}
+
+func TestImportMessagesCheckpointBeyondEnd(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := mockBlobStore(im)
+	op := newTestImportOp(dataID, fftypes.MessageImportFormatNDJSON, 1)
+	op.Output = fftypes.JSONObject{"checkpoint": fftypes.JSONObject{"row": 1}}
+	im.txHelper.(*txcommonmocks.Helper).On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, "EOF", mock.Anything).Return(nil)
+
+	im.importMessages(im.ctx, op)
+}
+
+func TestImportMessagesInvalidRow(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := mockBlobStore(im)
+	_, err := im.data.UploadBLOB(context.Background(), "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Data: strings.NewReader("{"),
+	}, true)
+	assert.NoError(t, err)
+	im.txHelper.(*txcommonmocks.Helper).On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.MatchedBy(func(errMsg string) bool {
+		return strings.Contains(errMsg, "FF10504")
+	}), mock.Anything).Return(nil)
+
+	im.importMessages(im.ctx, newTestImportOp(dataID, fftypes.MessageImportFormatNDJSON, 1))
+}
+
+func TestStartResumesPendingImports(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	dataID := mockBlobStore(im)
+	_, err := im.data.UploadBLOB(context.Background(), "ns1", &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Data: strings.NewReader("{}\n"),
+	}, true)
+	assert.NoError(t, err)
+	op := newTestImportOp(dataID, fftypes.MessageImportFormatNDJSON, 1)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op}, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil).Once()
+	mth := im.txHelper.(*txcommonmocks.Helper)
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusPending, "", mock.Anything).Return(nil).Maybe()
+	completed := make(chan struct{})
+	mth.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			close(completed)
+		}).
+		Return(nil)
+	im.broadcast.(*broadcastmocks.Manager).On("BroadcastMessage", mock.Anything, "ns1", mock.Anything, false).Return(&fftypes.Message{}, nil)
+
+	err = im.Start()
+	assert.NoError(t, err)
+	<-completed
+}
+
+func TestStartQueryFail(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.database.(*databasemocks.Plugin).On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := im.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportMessagesEmpty(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{})
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestImportMessagesTooLarge(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.maxMessages = 1
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{{}, {}})
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestImportMessagesUploadBlobFail(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.data.(*datamocks.Manager).On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{{}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportMessagesTransactionFail(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	mockBlobStore(im)
+	im.txHelper.(*txcommonmocks.Helper).On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeMessageImport).Return(nil, fmt.Errorf("pop"))
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{{}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportMessagesInsertOperationFail(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	mockBlobStore(im)
+	im.txHelper.(*txcommonmocks.Helper).On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeMessageImport).Return(fftypes.NewUUID(), nil)
+	im.database.(*databasemocks.Plugin).On("InsertOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := im.ImportMessages(context.Background(), "ns1", []*fftypes.MessageInOut{{}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportMessagesUploadFormats(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+	im.maxMessages = 0
+	mockBlobStore(im)
+
+	_, err := im.ImportMessagesUpload(context.Background(), "ns1", "", &fftypes.Multipart{
+		Filename: "messages.CSV",
+		Data:     strings.NewReader("tag\nt1\n"),
+	})
+	assert.Regexp(t, "FF10507", err)
+
+	_, err = im.ImportMessagesUpload(context.Background(), "ns1", "", &fftypes.Multipart{
+		Filename: "messages.jsonl",
+		Data:     strings.NewReader(`{"header":{"tag":"t1"}}`),
+	})
+	assert.Regexp(t, "FF10507", err)
+
+	_, err = im.ImportMessagesUpload(context.Background(), "ns1", "NDJSON", &fftypes.Multipart{
+		Filename: "messages.txt",
+		Data:     strings.NewReader(""),
+	})
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestImportMessagesUploadUnknownFormat(t *testing.T) {
+	im, done := newTestImportManager(t)
+	defer done()
+
+	_, err := im.ImportMessagesUpload(context.Background(), "ns1", "", &fftypes.Multipart{
+		Filename: "messages.txt",
+	})
+	assert.Regexp(t, "FF10503.*messages.txt", err)
+
+	_, err = im.ImportMessagesUpload(context.Background(), "ns1", "xml", &fftypes.Multipart{
+		Filename: "messages.csv",
+	})
+	assert.Regexp(t, "FF10503.*xml", err)
+}
+
+func TestImportTrackerFailuresCapped(t *testing.T) {
+	tracker := &importTracker{}
+	for i := 0; i < maxReportedFailures+1; i++ {
+		tracker.record(i+1, fmt.Errorf("pop"))
+	}
+	assert.Equal(t, int64(maxReportedFailures+1), tracker.status.Failed)
+	assert.Len(t, tracker.status.Failures, maxReportedFailures)
+}
@@ -0,0 +1,214 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package messageimport
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// csvColumn applies the value from one column of a CSV row to the message being built from it
+type csvColumn func(ctx context.Context, msg *fftypes.MessageInOut, value string) error
+
+var csvColumns = map[string]csvColumn{
+	"type": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		msg.Header.Type = fftypes.MessageType(strings.ToLower(value))
+		return nil
+	},
+	"tag": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		msg.Header.Tag = value
+		return nil
+	},
+	"topics": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		msg.Header.Topics = fftypes.NewFFStringArray(strings.Split(value, ",")...)
+		return nil
+	},
+	"cid": func(ctx context.Context, msg *fftypes.MessageInOut, value string) (err error) {
+		msg.Header.CID, err = fftypes.ParseUUID(ctx, value)
+		return err
+	},
+	"author": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		msg.Header.Author = value
+		return nil
+	},
+	"key": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		msg.Header.Key = value
+		return nil
+	},
+	"group": func(ctx context.Context, msg *fftypes.MessageInOut, value string) (err error) {
+		msg.Header.Group, err = fftypes.ParseBytes32(ctx, value)
+		return err
+	},
+	"value": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		// Values that are valid JSON are used as-is, and anything else is imported as a string
+		if json.Valid([]byte(value)) {
+			csvData(msg).Value = fftypes.JSONAnyPtr(value)
+		} else {
+			b, _ := json.Marshal(value)
+			csvData(msg).Value = fftypes.JSONAnyPtrBytes(b)
+		}
+		return nil
+	},
+	"validator": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		csvData(msg).Validator = fftypes.ValidatorType(strings.ToLower(value))
+		return nil
+	},
+	"datatype.name": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		csvDatatype(msg).Name = value
+		return nil
+	},
+	"datatype.version": func(ctx context.Context, msg *fftypes.MessageInOut, value string) error {
+		csvDatatype(msg).Version = value
+		return nil
+	},
+}
+
+// csvData returns the single in-line data item, that a CSV row can supply
+func csvData(msg *fftypes.MessageInOut) *fftypes.DataRefOrValue {
+	if len(msg.InlineData) == 0 {
+		msg.InlineData = fftypes.InlineData{{}}
+	}
+	return msg.InlineData[0]
+}
+
+func csvDatatype(msg *fftypes.MessageInOut) *fftypes.DatatypeRef {
+	d := csvData(msg)
+	if d.Datatype == nil {
+		d.Datatype = &fftypes.DatatypeRef{}
+	}
+	return d.Datatype
+}
+
+// messageReader streams the messages of an import one at a time, so the file is never held in memory.
+// Rows are numbered from 1, excluding the CSV header, and io.EOF is returned after the last message.
+type messageReader interface {
+	next() (*fftypes.MessageInOut, error)
+}
+
+func newMessageReader(ctx context.Context, format fftypes.MessageImportFormat, r io.Reader) (messageReader, error) {
+	switch format {
+	case fftypes.MessageImportFormatCSV:
+		return newCSVReader(ctx, r)
+	case fftypes.MessageImportFormatNDJSON:
+		return &ndjsonReader{ctx: ctx, decoder: json.NewDecoder(r)}, nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportUnknownFormat, format)
+	}
+}
+
+// ndjsonReader reads a stream of JSON messages, conventionally one per line
+type ndjsonReader struct {
+	ctx     context.Context
+	decoder *json.Decoder
+	row     int
+}
+
+func (nr *ndjsonReader) next() (*fftypes.MessageInOut, error) {
+	var msg fftypes.MessageInOut
+	err := nr.decoder.Decode(&msg)
+	if err == io.EOF {
+		return nil, err
+	}
+	nr.row++
+	if err != nil {
+		return nil, i18n.NewError(nr.ctx, i18n.MsgMessageImportInvalidRow, nr.row, err)
+	}
+	return &msg, nil
+}
+
+// csvReader reads a header row naming the columns, followed by one message per row.
+// Empty cells are skipped, so the same file can mix messages with and without data.
+type csvReader struct {
+	ctx     context.Context
+	reader  *csv.Reader
+	columns []csvColumn
+	row     int
+}
+
+func newCSVReader(ctx context.Context, r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportEmpty)
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageImportInvalidRow, 0, err)
+	}
+	columns := make([]csvColumn, len(header))
+	for i, name := range header {
+		column, ok := csvColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, i18n.NewError(ctx, i18n.MsgMessageImportUnknownColumn, name)
+		}
+		columns[i] = column
+	}
+	return &csvReader{ctx: ctx, reader: reader, columns: columns}, nil
+}
+
+func (cr *csvReader) next() (*fftypes.MessageInOut, error) {
+	record, err := cr.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	cr.row++
+	if err != nil {
+		return nil, i18n.NewError(cr.ctx, i18n.MsgMessageImportInvalidRow, cr.row, err)
+	}
+	msg := &fftypes.MessageInOut{}
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		if err := cr.columns[i](cr.ctx, msg, value); err != nil {
+			return nil, i18n.NewError(cr.ctx, i18n.MsgMessageImportInvalidRow, cr.row, err)
+		}
+	}
+	return msg, nil
+}
+
+// countMessages reads the whole import to check every row parses, and that it is within the limit on the number of messages
+func countMessages(ctx context.Context, format fftypes.MessageImportFormat, r io.Reader, maxMessages int) (int, error) {
+	mr, err := newMessageReader(ctx, format, r)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		_, err := mr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if count == maxMessages {
+			return 0, i18n.NewError(ctx, i18n.MsgMessageImportTooLarge, maxMessages)
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, i18n.NewError(ctx, i18n.MsgMessageImportEmpty)
+	}
+	return count, nil
+}
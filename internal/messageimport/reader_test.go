@@ -0,0 +1,154 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package messageimport
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(format fftypes.MessageImportFormat, input string) ([]*fftypes.MessageInOut, error) {
+	mr, err := newMessageReader(context.Background(), format, strings.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*fftypes.MessageInOut, 0)
+	for {
+		msg, err := mr.next()
+		if err == io.EOF {
+			return msgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	msgs, err := readAll(fftypes.MessageImportFormatNDJSON, `{"header":{"tag":"t1"},"data":[{"value":"one"}]}
+{"header":{"tag":"t2","group":"44dc0861e69d9bab17dd5e90a8898c2ea156ad04e5fabf83119cc010486e6c1b"}}
+`)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "t1", msgs[0].Header.Tag)
+	assert.Equal(t, `"one"`, msgs[0].InlineData[0].Value.String())
+	assert.Equal(t, "44dc0861e69d9bab17dd5e90a8898c2ea156ad04e5fabf83119cc010486e6c1b", msgs[1].Header.Group.String())
+}
+
+func TestReadNDJSONInvalid(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatNDJSON, `{"header":{"tag":"t1"}}
+{"header":`)
+	assert.Regexp(t, "FF10504.*row 2", err)
+}
+
+func TestCountMessagesNDJSONTooLarge(t *testing.T) {
+	_, err := countMessages(context.Background(), fftypes.MessageImportFormatNDJSON, strings.NewReader(`{}
+{}`), 1)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestReadCSV(t *testing.T) {
+	msgs, err := readAll(fftypes.MessageImportFormatCSV, `Type,tag,topics,cid,author,key,group,value,validator,datatype.name,datatype.version
+broadcast,t1,"topic1,topic2",c9a6b6a1-8f4d-4ef0-9d5b-0d2b5ed3e6b3,did:firefly:org/org1,0x12345,,"{""a"":1}",JSON,widget,1.0
+private,t2,,,,,44dc0861e69d9bab17dd5e90a8898c2ea156ad04e5fabf83119cc010486e6c1b,hello,,,
+,t3,,,,,,,,,
+`)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 3)
+
+	assert.Equal(t, fftypes.MessageTypeBroadcast, msgs[0].Header.Type)
+	assert.Equal(t, "t1", msgs[0].Header.Tag)
+	assert.Equal(t, fftypes.FFStringArray{"topic1", "topic2"}, msgs[0].Header.Topics)
+	assert.Equal(t, "c9a6b6a1-8f4d-4ef0-9d5b-0d2b5ed3e6b3", msgs[0].Header.CID.String())
+	assert.Equal(t, "did:firefly:org/org1", msgs[0].Header.Author)
+	assert.Equal(t, "0x12345", msgs[0].Header.Key)
+	assert.Nil(t, msgs[0].Header.Group)
+	assert.Len(t, msgs[0].InlineData, 1)
+	assert.Equal(t, `{"a":1}`, msgs[0].InlineData[0].Value.String())
+	assert.Equal(t, fftypes.ValidatorTypeJSON, msgs[0].InlineData[0].Validator)
+	assert.Equal(t, "widget", msgs[0].InlineData[0].Datatype.Name)
+	assert.Equal(t, "1.0", msgs[0].InlineData[0].Datatype.Version)
+
+	assert.Equal(t, fftypes.MessageTypePrivate, msgs[1].Header.Type)
+	assert.Equal(t, "44dc0861e69d9bab17dd5e90a8898c2ea156ad04e5fabf83119cc010486e6c1b", msgs[1].Header.Group.String())
+	assert.Equal(t, `"hello"`, msgs[1].InlineData[0].Value.String())
+
+	assert.Equal(t, "t3", msgs[2].Header.Tag)
+	assert.Empty(t, msgs[2].InlineData)
+}
+
+func TestReadCSVEmpty(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatCSV, "")
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestReadCSVBadHeader(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatCSV, `tag,"bad"quote`)
+	assert.Regexp(t, "FF10504", err)
+}
+
+func TestReadCSVUnknownColumn(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatCSV, "tag,colour\n")
+	assert.Regexp(t, "FF10505.*colour", err)
+}
+
+func TestReadCSVBadRecord(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatCSV, "tag,cid\nt1\n")
+	assert.Regexp(t, "FF10504.*row 1", err)
+}
+
+func TestReadCSVBadValue(t *testing.T) {
+	_, err := readAll(fftypes.MessageImportFormatCSV, "tag,cid\nt1,\nt2,not-a-uuid\n")
+	assert.Regexp(t, "FF10504.*row 2", err)
+}
+
+func TestCountMessagesCSVTooLarge(t *testing.T) {
+	_, err := countMessages(context.Background(), fftypes.MessageImportFormatCSV, strings.NewReader("tag\nt1\nt2\n"), 1)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestCountMessages(t *testing.T) {
+	count, err := countMessages(context.Background(), fftypes.MessageImportFormatCSV, strings.NewReader("tag\nt1\nt2\n"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCountMessagesEmpty(t *testing.T) {
+	_, err := countMessages(context.Background(), fftypes.MessageImportFormatNDJSON, strings.NewReader(""), 10)
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestCountMessagesInvalid(t *testing.T) {
+	_, err := countMessages(context.Background(), fftypes.MessageImportFormatNDJSON, strings.NewReader("{}\n{"), 10)
+	assert.Regexp(t, "FF10504.*row 2", err)
+}
+
+func TestCountMessagesBadHeader(t *testing.T) {
+	_, err := countMessages(context.Background(), fftypes.MessageImportFormatCSV, strings.NewReader("colour\n"), 10)
+	assert.Regexp(t, "FF10505", err)
+}
+
+func TestNewMessageReaderUnknownFormat(t *testing.T) {
+	_, err := newMessageReader(context.Background(), "xml", strings.NewReader(""))
+	assert.Regexp(t, "FF10503", err)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var AggregatorPinsCounter prometheus.Counter
+var EventLoopStalledCounter *prometheus.CounterVec
+
+// AggregatorPinsCounterName is the prometheus metric for tracking the total number of pins processed by the aggregator
+var AggregatorPinsCounterName = "ff_aggregator_pins_total"
+
+// EventLoopStalledCounterName is the prometheus metric for tracking the number of times an event loop was detected as stalled
+var EventLoopStalledCounterName = "ff_event_loop_stalled_total"
+
+var EventLoopLabelName = "loop"
+
+func InitAggregatorMetrics() {
+	AggregatorPinsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: AggregatorPinsCounterName,
+		Help: "Number of pins processed by the aggregator",
+	})
+	EventLoopStalledCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: EventLoopStalledCounterName,
+		Help: "Number of times an event loop made no progress with events waiting, for longer than the watchdog stall timeout",
+	}, []string{EventLoopLabelName})
+}
+
+func RegisterAggregatorMetrics() {
+	registry.MustRegister(AggregatorPinsCounter)
+	registry.MustRegister(EventLoopStalledCounter)
+}
@@ -23,6 +23,7 @@ import (
 var BlockchainTransactionsCounter *prometheus.CounterVec
 var BlockchainQueriesCounter *prometheus.CounterVec
 var BlockchainEventsCounter *prometheus.CounterVec
+var BlockchainTransactionHistogram *prometheus.HistogramVec
 
 // BlockchainTransactionsCounterName is the prometheus metric for tracking the total number of blockchain transactions
 var BlockchainTransactionsCounterName = "ff_blockchain_transactions_total"
@@ -33,9 +34,14 @@ var BlockchainQueriesCounterName = "ff_blockchain_queries_total"
 // BlockchainEventsCounterName is the prometheus metric for tracking the total number of blockchain events
 var BlockchainEventsCounterName = "ff_blockchain_events_total"
 
+// BlockchainTransactionHistogramName is the prometheus metric for tracking the time blockchain operations take to complete - histogram
+var BlockchainTransactionHistogramName = "ff_blockchain_transaction_histogram"
+
 var LocationLabelName = "location"
 var MethodNameLabelName = "methodName"
 var SignatureLabelName = "signature"
+var OperationTypeLabelName = "type"
+var OperationStatusLabelName = "status"
 
 func InitBlockchainMetrics() {
 	BlockchainTransactionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -50,10 +56,17 @@ func InitBlockchainMetrics() {
 		Name: BlockchainEventsCounterName,
 		Help: "Number of blockchain events",
 	}, []string{LocationLabelName, SignatureLabelName})
+	BlockchainTransactionHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: BlockchainTransactionHistogramName,
+		Help: "Histogram of blockchain operations, bucketed by time from submission to success or failure",
+		// Blockchain transactions take much longer than the default buckets allow for
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+	}, []string{OperationTypeLabelName, OperationStatusLabelName})
 }
 
 func RegisterBlockchainMetrics() {
 	registry.MustRegister(BlockchainTransactionsCounter)
 	registry.MustRegister(BlockchainQueriesCounter)
 	registry.MustRegister(BlockchainEventsCounter)
+	registry.MustRegister(BlockchainTransactionHistogram)
 }
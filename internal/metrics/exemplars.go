@@ -0,0 +1,60 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"unicode/utf8"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceIDExemplarLabel is the exemplar label for the correlation ID of the request that submitted the observed item
+var TraceIDExemplarLabel = "trace_id"
+
+// TransactionIDExemplarLabel is the exemplar label for the FireFly transaction of the observed item
+var TransactionIDExemplarLabel = "tx_id"
+
+// observe records a value in a histogram, with an exemplar linking it to the correlation ID and
+// transaction when exemplars are enabled
+func (mm *metricsManager) observe(h prometheus.Observer, value float64, correlationID string, txID *fftypes.UUID) {
+	if mm.exemplarsEnabled {
+		if exemplar := exemplarLabels(correlationID, txID); len(exemplar) > 0 {
+			if eo, ok := h.(prometheus.ExemplarObserver); ok {
+				eo.ObserveWithExemplar(value, exemplar)
+				return
+			}
+		}
+	}
+	h.Observe(value)
+}
+
+// exemplarLabels builds the labels of an exemplar, within the limit on their combined length. The transaction
+// ID is always included, and the correlation ID is included if it fits - as a truncated ID cannot be looked up.
+func exemplarLabels(correlationID string, txID *fftypes.UUID) prometheus.Labels {
+	labels := prometheus.Labels{}
+	remaining := prometheus.ExemplarMaxRunes
+	if txID != nil {
+		labels[TransactionIDExemplarLabel] = txID.String()
+		remaining -= len(TransactionIDExemplarLabel) + len(labels[TransactionIDExemplarLabel])
+	}
+	if correlationID != "" && utf8.ValidString(correlationID) &&
+		len(TraceIDExemplarLabel)+utf8.RuneCountInString(correlationID) <= remaining {
+		labels[TraceIDExemplarLabel] = correlationID
+	}
+	return labels
+}
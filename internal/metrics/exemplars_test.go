@@ -0,0 +1,121 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+// scrapeOpenMetrics returns the metrics in the OpenMetrics format, which is the only format that includes exemplars
+func scrapeOpenMetrics(t *testing.T) string {
+	handler := promhttp.HandlerFor(Registry(), promhttp.HandlerOpts{EnableOpenMetrics: true})
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+	return res.Body.String()
+}
+
+func TestMessageConfirmedExemplar(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.exemplarsEnabled = true
+
+	txID := fftypes.NewUUID()
+	msg := &fftypes.Message{
+		Header:        fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypeBroadcast},
+		CorrelationID: "req-12345",
+	}
+	mm.timeMap[msg.Header.ID.String()] = time.Now()
+	mm.MessageConfirmed(msg, txID, fftypes.EventTypeMessageConfirmed)
+
+	metrics := scrapeOpenMetrics(t)
+	assert.Regexp(t, `ff_broadcast_histogram_bucket\{le="[0-9.]+"\} 1 # \{[^}]*trace_id="req-12345"`, metrics)
+	assert.Regexp(t, `ff_broadcast_histogram_bucket\{le="[0-9.]+"\} 1 # \{[^}]*tx_id="`+txID.String()+`"`, metrics)
+}
+
+func TestMessageConfirmedNoExemplarWhenDisabled(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+
+	msg := &fftypes.Message{
+		Header:        fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypePrivate},
+		CorrelationID: "req-12345",
+	}
+	mm.timeMap[msg.Header.ID.String()] = time.Now()
+	mm.MessageConfirmed(msg, fftypes.NewUUID(), fftypes.EventTypeMessageConfirmed)
+
+	assert.NotRegexp(t, `ff_private_msg_histogram_bucket.*#`, scrapeOpenMetrics(t))
+}
+
+func TestExemplarsEnabledFromConfig(t *testing.T) {
+	config.Reset()
+	config.Set(config.MetricsExemplarsEnabled, true)
+	mm := NewMetricsManager(context.Background()).(*metricsManager)
+	assert.True(t, mm.exemplarsEnabled)
+}
+
+func TestBlockchainOperationCompleted(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.exemplarsEnabled = true
+
+	op := &fftypes.Operation{
+		Type:          fftypes.OpTypeBlockchainPinBatch,
+		Transaction:   fftypes.NewUUID(),
+		CorrelationID: "req-12345",
+		Created:       fftypes.UnixTime(time.Now().Add(-5 * time.Second).Unix()),
+	}
+	mm.BlockchainOperationCompleted(op, fftypes.OpStatusSucceeded)
+	mm.BlockchainOperationCompleted(&fftypes.Operation{Type: fftypes.OpTypeBlockchainInvoke}, fftypes.OpStatusFailed)
+
+	metrics := scrapeOpenMetrics(t)
+	assert.Regexp(t, `ff_blockchain_transaction_histogram_bucket\{status="Succeeded",type="blockchain_pin_batch",le="8.0"\} 1 # \{[^}]*tx_id="`+op.Transaction.String()+`"[^}]*\} [5-8]\.`, metrics)
+	// Operations without a created time are not observed
+	assert.NotRegexp(t, `status="Failed"`, metrics)
+}
+
+func TestExemplarLabels(t *testing.T) {
+	txID := fftypes.NewUUID()
+
+	// A correlation ID that does not fit alongside the transaction ID is omitted
+	longID := strings.Repeat("a", 20)
+	assert.Equal(t, prometheus.Labels{TransactionIDExemplarLabel: txID.String()}, exemplarLabels(longID, txID))
+	assert.Equal(t, prometheus.Labels{TraceIDExemplarLabel: longID}, exemplarLabels(longID, nil))
+	assert.Empty(t, exemplarLabels(strings.Repeat("a", 100), nil))
+	assert.Empty(t, exemplarLabels("\xff", nil))
+}
+
+func TestObserveWithoutExemplarLabels(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.exemplarsEnabled = true
+
+	mm.TransferConfirmed(&fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), Type: fftypes.TokenTransferTypeMint})
+	assert.NotRegexp(t, `ff_mint_histogram_bucket.*#`, scrapeOpenMetrics(t))
+}
@@ -30,12 +30,20 @@ var mutex = &sync.Mutex{}
 type Manager interface {
 	CountBatchPin()
 	MessageSubmitted(msg *fftypes.Message)
-	MessageConfirmed(msg *fftypes.Message, eventType fftypes.FFEnum)
+	MessageConfirmed(msg *fftypes.Message, tx *fftypes.UUID, eventType fftypes.FFEnum)
 	TransferSubmitted(transfer *fftypes.TokenTransfer)
 	TransferConfirmed(transfer *fftypes.TokenTransfer)
+	TransferDropped(pool *fftypes.TokenPool)
 	BlockchainTransaction(location, methodName string)
 	BlockchainQuery(location, methodName string)
 	BlockchainEvent(location, signature string)
+	BlockchainOperationCompleted(op *fftypes.Operation, status fftypes.OpStatus)
+	AggregatorPinsProcessed(count int)
+	EventLoopStalled(loop string)
+	SubscriptionDelivered(ns, name string)
+	SubscriptionRedelivered(ns, name string)
+	SubscriptionStatus(status *fftypes.SubscriptionStatus)
+	SubscriptionDeleted(ns, name string)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -44,9 +52,10 @@ type Manager interface {
 }
 
 type metricsManager struct {
-	ctx            context.Context
-	metricsEnabled bool
-	timeMap        map[string]time.Time
+	ctx              context.Context
+	metricsEnabled   bool
+	exemplarsEnabled bool
+	timeMap          map[string]time.Time
 }
 
 func (mm *metricsManager) Start() error {
@@ -55,9 +64,10 @@ func (mm *metricsManager) Start() error {
 
 func NewMetricsManager(ctx context.Context) Manager {
 	mm := &metricsManager{
-		ctx:            ctx,
-		metricsEnabled: config.GetBool(config.MetricsEnabled),
-		timeMap:        make(map[string]time.Time),
+		ctx:              ctx,
+		metricsEnabled:   config.GetBool(config.MetricsEnabled),
+		exemplarsEnabled: config.GetBool(config.MetricsExemplarsEnabled),
+		timeMap:          make(map[string]time.Time),
 	}
 
 	return mm
@@ -79,20 +89,20 @@ func (mm *metricsManager) MessageSubmitted(msg *fftypes.Message) {
 	}
 }
 
-func (mm *metricsManager) MessageConfirmed(msg *fftypes.Message, eventType fftypes.FFEnum) {
+func (mm *metricsManager) MessageConfirmed(msg *fftypes.Message, tx *fftypes.UUID, eventType fftypes.FFEnum) {
 	timeElapsed := time.Since(mm.GetTime(msg.Header.ID.String())).Seconds()
 	mm.DeleteTime(msg.Header.ID.String())
 
 	switch msg.Header.Type {
 	case fftypes.MessageTypeBroadcast:
-		BroadcastHistogram.Observe(timeElapsed)
+		mm.observe(BroadcastHistogram, timeElapsed, msg.CorrelationID, tx)
 		if eventType == fftypes.EventTypeMessageConfirmed { // Broadcast Confirmed
 			BroadcastConfirmedCounter.Inc()
 		} else if eventType == fftypes.EventTypeMessageRejected { // Broadcast Rejected
 			BroadcastRejectedCounter.Inc()
 		}
 	case fftypes.MessageTypePrivate:
-		PrivateMsgHistogram.Observe(timeElapsed)
+		mm.observe(PrivateMsgHistogram, timeElapsed, msg.CorrelationID, tx)
 		if eventType == fftypes.EventTypeMessageConfirmed { // Private Msg Confirmed
 			PrivateMsgConfirmedCounter.Inc()
 		} else if eventType == fftypes.EventTypeMessageRejected { // Private Msg Rejected
@@ -121,17 +131,21 @@ func (mm *metricsManager) TransferConfirmed(transfer *fftypes.TokenTransfer) {
 
 	switch transfer.Type {
 	case fftypes.TokenTransferTypeMint: // Mint confirmed
-		MintHistogram.Observe(timeElapsed)
+		mm.observe(MintHistogram, timeElapsed, "", transfer.TX.ID)
 		MintConfirmedCounter.Inc()
 	case fftypes.TokenTransferTypeTransfer: // Transfer confirmed
-		TransferHistogram.Observe(timeElapsed)
+		mm.observe(TransferHistogram, timeElapsed, "", transfer.TX.ID)
 		TransferConfirmedCounter.Inc()
 	case fftypes.TokenTransferTypeBurn: // Burn confirmed
-		BurnHistogram.Observe(timeElapsed)
+		mm.observe(BurnHistogram, timeElapsed, "", transfer.TX.ID)
 		BurnConfirmedCounter.Inc()
 	}
 }
 
+func (mm *metricsManager) TransferDropped(pool *fftypes.TokenPool) {
+	TransferDroppedCounter.WithLabelValues(pool.Namespace, pool.Name).Inc()
+}
+
 func (mm *metricsManager) BlockchainTransaction(location, methodName string) {
 	BlockchainTransactionsCounter.WithLabelValues(location, methodName).Inc()
 }
@@ -144,6 +158,42 @@ func (mm *metricsManager) BlockchainEvent(location, signature string) {
 	BlockchainEventsCounter.WithLabelValues(location, signature).Inc()
 }
 
+func (mm *metricsManager) BlockchainOperationCompleted(op *fftypes.Operation, status fftypes.OpStatus) {
+	if op.Created == nil {
+		return
+	}
+	timeElapsed := time.Since(*op.Created.Time()).Seconds()
+	mm.observe(BlockchainTransactionHistogram.WithLabelValues(op.Type.String(), string(status)), timeElapsed, op.CorrelationID, op.Transaction)
+}
+
+func (mm *metricsManager) AggregatorPinsProcessed(count int) {
+	AggregatorPinsCounter.Add(float64(count))
+}
+
+func (mm *metricsManager) EventLoopStalled(loop string) {
+	EventLoopStalledCounter.WithLabelValues(loop).Inc()
+}
+
+func (mm *metricsManager) SubscriptionDelivered(ns, name string) {
+	SubscriptionDeliveredCounter.WithLabelValues(ns, name).Inc()
+}
+
+func (mm *metricsManager) SubscriptionRedelivered(ns, name string) {
+	SubscriptionRedeliveredCounter.WithLabelValues(ns, name).Inc()
+}
+
+func (mm *metricsManager) SubscriptionStatus(status *fftypes.SubscriptionStatus) {
+	SubscriptionLagGauge.WithLabelValues(status.Namespace, status.Name).Set(float64(status.Lag))
+	SubscriptionDeliveryRateGauge.WithLabelValues(status.Namespace, status.Name).Set(status.DeliveryRate)
+}
+
+func (mm *metricsManager) SubscriptionDeleted(ns, name string) {
+	SubscriptionLagGauge.DeleteLabelValues(ns, name)
+	SubscriptionDeliveryRateGauge.DeleteLabelValues(ns, name)
+	SubscriptionDeliveredCounter.DeleteLabelValues(ns, name)
+	SubscriptionRedeliveredCounter.DeleteLabelValues(ns, name)
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
@@ -93,7 +93,7 @@ func TestMessageConfirmedBroadcastSuccess(t *testing.T) {
 	defer cancel()
 	Message.Header.Type = fftypes.MessageTypeBroadcast
 	mm.timeMap[msgID.String()] = time.Now()
-	mm.MessageConfirmed(Message, fftypes.EventTypeMessageConfirmed)
+	mm.MessageConfirmed(Message, nil, fftypes.EventTypeMessageConfirmed)
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
@@ -102,7 +102,7 @@ func TestMessageConfirmedBroadcastRejected(t *testing.T) {
 	defer cancel()
 	Message.Header.Type = fftypes.MessageTypeBroadcast
 	mm.timeMap[msgID.String()] = time.Now()
-	mm.MessageConfirmed(Message, fftypes.EventTypeMessageRejected)
+	mm.MessageConfirmed(Message, nil, fftypes.EventTypeMessageRejected)
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
@@ -111,7 +111,7 @@ func TestMessageConfirmedPrivateSuccess(t *testing.T) {
 	defer cancel()
 	Message.Header.Type = fftypes.MessageTypePrivate
 	mm.timeMap[msgID.String()] = time.Now()
-	mm.MessageConfirmed(Message, fftypes.EventTypeMessageConfirmed)
+	mm.MessageConfirmed(Message, nil, fftypes.EventTypeMessageConfirmed)
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
@@ -120,7 +120,7 @@ func TestMessageConfirmedPrivateRejected(t *testing.T) {
 	defer cancel()
 	Message.Header.Type = fftypes.MessageTypePrivate
 	mm.timeMap[msgID.String()] = time.Now()
-	mm.MessageConfirmed(Message, fftypes.EventTypeMessageRejected)
+	mm.MessageConfirmed(Message, nil, fftypes.EventTypeMessageRejected)
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
@@ -176,6 +176,15 @@ func TestTransferConfirmedMintBurn(t *testing.T) {
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
+func TestTransferDropped(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.TransferDropped(&fftypes.TokenPool{Namespace: "ns1", Name: "pool1"})
+	m, err := TransferDroppedCounter.GetMetricWithLabelValues("ns1", "pool1")
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+}
+
 func TestBlockchainTransaction(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
@@ -209,6 +218,43 @@ func TestBlockchainEvents(t *testing.T) {
 	assert.Equal(t, float64(1), v)
 }
 
+func TestAggregatorPinsProcessed(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorPinsProcessed(3)
+	assert.Equal(t, float64(3), testutil.ToFloat64(AggregatorPinsCounter))
+}
+
+func TestEventLoopStalled(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.EventLoopStalled("aggregator")
+	m, err := EventLoopStalledCounter.GetMetricWith(prometheus.Labels{EventLoopLabelName: "aggregator"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m))
+}
+
+func TestSubscriptionMetrics(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.SubscriptionDelivered("ns1", "sub1")
+	mm.SubscriptionDelivered("ns1", "sub1")
+	mm.SubscriptionRedelivered("ns1", "sub1")
+	mm.SubscriptionStatus(&fftypes.SubscriptionStatus{
+		SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+		Lag:             10,
+		DeliveryRate:    2.5,
+	})
+	assert.Equal(t, float64(2), testutil.ToFloat64(SubscriptionDeliveredCounter.WithLabelValues("ns1", "sub1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(SubscriptionRedeliveredCounter.WithLabelValues("ns1", "sub1")))
+	assert.Equal(t, float64(10), testutil.ToFloat64(SubscriptionLagGauge.WithLabelValues("ns1", "sub1")))
+	assert.Equal(t, float64(2.5), testutil.ToFloat64(SubscriptionDeliveryRateGauge.WithLabelValues("ns1", "sub1")))
+
+	mm.SubscriptionDeleted("ns1", "sub1")
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionLagGauge))
+	assert.Equal(t, 0, testutil.CollectAndCount(SubscriptionDeliveredCounter))
+}
+
 func TestIsMetricsEnabledTrue(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
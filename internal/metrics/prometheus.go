@@ -81,6 +81,8 @@ func initMetricsCollectors() {
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
 	InitBlockchainMetrics()
+	InitAggregatorMetrics()
+	InitSubscriptionMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -94,4 +96,6 @@ func registerMetricsCollectors() {
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
 	RegisterBlockchainMetrics()
+	RegisterAggregatorMetrics()
+	RegisterSubscriptionMetrics()
 }
@@ -0,0 +1,68 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var SubscriptionLagGauge *prometheus.GaugeVec
+var SubscriptionDeliveryRateGauge *prometheus.GaugeVec
+var SubscriptionDeliveredCounter *prometheus.CounterVec
+var SubscriptionRedeliveredCounter *prometheus.CounterVec
+
+// SubscriptionLagGaugeName is the prometheus metric for tracking how many event sequences a subscription is behind the latest event in its namespace
+var SubscriptionLagGaugeName = "ff_subscription_lag"
+
+// SubscriptionDeliveryRateGaugeName is the prometheus metric for tracking the rate events are delivered to a subscription
+var SubscriptionDeliveryRateGaugeName = "ff_subscription_delivery_rate"
+
+// SubscriptionDeliveredCounterName is the prometheus metric for tracking the total number of events delivered to a subscription
+var SubscriptionDeliveredCounterName = "ff_subscription_delivered_total"
+
+// SubscriptionRedeliveredCounterName is the prometheus metric for tracking the total number of events redelivered to a subscription, after a rejection or a reconnect
+var SubscriptionRedeliveredCounterName = "ff_subscription_redelivered_total"
+
+var SubscriptionNamespaceLabelName = "ns"
+var SubscriptionNameLabelName = "subscription"
+
+func InitSubscriptionMetrics() {
+	labels := []string{SubscriptionNamespaceLabelName, SubscriptionNameLabelName}
+	SubscriptionLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: SubscriptionLagGaugeName,
+		Help: "Number of event sequences between the latest event in the namespace and the offset committed by the subscription",
+	}, labels)
+	SubscriptionDeliveryRateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: SubscriptionDeliveryRateGaugeName,
+		Help: "Events per second delivered to the subscription, over the last subscription metrics interval",
+	}, labels)
+	SubscriptionDeliveredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionDeliveredCounterName,
+		Help: "Number of events delivered to the subscription",
+	}, labels)
+	SubscriptionRedeliveredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: SubscriptionRedeliveredCounterName,
+		Help: "Number of events delivered to the subscription more than once",
+	}, labels)
+}
+
+func RegisterSubscriptionMetrics() {
+	registry.MustRegister(SubscriptionLagGauge)
+	registry.MustRegister(SubscriptionDeliveryRateGauge)
+	registry.MustRegister(SubscriptionDeliveredCounter)
+	registry.MustRegister(SubscriptionRedeliveredCounter)
+}
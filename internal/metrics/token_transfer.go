@@ -24,6 +24,7 @@ var TransferSubmittedCounter prometheus.Counter
 var TransferConfirmedCounter prometheus.Counter
 var TransferRejectedCounter prometheus.Counter
 var TransferHistogram prometheus.Histogram
+var TransferDroppedCounter *prometheus.CounterVec
 
 // TransferSubmittedCounterName is the prometheus metric for tracking the total number of transfers submitted
 var TransferSubmittedCounterName = "ff_transfer_submitted_total"
@@ -37,6 +38,12 @@ var TransferRejectedCounterName = "ff_transfer_rejected_total"
 // TransferHistogramName is the prometheus metric for tracking the total number of transfers - histogram
 var TransferHistogramName = "ff_transfer_histogram"
 
+// TransferDroppedCounterName is the prometheus metric for tracking the total number of transfers dropped by the ingest filter of a pool
+var TransferDroppedCounterName = "ff_transfer_dropped_total"
+
+var TransferPoolNamespaceLabelName = "ns"
+var TransferPoolLabelName = "pool"
+
 func InitTokenTransferMetrics() {
 	TransferSubmittedCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: TransferSubmittedCounterName,
@@ -54,6 +61,10 @@ func InitTokenTransferMetrics() {
 		Name: TransferHistogramName,
 		Help: "Histogram of transfers, bucketed by time to finished",
 	})
+	TransferDroppedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: TransferDroppedCounterName,
+		Help: "Number of transfers dropped by the ingest filter of a token pool",
+	}, []string{TransferPoolNamespaceLabelName, TransferPoolLabelName})
 }
 
 func RegisterTokenTransferMetrics() {
@@ -61,4 +72,5 @@ func RegisterTokenTransferMetrics() {
 	registry.MustRegister(TransferConfirmedCounter)
 	registry.MustRegister(TransferRejectedCounter)
 	registry.MustRegister(TransferHistogram)
+	registry.MustRegister(TransferDroppedCounter)
 }
@@ -45,7 +45,7 @@ func (nm *networkMap) GetOrganizationByNameOrID(ctx context.Context, nameOrID st
 		log.L(ctx).Warnf("Identity '%s' (%s) is not an org identity", org.DID, org.ID)
 		return nil, nil
 	}
-	return org, nil
+	return nm.mergePrivateProfile(ctx, org)
 }
 
 func (nm *networkMap) GetOrganizations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error) {
@@ -98,7 +98,7 @@ func (nm *networkMap) GetIdentityByID(ctx context.Context, ns, id string) (*ffty
 	if identity == nil || identity.Namespace != ns {
 		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
 	}
-	return identity, nil
+	return nm.mergePrivateProfile(ctx, identity)
 }
 
 func (nm *networkMap) withVerifiers(ctx context.Context, identity *fftypes.Identity) (*fftypes.IdentityWithVerifiers, error) {
@@ -134,11 +134,11 @@ func (nm *networkMap) GetIdentityByDID(ctx context.Context, did string) (*fftype
 	if err != nil {
 		return nil, err
 	}
-	return identity, nil
+	return nm.mergePrivateProfile(ctx, identity)
 }
 
 func (nm *networkMap) GetIdentityByDIDWithVerifiers(ctx context.Context, did string) (*fftypes.IdentityWithVerifiers, error) {
-	identity, _, err := nm.identity.CachedIdentityLookupMustExist(ctx, did)
+	identity, err := nm.GetIdentityByDID(ctx, did)
 	if err != nil {
 		return nil, err
 	}
@@ -147,11 +147,16 @@ func (nm *networkMap) GetIdentityByDIDWithVerifiers(ctx context.Context, did str
 
 func (nm *networkMap) GetIdentities(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error) {
 	filter.Condition(filter.Builder().Eq("namespace", ns))
-	return nm.database.GetIdentities(ctx, filter)
+	return nm.GetIdentitiesGlobal(ctx, filter)
 }
 
 func (nm *networkMap) GetIdentitiesGlobal(ctx context.Context, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error) {
-	return nm.database.GetIdentities(ctx, filter)
+	identities, res, err := nm.database.GetIdentities(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	identities, err = nm.mergePrivateProfiles(ctx, identities)
+	return identities, res, err
 }
 
 func (nm *networkMap) GetIdentitiesWithVerifiers(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.IdentityWithVerifiers, *database.FilterResult, error) {
@@ -160,7 +165,7 @@ func (nm *networkMap) GetIdentitiesWithVerifiers(ctx context.Context, ns string,
 }
 
 func (nm *networkMap) GetIdentitiesWithVerifiersGlobal(ctx context.Context, filter database.AndFilter) ([]*fftypes.IdentityWithVerifiers, *database.FilterResult, error) {
-	identities, res, err := nm.database.GetIdentities(ctx, filter)
+	identities, res, err := nm.GetIdentitiesGlobal(ctx, filter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -31,6 +31,7 @@ import (
 func TestGetOrganizationByNameOrIDOk(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentityByID", nm.ctx, id).
 		Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: id, Type: fftypes.IdentityTypeOrg}}, nil)
@@ -159,6 +160,7 @@ func TestGetNodes(t *testing.T) {
 func TestGetIdentityByIDOk(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentityByID", nm.ctx, id).
 		Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: id, Type: fftypes.IdentityTypeOrg, Namespace: "ns1"}}, nil)
@@ -214,6 +216,7 @@ func TestGetIdentityByIDBadUUID(t *testing.T) {
 func TestGetIdentityByIDWithVerifiers(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentityByID", nm.ctx, id).
 		Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: id, Type: fftypes.IdentityTypeOrg, Namespace: "ns1"}}, nil)
@@ -231,6 +234,7 @@ func TestGetIdentityByIDWithVerifiers(t *testing.T) {
 func TestGetIdentityByIDWithVerifiersFail(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentityByID", nm.ctx, id).
 		Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: id, Type: fftypes.IdentityTypeOrg, Namespace: "ns1"}}, nil)
@@ -260,6 +264,7 @@ func TestGetIdentitiesGlobal(t *testing.T) {
 func TestGetIdentityVerifiers(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentityByID", nm.ctx, id).
 		Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: id, Type: fftypes.IdentityTypeOrg, Namespace: "ns1"}}, nil)
@@ -337,6 +342,7 @@ func TestGetVerifierByHashBadUUID(t *testing.T) {
 func TestGetVerifierByDIDOk(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	nm.identity.(*identitymanagermocks.Manager).On("CachedIdentityLookupMustExist", nm.ctx, "did:firefly:org/abc").
 		Return(testOrg("abc"), true, nil)
 	id, err := nm.GetIdentityByDID(nm.ctx, "did:firefly:org/abc")
@@ -347,6 +353,7 @@ func TestGetVerifierByDIDOk(t *testing.T) {
 func TestGetVerifierByDIDWithVerifiersOk(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	nm.identity.(*identitymanagermocks.Manager).On("CachedIdentityLookupMustExist", nm.ctx, "did:firefly:org/abc").
 		Return(testOrg("abc"), true, nil)
 	nm.database.(*databasemocks.Plugin).On("GetVerifiers", nm.ctx, mock.Anything).Return([]*fftypes.Verifier{
@@ -383,6 +390,7 @@ func TestGetVerifierByDIDNotErr(t *testing.T) {
 func TestGetOrganizationsWithVerifiers(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id1 := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentities", nm.ctx, mock.Anything).Return([]*fftypes.Identity{
 		{IdentityBase: fftypes.IdentityBase{
@@ -412,6 +420,7 @@ func TestGetOrganizationsWithVerifiersFailLookup(t *testing.T) {
 func TestGetIdentitiesWithVerifiersFailEnrich(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 	id1 := fftypes.NewUUID()
 	nm.database.(*databasemocks.Plugin).On("GetIdentities", nm.ctx, mock.Anything).Return([]*fftypes.Identity{
 		{IdentityBase: fftypes.IdentityBase{
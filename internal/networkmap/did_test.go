@@ -30,6 +30,7 @@ import (
 func TestDIDGenerationOK(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 
 	org1 := testOrg("org1")
 
@@ -120,6 +121,7 @@ func TestDIDGenerationOK(t *testing.T) {
 func TestDIDGenerationGetVerifiersFail(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 
 	org1 := testOrg("org1")
 
@@ -160,6 +162,7 @@ func TestDIDGenerationGetIdentityByDIDFail(t *testing.T) {
 func TestDIDGenerationGetIdentityByDIDFailVerifiers(t *testing.T) {
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
+	nm.database.(*databasemocks.Plugin).On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, nil)
 
 	org1 := testOrg("org1")
 
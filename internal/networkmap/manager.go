@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
@@ -60,12 +61,13 @@ type networkMap struct {
 	database  database.Plugin
 	broadcast broadcast.Manager
 	exchange  dataexchange.Plugin
+	messaging privatemessaging.Manager
 	identity  identity.Manager
 	syncasync syncasync.Bridge
 }
 
-func NewNetworkMap(ctx context.Context, di database.Plugin, bm broadcast.Manager, dx dataexchange.Plugin, im identity.Manager, sa syncasync.Bridge) (Manager, error) {
-	if di == nil || bm == nil || dx == nil || im == nil {
+func NewNetworkMap(ctx context.Context, di database.Plugin, bm broadcast.Manager, pm privatemessaging.Manager, dx dataexchange.Plugin, im identity.Manager, sa syncasync.Bridge) (Manager, error) {
+	if di == nil || bm == nil || pm == nil || dx == nil || im == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 
@@ -73,6 +75,7 @@ func NewNetworkMap(ctx context.Context, di database.Plugin, bm broadcast.Manager
 		ctx:       ctx,
 		database:  di,
 		broadcast: bm,
+		messaging: pm,
 		exchange:  dx,
 		identity:  im,
 		syncasync: sa,
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,15 +36,16 @@ func newTestNetworkmap(t *testing.T) (*networkMap, func()) {
 	mdi := &databasemocks.Plugin{}
 	mbm := &broadcastmocks.Manager{}
 	mdx := &dataexchangemocks.Plugin{}
+	mpm := &privatemessagingmocks.Manager{}
 	mim := &identitymanagermocks.Manager{}
 	msa := &syncasyncmocks.Bridge{}
-	nm, err := NewNetworkMap(ctx, mdi, mbm, mdx, mim, msa)
+	nm, err := NewNetworkMap(ctx, mdi, mbm, mpm, mdx, mim, msa)
 	assert.NoError(t, err)
 	return nm.(*networkMap), cancel
 
 }
 
 func TestNewNetworkMapMissingDep(t *testing.T) {
-	_, err := NewNetworkMap(context.Background(), nil, nil, nil, nil, nil)
+	_, err := NewNetworkMap(context.Background(), nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
@@ -0,0 +1,82 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (nm *networkMap) sendPrivateProfile(ctx context.Context, identity *fftypes.Identity, private *fftypes.IdentityPrivateProfileInput, signer *fftypes.SignerRef, waitConfirm bool) (*fftypes.Message, error) {
+	members := make([]fftypes.MemberInput, len(private.Recipients))
+	for i, recipient := range private.Recipients {
+		members[i] = fftypes.MemberInput{Identity: recipient}
+	}
+	payload, _ := json.Marshal(&fftypes.IdentityPrivateProfile{
+		Identity: identity.ID,
+		Profile:  private.Profile,
+	})
+	return nm.messaging.SendMessage(ctx, identity.Namespace, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Type:      fftypes.MessageTypePrivate,
+				Tag:       fftypes.SystemTagIdentityPrivateProfile,
+				SignerRef: *signer,
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtrBytes(payload)},
+		},
+		Group: &fftypes.InputGroup{
+			Members: members,
+		},
+	}, waitConfirm)
+}
+
+// mergePrivateProfile returns a copy of the identity with any private profile fields this node
+// has received from the identity merged over its broadcast profile
+func (nm *networkMap) mergePrivateProfile(ctx context.Context, identity *fftypes.Identity) (*fftypes.Identity, error) {
+	private, err := nm.database.GetIdentityPrivateProfile(ctx, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+	if private == nil || private.Author != identity.DID || len(private.Profile) == 0 {
+		return identity, nil
+	}
+	merged := *identity
+	merged.Profile = make(fftypes.JSONObject, len(identity.Profile)+len(private.Profile))
+	for k, v := range identity.Profile {
+		merged.Profile[k] = v
+	}
+	for k, v := range private.Profile {
+		merged.Profile[k] = v
+	}
+	return &merged, nil
+}
+
+func (nm *networkMap) mergePrivateProfiles(ctx context.Context, identities []*fftypes.Identity) ([]*fftypes.Identity, error) {
+	for i, identity := range identities {
+		merged, err := nm.mergePrivateProfile(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+		identities[i] = merged
+	}
+	return identities, nil
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendPrivateProfile(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+	signer := &fftypes.SignerRef{Author: identity.DID, Key: "0x12345"}
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		var private fftypes.IdentityPrivateProfile
+		err := in.InlineData[0].Value.Unmarshal(nm.ctx, &private)
+		assert.NoError(t, err)
+		return in.Header.Type == fftypes.MessageTypePrivate &&
+			in.Header.Tag == fftypes.SystemTagIdentityPrivateProfile &&
+			in.Header.Author == identity.DID &&
+			in.Group.Members[0].Identity == "did:firefly:org/org2" &&
+			private.Identity.Equals(identity.ID) &&
+			private.Profile.GetString("email") == "org1@example.com"
+	}), true).Return(&fftypes.Message{}, nil)
+
+	_, err := nm.sendPrivateProfile(nm.ctx, identity, &fftypes.IdentityPrivateProfileInput{
+		Profile:    fftypes.JSONObject{"email": "org1@example.com"},
+		Recipients: []string{"did:firefly:org/org2"},
+	}, signer, true)
+	assert.NoError(t, err)
+
+	mpm.AssertExpectations(t)
+}
+
+func TestMergePrivateProfile(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityPrivateProfile", nm.ctx, identity.ID).Return(&fftypes.IdentityPrivateProfile{
+		Identity: identity.ID,
+		Author:   identity.DID,
+		Profile:  fftypes.JSONObject{"some": "override", "email": "org1@example.com"},
+	}, nil)
+
+	merged, err := nm.mergePrivateProfile(nm.ctx, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, "override", merged.Profile.GetString("some"))
+	assert.Equal(t, "org1@example.com", merged.Profile.GetString("email"))
+	assert.Equal(t, "profiledata", identity.Profile.GetString("some"))
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMergePrivateProfileWrongAuthor(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityPrivateProfile", nm.ctx, identity.ID).Return(&fftypes.IdentityPrivateProfile{
+		Identity: identity.ID,
+		Author:   "did:firefly:org/org2",
+		Profile:  fftypes.JSONObject{"some": "override"},
+	}, nil)
+
+	merged, err := nm.mergePrivateProfile(nm.ctx, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, identity, merged)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMergePrivateProfilesFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityPrivateProfile", nm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.mergePrivateProfiles(nm.ctx, []*fftypes.Identity{testOrg("org1")})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
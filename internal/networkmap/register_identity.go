@@ -66,6 +66,25 @@ func (nm *networkMap) RegisterIdentity(ctx context.Context, ns string, dto *ffty
 
 	identity.DID, _ = identity.GenerateDID(ctx)
 
+	if _, err := nm.identity.ValidateProfile(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	// Private profile fields are sent by the identity itself, so the claim must be confirmed first
+	if dto.Private != nil {
+		if identity.Type == fftypes.IdentityTypeNode {
+			return nil, i18n.NewError(ctx, i18n.MsgPrivateProfileNodeIdentity)
+		}
+		if !waitConfirm {
+			return nil, i18n.NewError(ctx, i18n.MsgPrivateProfileNeedsConfirm)
+		}
+	}
+
+	// Pinning keys are authorized by the parent identity, so must be registered as a child
+	if dto.Pinning && (parent == nil || identity.Type == fftypes.IdentityTypeNode) {
+		return nil, i18n.NewError(ctx, i18n.MsgPinningKeyNotAllowed)
+	}
+
 	// Verify the chain
 	immediateParent, _, err := nm.identity.VerifyIdentityChain(ctx, identity)
 	if err != nil {
@@ -98,22 +117,30 @@ func (nm *networkMap) RegisterIdentity(ctx context.Context, ns string, dto *ffty
 	}
 
 	if waitConfirm {
-		return nm.syncasync.WaitForIdentity(ctx, identity.Namespace, identity.ID, func(ctx context.Context) error {
-			return nm.sendIdentityRequest(ctx, identity, claimSigner, parentSigner)
+		identity, err = nm.syncasync.WaitForIdentity(ctx, identity.Namespace, identity.ID, func(ctx context.Context) error {
+			return nm.sendIdentityRequest(ctx, identity, dto.Pinning, claimSigner, parentSigner)
 		})
+		if err != nil || dto.Private == nil {
+			return identity, err
+		}
+		if _, err = nm.sendPrivateProfile(ctx, identity, dto.Private, claimSigner, true); err != nil {
+			return nil, err
+		}
+		return nm.mergePrivateProfile(ctx, identity)
 	}
-	err = nm.sendIdentityRequest(ctx, identity, claimSigner, parentSigner)
+	err = nm.sendIdentityRequest(ctx, identity, dto.Pinning, claimSigner, parentSigner)
 	if err != nil {
 		return nil, err
 	}
 	return identity, nil
 }
 
-func (nm *networkMap) sendIdentityRequest(ctx context.Context, identity *fftypes.Identity, claimSigner *fftypes.SignerRef, parentSigner *fftypes.SignerRef) error {
+func (nm *networkMap) sendIdentityRequest(ctx context.Context, identity *fftypes.Identity, pinning bool, claimSigner *fftypes.SignerRef, parentSigner *fftypes.SignerRef) error {
 
 	// Send the claim - we disable the check on the DID author here, as we are registering the identity so it will not exist
 	claimMsg, err := nm.broadcast.BroadcastIdentityClaim(ctx, identity.Namespace, &fftypes.IdentityClaim{
 		Identity: identity,
+		Pinning:  pinning,
 	}, claimSigner, fftypes.SystemTagIdentityClaim, false)
 	if err != nil {
 		return err
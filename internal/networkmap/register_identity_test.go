@@ -23,7 +23,9 @@ import (
 
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +40,7 @@ func TestRegisterIdentityOrgWithParentOk(t *testing.T) {
 	parentIdentity := testOrg("parent1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentIdentity, false, nil)
 	mim.On("ResolveIdentitySigner", nm.ctx, parentIdentity).Return(&fftypes.SignerRef{
 		Key: "0x23456",
@@ -84,6 +87,7 @@ func TestRegisterIdentityOrgWithParentWaitConfirmOk(t *testing.T) {
 	parentIdentity := testOrg("parent1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentIdentity, false, nil)
 	mim.On("ResolveIdentitySigner", nm.ctx, parentIdentity).Return(&fftypes.SignerRef{
 		Key: "0x23456",
@@ -141,6 +145,7 @@ func TestRegisterIdentityCustomWithParentFail(t *testing.T) {
 	parentIdentity := testOrg("parent1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentIdentity, false, nil)
 	mim.On("CachedIdentityLookupMustExist", nm.ctx, "did:firefly:org/parent1").Return(&fftypes.Identity{
 		IdentityBase: fftypes.IdentityBase{
@@ -190,6 +195,7 @@ func TestRegisterIdentityGetParentMsgFail(t *testing.T) {
 	parentIdentity := testOrg("parent1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentIdentity, false, nil)
 	mim.On("ResolveIdentitySigner", nm.ctx, parentIdentity).Return(nil, fmt.Errorf("pop"))
 
@@ -209,6 +215,7 @@ func TestRegisterIdentityRootBroadcastFail(t *testing.T) {
 	defer cancel()
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(nil, false, nil)
 
 	mbm := nm.broadcast.(*broadcastmocks.Manager)
@@ -231,12 +238,70 @@ func TestRegisterIdentityRootBroadcastFail(t *testing.T) {
 	mbm.AssertExpectations(t)
 }
 
+func TestRegisterIdentityPinningKeyOk(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	parentIdentity := testOrg("parent1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentIdentity, false, nil)
+	mim.On("ResolveIdentitySigner", nm.ctx, parentIdentity).Return(&fftypes.SignerRef{
+		Key: "0x23456",
+	}, nil)
+
+	mockMsg1 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mockMsg2 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+
+	mbm.On("BroadcastIdentityClaim", nm.ctx,
+		"ns1",
+		mock.MatchedBy(func(ic *fftypes.IdentityClaim) bool {
+			return ic.Pinning
+		}),
+		mock.Anything,
+		fftypes.SystemTagIdentityClaim, false).Return(mockMsg1, nil)
+	mbm.On("BroadcastDefinition", nm.ctx,
+		"ns1",
+		mock.AnythingOfType("*fftypes.IdentityVerification"),
+		mock.Anything,
+		fftypes.SystemTagIdentityVerification, false).Return(mockMsg2, nil)
+
+	_, err := nm.RegisterIdentity(nm.ctx, "ns1", &fftypes.IdentityCreateDTO{
+		Name:    "pinner1",
+		Key:     "0x12345",
+		Parent:  fftypes.NewUUID().String(),
+		Pinning: true,
+	}, false)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestRegisterIdentityPinningKeyNoParent(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+	nm.identity.(*identitymanagermocks.Manager).On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+
+	_, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name:    "org1",
+		Key:     "0x12345",
+		Pinning: true,
+	}, false)
+	assert.Regexp(t, "FF10407", err)
+}
+
 func TestRegisterIdentityMissingKey(t *testing.T) {
 
 	nm, cancel := newTestNetworkmap(t)
 	defer cancel()
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(nil, false, nil)
 
 	_, err := nm.RegisterIdentity(nm.ctx, "ns1", &fftypes.IdentityCreateDTO{
@@ -254,6 +319,7 @@ func TestRegisterIdentityVerifyFail(t *testing.T) {
 	defer cancel()
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(nil, false, fmt.Errorf("pop"))
 
 	_, err := nm.RegisterIdentity(nm.ctx, "ns1", &fftypes.IdentityCreateDTO{
@@ -281,3 +347,125 @@ func TestRegisterIdentityBadParent(t *testing.T) {
 
 	mim.AssertExpectations(t)
 }
+
+func TestRegisterIdentityPrivateProfileWaitConfirmOk(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(nil, false, nil)
+
+	confirmed := testOrg("org1")
+	msa := nm.syncasync.(*syncasyncmocks.Bridge)
+	msa.On("WaitForIdentity", nm.ctx, fftypes.SystemNamespace, mock.Anything, mock.Anything).Return(confirmed, nil)
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Author == confirmed.DID && in.Header.Key == "0x12345"
+	}), true).Return(&fftypes.Message{}, nil)
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityPrivateProfile", nm.ctx, confirmed.ID).Return(&fftypes.IdentityPrivateProfile{
+		Identity: confirmed.ID,
+		Author:   confirmed.DID,
+		Profile:  fftypes.JSONObject{"email": "org1@example.com"},
+	}, nil)
+
+	org, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name: "org1",
+		Key:  "0x12345",
+		Private: &fftypes.IdentityPrivateProfileInput{
+			Profile:    fftypes.JSONObject{"email": "org1@example.com"},
+			Recipients: []string{"did:firefly:org/org2"},
+		},
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "org1@example.com", org.Profile.GetString("email"))
+
+	mim.AssertExpectations(t)
+	msa.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestRegisterIdentityPrivateProfileSendFail(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(nil, false, nil)
+
+	msa := nm.syncasync.(*syncasyncmocks.Bridge)
+	msa.On("WaitForIdentity", nm.ctx, fftypes.SystemNamespace, mock.Anything, mock.Anything).Return(testOrg("org1"), nil)
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name:    "org1",
+		Key:     "0x12345",
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, true)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	msa.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
+func TestRegisterIdentityPrivateProfileNoConfirm(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+
+	_, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name:    "org1",
+		Key:     "0x12345",
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, false)
+	assert.Regexp(t, "FF10432", err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestRegisterIdentityPrivateProfileNode(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+
+	_, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name:    "node1",
+		Type:    fftypes.IdentityTypeNode,
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, true)
+	assert.Regexp(t, "FF10433", err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestRegisterIdentityInvalidProfile(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, fmt.Errorf("pop"))
+
+	_, err := nm.RegisterIdentity(nm.ctx, fftypes.SystemNamespace, &fftypes.IdentityCreateDTO{
+		Name: "org1",
+		Key:  "0x12345",
+	}, false)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+}
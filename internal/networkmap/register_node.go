@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -50,6 +51,17 @@ func (nm *networkMap) RegisterNode(ctx context.Context, waitConfirm bool) (ident
 		return nil, err
 	}
 	nodeRequest.Profile = dxInfo
+	// Advertise the network protocol versions we support, so peers can negotiate compatible batch encodings with us
+	nodeRequest.Profile["protocolVersion"] = fftypes.ProtocolVersionLatest
+
+	// Advertise the key peers use to verify the delivery acknowledgements we send them
+	ackKey, err := privatemessaging.LoadDeliveryAckSigningKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ackKey != nil {
+		nodeRequest.Profile[fftypes.DeliveryAckKeyProfileKey] = privatemessaging.DeliveryAckPublicKey(ackKey)
+	}
 
 	return nm.RegisterIdentity(ctx, fftypes.SystemNamespace, nodeRequest, waitConfirm)
 }
@@ -17,7 +17,12 @@
 package networkmap
 
 import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"path"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -41,6 +46,7 @@ func TestRegisterNodeOk(t *testing.T) {
 	parentOrg := testOrg("org1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("GetNodeOwnerOrg", nm.ctx).Return(parentOrg, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentOrg, false, nil)
 	signerRef := &fftypes.SignerRef{Key: "0x23456"}
@@ -63,6 +69,69 @@ func TestRegisterNodeOk(t *testing.T) {
 	node, err := nm.RegisterNode(nm.ctx, false)
 	assert.NoError(t, err)
 	assert.Equal(t, *mockMsg.Header.ID, *node.Messages.Claim)
+	assert.Equal(t, fftypes.ProtocolVersionLatest, node.Profile["protocolVersion"])
+
+}
+
+func TestRegisterNodeDeliveryAckKey(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	b, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	keyFile := path.Join(t.TempDir(), "ack.key")
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}), 0600)
+	assert.NoError(t, err)
+
+	config.Set(config.OrgKey, "0x23456")
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+	config.Set(config.PrivateMessagingDeliveryAcksSigningKeyFile, keyFile)
+
+	parentOrg := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(parentOrg, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentOrg, false, nil)
+	signerRef := &fftypes.SignerRef{Key: "0x23456"}
+	mim.On("ResolveIdentitySigner", nm.ctx, parentOrg).Return(signerRef, nil)
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer1"}, nil)
+
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastIdentityClaim", nm.ctx,
+		fftypes.SystemNamespace,
+		mock.AnythingOfType("*fftypes.IdentityClaim"),
+		signerRef,
+		fftypes.SystemTagIdentityClaim, false).Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+
+	node, err := nm.RegisterNode(nm.ctx, false)
+	assert.NoError(t, err)
+	ack := &fftypes.DeliveryAck{Message: fftypes.NewUUID(), State: fftypes.MessageStateConfirmed}
+	ack.Sign(priv)
+	assert.True(t, ack.VerifySignature(node.Profile.GetString(fftypes.DeliveryAckKeyProfileKey)))
+
+}
+
+func TestRegisterNodeDeliveryAckKeyFail(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org1"), nil)
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer1"}, nil)
+
+	_, err := nm.RegisterNode(nm.ctx, false)
+	assert.Regexp(t, "FF10558", err)
 
 }
 
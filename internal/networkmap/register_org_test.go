@@ -60,6 +60,7 @@ func TestRegisterNodeOrgOk(t *testing.T) {
 	config.Set(config.NodeDescription, "Node 1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("GetNodeOwnerBlockchainKey", nm.ctx).Return(&fftypes.VerifierRef{
 		Value: "0x12345",
 	}, nil)
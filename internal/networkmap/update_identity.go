@@ -42,6 +42,10 @@ func (nm *networkMap) updateIdentityID(ctx context.Context, ns string, id *fftyp
 		return nil, i18n.NewError(ctx, i18n.Msg404NoResult)
 	}
 
+	if dto.Private != nil && identity.Type == fftypes.IdentityTypeNode {
+		return nil, i18n.NewError(ctx, i18n.MsgPrivateProfileNodeIdentity)
+	}
+
 	// Resolve the signer of the original claim
 	updateSigner, err := nm.identity.ResolveIdentitySigner(ctx, identity)
 	if err != nil {
@@ -52,6 +56,9 @@ func (nm *networkMap) updateIdentityID(ctx context.Context, ns string, id *fftyp
 	if err := identity.Validate(ctx); err != nil {
 		return nil, err
 	}
+	if _, err := nm.identity.ValidateProfile(ctx, identity); err != nil {
+		return nil, err
+	}
 
 	// Send the update
 	updateMsg, err := nm.broadcast.BroadcastDefinition(ctx, identity.Namespace, &fftypes.IdentityUpdate{
@@ -63,5 +70,14 @@ func (nm *networkMap) updateIdentityID(ctx context.Context, ns string, id *fftyp
 	}
 	identity.Messages.Update = updateMsg.Header.ID
 
+	if dto.Private != nil {
+		if _, err = nm.sendPrivateProfile(ctx, identity, dto.Private, updateSigner, waitConfirm); err != nil {
+			return nil, err
+		}
+		if waitConfirm {
+			return nm.mergePrivateProfile(ctx, identity)
+		}
+	}
+
 	return identity, err
 }
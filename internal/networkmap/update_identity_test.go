@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +37,7 @@ func TestUpdateIdentityProfileOk(t *testing.T) {
 	identity := testOrg("org1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
 	signerRef := &fftypes.SignerRef{Key: "0x12345"}
 	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(signerRef, nil)
@@ -71,6 +74,7 @@ func TestUpdateIdentityProfileBroadcastFail(t *testing.T) {
 	identity := testOrg("org1")
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
 	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
 	signerRef := &fftypes.SignerRef{Key: "0x12345"}
 	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(signerRef, nil)
@@ -192,3 +196,148 @@ func TestUpdateIdentityProfileBadID(t *testing.T) {
 	_, err := nm.UpdateIdentity(nm.ctx, "ns1", "badness", &fftypes.IdentityUpdateDTO{}, true)
 	assert.Regexp(t, "FF10142", err)
 }
+
+func TestUpdateIdentityPrivateProfileWaitConfirmOk(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
+	signerRef := &fftypes.SignerRef{Author: identity.DID, Key: "0x12345"}
+	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(signerRef, nil)
+
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinition", nm.ctx, fftypes.SystemNamespace, mock.AnythingOfType("*fftypes.IdentityUpdate"), signerRef, fftypes.SystemTagIdentityUpdate, true).
+		Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Author == identity.DID && in.Header.Key == "0x12345"
+	}), true).Return(&fftypes.Message{}, nil)
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityPrivateProfile", nm.ctx, identity.ID).Return(&fftypes.IdentityPrivateProfile{
+		Identity: identity.ID,
+		Author:   identity.DID,
+		Profile:  fftypes.JSONObject{"email": "org1@example.com"},
+	}, nil)
+
+	org, err := nm.UpdateIdentity(nm.ctx, identity.Namespace, identity.ID.String(), &fftypes.IdentityUpdateDTO{
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{"new": "profile"},
+		},
+		Private: &fftypes.IdentityPrivateProfileInput{
+			Profile:    fftypes.JSONObject{"email": "org1@example.com"},
+			Recipients: []string{"did:firefly:org/org2"},
+		},
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "profile", org.Profile.GetString("new"))
+	assert.Equal(t, "org1@example.com", org.Profile.GetString("email"))
+
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateIdentityPrivateProfileNoWait(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
+	signerRef := &fftypes.SignerRef{Author: identity.DID, Key: "0x12345"}
+	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(signerRef, nil)
+
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinition", nm.ctx, fftypes.SystemNamespace, mock.AnythingOfType("*fftypes.IdentityUpdate"), signerRef, fftypes.SystemTagIdentityUpdate, false).
+		Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.Anything, false).Return(&fftypes.Message{}, nil)
+
+	_, err := nm.UpdateIdentity(nm.ctx, identity.Namespace, identity.ID.String(), &fftypes.IdentityUpdateDTO{
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, false)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
+func TestUpdateIdentityPrivateProfileSendFail(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, nil)
+	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
+	signerRef := &fftypes.SignerRef{Author: identity.DID, Key: "0x12345"}
+	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(signerRef, nil)
+
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinition", nm.ctx, fftypes.SystemNamespace, mock.AnythingOfType("*fftypes.IdentityUpdate"), signerRef, fftypes.SystemTagIdentityUpdate, false).
+		Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+
+	mpm := nm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", nm.ctx, fftypes.SystemNamespace, mock.Anything, false).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.UpdateIdentity(nm.ctx, identity.Namespace, identity.ID.String(), &fftypes.IdentityUpdateDTO{
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, false)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
+func TestUpdateIdentityPrivateProfileNode(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("node1")
+	identity.Type = fftypes.IdentityTypeNode
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
+
+	_, err := nm.UpdateIdentity(nm.ctx, identity.Namespace, identity.ID.String(), &fftypes.IdentityUpdateDTO{
+		Private: &fftypes.IdentityPrivateProfileInput{},
+	}, false)
+	assert.Regexp(t, "FF10433", err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestUpdateIdentityInvalidProfile(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	identity := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("ValidateProfile", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(false, fmt.Errorf("pop"))
+	mim.On("CachedIdentityLookupByID", nm.ctx, identity.ID).Return(identity, nil)
+	mim.On("ResolveIdentitySigner", nm.ctx, identity).Return(&fftypes.SignerRef{Key: "0x12345"}, nil)
+
+	_, err := nm.UpdateIdentity(nm.ctx, identity.Namespace, identity.ID.String(), &fftypes.IdentityUpdateDTO{}, false)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+}
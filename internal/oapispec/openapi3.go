@@ -241,6 +241,11 @@ func addRoute(ctx context.Context, doc *openapi3.T, route *Route) {
 		addParam(ctx, op, "query", q.Name, q.Default, example, q.Description, q.Deprecated)
 	}
 	addParam(ctx, op, "header", "Request-Timeout", config.GetString(config.APIRequestTimeout), "", i18n.MsgRequestTimeoutDesc, false)
+	addParam(ctx, op, "header", CorrelationIDHeader, "", "", i18n.MsgCorrelationIDDesc, false)
+	if route.Method != http.MethodGet && route.JSONHandler != nil {
+		addParam(ctx, op, "header", "Prefer", "", "respond-async", i18n.MsgPreferHeaderDesc, false)
+		addParam(ctx, op, "query", "async", "", "", i18n.MsgAsyncQueryParam, false)
+	}
 	if route.FilterFactory != nil {
 		fields := route.FilterFactory.NewFilter(ctx).Fields()
 		sort.Strings(fields)
@@ -24,6 +24,13 @@ import (
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
+// CorrelationIDHeader is the header clients can set on any API request, to supply a correlation ID
+const CorrelationIDHeader = "X-FireFly-Request-ID"
+
+// ReadPrimaryHeader is the header clients can set to "true" on a GET request, so its queries are not served
+// from a read replica
+const ReadPrimaryHeader = "X-FireFly-Read-Primary"
+
 // Route defines each API operation on the REST API of Firefly
 // Having a standard pluggable layer here on top of Gorilla allows us to autmoatically
 // maintain the OpenAPI specification in-line with the code, while retaining the
@@ -0,0 +1,297 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const callbackSignatureHeader = "X-FireFly-Signature"
+
+// callbackDispatcher delivers the final state of an operation to the callback URL supplied when it was
+// submitted, for integrations that cannot hold open an event subscription. A callback is recorded in the
+// database when the operation is resolved, and delivered in the background in the same way as the outbox,
+// so a delivery that is still being retried survives a restart. The record is kept once the delivery
+// completes, so an operation that is resolved again, such as by a redelivered receipt, is not delivered twice.
+// The operation is POSTed as JSON, and signed in the same way as webhook deliveries if a signing secret is
+// configured. Failed deliveries are retried with a backoff.
+type callbackDispatcher struct {
+	ctx              context.Context
+	cancelFunc       func()
+	om               *operationsManager
+	client           *resty.Client
+	kick             chan struct{}
+	done             chan struct{}
+	batchSize        int
+	pollInterval     time.Duration
+	signingSecret    string
+	retryMaxAttempts int
+	retryInitDelay   time.Duration
+	retryMaxDelay    time.Duration
+	retryFactor      float64
+}
+
+func newCallbackDispatcher(ctx context.Context, om *operationsManager) *callbackDispatcher {
+	cdCtx, cancelFunc := context.WithCancel(ctx)
+	cd := &callbackDispatcher{
+		ctx:              cdCtx,
+		cancelFunc:       cancelFunc,
+		om:               om,
+		client:           resty.New().SetTimeout(config.GetDuration(config.OperationsCallbackRequestTimeout)),
+		kick:             make(chan struct{}, 1),
+		batchSize:        config.GetInt(config.OperationsCallbackBatchSize),
+		pollInterval:     config.GetDuration(config.OperationsCallbackPollInterval),
+		signingSecret:    config.GetString(config.OperationsCallbackSigningSecret),
+		retryMaxAttempts: config.GetInt(config.OperationsCallbackRetryMaxAttempts),
+		retryInitDelay:   config.GetDuration(config.OperationsCallbackRetryInitDelay),
+		retryMaxDelay:    config.GetDuration(config.OperationsCallbackRetryMaxDelay),
+		retryFactor:      config.GetFloat64(config.OperationsCallbackRetryFactor),
+	}
+	if cd.batchSize <= 0 {
+		cd.batchSize = 1
+	}
+	if cd.retryMaxAttempts <= 0 {
+		cd.retryMaxAttempts = 1
+	}
+	return cd
+}
+
+// callbackHostAllowed checks a host against the configured allow list, so requests cannot direct
+// the node to make requests to arbitrary hosts on its network
+func callbackHostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range config.GetStringSlice(config.OperationsCallbackAllowedHosts) {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCallbackURL checks a callback URL supplied with a request is one that can be delivered to
+func ValidateCallbackURL(ctx context.Context, callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return i18n.NewError(ctx, i18n.MsgInvalidCallbackURL, callbackURL)
+	}
+	if !callbackHostAllowed(u.Hostname()) {
+		return i18n.NewError(ctx, i18n.MsgCallbackHostNotAllowed, callbackURL)
+	}
+	return nil
+}
+
+// DeliverCallback records that the final state of an operation must be delivered to its callback URL, if it has one.
+// It should be called in the same database group that resolves the operation - the callback is delivered in the
+// background once the group commits. Recording the callback of an operation that already has one has no effect.
+func (om *operationsManager) DeliverCallback(ctx context.Context, op *fftypes.Operation) error {
+	return om.queueCallback(ctx, op.ID, op.Namespace, op.CallbackURL)
+}
+
+func (om *operationsManager) queueCallback(ctx context.Context, opID *fftypes.UUID, ns, callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	existing, err := om.database.GetOpCallbackByID(ctx, opID)
+	if err != nil || existing != nil {
+		return err
+	}
+	log.L(ctx).Debugf("Recording callback for operation %s", opID)
+	callback := &fftypes.OpCallback{
+		ID:          opID,
+		Namespace:   ns,
+		Status:      fftypes.OpCallbackStatusPending,
+		NextAttempt: fftypes.Now(),
+	}
+	return om.database.InsertOpCallback(ctx, callback, om.callbacks.kickDispatcher)
+}
+
+// recordCallback records a callback where the operation was resolved outside of a database group, so a
+// failure cannot be returned to roll back the resolution
+func (om *operationsManager) recordCallback(ctx context.Context, opID *fftypes.UUID, ns, callbackURL string) {
+	if err := om.queueCallback(ctx, opID, ns, callbackURL); err != nil {
+		log.L(ctx).Errorf("Failed to record callback for operation %s: %s", opID, err)
+	}
+}
+
+func (cd *callbackDispatcher) start() {
+	cd.done = make(chan struct{})
+	go cd.dispatchLoop()
+}
+
+func (cd *callbackDispatcher) waitStop() {
+	cd.cancelFunc()
+	if cd.done != nil {
+		<-cd.done
+	}
+}
+
+func (cd *callbackDispatcher) kickDispatcher() {
+	select {
+	case cd.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (cd *callbackDispatcher) calcDelay(attempts int) time.Duration {
+	return backoffDelay(attempts, cd.retryInitDelay, cd.retryMaxDelay, cd.retryFactor)
+}
+
+func (cd *callbackDispatcher) dispatchLoop() {
+	defer close(cd.done)
+	l := log.L(cd.ctx)
+	for {
+		full, err := cd.dispatchDue()
+		if err != nil {
+			l.Errorf("Callback dispatch failed: %s", err)
+		}
+		if full && err == nil {
+			// There might be more callbacks that are due
+			continue
+		}
+		timer := time.NewTimer(cd.pollInterval)
+		select {
+		case <-cd.kick:
+		case <-timer.C:
+		case <-cd.ctx.Done():
+			timer.Stop()
+			l.Debugf("Callback dispatcher exiting")
+			return
+		}
+		timer.Stop()
+	}
+}
+
+func (cd *callbackDispatcher) dispatchDue() (full bool, err error) {
+	fb := database.OpCallbackQueryFactory.NewFilter(cd.ctx)
+	filter := fb.And(
+		fb.Eq("status", fftypes.OpCallbackStatusPending),
+		fb.Lte("next", fftypes.Now()),
+	).
+		Sort("next").
+		Limit(uint64(cd.batchSize))
+	callbacks, _, err := cd.om.database.GetOpCallbacks(cd.ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	for _, callback := range callbacks {
+		if err := cd.dispatch(callback); err != nil {
+			return false, err
+		}
+	}
+	return len(callbacks) == cd.batchSize, nil
+}
+
+func (cd *callbackDispatcher) dispatch(callback *fftypes.OpCallback) error {
+	ctx := cd.ctx
+	l := log.L(ctx)
+	// The latest state of the operation is read on each attempt
+	op, err := cd.om.database.GetOperationByID(ctx, callback.ID)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		l.Warnf("Callback for operation %s failed, as the operation was not found", callback.ID)
+		return cd.complete(callback, fftypes.OpCallbackStatusFailed, i18n.NewError(ctx, i18n.Msg404NotFound).Error())
+	}
+	// The allow list is checked again, in case it has changed since the callback was requested
+	if err := ValidateCallbackURL(ctx, op.CallbackURL); err != nil {
+		l.Errorf("Callback for operation %s failed: %s", op.ID, err)
+		return cd.complete(callback, fftypes.OpCallbackStatusFailed, err.Error())
+	}
+
+	callback.Attempts++
+	err = cd.attemptDelivery(op)
+	if err == nil {
+		l.Infof("Delivered callback for operation %s", op.ID)
+		return cd.complete(callback, fftypes.OpCallbackStatusDelivered, "")
+	}
+	if callback.Attempts >= cd.retryMaxAttempts {
+		l.Errorf("Callback for operation %s failed after %d attempts: %s", op.ID, callback.Attempts, err)
+		return cd.complete(callback, fftypes.OpCallbackStatusFailed, err.Error())
+	}
+
+	next := fftypes.FFTime(time.Now().Add(cd.calcDelay(callback.Attempts)))
+	l.Warnf("Callback for operation %s attempt %d failed, next attempt at %s: %s", op.ID, callback.Attempts, next.String(), err)
+	update := database.OpCallbackQueryFactory.NewUpdate(ctx).
+		Set("attempts", callback.Attempts).
+		Set("next", &next).
+		Set("error", err.Error())
+	return cd.om.database.UpdateOpCallback(ctx, callback.ID, update)
+}
+
+func (cd *callbackDispatcher) complete(callback *fftypes.OpCallback, status fftypes.OpCallbackStatus, errorMessage string) error {
+	update := database.OpCallbackQueryFactory.NewUpdate(cd.ctx).
+		Set("status", status).
+		Set("attempts", callback.Attempts).
+		Set("error", errorMessage)
+	return cd.om.database.UpdateOpCallback(cd.ctx, callback.ID, update)
+}
+
+func (cd *callbackDispatcher) attemptDelivery(op *fftypes.Operation) error {
+	if op.Status == fftypes.OpStatusPending {
+		return i18n.NewError(cd.ctx, i18n.MsgOperationNotResolved, op.ID)
+	}
+	body, err := json.Marshal(op)
+	if err != nil {
+		return i18n.WrapError(cd.ctx, err, i18n.MsgSerializationFailed)
+	}
+	req := cd.client.R().
+		SetContext(cd.ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(body)
+	if cd.signingSecret != "" {
+		req.SetHeader(callbackSignatureHeader, signCallback(cd.signingSecret, body))
+	}
+	res, err := req.Post(op.CallbackURL)
+	if err != nil {
+		return err
+	}
+	if !res.IsSuccess() {
+		return i18n.NewError(cd.ctx, i18n.MsgCallbackDeliveryFailed, res.StatusCode())
+	}
+	return nil
+}
+
+// signCallback returns an HMAC-SHA256 signature of "<timestamp>.<body>", in the same format as the signature
+// header of webhook deliveries. For example:
+// X-FireFly-Signature: t=1651234567,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+func signCallback(secret string, body []byte) string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
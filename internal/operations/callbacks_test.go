@@ -0,0 +1,389 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testCallbackURL = "https://example.com/callback"
+
+func newTestCallbacks(t *testing.T) (*operationsManager, func()) {
+	om, cancel := newTestOperations(t)
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"example.com"})
+	om.callbacks.retryInitDelay = 1 * time.Millisecond
+	om.callbacks.retryMaxDelay = 1 * time.Millisecond
+	httpmock.ActivateNonDefault(om.callbacks.client.GetClient())
+	return om, func() {
+		cancel()
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func newTestCallbackOp() (*fftypes.Operation, *fftypes.OpCallback) {
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Status:      fftypes.OpStatusSucceeded,
+		CallbackURL: testCallbackURL,
+	}
+	return op, &fftypes.OpCallback{ID: op.ID, Namespace: op.Namespace, Status: fftypes.OpCallbackStatusPending}
+}
+
+func matchCallbackUpdate(field string, value interface{}) interface{} {
+	return mock.MatchedBy(func(update database.Update) bool {
+		info, _ := update.Finalize()
+		v, _ := info.SetOperations[0].Value.Value()
+		return info.SetOperations[0].Field == field && v == value
+	})
+}
+
+func TestValidateCallbackURL(t *testing.T) {
+	config.Reset()
+	ctx := context.Background()
+	assert.NoError(t, ValidateCallbackURL(ctx, ""))
+	assert.Regexp(t, "FF10576", ValidateCallbackURL(ctx, testCallbackURL))
+
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"example.com", "*.example.org", "LocalHost"})
+	assert.NoError(t, ValidateCallbackURL(ctx, testCallbackURL))
+	assert.NoError(t, ValidateCallbackURL(ctx, "https://EXAMPLE.com/callback"))
+	assert.NoError(t, ValidateCallbackURL(ctx, "http://localhost:3000/callback"))
+	assert.NoError(t, ValidateCallbackURL(ctx, "https://hooks.example.org/callback"))
+	assert.Regexp(t, "FF10576", ValidateCallbackURL(ctx, "https://example.org/callback"))
+	assert.Regexp(t, "FF10576", ValidateCallbackURL(ctx, "https://example.com.attacker.net/callback"))
+	assert.Regexp(t, "FF10576", ValidateCallbackURL(ctx, "http://169.254.169.254/latest/meta-data"))
+	assert.Regexp(t, "FF10484", ValidateCallbackURL(ctx, "ftp://example.com/callback"))
+	assert.Regexp(t, "FF10484", ValidateCallbackURL(ctx, "https://"))
+	assert.Regexp(t, "FF10484", ValidateCallbackURL(ctx, "://bad"))
+}
+
+func TestCallbackConfigDefaults(t *testing.T) {
+	config.Reset()
+	config.Set(config.OperationsCallbackBatchSize, 0)
+	config.Set(config.OperationsCallbackRetryMaxAttempts, 0)
+	cd := newCallbackDispatcher(context.Background(), &operationsManager{})
+	assert.Equal(t, 1, cd.batchSize)
+	assert.Equal(t, 1, cd.retryMaxAttempts)
+}
+
+func TestDeliverCallback(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, _ := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOpCallbackByID", context.Background(), op.ID).Return(nil, nil)
+	mdi.On("InsertOpCallback", context.Background(), mock.MatchedBy(func(callback *fftypes.OpCallback) bool {
+		return callback.ID.Equals(op.ID) &&
+			callback.Namespace == "ns1" &&
+			callback.Status == fftypes.OpCallbackStatusPending &&
+			callback.NextAttempt != nil
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		args[2].(database.PostCompletionHook)()
+	}).Return(nil)
+
+	err := om.DeliverCallback(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Len(t, om.callbacks.kick, 1)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeliverCallbackNoURL(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	err := om.DeliverCallback(context.Background(), &fftypes.Operation{ID: fftypes.NewUUID()})
+	assert.NoError(t, err)
+}
+
+func TestDeliverCallbackAlreadyRecorded(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, callback := newTestCallbackOp()
+	callback.Status = fftypes.OpCallbackStatusDelivered
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOpCallbackByID", context.Background(), op.ID).Return(callback, nil)
+
+	err := om.DeliverCallback(context.Background(), op)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeliverCallbackLookupFail(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, _ := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOpCallbackByID", context.Background(), op.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := om.DeliverCallback(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackStartStop(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, callback := newTestCallbackOp()
+	om.callbacks.batchSize = 1
+	om.callbacks.pollInterval = 1 * time.Hour
+	om.outbox.pollInterval = 1 * time.Hour
+
+	dispatched := make(chan struct{})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return([]*fftypes.OutboxEntry{}, nil, nil).Maybe()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return([]*fftypes.OpCallback{callback}, nil, nil).Once()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return([]*fftypes.OpCallback{}, nil, nil).Once()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return([]*fftypes.OpCallback{}, nil, nil).Run(func(args mock.Arguments) {
+		close(dispatched)
+	}).Once()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Maybe()
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("status", string(fftypes.OpCallbackStatusDelivered))).Return(nil)
+	httpmock.RegisterResponder("POST", testCallbackURL, httpmock.NewStringResponder(204, ""))
+
+	err := om.Start()
+	assert.NoError(t, err)
+	om.callbacks.kickDispatcher()
+	<-dispatched
+	om.WaitStop()
+
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchLoopPollFail(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	om.callbacks.pollInterval = 1 * time.Millisecond
+
+	polled := make(chan struct{})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(polled)
+	}).Once()
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Maybe()
+
+	om.callbacks.start()
+	<-polled
+	om.callbacks.waitStop()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchDueDispatchFail(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, callback := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return([]*fftypes.OpCallback{callback}, nil, nil)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(nil, fmt.Errorf("pop"))
+
+	full, err := om.callbacks.dispatchDue()
+	assert.EqualError(t, err, "pop")
+	assert.False(t, full)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchSigned(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+	om.callbacks.signingSecret = "secret1"
+
+	op, callback := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("status", string(fftypes.OpCallbackStatusDelivered))).Return(nil)
+
+	httpmock.RegisterResponder("POST", testCallbackURL, func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Contains(t, string(body), op.ID.String())
+		parts := strings.Split(req.Header.Get("X-FireFly-Signature"), ",")
+		assert.Len(t, parts, 2)
+		mac := hmac.New(sha256.New, []byte("secret1"))
+		mac.Write([]byte(strings.TrimPrefix(parts[0], "t=") + "."))
+		mac.Write(body)
+		assert.Equal(t, "v1="+hex.EncodeToString(mac.Sum(nil)), parts[1])
+		return httpmock.NewStringResponse(204, ""), nil
+	})
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, callback.Attempts)
+
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchRetry(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+	om.callbacks.retryMaxAttempts = 2
+
+	op, callback := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("attempts", int64(1))).Return(nil)
+	httpmock.RegisterResponder("POST", testCallbackURL, httpmock.NewStringResponder(500, ""))
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, callback.Attempts)
+
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchNotResolved(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+	om.callbacks.retryMaxAttempts = 2
+
+	op, callback := newTestCallbackOp()
+	op.Status = fftypes.OpStatusPending
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("attempts", int64(1))).Return(nil)
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchRetriesExhausted(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+	om.callbacks.retryMaxAttempts = 2
+
+	op, callback := newTestCallbackOp()
+	callback.Attempts = 1
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("status", string(fftypes.OpCallbackStatusFailed))).Return(nil)
+	httpmock.RegisterResponder("POST", testCallbackURL, httpmock.NewErrorResponder(fmt.Errorf("pop")))
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, callback.Attempts)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchOperationMissing(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, callback := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(nil, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("status", string(fftypes.OpCallbackStatusFailed))).Return(fmt.Errorf("pop"))
+
+	err := om.callbacks.dispatch(callback)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchHostNoLongerAllowed(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+	config.Set(config.OperationsCallbackAllowedHosts, []string{"other.example.com"})
+
+	op, callback := newTestCallbackOp()
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, matchCallbackUpdate("status", string(fftypes.OpCallbackStatusFailed))).Return(nil)
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestCallbackDispatchSerializeFail(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	op, callback := newTestCallbackOp()
+	op.Output = fftypes.JSONObject{"bad": map[bool]bool{true: false}}
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOpCallback", mock.Anything, op.ID, mock.Anything).Return(nil)
+
+	err := om.callbacks.dispatch(callback)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	mdi.AssertExpectations(t)
+}
+
+func TestRunOperationFailRecordsCallback(t *testing.T) {
+	om, cancel := newTestCallbacks(t)
+	defer cancel()
+
+	ctx := context.Background()
+	op := &fftypes.PreparedOperation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        fftypes.OpTypeBlockchainPinBatch,
+		CallbackURL: testCallbackURL,
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "pop", mock.Anything).Return(nil)
+	mdi.On("GetOpCallbackByID", mock.Anything, op.ID).Return(nil, nil)
+	mdi.On("InsertOpCallback", mock.Anything, mock.MatchedBy(func(callback *fftypes.OpCallback) bool {
+		return callback.ID.Equals(op.ID) && callback.Namespace == "ns1"
+	}), mock.Anything).Return(fmt.Errorf("pop2"))
+
+	om.RegisterHandler(ctx, &mockHandler{Err: fmt.Errorf("pop")}, []fftypes.OpType{fftypes.OpTypeBlockchainPinBatch})
+	err := om.RunOperation(ctx, op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
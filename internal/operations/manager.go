@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -37,6 +38,10 @@ type Manager interface {
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation, options ...RunOperationOption) error
 	RetryOperation(ctx context.Context, ns string, opID *fftypes.UUID) (*fftypes.Operation, error)
 	AddOrReuseOperation(ctx context.Context, op *fftypes.Operation) error
+	QueueOperation(ctx context.Context, op *fftypes.Operation) error
+	DeliverCallback(ctx context.Context, op *fftypes.Operation) error
+	Start() error
+	WaitStop()
 }
 
 type RunOperationOption int
@@ -46,23 +51,40 @@ const (
 )
 
 type operationsManager struct {
-	ctx      context.Context
-	database database.Plugin
-	handlers map[fftypes.OpType]OperationHandler
+	ctx       context.Context
+	database  database.Plugin
+	txHelper  txcommon.Helper
+	handlers  map[fftypes.OpType]OperationHandler
+	outbox    *outboxDispatcher
+	callbacks *callbackDispatcher
 }
 
-func NewOperationsManager(ctx context.Context, di database.Plugin) (Manager, error) {
-	if di == nil {
+func NewOperationsManager(ctx context.Context, di database.Plugin, txHelper txcommon.Helper) (Manager, error) {
+	if di == nil || txHelper == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	om := &operationsManager{
 		ctx:      ctx,
 		database: di,
+		txHelper: txHelper,
 		handlers: make(map[fftypes.OpType]OperationHandler),
 	}
+	om.outbox = newOutboxDispatcher(ctx, om)
+	om.callbacks = newCallbackDispatcher(ctx, om)
 	return om, nil
 }
 
+func (om *operationsManager) Start() error {
+	om.outbox.start()
+	om.callbacks.start()
+	return nil
+}
+
+func (om *operationsManager) WaitStop() {
+	om.outbox.waitStop()
+	om.callbacks.waitStop()
+}
+
 func (om *operationsManager) RegisterHandler(ctx context.Context, handler OperationHandler, ops []fftypes.OpType) {
 	for _, opType := range ops {
 		log.L(ctx).Debugf("OpType=%s registered to handler %s", opType, handler.Name())
@@ -90,13 +112,18 @@ func (om *operationsManager) RunOperation(ctx context.Context, op *fftypes.Prepa
 	if !ok {
 		return i18n.NewError(ctx, i18n.MsgOperationNotSupported, op.Type)
 	}
+	ctx = log.WithOperation(ctx, op.Namespace, op.Transaction.String(), op.ID.String())
 	log.L(ctx).Infof("Executing %s operation %s via handler %s", op.Type, op.ID, handler.Name())
 	log.L(ctx).Tracef("Operation detail: %+v", op)
 	if outputs, complete, err := handler.RunOperation(ctx, op); err != nil {
 		om.writeOperationFailure(ctx, op.ID, outputs, err, failState)
+		if failState == fftypes.OpStatusFailed {
+			om.recordCallback(ctx, op.ID, op.Namespace, op.CallbackURL)
+		}
 		return err
 	} else if complete {
 		om.writeOperationSuccess(ctx, op.ID, outputs)
+		om.recordCallback(ctx, op.ID, op.Namespace, op.CallbackURL)
 	}
 	return nil
 }
@@ -148,13 +175,13 @@ func (om *operationsManager) RetryOperation(ctx context.Context, ns string, opID
 }
 
 func (om *operationsManager) writeOperationSuccess(ctx context.Context, opID *fftypes.UUID, outputs fftypes.JSONObject) {
-	if err := om.database.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", outputs); err != nil {
+	if err := om.txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", outputs); err != nil {
 		log.L(ctx).Errorf("Failed to update operation %s: %s", opID, err)
 	}
 }
 
 func (om *operationsManager) writeOperationFailure(ctx context.Context, opID *fftypes.UUID, outputs fftypes.JSONObject, err error, newState fftypes.OpStatus) {
-	if err := om.database.ResolveOperation(ctx, opID, newState, err.Error(), outputs); err != nil {
+	if err := om.txHelper.ResolveOperation(ctx, opID, newState, err.Error(), outputs); err != nil {
 		log.L(ctx).Errorf("Failed to update operation %s: %s", opID, err)
 	}
 }
@@ -20,7 +20,9 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -58,13 +60,14 @@ func newTestOperations(t *testing.T) (*operationsManager, func()) {
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	om, err := NewOperationsManager(ctx, mdi)
+	txHelper := txcommon.NewTransactionHelper(mdi, &datamocks.Manager{})
+	om, err := NewOperationsManager(ctx, mdi, txHelper)
 	assert.NoError(t, err)
 	return om.(*operationsManager), cancel
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewOperationsManager(context.Background(), nil)
+	_, err := NewOperationsManager(context.Background(), nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -129,7 +132,7 @@ func TestRunOperationSyncSuccess(t *testing.T) {
 	}
 
 	mdi := om.database.(*databasemocks.Plugin)
-	mdi.On("ResolveOperation", ctx, op.ID, fftypes.OpStatusSucceeded, "", mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", mock.Anything).Return(nil)
 
 	om.RegisterHandler(ctx, &mockHandler{Complete: true}, []fftypes.OpType{fftypes.OpTypeBlockchainPinBatch})
 	err := om.RunOperation(ctx, op)
@@ -150,7 +153,7 @@ func TestRunOperationFail(t *testing.T) {
 	}
 
 	mdi := om.database.(*databasemocks.Plugin)
-	mdi.On("ResolveOperation", ctx, op.ID, fftypes.OpStatusFailed, "pop", mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "pop", mock.Anything).Return(nil)
 
 	om.RegisterHandler(ctx, &mockHandler{Err: fmt.Errorf("pop")}, []fftypes.OpType{fftypes.OpTypeBlockchainPinBatch})
 	err := om.RunOperation(ctx, op)
@@ -171,7 +174,7 @@ func TestRunOperationFailRemainPending(t *testing.T) {
 	}
 
 	mdi := om.database.(*databasemocks.Plugin)
-	mdi.On("ResolveOperation", ctx, op.ID, fftypes.OpStatusPending, "pop", mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusPending, "pop", mock.Anything).Return(nil)
 
 	om.RegisterHandler(ctx, &mockHandler{Err: fmt.Errorf("pop")}, []fftypes.OpType{fftypes.OpTypeBlockchainPinBatch})
 	err := om.RunOperation(ctx, op, RemainPendingOnFailure)
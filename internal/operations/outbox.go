@@ -0,0 +1,208 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// outboxDispatcher runs operations that have been queued in the outbox. An operation is queued in the
+// same database transaction that creates it, so the submission to the plugin cannot be lost by a crash
+// after that transaction commits. Entries are keyed by operation ID, which is also the request ID passed
+// to the plugin, so a submission that is repeated after a crash can be detected by the connector.
+// Failed submissions are retried with a backoff, until the maximum attempts are exhausted and the
+// operation is marked failed.
+type outboxDispatcher struct {
+	ctx              context.Context
+	cancelFunc       func()
+	om               *operationsManager
+	kick             chan struct{}
+	done             chan struct{}
+	batchSize        int
+	pollInterval     time.Duration
+	retryMaxAttempts int
+	retryInitDelay   time.Duration
+	retryMaxDelay    time.Duration
+	retryFactor      float64
+}
+
+func newOutboxDispatcher(ctx context.Context, om *operationsManager) *outboxDispatcher {
+	odCtx, cancelFunc := context.WithCancel(ctx)
+	od := &outboxDispatcher{
+		ctx:              odCtx,
+		cancelFunc:       cancelFunc,
+		om:               om,
+		kick:             make(chan struct{}, 1),
+		batchSize:        config.GetInt(config.OperationsOutboxBatchSize),
+		pollInterval:     config.GetDuration(config.OperationsOutboxPollInterval),
+		retryMaxAttempts: config.GetInt(config.OperationsOutboxRetryMaxAttempts),
+		retryInitDelay:   config.GetDuration(config.OperationsOutboxRetryInitDelay),
+		retryMaxDelay:    config.GetDuration(config.OperationsOutboxRetryMaxDelay),
+		retryFactor:      config.GetFloat64(config.OperationsOutboxRetryFactor),
+	}
+	if od.batchSize <= 0 {
+		od.batchSize = 1
+	}
+	if od.retryMaxAttempts <= 0 {
+		od.retryMaxAttempts = 1
+	}
+	return od
+}
+
+// QueueOperation records in the outbox that an operation needs to be run. It must be called in the same
+// database group that inserts the operation - the operation is run by the outbox dispatcher once the group commits.
+// Queuing an operation that is already in the outbox has no effect.
+func (om *operationsManager) QueueOperation(ctx context.Context, op *fftypes.Operation) error {
+	existing, err := om.database.GetOutboxEntryByID(ctx, op.ID)
+	if err != nil || existing != nil {
+		return err
+	}
+	entry := &fftypes.OutboxEntry{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		NextAttempt: fftypes.Now(),
+	}
+	return om.database.InsertOutboxEntry(ctx, entry, om.outbox.kickDispatcher)
+}
+
+func (od *outboxDispatcher) start() {
+	od.done = make(chan struct{})
+	go od.dispatchLoop()
+}
+
+func (od *outboxDispatcher) waitStop() {
+	od.cancelFunc()
+	if od.done != nil {
+		<-od.done
+	}
+}
+
+func (od *outboxDispatcher) kickDispatcher() {
+	select {
+	case od.kick <- struct{}{}:
+	default:
+	}
+}
+
+// backoffDelay is the delay before the next attempt, after the given number of failed attempts
+func backoffDelay(attempts int, initDelay, maxDelay time.Duration, factor float64) time.Duration {
+	delay := initDelay
+	for i := 1; i < attempts; i++ {
+		delay = time.Duration(math.Ceil(float64(delay) * factor))
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+func (od *outboxDispatcher) calcDelay(attempts int) time.Duration {
+	return backoffDelay(attempts, od.retryInitDelay, od.retryMaxDelay, od.retryFactor)
+}
+
+func (od *outboxDispatcher) dispatchLoop() {
+	defer close(od.done)
+	l := log.L(od.ctx)
+	for {
+		full, err := od.dispatchDue()
+		if err != nil {
+			l.Errorf("Outbox dispatch failed: %s", err)
+		}
+		if full && err == nil {
+			// There might be more operations that are due
+			continue
+		}
+		timer := time.NewTimer(od.pollInterval)
+		select {
+		case <-od.kick:
+		case <-timer.C:
+		case <-od.ctx.Done():
+			timer.Stop()
+			l.Debugf("Outbox dispatcher exiting")
+			return
+		}
+		timer.Stop()
+	}
+}
+
+func (od *outboxDispatcher) dispatchDue() (full bool, err error) {
+	fb := database.OutboxQueryFactory.NewFilter(od.ctx)
+	filter := fb.And(fb.Lte("next", fftypes.Now())).
+		Sort("next").
+		Limit(uint64(od.batchSize))
+	entries, _, err := od.om.database.GetOutboxEntries(od.ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if err := od.dispatch(entry); err != nil {
+			return false, err
+		}
+	}
+	return len(entries) == od.batchSize, nil
+}
+
+func (od *outboxDispatcher) dispatch(entry *fftypes.OutboxEntry) error {
+	ctx := od.ctx
+	op, err := od.om.database.GetOperationByID(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	if op == nil || op.Status != fftypes.OpStatusPending {
+		// The operation has been resolved by other means, such as a manual retry, so there is nothing to submit
+		log.L(ctx).Infof("Removing operation %s from outbox, as it is no longer pending", entry.ID)
+		return od.remove(entry)
+	}
+
+	entry.Attempts++
+	po, err := od.om.PrepareOperation(ctx, op)
+	if err == nil {
+		err = od.om.RunOperation(ctx, po, RemainPendingOnFailure)
+	}
+	if err == nil {
+		return od.remove(entry)
+	}
+	if entry.Attempts >= od.retryMaxAttempts {
+		log.L(ctx).Errorf("Operation %s failed after %d attempts: %s", op.ID, entry.Attempts, err)
+		od.om.writeOperationFailure(ctx, op.ID, nil, err, fftypes.OpStatusFailed)
+		od.om.recordCallback(ctx, op.ID, op.Namespace, op.CallbackURL)
+		return od.remove(entry)
+	}
+
+	next := fftypes.FFTime(time.Now().Add(od.calcDelay(entry.Attempts)))
+	log.L(ctx).Warnf("Operation %s attempt %d failed, next attempt at %s: %s", op.ID, entry.Attempts, next.String(), err)
+	update := database.OutboxQueryFactory.NewUpdate(ctx).
+		Set("attempts", entry.Attempts).
+		Set("next", &next).
+		Set("error", err.Error())
+	return od.om.database.UpdateOutboxEntry(ctx, entry.ID, update)
+}
+
+func (od *outboxDispatcher) remove(entry *fftypes.OutboxEntry) error {
+	err := od.om.database.DeleteOutboxEntry(od.ctx, entry.ID)
+	if err == database.DeleteRecordNotFound {
+		return nil
+	}
+	return err
+}
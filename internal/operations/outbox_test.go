@@ -0,0 +1,268 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestOutboxOp(om *operationsManager, handler *mockHandler) (*fftypes.Operation, *fftypes.OutboxEntry) {
+	op := &fftypes.Operation{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.OpTypeBlockchainPinBatch,
+		Status:    fftypes.OpStatusPending,
+	}
+	handler.Prepared = &fftypes.PreparedOperation{
+		ID:   op.ID,
+		Type: op.Type,
+	}
+	om.RegisterHandler(om.ctx, handler, []fftypes.OpType{fftypes.OpTypeBlockchainPinBatch})
+	return op, &fftypes.OutboxEntry{ID: op.ID, Namespace: op.Namespace}
+}
+
+func TestQueueOperation(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntryByID", context.Background(), op.ID).Return(nil, nil)
+	mdi.On("InsertOutboxEntry", context.Background(), mock.MatchedBy(func(entry *fftypes.OutboxEntry) bool {
+		return entry.ID.Equals(op.ID) && entry.Namespace == "ns1" && entry.NextAttempt != nil
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		// Both calls are swallowed by the single slot in the kick channel
+		args[2].(database.PostCompletionHook)()
+		args[2].(database.PostCompletionHook)()
+	}).Return(nil)
+
+	err := om.QueueOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Len(t, om.outbox.kick, 1)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestQueueOperationAlreadyQueued(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID()}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntryByID", context.Background(), op.ID).Return(&fftypes.OutboxEntry{ID: op.ID}, nil)
+
+	err := om.QueueOperation(context.Background(), op)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestQueueOperationLookupFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID()}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntryByID", context.Background(), op.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := om.QueueOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxConfigDefaults(t *testing.T) {
+	config.Reset()
+	config.Set(config.OperationsOutboxBatchSize, 0)
+	config.Set(config.OperationsOutboxRetryMaxAttempts, 0)
+	od := newOutboxDispatcher(context.Background(), &operationsManager{})
+	assert.Equal(t, 1, od.batchSize)
+	assert.Equal(t, 1, od.retryMaxAttempts)
+}
+
+func TestOutboxCalcDelay(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	od := om.outbox
+	od.retryInitDelay = 1 * time.Second
+	od.retryMaxDelay = 3 * time.Second
+	od.retryFactor = 2.0
+	assert.Equal(t, 1*time.Second, od.calcDelay(1))
+	assert.Equal(t, 2*time.Second, od.calcDelay(2))
+	assert.Equal(t, 3*time.Second, od.calcDelay(3))
+}
+
+func TestOutboxStartStop(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{})
+	om.outbox.batchSize = 1
+	om.outbox.pollInterval = 1 * time.Hour
+
+	dispatched := make(chan struct{})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return([]*fftypes.OutboxEntry{entry}, nil, nil).Once()
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return([]*fftypes.OutboxEntry{}, nil, nil).Once()
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return([]*fftypes.OutboxEntry{}, nil, nil).Run(func(args mock.Arguments) {
+		close(dispatched)
+	}).Once()
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Maybe()
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("DeleteOutboxEntry", mock.Anything, op.ID).Return(nil)
+	mdi.On("GetOpCallbacks", mock.Anything, mock.Anything).Return([]*fftypes.OpCallback{}, nil, nil).Maybe()
+
+	err := om.Start()
+	assert.NoError(t, err)
+	om.outbox.kickDispatcher()
+	<-dispatched
+	om.WaitStop()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxWaitStopNotStarted(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	om.WaitStop()
+}
+
+func TestOutboxDispatchLoopPollFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	om.outbox.pollInterval = 1 * time.Millisecond
+
+	polled := make(chan struct{})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(polled)
+	}).Once()
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Maybe()
+
+	om.outbox.start()
+	<-polled
+	om.outbox.waitStop()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxDispatchDueDispatchFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOutboxEntries", mock.Anything, mock.Anything).Return([]*fftypes.OutboxEntry{entry}, nil, nil)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(nil, fmt.Errorf("pop"))
+
+	full, err := om.outbox.dispatchDue()
+	assert.EqualError(t, err, "pop")
+	assert.False(t, full)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxDispatchNotPending(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{})
+	op.Status = fftypes.OpStatusFailed
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("DeleteOutboxEntry", mock.Anything, op.ID).Return(database.DeleteRecordNotFound)
+
+	err := om.outbox.dispatch(entry)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxDispatchOperationMissing(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(nil, nil)
+	mdi.On("DeleteOutboxEntry", mock.Anything, op.ID).Return(fmt.Errorf("pop"))
+
+	err := om.outbox.dispatch(entry)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxDispatchRetry(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{Err: fmt.Errorf("pop")})
+	om.outbox.retryMaxAttempts = 2
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("UpdateOutboxEntry", mock.Anything, op.ID, mock.Anything).Return(nil)
+
+	err := om.outbox.dispatch(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entry.Attempts)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOutboxDispatchRetriesExhausted(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op, entry := newTestOutboxOp(om, &mockHandler{Err: fmt.Errorf("pop")})
+	entry.Attempts = 1
+	om.outbox.retryMaxAttempts = 2
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(nil)
+	mdi.On("DeleteOutboxEntry", mock.Anything, op.ID).Return(nil)
+
+	err := om.outbox.dispatch(entry)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/batchmigration"
+	"github.com/hyperledger/firefly/internal/secrets"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// MigrateBatches rewrites the batches persisted by v0.13.x and earlier to the manifest format, while the
+// node is stopped. Only the database plugin is initialized.
+func (or *orchestrator) MigrateBatches(ctx context.Context) (*fftypes.BatchMigrationStatus, error) {
+	if err := secrets.ResolveConfig(ctx, secretsConfig); err != nil {
+		return nil, err
+	}
+	if err := or.initDatabaseCheckPreinit(ctx); err != nil {
+		return nil, err
+	}
+	if or.batchMigration == nil {
+		// Cannot fail, as the database is initialized
+		or.batchMigration, _ = batchmigration.NewBatchMigrator(ctx, or.database)
+	}
+	return or.batchMigration.Run(ctx)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMigrateBatches(t *testing.T) {
+	or := newTestOrchestrator()
+	or.batchMigration = nil
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{}, nil, nil)
+
+	status, err := or.MigrateBatches(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Scanned)
+	assert.NotNil(t, status.Completed)
+}
+
+func TestMigrateBatchesSecretsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.NodeName, "${env:FF_UT_UNSET_SECRET}")
+
+	_, err := or.MigrateBatches(context.Background())
+	assert.Regexp(t, "FF10439", err)
+}
+
+func TestMigrateBatchesDatabaseFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := or.MigrateBatches(context.Background())
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,215 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// bootstrapDefinitions is the content of one file in the bootstrap directory
+type bootstrapDefinitions struct {
+	file          string
+	Namespace     string                  `json:"namespace,omitempty"`
+	Datatypes     []*fftypes.Datatype     `json:"datatypes,omitempty"`
+	FFIs          []*fftypes.FFI          `json:"ffis,omitempty"`
+	ContractAPIs  []*fftypes.ContractAPI  `json:"contractAPIs,omitempty"`
+	TokenPools    []*fftypes.TokenPool    `json:"tokenPools,omitempty"`
+	Subscriptions []*fftypes.Subscription `json:"subscriptions,omitempty"`
+}
+
+// loadBootstrapDefinitions reads every YAML/JSON file in the directory, in name order, so that
+// invalid files are reported at startup rather than when they are applied
+func loadBootstrapDefinitions(ctx context.Context, dir string) ([]*bootstrapDefinitions, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgBootstrapDirReadFailed, dir, err)
+	}
+	files := make([]*bootstrapDefinitions, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		b, err := ioutil.ReadFile(path)
+		defs := &bootstrapDefinitions{file: entry.Name()}
+		if err == nil {
+			err = yaml.Unmarshal(b, defs)
+		}
+		if err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgBootstrapFileInvalid, path, err)
+		}
+		if defs.Namespace == "" {
+			defs.Namespace = config.GetString(config.NamespacesDefault)
+		}
+		files = append(files, defs)
+	}
+	return files, nil
+}
+
+func (or *orchestrator) startBootstrap() error {
+	dir := config.GetString(config.BootstrapDirectory)
+	if dir == "" {
+		return nil
+	}
+	files, err := loadBootstrapDefinitions(or.ctx, dir)
+	if err != nil {
+		return err
+	}
+	or.bootstrapDone = make(chan struct{})
+	go or.runBootstrap(files)
+	return nil
+}
+
+// runBootstrap applies the definitions in the background, as broadcasts can only be confirmed once the
+// event processing is running, and will fail until this node's organization is registered. Every
+// definition is checked for existence before it is applied, so the whole set is retried on failure.
+func (or *orchestrator) runBootstrap(files []*bootstrapDefinitions) {
+	defer close(or.bootstrapDone)
+	update := config.GetBool(config.BootstrapUpdate)
+	r := &retry.Retry{
+		InitialDelay: config.GetDuration(config.BootstrapRetryInitDelay),
+		MaximumDelay: config.GetDuration(config.BootstrapRetryMaxDelay),
+		Factor:       config.GetFloat64(config.BootstrapRetryFactor),
+	}
+	err := r.Do(or.ctx, "apply bootstrap definitions", func(attempt int) (retry bool, err error) {
+		for _, defs := range files {
+			if err := or.applyBootstrapDefinitions(or.ctx, defs, update); err != nil {
+				return true, err
+			}
+		}
+		return false, nil
+	})
+	if err == nil {
+		log.L(or.ctx).Infof("Bootstrap definitions applied from %d files", len(files))
+	}
+}
+
+func (or *orchestrator) applyBootstrapDefinitions(ctx context.Context, defs *bootstrapDefinitions, update bool) error {
+	ns := defs.Namespace
+	l := log.L(ctx)
+
+	// Datatypes, FFIs and token pools cannot be changed once they exist, so are only ever created
+	for _, datatype := range defs.Datatypes {
+		existing, err := or.database.GetDatatypeByName(ctx, ns, datatype.Name, datatype.Version)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			l.Infof("Bootstrap %s: defining datatype %s:%s", defs.file, datatype.Name, datatype.Version)
+			if _, err := or.broadcast.BroadcastDatatype(ctx, ns, datatype, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ffi := range defs.FFIs {
+		existing, err := or.database.GetFFI(ctx, ns, ffi.Name, ffi.Version)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			l.Infof("Bootstrap %s: defining FFI %s:%s", defs.file, ffi.Name, ffi.Version)
+			if _, err := or.contracts.BroadcastFFI(ctx, ns, ffi, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, api := range defs.ContractAPIs {
+		existing, err := or.database.GetContractAPIByName(ctx, ns, api.Name)
+		if err != nil {
+			return err
+		}
+		apply := existing == nil
+		if !apply && update {
+			if apply, err = or.bootstrapContractAPIChanged(ctx, ns, existing, api); err != nil {
+				return err
+			}
+		}
+		if apply {
+			l.Infof("Bootstrap %s: defining contract API %s", defs.file, api.Name)
+			if _, err := or.contracts.BroadcastContractAPI(ctx, "", ns, api, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pool := range defs.TokenPools {
+		existing, err := or.database.GetTokenPool(ctx, ns, pool.Name)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			l.Infof("Bootstrap %s: creating token pool %s", defs.file, pool.Name)
+			if _, err := or.assets.CreateTokenPool(ctx, ns, pool, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sub := range defs.Subscriptions {
+		existing, err := or.database.GetSubscriptionByName(ctx, ns, sub.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case existing == nil:
+			l.Infof("Bootstrap %s: creating subscription %s", defs.file, sub.Name)
+			_, err = or.createUpdateSubscription(ctx, ns, sub, true)
+		case update:
+			// The event manager ignores the update if the definition is unchanged
+			_, err = or.createUpdateSubscription(ctx, ns, sub, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bootstrapContractAPIChanged checks whether the API now references a different FFI. The location of
+// an existing API cannot be changed, which is reported when the API is broadcast.
+func (or *orchestrator) bootstrapContractAPIChanged(ctx context.Context, ns string, existing, api *fftypes.ContractAPI) (bool, error) {
+	if api.Interface == nil || existing.Interface == nil || !api.LocationAndLedgerEquals(existing) {
+		return true, nil
+	}
+	id := api.Interface.ID
+	if id == nil {
+		ffi, err := or.database.GetFFI(ctx, ns, api.Interface.Name, api.Interface.Version)
+		if err != nil || ffi == nil {
+			return true, err
+		}
+		id = ffi.ID
+	}
+	return !id.Equals(existing.Interface.ID), nil
+}
@@ -0,0 +1,325 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testBootstrapYAML = `
+datatypes:
+- name: widget
+  version: "1.0"
+  value: {"type": "object"}
+ffis:
+- name: widgets
+  version: "1.0"
+contractAPIs:
+- name: widgets
+  interface:
+    name: widgets
+    version: "1.0"
+  location:
+    address: "0x12345"
+tokenPools:
+- name: pool1
+  type: fungible
+subscriptions:
+- name: sub1
+  transport: websockets
+`
+
+func newTestBootstrapDefs(t *testing.T) *bootstrapDefinitions {
+	config.Reset()
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "defs.yaml"), []byte(testBootstrapYAML), 0644)
+	assert.NoError(t, err)
+	files, err := loadBootstrapDefinitions(context.Background(), dir)
+	assert.NoError(t, err)
+	return files[0]
+}
+
+func TestLoadBootstrapDefinitions(t *testing.T) {
+	config.Reset()
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "b.json"), []byte(`{"namespace":"ns1","datatypes":[{"name":"dt1","version":"1"}]}`), 0644)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path.Join(dir, "a.yaml"), []byte(testBootstrapYAML), 0644)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path.Join(dir, "README.md"), []byte(`ignored`), 0644)
+	assert.NoError(t, err)
+	err = os.Mkdir(path.Join(dir, "subdir.yaml"), 0755)
+	assert.NoError(t, err)
+
+	files, err := loadBootstrapDefinitions(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "a.yaml", files[0].file)
+	assert.Equal(t, "default", files[0].Namespace)
+	assert.Equal(t, "widget", files[0].Datatypes[0].Name)
+	assert.Equal(t, "widgets", files[0].ContractAPIs[0].Interface.Name)
+	assert.Equal(t, fftypes.TokenTypeFungible, files[0].TokenPools[0].Type)
+	assert.Equal(t, "websockets", files[0].Subscriptions[0].Transport)
+	assert.Equal(t, "b.json", files[1].file)
+	assert.Equal(t, "ns1", files[1].Namespace)
+}
+
+func TestLoadBootstrapDefinitionsBadDir(t *testing.T) {
+	_, err := loadBootstrapDefinitions(context.Background(), path.Join(t.TempDir(), "missing"))
+	assert.Regexp(t, "FF10463", err)
+}
+
+func TestLoadBootstrapDefinitionsBadFile(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "bad.yml"), []byte(`datatypes: {`), 0644)
+	assert.NoError(t, err)
+	_, err = loadBootstrapDefinitions(context.Background(), dir)
+	assert.Regexp(t, "FF10464.*bad.yml", err)
+}
+
+func TestStartBootstrapBadDir(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BootstrapDirectory, path.Join(t.TempDir(), "missing"))
+	err := or.startBootstrap()
+	assert.Regexp(t, "FF10463", err)
+}
+
+func TestStartBootstrapRetry(t *testing.T) {
+	or := newTestOrchestrator()
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "defs.json"), []byte(`{"datatypes":[{"name":"dt1","version":"1"}]}`), 0644)
+	assert.NoError(t, err)
+	config.Set(config.BootstrapDirectory, dir)
+	config.Set(config.BootstrapRetryInitDelay, "1ms")
+
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "dt1", "1").Return(nil, fmt.Errorf("pop")).Once()
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "dt1", "1").Return(&fftypes.Datatype{}, nil).Once()
+
+	err = or.startBootstrap()
+	assert.NoError(t, err)
+	<-or.bootstrapDone
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestStartBootstrapCancelled(t *testing.T) {
+	or := newTestOrchestrator()
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "defs.json"), []byte(`{"datatypes":[{"name":"dt1","version":"1"}]}`), 0644)
+	assert.NoError(t, err)
+	config.Set(config.BootstrapDirectory, dir)
+	or.cancelCtx()
+
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "dt1", "1").Return(nil, fmt.Errorf("pop"))
+
+	err = or.startBootstrap()
+	assert.NoError(t, err)
+	<-or.bootstrapDone
+}
+
+func TestApplyBootstrapCreate(t *testing.T) {
+	or := newTestOrchestrator()
+	defs := newTestBootstrapDefs(t)
+
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "widget", "1.0").Return(nil, nil)
+	or.mbm.On("BroadcastDatatype", mock.Anything, "default", defs.Datatypes[0], true).Return(&fftypes.Message{}, nil)
+	or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(nil, nil)
+	or.mcm.On("BroadcastFFI", mock.Anything, "default", defs.FFIs[0], true).Return(defs.FFIs[0], nil)
+	or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(nil, nil)
+	or.mcm.On("BroadcastContractAPI", mock.Anything, "", "default", defs.ContractAPIs[0], true).Return(defs.ContractAPIs[0], nil)
+	or.mdi.On("GetTokenPool", mock.Anything, "default", "pool1").Return(nil, nil)
+	or.mam.On("CreateTokenPool", mock.Anything, "default", defs.TokenPools[0], true).Return(defs.TokenPools[0], nil)
+	or.mdi.On("GetSubscriptionByName", mock.Anything, "default", "sub1").Return(nil, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "default").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, defs.Subscriptions[0], true).Return(nil)
+
+	err := or.applyBootstrapDefinitions(or.ctx, defs, false)
+	assert.NoError(t, err)
+
+	or.mbm.AssertExpectations(t)
+	or.mcm.AssertExpectations(t)
+	or.mam.AssertExpectations(t)
+	or.mem.AssertExpectations(t)
+}
+
+func TestApplyBootstrapExisting(t *testing.T) {
+	or := newTestOrchestrator()
+	defs := newTestBootstrapDefs(t)
+
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "widget", "1.0").Return(&fftypes.Datatype{}, nil)
+	or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(&fftypes.FFI{}, nil)
+	or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(&fftypes.ContractAPI{}, nil)
+	or.mdi.On("GetTokenPool", mock.Anything, "default", "pool1").Return(&fftypes.TokenPool{}, nil)
+	or.mdi.On("GetSubscriptionByName", mock.Anything, "default", "sub1").Return(&fftypes.Subscription{}, nil)
+
+	err := or.applyBootstrapDefinitions(or.ctx, defs, false)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestApplyBootstrapUpdate(t *testing.T) {
+	or := newTestOrchestrator()
+	defs := newTestBootstrapDefs(t)
+	existingAPI := &fftypes.ContractAPI{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		Location:  defs.ContractAPIs[0].Location,
+	}
+
+	or.mdi.On("GetDatatypeByName", mock.Anything, "default", "widget", "1.0").Return(&fftypes.Datatype{}, nil)
+	or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(&fftypes.FFI{ID: fftypes.NewUUID()}, nil)
+	or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(existingAPI, nil)
+	or.mcm.On("BroadcastContractAPI", mock.Anything, "", "default", defs.ContractAPIs[0], true).Return(defs.ContractAPIs[0], nil)
+	or.mdi.On("GetTokenPool", mock.Anything, "default", "pool1").Return(&fftypes.TokenPool{}, nil)
+	or.mdi.On("GetSubscriptionByName", mock.Anything, "default", "sub1").Return(&fftypes.Subscription{}, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "default").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, defs.Subscriptions[0], false).Return(nil)
+
+	err := or.applyBootstrapDefinitions(or.ctx, defs, true)
+	assert.NoError(t, err)
+
+	or.mcm.AssertExpectations(t)
+	or.mem.AssertExpectations(t)
+}
+
+func TestApplyBootstrapUpdateContractAPIUnchanged(t *testing.T) {
+	or := newTestOrchestrator()
+	defs := newTestBootstrapDefs(t)
+	ffiID := fftypes.NewUUID()
+	defs.ContractAPIs[0].Interface.ID = ffiID
+	defs.Datatypes, defs.FFIs, defs.TokenPools, defs.Subscriptions = nil, nil, nil, nil
+	existingAPI := &fftypes.ContractAPI{
+		Interface: &fftypes.FFIReference{ID: ffiID},
+		Location:  defs.ContractAPIs[0].Location,
+	}
+
+	or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(existingAPI, nil)
+
+	err := or.applyBootstrapDefinitions(or.ctx, defs, true)
+	assert.NoError(t, err)
+
+	or.mcm.AssertExpectations(t)
+}
+
+func TestBootstrapContractAPIChanged(t *testing.T) {
+	or := newTestOrchestrator()
+	existing := &fftypes.ContractAPI{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+	}
+
+	changed, err := or.bootstrapContractAPIChanged(or.ctx, "ns1", existing, &fftypes.ContractAPI{})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	api := &fftypes.ContractAPI{
+		Interface: &fftypes.FFIReference{Name: "ffi1", Version: "1.0"},
+	}
+	or.mdi.On("GetFFI", mock.Anything, "ns1", "ffi1", "1.0").Return(nil, fmt.Errorf("pop")).Once()
+	_, err = or.bootstrapContractAPIChanged(or.ctx, "ns1", existing, api)
+	assert.EqualError(t, err, "pop")
+
+	or.mdi.On("GetFFI", mock.Anything, "ns1", "ffi1", "1.0").Return(nil, nil).Once()
+	changed, err = or.bootstrapContractAPIChanged(or.ctx, "ns1", existing, api)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestApplyBootstrapContractAPIChangedFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defs := newTestBootstrapDefs(t)
+	defs.Datatypes, defs.FFIs = nil, nil
+	existingAPI := &fftypes.ContractAPI{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		Location:  defs.ContractAPIs[0].Location,
+	}
+
+	or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(existingAPI, nil)
+	or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(nil, fmt.Errorf("pop"))
+
+	err := or.applyBootstrapDefinitions(or.ctx, defs, true)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestApplyBootstrapFailures(t *testing.T) {
+	failures := []struct {
+		name  string
+		setup func(or *testOrchestrator, defs *bootstrapDefinitions)
+	}{
+		{"datatype lookup", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			or.mdi.On("GetDatatypeByName", mock.Anything, "default", "widget", "1.0").Return(nil, fmt.Errorf("pop"))
+		}},
+		{"datatype broadcast", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			or.mdi.On("GetDatatypeByName", mock.Anything, "default", "widget", "1.0").Return(nil, nil)
+			or.mbm.On("BroadcastDatatype", mock.Anything, "default", defs.Datatypes[0], true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"ffi lookup", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes = nil
+			or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(nil, fmt.Errorf("pop"))
+		}},
+		{"ffi broadcast", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes = nil
+			or.mdi.On("GetFFI", mock.Anything, "default", "widgets", "1.0").Return(nil, nil)
+			or.mcm.On("BroadcastFFI", mock.Anything, "default", defs.FFIs[0], true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"contract api lookup", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs = nil, nil
+			or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(nil, fmt.Errorf("pop"))
+		}},
+		{"contract api broadcast", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs = nil, nil
+			or.mdi.On("GetContractAPIByName", mock.Anything, "default", "widgets").Return(nil, nil)
+			or.mcm.On("BroadcastContractAPI", mock.Anything, "", "default", defs.ContractAPIs[0], true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"token pool lookup", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs, defs.ContractAPIs = nil, nil, nil
+			or.mdi.On("GetTokenPool", mock.Anything, "default", "pool1").Return(nil, fmt.Errorf("pop"))
+		}},
+		{"token pool create", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs, defs.ContractAPIs = nil, nil, nil
+			or.mdi.On("GetTokenPool", mock.Anything, "default", "pool1").Return(nil, nil)
+			or.mam.On("CreateTokenPool", mock.Anything, "default", defs.TokenPools[0], true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"subscription lookup", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs, defs.ContractAPIs, defs.TokenPools = nil, nil, nil, nil
+			or.mdi.On("GetSubscriptionByName", mock.Anything, "default", "sub1").Return(nil, fmt.Errorf("pop"))
+		}},
+		{"subscription create", func(or *testOrchestrator, defs *bootstrapDefinitions) {
+			defs.Datatypes, defs.FFIs, defs.ContractAPIs, defs.TokenPools = nil, nil, nil, nil
+			or.mdi.On("GetSubscriptionByName", mock.Anything, "default", "sub1").Return(nil, nil)
+			or.mdm.On("VerifyNamespaceExists", mock.Anything, "default").Return(fmt.Errorf("pop"))
+		}},
+	}
+	for _, f := range failures {
+		t.Run(f.name, func(t *testing.T) {
+			or := newTestOrchestrator()
+			defs := newTestBootstrapDefs(t)
+			f.setup(or, defs)
+			err := or.applyBootstrapDefinitions(or.ctx, defs, false)
+			assert.EqualError(t, err, "pop")
+		})
+	}
+}
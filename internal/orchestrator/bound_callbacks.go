@@ -72,6 +72,10 @@ func (bc *boundCallbacks) TokensApproved(plugin tokens.Plugin, approval *tokens.
 	return bc.ei.TokensApproved(plugin, approval)
 }
 
+func (bc *boundCallbacks) TokenPoolBackfillProgress(plugin tokens.Plugin, progress *tokens.TokenPoolBackfillProgress) error {
+	return bc.ei.TokenPoolBackfillProgress(plugin, progress)
+}
+
 func (bc *boundCallbacks) SharedStorageBatchDownloaded(ns, payloadRef string, data []byte) (*fftypes.UUID, error) {
 	return bc.ei.SharedStorageBatchDownloaded(bc.ss, ns, payloadRef, data)
 }
@@ -86,6 +86,11 @@ func TestBoundCallbacks(t *testing.T) {
 	err = bc.TokensApproved(mti, approval)
 	assert.EqualError(t, err, "pop")
 
+	progress := &tokens.TokenPoolBackfillProgress{}
+	mei.On("TokenPoolBackfillProgress", mti, progress).Return(fmt.Errorf("pop"))
+	err = bc.TokenPoolBackfillProgress(mti, progress)
+	assert.EqualError(t, err, "pop")
+
 	mei.On("BlockchainEvent", mock.AnythingOfType("*blockchain.EventWithSubscription")).Return(fmt.Errorf("pop"))
 	err = bc.BlockchainEvent(&blockchain.EventWithSubscription{})
 	assert.EqualError(t, err, "pop")
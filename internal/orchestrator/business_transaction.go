@@ -0,0 +1,91 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// businessNamespaces returns the namespaces searched for a business key. A namespace in the configured
+// list of linked namespaces can see the records of all the others in the list - any other namespace
+// only sees its own records.
+func businessNamespaces(ns string) []string {
+	linked := config.GetStringSlice(config.BusinessLinkedNamespaces)
+	for _, l := range linked {
+		if l == ns {
+			namespaces := []string{ns}
+			for _, other := range linked {
+				if other != ns {
+					namespaces = append(namespaces, other)
+				}
+			}
+			return namespaces
+		}
+	}
+	return []string{ns}
+}
+
+func businessKeyFilter(ctx context.Context, qf database.QueryFactory, namespaces []driver.Value, key string) database.Filter {
+	fb := qf.NewFilter(ctx)
+	return fb.And(
+		fb.Eq("businesskey", key),
+		fb.In("namespace", namespaces),
+	).Sort("created").Ascending().Limit(uint64(config.GetInt(config.BusinessMaxResults)))
+}
+
+// GetBusinessTransaction returns the messages, token transfers and operations recorded on this node
+// with the supplied business key, in the order they were created
+func (or *orchestrator) GetBusinessTransaction(ctx context.Context, ns, key string) (*fftypes.BusinessTransaction, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		// An empty key would match every record that was submitted without one
+		return nil, i18n.NewError(ctx, i18n.MsgMissingRequiredField, "key")
+	}
+	if err := fftypes.ValidateBusinessKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	namespaces := businessNamespaces(ns)
+	nsValues := make([]driver.Value, len(namespaces))
+	for i, n := range namespaces {
+		nsValues[i] = n
+	}
+
+	bt := &fftypes.BusinessTransaction{
+		BusinessKey: key,
+		Namespaces:  namespaces,
+	}
+	var err error
+	if bt.Messages, _, err = or.database.GetMessages(ctx, businessKeyFilter(ctx, database.MessageQueryFactory, nsValues, key)); err != nil {
+		return nil, err
+	}
+	if bt.TokenTransfers, _, err = or.database.GetTokenTransfers(ctx, businessKeyFilter(ctx, database.TokenTransferQueryFactory, nsValues, key)); err != nil {
+		return nil, err
+	}
+	if bt.Operations, _, err = or.database.GetOperations(ctx, businessKeyFilter(ctx, database.OperationQueryFactory, nsValues, key)); err != nil {
+		return nil, err
+	}
+	return bt, nil
+}
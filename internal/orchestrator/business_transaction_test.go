@@ -0,0 +1,128 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func businessFilterMatch(expected string) interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == expected
+	})
+}
+
+func TestGetBusinessTransaction(t *testing.T) {
+	or := newTestOrchestrator()
+	msgs := []*fftypes.Message{{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}}
+	transfers := []*fftypes.TokenTransfer{{LocalID: fftypes.NewUUID()}}
+	ops := []*fftypes.Operation{{ID: fftypes.NewUUID()}}
+	expected := "( businesskey == 'order-1' ) && ( namespace IN ['ns1'] ) sort=created limit=1000"
+	or.mdi.On("GetMessages", mock.Anything, businessFilterMatch(expected)).Return(msgs, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, businessFilterMatch(expected)).Return(transfers, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, businessFilterMatch(expected)).Return(ops, nil, nil)
+
+	bt, err := or.GetBusinessTransaction(context.Background(), "ns1", "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "order-1", bt.BusinessKey)
+	assert.Equal(t, []string{"ns1"}, bt.Namespaces)
+	assert.Equal(t, msgs, bt.Messages)
+	assert.Equal(t, transfers, bt.TokenTransfers)
+	assert.Equal(t, ops, bt.Operations)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetBusinessTransactionLinkedNamespaces(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BusinessLinkedNamespaces, []string{"ns1", "ns2", "ns3"})
+	config.Set(config.BusinessMaxResults, 10)
+	expected := "( businesskey == 'order-1' ) && ( namespace IN ['ns2','ns1','ns3'] ) sort=created limit=10"
+	or.mdi.On("GetMessages", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.Operation{}, nil, nil)
+
+	bt, err := or.GetBusinessTransaction(context.Background(), "ns2", "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns2", "ns1", "ns3"}, bt.Namespaces)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetBusinessTransactionNotLinked(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BusinessLinkedNamespaces, []string{"ns1", "ns2"})
+	expected := "( businesskey == 'order-1' ) && ( namespace IN ['ns3'] ) sort=created limit=1000"
+	or.mdi.On("GetMessages", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, businessFilterMatch(expected)).Return([]*fftypes.Operation{}, nil, nil)
+
+	bt, err := or.GetBusinessTransaction(context.Background(), "ns3", "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns3"}, bt.Namespaces)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetBusinessTransactionBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetBusinessTransaction(context.Background(), "!wrong", "order-1")
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetBusinessTransactionMissingKey(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetBusinessTransaction(context.Background(), "ns1", "")
+	assert.Regexp(t, "FF10140.*key", err)
+}
+
+func TestGetBusinessTransactionKeyTooLong(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetBusinessTransaction(context.Background(), "ns1", strings.Repeat("x", fftypes.BusinessKeyMaxLength+1))
+	assert.Regexp(t, "FF10188", err)
+}
+
+func TestGetBusinessTransactionMessagesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBusinessTransaction(context.Background(), "ns1", "order-1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBusinessTransactionTransfersFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBusinessTransaction(context.Background(), "ns1", "order-1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBusinessTransactionOperationsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBusinessTransaction(context.Background(), "ns1", "order-1")
+	assert.EqualError(t, err, "pop")
+}
@@ -18,15 +18,14 @@ package orchestrator
 
 import (
 	"context"
+	"math"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, numBuckets int64) (intervals []fftypes.ChartHistogramInterval) {
-	timeIntervalLength := (endTime - startTime) / numBuckets
-
+func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, timeIntervalLength int64) (intervals []fftypes.ChartHistogramInterval) {
 	for i := startTime; i < endTime; i += timeIntervalLength {
 		intervals = append(intervals, fftypes.ChartHistogramInterval{
 			StartTime: fftypes.UnixTime(i),
@@ -37,17 +36,44 @@ func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, nu
 	return intervals
 }
 
-func (or *orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, collection database.CollectionName) ([]*fftypes.ChartHistogram, error) {
-	if buckets > fftypes.ChartHistogramMaxBuckets || buckets < fftypes.ChartHistogramMinBuckets {
-		return nil, i18n.NewError(ctx, i18n.MsgInvalidNumberOfIntervals, fftypes.ChartHistogramMinBuckets, fftypes.ChartHistogramMaxBuckets)
+func (or *orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, collection database.CollectionName, options *fftypes.ChartHistogramOptions) ([]*fftypes.ChartHistogram, error) {
+	if options == nil {
+		options = &fftypes.ChartHistogramOptions{}
 	}
 	if startTime > endTime {
 		return nil, i18n.NewError(ctx, i18n.MsgHistogramInvalidTimes)
 	}
 
-	intervals := or.getHistogramIntervals(startTime, endTime, buckets)
+	timeIntervalLength := int64(options.Interval)
+	if timeIntervalLength > 0 {
+		// The last bucket is a full interval, even if it ends after the end time
+		buckets = (endTime - startTime + timeIntervalLength - 1) / timeIntervalLength
+	}
+	if buckets > fftypes.ChartHistogramMaxBuckets || buckets < fftypes.ChartHistogramMinBuckets {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidNumberOfIntervals, fftypes.ChartHistogramMinBuckets, fftypes.ChartHistogramMaxBuckets)
+	}
+	if timeIntervalLength <= 0 {
+		timeIntervalLength = (endTime - startTime) / buckets
+		if timeIntervalLength < 1 {
+			timeIntervalLength = 1
+		}
+	}
+
+	intervals := or.getHistogramIntervals(startTime, endTime, timeIntervalLength)
+
+	if len(options.Percentiles) > 0 {
+		if collection != database.CollectionName(database.CollectionMessages) || options.GroupBy != "" {
+			return nil, i18n.NewError(ctx, i18n.MsgHistogramLatencyCollection)
+		}
+		for _, percentile := range options.Percentiles {
+			if math.IsNaN(percentile) || percentile <= 0 || percentile > 100 {
+				return nil, i18n.NewError(ctx, i18n.MsgHistogramInvalidPercentile, percentile)
+			}
+		}
+		return or.database.GetChartLatencyHistogram(ctx, ns, intervals, options.Percentiles)
+	}
 
-	histogram, err := or.database.GetChartHistogram(ctx, ns, intervals, collection)
+	histogram, err := or.database.GetChartHistogram(ctx, ns, intervals, collection, options.GroupBy)
 	if err != nil {
 		return nil, err
 	}
@@ -19,7 +19,9 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -39,27 +41,27 @@ func makeTestIntervals(start int, numIntervals int) (intervals []fftypes.ChartHi
 
 func TestGetHistogramBadIntervalMin(t *testing.T) {
 	or := newTestOrchestrator()
-	_, err := or.GetChartHistogram(context.Background(), "ns1", 1234567890, 9876543210, fftypes.ChartHistogramMinBuckets-1, database.CollectionName("test"))
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1234567890, 9876543210, fftypes.ChartHistogramMinBuckets-1, database.CollectionName("test"), nil)
 	assert.Regexp(t, "FF10298", err)
 }
 
 func TestGetHistogramBadIntervalMax(t *testing.T) {
 	or := newTestOrchestrator()
-	_, err := or.GetChartHistogram(context.Background(), "ns1", 1234567890, 9876543210, fftypes.ChartHistogramMaxBuckets+1, database.CollectionName("test"))
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1234567890, 9876543210, fftypes.ChartHistogramMaxBuckets+1, database.CollectionName("test"), nil)
 	assert.Regexp(t, "FF10298", err)
 }
 
 func TestGetHistogramBadStartEndTimes(t *testing.T) {
 	or := newTestOrchestrator()
-	_, err := or.GetChartHistogram(context.Background(), "ns1", 9876543210, 1234567890, 10, database.CollectionName("test"))
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 9876543210, 1234567890, 10, database.CollectionName("test"), nil)
 	assert.Regexp(t, "FF10300", err)
 }
 
 func TestGetHistogramFailDB(t *testing.T) {
 	or := newTestOrchestrator()
 	intervals := makeTestIntervals(1000000000, 10)
-	or.mdi.On("GetChartHistogram", mock.Anything, "ns1", intervals, database.CollectionName("test")).Return(nil, fmt.Errorf("pop"))
-	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("test"))
+	or.mdi.On("GetChartHistogram", mock.Anything, "ns1", intervals, database.CollectionName("test"), "").Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("test"), nil)
 	assert.EqualError(t, err, "pop")
 }
 
@@ -68,7 +70,67 @@ func TestGetHistogramSuccess(t *testing.T) {
 	intervals := makeTestIntervals(1000000000, 10)
 	mockHistogram := []*fftypes.ChartHistogram{}
 
-	or.mdi.On("GetChartHistogram", mock.Anything, "ns1", intervals, database.CollectionName("test")).Return(mockHistogram, nil)
-	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("test"))
+	or.mdi.On("GetChartHistogram", mock.Anything, "ns1", intervals, database.CollectionName("test"), "").Return(mockHistogram, nil)
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("test"), nil)
 	assert.NoError(t, err)
 }
+
+func TestGetHistogramCustomInterval(t *testing.T) {
+	or := newTestOrchestrator()
+	intervals := []fftypes.ChartHistogramInterval{
+		{StartTime: fftypes.UnixTime(1000000000), EndTime: fftypes.UnixTime(1000003600)},
+		{StartTime: fftypes.UnixTime(1000003600), EndTime: fftypes.UnixTime(1000007200)},
+	}
+	mockHistogram := []*fftypes.ChartHistogram{}
+
+	or.mdi.On("GetChartHistogram", mock.Anything, "ns1", intervals, database.CollectionName("messages"), "tag").Return(mockHistogram, nil)
+	_, err := or.GetChartHistogram(context.Background(), "ns1", fftypes.UnixTime(1000000000).UnixNano(), fftypes.UnixTime(1000005000).UnixNano(), 0, database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Interval: fftypes.FFDuration(time.Hour),
+		GroupBy:  "tag",
+	})
+	assert.NoError(t, err)
+}
+
+func TestGetHistogramCustomIntervalTooSmall(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 0, int64(time.Hour), 0, database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Interval: fftypes.FFDuration(time.Second),
+	})
+	assert.Regexp(t, "FF10298", err)
+}
+
+func TestGetHistogramLatencies(t *testing.T) {
+	or := newTestOrchestrator()
+	intervals := makeTestIntervals(1000000000, 10)
+	mockHistogram := []*fftypes.ChartHistogram{}
+
+	or.mdi.On("GetChartLatencyHistogram", mock.Anything, "ns1", intervals, []float64{50, 99.9}).Return(mockHistogram, nil)
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Percentiles: []float64{50, 99.9},
+	})
+	assert.NoError(t, err)
+}
+
+func TestGetHistogramLatenciesNotMessages(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("events"), &fftypes.ChartHistogramOptions{
+		Percentiles: []float64{50},
+	})
+	assert.Regexp(t, "FF10546", err)
+}
+
+func TestGetHistogramLatenciesBadPercentile(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Percentiles: []float64{50, 101},
+	})
+	assert.Regexp(t, "FF10547", err)
+}
+
+func TestGetHistogramLatenciesNaNPercentile(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), "ns1", 1000000000, 1000000010, 10, database.CollectionName("messages"), &fftypes.ChartHistogramOptions{
+		Percentiles: []float64{math.NaN()},
+	})
+	assert.Regexp(t, "FF10547", err)
+}
@@ -0,0 +1,164 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const checkpointPageSize = 100
+
+// buildCheckpoint walks the dispatched pins in sequence order, up to the supplied sequence (or all pins if zero),
+// digesting each confirmed batch of the namespace the first time one of its pins is seen.
+// If maxBatches is non-zero, the walk stops once that many batches have been digested.
+func (or *orchestrator) buildCheckpoint(ctx context.Context, ns string, sequence int64, maxBatches int) (*fftypes.Checkpoint, error) {
+	cp := &fftypes.Checkpoint{
+		Namespace: ns,
+		Sequence:  sequence,
+		Batches:   []*fftypes.CheckpointBatch{},
+	}
+	seen := make(map[fftypes.UUID]bool)
+	lastSequence := int64(0)
+	for {
+		fb := database.PinQueryFactory.NewFilterLimit(ctx, checkpointPageSize)
+		conditions := []database.Filter{
+			fb.Gt("sequence", lastSequence),
+			fb.Eq("dispatched", true),
+		}
+		if sequence > 0 {
+			conditions = append(conditions, fb.Lte("sequence", sequence))
+		}
+		pins, _, err := or.database.GetPins(ctx, fb.And(conditions...).Sort("sequence"))
+		if err != nil {
+			return nil, err
+		}
+		for _, pin := range pins {
+			if maxBatches > 0 && len(cp.Batches) >= maxBatches {
+				return cp, nil
+			}
+			lastSequence = pin.Sequence
+			if sequence == 0 {
+				cp.Sequence = pin.Sequence
+			}
+			if pin.Batch == nil || seen[*pin.Batch] {
+				continue
+			}
+			seen[*pin.Batch] = true
+			cb, err := or.checkpointBatch(ctx, ns, pin)
+			if err != nil {
+				return nil, err
+			}
+			if cb != nil {
+				cp.Batches = append(cp.Batches, cb)
+				cp.Messages += cb.Messages
+			}
+		}
+		if len(pins) < checkpointPageSize {
+			break
+		}
+	}
+	return cp, nil
+}
+
+func (or *orchestrator) checkpointBatch(ctx context.Context, ns string, pin *fftypes.Pin) (*fftypes.CheckpointBatch, error) {
+	batch, err := or.database.GetBatchByID(ctx, pin.Batch)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil || batch.Namespace != ns || batch.Confirmed == nil {
+		return nil, nil
+	}
+	var manifest fftypes.BatchManifest
+	if err := batch.Manifest.Unmarshal(ctx, &manifest); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, "manifest")
+	}
+	leaves := make([]*fftypes.Bytes32, len(manifest.Messages))
+	for i, m := range manifest.Messages {
+		leaves[i] = m.Hash
+	}
+	return &fftypes.CheckpointBatch{
+		ID:       batch.ID,
+		Hash:     batch.Hash,
+		Sequence: pin.Sequence,
+		Messages: len(leaves),
+		Root:     fftypes.MerkleRoot(leaves),
+	}, nil
+}
+
+func checkpointRoot(batches []*fftypes.CheckpointBatch) *fftypes.Bytes32 {
+	roots := make([]*fftypes.Bytes32, len(batches))
+	for i, cb := range batches {
+		if cb != nil {
+			roots[i] = cb.Root
+		}
+	}
+	return fftypes.MerkleRoot(roots)
+}
+
+func (or *orchestrator) GetCheckpoint(ctx context.Context, ns string, sequence int64) (*fftypes.Checkpoint, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	cp, err := or.buildCheckpoint(ctx, ns, sequence, 0)
+	if err != nil {
+		return nil, err
+	}
+	cp.Root = checkpointRoot(cp.Batches)
+	return cp, nil
+}
+
+// DiffCheckpoint compares a checkpoint computed by another member against the local message stream.
+// Pin sequences are local to each member, so the comparison is made over the same number of batches,
+// rather than up to the sequence recorded in the remote checkpoint.
+func (or *orchestrator) DiffCheckpoint(ctx context.Context, ns string, remote *fftypes.Checkpoint) (*fftypes.CheckpointDiff, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	for i, r := range remote.Batches {
+		if r == nil || r.ID == nil || r.Root == nil {
+			return nil, i18n.NewError(ctx, i18n.MsgInvalidCheckpointBatch, i)
+		}
+	}
+	local, err := or.buildCheckpoint(ctx, ns, 0, len(remote.Batches))
+	if err != nil {
+		return nil, err
+	}
+	diff := &fftypes.CheckpointDiff{
+		Batches:    len(remote.Batches),
+		LocalRoot:  checkpointRoot(local.Batches),
+		RemoteRoot: checkpointRoot(remote.Batches),
+		Index:      -1,
+	}
+	for i, r := range remote.Batches {
+		var l *fftypes.CheckpointBatch
+		if i < len(local.Batches) {
+			l = local.Batches[i]
+		}
+		if l == nil || !l.ID.Equals(r.ID) || !l.Root.Equals(r.Root) {
+			diff.Index = i
+			diff.Local = l
+			diff.Remote = r
+			break
+		}
+	}
+	diff.Match = diff.Index < 0 && diff.LocalRoot.Equals(diff.RemoteRoot)
+	return diff, nil
+}
@@ -0,0 +1,269 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCheckpointBatch(ns string, msgCount int) *fftypes.BatchPersisted {
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+		},
+		Confirmed: fftypes.Now(),
+	}
+	msgs := make([]*fftypes.Message, msgCount)
+	for i := range msgs {
+		msgs[i] = &fftypes.Message{
+			Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+			Hash:   fftypes.NewRandB32(),
+		}
+	}
+	manifest := batch.GenManifest(msgs, nil, fftypes.ProtocolVersion1).String()
+	batch.Manifest = fftypes.JSONAnyPtr(manifest)
+	batch.Hash = fftypes.HashString(manifest)
+	return batch
+}
+
+func TestGetCheckpointLatest(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 3)
+	b2 := newTestCheckpointBatch("ns1", 1)
+	other := newTestCheckpointBatch("ns2", 1)
+	missing := fftypes.NewUUID()
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, b2.ID).Return(b2, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, other.ID).Return(other, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, missing).Return(nil, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{
+		{Sequence: 1, Batch: b1.ID},
+		{Sequence: 2, Batch: other.ID},
+		{Sequence: 3, Batch: b1.ID},
+		{Sequence: 4, Batch: missing},
+		{Sequence: 5},
+		{Sequence: 6, Batch: b2.ID},
+	}, nil, nil)
+
+	cp, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), cp.Sequence)
+	assert.Equal(t, 4, cp.Messages)
+	assert.Len(t, cp.Batches, 2)
+	assert.Equal(t, b1.ID, cp.Batches[0].ID)
+	assert.Equal(t, b1.Hash, cp.Batches[0].Hash)
+	assert.Equal(t, int64(1), cp.Batches[0].Sequence)
+	assert.Equal(t, 3, cp.Batches[0].Messages)
+	assert.Equal(t, b2.ID, cp.Batches[1].ID)
+	assert.Equal(t, fftypes.MerkleRoot([]*fftypes.Bytes32{cp.Batches[0].Root, cp.Batches[1].Root}), cp.Root)
+
+	// The same stream gives the same digest
+	cp2, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, cp.Root, cp2.Root)
+}
+
+func TestGetCheckpointPaged(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 1)
+	page1 := make([]*fftypes.Pin, checkpointPageSize)
+	for i := range page1 {
+		page1[i] = &fftypes.Pin{Sequence: int64(i + 1), Batch: b1.ID}
+	}
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( sequence >> 0 ) && ( dispatched == true ) && ( sequence <= 1000 ) sort=sequence limit=100"
+	})).Return(page1, nil, nil).Once()
+	or.mdi.On("GetPins", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( sequence >> 100 ) && ( dispatched == true ) && ( sequence <= 1000 ) sort=sequence limit=100"
+	})).Return([]*fftypes.Pin{}, nil, nil).Once()
+
+	cp, err := or.GetCheckpoint(context.Background(), "ns1", 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), cp.Sequence)
+	assert.Len(t, cp.Batches, 1)
+	assert.Equal(t, cp.Batches[0].Root, cp.Root)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetCheckpointEmpty(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+
+	cp, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, cp.Root)
+	assert.Empty(t, cp.Batches)
+}
+
+func TestGetCheckpointBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetCheckpoint(context.Background(), "!wrong", 0)
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetCheckpointPinsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetCheckpointBatchFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 1, Batch: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetCheckpointBadManifest(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 1)
+	b1.Manifest = fftypes.JSONAnyPtr("!json")
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 1, Batch: b1.ID}}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+
+	_, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.Regexp(t, "FF10151", err)
+}
+
+func TestDiffCheckpointMatch(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 2)
+	b2 := newTestCheckpointBatch("ns1", 1)
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, b2.ID).Return(b2, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{
+		{Sequence: 10, Batch: b1.ID},
+		{Sequence: 11, Batch: b2.ID},
+	}, nil, nil)
+
+	local, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+
+	// The remote member only has the first batch, at a different sequence
+	remote := &fftypes.Checkpoint{
+		Namespace: "ns1",
+		Sequence:  5,
+		Root:      local.Batches[0].Root,
+		Batches:   []*fftypes.CheckpointBatch{local.Batches[0]},
+	}
+	diff, err := or.DiffCheckpoint(context.Background(), "ns1", remote)
+	assert.NoError(t, err)
+	assert.True(t, diff.Match)
+	assert.Equal(t, 1, diff.Batches)
+	assert.Equal(t, -1, diff.Index)
+	assert.Equal(t, local.Batches[0].Root, diff.LocalRoot)
+}
+
+func TestDiffCheckpointMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 2)
+	b2 := newTestCheckpointBatch("ns1", 1)
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, b2.ID).Return(b2, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{
+		{Sequence: 1, Batch: b1.ID},
+		{Sequence: 2, Batch: b2.ID},
+	}, nil, nil)
+
+	local, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+
+	remoteBatch := *local.Batches[1]
+	remoteBatch.Root = fftypes.NewRandB32()
+	remote := &fftypes.Checkpoint{
+		Namespace: "ns1",
+		Batches:   []*fftypes.CheckpointBatch{local.Batches[0], &remoteBatch},
+	}
+	diff, err := or.DiffCheckpoint(context.Background(), "ns1", remote)
+	assert.NoError(t, err)
+	assert.False(t, diff.Match)
+	assert.Equal(t, 1, diff.Index)
+	assert.Equal(t, local.Batches[1], diff.Local)
+	assert.Equal(t, &remoteBatch, diff.Remote)
+	assert.NotEqual(t, diff.LocalRoot, diff.RemoteRoot)
+}
+
+func TestDiffCheckpointMissingLocal(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+
+	remote := &fftypes.Checkpoint{
+		Namespace: "ns1",
+		Batches:   []*fftypes.CheckpointBatch{{ID: fftypes.NewUUID(), Root: fftypes.NewRandB32()}},
+	}
+	diff, err := or.DiffCheckpoint(context.Background(), "ns1", remote)
+	assert.NoError(t, err)
+	assert.False(t, diff.Match)
+	assert.Equal(t, 0, diff.Index)
+	assert.Nil(t, diff.Local)
+	assert.Equal(t, remote.Batches[0], diff.Remote)
+}
+
+func TestDiffCheckpointBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.DiffCheckpoint(context.Background(), "!wrong", &fftypes.Checkpoint{})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestDiffCheckpointFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.DiffCheckpoint(context.Background(), "ns1", &fftypes.Checkpoint{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDiffCheckpointInvalidBatches(t *testing.T) {
+	or := newTestOrchestrator()
+
+	for _, batches := range [][]*fftypes.CheckpointBatch{
+		{nil},
+		{{Root: fftypes.NewRandB32()}},
+		{{ID: fftypes.NewUUID(), Root: fftypes.NewRandB32()}, {ID: fftypes.NewUUID()}},
+	} {
+		_, err := or.DiffCheckpoint(context.Background(), "ns1", &fftypes.Checkpoint{Batches: batches})
+		assert.Regexp(t, "FF10567", err)
+	}
+}
+
+func TestGetCheckpointNilMessageHash(t *testing.T) {
+	or := newTestOrchestrator()
+	b1 := newTestCheckpointBatch("ns1", 1)
+	b1.Manifest = fftypes.JSONAnyPtr(`{"messages":[{"id":"` + fftypes.NewUUID().String() + `"}]}`)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 1, Batch: b1.ID}}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, b1.ID).Return(b1, nil)
+
+	cp, err := or.GetCheckpoint(context.Background(), "ns1", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, cp.Batches[0].Root)
+	assert.Nil(t, cp.Root)
+}
@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) validateListenerTemplates(ctx context.Context, templates []*fftypes.ContractAPIListenerTemplate) error {
+	for i, lt := range templates {
+		if lt.Event == "" {
+			return i18n.NewError(ctx, i18n.MsgListenerTemplateNoEvent, i)
+		}
+		if lt.Name != "" {
+			if err := fftypes.ValidateFFNameField(ctx, lt.Name, "name"); err != nil {
+				return err
+			}
+		}
+		if sub := lt.Subscription; sub != nil {
+			if err := fftypes.ValidateFFNameFieldNoUUID(ctx, sub.Name, "name"); err != nil {
+				return err
+			}
+			if sub.Transport == "" {
+				return i18n.NewError(ctx, i18n.MsgListenerTemplateNoTransport, i)
+			}
+			if sub.Transport == system.SystemEventsTransport {
+				return i18n.NewError(ctx, i18n.MsgSystemTransportInternal)
+			}
+		}
+	}
+	return nil
+}
+
+// BroadcastContractAPI creates the listeners (and subscriptions) declared in the templates of a contract API,
+// then broadcasts its definition. The listeners are local to this node. Creating a listener subscribes in the
+// blockchain connector, which cannot be rolled back with a database transaction, so each is created in turn,
+// and all of them are removed again if a later step fails. The broadcast is the last step, so a definition
+// is only broadcast once everything it declares exists.
+func (or *orchestrator) BroadcastContractAPI(ctx context.Context, httpServerURL, ns string, api *fftypes.ContractAPIWithListeners, waitConfirm bool) (_ *fftypes.ContractAPIWithListeners, err error) {
+	if err := or.validateListenerTemplates(ctx, api.Listeners); err != nil {
+		return nil, err
+	}
+
+	var listenerIDs, subscriptionIDs []*fftypes.UUID
+	defer func() {
+		if err != nil {
+			or.removeListenerTemplates(ctx, ns, listenerIDs, subscriptionIDs)
+		}
+	}()
+
+	for _, lt := range api.Listeners {
+		listener, err := or.contracts.AddContractListener(ctx, ns, &fftypes.ContractListenerInput{
+			ContractListener: fftypes.ContractListener{
+				Name:      lt.Name,
+				Interface: api.Interface,
+				Location:  api.Location,
+				Event: &fftypes.FFISerializedEvent{
+					FFIEventDefinition: fftypes.FFIEventDefinition{Name: lt.Event},
+				},
+				Topic:   lt.Topic,
+				Options: lt.Options,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		listenerIDs = append(listenerIDs, listener.ID)
+		lt.Listener = listener.ID
+
+		if lt.Subscription != nil {
+			sub, err := or.createUpdateSubscription(ctx, ns, &fftypes.Subscription{
+				SubscriptionRef: fftypes.SubscriptionRef{
+					Name: lt.Subscription.Name,
+				},
+				Transport: lt.Subscription.Transport,
+				Options:   lt.Subscription.Options,
+				Filter: fftypes.SubscriptionFilter{
+					Events: fftypes.EventTypeBlockchainEventReceived.String(),
+					BlockchainEvent: fftypes.BlockchainEventFilter{
+						Listener: listener.ID.String(),
+					},
+				},
+			}, true)
+			if err != nil {
+				return nil, err
+			}
+			subscriptionIDs = append(subscriptionIDs, sub.ID)
+			lt.Subscription.ID = sub.ID
+			// The response does not echo secret transport options, such as webhook signing secrets
+			redacted, err := or.redactSubscription(ctx, sub)
+			if err != nil {
+				return nil, err
+			}
+			lt.Subscription.Options = redacted.Options
+		}
+	}
+
+	if _, err := or.contracts.BroadcastContractAPI(ctx, httpServerURL, ns, &api.ContractAPI, waitConfirm); err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+// removeListenerTemplates removes the listeners and subscriptions created for a contract API that failed.
+// Failures are logged, as the original error is the one returned.
+func (or *orchestrator) removeListenerTemplates(ctx context.Context, ns string, listenerIDs, subscriptionIDs []*fftypes.UUID) {
+	for _, id := range subscriptionIDs {
+		if err := or.DeleteSubscription(ctx, ns, id.String()); err != nil {
+			log.L(ctx).Errorf("Failed to remove subscription %s created for contract API: %s", id, err)
+		}
+	}
+	for _, id := range listenerIDs {
+		if err := or.contracts.DeleteContractListenerByNameOrID(ctx, ns, id.String()); err != nil {
+			log.L(ctx).Errorf("Failed to remove contract listener %s created for contract API: %s", id, err)
+		}
+	}
+}
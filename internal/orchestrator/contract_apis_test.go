@@ -0,0 +1,205 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/events/webhooks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestContractAPIWithListeners() *fftypes.ContractAPIWithListeners {
+	return &fftypes.ContractAPIWithListeners{
+		ContractAPI: fftypes.ContractAPI{
+			Name: "banana",
+			Interface: &fftypes.FFIReference{
+				ID: fftypes.NewUUID(),
+			},
+			Location: fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+		},
+		Listeners: []*fftypes.ContractAPIListenerTemplate{
+			{
+				Name:  "changed",
+				Event: "Changed",
+				Topic: "bananas",
+				Subscription: &fftypes.ContractAPISubscriptionTemplate{
+					Name:      "changed",
+					Transport: "websockets",
+				},
+			},
+			{
+				Event: "Peeled",
+			},
+		},
+	}
+}
+
+func TestBroadcastContractAPIWithListeners(t *testing.T) {
+	or := newTestOrchestrator()
+	api := newTestContractAPIWithListeners()
+	api.Listeners[0].Subscription.Transport = "webhooks"
+	err := json.Unmarshal([]byte(`{"url":"http://example.com","signing":{"secret":"secret1"}}`), &api.Listeners[0].Subscription.Options)
+	assert.NoError(t, err)
+
+	listenerID1 := fftypes.NewUUID()
+	listenerID2 := fftypes.NewUUID()
+	or.mcm.On("BroadcastContractAPI", mock.Anything, "http://localhost/api/v1", "ns1", &api.ContractAPI, false).Return(&api.ContractAPI, nil)
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.MatchedBy(func(l *fftypes.ContractListenerInput) bool {
+		return l.Name == "changed" && l.Event.Name == "Changed" && l.Topic == "bananas" &&
+			l.Interface == api.Interface && l.Location == api.Location
+	})).Return(&fftypes.ContractListener{ID: listenerID1}, nil)
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.MatchedBy(func(l *fftypes.ContractListenerInput) bool {
+		return l.Name == "" && l.Event.Name == "Peeled"
+	})).Return(&fftypes.ContractListener{ID: listenerID2}, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.MatchedBy(func(sub *fftypes.Subscription) bool {
+		return sub.Name == "changed" && sub.Transport == "webhooks" &&
+			sub.Filter.Events == "blockchain_event_received" &&
+			sub.Filter.BlockchainEvent.Listener == listenerID1.String() &&
+			sub.Options.TransportOptions().GetObject("signing").GetString("secret") == "secret1"
+	}), true).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{"webhooks": &webhooks.WebHooks{}})
+
+	res, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.NoError(t, err)
+	assert.Equal(t, listenerID1, res.Listeners[0].Listener)
+	assert.NotNil(t, res.Listeners[0].Subscription.ID)
+	b, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret1")
+	assert.Equal(t, listenerID2, res.Listeners[1].Listener)
+
+	or.mcm.AssertExpectations(t)
+	or.mem.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPIBroadcastFail(t *testing.T) {
+	or := newTestOrchestrator()
+	api := newTestContractAPIWithListeners()
+
+	listenerID1 := fftypes.NewUUID()
+	listenerID2 := fftypes.NewUUID()
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.MatchedBy(func(l *fftypes.ContractListenerInput) bool {
+		return l.Name == "changed"
+	})).Return(&fftypes.ContractListener{ID: listenerID1}, nil)
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.MatchedBy(func(l *fftypes.ContractListenerInput) bool {
+		return l.Name == ""
+	})).Return(&fftypes.ContractListener{ID: listenerID2}, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{})
+	or.mcm.On("BroadcastContractAPI", mock.Anything, "http://localhost/api/v1", "ns1", &api.ContractAPI, true).Return(nil, fmt.Errorf("pop"))
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1"}}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(sub, nil)
+	or.mem.On("DeleteDurableSubscription", mock.Anything, sub).Return(nil)
+	or.mcm.On("DeleteContractListenerByNameOrID", mock.Anything, "ns1", listenerID1.String()).Return(nil)
+	or.mcm.On("DeleteContractListenerByNameOrID", mock.Anything, "ns1", listenerID2.String()).Return(nil)
+
+	_, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, true)
+	assert.EqualError(t, err, "pop")
+
+	or.mcm.AssertExpectations(t)
+	or.mem.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPIListenerFail(t *testing.T) {
+	or := newTestOrchestrator()
+	api := newTestContractAPIWithListeners()
+
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.EqualError(t, err, "pop")
+
+	or.mcm.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPISubscriptionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	api := newTestContractAPIWithListeners()
+
+	listenerID := fftypes.NewUUID()
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.Anything).Return(&fftypes.ContractListener{ID: listenerID}, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+	or.mcm.On("DeleteContractListenerByNameOrID", mock.Anything, "ns1", listenerID.String()).Return(fmt.Errorf("pop2"))
+
+	_, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.EqualError(t, err, "pop")
+
+	or.mcm.AssertExpectations(t)
+	or.mem.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPIRemoveSubscriptionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	api := newTestContractAPIWithListeners()
+	api.Listeners = api.Listeners[0:1]
+
+	listenerID := fftypes.NewUUID()
+	or.mcm.On("AddContractListener", mock.Anything, "ns1", mock.Anything).Return(&fftypes.ContractListener{ID: listenerID}, nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{})
+	or.mcm.On("BroadcastContractAPI", mock.Anything, "http://localhost/api/v1", "ns1", &api.ContractAPI, false).Return(nil, fmt.Errorf("pop"))
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop2"))
+	or.mcm.On("DeleteContractListenerByNameOrID", mock.Anything, "ns1", listenerID.String()).Return(nil)
+
+	_, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.EqualError(t, err, "pop")
+
+	or.mcm.AssertExpectations(t)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestBroadcastContractAPIBadTemplates(t *testing.T) {
+	or := newTestOrchestrator()
+
+	api := newTestContractAPIWithListeners()
+	api.Listeners[1].Event = ""
+	_, err := or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.Regexp(t, "FF10419.*1", err)
+
+	api = newTestContractAPIWithListeners()
+	api.Listeners[0].Name = "!wrong"
+	_, err = or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.Regexp(t, "FF10131", err)
+
+	api = newTestContractAPIWithListeners()
+	api.Listeners[0].Subscription.Name = "!wrong"
+	_, err = or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.Regexp(t, "FF10131", err)
+
+	api = newTestContractAPIWithListeners()
+	api.Listeners[0].Subscription.Transport = ""
+	_, err = or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.Regexp(t, "FF10420.*0", err)
+
+	api = newTestContractAPIWithListeners()
+	api.Listeners[0].Subscription.Transport = system.SystemEventsTransport
+	_, err = or.BroadcastContractAPI(or.ctx, "http://localhost/api/v1", "ns1", api, false)
+	assert.Regexp(t, "FF10266", err)
+
+	or.mcm.AssertExpectations(t)
+}
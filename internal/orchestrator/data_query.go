@@ -19,8 +19,10 @@ package orchestrator
 import (
 	"context"
 	"database/sql/driver"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -63,6 +65,19 @@ func (or *orchestrator) GetTransactionOperations(ctx context.Context, ns, id str
 	return or.database.GetOperations(ctx, filter)
 }
 
+func (or *orchestrator) GetOperationsWithOutput(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error) {
+	ops, fr, err := or.GetOperations(ctx, ns, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, op := range ops {
+		if err := or.fetchOperationOutput(ctx, op); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ops, fr, nil
+}
+
 func (or *orchestrator) getMessageByID(ctx context.Context, ns, id string) (*fftypes.Message, error) {
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
@@ -105,7 +120,31 @@ func (or *orchestrator) GetBatchByID(ctx context.Context, ns, id string) (*fftyp
 	if err != nil {
 		return nil, err
 	}
-	return or.database.GetBatchByID(ctx, u)
+	batch, err := or.database.GetBatchByID(ctx, u)
+	if err != nil || batch == nil || batch.Group == nil || batch.TX.ID == nil {
+		return batch, err
+	}
+
+	// Report the data exchange send of a private batch to each of the nodes it was sent to
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := or.database.GetOperations(ctx, fb.And(
+		fb.Eq("tx", batch.TX.ID),
+		fb.Eq("type", fftypes.OpTypeDataExchangeSendBatch),
+	))
+	if err != nil {
+		return nil, err
+	}
+	batch.Recipients = make([]*fftypes.BatchRecipientStatus, 0, len(ops))
+	for _, op := range ops {
+		if op.Retry == nil {
+			nodeID, _ := fftypes.ParseUUID(ctx, op.Input.GetString("node"))
+			batch.Recipients = append(batch.Recipients, &fftypes.BatchRecipientStatus{
+				Node: nodeID,
+				Send: txOperationStatus(op),
+			})
+		}
+	}
+	return batch, nil
 }
 
 func (or *orchestrator) GetDataByID(ctx context.Context, ns, id string) (*fftypes.Data, error) {
@@ -142,6 +181,31 @@ func (or *orchestrator) GetOperationByID(ctx context.Context, ns, id string) (*f
 	return or.database.GetOperationByID(ctx, u)
 }
 
+func (or *orchestrator) GetOperationByIDWithOutput(ctx context.Context, ns, id string) (*fftypes.Operation, error) {
+	op, err := or.GetOperationByID(ctx, ns, id)
+	if err != nil || op == nil {
+		return op, err
+	}
+	return op, or.fetchOperationOutput(ctx, op)
+}
+
+// fetchOperationOutput resolves an output that was too large to store inline, from the operation output record it was stored in
+func (or *orchestrator) fetchOperationOutput(ctx context.Context, op *fftypes.Operation) error {
+	if op.OutputRef == nil {
+		return nil
+	}
+	output, err := or.database.GetOperationOutputByID(ctx, op.OutputRef)
+	if err != nil {
+		return err
+	}
+	if output == nil {
+		log.L(ctx).Warnf("Output %s for operation %s not found", op.OutputRef, op.ID)
+		return nil
+	}
+	op.Output = output.Value.JSONObject()
+	return nil
+}
+
 func (or *orchestrator) GetEventByID(ctx context.Context, ns, id string) (*fftypes.Event, error) {
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
@@ -163,6 +227,11 @@ func (or *orchestrator) GetTransactions(ctx context.Context, ns string, filter d
 	return or.database.GetTransactions(ctx, filter)
 }
 
+func (or *orchestrator) GetFeeSummaries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.FeeSummary, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetFeeSummaries(ctx, filter)
+}
+
 func (or *orchestrator) GetMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
 	return or.database.GetMessages(ctx, filter)
@@ -253,6 +322,15 @@ func (or *orchestrator) GetMessageEvents(ctx context.Context, ns, id string, fil
 	return or.database.GetEvents(ctx, filter)
 }
 
+func (or *orchestrator) GetMessageRecipients(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.MessageRecipient, *database.FilterResult, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil || msg == nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("message", msg.Header.ID))
+	return or.database.GetMessageRecipients(ctx, or.scopeNS(ns, filter))
+}
+
 func (or *orchestrator) GetBatches(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BatchPersisted, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
 	return or.database.GetBatches(ctx, filter)
@@ -295,6 +373,46 @@ func (or *orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filt
 	return or.database.GetBlockchainEvents(ctx, or.scopeNS(ns, filter))
 }
 
+func (or *orchestrator) fetchBlockchainEventRaw(ctx context.Context, event *fftypes.BlockchainEvent) error {
+	raw, err := or.database.GetBlockchainEventRaw(ctx, event.ID)
+	if err != nil || raw == nil {
+		return err
+	}
+	event.Info, err = raw.Info(ctx)
+	return err
+}
+
+func (or *orchestrator) GetBlockchainEventByIDWithRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error) {
+	event, err := or.database.GetBlockchainEventByID(ctx, id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	if err := or.fetchBlockchainEventRaw(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (or *orchestrator) GetBlockchainEventsWithRaw(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
+	events, fr, err := or.database.GetBlockchainEvents(ctx, or.scopeNS(ns, filter))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, event := range events {
+		if err := or.fetchBlockchainEventRaw(ctx, event); err != nil {
+			return nil, nil, err
+		}
+	}
+	return events, fr, nil
+}
+
+func (or *orchestrator) Search(ctx context.Context, ns, query string, filter database.AndFilter) ([]*fftypes.SearchResult, *database.FilterResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgMissingRequiredField, "q")
+	}
+	return or.database.Search(ctx, query, or.scopeNS(ns, filter))
+}
+
 func (or *orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
@@ -80,6 +80,15 @@ func TestGetTransactions(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetFeeSummaries(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetFeeSummaries", mock.Anything, mock.Anything).Return([]*fftypes.FeeSummary{}, nil, nil)
+	fb := database.FeeSummaryQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("key", "0x12345"))
+	_, _, err := or.GetFeeSummaries(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
 func TestGetMessageByIDBadID(t *testing.T) {
 	or := newTestOrchestrator()
 	_, err := or.GetMessageByID(context.Background(), "", "")
@@ -428,6 +437,36 @@ func TestGetMessageEventsBadMsgID(t *testing.T) {
 	assert.Nil(t, ev)
 }
 
+func TestGetMessageRecipientsOk(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	or.mdi.On("GetMessageRecipients", mock.Anything, mock.Anything).Return([]*fftypes.MessageRecipient{}, nil, nil)
+	fb := database.MessageRecipientQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("status", fftypes.MessageRecipientStatusPending))
+	_, _, err := or.GetMessageRecipients(context.Background(), "ns1", fftypes.NewUUID().String(), f)
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[1].Arguments[1].(database.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(
+		`( status == 'pending' ) && ( message == '%s' ) && ( namespace == 'ns1' )`, msg.Header.ID,
+	), calculatedFilter.String())
+}
+
+func TestGetMessageRecipientsBadMsgID(t *testing.T) {
+	or := newTestOrchestrator()
+	fb := database.MessageRecipientQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, nil)
+	recipients, _, err := or.GetMessageRecipients(context.Background(), "ns1", fftypes.NewUUID().String(), f)
+	assert.Regexp(t, "FF10109", err)
+	assert.Nil(t, recipients)
+}
+
 func TestGetBatchByID(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
@@ -436,6 +475,43 @@ func TestGetBatchByID(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetBatchByIDPrivateRecipients(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	node1 := fftypes.NewUUID()
+	node2 := fftypes.NewUUID()
+	or.mdi.On("GetBatchByID", mock.Anything, u).Return(&fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: u, Group: fftypes.NewRandB32()},
+		TX:          fftypes.TransactionRef{ID: txID},
+	}, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{ID: fftypes.NewUUID(), Status: fftypes.OpStatusFailed, Input: fftypes.JSONObject{"node": node1.String()}, Retry: fftypes.NewUUID()},
+		{ID: fftypes.NewUUID(), Status: fftypes.OpStatusSucceeded, Input: fftypes.JSONObject{"node": node1.String()}},
+		{ID: fftypes.NewUUID(), Status: fftypes.OpStatusPending, Error: "pop", Input: fftypes.JSONObject{"node": node2.String()}},
+	}, nil, nil)
+	batch, err := or.GetBatchByID(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Len(t, batch.Recipients, 2)
+	assert.Equal(t, node1, batch.Recipients[0].Node)
+	assert.Equal(t, fftypes.OpStatusSucceeded, batch.Recipients[0].Send.Status)
+	assert.Equal(t, node2, batch.Recipients[1].Node)
+	assert.Equal(t, fftypes.OpStatusPending, batch.Recipients[1].Send.Status)
+	assert.Equal(t, "pop", batch.Recipients[1].Send.Error)
+}
+
+func TestGetBatchByIDPrivateRecipientsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetBatchByID", mock.Anything, u).Return(&fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{ID: u, Group: fftypes.NewRandB32()},
+		TX:          fftypes.TransactionRef{ID: fftypes.NewUUID()},
+	}, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBatchByID(context.Background(), "ns1", u.String())
+	assert.EqualError(t, err, "pop")
+}
+
 func TestGetBatchByIDBadID(t *testing.T) {
 	or := newTestOrchestrator()
 	_, err := or.GetBatchByID(context.Background(), "", "")
@@ -676,6 +752,97 @@ func TestGetBlockchainEvents(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetBlockchainEventByIDWithRaw(t *testing.T) {
+	or := newTestOrchestrator()
+
+	event := &fftypes.BlockchainEvent{
+		ID:   fftypes.NewUUID(),
+		Info: fftypes.JSONObject{"truncated": true},
+	}
+	raw, err := fftypes.NewBlockchainEventRaw(event, fftypes.JSONObject{"logs": "full"}, true)
+	assert.NoError(t, err)
+	or.mdi.On("GetBlockchainEventByID", context.Background(), event.ID).Return(event, nil)
+	or.mdi.On("GetBlockchainEventRaw", context.Background(), event.ID).Return(raw, nil)
+
+	res, err := or.GetBlockchainEventByIDWithRaw(context.Background(), event.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "full", res.Info.GetString("logs"))
+}
+
+func TestGetBlockchainEventByIDWithRawNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+
+	id := fftypes.NewUUID()
+	or.mdi.On("GetBlockchainEventByID", context.Background(), id).Return(nil, nil)
+
+	res, err := or.GetBlockchainEventByIDWithRaw(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestGetBlockchainEventByIDWithRawNotStored(t *testing.T) {
+	or := newTestOrchestrator()
+
+	event := &fftypes.BlockchainEvent{
+		ID:   fftypes.NewUUID(),
+		Info: fftypes.JSONObject{"blockNumber": "10"},
+	}
+	or.mdi.On("GetBlockchainEventByID", context.Background(), event.ID).Return(event, nil)
+	or.mdi.On("GetBlockchainEventRaw", context.Background(), event.ID).Return(nil, nil)
+
+	res, err := or.GetBlockchainEventByIDWithRaw(context.Background(), event.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", res.Info.GetString("blockNumber"))
+}
+
+func TestGetBlockchainEventByIDWithRawFail(t *testing.T) {
+	or := newTestOrchestrator()
+
+	event := &fftypes.BlockchainEvent{ID: fftypes.NewUUID()}
+	or.mdi.On("GetBlockchainEventByID", context.Background(), event.ID).Return(event, nil)
+	or.mdi.On("GetBlockchainEventRaw", context.Background(), event.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.GetBlockchainEventByIDWithRaw(context.Background(), event.ID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockchainEventsWithRaw(t *testing.T) {
+	or := newTestOrchestrator()
+
+	event := &fftypes.BlockchainEvent{ID: fftypes.NewUUID()}
+	raw := &fftypes.BlockchainEventRaw{ID: event.ID, Data: []byte(`{"logs":"full"}`)}
+	or.mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{event}, nil, nil)
+	or.mdi.On("GetBlockchainEventRaw", context.Background(), event.ID).Return(raw, nil)
+
+	f := database.BlockchainEventQueryFactory.NewFilter(context.Background())
+	res, _, err := or.GetBlockchainEventsWithRaw(context.Background(), "ns", f.And())
+	assert.NoError(t, err)
+	assert.Equal(t, "full", res[0].Info.GetString("logs"))
+}
+
+func TestGetBlockchainEventsWithRawQueryFail(t *testing.T) {
+	or := newTestOrchestrator()
+
+	or.mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	f := database.BlockchainEventQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetBlockchainEventsWithRaw(context.Background(), "ns", f.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockchainEventsWithRawBadRaw(t *testing.T) {
+	or := newTestOrchestrator()
+
+	event := &fftypes.BlockchainEvent{ID: fftypes.NewUUID()}
+	raw := &fftypes.BlockchainEventRaw{ID: event.ID, Compressed: true, Data: []byte(`not gzip`)}
+	or.mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{event}, nil, nil)
+	or.mdi.On("GetBlockchainEventRaw", context.Background(), event.ID).Return(raw, nil)
+
+	f := database.BlockchainEventQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetBlockchainEventsWithRaw(context.Background(), "ns", f.And())
+	assert.Regexp(t, "FF10467", err)
+}
+
 func TestGetTransactionBlockchainEventsOk(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
@@ -698,3 +865,96 @@ func TestGetPins(t *testing.T) {
 	_, _, err := or.GetPins(context.Background(), f)
 	assert.NoError(t, err)
 }
+
+func TestSearch(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("Search", mock.Anything, "hello world", mock.Anything).Return([]*fftypes.SearchResult{}, nil, nil)
+	fb := database.SearchQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("type", fftypes.SearchResultTypeMessage))
+	_, _, err := or.Search(context.Background(), "ns1", "hello world", f)
+	assert.NoError(t, err)
+}
+
+func TestSearchMissingQuery(t *testing.T) {
+	or := newTestOrchestrator()
+	fb := database.SearchQueryFactory.NewFilter(context.Background())
+	_, _, err := or.Search(context.Background(), "ns1", " ", fb.And())
+	assert.Regexp(t, "FF10140", err)
+}
+
+func TestGetOperationByIDWithOutput(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	outputRef := fftypes.NewUUID()
+	or.mdi.On("GetOperationByID", mock.Anything, u).Return(&fftypes.Operation{ID: u, OutputRef: outputRef}, nil)
+	or.mdi.On("GetOperationOutputByID", mock.Anything, outputRef).Return(&fftypes.OperationOutput{
+		Value: fftypes.JSONAnyPtr(`{"big": "output"}`),
+	}, nil)
+	op, err := or.GetOperationByIDWithOutput(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "output", op.Output.GetString("big"))
+}
+
+func TestGetOperationByIDWithOutputInline(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetOperationByID", mock.Anything, u).Return(&fftypes.Operation{ID: u, Output: fftypes.JSONObject{"small": "output"}}, nil)
+	op, err := or.GetOperationByIDWithOutput(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "output", op.Output.GetString("small"))
+}
+
+func TestGetOperationByIDWithOutputNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetOperationByID", mock.Anything, u).Return(nil, nil)
+	op, err := or.GetOperationByIDWithOutput(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Nil(t, op)
+}
+
+func TestGetOperationByIDWithOutputMissing(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	outputRef := fftypes.NewUUID()
+	or.mdi.On("GetOperationByID", mock.Anything, u).Return(&fftypes.Operation{ID: u, OutputRef: outputRef}, nil)
+	or.mdi.On("GetOperationOutputByID", mock.Anything, outputRef).Return(nil, nil)
+	op, err := or.GetOperationByIDWithOutput(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Nil(t, op.Output)
+}
+
+func TestGetOperationsWithOutput(t *testing.T) {
+	or := newTestOrchestrator()
+	outputRef := fftypes.NewUUID()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{ID: fftypes.NewUUID(), OutputRef: outputRef},
+	}, nil, nil)
+	or.mdi.On("GetOperationOutputByID", mock.Anything, outputRef).Return(&fftypes.OperationOutput{
+		Value: fftypes.JSONAnyPtr(`{"big": "output"}`),
+	}, nil)
+	fb := database.OperationQueryFactory.NewFilter(context.Background())
+	ops, _, err := or.GetOperationsWithOutput(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Equal(t, "output", ops[0].Output.GetString("big"))
+}
+
+func TestGetOperationsWithOutputFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	fb := database.OperationQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetOperationsWithOutput(context.Background(), "ns1", fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetOperationsWithOutputGetFail(t *testing.T) {
+	or := newTestOrchestrator()
+	outputRef := fftypes.NewUUID()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{ID: fftypes.NewUUID(), OutputRef: outputRef},
+	}, nil, nil)
+	or.mdi.On("GetOperationOutputByID", mock.Anything, outputRef).Return(nil, fmt.Errorf("pop"))
+	fb := database.OperationQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetOperationsWithOutput(context.Background(), "ns1", fb.And())
+	assert.EqualError(t, err, "pop")
+}
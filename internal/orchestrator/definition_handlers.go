@@ -0,0 +1,51 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/definitions"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// RegisterDefinitionHandler registers a handler for definitions broadcast with an application specific tag, such as
+// "myapp:config". Handlers must be registered before Init, which passes them to the definition handlers before the
+// aggregator processes any pins.
+func (or *orchestrator) RegisterDefinitionHandler(ctx context.Context, tag string, handler definitions.CustomHandler) error {
+	if err := fftypes.ValidateCustomDefinitionTag(ctx, tag); err != nil {
+		return err
+	}
+	if _, exists := or.definitionHandlers[tag]; exists {
+		return i18n.NewError(ctx, i18n.MsgDefinitionHandlerRegistered, tag)
+	}
+	if or.definitionHandlers == nil {
+		or.definitionHandlers = make(map[string]definitions.CustomHandler)
+	}
+	or.definitionHandlers[tag] = handler
+	return nil
+}
+
+func (or *orchestrator) registerDefinitionHandlers(ctx context.Context) error {
+	for tag, handler := range or.definitionHandlers {
+		if err := or.definitions.RegisterCustomHandler(ctx, tag, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
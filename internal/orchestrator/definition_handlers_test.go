@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/definitionsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterDefinitionHandler(t *testing.T) {
+	or := newTestOrchestrator()
+	mdh := &definitionsmocks.DefinitionHandlers{}
+	or.definitions = mdh
+	mch := &definitionsmocks.CustomHandler{}
+
+	err := or.RegisterDefinitionHandler(context.Background(), "myapp:config", mch)
+	assert.NoError(t, err)
+
+	mdh.On("RegisterCustomHandler", context.Background(), "myapp:config", mch).Return(nil)
+	err = or.registerDefinitionHandlers(context.Background())
+	assert.NoError(t, err)
+
+	mdh.AssertExpectations(t)
+}
+
+func TestRegisterDefinitionHandlerInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+
+	err := or.RegisterDefinitionHandler(context.Background(), "myapp:config:v1", &definitionsmocks.CustomHandler{})
+	assert.Regexp(t, "FF10131", err)
+
+	err = or.RegisterDefinitionHandler(context.Background(), fftypes.SystemTagDefinePool, &definitionsmocks.CustomHandler{})
+	assert.Regexp(t, "FF10384", err)
+}
+
+func TestRegisterDefinitionHandlerDuplicate(t *testing.T) {
+	or := newTestOrchestrator()
+
+	err := or.RegisterDefinitionHandler(context.Background(), "myapp:config", &definitionsmocks.CustomHandler{})
+	assert.NoError(t, err)
+	err = or.RegisterDefinitionHandler(context.Background(), "myapp:config", &definitionsmocks.CustomHandler{})
+	assert.Regexp(t, "FF10385", err)
+}
+
+func TestRegisterDefinitionHandlersFail(t *testing.T) {
+	or := newTestOrchestrator()
+	mdh := &definitionsmocks.DefinitionHandlers{}
+	or.definitions = mdh
+	mch := &definitionsmocks.CustomHandler{}
+
+	err := or.RegisterDefinitionHandler(context.Background(), "myapp:config", mch)
+	assert.NoError(t, err)
+
+	mdh.On("RegisterCustomHandler", context.Background(), "myapp:config", mch).Return(fmt.Errorf("pop"))
+	err = or.registerDefinitionHandlers(context.Background())
+	assert.EqualError(t, err, "pop")
+
+	mdh.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// devKeysSupported is true when the blockchain plugin uses the Ethereum addresses that development keys derive
+func devKeysSupported() bool {
+	return config.GetString(config.BlockchainType) == (*ethereum.Ethereum)(nil).Name()
+}
+
+// devIdentityKeys derives the keys for the org (unless a key is configured explicitly) and for each of
+// the custom identities. The org key is set into the config, so every component uses it.
+// Keys are only derived for the ethereum plugin, as other plugins have their own form of signing key.
+func devIdentityKeys(ctx context.Context) ([]*identity.DevKey, error) {
+	seed := config.GetString(config.DevIdentitiesSeed)
+	orgName := config.GetString(config.OrgName)
+	if orgName == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgDevIdentitiesNoOrgName)
+	}
+	keys := make([]*identity.DevKey, 0)
+	if !devKeysSupported() {
+		log.L(ctx).Warnf("Development identity keys are not derived for blockchain type '%s' - only the org and node are registered", config.GetString(config.BlockchainType))
+		return keys, nil
+	}
+	if config.GetString(config.OrgKey) == "" && config.GetString(config.OrgIdentityDeprecated) == "" {
+		orgKey := identity.DeriveDevKey(seed, orgName)
+		config.Set(config.OrgKey, orgKey.Address)
+		keys = append(keys, orgKey)
+	}
+	for _, name := range config.GetStringSlice(config.DevIdentitiesNames) {
+		keys = append(keys, identity.DeriveDevKey(seed, fmt.Sprintf("%s/%s", orgName, name)))
+	}
+	return keys, nil
+}
+
+func (or *orchestrator) initDevIdentities(ctx context.Context) error {
+	if !config.GetBool(config.DevIdentitiesEnabled) {
+		return nil
+	}
+	keys, err := devIdentityKeys(ctx)
+	if err != nil {
+		return err
+	}
+	log.L(ctx).Warnf("Development identities enabled with seed '%s' - keys are derived from public information", config.GetString(config.DevIdentitiesSeed))
+	if keyFile := config.GetString(config.DevIdentitiesKeyFile); keyFile != "" {
+		b, _ := json.MarshalIndent(keys, "", "  ")
+		if err := ioutil.WriteFile(keyFile, b, 0600); err != nil {
+			return i18n.NewError(ctx, i18n.MsgDevIdentitiesKeyFileFailed, keyFile, err)
+		}
+	}
+	return nil
+}
+
+func (or *orchestrator) startDevIdentities() {
+	if !config.GetBool(config.DevIdentitiesEnabled) {
+		return
+	}
+	or.devIdentitiesDone = make(chan struct{})
+	go or.runDevIdentities()
+}
+
+// runDevIdentities registers the org, node and custom identities in the background, as each registration
+// must be confirmed before the next one can refer to it. Identities that already exist are skipped, so
+// the whole set is retried on failure.
+func (or *orchestrator) runDevIdentities() {
+	defer close(or.devIdentitiesDone)
+	r := &retry.Retry{
+		InitialDelay: config.GetDuration(config.DevIdentitiesRetryInitDelay),
+		MaximumDelay: config.GetDuration(config.DevIdentitiesRetryMaxDelay),
+		Factor:       config.GetFloat64(config.DevIdentitiesRetryFactor),
+	}
+	err := r.Do(or.ctx, "register development identities", func(attempt int) (retry bool, err error) {
+		return true, or.registerDevIdentities(or.ctx)
+	})
+	if err == nil {
+		log.L(or.ctx).Infof("Development identities registered")
+	}
+}
+
+func (or *orchestrator) registerDevIdentities(ctx context.Context) error {
+	l := log.L(ctx)
+	orgName := config.GetString(config.OrgName)
+	org, _, err := or.identity.CachedIdentityLookupNilOK(ctx, fftypes.FireFlyOrgDIDPrefix+orgName)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		l.Infof("Registering development org %s", orgName)
+		if org, err = or.networkmap.RegisterNodeOrganization(ctx, true); err != nil {
+			return err
+		}
+	}
+
+	nodeName := config.GetString(config.NodeName)
+	if nodeName == "" {
+		nodeName = fmt.Sprintf("%s.node", orgName)
+	}
+	node, _, err := or.identity.CachedIdentityLookupNilOK(ctx, fftypes.FireFlyNodeDIDPrefix+nodeName)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		l.Infof("Registering development node %s", nodeName)
+		if _, err = or.networkmap.RegisterNode(ctx, true); err != nil {
+			return err
+		}
+	}
+
+	ns := config.GetString(config.DevIdentitiesNamespace)
+	if ns == "" {
+		ns = config.GetString(config.NamespacesDefault)
+	}
+	if !devKeysSupported() {
+		return nil
+	}
+	seed := config.GetString(config.DevIdentitiesSeed)
+	for _, name := range config.GetStringSlice(config.DevIdentitiesNames) {
+		existing, _, err := or.identity.CachedIdentityLookupNilOK(ctx, fmt.Sprintf("%s%s/%s", fftypes.FireFlyCustomDIDPrefix, ns, name))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		l.Infof("Registering development identity %s in namespace %s", name, ns)
+		_, err = or.networkmap.RegisterIdentity(ctx, ns, &fftypes.IdentityCreateDTO{
+			Name:   name,
+			Type:   fftypes.IdentityTypeCustom,
+			Parent: org.ID.String(),
+			Key:    identity.DeriveDevKey(seed, fmt.Sprintf("%s/%s", orgName, name)).Address,
+		}, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
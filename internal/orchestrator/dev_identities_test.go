@@ -0,0 +1,218 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitDevIdentitiesDisabled(t *testing.T) {
+	or := newTestOrchestrator()
+	err := or.initDevIdentities(or.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, config.GetString(config.OrgKey))
+	or.startDevIdentities()
+	assert.Nil(t, or.devIdentitiesDone)
+}
+
+func TestInitDevIdentitiesKeyFile(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "ethereum")
+	keyFile := path.Join(t.TempDir(), "keys.json")
+	config.Set(config.DevIdentitiesEnabled, true)
+	config.Set(config.DevIdentitiesKeyFile, keyFile)
+	config.Set(config.DevIdentitiesNames, []string{"alice"})
+	config.Set(config.OrgName, "org_0")
+
+	err := or.initDevIdentities(or.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, identity.DeriveDevKey("firefly", "org_0").Address, config.GetString(config.OrgKey))
+
+	b, err := ioutil.ReadFile(keyFile)
+	assert.NoError(t, err)
+	var keys []*identity.DevKey
+	err = json.Unmarshal(b, &keys)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, "org_0", keys[0].Name)
+	assert.Equal(t, "org_0/alice", keys[1].Name)
+}
+
+func TestInitDevIdentitiesExplicitOrgKey(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "ethereum")
+	config.Set(config.DevIdentitiesEnabled, true)
+	config.Set(config.OrgName, "org_0")
+	config.Set(config.OrgKey, "0x12345")
+
+	keys, err := devIdentityKeys(or.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	assert.Equal(t, "0x12345", config.GetString(config.OrgKey))
+}
+
+func TestInitDevIdentitiesNotEthereum(t *testing.T) {
+	or := newTestOrchestrator()
+	keyFile := path.Join(t.TempDir(), "keys.json")
+	config.Set(config.BlockchainType, "fabric")
+	config.Set(config.DevIdentitiesEnabled, true)
+	config.Set(config.DevIdentitiesKeyFile, keyFile)
+	config.Set(config.DevIdentitiesNames, []string{"alice"})
+	config.Set(config.OrgName, "org_0")
+
+	err := or.initDevIdentities(or.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, config.GetString(config.OrgKey))
+
+	b, err := ioutil.ReadFile(keyFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, "[]", string(b))
+}
+
+func TestRegisterDevIdentitiesNotEthereum(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "fabric")
+	config.Set(config.DevIdentitiesNames, []string{"alice"})
+	config.Set(config.OrgName, "org_0")
+
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(&fftypes.Identity{}, false, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(&fftypes.Identity{}, false, nil)
+
+	err := or.registerDevIdentities(or.ctx)
+	assert.NoError(t, err)
+
+	or.mim.AssertExpectations(t)
+	or.mnm.AssertExpectations(t)
+}
+
+func TestInitDevIdentitiesNoOrgName(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.DevIdentitiesEnabled, true)
+	err := or.initDevIdentities(or.ctx)
+	assert.Regexp(t, "FF10529", err)
+}
+
+func TestInitDevIdentitiesBadKeyFile(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.DevIdentitiesEnabled, true)
+	config.Set(config.DevIdentitiesKeyFile, path.Join(t.TempDir(), "missing", "keys.json"))
+	config.Set(config.OrgName, "org_0")
+	err := or.initDevIdentities(or.ctx)
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestStartDevIdentitiesRegisterAll(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "ethereum")
+	config.Set(config.DevIdentitiesEnabled, true)
+	config.Set(config.DevIdentitiesNames, []string{"alice"})
+	config.Set(config.DevIdentitiesRetryInitDelay, "1ms")
+	config.Set(config.OrgName, "org_0")
+
+	org := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(nil, false, fmt.Errorf("pop")).Once()
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(nil, false, nil)
+	or.mnm.On("RegisterNodeOrganization", mock.Anything, true).Return(org, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(nil, false, nil)
+	or.mnm.On("RegisterNode", mock.Anything, true).Return(&fftypes.Identity{}, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:ns/default/alice").Return(nil, false, nil)
+	or.mnm.On("RegisterIdentity", mock.Anything, "default", mock.MatchedBy(func(dto *fftypes.IdentityCreateDTO) bool {
+		return dto.Name == "alice" &&
+			dto.Type == fftypes.IdentityTypeCustom &&
+			dto.Parent == org.ID.String() &&
+			dto.Key == identity.DeriveDevKey("firefly", "org_0/alice").Address
+	}), true).Return(&fftypes.Identity{}, nil)
+
+	or.startDevIdentities()
+	<-or.devIdentitiesDone
+
+	or.mim.AssertExpectations(t)
+	or.mnm.AssertExpectations(t)
+}
+
+func TestRegisterDevIdentitiesExisting(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "ethereum")
+	config.Set(config.DevIdentitiesNames, []string{"alice"})
+	config.Set(config.DevIdentitiesNamespace, "ns1")
+	config.Set(config.OrgName, "org_0")
+	config.Set(config.NodeName, "node_0")
+
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(&fftypes.Identity{}, false, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/node_0").Return(&fftypes.Identity{}, false, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:ns/ns1/alice").Return(&fftypes.Identity{}, false, nil)
+
+	err := or.registerDevIdentities(or.ctx)
+	assert.NoError(t, err)
+
+	or.mim.AssertExpectations(t)
+	or.mnm.AssertExpectations(t)
+}
+
+func TestRegisterDevIdentitiesFail(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(or *testOrchestrator)
+	}{
+		{"org register", func(or *testOrchestrator) {
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(nil, false, nil)
+			or.mnm.On("RegisterNodeOrganization", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"node lookup", func(or *testOrchestrator) {
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(&fftypes.Identity{}, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(nil, false, fmt.Errorf("pop"))
+		}},
+		{"node register", func(or *testOrchestrator) {
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(&fftypes.Identity{}, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(nil, false, nil)
+			or.mnm.On("RegisterNode", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+		}},
+		{"identity lookup", func(or *testOrchestrator) {
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(&fftypes.Identity{}, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(&fftypes.Identity{}, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:ns/default/alice").Return(nil, false, fmt.Errorf("pop"))
+		}},
+		{"identity register", func(or *testOrchestrator) {
+			org := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org_0").Return(org, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:node/org_0.node").Return(&fftypes.Identity{}, false, nil)
+			or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:ns/default/alice").Return(nil, false, nil)
+			or.mnm.On("RegisterIdentity", mock.Anything, "default", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			or := newTestOrchestrator()
+			config.Set(config.BlockchainType, "ethereum")
+			config.Set(config.DevIdentitiesNames, []string{"alice"})
+			config.Set(config.OrgName, "org_0")
+			test.setup(or)
+			err := or.registerDevIdentities(or.ctx)
+			assert.EqualError(t, err, "pop")
+		})
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const redactedValue = "***"
+
+// secretConfigKeys are the fragments of configuration key names, that identify values that must not be returned
+var secretConfigKeys = []string{"password", "passphrase", "secret", "token", "apikey", "authorization", "privatekey"}
+
+// secretDSNParamRegex matches the secret parameters of a key=value connection string, such as
+// "host=localhost password='my secret'" or "Server=db;Password=secret", where a value is either quoted or
+// runs to the next separator
+var secretDSNParamRegex = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(secretConfigKeys, "|") + `)[\w.-]*\s*=\s*)('(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|[^\s;&]+)`)
+
+type loadedPlugin struct {
+	pluginType   string
+	name         string
+	config       fftypes.JSONObject
+	capabilities interface{}
+	health       func(ctx context.Context) error
+}
+
+func isSecretConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// configObject converts a section of configuration to a JSONObject, including the maps
+// with interface{} keys that are parsed from YAML arrays
+func configObject(value interface{}) fftypes.JSONObject {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v
+	case map[interface{}]interface{}:
+		jo := fftypes.JSONObject{}
+		for k, e := range v {
+			jo[fmt.Sprintf("%v", k)] = e
+		}
+		return jo
+	default:
+		return fftypes.JSONObject{}
+	}
+}
+
+func redactConfigValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return redactConfig(configObject(v))
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, e := range v {
+			redacted[i] = redactConfigValue(key, e)
+		}
+		return redacted
+	case string:
+		if isSecretConfigKey(key) && v != "" {
+			return redactedValue
+		}
+		// Strip any password embedded in a URL, such as a database connection string
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, hasPassword := u.User.Password(); hasPassword {
+				u.User = url.UserPassword(u.User.Username(), redactedValue)
+				v = u.String()
+			}
+		}
+		// Strip any secrets from a key=value connection string
+		return secretDSNParamRegex.ReplaceAllString(v, "${1}"+redactedValue)
+	default:
+		if isSecretConfigKey(key) && v != nil {
+			return redactedValue
+		}
+		return v
+	}
+}
+
+// redactConfig returns a copy of a section of configuration, with secrets removed
+func redactConfig(conf map[string]interface{}) fftypes.JSONObject {
+	redacted := fftypes.JSONObject{}
+	for k, v := range conf {
+		redacted[k] = redactConfigValue(k, v)
+	}
+	return redacted
+}
+
+func capabilitiesJSON(capabilities interface{}) fftypes.JSONObject {
+	jo := fftypes.JSONObject{}
+	b, _ := json.Marshal(capabilities)
+	_ = json.Unmarshal(b, &jo)
+	return jo
+}
+
+func (or *orchestrator) databaseHealth(ctx context.Context) error {
+	fb := database.NamespaceQueryFactory.NewFilterLimit(ctx, 1)
+	_, _, err := or.database.GetNamespaces(ctx, fb.And())
+	return err
+}
+
+// loadedPlugins returns every plugin loaded by the node, in a stable order
+func (or *orchestrator) loadedPlugins() []*loadedPlugin {
+	conf := config.GetConfig()
+
+	storageConf := conf.GetObject("sharedstorage").GetObject(or.sharedstorage.Name())
+	if len(storageConf) == 0 {
+		storageConf = conf.GetObject("publicstorage").GetObject(or.sharedstorage.Name())
+	}
+	plugins := []*loadedPlugin{
+		{
+			pluginType:   "database",
+			name:         or.database.Name(),
+			config:       conf.GetObject("database").GetObject(or.database.Name()),
+			capabilities: or.database.Capabilities(),
+			health:       or.databaseHealth,
+		},
+		{
+			pluginType:   "blockchain",
+			name:         or.blockchain.Name(),
+			config:       conf.GetObject("blockchain").GetObject(or.blockchain.Name()),
+			capabilities: or.blockchain.Capabilities(),
+			health:       or.blockchain.Health,
+		},
+		{
+			pluginType:   "dataexchange",
+			name:         or.dataexchange.Name(),
+			config:       conf.GetObject("dataexchange").GetObject(or.dataexchange.Name()),
+			capabilities: or.dataexchange.Capabilities(),
+			health:       or.dataexchange.Health,
+		},
+		{
+			pluginType:   "sharedstorage",
+			name:         or.sharedstorage.Name(),
+			config:       storageConf,
+			capabilities: or.sharedstorage.Capabilities(),
+		},
+	}
+
+	tokensConf, _ := conf["tokens"].([]interface{})
+	tokenNames := make([]string, 0, len(or.tokens))
+	for name := range or.tokens {
+		tokenNames = append(tokenNames, name)
+	}
+	sort.Strings(tokenNames)
+	for _, name := range tokenNames {
+		plugin := &loadedPlugin{
+			pluginType:   "tokens",
+			name:         name,
+			config:       fftypes.JSONObject{},
+			capabilities: or.tokens[name].Capabilities(),
+			health:       or.tokens[name].Health,
+		}
+		for _, entry := range tokensConf {
+			if entryConf := configObject(entry); entryConf.GetString("name") == name {
+				plugin.config = entryConf
+			}
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	transports := or.events.Transports()
+	transportNames := make([]string, 0, len(transports))
+	for name := range transports {
+		transportNames = append(transportNames, name)
+	}
+	sort.Strings(transportNames)
+	for _, name := range transportNames {
+		plugins = append(plugins, &loadedPlugin{
+			pluginType:   "events",
+			name:         name,
+			config:       conf.GetObject("events").GetObject(name),
+			capabilities: transports[name].Capabilities(),
+		})
+	}
+	return plugins
+}
+
+// GetPluginDiagnostics describes each loaded plugin, to simplify debugging the environment of a node
+func (or *orchestrator) GetPluginDiagnostics(ctx context.Context) []*fftypes.PluginDiagnostics {
+	plugins := or.loadedPlugins()
+	diagnostics := make([]*fftypes.PluginDiagnostics, len(plugins))
+	for i, plugin := range plugins {
+		diag := &fftypes.PluginDiagnostics{
+			Type:         plugin.pluginType,
+			Name:         plugin.name,
+			Config:       redactConfig(plugin.config),
+			Capabilities: capabilitiesJSON(plugin.capabilities),
+			Connection:   fftypes.PluginConnectionStateUnknown,
+			Actions:      []fftypes.PluginDiagnosticAction{},
+		}
+		if plugin.health != nil {
+			diag.Actions = append(diag.Actions, fftypes.PluginDiagnosticActionTestConnection)
+			if err := plugin.health(ctx); err != nil {
+				diag.Connection = fftypes.PluginConnectionStateDisconnected
+				diag.Error = err.Error()
+			} else {
+				diag.Connection = fftypes.PluginConnectionStateConnected
+			}
+		}
+		diagnostics[i] = diag
+	}
+	return diagnostics
+}
+
+// RunPluginDiagnosticAction runs a diagnostic action against a single loaded plugin
+func (or *orchestrator) RunPluginDiagnosticAction(ctx context.Context, pluginType, name, action string) (*fftypes.PluginDiagnosticResult, error) {
+	var plugin *loadedPlugin
+	for _, p := range or.loadedPlugins() {
+		if p.pluginType == pluginType && p.name == name {
+			plugin = p
+			break
+		}
+	}
+	if plugin == nil {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+
+	diagAction := fftypes.PluginDiagnosticAction(action).Lower()
+	if diagAction != fftypes.PluginDiagnosticActionTestConnection || plugin.health == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgPluginActionNotSupported, action, pluginType, name)
+	}
+
+	startTime := time.Now()
+	err := plugin.health(ctx)
+	elapsed := fftypes.FFDuration(time.Since(startTime))
+	result := &fftypes.PluginDiagnosticResult{
+		Type:    plugin.pluginType,
+		Name:    plugin.name,
+		Action:  diagAction,
+		Success: err == nil,
+		Elapsed: &elapsed,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// ephemeralNamespaces returns the predefined namespaces flagged as ephemeral, such as the short-lived
+// namespaces of tests run against a shared development network
+func ephemeralNamespaces() []string {
+	namespaces := make([]string, 0)
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		if entry.GetBool("ephemeral") {
+			namespaces = append(namespaces, entry.GetString("name"))
+		}
+	}
+	return namespaces
+}
+
+func (or *orchestrator) startEphemeralNamespaces() {
+	namespaces := ephemeralNamespaces()
+	if len(namespaces) == 0 {
+		return
+	}
+	or.ephemeralDone = make(chan struct{})
+	go or.ephemeralNamespacesLoop(namespaces)
+}
+
+func (or *orchestrator) ephemeralNamespacesLoop(namespaces []string) {
+	defer close(or.ephemeralDone)
+	maxAge := config.GetDuration(config.NamespacesEphemeralMaxAge)
+	interval := config.GetDuration(config.NamespacesEphemeralInterval)
+	log.L(or.ctx).Infof("Records older than %s will be deleted every %s from ephemeral namespaces: %v", maxAge, interval, namespaces)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		or.deleteEphemeralRecords(namespaces, maxAge)
+		select {
+		case <-ticker.C:
+		case <-or.ctx.Done():
+			log.L(or.ctx).Debugf("Ephemeral namespace cleanup exiting")
+			return
+		}
+	}
+}
+
+// deleteEphemeralRecords deletes the records older than the maximum age from each namespace. A failure is
+// logged, and the remaining records are deleted on the next interval.
+func (or *orchestrator) deleteEphemeralRecords(namespaces []string, maxAge time.Duration) {
+	before := fftypes.FFTime(time.Now().Add(-maxAge))
+	for _, ns := range namespaces {
+		deleted, err := or.database.DeleteNamespaceRecords(or.ctx, ns, &before)
+		if err != nil {
+			log.L(or.ctx).Errorf("Failed to delete records older than %s from ephemeral namespace '%s': %s", before.String(), ns, err)
+			continue
+		}
+		if deleted > 0 {
+			log.L(or.ctx).Infof("Deleted %d records older than %s from ephemeral namespace '%s'", deleted, before.String(), ns)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEphemeralNamespacesNone(t *testing.T) {
+	or := newTestOrchestrator()
+	or.startEphemeralNamespaces()
+	assert.Nil(t, or.ephemeralDone)
+}
+
+func TestEphemeralNamespacesLoop(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "test1", "ephemeral": true},
+		{"name": "test2", "ephemeral": true},
+	})
+	config.Set(config.NamespacesEphemeralMaxAge, "1h")
+	config.Set(config.NamespacesEphemeralInterval, "1ms")
+
+	cutoff := time.Now().Add(-1 * time.Hour)
+	isCutoff := mock.MatchedBy(func(before *fftypes.FFTime) bool {
+		return !time.Time(*before).Before(cutoff) && time.Time(*before).Before(time.Now().Add(-59*time.Minute))
+	})
+	or.mdi.On("DeleteNamespaceRecords", mock.Anything, "test1", isCutoff).Return(int64(0), fmt.Errorf("pop"))
+	or.mdi.On("DeleteNamespaceRecords", mock.Anything, "test2", isCutoff).Return(int64(10), nil).Once()
+	or.mdi.On("DeleteNamespaceRecords", mock.Anything, "test2", isCutoff).Return(int64(0), nil).Run(func(args mock.Arguments) {
+		or.cancelCtx()
+	})
+
+	or.startEphemeralNamespaces()
+	<-or.ephemeralDone
+
+	or.mdi.AssertExpectations(t)
+}
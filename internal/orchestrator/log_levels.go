@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func getLogLevel(component string) *fftypes.LogLevel {
+	level, override := log.ComponentLevel(component)
+	return &fftypes.LogLevel{
+		Component: component,
+		Level:     level,
+		Override:  override,
+	}
+}
+
+func checkLogComponent(ctx context.Context, component string) error {
+	for _, c := range log.Components {
+		if c == component {
+			return nil
+		}
+	}
+	return i18n.NewError(ctx, i18n.MsgUnknownLogComponent, component)
+}
+
+func (or *orchestrator) GetLogLevels(ctx context.Context) []*fftypes.LogLevel {
+	levels := make([]*fftypes.LogLevel, len(log.Components))
+	for i, component := range log.Components {
+		levels[i] = getLogLevel(component)
+	}
+	return levels
+}
+
+func (or *orchestrator) SetLogLevel(ctx context.Context, component string, input *fftypes.LogLevelInput) (*fftypes.LogLevel, error) {
+	if err := checkLogComponent(ctx, component); err != nil {
+		return nil, err
+	}
+	if !log.ValidLevel(input.Level) {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidLogLevel, input.Level)
+	}
+	log.SetComponentLevel(component, input.Level)
+	log.L(ctx).Infof("Log level of '%s' set to '%s'", component, input.Level)
+	return getLogLevel(component), nil
+}
+
+func (or *orchestrator) ResetLogLevel(ctx context.Context, component string) error {
+	if err := checkLogComponent(ctx, component); err != nil {
+		return err
+	}
+	log.ResetComponentLevel(component)
+	log.L(ctx).Infof("Log level of '%s' reset", component)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetResetLogLevel(t *testing.T) {
+	or := newTestOrchestrator()
+	log.SetLevel("info")
+	defer log.ResetComponentLevel(log.ComponentEthereum)
+
+	level, err := or.SetLogLevel(context.Background(), log.ComponentEthereum, &fftypes.LogLevelInput{Level: "trace"})
+	assert.NoError(t, err)
+	assert.Equal(t, &fftypes.LogLevel{Component: "ethereum", Level: "trace", Override: true}, level)
+
+	levels := or.GetLogLevels(context.Background())
+	assert.Len(t, levels, len(log.Components))
+	for _, l := range levels {
+		if l.Component == log.ComponentEthereum {
+			assert.Equal(t, "trace", l.Level)
+		} else {
+			assert.False(t, l.Override)
+		}
+	}
+
+	err = or.ResetLogLevel(context.Background(), log.ComponentEthereum)
+	assert.NoError(t, err)
+	lvl, override := log.ComponentLevel(log.ComponentEthereum)
+	assert.Equal(t, "info", lvl)
+	assert.False(t, override)
+}
+
+func TestSetLogLevelUnknownComponent(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.SetLogLevel(context.Background(), "wrong", &fftypes.LogLevelInput{Level: "debug"})
+	assert.Regexp(t, "FF10527", err)
+}
+
+func TestSetLogLevelBadLevel(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.SetLogLevel(context.Background(), log.ComponentAggregator, &fftypes.LogLevelInput{Level: "verbose"})
+	assert.Regexp(t, "FF10528", err)
+}
+
+func TestResetLogLevelUnknownComponent(t *testing.T) {
+	or := newTestOrchestrator()
+	err := or.ResetLogLevel(context.Background(), "wrong")
+	assert.Regexp(t, "FF10527", err)
+}
@@ -18,8 +18,11 @@ package orchestrator
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -29,3 +32,43 @@ func (or *orchestrator) RequestReply(ctx context.Context, ns string, msg *fftype
 	}
 	return or.PrivateMessaging().RequestReply(ctx, ns, msg)
 }
+
+// ActivateDraftMessage makes a draft message ready to be sent, or schedules it to be made ready by the batch manager
+// at a later time. A scheduled message can be activated again, to send it immediately or at a different time.
+func (or *orchestrator) ActivateDraftMessage(ctx context.Context, ns, id string, input *fftypes.DraftActivation) (*fftypes.Message, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.State != fftypes.MessageStateDraft && msg.State != fftypes.MessageStateScheduled {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageNotDraft, msg.Header.ID, msg.State)
+	}
+
+	if input.SendAt != nil && time.Time(*input.SendAt).After(time.Now()) {
+		err = or.database.ScheduleDraftMessage(ctx, msg.Header.ID, input.SendAt)
+		if err == database.DeleteRecordNotFound {
+			// The message was activated while we were processing the request
+			return nil, i18n.NewError(ctx, i18n.MsgMessageNotDraft, msg.Header.ID, fftypes.MessageStateReady)
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg.State = fftypes.MessageStateScheduled
+		msg.SendAt = input.SendAt
+		log.L(ctx).Infof("Draft message %s scheduled to send at %s", msg.Header.ID, msg.SendAt)
+		return msg, nil
+	}
+
+	msg.State = fftypes.MessageStateReady
+	msg.SendAt = input.SendAt
+	err = or.database.ActivateDraftMessage(ctx, msg)
+	if err == database.DeleteRecordNotFound {
+		// The message was activated while we were processing the request
+		return nil, i18n.NewError(ctx, i18n.MsgMessageNotDraft, msg.Header.ID, fftypes.MessageStateReady)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Draft message %s is ready to send", msg.Header.ID)
+	return msg, nil
+}
@@ -0,0 +1,171 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func messageProofStep(description string, encoding fftypes.MessageProofEncoding, input []byte, expected *fftypes.Bytes32) *fftypes.MessageProofStep {
+	var output fftypes.Bytes32 = sha256.Sum256(input)
+	step := &fftypes.MessageProofStep{
+		Description: description,
+		Encoding:    encoding,
+		Output:      &output,
+		Verified:    expected.Equals(&output),
+	}
+	if encoding == fftypes.MessageProofEncodingHex {
+		step.Input = hex.EncodeToString(input)
+	} else {
+		step.Input = string(input)
+	}
+	return step
+}
+
+// messagePinStep builds the step that reproduces the context or pin for one topic of a message, in the
+// same way as the batch processor when the batch was sealed
+func messagePinStep(ctx context.Context, msg *fftypes.Message, idx int, pin *fftypes.MessageProofPin, chainPin *fftypes.Pin) *fftypes.MessageProofStep {
+	if msg.Header.Group == nil {
+		var expected *fftypes.Bytes32
+		if chainPin != nil {
+			expected = chainPin.Hash
+		}
+		return messageProofStep("Hash of the topic, which is the context written to the blockchain for a broadcast message",
+			fftypes.MessageProofEncodingUTF8, []byte(pin.Topic), expected)
+	}
+
+	// Private messages record the masked pin, and nonce, as "<hash>:<nonce>" for each topic
+	var expected *fftypes.Bytes32
+	if idx < len(msg.Pins) {
+		pinParts := strings.Split(msg.Pins[idx], ":")
+		expected, _ = fftypes.ParseBytes32(ctx, pinParts[0])
+		if len(pinParts) == 2 {
+			pin.Nonce, _ = strconv.ParseInt(pinParts[1], 10, 64)
+		}
+	}
+	input := []byte(pin.Topic)
+	input = append(input, (*msg.Header.Group)[:]...)
+	input = append(input, []byte(msg.Header.Author)...)
+	nonceBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonceBytes, uint64(pin.Nonce))
+	input = append(input, nonceBytes...)
+	return messageProofStep("Hash of the topic, group hash, author and 8 byte big-endian nonce, which is the masked pin written to the blockchain for a private message",
+		fftypes.MessageProofEncodingHex, input, expected)
+}
+
+func (or *orchestrator) GetMessageProof(ctx context.Context, ns, id string) (*fftypes.MessageProof, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Header.TxType != fftypes.TransactionTypeBatchPin || msg.BatchID == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageNotPinned, msg.Header.ID)
+	}
+	batch, err := or.database.GetBatchByID(ctx, msg.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil || batch.Confirmed == nil || batch.TX.ID == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgMessageNotPinned, msg.Header.ID)
+	}
+	var manifest fftypes.BatchManifest
+	if err := batch.Manifest.Unmarshal(ctx, &manifest); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, "manifest")
+	}
+
+	proof := &fftypes.MessageProof{
+		Message: msg.BatchMessage(),
+		Batch:   batch,
+	}
+	if proof.Transaction, err = or.database.GetTransactionByID(ctx, batch.TX.ID); err != nil {
+		return nil, err
+	}
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	if proof.BlockchainEvents, _, err = or.database.GetBlockchainEvents(ctx, fb.And(
+		fb.Eq("tx.id", batch.TX.ID),
+		fb.Eq("namespace", ns),
+	)); err != nil {
+		return nil, err
+	}
+	pfb := database.PinQueryFactory.NewFilter(ctx)
+	pins, _, err := or.database.GetPins(ctx, pfb.Eq("batch", batch.ID))
+	if err != nil {
+		return nil, err
+	}
+	pinsByIndex := make(map[int64]*fftypes.Pin, len(pins))
+	for _, pin := range pins {
+		pinsByIndex[pin.Index] = pin
+	}
+
+	dataRefs, _ := json.Marshal(&msg.Data)
+	header, _ := json.Marshal(&msg.Header)
+	proof.Steps = []*fftypes.MessageProofStep{
+		messageProofStep("Hash of the data references of the message, which is the datahash in the message header",
+			fftypes.MessageProofEncodingUTF8, dataRefs, msg.Header.DataHash),
+		messageProofStep("Hash of the message header, which is the hash of the message",
+			fftypes.MessageProofEncodingUTF8, header, msg.Hash),
+	}
+
+	// The pins of each message in the batch follow on from those of the previous message, one per topic
+	var entry *fftypes.MessageManifestEntry
+	pinIndex := int64(0)
+	for _, m := range manifest.Messages {
+		if m.ID.Equals(msg.Header.ID) {
+			entry = m
+			break
+		}
+		pinIndex += int64(m.Topics)
+	}
+	batchStep := messageProofStep("Hash of the batch manifest, which must list the ID and hash of the message, and is the batch hash written to the blockchain",
+		fftypes.MessageProofEncodingUTF8, batch.Manifest.Bytes(), batch.Hash)
+	batchStep.Verified = batchStep.Verified && entry != nil && entry.Hash.Equals(msg.Hash)
+	proof.Steps = append(proof.Steps, batchStep)
+
+	proof.Verified = true
+	proof.Pins = make([]*fftypes.MessageProofPin, len(msg.Header.Topics))
+	for i, topic := range msg.Header.Topics {
+		pin := &fftypes.MessageProofPin{
+			Topic:  topic,
+			Index:  pinIndex + int64(i),
+			Masked: msg.Header.Group != nil,
+		}
+		chainPin := pinsByIndex[pin.Index]
+		step := messagePinStep(ctx, msg, i, pin, chainPin)
+		pin.Hash = step.Output
+		if chainPin != nil {
+			pin.Pinned = chainPin.Hash.Equals(pin.Hash) && chainPin.BatchHash.Equals(batch.Hash) && chainPin.Masked == pin.Masked
+		}
+		proof.Pins[i] = pin
+		proof.Steps = append(proof.Steps, step)
+		proof.Verified = proof.Verified && pin.Pinned
+	}
+	for _, step := range proof.Steps {
+		proof.Verified = proof.Verified && step.Verified
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,265 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestMessageProof(t *testing.T, group *fftypes.Bytes32) (*fftypes.Message, *fftypes.BatchPersisted, []*fftypes.Pin) {
+	otherMsg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topicA"},
+		},
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1", "topic2"},
+			Group:     group,
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/org1", Key: "0x12345"},
+		},
+		Data: fftypes.DataRefs{{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}},
+	}
+	assert.NoError(t, otherMsg.Seal(context.Background()))
+	assert.NoError(t, msg.Seal(context.Background()))
+
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeBatchPin,
+			ID:   fftypes.NewUUID(),
+		},
+		Confirmed: fftypes.Now(),
+	}
+	msg.BatchID = batch.ID
+
+	pins := []*fftypes.Pin{{Index: 0, Hash: fftypes.NewRandB32()}}
+	for i, topic := range msg.Header.Topics {
+		h := sha256.New()
+		h.Write([]byte(topic))
+		if group != nil {
+			h.Write((*group)[:])
+			h.Write([]byte(msg.Header.Author))
+			nonceBytes := make([]byte, 8)
+			binary.BigEndian.PutUint64(nonceBytes, uint64(i+10))
+			h.Write(nonceBytes)
+		}
+		pinHash := fftypes.HashResult(h)
+		if group != nil {
+			msg.Pins = append(msg.Pins, fmt.Sprintf("%s:%.16d", pinHash, i+10))
+		}
+		pins = append(pins, &fftypes.Pin{Index: int64(i + 1), Hash: pinHash, Masked: group != nil})
+	}
+
+	manifest := batch.GenManifest([]*fftypes.Message{otherMsg, msg}, nil, fftypes.ProtocolVersion1).String()
+	batch.Manifest = fftypes.JSONAnyPtr(manifest)
+	batch.Hash = fftypes.HashString(manifest)
+	for _, pin := range pins {
+		pin.Batch = batch.ID
+		pin.BatchHash = batch.Hash
+	}
+	return msg, batch, pins
+}
+
+func mockMessageProofQueries(or *testOrchestrator, batch *fftypes.BatchPersisted, pins []*fftypes.Pin) {
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(&fftypes.Transaction{ID: batch.TX.ID}, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{{Name: "BatchPin"}}, nil, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(pins, nil, nil)
+}
+
+func TestGetMessageProofBroadcast(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, pins := newTestMessageProof(t, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mockMessageProofQueries(or, batch, pins)
+
+	proof, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.True(t, proof.Verified)
+	assert.Len(t, proof.Steps, 5)
+	assert.Len(t, proof.Pins, 2)
+	assert.Equal(t, int64(1), proof.Pins[0].Index)
+	assert.Equal(t, "topic2", proof.Steps[4].Input)
+	assert.Equal(t, fftypes.MessageProofEncodingUTF8, proof.Steps[4].Encoding)
+	assert.Equal(t, batch.Hash, proof.Steps[2].Output)
+	assert.Len(t, proof.BlockchainEvents, 1)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageProofPrivate(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, pins := newTestMessageProof(t, fftypes.NewRandB32())
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mockMessageProofQueries(or, batch, pins)
+
+	proof, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.True(t, proof.Verified)
+	assert.True(t, proof.Pins[1].Masked)
+	assert.Equal(t, int64(11), proof.Pins[1].Nonce)
+	assert.Equal(t, fftypes.MessageProofEncodingHex, proof.Steps[4].Encoding)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageProofPinMissing(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, pins := newTestMessageProof(t, fftypes.NewRandB32())
+	msg.Pins = msg.Pins[0:1]
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mockMessageProofQueries(or, batch, pins[0:2])
+
+	proof, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.False(t, proof.Verified)
+	assert.True(t, proof.Pins[0].Pinned)
+	assert.False(t, proof.Pins[1].Pinned)
+	assert.False(t, proof.Steps[4].Verified)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageProofTampered(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, pins := newTestMessageProof(t, nil)
+	msg.Header.Tag = "changed"
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mockMessageProofQueries(or, batch, pins)
+
+	proof, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.False(t, proof.Verified)
+	assert.True(t, proof.Steps[0].Verified)
+	assert.False(t, proof.Steps[1].Verified)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageProofNotInManifest(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, pins := newTestMessageProof(t, nil)
+	batch.Manifest = fftypes.JSONAnyPtr(`{"messages":[]}`)
+	batch.Hash = batch.Manifest.Hash()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mockMessageProofQueries(or, batch, pins)
+
+	proof, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.False(t, proof.Verified)
+	assert.False(t, proof.Steps[2].Verified)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageProofBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessageProof(context.Background(), "ns1", "!uuid")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetMessageProofUnpinned(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, _, _ := newTestMessageProof(t, nil)
+	msg.Header.TxType = fftypes.TransactionTypeUnpinned
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestGetMessageProofBatchFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageProofBatchNotConfirmed(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	batch.Confirmed = nil
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestGetMessageProofBadManifest(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	batch.Manifest = fftypes.JSONAnyPtr("!json")
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.Regexp(t, "FF10151", err)
+}
+
+func TestGetMessageProofTransactionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageProofBlockchainEventsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageProofPinsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestMessageProof(t, nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetMessageProof(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
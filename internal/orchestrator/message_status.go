@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func messageStateStatus(state fftypes.MessageState) fftypes.OpStatus {
+	switch state {
+	case fftypes.MessageStateConfirmed:
+		return fftypes.OpStatusSucceeded
+	case fftypes.MessageStateRejected:
+		return fftypes.OpStatusFailed
+	default:
+		return fftypes.OpStatusPending
+	}
+}
+
+func recipientStatus(status fftypes.MessageRecipientStatus) fftypes.OpStatus {
+	switch status {
+	case fftypes.MessageRecipientStatusConfirmed:
+		return fftypes.OpStatusSucceeded
+	case fftypes.MessageRecipientStatusRejected:
+		return fftypes.OpStatusFailed
+	default:
+		return fftypes.OpStatusPending
+	}
+}
+
+func (or *orchestrator) GetMessageStatus(ctx context.Context, ns, id string) (*fftypes.MessageStatus, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	result := &fftypes.MessageStatus{
+		Message: msg.Header.ID,
+		State:   msg.State,
+		Status:  fftypes.OpStatusSucceeded,
+		Pins:    msg.Pins,
+	}
+	updateStatus(&result.Status, messageStateStatus(msg.State))
+
+	var batch *fftypes.BatchPersisted
+	if msg.BatchID != nil {
+		if batch, err = or.database.GetBatchByID(ctx, msg.BatchID); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case batch == nil:
+		result.Batch = pendingPlaceholder(fftypes.TransactionStatusTypeBatch)
+		updateStatus(&result.Status, fftypes.OpStatusPending)
+	case batch.Confirmed == nil:
+		result.Batch = pendingPlaceholder(fftypes.TransactionStatusTypeBatch)
+		result.Batch.SubType = batch.Type.String()
+		result.Batch.ID = batch.ID
+		updateStatus(&result.Status, fftypes.OpStatusPending)
+	default:
+		result.Batch = &fftypes.TransactionStatusDetails{
+			Status:    fftypes.OpStatusSucceeded,
+			Type:      fftypes.TransactionStatusTypeBatch,
+			SubType:   batch.Type.String(),
+			Timestamp: batch.Confirmed,
+			ID:        batch.ID,
+		}
+	}
+
+	var txID *fftypes.UUID
+	if batch != nil && batch.TX.ID != nil {
+		txID = batch.TX.ID
+		tx, err := or.database.GetTransactionByID(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+		// The transaction is only recorded locally once it is known to this node
+		if tx != nil {
+			if result.Transaction, err = or.getTransactionStatus(ctx, ns, txID.String(), tx); err != nil {
+				return nil, err
+			}
+			updateStatus(&result.Status, result.Transaction.Status)
+		}
+	}
+
+	if msg.Header.Group != nil {
+		if result.Members, err = or.getMessageMemberStatus(ctx, msg, txID); err != nil {
+			return nil, err
+		}
+		for _, member := range result.Members {
+			if member.Send != nil {
+				updateStatus(&result.Status, member.Send.Status)
+			}
+			if member.Recipient != nil {
+				updateStatus(&result.Status, recipientStatus(member.Recipient.Status))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// getMessageMemberStatus combines the data exchange sends performed by this node for a private
+// message, with any delivery acknowledgements received back from the members of the group
+func (or *orchestrator) getMessageMemberStatus(ctx context.Context, msg *fftypes.Message, txID *fftypes.UUID) ([]*fftypes.MessageMemberStatus, error) {
+	group, err := or.database.GetGroupByHash(ctx, msg.Header.Group)
+	if err != nil || group == nil {
+		return nil, err
+	}
+
+	sends := make(map[string]*fftypes.Operation)
+	if txID != nil {
+		fb := database.OperationQueryFactory.NewFilter(ctx)
+		ops, _, err := or.database.GetOperations(ctx, fb.And(
+			fb.Eq("tx", txID),
+			fb.Eq("type", fftypes.OpTypeDataExchangeSendBatch),
+		))
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range ops {
+			if op.Retry == nil {
+				sends[op.Input.GetString("node")] = op
+			}
+		}
+	}
+
+	recipients := make(map[string]*fftypes.MessageRecipient)
+	if config.GetBool(config.PrivateMessagingDeliveryAcksEnabled) {
+		fb := database.MessageRecipientQueryFactory.NewFilter(ctx)
+		acks, _, err := or.database.GetMessageRecipients(ctx, fb.Eq("message", msg.Header.ID))
+		if err != nil {
+			return nil, err
+		}
+		for _, ack := range acks {
+			recipients[ack.Node.String()] = ack
+		}
+	}
+
+	members := make([]*fftypes.MessageMemberStatus, len(group.Members))
+	for i, member := range group.Members {
+		members[i] = &fftypes.MessageMemberStatus{
+			Identity:  member.Identity,
+			Node:      member.Node,
+			Recipient: recipients[member.Node.String()],
+		}
+		if op, ok := sends[member.Node.String()]; ok {
+			members[i].Send = txOperationStatus(op)
+		}
+	}
+	return members, nil
+}
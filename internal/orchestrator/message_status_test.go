@@ -0,0 +1,299 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPrivateMessageStatus() (*fftypes.Message, *fftypes.BatchPersisted, *fftypes.Group) {
+	group := &fftypes.Group{
+		Hash: fftypes.NewRandB32(),
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{
+				{Identity: "did:firefly:org/org1", Node: fftypes.NewUUID()},
+				{Identity: "did:firefly:org/org2", Node: fftypes.NewUUID()},
+			},
+		},
+	}
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.BatchTypePrivate,
+		},
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeUnpinned,
+			ID:   fftypes.NewUUID(),
+		},
+		Confirmed: fftypes.Now(),
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			Group:  group.Hash,
+			TxType: fftypes.TransactionTypeUnpinned,
+		},
+		BatchID: batch.ID,
+		State:   fftypes.MessageStateConfirmed,
+	}
+	return msg, batch, group
+}
+
+func TestGetMessageStatusPrivateSuccess(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+
+	msg, batch, group := newTestPrivateMessageStatus()
+	sendOp := &fftypes.Operation{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.OpTypeDataExchangeSendBatch,
+		Status: fftypes.OpStatusSucceeded,
+		Input:  fftypes.JSONObject{"node": group.Members[1].Node.String()},
+	}
+	retriedOp := &fftypes.Operation{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.OpTypeDataExchangeSendBatch,
+		Status: fftypes.OpStatusFailed,
+		Input:  fftypes.JSONObject{"node": group.Members[1].Node.String()},
+		Retry:  sendOp.ID,
+	}
+	ack := &fftypes.MessageRecipient{
+		Message: msg.Header.ID,
+		Node:    group.Members[1].Node,
+		Status:  fftypes.MessageRecipientStatusConfirmed,
+	}
+
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(&fftypes.Transaction{ID: batch.TX.ID, Type: fftypes.TransactionTypeUnpinned}, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{sendOp, retriedOp}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	or.mdi.On("GetBatches", mock.Anything, mock.Anything).Return([]*fftypes.BatchPersisted{batch}, nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(group, nil)
+	or.mdi.On("GetMessageRecipients", mock.Anything, mock.Anything).Return([]*fftypes.MessageRecipient{ack}, nil, nil)
+
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, status.Status)
+	assert.Equal(t, fftypes.MessageStateConfirmed, status.State)
+	assert.Equal(t, batch.ID, status.Batch.ID)
+	assert.Equal(t, fftypes.OpStatusSucceeded, status.Batch.Status)
+	assert.Equal(t, fftypes.OpStatusSucceeded, status.Transaction.Status)
+	assert.Len(t, status.Members, 2)
+	assert.Nil(t, status.Members[0].Send)
+	assert.Nil(t, status.Members[0].Recipient)
+	assert.Equal(t, sendOp.ID, status.Members[1].Send.ID)
+	assert.Equal(t, ack, status.Members[1].Recipient)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageStatusAckRejected(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+
+	msg, batch, group := newTestPrivateMessageStatus()
+	ack := &fftypes.MessageRecipient{
+		Message: msg.Header.ID,
+		Node:    group.Members[1].Node,
+		Status:  fftypes.MessageRecipientStatusRejected,
+	}
+
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(group, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetMessageRecipients", mock.Anything, mock.Anything).Return([]*fftypes.MessageRecipient{ack}, nil, nil)
+
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, status.Status)
+	assert.Nil(t, status.Transaction)
+	assert.Equal(t, fftypes.MessageRecipientStatusRejected, status.Members[1].Recipient.Status)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageStatusAckPending(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+
+	msg, batch, group := newTestPrivateMessageStatus()
+	ack := &fftypes.MessageRecipient{
+		Message: msg.Header.ID,
+		Node:    group.Members[1].Node,
+		Status:  fftypes.MessageRecipientStatusPending,
+	}
+
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(group, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetMessageRecipients", mock.Anything, mock.Anything).Return([]*fftypes.MessageRecipient{ack}, nil, nil)
+
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusPending, status.Status)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageStatusNoBatch(t *testing.T) {
+	or := newTestOrchestrator()
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+		State: fftypes.MessageStateReady,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusPending, status.Status)
+	assert.Equal(t, fftypes.OpStatusPending, status.Batch.Status)
+	assert.Nil(t, status.Batch.ID)
+	assert.Nil(t, status.Transaction)
+	assert.Nil(t, status.Members)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageStatusBatchUnconfirmed(t *testing.T) {
+	or := newTestOrchestrator()
+
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.BatchTypeBroadcast,
+		},
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+		BatchID: batch.ID,
+		State:   fftypes.MessageStateRejected,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, status.Status)
+	assert.Equal(t, fftypes.OpStatusPending, status.Batch.Status)
+	assert.Equal(t, batch.ID, status.Batch.ID)
+	assert.Equal(t, "broadcast", status.Batch.SubType)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageStatusNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.GetMessageStatus(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetMessageStatusBatchError(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageStatusTXError(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageStatusTXStatusError(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, _ := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(&fftypes.Transaction{ID: batch.TX.ID, Type: fftypes.TransactionTypeUnpinned}, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageStatusGroupError(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, group := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageStatusGroupNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, group := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(nil, nil)
+	status, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Nil(t, status.Members)
+}
+
+func TestGetMessageStatusSendOpsError(t *testing.T) {
+	or := newTestOrchestrator()
+	msg, batch, group := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(group, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageStatusRecipientsError(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+	msg, batch, group := newTestPrivateMessageStatus()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batch.ID).Return(batch, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, batch.TX.ID).Return(nil, nil)
+	or.mdi.On("GetGroupByHash", mock.Anything, group.Hash).Return(group, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetMessageRecipients", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageStatus(context.Background(), "ns1", msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
@@ -18,10 +18,14 @@ package orchestrator
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestRequestReplyMissingGroup(t *testing.T) {
@@ -44,3 +48,116 @@ func TestRequestReply(t *testing.T) {
 	_, err := or.RequestReply(context.Background(), "ns1", input)
 	assert.NoError(t, err)
 }
+
+func TestActivateDraftMessageNow(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateDraft,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("ActivateDraftMessage", mock.Anything, mock.MatchedBy(func(m *fftypes.Message) bool {
+		return m.State == fftypes.MessageStateReady
+	})).Return(nil)
+
+	// A time in the past activates the draft immediately
+	sendAt := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	activated, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{SendAt: &sendAt})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateReady, activated.State)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestActivateDraftMessageScheduled(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateDraft,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	sendAt := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	or.mdi.On("ScheduleDraftMessage", mock.Anything, msg.Header.ID, &sendAt).Return(nil)
+
+	activated, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{SendAt: &sendAt})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateScheduled, activated.State)
+	assert.Equal(t, &sendAt, activated.SendAt)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestActivateDraftMessageScheduleFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateScheduled,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("ScheduleDraftMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	sendAt := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{SendAt: &sendAt})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestActivateDraftMessageScheduleAlreadyActivated(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateScheduled,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("ScheduleDraftMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(database.DeleteRecordNotFound)
+
+	sendAt := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{SendAt: &sendAt})
+	assert.Regexp(t, "FF10539.*ready", err)
+}
+
+func TestActivateDraftMessageNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(nil, nil)
+
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msgID.String(), &fftypes.DraftActivation{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestActivateDraftMessageNotDraft(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateSent,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{})
+	assert.Regexp(t, "FF10539.*sent", err)
+}
+
+func TestActivateDraftMessageAlreadyActivated(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateDraft,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("ActivateDraftMessage", mock.Anything, msg).Return(database.DeleteRecordNotFound)
+
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{})
+	assert.Regexp(t, "FF10539.*ready", err)
+}
+
+func TestActivateDraftMessageFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		State:  fftypes.MessageStateDraft,
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("ActivateDraftMessage", mock.Anything, msg).Return(fmt.Errorf("pop"))
+
+	_, err := or.ActivateDraftMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.DraftActivation{})
+	assert.Regexp(t, "pop", err)
+}
@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) GetNamespaceSigner(ctx context.Context, ns string) (*fftypes.NamespaceSigner, error) {
+	return or.identity.GetNamespaceSigner(ctx, ns)
+}
+
+func (or *orchestrator) SetNamespaceSigner(ctx context.Context, ns string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error) {
+	return or.identity.SetNamespaceSigner(ctx, ns, signerRef)
+}
+
+func (or *orchestrator) DeleteNamespaceSigner(ctx context.Context, ns string) error {
+	return or.identity.DeleteNamespaceSigner(ctx, ns)
+}
@@ -0,0 +1,51 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNamespaceSigner(t *testing.T) {
+	or := newTestOrchestrator()
+	signer := &fftypes.NamespaceSigner{Namespace: "ns1"}
+	or.mim.On("GetNamespaceSigner", context.Background(), "ns1").Return(signer, nil)
+	res, err := or.GetNamespaceSigner(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, signer, res)
+}
+
+func TestSetNamespaceSigner(t *testing.T) {
+	or := newTestOrchestrator()
+	signerRef := &fftypes.SignerRef{Key: "key1"}
+	signer := &fftypes.NamespaceSigner{Namespace: "ns1", SignerRef: *signerRef}
+	or.mim.On("SetNamespaceSigner", context.Background(), "ns1", signerRef).Return(signer, nil)
+	res, err := or.SetNamespaceSigner(context.Background(), "ns1", signerRef)
+	assert.NoError(t, err)
+	assert.Equal(t, signer, res)
+}
+
+func TestDeleteNamespaceSigner(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mim.On("DeleteNamespaceSigner", context.Background(), "ns1").Return(nil)
+	err := or.DeleteNamespaceSigner(context.Background(), "ns1")
+	assert.NoError(t, err)
+}
@@ -19,9 +19,13 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"sync"
 
+	"github.com/hyperledger/firefly/internal/approvals"
 	"github.com/hyperledger/firefly/internal/assets"
 	"github.com/hyperledger/firefly/internal/batch"
+	"github.com/hyperledger/firefly/internal/batchmigration"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/broadcast"
@@ -30,17 +34,22 @@ import (
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/database/difactory"
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
+	"github.com/hyperledger/firefly/internal/dataexport"
 	"github.com/hyperledger/firefly/internal/definitions"
 	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/messageimport"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/networkmap"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/rebuild"
 	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/secrets"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
 	"github.com/hyperledger/firefly/internal/syncasync"
@@ -64,6 +73,7 @@ var (
 	publicstorageConfig = config.NewPluginConfig("publicstorage")
 	dataexchangeConfig  = config.NewPluginConfig("dataexchange")
 	tokensConfig        = config.NewPluginConfig("tokens").Array()
+	secretsConfig       = config.NewPluginConfig("secrets")
 )
 
 // Orchestrator is the main interface behind the API, implementing the actions
@@ -78,21 +88,57 @@ type Orchestrator interface {
 	Data() data.Manager
 	Assets() assets.Manager
 	Contracts() contracts.Manager
+	MessageImport() messageimport.Manager
+	DataExport() dataexport.Manager
+	Approvals() approvals.Manager
 	Metrics() metrics.Manager
 	BatchManager() batch.Manager
 	Operations() operations.Manager
+	BatchMigration() batchmigration.Manager
 	IsPreInit() bool
+	IsStandby() bool
+	RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) error
+	RegisterDefinitionHandler(ctx context.Context, tag string, handler definitions.CustomHandler) error
 
 	// Status
 	GetStatus(ctx context.Context) (*fftypes.NodeStatus, error)
+	GetCircuitStatus(ctx context.Context) []*fftypes.CircuitStatus
+	GetReadiness(ctx context.Context) *fftypes.NodeReadiness
+	GetLiveness(ctx context.Context) *fftypes.NodeLiveness
+	RegisterPinKey(ctx context.Context) (*fftypes.Operation, error)
+
+	// Standby
+	GetStandbyStatus(ctx context.Context) (*fftypes.StandbyStatus, error)
+	PromoteStandby(ctx context.Context, req *fftypes.StandbyPromotion) (*fftypes.StandbyStatus, error)
+
+	// State snapshots
+	GetStateSnapshot(ctx context.Context) (*fftypes.StateSnapshot, error)
+	RestoreStateSnapshot(ctx context.Context, snapshot *fftypes.StateSnapshot) (*fftypes.StateSnapshotRestore, error)
 
 	// Subscription management
 	GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error)
 	GetSubscriptionByID(ctx context.Context, ns, id string) (*fftypes.Subscription, error)
+	GetSubscriptionStatus(ctx context.Context, ns, id string) (*fftypes.SubscriptionStatus, error)
+	GetSubscriptionRedeliveries(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.SubscriptionRedelivery, *database.FilterResult, error)
 	CreateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	DeleteSubscription(ctx context.Context, ns, id string) error
 
+	// Routing rules
+	GetRoutingRules(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.RoutingRule, *database.FilterResult, error)
+	GetRoutingRuleByNameOrID(ctx context.Context, ns, nameOrID string) (*fftypes.RoutingRule, error)
+	CreateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error)
+	CreateUpdateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error)
+	DeleteRoutingRule(ctx context.Context, ns, nameOrID string) error
+
+	// Namespace signers
+	GetNamespaceSigner(ctx context.Context, ns string) (*fftypes.NamespaceSigner, error)
+	SetNamespaceSigner(ctx context.Context, ns string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error)
+	DeleteNamespaceSigner(ctx context.Context, ns string) error
+
+	// Contract APIs
+	BroadcastContractAPI(ctx context.Context, httpServerURL, ns string, api *fftypes.ContractAPIWithListeners, waitConfirm bool) (*fftypes.ContractAPIWithListeners, error)
+
 	// Data Query
 	GetNamespace(ctx context.Context, ns string) (*fftypes.Namespace, error)
 	GetNamespaces(ctx context.Context, filter database.AndFilter) ([]*fftypes.Namespace, *database.FilterResult, error)
@@ -101,6 +147,7 @@ type Orchestrator interface {
 	GetTransactionBlockchainEvents(ctx context.Context, ns, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error)
 	GetTransactionStatus(ctx context.Context, ns, id string) (*fftypes.TransactionStatus, error)
 	GetTransactions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Transaction, *database.FilterResult, error)
+	GetFeeSummaries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.FeeSummary, *database.FilterResult, error)
 	GetMessageByID(ctx context.Context, ns, id string) (*fftypes.Message, error)
 	GetMessageByIDWithData(ctx context.Context, ns, id string) (*fftypes.MessageInOut, error)
 	GetMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error)
@@ -108,6 +155,12 @@ type Orchestrator interface {
 	GetMessageTransaction(ctx context.Context, ns, id string) (*fftypes.Transaction, error)
 	GetMessageOperations(ctx context.Context, ns, id string) ([]*fftypes.Operation, *database.FilterResult, error)
 	GetMessageEvents(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.Event, *database.FilterResult, error)
+	GetMessageRecipients(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.MessageRecipient, *database.FilterResult, error)
+	GetMessageStatus(ctx context.Context, ns, id string) (*fftypes.MessageStatus, error)
+	GetMessageProof(ctx context.Context, ns, id string) (*fftypes.MessageProof, error)
+	GetCheckpoint(ctx context.Context, ns string, sequence int64) (*fftypes.Checkpoint, error)
+	DiffCheckpoint(ctx context.Context, ns string, remote *fftypes.Checkpoint) (*fftypes.CheckpointDiff, error)
+	WaitForMessageState(ctx context.Context, ns, id, waitFor, timeout string) error
 	GetMessageData(ctx context.Context, ns, id string) (fftypes.DataArray, error)
 	GetMessagesForData(ctx context.Context, ns, dataID string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error)
 	GetBatchByID(ctx context.Context, ns, id string) (*fftypes.BatchPersisted, error)
@@ -118,27 +171,46 @@ type Orchestrator interface {
 	GetDatatypeByName(ctx context.Context, ns, name, version string) (*fftypes.Datatype, error)
 	GetDatatypes(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Datatype, *database.FilterResult, error)
 	GetOperationByID(ctx context.Context, ns, id string) (*fftypes.Operation, error)
+	GetOperationByIDWithOutput(ctx context.Context, ns, id string) (*fftypes.Operation, error)
 	GetOperations(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error)
+	GetOperationsWithOutput(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error)
 	GetEventByID(ctx context.Context, ns, id string) (*fftypes.Event, error)
 	GetEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Event, *database.FilterResult, error)
 	GetEventsWithReferences(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.EnrichedEvent, *database.FilterResult, error)
 	GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error)
 	GetBlockchainEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error)
+	GetBlockchainEventByIDWithRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error)
+	GetBlockchainEventsWithRaw(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error)
 	GetPins(ctx context.Context, filter database.AndFilter) ([]*fftypes.Pin, *database.FilterResult, error)
+	Search(ctx context.Context, ns, query string, filter database.AndFilter) ([]*fftypes.SearchResult, *database.FilterResult, error)
+	GetChanges(ctx context.Context, ns string, since int64, limit uint64) (*fftypes.SyncChanges, error)
+	GetBusinessTransaction(ctx context.Context, ns, key string) (*fftypes.BusinessTransaction, error)
 
 	// Charts
-	GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*fftypes.ChartHistogram, error)
+	GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName, options *fftypes.ChartHistogramOptions) ([]*fftypes.ChartHistogram, error)
 
 	// Config Management
 	GetConfig(ctx context.Context) fftypes.JSONObject
+	GetPluginDiagnostics(ctx context.Context) []*fftypes.PluginDiagnostics
+	RunPluginDiagnosticAction(ctx context.Context, pluginType, name, action string) (*fftypes.PluginDiagnosticResult, error)
 	GetConfigRecord(ctx context.Context, key string) (*fftypes.ConfigRecord, error)
 	GetConfigRecords(ctx context.Context, filter database.AndFilter) ([]*fftypes.ConfigRecord, *database.FilterResult, error)
 	PutConfigRecord(ctx context.Context, key string, configRecord *fftypes.JSONAny) (outputValue *fftypes.JSONAny, err error)
 	DeleteConfigRecord(ctx context.Context, key string) (err error)
 	ResetConfig(ctx context.Context)
+	GetLogLevels(ctx context.Context) []*fftypes.LogLevel
+	SetLogLevel(ctx context.Context, component string, input *fftypes.LogLevelInput) (*fftypes.LogLevel, error)
+	ResetLogLevel(ctx context.Context, component string) error
+
+	// Offline tools
+	MigrateBatches(ctx context.Context) (*fftypes.BatchMigrationStatus, error)
+	Rebuild(ctx context.Context) (*fftypes.RebuildStatus, error)
 
 	// Message Routing
 	RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (reply *fftypes.MessageInOut, err error)
+
+	// Message Drafts
+	ActivateDraftMessage(ctx context.Context, ns, id string, input *fftypes.DraftActivation) (*fftypes.Message, error)
 }
 
 type orchestrator struct {
@@ -165,11 +237,25 @@ type orchestrator struct {
 	bc             boundCallbacks
 	preInitMode    bool
 	contracts      contracts.Manager
+	messageImport  messageimport.Manager
+	dataExport     dataexport.Manager
+	approvals      approvals.Manager
 	node           *fftypes.UUID
 	metrics        metrics.Manager
 	operations     operations.Manager
 	sharedDownload shareddownload.Manager
 	txHelper       txcommon.Helper
+	batchMigration batchmigration.Manager
+	rebuild        rebuild.Manager
+	standby        bool
+	standbyMux     sync.Mutex
+	promoteMux     sync.Mutex
+	promoted       *fftypes.FFTime
+	bootstrapDone  chan struct{}
+
+	devIdentitiesDone  chan struct{}
+	ephemeralDone      chan struct{}
+	definitionHandlers map[string]definitions.CustomHandler
 }
 
 func NewOrchestrator() Orchestrator {
@@ -183,6 +269,7 @@ func NewOrchestrator() Orchestrator {
 	ssfactory.InitPrefix(publicstorageConfig)
 	dxfactory.InitPrefix(dataexchangeConfig)
 	tifactory.InitPrefix(tokensConfig)
+	secrets.InitPrefix(secretsConfig)
 
 	return or
 }
@@ -190,14 +277,22 @@ func NewOrchestrator() Orchestrator {
 func (or *orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc) (err error) {
 	or.ctx = ctx
 	or.cancelCtx = cancelCtx
+	or.standby = config.GetBool(config.StandbyEnabled)
+	if err = faults.Load(ctx); err != nil {
+		return err
+	}
 	err = or.initPlugins(ctx)
 	if or.preInitMode {
 		return nil
 	}
 	if err == nil {
-		err = or.initComponents(ctx)
+		err = or.initDevIdentities(ctx)
 	}
 	if err == nil {
+		err = or.initComponents(ctx)
+	}
+	if err == nil && !or.standby {
+		// In standby the database is a read-only replica, so namespaces are initialized on promotion
 		err = or.initNamespaces(ctx)
 	}
 	// Bind together the blockchain interface callbacks, with the events manager
@@ -213,7 +308,18 @@ func (or *orchestrator) Start() error {
 		log.L(or.ctx).Infof("Orchestrator in pre-init mode, waiting for initialization")
 		return nil
 	}
+	if or.IsStandby() {
+		log.L(or.ctx).Infof("Orchestrator in standby mode, waiting for promotion")
+		return nil
+	}
+	return or.start()
+}
+
+func (or *orchestrator) start() error {
 	err := or.blockchain.Start()
+	if err == nil {
+		err = or.operations.Start()
+	}
 	if err == nil {
 		err = or.batch.Start()
 	}
@@ -229,6 +335,12 @@ func (or *orchestrator) Start() error {
 	if err == nil {
 		err = or.sharedDownload.Start()
 	}
+	if err == nil {
+		err = or.dataExport.Start()
+	}
+	if err == nil {
+		err = or.messageImport.Start()
+	}
 	if err == nil {
 		for _, el := range or.tokens {
 			if err = el.Start(); err != nil {
@@ -239,6 +351,16 @@ func (or *orchestrator) Start() error {
 	if err == nil {
 		err = or.metrics.Start()
 	}
+	if err == nil {
+		err = or.approvals.Start()
+	}
+	if err == nil {
+		err = or.startBootstrap()
+	}
+	if err == nil {
+		or.startDevIdentities()
+		or.startEphemeralNamespaces()
+	}
 	or.started = true
 	return err
 }
@@ -263,6 +385,10 @@ func (or *orchestrator) WaitStop() {
 		or.sharedDownload.WaitStop()
 		or.sharedDownload = nil
 	}
+	if or.operations != nil {
+		or.operations.WaitStop()
+		or.operations = nil
+	}
 	or.started = false
 }
 
@@ -302,6 +428,34 @@ func (or *orchestrator) Contracts() contracts.Manager {
 	return or.contracts
 }
 
+// RunAsGroup runs the steps of a request that writes to the database more than once in a single
+// database transaction, so a failure part way through does not leave the earlier writes behind.
+// Calls made to the managers with the supplied context join the transaction.
+//
+// The write handlers that do not use it, or a group in their manager, are excluded because:
+//   - Broadcasts and private messages, including definitions and identity registrations, are written by the
+//     message writer. With workers configured it writes on its own background transaction, which a caller's
+//     transaction cannot include, and waiting on it from inside one can block on the database connection.
+//   - Calls to blockchain, token and data exchange connectors cannot be rolled back by a transaction, so those
+//     steps compensate on failure instead, such as the listener templates of a contract API.
+//   - The remaining handlers make a single write, such as deleting a subscription, routing rule or group alias,
+//     setting a config record or namespace signer, or activating a draft message.
+func (or *orchestrator) RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) error {
+	return or.database.RunAsGroup(ctx, fn)
+}
+
+func (or *orchestrator) MessageImport() messageimport.Manager {
+	return or.messageImport
+}
+
+func (or *orchestrator) DataExport() dataexport.Manager {
+	return or.dataExport
+}
+
+func (or *orchestrator) Approvals() approvals.Manager {
+	return or.approvals
+}
+
 func (or *orchestrator) Metrics() metrics.Manager {
 	return or.metrics
 }
@@ -310,6 +464,10 @@ func (or *orchestrator) Operations() operations.Manager {
 	return or.operations
 }
 
+func (or *orchestrator) BatchMigration() batchmigration.Manager {
+	return or.batchMigration
+}
+
 func (or *orchestrator) initDatabaseCheckPreinit(ctx context.Context) (err error) {
 	if or.database == nil {
 		diType := config.GetString(config.DatabaseType)
@@ -331,7 +489,11 @@ func (or *orchestrator) initDatabaseCheckPreinit(ctx context.Context) (err error
 		or.preInitMode = true
 		return nil
 	}
-	return config.MergeConfig(configRecords)
+	if err = config.MergeConfig(configRecords); err != nil {
+		return err
+	}
+	// The config records might contain secret references of their own
+	return secrets.ResolveConfig(ctx, secretsConfig)
 }
 
 func (or *orchestrator) initDataExchange(ctx context.Context) (err error) {
@@ -380,6 +542,11 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 		or.metrics = metrics.NewMetricsManager(ctx)
 	}
 
+	// Secrets must be resolved before any plugin reads its config
+	if err = secrets.ResolveConfig(ctx, secretsConfig); err != nil {
+		return err
+	}
+
 	if err = or.initDatabaseCheckPreinit(ctx); err != nil {
 		return err
 	} else if or.preInitMode {
@@ -405,6 +572,9 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 	if err = or.blockchain.Init(ctx, blockchainConfig.SubPrefix(or.blockchain.Name()), &or.bc, or.metrics); err != nil {
 		return err
 	}
+	if err = or.checkChainID(ctx); err != nil {
+		return err
+	}
 
 	storageConfig := sharedstorageConfig
 	if or.sharedstorage == nil {
@@ -471,6 +641,24 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 	return nil
 }
 
+// checkChainID stops the node starting if the blockchain is on a different chain to the one recorded
+// on the most recent transaction, as the node has been pointed at a different network since it last ran
+func (or *orchestrator) checkChainID(ctx context.Context) error {
+	chainID := or.blockchain.ChainID()
+	if chainID == 0 {
+		return nil
+	}
+	fb := database.TransactionQueryFactory.NewFilter(ctx)
+	txs, _, err := or.database.GetTransactions(ctx, fb.Neq("chainid", 0).Sort("created").Descending().Limit(1))
+	if err != nil {
+		return err
+	}
+	if len(txs) > 0 && txs[0].ChainID != chainID {
+		return i18n.NewError(ctx, i18n.MsgChainIDChanged, strconv.FormatInt(chainID, 10), txs[0].ID, strconv.FormatInt(txs[0].ChainID, 10))
+	}
+	return nil
+}
+
 func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 
 	if or.data == nil {
@@ -481,7 +669,7 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.txHelper == nil {
-		or.txHelper = txcommon.NewTransactionHelper(or.database, or.data)
+		or.txHelper = txcommon.NewTransactionHelper(or.database, or.data, txcommon.WithChainID(or.blockchain.ChainID()))
 	}
 
 	if or.identity == nil {
@@ -499,7 +687,13 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.operations == nil {
-		if or.operations, err = operations.NewOperationsManager(ctx, or.database); err != nil {
+		if or.operations, err = operations.NewOperationsManager(ctx, or.database, or.txHelper); err != nil {
+			return err
+		}
+	}
+
+	if or.batchMigration == nil {
+		if or.batchMigration, err = batchmigration.NewBatchMigrator(ctx, or.database); err != nil {
 			return err
 		}
 	}
@@ -507,7 +701,7 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	or.syncasync = syncasync.NewSyncAsyncBridge(ctx, or.database, or.data)
 
 	if or.batchpin == nil {
-		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchain, or.metrics, or.operations); err != nil {
+		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchain, or.metrics, or.operations, or.txHelper); err != nil {
 			return err
 		}
 	}
@@ -524,8 +718,14 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.approvals == nil {
+		if or.approvals, err = approvals.NewApprovalManager(ctx, or.database); err != nil {
+			return err
+		}
+	}
+
 	if or.assets == nil {
-		or.assets, err = assets.NewAssetManager(ctx, or.database, or.identity, or.data, or.syncasync, or.broadcast, or.messaging, or.tokens, or.metrics, or.operations, or.txHelper)
+		or.assets, err = assets.NewAssetManager(ctx, or.database, or.identity, or.data, or.syncasync, or.broadcast, or.messaging, or.tokens, or.metrics, or.operations, or.txHelper, or.approvals)
 		if err != nil {
 			return err
 		}
@@ -538,7 +738,24 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.messageImport == nil {
+		or.messageImport, err = messageimport.NewImportManager(ctx, or.database, or.data, or.txHelper, or.broadcast, or.messaging)
+		if err != nil {
+			return err
+		}
+	}
+
+	if or.dataExport == nil {
+		or.dataExport, err = dataexport.NewExportManager(ctx, or.database, or.sharedstorage, or.txHelper)
+		if err != nil {
+			return err
+		}
+	}
+
 	or.definitions = definitions.NewDefinitionHandlers(or.database, or.blockchain, or.dataexchange, or.data, or.identity, or.broadcast, or.messaging, or.assets, or.contracts)
+	if err = or.registerDefinitionHandlers(ctx); err != nil {
+		return err
+	}
 
 	if or.sharedDownload == nil {
 		or.sharedDownload, err = shareddownload.NewDownloadManager(ctx, or.database, or.sharedstorage, or.dataexchange, or.operations, &or.bc)
@@ -548,14 +765,14 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.events == nil {
-		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchain, or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.sharedDownload, or.metrics, or.txHelper)
+		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchain, or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.contracts, or.sharedDownload, or.operations, or.metrics, or.txHelper)
 		if err != nil {
 			return err
 		}
 	}
 
 	if or.networkmap == nil {
-		or.networkmap, err = networkmap.NewNetworkMap(ctx, or.database, or.broadcast, or.dataexchange, or.identity, or.syncasync)
+		or.networkmap, err = networkmap.NewNetworkMap(ctx, or.database, or.broadcast, or.messaging, or.dataexchange, or.identity, or.syncasync)
 		if err != nil {
 			return err
 		}
@@ -25,7 +25,9 @@ import (
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
 	"github.com/hyperledger/firefly/internal/restclient"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/batchmigrationmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -33,10 +35,12 @@ import (
 	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/dataexportmocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/identitymocks"
+	"github.com/hyperledger/firefly/mocks/messageimportmocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
@@ -77,6 +81,10 @@ type testOrchestrator struct {
 	mbp *batchpinmocks.Submitter
 	mth *txcommonmocks.Helper
 	msd *shareddownloadmocks.Manager
+	mmg *batchmigrationmocks.Manager
+	mmp *messageimportmocks.Manager
+	mde *dataexportmocks.Manager
+	mar *approvalmocks.Manager
 }
 
 func newTestOrchestrator() *testOrchestrator {
@@ -107,6 +115,10 @@ func newTestOrchestrator() *testOrchestrator {
 		mbp: &batchpinmocks.Submitter{},
 		mth: &txcommonmocks.Helper{},
 		msd: &shareddownloadmocks.Manager{},
+		mmg: &batchmigrationmocks.Manager{},
+		mmp: &messageimportmocks.Manager{},
+		mde: &dataexportmocks.Manager{},
+		mar: &approvalmocks.Manager{},
 	}
 	tor.orchestrator.database = tor.mdi
 	tor.orchestrator.data = tor.mdm
@@ -128,10 +140,15 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.batchpin = tor.mbp
 	tor.orchestrator.sharedDownload = tor.msd
 	tor.orchestrator.txHelper = tor.mth
+	tor.orchestrator.batchMigration = tor.mmg
+	tor.orchestrator.messageImport = tor.mmp
+	tor.orchestrator.dataExport = tor.mde
+	tor.orchestrator.approvals = tor.mar
 	tor.mdi.On("Name").Return("mock-di").Maybe()
 	tor.mem.On("Name").Return("mock-ei").Maybe()
 	tor.mps.On("Name").Return("mock-ps").Maybe()
 	tor.mbi.On("Name").Return("mock-bi").Maybe()
+	tor.mbi.On("ChainID").Return(int64(0)).Maybe()
 	tor.mii.On("Name").Return("mock-ii").Maybe()
 	tor.mdx.On("Name").Return("mock-dx").Maybe()
 	tor.mam.On("Name").Return("mock-am").Maybe()
@@ -141,6 +158,15 @@ func newTestOrchestrator() *testOrchestrator {
 	return tor
 }
 
+func (tor *testOrchestrator) mockRunAsGroup() {
+	rag := tor.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+}
+
 func TestNewOrchestrator(t *testing.T) {
 	or := NewOrchestrator()
 	assert.NotNil(t, or)
@@ -220,6 +246,69 @@ func TestBlockchainInitFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestBlockchainChainIDChanged(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("Name").Return("mock-bi")
+	mbi.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mbi.On("ChainID").Return(int64(1337))
+	or.blockchain = mbi
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return([]*fftypes.Transaction{
+		{ID: fftypes.NewUUID(), ChainID: 1},
+	}, nil, nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10571.*1337.*chain ID 1$", err)
+}
+
+func TestCheckChainIDSame(t *testing.T) {
+	or := newTestOrchestrator()
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("ChainID").Return(int64(1337))
+	or.blockchain = mbi
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return([]*fftypes.Transaction{
+		{ID: fftypes.NewUUID(), ChainID: 1337},
+	}, nil, nil)
+	err := or.checkChainID(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestCheckChainIDQueryFail(t *testing.T) {
+	or := newTestOrchestrator()
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("ChainID").Return(int64(1337))
+	or.blockchain = mbi
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.checkChainID(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInitSecretsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.NodeName, "${env:FF_UT_UNSET_SECRET}")
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10439.*node.name.*FF10435", err)
+}
+
+func TestBlockchainInitMergeConfigRecordsSecretFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{
+		{
+			Key:   "node.name",
+			Value: fftypes.JSONAnyPtr(`"${env:FF_UT_UNSET_SECRET}"`),
+		},
+	}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10439.*node.name.*FF10435", err)
+}
+
 func TestBlockchainInitGetConfigRecordsFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -562,6 +651,30 @@ func TestInitContractsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitMessageImportComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.messageImport = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitDataExportComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.dataExport = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitApprovalsComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.approvals = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestInitBatchPinComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -578,11 +691,20 @@ func TestInitOperationsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitBatchMigrationComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.batchMigration = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestStartBatchFail(t *testing.T) {
 	config.Reset()
 	or := newTestOrchestrator()
 	or.mba.On("Start").Return(fmt.Errorf("pop"))
 	or.mbi.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
 	err := or.Start()
 	assert.EqualError(t, err, "pop")
 }
@@ -591,12 +713,15 @@ func TestStartTokensFail(t *testing.T) {
 	config.Reset()
 	or := newTestOrchestrator()
 	or.mbi.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
 	or.mba.On("Start").Return(nil)
 	or.mem.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
 	or.mpm.On("Start").Return(nil)
 	or.mam.On("Start").Return(nil)
 	or.msd.On("Start").Return(nil)
+	or.mde.On("Start").Return(nil)
+	or.mmp.On("Start").Return(nil)
 	or.mti.On("Start").Return(fmt.Errorf("pop"))
 	err := or.Start()
 	assert.EqualError(t, err, "pop")
@@ -606,6 +731,7 @@ func TestStartStopOk(t *testing.T) {
 	config.Reset()
 	or := newTestOrchestrator()
 	or.mbi.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
 	or.mba.On("Start").Return(nil)
 	or.mem.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
@@ -614,6 +740,9 @@ func TestStartStopOk(t *testing.T) {
 	or.mti.On("Start").Return(nil)
 	or.mmi.On("Start").Return(nil)
 	or.msd.On("Start").Return(nil)
+	or.mde.On("Start").Return(nil)
+	or.mmp.On("Start").Return(nil)
+	or.mar.On("Start").Return(nil)
 	or.mbi.On("WaitStop").Return(nil)
 	or.mba.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
@@ -622,6 +751,7 @@ func TestStartStopOk(t *testing.T) {
 	or.mti.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
 	or.msd.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
 	err := or.Start()
 	assert.NoError(t, err)
 	or.WaitStop()
@@ -714,8 +844,46 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mdm, or.Data())
 	assert.Equal(t, or.mam, or.Assets())
 	assert.Equal(t, or.mcm, or.Contracts())
+	assert.Equal(t, or.mmp, or.MessageImport())
+	assert.Equal(t, or.mde, or.DataExport())
+	assert.Equal(t, or.mar, or.Approvals())
 	assert.Equal(t, or.mmi, or.Metrics())
 	assert.Equal(t, or.mom, or.Operations())
+	assert.Equal(t, or.mmg, or.BatchMigration())
+}
+
+func TestInitFaultsNotBuilt(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.FaultsScenario, "scenario.yaml")
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10451", err)
+}
+
+func TestInitStandby(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("InitPrefix", mock.Anything).Return()
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mti.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mmi.On("Init").Return(nil)
+	err := config.ReadConfig(configDir + "/firefly.core.yaml")
+	assert.NoError(t, err)
+	config.Set(config.StandbyEnabled, true)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err = or.Init(ctx, cancelCtx)
+	assert.NoError(t, err)
+
+	assert.True(t, or.IsStandby())
+	err = or.Start()
+	assert.NoError(t, err)
+	or.mdi.AssertNotCalled(t, "UpsertNamespace", mock.Anything, mock.Anything, mock.Anything)
+	or.mbi.AssertNotCalled(t, "Start")
 }
 
 func TestInitDataExchangeGetNodesFail(t *testing.T) {
@@ -23,6 +23,10 @@ import (
 )
 
 func (or *orchestrator) attemptChangeEventDispatch(ev *fftypes.ChangeEvent) {
+	if or.events == nil {
+		// Offline tools, such as the batch migration, run without the event manager
+		return
+	}
 	// For change events we're not processing as a system, we don't block our processing to dispatch
 	// them remotely. So if the queue is full, we discard the event rather than blocking.
 	select {
@@ -72,6 +76,8 @@ func (or *orchestrator) UUIDCollectionNSEvent(resType database.UUIDCollectionNS,
 		or.events.DeletedSubscriptions() <- id
 	case eventType == fftypes.ChangeEventTypeUpdated && resType == database.CollectionSubscriptions:
 		or.events.SubscriptionUpdates() <- id
+	case resType == database.CollectionRoutingRules:
+		or.events.RoutingRuleChanges() <- ns
 	}
 	or.attemptChangeEventDispatch(&fftypes.ChangeEvent{
 		Collection: string(resType),
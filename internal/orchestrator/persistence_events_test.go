@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/eventmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMessageCreated(t *testing.T) {
@@ -94,6 +95,19 @@ func TestSubscriptionDeleted(t *testing.T) {
 	mem.AssertExpectations(t)
 }
 
+func TestRoutingRuleChanged(t *testing.T) {
+	mem := &eventmocks.EventManager{}
+	o := &orchestrator{
+		events: mem,
+	}
+	routingRuleChanges := make(chan string, 1)
+	mem.On("RoutingRuleChanges").Return((chan<- string)(routingRuleChanges))
+	mem.On("ChangeEvents").Return((chan<- *fftypes.ChangeEvent)(make(chan *fftypes.ChangeEvent, 1)))
+	o.UUIDCollectionNSEvent(database.CollectionRoutingRules, fftypes.ChangeEventTypeUpdated, "ns1", fftypes.NewUUID())
+	assert.Equal(t, "ns1", <-routingRuleChanges)
+	mem.AssertExpectations(t)
+}
+
 func TestUUIDCollectionEventFull(t *testing.T) {
 	mem := &eventmocks.EventManager{}
 	o := &orchestrator{
@@ -115,3 +129,8 @@ func TestHashCollectionNSEventOk(t *testing.T) {
 	o.HashCollectionNSEvent(database.CollectionGroups, fftypes.ChangeEventTypeDeleted, "ns1", fftypes.NewRandB32())
 	mem.AssertExpectations(t)
 }
+
+func TestChangeEventOffline(t *testing.T) {
+	o := &orchestrator{}
+	o.UUIDCollectionNSEvent(database.CollectionBatches, fftypes.ChangeEventTypeUpdated, "ns1", fftypes.NewUUID())
+}
@@ -0,0 +1,41 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/rebuild"
+	"github.com/hyperledger/firefly/internal/secrets"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Rebuild clears the state derived from aggregating the pinned batches, while the node is stopped, so it is
+// reconstructed when the node restarts. Only the database plugin is initialized.
+func (or *orchestrator) Rebuild(ctx context.Context) (*fftypes.RebuildStatus, error) {
+	if err := secrets.ResolveConfig(ctx, secretsConfig); err != nil {
+		return nil, err
+	}
+	if err := or.initDatabaseCheckPreinit(ctx); err != nil {
+		return nil, err
+	}
+	if or.rebuild == nil {
+		// Cannot fail, as the database is initialized
+		or.rebuild, _ = rebuild.NewRebuildManager(ctx, or.database)
+	}
+	return or.rebuild.Run(ctx)
+}
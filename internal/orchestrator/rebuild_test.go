@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRebuild(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil)
+	or.mdi.On("RunAsGroup", mock.Anything, mock.Anything).Return(nil)
+
+	status, err := or.Rebuild(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Messages)
+	assert.NotNil(t, status.Completed)
+}
+
+func TestRebuildSecretsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.NodeName, "${env:FF_UT_UNSET_SECRET}")
+
+	_, err := or.Rebuild(context.Background())
+	assert.Regexp(t, "FF10439", err)
+}
+
+func TestRebuildDatabaseFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := or.Rebuild(context.Background())
+	assert.EqualError(t, err, "pop")
+}
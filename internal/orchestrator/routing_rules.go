@@ -0,0 +1,103 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) CreateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error) {
+	return or.createUpdateRoutingRule(ctx, ns, rule, true)
+}
+
+func (or *orchestrator) CreateUpdateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error) {
+	return or.createUpdateRoutingRule(ctx, ns, rule, false)
+}
+
+func (or *orchestrator) createUpdateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule, mustNew bool) (*fftypes.RoutingRule, error) {
+	rule.Namespace = ns
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if err := rule.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	// The existence check and the write are made in one database transaction, so a concurrent
+	// request for the same name cannot create a duplicate between them
+	err := or.RunAsGroup(ctx, func(ctx context.Context) error {
+		// Do a check first for existence, to give a nice 409 if we find one
+		existing, err := or.database.GetRoutingRuleByName(ctx, ns, rule.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if mustNew {
+				return i18n.NewError(ctx, i18n.MsgAlreadyExists, "routing rule", ns, rule.Name)
+			}
+			rule.ID = existing.ID
+			rule.Created = existing.Created
+			rule.Updated = fftypes.Now()
+		} else {
+			rule.ID = fftypes.NewUUID()
+			rule.Created = fftypes.Now()
+			rule.Updated = nil
+		}
+
+		// The subscription manager reloads the rules for the namespace when it is notified of the change
+		return or.database.UpsertRoutingRule(ctx, rule, !mustNew)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (or *orchestrator) GetRoutingRules(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.RoutingRule, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetRoutingRules(ctx, filter)
+}
+
+func (or *orchestrator) GetRoutingRuleByNameOrID(ctx context.Context, ns, nameOrID string) (rule *fftypes.RoutingRule, err error) {
+	id, err := fftypes.ParseUUID(ctx, nameOrID)
+	if err != nil {
+		if err := fftypes.ValidateFFNameField(ctx, nameOrID, "name"); err != nil {
+			return nil, err
+		}
+		if rule, err = or.database.GetRoutingRuleByName(ctx, ns, nameOrID); err != nil {
+			return nil, err
+		}
+	} else if rule, err = or.database.GetRoutingRuleByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return rule, nil
+}
+
+func (or *orchestrator) DeleteRoutingRule(ctx context.Context, ns, nameOrID string) error {
+	rule, err := or.GetRoutingRuleByNameOrID(ctx, ns, nameOrID)
+	if err != nil {
+		return err
+	}
+	return or.database.DeleteRoutingRuleByID(ctx, rule.ID)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateRoutingRuleBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "!wrong").Return(fmt.Errorf("pop"))
+	_, err := or.CreateRoutingRule(or.ctx, "!wrong", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestCreateRoutingRuleInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.CreateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name: "rule1",
+	})
+	assert.Regexp(t, "FF10473", err)
+}
+
+func TestCreateRoutingRuleLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(nil, fmt.Errorf("pop"))
+	_, err := or.CreateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestCreateRoutingRuleExists(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(&fftypes.RoutingRule{}, nil)
+	_, err := or.CreateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.Regexp(t, "FF10193", err)
+}
+
+func TestCreateRoutingRuleOk(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(nil, nil)
+	or.mdi.On("UpsertRoutingRule", mock.Anything, mock.Anything, false).Return(nil)
+	rule, err := or.CreateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Tag:    "^invoice",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", rule.Namespace)
+	assert.NotNil(t, rule.ID)
+	assert.NotNil(t, rule.Created)
+	assert.Nil(t, rule.Updated)
+}
+
+func TestCreateUpdateRoutingRuleExisting(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	existing := &fftypes.RoutingRule{
+		ID:      fftypes.NewUUID(),
+		Created: fftypes.Now(),
+	}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(existing, nil)
+	or.mdi.On("UpsertRoutingRule", mock.Anything, mock.Anything, true).Return(nil)
+	rule, err := or.CreateUpdateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, rule.ID)
+	assert.Equal(t, existing.Created, rule.Created)
+	assert.NotNil(t, rule.Updated)
+}
+
+func TestCreateUpdateRoutingRuleUpsertFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(nil, nil)
+	or.mdi.On("UpsertRoutingRule", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+	_, err := or.CreateUpdateRoutingRule(or.ctx, "ns1", &fftypes.RoutingRule{
+		Name:   "rule1",
+		Labels: fftypes.FFStringArray{"label1"},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetRoutingRules(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetRoutingRules", mock.Anything, mock.Anything).Return([]*fftypes.RoutingRule{}, nil, nil)
+	fb := database.RoutingRuleQueryFactory.NewFilter(or.ctx)
+	f := fb.And(fb.Eq("id", u))
+	_, _, err := or.GetRoutingRules(or.ctx, "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetRoutingRuleByNameOrIDByName(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(&fftypes.RoutingRule{Namespace: "ns1"}, nil)
+	rule, err := or.GetRoutingRuleByNameOrID(or.ctx, "ns1", "rule1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", rule.Namespace)
+}
+
+func TestGetRoutingRuleByNameOrIDBadName(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetRoutingRuleByNameOrID(or.ctx, "ns1", "!bad")
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetRoutingRuleByNameOrIDByNameFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetRoutingRuleByName", mock.Anything, "ns1", "rule1").Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetRoutingRuleByNameOrID(or.ctx, "ns1", "rule1")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetRoutingRuleByNameOrIDByIDFail(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetRoutingRuleByID", mock.Anything, u).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetRoutingRuleByNameOrID(or.ctx, "ns1", u.String())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetRoutingRuleByNameOrIDWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetRoutingRuleByID", mock.Anything, u).Return(&fftypes.RoutingRule{Namespace: "ns2"}, nil)
+	_, err := or.GetRoutingRuleByNameOrID(or.ctx, "ns1", u.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestDeleteRoutingRule(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetRoutingRuleByID", mock.Anything, u).Return(&fftypes.RoutingRule{ID: u, Namespace: "ns1"}, nil)
+	or.mdi.On("DeleteRoutingRuleByID", mock.Anything, u).Return(nil)
+	err := or.DeleteRoutingRule(or.ctx, "ns1", u.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteRoutingRuleNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetRoutingRuleByID", mock.Anything, u).Return(nil, nil)
+	err := or.DeleteRoutingRule(or.ctx, "ns1", u.String())
+	assert.Regexp(t, "FF10109", err)
+}
@@ -0,0 +1,182 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type namedSequence struct {
+	name  string
+	value int64
+}
+
+func sequenceList(s *fftypes.StandbySequences) []namedSequence {
+	return []namedSequence{
+		{name: "messages", value: s.Messages},
+		{name: "events", value: s.Events},
+		{name: "pins", value: s.Pins},
+	}
+}
+
+func (or *orchestrator) IsStandby() bool {
+	or.standbyMux.Lock()
+	defer or.standbyMux.Unlock()
+	return or.standby
+}
+
+func (or *orchestrator) getLatestSequences(ctx context.Context) (*fftypes.StandbySequences, error) {
+	sequences := &fftypes.StandbySequences{}
+
+	mfb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := or.database.GetMessages(ctx, mfb.And().Sort("sequence").Descending().Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) > 0 {
+		sequences.Messages = msgs[0].Sequence
+	}
+
+	efb := database.EventQueryFactory.NewFilter(ctx)
+	events, _, err := or.database.GetEvents(ctx, efb.And().Sort("sequence").Descending().Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		sequences.Events = events[0].Sequence
+	}
+
+	pfb := database.PinQueryFactory.NewFilter(ctx)
+	pins, _, err := or.database.GetPins(ctx, pfb.And().Sort("sequence").Descending().Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(pins) > 0 {
+		sequences.Pins = pins[0].Sequence
+	}
+
+	return sequences, nil
+}
+
+func (or *orchestrator) GetStandbyStatus(ctx context.Context) (*fftypes.StandbyStatus, error) {
+	sequences, err := or.getLatestSequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	or.standbyMux.Lock()
+	defer or.standbyMux.Unlock()
+	return &fftypes.StandbyStatus{
+		Standby:   or.standby,
+		Promoted:  or.promoted,
+		Sequences: *sequences,
+	}, nil
+}
+
+// checkPromotion verifies the replicated database is complete and consistent enough to take over processing:
+// - it has reached any minimum sequences supplied by the caller
+// - no offset is beyond the latest sequence of the table it tracks
+// - replication has quiesced, so no more writes are arriving from the old primary
+func (or *orchestrator) checkPromotion(ctx context.Context, req *fftypes.StandbyPromotion) error {
+	before, err := or.getLatestSequences(ctx)
+	if err != nil {
+		return err
+	}
+
+	if req.MinSequences != nil {
+		required := sequenceList(req.MinSequences)
+		for i, latest := range sequenceList(before) {
+			if latest.value < required[i].value {
+				return i18n.NewError(ctx, i18n.MsgStandbyBehind, latest.name, latest.value, required[i].value)
+			}
+		}
+	}
+
+	offsets, _, err := or.database.GetOffsets(ctx, database.OffsetQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, offset := range offsets {
+		var latest namedSequence
+		switch offset.Type {
+		case fftypes.OffsetTypeAggregator:
+			latest = namedSequence{name: "pins", value: before.Pins}
+		case fftypes.OffsetTypeSubscription:
+			latest = namedSequence{name: "events", value: before.Events}
+		default:
+			continue
+		}
+		if offset.Current > latest.value {
+			return i18n.NewError(ctx, i18n.MsgStandbyOffsetAhead, offset.Type, offset.Name, offset.Current, latest.name, latest.value)
+		}
+	}
+
+	select {
+	case <-time.After(config.GetDuration(config.StandbyPromoteQuiesceTime)):
+	case <-ctx.Done():
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	}
+
+	after, err := or.getLatestSequences(ctx)
+	if err != nil {
+		return err
+	}
+	latest := sequenceList(after)
+	for i, previous := range sequenceList(before) {
+		if latest[i].value != previous.value {
+			return i18n.NewError(ctx, i18n.MsgStandbyStillReplicating, previous.name, previous.value, latest[i].value)
+		}
+	}
+	return nil
+}
+
+// PromoteStandby takes a standby node out of standby, after checking the replicated database is safe
+// to take over from (unless forced), then initializes namespaces and starts all pollers and plugin
+// event streams - including accepting WebSocket connections from applications.
+func (or *orchestrator) PromoteStandby(ctx context.Context, req *fftypes.StandbyPromotion) (*fftypes.StandbyStatus, error) {
+	or.promoteMux.Lock()
+	defer or.promoteMux.Unlock()
+
+	if !or.IsStandby() {
+		return nil, i18n.NewError(ctx, i18n.MsgNodeNotInStandby)
+	}
+
+	if req.Force {
+		log.L(ctx).Warnf("Forcing promotion from standby without safety checks")
+	} else if err := or.checkPromotion(ctx, req); err != nil {
+		return nil, err
+	}
+
+	log.L(ctx).Infof("Promoting node from standby")
+	if err := or.initNamespaces(ctx); err != nil {
+		return nil, err
+	}
+	or.standbyMux.Lock()
+	or.standby = false
+	or.promoted = fftypes.Now()
+	or.standbyMux.Unlock()
+	if err := or.start(); err != nil {
+		return nil, err
+	}
+	return or.GetStandbyStatus(ctx)
+}
@@ -0,0 +1,249 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockLatestSequences(or *testOrchestrator, messages, events, pins int64) {
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{{Sequence: messages}}, nil, nil).Once()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: events}}, nil, nil).Once()
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: pins}}, nil, nil).Once()
+}
+
+func mockStartAll(or *testOrchestrator) {
+	or.mbi.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.mpm.On("Start").Return(nil)
+	or.msd.On("Start").Return(nil)
+	or.mde.On("Start").Return(nil)
+	or.mmp.On("Start").Return(nil)
+	or.mti.On("Start").Return(nil)
+	or.mmi.On("Start").Return(nil)
+	or.mar.On("Start").Return(nil)
+}
+
+func TestGetStandbyStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	mockLatestSequences(or, 1, 2, 3)
+
+	status, err := or.GetStandbyStatus(or.ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.Standby)
+	assert.Nil(t, status.Promoted)
+	assert.Equal(t, fftypes.StandbySequences{Messages: 1, Events: 2, Pins: 3}, status.Sequences)
+}
+
+func TestGetStandbyStatusEmpty(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+
+	status, err := or.GetStandbyStatus(or.ctx)
+	assert.NoError(t, err)
+	assert.False(t, status.Standby)
+	assert.Equal(t, fftypes.StandbySequences{}, status.Sequences)
+}
+
+func TestGetStandbyStatusMessagesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStandbyStatus(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStandbyStatusEventsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStandbyStatus(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStandbyStatusPinsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStandbyStatus(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPromoteStandbyNotInStandby(t *testing.T) {
+	or := newTestOrchestrator()
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.Regexp(t, "FF10445", err)
+}
+
+func TestPromoteStandbyOk(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	config.Set(config.StandbyPromoteQuiesceTime, "0")
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{
+		{Type: fftypes.OffsetTypeAggregator, Name: "aggregator", Current: 30},
+		{Type: fftypes.OffsetTypeSubscription, Name: "sub1", Current: 20},
+		{Type: fftypes.OffsetTypeBatch, Name: "batch", Current: 100},
+	}, nil, nil)
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetNamespace", mock.Anything, mock.Anything).Return(nil, nil)
+	or.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(nil)
+	mockStartAll(or)
+	mockLatestSequences(or, 10, 20, 30)
+
+	status, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{
+		MinSequences: &fftypes.StandbySequences{Messages: 10, Events: 15, Pins: 0},
+	})
+	assert.NoError(t, err)
+	assert.False(t, status.Standby)
+	assert.NotNil(t, status.Promoted)
+	assert.False(t, or.IsStandby())
+	or.mdi.AssertExpectations(t)
+	or.mbi.AssertExpectations(t)
+}
+
+func TestPromoteStandbyForce(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	or.mdi.On("GetNamespace", mock.Anything, mock.Anything).Return(nil, nil)
+	or.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(nil)
+	mockStartAll(or)
+	mockLatestSequences(or, 10, 20, 30)
+
+	status, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{Force: true})
+	assert.NoError(t, err)
+	assert.False(t, status.Standby)
+	or.mdi.AssertNotCalled(t, "GetOffsets", mock.Anything, mock.Anything)
+}
+
+func TestPromoteStandbyBehind(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	mockLatestSequences(or, 10, 20, 30)
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{
+		MinSequences: &fftypes.StandbySequences{Messages: 10, Events: 21},
+	})
+	assert.Regexp(t, "FF10448.*events.*20.*21", err)
+	assert.True(t, or.IsStandby())
+}
+
+func TestPromoteStandbySequencesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPromoteStandbyGetOffsetsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPromoteStandbyOffsetAhead(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{
+		{Type: fftypes.OffsetTypeSubscription, Name: "sub1", Current: 21},
+	}, nil, nil)
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.Regexp(t, "FF10447.*sub1.*21.*events.*20", err)
+}
+
+func TestPromoteStandbyStillReplicating(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	config.Set(config.StandbyPromoteQuiesceTime, "0")
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	mockLatestSequences(or, 10, 20, 31)
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.Regexp(t, "FF10446.*pins.*30.*31", err)
+}
+
+func TestPromoteStandbyQuiesceSequencesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	config.Set(config.StandbyPromoteQuiesceTime, "0")
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPromoteStandbyContextCancelled(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	config.Set(config.StandbyPromoteQuiesceTime, "1h")
+	mockLatestSequences(or, 10, 20, 30)
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := or.PromoteStandby(ctx, &fftypes.StandbyPromotion{})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestPromoteStandbyInitNamespacesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	or.mdi.On("GetNamespace", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{Force: true})
+	assert.EqualError(t, err, "pop")
+	assert.True(t, or.IsStandby())
+}
+
+func TestPromoteStandbyStartFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	or.mdi.On("GetNamespace", mock.Anything, mock.Anything).Return(nil, nil)
+	or.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(nil)
+	or.mbi.On("Start").Return(fmt.Errorf("pop"))
+
+	_, err := or.PromoteStandby(or.ctx, &fftypes.StandbyPromotion{Force: true})
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,236 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// GetStateSnapshot reads the operational state of the node in a single database transaction, so the offsets,
+// nextpins and nonces in the snapshot are consistent with each other. The listener checkpoints can involve a
+// call to the blockchain connector, so they are read once the transaction is over.
+func (or *orchestrator) GetStateSnapshot(ctx context.Context) (snapshot *fftypes.StateSnapshot, err error) {
+	snapshot = &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Created: fftypes.Now(),
+	}
+	var listeners []*fftypes.ContractListener
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		if snapshot.Offsets, _, err = or.database.GetOffsets(ctx, database.OffsetQueryFactory.NewFilter(ctx).And()); err != nil {
+			return err
+		}
+		if snapshot.NextPins, _, err = or.database.GetNextPins(ctx, database.NextPinQueryFactory.NewFilter(ctx).And()); err != nil {
+			return err
+		}
+		if snapshot.Nonces, _, err = or.database.GetNonces(ctx, database.NonceQueryFactory.NewFilter(ctx).And()); err != nil {
+			return err
+		}
+		listeners, _, err = or.database.GetContractListeners(ctx, database.ContractListenerQueryFactory.NewFilter(ctx).And())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ListenerCheckpoints = make([]*fftypes.ContractListenerCheckpoint, 0, len(listeners))
+	for _, listener := range listeners {
+		checkpoint, err := or.contracts.GetContractListenerCheckpoint(ctx, listener.Namespace, listener.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		snapshot.ListenerCheckpoints = append(snapshot.ListenerCheckpoints, checkpoint)
+	}
+	return snapshot, nil
+}
+
+// latestSequence returns the highest sequence in the table an offset type refers to, or -1 if the table is empty
+func (or *orchestrator) latestSequence(ctx context.Context, offsetType fftypes.OffsetType) (int64, error) {
+	switch offsetType {
+	case fftypes.OffsetTypeAggregator:
+		pins, _, err := or.database.GetPins(ctx, database.PinQueryFactory.NewFilter(ctx).And().Sort("sequence").Descending().Limit(1))
+		if err != nil || len(pins) == 0 {
+			return -1, err
+		}
+		return pins[0].Sequence, nil
+	case fftypes.OffsetTypeSubscription:
+		events, _, err := or.database.GetEvents(ctx, database.EventQueryFactory.NewFilter(ctx).And().Sort("sequence").Descending().Limit(1))
+		if err != nil || len(events) == 0 {
+			return -1, err
+		}
+		return events[0].Sequence, nil
+	default:
+		return -1, nil
+	}
+}
+
+// restoreOffset moves an offset forwards. Offsets are local sequence numbers, so an offset beyond the latest
+// sequence in this database would skip every record written after the restore, until it caught up - this
+// happens if the snapshot is restored onto a database that was not rebuilt from the same backup.
+func (or *orchestrator) restoreOffset(ctx context.Context, offset *fftypes.Offset, latest map[fftypes.OffsetType]int64) (bool, error) {
+	latestSequence, ok := latest[offset.Type]
+	if !ok {
+		var err error
+		if latestSequence, err = or.latestSequence(ctx, offset.Type); err != nil {
+			return false, err
+		}
+		latest[offset.Type] = latestSequence
+	}
+	if offset.Current > latestSequence {
+		return false, i18n.NewError(ctx, i18n.MsgStateSnapshotOffsetAhead, offset.Type, offset.Name, offset.Current, latestSequence)
+	}
+
+	existing, err := or.database.GetOffset(ctx, offset.Type, offset.Name)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		restored := *offset
+		restored.RowID = 0
+		return true, or.database.UpsertOffset(ctx, &restored, false)
+	}
+	if existing.Current >= offset.Current {
+		return false, nil
+	}
+	update := database.OffsetQueryFactory.NewUpdate(ctx).Set("current", offset.Current)
+	return true, or.database.UpdateOffset(ctx, existing.RowID, update)
+}
+
+func (or *orchestrator) restoreNextPin(ctx context.Context, nextPin *fftypes.NextPin) (bool, error) {
+	existing, err := or.database.GetNextPinByContextAndIdentity(ctx, nextPin.Context, nextPin.Identity)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		restored := *nextPin
+		restored.Sequence = 0
+		return true, or.database.InsertNextPin(ctx, &restored)
+	}
+	if existing.Nonce >= nextPin.Nonce {
+		return false, nil
+	}
+	update := database.NextPinQueryFactory.NewUpdate(ctx).
+		Set("nonce", nextPin.Nonce).
+		Set("hash", nextPin.Hash)
+	return true, or.database.UpdateNextPin(ctx, existing.Sequence, update)
+}
+
+func (or *orchestrator) restoreNonce(ctx context.Context, nonce *fftypes.Nonce) (bool, error) {
+	existing, err := or.database.GetNonce(ctx, nonce.Context)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil && existing.Nonce >= nonce.Nonce {
+		return false, nil
+	}
+	return true, or.database.UpsertNonce(ctx, nonce)
+}
+
+// restoreListenerCheckpoint sets the checkpoint on a listener that already exists on this node, so the events
+// up to and including the checkpoint are ignored when the connector redelivers them
+func (or *orchestrator) restoreListenerCheckpoint(ctx context.Context, checkpoint *fftypes.ContractListenerCheckpoint) (bool, error) {
+	if checkpoint.Listener == nil || checkpoint.ProtocolID == "" {
+		return false, nil
+	}
+	listener, err := or.database.GetContractListenerByID(ctx, checkpoint.Listener)
+	if err != nil || listener == nil {
+		return false, err
+	}
+	current, err := or.contracts.GetContractListenerCheckpoint(ctx, listener.Namespace, listener.ID.String())
+	if err != nil {
+		return false, err
+	}
+	if current.ProtocolID >= checkpoint.ProtocolID {
+		return false, nil
+	}
+	if listener.Options == nil {
+		listener.Options = &fftypes.ContractListenerOptions{}
+	}
+	listener.Options.Checkpoint = &fftypes.ContractListenerCheckpoint{
+		Listener:   listener.ID,
+		FirstEvent: checkpoint.FirstEvent,
+		ProtocolID: checkpoint.ProtocolID,
+	}
+	return true, or.database.UpsertContractListener(ctx, listener)
+}
+
+func countRestore(count *fftypes.StateSnapshotRestoreCount, restored bool) {
+	if restored {
+		count.Restored++
+	} else {
+		count.Skipped++
+	}
+}
+
+// RestoreStateSnapshot writes a state snapshot to the database of a node in standby, before it is promoted.
+// State is only ever moved forwards - a record the node has already reached or passed is skipped - so a
+// stale snapshot cannot cause private messages to be re-ordered, or nonces to be reused.
+func (or *orchestrator) RestoreStateSnapshot(ctx context.Context, snapshot *fftypes.StateSnapshot) (*fftypes.StateSnapshotRestore, error) {
+	or.promoteMux.Lock()
+	defer or.promoteMux.Unlock()
+
+	if !or.IsStandby() {
+		return nil, i18n.NewError(ctx, i18n.MsgNodeNotInStandby)
+	}
+	if snapshot.Version != fftypes.StateSnapshotVersion {
+		return nil, i18n.NewError(ctx, i18n.MsgStateSnapshotVersion, snapshot.Version, fftypes.StateSnapshotVersion)
+	}
+
+	var result *fftypes.StateSnapshotRestore
+	err := or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		result = &fftypes.StateSnapshotRestore{}
+		latest := make(map[fftypes.OffsetType]int64)
+		for _, offset := range snapshot.Offsets {
+			restored, err := or.restoreOffset(ctx, offset, latest)
+			if err != nil {
+				return err
+			}
+			countRestore(&result.Offsets, restored)
+		}
+		for _, nextPin := range snapshot.NextPins {
+			restored, err := or.restoreNextPin(ctx, nextPin)
+			if err != nil {
+				return err
+			}
+			countRestore(&result.NextPins, restored)
+		}
+		for _, nonce := range snapshot.Nonces {
+			restored, err := or.restoreNonce(ctx, nonce)
+			if err != nil {
+				return err
+			}
+			countRestore(&result.Nonces, restored)
+		}
+		for _, checkpoint := range snapshot.ListenerCheckpoints {
+			restored, err := or.restoreListenerCheckpoint(ctx, checkpoint)
+			if err != nil {
+				return err
+			}
+			countRestore(&result.ListenerCheckpoints, restored)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Restored state snapshot created %s: offsets=%+v nextpins=%+v nonces=%+v listenerCheckpoints=%+v",
+		snapshot.Created, result.Offsets, result.NextPins, result.Nonces, result.ListenerCheckpoints)
+	return result, nil
+}
@@ -0,0 +1,297 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetStateSnapshot(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	listener := &fftypes.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	checkpoint := &fftypes.ContractListenerCheckpoint{Listener: listener.ID, FirstEvent: "12", ProtocolID: "000000000012/000001/000000"}
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{{Type: fftypes.OffsetTypeAggregator, Name: "aggregator", Current: 10}}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{{Context: fftypes.NewRandB32(), Nonce: 3}}, nil, nil)
+	or.mdi.On("GetNonces", mock.Anything, mock.Anything).Return([]*fftypes.Nonce{{Context: fftypes.NewRandB32(), Nonce: 5}}, nil, nil)
+	or.mdi.On("GetContractListeners", mock.Anything, mock.Anything).Return([]*fftypes.ContractListener{listener}, nil, nil)
+	or.mcm.On("GetContractListenerCheckpoint", mock.Anything, "ns1", listener.ID.String()).Return(checkpoint, nil)
+
+	snapshot, err := or.GetStateSnapshot(or.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.StateSnapshotVersion, snapshot.Version)
+	assert.NotNil(t, snapshot.Created)
+	assert.Equal(t, int64(10), snapshot.Offsets[0].Current)
+	assert.Equal(t, int64(3), snapshot.NextPins[0].Nonce)
+	assert.Equal(t, int64(5), snapshot.Nonces[0].Nonce)
+	assert.Equal(t, []*fftypes.ContractListenerCheckpoint{checkpoint}, snapshot.ListenerCheckpoints)
+}
+
+func TestGetStateSnapshotOffsetsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStateSnapshot(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStateSnapshotNextPinsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStateSnapshot(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStateSnapshotNoncesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil)
+	or.mdi.On("GetNonces", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStateSnapshot(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStateSnapshotListenersFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil)
+	or.mdi.On("GetNonces", mock.Anything, mock.Anything).Return([]*fftypes.Nonce{}, nil, nil)
+	or.mdi.On("GetContractListeners", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStateSnapshot(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetStateSnapshotCheckpointFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*fftypes.Offset{}, nil, nil)
+	or.mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil)
+	or.mdi.On("GetNonces", mock.Anything, mock.Anything).Return([]*fftypes.Nonce{}, nil, nil)
+	or.mdi.On("GetContractListeners", mock.Anything, mock.Anything).Return([]*fftypes.ContractListener{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mcm.On("GetContractListenerCheckpoint", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.GetStateSnapshot(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshot(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+
+	newOffset := &fftypes.Offset{Type: fftypes.OffsetTypeAggregator, Name: "aggregator", Current: 10}
+	behindOffset := &fftypes.Offset{Type: fftypes.OffsetTypeSubscription, Name: "sub1", Current: 20}
+	aheadOffset := &fftypes.Offset{Type: fftypes.OffsetTypeSubscription, Name: "sub2", Current: 30}
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 10}}, nil, nil).Once()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 40}}, nil, nil).Once()
+	or.mdi.On("GetOffset", mock.Anything, newOffset.Type, newOffset.Name).Return(nil, nil)
+	or.mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(o *fftypes.Offset) bool {
+		return o.Name == "aggregator" && o.Current == 10
+	}), false).Return(nil)
+	or.mdi.On("GetOffset", mock.Anything, behindOffset.Type, behindOffset.Name).Return(&fftypes.Offset{RowID: 1, Current: 15}, nil)
+	or.mdi.On("UpdateOffset", mock.Anything, int64(1), mock.MatchedBy(func(u database.Update) bool {
+		info, _ := u.Finalize()
+		val, _ := info.SetOperations[0].Value.Value()
+		return info.SetOperations[0].Field == "current" && val == int64(20)
+	})).Return(nil)
+	or.mdi.On("GetOffset", mock.Anything, aheadOffset.Type, aheadOffset.Name).Return(&fftypes.Offset{RowID: 2, Current: 35}, nil)
+
+	newNextPin := &fftypes.NextPin{Context: fftypes.NewRandB32(), Identity: "did:firefly:org/org1", Hash: fftypes.NewRandB32(), Nonce: 3}
+	behindNextPin := &fftypes.NextPin{Context: fftypes.NewRandB32(), Identity: "did:firefly:org/org1", Hash: fftypes.NewRandB32(), Nonce: 5}
+	aheadNextPin := &fftypes.NextPin{Context: fftypes.NewRandB32(), Identity: "did:firefly:org/org1", Hash: fftypes.NewRandB32(), Nonce: 7}
+	or.mdi.On("GetNextPinByContextAndIdentity", mock.Anything, newNextPin.Context, newNextPin.Identity).Return(nil, nil)
+	or.mdi.On("InsertNextPin", mock.Anything, mock.MatchedBy(func(np *fftypes.NextPin) bool {
+		return np.Hash.Equals(newNextPin.Hash) && np.Nonce == 3
+	})).Return(nil)
+	or.mdi.On("GetNextPinByContextAndIdentity", mock.Anything, behindNextPin.Context, behindNextPin.Identity).Return(&fftypes.NextPin{Sequence: 11, Nonce: 4}, nil)
+	or.mdi.On("UpdateNextPin", mock.Anything, int64(11), mock.Anything).Return(nil)
+	or.mdi.On("GetNextPinByContextAndIdentity", mock.Anything, aheadNextPin.Context, aheadNextPin.Identity).Return(&fftypes.NextPin{Sequence: 12, Nonce: 7}, nil)
+
+	newNonce := &fftypes.Nonce{Context: fftypes.NewRandB32(), Nonce: 8}
+	aheadNonce := &fftypes.Nonce{Context: fftypes.NewRandB32(), Nonce: 9}
+	or.mdi.On("GetNonce", mock.Anything, newNonce.Context).Return(nil, nil)
+	or.mdi.On("UpsertNonce", mock.Anything, newNonce).Return(nil)
+	or.mdi.On("GetNonce", mock.Anything, aheadNonce.Context).Return(&fftypes.Nonce{Nonce: 10}, nil)
+
+	behindListener := &fftypes.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	aheadListener := &fftypes.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", Options: &fftypes.ContractListenerOptions{}}
+	missingListener := fftypes.NewUUID()
+	or.mdi.On("GetContractListenerByID", mock.Anything, behindListener.ID).Return(behindListener, nil)
+	or.mcm.On("GetContractListenerCheckpoint", mock.Anything, "ns1", behindListener.ID.String()).Return(&fftypes.ContractListenerCheckpoint{}, nil)
+	or.mdi.On("UpsertContractListener", mock.Anything, mock.MatchedBy(func(l *fftypes.ContractListener) bool {
+		return l.ID.Equals(behindListener.ID) && l.Options.Checkpoint.ProtocolID == "000000000012/000001/000000"
+	})).Return(nil)
+	or.mdi.On("GetContractListenerByID", mock.Anything, aheadListener.ID).Return(aheadListener, nil)
+	or.mcm.On("GetContractListenerCheckpoint", mock.Anything, "ns1", aheadListener.ID.String()).Return(&fftypes.ContractListenerCheckpoint{ProtocolID: "000000000013/000000/000000"}, nil)
+	or.mdi.On("GetContractListenerByID", mock.Anything, missingListener).Return(nil, nil)
+
+	result, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version:  fftypes.StateSnapshotVersion,
+		Offsets:  []*fftypes.Offset{newOffset, behindOffset, aheadOffset},
+		NextPins: []*fftypes.NextPin{newNextPin, behindNextPin, aheadNextPin},
+		Nonces:   []*fftypes.Nonce{newNonce, aheadNonce},
+		ListenerCheckpoints: []*fftypes.ContractListenerCheckpoint{
+			{Listener: behindListener.ID, FirstEvent: "12", ProtocolID: "000000000012/000001/000000"},
+			{Listener: aheadListener.ID, FirstEvent: "12", ProtocolID: "000000000012/000001/000000"},
+			{Listener: missingListener, FirstEvent: "12", ProtocolID: "000000000012/000001/000000"},
+			{Listener: fftypes.NewUUID(), FirstEvent: "0"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &fftypes.StateSnapshotRestore{
+		Offsets:             fftypes.StateSnapshotRestoreCount{Restored: 2, Skipped: 1},
+		NextPins:            fftypes.StateSnapshotRestoreCount{Restored: 2, Skipped: 1},
+		Nonces:              fftypes.StateSnapshotRestoreCount{Restored: 1, Skipped: 1},
+		ListenerCheckpoints: fftypes.StateSnapshotRestoreCount{Restored: 1, Skipped: 3},
+	}, result)
+	or.mdi.AssertExpectations(t)
+	or.mcm.AssertExpectations(t)
+}
+
+func TestRestoreStateSnapshotNotStandby(t *testing.T) {
+	or := newTestOrchestrator()
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{Version: fftypes.StateSnapshotVersion})
+	assert.Regexp(t, "FF10445", err)
+}
+
+func TestRestoreStateSnapshotBadVersion(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{Version: 2})
+	assert.Regexp(t, "FF10548", err)
+}
+
+func TestRestoreStateSnapshotOffsetFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Offsets: []*fftypes.Offset{{Type: "unknown", Current: -1}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotOffsetBeyondDatabase(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Offsets: []*fftypes.Offset{{Type: fftypes.OffsetTypeAggregator, Name: "aggregator", Current: 10}},
+	})
+	assert.Regexp(t, "FF10566.*aggregator:aggregator.*10.*-1", err)
+}
+
+func TestRestoreStateSnapshotOffsetLatestPinFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Offsets: []*fftypes.Offset{{Type: fftypes.OffsetTypeAggregator, Name: "aggregator", Current: 10}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotOffsetLatestEventFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Offsets: []*fftypes.Offset{{Type: fftypes.OffsetTypeSubscription, Name: "sub1", Current: 10}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotNextPinFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetNextPinByContextAndIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version:  fftypes.StateSnapshotVersion,
+		NextPins: []*fftypes.NextPin{{}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotNonceFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetNonce", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version: fftypes.StateSnapshotVersion,
+		Nonces:  []*fftypes.Nonce{{}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotListenerFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetContractListenerByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version:             fftypes.StateSnapshotVersion,
+		ListenerCheckpoints: []*fftypes.ContractListenerCheckpoint{{Listener: fftypes.NewUUID(), ProtocolID: "000000000012/000001/000000"}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRestoreStateSnapshotListenerCheckpointFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mockRunAsGroup()
+	or.standby = true
+	or.mdi.On("GetContractListenerByID", mock.Anything, mock.Anything).Return(&fftypes.ContractListener{ID: fftypes.NewUUID()}, nil)
+	or.mcm.On("GetContractListenerCheckpoint", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RestoreStateSnapshot(or.ctx, &fftypes.StateSnapshot{
+		Version:             fftypes.StateSnapshotVersion,
+		ListenerCheckpoints: []*fftypes.ContractListenerCheckpoint{{Listener: fftypes.NewUUID(), ProtocolID: "000000000012/000001/000000"}},
+	})
+	assert.EqualError(t, err, "pop")
+}
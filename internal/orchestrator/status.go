@@ -19,7 +19,9 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/hyperledger/firefly/internal/circuit"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -88,5 +90,91 @@ func (or *orchestrator) GetStatus(ctx context.Context) (status *fftypes.NodeStat
 		}
 	}
 
+	// Reported whether or not the org is registered, as the org key must be able to pin to register the org
+	if pinAccess, err := or.batchpin.PinAccess(ctx); err != nil {
+		log.L(ctx).Warnf("Failed to query pin access for status: %s", err)
+	} else {
+		status.Org.PinAccess = pinAccess
+	}
+
+	for _, name := range or.tokenPluginNames() {
+		for _, warning := range or.tokens[name].ConnectorWarnings() {
+			status.Warnings = append(status.Warnings, &fftypes.NodeStatusWarning{Type: "tokens", Name: name, Warning: warning})
+		}
+	}
+
 	return status, nil
 }
+
+func (or *orchestrator) tokenPluginNames() []string {
+	names := make([]string, 0, len(or.tokens))
+	for name := range or.tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterPinKey submits the transaction to register the org key, when the batch pin contract restricts pinning to registered keys
+func (or *orchestrator) RegisterPinKey(ctx context.Context) (*fftypes.Operation, error) {
+	return or.batchpin.RegisterPinKey(ctx)
+}
+
+func (or *orchestrator) GetCircuitStatus(ctx context.Context) []*fftypes.CircuitStatus {
+	return circuit.Statuses()
+}
+
+func pluginHealth(pluginType, name string, required bool, err error) *fftypes.PluginHealth {
+	health := &fftypes.PluginHealth{
+		Type:     pluginType,
+		Name:     name,
+		Healthy:  err == nil,
+		Required: required,
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// GetReadiness checks each plugin is able to process requests. The node is not ready if the database,
+// blockchain or data exchange is unavailable, and degraded if any token connector is unavailable.
+// A node in standby reports the standby status, so it does not receive traffic until promoted.
+func (or *orchestrator) GetReadiness(ctx context.Context) *fftypes.NodeReadiness {
+	readiness := &fftypes.NodeReadiness{
+		Status: fftypes.ReadinessStatusReady,
+	}
+
+	readiness.Plugins = append(readiness.Plugins,
+		pluginHealth("database", or.database.Name(), true, or.databaseHealth(ctx)),
+		pluginHealth("blockchain", or.blockchain.Name(), true, or.blockchain.Health(ctx)),
+		pluginHealth("dataexchange", or.dataexchange.Name(), true, or.dataexchange.Health(ctx)),
+	)
+
+	for _, name := range or.tokenPluginNames() {
+		readiness.Plugins = append(readiness.Plugins, pluginHealth("tokens", name, false, or.tokens[name].Health(ctx)))
+	}
+
+	for _, plugin := range readiness.Plugins {
+		switch {
+		case plugin.Healthy:
+		case plugin.Required:
+			log.L(ctx).Warnf("Node not ready - %s plugin '%s' unavailable: %s", plugin.Type, plugin.Name, plugin.Error)
+			readiness.Status = fftypes.ReadinessStatusNotReady
+		case readiness.Status == fftypes.ReadinessStatusReady:
+			log.L(ctx).Warnf("Node degraded - %s plugin '%s' unavailable: %s", plugin.Type, plugin.Name, plugin.Error)
+			readiness.Status = fftypes.ReadinessStatusDegraded
+		}
+	}
+	if readiness.Status != fftypes.ReadinessStatusNotReady && or.IsStandby() {
+		readiness.Status = fftypes.ReadinessStatusStandby
+	}
+	return readiness
+}
+
+// GetLiveness reports the node as live until it is shut down
+func (or *orchestrator) GetLiveness(ctx context.Context) *fftypes.NodeLiveness {
+	return &fftypes.NodeLiveness{
+		Live: or.ctx.Err() == nil,
+	}
+}
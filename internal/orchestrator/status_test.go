@@ -19,7 +19,9 @@ package orchestrator
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/circuit"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
@@ -61,6 +63,13 @@ func TestGetStatusRegistered(t *testing.T) {
 			Value: "0x12345",
 		}},
 	}, nil, nil)
+	or.mbp.On("PinAccess", or.ctx).Return(&fftypes.PinAccess{
+		Permissioned: true,
+		Key:          "0x12345",
+		Registered:   true,
+	}, nil)
+
+	or.mti.On("ConnectorWarnings").Return([]string{"FF10551: degraded"})
 
 	status, err := or.GetStatus(or.ctx)
 	assert.NoError(t, err)
@@ -70,12 +79,14 @@ func TestGetStatusRegistered(t *testing.T) {
 	assert.Equal(t, "org1", status.Org.Name)
 	assert.True(t, status.Org.Registered)
 	assert.Equal(t, "did:firefly:org/org1", status.Org.DID)
+	assert.True(t, status.Org.PinAccess.Registered)
 
 	assert.Equal(t, *orgID, *status.Org.ID)
 	assert.Equal(t, "node1", status.Node.Name)
 	assert.True(t, status.Node.Registered)
 	assert.Equal(t, *nodeID, *status.Node.ID)
 	assert.Equal(t, "0x12345", status.Org.Verifiers[0].Value)
+	assert.Equal(t, []*fftypes.NodeStatusWarning{{Type: "tokens", Name: "token", Warning: "FF10551: degraded"}}, status.Warnings)
 
 	assert.True(t, or.GetNodeUUID(or.ctx).Equals(nodeID))
 	assert.True(t, or.GetNodeUUID(or.ctx).Equals(nodeID)) // cached
@@ -149,6 +160,9 @@ func TestGetStatusWrongNodeOwner(t *testing.T) {
 			Value: "0x12345",
 		}},
 	}, nil, nil)
+	or.mbp.On("PinAccess", or.ctx).Return(&fftypes.PinAccess{}, nil)
+
+	or.mti.On("ConnectorWarnings").Return(nil)
 
 	status, err := or.GetStatus(or.ctx)
 	assert.NoError(t, err)
@@ -176,9 +190,13 @@ func TestGetStatusUnregistered(t *testing.T) {
 
 	mim := or.identity.(*identitymanagermocks.Manager)
 	mim.On("GetNodeOwnerOrg", or.ctx).Return(nil, fmt.Errorf("pop"))
+	or.mbp.On("PinAccess", or.ctx).Return(nil, fmt.Errorf("pop"))
+
+	or.mti.On("ConnectorWarnings").Return(nil)
 
 	status, err := or.GetStatus(or.ctx)
 	assert.NoError(t, err)
+	assert.Nil(t, status.Org.PinAccess)
 
 	assert.Equal(t, "default", status.Defaults.Namespace)
 
@@ -218,6 +236,9 @@ func TestGetStatusOrgOnlyRegistered(t *testing.T) {
 			Value: "0x12345",
 		}},
 	}, nil, nil)
+	or.mbp.On("PinAccess", or.ctx).Return(&fftypes.PinAccess{}, nil)
+
+	or.mti.On("ConnectorWarnings").Return(nil)
 
 	status, err := or.GetStatus(or.ctx)
 	assert.NoError(t, err)
@@ -268,3 +289,86 @@ func TestGetStatusNodeError(t *testing.T) {
 	assert.Nil(t, or.GetNodeUUID(or.ctx))
 
 }
+
+func TestRegisterPinKey(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mbp.On("RegisterPinKey", or.ctx).Return(&fftypes.Operation{}, nil)
+
+	_, err := or.RegisterPinKey(or.ctx)
+	assert.NoError(t, err)
+
+	or.mbp.AssertExpectations(t)
+}
+
+func TestGetCircuitStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	circuit.Reset()
+	defer circuit.Reset()
+
+	circuit.NewBreaker("ethconnect", 5, time.Second)
+
+	statuses := or.GetCircuitStatus(or.ctx)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "ethconnect", statuses[0].Name)
+}
+
+func TestGetReadinessReady(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{}, nil, nil)
+	or.mbi.On("Health", mock.Anything).Return(nil)
+	or.mdx.On("Health", mock.Anything).Return(nil)
+	or.mti.On("Health", mock.Anything).Return(nil)
+
+	readiness := or.GetReadiness(or.ctx)
+	assert.Equal(t, fftypes.ReadinessStatusReady, readiness.Status)
+	assert.Len(t, readiness.Plugins, 4)
+	assert.Equal(t, "database", readiness.Plugins[0].Type)
+	assert.True(t, readiness.Plugins[0].Required)
+	assert.Equal(t, "tokens", readiness.Plugins[3].Type)
+	assert.Equal(t, "token", readiness.Plugins[3].Name)
+	assert.False(t, readiness.Plugins[3].Required)
+}
+
+func TestGetReadinessDegraded(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{}, nil, nil)
+	or.mbi.On("Health", mock.Anything).Return(nil)
+	or.mdx.On("Health", mock.Anything).Return(nil)
+	or.mti.On("Health", mock.Anything).Return(fmt.Errorf("pop"))
+
+	readiness := or.GetReadiness(or.ctx)
+	assert.Equal(t, fftypes.ReadinessStatusDegraded, readiness.Status)
+	assert.False(t, readiness.Plugins[3].Healthy)
+	assert.Equal(t, "pop", readiness.Plugins[3].Error)
+}
+
+func TestGetReadinessStandby(t *testing.T) {
+	or := newTestOrchestrator()
+	or.standby = true
+	or.mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{}, nil, nil)
+	or.mbi.On("Health", mock.Anything).Return(nil)
+	or.mdx.On("Health", mock.Anything).Return(nil)
+	or.mti.On("Health", mock.Anything).Return(fmt.Errorf("pop"))
+
+	readiness := or.GetReadiness(or.ctx)
+	assert.Equal(t, fftypes.ReadinessStatusStandby, readiness.Status)
+}
+
+func TestGetReadinessNotReady(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	or.mbi.On("Health", mock.Anything).Return(nil)
+	or.mdx.On("Health", mock.Anything).Return(nil)
+	or.mti.On("Health", mock.Anything).Return(fmt.Errorf("pop"))
+
+	readiness := or.GetReadiness(or.ctx)
+	assert.Equal(t, fftypes.ReadinessStatusNotReady, readiness.Status)
+	assert.False(t, readiness.Plugins[0].Healthy)
+}
+
+func TestGetLiveness(t *testing.T) {
+	or := newTestOrchestrator()
+	assert.True(t, or.GetLiveness(or.ctx).Live)
+	or.cancelCtx()
+	assert.False(t, or.GetLiveness(or.ctx).Live)
+}
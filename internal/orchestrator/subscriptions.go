@@ -18,19 +18,29 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 func (or *orchestrator) CreateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error) {
-	return or.createUpdateSubscription(ctx, ns, subDef, true)
+	sub, err := or.createUpdateSubscription(ctx, ns, subDef, true)
+	if err != nil {
+		return nil, err
+	}
+	return or.redactSubscription(ctx, sub)
 }
 
 func (or *orchestrator) CreateUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error) {
-	return or.createUpdateSubscription(ctx, ns, subDef, false)
+	sub, err := or.createUpdateSubscription(ctx, ns, subDef, false)
+	if err != nil {
+		return nil, err
+	}
+	return or.redactSubscription(ctx, sub)
 }
 
 func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription, mustNew bool) (*fftypes.Subscription, error) {
@@ -44,6 +54,9 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string,
 	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, subDef.Name, "name"); err != nil {
 		return nil, err
 	}
+	if err := subDef.Annotations.Validate(ctx); err != nil {
+		return nil, err
+	}
 	if subDef.Transport == system.SystemEventsTransport {
 		return nil, i18n.NewError(ctx, i18n.MsgSystemTransportInternal)
 	}
@@ -51,6 +64,26 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string,
 	return subDef, or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
 }
 
+// redactSubscription returns a copy of the subscription with any secret transport options, such as webhook
+// signing secrets, removed - so they are not returned through the API
+func (or *orchestrator) redactSubscription(ctx context.Context, sub *fftypes.Subscription) (*fftypes.Subscription, error) {
+	redactor, ok := or.events.Transports()[sub.Transport].(events.OptionsRedactor)
+	if !ok {
+		return sub, nil
+	}
+	redacted := *sub
+	redacted.Options = fftypes.SubscriptionOptions{}
+	b, err := json.Marshal(&sub.Options)
+	if err == nil {
+		err = json.Unmarshal(b, &redacted.Options)
+	}
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
+	}
+	redactor.RedactOptions(redacted.Options.TransportOptions())
+	return &redacted, nil
+}
+
 func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) error {
 	u, err := fftypes.ParseUUID(ctx, id)
 	if err != nil {
@@ -68,13 +101,53 @@ func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) e
 
 func (or *orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
-	return or.database.GetSubscriptions(ctx, filter)
+	subs, fr, err := or.database.GetSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, sub := range subs {
+		if subs[i], err = or.redactSubscription(ctx, sub); err != nil {
+			return nil, nil, err
+		}
+	}
+	return subs, fr, nil
 }
 
-func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string) (*fftypes.Subscription, error) {
+func (or *orchestrator) getSubscriptionByID(ctx context.Context, ns, id string) (*fftypes.Subscription, error) {
 	u, err := or.verifyIDAndNamespace(ctx, ns, id)
 	if err != nil {
 		return nil, err
 	}
 	return or.database.GetSubscriptionByID(ctx, u)
 }
+
+func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string) (*fftypes.Subscription, error) {
+	sub, err := or.getSubscriptionByID(ctx, ns, id)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return or.redactSubscription(ctx, sub)
+}
+
+func (or *orchestrator) GetSubscriptionStatus(ctx context.Context, ns, id string) (*fftypes.SubscriptionStatus, error) {
+	sub, err := or.getSubscriptionByID(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return or.events.GetSubscriptionStatus(ctx, sub)
+}
+
+func (or *orchestrator) GetSubscriptionRedeliveries(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.SubscriptionRedelivery, *database.FilterResult, error) {
+	sub, err := or.getSubscriptionByID(ctx, ns, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	filter = filter.Condition(filter.Builder().Eq("subscription", sub.ID))
+	return or.database.GetSubscriptionRedeliveries(ctx, or.scopeNS(ns, filter))
+}
@@ -18,11 +18,14 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/events/webhooks"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -50,6 +53,20 @@ func TestCreateSubscriptionBadName(t *testing.T) {
 	assert.Regexp(t, "FF10131", err)
 }
 
+func TestCreateSubscriptionBadAnnotations(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.CreateSubscription(or.ctx, "ns1", &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "sub1",
+		},
+		Annotations: fftypes.Annotations{
+			"!owner": "team-a",
+		},
+	})
+	assert.Regexp(t, "FF10131.*annotations", err)
+}
+
 func TestCreateSubscriptionSystemTransport(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
@@ -71,6 +88,7 @@ func TestCreateSubscriptionOk(t *testing.T) {
 	}
 	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
 	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{})
 	s1, err := or.CreateSubscription(or.ctx, "ns1", sub)
 	assert.NoError(t, err)
 	assert.Equal(t, s1, sub)
@@ -86,11 +104,65 @@ func TestCreateUpdateSubscriptionOk(t *testing.T) {
 	}
 	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
 	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, false).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{})
 	s1, err := or.CreateUpdateSubscription(or.ctx, "ns1", sub)
 	assert.NoError(t, err)
 	assert.Equal(t, s1, sub)
 	assert.Equal(t, "ns1", sub.Namespace)
 }
+
+func TestCreateUpdateSubscriptionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "sub1",
+		},
+	}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, false).Return(fmt.Errorf("pop"))
+	_, err := or.CreateUpdateSubscription(or.ctx, "ns1", sub)
+	assert.EqualError(t, err, "pop")
+}
+
+func newTestSigningSubscription(t *testing.T) *fftypes.Subscription {
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+		Transport: "webhooks",
+	}
+	err := json.Unmarshal([]byte(`{
+		"url": "http://example.com",
+		"signing": {
+			"secret": "secret1",
+			"previousSecrets": ["secret0"],
+			"header": "X-Signature"
+		}
+	}`), &sub.Options)
+	assert.NoError(t, err)
+	return sub
+}
+
+func TestCreateSubscriptionRedactsSigningSecrets(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestSigningSubscription(t)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.MatchedBy(func(sub *fftypes.Subscription) bool {
+		return sub.Options.TransportOptions().GetObject("signing").GetString("secret") == "secret1"
+	}), true).Return(nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{"webhooks": &webhooks.WebHooks{}})
+
+	s1, err := or.CreateSubscription(or.ctx, "ns1", sub)
+	assert.NoError(t, err)
+	b, err := json.Marshal(s1)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret1")
+	assert.NotContains(t, string(b), "secret0")
+	assert.Equal(t, "X-Signature", s1.Options.TransportOptions().GetObject("signing").GetString("header"))
+	or.mem.AssertExpectations(t)
+}
 func TestDeleteSubscriptionBadUUID(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
@@ -144,6 +216,43 @@ func TestGetSubscriptions(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetSubscriptionsRedactsSigningSecrets(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestSigningSubscription(t)
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{"webhooks": &webhooks.WebHooks{}})
+	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
+	subs, _, err := or.GetSubscriptions(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, subs, 1)
+	b, err := json.Marshal(subs)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret1")
+	assert.NotContains(t, string(b), "secret0")
+	assert.Equal(t, "http://example.com", subs[0].Options.TransportOptions().GetString("url"))
+	// The stored subscription is not modified
+	assert.Equal(t, "secret1", sub.Options.TransportOptions().GetObject("signing").GetString("secret"))
+}
+
+func TestGetSubscriptionsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptions(context.Background(), "ns1", fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSubscriptionsRedactFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestSigningSubscription(t)
+	sub.Options.TransportOptions()["bad"] = map[bool]bool{true: false}
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{"webhooks": &webhooks.WebHooks{}})
+	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptions(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF10137", err)
+}
+
 func TestGetSGetSubscriptionsByID(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
@@ -152,8 +261,97 @@ func TestGetSGetSubscriptionsByID(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetSubscriptionByIDRedactsSigningSecrets(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestSigningSubscription(t)
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("Transports").Return(map[string]events.Plugin{"webhooks": &webhooks.WebHooks{}})
+	s1, err := or.GetSubscriptionByID(context.Background(), "ns1", sub.ID.String())
+	assert.NoError(t, err)
+	b, err := json.Marshal(s1)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret1")
+}
+
 func TestGetSubscriptionDefsByIDBadID(t *testing.T) {
 	or := newTestOrchestrator()
 	_, err := or.GetSubscriptionByID(context.Background(), "", "")
 	assert.Regexp(t, "FF10142", err)
 }
+
+func TestGetSubscriptionStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("GetSubscriptionStatus", mock.Anything, sub).Return(&fftypes.SubscriptionStatus{Lag: 10}, nil)
+	status, err := or.GetSubscriptionStatus(or.ctx, "ns1", sub.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), status.Lag)
+}
+
+func TestGetSubscriptionStatusBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetSubscriptionStatus(or.ctx, "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetSubscriptionStatusNSMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	_, err := or.GetSubscriptionStatus(or.ctx, "ns2", sub.ID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetSubscriptionRedeliveries(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{
+		{Subscription: sub.ID, Attempts: 3, Parked: true},
+	}, nil, nil)
+	fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background())
+	redeliveries, _, err := or.GetSubscriptionRedeliveries(or.ctx, "ns1", sub.ID.String(), fb.And(fb.Eq("parked", true)))
+	assert.NoError(t, err)
+	assert.Len(t, redeliveries, 1)
+}
+
+func TestGetSubscriptionRedeliveriesBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptionRedeliveries(or.ctx, "ns1", "bad", fb.And())
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetSubscriptionRedeliveriesNSMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptionRedeliveries(or.ctx, "ns2", sub.ID.String(), fb.And())
+	assert.Regexp(t, "FF10109", err)
+}
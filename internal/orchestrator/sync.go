@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func syncChangeIDs(refs []*fftypes.SyncChangeRef, changeType fftypes.SyncChangeType) []driver.Value {
+	ids := make([]driver.Value, 0)
+	for _, ref := range refs {
+		if ref.Type == changeType {
+			ids = append(ids, *ref.Reference)
+		}
+	}
+	return ids
+}
+
+// GetChanges returns the operations, transactions and events that have changed after the supplied sequence of
+// the change log, in the order the changes were committed. Each change embeds the latest state of the object,
+// so an object that changed more than once in the page has the same state in each of its changes. A change to
+// an object that has since been deleted is skipped, but the sequence still moves past it.
+func (or *orchestrator) GetChanges(ctx context.Context, ns string, since int64, limit uint64) (*fftypes.SyncChanges, error) {
+	fb := database.SyncChangeQueryFactory.NewFilter(ctx)
+	refs, _, err := or.database.GetSyncChanges(ctx, fb.And(
+		fb.Eq("namespace", ns),
+		fb.Gt("sequence", since),
+	).Sort("sequence").Ascending().Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make(map[fftypes.UUID]*fftypes.Transaction)
+	if ids := syncChangeIDs(refs, fftypes.SyncChangeTypeTransaction); len(ids) > 0 {
+		fb := database.TransactionQueryFactory.NewFilter(ctx)
+		results, _, err := or.database.GetTransactions(ctx, fb.In("id", ids))
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range results {
+			txs[*tx.ID] = tx
+		}
+	}
+
+	ops := make(map[fftypes.UUID]*fftypes.Operation)
+	if ids := syncChangeIDs(refs, fftypes.SyncChangeTypeOperation); len(ids) > 0 {
+		fb := database.OperationQueryFactory.NewFilter(ctx)
+		results, _, err := or.database.GetOperations(ctx, fb.In("id", ids))
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range results {
+			ops[*op.ID] = op
+		}
+	}
+
+	events := make(map[fftypes.UUID]*fftypes.Event)
+	if ids := syncChangeIDs(refs, fftypes.SyncChangeTypeEvent); len(ids) > 0 {
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		results, _, err := or.database.GetEvents(ctx, fb.In("id", ids))
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range results {
+			events[*event.ID] = event
+		}
+	}
+
+	result := &fftypes.SyncChanges{
+		Changes: make([]*fftypes.SyncChange, 0, len(refs)),
+		Next:    since,
+	}
+	for _, ref := range refs {
+		result.Next = ref.Sequence
+		change := &fftypes.SyncChange{
+			Sequence: ref.Sequence,
+			Type:     ref.Type,
+			Changed:  ref.Changed,
+		}
+		switch ref.Type {
+		case fftypes.SyncChangeTypeTransaction:
+			change.Transaction = txs[*ref.Reference]
+		case fftypes.SyncChangeTypeOperation:
+			change.Operation = ops[*ref.Reference]
+		case fftypes.SyncChangeTypeEvent:
+			change.Event = events[*ref.Reference]
+		}
+		if change.Transaction == nil && change.Operation == nil && change.Event == nil {
+			continue
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testSyncRef(seq int64, changeType fftypes.SyncChangeType, id *fftypes.UUID) *fftypes.SyncChangeRef {
+	return &fftypes.SyncChangeRef{Sequence: seq, Namespace: "ns1", Type: changeType, Reference: id, Changed: fftypes.Now()}
+}
+
+func TestGetChangesInSequence(t *testing.T) {
+	or := newTestOrchestrator()
+	tx := &fftypes.Transaction{ID: fftypes.NewUUID()}
+	op := &fftypes.Operation{ID: fftypes.NewUUID()}
+	event := &fftypes.Event{ID: fftypes.NewUUID()}
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( namespace == 'ns1' ) && ( sequence >> 10 ) sort=sequence limit=5"
+	})).Return([]*fftypes.SyncChangeRef{
+		testSyncRef(11, fftypes.SyncChangeTypeTransaction, tx.ID),
+		testSyncRef(12, fftypes.SyncChangeTypeOperation, op.ID),
+		testSyncRef(14, fftypes.SyncChangeTypeEvent, event.ID),
+		testSyncRef(15, fftypes.SyncChangeTypeOperation, op.ID),
+		testSyncRef(16, fftypes.SyncChangeTypeEvent, fftypes.NewUUID()), // deleted since
+	}, nil, nil)
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return([]*fftypes.Transaction{tx}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{event}, nil, nil)
+
+	changes, err := or.GetChanges(context.Background(), "ns1", 10, 5)
+	assert.NoError(t, err)
+	assert.Len(t, changes.Changes, 4)
+	assert.Equal(t, int64(11), changes.Changes[0].Sequence)
+	assert.Equal(t, tx, changes.Changes[0].Transaction)
+	assert.Equal(t, op, changes.Changes[1].Operation)
+	assert.Equal(t, event, changes.Changes[2].Event)
+	assert.Equal(t, op, changes.Changes[3].Operation)
+	assert.Equal(t, int64(16), changes.Next)
+}
+
+func TestGetChangesNoChanges(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.Anything).Return([]*fftypes.SyncChangeRef{}, nil, nil)
+
+	changes, err := or.GetChanges(context.Background(), "ns1", 10, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, changes.Changes)
+	assert.Equal(t, int64(10), changes.Next)
+}
+
+func TestGetChangesSyncChangesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetChangesTransactionsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.Anything).Return([]*fftypes.SyncChangeRef{
+		testSyncRef(1, fftypes.SyncChangeTypeTransaction, fftypes.NewUUID()),
+	}, nil, nil)
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetChangesOperationsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.Anything).Return([]*fftypes.SyncChangeRef{
+		testSyncRef(1, fftypes.SyncChangeTypeOperation, fftypes.NewUUID()),
+	}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetChangesEventsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSyncChanges", mock.Anything, mock.Anything).Return([]*fftypes.SyncChangeRef{
+		testSyncRef(1, fftypes.SyncChangeTypeEvent, fftypes.NewUUID()),
+	}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 10)
+	assert.EqualError(t, err, "pop")
+}
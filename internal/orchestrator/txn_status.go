@@ -25,9 +25,9 @@ import (
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func updateStatus(result *fftypes.TransactionStatus, newStatus fftypes.OpStatus) {
-	if result.Status != fftypes.OpStatusFailed && newStatus != fftypes.OpStatusSucceeded {
-		result.Status = newStatus
+func updateStatus(status *fftypes.OpStatus, newStatus fftypes.OpStatus) {
+	if *status != fftypes.OpStatusFailed && newStatus != fftypes.OpStatusSucceeded {
+		*status = newStatus
 	}
 }
 
@@ -62,17 +62,20 @@ func txBlockchainEventStatus(event *fftypes.BlockchainEvent) *fftypes.Transactio
 }
 
 func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string) (*fftypes.TransactionStatus, error) {
-	result := &fftypes.TransactionStatus{
-		Status:  fftypes.OpStatusSucceeded,
-		Details: make([]*fftypes.TransactionStatusDetails, 0),
-	}
-
 	tx, err := or.GetTransactionByID(ctx, ns, id)
 	if err != nil {
 		return nil, err
 	} else if tx == nil {
 		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
 	}
+	return or.getTransactionStatus(ctx, ns, id, tx)
+}
+
+func (or *orchestrator) getTransactionStatus(ctx context.Context, ns, id string, tx *fftypes.Transaction) (*fftypes.TransactionStatus, error) {
+	result := &fftypes.TransactionStatus{
+		Status:  fftypes.OpStatusSucceeded,
+		Details: make([]*fftypes.TransactionStatusDetails, 0),
+	}
 
 	ops, _, err := or.GetTransactionOperations(ctx, ns, id)
 	if err != nil {
@@ -81,7 +84,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 	for _, op := range ops {
 		result.Details = append(result.Details, txOperationStatus(op))
 		if op.Retry == nil {
-			updateStatus(result, op.Status)
+			updateStatus(&result.Status, op.Status)
 		}
 	}
 
@@ -94,10 +97,11 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 	}
 
 	switch tx.Type {
-	case fftypes.TransactionTypeBatchPin:
-		if len(events) == 0 {
+	case fftypes.TransactionTypeBatchPin, fftypes.TransactionTypeUnpinned:
+		// Unpinned batches are delivered directly over data exchange, so there is no blockchain event
+		if tx.Type == fftypes.TransactionTypeBatchPin && len(events) == 0 {
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeBlockchainEvent))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		}
 		f := database.BatchQueryFactory.NewFilter(ctx)
 		switch batches, _, err := or.database.GetBatches(ctx, f.Eq("tx.id", id)); {
@@ -105,7 +109,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			return nil, err
 		case len(batches) == 0:
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeBatch))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		default:
 			result.Details = append(result.Details, &fftypes.TransactionStatusDetails{
 				Status:    fftypes.OpStatusSucceeded,
@@ -119,7 +123,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 	case fftypes.TransactionTypeTokenPool:
 		if len(events) == 0 {
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeBlockchainEvent))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		}
 		f := database.TokenPoolQueryFactory.NewFilter(ctx)
 		switch pools, _, err := or.database.GetTokenPools(ctx, f.Eq("tx.id", id)); {
@@ -127,7 +131,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			return nil, err
 		case len(pools) == 0:
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeTokenPool))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		case pools[0].State != fftypes.TokenPoolStateConfirmed:
 			result.Details = append(result.Details, &fftypes.TransactionStatusDetails{
 				Status:  fftypes.OpStatusPending,
@@ -148,7 +152,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 	case fftypes.TransactionTypeTokenTransfer:
 		if len(events) == 0 {
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeBlockchainEvent))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		}
 		f := database.TokenTransferQueryFactory.NewFilter(ctx)
 		switch transfers, _, err := or.database.GetTokenTransfers(ctx, f.Eq("tx.id", id)); {
@@ -156,7 +160,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			return nil, err
 		case len(transfers) == 0:
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeTokenTransfer))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		default:
 			result.Details = append(result.Details, &fftypes.TransactionStatusDetails{
 				Status:    fftypes.OpStatusSucceeded,
@@ -170,7 +174,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 	case fftypes.TransactionTypeTokenApproval:
 		if len(events) == 0 {
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeBlockchainEvent))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		}
 		f := database.TokenApprovalQueryFacory.NewFilter(ctx)
 		switch approvals, _, err := or.database.GetTokenApprovals(ctx, f.Eq("tx.id", id)); {
@@ -178,7 +182,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			return nil, err
 		case len(approvals) == 0:
 			result.Details = append(result.Details, pendingPlaceholder(fftypes.TransactionStatusTypeTokenApproval))
-			updateStatus(result, fftypes.OpStatusPending)
+			updateStatus(&result.Status, fftypes.OpStatusPending)
 		default:
 			result.Details = append(result.Details, &fftypes.TransactionStatusDetails{
 				Status:    fftypes.OpStatusSucceeded,
@@ -188,7 +192,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			})
 		}
 
-	case fftypes.TransactionTypeContractInvoke:
+	case fftypes.TransactionTypeContractInvoke, fftypes.TransactionTypeMessageImport, fftypes.TransactionTypeDataExport:
 		// no blockchain events or other objects
 
 	default:
@@ -216,6 +216,49 @@ func TestGetTransactionStatusBatchPinPending(t *testing.T) {
 	or.mdi.AssertExpectations(t)
 }
 
+func TestGetTransactionStatusUnpinnedSuccess(t *testing.T) {
+	or := newTestOrchestrator()
+
+	txID := fftypes.NewUUID()
+	tx := &fftypes.Transaction{
+		Type: fftypes.TransactionTypeUnpinned,
+	}
+	batches := []*fftypes.BatchPersisted{
+		{
+			BatchHeader: fftypes.BatchHeader{
+				ID:   fftypes.NewUUID(),
+				Type: fftypes.BatchTypePrivate,
+			},
+			Confirmed: fftypes.UnixTime(2),
+		},
+	}
+
+	or.mdi.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	or.mdi.On("GetBatches", mock.Anything, mock.Anything).Return(batches, nil, nil)
+
+	status, err := or.GetTransactionStatus(context.Background(), "ns1", txID.String())
+	assert.NoError(t, err)
+
+	expectedStatus := compactJSON(`{
+		"status": "Succeeded",
+		"details": [
+			{
+				"type": "Batch",
+				"subtype": "private",
+				"status": "Succeeded",
+				"timestamp": "1970-01-01T00:00:02Z",
+				"id": "` + batches[0].ID.String() + `"
+			}
+		]
+	}`)
+	statusJSON, _ := json.Marshal(status)
+	assert.Equal(t, expectedStatus, string(statusJSON))
+
+	or.mdi.AssertExpectations(t)
+}
+
 func TestGetTransactionStatusTokenPoolSuccess(t *testing.T) {
 	or := newTestOrchestrator()
 
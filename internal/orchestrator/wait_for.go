@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const defaultWaitForTimeout = 5 * time.Second
+
+// The states a message can be waited for are the final ones, as they are reached along with an event
+var waitForMessageStates = []fftypes.MessageState{
+	fftypes.MessageStateConfirmed,
+	fftypes.MessageStateRejected,
+}
+
+// WaitForMessageState blocks until the message reaches the requested state, giving simple clients read-your-writes
+// consistency on a query immediately after a submission. The message is re-checked each time new events are committed.
+// If the message reaches a different final state, waiting stops so the caller can see that state.
+func (or *orchestrator) WaitForMessageState(ctx context.Context, ns, id, waitFor, timeout string) error {
+	var state fftypes.MessageState
+	for _, s := range waitForMessageStates {
+		if strings.EqualFold(waitFor, string(s)) {
+			state = s
+		}
+	}
+	if state == "" {
+		return i18n.NewError(ctx, i18n.MsgInvalidWaitFor, waitFor, waitForMessageStates)
+	}
+
+	waitTimeout := defaultWaitForTimeout
+	if timeout != "" {
+		d, err := fftypes.ParseDurationString(timeout, time.Second /* default is seconds */)
+		if err != nil {
+			return err
+		}
+		waitTimeout = time.Duration(d)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+	err := or.events.WaitForCondition(waitCtx, func() (bool, error) {
+		msg, err := or.getMessageByID(waitCtx, ns, id)
+		if err != nil {
+			return false, err
+		}
+		return msg.State == fftypes.MessageStateConfirmed || msg.State == fftypes.MessageStateRejected, nil
+	})
+	if err != nil && waitCtx.Err() != nil {
+		return i18n.NewError(ctx, i18n.MsgWaitForTimeout, waitTimeout, id, state)
+	}
+	return err
+}
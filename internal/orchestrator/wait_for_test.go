@@ -0,0 +1,106 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockWaitForCondition(or *testOrchestrator, checks int) {
+	or.mem.On("WaitForCondition", mock.Anything, mock.Anything).Return(func(ctx context.Context, check func() (bool, error)) error {
+		for i := 0; i < checks; i++ {
+			done, err := check()
+			if err != nil || done {
+				return err
+			}
+		}
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	})
+}
+
+func TestWaitForMessageStateConfirmed(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	mockWaitForCondition(or, 2)
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "ns1"},
+		State:  fftypes.MessageStateSent,
+	}, nil).Once()
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "ns1"},
+		State:  fftypes.MessageStateConfirmed,
+	}, nil).Once()
+
+	err := or.WaitForMessageState(or.ctx, "ns1", msgID.String(), "Confirmed", "")
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestWaitForMessageStateOtherFinalState(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	mockWaitForCondition(or, 1)
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "ns1"},
+		State:  fftypes.MessageStateRejected,
+	}, nil)
+
+	err := or.WaitForMessageState(or.ctx, "ns1", msgID.String(), "confirmed", "1s")
+	assert.NoError(t, err)
+}
+
+func TestWaitForMessageStateTimeout(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	or.mem.On("WaitForCondition", mock.Anything, mock.Anything).Return(func(ctx context.Context, check func() (bool, error)) error {
+		<-ctx.Done()
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	})
+
+	err := or.WaitForMessageState(or.ctx, "ns1", msgID.String(), "confirmed", "1ms")
+	assert.Regexp(t, "FF10423.*1ms.*confirmed", err)
+}
+
+func TestWaitForMessageStateLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	mockWaitForCondition(or, 1)
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(nil, fmt.Errorf("pop"))
+
+	err := or.WaitForMessageState(or.ctx, "ns1", msgID.String(), "confirmed", "")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestWaitForMessageStateBadWaitFor(t *testing.T) {
+	or := newTestOrchestrator()
+	err := or.WaitForMessageState(or.ctx, "ns1", fftypes.NewUUID().String(), "sent", "")
+	assert.Regexp(t, "FF10422", err)
+}
+
+func TestWaitForMessageStateBadTimeout(t *testing.T) {
+	or := newTestOrchestrator()
+	err := or.WaitForMessageState(or.ctx, "ns1", fftypes.NewUUID().String(), "rejected", "forever")
+	assert.Regexp(t, "FF10167", err)
+}
@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// LoadDeliveryAckSigningKey reads the key used to sign the delivery acknowledgements sent by this node, which
+// must be configured when delivery acknowledgements are enabled. Returns nil when they are disabled.
+func LoadDeliveryAckSigningKey(ctx context.Context) (ed25519.PrivateKey, error) {
+	if !config.GetBool(config.PrivateMessagingDeliveryAcksEnabled) {
+		return nil, nil
+	}
+	keyFile := config.GetString(config.PrivateMessagingDeliveryAcksSigningKeyFile)
+	if keyFile == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgDeliveryAckSigningKeyMissing)
+	}
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDeliveryAckSigningKeyInvalid, keyFile, err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDeliveryAckSigningKeyInvalid, keyFile, "no PEM data")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDeliveryAckSigningKeyInvalid, keyFile, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgDeliveryAckSigningKeyInvalid, keyFile, fmt.Sprintf("%T is not an ed25519 key", key))
+	}
+	return edKey, nil
+}
+
+// DeliveryAckPublicKey is the base64 encoding of the public key for a delivery acknowledgement signing key,
+// as advertised in the profile of the node
+func DeliveryAckPublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// trackRecipients records each message in a batch as pending delivery to the node, until it acknowledges processing it
+func (pm *privateMessaging) trackRecipients(ctx context.Context, batch *fftypes.Batch, node *fftypes.Identity) error {
+	if !pm.deliveryAcks {
+		return nil
+	}
+	for _, msg := range batch.Payload.Messages {
+		if err := pm.database.UpsertMessageRecipient(ctx, &fftypes.MessageRecipient{
+			Namespace: batch.Namespace,
+			Message:   msg.Header.ID,
+			Node:      node.ID,
+			Status:    fftypes.MessageRecipientStatusPending,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDeliveryAck queues an acknowledgement back to the node that sent a private message, once it
+// has been processed by this node. Messages sent by this node are not acknowledged.
+// This is called within the database transaction of the aggregator, so the send is performed by the
+// operations outbox once that transaction commits - with retry, and signed in RunOperation.
+func (pm *privateMessaging) SendDeliveryAck(ctx context.Context, batchID *fftypes.UUID, msg *fftypes.Message, state fftypes.MessageState) error {
+	l := log.L(ctx)
+
+	batch, err := pm.database.GetBatchByID(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if batch == nil || batch.Node == nil {
+		l.Warnf("Unable to acknowledge message %s - sending node of batch %s is unknown", msg.Header.ID, batchID)
+		return nil
+	}
+
+	node, err := pm.database.GetIdentityByID(ctx, batch.Node)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		l.Warnf("Unable to acknowledge message %s - node %s not found", msg.Header.ID, batch.Node)
+		return nil
+	}
+
+	localOrg, err := pm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return err
+	}
+	if node.Parent.Equals(localOrg.ID) {
+		return nil
+	}
+
+	ack := &fftypes.DeliveryAck{
+		Namespace: msg.Header.Namespace,
+		Message:   msg.Header.ID,
+		State:     state,
+	}
+	op := fftypes.NewOperation(
+		pm.exchange,
+		msg.Header.Namespace,
+		batch.TX.ID,
+		fftypes.OpTypeDataExchangeSendAck)
+	addAckSendInputs(op, node.ID, ack)
+	l.Debugf("Acknowledging message %s as %s to node %s in operation %s", msg.Header.ID, state, node.ID, op.ID)
+	if err = pm.operations.AddOrReuseOperation(ctx, op); err != nil {
+		return err
+	}
+	return pm.operations.QueueOperation(ctx, op)
+}
@@ -0,0 +1,330 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
+	"github.com/hyperledger/firefly/mocks/batchpinmocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func writeTestAckSigningKey(t *testing.T) (string, ed25519.PrivateKey) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	b, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	keyFile := path.Join(t.TempDir(), "ack.key")
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}), 0600)
+	assert.NoError(t, err)
+	return keyFile, priv
+}
+
+func newTestAckMessage() (*fftypes.BatchPersisted, *fftypes.Identity, *fftypes.Message) {
+	node := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:     fftypes.NewUUID(),
+			Parent: fftypes.NewUUID(),
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{"id": "peer1"},
+		},
+	}
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:   fftypes.NewUUID(),
+			Node: node.ID,
+		},
+		TX: fftypes.TransactionRef{ID: fftypes.NewUUID()},
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+	}
+	return batch, node, msg
+}
+
+func TestSendDeliveryAckOk(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mom := pm.operations.(*operationmocks.Manager)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}, nil)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeDataExchangeSendAck && op.Transaction.Equals(batch.TX.ID) &&
+			op.Input.GetString("message") == msg.Header.ID.String()
+	})).Return(nil)
+	mom.On("QueueOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeDataExchangeSendAck && op.Input.GetString("node") == node.ID.String() &&
+			op.Input.GetString("state") == string(fftypes.MessageStateConfirmed)
+	})).Return(nil)
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestSendDeliveryAckLocalNode(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: node.Parent}}, nil)
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestSendDeliveryAckGetBatchFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, _, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendDeliveryAckBatchNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, _, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(nil, nil)
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.NoError(t, err)
+}
+
+func TestSendDeliveryAckGetNodeFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendDeliveryAckNodeNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(nil, nil)
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.NoError(t, err)
+}
+
+func TestSendDeliveryAckGetOrgFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendDeliveryAckAddOpFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mom := pm.operations.(*operationmocks.Manager)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}, nil)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendDeliveryAckQueueOpFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	batch, node, msg := newTestAckMessage()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mom := pm.operations.(*operationmocks.Manager)
+	mdi.On("GetBatchByID", pm.ctx, batch.ID).Return(batch, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}, nil)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(nil)
+	mom.On("QueueOperation", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := pm.SendDeliveryAck(pm.ctx, batch.ID, msg, fftypes.MessageStateConfirmed)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestLoadDeliveryAckSigningKey(t *testing.T) {
+	config.Reset()
+	key, err := LoadDeliveryAckSigningKey(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+
+	keyFile, priv := writeTestAckSigningKey(t)
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+	config.Set(config.PrivateMessagingDeliveryAcksSigningKeyFile, keyFile)
+	key, err = LoadDeliveryAckSigningKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, priv, key)
+	assert.NotEmpty(t, DeliveryAckPublicKey(key))
+}
+
+func TestLoadDeliveryAckSigningKeyErrors(t *testing.T) {
+	config.Reset()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+	_, err := LoadDeliveryAckSigningKey(context.Background())
+	assert.Regexp(t, "FF10558", err)
+
+	dir := t.TempDir()
+	config.Set(config.PrivateMessagingDeliveryAcksSigningKeyFile, path.Join(dir, "missing"))
+	_, err = LoadDeliveryAckSigningKey(context.Background())
+	assert.Regexp(t, "FF10559", err)
+
+	notPEM := path.Join(dir, "notpem")
+	ioutil.WriteFile(notPEM, []byte("not a key"), 0600)
+	config.Set(config.PrivateMessagingDeliveryAcksSigningKeyFile, notPEM)
+	_, err = LoadDeliveryAckSigningKey(context.Background())
+	assert.Regexp(t, "FF10559.*no PEM data", err)
+
+	badKey := path.Join(dir, "badkey")
+	ioutil.WriteFile(badKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("bad")}), 0600)
+	config.Set(config.PrivateMessagingDeliveryAcksSigningKeyFile, badKey)
+	_, err = LoadDeliveryAckSigningKey(context.Background())
+	assert.Regexp(t, "FF10559", err)
+}
+
+func TestNewPrivateMessagingAckKeyFail(t *testing.T) {
+	config.Reset()
+	config.Set(config.PrivateMessagingDeliveryAcksEnabled, true)
+	mom := &operationmocks.Manager{}
+	_, err := NewPrivateMessaging(context.Background(), &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, &dataexchangemocks.Plugin{},
+		&blockchainmocks.Plugin{}, &batchmocks.Manager{}, &datamocks.Manager{}, &syncasyncmocks.Bridge{}, &batchpinmocks.Submitter{}, &metricsmocks.Manager{}, mom)
+	assert.Regexp(t, "FF10558", err)
+}
+
+func TestTrackRecipients(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.deliveryAcks = true
+
+	node := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{Namespace: "ns1"},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+			},
+		},
+	}
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertMessageRecipient", pm.ctx, mock.MatchedBy(func(r *fftypes.MessageRecipient) bool {
+		return r.Namespace == "ns1" && r.Node.Equals(node.ID) && r.Status == fftypes.MessageRecipientStatusPending
+	})).Return(nil).Twice()
+
+	err := pm.trackRecipients(pm.ctx, batch, node)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTrackRecipientsFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.deliveryAcks = true
+
+	batch := &fftypes.Batch{
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+			},
+		},
+	}
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertMessageRecipient", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := pm.trackRecipients(pm.ctx, batch, &fftypes.Identity{})
+	assert.EqualError(t, err, "pop")
+}
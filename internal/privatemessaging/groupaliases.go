@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (pm *privateMessaging) CreateGroupAlias(ctx context.Context, ns string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error) {
+	return pm.createUpdateGroupAlias(ctx, ns, input, true)
+}
+
+func (pm *privateMessaging) UpdateGroupAlias(ctx context.Context, ns, name string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error) {
+	input.Name = name
+	return pm.createUpdateGroupAlias(ctx, ns, input, false)
+}
+
+func (pm *privateMessaging) createUpdateGroupAlias(ctx context.Context, ns string, input *fftypes.GroupAliasInput, mustNew bool) (alias *fftypes.GroupAlias, err error) {
+	if err := pm.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	// Do a check first for existence, to give a nice 409 if we find one
+	existing, err := pm.database.GetGroupAliasByName(ctx, ns, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && mustNew {
+		return nil, i18n.NewError(ctx, i18n.MsgAlreadyExists, "group alias", ns, input.Name)
+	}
+
+	alias = &fftypes.GroupAlias{
+		Namespace: ns,
+		Name:      input.Name,
+	}
+	if existing != nil {
+		alias.ID = existing.ID
+		alias.Created = existing.Created
+		alias.Updated = fftypes.Now()
+	} else {
+		alias.ID = fftypes.NewUUID()
+		alias.Created = fftypes.Now()
+	}
+
+	if input.Group == nil {
+		// Resolving a member list might need to create the group, which is signed in the same way as a message
+		if err := pm.identity.ResolveInputSigningIdentity(ctx, ns, &input.SignerRef); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+		}
+	}
+
+	err = pm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if alias.Group, err = pm.resolveGroupAliasTarget(ctx, ns, input); err != nil {
+			return err
+		}
+		return pm.database.UpsertGroupAlias(ctx, alias, !mustNew)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+func (pm *privateMessaging) resolveGroupAliasTarget(ctx context.Context, ns string, input *fftypes.GroupAliasInput) (*fftypes.Bytes32, error) {
+	if input.Group != nil {
+		group, err := pm.database.GetGroupByHash(ctx, input.Group)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil || group.Namespace != ns {
+			return nil, i18n.NewError(ctx, i18n.MsgGroupNotFound, input.Group)
+		}
+		return group.Hash, nil
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: ns,
+				SignerRef: input.SignerRef,
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Ledger:  input.Ledger,
+			Members: input.Members,
+		},
+	}
+	if err := pm.resolveRecipientList(ctx, in); err != nil {
+		return nil, err
+	}
+	return in.Header.Group, nil
+}
+
+func (pm *privateMessaging) GetGroupAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.GroupAlias, *database.FilterResult, error) {
+	return pm.database.GetGroupAliases(ctx, filter.Condition(filter.Builder().Eq("namespace", ns)))
+}
+
+func (pm *privateMessaging) GetGroupAliasByName(ctx context.Context, ns, name string) (*fftypes.GroupAlias, error) {
+	alias, err := pm.database.GetGroupAliasByName(ctx, ns, name)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgGroupAliasNotFound, name)
+	}
+	return alias, nil
+}
+
+func (pm *privateMessaging) DeleteGroupAlias(ctx context.Context, ns, name string) error {
+	alias, err := pm.GetGroupAliasByName(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	return pm.database.DeleteGroupAliasByID(ctx, alias.ID)
+}
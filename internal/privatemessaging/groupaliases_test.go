@@ -0,0 +1,302 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+}
+
+func TestCreateGroupAliasByHash(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	hash := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, hash).Return(&fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{Namespace: "ns1"},
+		Hash:          hash,
+	}, nil)
+	mdi.On("UpsertGroupAlias", pm.ctx, mock.MatchedBy(func(alias *fftypes.GroupAlias) bool {
+		return alias.Namespace == "ns1" && alias.Name == "team1" && alias.Group.Equals(hash) && alias.ID != nil
+	}), false).Return(nil)
+
+	alias, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{
+		Name:  "team1",
+		Group: hash,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hash, alias.Group)
+	assert.NotNil(t, alias.Created)
+	assert.Nil(t, alias.Updated)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestCreateGroupAliasByMembers(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+	hash := fftypes.NewRandB32()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything, mock.Anything).Return(&fftypes.Group{Hash: hash}, nil, nil)
+	mdi.On("UpsertGroupAlias", pm.ctx, mock.MatchedBy(func(alias *fftypes.GroupAlias) bool {
+		return alias.Group.Equals(hash)
+	}), false).Return(nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+	mim.On("CachedIdentityLookupMustExist", pm.ctx, "org1").Return(localOrg, false, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	alias, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{
+		Name:    "team1",
+		Members: []fftypes.MemberInput{{Identity: "org1"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hash, alias.Group)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestCreateGroupAliasBadNamespace(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(fmt.Errorf("pop"))
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateGroupAliasInvalid(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{Name: "team1"})
+	assert.Regexp(t, "FF10480", err)
+}
+
+func TestCreateGroupAliasLookupFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{Name: "team1", Group: fftypes.NewRandB32()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateGroupAliasExists(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(&fftypes.GroupAlias{}, nil)
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{Name: "team1", Group: fftypes.NewRandB32()})
+	assert.Regexp(t, "FF10193", err)
+}
+
+func TestCreateGroupAliasBadSigner(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{
+		Name:    "team1",
+		Members: []fftypes.MemberInput{{Identity: "org1"}},
+	})
+	assert.Regexp(t, "FF10206.*pop", err)
+}
+
+func TestCreateGroupAliasMembersFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.CreateGroupAlias(pm.ctx, "ns1", &fftypes.GroupAliasInput{
+		Name:    "team1",
+		Members: []fftypes.MemberInput{{Identity: "org1"}},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateGroupAlias(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	hash := fftypes.NewRandB32()
+	existing := &fftypes.GroupAlias{
+		ID:      fftypes.NewUUID(),
+		Group:   fftypes.NewRandB32(),
+		Created: fftypes.Now(),
+	}
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(existing, nil)
+	mdi.On("GetGroupByHash", pm.ctx, hash).Return(&fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{Namespace: "ns1"},
+		Hash:          hash,
+	}, nil)
+	mdi.On("UpsertGroupAlias", pm.ctx, mock.Anything, true).Return(nil)
+
+	alias, err := pm.UpdateGroupAlias(pm.ctx, "ns1", "team1", &fftypes.GroupAliasInput{Group: hash})
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, alias.ID)
+	assert.Equal(t, existing.Created, alias.Created)
+	assert.Equal(t, hash, alias.Group)
+	assert.NotNil(t, alias.Updated)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestUpdateGroupAliasGroupNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	hash := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, hash).Return(&fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{Namespace: "ns2"},
+		Hash:          hash,
+	}, nil)
+
+	_, err := pm.UpdateGroupAlias(pm.ctx, "ns1", "team1", &fftypes.GroupAliasInput{Group: hash})
+	assert.Regexp(t, "FF10226", err)
+}
+
+func TestUpdateGroupAliasGroupLookupFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	hash := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", pm.ctx, "ns1").Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, hash).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.UpdateGroupAlias(pm.ctx, "ns1", "team1", &fftypes.GroupAliasInput{Group: hash})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetGroupAliases(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliases", pm.ctx, mock.Anything).Return([]*fftypes.GroupAlias{}, nil, nil)
+
+	fb := database.GroupAliasQueryFactory.NewFilter(pm.ctx)
+	_, _, err := pm.GetGroupAliases(pm.ctx, "ns1", fb.And(fb.Eq("name", "team1")))
+	assert.NoError(t, err)
+}
+
+func TestGetGroupAliasByNameFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.GetGroupAliasByName(pm.ctx, "ns1", "team1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteGroupAlias(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	alias := &fftypes.GroupAlias{ID: fftypes.NewUUID()}
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(alias, nil)
+	mdi.On("DeleteGroupAliasByID", pm.ctx, alias.ID).Return(nil)
+
+	err := pm.DeleteGroupAlias(pm.ctx, "ns1", "team1")
+	assert.NoError(t, err)
+}
+
+func TestDeleteGroupAliasNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+
+	err := pm.DeleteGroupAlias(pm.ctx, "ns1", "team1")
+	assert.Regexp(t, "FF10481", err)
+}
@@ -41,6 +41,7 @@ type groupManager struct {
 	data          data.Manager
 	groupCacheTTL time.Duration
 	groupCache    *ccache.Cache
+	residency     *residencyPolicy
 }
 
 type groupHashEntry struct {
@@ -98,6 +99,14 @@ func (gm *groupManager) groupInit(ctx context.Context, signer *fftypes.SignerRef
 		return i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
 	}
 
+	// The group, its data and the init message are written in one database transaction, so a failure
+	// part way through cannot leave a group that is never sent to the other members
+	return gm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		return gm.writeGroupInit(ctx, signer, group, data)
+	})
+}
+
+func (gm *groupManager) writeGroupInit(ctx context.Context, signer *fftypes.SignerRef, group *fftypes.Group, data *fftypes.Data) (err error) {
 	// In the case of groups, we actually write the unconfirmed group directly to our database.
 	// So it can be used straight away.
 	// We're able to do this by making the identifier of the group a hash of the identity fields
@@ -226,6 +235,13 @@ func (gm *groupManager) ResolveInitGroup(ctx context.Context, msg *fftypes.Messa
 			log.L(ctx).Warnf("Group %s definition in message %s invalid: mismatched hash with message '%s'", msg.Header.Group, msg.Header.ID, newGroup.Hash)
 			return nil, nil
 		}
+		refused, err := gm.residency.refusedMember(ctx, newGroup.Namespace, newGroup.Members)
+		if err != nil {
+			return nil, err
+		}
+		if refused != "" {
+			return nil, gm.residency.auditRefusal(ctx, newGroup.Namespace, msg.Header.ID, refused)
+		}
 		newGroup.Message = msg.Header.ID
 		err = gm.database.UpsertGroup(ctx, &newGroup, database.UpsertOptimizationNew /* we think we're first to create this */)
 		if err != nil {
@@ -43,7 +43,7 @@ func (pm *privateMessaging) SendMessage(ctx context.Context, ns string, in *ffty
 	if pm.metrics.IsMetricsEnabled() {
 		pm.metrics.MessageSubmitted(&in.Message)
 	}
-	if waitConfirm {
+	if waitConfirm && !in.Draft {
 		err = message.SendAndWait(ctx)
 	} else {
 		err = message.Send(ctx)
@@ -58,6 +58,9 @@ func (pm *privateMessaging) RequestReply(ctx context.Context, ns string, in *fft
 	if in.Header.CID != nil {
 		return nil, i18n.NewError(ctx, i18n.MsgRequestCannotHaveCID)
 	}
+	if in.Draft {
+		return nil, i18n.NewError(ctx, i18n.MsgRequestCannotBeDraft)
+	}
 	message := pm.NewMessage(ns, in)
 	return pm.syncasync.WaitForReply(ctx, ns, in.Header.ID, message.Send)
 }
@@ -99,6 +102,10 @@ func (s *messageSender) setDefaults() {
 	msg.Header.ID = fftypes.NewUUID()
 	msg.Header.Namespace = s.namespace
 	msg.State = fftypes.MessageStateReady
+	if msg.Draft {
+		// A draft is stored along with its data, but is not picked up for batching until it is activated
+		msg.State = fftypes.MessageStateDraft
+	}
 	if msg.Header.Type == "" {
 		msg.Header.Type = fftypes.MessageTypePrivate
 	}
@@ -166,8 +173,16 @@ func (s *messageSender) sendInternal(ctx context.Context, method sendMethod) err
 		return nil
 	}
 
+	// Register for an immediate flush before the write, so the batch manager cannot see the message first
+	if s.msg.Message.FlushImmediately {
+		s.mgr.batch.FlushOnArrival(msg.Header.ID)
+	}
+
 	// Store the message - this asynchronously triggers the next step in process
 	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
+		if s.msg.Message.FlushImmediately {
+			s.mgr.batch.CancelFlushOnArrival(msg.Header.ID)
+		}
 		return err
 	}
 	log.L(ctx).Infof("Sent private message %s:%s sequence=%d", msg.Header.Namespace, msg.Header.ID, msg.Sequence)
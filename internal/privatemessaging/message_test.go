@@ -23,7 +23,9 @@ import (
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -169,6 +171,45 @@ func TestSendUnpinnedMessageE2EOk(t *testing.T) {
 
 }
 
+func TestSendMessageDraft(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", pm.ctx, mock.MatchedBy(func(newMsg *data.NewMessage) bool {
+		return newMsg.Message.State == fftypes.MessageStateDraft
+	})).Return(nil).Once()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	// A draft is not waited for, even if confirmation is requested
+	msg, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+		Draft: true,
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateDraft, msg.State)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
 func TestSendMessageBadGroup(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -453,6 +494,87 @@ func TestSendUnpinnedMessageInsertFail(t *testing.T) {
 
 }
 
+func TestSendMessageFlushImmediately(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", pm.ctx, mock.Anything).Return(nil).Once()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	mba := pm.batch.(*batchmocks.Manager)
+	mba.On("FlushOnArrival", mock.Anything).Return()
+
+	msg, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+		FlushImmediately: true,
+	}, false)
+	assert.NoError(t, err)
+	mba.AssertCalled(t, "FlushOnArrival", msg.Header.ID)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mba.AssertExpectations(t)
+
+}
+
+func TestSendMessageFlushImmediatelyInsertFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", pm.ctx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	mba := pm.batch.(*batchmocks.Manager)
+	mba.On("FlushOnArrival", mock.Anything).Return()
+	mba.On("CancelFlushOnArrival", mock.Anything).Return()
+
+	msg, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+		FlushImmediately: true,
+	}, false)
+	assert.EqualError(t, err, "pop")
+	mba.AssertCalled(t, "CancelFlushOnArrival", msg.Header.ID)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mba.AssertExpectations(t)
+
+}
+
 func TestSendUnpinnedMessageConfirmFail(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -588,6 +710,22 @@ func TestRequestReplyInvalidCID(t *testing.T) {
 	assert.Regexp(t, "FF10262", err)
 }
 
+func TestRequestReplyDraft(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	_, err := pm.RequestReply(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag:   "mytag",
+				Group: fftypes.NewRandB32(),
+			},
+		},
+		Draft: true,
+	})
+	assert.Regexp(t, "FF10540", err)
+}
+
 func TestRequestReplySuccess(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -664,7 +802,7 @@ func TestDispatchedUnpinnedMessageOK(t *testing.T) {
 	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
 		data := op.Data.(batchSendData)
 		return op.Type == fftypes.OpTypeDataExchangeSendBatch && *data.Node.ID == *node2.ID
-	})).Return(nil)
+	}), operations.RemainPendingOnFailure).Return(nil)
 
 	err := pm.dispatchUnpinnedBatch(pm.ctx, &batch.DispatchState{
 		Persisted: fftypes.BatchPersisted{
@@ -737,7 +875,7 @@ func TestSendDataTransferBlobsFail(t *testing.T) {
 				},
 			},
 		},
-	}, nodes)
+	}, nodes, nil)
 	assert.Regexp(t, "pop", err)
 
 	mdi.AssertExpectations(t)
@@ -762,7 +900,7 @@ func TestSendDataTransferFail(t *testing.T) {
 	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
 		data := op.Data.(batchSendData)
 		return op.Type == fftypes.OpTypeDataExchangeSendBatch && *data.Node.ID == *node2.ID
-	})).Return(fmt.Errorf("pop"))
+	}), operations.RemainPendingOnFailure).Return(fmt.Errorf("pop"))
 
 	err := pm.sendData(pm.ctx, &fftypes.TransportWrapper{
 		Batch: &fftypes.Batch{
@@ -784,7 +922,7 @@ func TestSendDataTransferFail(t *testing.T) {
 				},
 			},
 		},
-	}, nodes)
+	}, nodes, nil)
 	assert.Regexp(t, "pop", err)
 
 	mim.AssertExpectations(t)
@@ -832,7 +970,7 @@ func TestSendDataTransferInsertOperationFail(t *testing.T) {
 				},
 			},
 		},
-	}, nodes)
+	}, nodes, nil)
 	assert.Regexp(t, "pop", err)
 
 }
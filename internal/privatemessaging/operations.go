@@ -18,7 +18,6 @@ package privatemessaging
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -34,6 +33,11 @@ type batchSendData struct {
 	Transport *fftypes.TransportWrapper `json:"transport"`
 }
 
+type ackSendData struct {
+	Node *fftypes.Identity    `json:"node"`
+	Ack  *fftypes.DeliveryAck `json:"ack"`
+}
+
 func addTransferBlobInputs(op *fftypes.Operation, nodeID *fftypes.UUID, blobHash *fftypes.Bytes32) {
 	op.Input = fftypes.JSONObject{
 		"node": nodeID.String(),
@@ -68,6 +72,26 @@ func retrieveBatchSendInputs(ctx context.Context, op *fftypes.Operation) (nodeID
 	return nodeID, groupHash, batchID, err
 }
 
+func addAckSendInputs(op *fftypes.Operation, nodeID *fftypes.UUID, ack *fftypes.DeliveryAck) {
+	op.Input = fftypes.JSONObject{
+		"node":    nodeID.String(),
+		"message": ack.Message.String(),
+		"state":   string(ack.State),
+	}
+}
+
+func retrieveAckSendInputs(ctx context.Context, op *fftypes.Operation) (nodeID *fftypes.UUID, ack *fftypes.DeliveryAck, err error) {
+	nodeID, err = fftypes.ParseUUID(ctx, op.Input.GetString("node"))
+	if err == nil {
+		ack = &fftypes.DeliveryAck{
+			Namespace: op.Namespace,
+			State:     fftypes.MessageState(op.Input.GetString("state")),
+		}
+		ack.Message, err = fftypes.ParseUUID(ctx, op.Input.GetString("message"))
+	}
+	return nodeID, ack, err
+}
+
 func (pm *privateMessaging) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	switch op.Type {
 	case fftypes.OpTypeDataExchangeSendBlob:
@@ -119,6 +143,19 @@ func (pm *privateMessaging) PrepareOperation(ctx context.Context, op *fftypes.Op
 		transport := &fftypes.TransportWrapper{Group: group, Batch: batch}
 		return opSendBatch(op, node, transport), nil
 
+	case fftypes.OpTypeDataExchangeSendAck:
+		nodeID, ack, err := retrieveAckSendInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		node, err := pm.database.GetIdentityByID(ctx, nodeID)
+		if err != nil {
+			return nil, err
+		} else if node == nil {
+			return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		return opSendAck(op, node, ack), nil
+
 	default:
 		return nil, i18n.NewError(ctx, i18n.MsgOperationNotSupported, op.Type)
 	}
@@ -130,12 +167,26 @@ func (pm *privateMessaging) RunOperation(ctx context.Context, op *fftypes.Prepar
 		return nil, false, pm.exchange.TransferBLOB(ctx, op.ID, data.Node.Profile.GetString("id"), data.Blob.PayloadRef)
 
 	case batchSendData:
-		payload, err := json.Marshal(data.Transport)
+		payload, err := fftypes.SerializeTransportPayload(ctx, data.Transport.Batch.ProtocolVersion, data.Transport)
 		if err != nil {
-			return nil, false, i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
+			return nil, false, err
 		}
 		return nil, false, pm.exchange.SendMessage(ctx, op.ID, data.Node.Profile.GetString("id"), payload)
 
+	case ackSendData:
+		if pm.ackSigningKey != nil {
+			data.Ack.Sign(pm.ackSigningKey)
+		}
+		protocolVersion, err := fftypes.NegotiateProtocolVersion(ctx, []*fftypes.Identity{data.Node})
+		if err != nil {
+			return nil, false, err
+		}
+		payload, err := fftypes.SerializeTransportPayload(ctx, protocolVersion, &fftypes.TransportWrapper{Ack: data.Ack})
+		if err == nil {
+			err = pm.exchange.SendMessage(ctx, op.ID, data.Node.Profile.GetString("id"), payload)
+		}
+		return nil, false, err
+
 	default:
 		return nil, false, i18n.NewError(ctx, i18n.MsgOperationDataIncorrect, op.Data)
 	}
@@ -143,16 +194,30 @@ func (pm *privateMessaging) RunOperation(ctx context.Context, op *fftypes.Prepar
 
 func opSendBlob(op *fftypes.Operation, node *fftypes.Identity, blob *fftypes.Blob) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: transferBlobData{Node: node, Blob: blob},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        transferBlobData{Node: node, Blob: blob},
 	}
 }
 
 func opSendBatch(op *fftypes.Operation, node *fftypes.Identity, transport *fftypes.TransportWrapper) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
-		Data: batchSendData{Node: node, Transport: transport},
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        batchSendData{Node: node, Transport: transport},
+	}
+}
+
+func opSendAck(op *fftypes.Operation, node *fftypes.Identity, ack *fftypes.DeliveryAck) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
+		Data:        ackSendData{Node: node, Ack: ack},
 	}
 }
@@ -127,6 +127,79 @@ func TestPrepareAndRunBatchSend(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestPrepareAndRunAckSend(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	_, pm.ackSigningKey = writeTestAckSigningKey(t)
+
+	op := &fftypes.Operation{
+		Type:      fftypes.OpTypeDataExchangeSendAck,
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	node := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID: fftypes.NewUUID(),
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{
+				"id":              "peer1",
+				"protocolVersion": float64(fftypes.ProtocolVersion2),
+			},
+		},
+	}
+	ack := &fftypes.DeliveryAck{
+		Namespace: "ns1",
+		Message:   fftypes.NewUUID(),
+		State:     fftypes.MessageStateConfirmed,
+	}
+	addAckSendInputs(op, node.ID, ack)
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdx := pm.exchange.(*dataexchangemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), node.ID).Return(node, nil)
+	mdx.On("SendMessage", context.Background(), op.ID, "peer1", mock.MatchedBy(func(payload []byte) bool {
+		var tw fftypes.TransportWrapper
+		v, err := fftypes.DeserializeTransportPayload(context.Background(), payload, 1024*1024, &tw)
+		return err == nil && v == fftypes.ProtocolVersion2 && tw.Batch == nil && tw.Ack.Message.Equals(ack.Message) &&
+			tw.Ack.VerifySignature(DeliveryAckPublicKey(pm.ackSigningKey))
+	})).Return(nil)
+
+	po, err := pm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, node, po.Data.(ackSendData).Node)
+	assert.Equal(t, ack, po.Data.(ackSendData).Ack)
+
+	_, complete, err := pm.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestRunOperationAckSendBadProtocolVersion(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	op := &fftypes.PreparedOperation{
+		ID: fftypes.NewUUID(),
+		Data: ackSendData{
+			Node: &fftypes.Identity{
+				IdentityProfile: fftypes.IdentityProfile{
+					Profile: fftypes.JSONObject{"protocolVersion": "bad"},
+				},
+			},
+			Ack: &fftypes.DeliveryAck{},
+		},
+	}
+
+	_, complete, err := pm.RunOperation(context.Background(), op)
+	assert.False(t, complete)
+	assert.Regexp(t, "FF10", err)
+}
+
 func TestPrepareAndRunBatchSendHydrateFail(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -307,6 +380,57 @@ func TestPrepareOperationBlobSendBlobNotFound(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestPrepareOperationAckSendBadInput(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeDataExchangeSendAck,
+		Input: fftypes.JSONObject{"node": "bad"},
+	}
+
+	_, err := pm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestPrepareOperationAckSendNodeFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendAck,
+	}
+	addAckSendInputs(op, nodeID, &fftypes.DeliveryAck{Message: fftypes.NewUUID()})
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.PrepareOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareOperationAckSendNodeNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeDataExchangeSendAck,
+	}
+	addAckSendInputs(op, nodeID, &fftypes.DeliveryAck{Message: fftypes.NewUUID()})
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, nil)
+
+	_, err := pm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestPrepareOperationBatchSendBadInput(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
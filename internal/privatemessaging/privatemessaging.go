@@ -18,6 +18,7 @@ package privatemessaging
 
 import (
 	"context"
+	"crypto/ed25519"
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
@@ -49,6 +50,14 @@ type Manager interface {
 	NewMessage(ns string, msg *fftypes.MessageInOut) sysmessaging.MessageSender
 	SendMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error)
 	RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut) (reply *fftypes.MessageInOut, err error)
+	SendDeliveryAck(ctx context.Context, batchID *fftypes.UUID, msg *fftypes.Message, state fftypes.MessageState) error
+
+	// Group aliases
+	GetGroupAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.GroupAlias, *database.FilterResult, error)
+	GetGroupAliasByName(ctx context.Context, ns, name string) (*fftypes.GroupAlias, error)
+	CreateGroupAlias(ctx context.Context, ns string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error)
+	UpdateGroupAlias(ctx context.Context, ns, name string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error)
+	DeleteGroupAlias(ctx context.Context, ns, name string) error
 
 	// From operations.OperationHandler
 	PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error)
@@ -75,6 +84,8 @@ type privateMessaging struct {
 	metrics               metrics.Manager
 	operations            operations.Manager
 	orgFirstNodes         map[fftypes.UUID]*fftypes.Identity
+	deliveryAcks          bool
+	ackSigningKey         ed25519.PrivateKey
 }
 
 func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Manager, dx dataexchange.Plugin, bi blockchain.Plugin, ba batch.Manager, dm data.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager) (Manager, error) {
@@ -97,6 +108,7 @@ func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Ma
 			database:      di,
 			data:          dm,
 			groupCacheTTL: config.GetDuration(config.GroupCacheTTL),
+			residency:     newResidencyPolicy(di, im),
 		},
 		retry: retry.Retry{
 			InitialDelay: config.GetDuration(config.PrivateMessagingRetryInitDelay),
@@ -108,6 +120,11 @@ func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Ma
 		metrics:               mm,
 		operations:            om,
 		orgFirstNodes:         make(map[fftypes.UUID]*fftypes.Identity),
+		deliveryAcks:          config.GetBool(config.PrivateMessagingDeliveryAcksEnabled),
+	}
+	var err error
+	if pm.ackSigningKey, err = LoadDeliveryAckSigningKey(ctx); err != nil {
+		return nil, err
 	}
 	pm.groupManager.groupCache = ccache.New(
 		// We use a LRU cache with a size-aware max
@@ -122,6 +139,7 @@ func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Ma
 		BatchTimeout:   config.GetDuration(config.PrivateMessagingBatchTimeout),
 		DisposeTimeout: config.GetDuration(config.PrivateMessagingBatchAgentTimeout),
 	}
+	bo.NegotiateProtocol = pm.negotiateProtocol
 
 	ba.RegisterDispatcher(pinnedPrivateDispatcherName,
 		fftypes.TransactionTypeBatchPin,
@@ -142,6 +160,7 @@ func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Ma
 	om.RegisterHandler(ctx, pm, []fftypes.OpType{
 		fftypes.OpTypeDataExchangeSendBlob,
 		fftypes.OpTypeDataExchangeSendBatch,
+		fftypes.OpTypeDataExchangeSendAck,
 	})
 
 	return pm, nil
@@ -169,8 +188,19 @@ func (pm *privateMessaging) dispatchUnpinnedBatch(ctx context.Context, state *ba
 	return pm.dispatchBatchCommon(ctx, state)
 }
 
+// negotiateProtocol determines the newest network protocol version supported by all the nodes in the group.
+// A node advertising an invalid version blocks dispatch, as it would not be able to process the batch.
+func (pm *privateMessaging) negotiateProtocol(ctx context.Context, state *batch.DispatchState) (uint, error) {
+	_, nodes, err := pm.groupManager.getGroupNodes(ctx, state.Persisted.Group, false /* fail if not found */)
+	if err != nil {
+		return 0, err
+	}
+	return fftypes.NegotiateProtocolVersion(ctx, nodes)
+}
+
 func (pm *privateMessaging) dispatchBatchCommon(ctx context.Context, state *batch.DispatchState) error {
 	batch := state.Persisted.GenInflight(state.Messages, state.Data)
+	batch.ProtocolVersion = state.ProtocolVersion
 	tw := &fftypes.TransportWrapper{
 		Batch: batch,
 	}
@@ -181,13 +211,32 @@ func (pm *privateMessaging) dispatchBatchCommon(ctx context.Context, state *batc
 		return err
 	}
 
+	// The allowed orgs can be narrowed after a message is accepted, so they are checked again before the data leaves
+	// the node. Every member needs the data to process the pin, so the batch is not sent to any of them if one is refused.
+	refused, err := pm.residency.refusedNode(ctx, batch.Namespace, nodes)
+	if err != nil {
+		return err
+	}
+	if refused != "" {
+		if !state.RefusalAudited {
+			if err = pm.residency.auditRefusal(ctx, batch.Namespace, batch.ID, refused); err != nil {
+				return err
+			}
+			state.RefusalAudited = true
+		}
+		return i18n.NewError(ctx, i18n.MsgPrivateBatchOrgNotAllowed, batch.ID, batch.Namespace, refused)
+	}
+
 	if batch.Payload.TX.Type == fftypes.TransactionTypeUnpinned {
 		// In the case of an un-pinned message we cannot be sure the group has been broadcast via the blockchain.
 		// So we have to take the hit of sending it along with every message.
 		tw.Group = group
 	}
 
-	return pm.sendData(ctx, tw, nodes)
+	if state.SentNodes == nil {
+		state.SentNodes = make(map[fftypes.UUID]bool)
+	}
+	return pm.sendData(ctx, tw, nodes, state.SentNodes)
 }
 
 func (pm *privateMessaging) transferBlobs(ctx context.Context, data fftypes.DataArray, txid *fftypes.UUID, node *fftypes.Identity) error {
@@ -217,7 +266,7 @@ func (pm *privateMessaging) transferBlobs(ctx context.Context, data fftypes.Data
 				return err
 			}
 
-			if err = pm.operations.RunOperation(ctx, opSendBlob(op, node, blob)); err != nil {
+			if err = pm.operations.RunOperation(ctx, opSendBlob(op, node, blob), operations.RemainPendingOnFailure); err != nil {
 				return err
 			}
 		}
@@ -225,7 +274,11 @@ func (pm *privateMessaging) transferBlobs(ctx context.Context, data fftypes.Data
 	return nil
 }
 
-func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportWrapper, nodes []*fftypes.Identity) (err error) {
+// sendData sends the batch to each of the nodes, skipping those it has already been sent to by an earlier attempt.
+// A failure to send to one node does not prevent the batch being sent to the others, and the first failure is
+// returned once every node has been attempted, so that the dispatch is retried to only the nodes that failed.
+// The dispatch is retried until it succeeds, so a failed send leaves the operation pending with the error recorded.
+func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportWrapper, nodes []*fftypes.Identity, sent map[fftypes.UUID]bool) (err error) {
 	l := log.L(ctx)
 	batch := tw.Batch
 
@@ -236,6 +289,7 @@ func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportW
 	}
 
 	// Write it to the dataexchange for each member
+	var sendErr error
 	for i, node := range nodes {
 
 		if node.Parent.Equals(localOrg.ID) {
@@ -243,30 +297,50 @@ func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportW
 			continue
 		}
 
-		l.Debugf("Sending batch %s:%s to group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, node.ID, i+1, len(nodes))
-
-		// Initiate transfer of any blobs first
-		if err = pm.transferBlobs(ctx, batch.Payload.Data, batch.Payload.TX.ID, node); err != nil {
-			return err
+		if sent[*node.ID] {
+			l.Debugf("Batch %s:%s already sent to group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, node.ID, i+1, len(nodes))
+			continue
 		}
 
-		op := fftypes.NewOperation(
-			pm.exchange,
-			batch.Namespace,
-			batch.Payload.TX.ID,
-			fftypes.OpTypeDataExchangeSendBatch)
-		var groupHash *fftypes.Bytes32
-		if tw.Group != nil {
-			groupHash = tw.Group.Hash
-		}
-		addBatchSendInputs(op, node.ID, groupHash, batch.ID)
-		if err = pm.operations.AddOrReuseOperation(ctx, op); err != nil {
-			return err
+		l.Debugf("Sending batch %s:%s to group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, node.ID, i+1, len(nodes))
+		if err = pm.sendDataToNode(ctx, tw, node); err != nil {
+			l.Errorf("Failed to send batch %s:%s to node=%s: %s", batch.Namespace, batch.ID, node.ID, err)
+			if sendErr == nil {
+				sendErr = err
+			}
+			continue
 		}
-		if err = pm.operations.RunOperation(ctx, opSendBatch(op, node, tw)); err != nil {
-			return err
+		if sent != nil {
+			sent[*node.ID] = true
 		}
 	}
 
-	return nil
+	return sendErr
+}
+
+func (pm *privateMessaging) sendDataToNode(ctx context.Context, tw *fftypes.TransportWrapper, node *fftypes.Identity) error {
+	batch := tw.Batch
+
+	// Initiate transfer of any blobs first
+	if err := pm.transferBlobs(ctx, batch.Payload.Data, batch.Payload.TX.ID, node); err != nil {
+		return err
+	}
+
+	op := fftypes.NewOperation(
+		pm.exchange,
+		batch.Namespace,
+		batch.Payload.TX.ID,
+		fftypes.OpTypeDataExchangeSendBatch)
+	var groupHash *fftypes.Bytes32
+	if tw.Group != nil {
+		groupHash = tw.Group.Hash
+	}
+	addBatchSendInputs(op, node.ID, groupHash, batch.ID)
+	if err := pm.operations.AddOrReuseOperation(ctx, op); err != nil {
+		return err
+	}
+	if err := pm.trackRecipients(ctx, batch, node); err != nil {
+		return err
+	}
+	return pm.operations.RunOperation(ctx, opSendBatch(op, node, tw), operations.RemainPendingOnFailure)
 }
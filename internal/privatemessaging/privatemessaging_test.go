@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -159,14 +160,14 @@ func TestDispatchBatchWithBlobs(t *testing.T) {
 		}
 		data := op.Data.(transferBlobData)
 		return *data.Node.ID == *node2.ID
-	})).Return(nil)
+	}), operations.RemainPendingOnFailure).Return(nil)
 	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
 		if op.Type != fftypes.OpTypeDataExchangeSendBatch {
 			return false
 		}
 		data := op.Data.(batchSendData)
 		return *data.Node.ID == *node2.ID
-	})).Return(nil)
+	}), operations.RemainPendingOnFailure).Return(nil)
 
 	mbp.On("SubmitPinnedBatch", pm.ctx, mock.Anything, mock.Anything).Return(nil)
 
@@ -200,6 +201,78 @@ func TestDispatchBatchWithBlobs(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestDispatchBatchRetriesFailedNodesOnly(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("localorg")
+	groupID := fftypes.NewRandB32()
+	node1 := newTestNode("node1", localOrg)
+	node2 := newTestNode("node2", newTestOrg("remoteorg2"))
+	node3 := newTestNode("node3", newTestOrg("remoteorg3"))
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mbp := pm.batchpin.(*batchpinmocks.Submitter)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mom := pm.operations.(*operationmocks.Manager)
+
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{
+		Hash: fftypes.NewRandB32(),
+		GroupIdentity: fftypes.GroupIdentity{
+			Name: "group1",
+			Members: fftypes.Members{
+				{Identity: "org1", Node: node1.ID},
+				{Identity: "org2", Node: node2.ID},
+				{Identity: "org3", Node: node3.ID},
+			},
+		},
+	}, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node1.ID).Return(node1, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node2.ID).Return(node2, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node3.ID).Return(node3, nil)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(nil)
+	sendTo := func(node *fftypes.Identity) interface{} {
+		return mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+			return op.Type == fftypes.OpTypeDataExchangeSendBatch && op.Data.(batchSendData).Node.ID.Equals(node.ID)
+		})
+	}
+	mom.On("RunOperation", pm.ctx, sendTo(node2), operations.RemainPendingOnFailure).Return(nil).Once()
+	mom.On("RunOperation", pm.ctx, sendTo(node3), operations.RemainPendingOnFailure).Return(fmt.Errorf("pop")).Once()
+	mom.On("RunOperation", pm.ctx, sendTo(node3), operations.RemainPendingOnFailure).Return(nil).Once()
+	mbp.On("SubmitPinnedBatch", pm.ctx, mock.Anything, mock.Anything).Return(nil).Once()
+
+	state := &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Group:     groupID,
+				Namespace: "ns1",
+			},
+			TX: fftypes.TransactionRef{
+				Type: fftypes.TransactionTypeBatchPin,
+				ID:   fftypes.NewUUID(),
+			},
+		},
+	}
+
+	// The send to node3 fails, so the batch is not pinned
+	err := pm.dispatchPinnedBatch(pm.ctx, state)
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, map[fftypes.UUID]bool{*node2.ID: true}, state.SentNodes)
+
+	// The retry only sends to node3
+	err = pm.dispatchPinnedBatch(pm.ctx, state)
+	assert.NoError(t, err)
+	assert.Equal(t, map[fftypes.UUID]bool{*node2.ID: true, *node3.ID: true}, state.SentNodes)
+
+	mdi.AssertExpectations(t)
+	mbp.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
 func TestNewPrivateMessagingMissingDeps(t *testing.T) {
 	_, err := NewPrivateMessaging(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
@@ -216,6 +289,50 @@ func TestDispatchErrorFindingGroup(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestNegotiateProtocol(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	groupID := fftypes.NewRandB32()
+	node1 := newTestNode("node1", newTestOrg("localorg"))
+	node1.Profile["protocolVersion"] = float64(2)
+	node2 := newTestNode("node2", newTestOrg("remoteorg"))
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{
+		Hash: groupID,
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{
+				{Identity: "org1", Node: node1.ID},
+				{Identity: "org2", Node: node2.ID},
+			},
+		},
+	}, nil)
+	mdi.On("GetIdentityByID", pm.ctx, node1.ID).Return(node1, nil).Once()
+	mdi.On("GetIdentityByID", pm.ctx, node2.ID).Return(node2, nil).Once()
+
+	version, err := pm.negotiateProtocol(pm.ctx, &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{Group: groupID},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ProtocolVersion1, version)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestNegotiateProtocolGroupFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.negotiateProtocol(pm.ctx, &batch.DispatchState{})
+	assert.Regexp(t, "pop", err)
+}
+
 func TestSendAndSubmitBatchBadID(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -346,6 +463,55 @@ func TestSendSubmitInsertOperationFail(t *testing.T) {
 	mim.AssertExpectations(t)
 }
 
+func TestSendSubmitTrackRecipientsFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.deliveryAcks = true
+
+	localOrg := newTestOrg("localorg")
+	groupID := fftypes.NewRandB32()
+	node1 := newTestNode("node1", localOrg)
+	node2 := newTestNode("node2", newTestOrg("remoteorg"))
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", pm.ctx, node1.ID).Return(node1, nil).Once()
+	mdi.On("GetIdentityByID", pm.ctx, node2.ID).Return(node2, nil).Once()
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{
+		Hash: fftypes.NewRandB32(),
+		GroupIdentity: fftypes.GroupIdentity{
+			Name: "group1",
+			Members: fftypes.Members{
+				{Identity: "org1", Node: node1.ID},
+				{Identity: "org2", Node: node2.ID},
+			},
+		},
+	}, nil)
+	mdi.On("UpsertMessageRecipient", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mom := pm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(nil)
+
+	err := pm.dispatchPinnedBatch(pm.ctx, &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				Group: groupID,
+				SignerRef: fftypes.SignerRef{
+					Author: "org1",
+				},
+			},
+		},
+		Messages: []*fftypes.Message{
+			{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
 func TestSendSubmitBlobTransferFail(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -383,7 +549,7 @@ func TestSendSubmitBlobTransferFail(t *testing.T) {
 	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
 		data := op.Data.(transferBlobData)
 		return op.Type == fftypes.OpTypeDataExchangeSendBlob && *data.Node.ID == *node2.ID
-	})).Return(fmt.Errorf("pop"))
+	}), operations.RemainPendingOnFailure).Return(fmt.Errorf("pop"))
 
 	err := pm.dispatchPinnedBatch(pm.ctx, &batch.DispatchState{
 		Persisted: fftypes.BatchPersisted{
@@ -440,14 +606,14 @@ func TestWriteTransactionSubmitBatchPinFail(t *testing.T) {
 		}
 		data := op.Data.(transferBlobData)
 		return *data.Node.ID == *node2.ID
-	})).Return(nil)
+	}), operations.RemainPendingOnFailure).Return(nil)
 	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
 		if op.Type != fftypes.OpTypeDataExchangeSendBatch {
 			return false
 		}
 		data := op.Data.(batchSendData)
 		return *data.Node.ID == *node2.ID
-	})).Return(nil)
+	}), operations.RemainPendingOnFailure).Return(nil)
 
 	mdi.On("GetBlobMatchingHash", pm.ctx, blob1).Return(&fftypes.Blob{
 		Hash:       blob1,
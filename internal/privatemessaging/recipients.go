@@ -27,6 +27,16 @@ import (
 )
 
 func (pm *privateMessaging) resolveRecipientList(ctx context.Context, in *fftypes.MessageInOut) error {
+	if in.Group != nil && in.Group.Alias != "" {
+		if in.Header.Group != nil || len(in.Group.Members) > 0 {
+			return i18n.NewError(ctx, i18n.MsgGroupAliasWithMembers)
+		}
+		alias, err := pm.GetGroupAliasByName(ctx, in.Header.Namespace, in.Group.Alias)
+		if err != nil {
+			return err
+		}
+		in.Header.Group = alias.Group
+	}
 	if in.Header.Group != nil {
 		log.L(ctx).Debugf("Group '%s' specified for message", in.Header.Group)
 		group, err := pm.database.GetGroupByHash(ctx, in.Header.Group)
@@ -37,7 +47,7 @@ func (pm *privateMessaging) resolveRecipientList(ctx context.Context, in *fftype
 			return i18n.NewError(ctx, i18n.MsgGroupNotFound, in.Header.Group)
 		}
 		// We have a group already resolved
-		return nil
+		return pm.checkResidency(ctx, in, group.Members)
 	}
 	if in.Group == nil || len(in.Group.Members) == 0 {
 		return i18n.NewError(ctx, i18n.MsgGroupMustHaveMembers)
@@ -47,6 +57,9 @@ func (pm *privateMessaging) resolveRecipientList(ctx context.Context, in *fftype
 		return err
 	}
 	log.L(ctx).Debugf("Resolved group '%s' for message. New=%t", group.Hash, isNew)
+	if err := pm.checkResidency(ctx, in, group.Members); err != nil {
+		return err
+	}
 	in.Message.Header.Group = group.Hash
 
 	// If the group is new, we need to do a group initialization, before we send the message itself.
@@ -56,6 +69,19 @@ func (pm *privateMessaging) resolveRecipientList(ctx context.Context, in *fftype
 	return err
 }
 
+// checkResidency refuses to send a message to a group that includes an org the namespace is not allowed to exchange private data with
+func (pm *privateMessaging) checkResidency(ctx context.Context, in *fftypes.MessageInOut, members fftypes.Members) error {
+	ns := in.Header.Namespace
+	refused, err := pm.residency.refusedMember(ctx, ns, members)
+	if err != nil || refused == "" {
+		return err
+	}
+	if err := pm.residency.auditRefusal(ctx, ns, in.Header.ID, refused); err != nil {
+		return err
+	}
+	return i18n.NewError(ctx, i18n.MsgPrivateDataOrgNotAllowed, ns, refused)
+}
+
 func (pm *privateMessaging) getFirstNodeForOrg(ctx context.Context, identity *fftypes.Identity) (*fftypes.Identity, error) {
 	node := pm.orgFirstNodes[*identity.ID]
 	if node == nil && identity.Type == fftypes.IdentityTypeOrg {
@@ -41,6 +41,7 @@ func TestResolveMemberListNewGroupE2E(t *testing.T) {
 
 	var dataID *fftypes.UUID
 	mdi := pm.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
 	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{remoteNode}, nil, nil).Once()
 	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil).Once()
 	mdi.On("GetGroupByHash", pm.ctx, mock.Anything, mock.Anything).Return(nil, nil).Once()
@@ -435,3 +436,67 @@ func TestResolveLocalNodeNotError(t *testing.T) {
 	_, err := pm.resolveLocalNode(pm.ctx, newTestOrg("localorg"))
 	assert.EqualError(t, err, "pop")
 }
+
+func TestResolveGroupAlias(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	hash := fftypes.NewRandB32()
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(&fftypes.GroupAlias{Group: hash}, nil)
+	mdi.On("GetGroupByHash", pm.ctx, hash).Return(&fftypes.Group{Hash: hash}, nil)
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Alias: "team1",
+		},
+	}
+	err := pm.resolveRecipientList(pm.ctx, in)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, in.Header.Group)
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveGroupAliasNotFound(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupAliasByName", pm.ctx, "ns1", "team1").Return(nil, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Alias: "team1",
+		},
+	})
+	assert.Regexp(t, "FF10481", err)
+
+}
+
+func TestResolveGroupAliasWithMembers(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Group: &fftypes.InputGroup{
+			Alias:   "team1",
+			Members: []fftypes.MemberInput{{Identity: "org1"}},
+		},
+	})
+	assert.Regexp(t, "FF10482", err)
+
+}
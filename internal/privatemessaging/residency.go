@@ -0,0 +1,126 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// residencyPolicy restricts the orgs that a namespace can exchange private data with, for data residency.
+// Namespaces without an allow-list in the predefined namespace config are not restricted, and the
+// local org is always allowed.
+type residencyPolicy struct {
+	database    database.Plugin
+	identity    identity.Manager
+	allowedOrgs map[string]map[string]bool
+}
+
+func newResidencyPolicy(di database.Plugin, im identity.Manager) *residencyPolicy {
+	rp := &residencyPolicy{
+		database:    di,
+		identity:    im,
+		allowedOrgs: make(map[string]map[string]bool),
+	}
+	for _, entry := range config.GetObjectArray(config.NamespacesPredefined) {
+		orgs := entry.GetObject("privateData").GetStringArray("allowedOrgs")
+		if len(orgs) > 0 {
+			allowed := make(map[string]bool, len(orgs))
+			for _, did := range orgs {
+				allowed[did] = true
+			}
+			rp.allowedOrgs[entry.GetString("name")] = allowed
+		}
+	}
+	return rp
+}
+
+// nodeOrgAllowed returns the DID of the org that owns a node, and whether the namespace is allowed to send private data to it
+func (rp *residencyPolicy) nodeOrgAllowed(ctx context.Context, ns string, node *fftypes.Identity) (string, bool, error) {
+	allowed := rp.allowedOrgs[ns]
+	if allowed == nil {
+		return "", true, nil
+	}
+	localOrg, err := rp.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if node.Parent.Equals(localOrg.ID) {
+		return localOrg.DID, true, nil
+	}
+	org, err := rp.identity.CachedIdentityLookupByID(ctx, node.Parent)
+	if err != nil {
+		return "", false, err
+	}
+	if org == nil {
+		return node.DID, false, nil
+	}
+	return org.DID, allowed[org.DID], nil
+}
+
+// refusedMember returns the DID of the first org in the group that the namespace is not allowed to exchange
+// private data with, or an empty string if all members are allowed
+func (rp *residencyPolicy) refusedMember(ctx context.Context, ns string, members fftypes.Members) (string, error) {
+	if rp.allowedOrgs[ns] == nil {
+		return "", nil
+	}
+	for _, member := range members {
+		node, err := rp.identity.CachedIdentityLookupByID(ctx, member.Node)
+		if err != nil {
+			return "", err
+		}
+		if node == nil {
+			return member.Identity, nil
+		}
+		did, allowed, err := rp.nodeOrgAllowed(ctx, ns, node)
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			return did, nil
+		}
+	}
+	return "", nil
+}
+
+// refusedNode returns the DID of the org that owns the first node the namespace is not allowed to send private
+// data to, or an empty string if all the nodes are allowed
+func (rp *residencyPolicy) refusedNode(ctx context.Context, ns string, nodes []*fftypes.Identity) (string, error) {
+	for _, node := range nodes {
+		did, allowed, err := rp.nodeOrgAllowed(ctx, ns, node)
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			return did, nil
+		}
+	}
+	return "", nil
+}
+
+// auditRefusal records a refused exchange of private data as an event, with the refused org as the topic.
+// The reference is the message that was refused, or the batch if it was refused at dispatch.
+func (rp *residencyPolicy) auditRefusal(ctx context.Context, ns string, ref *fftypes.UUID, did string) error {
+	log.L(ctx).Warnf("Refused private data exchange in namespace '%s' with '%s' (ref=%s): not an allowed org", ns, did, ref)
+	event := fftypes.NewEvent(fftypes.EventTypePrivateDataRefused, ns, ref, nil, did)
+	return rp.database.InsertEvent(ctx, event)
+}
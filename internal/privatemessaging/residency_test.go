@@ -0,0 +1,458 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/batch"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestResidency(t *testing.T) (*privateMessaging, *fftypes.Identity, *fftypes.Identity, func()) {
+	pm, cancel := newTestPrivateMessaging(t)
+	localOrg := newTestOrg("localorg")
+	allowedOrg := newTestOrg("allowedorg")
+	pm.residency.allowedOrgs["ns1"] = map[string]bool{allowedOrg.DID: true}
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil).Maybe()
+	mim.On("CachedIdentityLookupByID", pm.ctx, allowedOrg.ID).Return(allowedOrg, nil).Maybe()
+	return pm, localOrg, allowedOrg, cancel
+}
+
+func isRefusedEvent(did string) interface{} {
+	return mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypePrivateDataRefused && event.Namespace == "ns1" && event.Topic == did
+	})
+}
+
+func TestNewResidencyPolicyFromConfig(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "privateData": map[string]interface{}{"allowedOrgs": []interface{}{"did:firefly:org/org1"}}},
+		{"name": "ns2"},
+	})
+	rp := newResidencyPolicy(&databasemocks.Plugin{}, &identitymanagermocks.Manager{})
+	assert.True(t, rp.allowedOrgs["ns1"]["did:firefly:org/org1"])
+	assert.Nil(t, rp.allowedOrgs["ns2"])
+}
+
+func TestNodeOrgAllowed(t *testing.T) {
+	pm, localOrg, allowedOrg, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+
+	did, allowed, err := pm.residency.nodeOrgAllowed(pm.ctx, "ns1", newTestNode("node1", localOrg))
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, localOrg.DID, did)
+
+	did, allowed, err = pm.residency.nodeOrgAllowed(pm.ctx, "ns1", newTestNode("node2", allowedOrg))
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, allowedOrg.DID, did)
+
+	did, allowed, err = pm.residency.nodeOrgAllowed(pm.ctx, "ns1", newTestNode("node3", otherOrg))
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, otherOrg.DID, did)
+
+	_, allowed, err = pm.residency.nodeOrgAllowed(pm.ctx, "ns2", newTestNode("node3", otherOrg))
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestNodeOrgAllowedOrgNotFound(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	node := newTestNode("node1", newTestOrg("unknown"))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, node.Parent).Return(nil, nil)
+
+	did, allowed, err := pm.residency.nodeOrgAllowed(pm.ctx, "ns1", node)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, node.DID, did)
+}
+
+func TestNodeOrgAllowedLookupFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	node := newTestNode("node1", newTestOrg("unknown"))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, node.Parent).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := pm.residency.nodeOrgAllowed(pm.ctx, "ns1", node)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestNodeOrgAllowedLocalOrgFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	pm.residency.allowedOrgs["ns1"] = map[string]bool{}
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := pm.residency.nodeOrgAllowed(pm.ctx, "ns1", newTestNode("node1", newTestOrg("org1")))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefusedMemberNodeNotFound(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, nodeID).Return(nil, nil)
+
+	did, err := pm.residency.refusedMember(pm.ctx, "ns1", fftypes.Members{
+		{Identity: "did:firefly:org/unknown", Node: nodeID},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/unknown", did)
+}
+
+func TestRefusedMemberNodeLookupFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, nodeID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.residency.refusedMember(pm.ctx, "ns1", fftypes.Members{
+		{Identity: "did:firefly:org/unknown", Node: nodeID},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefusedMemberOrgLookupFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	node := newTestNode("node1", newTestOrg("unknown"))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, node.ID).Return(node, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, node.Parent).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.residency.refusedMember(pm.ctx, "ns1", fftypes.Members{
+		{Identity: "did:firefly:org/unknown", Node: node.ID},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveRecipientListExistingGroupRefused(t *testing.T) {
+	pm, localOrg, allowedOrg, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	localNode := newTestNode("node1", localOrg)
+	allowedNode := newTestNode("node2", allowedOrg)
+	otherNode := newTestNode("node3", otherOrg)
+	msgID := fftypes.NewUUID()
+	groupHash := fftypes.NewRandB32()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, localNode.ID).Return(localNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, allowedNode.ID).Return(allowedNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherNode.ID).Return(otherNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupHash).Return(&fftypes.Group{
+		Hash: groupHash,
+		GroupIdentity: fftypes.GroupIdentity{
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: localOrg.DID, Node: localNode.ID},
+				{Identity: allowedOrg.DID, Node: allowedNode.ID},
+				{Identity: otherOrg.DID, Node: otherNode.ID},
+			},
+		},
+	}, nil)
+	mdi.On("InsertEvent", pm.ctx, isRefusedEvent(otherOrg.DID)).Return(nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        msgID,
+				Namespace: "ns1",
+				Group:     groupHash,
+			},
+		},
+	})
+	assert.Regexp(t, "FF10525.*otherorg", err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestResolveRecipientListNewGroupRefusedAuditFail(t *testing.T) {
+	pm, localOrg, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	localNode := newTestNode("node1", localOrg)
+	otherNode := newTestNode("node2", otherOrg)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupMustExist", pm.ctx, "otherorg").Return(otherOrg, false, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, localNode.ID).Return(localNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherNode.ID).Return(otherNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{otherNode}, nil, nil).Once()
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil).Once()
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertEvent", pm.ctx, isRefusedEvent(otherOrg.DID)).Return(fmt.Errorf("pop"))
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "otherorg"},
+			},
+		},
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestResolveRecipientListNewGroupLookupFail(t *testing.T) {
+	pm, localOrg, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	localNode := newTestNode("node1", localOrg)
+	otherNode := newTestNode("node2", otherOrg)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupMustExist", pm.ctx, "otherorg").Return(otherOrg, false, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{otherNode}, nil, nil).Once()
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil).Once()
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(nil, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "otherorg"},
+			},
+		},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveInitGroupRefused(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	otherNode := newTestNode("node1", otherOrg)
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Name:      "group1",
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: otherOrg.DID, Node: otherNode.ID},
+			},
+		},
+	}
+	group.Seal()
+	b, _ := json.Marshal(&group)
+	msgID := fftypes.NewUUID()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherNode.ID).Return(otherNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", pm.ctx, mock.Anything).Return(fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtrBytes(b)},
+	}, true, nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", pm.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypePrivateDataRefused && event.Reference.Equals(msgID)
+	})).Return(nil)
+
+	resolved, err := pm.ResolveInitGroup(pm.ctx, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        msgID,
+			Namespace: fftypes.SystemNamespace,
+			Tag:       fftypes.SystemTagDefineGroup,
+			Group:     group.Hash,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, resolved)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveInitGroupResidencyFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Name:      "group1",
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: "did:firefly:org/otherorg", Node: fftypes.NewUUID()},
+			},
+		},
+	}
+	group.Seal()
+	b, _ := json.Marshal(&group)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", pm.ctx, mock.Anything).Return(fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtrBytes(b)},
+	}, true, nil)
+
+	_, err := pm.ResolveInitGroup(pm.ctx, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: fftypes.SystemNamespace,
+			Tag:       fftypes.SystemTagDefineGroup,
+			Group:     group.Hash,
+		},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func newTestResidencyDispatch(pm *privateMessaging, node *fftypes.Identity) *batch.DispatchState {
+	groupID := fftypes.NewRandB32()
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", pm.ctx, node.ID).Return(node, nil)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{
+		Hash: groupID,
+		GroupIdentity: fftypes.GroupIdentity{
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: "did:firefly:org/otherorg", Node: node.ID},
+			},
+		},
+	}, nil)
+	return &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+				Group:     groupID,
+			},
+		},
+	}
+}
+
+func TestDispatchFailsRefusedNode(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	state := newTestResidencyDispatch(pm, newTestNode("node1", otherOrg))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", pm.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypePrivateDataRefused && event.Reference.Equals(state.Persisted.ID)
+	})).Return(nil).Once()
+
+	err := pm.dispatchPinnedBatch(pm.ctx, state)
+	assert.Regexp(t, "FF10572.*otherorg", err)
+	assert.True(t, state.RefusalAudited)
+
+	// The refusal is only recorded once, when the dispatch is retried
+	err = pm.dispatchPinnedBatch(pm.ctx, state)
+	assert.Regexp(t, "FF10572.*otherorg", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDispatchRefusedNodeAuditFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	state := newTestResidencyDispatch(pm, newTestNode("node1", otherOrg))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(otherOrg, nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := pm.dispatchUnpinnedBatch(pm.ctx, state)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDispatchResidencyLookupFail(t *testing.T) {
+	pm, _, _, cancel := newTestResidency(t)
+	defer cancel()
+
+	otherOrg := newTestOrg("otherorg")
+	state := newTestResidencyDispatch(pm, newTestNode("node1", otherOrg))
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, otherOrg.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.dispatchUnpinnedBatch(pm.ctx, state)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefusedMemberAllAllowed(t *testing.T) {
+	pm, localOrg, allowedOrg, cancel := newTestResidency(t)
+	defer cancel()
+
+	localNode := newTestNode("node1", localOrg)
+	allowedNode := newTestNode("node2", allowedOrg)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, localNode.ID).Return(localNode, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, allowedNode.ID).Return(allowedNode, nil)
+
+	did, err := pm.residency.refusedMember(pm.ctx, "ns1", fftypes.Members{
+		{Identity: localOrg.DID, Node: localNode.ID},
+		{Identity: allowedOrg.DID, Node: allowedNode.ID},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, did)
+}
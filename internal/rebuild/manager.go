@@ -0,0 +1,177 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebuild
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager clears the state derived from aggregating the pinned batches, while the node is stopped, so that it is
+// reconstructed from genesis when the node restarts and the aggregator replays the pins.
+// Definitions, and the messages that carry them, are preserved - along with the batches, data and pins themselves.
+// Only the pins and batches already stored are replayed. Lost or corrupted pins and batches are not fetched
+// again from the blockchain or shared storage.
+type Manager interface {
+	// Run clears the derived state, returning once complete
+	Run(ctx context.Context) (*fftypes.RebuildStatus, error)
+}
+
+type rebuildManager struct {
+	database database.Plugin
+	pageSize uint64
+}
+
+func NewRebuildManager(ctx context.Context, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	return &rebuildManager{
+		database: di,
+		pageSize: uint64(config.GetUint(config.RebuildPageSize)),
+	}, nil
+}
+
+func (rm *rebuildManager) Run(ctx context.Context) (*fftypes.RebuildStatus, error) {
+	status := &fftypes.RebuildStatus{
+		Started: fftypes.Now(),
+	}
+	log.L(ctx).Infof("Rebuild started")
+	// Each step can safely be run again, so a rebuild that fails part way through is completed by running it again
+	steps := []func(ctx context.Context, status *fftypes.RebuildStatus) error{
+		rm.resetMessages,
+		rm.clearRedeliveries,
+		rm.clearNextPins,
+		rm.resetPins,
+	}
+	for _, step := range steps {
+		if err := step(ctx, status); err != nil {
+			log.L(ctx).Errorf("Rebuild failed: %s", err)
+			return status, err
+		}
+	}
+	status.Completed = fftypes.Now()
+	log.L(ctx).Infof("Rebuild completed: messages=%d nextpins=%d redeliveries=%d", status.Messages, status.NextPins, status.Redeliveries)
+	return status, nil
+}
+
+// resetMessages returns the messages confirmed or rejected by the aggregator to pending, and deletes the events
+// emitted for them. Definition messages keep their state, as the aggregator does not process them again.
+func (rm *rebuildManager) resetMessages(ctx context.Context, status *fftypes.RebuildStatus) error {
+	for {
+		// Reset messages no longer match, so we always read the first page
+		fb := database.MessageQueryFactory.NewFilter(ctx)
+		filter := fb.And(
+			fb.In("state", []driver.Value{fftypes.MessageStateConfirmed, fftypes.MessageStateRejected}),
+			fb.Neq("type", fftypes.MessageTypeDefinition),
+		).Sort("sequence").Limit(rm.pageSize)
+		msgs, _, err := rm.database.GetMessages(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+
+		msgIDs := make([]driver.Value, len(msgs))
+		for i, msg := range msgs {
+			msgIDs[i] = msg.Header.ID
+		}
+		err = rm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+			eb := database.EventQueryFactory.NewFilter(ctx)
+			err := rm.database.DeleteEvents(ctx, eb.And(
+				eb.In("type", []driver.Value{fftypes.EventTypeMessageConfirmed, fftypes.EventTypeMessageRejected}),
+				eb.In("reference", msgIDs),
+			))
+			if err != nil {
+				return err
+			}
+			mb := database.MessageQueryFactory.NewFilter(ctx)
+			update := database.MessageQueryFactory.NewUpdate(ctx).Set("state", fftypes.MessageStatePending)
+			return rm.database.UpdateMessages(ctx, mb.In("id", msgIDs), update)
+		})
+		if err != nil {
+			return err
+		}
+		status.Messages += int64(len(msgs))
+		log.L(ctx).Infof("Rebuild reset %d messages", status.Messages)
+	}
+}
+
+// clearRedeliveries deletes the rejected delivery counts of subscriptions, as they refer to the deleted events
+func (rm *rebuildManager) clearRedeliveries(ctx context.Context, status *fftypes.RebuildStatus) error {
+	for {
+		fb := database.SubscriptionRedeliveryQueryFactory.NewFilter(ctx)
+		redeliveries, _, err := rm.database.GetSubscriptionRedeliveries(ctx, fb.And().Limit(rm.pageSize))
+		if err != nil {
+			return err
+		}
+		if len(redeliveries) == 0 {
+			return nil
+		}
+		for _, r := range redeliveries {
+			if err := rm.database.DeleteSubscriptionRedelivery(ctx, r.Subscription, r.Event); err != nil {
+				return err
+			}
+			status.Redeliveries++
+		}
+	}
+}
+
+// clearNextPins deletes the next expected pin for each private context, so they are calculated again from the
+// first nonce of each group member as the masked pins are replayed
+func (rm *rebuildManager) clearNextPins(ctx context.Context, status *fftypes.RebuildStatus) error {
+	for {
+		fb := database.NextPinQueryFactory.NewFilter(ctx)
+		nextPins, _, err := rm.database.GetNextPins(ctx, fb.And().Limit(rm.pageSize))
+		if err != nil {
+			return err
+		}
+		if len(nextPins) == 0 {
+			return nil
+		}
+		for _, np := range nextPins {
+			if err := rm.database.DeleteNextPin(ctx, np.Sequence); err != nil {
+				return err
+			}
+			status.NextPins++
+		}
+	}
+}
+
+// resetPins marks all the pins undispatched, and moves the aggregator back to the first pin
+func (rm *rebuildManager) resetPins(ctx context.Context, status *fftypes.RebuildStatus) error {
+	return rm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		fb := database.PinQueryFactory.NewFilter(ctx)
+		update := database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", false)
+		if err := rm.database.UpdatePins(ctx, fb.And(fb.Eq("dispatched", true)), update); err != nil {
+			return err
+		}
+		return rm.database.UpsertOffset(ctx, &fftypes.Offset{
+			Type:    fftypes.OffsetTypeAggregator,
+			Name:    events.AggregatorOffsetName,
+			Current: 0,
+		}, true)
+	})
+}
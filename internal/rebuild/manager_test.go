@@ -0,0 +1,154 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebuild
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRebuildManager() (*rebuildManager, *databasemocks.Plugin) {
+	config.Reset()
+	mdi := &databasemocks.Plugin{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	rm, _ := NewRebuildManager(context.Background(), mdi)
+	return rm.(*rebuildManager), mdi
+}
+
+func TestNewRebuildManagerMissingDeps(t *testing.T) {
+	_, err := NewRebuildManager(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestRebuildOK(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+
+	msg1 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	msg2 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg1, msg2}, nil, nil).Once()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil).Once()
+	mdi.On("DeleteEvents", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	redelivery := &fftypes.SubscriptionRedelivery{Subscription: fftypes.NewUUID(), Event: fftypes.NewUUID()}
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{redelivery}, nil, nil).Once()
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{}, nil, nil).Once()
+	mdi.On("DeleteSubscriptionRedelivery", mock.Anything, redelivery.Subscription, redelivery.Event).Return(nil)
+
+	mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{{Sequence: 1}, {Sequence: 2}}, nil, nil).Once()
+	mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil).Once()
+	mdi.On("DeleteNextPin", mock.Anything, int64(1)).Return(nil)
+	mdi.On("DeleteNextPin", mock.Anything, int64(2)).Return(nil)
+
+	mdi.On("UpdatePins", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertOffset", mock.Anything, &fftypes.Offset{
+		Type:    fftypes.OffsetTypeAggregator,
+		Name:    events.AggregatorOffsetName,
+		Current: 0,
+	}, true).Return(nil)
+
+	status, err := rm.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), status.Messages)
+	assert.Equal(t, int64(1), status.Redeliveries)
+	assert.Equal(t, int64(2), status.NextPins)
+	assert.NotNil(t, status.Completed)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRebuildGetMessagesFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	status, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+	assert.Nil(t, status.Completed)
+}
+
+func TestRebuildDeleteEventsFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{
+		{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+	}, nil, nil)
+	mdi.On("DeleteEvents", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRebuildGetRedeliveriesFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRebuildDeleteRedeliveryFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{{}}, nil, nil)
+	mdi.On("DeleteSubscriptionRedelivery", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRebuildGetNextPinsFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{}, nil, nil)
+	mdi.On("GetNextPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRebuildDeleteNextPinFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{}, nil, nil)
+	mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{{Sequence: 1}}, nil, nil)
+	mdi.On("DeleteNextPin", mock.Anything, int64(1)).Return(fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRebuildUpdatePinsFail(t *testing.T) {
+	rm, mdi := newTestRebuildManager()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	mdi.On("GetSubscriptionRedeliveries", mock.Anything, mock.Anything).Return([]*fftypes.SubscriptionRedelivery{}, nil, nil)
+	mdi.On("GetNextPins", mock.Anything, mock.Anything).Return([]*fftypes.NextPin{}, nil, nil)
+	mdi.On("UpdatePins", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := rm.Run(context.Background())
+	assert.EqualError(t, err, "pop")
+}
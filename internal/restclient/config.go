@@ -29,6 +29,9 @@ const (
 	defaultHTTPConnectionTimeout     = "30s"
 	defaultHTTPTLSHandshakeTimeout   = "10s" // match Go's default
 	defaultHTTPExpectContinueTimeout = "1s"  // match Go's default
+	defaultCircuitBreakerEnabled     = false
+	defaultCircuitBreakerThreshold   = 5
+	defaultCircuitBreakerReset       = "30s"
 )
 
 const (
@@ -42,6 +45,16 @@ const (
 	HTTPConfigAuthUsername = "auth.username"
 	// HTTPConfigAuthPassword HTTPS Basic Auth configuration - secret / password
 	HTTPConfigAuthPassword = "auth.password"
+	// HTTPConfigAuthTokenFile a file containing a bearer token, which is read again when the token is rejected
+	HTTPConfigAuthTokenFile = "auth.tokenFile"
+	// HTTPConfigAuthOAuth2TokenURL the token endpoint to obtain bearer tokens from with the OAuth2 client credentials flow
+	HTTPConfigAuthOAuth2TokenURL = "auth.oauth2.tokenUrl"
+	// HTTPConfigAuthOAuth2ClientID the client ID for the OAuth2 client credentials flow
+	HTTPConfigAuthOAuth2ClientID = "auth.oauth2.clientId"
+	// HTTPConfigAuthOAuth2ClientSecret the client secret for the OAuth2 client credentials flow
+	HTTPConfigAuthOAuth2ClientSecret = "auth.oauth2.clientSecret"
+	// HTTPConfigAuthOAuth2Scopes the space separated scopes to request with the OAuth2 client credentials flow
+	HTTPConfigAuthOAuth2Scopes = "auth.oauth2.scopes"
 	// HTTPConfigRetryEnabled whether retry is enabled on the actions performed over this HTTP request (does not disable retry at higher layers)
 	HTTPConfigRetryEnabled = "retry.enabled"
 	// HTTPConfigRetryCount the maximum number of retries
@@ -62,6 +75,12 @@ const (
 	HTTTPTLSHandshakeTimeout = "tlsHandshakeTimeout"
 	// HTTPExpectContinueTimeout see ExpectContinueTimeout in Go docs
 	HTTPExpectContinueTimeout = "expectContinueTimeout"
+	// HTTPConfigCircuitBreakerEnabled whether to stop calling the endpoint after consecutive failures, until it recovers
+	HTTPConfigCircuitBreakerEnabled = "circuitBreaker.enabled"
+	// HTTPConfigCircuitBreakerFailureThreshold the number of consecutive failures that open the circuit
+	HTTPConfigCircuitBreakerFailureThreshold = "circuitBreaker.failureThreshold"
+	// HTTPConfigCircuitBreakerResetTimeout how long an open circuit rejects calls, before allowing a probe call through
+	HTTPConfigCircuitBreakerResetTimeout = "circuitBreaker.resetTimeout"
 
 	// HTTPCustomClient - unit test only - allows injection of a custom HTTP client to resty
 	HTTPCustomClient = "customClient"
@@ -73,6 +92,11 @@ func InitPrefix(prefix config.KeySet) {
 	prefix.AddKnownKey(HTTPConfigHeaders)
 	prefix.AddKnownKey(HTTPConfigAuthUsername)
 	prefix.AddKnownKey(HTTPConfigAuthPassword)
+	prefix.AddKnownKey(HTTPConfigAuthTokenFile)
+	prefix.AddKnownKey(HTTPConfigAuthOAuth2TokenURL)
+	prefix.AddKnownKey(HTTPConfigAuthOAuth2ClientID)
+	prefix.AddKnownKey(HTTPConfigAuthOAuth2ClientSecret)
+	prefix.AddKnownKey(HTTPConfigAuthOAuth2Scopes)
 	prefix.AddKnownKey(HTTPConfigRetryEnabled, defaultRetryEnabled)
 	prefix.AddKnownKey(HTTPConfigRetryCount, defaultRetryCount)
 	prefix.AddKnownKey(HTTPConfigRetryInitDelay, defaultRetryWaitTime)
@@ -83,6 +107,9 @@ func InitPrefix(prefix config.KeySet) {
 	prefix.AddKnownKey(HTTPConnectionTimeout, defaultHTTPConnectionTimeout)
 	prefix.AddKnownKey(HTTTPTLSHandshakeTimeout, defaultHTTPTLSHandshakeTimeout)
 	prefix.AddKnownKey(HTTPExpectContinueTimeout, defaultHTTPExpectContinueTimeout)
+	prefix.AddKnownKey(HTTPConfigCircuitBreakerEnabled, defaultCircuitBreakerEnabled)
+	prefix.AddKnownKey(HTTPConfigCircuitBreakerFailureThreshold, defaultCircuitBreakerThreshold)
+	prefix.AddKnownKey(HTTPConfigCircuitBreakerResetTimeout, defaultCircuitBreakerReset)
 
 	prefix.AddKnownKey(HTTPCustomClient)
 }
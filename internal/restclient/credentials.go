@@ -0,0 +1,206 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+// oauth2ExpiryMargin is how long before it expires an OAuth2 access token is replaced, so that a
+// token is not rejected while a request is in flight
+const oauth2ExpiryMargin = 10 * time.Second
+
+// CredentialProvider supplies the Authorization header for the requests to a connector, for
+// credentials that can be rotated while FireFly is running
+type CredentialProvider interface {
+	// Authorization returns the current value of the Authorization header, obtaining it if required
+	Authorization(ctx context.Context) (string, error)
+
+	// Refresh discards the current credentials, after the connector rejected them, so that the
+	// next call to Authorization obtains new ones
+	Refresh(ctx context.Context)
+}
+
+// NewCredentialProvider returns the credential provider configured for a connector, or nil if the
+// connector uses static credentials
+func NewCredentialProvider(staticConfig config.Prefix) CredentialProvider {
+	if tokenFile := staticConfig.GetString(HTTPConfigAuthTokenFile); tokenFile != "" {
+		return &fileCredentials{
+			filename: tokenFile,
+		}
+	}
+	if tokenURL := staticConfig.GetString(HTTPConfigAuthOAuth2TokenURL); tokenURL != "" {
+		return &oauth2Credentials{
+			client:       resty.New().SetTimeout(staticConfig.GetDuration(HTTPConfigRequestTimeout)),
+			tokenURL:     tokenURL,
+			clientID:     staticConfig.GetString(HTTPConfigAuthOAuth2ClientID),
+			clientSecret: staticConfig.GetString(HTTPConfigAuthOAuth2ClientSecret),
+			scopes:       staticConfig.GetString(HTTPConfigAuthOAuth2Scopes),
+		}
+	}
+	return nil
+}
+
+// fileCredentials reads a bearer token from a file, such as one mounted from a Kubernetes secret,
+// and reads the file again when the token is rejected
+type fileCredentials struct {
+	filename string
+	mux      sync.Mutex
+	header   string
+}
+
+func (fc *fileCredentials) Authorization(ctx context.Context) (string, error) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+	if fc.header == "" {
+		b, err := ioutil.ReadFile(fc.filename)
+		if err != nil {
+			return "", i18n.WrapError(ctx, err, i18n.MsgCredentialsFileReadFailed, fc.filename)
+		}
+		// Files such as Kubernetes and Docker secrets commonly have a trailing newline
+		token := strings.TrimRight(string(b), "\r\n")
+		if token == "" {
+			return "", i18n.NewError(ctx, i18n.MsgCredentialsFileEmpty, fc.filename)
+		}
+		log.L(ctx).Debugf("Read bearer token from '%s'", fc.filename)
+		fc.header = fmt.Sprintf("Bearer %s", token)
+	}
+	return fc.header, nil
+}
+
+func (fc *fileCredentials) Refresh(ctx context.Context) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+	fc.header = ""
+}
+
+// oauth2Credentials obtains access tokens using the OAuth2 client credentials flow, and obtains a new
+// token when the current one is about to expire or is rejected
+type oauth2Credentials struct {
+	client       *resty.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       string
+	mux          sync.Mutex
+	header       string
+	expiry       time.Time
+}
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (oc *oauth2Credentials) Authorization(ctx context.Context) (string, error) {
+	oc.mux.Lock()
+	defer oc.mux.Unlock()
+	if oc.header != "" && (oc.expiry.IsZero() || time.Now().Before(oc.expiry)) {
+		return oc.header, nil
+	}
+
+	form := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     oc.clientID,
+		"client_secret": oc.clientSecret,
+	}
+	if oc.scopes != "" {
+		form["scope"] = oc.scopes
+	}
+	var token oauth2TokenResponse
+	res, err := oc.client.R().
+		SetContext(ctx).
+		SetFormData(form).
+		SetResult(&token).
+		Post(oc.tokenURL)
+	if err != nil || !res.IsSuccess() {
+		return "", WrapRestErr(ctx, res, err, i18n.MsgCredentialsOAuth2Failed)
+	}
+	if token.AccessToken == "" {
+		return "", i18n.NewError(ctx, i18n.MsgCredentialsOAuth2NoToken, oc.tokenURL)
+	}
+
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	oc.header = fmt.Sprintf("%s %s", tokenType, token.AccessToken)
+	oc.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		oc.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - oauth2ExpiryMargin)
+	}
+	log.L(ctx).Debugf("Obtained OAuth2 access token from %s (expires_in=%ds)", oc.tokenURL, token.ExpiresIn)
+	return oc.header, nil
+}
+
+func (oc *oauth2Credentials) Refresh(ctx context.Context) {
+	oc.mux.Lock()
+	defer oc.mux.Unlock()
+	oc.header = ""
+}
+
+// credentialsTransport sets the Authorization header of each request from a credential provider. When
+// the credentials are rejected with a 401, they are refreshed and the request is sent once more.
+type credentialsTransport struct {
+	base     http.RoundTripper
+	provider CredentialProvider
+}
+
+func (ct *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := ct.send(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The body has been consumed, and cannot be sent again
+		return res, nil
+	}
+
+	ctx := req.Context()
+	log.L(ctx).Infof("Credentials rejected by %s - refreshing and retrying", req.URL.Host)
+	ct.provider.Refresh(ctx)
+	retryReq := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retryReq.Body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+	_ = res.Body.Close()
+	return ct.send(retryReq)
+}
+
+func (ct *credentialsTransport) send(req *http.Request) (*http.Response, error) {
+	authorization, err := ct.provider.Authorization(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", authorization)
+	return ct.base.RoundTrip(req)
+}
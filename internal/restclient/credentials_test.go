@@ -0,0 +1,261 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoCredentialProvider(t *testing.T) {
+	resetConf()
+	assert.Nil(t, NewCredentialProvider(utConfPrefix))
+}
+
+func TestFileCredentialsRefreshedOn401(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(tokenFile, []byte("token1\n"), 0600)
+	assert.NoError(t, err)
+
+	var bodies []string
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if req.Header.Get("Authorization") != "Bearer token2" {
+			// Rotate the token after it is rejected
+			_ = ioutil.WriteFile(tokenFile, []byte("token2\n"), 0600)
+			res.WriteHeader(401)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer svr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, svr.URL)
+	utConfPrefix.Set(HTTPConfigAuthUsername, "user")
+	utConfPrefix.Set(HTTPConfigAuthPassword, "pass")
+	utConfPrefix.Set(HTTPConfigAuthTokenFile, tokenFile)
+	c := New(context.Background(), utConfPrefix)
+
+	res, err := c.R().SetBody(map[string]string{"some": "data"}).Post("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode())
+	assert.Equal(t, []string{`{"some":"data"}`, `{"some":"data"}`}, bodies)
+
+	// The refreshed token is used from then on
+	res, err = c.R().Get("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode())
+	assert.Len(t, bodies, 3)
+}
+
+func TestFileCredentialsStillRejected(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(tokenFile, []byte("token1"), 0600)
+	assert.NoError(t, err)
+
+	calls := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(401)
+	}))
+	defer svr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, svr.URL)
+	utConfPrefix.Set(HTTPConfigAuthTokenFile, tokenFile)
+	c := New(context.Background(), utConfPrefix)
+
+	res, err := c.R().Get("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode())
+	assert.Equal(t, 2, calls)
+}
+
+func TestFileCredentialsMissing(t *testing.T) {
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigAuthTokenFile, path.Join(t.TempDir(), "missing"))
+	c := New(context.Background(), utConfPrefix)
+
+	_, err := c.R().Get("/test")
+	assert.Regexp(t, "FF10534", err)
+}
+
+func TestFileCredentialsEmpty(t *testing.T) {
+	tokenFile := path.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(tokenFile, []byte("\n"), 0600)
+	assert.NoError(t, err)
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigAuthTokenFile, tokenFile)
+	_, err = NewCredentialProvider(utConfPrefix).Authorization(context.Background())
+	assert.Regexp(t, "FF10535", err)
+}
+
+func TestOAuth2Credentials(t *testing.T) {
+	tokens := 0
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseForm())
+		assert.Equal(t, "client_credentials", req.Form.Get("grant_type"))
+		assert.Equal(t, "client1", req.Form.Get("client_id"))
+		assert.Equal(t, "secret1", req.Form.Get("client_secret"))
+		assert.Equal(t, "read write", req.Form.Get("scope"))
+		tokens++
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		fmt.Fprintf(res, `{"access_token":"token%d","token_type":"bearer","expires_in":3600}`, tokens)
+	}))
+	defer tokenSvr.Close()
+
+	var received []string
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		received = append(received, req.Header.Get("Authorization"))
+		if len(received) == 2 {
+			// Revoke the first token
+			res.WriteHeader(401)
+			return
+		}
+		res.WriteHeader(200)
+	}))
+	defer svr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, svr.URL)
+	utConfPrefix.Set(HTTPConfigAuthOAuth2TokenURL, tokenSvr.URL)
+	utConfPrefix.Set(HTTPConfigAuthOAuth2ClientID, "client1")
+	utConfPrefix.Set(HTTPConfigAuthOAuth2ClientSecret, "secret1")
+	utConfPrefix.Set(HTTPConfigAuthOAuth2Scopes, "read write")
+	c := New(context.Background(), utConfPrefix)
+
+	for i := 0; i < 3; i++ {
+		res, err := c.R().Get("/test")
+		assert.NoError(t, err)
+		assert.Equal(t, 200, res.StatusCode())
+	}
+	assert.Equal(t, []string{"Bearer token1", "Bearer token1", "Bearer token2", "Bearer token2"}, received)
+	assert.Equal(t, 2, tokens)
+}
+
+func TestOAuth2CredentialsExpired(t *testing.T) {
+	tokens := 0
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		tokens++
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		fmt.Fprintf(res, `{"access_token":"token%d","token_type":"MAC","expires_in":1}`, tokens)
+	}))
+	defer tokenSvr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigAuthOAuth2TokenURL, tokenSvr.URL)
+	provider := NewCredentialProvider(utConfPrefix)
+
+	// The token expires within the margin, so is replaced on every call
+	authorization, err := provider.Authorization(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "MAC token1", authorization)
+	authorization, err = provider.Authorization(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "MAC token2", authorization)
+}
+
+func TestOAuth2CredentialsFail(t *testing.T) {
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(400)
+		fmt.Fprint(res, `{"error":"invalid_client"}`)
+	}))
+	defer tokenSvr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigAuthOAuth2TokenURL, tokenSvr.URL)
+	_, err := NewCredentialProvider(utConfPrefix).Authorization(context.Background())
+	assert.Regexp(t, "FF10536.*invalid_client", err)
+}
+
+func TestOAuth2CredentialsNoToken(t *testing.T) {
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		fmt.Fprint(res, `{}`)
+	}))
+	defer tokenSvr.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigAuthOAuth2TokenURL, tokenSvr.URL)
+	_, err := NewCredentialProvider(utConfPrefix).Authorization(context.Background())
+	assert.Regexp(t, "FF10537", err)
+}
+
+type nonRewindableBody struct{}
+
+func (b *nonRewindableBody) Read(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func (b *nonRewindableBody) Close() error {
+	return nil
+}
+
+func TestCredentialsTransportBodyNotRewindable(t *testing.T) {
+	calls := 0
+	ct := &credentialsTransport{
+		base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 401, Body: ioutil.NopCloser(&nonRewindableBody{})}, nil
+		}),
+		provider: &fileCredentials{header: "Bearer token1"},
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:12345/test", &nonRewindableBody{})
+	res, err := ct.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCredentialsTransportGetBodyFails(t *testing.T) {
+	calls := 0
+	ct := &credentialsTransport{
+		base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: 401, Body: ioutil.NopCloser(&nonRewindableBody{})}, nil
+		}),
+		provider: &fileCredentials{header: "Bearer token1"},
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:12345/test", &nonRewindableBody{})
+	req.GetBody = func() (io.ReadCloser, error) { return nil, fmt.Errorf("pop") }
+	res, err := ct.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
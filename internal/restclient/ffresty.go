@@ -27,7 +27,9 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/circuit"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -40,6 +42,8 @@ type retryCtx struct {
 	id       string
 	start    time.Time
 	attempts uint
+	breaker  *circuit.Breaker
+	rejected bool
 }
 
 // OnAfterResponse when using SetDoNotParseResponse(true) for streming binary replies,
@@ -59,6 +63,18 @@ func OnAfterResponse(c *resty.Client, resp *resty.Response) {
 		level = logrus.ErrorLevel
 	}
 	log.L(rctx).Logf(level, "<== %s %s [%d] (%.2fms)", resp.Request.Method, resp.Request.URL, status, elapsed)
+	if rc.breaker != nil {
+		if status >= 500 {
+			rc.breaker.Failure(rctx, fmt.Sprintf("%s %s [%d]", resp.Request.Method, resp.Request.URL, status))
+		} else {
+			rc.breaker.Success(rctx)
+		}
+	}
+}
+
+// SectionName returns the name of the config section containing the HTTP client configuration
+func SectionName(staticConfig config.Prefix) string {
+	return strings.TrimSuffix(staticConfig.Resolve(HTTPConfigURL), "."+HTTPConfigURL)
 }
 
 // New creates a new Resty client, using static configuration (from the config file)
@@ -108,21 +124,50 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 
 	client.SetTimeout(staticConfig.GetDuration(HTTPConfigRequestTimeout))
 
+	// Circuits and injected faults are named after the config section of the plugin
+	name := SectionName(staticConfig)
+
+	var breaker *circuit.Breaker
+	if staticConfig.GetBool(HTTPConfigCircuitBreakerEnabled) {
+		breaker = circuit.NewBreaker(name,
+			staticConfig.GetInt(HTTPConfigCircuitBreakerFailureThreshold),
+			staticConfig.GetDuration(HTTPConfigCircuitBreakerResetTimeout))
+		// Failures where no response was received are only reported here (after any retries)
+		client.OnError(func(req *resty.Request, err error) {
+			rc := req.Context().Value(retryCtxKey{}).(*retryCtx)
+			re, isResponseErr := err.(*resty.ResponseError)
+			responded := isResponseErr && re.Response.RawResponse != nil
+			if !responded && !rc.rejected {
+				breaker.Failure(req.Context(), err.Error())
+			}
+		})
+	}
+
 	client.OnBeforeRequest(func(c *resty.Client, req *resty.Request) error {
 		rctx := req.Context()
-		rc := rctx.Value(retryCtxKey{})
+		rc, _ := rctx.Value(retryCtxKey{}).(*retryCtx)
 		if rc == nil {
 			// First attempt
-			r := &retryCtx{
-				id:    fftypes.ShortID(),
-				start: time.Now(),
+			rc = &retryCtx{
+				id:      fftypes.ShortID(),
+				start:   time.Now(),
+				breaker: breaker,
 			}
-			rctx = context.WithValue(rctx, retryCtxKey{}, r)
+			rctx = context.WithValue(rctx, retryCtxKey{}, rc)
 			// Create a request logger from the root logger passed into the client
-			l := log.L(ctx).WithField("breq", r.id)
+			l := log.L(ctx).WithField("breq", rc.id)
 			rctx = log.WithLogger(rctx, l)
 			req.SetContext(rctx)
 		}
+		if breaker != nil {
+			if err := breaker.Allow(rctx); err != nil {
+				rc.rejected = true
+				return err
+			}
+		}
+		if err := faults.Inject(rctx, name, fmt.Sprintf("%s %s", req.Method, req.URL)); err != nil {
+			return err
+		}
 		log.L(rctx).Debugf("==> %s %s%s", req.Method, url, req.URL)
 		return nil
 	})
@@ -143,6 +188,15 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 		client.SetHeader("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", authUsername, authPassword)))))
 	}
 
+	// Rotated credentials are set on each request by the transport, replacing any static credentials
+	if provider := NewCredentialProvider(staticConfig); provider != nil {
+		transport := client.GetClient().Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		client.SetTransport(&credentialsTransport{base: transport, provider: provider})
+	}
+
 	if staticConfig.GetBool(HTTPConfigRetryEnabled) {
 		retryCount := staticConfig.GetInt(HTTPConfigRetryCount)
 		minTimeout := staticConfig.GetDuration(HTTPConfigRetryInitDelay)
@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package restclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/faults"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectedFault(t *testing.T) {
+
+	ctx := context.Background()
+	scenario, err := faults.ParseScenario(ctx, "ut", []byte(`{"rules":[{"target":"^http_unit_tests$","operation":"^GET /test$","error":"pop","count":1}]}`))
+	assert.NoError(t, err)
+	faults.Activate(ctx, scenario)
+	defer faults.Activate(ctx, nil)
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+
+	c := New(ctx, utConfPrefix)
+	httpmock.ActivateNonDefault(c.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		httpmock.NewStringResponder(200, `{}`))
+
+	// The injected fault fails the call without it being made
+	_, err = c.R().Get("/test")
+	assert.Regexp(t, "FF10453.*pop", err)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+
+	resp, err := c.R().Get("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode())
+
+}
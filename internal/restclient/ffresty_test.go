@@ -22,9 +22,12 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/circuit"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -159,3 +162,75 @@ func TestErrResponse(t *testing.T) {
 func TestOnAfterResponseNil(t *testing.T) {
 	OnAfterResponse(nil, nil)
 }
+
+func TestCircuitBreakerOpensAndCloses(t *testing.T) {
+
+	ctx := context.Background()
+	circuit.Reset()
+	defer circuit.Reset()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigCircuitBreakerEnabled, true)
+	utConfPrefix.Set(HTTPConfigCircuitBreakerFailureThreshold, 2)
+	utConfPrefix.Set(HTTPConfigCircuitBreakerResetTimeout, "1ms")
+
+	c := New(ctx, utConfPrefix)
+	httpmock.ActivateNonDefault(c.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		httpmock.NewStringResponder(500, `{"message": "pop"}`))
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.R().Get("/test")
+		assert.NoError(t, err)
+		assert.Equal(t, 500, resp.StatusCode())
+	}
+	statuses := circuit.Statuses()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "http_unit_tests", statuses[0].Name)
+	assert.Equal(t, fftypes.CircuitStateOpen, statuses[0].State)
+	assert.Regexp(t, "GET .*/test \\[500\\]", statuses[0].LastError)
+
+	// Wait for the reset timeout, then a successful probe closes the circuit
+	time.Sleep(5 * time.Millisecond)
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		httpmock.NewStringResponder(200, `{}`))
+	resp, err := c.R().Get("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode())
+	assert.Equal(t, 3, httpmock.GetTotalCallCount())
+	assert.Equal(t, fftypes.CircuitStateClosed, circuit.Statuses()[0].State)
+
+}
+
+func TestCircuitBreakerRejectsWhenOpen(t *testing.T) {
+
+	ctx := context.Background()
+	circuit.Reset()
+	defer circuit.Reset()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigCircuitBreakerEnabled, true)
+	utConfPrefix.Set(HTTPConfigCircuitBreakerFailureThreshold, 1)
+
+	c := New(ctx, utConfPrefix)
+	httpmock.ActivateNonDefault(c.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		httpmock.NewErrorResponder(fmt.Errorf("pop")))
+
+	_, err := c.R().Get("/test")
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, fftypes.CircuitStateOpen, circuit.Statuses()[0].State)
+
+	// Rejected calls are not attempted, and do not count as further failures
+	_, err = c.R().Get("/test")
+	assert.Regexp(t, "FF10388", err)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Equal(t, 1, circuit.Statuses()[0].ConsecutiveFailures)
+
+}
@@ -0,0 +1,146 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/secrets/vault"
+)
+
+// SecretsConfigVaultSubconf is the configuration of the Vault provider, used for ${vault:...} references
+const SecretsConfigVaultSubconf = "vault"
+
+// Provider resolves the secret references for one scheme, such as "${env:MY_VAR}"
+type Provider interface {
+	// Scheme is the part before the colon in the references this provider resolves
+	Scheme() string
+
+	// Resolve returns the secret at the part of the reference after the colon
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+type envProvider struct{}
+
+func (p *envProvider) Scheme() string {
+	return "env"
+}
+
+func (p *envProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", i18n.NewError(ctx, i18n.MsgSecretEnvNotSet, ref)
+	}
+	return value, nil
+}
+
+type fileProvider struct{}
+
+func (p *fileProvider) Scheme() string {
+	return "file"
+}
+
+func (p *fileProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	b, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, i18n.MsgSecretFileReadFailed, ref)
+	}
+	// Files such as Kubernetes and Docker secrets commonly have a trailing newline
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// InitPrefix registers the configuration of the secrets providers
+func InitPrefix(prefix config.Prefix) {
+	(&vault.Vault{}).InitPrefix(prefix.SubPrefix(SecretsConfigVaultSubconf))
+}
+
+type resolver struct {
+	prefix    config.Prefix
+	providers map[string]Provider
+	vault     *vault.Vault
+}
+
+// ResolveConfig replaces every ${env:...}, ${file:...} and ${vault:...} secret reference in the configuration
+// with the secret it refers to. Vault is only contacted if the configuration contains vault references.
+func ResolveConfig(ctx context.Context, prefix config.Prefix) error {
+	r := &resolver{
+		prefix:    prefix,
+		providers: map[string]Provider{},
+	}
+	for _, p := range []Provider{&envProvider{}, &fileProvider{}, &vault.Vault{}} {
+		r.providers[p.Scheme()] = p
+	}
+	return config.ResolveSecrets(ctx, r.resolve)
+}
+
+// parseRef only treats a value as a reference if the whole value is in the explicit ${scheme:ref} syntax,
+// so that ordinary values such as SQLite "file:" data sources are never mistaken for secrets
+func (r *resolver) parseRef(value string) (Provider, string, bool) {
+	if !strings.HasPrefix(value, "${") || !strings.HasSuffix(value, "}") {
+		return nil, "", false
+	}
+	value = value[2 : len(value)-1]
+	i := strings.Index(value, ":")
+	if i < 0 {
+		return nil, "", false
+	}
+	p, ok := r.providers[value[0:i]]
+	return p, value[i+1:], ok
+}
+
+func (r *resolver) initVault(ctx context.Context) error {
+	vaultPrefix := r.prefix.SubPrefix(SecretsConfigVaultSubconf)
+	token := vaultPrefix.GetString(vault.VaultConfigToken)
+	if p, ref, isRef := r.parseRef(token); isRef && p.Scheme() != "vault" {
+		var err error
+		if token, err = p.Resolve(ctx, ref); err != nil {
+			return err
+		}
+	}
+	r.vault = &vault.Vault{}
+	if err := r.vault.Init(ctx, vaultPrefix, token); err != nil {
+		r.vault = nil
+		return err
+	}
+	r.providers[r.vault.Scheme()] = r.vault
+	return nil
+}
+
+func (r *resolver) resolve(ctx context.Context, key, value string) (string, bool, error) {
+	p, ref, isRef := r.parseRef(value)
+	if !isRef {
+		return value, false, nil
+	}
+	if p.Scheme() == "vault" && r.vault == nil {
+		if err := r.initVault(ctx); err != nil {
+			return "", true, i18n.WrapError(ctx, err, i18n.MsgSecretResolveFailed, key)
+		}
+		p = r.vault
+	}
+	secret, err := p.Resolve(ctx, ref)
+	if err != nil {
+		return "", true, i18n.WrapError(ctx, err, i18n.MsgSecretResolveFailed, key)
+	}
+	log.L(ctx).Debugf("Resolved %s secret for config key '%s'", p.Scheme(), key)
+	return secret, true, nil
+}
@@ -0,0 +1,163 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/secrets/vault"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("secrets_unit_tests")
+var utVaultPrefix = utConfPrefix.SubPrefix(SecretsConfigVaultSubconf)
+
+// sorts before utConfPrefix, so references are resolved before the Vault token
+var utTargetPrefix = config.NewPluginConfig("secrets_target_unit_tests")
+
+func resetConf() {
+	config.Reset()
+	InitPrefix(utConfPrefix)
+	utTargetPrefix.AddKnownKey("env")
+	utTargetPrefix.AddKnownKey("file")
+	utTargetPrefix.AddKnownKey("vault")
+	utTargetPrefix.AddKnownKey("plain")
+	utTargetPrefix.AddKnownKey("sqlite")
+	utTargetPrefix.AddKnownKey("unknown")
+	utTargetPrefix.AddKnownKey("partial")
+	utTargetPrefix.AddKnownKey("bare")
+	utTargetPrefix.AddKnownKey("noscheme")
+}
+
+func TestResolveConfigEnvAndFile(t *testing.T) {
+	resetConf()
+
+	os.Setenv("FF_UT_SECRET", "secret1")
+	defer os.Unsetenv("FF_UT_SECRET")
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretFile := path.Join(dir, "secret")
+	err = ioutil.WriteFile(secretFile, []byte("secret2\n"), 0600)
+	assert.NoError(t, err)
+
+	utTargetPrefix.Set("env", "${env:FF_UT_SECRET}")
+	utTargetPrefix.Set("file", "${file:"+secretFile+"}")
+	utTargetPrefix.Set("plain", "http://localhost:12345")
+	utTargetPrefix.Set("sqlite", "file:/data/firefly.db?_busy_timeout=5000")
+	utTargetPrefix.Set("unknown", "${other:value}")
+	utTargetPrefix.Set("partial", "${env:FF_UT_SECRET")
+	utTargetPrefix.Set("bare", "env:FF_UT_SECRET")
+	utTargetPrefix.Set("noscheme", "${FF_UT_SECRET}")
+
+	err = ResolveConfig(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret1", utTargetPrefix.GetString("env"))
+	assert.Equal(t, "secret2", utTargetPrefix.GetString("file"))
+	assert.Equal(t, "http://localhost:12345", utTargetPrefix.GetString("plain"))
+	assert.Equal(t, "file:/data/firefly.db?_busy_timeout=5000", utTargetPrefix.GetString("sqlite"))
+	assert.Equal(t, "${other:value}", utTargetPrefix.GetString("unknown"))
+	assert.Equal(t, "${env:FF_UT_SECRET", utTargetPrefix.GetString("partial"))
+	assert.Equal(t, "env:FF_UT_SECRET", utTargetPrefix.GetString("bare"))
+	assert.Equal(t, "${FF_UT_SECRET}", utTargetPrefix.GetString("noscheme"))
+}
+
+func TestResolveConfigEnvNotSet(t *testing.T) {
+	resetConf()
+	utTargetPrefix.Set("env", "${env:FF_UT_UNSET_SECRET}")
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10439.*secrets_target_unit_tests.env.*FF10435.*FF_UT_UNSET_SECRET", err)
+}
+
+func TestResolveConfigFileMissing(t *testing.T) {
+	resetConf()
+	utTargetPrefix.Set("file", "${file:/does/not/exist}")
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10439.*FF10436.*/does/not/exist", err)
+}
+
+func TestResolveConfigVault(t *testing.T) {
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	os.Setenv("FF_UT_VAULT_TOKEN", "token1")
+	defer os.Unsetenv("FF_UT_VAULT_TOKEN")
+	utVaultPrefix.Set(restclient.HTTPConfigURL, "http://vault:8200")
+	utVaultPrefix.Set(restclient.HTTPCustomClient, mockedClient)
+	utVaultPrefix.Set(vault.VaultConfigToken, "${env:FF_UT_VAULT_TOKEN}")
+	utTargetPrefix.Set("vault", "${vault:firefly/db#password}")
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "token1", req.Header.Get("X-Vault-Token"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data": map[string]interface{}{
+						"password": "secret3",
+					},
+				},
+			})(req)
+		})
+
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret3", utTargetPrefix.GetString("vault"))
+	assert.Equal(t, "***", config.GetConfig().GetObject("secrets_target_unit_tests").GetString("vault"))
+}
+
+func TestResolveConfigVaultTokenFail(t *testing.T) {
+	resetConf()
+	utVaultPrefix.Set(vault.VaultConfigToken, "${env:FF_UT_UNSET_SECRET}")
+	utTargetPrefix.Set("vault", "${vault:firefly/db#password}")
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10439.*FF10435", err)
+}
+
+func TestResolveConfigVaultNotConfigured(t *testing.T) {
+	resetConf()
+	utVaultPrefix.Set(vault.VaultConfigToken, "${vault:not/allowed}")
+	utTargetPrefix.Set("vault", "${vault:firefly/db#password}")
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10439.*FF10138", err)
+}
+
+func TestResolveConfigVaultFail(t *testing.T) {
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	utVaultPrefix.Set(restclient.HTTPConfigURL, "http://vault:8200")
+	utVaultPrefix.Set(restclient.HTTPCustomClient, mockedClient)
+	utTargetPrefix.Set("vault", "${vault:firefly/db#password}")
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		httpmock.NewStringResponder(404, `{"errors":[]}`))
+
+	err := ResolveConfig(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10439.*FF10437", err)
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+const (
+	defaultMount     = "secret"
+	defaultKVVersion = 2
+)
+
+const (
+	// VaultConfigToken is the token used to authenticate to Vault - this can be an env: or file: secret reference
+	VaultConfigToken = "token"
+	// VaultConfigNamespace is the Vault Enterprise namespace to read secrets from
+	VaultConfigNamespace = "namespace"
+	// VaultConfigMount is the path the KV secrets engine is mounted at
+	VaultConfigMount = "mount"
+	// VaultConfigKVVersion is the version of the KV secrets engine - 1 or 2
+	VaultConfigKVVersion = "kvVersion"
+)
+
+func (v *Vault) InitPrefix(prefix config.Prefix) {
+	restclient.InitPrefix(prefix)
+	prefix.AddKnownKey(VaultConfigToken)
+	prefix.AddKnownKey(VaultConfigNamespace)
+	prefix.AddKnownKey(VaultConfigMount, defaultMount)
+	prefix.AddKnownKey(VaultConfigKVVersion, defaultKVVersion)
+}
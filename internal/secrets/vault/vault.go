@@ -0,0 +1,96 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+// defaultField is the field of the secret that is used, when the reference does not specify one
+const defaultField = "value"
+
+// Vault reads secrets from the KV secrets engine of HashiCorp Vault
+type Vault struct {
+	client    *resty.Client
+	mount     string
+	kvVersion int
+}
+
+type kvResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+func (v *Vault) Scheme() string {
+	return "vault"
+}
+
+// Init connects to Vault - the token is passed separately, as it might itself have been a secret reference
+func (v *Vault) Init(ctx context.Context, prefix config.Prefix, token string) error {
+	if prefix.GetString(restclient.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(restclient.HTTPConfigURL), "vault")
+	}
+	v.client = restclient.New(log.WithLogField(ctx, "secrets", "vault"), prefix)
+	v.client.SetHeader("X-Vault-Token", token)
+	if namespace := prefix.GetString(VaultConfigNamespace); namespace != "" {
+		v.client.SetHeader("X-Vault-Namespace", namespace)
+	}
+	v.mount = strings.Trim(prefix.GetString(VaultConfigMount), "/")
+	v.kvVersion = prefix.GetInt(VaultConfigKVVersion)
+	return nil
+}
+
+// Resolve reads a field of a secret, referenced as "path/to/secret#field"
+func (v *Vault) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field := ref, defaultField
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		path, field = ref[0:i], ref[i+1:]
+	}
+	path = strings.Trim(path, "/")
+
+	url := fmt.Sprintf("/v1/%s/%s", v.mount, path)
+	if v.kvVersion >= 2 {
+		url = fmt.Sprintf("/v1/%s/data/%s", v.mount, path)
+	}
+	var kv kvResponse
+	res, err := v.client.R().
+		SetContext(ctx).
+		SetResult(&kv).
+		Get(url)
+	if err != nil || !res.IsSuccess() {
+		return "", restclient.WrapRestErr(ctx, res, err, i18n.MsgVaultRESTErr)
+	}
+
+	data := kv.Data
+	if v.kvVersion >= 2 {
+		// Version 2 wraps the secret data alongside its metadata
+		data, _ = kv.Data["data"].(map[string]interface{})
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", i18n.NewError(ctx, i18n.MsgVaultSecretFieldMissing, path, field)
+	}
+	log.L(ctx).Debugf("Read secret '%s' from Vault", path)
+	return value, nil
+}
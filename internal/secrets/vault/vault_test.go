@@ -0,0 +1,132 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("vault_unit_tests")
+
+func resetConf() {
+	config.Reset()
+	v := &Vault{}
+	v.InitPrefix(utConfPrefix)
+}
+
+func newTestVault(t *testing.T) (*Vault, func()) {
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+
+	resetConf()
+	utConfPrefix.Set(restclient.HTTPConfigURL, "http://vault:8200")
+	utConfPrefix.Set(restclient.HTTPCustomClient, mockedClient)
+	utConfPrefix.Set(VaultConfigNamespace, "ns1")
+
+	v := &Vault{}
+	err := v.Init(context.Background(), utConfPrefix, "token1")
+	assert.NoError(t, err)
+	assert.Equal(t, "vault", v.Scheme())
+	return v, httpmock.DeactivateAndReset
+}
+
+func TestInitMissingURL(t *testing.T) {
+	resetConf()
+	v := &Vault{}
+	err := v.Init(context.Background(), utConfPrefix, "token1")
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestResolveKVv2(t *testing.T) {
+	v, done := newTestVault(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "token1", req.Header.Get("X-Vault-Token"))
+			assert.Equal(t, "ns1", req.Header.Get("X-Vault-Namespace"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data": map[string]interface{}{
+						"password": "pass1",
+						"value":    "default1",
+					},
+				},
+			})(req)
+		})
+
+	secret, err := v.Resolve(context.Background(), "/firefly/db#password")
+	assert.NoError(t, err)
+	assert.Equal(t, "pass1", secret)
+
+	secret, err = v.Resolve(context.Background(), "firefly/db")
+	assert.NoError(t, err)
+	assert.Equal(t, "default1", secret)
+}
+
+func TestResolveKVv1(t *testing.T) {
+	v, done := newTestVault(t)
+	defer done()
+	v.kvVersion = 1
+	v.mount = "kv"
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/kv/firefly/db",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"data": map[string]interface{}{
+				"password": "pass1",
+			},
+		}))
+
+	secret, err := v.Resolve(context.Background(), "firefly/db#password")
+	assert.NoError(t, err)
+	assert.Equal(t, "pass1", secret)
+}
+
+func TestResolveMissingField(t *testing.T) {
+	v, done := newTestVault(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{
+					"password": 12345,
+				},
+			},
+		}))
+
+	_, err := v.Resolve(context.Background(), "firefly/db#password")
+	assert.Regexp(t, "FF10438.*firefly/db.*password", err)
+}
+
+func TestResolveError(t *testing.T) {
+	v, done := newTestVault(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		httpmock.NewStringResponder(403, `{"errors":["permission denied"]}`))
+
+	_, err := v.Resolve(context.Background(), "firefly/db#password")
+	assert.Regexp(t, "FF10437.*permission denied", err)
+}
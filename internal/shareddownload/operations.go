@@ -169,8 +169,10 @@ func (dm *downloadManager) downloadBlob(ctx context.Context, data downloadBlobDa
 
 func opDownloadBatch(op *fftypes.Operation, ns string, payloadRef string) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
 		Data: downloadBatchData{
 			Namespace:  ns,
 			PayloadRef: payloadRef,
@@ -180,8 +182,10 @@ func opDownloadBatch(op *fftypes.Operation, ns string, payloadRef string) *fftyp
 
 func opDownloadBlob(op *fftypes.Operation, ns string, dataID *fftypes.UUID, payloadRef string) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
-		ID:   op.ID,
-		Type: op.Type,
+		ID:          op.ID,
+		Namespace:   op.Namespace,
+		Transaction: op.Transaction,
+		Type:        op.Type,
 		Data: downloadBlobData{
 			Namespace:  ns,
 			DataID:     dataID,
@@ -19,6 +19,8 @@ package fftokens
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
@@ -39,6 +41,8 @@ type FFTokens struct {
 	configuredName string
 	client         *resty.Client
 	wsconn         wsclient.WSClient
+	protocolMux    sync.Mutex
+	protocol       *negotiatedProtocol
 }
 
 type wsEvent struct {
@@ -56,6 +60,7 @@ const (
 	messageTokenBurn     msgType = "token-burn"
 	messageTokenTransfer msgType = "token-transfer"
 	messageTokenApproval msgType = "token-approval"
+	messageTokenBackfill msgType = "token-pool-backfill"
 )
 
 type tokenData struct {
@@ -75,6 +80,11 @@ type createPool struct {
 	Symbol    string             `json:"symbol"`
 }
 
+type deployPool struct {
+	createPool
+	Params *fftypes.JSONAny `json:"params,omitempty"`
+}
+
 type tokenApproval struct {
 	Signer    string             `json:"signer"`
 	Operator  string             `json:"operator"`
@@ -82,7 +92,7 @@ type tokenApproval struct {
 	PoolID    string             `json:"poolId"`
 	RequestID string             `json:"requestId,omitempty"`
 	Data      string             `json:"data,omitempty"`
-	Config    fftypes.JSONObject `json:"config"`
+	Config    fftypes.JSONObject `json:"config,omitempty"`
 }
 
 type activatePool struct {
@@ -90,6 +100,7 @@ type activatePool struct {
 	PoolConfig  fftypes.JSONObject `json:"poolConfig"`
 	Transaction fftypes.JSONObject `json:"transaction"`
 	RequestID   string             `json:"requestId,omitempty"`
+	FromBlock   string             `json:"fromBlock,omitempty"`
 }
 
 type mintTokens struct {
@@ -112,6 +123,11 @@ type burnTokens struct {
 	Data       string `json:"data,omitempty"`
 }
 
+type connectorCapabilities struct {
+	fftypes.TokenConnectorCapabilities
+	Standards []string `json:"standards"`
+}
+
 type transferTokens struct {
 	PoolID     string `json:"poolId"`
 	TokenIndex string `json:"tokenIndex,omitempty"`
@@ -123,12 +139,28 @@ type transferTokens struct {
 	Data       string `json:"data,omitempty"`
 }
 
+type batchTransfer struct {
+	Type       fftypes.TokenTransferType `json:"type"`
+	PoolID     string                    `json:"poolId"`
+	TokenIndex string                    `json:"tokenIndex,omitempty"`
+	From       string                    `json:"from,omitempty"`
+	To         string                    `json:"to,omitempty"`
+	Amount     string                    `json:"amount"`
+	RequestID  string                    `json:"requestId"`
+	Signer     string                    `json:"signer"`
+	Data       string                    `json:"data,omitempty"`
+}
+
+type batchTransfers struct {
+	Requests []*batchTransfer `json:"requests"`
+}
+
 func (ft *FFTokens) Name() string {
 	return "fftokens"
 }
 
 func (ft *FFTokens) Init(ctx context.Context, name string, prefix config.Prefix, callbacks tokens.Callbacks) (err error) {
-	ft.ctx = log.WithLogField(ctx, "proto", "fftokens")
+	ft.ctx = log.WithComponent(log.WithLogField(ctx, "proto", "fftokens"), log.ComponentFFTokens)
 	ft.callbacks = callbacks
 	ft.configuredName = name
 
@@ -145,11 +177,13 @@ func (ft *FFTokens) Init(ctx context.Context, name string, prefix config.Prefix,
 		wsConfig.WSKeyPath = "/api/ws"
 	}
 
-	ft.wsconn, err = wsclient.New(ctx, wsConfig, nil, nil)
+	ft.wsconn, err = wsclient.New(ctx, wsConfig, nil, ft.afterConnect)
 	if err != nil {
 		return err
 	}
 
+	ft.negotiateProtocol(ft.ctx)
+
 	go ft.eventLoop()
 
 	return nil
@@ -159,10 +193,40 @@ func (ft *FFTokens) Start() error {
 	return ft.wsconn.Connect()
 }
 
+// afterConnect repeats the protocol version handshake each time the WebSocket connects, as the connector
+// may have been upgraded (or downgraded) while it was disconnected
+func (ft *FFTokens) afterConnect(ctx context.Context, w wsclient.WSClient) error {
+	ft.negotiateProtocol(ctx)
+	return nil
+}
+
 func (ft *FFTokens) Capabilities() *tokens.Capabilities {
 	return ft.capabilities
 }
 
+func (ft *FFTokens) Health(ctx context.Context) error {
+	if state := ft.wsconn.State(); state != wsclient.WSStateConnected {
+		return i18n.NewError(ctx, i18n.MsgPluginNotConnected, ft.Name(), state)
+	}
+	return nil
+}
+
+func (ft *FFTokens) ConnectorCapabilities(ctx context.Context) (*fftypes.TokenConnectorCapabilities, []string, error) {
+	var caps connectorCapabilities
+	res, err := ft.client.R().SetContext(ctx).
+		SetResult(&caps).
+		Get("/api/v1/capabilities")
+	if err == nil && res.StatusCode() == http.StatusNotFound {
+		// Older connectors do not implement the capabilities query
+		log.L(ctx).Debugf("Token connector '%s' does not report its capabilities", ft.configuredName)
+		return nil, nil, nil
+	}
+	if err != nil || !res.IsSuccess() {
+		return nil, nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgTokensRESTErr)
+	}
+	return &caps.TokenConnectorCapabilities, caps.Standards, nil
+}
+
 func (ft *FFTokens) handleReceipt(ctx context.Context, data fftypes.JSONObject) error {
 	l := log.L(ctx)
 
@@ -179,6 +243,7 @@ func (ft *FFTokens) handleReceipt(ctx context.Context, data fftypes.JSONObject)
 		l.Errorf("Reply cannot be processed - bad ID: %+v", data)
 		return nil // Swallow this and move on
 	}
+	l = log.L(log.WithOperation(ctx, "", "", requestID))
 	replyType := fftypes.OpStatusSucceeded
 	if !success {
 		replyType = fftypes.OpStatusFailed
@@ -254,6 +319,23 @@ func (ft *FFTokens) handleTokenPoolCreate(ctx context.Context, data fftypes.JSON
 	return ft.callbacks.TokenPoolCreated(ft, pool)
 }
 
+func (ft *FFTokens) handleTokenPoolBackfill(ctx context.Context, data fftypes.JSONObject) (err error) {
+	poolProtocolID := data.GetString("poolId")
+	if poolProtocolID == "" {
+		log.L(ctx).Errorf("TokenPoolBackfill event is not valid - missing data: %+v", data)
+		return nil // move on
+	}
+
+	// If there's an error dispatching the event, we must return the error and shutdown
+	return ft.callbacks.TokenPoolBackfillProgress(ft, &tokens.TokenPoolBackfillProgress{
+		PoolProtocolID: poolProtocolID,
+		Connector:      ft.configuredName,
+		Block:          data.GetString("blockNumber"),
+		Transfers:      data.GetInt64("transfers"),
+		Complete:       data.GetBool("complete"),
+	})
+}
+
 func (ft *FFTokens) handleTokenTransfer(ctx context.Context, t fftypes.TokenTransferType, data fftypes.JSONObject) (err error) {
 	eventProtocolID := data.GetString("id")
 	poolProtocolID := data.GetString("poolId")
@@ -457,6 +539,8 @@ func (ft *FFTokens) eventLoop() {
 				err = ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeTransfer, msg.Data)
 			case messageTokenApproval:
 				err = ft.handleTokenApproval(ctx, msg.Data)
+			case messageTokenBackfill:
+				err = ft.handleTokenPoolBackfill(ctx, msg.Data)
 			default:
 				l.Errorf("Message unexpected: %s", msg.Event)
 			}
@@ -480,22 +564,41 @@ func (ft *FFTokens) eventLoop() {
 	}
 }
 
-func (ft *FFTokens) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
+func (ft *FFTokens) newCreatePool(opID *fftypes.UUID, pool *fftypes.TokenPool) createPool {
 	data, _ := json.Marshal(tokenData{
 		TX:     pool.TX.ID,
 		TXType: pool.TX.Type,
 	})
+	return createPool{
+		Type:      pool.Type,
+		RequestID: opID.String(),
+		Signer:    pool.Key,
+		Data:      string(data),
+		Config:    pool.Config,
+		Name:      pool.Name,
+		Symbol:    pool.Symbol,
+	}
+}
+
+func (ft *FFTokens) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
+	body := ft.newCreatePool(opID, pool)
+	return ft.submitTokenPool(ctx, "/api/v1/createpool", &body)
+}
+
+func (ft *FFTokens) DeployTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
+	body := &deployPool{
+		createPool: ft.newCreatePool(opID, pool),
+	}
+	if pool.Deploy != nil {
+		body.Params = pool.Deploy.Params
+	}
+	return ft.submitTokenPool(ctx, "/api/v1/deploypool", body)
+}
+
+func (ft *FFTokens) submitTokenPool(ctx context.Context, path string, body interface{}) (complete bool, err error) {
 	res, err := ft.client.R().SetContext(ctx).
-		SetBody(&createPool{
-			Type:      pool.Type,
-			RequestID: opID.String(),
-			Signer:    pool.Key,
-			Data:      string(data),
-			Config:    pool.Config,
-			Name:      pool.Name,
-			Symbol:    pool.Symbol,
-		}).
-		Post("/api/v1/createpool")
+		SetBody(body).
+		Post(path)
 	if err != nil || !res.IsSuccess() {
 		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgTokensRESTErr)
 	}
@@ -511,13 +614,21 @@ func (ft *FFTokens) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, poo
 }
 
 func (ft *FFTokens) ActivateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool, blockchainInfo fftypes.JSONObject) (complete bool, err error) {
+	body := &activatePool{
+		RequestID:   opID.String(),
+		PoolID:      pool.ProtocolID,
+		PoolConfig:  pool.Config,
+		Transaction: blockchainInfo,
+	}
+	if pool.Backfill != nil && pool.Backfill.State != fftypes.TokenPoolBackfillStateComplete {
+		// The connector replays the historical transfers from this block, before delivering new ones
+		if err := ft.checkFeature(ctx, featurePoolBackfill); err != nil {
+			return false, err
+		}
+		body.FromBlock = pool.Backfill.FromBlock
+	}
 	res, err := ft.client.R().SetContext(ctx).
-		SetBody(&activatePool{
-			RequestID:   opID.String(),
-			PoolID:      pool.ProtocolID,
-			PoolConfig:  pool.Config,
-			Transaction: blockchainInfo,
-		}).
+		SetBody(body).
 		Post("/api/v1/activatepool")
 	if err != nil || !res.IsSuccess() {
 		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgTokensRESTErr)
@@ -606,7 +717,50 @@ func (ft *FFTokens) TransferTokens(ctx context.Context, opID *fftypes.UUID, pool
 	return nil
 }
 
+func (ft *FFTokens) TransferTokensBatch(ctx context.Context, requests []*tokens.TokenTransferRequest) error {
+	body := &batchTransfers{
+		Requests: make([]*batchTransfer, len(requests)),
+	}
+	for i, req := range requests {
+		transfer := req.Transfer
+		data, _ := json.Marshal(tokenData{
+			TX:          transfer.TX.ID,
+			TXType:      transfer.TX.Type,
+			Message:     transfer.Message,
+			MessageHash: transfer.MessageHash,
+		})
+		bt := &batchTransfer{
+			Type:       transfer.Type,
+			PoolID:     req.PoolProtocolID,
+			TokenIndex: transfer.TokenIndex,
+			Amount:     transfer.Amount.Int().String(),
+			RequestID:  req.OpID.String(),
+			Signer:     transfer.Key,
+			Data:       string(data),
+		}
+		if transfer.Type != fftypes.TokenTransferTypeMint {
+			bt.From = transfer.From
+		}
+		if transfer.Type != fftypes.TokenTransferTypeBurn {
+			bt.To = transfer.To
+		}
+		body.Requests[i] = bt
+	}
+	res, err := ft.client.R().SetContext(ctx).
+		SetBody(body).
+		Post("/api/v1/batch")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgTokensRESTErr)
+	}
+	return nil
+}
+
 func (ft *FFTokens) TokensApproval(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, approval *fftypes.TokenApproval) error {
+	if len(approval.Config) > 0 {
+		if err := ft.checkFeature(ctx, featureApprovalConfig); err != nil {
+			return err
+		}
+	}
 	data, _ := json.Marshal(tokenData{
 		TX:     approval.TX.ID,
 		TXType: approval.TX.Type,
@@ -151,6 +151,80 @@ func TestCreateTokenPool(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeployTokenPool(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	opID := fftypes.NewUUID()
+	pool := &fftypes.TokenPool{
+		ID: fftypes.NewUUID(),
+		TX: fftypes.TransactionRef{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.TransactionTypeTokenPool,
+		},
+		Namespace: "ns1",
+		Name:      "new-pool",
+		Type:      "fungible",
+		Key:       "0x123",
+		Symbol:    "symbol",
+		Deploy: &fftypes.TokenPoolDeploy{
+			Params: fftypes.JSONAnyPtr(`["FFC","FireFly Coin"]`),
+		},
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/deploypool", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			body := make(fftypes.JSONObject)
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, fftypes.JSONObject{
+				"requestId": opID.String(),
+				"signer":    "0x123",
+				"type":      "fungible",
+				"config":    nil,
+				"data": fftypes.JSONObject{
+					"tx":     pool.TX.ID.String(),
+					"txtype": fftypes.TransactionTypeTokenPool.String(),
+				}.String(),
+				"name":   "new-pool",
+				"symbol": "symbol",
+				"params": []interface{}{"FFC", "FireFly Coin"},
+			}, body)
+
+			res := &http.Response{
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"id":"1"}`))),
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				StatusCode: 202,
+			}
+			return res, nil
+		})
+
+	complete, err := h.DeployTokenPool(context.Background(), opID, pool)
+	assert.False(t, complete)
+	assert.NoError(t, err)
+}
+
+func TestDeployTokenPoolNoParams(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	pool := &fftypes.TokenPool{
+		Name: "new-pool",
+		Type: "fungible",
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/deploypool", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{
+			"errors": []string{"deployment failed"},
+		}))
+
+	complete, err := h.DeployTokenPool(context.Background(), fftypes.NewUUID(), pool)
+	assert.False(t, complete)
+	assert.Regexp(t, "FF10274", err)
+}
+
 func TestCreateTokenPoolError(t *testing.T) {
 	h, _, _, httpURL, done := newTestFFTokens(t)
 	defer done()
@@ -309,6 +383,50 @@ func TestActivateTokenPool(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestActivateTokenPoolBackfill(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	opID := fftypes.NewUUID()
+	txInfo := map[string]interface{}{
+		"foo": "bar",
+	}
+	pool := &fftypes.TokenPool{
+		ProtocolID: "N1",
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "1000",
+			State:     fftypes.TokenPoolBackfillStatePending,
+		},
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/activatepool", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			body := make(fftypes.JSONObject)
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, fftypes.JSONObject{
+				"requestId":   opID.String(),
+				"poolId":      "N1",
+				"poolConfig":  nil,
+				"transaction": txInfo,
+				"fromBlock":   "1000",
+			}, body)
+
+			res := &http.Response{
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"id":"1"}`))),
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				StatusCode: 202,
+			}
+			return res, nil
+		})
+
+	complete, err := h.ActivateTokenPool(context.Background(), opID, pool, txInfo)
+	assert.False(t, complete)
+	assert.NoError(t, err)
+}
+
 func TestActivateTokenPoolError(t *testing.T) {
 	h, _, _, httpURL, done := newTestFFTokens(t)
 	defer done()
@@ -542,6 +660,100 @@ func TestTokenApprovalError(t *testing.T) {
 	assert.Regexp(t, "FF10274", err)
 }
 
+func TestTransferTokensBatch(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	txID := fftypes.NewUUID()
+	tx := fftypes.TransactionRef{
+		ID:   txID,
+		Type: fftypes.TransactionTypeTokenTransfer,
+	}
+	opID1 := fftypes.NewUUID()
+	opID2 := fftypes.NewUUID()
+	opID3 := fftypes.NewUUID()
+	requests := []*tokens.TokenTransferRequest{
+		{OpID: opID1, PoolProtocolID: "123", Transfer: &fftypes.TokenTransfer{
+			Type: fftypes.TokenTransferTypeMint, From: "0x123", To: "user1", Key: "0x123", Amount: *fftypes.NewFFBigInt(10), TX: tx,
+		}},
+		{OpID: opID2, PoolProtocolID: "123", Transfer: &fftypes.TokenTransfer{
+			Type: fftypes.TokenTransferTypeTransfer, From: "user1", To: "user2", Key: "0x123", Amount: *fftypes.NewFFBigInt(5), TX: tx,
+		}},
+		{OpID: opID3, PoolProtocolID: "456", Transfer: &fftypes.TokenTransfer{
+			Type: fftypes.TokenTransferTypeBurn, TokenIndex: "1", From: "user2", To: "user2", Key: "0x123", Amount: *fftypes.NewFFBigInt(1), TX: tx,
+		}},
+	}
+	data := fftypes.JSONObject{
+		"tx":     txID.String(),
+		"txtype": fftypes.TransactionTypeTokenTransfer.String(),
+	}.String()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/batch", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			body := make(fftypes.JSONObject)
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, fftypes.JSONObject{
+				"requests": []interface{}{
+					map[string]interface{}{
+						"type":      "mint",
+						"poolId":    "123",
+						"to":        "user1",
+						"amount":    "10",
+						"signer":    "0x123",
+						"requestId": opID1.String(),
+						"data":      data,
+					},
+					map[string]interface{}{
+						"type":      "transfer",
+						"poolId":    "123",
+						"from":      "user1",
+						"to":        "user2",
+						"amount":    "5",
+						"signer":    "0x123",
+						"requestId": opID2.String(),
+						"data":      data,
+					},
+					map[string]interface{}{
+						"type":       "burn",
+						"poolId":     "456",
+						"tokenIndex": "1",
+						"from":       "user2",
+						"amount":     "1",
+						"signer":     "0x123",
+						"requestId":  opID3.String(),
+						"data":       data,
+					},
+				},
+			}, body)
+
+			res := &http.Response{
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				StatusCode: 202,
+			}
+			return res, nil
+		})
+
+	err := h.TransferTokensBatch(context.Background(), requests)
+	assert.NoError(t, err)
+}
+
+func TestTransferTokensBatchError(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/batch", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	err := h.TransferTokensBatch(context.Background(), []*tokens.TokenTransferRequest{
+		{OpID: fftypes.NewUUID(), Transfer: &fftypes.TokenTransfer{Type: fftypes.TokenTransferTypeMint}},
+	})
+	assert.Regexp(t, "FF10274", err)
+}
+
 func TestMintTokensError(t *testing.T) {
 	h, _, _, httpURL, done := newTestFFTokens(t)
 	defer done()
@@ -995,6 +1207,35 @@ func TestEvents(t *testing.T) {
 	msg = <-toServer
 	assert.Equal(t, `{"data":{"id":"9"},"event":"ack"}`, string(msg))
 
+	// token-pool-backfill: success
+	mcb.On("TokenPoolBackfillProgress", h, &tokens.TokenPoolBackfillProgress{
+		PoolProtocolID: "F1",
+		Connector:      "testtokens",
+		Block:          "1000",
+		Transfers:      25,
+		Complete:       true,
+	}).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{
+		"id":    "19",
+		"event": "token-pool-backfill",
+		"data": fftypes.JSONObject{
+			"poolId":      "F1",
+			"blockNumber": "1000",
+			"transfers":   25,
+			"complete":    true,
+		},
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"19"},"event":"ack"}`, string(msg))
+
+	// token-pool-backfill: missing data
+	fromServer <- fftypes.JSONObject{
+		"id":    "20",
+		"event": "token-pool-backfill",
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"20"},"event":"ack"}`, string(msg))
+
 	mcb.AssertExpectations(t)
 }
 
@@ -1044,3 +1285,56 @@ func TestEventLoopClosedContext(t *testing.T) {
 	wsm.On("Receive").Return((<-chan []byte)(r))
 	h.eventLoop() // we're simply looking for it exiting
 }
+
+func TestHealth(t *testing.T) {
+	h, _, _, _, done := newTestFFTokens(t)
+	defer done()
+
+	assert.Regexp(t, "FF10389", h.Health(context.Background()))
+
+	err := h.Start()
+	assert.NoError(t, err)
+	assert.NoError(t, h.Health(context.Background()))
+}
+
+func TestConnectorCapabilities(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"approvals": true,
+			"uris":      false,
+			"deploy":    true,
+			"standards": []string{"ERC20", "ERC721"},
+		}))
+
+	caps, standards, err := h.ConnectorCapabilities(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &fftypes.TokenConnectorCapabilities{Approvals: true, Deploy: true}, caps)
+	assert.Equal(t, []string{"ERC20", "ERC721"}, standards)
+}
+
+func TestConnectorCapabilitiesNotSupported(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{}))
+
+	caps, standards, err := h.ConnectorCapabilities(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, caps)
+	assert.Nil(t, standards)
+}
+
+func TestConnectorCapabilitiesError(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	_, _, err := h.ConnectorCapabilities(context.Background())
+	assert.Regexp(t, "FF10274", err)
+}
@@ -0,0 +1,148 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftokens
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+type protocolVersion struct {
+	major int
+	minor int
+}
+
+func (v protocolVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+// supports is true if a connector at this version implements the protocol of the required version
+func (v protocolVersion) supports(required protocolVersion) bool {
+	return v.major == required.major && v.minor >= required.minor
+}
+
+var (
+	// supportedProtocolVersion is the version of the fftokens protocol implemented by this plugin.
+	// A connector with a different major version is not compatible.
+	supportedProtocolVersion = protocolVersion{major: 1, minor: 2}
+	// baselineProtocolVersion is assumed for connectors that do not report a version
+	baselineProtocolVersion = protocolVersion{major: 1, minor: 0}
+)
+
+// protocolFeature is an optional part of the protocol, which is only used with connectors that report a
+// version that includes it
+type protocolFeature struct {
+	name       string
+	minVersion protocolVersion
+}
+
+var (
+	featureApprovalConfig = &protocolFeature{name: "approval config", minVersion: protocolVersion{major: 1, minor: 1}}
+	featurePoolBackfill   = &protocolFeature{name: "pool backfill", minVersion: protocolVersion{major: 1, minor: 2}}
+
+	protocolFeatures = []*protocolFeature{featureApprovalConfig, featurePoolBackfill}
+)
+
+// negotiatedProtocol is the result of the version handshake with the connector
+type negotiatedProtocol struct {
+	reported string
+	version  protocolVersion
+	warnings []string
+}
+
+// parseProtocolVersion accepts a version of the form "1.2", "1.2.3" or "v1.2.3" - the patch is ignored
+func parseProtocolVersion(version string) (v protocolVersion, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return v, false
+	}
+	var err1, err2 error
+	v.major, err1 = strconv.Atoi(parts[0])
+	v.minor, err2 = strconv.Atoi(parts[1])
+	return v, err1 == nil && err2 == nil && v.major >= 0 && v.minor >= 0
+}
+
+// negotiateProtocol queries the version of the protocol the connector implements, to decide which optional
+// features can be used with it. A failure to query the connector leaves the previous result in place, and
+// the handshake is tried again the next time the WebSocket connects.
+func (ft *FFTokens) negotiateProtocol(ctx context.Context) {
+	caps, _, err := ft.ConnectorCapabilities(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to negotiate protocol version with token connector '%s': %s", ft.configuredName, err)
+		return
+	}
+
+	protocol := &negotiatedProtocol{version: baselineProtocolVersion}
+	if caps != nil {
+		protocol.reported = caps.Version
+	}
+	if protocol.reported == "" {
+		protocol.reported = baselineProtocolVersion.String()
+		log.L(ctx).Infof("Token connector '%s' does not report its protocol version - assuming %s", ft.configuredName, protocol.reported)
+	} else if v, ok := parseProtocolVersion(protocol.reported); ok {
+		protocol.version = v
+	} else {
+		protocol.warnings = append(protocol.warnings, i18n.NewError(ctx, i18n.MsgTokensProtocolVersionInvalid, ft.configuredName, protocol.reported, baselineProtocolVersion).Error())
+	}
+
+	if protocol.version.major != supportedProtocolVersion.major {
+		protocol.warnings = append(protocol.warnings, i18n.NewError(ctx, i18n.MsgTokensProtocolIncompatible, ft.configuredName, protocol.reported, supportedProtocolVersion).Error())
+	} else {
+		var disabled []string
+		for _, feature := range protocolFeatures {
+			if !protocol.version.supports(feature.minVersion) {
+				disabled = append(disabled, fmt.Sprintf("%s (%s)", feature.name, feature.minVersion))
+			}
+		}
+		if len(disabled) > 0 {
+			protocol.warnings = append(protocol.warnings, i18n.NewError(ctx, i18n.MsgTokensProtocolDegraded, ft.configuredName, protocol.reported, strings.Join(disabled, ", ")).Error())
+		}
+	}
+	for _, warning := range protocol.warnings {
+		log.L(ctx).Warnf("%s", warning)
+	}
+
+	ft.protocolMux.Lock()
+	ft.protocol = protocol
+	ft.protocolMux.Unlock()
+}
+
+// checkFeature returns an error if the connector reported a protocol version that does not include the
+// feature. Until the handshake has completed, every feature is assumed to be available.
+func (ft *FFTokens) checkFeature(ctx context.Context, feature *protocolFeature) error {
+	ft.protocolMux.Lock()
+	protocol := ft.protocol
+	ft.protocolMux.Unlock()
+	if protocol == nil || protocol.version.supports(feature.minVersion) {
+		return nil
+	}
+	return i18n.NewError(ctx, i18n.MsgTokensFeatureNotSupported, ft.configuredName, feature.name, feature.minVersion, protocol.reported)
+}
+
+func (ft *FFTokens) ConnectorWarnings() []string {
+	ft.protocolMux.Lock()
+	defer ft.protocolMux.Unlock()
+	if ft.protocol == nil {
+		return nil
+	}
+	return ft.protocol.warnings
+}
@@ -0,0 +1,152 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftokens
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProtocolVersion(t *testing.T) {
+	v, ok := parseProtocolVersion("1.2")
+	assert.True(t, ok)
+	assert.Equal(t, protocolVersion{major: 1, minor: 2}, v)
+	v, ok = parseProtocolVersion("v1.1.7")
+	assert.True(t, ok)
+	assert.Equal(t, protocolVersion{major: 1, minor: 1}, v)
+	_, ok = parseProtocolVersion("1")
+	assert.False(t, ok)
+	_, ok = parseProtocolVersion("1.x")
+	assert.False(t, ok)
+	_, ok = parseProtocolVersion("1.-1")
+	assert.False(t, ok)
+}
+
+func mockCapabilitiesVersion(httpURL, version string) {
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"version":   version,
+			"approvals": true,
+		}))
+}
+
+func TestNegotiateProtocolSupported(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	mockCapabilitiesVersion(httpURL, "1.2.0")
+	err := h.afterConnect(context.Background(), nil)
+	assert.NoError(t, err)
+
+	assert.Empty(t, h.ConnectorWarnings())
+	assert.NoError(t, h.checkFeature(context.Background(), featureApprovalConfig))
+	assert.NoError(t, h.checkFeature(context.Background(), featurePoolBackfill))
+}
+
+func TestNegotiateProtocolDegraded(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	mockCapabilitiesVersion(httpURL, "1.1")
+	h.negotiateProtocol(context.Background())
+
+	warnings := h.ConnectorWarnings()
+	assert.Len(t, warnings, 1)
+	assert.Regexp(t, "FF10551.*pool backfill \\(1.2\\)", warnings[0])
+	assert.NoError(t, h.checkFeature(context.Background(), featureApprovalConfig))
+
+	complete, err := h.ActivateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{
+		ProtocolID: "N1",
+		Backfill: &fftypes.TokenPoolBackfill{
+			FromBlock: "1000",
+			State:     fftypes.TokenPoolBackfillStatePending,
+		},
+	}, fftypes.JSONObject{})
+	assert.False(t, complete)
+	assert.Regexp(t, "FF10552.*pool backfill.*1.2.*1.1", err)
+}
+
+func TestNegotiateProtocolNotReported(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{}))
+	h.negotiateProtocol(context.Background())
+
+	warnings := h.ConnectorWarnings()
+	assert.Len(t, warnings, 1)
+	assert.Regexp(t, "FF10551.*approval config \\(1.1\\), pool backfill \\(1.2\\)", warnings[0])
+
+	err := h.TokensApproval(context.Background(), fftypes.NewUUID(), "123", &fftypes.TokenApproval{
+		Config: fftypes.JSONObject{"foo": "bar"},
+	})
+	assert.Regexp(t, "FF10552.*approval config", err)
+}
+
+func TestNegotiateProtocolIncompatible(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	mockCapabilitiesVersion(httpURL, "2.0")
+	h.negotiateProtocol(context.Background())
+
+	warnings := h.ConnectorWarnings()
+	assert.Len(t, warnings, 1)
+	assert.Regexp(t, "FF10550", warnings[0])
+	assert.Regexp(t, "FF10552", h.checkFeature(context.Background(), featureApprovalConfig))
+}
+
+func TestNegotiateProtocolInvalidVersion(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	mockCapabilitiesVersion(httpURL, "latest")
+	h.negotiateProtocol(context.Background())
+
+	warnings := h.ConnectorWarnings()
+	assert.Len(t, warnings, 2)
+	assert.Regexp(t, "FF10549.*latest", warnings[0])
+	assert.Regexp(t, "FF10551", warnings[1])
+}
+
+func TestNegotiateProtocolFailKeepsPrevious(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	mockCapabilitiesVersion(httpURL, "2.0")
+	h.negotiateProtocol(context.Background())
+	assert.Len(t, h.ConnectorWarnings(), 1)
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/capabilities", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+	h.negotiateProtocol(context.Background())
+	assert.Len(t, h.ConnectorWarnings(), 1)
+}
+
+func TestNegotiateProtocolNotYetNegotiated(t *testing.T) {
+	h, _, _, _, done := newTestFFTokens(t)
+	defer done()
+
+	assert.Nil(t, h.ConnectorWarnings())
+	assert.NoError(t, h.checkFeature(context.Background(), featurePoolBackfill))
+}
@@ -0,0 +1,263 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+)
+
+var (
+	hubsMux sync.Mutex
+	hubs    = map[string]*hub{}
+)
+
+// hub holds the token pools and balances shared by all the loopback plugins in the process with the same hub name.
+// Every change is made under the hub lock, and the resulting events are queued to each plugin before the lock
+// is released, so all plugins see the same events in the same order.
+type hub struct {
+	name     string
+	mux      sync.Mutex
+	plugins  map[*Loopback]bool
+	pools    map[string]*pool
+	sequence int64
+}
+
+type pool struct {
+	protocolID string
+	tokenType  fftypes.TokenType
+	symbol     string
+	info       fftypes.JSONObject
+	event      blockchain.Event
+	nextIndex  int64
+	balances   map[string]*big.Int
+	approvals  map[string]bool
+	activated  map[*Loopback]bool
+}
+
+func getHub(name string) *hub {
+	hubsMux.Lock()
+	defer hubsMux.Unlock()
+	h, ok := hubs[name]
+	if !ok {
+		h = &hub{
+			name:    name,
+			plugins: make(map[*Loopback]bool),
+			pools:   make(map[string]*pool),
+		}
+		hubs[name] = h
+	}
+	return h
+}
+
+func (h *hub) attach(lt *Loopback) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.plugins[lt] = true
+}
+
+func (h *hub) detach(lt *Loopback) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	delete(h.plugins, lt)
+	for _, p := range h.pools {
+		delete(p.activated, lt)
+	}
+}
+
+// newEvent allocates the next event on the hub - must be called with the hub locked
+func (h *hub) newEvent(name string, output fftypes.JSONObject) blockchain.Event {
+	h.sequence++
+	txHash := "0x" + fftypes.HashString(fmt.Sprintf("%s/%d", h.name, h.sequence)).String()
+	return blockchain.Event{
+		BlockchainTXID: txHash,
+		Name:           name,
+		ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", h.sequence, 0, 0),
+		Output:         output,
+		Info: fftypes.JSONObject{
+			"blockNumber":     strconv.FormatInt(h.sequence, 10),
+			"transactionHash": txHash,
+		},
+		Timestamp: fftypes.Now(),
+		Location:  h.name,
+		Signature: name,
+	}
+}
+
+func (h *hub) createPool(tokenType fftypes.TokenType, symbol string, info fftypes.JSONObject) *pool {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	p := &pool{
+		protocolID: strconv.Itoa(len(h.pools) + 1),
+		tokenType:  tokenType,
+		symbol:     symbol,
+		info:       info,
+		nextIndex:  1,
+		balances:   make(map[string]*big.Int),
+		approvals:  make(map[string]bool),
+		activated:  make(map[*Loopback]bool),
+	}
+	p.event = h.newEvent("TokenPool", fftypes.JSONObject{
+		"poolId": p.protocolID,
+		"type":   string(tokenType),
+	})
+	h.pools[p.protocolID] = p
+	return p
+}
+
+func (h *hub) activatePool(ctx context.Context, lt *Loopback, protocolID string) (*pool, error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	p, ok := h.pools[protocolID]
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgLoopbackPoolNotFound, protocolID, h.name)
+	}
+	p.activated[lt] = true
+	return p, nil
+}
+
+func balanceKey(tokenIndex, account string) string {
+	return tokenIndex + "/" + account
+}
+
+func (p *pool) balance(tokenIndex, account string) *big.Int {
+	if b, ok := p.balances[balanceKey(tokenIndex, account)]; ok {
+		return b
+	}
+	return big.NewInt(0)
+}
+
+func (p *pool) addBalance(tokenIndex, account string, amount *big.Int) {
+	p.balances[balanceKey(tokenIndex, account)] = new(big.Int).Add(p.balance(tokenIndex, account), amount)
+}
+
+// checkTransfer validates a transfer against the current balances and approvals of the pool, allocating
+// the index of a newly minted non-fungible token
+func (p *pool) checkTransfer(ctx context.Context, transfer *fftypes.TokenTransfer) error {
+	amount := transfer.Amount.Int()
+	if transfer.Type == fftypes.TokenTransferTypeMint {
+		if p.tokenType == fftypes.TokenTypeNonFungible {
+			if amount.Cmp(big.NewInt(1)) != 0 {
+				return i18n.NewError(ctx, i18n.MsgLoopbackNonFungibleAmount)
+			}
+			transfer.TokenIndex = strconv.FormatInt(p.nextIndex, 10)
+			p.nextIndex++
+		}
+		return nil
+	}
+	if transfer.Key != transfer.From && !p.approvals[balanceKey(transfer.From, transfer.Key)] {
+		return i18n.NewError(ctx, i18n.MsgLoopbackNotApproved, transfer.Key, p.protocolID, transfer.From)
+	}
+	if p.balance(transfer.TokenIndex, transfer.From).Cmp(amount) < 0 {
+		return i18n.NewError(ctx, i18n.MsgLoopbackInsufficientBalance, transfer.TokenIndex, p.protocolID, transfer.From)
+	}
+	return nil
+}
+
+// transferBatch checks every pool exists before performing any of the transfers, so a batch is rejected as a whole
+func (h *hub) transferBatch(ctx context.Context, lt *Loopback, requests []*tokens.TokenTransferRequest) error {
+	h.mux.Lock()
+	for _, req := range requests {
+		if _, ok := h.pools[req.PoolProtocolID]; !ok {
+			h.mux.Unlock()
+			return i18n.NewError(ctx, i18n.MsgLoopbackPoolNotFound, req.PoolProtocolID, h.name)
+		}
+	}
+	h.mux.Unlock()
+	for _, req := range requests {
+		if err := h.transfer(ctx, lt, req.OpID, req.PoolProtocolID, req.Transfer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *hub) transfer(ctx context.Context, lt *Loopback, opID *fftypes.UUID, protocolID string, transfer *fftypes.TokenTransfer) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	p, ok := h.pools[protocolID]
+	if !ok {
+		return i18n.NewError(ctx, i18n.MsgLoopbackPoolNotFound, protocolID, h.name)
+	}
+
+	t := *transfer
+	if err := p.checkTransfer(ctx, &t); err != nil {
+		lt.queueReceipt(opID, fftypes.OpStatusFailed, "", err.Error(), nil)
+		return nil
+	}
+	if t.Type != fftypes.TokenTransferTypeMint {
+		p.addBalance(t.TokenIndex, t.From, new(big.Int).Neg(t.Amount.Int()))
+	}
+	if t.Type != fftypes.TokenTransferTypeBurn {
+		p.addBalance(t.TokenIndex, t.To, t.Amount.Int())
+	}
+
+	var name string
+	switch t.Type {
+	case fftypes.TokenTransferTypeMint:
+		name = "Mint"
+	case fftypes.TokenTransferTypeBurn:
+		name = "Burn"
+	default:
+		name = "Transfer"
+	}
+	event := h.newEvent(name, fftypes.JSONObject{
+		"poolId":     protocolID,
+		"tokenIndex": t.TokenIndex,
+		"from":       t.From,
+		"to":         t.To,
+		"amount":     t.Amount.Int().String(),
+	})
+	t.ProtocolID = event.ProtocolID
+	lt.queueReceipt(opID, fftypes.OpStatusSucceeded, event.BlockchainTXID, "", event.Info)
+	for activated := range p.activated {
+		activated.queueTransfer(protocolID, &t, event)
+	}
+	return nil
+}
+
+func (h *hub) approve(ctx context.Context, lt *Loopback, opID *fftypes.UUID, protocolID string, approval *fftypes.TokenApproval) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	p, ok := h.pools[protocolID]
+	if !ok {
+		return i18n.NewError(ctx, i18n.MsgLoopbackPoolNotFound, protocolID, h.name)
+	}
+
+	p.approvals[balanceKey(approval.Key, approval.Operator)] = approval.Approved
+	event := h.newEvent("TokenApproval", fftypes.JSONObject{
+		"poolId":   protocolID,
+		"signer":   approval.Key,
+		"operator": approval.Operator,
+		"approved": approval.Approved,
+	})
+	a := *approval
+	a.ProtocolID = event.ProtocolID
+	lt.queueReceipt(opID, fftypes.OpStatusSucceeded, event.BlockchainTXID, "", event.Info)
+	for activated := range p.activated {
+		activated.queueApproval(protocolID, &a, event)
+	}
+	return nil
+}
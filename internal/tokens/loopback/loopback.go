@@ -0,0 +1,205 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopback
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+)
+
+// Loopback is a tokens plugin for development and CI, that keeps token pools and balances in memory instead of
+// using a token connector. Plugins in the same process with the same hub name share pools and balances, so the
+// members of a multi-node test see each other's transfers.
+type Loopback struct {
+	ctx            context.Context
+	callbacks      tokens.Callbacks
+	configuredName string
+	hub            *hub
+	mux            sync.Mutex
+	events         []func() error
+	notify         chan struct{}
+}
+
+func (lt *Loopback) Name() string {
+	return "loopback"
+}
+
+func (lt *Loopback) Init(ctx context.Context, name string, prefix config.Prefix, callbacks tokens.Callbacks) (err error) {
+	lt.ctx = log.WithLogField(ctx, "proto", "loopback")
+	lt.callbacks = callbacks
+	lt.configuredName = name
+	lt.notify = make(chan struct{}, 1)
+	lt.hub = getHub(prefix.GetString(LoopbackConfigHub))
+	lt.hub.attach(lt)
+	return nil
+}
+
+func (lt *Loopback) Start() error {
+	go lt.eventLoop()
+	return nil
+}
+
+func (lt *Loopback) Capabilities() *tokens.Capabilities {
+	return &tokens.Capabilities{}
+}
+
+func (lt *Loopback) Health(ctx context.Context) error {
+	return nil
+}
+
+func (lt *Loopback) ConnectorCapabilities(ctx context.Context) (*fftypes.TokenConnectorCapabilities, []string, error) {
+	return &fftypes.TokenConnectorCapabilities{
+		Approvals:      true,
+		URIs:           true,
+		Deploy:         true,
+		BatchTransfers: true,
+	}, []string{lt.Name()}, nil
+}
+
+func (lt *Loopback) ConnectorWarnings() []string {
+	return nil
+}
+
+func (lt *Loopback) queue(event func() error) {
+	lt.mux.Lock()
+	lt.events = append(lt.events, event)
+	lt.mux.Unlock()
+	select {
+	case lt.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (lt *Loopback) eventLoop() {
+	defer lt.hub.detach(lt)
+	l := log.L(lt.ctx).WithField("role", "event-loop")
+	for {
+		lt.mux.Lock()
+		events := lt.events
+		lt.events = nil
+		lt.mux.Unlock()
+		for _, event := range events {
+			if err := event(); err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
+		}
+		select {
+		case <-lt.ctx.Done():
+			l.Debugf("Event loop exiting (context cancelled)")
+			return
+		case <-lt.notify:
+		}
+	}
+}
+
+func (lt *Loopback) queueReceipt(opID *fftypes.UUID, status fftypes.OpStatus, txHash, errorMessage string, info fftypes.JSONObject) {
+	lt.queue(func() error {
+		log.L(lt.ctx).Infof("Tokens '%s' reply: request=%s message=%s", status, opID, errorMessage)
+		return lt.callbacks.TokenOpUpdate(lt, opID, status, txHash, errorMessage, info)
+	})
+}
+
+func (lt *Loopback) withSource(event blockchain.Event) blockchain.Event {
+	event.Source = lt.Name() + ":" + lt.configuredName
+	return event
+}
+
+func (lt *Loopback) queueTransfer(protocolID string, transfer *fftypes.TokenTransfer, event blockchain.Event) {
+	t := *transfer
+	t.Connector = lt.configuredName
+	lt.queue(func() error {
+		return lt.callbacks.TokensTransferred(lt, &tokens.TokenTransfer{
+			PoolProtocolID: protocolID,
+			TokenTransfer:  t,
+			Event:          lt.withSource(event),
+		})
+	})
+}
+
+func (lt *Loopback) queueApproval(protocolID string, approval *fftypes.TokenApproval, event blockchain.Event) {
+	a := *approval
+	a.Connector = lt.configuredName
+	lt.queue(func() error {
+		return lt.callbacks.TokensApproved(lt, &tokens.TokenApproval{
+			PoolProtocolID: protocolID,
+			TokenApproval:  a,
+			Event:          lt.withSource(event),
+		})
+	})
+}
+
+func (lt *Loopback) poolCreated(p *pool, tx fftypes.TransactionRef) error {
+	return lt.callbacks.TokenPoolCreated(lt, &tokens.TokenPool{
+		Type:       p.tokenType,
+		ProtocolID: p.protocolID,
+		TX:         tx,
+		Connector:  lt.configuredName,
+		Standard:   lt.Name(),
+		Symbol:     p.symbol,
+		Info:       p.info,
+		Event:      lt.withSource(p.event),
+	})
+}
+
+// CreateTokenPool creates the pool on the hub straight away, so completes synchronously
+func (lt *Loopback) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
+	p := lt.hub.createPool(pool.Type, pool.Symbol, nil)
+	return true, lt.poolCreated(p, pool.TX)
+}
+
+// DeployTokenPool simulates the deployment of a token contract, with an address derived from the pool
+func (lt *Loopback) DeployTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
+	address := "0x" + fftypes.HashString(lt.hub.name + "/" + opID.String()).String()[0:40]
+	p := lt.hub.createPool(pool.Type, pool.Symbol, fftypes.JSONObject{"address": address})
+	return true, lt.poolCreated(p, pool.TX)
+}
+
+// ActivateTokenPool starts delivery of the events for an existing pool on the hub to this plugin
+func (lt *Loopback) ActivateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool, blockchainInfo fftypes.JSONObject) (complete bool, err error) {
+	p, err := lt.hub.activatePool(ctx, lt, pool.ProtocolID)
+	if err != nil {
+		return false, err
+	}
+	return true, lt.poolCreated(p, pool.TX)
+}
+
+func (lt *Loopback) MintTokens(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, mint *fftypes.TokenTransfer) error {
+	return lt.hub.transfer(ctx, lt, opID, poolProtocolID, mint)
+}
+
+func (lt *Loopback) BurnTokens(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, burn *fftypes.TokenTransfer) error {
+	return lt.hub.transfer(ctx, lt, opID, poolProtocolID, burn)
+}
+
+func (lt *Loopback) TransferTokens(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, transfer *fftypes.TokenTransfer) error {
+	return lt.hub.transfer(ctx, lt, opID, poolProtocolID, transfer)
+}
+
+func (lt *Loopback) TransferTokensBatch(ctx context.Context, requests []*tokens.TokenTransferRequest) error {
+	return lt.hub.transferBatch(ctx, lt, requests)
+}
+
+func (lt *Loopback) TokensApproval(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, approval *fftypes.TokenApproval) error {
+	return lt.hub.approve(ctx, lt, opID, poolProtocolID, approval)
+}
@@ -0,0 +1,377 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/tokenmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("tokens").Array()
+
+type testPlugin struct {
+	*Loopback
+	mcb       *tokenmocks.Callbacks
+	receipts  chan *fftypes.OpStatus
+	transfers chan *tokens.TokenTransfer
+	approvals chan *tokens.TokenApproval
+	cancel    func()
+}
+
+func newTestHub(t *testing.T, count int) []*testPlugin {
+	config.Reset()
+	(&Loopback{}).InitPrefix(utConfPrefix)
+	utConfPrefix.AddKnownKey(LoopbackConfigHub, fftypes.NewUUID().String())
+	config.Set("tokens", []fftypes.JSONObject{{}})
+
+	plugins := make([]*testPlugin, count)
+	for i := range plugins {
+		ctx, cancel := context.WithCancel(context.Background())
+		tp := &testPlugin{
+			Loopback:  &Loopback{},
+			mcb:       &tokenmocks.Callbacks{},
+			receipts:  make(chan *fftypes.OpStatus, 10),
+			transfers: make(chan *tokens.TokenTransfer, 10),
+			approvals: make(chan *tokens.TokenApproval, 10),
+			cancel:    cancel,
+		}
+		err := tp.Init(ctx, fmt.Sprintf("tokens%d", i), utConfPrefix.ArrayEntry(0), tp.mcb)
+		assert.NoError(t, err)
+		tp.mcb.On("TokenOpUpdate", tp.Loopback, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			status := args[2].(fftypes.OpStatus)
+			tp.receipts <- &status
+		}).Return(nil).Maybe()
+		tp.mcb.On("TokensTransferred", tp.Loopback, mock.Anything).Run(func(args mock.Arguments) {
+			tp.transfers <- args[1].(*tokens.TokenTransfer)
+		}).Return(nil).Maybe()
+		tp.mcb.On("TokensApproved", tp.Loopback, mock.Anything).Run(func(args mock.Arguments) {
+			tp.approvals <- args[1].(*tokens.TokenApproval)
+		}).Return(nil).Maybe()
+		plugins[i] = tp
+	}
+	return plugins
+}
+
+func (tp *testPlugin) createActivePool(t *testing.T, tokenType fftypes.TokenType) string {
+	var created *tokens.TokenPool
+	tp.mcb.On("TokenPoolCreated", tp.Loopback, mock.Anything).Run(func(args mock.Arguments) {
+		created = args[1].(*tokens.TokenPool)
+	}).Return(nil).Once()
+	complete, err := tp.CreateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{Type: tokenType})
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	return created.ProtocolID
+}
+
+func (tp *testPlugin) activate(t *testing.T, protocolID string) {
+	tp.mcb.On("TokenPoolCreated", tp.Loopback, mock.Anything).Return(nil).Once()
+	complete, err := tp.ActivateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{ProtocolID: protocolID}, nil)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+}
+
+func (tp *testPlugin) done() {
+	tp.cancel()
+}
+
+func transfer(transferType fftypes.TokenTransferType, key, from, to string, amount int64) *fftypes.TokenTransfer {
+	t := &fftypes.TokenTransfer{
+		Type: transferType,
+		Key:  key,
+		From: from,
+		To:   to,
+		TX: fftypes.TransactionRef{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.TransactionTypeTokenTransfer,
+		},
+	}
+	t.Amount.Int().SetInt64(amount)
+	return t
+}
+
+func TestInit(t *testing.T) {
+	plugins := newTestHub(t, 1)
+	lt := plugins[0]
+	defer lt.done()
+
+	assert.Equal(t, "loopback", lt.Name())
+	assert.Equal(t, "tokens0", lt.configuredName)
+	assert.NotNil(t, lt.Capabilities())
+	assert.NoError(t, lt.Health(context.Background()))
+	caps, standards, err := lt.ConnectorCapabilities(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, caps.Approvals)
+	assert.True(t, caps.URIs)
+	assert.True(t, caps.Deploy)
+	assert.True(t, caps.BatchTransfers)
+	assert.Equal(t, []string{"loopback"}, standards)
+	assert.Empty(t, lt.ConnectorWarnings())
+}
+
+func TestCreatePool(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	txID := fftypes.NewUUID()
+	lt.mcb.On("TokenPoolCreated", lt.Loopback, mock.MatchedBy(func(p *tokens.TokenPool) bool {
+		return p.ProtocolID == "1" &&
+			p.Type == fftypes.TokenTypeFungible &&
+			p.Symbol == "FFC" &&
+			p.Connector == "tokens0" &&
+			p.Standard == "loopback" &&
+			p.TX.ID == txID &&
+			p.Event.Source == "loopback:tokens0" &&
+			p.Event.Name == "TokenPool" &&
+			p.Event.ProtocolID == "000000000001/000000/000000"
+	})).Return(nil).Once()
+
+	complete, err := lt.CreateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{
+		Type:   fftypes.TokenTypeFungible,
+		Symbol: "FFC",
+		TX:     fftypes.TransactionRef{ID: txID, Type: fftypes.TransactionTypeTokenPool},
+	})
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	lt.mcb.AssertExpectations(t)
+}
+
+func TestDeployPool(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	lt.mcb.On("TokenPoolCreated", lt.Loopback, mock.MatchedBy(func(p *tokens.TokenPool) bool {
+		return p.ProtocolID == "1" && len(p.Info.GetString("address")) == 42
+	})).Return(nil).Once()
+
+	complete, err := lt.DeployTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{Type: fftypes.TokenTypeFungible})
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	lt.mcb.AssertExpectations(t)
+}
+
+func TestActivatePoolSharedHub(t *testing.T) {
+	plugins := newTestHub(t, 2)
+	lt1, lt2 := plugins[0], plugins[1]
+	defer lt1.done()
+	defer lt2.done()
+
+	protocolID := lt1.createActivePool(t, fftypes.TokenTypeFungible)
+
+	lt2.mcb.On("TokenPoolCreated", lt2.Loopback, mock.MatchedBy(func(p *tokens.TokenPool) bool {
+		return p.ProtocolID == protocolID &&
+			p.Connector == "tokens1" &&
+			p.Event.Source == "loopback:tokens1" &&
+			p.Event.ProtocolID == "000000000001/000000/000000"
+	})).Return(nil).Once()
+	complete, err := lt2.ActivateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{ProtocolID: protocolID}, nil)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	lt2.mcb.AssertExpectations(t)
+}
+
+func TestActivatePoolNotFound(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	_, err := lt.ActivateTokenPool(context.Background(), fftypes.NewUUID(), &fftypes.TokenPool{ProtocolID: "99"}, nil)
+	assert.Regexp(t, "FF10457", err)
+}
+
+func TestFungibleTransfers(t *testing.T) {
+	plugins := newTestHub(t, 2)
+	lt1, lt2 := plugins[0], plugins[1]
+	defer lt1.done()
+	defer lt2.done()
+
+	protocolID := lt1.createActivePool(t, fftypes.TokenTypeFungible)
+	lt1.activate(t, protocolID)
+	lt2.activate(t, protocolID)
+	assert.NoError(t, lt1.Start())
+	assert.NoError(t, lt2.Start())
+	ctx := context.Background()
+
+	// Mint is seen by both members, and only the submitter gets the receipt
+	mint := transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 10)
+	mint.URI = "https://example.com/token"
+	err := lt1.MintTokens(ctx, fftypes.NewUUID(), protocolID, mint)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt1.receipts)
+	for _, lt := range plugins {
+		minted := <-lt.transfers
+		assert.Equal(t, protocolID, minted.PoolProtocolID)
+		assert.Equal(t, fftypes.TokenTransferTypeMint, minted.Type)
+		assert.Equal(t, lt.configuredName, minted.Connector)
+		assert.Equal(t, "0x01", minted.To)
+		assert.Equal(t, int64(10), minted.Amount.Int().Int64())
+		assert.Equal(t, mint.URI, minted.URI)
+		assert.Equal(t, mint.TX, minted.TX)
+		assert.Equal(t, "000000000002/000000/000000", minted.ProtocolID)
+		assert.Equal(t, "Mint", minted.Event.Name)
+	}
+
+	// Transfer more than the balance fails
+	err = lt1.TransferTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeTransfer, "0x01", "0x01", "0x02", 11))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, *<-lt1.receipts)
+
+	// Transfer by an operator fails until approved
+	err = lt2.TransferTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeTransfer, "0x02", "0x01", "0x02", 4))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, *<-lt2.receipts)
+
+	err = lt1.TokensApproval(ctx, fftypes.NewUUID(), protocolID, &fftypes.TokenApproval{Key: "0x01", Operator: "0x02", Approved: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt1.receipts)
+	for _, lt := range plugins {
+		approval := <-lt.approvals
+		assert.Equal(t, protocolID, approval.PoolProtocolID)
+		assert.Equal(t, lt.configuredName, approval.Connector)
+		assert.True(t, approval.Approved)
+		assert.Equal(t, "TokenApproval", approval.Event.Name)
+	}
+
+	err = lt2.TransferTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeTransfer, "0x02", "0x01", "0x02", 4))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt2.receipts)
+	for _, lt := range plugins {
+		transferred := <-lt.transfers
+		assert.Equal(t, "Transfer", transferred.Event.Name)
+		assert.Equal(t, "0x01", transferred.From)
+		assert.Equal(t, "0x02", transferred.To)
+	}
+
+	// Burn the remaining balance
+	err = lt1.BurnTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeBurn, "0x01", "0x01", "", 6))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt1.receipts)
+	for _, lt := range plugins {
+		burned := <-lt.transfers
+		assert.Equal(t, "Burn", burned.Event.Name)
+	}
+
+	lt1.Loopback.hub.mux.Lock()
+	pool := lt1.Loopback.hub.pools[protocolID]
+	assert.Equal(t, int64(0), pool.balance("", "0x01").Int64())
+	assert.Equal(t, int64(4), pool.balance("", "0x02").Int64())
+	lt1.Loopback.hub.mux.Unlock()
+}
+
+func TestTransferBatch(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	protocolID := lt.createActivePool(t, fftypes.TokenTypeFungible)
+	lt.activate(t, protocolID)
+	assert.NoError(t, lt.Start())
+	ctx := context.Background()
+
+	// A missing pool rejects the whole batch, before any transfer is performed
+	err := lt.TransferTokensBatch(ctx, []*tokens.TokenTransferRequest{
+		{OpID: fftypes.NewUUID(), PoolProtocolID: protocolID, Transfer: transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 10)},
+		{OpID: fftypes.NewUUID(), PoolProtocolID: "99", Transfer: transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 10)},
+	})
+	assert.Regexp(t, "FF10457", err)
+
+	err = lt.TransferTokensBatch(ctx, []*tokens.TokenTransferRequest{
+		{OpID: fftypes.NewUUID(), PoolProtocolID: protocolID, Transfer: transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 10)},
+		{OpID: fftypes.NewUUID(), PoolProtocolID: protocolID, Transfer: transfer(fftypes.TokenTransferTypeTransfer, "0x01", "0x01", "0x02", 4)},
+	})
+	assert.NoError(t, err)
+	for _, name := range []string{"Mint", "Transfer"} {
+		assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt.receipts)
+		assert.Equal(t, name, (<-lt.transfers).Event.Name)
+	}
+
+	lt.Loopback.hub.mux.Lock()
+	pool := lt.Loopback.hub.pools[protocolID]
+	assert.Equal(t, int64(6), pool.balance("", "0x01").Int64())
+	assert.Equal(t, int64(4), pool.balance("", "0x02").Int64())
+	lt.Loopback.hub.mux.Unlock()
+}
+
+func TestNonFungibleMint(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	protocolID := lt.createActivePool(t, fftypes.TokenTypeNonFungible)
+	lt.activate(t, protocolID)
+	assert.NoError(t, lt.Start())
+	ctx := context.Background()
+
+	err := lt.MintTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 2))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, *<-lt.receipts)
+
+	for _, index := range []string{"1", "2"} {
+		err = lt.MintTokens(ctx, fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, fftypes.OpStatusSucceeded, *<-lt.receipts)
+		minted := <-lt.transfers
+		assert.Equal(t, index, minted.TokenIndex)
+	}
+
+	burn := transfer(fftypes.TokenTransferTypeBurn, "0x01", "0x01", "", 1)
+	burn.TokenIndex = "3"
+	err = lt.BurnTokens(ctx, fftypes.NewUUID(), protocolID, burn)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, *<-lt.receipts)
+}
+
+func TestPoolNotFound(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+	ctx := context.Background()
+
+	err := lt.MintTokens(ctx, fftypes.NewUUID(), "99", transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 1))
+	assert.Regexp(t, "FF10457", err)
+	err = lt.TokensApproval(ctx, fftypes.NewUUID(), "99", &fftypes.TokenApproval{})
+	assert.Regexp(t, "FF10457", err)
+}
+
+func TestEventLoopCallbackError(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	defer lt.done()
+
+	protocolID := lt.createActivePool(t, fftypes.TokenTypeFungible)
+	lt.activate(t, protocolID)
+
+	mcb := &tokenmocks.Callbacks{}
+	lt.callbacks = mcb
+	mcb.On("TokenOpUpdate", lt.Loopback, mock.Anything, fftypes.OpStatusSucceeded, mock.Anything, "", mock.Anything).Return(fmt.Errorf("pop"))
+	err := lt.MintTokens(context.Background(), fftypes.NewUUID(), protocolID, transfer(fftypes.TokenTransferTypeMint, "0x01", "", "0x01", 1))
+	assert.NoError(t, err)
+
+	lt.eventLoop()
+	mcb.AssertExpectations(t)
+	assert.False(t, lt.hub.plugins[lt.Loopback])
+	assert.Empty(t, lt.hub.pools[protocolID].activated)
+}
+
+func TestEventLoopCancelled(t *testing.T) {
+	lt := newTestHub(t, 1)[0]
+	lt.done()
+	lt.eventLoop()
+	assert.False(t, lt.hub.plugins[lt.Loopback])
+}
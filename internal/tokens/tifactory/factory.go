@@ -22,11 +22,13 @@ import (
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/tokens/fftokens"
+	"github.com/hyperledger/firefly/internal/tokens/loopback"
 	"github.com/hyperledger/firefly/pkg/tokens"
 )
 
 var pluginsByName = map[string]func() tokens.Plugin{
 	(*fftokens.FFTokens)(nil).Name(): func() tokens.Plugin { return &fftokens.FFTokens{} },
+	(*loopback.Loopback)(nil).Name(): func() tokens.Plugin { return &loopback.Loopback{} },
 }
 
 func InitPrefix(prefix config.PrefixArray) {
@@ -27,6 +27,18 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *fftypes.Even
 		Event: *event,
 	}
 
+	// Events created outside of the original request, such as confirmations from the blockchain,
+	// are correlated through their transaction
+	if event.CorrelationID == "" && event.Transaction != nil {
+		tx, err := t.GetTransactionByIDCached(ctx, event.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil {
+			e.CorrelationID = tx.CorrelationID
+		}
+	}
+
 	switch event.Type {
 	case fftypes.EventTypeTransactionSubmitted:
 		tx, err := t.GetTransactionByIDCached(ctx, event.Reference)
@@ -94,6 +106,13 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *fftypes.Even
 			return nil, err
 		}
 		e.TokenTransfer = transfer
+	case fftypes.EventTypeApprovalRequestPending, fftypes.EventTypeApprovalRequestApproved,
+		fftypes.EventTypeApprovalRequestRejected, fftypes.EventTypeApprovalRequestFailed:
+		req, err := t.database.GetApprovalRequestByID(ctx, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.ApprovalRequest = req
 	}
 	return e, nil
 }
@@ -497,6 +497,55 @@ func TestEnrichTokenPoolConfirmedFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichApprovalRequestApproved(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi.On("GetApprovalRequestByID", mock.Anything, ref1).Return(&fftypes.ApprovalRequest{
+		ID: ref1,
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        ev1,
+		Type:      fftypes.EventTypeApprovalRequestApproved,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.ApprovalRequest.ID)
+}
+
+func TestEnrichApprovalRequestPendingFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi.On("GetApprovalRequestByID", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:        ev1,
+		Type:      fftypes.EventTypeApprovalRequestPending,
+		Reference: ref1,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestEnrichTokenApprovalConfirmed(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -646,3 +695,84 @@ func TestEnrichTokenTransferConfirmedFail(t *testing.T) {
 	_, err := txHelper.EnrichEvent(ctx, event)
 	assert.EqualError(t, err, "pop")
 }
+
+func TestEnrichCorrelationIDFromTransaction(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	mdi.On("GetTransactionByID", mock.Anything, txID).Return(&fftypes.Transaction{
+		ID:            txID,
+		CorrelationID: "corr1",
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.EventTypeCircuitOpened,
+		Transaction: txID,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, "corr1", enriched.CorrelationID)
+}
+
+func TestEnrichCorrelationIDTransactionNotFound(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	mdi.On("GetTransactionByID", mock.Anything, txID).Return(nil, nil)
+
+	event := &fftypes.Event{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.EventTypeCircuitOpened,
+		Transaction: txID,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Empty(t, enriched.CorrelationID)
+}
+
+func TestEnrichCorrelationIDTransactionFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	mdi.On("GetTransactionByID", mock.Anything, txID).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.EventTypeCircuitOpened,
+		Transaction: txID,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichCorrelationIDOnEvent(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	event := &fftypes.Event{
+		ID:            fftypes.NewUUID(),
+		Type:          fftypes.EventTypeCircuitOpened,
+		Transaction:   fftypes.NewUUID(),
+		CorrelationID: "corr1",
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, "corr1", enriched.CorrelationID)
+	mdi.AssertExpectations(t)
+}
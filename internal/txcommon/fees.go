@@ -0,0 +1,90 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txcommon
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func receiptInteger(output fftypes.JSONObject, key string) *big.Int {
+	s, ok := output.GetStringOk(key)
+	if !ok {
+		return nil
+	}
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil
+	}
+	return i
+}
+
+// ExtractTransactionFee reads the fee of a blockchain transaction from the standard gasUsed and effectiveGasPrice
+// fields of its receipt, as included in the output of the operation that submitted it. Returns nil if the
+// receipt does not report the gas used. The total is only calculated if the effective gas price is reported.
+func ExtractTransactionFee(output fftypes.JSONObject) *fftypes.TransactionFee {
+	gasUsed := receiptInteger(output, "gasUsed")
+	if gasUsed == nil {
+		return nil
+	}
+	gasPrice := receiptInteger(output, "effectiveGasPrice")
+	if gasPrice == nil {
+		return &fftypes.TransactionFee{GasUsed: (*fftypes.FFBigInt)(gasUsed)}
+	}
+	return fftypes.NewTransactionFee(gasUsed, gasPrice)
+}
+
+// RecordOperationFee stores the fee reported in the receipt of an operation against the operation and its transaction,
+// and adds it to the daily fee summary for the key that signed the blockchain transaction.
+// Receipts that are delivered more than once are only counted the first time.
+func (t *transactionHelper) RecordOperationFee(ctx context.Context, op *fftypes.Operation, output fftypes.JSONObject) error {
+	fee := ExtractTransactionFee(output)
+	if fee == nil || op.Fee != nil {
+		return nil
+	}
+	if err := t.database.UpdateOperation(ctx, op.ID, database.OperationQueryFactory.NewUpdate(ctx).Set("fee", fee)); err != nil {
+		return err
+	}
+
+	tx, err := t.database.GetTransactionByID(ctx, op.Transaction)
+	if err != nil {
+		return err
+	}
+	if tx != nil {
+		tx.Fee = tx.Fee.Add(fee)
+		if err := t.database.UpdateTransaction(ctx, tx.ID, database.TransactionQueryFactory.NewUpdate(ctx).Set("fee", tx.Fee)); err != nil {
+			return err
+		}
+		t.updateTransactionsCache(tx)
+	}
+
+	summary := &fftypes.FeeSummary{
+		Namespace:    op.Namespace,
+		Key:          output.GetString("from"),
+		Day:          time.Now().UTC().Format(fftypes.FeeSummaryDay),
+		Transactions: 1,
+		GasUsed:      fee.GasUsed,
+		Total:        fee.Total,
+	}
+	log.L(ctx).Debugf("Recording fee for operation %s: gasUsed=%s total=%s key=%s", op.ID, fee.GasUsed.Int(), fee.Total.Int(), summary.Key)
+	return t.database.UpsertFeeSummary(ctx, summary)
+}
@@ -0,0 +1,230 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExtractTransactionFee(t *testing.T) {
+
+	fee := ExtractTransactionFee(fftypes.JSONObject{
+		"gasUsed":           "21000",
+		"effectiveGasPrice": "0x3b9aca00",
+	})
+	assert.Equal(t, int64(21000), fee.GasUsed.Int().Int64())
+	assert.Equal(t, int64(1000000000), fee.GasPrice.Int().Int64())
+	assert.Equal(t, int64(21000000000000), fee.Total.Int().Int64())
+
+}
+
+func TestExtractTransactionFeeNoPrice(t *testing.T) {
+
+	fee := ExtractTransactionFee(fftypes.JSONObject{
+		"gasUsed": "0x5208",
+	})
+	assert.Equal(t, int64(21000), fee.GasUsed.Int().Int64())
+	assert.Nil(t, fee.GasPrice)
+	assert.Nil(t, fee.Total)
+
+}
+
+func TestExtractTransactionFeeMissingOrInvalid(t *testing.T) {
+
+	assert.Nil(t, ExtractTransactionFee(fftypes.JSONObject{}))
+	assert.Nil(t, ExtractTransactionFee(fftypes.JSONObject{"gasUsed": "lots"}))
+
+}
+
+func newTestFeeOp() (*fftypes.Operation, fftypes.JSONObject) {
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Transaction: fftypes.NewUUID(),
+	}
+	output := fftypes.JSONObject{
+		"from":              "0x12345",
+		"gasUsed":           "21000",
+		"effectiveGasPrice": "2",
+	}
+	return op, output
+}
+
+func TestRecordOperationFeeOK(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	tx := &fftypes.Transaction{
+		ID:  op.Transaction,
+		Fee: fftypes.NewTransactionFee(fftypes.NewFFBigInt(100).Int(), fftypes.NewFFBigInt(2).Int()),
+	}
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(nil)
+	mdi.On("GetTransactionByID", ctx, op.Transaction).Return(tx, nil)
+	mdi.On("UpdateTransaction", ctx, op.Transaction, mock.Anything).Return(nil)
+	mdi.On("UpsertFeeSummary", ctx, mock.MatchedBy(func(summary *fftypes.FeeSummary) bool {
+		return summary.Namespace == "ns1" &&
+			summary.Key == "0x12345" &&
+			summary.Transactions == 1 &&
+			summary.GasUsed.Int().Int64() == 21000 &&
+			summary.Total.Int().Int64() == 42000
+	})).Return(nil)
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(21100), tx.Fee.GasUsed.Int().Int64())
+	assert.Equal(t, int64(42200), tx.Fee.Total.Int().Int64())
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeNoTransaction(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(nil)
+	mdi.On("GetTransactionByID", ctx, op.Transaction).Return(nil, nil)
+	mdi.On("UpsertFeeSummary", ctx, mock.Anything).Return(nil)
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeAlreadyRecorded(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	op.Fee = ExtractTransactionFee(output)
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeNoFee(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, _ := newTestFeeOp()
+
+	err := txHelper.RecordOperationFee(ctx, op, fftypes.JSONObject{})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeUpdateOpFail(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeGetTxFail(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(nil)
+	mdi.On("GetTransactionByID", ctx, op.Transaction).Return(nil, fmt.Errorf("pop"))
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeUpdateTxFail(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(nil)
+	mdi.On("GetTransactionByID", ctx, op.Transaction).Return(&fftypes.Transaction{ID: op.Transaction}, nil)
+	mdi.On("UpdateTransaction", ctx, op.Transaction, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestRecordOperationFeeUpsertSummaryFail(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	op, output := newTestFeeOp()
+	mdi.On("UpdateOperation", ctx, op.ID, mock.Anything).Return(nil)
+	mdi.On("GetTransactionByID", ctx, op.Transaction).Return(nil, nil)
+	mdi.On("UpsertFeeSummary", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.RecordOperationFee(ctx, op, output)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+
+}
@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txcommon
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// ResolveOperation updates the status of an operation. Outputs larger than the configured maximum inline size
+// (such as large contract invoke receipts) are stored in a separate operation output record, referenced from the
+// operation, so they do not bloat the operations table.
+func (t *transactionHelper) ResolveOperation(ctx context.Context, opID *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) error {
+	if output != nil {
+		b, err := json.Marshal(output)
+		if err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
+		}
+		if int64(len(b)) > t.maxInlineOutput {
+			return t.resolveOperationOverflow(ctx, opID, status, errorMsg, b)
+		}
+	}
+	return t.database.ResolveOperation(ctx, opID, status, errorMsg, output)
+}
+
+func (t *transactionHelper) resolveOperationOverflow(ctx context.Context, opID *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output []byte) error {
+	return t.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		op, err := t.database.GetOperationByID(ctx, opID)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			return i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+
+		// An operation resolved more than once replaces its existing output record, rather than leaving it behind
+		outputID := op.OutputRef
+		if outputID == nil {
+			outputID = fftypes.NewUUID()
+		}
+		if err := t.database.UpsertOperationOutput(ctx, &fftypes.OperationOutput{
+			ID:        outputID,
+			Operation: opID,
+			Namespace: op.Namespace,
+			Value:     fftypes.JSONAnyPtrBytes(output),
+		}); err != nil {
+			return err
+		}
+		log.L(ctx).Infof("Output of operation %s (%d bytes) stored in operation output %s", opID, len(output), outputID)
+
+		// Any output from an earlier update of the operation is cleared, so only the reference remains
+		update := database.OperationQueryFactory.NewUpdate(ctx).
+			Set("status", status).
+			Set("error", errorMsg).
+			Set("output", nil).
+			Set("outputref", outputID)
+		return t.database.UpdateOperation(ctx, opID, update)
+	})
+}
@@ -0,0 +1,172 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txcommon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestOutputHelper(maxInline string) (*transactionHelper, *databasemocks.Plugin) {
+	config.Reset()
+	config.Set(config.OperationsOutputMaxInlineSize, maxInline)
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	return NewTransactionHelper(mdi, mdm).(*transactionHelper), mdi
+}
+
+func TestResolveOperationInline(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("1Kb")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+	output := fftypes.JSONObject{"some": "output"}
+
+	mdi.On("ResolveOperation", ctx, opID, fftypes.OpStatusSucceeded, "", output).Return(nil)
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", output)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationNoOutput(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("0")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+
+	mdi.On("ResolveOperation", ctx, opID, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(nil)
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusFailed, "pop", nil)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationOverflow(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("1Kb")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+	output := fftypes.JSONObject{"receipt": strings.Repeat("a", 2048)}
+
+	var outputID *fftypes.UUID
+	mdi.On("GetOperationByID", ctx, opID).Return(&fftypes.Operation{ID: opID, Namespace: "ns1"}, nil)
+	mdi.On("UpsertOperationOutput", ctx, mock.MatchedBy(func(opOutput *fftypes.OperationOutput) bool {
+		outputID = opOutput.ID
+		return opOutput.ID != nil &&
+			opOutput.Operation.Equals(opID) &&
+			opOutput.Namespace == "ns1" &&
+			opOutput.Value.JSONObject().GetString("receipt") == output.GetString("receipt")
+	})).Return(nil)
+	mdi.On("UpdateOperation", ctx, opID, mock.MatchedBy(func(update database.Update) bool {
+		info, _ := update.Finalize()
+		if len(info.SetOperations) != 4 {
+			return false
+		}
+		out, _ := info.SetOperations[2].Value.Value()
+		ref, _ := info.SetOperations[3].Value.Value()
+		return info.SetOperations[0].Field == "status" &&
+			info.SetOperations[2].Field == "output" &&
+			out.([]byte) == nil &&
+			info.SetOperations[3].Field == "outputref" &&
+			ref == outputID.String()
+	})).Return(nil)
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", output)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "UpsertData", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveOperationOverflowReplacesExisting(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("0")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+	existingRef := fftypes.NewUUID()
+
+	mdi.On("GetOperationByID", ctx, opID).Return(&fftypes.Operation{ID: opID, Namespace: "ns1", OutputRef: existingRef}, nil)
+	mdi.On("UpsertOperationOutput", ctx, mock.MatchedBy(func(opOutput *fftypes.OperationOutput) bool {
+		return opOutput.ID.Equals(existingRef)
+	})).Return(nil)
+	mdi.On("UpdateOperation", ctx, opID, mock.Anything).Return(nil)
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject{"some": "output"})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationOverflowGetFail(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("0")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+
+	mdi.On("GetOperationByID", ctx, opID).Return(nil, fmt.Errorf("pop"))
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject{"some": "output"})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationOverflowNotFound(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("0")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+
+	mdi.On("GetOperationByID", ctx, opID).Return(nil, nil)
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject{"some": "output"})
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationOverflowUpsertOutputFail(t *testing.T) {
+	txHelper, mdi := newTestOutputHelper("0")
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+
+	mdi.On("GetOperationByID", ctx, opID).Return(&fftypes.Operation{ID: opID, Namespace: "ns1"}, nil)
+	mdi.On("UpsertOperationOutput", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.ResolveOperation(ctx, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject{"some": "output"})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveOperationBadOutput(t *testing.T) {
+	txHelper, _ := newTestOutputHelper("1Kb")
+
+	err := txHelper.ResolveOperation(context.Background(), fftypes.NewUUID(), fftypes.OpStatusSucceeded, "", fftypes.JSONObject{"bad": map[bool]bool{true: false}})
+	assert.Regexp(t, "FF10137", err)
+}
@@ -37,6 +37,8 @@ type Helper interface {
 	EnrichEvent(ctx context.Context, event *fftypes.Event) (*fftypes.EnrichedEvent, error)
 	GetTransactionByIDCached(ctx context.Context, id *fftypes.UUID) (*fftypes.Transaction, error)
 	GetBlockchainEventByIDCached(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error)
+	ResolveOperation(ctx context.Context, opID *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) error
+	RecordOperationFee(ctx context.Context, op *fftypes.Operation, output fftypes.JSONObject) error
 }
 
 type transactionHelper struct {
@@ -46,12 +48,28 @@ type transactionHelper struct {
 	transactionCacheTTL  time.Duration
 	blockchainEventCache *ccache.Cache
 	blockchainEventTTL   time.Duration
+	maxInlineOutput      int64
+	chainID              int64
 }
 
-func NewTransactionHelper(di database.Plugin, dm data.Manager) Helper {
+// HelperOption customizes the transaction helper returned by NewTransactionHelper
+type HelperOption func(t *transactionHelper)
+
+// WithChainID records the given blockchain chain ID on every transaction the helper creates
+func WithChainID(chainID int64) HelperOption {
+	return func(t *transactionHelper) {
+		t.chainID = chainID
+	}
+}
+
+func NewTransactionHelper(di database.Plugin, dm data.Manager, options ...HelperOption) Helper {
 	t := &transactionHelper{
-		database: di,
-		data:     dm,
+		database:        di,
+		data:            dm,
+		maxInlineOutput: config.GetByteSize(config.OperationsOutputMaxInlineSize),
+	}
+	for _, option := range options {
+		option(t)
 	}
 	t.transactionCache = ccache.New(
 		// We use a LRU cache with a size-aware max
@@ -91,6 +109,7 @@ func (t *transactionHelper) SubmitNewTransaction(ctx context.Context, ns string,
 		ID:        fftypes.NewUUID(),
 		Namespace: ns,
 		Type:      txType,
+		ChainID:   t.chainID,
 	}
 
 	if err := t.database.InsertTransaction(ctx, tx); err != nil {
@@ -140,6 +159,7 @@ func (t *transactionHelper) PersistTransaction(ctx context.Context, ns string, i
 			Namespace:     ns,
 			Type:          txType,
 			BlockchainIDs: fftypes.NewFFStringArray(strings.ToLower(blockchainTXID)),
+			ChainID:       t.chainID,
 		}
 		if err = t.database.InsertTransaction(ctx, tx); err != nil {
 			return false, err
@@ -33,7 +33,7 @@ func TestSubmitNewTransactionOK(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
-	txHelper := NewTransactionHelper(mdi, mdm)
+	txHelper := NewTransactionHelper(mdi, mdm, WithChainID(1337))
 	ctx := context.Background()
 
 	var txidInserted *fftypes.UUID
@@ -43,6 +43,7 @@ func TestSubmitNewTransactionOK(t *testing.T) {
 		assert.Equal(t, "ns1", transaction.Namespace)
 		assert.Equal(t, fftypes.TransactionTypeBatchPin, transaction.Type)
 		assert.Empty(t, transaction.BlockchainIDs)
+		assert.Equal(t, int64(1337), transaction.ChainID)
 		return true
 	})).Return(nil)
 	mdi.On("InsertEvent", ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
@@ -94,7 +95,7 @@ func TestPersistTransactionNew(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
-	txHelper := NewTransactionHelper(mdi, mdm)
+	txHelper := NewTransactionHelper(mdi, mdm, WithChainID(1337))
 	ctx := context.Background()
 
 	txid := fftypes.NewUUID()
@@ -104,6 +105,7 @@ func TestPersistTransactionNew(t *testing.T) {
 		assert.Equal(t, "ns1", transaction.Namespace)
 		assert.Equal(t, fftypes.TransactionTypeBatchPin, transaction.Type)
 		assert.Equal(t, fftypes.FFStringArray{"0x222222"}, transaction.BlockchainIDs)
+		assert.Equal(t, int64(1337), transaction.ChainID)
 		return true
 	})).Return(nil)
 
@@ -32,6 +32,29 @@ func (_m *Manager) ActivateTokenPool(ctx context.Context, pool *fftypes.TokenPoo
 	return r0
 }
 
+// BulkTransferTokens provides a mock function with given fields: ctx, ns, bulk
+func (_m *Manager) BulkTransferTokens(ctx context.Context, ns string, bulk *fftypes.TokenTransferBulkInput) (*fftypes.TokenTransferBulk, error) {
+	ret := _m.Called(ctx, ns, bulk)
+
+	var r0 *fftypes.TokenTransferBulk
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.TokenTransferBulkInput) *fftypes.TokenTransferBulk); ok {
+		r0 = rf(ctx, ns, bulk)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TokenTransferBulk)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.TokenTransferBulkInput) error); ok {
+		r1 = rf(ctx, ns, bulk)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BurnTokens provides a mock function with given fields: ctx, ns, transfer, waitConfirm
 func (_m *Manager) BurnTokens(ctx context.Context, ns string, transfer *fftypes.TokenTransferInput, waitConfirm bool) (*fftypes.TokenTransfer, error) {
 	ret := _m.Called(ctx, ns, transfer, waitConfirm)
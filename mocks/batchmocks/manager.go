@@ -14,11 +14,53 @@ type Manager struct {
 	mock.Mock
 }
 
+// Assembly provides a mock function with given fields: ns
+func (_m *Manager) Assembly(ns string) *batch.AssemblyResult {
+	ret := _m.Called(ns)
+
+	var r0 *batch.AssemblyResult
+	if rf, ok := ret.Get(0).(func(string) *batch.AssemblyResult); ok {
+		r0 = rf(ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*batch.AssemblyResult)
+		}
+	}
+
+	return r0
+}
+
+// CancelFlushOnArrival provides a mock function with given fields: msgID
+func (_m *Manager) CancelFlushOnArrival(msgID *fftypes.UUID) {
+	_m.Called(msgID)
+}
+
 // Close provides a mock function with given fields:
 func (_m *Manager) Close() {
 	_m.Called()
 }
 
+// Flush provides a mock function with given fields: ns
+func (_m *Manager) Flush(ns string) *batch.FlushResult {
+	ret := _m.Called(ns)
+
+	var r0 *batch.FlushResult
+	if rf, ok := ret.Get(0).(func(string) *batch.FlushResult); ok {
+		r0 = rf(ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*batch.FlushResult)
+		}
+	}
+
+	return r0
+}
+
+// FlushOnArrival provides a mock function with given fields: msgID
+func (_m *Manager) FlushOnArrival(msgID *fftypes.UUID) {
+	_m.Called(msgID)
+}
+
 // NewMessages provides a mock function with given fields:
 func (_m *Manager) NewMessages() chan<- int64 {
 	ret := _m.Called()
@@ -28,6 +28,29 @@ func (_m *Submitter) Name() string {
 	return r0
 }
 
+// PinAccess provides a mock function with given fields: ctx
+func (_m *Submitter) PinAccess(ctx context.Context) (*fftypes.PinAccess, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.PinAccess
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.PinAccess); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.PinAccess)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PrepareOperation provides a mock function with given fields: ctx, op
 func (_m *Submitter) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	ret := _m.Called(ctx, op)
@@ -51,6 +74,29 @@ func (_m *Submitter) PrepareOperation(ctx context.Context, op *fftypes.Operation
 	return r0, r1
 }
 
+// RegisterPinKey provides a mock function with given fields: ctx
+func (_m *Submitter) RegisterPinKey(ctx context.Context) (*fftypes.Operation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.Operation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunOperation provides a mock function with given fields: ctx, op
 func (_m *Submitter) RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (fftypes.JSONObject, bool, error) {
 	ret := _m.Called(ctx, op)
@@ -50,6 +50,20 @@ func (_m *Plugin) Capabilities() *blockchain.Capabilities {
 	return r0
 }
 
+// ChainID provides a mock function with given fields:
+func (_m *Plugin) ChainID() int64 {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
 // DeleteContractListener provides a mock function with given fields: ctx, subscription
 func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
 	ret := _m.Called(ctx, subscription)
@@ -64,6 +78,41 @@ func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *ffty
 	return r0
 }
 
+// DecodeRevertReason provides a mock function with given fields: ctx, opOutput, errors
+func (_m *Plugin) DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool) {
+	ret := _m.Called(ctx, opOutput, errors)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, fftypes.JSONObject, []*fftypes.FFIErrorDefinition) string); ok {
+		r0 = rf(ctx, opOutput, errors)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(context.Context, fftypes.JSONObject, []*fftypes.FFIErrorDefinition) bool); ok {
+		r1 = rf(ctx, opOutput, errors)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// DeployContract provides a mock function with given fields: ctx, operationID, signingKey, definition, contract, constructor, input
+func (_m *Plugin) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error {
+	ret := _m.Called(ctx, operationID, signingKey, definition, contract, constructor, input)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.JSONAny, *fftypes.FFIMethod, []interface{}) error); ok {
+		r0 = rf(ctx, operationID, signingKey, definition, contract, constructor, input)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, generationRequest
 func (_m *Plugin) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, generationRequest)
@@ -110,6 +159,20 @@ func (_m *Plugin) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamVal
 	return r0, r1
 }
 
+// Health provides a mock function with given fields: ctx
+func (_m *Plugin) Health(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, prefix, callbacks, _a3
 func (_m *Plugin) Init(ctx context.Context, prefix config.Prefix, callbacks blockchain.Callbacks, _a3 metrics.Manager) error {
 	ret := _m.Called(ctx, prefix, callbacks, _a3)
@@ -129,13 +192,13 @@ func (_m *Plugin) InitPrefix(prefix config.Prefix) {
 	_m.Called(prefix)
 }
 
-// InvokeContract provides a mock function with given fields: ctx, operationID, signingKey, location, method, input
-func (_m *Plugin) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
-	ret := _m.Called(ctx, operationID, signingKey, location, method, input)
+// InvokeContract provides a mock function with given fields: ctx, operationID, signingKey, location, method, input, errors
+func (_m *Plugin) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error {
+	ret := _m.Called(ctx, operationID, signingKey, location, method, input, errors)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}) error); ok {
-		r0 = rf(ctx, operationID, signingKey, location, method, input)
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}, []*fftypes.FFIErrorDefinition) error); ok {
+		r0 = rf(ctx, operationID, signingKey, location, method, input, errors)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -178,6 +241,43 @@ func (_m *Plugin) NormalizeSigningKey(ctx context.Context, keyRef string) (strin
 	return r0, r1
 }
 
+// PauseContractListener provides a mock function with given fields: ctx, subscription
+func (_m *Plugin) PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.ContractListener) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PinAccess provides a mock function with given fields: ctx, signingKey
+func (_m *Plugin) PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error) {
+	ret := _m.Called(ctx, signingKey)
+
+	var r0 *fftypes.PinAccess
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.PinAccess); ok {
+		r0 = rf(ctx, signingKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.PinAccess)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, signingKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryContract provides a mock function with given fields: ctx, location, method, input
 func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
 	ret := _m.Called(ctx, location, method, input)
@@ -201,6 +301,34 @@ func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	return r0, r1
 }
 
+// RegisterPinKey provides a mock function with given fields: ctx, operationID, signingKey
+func (_m *Plugin) RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error {
+	ret := _m.Called(ctx, operationID, signingKey)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string) error); ok {
+		r0 = rf(ctx, operationID, signingKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResumeContractListener provides a mock function with given fields: ctx, subscription
+func (_m *Plugin) ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.ContractListener) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Start provides a mock function with given fields:
 func (_m *Plugin) Start() error {
 	ret := _m.Called()
@@ -16,6 +16,29 @@ type Manager struct {
 	mock.Mock
 }
 
+// BroadcastCustomDefinition provides a mock function with given fields: ctx, ns, def, waitConfirm
+func (_m *Manager) BroadcastCustomDefinition(ctx context.Context, ns string, def *fftypes.CustomDefinition, waitConfirm bool) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, def, waitConfirm)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.CustomDefinition, bool) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, def, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.CustomDefinition, bool) error); ok {
+		r1 = rf(ctx, ns, def, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BroadcastDatatype provides a mock function with given fields: ctx, ns, datatype, waitConfirm
 func (_m *Manager) BroadcastDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype, waitConfirm bool) (*fftypes.Message, error) {
 	ret := _m.Called(ctx, ns, datatype, waitConfirm)
@@ -100,6 +100,29 @@ func (_m *Manager) DeleteContractListenerByNameOrID(ctx context.Context, ns stri
 	return r0
 }
 
+// DeployContract provides a mock function with given fields: ctx, ns, req
+func (_m *Manager) DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.Operation, error) {
+	ret := _m.Called(ctx, ns, req)
+
+	var r0 *fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.ContractDeployRequest) *fftypes.Operation); ok {
+		r0 = rf(ctx, ns, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.ContractDeployRequest) error); ok {
+		r1 = rf(ctx, ns, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, ns, generationRequest
 func (_m *Manager) GenerateFFI(ctx context.Context, ns string, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, ns, generationRequest)
@@ -201,6 +224,29 @@ func (_m *Manager) GetContractListenerByNameOrID(ctx context.Context, ns string,
 	return r0, r1
 }
 
+// GetContractListenerCheckpoint provides a mock function with given fields: ctx, ns, nameOrID
+func (_m *Manager) GetContractListenerCheckpoint(ctx context.Context, ns string, nameOrID string) (*fftypes.ContractListenerCheckpoint, error) {
+	ret := _m.Called(ctx, ns, nameOrID)
+
+	var r0 *fftypes.ContractListenerCheckpoint
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.ContractListenerCheckpoint); ok {
+		r0 = rf(ctx, ns, nameOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ContractListenerCheckpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, nameOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetContractListeners provides a mock function with given fields: ctx, ns, filter
 func (_m *Manager) GetContractListeners(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ContractListener, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -334,6 +380,11 @@ func (_m *Manager) GetFFIs(ctx context.Context, ns string, filter database.AndFi
 	return r0, r1, r2
 }
 
+// InvalidateQueryCache provides a mock function with given fields: ns, location
+func (_m *Manager) InvalidateQueryCache(ns string, location *fftypes.JSONAny) {
+	_m.Called(ns, location)
+}
+
 // InvokeContract provides a mock function with given fields: ctx, ns, req
 func (_m *Manager) InvokeContract(ctx context.Context, ns string, req *fftypes.ContractCallRequest) (interface{}, error) {
 	ret := _m.Called(ctx, ns, req)
@@ -447,6 +498,29 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 	return r0, r1, r2
 }
 
+// UpdateContractListenerByNameOrID provides a mock function with given fields: ctx, ns, nameOrID, update
+func (_m *Manager) UpdateContractListenerByNameOrID(ctx context.Context, ns string, nameOrID string, update *fftypes.ContractListenerUpdateDTO) (*fftypes.ContractListener, error) {
+	ret := _m.Called(ctx, ns, nameOrID, update)
+
+	var r0 *fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.ContractListenerUpdateDTO) *fftypes.ContractListener); ok {
+		r0 = rf(ctx, ns, nameOrID, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ContractListener)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.ContractListenerUpdateDTO) error); ok {
+		r1 = rf(ctx, ns, nameOrID, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ValidateFFIAndSetPathnames provides a mock function with given fields: ctx, ffi
 func (_m *Manager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *fftypes.FFI) error {
 	ret := _m.Called(ctx, ffi)
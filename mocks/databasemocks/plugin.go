@@ -19,6 +19,20 @@ type Plugin struct {
 	mock.Mock
 }
 
+// ActivateDraftMessage provides a mock function with given fields: ctx, message
+func (_m *Plugin) ActivateDraftMessage(ctx context.Context, message *fftypes.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *database.Capabilities {
 	ret := _m.Called()
@@ -35,6 +49,20 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// CompletePendingMessage provides a mock function with given fields: ctx, message
+func (_m *Plugin) CompletePendingMessage(ctx context.Context, message *fftypes.Message) error {
+	ret := _m.Called(ctx, message)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -77,6 +105,34 @@ func (_m *Plugin) DeleteContractListenerByID(ctx context.Context, id *fftypes.UU
 	return r0
 }
 
+// DeleteEvents provides a mock function with given fields: ctx, filter
+func (_m *Plugin) DeleteEvents(ctx context.Context, filter database.Filter) error {
+	ret := _m.Called(ctx, filter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) error); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteGroupAliasByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteGroupAliasByID(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNamespace provides a mock function with given fields: ctx, id
 func (_m *Plugin) DeleteNamespace(ctx context.Context, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -91,6 +147,41 @@ func (_m *Plugin) DeleteNamespace(ctx context.Context, id *fftypes.UUID) error {
 	return r0
 }
 
+// DeleteNamespaceRecords provides a mock function with given fields: ctx, ns, before
+func (_m *Plugin) DeleteNamespaceRecords(ctx context.Context, ns string, before *fftypes.FFTime) (int64, error) {
+	ret := _m.Called(ctx, ns, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.FFTime) int64); ok {
+		r0 = rf(ctx, ns, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.FFTime) error); ok {
+		r1 = rf(ctx, ns, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteNamespaceSigner provides a mock function with given fields: ctx, ns
+func (_m *Plugin) DeleteNamespaceSigner(ctx context.Context, ns string) error {
+	ret := _m.Called(ctx, ns)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNextPin provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteNextPin(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -133,6 +224,20 @@ func (_m *Plugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name strin
 	return r0
 }
 
+// DeleteOutboxEntry provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteOutboxEntry(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeletePin provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeletePin(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -147,6 +252,20 @@ func (_m *Plugin) DeletePin(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteRoutingRuleByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteRoutingRuleByID(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscriptionByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -161,6 +280,75 @@ func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID)
 	return r0
 }
 
+// DeleteSubscriptionRedelivery provides a mock function with given fields: ctx, subscription, event
+func (_m *Plugin) DeleteSubscriptionRedelivery(ctx context.Context, subscription *fftypes.UUID, event *fftypes.UUID) error {
+	ret := _m.Called(ctx, subscription, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, subscription, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetApprovalRequestByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetApprovalRequestByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ApprovalRequest, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.ApprovalRequest
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.ApprovalRequest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ApprovalRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApprovalRequests provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetApprovalRequests(ctx context.Context, filter database.Filter) ([]*fftypes.ApprovalRequest, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.ApprovalRequest
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.ApprovalRequest); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.ApprovalRequest)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetBatchByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
@@ -294,6 +482,29 @@ func (_m *Plugin) GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID)
 	return r0, r1
 }
 
+// GetBlockchainEventRaw provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetBlockchainEventRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEventRaw, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.BlockchainEventRaw
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BlockchainEventRaw); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BlockchainEventRaw)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockchainEvents provides a mock function with given fields: ctx, filter
 func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -326,13 +537,36 @@ func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filte
 	return r0, r1, r2
 }
 
-// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection
-func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName) ([]*fftypes.ChartHistogram, error) {
-	ret := _m.Called(ctx, ns, intervals, collection)
+// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection, groupBy
+func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName, groupBy string) ([]*fftypes.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, intervals, collection, groupBy)
+
+	var r0 []*fftypes.ChartHistogram
+	if rf, ok := ret.Get(0).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName, string) []*fftypes.ChartHistogram); ok {
+		r0 = rf(ctx, ns, intervals, collection, groupBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.ChartHistogram)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName, string) error); ok {
+		r1 = rf(ctx, ns, intervals, collection, groupBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChartLatencyHistogram provides a mock function with given fields: ctx, ns, intervals, percentiles
+func (_m *Plugin) GetChartLatencyHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, percentiles []float64) ([]*fftypes.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, intervals, percentiles)
 
 	var r0 []*fftypes.ChartHistogram
-	if rf, ok := ret.Get(0).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) []*fftypes.ChartHistogram); ok {
-		r0 = rf(ctx, ns, intervals, collection)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []fftypes.ChartHistogramInterval, []float64) []*fftypes.ChartHistogram); ok {
+		r0 = rf(ctx, ns, intervals, percentiles)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*fftypes.ChartHistogram)
@@ -340,8 +574,8 @@ func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) error); ok {
-		r1 = rf(ctx, ns, intervals, collection)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []fftypes.ChartHistogramInterval, []float64) error); ok {
+		r1 = rf(ctx, ns, intervals, percentiles)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -849,6 +1083,38 @@ func (_m *Plugin) GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FF
 	return r0, r1
 }
 
+// GetFFIErrors provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetFFIErrors(ctx context.Context, filter database.Filter) ([]*fftypes.FFIError, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.FFIError
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FFIError); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.FFIError)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetFFIEvent provides a mock function with given fields: ctx, ns, interfaceID, pathName
 func (_m *Plugin) GetFFIEvent(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*fftypes.FFIEvent, error) {
 	ret := _m.Called(ctx, ns, interfaceID, pathName)
@@ -1014,39 +1280,16 @@ func (_m *Plugin) GetFFIs(ctx context.Context, ns string, filter database.Filter
 	return r0, r1, r2
 }
 
-// GetGroupByHash provides a mock function with given fields: ctx, hash
-func (_m *Plugin) GetGroupByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Group, error) {
-	ret := _m.Called(ctx, hash)
-
-	var r0 *fftypes.Group
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Group); ok {
-		r0 = rf(ctx, hash)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Group)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
-		r1 = rf(ctx, hash)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetGroups provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fftypes.Group, *database.FilterResult, error) {
+// GetFeeSummaries provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetFeeSummaries(ctx context.Context, filter database.Filter) ([]*fftypes.FeeSummary, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Group
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Group); ok {
+	var r0 []*fftypes.FeeSummary
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FeeSummary); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Group)
+			r0 = ret.Get(0).([]*fftypes.FeeSummary)
 		}
 	}
 
@@ -1069,29 +1312,162 @@ func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fft
 	return r0, r1, r2
 }
 
-// GetIdentities provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetIdentities(ctx context.Context, filter database.Filter) ([]*fftypes.Identity, *database.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
+// GetGroupAliasByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetGroupAliasByID(ctx context.Context, id *fftypes.UUID) (*fftypes.GroupAlias, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 []*fftypes.Identity
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Identity); ok {
-		r0 = rf(ctx, filter)
+	var r0 *fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.GroupAlias); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Identity)
+			r0 = ret.Get(0).(*fftypes.GroupAlias)
 		}
 	}
 
-	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*database.FilterResult)
-		}
+		r1 = ret.Error(1)
 	}
 
-	var r2 error
+	return r0, r1
+}
+
+// GetGroupAliasByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetGroupAliasByName(ctx context.Context, ns string, name string) (*fftypes.GroupAlias, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.GroupAlias); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroupAliases provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetGroupAliases(ctx context.Context, filter database.Filter) ([]*fftypes.GroupAlias, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.GroupAlias); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetGroupByHash provides a mock function with given fields: ctx, hash
+func (_m *Plugin) GetGroupByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Group, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *fftypes.Group
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Group); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Group)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroups provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fftypes.Group, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Group
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Group); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Group)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetIdentities provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetIdentities(ctx context.Context, filter database.Filter) ([]*fftypes.Identity, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Identity
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Identity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Identity)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
 	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
 		r2 = rf(ctx, filter)
 	} else {
@@ -1170,6 +1546,29 @@ func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, n
 	return r0, r1
 }
 
+// GetIdentityPrivateProfile provides a mock function with given fields: ctx, identity
+func (_m *Plugin) GetIdentityPrivateProfile(ctx context.Context, identity *fftypes.UUID) (*fftypes.IdentityPrivateProfile, error) {
+	ret := _m.Called(ctx, identity)
+
+	var r0 *fftypes.IdentityPrivateProfile
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.IdentityPrivateProfile); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.IdentityPrivateProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessageByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Message, error) {
 	ret := _m.Called(ctx, id)
@@ -1216,6 +1615,38 @@ func (_m *Plugin) GetMessageIDs(ctx context.Context, filter database.Filter) ([]
 	return r0, r1
 }
 
+// GetMessageRecipients provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessageRecipients(ctx context.Context, filter database.Filter) ([]*fftypes.MessageRecipient, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.MessageRecipient
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.MessageRecipient); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageRecipient)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessages provides a mock function with given fields: ctx, filter
 func (_m *Plugin) GetMessages(ctx context.Context, filter database.Filter) ([]*fftypes.Message, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1326,6 +1757,29 @@ func (_m *Plugin) GetNamespaceByID(ctx context.Context, id *fftypes.UUID) (*ffty
 	return r0, r1
 }
 
+// GetNamespaceSigner provides a mock function with given fields: ctx, ns
+func (_m *Plugin) GetNamespaceSigner(ctx context.Context, ns string) (*fftypes.NamespaceSigner, error) {
+	ret := _m.Called(ctx, ns)
+
+	var r0 *fftypes.NamespaceSigner
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.NamespaceSigner); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceSigner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNamespaces provides a mock function with given fields: ctx, filter
 func (_m *Plugin) GetNamespaces(ctx context.Context, filter database.Filter) ([]*fftypes.Namespace, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1546,6 +2000,61 @@ func (_m *Plugin) GetOffsets(ctx context.Context, filter database.Filter) ([]*ff
 	return r0, r1, r2
 }
 
+// GetOpCallbackByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetOpCallbackByID(ctx context.Context, id *fftypes.UUID) (*fftypes.OpCallback, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.OpCallback
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.OpCallback); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.OpCallback)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpCallbacks provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetOpCallbacks(ctx context.Context, filter database.Filter) ([]*fftypes.OpCallback, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.OpCallback
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.OpCallback); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.OpCallback)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetOperationByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetOperationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Operation, error) {
 	ret := _m.Called(ctx, id)
@@ -1569,6 +2078,29 @@ func (_m *Plugin) GetOperationByID(ctx context.Context, id *fftypes.UUID) (*ffty
 	return r0, r1
 }
 
+// GetOperationOutputByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetOperationOutputByID(ctx context.Context, id *fftypes.UUID) (*fftypes.OperationOutput, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.OperationOutput
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.OperationOutput); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.OperationOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOperations provides a mock function with given fields: ctx, filter
 func (_m *Plugin) GetOperations(ctx context.Context, filter database.Filter) ([]*fftypes.Operation, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1601,6 +2133,61 @@ func (_m *Plugin) GetOperations(ctx context.Context, filter database.Filter) ([]
 	return r0, r1, r2
 }
 
+// GetOutboxEntries provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetOutboxEntries(ctx context.Context, filter database.Filter) ([]*fftypes.OutboxEntry, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.OutboxEntry
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.OutboxEntry); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.OutboxEntry)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetOutboxEntryByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetOutboxEntryByID(ctx context.Context, id *fftypes.UUID) (*fftypes.OutboxEntry, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.OutboxEntry
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.OutboxEntry); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.OutboxEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPins provides a mock function with given fields: ctx, filter
 func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftypes.Pin, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1610,7 +2197,163 @@ func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftyp
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Pin)
+			r0 = ret.Get(0).([]*fftypes.Pin)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetRoutingRuleByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetRoutingRuleByID(ctx context.Context, id *fftypes.UUID) (*fftypes.RoutingRule, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.RoutingRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.RoutingRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRoutingRuleByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetRoutingRuleByName(ctx context.Context, ns string, name string) (*fftypes.RoutingRule, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.RoutingRule); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.RoutingRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRoutingRules provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetRoutingRules(ctx context.Context, filter database.Filter) ([]*fftypes.RoutingRule, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.RoutingRule); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.RoutingRule)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSubscriptionByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetSubscriptionByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Subscription, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Subscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetSubscriptionByName(ctx context.Context, ns string, name string) (*fftypes.Subscription, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionRedeliveries provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetSubscriptionRedeliveries(ctx context.Context, filter database.Filter) ([]*fftypes.SubscriptionRedelivery, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.SubscriptionRedelivery
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.SubscriptionRedelivery); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SubscriptionRedelivery)
 		}
 	}
 
@@ -1633,22 +2376,22 @@ func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftyp
 	return r0, r1, r2
 }
 
-// GetSubscriptionByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetSubscriptionByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Subscription, error) {
-	ret := _m.Called(ctx, id)
+// GetSubscriptionRedelivery provides a mock function with given fields: ctx, subscription, event
+func (_m *Plugin) GetSubscriptionRedelivery(ctx context.Context, subscription *fftypes.UUID, event *fftypes.UUID) (*fftypes.SubscriptionRedelivery, error) {
+	ret := _m.Called(ctx, subscription, event)
 
-	var r0 *fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Subscription); ok {
-		r0 = rf(ctx, id)
+	var r0 *fftypes.SubscriptionRedelivery
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.UUID) *fftypes.SubscriptionRedelivery); ok {
+		r0 = rf(ctx, subscription, event)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Subscription)
+			r0 = ret.Get(0).(*fftypes.SubscriptionRedelivery)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, subscription, event)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1656,39 +2399,48 @@ func (_m *Plugin) GetSubscriptionByID(ctx context.Context, id *fftypes.UUID) (*f
 	return r0, r1
 }
 
-// GetSubscriptionByName provides a mock function with given fields: ctx, ns, name
-func (_m *Plugin) GetSubscriptionByName(ctx context.Context, ns string, name string) (*fftypes.Subscription, error) {
-	ret := _m.Called(ctx, ns, name)
+// GetSubscriptions provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetSubscriptions(ctx context.Context, filter database.Filter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 *fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
-		r0 = rf(ctx, ns, name)
+	var r0 []*fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Subscription); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Subscription)
+			r0 = ret.Get(0).([]*fftypes.Subscription)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, name)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// GetSubscriptions provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetSubscriptions(ctx context.Context, filter database.Filter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+// GetSyncChanges provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetSyncChanges(ctx context.Context, filter database.Filter) ([]*fftypes.SyncChangeRef, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Subscription); ok {
+	var r0 []*fftypes.SyncChangeRef
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.SyncChangeRef); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Subscription)
+			r0 = ret.Get(0).([]*fftypes.SyncChangeRef)
 		}
 	}
 
@@ -2239,6 +2991,20 @@ func (_m *Plugin) InitPrefix(prefix config.Prefix) {
 	_m.Called(prefix)
 }
 
+// InsertApprovalRequest provides a mock function with given fields: ctx, req
+func (_m *Plugin) InsertApprovalRequest(ctx context.Context, req *fftypes.ApprovalRequest) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.ApprovalRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertBlob provides a mock function with given fields: ctx, blob
 func (_m *Plugin) InsertBlob(ctx context.Context, blob *fftypes.Blob) error {
 	ret := _m.Called(ctx, blob)
@@ -2267,6 +3033,20 @@ func (_m *Plugin) InsertBlockchainEvent(ctx context.Context, event *fftypes.Bloc
 	return r0
 }
 
+// InsertBlockchainEventRaw provides a mock function with given fields: ctx, raw
+func (_m *Plugin) InsertBlockchainEventRaw(ctx context.Context, raw *fftypes.BlockchainEventRaw) error {
+	ret := _m.Called(ctx, raw)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.BlockchainEventRaw) error); ok {
+		r0 = rf(ctx, raw)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertDataArray provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertDataArray(ctx context.Context, data fftypes.DataArray) error {
 	ret := _m.Called(ctx, data)
@@ -2323,6 +3103,27 @@ func (_m *Plugin) InsertNextPin(ctx context.Context, nextpin *fftypes.NextPin) e
 	return r0
 }
 
+// InsertOpCallback provides a mock function with given fields: ctx, callback, hooks
+func (_m *Plugin) InsertOpCallback(ctx context.Context, callback *fftypes.OpCallback, hooks ...database.PostCompletionHook) error {
+	_va := make([]interface{}, len(hooks))
+	for _i := range hooks {
+		_va[_i] = hooks[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, callback)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.OpCallback, ...database.PostCompletionHook) error); ok {
+		r0 = rf(ctx, callback, hooks...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertOperation provides a mock function with given fields: ctx, operation, hooks
 func (_m *Plugin) InsertOperation(ctx context.Context, operation *fftypes.Operation, hooks ...database.PostCompletionHook) error {
 	_va := make([]interface{}, len(hooks))
@@ -2344,6 +3145,27 @@ func (_m *Plugin) InsertOperation(ctx context.Context, operation *fftypes.Operat
 	return r0
 }
 
+// InsertOutboxEntry provides a mock function with given fields: ctx, entry, hooks
+func (_m *Plugin) InsertOutboxEntry(ctx context.Context, entry *fftypes.OutboxEntry, hooks ...database.PostCompletionHook) error {
+	_va := make([]interface{}, len(hooks))
+	for _i := range hooks {
+		_va[_i] = hooks[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, entry)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.OutboxEntry, ...database.PostCompletionHook) error); ok {
+		r0 = rf(ctx, entry, hooks...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertPins provides a mock function with given fields: ctx, pins
 func (_m *Plugin) InsertPins(ctx context.Context, pins []*fftypes.Pin) error {
 	ret := _m.Called(ctx, pins)
@@ -2400,6 +3222,20 @@ func (_m *Plugin) ReplaceMessage(ctx context.Context, message *fftypes.Message)
 	return r0
 }
 
+// ReserveNonces provides a mock function with given fields: ctx, _a1, count
+func (_m *Plugin) ReserveNonces(ctx context.Context, _a1 *fftypes.Nonce, count int64) error {
+	ret := _m.Called(ctx, _a1, count)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Nonce, int64) error); ok {
+		r0 = rf(ctx, _a1, count)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResolveOperation provides a mock function with given fields: ctx, id, status, errorMsg, output
 func (_m *Plugin) ResolveOperation(ctx context.Context, id *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) error {
 	ret := _m.Called(ctx, id, status, errorMsg, output)
@@ -2428,6 +3264,66 @@ func (_m *Plugin) RunAsGroup(ctx context.Context, fn func(context.Context) error
 	return r0
 }
 
+// ScheduleDraftMessage provides a mock function with given fields: ctx, msgID, sendAt
+func (_m *Plugin) ScheduleDraftMessage(ctx context.Context, msgID *fftypes.UUID, sendAt *fftypes.FFTime) error {
+	ret := _m.Called(ctx, msgID, sendAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.FFTime) error); ok {
+		r0 = rf(ctx, msgID, sendAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: ctx, query, filter
+func (_m *Plugin) Search(ctx context.Context, query string, filter database.Filter) ([]*fftypes.SearchResult, *database.FilterResult, error) {
+	ret := _m.Called(ctx, query, filter)
+
+	var r0 []*fftypes.SearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.Filter) []*fftypes.SearchResult); ok {
+		r0 = rf(ctx, query, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SearchResult)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, query, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.Filter) error); ok {
+		r2 = rf(ctx, query, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateApprovalRequest provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateApprovalRequest(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateBatch provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateBatch(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2568,6 +3464,20 @@ func (_m *Plugin) UpdateOffset(ctx context.Context, rowID int64, update database
 	return r0
 }
 
+// UpdateOpCallback provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateOpCallback(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateOperation provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateOperation(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2582,6 +3492,20 @@ func (_m *Plugin) UpdateOperation(ctx context.Context, id *fftypes.UUID, update
 	return r0
 }
 
+// UpdateOutboxEntry provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateOutboxEntry(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePins provides a mock function with given fields: ctx, filter, update
 func (_m *Plugin) UpdatePins(ctx context.Context, filter database.Filter, update database.Update) error {
 	ret := _m.Called(ctx, filter, update)
@@ -2750,6 +3674,20 @@ func (_m *Plugin) UpsertFFI(ctx context.Context, cd *fftypes.FFI) error {
 	return r0
 }
 
+// UpsertFFIError provides a mock function with given fields: ctx, errorDef
+func (_m *Plugin) UpsertFFIError(ctx context.Context, errorDef *fftypes.FFIError) error {
+	ret := _m.Called(ctx, errorDef)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.FFIError) error); ok {
+		r0 = rf(ctx, errorDef)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertFFIEvent provides a mock function with given fields: ctx, method
 func (_m *Plugin) UpsertFFIEvent(ctx context.Context, method *fftypes.FFIEvent) error {
 	ret := _m.Called(ctx, method)
@@ -2778,6 +3716,20 @@ func (_m *Plugin) UpsertFFIMethod(ctx context.Context, method *fftypes.FFIMethod
 	return r0
 }
 
+// UpsertFeeSummary provides a mock function with given fields: ctx, summary
+func (_m *Plugin) UpsertFeeSummary(ctx context.Context, summary *fftypes.FeeSummary) error {
+	ret := _m.Called(ctx, summary)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.FeeSummary) error); ok {
+		r0 = rf(ctx, summary)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertGroup provides a mock function with given fields: ctx, data, optimization
 func (_m *Plugin) UpsertGroup(ctx context.Context, data *fftypes.Group, optimization database.UpsertOptimization) error {
 	ret := _m.Called(ctx, data, optimization)
@@ -2792,6 +3744,20 @@ func (_m *Plugin) UpsertGroup(ctx context.Context, data *fftypes.Group, optimiza
 	return r0
 }
 
+// UpsertGroupAlias provides a mock function with given fields: ctx, alias, allowExisting
+func (_m *Plugin) UpsertGroupAlias(ctx context.Context, alias *fftypes.GroupAlias, allowExisting bool) error {
+	ret := _m.Called(ctx, alias, allowExisting)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.GroupAlias, bool) error); ok {
+		r0 = rf(ctx, alias, allowExisting)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertIdentity provides a mock function with given fields: ctx, data, optimization
 func (_m *Plugin) UpsertIdentity(ctx context.Context, data *fftypes.Identity, optimization database.UpsertOptimization) error {
 	ret := _m.Called(ctx, data, optimization)
@@ -2806,6 +3772,20 @@ func (_m *Plugin) UpsertIdentity(ctx context.Context, data *fftypes.Identity, op
 	return r0
 }
 
+// UpsertIdentityPrivateProfile provides a mock function with given fields: ctx, profile
+func (_m *Plugin) UpsertIdentityPrivateProfile(ctx context.Context, profile *fftypes.IdentityPrivateProfile) error {
+	ret := _m.Called(ctx, profile)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.IdentityPrivateProfile) error); ok {
+		r0 = rf(ctx, profile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertMessage provides a mock function with given fields: ctx, message, optimization
 func (_m *Plugin) UpsertMessage(ctx context.Context, message *fftypes.Message, optimization database.UpsertOptimization) error {
 	ret := _m.Called(ctx, message, optimization)
@@ -2820,6 +3800,20 @@ func (_m *Plugin) UpsertMessage(ctx context.Context, message *fftypes.Message, o
 	return r0
 }
 
+// UpsertMessageRecipient provides a mock function with given fields: ctx, recipient
+func (_m *Plugin) UpsertMessageRecipient(ctx context.Context, recipient *fftypes.MessageRecipient) error {
+	ret := _m.Called(ctx, recipient)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.MessageRecipient) error); ok {
+		r0 = rf(ctx, recipient)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertNamespace provides a mock function with given fields: ctx, data, allowExisting
 func (_m *Plugin) UpsertNamespace(ctx context.Context, data *fftypes.Namespace, allowExisting bool) error {
 	ret := _m.Called(ctx, data, allowExisting)
@@ -2834,8 +3828,22 @@ func (_m *Plugin) UpsertNamespace(ctx context.Context, data *fftypes.Namespace,
 	return r0
 }
 
-// UpsertNonceNext provides a mock function with given fields: ctx, _a1
-func (_m *Plugin) UpsertNonceNext(ctx context.Context, _a1 *fftypes.Nonce) error {
+// UpsertNamespaceSigner provides a mock function with given fields: ctx, signer
+func (_m *Plugin) UpsertNamespaceSigner(ctx context.Context, signer *fftypes.NamespaceSigner) error {
+	ret := _m.Called(ctx, signer)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.NamespaceSigner) error); ok {
+		r0 = rf(ctx, signer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertNonce provides a mock function with given fields: ctx, _a1
+func (_m *Plugin) UpsertNonce(ctx context.Context, _a1 *fftypes.Nonce) error {
 	ret := _m.Called(ctx, _a1)
 
 	var r0 error
@@ -2862,6 +3870,20 @@ func (_m *Plugin) UpsertOffset(ctx context.Context, data *fftypes.Offset, allowE
 	return r0
 }
 
+// UpsertOperationOutput provides a mock function with given fields: ctx, output
+func (_m *Plugin) UpsertOperationOutput(ctx context.Context, output *fftypes.OperationOutput) error {
+	ret := _m.Called(ctx, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.OperationOutput) error); ok {
+		r0 = rf(ctx, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertPin provides a mock function with given fields: ctx, parked
 func (_m *Plugin) UpsertPin(ctx context.Context, parked *fftypes.Pin) error {
 	ret := _m.Called(ctx, parked)
@@ -2876,6 +3898,20 @@ func (_m *Plugin) UpsertPin(ctx context.Context, parked *fftypes.Pin) error {
 	return r0
 }
 
+// UpsertRoutingRule provides a mock function with given fields: ctx, rule, allowExisting
+func (_m *Plugin) UpsertRoutingRule(ctx context.Context, rule *fftypes.RoutingRule, allowExisting bool) error {
+	ret := _m.Called(ctx, rule, allowExisting)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.RoutingRule, bool) error); ok {
+		r0 = rf(ctx, rule, allowExisting)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertSubscription provides a mock function with given fields: ctx, data, allowExisting
 func (_m *Plugin) UpsertSubscription(ctx context.Context, data *fftypes.Subscription, allowExisting bool) error {
 	ret := _m.Called(ctx, data, allowExisting)
@@ -2890,6 +3926,20 @@ func (_m *Plugin) UpsertSubscription(ctx context.Context, data *fftypes.Subscrip
 	return r0
 }
 
+// UpsertSubscriptionRedelivery provides a mock function with given fields: ctx, redelivery
+func (_m *Plugin) UpsertSubscriptionRedelivery(ctx context.Context, redelivery *fftypes.SubscriptionRedelivery) error {
+	ret := _m.Called(ctx, redelivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.SubscriptionRedelivery) error); ok {
+		r0 = rf(ctx, redelivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertTokenApproval provides a mock function with given fields: ctx, approval
 func (_m *Plugin) UpsertTokenApproval(ctx context.Context, approval *fftypes.TokenApproval) error {
 	ret := _m.Called(ctx, approval)
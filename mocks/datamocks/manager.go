@@ -18,6 +18,29 @@ type Manager struct {
 	mock.Mock
 }
 
+// AlternateBlobs provides a mock function with given fields: ctx, blob
+func (_m *Manager) AlternateBlobs(ctx context.Context, blob *fftypes.Blob) ([]*fftypes.Blob, error) {
+	ret := _m.Called(ctx, blob)
+
+	var r0 []*fftypes.Blob
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Blob) []*fftypes.Blob); ok {
+		r0 = rf(ctx, blob)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Blob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Blob) error); ok {
+		r1 = rf(ctx, blob)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckDatatype provides a mock function with given fields: ctx, ns, datatype
 func (_m *Manager) CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error {
 	ret := _m.Called(ctx, ns, datatype)
@@ -170,6 +193,38 @@ func (_m *Manager) HydrateBatch(ctx context.Context, persistedBatch *fftypes.Bat
 	return r0, r1
 }
 
+// Limits provides a mock function with given fields: ns
+func (_m *Manager) Limits(ns string) *fftypes.MessageLimits {
+	ret := _m.Called(ns)
+
+	var r0 *fftypes.MessageLimits
+	if rf, ok := ret.Get(0).(func(string) *fftypes.MessageLimits); ok {
+		r0 = rf(ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageLimits)
+		}
+	}
+
+	return r0
+}
+
+// LimitsStatus provides a mock function with given fields:
+func (_m *Manager) LimitsStatus() *fftypes.LimitsStatus {
+	ret := _m.Called()
+
+	var r0 *fftypes.LimitsStatus
+	if rf, ok := ret.Get(0).(func() *fftypes.LimitsStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.LimitsStatus)
+		}
+	}
+
+	return r0
+}
+
 // PeekMessageCache provides a mock function with given fields: ctx, id, options
 func (_m *Manager) PeekMessageCache(ctx context.Context, id *fftypes.UUID, options ...data.CacheReadOption) (*fftypes.Message, fftypes.DataArray) {
 	_va := make([]interface{}, len(options))
@@ -216,6 +271,27 @@ func (_m *Manager) ResolveInlineData(ctx context.Context, msg *data.NewMessage)
 	return r0
 }
 
+// ResolvePendingData provides a mock function with given fields: ctx, msg
+func (_m *Manager) ResolvePendingData(ctx context.Context, msg *fftypes.Message) (bool, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Message) bool); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Message) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateMessageCache provides a mock function with given fields: msg, _a1
 func (_m *Manager) UpdateMessageCache(msg *fftypes.Message, _a1 fftypes.DataArray) {
 	_m.Called(msg, _a1)
@@ -298,6 +374,27 @@ func (_m *Manager) ValidateAll(ctx context.Context, _a1 fftypes.DataArray) (bool
 	return r0, r1
 }
 
+// ValidateValue provides a mock function with given fields: ctx, ns, datatype, value
+func (_m *Manager) ValidateValue(ctx context.Context, ns string, datatype *fftypes.DatatypeRef, value *fftypes.JSONAny) (bool, error) {
+	ret := _m.Called(ctx, ns, datatype, value)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.DatatypeRef, *fftypes.JSONAny) bool); ok {
+		r0 = rf(ctx, ns, datatype, value)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.DatatypeRef, *fftypes.JSONAny) error); ok {
+		r1 = rf(ctx, ns, datatype, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyNamespaceExists provides a mock function with given fields: ctx, ns
 func (_m *Manager) VerifyNamespaceExists(ctx context.Context, ns string) error {
 	ret := _m.Called(ctx, ns)
@@ -115,6 +115,43 @@ func (_m *DefinitionHandlers) HandleDefinitionBroadcast(ctx context.Context, sta
 	return r0, r1
 }
 
+// HandleIdentityPrivateProfile provides a mock function with given fields: ctx, state, msg, data
+func (_m *DefinitionHandlers) HandleIdentityPrivateProfile(ctx context.Context, state definitions.DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray) (definitions.HandlerResult, error) {
+	ret := _m.Called(ctx, state, msg, data)
+
+	var r0 definitions.HandlerResult
+	if rf, ok := ret.Get(0).(func(context.Context, definitions.DefinitionBatchState, *fftypes.Message, fftypes.DataArray) definitions.HandlerResult); ok {
+		r0 = rf(ctx, state, msg, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(definitions.HandlerResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, definitions.DefinitionBatchState, *fftypes.Message, fftypes.DataArray) error); ok {
+		r1 = rf(ctx, state, msg, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterCustomHandler provides a mock function with given fields: ctx, tag, handler
+func (_m *DefinitionHandlers) RegisterCustomHandler(ctx context.Context, tag string, handler definitions.CustomHandler) error {
+	ret := _m.Called(ctx, tag, handler)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, definitions.CustomHandler) error); ok {
+		r0 = rf(ctx, tag, handler)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResolveInitGroup provides a mock function with given fields: ctx, msg
 func (_m *DefinitionHandlers) ResolveInitGroup(ctx context.Context, msg *fftypes.Message) (*fftypes.Group, error) {
 	ret := _m.Called(ctx, msg)
@@ -138,7 +175,7 @@ func (_m *DefinitionHandlers) ResolveInitGroup(ctx context.Context, msg *fftypes
 	return r0, r1
 }
 
-// SendReply provides a mock function with given fields: ctx, event, reply
-func (_m *DefinitionHandlers) SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut) {
-	_m.Called(ctx, event, reply)
+// SendReply provides a mock function with given fields: ctx, event, reply, replyTo
+func (_m *DefinitionHandlers) SendReply(ctx context.Context, event *fftypes.Event, reply *fftypes.MessageInOut, replyTo *fftypes.ReplyRouting) {
+	_m.Called(ctx, event, reply, replyTo)
 }
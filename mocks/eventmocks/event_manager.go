@@ -9,6 +9,8 @@ import (
 
 	dataexchange "github.com/hyperledger/firefly/pkg/dataexchange"
 
+	events "github.com/hyperledger/firefly/pkg/events"
+
 	fftypes "github.com/hyperledger/firefly/pkg/fftypes"
 
 	mock "github.com/stretchr/testify/mock"
@@ -127,6 +129,29 @@ func (_m *EventManager) DeletedSubscriptions() chan<- *fftypes.UUID {
 	return r0
 }
 
+// GetSubscriptionStatus provides a mock function with given fields: ctx, subDef
+func (_m *EventManager) GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	ret := _m.Called(ctx, subDef)
+
+	var r0 *fftypes.SubscriptionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Subscription) *fftypes.SubscriptionStatus); ok {
+		r0 = rf(ctx, subDef)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SubscriptionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Subscription) error); ok {
+		r1 = rf(ctx, subDef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MessageReceived provides a mock function with given fields: dx, peerID, data
 func (_m *EventManager) MessageReceived(dx dataexchange.Plugin, peerID string, data []byte) (string, error) {
 	ret := _m.Called(dx, peerID, data)
@@ -224,6 +249,22 @@ func (_m *EventManager) PrivateBLOBReceived(dx dataexchange.Plugin, peerID strin
 	return r0
 }
 
+// RoutingRuleChanges provides a mock function with given fields:
+func (_m *EventManager) RoutingRuleChanges() chan<- string {
+	ret := _m.Called()
+
+	var r0 chan<- string
+	if rf, ok := ret.Get(0).(func() chan<- string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(chan<- string)
+		}
+	}
+
+	return r0
+}
+
 // SharedStorageBLOBDownloaded provides a mock function with given fields: ss, hash, size, payloadRef
 func (_m *EventManager) SharedStorageBLOBDownloaded(ss sharedstorage.Plugin, hash fftypes.Bytes32, size int64, payloadRef string) error {
 	ret := _m.Called(ss, hash, size, payloadRef)
@@ -291,6 +332,20 @@ func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	return r0
 }
 
+// TokenPoolBackfillProgress provides a mock function with given fields: ti, progress
+func (_m *EventManager) TokenPoolBackfillProgress(ti tokens.Plugin, progress *tokens.TokenPoolBackfillProgress) error {
+	ret := _m.Called(ti, progress)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(tokens.Plugin, *tokens.TokenPoolBackfillProgress) error); ok {
+		r0 = rf(ti, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TokenPoolCreated provides a mock function with given fields: ti, pool
 func (_m *EventManager) TokenPoolCreated(ti tokens.Plugin, pool *tokens.TokenPool) error {
 	ret := _m.Called(ti, pool)
@@ -347,6 +402,36 @@ func (_m *EventManager) TransferResult(dx dataexchange.Plugin, trackingID string
 	return r0
 }
 
+// Transports provides a mock function with given fields:
+func (_m *EventManager) Transports() map[string]events.Plugin {
+	ret := _m.Called()
+
+	var r0 map[string]events.Plugin
+	if rf, ok := ret.Get(0).(func() map[string]events.Plugin); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]events.Plugin)
+		}
+	}
+
+	return r0
+}
+
+// WaitForCondition provides a mock function with given fields: ctx, check
+func (_m *EventManager) WaitForCondition(ctx context.Context, check func() (bool, error)) error {
+	ret := _m.Called(ctx, check)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func() (bool, error)) error); ok {
+		r0 = rf(ctx, check)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *EventManager) WaitStop() {
 	_m.Called()
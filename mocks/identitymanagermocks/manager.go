@@ -121,6 +121,20 @@ func (_m *Manager) CachedVerifierLookup(ctx context.Context, vType fftypes.FFEnu
 	return r0, r1
 }
 
+// DeleteNamespaceSigner provides a mock function with given fields: ctx, namespace
+func (_m *Manager) DeleteNamespaceSigner(ctx context.Context, namespace string) error {
+	ret := _m.Called(ctx, namespace)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindIdentityForVerifier provides a mock function with given fields: ctx, iTypes, namespace, verifier
 func (_m *Manager) FindIdentityForVerifier(ctx context.Context, iTypes []fftypes.FFEnum, namespace string, verifier *fftypes.VerifierRef) (*fftypes.Identity, error) {
 	ret := _m.Called(ctx, iTypes, namespace, verifier)
@@ -144,6 +158,29 @@ func (_m *Manager) FindIdentityForVerifier(ctx context.Context, iTypes []fftypes
 	return r0, r1
 }
 
+// GetNamespaceSigner provides a mock function with given fields: ctx, namespace
+func (_m *Manager) GetNamespaceSigner(ctx context.Context, namespace string) (*fftypes.NamespaceSigner, error) {
+	ret := _m.Called(ctx, namespace)
+
+	var r0 *fftypes.NamespaceSigner
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.NamespaceSigner); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceSigner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNodeOwnerBlockchainKey provides a mock function with given fields: ctx
 func (_m *Manager) GetNodeOwnerBlockchainKey(ctx context.Context) (*fftypes.VerifierRef, error) {
 	ret := _m.Called(ctx)
@@ -262,6 +299,50 @@ func (_m *Manager) ResolveNodeOwnerSigningIdentity(ctx context.Context, msgSigne
 	return r0
 }
 
+// SetNamespaceSigner provides a mock function with given fields: ctx, namespace, signerRef
+func (_m *Manager) SetNamespaceSigner(ctx context.Context, namespace string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error) {
+	ret := _m.Called(ctx, namespace, signerRef)
+
+	var r0 *fftypes.NamespaceSigner
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SignerRef) *fftypes.NamespaceSigner); ok {
+		r0 = rf(ctx, namespace, signerRef)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceSigner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SignerRef) error); ok {
+		r1 = rf(ctx, namespace, signerRef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateProfile provides a mock function with given fields: ctx, identity
+func (_m *Manager) ValidateProfile(ctx context.Context, identity *fftypes.Identity) (bool, error) {
+	ret := _m.Called(ctx, identity)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Identity) bool); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Identity) error); ok {
+		r1 = rf(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyIdentityChain provides a mock function with given fields: ctx, _a1
 func (_m *Manager) VerifyIdentityChain(ctx context.Context, _a1 *fftypes.Identity) (*fftypes.Identity, bool, error) {
 	ret := _m.Called(ctx, _a1)
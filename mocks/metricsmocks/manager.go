@@ -20,11 +20,21 @@ func (_m *Manager) AddTime(id string) {
 	_m.Called(id)
 }
 
+// AggregatorPinsProcessed provides a mock function with given fields: count
+func (_m *Manager) AggregatorPinsProcessed(count int) {
+	_m.Called(count)
+}
+
 // BlockchainEvent provides a mock function with given fields: location, signature
 func (_m *Manager) BlockchainEvent(location string, signature string) {
 	_m.Called(location, signature)
 }
 
+// BlockchainOperationCompleted provides a mock function with given fields: op, status
+func (_m *Manager) BlockchainOperationCompleted(op *fftypes.Operation, status fftypes.OpStatus) {
+	_m.Called(op, status)
+}
+
 // BlockchainQuery provides a mock function with given fields: location, methodName
 func (_m *Manager) BlockchainQuery(location string, methodName string) {
 	_m.Called(location, methodName)
@@ -45,6 +55,11 @@ func (_m *Manager) DeleteTime(id string) {
 	_m.Called(id)
 }
 
+// EventLoopStalled provides a mock function with given fields: loop
+func (_m *Manager) EventLoopStalled(loop string) {
+	_m.Called(loop)
+}
+
 // GetTime provides a mock function with given fields: id
 func (_m *Manager) GetTime(id string) time.Time {
 	ret := _m.Called(id)
@@ -73,9 +88,9 @@ func (_m *Manager) IsMetricsEnabled() bool {
 	return r0
 }
 
-// MessageConfirmed provides a mock function with given fields: msg, eventType
-func (_m *Manager) MessageConfirmed(msg *fftypes.Message, eventType fftypes.FFEnum) {
-	_m.Called(msg, eventType)
+// MessageConfirmed provides a mock function with given fields: msg, tx, eventType
+func (_m *Manager) MessageConfirmed(msg *fftypes.Message, tx *fftypes.UUID, eventType fftypes.FFEnum) {
+	_m.Called(msg, tx, eventType)
 }
 
 // MessageSubmitted provides a mock function with given fields: msg
@@ -97,11 +112,36 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// SubscriptionDeleted provides a mock function with given fields: ns, name
+func (_m *Manager) SubscriptionDeleted(ns string, name string) {
+	_m.Called(ns, name)
+}
+
+// SubscriptionDelivered provides a mock function with given fields: ns, name
+func (_m *Manager) SubscriptionDelivered(ns string, name string) {
+	_m.Called(ns, name)
+}
+
+// SubscriptionRedelivered provides a mock function with given fields: ns, name
+func (_m *Manager) SubscriptionRedelivered(ns string, name string) {
+	_m.Called(ns, name)
+}
+
+// SubscriptionStatus provides a mock function with given fields: status
+func (_m *Manager) SubscriptionStatus(status *fftypes.SubscriptionStatus) {
+	_m.Called(status)
+}
+
 // TransferConfirmed provides a mock function with given fields: transfer
 func (_m *Manager) TransferConfirmed(transfer *fftypes.TokenTransfer) {
 	_m.Called(transfer)
 }
 
+// TransferDropped provides a mock function with given fields: pool
+func (_m *Manager) TransferDropped(pool *fftypes.TokenPool) {
+	_m.Called(pool)
+}
+
 // TransferSubmitted provides a mock function with given fields: transfer
 func (_m *Manager) TransferSubmitted(transfer *fftypes.TokenTransfer) {
 	_m.Called(transfer)
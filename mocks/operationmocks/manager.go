@@ -30,6 +30,20 @@ func (_m *Manager) AddOrReuseOperation(ctx context.Context, op *fftypes.Operatio
 	return r0
 }
 
+// DeliverCallback provides a mock function with given fields: ctx, op
+func (_m *Manager) DeliverCallback(ctx context.Context, op *fftypes.Operation) error {
+	ret := _m.Called(ctx, op)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Operation) error); ok {
+		r0 = rf(ctx, op)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PrepareOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	ret := _m.Called(ctx, op)
@@ -53,6 +67,20 @@ func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation)
 	return r0, r1
 }
 
+// QueueOperation provides a mock function with given fields: ctx, op
+func (_m *Manager) QueueOperation(ctx context.Context, op *fftypes.Operation) error {
+	ret := _m.Called(ctx, op)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Operation) error); ok {
+		r0 = rf(ctx, op)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RegisterHandler provides a mock function with given fields: ctx, handler, ops
 func (_m *Manager) RegisterHandler(ctx context.Context, handler operations.OperationHandler, ops []fftypes.FFEnum) {
 	_m.Called(ctx, handler, ops)
@@ -101,3 +129,22 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 
 	return r0
 }
+
+// Start provides a mock function with given fields:
+func (_m *Manager) Start() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WaitStop provides a mock function with given fields:
+func (_m *Manager) WaitStop() {
+	_m.Called()
+}
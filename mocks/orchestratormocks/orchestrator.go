@@ -3,9 +3,13 @@
 package orchestratormocks
 
 import (
+	approvals "github.com/hyperledger/firefly/internal/approvals"
+
 	assets "github.com/hyperledger/firefly/internal/assets"
 	batch "github.com/hyperledger/firefly/internal/batch"
 
+	batchmigration "github.com/hyperledger/firefly/internal/batchmigration"
+
 	broadcast "github.com/hyperledger/firefly/internal/broadcast"
 
 	context "context"
@@ -14,12 +18,18 @@ import (
 
 	data "github.com/hyperledger/firefly/internal/data"
 
+	dataexport "github.com/hyperledger/firefly/internal/dataexport"
+
+	definitions "github.com/hyperledger/firefly/pkg/definitions"
+
 	database "github.com/hyperledger/firefly/pkg/database"
 
 	events "github.com/hyperledger/firefly/internal/events"
 
 	fftypes "github.com/hyperledger/firefly/pkg/fftypes"
 
+	messageimport "github.com/hyperledger/firefly/internal/messageimport"
+
 	metrics "github.com/hyperledger/firefly/internal/metrics"
 
 	mock "github.com/stretchr/testify/mock"
@@ -36,6 +46,45 @@ type Orchestrator struct {
 	mock.Mock
 }
 
+// ActivateDraftMessage provides a mock function with given fields: ctx, ns, id, input
+func (_m *Orchestrator) ActivateDraftMessage(ctx context.Context, ns string, id string, input *fftypes.DraftActivation) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, id, input)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.DraftActivation) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, id, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.DraftActivation) error); ok {
+		r1 = rf(ctx, ns, id, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Approvals provides a mock function with given fields:
+func (_m *Orchestrator) Approvals() approvals.Manager {
+	ret := _m.Called()
+
+	var r0 approvals.Manager
+	if rf, ok := ret.Get(0).(func() approvals.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(approvals.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Assets provides a mock function with given fields:
 func (_m *Orchestrator) Assets() assets.Manager {
 	ret := _m.Called()
@@ -68,6 +117,22 @@ func (_m *Orchestrator) BatchManager() batch.Manager {
 	return r0
 }
 
+// BatchMigration provides a mock function with given fields:
+func (_m *Orchestrator) BatchMigration() batchmigration.Manager {
+	ret := _m.Called()
+
+	var r0 batchmigration.Manager
+	if rf, ok := ret.Get(0).(func() batchmigration.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(batchmigration.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Broadcast provides a mock function with given fields:
 func (_m *Orchestrator) Broadcast() broadcast.Manager {
 	ret := _m.Called()
@@ -84,6 +149,29 @@ func (_m *Orchestrator) Broadcast() broadcast.Manager {
 	return r0
 }
 
+// BroadcastContractAPI provides a mock function with given fields: ctx, httpServerURL, ns, api, waitConfirm
+func (_m *Orchestrator) BroadcastContractAPI(ctx context.Context, httpServerURL string, ns string, api *fftypes.ContractAPIWithListeners, waitConfirm bool) (*fftypes.ContractAPIWithListeners, error) {
+	ret := _m.Called(ctx, httpServerURL, ns, api, waitConfirm)
+
+	var r0 *fftypes.ContractAPIWithListeners
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.ContractAPIWithListeners, bool) *fftypes.ContractAPIWithListeners); ok {
+		r0 = rf(ctx, httpServerURL, ns, api, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ContractAPIWithListeners)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.ContractAPIWithListeners, bool) error); ok {
+		r1 = rf(ctx, httpServerURL, ns, api, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Contracts provides a mock function with given fields:
 func (_m *Orchestrator) Contracts() contracts.Manager {
 	ret := _m.Called()
@@ -100,6 +188,29 @@ func (_m *Orchestrator) Contracts() contracts.Manager {
 	return r0
 }
 
+// CreateRoutingRule provides a mock function with given fields: ctx, ns, rule
+func (_m *Orchestrator) CreateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error) {
+	ret := _m.Called(ctx, ns, rule)
+
+	var r0 *fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.RoutingRule) *fftypes.RoutingRule); ok {
+		r0 = rf(ctx, ns, rule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.RoutingRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.RoutingRule) error); ok {
+		r1 = rf(ctx, ns, rule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateSubscription provides a mock function with given fields: ctx, ns, subDef
 func (_m *Orchestrator) CreateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error) {
 	ret := _m.Called(ctx, ns, subDef)
@@ -123,6 +234,29 @@ func (_m *Orchestrator) CreateSubscription(ctx context.Context, ns string, subDe
 	return r0, r1
 }
 
+// CreateUpdateRoutingRule provides a mock function with given fields: ctx, ns, rule
+func (_m *Orchestrator) CreateUpdateRoutingRule(ctx context.Context, ns string, rule *fftypes.RoutingRule) (*fftypes.RoutingRule, error) {
+	ret := _m.Called(ctx, ns, rule)
+
+	var r0 *fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.RoutingRule) *fftypes.RoutingRule); ok {
+		r0 = rf(ctx, ns, rule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.RoutingRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.RoutingRule) error); ok {
+		r1 = rf(ctx, ns, rule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateUpdateSubscription provides a mock function with given fields: ctx, ns, subDef
 func (_m *Orchestrator) CreateUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error) {
 	ret := _m.Called(ctx, ns, subDef)
@@ -162,6 +296,22 @@ func (_m *Orchestrator) Data() data.Manager {
 	return r0
 }
 
+// DataExport provides a mock function with given fields:
+func (_m *Orchestrator) DataExport() dataexport.Manager {
+	ret := _m.Called()
+
+	var r0 dataexport.Manager
+	if rf, ok := ret.Get(0).(func() dataexport.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(dataexport.Manager)
+		}
+	}
+
+	return r0
+}
+
 // DeleteConfigRecord provides a mock function with given fields: ctx, key
 func (_m *Orchestrator) DeleteConfigRecord(ctx context.Context, key string) error {
 	ret := _m.Called(ctx, key)
@@ -176,6 +326,34 @@ func (_m *Orchestrator) DeleteConfigRecord(ctx context.Context, key string) erro
 	return r0
 }
 
+// DeleteNamespaceSigner provides a mock function with given fields: ctx, ns
+func (_m *Orchestrator) DeleteNamespaceSigner(ctx context.Context, ns string) error {
+	ret := _m.Called(ctx, ns)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRoutingRule provides a mock function with given fields: ctx, ns, nameOrID
+func (_m *Orchestrator) DeleteRoutingRule(ctx context.Context, ns string, nameOrID string) error {
+	ret := _m.Called(ctx, ns, nameOrID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, nameOrID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscription provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) DeleteSubscription(ctx context.Context, ns string, id string) error {
 	ret := _m.Called(ctx, ns, id)
@@ -190,6 +368,29 @@ func (_m *Orchestrator) DeleteSubscription(ctx context.Context, ns string, id st
 	return r0
 }
 
+// DiffCheckpoint provides a mock function with given fields: ctx, ns, remote
+func (_m *Orchestrator) DiffCheckpoint(ctx context.Context, ns string, remote *fftypes.Checkpoint) (*fftypes.CheckpointDiff, error) {
+	ret := _m.Called(ctx, ns, remote)
+
+	var r0 *fftypes.CheckpointDiff
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.Checkpoint) *fftypes.CheckpointDiff); ok {
+		r0 = rf(ctx, ns, remote)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.CheckpointDiff)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.Checkpoint) error); ok {
+		r1 = rf(ctx, ns, remote)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Events provides a mock function with given fields:
 func (_m *Orchestrator) Events() events.EventManager {
 	ret := _m.Called()
@@ -284,6 +485,29 @@ func (_m *Orchestrator) GetBlockchainEventByID(ctx context.Context, id *fftypes.
 	return r0, r1
 }
 
+// GetBlockchainEventByIDWithRaw provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) GetBlockchainEventByIDWithRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BlockchainEvent); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BlockchainEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockchainEvents provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -316,13 +540,91 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filt
 	return r0, r1, r2
 }
 
-// GetChartHistogram provides a mock function with given fields: ctx, ns, startTime, endTime, buckets, tableName
-func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*fftypes.ChartHistogram, error) {
-	ret := _m.Called(ctx, ns, startTime, endTime, buckets, tableName)
+// GetBlockchainEventsWithRaw provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetBlockchainEventsWithRaw(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.BlockchainEvent); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetBusinessTransaction provides a mock function with given fields: ctx, ns, key
+func (_m *Orchestrator) GetBusinessTransaction(ctx context.Context, ns string, key string) (*fftypes.BusinessTransaction, error) {
+	ret := _m.Called(ctx, ns, key)
+
+	var r0 *fftypes.BusinessTransaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.BusinessTransaction); ok {
+		r0 = rf(ctx, ns, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BusinessTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChanges provides a mock function with given fields: ctx, ns, since, limit
+func (_m *Orchestrator) GetChanges(ctx context.Context, ns string, since int64, limit uint64) (*fftypes.SyncChanges, error) {
+	ret := _m.Called(ctx, ns, since, limit)
+
+	var r0 *fftypes.SyncChanges
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, uint64) *fftypes.SyncChanges); ok {
+		r0 = rf(ctx, ns, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SyncChanges)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, uint64) error); ok {
+		r1 = rf(ctx, ns, since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChartHistogram provides a mock function with given fields: ctx, ns, startTime, endTime, buckets, tableName, options
+func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName, options *fftypes.ChartHistogramOptions) ([]*fftypes.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, startTime, endTime, buckets, tableName, options)
 
 	var r0 []*fftypes.ChartHistogram
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int64, database.CollectionName) []*fftypes.ChartHistogram); ok {
-		r0 = rf(ctx, ns, startTime, endTime, buckets, tableName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int64, database.CollectionName, *fftypes.ChartHistogramOptions) []*fftypes.ChartHistogram); ok {
+		r0 = rf(ctx, ns, startTime, endTime, buckets, tableName, options)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*fftypes.ChartHistogram)
@@ -330,8 +632,31 @@ func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startT
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64, int64, database.CollectionName) error); ok {
-		r1 = rf(ctx, ns, startTime, endTime, buckets, tableName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64, int64, database.CollectionName, *fftypes.ChartHistogramOptions) error); ok {
+		r1 = rf(ctx, ns, startTime, endTime, buckets, tableName, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCheckpoint provides a mock function with given fields: ctx, ns, sequence
+func (_m *Orchestrator) GetCheckpoint(ctx context.Context, ns string, sequence int64) (*fftypes.Checkpoint, error) {
+	ret := _m.Called(ctx, ns, sequence)
+
+	var r0 *fftypes.Checkpoint
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *fftypes.Checkpoint); ok {
+		r0 = rf(ctx, ns, sequence)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Checkpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, ns, sequence)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -339,6 +664,22 @@ func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startT
 	return r0, r1
 }
 
+// GetCircuitStatus provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetCircuitStatus(ctx context.Context) []*fftypes.CircuitStatus {
+	ret := _m.Called(ctx)
+
+	var r0 []*fftypes.CircuitStatus
+	if rf, ok := ret.Get(0).(func(context.Context) []*fftypes.CircuitStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.CircuitStatus)
+		}
+	}
+
+	return r0
+}
+
 // GetConfig provides a mock function with given fields: ctx
 func (_m *Orchestrator) GetConfig(ctx context.Context) fftypes.JSONObject {
 	ret := _m.Called(ctx)
@@ -630,24 +971,88 @@ func (_m *Orchestrator) GetEventsWithReferences(ctx context.Context, ns string,
 	return r0, r1, r2
 }
 
-// GetMessageByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetMessageByID(ctx context.Context, ns string, id string) (*fftypes.Message, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetFeeSummaries provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetFeeSummaries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.FeeSummary, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
 
-	var r0 *fftypes.Message
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Message); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 []*fftypes.FeeSummary
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.FeeSummary); ok {
+		r0 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Message)
+			r0 = ret.Get(0).([]*fftypes.FeeSummary)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, id)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetLiveness provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetLiveness(ctx context.Context) *fftypes.NodeLiveness {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.NodeLiveness
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeLiveness); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NodeLiveness)
+		}
+	}
+
+	return r0
+}
+
+// GetLogLevels provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetLogLevels(ctx context.Context) []*fftypes.LogLevel {
+	ret := _m.Called(ctx)
+
+	var r0 []*fftypes.LogLevel
+	if rf, ok := ret.Get(0).(func(context.Context) []*fftypes.LogLevel); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.LogLevel)
+		}
+	}
+
+	return r0
+}
+
+// GetMessageByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageByID(ctx context.Context, ns string, id string) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
 	}
 
 	return r0, r1
@@ -763,6 +1168,84 @@ func (_m *Orchestrator) GetMessageOperations(ctx context.Context, ns string, id
 	return r0, r1, r2
 }
 
+// GetMessageProof provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageProof(ctx context.Context, ns string, id string) (*fftypes.MessageProof, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.MessageProof
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageProof); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageProof)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageRecipients provides a mock function with given fields: ctx, ns, id, filter
+func (_m *Orchestrator) GetMessageRecipients(ctx context.Context, ns string, id string, filter database.AndFilter) ([]*fftypes.MessageRecipient, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id, filter)
+
+	var r0 []*fftypes.MessageRecipient
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, database.AndFilter) []*fftypes.MessageRecipient); ok {
+		r0 = rf(ctx, ns, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageRecipient)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageStatus provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageStatus(ctx context.Context, ns string, id string) (*fftypes.MessageStatus, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.MessageStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageStatus); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessageTransaction provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetMessageTransaction(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -905,6 +1388,29 @@ func (_m *Orchestrator) GetNamespace(ctx context.Context, ns string) (*fftypes.N
 	return r0, r1
 }
 
+// GetNamespaceSigner provides a mock function with given fields: ctx, ns
+func (_m *Orchestrator) GetNamespaceSigner(ctx context.Context, ns string) (*fftypes.NamespaceSigner, error) {
+	ret := _m.Called(ctx, ns)
+
+	var r0 *fftypes.NamespaceSigner
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.NamespaceSigner); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceSigner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNamespaces provides a mock function with given fields: ctx, filter
 func (_m *Orchestrator) GetNamespaces(ctx context.Context, filter database.AndFilter) ([]*fftypes.Namespace, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -960,6 +1466,29 @@ func (_m *Orchestrator) GetOperationByID(ctx context.Context, ns string, id stri
 	return r0, r1
 }
 
+// GetOperationByIDWithOutput provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetOperationByIDWithOutput(ctx context.Context, ns string, id string) (*fftypes.Operation, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Operation); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOperations provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetOperations(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -992,6 +1521,38 @@ func (_m *Orchestrator) GetOperations(ctx context.Context, ns string, filter dat
 	return r0, r1, r2
 }
 
+// GetOperationsWithOutput provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetOperationsWithOutput(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Operation); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Operation)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetPins provides a mock function with given fields: ctx, filter
 func (_m *Orchestrator) GetPins(ctx context.Context, filter database.AndFilter) ([]*fftypes.Pin, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1024,45 +1585,54 @@ func (_m *Orchestrator) GetPins(ctx context.Context, filter database.AndFilter)
 	return r0, r1, r2
 }
 
-// GetStatus provides a mock function with given fields: ctx
-func (_m *Orchestrator) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
+// GetPluginDiagnostics provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetPluginDiagnostics(ctx context.Context) []*fftypes.PluginDiagnostics {
 	ret := _m.Called(ctx)
 
-	var r0 *fftypes.NodeStatus
-	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeStatus); ok {
+	var r0 []*fftypes.PluginDiagnostics
+	if rf, ok := ret.Get(0).(func(context.Context) []*fftypes.PluginDiagnostics); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.NodeStatus)
+			r0 = ret.Get(0).([]*fftypes.PluginDiagnostics)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	return r0
+}
+
+// GetReadiness provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetReadiness(ctx context.Context) *fftypes.NodeReadiness {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.NodeReadiness
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeReadiness); ok {
+		r0 = rf(ctx)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NodeReadiness)
+		}
 	}
 
-	return r0, r1
+	return r0
 }
 
-// GetSubscriptionByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id string) (*fftypes.Subscription, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetRoutingRuleByNameOrID provides a mock function with given fields: ctx, ns, nameOrID
+func (_m *Orchestrator) GetRoutingRuleByNameOrID(ctx context.Context, ns string, nameOrID string) (*fftypes.RoutingRule, error) {
+	ret := _m.Called(ctx, ns, nameOrID)
 
-	var r0 *fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 *fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.RoutingRule); ok {
+		r0 = rf(ctx, ns, nameOrID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Subscription)
+			r0 = ret.Get(0).(*fftypes.RoutingRule)
 		}
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, id)
+		r1 = rf(ctx, ns, nameOrID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1070,16 +1640,16 @@ func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id s
 	return r0, r1
 }
 
-// GetSubscriptions provides a mock function with given fields: ctx, ns, filter
-func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+// GetRoutingRules provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetRoutingRules(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.RoutingRule, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
 
-	var r0 []*fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Subscription); ok {
+	var r0 []*fftypes.RoutingRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.RoutingRule); ok {
 		r0 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Subscription)
+			r0 = ret.Get(0).([]*fftypes.RoutingRule)
 		}
 	}
 
@@ -1102,55 +1672,234 @@ func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter
 	return r0, r1, r2
 }
 
-// GetTransactionBlockchainEvents provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns string, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetStandbyStatus provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetStandbyStatus(ctx context.Context) (*fftypes.StandbyStatus, error) {
+	ret := _m.Called(ctx)
 
-	var r0 []*fftypes.BlockchainEvent
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.BlockchainEvent); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 *fftypes.StandbyStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.StandbyStatus); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
-		}
-	}
-
-	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
-		r1 = rf(ctx, ns, id)
-	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*database.FilterResult)
+			r0 = ret.Get(0).(*fftypes.StandbyStatus)
 		}
 	}
 
-	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
-		r2 = rf(ctx, ns, id)
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
-// GetTransactionByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetTransactionByID(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetStateSnapshot provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetStateSnapshot(ctx context.Context) (*fftypes.StateSnapshot, error) {
+	ret := _m.Called(ctx)
 
-	var r0 *fftypes.Transaction
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Transaction); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 *fftypes.StateSnapshot
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.StateSnapshot); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Transaction)
+			r0 = ret.Get(0).(*fftypes.StateSnapshot)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, id)
-	} else {
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatus provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.NodeStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NodeStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id string) (*fftypes.Subscription, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionRedeliveries provides a mock function with given fields: ctx, ns, id, filter
+func (_m *Orchestrator) GetSubscriptionRedeliveries(ctx context.Context, ns string, id string, filter database.AndFilter) ([]*fftypes.SubscriptionRedelivery, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id, filter)
+
+	var r0 []*fftypes.SubscriptionRedelivery
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, database.AndFilter) []*fftypes.SubscriptionRedelivery); ok {
+		r0 = rf(ctx, ns, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SubscriptionRedelivery)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSubscriptionStatus provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetSubscriptionStatus(ctx context.Context, ns string, id string) (*fftypes.SubscriptionStatus, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.SubscriptionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.SubscriptionStatus); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SubscriptionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptions provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Subscription); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Subscription)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTransactionBlockchainEvents provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns string, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 []*fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.BlockchainEvent); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, ns, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTransactionByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionByID(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Transaction); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
 		r1 = ret.Error(1)
 	}
 
@@ -1272,6 +2021,36 @@ func (_m *Orchestrator) IsPreInit() bool {
 	return r0
 }
 
+// IsStandby provides a mock function with given fields:
+func (_m *Orchestrator) IsStandby() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MessageImport provides a mock function with given fields:
+func (_m *Orchestrator) MessageImport() messageimport.Manager {
+	ret := _m.Called()
+
+	var r0 messageimport.Manager
+	if rf, ok := ret.Get(0).(func() messageimport.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(messageimport.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Metrics provides a mock function with given fields:
 func (_m *Orchestrator) Metrics() metrics.Manager {
 	ret := _m.Called()
@@ -1288,6 +2067,29 @@ func (_m *Orchestrator) Metrics() metrics.Manager {
 	return r0
 }
 
+// MigrateBatches provides a mock function with given fields: ctx
+func (_m *Orchestrator) MigrateBatches(ctx context.Context) (*fftypes.BatchMigrationStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.BatchMigrationStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.BatchMigrationStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchMigrationStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NetworkMap provides a mock function with given fields:
 func (_m *Orchestrator) NetworkMap() networkmap.Manager {
 	ret := _m.Called()
@@ -1336,6 +2138,29 @@ func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return r0
 }
 
+// PromoteStandby provides a mock function with given fields: ctx, req
+func (_m *Orchestrator) PromoteStandby(ctx context.Context, req *fftypes.StandbyPromotion) (*fftypes.StandbyStatus, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *fftypes.StandbyStatus
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.StandbyPromotion) *fftypes.StandbyStatus); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.StandbyStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.StandbyPromotion) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PutConfigRecord provides a mock function with given fields: ctx, key, configRecord
 func (_m *Orchestrator) PutConfigRecord(ctx context.Context, key string, configRecord *fftypes.JSONAny) (*fftypes.JSONAny, error) {
 	ret := _m.Called(ctx, key, configRecord)
@@ -1359,6 +2184,66 @@ func (_m *Orchestrator) PutConfigRecord(ctx context.Context, key string, configR
 	return r0, r1
 }
 
+// Rebuild provides a mock function with given fields: ctx
+func (_m *Orchestrator) Rebuild(ctx context.Context) (*fftypes.RebuildStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.RebuildStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.RebuildStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.RebuildStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterDefinitionHandler provides a mock function with given fields: ctx, tag, handler
+func (_m *Orchestrator) RegisterDefinitionHandler(ctx context.Context, tag string, handler definitions.CustomHandler) error {
+	ret := _m.Called(ctx, tag, handler)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, definitions.CustomHandler) error); ok {
+		r0 = rf(ctx, tag, handler)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterPinKey provides a mock function with given fields: ctx
+func (_m *Orchestrator) RegisterPinKey(ctx context.Context) (*fftypes.Operation, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.Operation); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Operation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RequestReply provides a mock function with given fields: ctx, ns, msg
 func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageInOut, error) {
 	ret := _m.Called(ctx, ns, msg)
@@ -1387,6 +2272,158 @@ func (_m *Orchestrator) ResetConfig(ctx context.Context) {
 	_m.Called(ctx)
 }
 
+// ResetLogLevel provides a mock function with given fields: ctx, component
+func (_m *Orchestrator) ResetLogLevel(ctx context.Context, component string) error {
+	ret := _m.Called(ctx, component)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, component)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreStateSnapshot provides a mock function with given fields: ctx, snapshot
+func (_m *Orchestrator) RestoreStateSnapshot(ctx context.Context, snapshot *fftypes.StateSnapshot) (*fftypes.StateSnapshotRestore, error) {
+	ret := _m.Called(ctx, snapshot)
+
+	var r0 *fftypes.StateSnapshotRestore
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.StateSnapshot) *fftypes.StateSnapshotRestore); ok {
+		r0 = rf(ctx, snapshot)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.StateSnapshotRestore)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.StateSnapshot) error); ok {
+		r1 = rf(ctx, snapshot)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunAsGroup provides a mock function with given fields: ctx, fn
+func (_m *Orchestrator) RunAsGroup(ctx context.Context, fn func(context.Context) error) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RunPluginDiagnosticAction provides a mock function with given fields: ctx, pluginType, name, action
+func (_m *Orchestrator) RunPluginDiagnosticAction(ctx context.Context, pluginType string, name string, action string) (*fftypes.PluginDiagnosticResult, error) {
+	ret := _m.Called(ctx, pluginType, name, action)
+
+	var r0 *fftypes.PluginDiagnosticResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.PluginDiagnosticResult); ok {
+		r0 = rf(ctx, pluginType, name, action)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.PluginDiagnosticResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, pluginType, name, action)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, ns, query, filter
+func (_m *Orchestrator) Search(ctx context.Context, ns string, query string, filter database.AndFilter) ([]*fftypes.SearchResult, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, query, filter)
+
+	var r0 []*fftypes.SearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, database.AndFilter) []*fftypes.SearchResult); ok {
+		r0 = rf(ctx, ns, query, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SearchResult)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, query, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, query, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetLogLevel provides a mock function with given fields: ctx, component, input
+func (_m *Orchestrator) SetLogLevel(ctx context.Context, component string, input *fftypes.LogLevelInput) (*fftypes.LogLevel, error) {
+	ret := _m.Called(ctx, component, input)
+
+	var r0 *fftypes.LogLevel
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.LogLevelInput) *fftypes.LogLevel); ok {
+		r0 = rf(ctx, component, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.LogLevel)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.LogLevelInput) error); ok {
+		r1 = rf(ctx, component, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetNamespaceSigner provides a mock function with given fields: ctx, ns, signerRef
+func (_m *Orchestrator) SetNamespaceSigner(ctx context.Context, ns string, signerRef *fftypes.SignerRef) (*fftypes.NamespaceSigner, error) {
+	ret := _m.Called(ctx, ns, signerRef)
+
+	var r0 *fftypes.NamespaceSigner
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SignerRef) *fftypes.NamespaceSigner); ok {
+		r0 = rf(ctx, ns, signerRef)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceSigner)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SignerRef) error); ok {
+		r1 = rf(ctx, ns, signerRef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
@@ -1401,6 +2438,20 @@ func (_m *Orchestrator) Start() error {
 	return r0
 }
 
+// WaitForMessageState provides a mock function with given fields: ctx, ns, id, waitFor, timeout
+func (_m *Orchestrator) WaitForMessageState(ctx context.Context, ns string, id string, waitFor string, timeout string) error {
+	ret := _m.Called(ctx, ns, id, waitFor, timeout)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, ns, id, waitFor, timeout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *Orchestrator) WaitStop() {
 	_m.Called()
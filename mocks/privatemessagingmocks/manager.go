@@ -18,6 +18,43 @@ type Manager struct {
 	mock.Mock
 }
 
+// CreateGroupAlias provides a mock function with given fields: ctx, ns, input
+func (_m *Manager) CreateGroupAlias(ctx context.Context, ns string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error) {
+	ret := _m.Called(ctx, ns, input)
+
+	var r0 *fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.GroupAliasInput) *fftypes.GroupAlias); ok {
+		r0 = rf(ctx, ns, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.GroupAliasInput) error); ok {
+		r1 = rf(ctx, ns, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteGroupAlias provides a mock function with given fields: ctx, ns, name
+func (_m *Manager) DeleteGroupAlias(ctx context.Context, ns string, name string) error {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EnsureLocalGroup provides a mock function with given fields: ctx, group
 func (_m *Manager) EnsureLocalGroup(ctx context.Context, group *fftypes.Group) (bool, error) {
 	ret := _m.Called(ctx, group)
@@ -39,6 +76,61 @@ func (_m *Manager) EnsureLocalGroup(ctx context.Context, group *fftypes.Group) (
 	return r0, r1
 }
 
+// GetGroupAliasByName provides a mock function with given fields: ctx, ns, name
+func (_m *Manager) GetGroupAliasByName(ctx context.Context, ns string, name string) (*fftypes.GroupAlias, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.GroupAlias); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroupAliases provides a mock function with given fields: ctx, ns, filter
+func (_m *Manager) GetGroupAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.GroupAlias, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.GroupAlias); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetGroupByID provides a mock function with given fields: ctx, id
 func (_m *Manager) GetGroupByID(ctx context.Context, id string) (*fftypes.Group, error) {
 	ret := _m.Called(ctx, id)
@@ -223,6 +315,20 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 	return r0, r1, r2
 }
 
+// SendDeliveryAck provides a mock function with given fields: ctx, batchID, msg, state
+func (_m *Manager) SendDeliveryAck(ctx context.Context, batchID *fftypes.UUID, msg *fftypes.Message, state fftypes.FFEnum) error {
+	ret := _m.Called(ctx, batchID, msg, state)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.Message, fftypes.FFEnum) error); ok {
+		r0 = rf(ctx, batchID, msg, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendMessage provides a mock function with given fields: ctx, ns, in, waitConfirm
 func (_m *Manager) SendMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (*fftypes.Message, error) {
 	ret := _m.Called(ctx, ns, in, waitConfirm)
@@ -259,3 +365,26 @@ func (_m *Manager) Start() error {
 
 	return r0
 }
+
+// UpdateGroupAlias provides a mock function with given fields: ctx, ns, name, input
+func (_m *Manager) UpdateGroupAlias(ctx context.Context, ns string, name string, input *fftypes.GroupAliasInput) (*fftypes.GroupAlias, error) {
+	ret := _m.Called(ctx, ns, name, input)
+
+	var r0 *fftypes.GroupAlias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.GroupAliasInput) *fftypes.GroupAlias); ok {
+		r0 = rf(ctx, ns, name, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.GroupAlias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.GroupAliasInput) error); ok {
+		r1 = rf(ctx, ns, name, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
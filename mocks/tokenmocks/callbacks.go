@@ -28,6 +28,20 @@ func (_m *Callbacks) TokenOpUpdate(plugin tokens.Plugin, operationID *fftypes.UU
 	return r0
 }
 
+// TokenPoolBackfillProgress provides a mock function with given fields: plugin, progress
+func (_m *Callbacks) TokenPoolBackfillProgress(plugin tokens.Plugin, progress *tokens.TokenPoolBackfillProgress) error {
+	ret := _m.Called(plugin, progress)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(tokens.Plugin, *tokens.TokenPoolBackfillProgress) error); ok {
+		r0 = rf(plugin, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TokenPoolCreated provides a mock function with given fields: plugin, pool
 func (_m *Callbacks) TokenPoolCreated(plugin tokens.Plugin, pool *tokens.TokenPool) error {
 	ret := _m.Called(plugin, pool)
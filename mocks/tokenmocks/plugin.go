@@ -70,6 +70,54 @@ func (_m *Plugin) Capabilities() *tokens.Capabilities {
 	return r0
 }
 
+// ConnectorCapabilities provides a mock function with given fields: ctx
+func (_m *Plugin) ConnectorCapabilities(ctx context.Context) (*fftypes.TokenConnectorCapabilities, []string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.TokenConnectorCapabilities
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.TokenConnectorCapabilities); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TokenConnectorCapabilities)
+		}
+	}
+
+	var r1 []string
+	if rf, ok := ret.Get(1).(func(context.Context) []string); ok {
+		r1 = rf(ctx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
+		r2 = rf(ctx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ConnectorWarnings provides a mock function with given fields:
+func (_m *Plugin) ConnectorWarnings() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
 // CreateTokenPool provides a mock function with given fields: ctx, opID, pool
 func (_m *Plugin) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (bool, error) {
 	ret := _m.Called(ctx, opID, pool)
@@ -91,6 +139,41 @@ func (_m *Plugin) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool
 	return r0, r1
 }
 
+// DeployTokenPool provides a mock function with given fields: ctx, opID, pool
+func (_m *Plugin) DeployTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (bool, error) {
+	ret := _m.Called(ctx, opID, pool)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.TokenPool) bool); ok {
+		r0 = rf(ctx, opID, pool)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, *fftypes.TokenPool) error); ok {
+		r1 = rf(ctx, opID, pool)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Health provides a mock function with given fields: ctx
+func (_m *Plugin) Health(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, name, prefix, callbacks
 func (_m *Plugin) Init(ctx context.Context, name string, prefix config.Prefix, callbacks tokens.Callbacks) error {
 	ret := _m.Called(ctx, name, prefix, callbacks)
@@ -179,3 +262,17 @@ func (_m *Plugin) TransferTokens(ctx context.Context, opID *fftypes.UUID, poolPr
 
 	return r0
 }
+
+// TransferTokensBatch provides a mock function with given fields: ctx, requests
+func (_m *Plugin) TransferTokensBatch(ctx context.Context, requests []*tokens.TokenTransferRequest) error {
+	ret := _m.Called(ctx, requests)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*tokens.TokenTransferRequest) error); ok {
+		r0 = rf(ctx, requests)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -132,6 +132,34 @@ func (_m *Helper) PersistTransaction(ctx context.Context, ns string, id *fftypes
 	return r0, r1
 }
 
+// RecordOperationFee provides a mock function with given fields: ctx, op, output
+func (_m *Helper) RecordOperationFee(ctx context.Context, op *fftypes.Operation, output fftypes.JSONObject) error {
+	ret := _m.Called(ctx, op, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Operation, fftypes.JSONObject) error); ok {
+		r0 = rf(ctx, op, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResolveOperation provides a mock function with given fields: ctx, opID, status, errorMsg, output
+func (_m *Helper) ResolveOperation(ctx context.Context, opID *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) error {
+	ret := _m.Called(ctx, opID, status, errorMsg, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, fftypes.OpStatus, string, fftypes.JSONObject) error); ok {
+		r0 = rf(ctx, opID, status, errorMsg, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SubmitNewTransaction provides a mock function with given fields: ctx, ns, txType
 func (_m *Helper) SubmitNewTransaction(ctx context.Context, ns string, txType fftypes.FFEnum) (*fftypes.UUID, error) {
 	ret := _m.Called(ctx, ns, txType)
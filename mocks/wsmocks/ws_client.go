@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	wsclient "github.com/hyperledger/firefly/pkg/wsclient"
 )
 
 // WSClient is an autogenerated mock type for the WSClient type
@@ -13,6 +15,11 @@ type WSClient struct {
 	mock.Mock
 }
 
+// AddAfterReconnectHook provides a mock function with given fields: hook
+func (_m *WSClient) AddAfterReconnectHook(hook wsclient.WSPostConnectHandler) {
+	_m.Called(hook)
+}
+
 // Close provides a mock function with given fields:
 func (_m *WSClient) Close() {
 	_m.Called()
@@ -32,6 +39,11 @@ func (_m *WSClient) Connect() error {
 	return r0
 }
 
+// OnStateChange provides a mock function with given fields: handler
+func (_m *WSClient) OnStateChange(handler wsclient.WSStateChangeHandler) {
+	_m.Called(handler)
+}
+
 // Receive provides a mock function with given fields:
 func (_m *WSClient) Receive() <-chan []byte {
 	ret := _m.Called()
@@ -67,6 +79,20 @@ func (_m *WSClient) SetURL(url string) {
 	_m.Called(url)
 }
 
+// State provides a mock function with given fields:
+func (_m *WSClient) State() wsclient.WSConnectionState {
+	ret := _m.Called()
+
+	var r0 wsclient.WSConnectionState
+	if rf, ok := ret.Get(0).(func() wsclient.WSConnectionState); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(wsclient.WSConnectionState)
+	}
+
+	return r0
+}
+
 // URL provides a mock function with given fields:
 func (_m *WSClient) URL() string {
 	ret := _m.Called()
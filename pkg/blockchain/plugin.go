@@ -41,9 +41,15 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// Health returns an error describing the problem, if the plugin is not currently connected to its connector
+	Health(ctx context.Context) error
+
 	// VerifierType returns the verifier (key) type that is used by this blockchain
 	VerifierType() fftypes.VerifierType
 
+	// ChainID returns the identifier of the chain the plugin is connected to, or zero if the blockchain has no such identifier
+	ChainID() int64
+
 	// NormalizeSigningKey verifies that the supplied identity string is valid syntax according to the protocol.
 	// - Can apply transformations to the supplied signing identity (only), such as lower case.
 	// - Can perform sophisicated resolution, such as resolving a Fabric shortname to a MSP ID, or using an external REST API plugin to resolve a HD wallet address
@@ -52,8 +58,18 @@ type Plugin interface {
 	// SubmitBatchPin sequences a batch of message globally to all viewers of a given ledger
 	SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID, ledgerID *fftypes.UUID, signingKey string, batch *BatchPin) error
 
-	// InvokeContract submits a new transaction to be executed by custom on-chain logic
-	InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error
+	// InvokeContract submits a new transaction to be executed by custom on-chain logic.
+	// The optional errors describe custom errors the method can raise, for connectors that decode failure reasons themselves.
+	InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIErrorDefinition) error
+
+	// DecodeRevertReason decodes the failure reason of an invocation from the output of its failed operation,
+	// using the custom errors the method can raise. Returns false if the reason cannot be decoded.
+	DecodeRevertReason(ctx context.Context, opOutput fftypes.JSONObject, errors []*fftypes.FFIErrorDefinition) (string, bool)
+
+	// DeployContract submits a new transaction to deploy a smart contract. The constructor is described either by
+	// the blockchain specific definition, or by a constructor method from a FireFly Interface.
+	// On success the address of the new contract must be reported as "contractAddress" in the operation output.
+	DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, constructor *fftypes.FFIMethod, input []interface{}) error
 
 	// QueryContract executes a method via custom on-chain logic and returns the result
 	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error)
@@ -64,11 +80,23 @@ type Plugin interface {
 	// DeleteContractListener deletes a previously-created subscription
 	DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error
 
+	// PauseContractListener suspends delivery of events for a subscription, retaining its checkpoint
+	PauseContractListener(ctx context.Context, subscription *fftypes.ContractListener) error
+
+	// ResumeContractListener resumes delivery of events for a paused subscription, from its checkpoint
+	ResumeContractListener(ctx context.Context, subscription *fftypes.ContractListener) error
+
 	// GetFFIParamValidator returns a blockchain-plugin-specific validator for FFIParams and their JSON Schema
 	GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error)
 
 	// GenerateFFI returns an FFI from a blockchain specific interface format e.g. an Ethereum ABI
 	GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error)
+
+	// PinAccess reports whether the batch pin contract restricts pinning to registered keys, and if so whether the key is registered
+	PinAccess(ctx context.Context, signingKey string) (*fftypes.PinAccess, error)
+
+	// RegisterPinKey submits a transaction to register a key with a batch pin contract that restricts pinning to registered keys
+	RegisterPinKey(ctx context.Context, operationID *fftypes.UUID, signingKey string) error
 }
 
 // Callbacks is the interface provided to the blockchain plugin, to allow it to pass events back to firefly.
@@ -102,6 +130,10 @@ type Capabilities struct {
 	// GlobalSequencer means submitting an ordered piece of data visible to all
 	// participants of the network (requires an all-participant chain)
 	GlobalSequencer bool
+
+	// ConfirmationDepth means the connector can hold back events until the block containing them
+	// is a given number of blocks deep in the chain
+	ConfirmationDepth bool
 }
 
 // TransactionStatus is the only architecturally significant thing that Firefly tracks on blockchain transactions.
@@ -0,0 +1,196 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance is a test suite for implementations of the database plugin interface.
+//
+// Authors of a database plugin can run the suite from a Go test in their own repository,
+// to check the plugin behaves in the way FireFly core expects - including the edge cases
+// around hash checking, upsert optimizations, filters, and grouping operations into
+// a single transaction with RunAsGroup:
+//
+//	func TestConformance(t *testing.T) {
+//	    conformance.Run(t, func(t *testing.T, callbacks database.Callbacks) (database.Plugin, func()) {
+//	        p := newTestPlugin(t) // a new instance of the plugin, against an empty database
+//	        err := p.Init(context.Background(), testConfigPrefix, callbacks)
+//	        assert.NoError(t, err)
+//	        return p, p.Close
+//	    })
+//	}
+//
+// Each test is run against a new plugin instance from the factory, which must start empty.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// Factory returns a new initialized instance of the plugin under test, with an empty
+// database, that emits change events to the supplied callbacks. The cleanup function
+// is called when the test completes.
+type Factory func(t *testing.T, callbacks database.Callbacks) (plugin database.Plugin, cleanup func())
+
+// ChangeEvent is a change event emitted by the plugin, as recorded by Callbacks
+type ChangeEvent struct {
+	Collection string
+	Type       fftypes.ChangeEventType
+	Namespace  string
+	ID         *fftypes.UUID
+	Hash       *fftypes.Bytes32
+	Sequence   int64
+}
+
+// Callbacks records the change events emitted by the plugin under test
+type Callbacks struct {
+	mux    sync.Mutex
+	events []*ChangeEvent
+}
+
+func (cb *Callbacks) record(ev *ChangeEvent) {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	cb.events = append(cb.events, ev)
+}
+
+func (cb *Callbacks) OrderedUUIDCollectionNSEvent(resType database.OrderedUUIDCollectionNS, eventType fftypes.ChangeEventType, ns string, id *fftypes.UUID, sequence int64) {
+	cb.record(&ChangeEvent{Collection: string(resType), Type: eventType, Namespace: ns, ID: id, Sequence: sequence})
+}
+
+func (cb *Callbacks) OrderedCollectionEvent(resType database.OrderedCollection, eventType fftypes.ChangeEventType, sequence int64) {
+	cb.record(&ChangeEvent{Collection: string(resType), Type: eventType, Sequence: sequence})
+}
+
+func (cb *Callbacks) UUIDCollectionNSEvent(resType database.UUIDCollectionNS, eventType fftypes.ChangeEventType, ns string, id *fftypes.UUID) {
+	cb.record(&ChangeEvent{Collection: string(resType), Type: eventType, Namespace: ns, ID: id})
+}
+
+func (cb *Callbacks) UUIDCollectionEvent(resType database.UUIDCollection, eventType fftypes.ChangeEventType, id *fftypes.UUID) {
+	cb.record(&ChangeEvent{Collection: string(resType), Type: eventType, ID: id})
+}
+
+func (cb *Callbacks) HashCollectionNSEvent(resType database.HashCollectionNS, eventType fftypes.ChangeEventType, ns string, hash *fftypes.Bytes32) {
+	cb.record(&ChangeEvent{Collection: string(resType), Type: eventType, Namespace: ns, Hash: hash})
+}
+
+// Events returns a copy of the change events recorded so far
+func (cb *Callbacks) Events() []*ChangeEvent {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	return append([]*ChangeEvent{}, cb.events...)
+}
+
+// suite is the state passed to each test
+type suite struct {
+	ctx       context.Context
+	db        database.Plugin
+	callbacks *Callbacks
+}
+
+type conformanceTest struct {
+	name string
+	run  func(t *testing.T, s *suite)
+}
+
+var tests = []conformanceTest{
+	{"Namespaces", testNamespaces},
+	{"Messages", testMessages},
+	{"MessagesHashMismatch", testMessagesHashMismatch},
+	{"MessagesUpsertOptimizations", testMessagesUpsertOptimizations},
+	{"MessageRecipients", testMessageRecipients},
+	{"Data", testData},
+	{"Batches", testBatches},
+	{"Transactions", testTransactions},
+	{"FeeSummaries", testFeeSummaries},
+	{"Datatypes", testDatatypes},
+	{"Offsets", testOffsets},
+	{"Pins", testPins},
+	{"Operations", testOperations},
+	{"Subscriptions", testSubscriptions},
+	{"RoutingRules", testRoutingRules},
+	{"GroupAliases", testGroupAliases},
+	{"ApprovalRequests", testApprovalRequests},
+	{"Events", testEvents},
+	{"Identities", testIdentities},
+	{"Verifiers", testVerifiers},
+	{"Groups", testGroups},
+	{"Nonces", testNonces},
+	{"NextPins", testNextPins},
+	{"IdentityPrivateProfiles", testIdentityPrivateProfiles},
+	{"NamespaceSigners", testNamespaceSigners},
+	{"Outbox", testOutbox},
+	{"OpCallbacks", testOpCallbacks},
+	{"Blobs", testBlobs},
+	{"ConfigRecords", testConfigRecords},
+	{"TokenPools", testTokenPools},
+	{"TokenBalances", testTokenBalances},
+	{"TokenTransfers", testTokenTransfers},
+	{"TokenApprovals", testTokenApprovals},
+	{"FFIs", testFFIs},
+	{"ContractAPIs", testContractAPIs},
+	{"ContractListeners", testContractListeners},
+	{"BlockchainEvents", testBlockchainEvents},
+	{"ChartHistogram", testChartHistogram},
+	{"ChartHistogramGroupBy", testChartHistogramGroupBy},
+	{"ChartLatencyHistogram", testChartLatencyHistogram},
+	{"Search", testSearch},
+	{"FilterCombinations", testFilterCombinations},
+	{"RunAsGroupCommit", testRunAsGroupCommit},
+	{"RunAsGroupRollback", testRunAsGroupRollback},
+	{"RunAsGroupNested", testRunAsGroupNested},
+}
+
+// Run executes every test in the conformance suite, each against a new instance of the plugin from the factory
+func Run(t *testing.T, factory Factory) {
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			callbacks := &Callbacks{}
+			db, cleanup := factory(t, callbacks)
+			defer cleanup()
+			test.run(t, &suite{
+				ctx:       context.Background(),
+				db:        db,
+				callbacks: callbacks,
+			})
+		})
+	}
+}
+
+// assertChangeEvent checks a change event has been emitted for the collection, with the given ID or hash
+func (s *suite) assertChangeEvent(t *testing.T, collection interface{}, eventType fftypes.ChangeEventType, id *fftypes.UUID, hash *fftypes.Bytes32) {
+	for _, ev := range s.callbacks.Events() {
+		if ev.Collection == fmt.Sprint(collection) && ev.Type == eventType &&
+			(id == nil || id.Equals(ev.ID)) &&
+			(hash == nil || hash.Equals(ev.Hash)) {
+			return
+		}
+	}
+	assert.Fail(t, "missing change event", "collection=%s type=%s id=%s hash=%s", collection, eventType, id, hash)
+}
+
+// assertJSONEqual compares the JSON serialization of two objects, which is how FireFly returns them on the API
+func assertJSONEqual(t *testing.T, expected, actual interface{}) {
+	expectedJSON, _ := json.Marshal(expected)
+	actualJSON, _ := json.Marshal(actual)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+// +build cgo
+
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/internal/database/sqlite3"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformanceSQLite3(t *testing.T) {
+	Run(t, func(t *testing.T, callbacks database.Callbacks) (database.Plugin, func()) {
+		sqlite := &sqlite3.SQLite3{}
+		prefix := config.NewPluginConfig("unittest.conformance")
+		sqlite.InitPrefix(prefix)
+		prefix.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
+		prefix.Set(sqlcommon.SQLConfMigrationsAuto, true)
+		prefix.Set(sqlcommon.SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+		prefix.Set(sqlcommon.SQLConfMaxConnections, 1)
+		err := sqlite.Init(context.Background(), prefix, callbacks)
+		assert.NoError(t, err)
+		return sqlite, sqlite.Close
+	})
+}
@@ -0,0 +1,701 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNamespaces(t *testing.T, s *suite) {
+	namespace := &fftypes.Namespace{
+		Message: fftypes.NewUUID(),
+		Type:    fftypes.NamespaceTypeLocal,
+		Name:    "namespace1",
+		Created: fftypes.Now(),
+	}
+
+	// The plugin generates the ID when one is not supplied
+	err := s.db.UpsertNamespace(s.ctx, namespace, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, namespace.ID)
+	s.assertChangeEvent(t, database.CollectionNamespaces, fftypes.ChangeEventTypeCreated, namespace.ID, nil)
+
+	namespaceRead, err := s.db.GetNamespace(s.ctx, namespace.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, namespace, namespaceRead)
+
+	// A different ID for an existing name must be rejected
+	err = s.db.UpsertNamespace(s.ctx, &fftypes.Namespace{
+		ID:   fftypes.NewUUID(),
+		Name: namespace.Name,
+	}, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	namespaceUpdated := &fftypes.Namespace{
+		Message:     fftypes.NewUUID(),
+		Type:        fftypes.NamespaceTypeBroadcast,
+		Name:        namespace.Name,
+		Description: "description1",
+		Created:     fftypes.Now(),
+	}
+	err = s.db.UpsertNamespace(s.ctx, namespaceUpdated, true)
+	assert.NoError(t, err)
+	assert.Equal(t, *namespace.ID, *namespaceUpdated.ID)
+	s.assertChangeEvent(t, database.CollectionNamespaces, fftypes.ChangeEventTypeUpdated, namespace.ID, nil)
+
+	fb := database.NamespaceQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("type", string(namespaceUpdated.Type)),
+		fb.Eq("name", namespaceUpdated.Name),
+	)
+	namespaces, res, err := s.db.GetNamespaces(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, namespaces, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, namespaceUpdated, namespaces[0])
+
+	namespaceRead, err = s.db.GetNamespaceByID(s.ctx, namespace.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, namespaceUpdated, namespaceRead)
+
+	err = s.db.DeleteNamespace(s.ctx, namespace.ID)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionNamespaces, fftypes.ChangeEventTypeDeleted, namespace.ID, nil)
+	namespaces, _, err = s.db.GetNamespaces(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, namespaces)
+	namespaceRead, err = s.db.GetNamespace(s.ctx, namespace.Name)
+	assert.NoError(t, err)
+	assert.Nil(t, namespaceRead)
+}
+
+func testDatatypes(t *testing.T, s *suite) {
+	datatype := &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Message:   fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "customer",
+		Version:   "0.0.1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"some":"datatype"}`),
+	}
+	err := s.db.UpsertDatatype(s.ctx, datatype, true)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionDataTypes, fftypes.ChangeEventTypeCreated, datatype.ID, nil)
+
+	datatypeRead, err := s.db.GetDatatypeByID(s.ctx, datatype.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, datatype, datatypeRead)
+
+	datatype.Value = fftypes.JSONAnyPtr(`{"another":"datatype"}`)
+	datatype.Indexes = fftypes.FFStringArray{"some.field"}
+	err = s.db.UpsertDatatype(s.ctx, datatype, true)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionDataTypes, fftypes.ChangeEventTypeUpdated, datatype.ID, nil)
+
+	datatypeRead, err = s.db.GetDatatypeByName(s.ctx, datatype.Namespace, datatype.Name, datatype.Version)
+	assert.NoError(t, err)
+	assertJSONEqual(t, datatype, datatypeRead)
+
+	datatypeRead, err = s.db.GetDatatypeByName(s.ctx, datatype.Namespace, datatype.Name, "0.0.2")
+	assert.NoError(t, err)
+	assert.Nil(t, datatypeRead)
+
+	fb := database.DatatypeQueryFactory.NewFilter(s.ctx)
+	datatypes, res, err := s.db.GetDatatypes(s.ctx, fb.And(
+		fb.Eq("id", datatype.ID.String()),
+		fb.Eq("namespace", datatype.Namespace),
+		fb.Eq("validator", string(datatype.Validator)),
+		fb.Eq("name", datatype.Name),
+		fb.Eq("version", datatype.Version),
+		fb.Gt("created", "0"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, datatypes, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, datatype, datatypes[0])
+
+	err = s.db.UpdateDatatype(s.ctx, datatype.ID, database.DatatypeQueryFactory.NewUpdate(s.ctx).Set("version", "2.0.0"))
+	assert.NoError(t, err)
+	datatypes, _, err = s.db.GetDatatypes(s.ctx, fb.And(
+		fb.Eq("id", datatype.ID.String()),
+		fb.Eq("version", "2.0.0"),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, datatypes, 1)
+}
+
+func testOffsets(t *testing.T, s *suite) {
+	offset := &fftypes.Offset{
+		Type:    fftypes.OffsetTypeBatch,
+		Name:    "offset1",
+		Current: 12345,
+	}
+	err := s.db.UpsertOffset(s.ctx, offset, true)
+	assert.NoError(t, err)
+
+	offsetRead, err := s.db.GetOffset(s.ctx, offset.Type, offset.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, offset, offsetRead)
+
+	// Upserting an existing offset returns the row ID, so it can be updated directly
+	offsetUpdated := &fftypes.Offset{
+		Type:    fftypes.OffsetTypeBatch,
+		Name:    "offset1",
+		Current: 23456,
+	}
+	err = s.db.UpsertOffset(s.ctx, offsetUpdated, true)
+	assert.NoError(t, err)
+	assert.Equal(t, offsetRead.RowID, offsetUpdated.RowID)
+
+	offsetRead, err = s.db.GetOffset(s.ctx, offset.Type, offset.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, offsetUpdated, offsetRead)
+
+	offsetRead, err = s.db.GetOffset(s.ctx, fftypes.OffsetTypeSubscription, offset.Name)
+	assert.NoError(t, err)
+	assert.Nil(t, offsetRead)
+
+	err = s.db.UpdateOffset(s.ctx, offsetUpdated.RowID, database.OffsetQueryFactory.NewUpdate(s.ctx).Set("current", 34567))
+	assert.NoError(t, err)
+
+	fb := database.OffsetQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("type", string(offset.Type)),
+		fb.Eq("name", offset.Name),
+		fb.Eq("current", 34567),
+	)
+	offsets, res, err := s.db.GetOffsets(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	err = s.db.DeleteOffset(s.ctx, offset.Type, offset.Name)
+	assert.NoError(t, err)
+	offsets, _, err = s.db.GetOffsets(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, offsets)
+}
+
+func testSubscriptions(t *testing.T, s *suite) {
+	subscription := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Namespace: "ns1",
+			Name:      "subscription1",
+		},
+		Created: fftypes.Now(),
+	}
+	err := s.db.UpsertSubscription(s.ctx, subscription, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, subscription.ID)
+	s.assertChangeEvent(t, database.CollectionSubscriptions, fftypes.ChangeEventTypeCreated, subscription.ID, nil)
+
+	subscriptionRead, err := s.db.GetSubscriptionByName(s.ctx, subscription.Namespace, subscription.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, subscription, subscriptionRead)
+
+	newest := fftypes.SubOptsFirstEventNewest
+	readAhead := uint16(50)
+	options := fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			FirstEvent: &newest,
+			ReadAhead:  &readAhead,
+		},
+	}
+	options.TransportOptions()["my-transport-option"] = true
+	subscriptionUpdated := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "subscription1",
+		},
+		Transport: "websockets",
+		Filter: fftypes.SubscriptionFilter{
+			Events: string(fftypes.EventTypeMessageConfirmed),
+			Topic:  "topics.*",
+			Message: fftypes.MessageFilter{
+				Tag: "tag.*",
+			},
+		},
+		Options: options,
+		Created: fftypes.Now(),
+		Updated: fftypes.Now(),
+	}
+
+	// A different ID for an existing name must be rejected
+	err = s.db.UpsertSubscription(s.ctx, subscriptionUpdated, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	subscriptionUpdated.ID = nil
+	err = s.db.UpsertSubscription(s.ctx, subscriptionUpdated, true)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionSubscriptions, fftypes.ChangeEventTypeUpdated, subscription.ID, nil)
+
+	// Transport specific options must round trip
+	subscriptionRead, err = s.db.GetSubscriptionByID(s.ctx, subscription.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, subscriptionUpdated, subscriptionRead)
+	assert.Equal(t, true, subscriptionRead.Options.TransportOptions()["my-transport-option"])
+
+	fb := database.SubscriptionQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("namespace", subscription.Namespace),
+		fb.Eq("name", subscription.Name),
+	)
+	subscriptions, res, err := s.db.GetSubscriptions(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, subscriptionUpdated, subscriptions[0])
+
+	err = s.db.UpdateSubscription(s.ctx, subscription.Namespace, subscription.Name,
+		database.SubscriptionQueryFactory.NewUpdate(s.ctx).Set("transport", "webhooks"))
+	assert.NoError(t, err)
+	subscriptionRead, err = s.db.GetSubscriptionByID(s.ctx, subscription.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "webhooks", subscriptionRead.Transport)
+
+	err = s.db.DeleteSubscriptionByID(s.ctx, subscription.ID)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionSubscriptions, fftypes.ChangeEventTypeDeleted, subscription.ID, nil)
+	subscriptions, _, err = s.db.GetSubscriptions(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+func testRoutingRules(t *testing.T, s *suite) {
+	rule := &fftypes.RoutingRule{
+		Namespace: "ns1",
+		Name:      "rule1",
+		Tag:       "^invoice",
+		Labels:    fftypes.FFStringArray{"finance"},
+		Created:   fftypes.Now(),
+	}
+	err := s.db.UpsertRoutingRule(s.ctx, rule, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, rule.ID)
+	s.assertChangeEvent(t, database.CollectionRoutingRules, fftypes.ChangeEventTypeCreated, rule.ID, nil)
+
+	ruleRead, err := s.db.GetRoutingRuleByName(s.ctx, rule.Namespace, rule.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, rule, ruleRead)
+
+	ruleUpdated := &fftypes.RoutingRule{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "rule1",
+		Topic:     "^orders",
+		Labels:    fftypes.FFStringArray{"finance", "orders"},
+		Created:   rule.Created,
+		Updated:   fftypes.Now(),
+	}
+
+	// A different ID for an existing name must be rejected
+	err = s.db.UpsertRoutingRule(s.ctx, ruleUpdated, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	ruleUpdated.ID = nil
+	err = s.db.UpsertRoutingRule(s.ctx, ruleUpdated, true)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionRoutingRules, fftypes.ChangeEventTypeUpdated, rule.ID, nil)
+
+	ruleRead, err = s.db.GetRoutingRuleByID(s.ctx, rule.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, ruleUpdated, ruleRead)
+
+	fb := database.RoutingRuleQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("namespace", rule.Namespace),
+		fb.Eq("name", rule.Name),
+	)
+	rules, res, err := s.db.GetRoutingRules(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, ruleUpdated, rules[0])
+
+	err = s.db.DeleteRoutingRuleByID(s.ctx, rule.ID)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionRoutingRules, fftypes.ChangeEventTypeDeleted, rule.ID, nil)
+	fb = database.RoutingRuleQueryFactory.NewFilter(s.ctx)
+	rules, _, err = s.db.GetRoutingRules(s.ctx, fb.Eq("namespace", rule.Namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func testApprovalRequests(t *testing.T, s *suite) {
+	req := &fftypes.ApprovalRequest{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.ApprovalRequestTypeTokenMint,
+		State:     fftypes.ApprovalRequestStatePending,
+		Reference: fftypes.NewUUID(),
+		Request:   fftypes.JSONAnyPtr(`{"amount":"100"}`),
+		Required:  2,
+		Decisions: fftypes.ApprovalDecisions{},
+		Created:   fftypes.Now(),
+	}
+	err := s.db.InsertApprovalRequest(s.ctx, req)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionApprovalRequests, fftypes.ChangeEventTypeCreated, req.ID, nil)
+
+	reqRead, err := s.db.GetApprovalRequestByID(s.ctx, req.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, req, reqRead)
+
+	decisions := fftypes.ApprovalDecisions{
+		{Approver: "did:firefly:org/org1", Approved: false, Comment: "too much", Created: fftypes.Now()},
+	}
+	up := database.ApprovalRequestQueryFactory.NewUpdate(s.ctx).
+		Set("decisions", decisions).
+		Set("state", fftypes.ApprovalRequestStateRejected)
+	err = s.db.UpdateApprovalRequest(s.ctx, req.ID, up)
+	assert.NoError(t, err)
+
+	fb := database.ApprovalRequestQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("namespace", req.Namespace),
+		fb.Eq("state", fftypes.ApprovalRequestStateRejected),
+	)
+	reqs, res, err := s.db.GetApprovalRequests(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, reqs, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, "too much", reqs[0].Decisions[0].Comment)
+}
+
+func testGroupAliases(t *testing.T, s *suite) {
+	alias := &fftypes.GroupAlias{
+		Namespace: "ns1",
+		Name:      "alias1",
+		Group:     fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+	}
+	err := s.db.UpsertGroupAlias(s.ctx, alias, true)
+	assert.NoError(t, err)
+	assert.NotNil(t, alias.ID)
+	s.assertChangeEvent(t, database.CollectionGroupAliases, fftypes.ChangeEventTypeCreated, alias.ID, nil)
+
+	aliasRead, err := s.db.GetGroupAliasByName(s.ctx, alias.Namespace, alias.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, alias, aliasRead)
+
+	aliasUpdated := &fftypes.GroupAlias{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "alias1",
+		Group:     fftypes.NewRandB32(),
+		Created:   alias.Created,
+		Updated:   fftypes.Now(),
+	}
+
+	// A different ID for an existing name must be rejected
+	err = s.db.UpsertGroupAlias(s.ctx, aliasUpdated, true)
+	assert.Equal(t, database.IDMismatch, err)
+
+	aliasUpdated.ID = nil
+	err = s.db.UpsertGroupAlias(s.ctx, aliasUpdated, true)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionGroupAliases, fftypes.ChangeEventTypeUpdated, alias.ID, nil)
+
+	aliasRead, err = s.db.GetGroupAliasByID(s.ctx, alias.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, aliasUpdated, aliasRead)
+
+	fb := database.GroupAliasQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("namespace", alias.Namespace),
+		fb.Eq("name", alias.Name),
+	)
+	aliases, res, err := s.db.GetGroupAliases(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, aliases, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, aliasUpdated, aliases[0])
+
+	err = s.db.DeleteGroupAliasByID(s.ctx, alias.ID)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionGroupAliases, fftypes.ChangeEventTypeDeleted, alias.ID, nil)
+	fb = database.GroupAliasQueryFactory.NewFilter(s.ctx)
+	aliases, _, err = s.db.GetGroupAliases(s.ctx, fb.Eq("namespace", alias.Namespace))
+	assert.NoError(t, err)
+	assert.Empty(t, aliases)
+}
+
+func testConfigRecords(t *testing.T, s *suite) {
+	configRecord := &fftypes.ConfigRecord{
+		Key:   "foo",
+		Value: fftypes.JSONAnyPtr(`{"foo":"bar"}`),
+	}
+	err := s.db.UpsertConfigRecord(s.ctx, configRecord, true)
+	assert.NoError(t, err)
+
+	configRecordRead, err := s.db.GetConfigRecord(s.ctx, configRecord.Key)
+	assert.NoError(t, err)
+	assertJSONEqual(t, configRecord, configRecordRead)
+
+	configRecordUpdated := &fftypes.ConfigRecord{
+		Key:   "foo",
+		Value: fftypes.JSONAnyPtr(`{"fiz":"buzz"}`),
+	}
+	err = s.db.UpsertConfigRecord(s.ctx, configRecordUpdated, true)
+	assert.NoError(t, err)
+
+	fb := database.ConfigRecordQueryFactory.NewFilter(s.ctx)
+	configRecords, res, err := s.db.GetConfigRecords(s.ctx, fb.And().Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, configRecords, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, configRecordUpdated, configRecords[0])
+
+	err = s.db.DeleteConfigRecord(s.ctx, configRecord.Key)
+	assert.NoError(t, err)
+	configRecordRead, err = s.db.GetConfigRecord(s.ctx, configRecord.Key)
+	assert.NoError(t, err)
+	assert.Nil(t, configRecordRead)
+}
+
+func testFFIs(t *testing.T, s *suite) {
+	ffi := &fftypes.FFI{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Name:        "math",
+		Version:     "v1.0.0",
+		Description: "Does things and stuff",
+		Message:     fftypes.NewUUID(),
+	}
+	err := s.db.UpsertFFI(s.ctx, ffi)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionFFIs, fftypes.ChangeEventTypeCreated, ffi.ID, nil)
+
+	ffiRead, err := s.db.GetFFIByID(s.ctx, ffi.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, ffi, ffiRead)
+
+	ffi.Description = "Does more things"
+	err = s.db.UpsertFFI(s.ctx, ffi)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionFFIs, fftypes.ChangeEventTypeUpdated, ffi.ID, nil)
+
+	ffiRead, err = s.db.GetFFI(s.ctx, ffi.Namespace, ffi.Name, ffi.Version)
+	assert.NoError(t, err)
+	assertJSONEqual(t, ffi, ffiRead)
+
+	ffiRead, err = s.db.GetFFI(s.ctx, ffi.Namespace, ffi.Name, "v2.0.0")
+	assert.NoError(t, err)
+	assert.Nil(t, ffiRead)
+
+	fb := database.FFIQueryFactory.NewFilter(s.ctx)
+	ffis, res, err := s.db.GetFFIs(s.ctx, ffi.Namespace, fb.Eq("name", ffi.Name).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, ffis, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	ffis, _, err = s.db.GetFFIs(s.ctx, "ns2", fb.Eq("name", ffi.Name))
+	assert.NoError(t, err)
+	assert.Empty(t, ffis)
+
+	method := &fftypes.FFIMethod{
+		ID:          fftypes.NewUUID(),
+		Contract:    ffi.ID,
+		Name:        "sum",
+		Namespace:   ffi.Namespace,
+		Pathname:    "sum",
+		Description: "Adds things",
+		Params: fftypes.FFIParams{
+			{Name: "x", Schema: fftypes.JSONAnyPtr(`{"type":"integer"}`)},
+			{Name: "y", Schema: fftypes.JSONAnyPtr(`{"type":"integer"}`)},
+		},
+		Returns: fftypes.FFIParams{
+			{Name: "result", Schema: fftypes.JSONAnyPtr(`{"type":"integer"}`)},
+		},
+	}
+	err = s.db.UpsertFFIMethod(s.ctx, method)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionFFIMethods, fftypes.ChangeEventTypeCreated, method.ID, nil)
+
+	methodRead, err := s.db.GetFFIMethod(s.ctx, ffi.Namespace, ffi.ID, method.Pathname)
+	assert.NoError(t, err)
+	assertJSONEqual(t, method, methodRead)
+
+	methodRead, err = s.db.GetFFIMethod(s.ctx, ffi.Namespace, fftypes.NewUUID(), method.Pathname)
+	assert.NoError(t, err)
+	assert.Nil(t, methodRead)
+
+	mfb := database.FFIMethodQueryFactory.NewFilter(s.ctx)
+	methods, res, err := s.db.GetFFIMethods(s.ctx, mfb.And(
+		mfb.Eq("interface", ffi.ID),
+		mfb.Eq("pathname", method.Pathname),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, methods, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, method, methods[0])
+
+	event := &fftypes.FFIEvent{
+		ID:        fftypes.NewUUID(),
+		Contract:  ffi.ID,
+		Namespace: ffi.Namespace,
+		Pathname:  "Changed",
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name:        "Changed",
+			Description: "Things changed",
+			Params: fftypes.FFIParams{
+				{Name: "value", Schema: fftypes.JSONAnyPtr(`{"type":"integer"}`)},
+			},
+		},
+	}
+	err = s.db.UpsertFFIEvent(s.ctx, event)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionFFIEvents, fftypes.ChangeEventTypeCreated, event.ID, nil)
+
+	event.Params = fftypes.FFIParams{}
+	err = s.db.UpsertFFIEvent(s.ctx, event)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionFFIEvents, fftypes.ChangeEventTypeUpdated, event.ID, nil)
+
+	eventRead, err := s.db.GetFFIEvent(s.ctx, ffi.Namespace, ffi.ID, event.Pathname)
+	assert.NoError(t, err)
+	assertJSONEqual(t, event, eventRead)
+	eventRead, err = s.db.GetFFIEventByID(s.ctx, event.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, event, eventRead)
+
+	efb := database.FFIEventQueryFactory.NewFilter(s.ctx)
+	events, res, err := s.db.GetFFIEvents(s.ctx, efb.And(
+		efb.Eq("interface", ffi.ID),
+		efb.Eq("name", event.Name),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+}
+
+func testContractAPIs(t *testing.T, s *suite) {
+	api := &fftypes.ContractAPI{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "banana",
+		Interface: &fftypes.FFIReference{
+			ID:      fftypes.NewUUID(),
+			Name:    "banana",
+			Version: "v1.0.0",
+		},
+		Location: fftypes.JSONAnyPtr(`{"address":"0x12345"}`),
+		Message:  fftypes.NewUUID(),
+	}
+	err := s.db.UpsertContractAPI(s.ctx, api)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionContractAPIs, fftypes.ChangeEventTypeCreated, api.ID, nil)
+
+	apiRead, err := s.db.GetContractAPIByID(s.ctx, api.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *api.ID, *apiRead.ID)
+	assert.Equal(t, *api.Interface.ID, *apiRead.Interface.ID)
+	assert.Equal(t, api.Location.String(), apiRead.Location.String())
+
+	api.Location = fftypes.JSONAnyPtr(`{"address":"0x67890"}`)
+	err = s.db.UpsertContractAPI(s.ctx, api)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionContractAPIs, fftypes.ChangeEventTypeUpdated, api.ID, nil)
+
+	apiRead, err = s.db.GetContractAPIByName(s.ctx, api.Namespace, api.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, *api.ID, *apiRead.ID)
+	assert.Equal(t, api.Location.String(), apiRead.Location.String())
+
+	apiRead, err = s.db.GetContractAPIByName(s.ctx, "ns2", api.Name)
+	assert.NoError(t, err)
+	assert.Nil(t, apiRead)
+
+	fb := database.ContractAPIQueryFactory.NewFilter(s.ctx)
+	apis, _, err := s.db.GetContractAPIs(s.ctx, api.Namespace, fb.And(fb.Eq("name", api.Name)))
+	assert.NoError(t, err)
+	assert.Len(t, apis, 1)
+	apis, _, err = s.db.GetContractAPIs(s.ctx, "ns2", fb.And(fb.Eq("name", api.Name)))
+	assert.NoError(t, err)
+	assert.Empty(t, apis)
+}
+
+func testContractListeners(t *testing.T, s *suite) {
+	listener := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+		Interface: &fftypes.FFIReference{
+			ID: fftypes.NewUUID(),
+		},
+		Event: &fftypes.FFISerializedEvent{
+			FFIEventDefinition: fftypes.FFIEventDefinition{
+				Name: "event1",
+			},
+		},
+		Namespace:  "ns1",
+		Name:       "listener1",
+		ProtocolID: "sb-123",
+		Location:   fftypes.JSONAnyPtr(`{"path":"my-api"}`),
+		Topic:      "topic1",
+		Options: &fftypes.ContractListenerOptions{
+			FirstEvent: string(fftypes.SubOptsFirstEventOldest),
+		},
+		State: fftypes.ContractListenerStateActive,
+	}
+	err := s.db.UpsertContractListener(s.ctx, listener)
+	assert.NoError(t, err)
+	assert.NotNil(t, listener.Created)
+	s.assertChangeEvent(t, database.CollectionContractListeners, fftypes.ChangeEventTypeCreated, listener.ID, nil)
+
+	listenerRead, err := s.db.GetContractListener(s.ctx, listener.Namespace, listener.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, listener, listenerRead)
+	listenerRead, err = s.db.GetContractListenerByID(s.ctx, listener.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, listener, listenerRead)
+
+	listener.State = fftypes.ContractListenerStatePaused
+	err = s.db.UpsertContractListener(s.ctx, listener)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionContractListeners, fftypes.ChangeEventTypeUpdated, listener.ID, nil)
+
+	listenerRead, err = s.db.GetContractListenerByProtocolID(s.ctx, listener.ProtocolID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, listener, listenerRead)
+
+	listenerRead, err = s.db.GetContractListenerByProtocolID(s.ctx, "sb-456")
+	assert.NoError(t, err)
+	assert.Nil(t, listenerRead)
+
+	fb := database.ContractListenerQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("protocolid", listener.ProtocolID),
+		fb.Eq("state", listener.State),
+	)
+	listeners, res, err := s.db.GetContractListeners(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, listeners, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	err = s.db.DeleteContractListenerByID(s.ctx, listener.ID)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionContractListeners, fftypes.ChangeEventTypeDeleted, listener.ID, nil)
+	listeners, _, err = s.db.GetContractListeners(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, listeners)
+}
@@ -0,0 +1,401 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIdentities(t *testing.T, s *suite) {
+	identity := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:ns/ns1/identity1",
+			Parent:    fftypes.NewUUID(),
+			Type:      fftypes.IdentityTypeCustom,
+			Namespace: "ns1",
+			Name:      "identity1",
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Description: "Identity One",
+		},
+		Messages: fftypes.IdentityMessages{
+			Claim: fftypes.NewUUID(),
+		},
+		Created: fftypes.Now(),
+	}
+	err := s.db.UpsertIdentity(s.ctx, identity, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionIdentities, fftypes.ChangeEventTypeCreated, identity.ID, nil)
+
+	identityRead, err := s.db.GetIdentityByID(s.ctx, identity.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, identity, identityRead)
+
+	identity.Description = "Identity One Updated"
+	identity.Profile = fftypes.JSONObject{"some": "value"}
+	identity.Messages.Verification = fftypes.NewUUID()
+	identity.Updated = fftypes.Now()
+	err = s.db.UpsertIdentity(s.ctx, identity, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionIdentities, fftypes.ChangeEventTypeUpdated, identity.ID, nil)
+
+	identityRead, err = s.db.GetIdentityByName(s.ctx, identity.Type, identity.Namespace, identity.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, identity, identityRead)
+	identityRead, err = s.db.GetIdentityByDID(s.ctx, identity.DID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, identity, identityRead)
+
+	identityRead, err = s.db.GetIdentityByName(s.ctx, fftypes.IdentityTypeOrg, identity.Namespace, identity.Name)
+	assert.NoError(t, err)
+	assert.Nil(t, identityRead)
+	identityRead, err = s.db.GetIdentityByDID(s.ctx, "did:firefly:ns/ns1/unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, identityRead)
+
+	fb := database.IdentityQueryFactory.NewFilter(s.ctx)
+	identities, res, err := s.db.GetIdentities(s.ctx, fb.And(
+		fb.Eq("description", identity.Description),
+		fb.Eq("did", identity.DID),
+		fb.Eq("parent", identity.Parent),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, identities, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, identity, identities[0])
+
+	updateTime := fftypes.Now()
+	err = s.db.UpdateIdentity(s.ctx, identity.ID, database.IdentityQueryFactory.NewUpdate(s.ctx).Set("updated", updateTime))
+	assert.NoError(t, err)
+	identities, _, err = s.db.GetIdentities(s.ctx, fb.Eq("updated", updateTime))
+	assert.NoError(t, err)
+	assert.Len(t, identities, 1)
+}
+
+func testVerifiers(t *testing.T, s *suite) {
+	verifier := &fftypes.Verifier{
+		Identity:  fftypes.NewUUID(),
+		Namespace: "ns1",
+		VerifierRef: fftypes.VerifierRef{
+			Type:  fftypes.VerifierTypeEthAddress,
+			Value: "0x12345",
+		},
+		Created: fftypes.Now(),
+	}
+	verifier.Seal()
+	err := s.db.UpsertVerifier(s.ctx, verifier, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionVerifiers, fftypes.ChangeEventTypeCreated, nil, verifier.Hash)
+
+	verifierRead, err := s.db.GetVerifierByHash(s.ctx, verifier.Hash)
+	assert.NoError(t, err)
+	assertJSONEqual(t, verifier, verifierRead)
+
+	// The hash is derived from the type, namespace and value - so the identity can be updated
+	verifier.Identity = fftypes.NewUUID()
+	verifier.Pinning = true
+	err = s.db.UpsertVerifier(s.ctx, verifier, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionVerifiers, fftypes.ChangeEventTypeUpdated, nil, verifier.Hash)
+
+	verifierRead, err = s.db.GetVerifierByValue(s.ctx, verifier.Type, verifier.Namespace, verifier.Value)
+	assert.NoError(t, err)
+	assertJSONEqual(t, verifier, verifierRead)
+
+	verifierRead, err = s.db.GetVerifierByValue(s.ctx, verifier.Type, "ns2", verifier.Value)
+	assert.NoError(t, err)
+	assert.Nil(t, verifierRead)
+
+	fb := database.VerifierQueryFactory.NewFilter(s.ctx)
+	verifiers, res, err := s.db.GetVerifiers(s.ctx, fb.And(
+		fb.Eq("value", verifier.Value),
+		fb.Eq("namespace", verifier.Namespace),
+		fb.Eq("identity", verifier.Identity),
+		fb.Eq("pinning", true),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, verifiers, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, verifier, verifiers[0])
+
+	updateTime := fftypes.Now()
+	err = s.db.UpdateVerifier(s.ctx, verifier.Hash, database.VerifierQueryFactory.NewUpdate(s.ctx).Set("created", updateTime))
+	assert.NoError(t, err)
+	verifiers, _, err = s.db.GetVerifiers(s.ctx, fb.Eq("created", updateTime))
+	assert.NoError(t, err)
+	assert.Len(t, verifiers, 1)
+}
+
+func testGroups(t *testing.T, s *suite) {
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Name:      "group1",
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: "did:firefly:org/org1", Node: fftypes.NewUUID()},
+				{Identity: "did:firefly:org/org2", Node: fftypes.NewUUID()},
+			},
+		},
+		Hash:    fftypes.NewRandB32(),
+		Created: fftypes.Now(),
+	}
+	err := s.db.UpsertGroup(s.ctx, group, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionGroups, fftypes.ChangeEventTypeCreated, nil, group.Hash)
+
+	// Members are returned in the order they were stored
+	groupRead, err := s.db.GetGroupByHash(s.ctx, group.Hash)
+	assert.NoError(t, err)
+	assertJSONEqual(t, group, groupRead)
+
+	groupRead, err = s.db.GetGroupByHash(s.ctx, fftypes.NewRandB32())
+	assert.NoError(t, err)
+	assert.Nil(t, groupRead)
+
+	group.Message = fftypes.NewUUID()
+	group.Ledger = fftypes.NewUUID()
+	err = s.db.UpsertGroup(s.ctx, group, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionGroups, fftypes.ChangeEventTypeUpdated, nil, group.Hash)
+
+	fb := database.GroupQueryFactory.NewFilter(s.ctx)
+	groups, res, err := s.db.GetGroups(s.ctx, fb.And(
+		fb.Eq("hash", group.Hash),
+		fb.Eq("namespace", group.Namespace),
+		fb.Eq("message", group.Message),
+		fb.Eq("ledger", group.Ledger),
+		fb.Gt("created", "0"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, group, groups[0])
+
+	message2 := fftypes.NewUUID()
+	err = s.db.UpdateGroup(s.ctx, group.Hash, database.GroupQueryFactory.NewUpdate(s.ctx).Set("message", message2))
+	assert.NoError(t, err)
+	groups, _, err = s.db.GetGroups(s.ctx, fb.Eq("message", message2))
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Len(t, groups[0].Members, 2)
+}
+
+func testNonces(t *testing.T, s *suite) {
+	nonce := &fftypes.Nonce{
+		Context: fftypes.NewRandB32(),
+		Group:   fftypes.NewRandB32(),
+		Topic:   "topic1",
+	}
+
+	// The first reservation creates the nonce at zero, and each subsequent reservation starts
+	// after the last nonce of the previous one
+	err := s.db.ReserveNonces(s.ctx, nonce, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), nonce.Nonce)
+	err = s.db.ReserveNonces(s.ctx, nonce, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nonce.Nonce)
+	err = s.db.ReserveNonces(s.ctx, nonce, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), nonce.Nonce)
+
+	// A reservation rolled back with its transaction leaves no gap
+	err = s.db.RunAsGroup(s.ctx, func(ctx context.Context) error {
+		rolledBack := *nonce
+		if err := s.db.ReserveNonces(ctx, &rolledBack, 5); err != nil {
+			return err
+		}
+		return fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "pop", err)
+
+	nonceRead, err := s.db.GetNonce(s.ctx, nonce.Context)
+	assert.NoError(t, err)
+	assertJSONEqual(t, nonce, nonceRead)
+
+	nonceRead, err = s.db.GetNonce(s.ctx, fftypes.NewRandB32())
+	assert.NoError(t, err)
+	assert.Nil(t, nonceRead)
+
+	// Setting the nonce moves the next reservation on, and creates the context if not found
+	nonce.Nonce = 10
+	err = s.db.UpsertNonce(s.ctx, nonce)
+	assert.NoError(t, err)
+	err = s.db.ReserveNonces(s.ctx, nonce, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), nonce.Nonce)
+	upserted := &fftypes.Nonce{
+		Context: fftypes.NewRandB32(),
+		Group:   fftypes.NewRandB32(),
+		Topic:   "topic2",
+		Nonce:   5,
+	}
+	err = s.db.UpsertNonce(s.ctx, upserted)
+	assert.NoError(t, err)
+	nonceRead, err = s.db.GetNonce(s.ctx, upserted.Context)
+	assert.NoError(t, err)
+	assertJSONEqual(t, upserted, nonceRead)
+
+	fb := database.NonceQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("context", nonce.Context),
+		fb.Eq("nonce", nonce.Nonce),
+		fb.Eq("group", nonce.Group),
+		fb.Eq("topic", nonce.Topic),
+	)
+	nonces, res, err := s.db.GetNonces(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, nonces, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	err = s.db.DeleteNonce(s.ctx, nonce.Context)
+	assert.NoError(t, err)
+	nonces, _, err = s.db.GetNonces(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, nonces)
+}
+
+func testNextPins(t *testing.T, s *suite) {
+	nextpin := &fftypes.NextPin{
+		Context:  fftypes.NewRandB32(),
+		Identity: "did:firefly:org/org1",
+		Hash:     fftypes.NewRandB32(),
+		Nonce:    12345,
+	}
+	err := s.db.InsertNextPin(s.ctx, nextpin)
+	assert.NoError(t, err)
+	assert.Greater(t, nextpin.Sequence, int64(0))
+
+	nextpinRead, err := s.db.GetNextPinByContextAndIdentity(s.ctx, nextpin.Context, nextpin.Identity)
+	assert.NoError(t, err)
+	assertJSONEqual(t, nextpin, nextpinRead)
+
+	nextpinRead, err = s.db.GetNextPinByContextAndIdentity(s.ctx, nextpin.Context, "did:firefly:org/org2")
+	assert.NoError(t, err)
+	assert.Nil(t, nextpinRead)
+
+	nextpin.Nonce = 12346
+	nextpin.Hash = fftypes.NewRandB32()
+	err = s.db.UpdateNextPin(s.ctx, nextpin.Sequence, database.NextPinQueryFactory.NewUpdate(s.ctx).
+		Set("hash", nextpin.Hash).
+		Set("nonce", nextpin.Nonce),
+	)
+	assert.NoError(t, err)
+
+	nextpinRead, err = s.db.GetNextPinByHash(s.ctx, nextpin.Hash)
+	assert.NoError(t, err)
+	assertJSONEqual(t, nextpin, nextpinRead)
+
+	fb := database.NextPinQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("context", nextpin.Context),
+		fb.Eq("hash", nextpin.Hash),
+		fb.Eq("identity", nextpin.Identity),
+		fb.Eq("nonce", nextpin.Nonce),
+	)
+	nextpins, res, err := s.db.GetNextPins(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, nextpins, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	err = s.db.DeleteNextPin(s.ctx, nextpin.Sequence)
+	assert.NoError(t, err)
+	nextpins, _, err = s.db.GetNextPins(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, nextpins)
+}
+
+func testIdentityPrivateProfiles(t *testing.T, s *suite) {
+	profile := &fftypes.IdentityPrivateProfile{
+		Identity: fftypes.NewUUID(),
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Profile:  fftypes.JSONObject{"phone": "555-1234"},
+	}
+	err := s.db.UpsertIdentityPrivateProfile(s.ctx, profile)
+	assert.NoError(t, err)
+	assert.NotNil(t, profile.Updated)
+
+	profileRead, err := s.db.GetIdentityPrivateProfile(s.ctx, profile.Identity)
+	assert.NoError(t, err)
+	assert.Equal(t, profile.Author, profileRead.Author)
+	assert.Equal(t, *profile.Message, *profileRead.Message)
+	assert.Equal(t, "555-1234", profileRead.Profile.GetString("phone"))
+
+	// Upserting replaces the whole profile
+	profileUpdated := &fftypes.IdentityPrivateProfile{
+		Identity: profile.Identity,
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Profile:  fftypes.JSONObject{"email": "org1@example.com"},
+	}
+	err = s.db.UpsertIdentityPrivateProfile(s.ctx, profileUpdated)
+	assert.NoError(t, err)
+
+	profileRead, err = s.db.GetIdentityPrivateProfile(s.ctx, profile.Identity)
+	assert.NoError(t, err)
+	assert.Equal(t, *profileUpdated.Message, *profileRead.Message)
+	assert.Equal(t, fftypes.JSONObject{"email": "org1@example.com"}, profileRead.Profile)
+
+	profileRead, err = s.db.GetIdentityPrivateProfile(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, profileRead)
+}
+
+func testNamespaceSigners(t *testing.T, s *suite) {
+	signer := &fftypes.NamespaceSigner{
+		Namespace: "ns1",
+		SignerRef: fftypes.SignerRef{
+			Author: "did:firefly:org/org1",
+			Key:    "0x12345",
+		},
+	}
+	err := s.db.UpsertNamespaceSigner(s.ctx, signer)
+	assert.NoError(t, err)
+	assert.NotNil(t, signer.Updated)
+
+	signerRead, err := s.db.GetNamespaceSigner(s.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, signer.SignerRef, signerRead.SignerRef)
+
+	err = s.db.UpsertNamespaceSigner(s.ctx, &fftypes.NamespaceSigner{
+		Namespace: "ns1",
+		SignerRef: fftypes.SignerRef{
+			Key: "0x67890",
+		},
+	})
+	assert.NoError(t, err)
+	signerRead, err = s.db.GetNamespaceSigner(s.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SignerRef{Key: "0x67890"}, signerRead.SignerRef)
+
+	// Deleting a record that does not exist returns the sentinel error
+	err = s.db.DeleteNamespaceSigner(s.ctx, "ns1")
+	assert.NoError(t, err)
+	signerRead, err = s.db.GetNamespaceSigner(s.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, signerRead)
+	err = s.db.DeleteNamespaceSigner(s.ctx, "ns1")
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+}
@@ -0,0 +1,433 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMessage(ns string, data ...*fftypes.DataRef) *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.MessageTypeBroadcast,
+			SignerRef: fftypes.SignerRef{
+				Key:    "0x12345",
+				Author: "did:firefly:org/org1",
+			},
+			Created:   fftypes.Now(),
+			Namespace: ns,
+			Topics:    fftypes.FFStringArray{"topic1"},
+			DataHash:  fftypes.NewRandB32(),
+			TxType:    fftypes.TransactionTypeBatchPin,
+		},
+		Hash:  fftypes.NewRandB32(),
+		State: fftypes.MessageStateStaged,
+		Data:  data,
+	}
+}
+
+func testMessages(t *testing.T, s *suite) {
+	dataID1 := fftypes.NewUUID()
+	dataID2 := fftypes.NewUUID()
+	msg := newTestMessage("ns1",
+		&fftypes.DataRef{ID: dataID1, Hash: fftypes.NewRandB32()},
+		&fftypes.DataRef{ID: dataID2, Hash: fftypes.NewRandB32()},
+	)
+	msg.CorrelationID = "corr1"
+
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg.Header.ID, nil)
+
+	// The message is returned exactly as stored, including the order of the data refs, and the generated sequence
+	msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Greater(t, msgRead.Sequence, int64(0))
+	msg.Sequence = msgRead.Sequence
+	assertJSONEqual(t, msg, msgRead)
+
+	// Not found is a nil result, rather than an error
+	msgRead, err = s.db.GetMessageByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+
+	// Update all the mutable fields of the message with an upsert
+	msg.Header.CID = fftypes.NewUUID()
+	msg.Header.Tag = "tag1"
+	msg.Header.Topics = fftypes.FFStringArray{"topic1", "topic2"}
+	msg.Header.Group = fftypes.NewRandB32()
+	msg.Pins = fftypes.FFStringArray{fftypes.NewRandB32().String()}
+	msg.State = fftypes.MessageStateConfirmed
+	msg.Confirmed = fftypes.Now()
+	msg.BatchID = fftypes.NewUUID()
+	err = s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeUpdated, msg.Header.ID, nil)
+
+	msgRead, err = s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, msg, msgRead)
+
+	// Query on every field type
+	fb := database.MessageQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("id", msg.Header.ID.String()),
+		fb.Eq("namespace", msg.Header.Namespace),
+		fb.Eq("type", string(msg.Header.Type)),
+		fb.Eq("author", msg.Header.Author),
+		fb.Eq("topics", msg.Header.Topics),
+		fb.Eq("group", msg.Header.Group),
+		fb.Eq("cid", msg.Header.CID),
+		fb.Eq("batch", msg.BatchID),
+		fb.Eq("correlationid", "corr1"),
+		fb.Gt("created", "0"),
+		fb.Gt("confirmed", "0"),
+	)
+	msgs, res, err := s.db.GetMessages(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, msg, msgs[0])
+
+	msgIDs, err := s.db.GetMessageIDs(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Len(t, msgIDs, 1)
+	assert.Equal(t, *msg.Header.ID, msgIDs[0].ID)
+	assert.Equal(t, msg.Sequence, msgIDs[0].Sequence)
+
+	// Messages can be found by the data they refer to
+	msgs, _, err = s.db.GetMessagesForData(s.ctx, dataID2, database.MessageQueryFactory.NewFilter(s.ctx).And())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assertJSONEqual(t, msg, msgs[0])
+	msgs, _, err = s.db.GetMessagesForData(s.ctx, fftypes.NewUUID(), database.MessageQueryFactory.NewFilter(s.ctx).And())
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// Negative filter
+	msgs, _, err = s.db.GetMessages(s.ctx, fb.And(
+		fb.Eq("id", msg.Header.ID.String()),
+		fb.Eq("created", "0"),
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// Update a single message
+	group2 := fftypes.NewRandB32()
+	err = s.db.UpdateMessage(s.ctx, msg.Header.ID, database.MessageQueryFactory.NewUpdate(s.ctx).Set("group", group2))
+	assert.NoError(t, err)
+	msgs, _, err = s.db.GetMessages(s.ctx, fb.Eq("group", group2))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+
+	// Insert a set of messages in one operation, and update them all with a filter
+	msg2 := newTestMessage("ns1", &fftypes.DataRef{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()})
+	msg3 := newTestMessage("ns1")
+	err = s.db.InsertMessages(s.ctx, []*fftypes.Message{msg2, msg3})
+	assert.NoError(t, err)
+	assert.Greater(t, msg3.Sequence, msg2.Sequence)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg2.Header.ID, nil)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg3.Header.ID, nil)
+
+	err = s.db.UpdateMessages(s.ctx,
+		fb.In("id", []driver.Value{msg2.Header.ID, msg3.Header.ID}),
+		database.MessageQueryFactory.NewUpdate(s.ctx).Set("state", fftypes.MessageStateReady))
+	assert.NoError(t, err)
+	msgs, _, err = s.db.GetMessages(s.ctx, fb.Eq("state", fftypes.MessageStateReady))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+
+	// Replace moves the message to the end of the sequence
+	msg.State = fftypes.MessageStateReady
+	msg.Header.Group = group2
+	err = s.db.ReplaceMessage(s.ctx, msg)
+	assert.NoError(t, err)
+	msgRead, err = s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Greater(t, msgRead.Sequence, msg3.Sequence)
+	msg.Sequence = msgRead.Sequence
+	assertJSONEqual(t, msg, msgRead)
+}
+
+func testMessagesHashMismatch(t *testing.T, s *suite) {
+	msg := newTestMessage("ns1", &fftypes.DataRef{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()})
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationSkip)
+	assert.NoError(t, err)
+
+	// A change to the hash of an existing message must be rejected, regardless of the optimization
+	originalHash := msg.Hash
+	msg.Hash = fftypes.NewRandB32()
+	for _, optimization := range []database.UpsertOptimization{
+		database.UpsertOptimizationSkip,
+		database.UpsertOptimizationNew,
+		database.UpsertOptimizationExisting,
+	} {
+		err = s.db.UpsertMessage(s.ctx, msg, optimization)
+		assert.Equal(t, database.HashMismatch, err)
+	}
+
+	msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, originalHash, msgRead.Hash)
+}
+
+func testMessagesUpsertOptimizations(t *testing.T, s *suite) {
+	// An optimization is only a hint - inserting with the "existing" optimization must still create the message
+	msg := newTestMessage("ns1", &fftypes.DataRef{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()})
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg.Header.ID, nil)
+
+	msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, msgRead)
+
+	// ... and updating with the "new" optimization must still update it, without creating a duplicate
+	msg.State = fftypes.MessageStateSent
+	err = s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeUpdated, msg.Header.ID, nil)
+
+	fb := database.MessageQueryFactory.NewFilter(s.ctx)
+	msgs, _, err := s.db.GetMessages(s.ctx, fb.Eq("id", msg.Header.ID))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, fftypes.MessageStateSent, msgs[0].State)
+	assert.Len(t, msgs[0].Data, 1)
+}
+
+func testMessageRecipients(t *testing.T, s *suite) {
+	recipient := &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   fftypes.NewUUID(),
+		Node:      fftypes.NewUUID(),
+		Status:    fftypes.MessageRecipientStatusPending,
+	}
+	err := s.db.UpsertMessageRecipient(s.ctx, recipient)
+	assert.NoError(t, err)
+	assert.NotNil(t, recipient.Created)
+
+	other := &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   recipient.Message,
+		Node:      fftypes.NewUUID(),
+		Status:    fftypes.MessageRecipientStatusPending,
+	}
+	err = s.db.UpsertMessageRecipient(s.ctx, other)
+	assert.NoError(t, err)
+
+	// Upserting the same message and node updates the existing entry
+	err = s.db.UpsertMessageRecipient(s.ctx, &fftypes.MessageRecipient{
+		Namespace: "ns1",
+		Message:   recipient.Message,
+		Node:      recipient.Node,
+		Status:    fftypes.MessageRecipientStatusConfirmed,
+	})
+	assert.NoError(t, err)
+
+	fb := database.MessageRecipientQueryFactory.NewFilter(s.ctx)
+	recipients, res, err := s.db.GetMessageRecipients(s.ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("message", recipient.Message),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 2)
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	recipients, _, err = s.db.GetMessageRecipients(s.ctx, fb.And(
+		fb.Eq("node", recipient.Node),
+		fb.Eq("status", fftypes.MessageRecipientStatusConfirmed),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 1)
+	assert.Equal(t, recipient.Created.String(), recipients[0].Created.String())
+}
+
+func testData(t *testing.T, s *suite) {
+	data := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Datatype: &fftypes.DatatypeRef{
+			Name:    "customer",
+			Version: "0.0.1",
+		},
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"some":"data","with":{"nesting":12345}}`),
+		ValueSize: 12345,
+		Blob: &fftypes.BlobRef{
+			Hash:   fftypes.NewRandB32(),
+			Public: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+			Name:   "path/to/myfile.ext",
+			Size:   12345,
+		},
+	}
+	err := s.db.UpsertData(s.ctx, data, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionData, fftypes.ChangeEventTypeCreated, data.ID, nil)
+
+	// The value is only returned when requested
+	dataRead, err := s.db.GetDataByID(s.ctx, data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, *data.ID, *dataRead.ID)
+	assert.Nil(t, dataRead.Value)
+	dataRead, err = s.db.GetDataByID(s.ctx, data.ID, true)
+	assert.NoError(t, err)
+	assertJSONEqual(t, data, dataRead)
+
+	dataRead, err = s.db.GetDataByID(s.ctx, fftypes.NewUUID(), true)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead)
+
+	// Hash changes are rejected regardless of the optimization
+	updated := *data
+	updated.Hash = fftypes.NewRandB32()
+	err = s.db.UpsertData(s.ctx, &updated, database.UpsertOptimizationNew)
+	assert.Equal(t, database.HashMismatch, err)
+	err = s.db.UpsertData(s.ctx, &updated, database.UpsertOptimizationExisting)
+	assert.Equal(t, database.HashMismatch, err)
+
+	updated.Hash = data.Hash
+	updated.Value = fftypes.JSONAnyPtr(`{"another":"value"}`)
+	err = s.db.UpsertData(s.ctx, &updated, database.UpsertOptimizationSkip)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionData, fftypes.ChangeEventTypeUpdated, data.ID, nil)
+
+	fb := database.DataQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("id", data.ID.String()),
+		fb.Eq("namespace", data.Namespace),
+		fb.Eq("validator", string(data.Validator)),
+		fb.Eq("datatype.name", data.Datatype.Name),
+		fb.Eq("datatype.version", data.Datatype.Version),
+		fb.Eq("hash", data.Hash),
+		fb.Gt("created", 0),
+	)
+	dataRes, res, err := s.db.GetData(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, &updated, dataRes[0])
+
+	dataRefs, _, err := s.db.GetDataRefs(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Len(t, dataRefs, 1)
+	assert.Equal(t, *data.ID, *dataRefs[0].ID)
+	assert.Equal(t, *data.Hash, *dataRefs[0].Hash)
+
+	// Insert an array of data in one operation
+	data2 := &fftypes.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Created: fftypes.Now(), Value: fftypes.JSONAnyPtr(`"two"`)}
+	data3 := &fftypes.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Created: fftypes.Now(), Value: fftypes.JSONAnyPtr(`"three"`)}
+	err = s.db.InsertDataArray(s.ctx, fftypes.DataArray{data2, data3})
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionData, fftypes.ChangeEventTypeCreated, data3.ID, nil)
+	dataRes, _, err = s.db.GetData(s.ctx, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 3)
+
+	// Update
+	up := database.DataQueryFactory.NewUpdate(s.ctx).Set("validator", fftypes.ValidatorTypeSystemDefinition)
+	err = s.db.UpdateData(s.ctx, data2.ID, up)
+	assert.NoError(t, err)
+	dataRes, _, err = s.db.GetData(s.ctx, fb.Eq("validator", fftypes.ValidatorTypeSystemDefinition))
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 1)
+	assert.Equal(t, *data2.ID, *dataRes[0].ID)
+}
+
+func testBatches(t *testing.T, s *suite) {
+	msgID1 := fftypes.NewUUID()
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.BatchTypeBroadcast,
+			SignerRef: fftypes.SignerRef{
+				Key:    "0x12345",
+				Author: "did:firefly:org/org1",
+			},
+			Namespace: "ns1",
+			Node:      fftypes.NewUUID(),
+			Created:   fftypes.Now(),
+		},
+		Hash: fftypes.NewRandB32(),
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeBatchPin,
+			ID:   fftypes.NewUUID(),
+		},
+		Manifest: fftypes.JSONAnyPtr((&fftypes.BatchManifest{
+			Messages: []*fftypes.MessageManifestEntry{
+				{MessageRef: fftypes.MessageRef{ID: msgID1}},
+			},
+		}).String()),
+	}
+	err := s.db.UpsertBatch(s.ctx, batch)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionBatches, fftypes.ChangeEventTypeCreated, batch.ID, nil)
+
+	batchRead, err := s.db.GetBatchByID(s.ctx, batch.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, batch, batchRead)
+
+	batchRead, err = s.db.GetBatchByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, batchRead)
+
+	// Hash changes are rejected
+	updated := *batch
+	updated.Hash = fftypes.NewRandB32()
+	err = s.db.UpsertBatch(s.ctx, &updated)
+	assert.Equal(t, database.HashMismatch, err)
+
+	updated.Hash = batch.Hash
+	updated.PayloadRef = "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD"
+	updated.Confirmed = fftypes.Now()
+	err = s.db.UpsertBatch(s.ctx, &updated)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionBatches, fftypes.ChangeEventTypeUpdated, batch.ID, nil)
+
+	batchRead, err = s.db.GetBatchByID(s.ctx, batch.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, &updated, batchRead)
+
+	fb := database.BatchQueryFactory.NewFilter(s.ctx)
+	batches, res, err := s.db.GetBatches(s.ctx, fb.And(
+		fb.Eq("id", batch.ID.String()),
+		fb.Eq("namespace", batch.Namespace),
+		fb.Eq("author", batch.Author),
+		fb.Eq("tx.id", batch.TX.ID),
+		fb.Gt("created", "0"),
+		fb.Gt("confirmed", "0"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, &updated, batches[0])
+
+	// Update
+	err = s.db.UpdateBatch(s.ctx, batch.ID, database.BatchQueryFactory.NewUpdate(s.ctx).Set("author", "did:firefly:org/org2"))
+	assert.NoError(t, err)
+	batches, _, err = s.db.GetBatches(s.ctx, fb.Eq("author", "did:firefly:org/org2"))
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+}
@@ -0,0 +1,600 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPins(t *testing.T, s *suite) {
+	pin := &fftypes.Pin{
+		Masked:    true,
+		Hash:      fftypes.NewRandB32(),
+		Batch:     fftypes.NewUUID(),
+		BatchHash: fftypes.NewRandB32(),
+		Index:     10,
+		Created:   fftypes.Now(),
+		Signer:    "0x12345",
+	}
+	err := s.db.UpsertPin(s.ctx, pin)
+	assert.NoError(t, err)
+	assert.Greater(t, pin.Sequence, int64(0))
+	s.assertChangeEvent(t, database.CollectionPins, fftypes.ChangeEventTypeCreated, nil, nil)
+
+	fb := database.PinQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("masked", pin.Masked),
+		fb.Eq("hash", pin.Hash),
+		fb.Eq("batch", pin.Batch),
+		fb.Gt("created", 0),
+	)
+	pins, res, err := s.db.GetPins(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, pins, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	err = s.db.UpdatePins(s.ctx, fb.Eq("sequence", pin.Sequence), database.PinQueryFactory.NewUpdate(s.ctx).Set("dispatched", true))
+	assert.NoError(t, err)
+
+	// Upserting a pin that already exists must not insert it twice, and must return the stored sequence and state
+	existingSequence := pin.Sequence
+	pin.Sequence = 99999
+	err = s.db.UpsertPin(s.ctx, pin)
+	assert.NoError(t, err)
+	assert.Equal(t, existingSequence, pin.Sequence)
+	assert.True(t, pin.Dispatched)
+	pins, _, err = s.db.GetPins(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Len(t, pins, 1)
+
+	pin2 := &fftypes.Pin{
+		Hash:      fftypes.NewRandB32(),
+		Batch:     pin.Batch,
+		BatchHash: pin.BatchHash,
+		Index:     11,
+		Created:   fftypes.Now(),
+	}
+	err = s.db.InsertPins(s.ctx, []*fftypes.Pin{pin2})
+	assert.NoError(t, err)
+	pins, _, err = s.db.GetPins(s.ctx, fb.Eq("batch", pin.Batch).Sort("sequence"))
+	assert.NoError(t, err)
+	assert.Len(t, pins, 2)
+	assert.Equal(t, int64(11), pins[1].Index)
+
+	err = s.db.DeletePin(s.ctx, pin.Sequence)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionPins, fftypes.ChangeEventTypeDeleted, nil, nil)
+	pins, _, err = s.db.GetPins(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, pins)
+}
+
+func testOutbox(t *testing.T, s *suite) {
+	entry := &fftypes.OutboxEntry{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		NextAttempt: fftypes.Now(),
+	}
+	hookCalled := false
+	err := s.db.InsertOutboxEntry(s.ctx, entry, func() {
+		hookCalled = true
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	assert.NotNil(t, entry.Created)
+
+	// Entries are insert only
+	err = s.db.InsertOutboxEntry(s.ctx, entry)
+	assert.Error(t, err)
+
+	entryRead, err := s.db.GetOutboxEntryByID(s.ctx, entry.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *entry.ID, *entryRead.ID)
+	assert.Equal(t, entry.NextAttempt.String(), entryRead.NextAttempt.String())
+
+	next := fftypes.FFTime(entry.NextAttempt.Time().Add(1 * time.Second))
+	err = s.db.UpdateOutboxEntry(s.ctx, entry.ID, database.OutboxQueryFactory.NewUpdate(s.ctx).
+		Set("attempts", 1).
+		Set("next", &next).
+		Set("error", "pop"),
+	)
+	assert.NoError(t, err)
+
+	fb := database.OutboxQueryFactory.NewFilter(s.ctx)
+	entries, res, err := s.db.GetOutboxEntries(s.ctx, fb.Lte("next", entry.NextAttempt).Count(true))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, int64(0), *res.TotalCount)
+	entries, _, err = s.db.GetOutboxEntries(s.ctx, fb.Lte("next", &next))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.Equal(t, "pop", entries[0].Error)
+
+	err = s.db.DeleteOutboxEntry(s.ctx, entry.ID)
+	assert.NoError(t, err)
+	entryRead, err = s.db.GetOutboxEntryByID(s.ctx, entry.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, entryRead)
+}
+
+func testOpCallbacks(t *testing.T, s *suite) {
+	callback := &fftypes.OpCallback{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Status:      fftypes.OpCallbackStatusPending,
+		NextAttempt: fftypes.Now(),
+	}
+	hookCalled := false
+	err := s.db.InsertOpCallback(s.ctx, callback, func() {
+		hookCalled = true
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	assert.NotNil(t, callback.Created)
+
+	// An operation only has one callback
+	err = s.db.InsertOpCallback(s.ctx, callback)
+	assert.Error(t, err)
+
+	callbackRead, err := s.db.GetOpCallbackByID(s.ctx, callback.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *callback.ID, *callbackRead.ID)
+	assert.Equal(t, fftypes.OpCallbackStatusPending, callbackRead.Status)
+	assert.Equal(t, callback.NextAttempt.String(), callbackRead.NextAttempt.String())
+
+	next := fftypes.FFTime(callback.NextAttempt.Time().Add(1 * time.Second))
+	err = s.db.UpdateOpCallback(s.ctx, callback.ID, database.OpCallbackQueryFactory.NewUpdate(s.ctx).
+		Set("attempts", 1).
+		Set("next", &next).
+		Set("error", "pop"),
+	)
+	assert.NoError(t, err)
+
+	fb := database.OpCallbackQueryFactory.NewFilter(s.ctx)
+	callbacks, res, err := s.db.GetOpCallbacks(s.ctx, fb.Lte("next", callback.NextAttempt).Count(true))
+	assert.NoError(t, err)
+	assert.Empty(t, callbacks)
+	assert.Equal(t, int64(0), *res.TotalCount)
+	callbacks, _, err = s.db.GetOpCallbacks(s.ctx, fb.Lte("next", &next))
+	assert.NoError(t, err)
+	assert.Len(t, callbacks, 1)
+	assert.Equal(t, 1, callbacks[0].Attempts)
+	assert.Equal(t, "pop", callbacks[0].Error)
+
+	err = s.db.UpdateOpCallback(s.ctx, callback.ID, database.OpCallbackQueryFactory.NewUpdate(s.ctx).
+		Set("status", fftypes.OpCallbackStatusDelivered),
+	)
+	assert.NoError(t, err)
+	callbacks, _, err = s.db.GetOpCallbacks(s.ctx, fb.Eq("status", fftypes.OpCallbackStatusPending))
+	assert.NoError(t, err)
+	assert.Empty(t, callbacks)
+}
+
+func testBlobs(t *testing.T, s *suite) {
+	blob := &fftypes.Blob{
+		Hash:       fftypes.NewRandB32(),
+		Size:       12345,
+		PayloadRef: fftypes.NewRandB32().String(),
+		Peer:       "peer1",
+		Created:    fftypes.Now(),
+	}
+	err := s.db.InsertBlob(s.ctx, blob)
+	assert.NoError(t, err)
+
+	blobRead, err := s.db.GetBlobMatchingHash(s.ctx, blob.Hash)
+	assert.NoError(t, err)
+	assertJSONEqual(t, blob, blobRead)
+
+	blobRead, err = s.db.GetBlobMatchingHash(s.ctx, fftypes.NewRandB32())
+	assert.NoError(t, err)
+	assert.Nil(t, blobRead)
+
+	fb := database.BlobQueryFactory.NewFilter(s.ctx)
+	filter := fb.And(
+		fb.Eq("hash", blob.Hash),
+		fb.Eq("payloadref", blob.PayloadRef),
+		fb.Eq("created", blob.Created),
+	)
+	blobs, res, err := s.db.GetBlobs(s.ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, blobs, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, blob, blobs[0])
+	assert.Greater(t, blobs[0].Sequence, int64(0))
+
+	err = s.db.DeleteBlob(s.ctx, blobs[0].Sequence)
+	assert.NoError(t, err)
+	blobs, _, err = s.db.GetBlobs(s.ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, blobs)
+}
+
+func testChartHistogram(t *testing.T, s *suite) {
+	start := time.Now().Add(-1 * time.Minute)
+	for _, eventType := range []fftypes.EventType{
+		fftypes.EventTypeMessageConfirmed,
+		fftypes.EventTypeMessageConfirmed,
+		fftypes.EventTypeTransactionSubmitted,
+	} {
+		err := s.db.InsertEvent(s.ctx, fftypes.NewEvent(eventType, "ns1", fftypes.NewUUID(), nil, "topic1"))
+		assert.NoError(t, err)
+	}
+	// Events in other namespaces must not be counted
+	err := s.db.InsertEvent(s.ctx, fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, "ns2", fftypes.NewUUID(), nil, "topic1"))
+	assert.NoError(t, err)
+
+	startTime := fftypes.FFTime(start)
+	midTime := fftypes.FFTime(start.Add(2 * time.Minute))
+	endTime := fftypes.FFTime(start.Add(4 * time.Minute))
+	histogram, err := s.db.GetChartHistogram(s.ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: &startTime, EndTime: &midTime},
+		{StartTime: &midTime, EndTime: &endTime},
+	}, database.CollectionName(database.CollectionEvents), "")
+	assert.NoError(t, err)
+	assert.Len(t, histogram, 2)
+	assert.Equal(t, "3", histogram[0].Count)
+	assert.Equal(t, "0", histogram[1].Count)
+	typeCounts := map[string]string{}
+	for _, bucketType := range histogram[0].Types {
+		typeCounts[bucketType.Type] = bucketType.Count
+	}
+	assert.Equal(t, "2", typeCounts[fftypes.EventTypeMessageConfirmed.String()])
+	assert.Equal(t, "1", typeCounts[fftypes.EventTypeTransactionSubmitted.String()])
+
+	_, err = s.db.GetChartHistogram(s.ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: &startTime, EndTime: &midTime},
+	}, database.CollectionName("unknown"), "")
+	assert.Error(t, err)
+}
+
+func testChartHistogramGroupBy(t *testing.T, s *suite) {
+	start := time.Now().Add(-1 * time.Minute)
+	for _, tag := range []string{"invoice", "invoice", "payment"} {
+		msg := newTestMessage("ns1")
+		msg.Header.Tag = tag
+		err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+	// Tags in other namespaces must not be counted, or grouped by
+	msg := newTestMessage("ns2")
+	msg.Header.Tag = "other"
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	startTime := fftypes.FFTime(start)
+	endTime := fftypes.FFTime(start.Add(2 * time.Minute))
+	histogram, err := s.db.GetChartHistogram(s.ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: &startTime, EndTime: &endTime},
+	}, database.CollectionName(database.CollectionMessages), "tag")
+	assert.NoError(t, err)
+	assert.Len(t, histogram, 1)
+	assert.Equal(t, "3", histogram[0].Count)
+	tagCounts := map[string]string{}
+	for _, bucketType := range histogram[0].Types {
+		tagCounts[bucketType.Type] = bucketType.Count
+	}
+	assert.Equal(t, map[string]string{"invoice": "2", "payment": "1"}, tagCounts)
+
+	_, err = s.db.GetChartHistogram(s.ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: &startTime, EndTime: &endTime},
+	}, database.CollectionName(database.CollectionMessages), "id")
+	assert.Error(t, err)
+}
+
+func testChartLatencyHistogram(t *testing.T, s *suite) {
+	start := time.Now().Add(-10 * time.Minute)
+	for i, latency := range []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		msg := newTestMessage("ns1")
+		created := fftypes.FFTime(start.Add(time.Duration(i) * time.Second))
+		confirmed := fftypes.FFTime(time.Time(created).Add(latency))
+		msg.Header.Created = &created
+		msg.Confirmed = &confirmed
+		err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+	// Messages that are not confirmed are not counted
+	msg := newTestMessage("ns1")
+	created := fftypes.FFTime(start)
+	msg.Header.Created = &created
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	startTime := fftypes.FFTime(start)
+	midTime := fftypes.FFTime(start.Add(1 * time.Minute))
+	endTime := fftypes.FFTime(start.Add(2 * time.Minute))
+	histogram, err := s.db.GetChartLatencyHistogram(s.ctx, "ns1", []fftypes.ChartHistogramInterval{
+		{StartTime: &startTime, EndTime: &midTime},
+		{StartTime: &midTime, EndTime: &endTime},
+	}, []float64{50, 100})
+	assert.NoError(t, err)
+	assert.Len(t, histogram, 2)
+	assert.Equal(t, "4", histogram[0].Count)
+	assert.Len(t, histogram[0].Latencies, 2)
+	assert.Equal(t, float64(50), histogram[0].Latencies[0].Percentile)
+	assert.Equal(t, 2*time.Second, time.Duration(*histogram[0].Latencies[0].Latency))
+	assert.Equal(t, 8*time.Second, time.Duration(*histogram[0].Latencies[1].Latency))
+	assert.Equal(t, "0", histogram[1].Count)
+	assert.Empty(t, histogram[1].Latencies)
+}
+
+func testSearch(t *testing.T, s *suite) {
+	if !s.db.Capabilities().FullTextSearch {
+		t.Skip("full-text search not supported")
+	}
+
+	msg := newTestMessage("ns1")
+	msg.Header.Tag = "shipment"
+	msg.Header.Topics = fftypes.FFStringArray{"widgets", "orders"}
+	err := s.db.UpsertMessage(s.ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	data := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"description":"urgent shipment of widgets, more widgets to follow"}`),
+	}
+	err = s.db.UpsertData(s.ctx, data, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	event := &fftypes.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "WidgetsShipped",
+		Output:    fftypes.JSONObject{"status": "delivered"},
+		Timestamp: fftypes.Now(),
+	}
+	err = s.db.InsertBlockchainEvent(s.ctx, event)
+	assert.NoError(t, err)
+
+	otherNS := &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns2",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`"widgets"`),
+	}
+	err = s.db.UpsertData(s.ctx, otherNS, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	// Results are ranked, so the data with the most hits comes first
+	fb := database.SearchQueryFactory.NewFilter(s.ctx)
+	results, res, err := s.db.Search(s.ctx, "widgets", fb.Eq("namespace", "ns1").Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *res.TotalCount)
+	assert.Len(t, results, 2)
+	assert.Equal(t, *data.ID, *results[0].ID)
+	assert.Equal(t, *msg.Header.ID, *results[1].ID)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	// Query syntax must be treated as plain terms, which must all match
+	results, _, err = s.db.Search(s.ctx, `"delivered" OR`, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	results, _, err = s.db.Search(s.ctx, `"delivered"`, fb.Eq("namespace", "ns1"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, fftypes.SearchResultTypeBlockchainEvent, results[0].Type)
+
+	results, _, err = s.db.Search(s.ctx, "widgets", fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("type", fftypes.SearchResultTypeMessage),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, *msg.Header.ID, *results[0].ID)
+}
+
+func testFilterCombinations(t *testing.T, s *suite) {
+	tags := []string{"alpha", "Beta", "gamma", "delta", "alphabet"}
+	msgs := make([]*fftypes.Message, len(tags))
+	for i, tag := range tags {
+		msgs[i] = newTestMessage("ns1")
+		msgs[i].Header.Tag = tag
+		err := s.db.UpsertMessage(s.ctx, msgs[i], database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	fb := database.MessageQueryFactory.NewFilter(s.ctx)
+	checkTags := func(filter database.Filter, expected ...string) {
+		results, res, err := s.db.GetMessages(s.ctx, filter.Sort("sequence").Count(true))
+		assert.NoError(t, err)
+		if expected == nil {
+			expected = []string{}
+		}
+		actual := make([]string, len(results))
+		for i, msg := range results {
+			actual[i] = msg.Header.Tag
+		}
+		assert.Equal(t, expected, actual, "%s", filter)
+		assert.Equal(t, int64(len(expected)), *res.TotalCount, "%s", filter)
+	}
+
+	checkTags(fb.Eq("tag", "alpha"), "alpha")
+	checkTags(fb.Neq("tag", "alpha"), "Beta", "gamma", "delta", "alphabet")
+	checkTags(fb.IEq("tag", "BETA"), "Beta")
+	checkTags(fb.NIeq("tag", "BETA"), "alpha", "gamma", "delta", "alphabet")
+	checkTags(fb.In("tag", []driver.Value{"alpha", "gamma", "unknown"}), "alpha", "gamma")
+	checkTags(fb.NotIn("tag", []driver.Value{"alpha", "gamma"}), "Beta", "delta", "alphabet")
+	checkTags(fb.Contains("tag", "lph"), "alpha", "alphabet")
+	checkTags(fb.NotContains("tag", "lph"), "Beta", "gamma", "delta")
+	checkTags(fb.IContains("tag", "BET"), "Beta", "alphabet")
+	checkTags(fb.NotIContains("tag", "BET"), "alpha", "gamma", "delta")
+	checkTags(fb.StartsWith("tag", "alpha"), "alpha", "alphabet")
+	checkTags(fb.NotStartsWith("tag", "alpha"), "Beta", "gamma", "delta")
+	checkTags(fb.IStartsWith("tag", "b"), "Beta")
+	checkTags(fb.NotIStartsWith("tag", "b"), "alpha", "gamma", "delta", "alphabet")
+	checkTags(fb.EndsWith("tag", "ta"), "Beta", "delta")
+	checkTags(fb.NotEndsWith("tag", "a"), "alphabet")
+	checkTags(fb.IEndsWith("tag", "TA"), "Beta", "delta")
+	checkTags(fb.NotIEndsWith("tag", "A"), "alphabet")
+	checkTags(fb.Gte("sequence", msgs[3].Sequence), "delta", "alphabet")
+	checkTags(fb.Lt("sequence", msgs[1].Sequence), "alpha")
+	checkTags(fb.Or(
+		fb.Eq("tag", "alpha"),
+		fb.Eq("tag", "gamma"),
+	), "alpha", "gamma")
+	checkTags(fb.And(
+		fb.StartsWith("tag", "alpha"),
+		fb.Neq("tag", "alpha"),
+	), "alphabet")
+	checkTags(fb.And(
+		fb.Or(
+			fb.EndsWith("tag", "ta"),
+			fb.StartsWith("tag", "alpha"),
+		),
+		fb.Gt("sequence", msgs[0].Sequence),
+	), "Beta", "delta", "alphabet")
+	checkTags(fb.And(
+		fb.Eq("tag", "alpha"),
+		fb.Eq("tag", "Beta"),
+	))
+
+	// Paging applies after sorting, and does not affect the total count
+	results, res, err := s.db.GetMessages(s.ctx, fb.And().
+		Sort("sequence").
+		Descending().
+		Skip(1).
+		Limit(2).
+		Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, *msgs[3].Header.ID, *results[0].Header.ID)
+	assert.Equal(t, *msgs[2].Header.ID, *results[1].Header.ID)
+	assert.Equal(t, int64(len(msgs)), *res.TotalCount)
+
+	results, _, err = s.db.GetMessages(s.ctx, fb.And().Sort("sequence").Skip(uint64(len(msgs))))
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func testRunAsGroupCommit(t *testing.T, s *suite) {
+	msg := newTestMessage("ns1")
+	hookCalled := false
+	err := s.db.RunAsGroup(s.ctx, func(ctx context.Context) error {
+		err := s.db.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		err = s.db.InsertOperation(ctx, &fftypes.Operation{
+			ID:          fftypes.NewUUID(),
+			Namespace:   "ns1",
+			Transaction: fftypes.NewUUID(),
+			Type:        fftypes.OpTypeBlockchainPinBatch,
+			Status:      fftypes.OpStatusPending,
+			Created:     fftypes.Now(),
+		}, func() {
+			hookCalled = true
+		})
+		assert.NoError(t, err)
+
+		// Operations within the group are visible to the group
+		msgRead, err := s.db.GetMessageByID(ctx, msg.Header.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, msgRead)
+
+		// Change events and post-commit hooks must wait for the group to be committed
+		assert.Empty(t, s.callbacks.Events())
+		assert.False(t, hookCalled)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg.Header.ID, nil)
+	s.assertChangeEvent(t, database.CollectionOperations, fftypes.ChangeEventTypeCreated, nil, nil)
+
+	msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, msgRead)
+}
+
+func testRunAsGroupRollback(t *testing.T, s *suite) {
+	msg := newTestMessage("ns1")
+	hookCalled := false
+	err := s.db.RunAsGroup(s.ctx, func(ctx context.Context) error {
+		err := s.db.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		err = s.db.InsertOperation(ctx, &fftypes.Operation{
+			ID:          fftypes.NewUUID(),
+			Namespace:   "ns1",
+			Transaction: fftypes.NewUUID(),
+			Type:        fftypes.OpTypeBlockchainPinBatch,
+			Status:      fftypes.OpStatusPending,
+			Created:     fftypes.Now(),
+		}, func() {
+			hookCalled = true
+		})
+		assert.NoError(t, err)
+		return fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "pop", err)
+
+	// Nothing from a failed group is stored, and no events or hooks are fired
+	assert.False(t, hookCalled)
+	assert.Empty(t, s.callbacks.Events())
+	msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+}
+
+func testRunAsGroupNested(t *testing.T, s *suite) {
+	msg1 := newTestMessage("ns1")
+	msg2 := newTestMessage("ns1")
+
+	// A nested group joins the outer group, so a failure in the outer group discards the work of both
+	err := s.db.RunAsGroup(s.ctx, func(ctx context.Context) error {
+		err := s.db.UpsertMessage(ctx, msg1, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		err = s.db.RunAsGroup(ctx, func(ctx context.Context) error {
+			return s.db.UpsertMessage(ctx, msg2, database.UpsertOptimizationNew)
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, s.callbacks.Events())
+		return fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "pop", err)
+	assert.Empty(t, s.callbacks.Events())
+	for _, msg := range []*fftypes.Message{msg1, msg2} {
+		msgRead, err := s.db.GetMessageByID(s.ctx, msg.Header.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, msgRead)
+	}
+
+	// On success, everything from both groups is committed together
+	err = s.db.RunAsGroup(s.ctx, func(ctx context.Context) error {
+		err := s.db.UpsertMessage(ctx, msg1, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		return s.db.RunAsGroup(ctx, func(ctx context.Context) error {
+			return s.db.UpsertMessage(ctx, msg2, database.UpsertOptimizationNew)
+		})
+	})
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg1.Header.ID, nil)
+	s.assertChangeEvent(t, database.CollectionMessages, fftypes.ChangeEventTypeCreated, msg2.Header.ID, nil)
+	fb := database.MessageQueryFactory.NewFilter(s.ctx)
+	msgs, _, err := s.db.GetMessages(s.ctx, fb.In("id", []driver.Value{msg1.Header.ID, msg2.Header.ID}))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+}
@@ -0,0 +1,260 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTokenPools(t *testing.T, s *suite) {
+	pool := &fftypes.TokenPool{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Name:       "my-pool",
+		Standard:   "ERC1155",
+		Type:       fftypes.TokenTypeFungible,
+		ProtocolID: "12345",
+		Connector:  "erc1155",
+		Symbol:     "COIN",
+		Message:    fftypes.NewUUID(),
+		State:      fftypes.TokenPoolStateConfirmed,
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeTokenPool,
+			ID:   fftypes.NewUUID(),
+		},
+		Info: fftypes.JSONObject{
+			"pool": "info",
+		},
+	}
+	err := s.db.UpsertTokenPool(s.ctx, pool)
+	assert.NoError(t, err)
+	assert.NotNil(t, pool.Created)
+	s.assertChangeEvent(t, database.CollectionTokenPools, fftypes.ChangeEventTypeCreated, pool.ID, nil)
+
+	poolRead, err := s.db.GetTokenPoolByID(s.ctx, pool.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, pool, poolRead)
+	poolRead, err = s.db.GetTokenPool(s.ctx, pool.Namespace, pool.Name)
+	assert.NoError(t, err)
+	assertJSONEqual(t, pool, poolRead)
+	poolRead, err = s.db.GetTokenPoolByProtocolID(s.ctx, pool.Connector, pool.ProtocolID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, pool, poolRead)
+
+	poolRead, err = s.db.GetTokenPoolByProtocolID(s.ctx, "erc20", pool.ProtocolID)
+	assert.NoError(t, err)
+	assert.Nil(t, poolRead)
+
+	// A different ID for an existing name must be rejected
+	err = s.db.UpsertTokenPool(s.ctx, &fftypes.TokenPool{
+		ID:        fftypes.NewUUID(),
+		Namespace: pool.Namespace,
+		Name:      pool.Name,
+	})
+	assert.Equal(t, database.IDMismatch, err)
+
+	pool.ProtocolID = "67890"
+	pool.Type = fftypes.TokenTypeNonFungible
+	err = s.db.UpsertTokenPool(s.ctx, pool)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionTokenPools, fftypes.ChangeEventTypeUpdated, pool.ID, nil)
+
+	fb := database.TokenPoolQueryFactory.NewFilter(s.ctx)
+	pools, res, err := s.db.GetTokenPools(s.ctx, fb.And(
+		fb.Eq("id", pool.ID.String()),
+		fb.Eq("namespace", pool.Namespace),
+		fb.Eq("name", pool.Name),
+		fb.Eq("protocolid", pool.ProtocolID),
+		fb.Eq("message", pool.Message),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, pools, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, pool, pools[0])
+}
+
+func testTokenBalances(t *testing.T, s *suite) {
+	transfer := &fftypes.TokenTransfer{
+		Pool:       fftypes.NewUUID(),
+		TokenIndex: "1",
+		URI:        "firefly://token/1",
+		Connector:  "erc1155",
+		Namespace:  "ns1",
+		To:         "0x0",
+		Amount:     *fftypes.NewFFBigInt(10),
+	}
+
+	// A mint only credits the recipient
+	err := s.db.UpdateTokenBalances(s.ctx, transfer)
+	assert.NoError(t, err)
+	balance, err := s.db.GetTokenBalance(s.ctx, transfer.Pool, "1", "0x0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), balance.Balance.Int().Int64())
+	assert.Equal(t, transfer.URI, balance.URI)
+	assert.NotNil(t, balance.Updated)
+
+	// A transfer moves the amount between the two keys
+	transfer.From = "0x0"
+	transfer.To = "0x1"
+	transfer.Amount = *fftypes.NewFFBigInt(4)
+	err = s.db.UpdateTokenBalances(s.ctx, transfer)
+	assert.NoError(t, err)
+	balance, err = s.db.GetTokenBalance(s.ctx, transfer.Pool, "1", "0x0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), balance.Balance.Int().Int64())
+	balance, err = s.db.GetTokenBalance(s.ctx, transfer.Pool, "1", "0x1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), balance.Balance.Int().Int64())
+
+	balance, err = s.db.GetTokenBalance(s.ctx, transfer.Pool, "2", "0x1")
+	assert.NoError(t, err)
+	assert.Nil(t, balance)
+
+	fb := database.TokenBalanceQueryFactory.NewFilter(s.ctx)
+	balances, res, err := s.db.GetTokenBalances(s.ctx, fb.And(
+		fb.Eq("pool", transfer.Pool),
+		fb.Eq("tokenindex", transfer.TokenIndex),
+	).Sort("key").Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	accounts, _, err := s.db.GetTokenAccounts(s.ctx, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+	keys := make([]string, len(accounts))
+	for i, account := range accounts {
+		keys[i] = account.Key
+	}
+	assert.ElementsMatch(t, []string{"0x0", "0x1"}, keys)
+
+	pools, _, err := s.db.GetTokenAccountPools(s.ctx, "0x1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, pools, 1)
+	assert.Equal(t, *transfer.Pool, *pools[0].Pool)
+	pools, _, err = s.db.GetTokenAccountPools(s.ctx, "0x2", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, pools)
+}
+
+func testTokenTransfers(t *testing.T, s *suite) {
+	transfer := &fftypes.TokenTransfer{
+		LocalID:     fftypes.NewUUID(),
+		Type:        fftypes.TokenTransferTypeTransfer,
+		Pool:        fftypes.NewUUID(),
+		TokenIndex:  "1",
+		URI:         "firefly://token/1",
+		Connector:   "erc1155",
+		Namespace:   "ns1",
+		From:        "0x01",
+		To:          "0x02",
+		ProtocolID:  "12345",
+		Message:     fftypes.NewUUID(),
+		MessageHash: fftypes.NewRandB32(),
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeTokenTransfer,
+			ID:   fftypes.NewUUID(),
+		},
+		BlockchainEvent: fftypes.NewUUID(),
+		Amount:          *fftypes.NewFFBigInt(10),
+	}
+	err := s.db.UpsertTokenTransfer(s.ctx, transfer)
+	assert.NoError(t, err)
+	assert.NotNil(t, transfer.Created)
+	s.assertChangeEvent(t, database.CollectionTokenTransfers, fftypes.ChangeEventTypeCreated, transfer.LocalID, nil)
+
+	transferRead, err := s.db.GetTokenTransfer(s.ctx, transfer.LocalID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, transfer, transferRead)
+	transferRead, err = s.db.GetTokenTransferByProtocolID(s.ctx, transfer.Connector, transfer.ProtocolID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, transfer, transferRead)
+
+	transferRead, err = s.db.GetTokenTransferByProtocolID(s.ctx, "erc20", transfer.ProtocolID)
+	assert.NoError(t, err)
+	assert.Nil(t, transferRead)
+
+	transfer.Type = fftypes.TokenTransferTypeMint
+	transfer.Amount = *fftypes.NewFFBigInt(1)
+	transfer.To = "0x03"
+	err = s.db.UpsertTokenTransfer(s.ctx, transfer)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionTokenTransfers, fftypes.ChangeEventTypeUpdated, transfer.LocalID, nil)
+
+	fb := database.TokenTransferQueryFactory.NewFilter(s.ctx)
+	transfers, res, err := s.db.GetTokenTransfers(s.ctx, fb.And(
+		fb.Eq("pool", transfer.Pool),
+		fb.Eq("tokenindex", transfer.TokenIndex),
+		fb.Eq("from", transfer.From),
+		fb.Eq("to", transfer.To),
+		fb.Eq("protocolid", transfer.ProtocolID),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, transfer, transfers[0])
+}
+
+func testTokenApprovals(t *testing.T, s *suite) {
+	approval := &fftypes.TokenApproval{
+		LocalID:    fftypes.NewUUID(),
+		Pool:       fftypes.NewUUID(),
+		Connector:  "erc1155",
+		Namespace:  "ns1",
+		Key:        "0x01",
+		Operator:   "0x02",
+		Approved:   true,
+		ProtocolID: "12345",
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeTokenApproval,
+			ID:   fftypes.NewUUID(),
+		},
+		BlockchainEvent: fftypes.NewUUID(),
+	}
+	err := s.db.UpsertTokenApproval(s.ctx, approval)
+	assert.NoError(t, err)
+	assert.NotNil(t, approval.Created)
+	s.assertChangeEvent(t, database.CollectionTokenApprovals, fftypes.ChangeEventTypeCreated, approval.LocalID, nil)
+
+	approvalRead, err := s.db.GetTokenApproval(s.ctx, approval.LocalID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, approval, approvalRead)
+	approvalRead, err = s.db.GetTokenApprovalByProtocolID(s.ctx, approval.Connector, approval.ProtocolID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, approval, approvalRead)
+
+	approval.Approved = false
+	err = s.db.UpsertTokenApproval(s.ctx, approval)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionTokenApprovals, fftypes.ChangeEventTypeUpdated, approval.LocalID, nil)
+
+	fb := database.TokenApprovalQueryFacory.NewFilter(s.ctx)
+	approvals, res, err := s.db.GetTokenApprovals(s.ctx, fb.And(
+		fb.Eq("pool", approval.Pool),
+		fb.Eq("key", approval.Key),
+		fb.Eq("operator", approval.Operator),
+		fb.Eq("approved", false),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, approvals, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, approval, approvals[0])
+}
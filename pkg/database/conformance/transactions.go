@@ -0,0 +1,317 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTransactions(t *testing.T, s *suite) {
+	tx := &fftypes.Transaction{
+		ID:            fftypes.NewUUID(),
+		Type:          fftypes.TransactionTypeBatchPin,
+		Namespace:     "ns1",
+		BlockchainIDs: fftypes.FFStringArray{"tx1"},
+	}
+	err := s.db.InsertTransaction(s.ctx, tx)
+	assert.NoError(t, err)
+	assert.NotNil(t, tx.Created)
+	s.assertChangeEvent(t, database.CollectionTransactions, fftypes.ChangeEventTypeCreated, tx.ID, nil)
+
+	txRead, err := s.db.GetTransactionByID(s.ctx, tx.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, tx, txRead)
+
+	txRead, err = s.db.GetTransactionByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, txRead)
+
+	fb := database.TransactionQueryFactory.NewFilter(s.ctx)
+	txns, res, err := s.db.GetTransactions(s.ctx, fb.And(
+		fb.Eq("id", tx.ID.String()),
+		fb.Eq("type", tx.Type),
+		fb.Gt("created", "0"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, txns, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, tx, txns[0])
+
+	// Update the blockchain IDs and fee, which are set as the transaction progresses
+	fee := &fftypes.TransactionFee{GasUsed: fftypes.NewFFBigInt(21000), Total: fftypes.NewFFBigInt(42000)}
+	up := database.TransactionQueryFactory.NewUpdate(s.ctx).
+		Set("blockchainids", fftypes.FFStringArray{"0x12345", "0x23456"}).
+		Set("fee", fee)
+	err = s.db.UpdateTransaction(s.ctx, tx.ID, up)
+	assert.NoError(t, err)
+
+	txns, _, err = s.db.GetTransactions(s.ctx, fb.And(
+		fb.Eq("id", tx.ID.String()),
+		fb.Eq("blockchainids", "0x12345,0x23456"),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, txns, 1)
+	assert.Equal(t, tx.Created.String(), txns[0].Created.String())
+	assert.Equal(t, int64(42000), txns[0].Fee.Total.Int().Int64())
+}
+
+func testFeeSummaries(t *testing.T, s *suite) {
+	summary := &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-01",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(21000),
+		Total:        fftypes.NewFFBigInt(42000),
+	}
+	err := s.db.UpsertFeeSummary(s.ctx, summary)
+	assert.NoError(t, err)
+	assert.NotNil(t, summary.Updated)
+
+	// Upserting the same key and day accumulates the totals
+	err = s.db.UpsertFeeSummary(s.ctx, &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-01",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(50000),
+		Total:        fftypes.NewFFBigInt(100000),
+	})
+	assert.NoError(t, err)
+	err = s.db.UpsertFeeSummary(s.ctx, &fftypes.FeeSummary{
+		Namespace:    "ns1",
+		Key:          "0x12345",
+		Day:          "2022-05-02",
+		Transactions: 1,
+		GasUsed:      fftypes.NewFFBigInt(1),
+		Total:        fftypes.NewFFBigInt(1),
+	})
+	assert.NoError(t, err)
+
+	fb := database.FeeSummaryQueryFactory.NewFilter(s.ctx)
+	summaries, res, err := s.db.GetFeeSummaries(s.ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("key", "0x12345"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	summaries, _, err = s.db.GetFeeSummaries(s.ctx, fb.And(
+		fb.Eq("day", "2022-05-01"),
+		fb.Eq("transactions", 2),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, int64(71000), summaries[0].GasUsed.Int().Int64())
+	assert.Equal(t, int64(142000), summaries[0].Total.Int().Int64())
+}
+
+func testOperations(t *testing.T, s *suite) {
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        fftypes.OpTypeBlockchainPinBatch,
+		Transaction: fftypes.NewUUID(),
+		Status:      fftypes.OpStatusPending,
+		Plugin:      "ethereum",
+		Input:       fftypes.JSONObject{"some": "input-info"},
+		Output:      fftypes.JSONObject{"some": "output-info"},
+		OutputRef:   fftypes.NewUUID(),
+		Fee:         fftypes.NewTransactionFee(big.NewInt(21000), big.NewInt(2)),
+		Created:     fftypes.Now(),
+		Updated:     fftypes.Now(),
+	}
+
+	// Post completion hooks must be called once the operation is committed
+	hookCalled := false
+	err := s.db.InsertOperation(s.ctx, op, func() {
+		hookCalled = true
+	})
+	assert.NoError(t, err)
+	assert.True(t, hookCalled)
+	s.assertChangeEvent(t, database.CollectionOperations, fftypes.ChangeEventTypeCreated, op.ID, nil)
+
+	opRead, err := s.db.GetOperationByID(s.ctx, op.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, op, opRead)
+
+	opRead, err = s.db.GetOperationByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, opRead)
+
+	fb := database.OperationQueryFactory.NewFilter(s.ctx)
+	ops, res, err := s.db.GetOperations(s.ctx, fb.And(
+		fb.Eq("id", op.ID.String()),
+		fb.Eq("tx", op.Transaction),
+		fb.Eq("type", op.Type),
+		fb.Eq("status", op.Status),
+		fb.Eq("plugin", op.Plugin),
+		fb.Eq("outputref", op.OutputRef),
+		fb.Gt("created", 0),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, op, ops[0])
+
+	// Resolve the operation, merging in the output
+	err = s.db.ResolveOperation(s.ctx, op.ID, fftypes.OpStatusFailed, "pop", fftypes.JSONObject{"extra": "info"})
+	assert.NoError(t, err)
+	ops, _, err = s.db.GetOperations(s.ctx, fb.And(
+		fb.Eq("id", op.ID.String()),
+		fb.Eq("status", fftypes.OpStatusFailed),
+		fb.Eq("error", "pop"),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+	assert.Equal(t, "info", ops[0].Output.GetString("extra"))
+
+	// Resolving without an output leaves the existing output in place
+	err = s.db.ResolveOperation(s.ctx, op.ID, fftypes.OpStatusSucceeded, "", nil)
+	assert.NoError(t, err)
+	opRead, err = s.db.GetOperationByID(s.ctx, op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, opRead.Status)
+	assert.Empty(t, opRead.Error)
+	assert.Equal(t, "info", opRead.Output.GetString("extra"))
+
+	retryID := fftypes.NewUUID()
+	err = s.db.UpdateOperation(s.ctx, op.ID, database.OperationQueryFactory.NewUpdate(s.ctx).Set("retry", retryID))
+	assert.NoError(t, err)
+	ops, _, err = s.db.GetOperations(s.ctx, fb.Eq("retry", retryID))
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+}
+
+func testEvents(t *testing.T, s *suite) {
+	event := &fftypes.Event{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Type:       fftypes.EventTypeMessageConfirmed,
+		Reference:  fftypes.NewUUID(),
+		Correlator: fftypes.NewUUID(),
+		Topic:      "topic1",
+		Created:    fftypes.Now(),
+	}
+	err := s.db.InsertEvent(s.ctx, event)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionEvents, fftypes.ChangeEventTypeCreated, event.ID, nil)
+
+	eventRead, err := s.db.GetEventByID(s.ctx, event.ID)
+	assert.NoError(t, err)
+	assert.Greater(t, eventRead.Sequence, int64(0))
+	event.Sequence = eventRead.Sequence
+	assertJSONEqual(t, event, eventRead)
+
+	eventRead, err = s.db.GetEventByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
+
+	// Events are sequenced in the order they are inserted
+	event2 := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic2")
+	err = s.db.InsertEvent(s.ctx, event2)
+	assert.NoError(t, err)
+	fb := database.EventQueryFactory.NewFilter(s.ctx)
+	events, res, err := s.db.GetEvents(s.ctx, fb.Gt("sequence", event.Sequence).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, *event2.ID, *events[0].ID)
+
+	events, _, err = s.db.GetEvents(s.ctx, fb.And(
+		fb.Eq("id", event.ID.String()),
+		fb.Eq("reference", fftypes.NewUUID().String()),
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	newRef := fftypes.NewUUID()
+	err = s.db.UpdateEvent(s.ctx, event.ID, database.EventQueryFactory.NewUpdate(s.ctx).Set("reference", newRef))
+	assert.NoError(t, err)
+	events, _, err = s.db.GetEvents(s.ctx, fb.Eq("reference", newRef))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	err = s.db.DeleteEvents(s.ctx, fb.And(fb.Eq("reference", newRef)))
+	assert.NoError(t, err)
+	events, _, err = s.db.GetEvents(s.ctx, fb.Eq("reference", newRef))
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func testBlockchainEvents(t *testing.T, s *suite) {
+	event := &fftypes.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Listener:   fftypes.NewUUID(),
+		Source:     "ethereum",
+		Name:       "Changed",
+		ProtocolID: "000000000010/000020/000030",
+		Output:     fftypes.JSONObject{"value": "1"},
+		Info:       fftypes.JSONObject{"blockNumber": "10"},
+		Timestamp:  fftypes.Now(),
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeContractInvoke,
+			ID:   fftypes.NewUUID(),
+		},
+	}
+	err := s.db.InsertBlockchainEvent(s.ctx, event)
+	assert.NoError(t, err)
+	s.assertChangeEvent(t, database.CollectionBlockchainEvents, fftypes.ChangeEventTypeCreated, event.ID, nil)
+
+	eventRead, err := s.db.GetBlockchainEventByID(s.ctx, event.ID)
+	assert.NoError(t, err)
+	assertJSONEqual(t, event, eventRead)
+
+	eventRead, err = s.db.GetBlockchainEventByID(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
+
+	fb := database.BlockchainEventQueryFactory.NewFilter(s.ctx)
+	events, res, err := s.db.GetBlockchainEvents(s.ctx, fb.And(
+		fb.Eq("name", "Changed"),
+		fb.Eq("listener", event.Listener),
+		fb.Eq("protocolid", event.ProtocolID),
+		fb.Eq("tx.id", event.TX.ID),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assertJSONEqual(t, event, events[0])
+
+	// The raw information for an event is stored separately
+	raw, err := fftypes.NewBlockchainEventRaw(event, fftypes.JSONObject{"blockNumber": "10", "large": "info"}, true)
+	assert.NoError(t, err)
+	err = s.db.InsertBlockchainEventRaw(s.ctx, raw)
+	assert.NoError(t, err)
+
+	rawRead, err := s.db.GetBlockchainEventRaw(s.ctx, event.ID)
+	assert.NoError(t, err)
+	info, err := rawRead.Info(s.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "info", info.GetString("large"))
+
+	rawRead, err = s.db.GetBlockchainEventRaw(s.ctx, fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, rawRead)
+}
@@ -347,7 +347,7 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		fValues := f.value.([]driver.Value)
 		values = make([]FieldSerialization, len(fValues))
 		name := strings.ToLower(f.field)
-		field, ok := f.fb.queryFields[name]
+		field, ok := f.fb.queryFields.getField(name)
 		if !ok {
 			return nil, i18n.NewError(f.fb.ctx, i18n.MsgInvalidFilterField, name)
 		}
@@ -359,7 +359,7 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		}
 	default:
 		name := strings.ToLower(f.field)
-		field, ok := f.fb.queryFields[name]
+		field, ok := f.fb.queryFields.getField(name)
 		if !ok {
 			return nil, i18n.NewError(f.fb.ctx, i18n.MsgInvalidFilterField, name)
 		}
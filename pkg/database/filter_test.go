@@ -271,6 +271,19 @@ func TestBuildMessageFailTimeConvert(t *testing.T) {
 	assert.Regexp(t, "FF10149.*created", err)
 }
 
+func TestBuildDataWildcardField(t *testing.T) {
+	fb := DataQueryFactory.NewFilter(context.Background())
+	f, err := fb.And(
+		fb.Eq("value.orderId", "order1"),
+		fb.In("value.customer.id", []driver.Value{"cust1", 12345}),
+	).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, `( value.orderId == 'order1' ) && ( value.customer.id IN ['cust1','12345'] )`, f.String())
+
+	_, err = fb.Eq("value.", "order1").Finalize()
+	assert.Regexp(t, "FF10148.*value.", err)
+}
+
 func TestQueryFactoryBadField(t *testing.T) {
 	fb := MessageQueryFactory.NewFilter(context.Background())
 	_, err := fb.And(
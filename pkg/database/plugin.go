@@ -64,6 +64,11 @@ type iNamespaceCollection interface {
 	// DeleteNamespace - Delete namespace
 	DeleteNamespace(ctx context.Context, id *fftypes.UUID) (err error)
 
+	// DeleteNamespaceRecords - Delete the messages, data, batches, transactions, operations, events and approval requests
+	// of a namespace created before a time that are complete, along with the records that belong to them, in bounded
+	// batches. Returns the number of records deleted
+	DeleteNamespaceRecords(ctx context.Context, ns string, before *fftypes.FFTime) (deleted int64, err error)
+
 	// GetNamespace - Get an namespace by name
 	GetNamespace(ctx context.Context, name string) (offset *fftypes.Namespace, err error)
 
@@ -90,6 +95,20 @@ type iMessageCollection interface {
 	// A new event is raised for the message, with the new sequence number - as if it was brand new.
 	ReplaceMessage(ctx context.Context, message *fftypes.Message) (err error)
 
+	// CompletePendingMessage replaces a message stored in the pending_data state with its sealed form, along with
+	// its data refs, assigning it a new sequence number in the same way as ReplaceMessage.
+	// Returns DeleteRecordNotFound if the message is no longer pending.
+	CompletePendingMessage(ctx context.Context, message *fftypes.Message) (err error)
+
+	// ActivateDraftMessage replaces a message stored in the draft or scheduled state with its activated form,
+	// assigning it a new sequence number in the same way as ReplaceMessage, so that it is picked up for batching.
+	// Returns DeleteRecordNotFound if the message is no longer a draft.
+	ActivateDraftMessage(ctx context.Context, message *fftypes.Message) (err error)
+
+	// ScheduleDraftMessage moves a message in the draft or scheduled state to the scheduled state, to be sent at the given time.
+	// Returns DeleteRecordNotFound if the message is no longer a draft.
+	ScheduleDraftMessage(ctx context.Context, msgID *fftypes.UUID, sendAt *fftypes.FFTime) (err error)
+
 	// UpdateMessages - Update messages
 	UpdateMessages(ctx context.Context, filter Filter, update Update) (err error)
 
@@ -106,6 +125,14 @@ type iMessageCollection interface {
 	GetMessagesForData(ctx context.Context, dataID *fftypes.UUID, filter Filter) (message []*fftypes.Message, res *FilterResult, err error)
 }
 
+type iMessageRecipientCollection interface {
+	// UpsertMessageRecipient - Record the delivery status of a private message to a recipient node
+	UpsertMessageRecipient(ctx context.Context, recipient *fftypes.MessageRecipient) (err error)
+
+	// GetMessageRecipients - List the delivery status of private messages to recipient nodes
+	GetMessageRecipients(ctx context.Context, filter Filter) (recipients []*fftypes.MessageRecipient, res *FilterResult, err error)
+}
+
 type iDataCollection interface {
 	// UpsertData - Upsert a data record. A hint can be supplied to whether the data already exists.
 	//              The database layer must ensure that if a record already exists, the hash of that existing record
@@ -156,6 +183,14 @@ type iTransactionCollection interface {
 	GetTransactions(ctx context.Context, filter Filter) (message []*fftypes.Transaction, res *FilterResult, err error)
 }
 
+type iFeeCollection interface {
+	// UpsertFeeSummary - Add the transactions and fees in the summary, to any existing summary for the same namespace, key and day
+	UpsertFeeSummary(ctx context.Context, summary *fftypes.FeeSummary) (err error)
+
+	// GetFeeSummaries - List the fees paid per namespace, key and day
+	GetFeeSummaries(ctx context.Context, filter Filter) (summaries []*fftypes.FeeSummary, res *FilterResult, err error)
+}
+
 type iDatatypeCollection interface {
 	// UpsertDatatype - Upsert a data definition
 	UpsertDatatype(ctx context.Context, datadef *fftypes.Datatype, allowExisting bool) (err error)
@@ -245,6 +280,56 @@ type iSubscriptionCollection interface {
 	DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID) (err error)
 }
 
+type iRoutingRuleCollection interface {
+	// UpsertRoutingRule - Upsert a routing rule, matching an existing rule by name
+	// Throws IDMismatch error if updating and ids don't match
+	UpsertRoutingRule(ctx context.Context, rule *fftypes.RoutingRule, allowExisting bool) (err error)
+
+	// GetRoutingRuleByName - Get a routing rule by name
+	GetRoutingRuleByName(ctx context.Context, ns, name string) (rule *fftypes.RoutingRule, err error)
+
+	// GetRoutingRuleByID - Get a routing rule by id
+	GetRoutingRuleByID(ctx context.Context, id *fftypes.UUID) (rule *fftypes.RoutingRule, err error)
+
+	// GetRoutingRules - Get routing rules
+	GetRoutingRules(ctx context.Context, filter Filter) (rules []*fftypes.RoutingRule, res *FilterResult, err error)
+
+	// DeleteRoutingRuleByID - Delete a routing rule
+	DeleteRoutingRuleByID(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
+type iApprovalRequestCollection interface {
+	// InsertApprovalRequest - Insert a new approval request
+	InsertApprovalRequest(ctx context.Context, req *fftypes.ApprovalRequest) (err error)
+
+	// UpdateApprovalRequest - Update an approval request
+	UpdateApprovalRequest(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+
+	// GetApprovalRequestByID - Get an approval request by id
+	GetApprovalRequestByID(ctx context.Context, id *fftypes.UUID) (req *fftypes.ApprovalRequest, err error)
+
+	// GetApprovalRequests - Get approval requests
+	GetApprovalRequests(ctx context.Context, filter Filter) (reqs []*fftypes.ApprovalRequest, res *FilterResult, err error)
+}
+
+type iGroupAliasCollection interface {
+	// UpsertGroupAlias - Upsert a group alias, matching an existing alias by name
+	// Throws IDMismatch error if updating and ids don't match
+	UpsertGroupAlias(ctx context.Context, alias *fftypes.GroupAlias, allowExisting bool) (err error)
+
+	// GetGroupAliasByName - Get a group alias by name
+	GetGroupAliasByName(ctx context.Context, ns, name string) (alias *fftypes.GroupAlias, err error)
+
+	// GetGroupAliasByID - Get a group alias by id
+	GetGroupAliasByID(ctx context.Context, id *fftypes.UUID) (alias *fftypes.GroupAlias, err error)
+
+	// GetGroupAliases - Get group aliases
+	GetGroupAliases(ctx context.Context, filter Filter) (aliases []*fftypes.GroupAlias, res *FilterResult, err error)
+
+	// DeleteGroupAliasByID - Delete a group alias
+	DeleteGroupAliasByID(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
 type iEventCollection interface {
 	// InsertEvent - Insert an event. The order of the sequences added to the database, must match the order that
 	//               the rows/objects appear available to the event dispatcher. For a concurrency enabled database
@@ -260,6 +345,9 @@ type iEventCollection interface {
 
 	// GetEvents - Get events
 	GetEvents(ctx context.Context, filter Filter) (message []*fftypes.Event, res *FilterResult, err error)
+
+	// DeleteEvents - Delete all events matching the filter
+	DeleteEvents(ctx context.Context, filter Filter) (err error)
 }
 
 type iIdentitiesCollection interface {
@@ -314,8 +402,13 @@ type iGroupCollection interface {
 }
 
 type iNonceCollection interface {
-	// UpsertNonceNext - Upsert a context, assigning zero if not found, or the next nonce if it is
-	UpsertNonceNext(ctx context.Context, context *fftypes.Nonce) (err error)
+	// ReserveNonces - Reserve the next count nonces on a context, creating it at zero if not found.
+	// The first reserved nonce is set on the supplied object, and the reservation is released if the
+	// enclosing transaction rolls back, so reserved nonces never leave gaps
+	ReserveNonces(ctx context.Context, context *fftypes.Nonce, count int64) (err error)
+
+	// UpsertNonce - Set the last nonce reserved on a context, creating it if not found
+	UpsertNonce(ctx context.Context, context *fftypes.Nonce) (err error)
 
 	// GetNonce - Get a context by hash
 	GetNonce(ctx context.Context, hash *fftypes.Bytes32) (message *fftypes.Nonce, err error)
@@ -347,6 +440,78 @@ type iNextPinCollection interface {
 	DeleteNextPin(ctx context.Context, sequence int64) (err error)
 }
 
+type iIdentityPrivateProfileCollection interface {
+	// UpsertIdentityPrivateProfile - Upsert the private profile fields received for an identity
+	UpsertIdentityPrivateProfile(ctx context.Context, profile *fftypes.IdentityPrivateProfile) (err error)
+
+	// GetIdentityPrivateProfile - Get the private profile fields received for an identity
+	GetIdentityPrivateProfile(ctx context.Context, identity *fftypes.UUID) (profile *fftypes.IdentityPrivateProfile, err error)
+}
+
+type iNamespaceSignerCollection interface {
+	// UpsertNamespaceSigner - Upsert the default signer for a namespace
+	UpsertNamespaceSigner(ctx context.Context, signer *fftypes.NamespaceSigner) (err error)
+
+	// GetNamespaceSigner - Get the default signer for a namespace
+	GetNamespaceSigner(ctx context.Context, ns string) (signer *fftypes.NamespaceSigner, err error)
+
+	// DeleteNamespaceSigner - Delete the default signer for a namespace
+	DeleteNamespaceSigner(ctx context.Context, ns string) (err error)
+}
+
+type iSubscriptionRedeliveryCollection interface {
+	// UpsertSubscriptionRedelivery - Upsert the rejected delivery attempts of an event on a subscription
+	UpsertSubscriptionRedelivery(ctx context.Context, redelivery *fftypes.SubscriptionRedelivery) (err error)
+
+	// GetSubscriptionRedelivery - Get the rejected delivery attempts of an event on a subscription
+	GetSubscriptionRedelivery(ctx context.Context, subscription, event *fftypes.UUID) (redelivery *fftypes.SubscriptionRedelivery, err error)
+
+	// GetSubscriptionRedeliveries - Get subscription redeliveries
+	GetSubscriptionRedeliveries(ctx context.Context, filter Filter) (redeliveries []*fftypes.SubscriptionRedelivery, res *FilterResult, err error)
+
+	// DeleteSubscriptionRedelivery - Delete the rejected delivery attempts of an event on a subscription
+	DeleteSubscriptionRedelivery(ctx context.Context, subscription, event *fftypes.UUID) (err error)
+}
+
+type iOutboxCollection interface {
+	// InsertOutboxEntry - insert an outbox entry, in the same database transaction as the operation it submits
+	InsertOutboxEntry(ctx context.Context, entry *fftypes.OutboxEntry, hooks ...PostCompletionHook) (err error)
+
+	// GetOutboxEntryByID - lookup the outbox entry for an operation
+	GetOutboxEntryByID(ctx context.Context, id *fftypes.UUID) (entry *fftypes.OutboxEntry, err error)
+
+	// GetOutboxEntries - get outbox entries
+	GetOutboxEntries(ctx context.Context, filter Filter) (entries []*fftypes.OutboxEntry, res *FilterResult, err error)
+
+	// UpdateOutboxEntry - update the outbox entry for an operation
+	UpdateOutboxEntry(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+
+	// DeleteOutboxEntry - delete the outbox entry for an operation
+	DeleteOutboxEntry(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
+type iOpCallbackCollection interface {
+	// InsertOpCallback - insert an operation callback, in the same database transaction that resolves the operation
+	InsertOpCallback(ctx context.Context, callback *fftypes.OpCallback, hooks ...PostCompletionHook) (err error)
+
+	// GetOpCallbackByID - lookup the callback for an operation
+	GetOpCallbackByID(ctx context.Context, id *fftypes.UUID) (callback *fftypes.OpCallback, err error)
+
+	// GetOpCallbacks - get operation callbacks
+	GetOpCallbacks(ctx context.Context, filter Filter) (callbacks []*fftypes.OpCallback, res *FilterResult, err error)
+
+	// UpdateOpCallback - update the callback for an operation
+	UpdateOpCallback(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+}
+
+type iOperationOutputCollection interface {
+	// UpsertOperationOutput - insert or replace the output of an operation that is too large to store inline
+	UpsertOperationOutput(ctx context.Context, output *fftypes.OperationOutput) (err error)
+
+	// GetOperationOutputByID - get the output of an operation by the ID referenced from the operation
+	GetOperationOutputByID(ctx context.Context, id *fftypes.UUID) (output *fftypes.OperationOutput, err error)
+}
+
 type iBlobCollection interface {
 	// InsertBlob - insert a blob
 	InsertBlob(ctx context.Context, blob *fftypes.Blob) (err error)
@@ -458,6 +623,11 @@ type iFFIEventCollection interface {
 	GetFFIEvents(ctx context.Context, filter Filter) (events []*fftypes.FFIEvent, res *FilterResult, err error)
 }
 
+type iFFIErrorCollection interface {
+	UpsertFFIError(ctx context.Context, errorDef *fftypes.FFIError) error
+	GetFFIErrors(ctx context.Context, filter Filter) (errors []*fftypes.FFIError, res *FilterResult, err error)
+}
+
 type iContractAPICollection interface {
 	UpsertContractAPI(ctx context.Context, cd *fftypes.ContractAPI) error
 	GetContractAPIs(ctx context.Context, ns string, filter AndFilter) ([]*fftypes.ContractAPI, *FilterResult, error)
@@ -494,12 +664,31 @@ type iBlockchainEventCollection interface {
 
 	// GetBlockchainEvents - get smart contract events
 	GetBlockchainEvents(ctx context.Context, filter Filter) ([]*fftypes.BlockchainEvent, *FilterResult, error)
+
+	// InsertBlockchainEventRaw - insert the full raw information for a blockchain event
+	InsertBlockchainEventRaw(ctx context.Context, raw *fftypes.BlockchainEventRaw) (err error)
+
+	// GetBlockchainEventRaw - get the full raw information for a blockchain event, if it was retained
+	GetBlockchainEventRaw(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEventRaw, error)
 }
 
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
 type iChartCollection interface {
-	// GetChartHistogram - Get charting data for a histogram
-	GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection CollectionName) ([]*fftypes.ChartHistogram, error)
+	// GetChartHistogram - Get charting data for a histogram, counted by the groupBy field (or the type, if empty)
+	GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection CollectionName, groupBy string) ([]*fftypes.ChartHistogram, error)
+
+	// GetChartLatencyHistogram - Get percentiles of the time taken to confirm the messages created in each interval
+	GetChartLatencyHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, percentiles []float64) ([]*fftypes.ChartHistogram, error)
+}
+
+type iSyncChangeCollection interface {
+	// GetSyncChanges - Get entries from the change log of operations, transactions and events
+	GetSyncChanges(ctx context.Context, filter Filter) ([]*fftypes.SyncChangeRef, *FilterResult, error)
+}
+
+type iSearchCollection interface {
+	// Search - Full-text search across message tags/topics, data values and blockchain events, ordered by rank
+	Search(ctx context.Context, query string, filter Filter) ([]*fftypes.SearchResult, *FilterResult, error)
 }
 
 // PeristenceInterface are the operations that must be implemented by a database interfavce plugin.
@@ -539,20 +728,31 @@ type PersistenceInterface interface {
 
 	iNamespaceCollection
 	iMessageCollection
+	iMessageRecipientCollection
 	iDataCollection
 	iBatchCollection
 	iTransactionCollection
+	iFeeCollection
 	iDatatypeCollection
 	iOffsetCollection
 	iPinCollection
 	iOperationCollection
 	iSubscriptionCollection
+	iSubscriptionRedeliveryCollection
+	iRoutingRuleCollection
+	iGroupAliasCollection
+	iApprovalRequestCollection
 	iEventCollection
 	iIdentitiesCollection
 	iVerifiersCollection
 	iGroupCollection
 	iNonceCollection
 	iNextPinCollection
+	iOutboxCollection
+	iOpCallbackCollection
+	iOperationOutputCollection
+	iIdentityPrivateProfileCollection
+	iNamespaceSignerCollection
 	iBlobCollection
 	iConfigRecordCollection
 	iTokenPoolCollection
@@ -562,10 +762,13 @@ type PersistenceInterface interface {
 	iFFICollection
 	iFFIMethodCollection
 	iFFIEventCollection
+	iFFIErrorCollection
 	iContractAPICollection
 	iContractListenerCollection
 	iBlockchainEventCollection
 	iChartCollection
+	iSearchCollection
+	iSyncChangeCollection
 }
 
 // CollectionName represents all collections
@@ -601,14 +804,20 @@ const (
 	CollectionDataTypes         UUIDCollectionNS = "datatypes"
 	CollectionOperations        UUIDCollectionNS = "operations"
 	CollectionSubscriptions     UUIDCollectionNS = "subscriptions"
+	CollectionRoutingRules      UUIDCollectionNS = "routingrules"
+	CollectionGroupAliases      UUIDCollectionNS = "groupaliases"
+	CollectionApprovalRequests  UUIDCollectionNS = "approvalrequests"
 	CollectionTransactions      UUIDCollectionNS = "transactions"
 	CollectionTokenPools        UUIDCollectionNS = "tokenpools"
 	CollectionFFIs              UUIDCollectionNS = "ffi"
 	CollectionFFIMethods        UUIDCollectionNS = "ffimethods"
 	CollectionFFIEvents         UUIDCollectionNS = "ffievents"
+	CollectionFFIErrors         UUIDCollectionNS = "ffierrors"
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractsubscriptions"
 	CollectionIdentities        UUIDCollectionNS = "identities"
+	CollectionTokenTransfers    UUIDCollectionNS = "tokentransfers"
+	CollectionTokenApprovals    UUIDCollectionNS = "tokenapprovals"
 )
 
 // HashCollectionNS is a collection where the primary key is a hash, such that it can
@@ -625,9 +834,7 @@ const (
 type UUIDCollection CollectionName
 
 const (
-	CollectionNamespaces     UUIDCollection = "namespaces"
-	CollectionTokenTransfers UUIDCollection = "tokentransfers"
-	CollectionTokenApprovals UUIDCollection = "tokenapprovals"
+	CollectionNamespaces UUIDCollection = "namespaces"
 )
 
 // OtherCollection are odd balls, that don't fit any of the categories above.
@@ -637,12 +844,17 @@ const (
 type OtherCollection CollectionName
 
 const (
-	CollectionConfigrecords OtherCollection = "configrecords"
-	CollectionBlobs         OtherCollection = "blobs"
-	CollectionNextpins      OtherCollection = "nextpins"
-	CollectionNonces        OtherCollection = "nonces"
-	CollectionOffsets       OtherCollection = "offsets"
-	CollectionTokenBalances OtherCollection = "tokenbalances"
+	CollectionConfigrecords     OtherCollection = "configrecords"
+	CollectionBlobs             OtherCollection = "blobs"
+	CollectionFees              OtherCollection = "fees"
+	CollectionIdentityProfiles  OtherCollection = "identityprofiles"
+	CollectionMessageRecipients OtherCollection = "messagerecipients"
+	CollectionNextpins          OtherCollection = "nextpins"
+	CollectionNonces            OtherCollection = "nonces"
+	CollectionOffsets           OtherCollection = "offsets"
+	CollectionOpCallbacks       OtherCollection = "opcallbacks"
+	CollectionOutbox            OtherCollection = "outbox"
+	CollectionTokenBalances     OtherCollection = "tokenbalances"
 )
 
 // PostCompletionHook is a closure/function that will be called after a successful insertion.
@@ -679,7 +891,8 @@ type Callbacks interface {
 
 // Capabilities defines the capabilities a plugin can report as implementing or not
 type Capabilities struct {
-	Concurrency bool
+	Concurrency    bool
+	FullTextSearch bool
 }
 
 // NamespaceQueryFactory filter fields for namespaces
@@ -695,23 +908,45 @@ var NamespaceQueryFactory = &queryFields{
 
 // MessageQueryFactory filter fields for messages
 var MessageQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"cid":       &UUIDField{},
+	"id":            &UUIDField{},
+	"cid":           &UUIDField{},
+	"namespace":     &StringField{},
+	"type":          &StringField{},
+	"author":        &StringField{},
+	"key":           &StringField{},
+	"topics":        &FFStringArrayField{},
+	"tag":           &StringField{},
+	"group":         &Bytes32Field{},
+	"created":       &TimeField{},
+	"hash":          &Bytes32Field{},
+	"pins":          &FFStringArrayField{},
+	"state":         &StringField{},
+	"confirmed":     &TimeField{},
+	"sequence":      &Int64Field{},
+	"txtype":        &StringField{},
+	"batch":         &UUIDField{},
+	"correlationid": &StringField{},
+	"businesskey":   &StringField{},
+	"sendat":        &TimeField{},
+}
+
+// MessageRecipientQueryFactory filter fields for message recipients
+var MessageRecipientQueryFactory = &queryFields{
 	"namespace": &StringField{},
-	"type":      &StringField{},
-	"author":    &StringField{},
-	"key":       &StringField{},
-	"topics":    &FFStringArrayField{},
-	"tag":       &StringField{},
-	"group":     &Bytes32Field{},
+	"message":   &UUIDField{},
+	"node":      &UUIDField{},
+	"status":    &StringField{},
 	"created":   &TimeField{},
-	"hash":      &Bytes32Field{},
-	"pins":      &FFStringArrayField{},
-	"state":     &StringField{},
-	"confirmed": &TimeField{},
-	"sequence":  &Int64Field{},
-	"txtype":    &StringField{},
-	"batch":     &UUIDField{},
+	"updated":   &TimeField{},
+}
+
+// FeeSummaryQueryFactory filter fields for fee summaries
+var FeeSummaryQueryFactory = &queryFields{
+	"namespace":    &StringField{},
+	"key":          &StringField{},
+	"day":          &StringField{},
+	"transactions": &Int64Field{},
+	"updated":      &TimeField{},
 }
 
 // BatchQueryFactory filter fields for batches
@@ -736,37 +971,62 @@ var TransactionQueryFactory = &queryFields{
 	"id":            &UUIDField{},
 	"type":          &StringField{},
 	"created":       &TimeField{},
+	"updated":       &TimeField{},
 	"namespace":     &StringField{},
 	"blockchainids": &FFStringArrayField{},
+	"fee":           &JSONField{},
+	"correlationid": &StringField{},
+	"chainid":       &Int64Field{},
 }
 
 // DataQueryFactory filter fields for data
 var DataQueryFactory = &queryFields{
-	"id":               &UUIDField{},
-	"namespace":        &StringField{},
-	"validator":        &StringField{},
-	"datatype.name":    &StringField{},
-	"datatype.version": &StringField{},
-	"hash":             &Bytes32Field{},
-	"blob.hash":        &Bytes32Field{},
-	"blob.public":      &StringField{},
-	"blob.name":        &StringField{},
-	"blob.size":        &Int64Field{},
-	"created":          &TimeField{},
-	"value":            &JSONField{},
+	"id":                 &UUIDField{},
+	"namespace":          &StringField{},
+	"validator":          &StringField{},
+	"datatype.name":      &StringField{},
+	"datatype.version":   &StringField{},
+	"hash":               &Bytes32Field{},
+	"blob.hash":          &Bytes32Field{},
+	"blob.public":        &StringField{},
+	"blob.name":          &StringField{},
+	"blob.size":          &Int64Field{},
+	"blob.hashalgorithm": &StringField{},
+	"mediatype":          &StringField{},
+	"created":            &TimeField{},
+	"value":              &JSONField{},
+	"value.*":            &StringField{},
 }
 
-// DatatypeQueryFactory filter fields for data definitions
-var DatatypeQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"message":   &UUIDField{},
+// SearchQueryFactory filter fields for full-text search results
+var SearchQueryFactory = &queryFields{
 	"namespace": &StringField{},
-	"validator": &StringField{},
-	"name":      &StringField{},
-	"version":   &StringField{},
+	"type":      &StringField{},
+	"id":        &UUIDField{},
 	"created":   &TimeField{},
 }
 
+// SyncChangeQueryFactory filter fields for the change log
+var SyncChangeQueryFactory = &queryFields{
+	"sequence":  &Int64Field{},
+	"namespace": &StringField{},
+	"type":      &StringField{},
+	"reference": &UUIDField{},
+	"changed":   &TimeField{},
+}
+
+// DatatypeQueryFactory filter fields for data definitions
+var DatatypeQueryFactory = &queryFields{
+	"id":            &UUIDField{},
+	"message":       &UUIDField{},
+	"namespace":     &StringField{},
+	"validator":     &StringField{},
+	"name":          &StringField{},
+	"version":       &StringField{},
+	"created":       &TimeField{},
+	"annotations.*": &StringField{},
+}
+
 // OffsetQueryFactory filter fields for data offsets
 var OffsetQueryFactory = &queryFields{
 	"name":    &StringField{},
@@ -776,43 +1036,95 @@ var OffsetQueryFactory = &queryFields{
 
 // OperationQueryFactory filter fields for data operations
 var OperationQueryFactory = &queryFields{
+	"id":            &UUIDField{},
+	"tx":            &UUIDField{},
+	"type":          &StringField{},
+	"namespace":     &StringField{},
+	"status":        &StringField{},
+	"error":         &StringField{},
+	"plugin":        &StringField{},
+	"input":         &JSONField{},
+	"output":        &JSONField{},
+	"created":       &TimeField{},
+	"updated":       &TimeField{},
+	"retry":         &UUIDField{},
+	"outputref":     &UUIDField{},
+	"fee":           &JSONField{},
+	"correlationid": &StringField{},
+	"businesskey":   &StringField{},
+}
+
+// SubscriptionQueryFactory filter fields for data subscriptions
+var SubscriptionQueryFactory = &queryFields{
+	"id":            &UUIDField{},
+	"namespace":     &StringField{},
+	"name":          &StringField{},
+	"transport":     &StringField{},
+	"events":        &StringField{},
+	"filters":       &JSONField{},
+	"options":       &StringField{},
+	"created":       &TimeField{},
+	"annotations.*": &StringField{},
+}
+
+// SubscriptionRedeliveryQueryFactory filter fields for subscription redeliveries
+var SubscriptionRedeliveryQueryFactory = &queryFields{
+	"subscription": &UUIDField{},
+	"namespace":    &StringField{},
+	"event":        &UUIDField{},
+	"attempts":     &Int64Field{},
+	"parked":       &BoolField{},
+	"updated":      &TimeField{},
+}
+
+// RoutingRuleQueryFactory filter fields for routing rules
+var RoutingRuleQueryFactory = &queryFields{
 	"id":        &UUIDField{},
-	"tx":        &UUIDField{},
-	"type":      &StringField{},
 	"namespace": &StringField{},
-	"status":    &StringField{},
+	"name":      &StringField{},
+	"tag":       &StringField{},
+	"topic":     &StringField{},
+	"labels":    &FFStringArrayField{},
+	"created":   &TimeField{},
+	"updated":   &TimeField{},
+}
+
+// ApprovalRequestQueryFactory filter fields for approval requests
+var ApprovalRequestQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"type":      &StringField{},
+	"state":     &StringField{},
+	"reference": &UUIDField{},
+	"required":  &Int64Field{},
+	"decisions": &JSONField{},
 	"error":     &StringField{},
-	"plugin":    &StringField{},
-	"input":     &JSONField{},
-	"output":    &JSONField{},
 	"created":   &TimeField{},
 	"updated":   &TimeField{},
-	"retry":     &UUIDField{},
 }
 
-// SubscriptionQueryFactory filter fields for data subscriptions
-var SubscriptionQueryFactory = &queryFields{
+// GroupAliasQueryFactory filter fields for group aliases
+var GroupAliasQueryFactory = &queryFields{
 	"id":        &UUIDField{},
 	"namespace": &StringField{},
 	"name":      &StringField{},
-	"transport": &StringField{},
-	"events":    &StringField{},
-	"filters":   &JSONField{},
-	"options":   &StringField{},
+	"group":     &Bytes32Field{},
 	"created":   &TimeField{},
+	"updated":   &TimeField{},
 }
 
 // EventQueryFactory filter fields for data events
 var EventQueryFactory = &queryFields{
-	"id":         &UUIDField{},
-	"type":       &StringField{},
-	"namespace":  &StringField{},
-	"reference":  &UUIDField{},
-	"correlator": &UUIDField{},
-	"tx":         &UUIDField{},
-	"topic":      &StringField{},
-	"sequence":   &Int64Field{},
-	"created":    &TimeField{},
+	"id":            &UUIDField{},
+	"type":          &StringField{},
+	"namespace":     &StringField{},
+	"reference":     &UUIDField{},
+	"correlator":    &UUIDField{},
+	"tx":            &UUIDField{},
+	"topic":         &StringField{},
+	"sequence":      &Int64Field{},
+	"created":       &TimeField{},
+	"correlationid": &StringField{},
 }
 
 // PinQueryFactory filter fields for parked contexts
@@ -823,6 +1135,7 @@ var PinQueryFactory = &queryFields{
 	"batch":      &UUIDField{},
 	"index":      &Int64Field{},
 	"dispatched": &BoolField{},
+	"parkreason": &StringField{},
 	"created":    &TimeField{},
 }
 
@@ -850,6 +1163,7 @@ var VerifierQueryFactory = &queryFields{
 	"type":      &StringField{},
 	"namespace": &StringField{},
 	"value":     &StringField{},
+	"pinning":   &BoolField{},
 	"created":   &TimeField{},
 }
 
@@ -879,6 +1193,27 @@ var NextPinQueryFactory = &queryFields{
 	"nonce":    &Int64Field{},
 }
 
+// OutboxQueryFactory filter fields for outbox entries
+var OutboxQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"attempts":  &Int64Field{},
+	"next":      &TimeField{},
+	"error":     &StringField{},
+	"created":   &TimeField{},
+}
+
+// OpCallbackQueryFactory filter fields for operation callbacks
+var OpCallbackQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"status":    &StringField{},
+	"attempts":  &Int64Field{},
+	"next":      &TimeField{},
+	"error":     &StringField{},
+	"created":   &TimeField{},
+}
+
 // ConfigRecordQueryFactory filter fields for config records
 var ConfigRecordQueryFactory = &queryFields{
 	"key":   &StringField{},
@@ -895,19 +1230,20 @@ var BlobQueryFactory = &queryFields{
 
 // TokenPoolQueryFactory filter fields for token pools
 var TokenPoolQueryFactory = &queryFields{
-	"id":         &UUIDField{},
-	"type":       &StringField{},
-	"namespace":  &StringField{},
-	"name":       &StringField{},
-	"standard":   &StringField{},
-	"protocolid": &StringField{},
-	"symbol":     &StringField{},
-	"message":    &UUIDField{},
-	"state":      &StringField{},
-	"created":    &TimeField{},
-	"connector":  &StringField{},
-	"tx.type":    &StringField{},
-	"tx.id":      &UUIDField{},
+	"id":            &UUIDField{},
+	"type":          &StringField{},
+	"namespace":     &StringField{},
+	"name":          &StringField{},
+	"standard":      &StringField{},
+	"protocolid":    &StringField{},
+	"symbol":        &StringField{},
+	"message":       &UUIDField{},
+	"state":         &StringField{},
+	"created":       &TimeField{},
+	"connector":     &StringField{},
+	"tx.type":       &StringField{},
+	"tx.id":         &UUIDField{},
+	"annotations.*": &StringField{},
 }
 
 // TokenBalanceQueryFactory filter fields for token balances
@@ -956,6 +1292,7 @@ var TokenTransferQueryFactory = &queryFields{
 	"tx.id":           &UUIDField{},
 	"blockchainevent": &UUIDField{},
 	"type":            &StringField{},
+	"businesskey":     &StringField{},
 }
 
 var TokenApprovalQueryFacory = &queryFields{
@@ -975,10 +1312,11 @@ var TokenApprovalQueryFacory = &queryFields{
 
 // FFIQueryFactory filter fields for contract definitions
 var FFIQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"namespace": &StringField{},
-	"name":      &StringField{},
-	"version":   &StringField{},
+	"id":            &UUIDField{},
+	"namespace":     &StringField{},
+	"name":          &StringField{},
+	"version":       &StringField{},
+	"annotations.*": &StringField{},
 }
 
 // FFIMethodQueryFactory filter fields for contract methods
@@ -1001,12 +1339,23 @@ var FFIEventQueryFactory = &queryFields{
 	"description": &StringField{},
 }
 
+// FFIErrorQueryFactory filter fields for contract errors
+var FFIErrorQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"namespace":   &StringField{},
+	"name":        &StringField{},
+	"pathname":    &StringField{},
+	"interface":   &UUIDField{},
+	"description": &StringField{},
+}
+
 // ContractListenerQueryFactory filter fields for contract listeners
 var ContractListenerQueryFactory = &queryFields{
 	"id":         &UUIDField{},
 	"interface":  &UUIDField{},
 	"namespace":  &StringField{},
 	"protocolid": &StringField{},
+	"state":      &StringField{},
 	"created":    &TimeField{},
 }
 
@@ -1025,8 +1374,9 @@ var BlockchainEventQueryFactory = &queryFields{
 
 // ContractAPIQueryFactory filter fields for Contract APIs
 var ContractAPIQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"name":      &StringField{},
-	"namespace": &StringField{},
-	"interface": &UUIDField{},
+	"id":            &UUIDField{},
+	"name":          &StringField{},
+	"namespace":     &StringField{},
+	"interface":     &UUIDField{},
+	"annotations.*": &StringField{},
 }
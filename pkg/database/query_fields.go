@@ -40,6 +40,25 @@ type QueryFactory interface {
 
 type queryFields map[string]Field
 
+// WildcardFieldSuffix can be used on the end of a field name, to allow any sub-field
+// under that prefix to be used in a filter (such as "value.*" for indexed JSON paths)
+const WildcardFieldSuffix = ".*"
+
+func (qf queryFields) getField(name string) (Field, bool) {
+	if field, ok := qf[name]; ok {
+		return field, true
+	}
+	for k, field := range qf {
+		if strings.HasSuffix(k, WildcardFieldSuffix) {
+			prefix := strings.TrimSuffix(k, "*")
+			if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+				return field, true
+			}
+		}
+	}
+	return nil, false
+}
+
 func (qf *queryFields) NewFilterLimit(ctx context.Context, defLimit uint64) FilterBuilder {
 	return &filterBuilder{
 		ctx:         ctx,
@@ -286,6 +305,11 @@ func (f *jsonField) Scan(src interface{}) (err error) {
 		f.b, err = json.Marshal(tv)
 	case nil:
 		f.b = nil
+	case driver.Valuer:
+		var v driver.Value
+		if v, err = tv.Value(); err == nil {
+			return f.Scan(v)
+		}
 	default:
 		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, f.b)
 	}
@@ -17,6 +17,7 @@
 package database
 
 import (
+	"math/big"
 	"testing"
 	"time"
 
@@ -173,6 +174,15 @@ func TestJSONField(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, v)
 
+	err = f.Scan(fftypes.NewTransactionFee(big.NewInt(10), big.NewInt(2)))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"gasUsed":"10","gasPrice":"2","total":"20"}`, f.String())
+
+	var tooBig fftypes.FFBigInt
+	tooBig.Int().Exp(big.NewInt(2), big.NewInt(512), nil)
+	err = f.Scan(tooBig)
+	assert.Regexp(t, "FF10282", err)
+
 }
 
 func TestBoolField(t *testing.T) {
@@ -0,0 +1,33 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "context"
+
+type readReplicaKey struct{}
+
+// WithReadReplica marks reads made with the returned context as able to tolerate stale data, so a plugin
+// configured with a read replica can serve them from it. Reads inside a transaction always use the primary
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaKey{}, true)
+}
+
+// ReadReplicaAllowed returns true if the context was marked by WithReadReplica
+func ReadReplicaAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(readReplicaKey{}).(bool)
+	return allowed
+}
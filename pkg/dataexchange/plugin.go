@@ -68,6 +68,9 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// Health returns an error describing the problem, if the plugin is not currently connected to its connector
+	Health(ctx context.Context) error
+
 	// GetEndpointInfo returns the information about the local endpoint
 	GetEndpointInfo(ctx context.Context) (peer fftypes.JSONObject, err error)
 
@@ -0,0 +1,85 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// CustomHandler can be registered against an application specific tag, to process definition broadcasts
+// with that tag in-line in the aggregator. This allows extensions to build application-level consensus
+// objects that are confirmed in the same order on every node. The returned HandlerResult has exactly the
+// same semantics as for the built-in system definitions (confirm, reject, retry or wait).
+//
+// The data of the message is a single fftypes.CustomDefinition, as broadcast to the network.
+type CustomHandler interface {
+	HandleDefinition(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error)
+}
+
+type HandlerResult struct {
+	Action           DefinitionMessageAction
+	CustomCorrelator *fftypes.UUID
+}
+
+// DefinitionMessageAction is the action to be taken on an individual definition message
+type DefinitionMessageAction int
+
+const (
+	// ActionReject the message was successfully processed, but was malformed/invalid and should be marked as rejected
+	ActionReject DefinitionMessageAction = iota
+
+	// ActionConfirm the message was valid and should be confirmed
+	ActionConfirm
+
+	// ActionRetry a recoverable error was encountered - batch should be halted and then re-processed from the start
+	ActionRetry
+
+	// ActionWait the message is still awaiting further pieces for aggregation and should be held in pending state
+	ActionWait
+)
+
+func (dma DefinitionMessageAction) String() string {
+	switch dma {
+	case ActionReject:
+		return "reject"
+	case ActionConfirm:
+		return "confirm"
+	case ActionRetry:
+		return "retry"
+	case ActionWait:
+		return "wait"
+	default:
+		return "unknown"
+	}
+}
+
+// DefinitionBatchState tracks the state between definition handlers that run in-line on the pin processing route in the
+// aggregator as part of a batch of pins. They might have complex API calls, and interdependencies, that need to be managed via this state.
+// The actions to be taken at the end of a definition batch.
+// See further notes on "batchState" in the event aggregator
+type DefinitionBatchState interface {
+	// PreFinalize may perform a blocking action (possibly to an external connector) that should execute outside database RunAsGroup
+	AddPreFinalize(func(ctx context.Context) error)
+
+	// Finalize may perform final, non-idempotent database operations (such as inserting Events)
+	AddFinalize(func(ctx context.Context) error)
+
+	// GetPendingConfirm returns a map of messages are that pending confirmation after already being processed in this batch
+	GetPendingConfirm() map[fftypes.UUID]*fftypes.Message
+}
@@ -0,0 +1,262 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance is a test kit for implementations of the event transport plugin interface.
+//
+// Authors of a transport - such as one delivering events over Kafka, NATS or server-sent events - can
+// run the suite from a Go test, to check the transport behaves in the same way as the websockets
+// reference implementation. The suite covers option validation, the lifecycle of connections,
+// ordered delivery of events and the semantics of acknowledging and rejecting them:
+//
+//	func TestConformance(t *testing.T) {
+//	    conformance.Run(t, func(t *testing.T, callbacks events.Callbacks) (events.Plugin, conformance.Client, func()) {
+//	        p := newTestPlugin(t)
+//	        err := p.Init(context.Background(), testConfigPrefix, callbacks)
+//	        assert.NoError(t, err)
+//	        return p, &myTestClient{plugin: p}, p.Close
+//	    })
+//	}
+//
+// Each test is run against a new plugin instance from the factory.
+package conformance
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// Timeout is how long the suite waits for the transport to deliver an event, or to fire a callback
+var Timeout = 5 * time.Second
+
+// Factory returns a new initialized instance of the transport under test, that fires the supplied callbacks,
+// along with a client that drives the application side of the transport. The cleanup function is called
+// when the test completes.
+type Factory func(t *testing.T, callbacks events.Callbacks) (plugin events.Plugin, client Client, cleanup func())
+
+// Client drives the application side of the transport under test - such as a WebSocket client,
+// or a consumer of a topic on a message broker
+type Client interface {
+	// ValidOptions returns subscription options that the transport must accept
+	ValidOptions() []*fftypes.SubscriptionOptions
+
+	// InvalidOptions returns subscription options that the transport must reject
+	InvalidOptions() []*fftypes.SubscriptionOptions
+
+	// Connect opens a new application connection, that starts delivery of the durable subscription.
+	// The transport must fire RegisterConnection for the connection, but Connect does not need to wait for it
+	Connect(t *testing.T, sub *fftypes.SubscriptionRef) Connection
+}
+
+// Connection is a single application connection to the transport
+type Connection interface {
+	// Receive returns the next event delivered to the connection, or nil if nothing is delivered before the timeout
+	Receive(t *testing.T, timeout time.Duration) *fftypes.EventDelivery
+
+	// Ack acknowledges an event delivered to the connection
+	Ack(t *testing.T, event *fftypes.EventDelivery)
+
+	// Nack rejects an event delivered to the connection, requesting that it is redelivered
+	Nack(t *testing.T, event *fftypes.EventDelivery)
+
+	// Close closes the connection
+	Close(t *testing.T)
+}
+
+// DeliveryResponse is a response to a delivered event, as recorded by Callbacks
+type DeliveryResponse struct {
+	ConnID   string
+	Response *fftypes.EventDeliveryResponse
+}
+
+// Callbacks records the callbacks fired by the transport under test
+type Callbacks struct {
+	mux        sync.Mutex
+	matchers   map[string]events.SubscriptionMatcher
+	registered chan string
+	closed     chan string
+	responses  chan *DeliveryResponse
+}
+
+func newCallbacks() *Callbacks {
+	return &Callbacks{
+		matchers:   make(map[string]events.SubscriptionMatcher),
+		registered: make(chan string, 100),
+		closed:     make(chan string, 100),
+		responses:  make(chan *DeliveryResponse, 100),
+	}
+}
+
+func (cb *Callbacks) RegisterConnection(connID string, matcher events.SubscriptionMatcher) error {
+	cb.mux.Lock()
+	cb.matchers[connID] = matcher
+	cb.mux.Unlock()
+	cb.registered <- connID
+	return nil
+}
+
+func (cb *Callbacks) EphemeralSubscription(connID, namespace string, filter *fftypes.SubscriptionFilter, options *fftypes.SubscriptionOptions) error {
+	return nil
+}
+
+func (cb *Callbacks) ConnnectionClosed(connID string) {
+	cb.closed <- connID
+}
+
+func (cb *Callbacks) DeliveryResponse(connID string, inflight *fftypes.EventDeliveryResponse) {
+	cb.responses <- &DeliveryResponse{ConnID: connID, Response: inflight}
+}
+
+// Matcher returns the subscription matcher most recently registered for a connection
+func (cb *Callbacks) Matcher(connID string) events.SubscriptionMatcher {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	return cb.matchers[connID]
+}
+
+// suite is the state passed to each test
+type suite struct {
+	ctx       context.Context
+	plugin    events.Plugin
+	client    Client
+	callbacks *Callbacks
+}
+
+type conformanceTest struct {
+	name string
+	run  func(t *testing.T, s *suite)
+}
+
+var tests = []conformanceTest{
+	{"Basics", testBasics},
+	{"OptionValidation", testOptionValidation},
+	{"ConnectionLifecycle", testConnectionLifecycle},
+	{"UnknownConnection", testUnknownConnection},
+	{"DeliveryOrdering", testDeliveryOrdering},
+	{"AckSemantics", testAckSemantics},
+	{"MultipleConnections", testMultipleConnections},
+}
+
+// Run executes every test in the conformance suite, each against a new instance of the transport from the factory
+func Run(t *testing.T, factory Factory) {
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			callbacks := newCallbacks()
+			plugin, client, cleanup := factory(t, callbacks)
+			defer cleanup()
+			test.run(t, &suite{
+				ctx:       context.Background(),
+				plugin:    plugin,
+				client:    client,
+				callbacks: callbacks,
+			})
+		})
+	}
+}
+
+// connect opens a connection for a subscription, and waits for the transport to register it
+func (s *suite) connect(t *testing.T, sub *fftypes.Subscription) (Connection, string) {
+	conn := s.client.Connect(t, &sub.SubscriptionRef)
+	return conn, s.waitRegistered(t)
+}
+
+func (s *suite) waitRegistered(t assert.TestingT) string {
+	select {
+	case connID := <-s.callbacks.registered:
+		return connID
+	case <-time.After(Timeout):
+		assert.FailNow(t, "connection not registered")
+		return ""
+	}
+}
+
+func (s *suite) waitClosed(t assert.TestingT, connID string) {
+	timeout := time.After(Timeout)
+	for {
+		select {
+		case closedID := <-s.callbacks.closed:
+			if closedID == connID {
+				return
+			}
+		case <-timeout:
+			assert.FailNow(t, "connection close not notified", "connID=%s", connID)
+			return
+		}
+	}
+}
+
+func (s *suite) waitResponse(t assert.TestingT) *DeliveryResponse {
+	select {
+	case res := <-s.callbacks.responses:
+		return res
+	case <-time.After(Timeout):
+		assert.FailNow(t, "no delivery response")
+		return nil
+	}
+}
+
+func (s *suite) assertNoResponse(t assert.TestingT) {
+	select {
+	case res := <-s.callbacks.responses:
+		assert.Fail(t, "unexpected delivery response", "id=%s rejected=%t", res.Response.ID, res.Response.Rejected)
+	case <-time.After(Timeout / 10):
+	}
+}
+
+func (s *suite) receive(t *testing.T, conn Connection) *fftypes.EventDelivery {
+	return s.checkReceived(t, conn.Receive(t, Timeout))
+}
+
+func (s *suite) checkReceived(t assert.TestingT, event *fftypes.EventDelivery) *fftypes.EventDelivery {
+	if event == nil {
+		assert.FailNow(t, "no event delivered")
+	}
+	return event
+}
+
+func newTestSubscription(name string) *fftypes.Subscription {
+	return &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      name,
+		},
+		Created: fftypes.Now(),
+	}
+}
+
+func newTestDelivery(sub *fftypes.Subscription, sequence int64) *fftypes.EventDelivery {
+	return &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Sequence:  sequence,
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: sub.Namespace,
+				Reference: fftypes.NewUUID(),
+				Topic:     "topic1",
+				Created:   fftypes.Now(),
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/events/websockets"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type testWSClient struct {
+	ctx context.Context
+	url string
+}
+
+type testWSConnection struct {
+	ctx context.Context
+	wsc wsclient.WSClient
+}
+
+func (c *testWSClient) ValidOptions() []*fftypes.SubscriptionOptions {
+	return []*fftypes.SubscriptionOptions{{}}
+}
+
+func (c *testWSClient) InvalidOptions() []*fftypes.SubscriptionOptions {
+	withData := true
+	return []*fftypes.SubscriptionOptions{
+		{SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{WithData: &withData}},
+	}
+}
+
+func (c *testWSClient) Connect(t *testing.T, sub *fftypes.SubscriptionRef) Connection {
+	clientPrefix := config.NewPluginConfig("unittest.conformance.wsclient")
+	wsconfig.InitPrefix(clientPrefix)
+	clientPrefix.Set(restclient.HTTPConfigURL, c.url)
+	wsc, err := wsclient.New(c.ctx, wsconfig.GenerateConfigFromPrefix(clientPrefix), nil, nil)
+	assert.NoError(t, err)
+	err = wsc.Connect()
+	assert.NoError(t, err)
+	conn := &testWSConnection{ctx: c.ctx, wsc: wsc}
+	conn.send(t, &fftypes.WSClientActionStartPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{Type: fftypes.WSClientActionStart},
+		Namespace:          sub.Namespace,
+		Name:               sub.Name,
+	})
+	return conn
+}
+
+func (c *testWSConnection) send(t *testing.T, msg interface{}) {
+	b, _ := json.Marshal(msg)
+	err := c.wsc.Send(c.ctx, b)
+	assert.NoError(t, err)
+}
+
+func (c *testWSConnection) Receive(t *testing.T, timeout time.Duration) *fftypes.EventDelivery {
+	select {
+	case b := <-c.wsc.Receive():
+		var event fftypes.EventDelivery
+		err := json.Unmarshal(b, &event)
+		assert.NoError(t, err)
+		return &event
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func (c *testWSConnection) Ack(t *testing.T, event *fftypes.EventDelivery) {
+	c.send(t, &fftypes.WSClientActionAckPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{Type: fftypes.WSClientActionAck},
+		ID:                 event.ID,
+		Subscription:       &event.Subscription,
+	})
+}
+
+func (c *testWSConnection) Nack(t *testing.T, event *fftypes.EventDelivery) {
+	c.send(t, &fftypes.WSClientActionAckPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{Type: fftypes.WSClientActionAck},
+		ID:                 event.ID,
+		Subscription:       &event.Subscription,
+		Rejected:           true,
+	})
+}
+
+func (c *testWSConnection) Close(t *testing.T) {
+	c.wsc.Close()
+}
+
+func TestConformanceWebSockets(t *testing.T) {
+	Run(t, func(t *testing.T, callbacks events.Callbacks) (events.Plugin, Client, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ws := &websockets.WebSockets{}
+		prefix := config.NewPluginConfig("unittest.conformance.websockets")
+		ws.InitPrefix(prefix)
+		err := ws.Init(ctx, prefix, callbacks)
+		assert.NoError(t, err)
+		svr := httptest.NewServer(ws)
+		client := &testWSClient{
+			ctx: ctx,
+			url: fmt.Sprintf("http://%s", svr.Listener.Addr()),
+		}
+		return ws, client, func() {
+			cancel()
+			ws.WaitClosed()
+			svr.Close()
+		}
+	})
+}
+
+type testFailT struct {
+	failed bool
+}
+
+func (ft *testFailT) Errorf(format string, args ...interface{}) {}
+
+func (ft *testFailT) FailNow() { ft.failed = true }
+
+func TestSuiteFailures(t *testing.T) {
+	savedTimeout := Timeout
+	Timeout = 10 * time.Millisecond
+	defer func() { Timeout = savedTimeout }()
+	cb := newCallbacks()
+	s := &suite{callbacks: cb}
+
+	err := cb.EphemeralSubscription("conn1", "ns1", &fftypes.SubscriptionFilter{}, &fftypes.SubscriptionOptions{})
+	assert.NoError(t, err)
+
+	ft := &testFailT{}
+	assert.Empty(t, s.waitRegistered(ft))
+	assert.True(t, ft.failed)
+
+	ft = &testFailT{}
+	cb.ConnnectionClosed("conn2")
+	s.waitClosed(ft, "conn1")
+	assert.True(t, ft.failed)
+
+	ft = &testFailT{}
+	assert.Nil(t, s.waitResponse(ft))
+	assert.True(t, ft.failed)
+
+	ft = &testFailT{}
+	cb.DeliveryResponse("conn1", &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID()})
+	s.assertNoResponse(ft)
+
+	ft = &testFailT{}
+	assert.Nil(t, s.checkReceived(ft, nil))
+	assert.True(t, ft.failed)
+}
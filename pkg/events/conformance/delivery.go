@@ -0,0 +1,191 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBasics(t *testing.T, s *suite) {
+	assert.NotEmpty(t, s.plugin.Name())
+	capabilities := s.plugin.Capabilities()
+	assert.NotNil(t, capabilities)
+
+	// The schema is returned on the API, so must be valid JSON
+	schema := s.plugin.GetOptionsSchema(s.ctx)
+	assert.True(t, json.Valid([]byte(schema)), schema)
+
+	// A transport that supports change events must be able to receive them
+	if capabilities != nil && capabilities.ChangeEvents {
+		_, ok := s.plugin.(events.ChangeEventListener)
+		assert.True(t, ok, "change events supported, but ChangeEventListener not implemented")
+	}
+}
+
+func testOptionValidation(t *testing.T, s *suite) {
+	for _, options := range s.client.ValidOptions() {
+		err := s.plugin.ValidateOptions(options)
+		assert.NoError(t, err)
+
+		// The plugin can modify the options, but must accept its own output - as that is what is stored
+		err = s.plugin.ValidateOptions(options)
+		assert.NoError(t, err)
+	}
+	for _, options := range s.client.InvalidOptions() {
+		err := s.plugin.ValidateOptions(options)
+		assert.Error(t, err)
+	}
+}
+
+func testConnectionLifecycle(t *testing.T, s *suite) {
+	sub := newTestSubscription("sub1")
+	conn, connID := s.connect(t, sub)
+	assert.NotEmpty(t, connID)
+
+	// The connection must only match the subscription it was started for
+	matcher := s.callbacks.Matcher(connID)
+	assert.True(t, matcher(sub.SubscriptionRef))
+	assert.False(t, matcher(fftypes.SubscriptionRef{Namespace: sub.Namespace, Name: "sub2"}))
+	assert.False(t, matcher(fftypes.SubscriptionRef{Namespace: "ns2", Name: sub.Name}))
+
+	conn.Close(t)
+	s.waitClosed(t, connID)
+
+	// The core might still attempt delivery after the close notification, which must be safe
+	assert.NotPanics(t, func() {
+		_ = s.plugin.DeliveryRequest(connID, sub, newTestDelivery(sub, 1), nil)
+	})
+	s.assertNoResponse(t)
+}
+
+func testUnknownConnection(t *testing.T, s *suite) {
+	sub := newTestSubscription("sub1")
+	err := s.plugin.DeliveryRequest(fftypes.NewUUID().String(), sub, newTestDelivery(sub, 1), nil)
+	assert.Error(t, err)
+}
+
+func testDeliveryOrdering(t *testing.T, s *suite) {
+	sub := newTestSubscription("sub1")
+	conn, connID := s.connect(t, sub)
+	defer conn.Close(t)
+
+	// Events are delivered in the order they are requested, without waiting for each to be acknowledged
+	sent := make([]*fftypes.EventDelivery, 10)
+	for i := range sent {
+		sent[i] = newTestDelivery(sub, int64(i+1))
+		err := s.plugin.DeliveryRequest(connID, sub, sent[i], nil)
+		assert.NoError(t, err)
+	}
+	received := make([]*fftypes.EventDelivery, len(sent))
+	for i, expected := range sent {
+		received[i] = s.receive(t, conn)
+		assert.Equal(t, *expected.ID, *received[i].ID)
+		assert.Equal(t, expected.Sequence, received[i].Sequence)
+		assert.Equal(t, expected.Type, received[i].Type)
+		assert.Equal(t, sub.Namespace, received[i].Subscription.Namespace)
+		assert.Equal(t, sub.Name, received[i].Subscription.Name)
+	}
+
+	// Acknowledgements are passed back to the core in the order they are made, against the registered connection
+	for _, event := range received {
+		conn.Ack(t, event)
+	}
+	for _, expected := range sent {
+		res := s.waitResponse(t)
+		assert.Equal(t, connID, res.ConnID)
+		assert.Equal(t, *expected.ID, *res.Response.ID)
+		assert.Equal(t, sub.Name, res.Response.Subscription.Name)
+		assert.False(t, res.Response.Rejected)
+	}
+}
+
+func testAckSemantics(t *testing.T, s *suite) {
+	sub := newTestSubscription("sub1")
+	conn, connID := s.connect(t, sub)
+	defer conn.Close(t)
+
+	event1 := newTestDelivery(sub, 1)
+	err := s.plugin.DeliveryRequest(connID, sub, event1, nil)
+	assert.NoError(t, err)
+	received := s.receive(t, conn)
+
+	// Nothing is passed back until the application responds
+	s.assertNoResponse(t)
+
+	conn.Ack(t, received)
+	res := s.waitResponse(t)
+	assert.Equal(t, *event1.ID, *res.Response.ID)
+	assert.False(t, res.Response.Rejected)
+
+	// A rejected event is passed back, so the core redelivers it
+	event2 := newTestDelivery(sub, 2)
+	err = s.plugin.DeliveryRequest(connID, sub, event2, nil)
+	assert.NoError(t, err)
+	received = s.receive(t, conn)
+	conn.Nack(t, received)
+	res = s.waitResponse(t)
+	assert.Equal(t, connID, res.ConnID)
+	assert.Equal(t, *event2.ID, *res.Response.ID)
+	assert.True(t, res.Response.Rejected)
+
+	// Each event is passed back exactly once
+	s.assertNoResponse(t)
+}
+
+func testMultipleConnections(t *testing.T, s *suite) {
+	sub1 := newTestSubscription("sub1")
+	sub2 := newTestSubscription("sub2")
+	conn1, connID1 := s.connect(t, sub1)
+	defer conn1.Close(t)
+	conn2, connID2 := s.connect(t, sub2)
+	assert.NotEqual(t, connID1, connID2)
+
+	// Events are only delivered to the connection they are requested on
+	event1 := newTestDelivery(sub1, 1)
+	event2 := newTestDelivery(sub2, 2)
+	err := s.plugin.DeliveryRequest(connID2, sub2, event2, nil)
+	assert.NoError(t, err)
+	err = s.plugin.DeliveryRequest(connID1, sub1, event1, nil)
+	assert.NoError(t, err)
+	received1 := s.receive(t, conn1)
+	assert.Equal(t, *event1.ID, *received1.ID)
+	received2 := s.receive(t, conn2)
+	assert.Equal(t, *event2.ID, *received2.ID)
+
+	conn2.Ack(t, received2)
+	res := s.waitResponse(t)
+	assert.Equal(t, connID2, res.ConnID)
+	assert.Equal(t, *event2.ID, *res.Response.ID)
+
+	// Closing one connection does not affect the other
+	conn2.Close(t)
+	s.waitClosed(t, connID2)
+	event3 := newTestDelivery(sub1, 3)
+	err = s.plugin.DeliveryRequest(connID1, sub1, event3, nil)
+	assert.NoError(t, err)
+	received1 = s.receive(t, conn1)
+	assert.Equal(t, *event3.ID, *received1.ID)
+	conn1.Ack(t, received1)
+	res = s.waitResponse(t)
+	assert.Equal(t, connID1, res.ConnID)
+	assert.Equal(t, *event3.ID, *res.Response.ID)
+}
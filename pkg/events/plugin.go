@@ -57,6 +57,13 @@ type ChangeEventListener interface {
 	ChangeEvent(connID string, ce *fftypes.ChangeEvent)
 }
 
+// OptionsRedactor is an optional interface for transports with secret options, such as signing keys, which
+// must not be returned when a subscription is read back through the API
+type OptionsRedactor interface {
+	// RedactOptions removes any secret values from the transport specific options, in place
+	RedactOptions(options fftypes.JSONObject)
+}
+
 // PluginAll is a combined interface for easy mocking, with all optional features
 type PluginAll interface {
 	Plugin
@@ -0,0 +1,79 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// AnnotationsMax is the maximum number of annotations that can be set on a single resource
+const AnnotationsMax = 32
+
+// Annotations are custom key/value metadata set on a resource, such as the owning team or the environment,
+// that can be used to filter queries with an "annotations.<key>" field
+type Annotations map[string]string
+
+// Validate checks each key is a valid name, and each value fits in the index used to filter on it
+func (a Annotations) Validate(ctx context.Context) error {
+	if len(a) > AnnotationsMax {
+		return i18n.NewError(ctx, i18n.MsgTooManyItems, "annotations", AnnotationsMax, len(a))
+	}
+	for k, v := range a {
+		fieldName := fmt.Sprintf("annotations.%s", k)
+		if err := ValidateFFNameField(ctx, k, fieldName); err != nil {
+			return err
+		}
+		if err := ValidateLength(ctx, v, fieldName, FFStringArrayStandardMax); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scan implements sql.Scanner
+func (a *Annotations) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &a)
+
+	case []byte:
+		return json.Unmarshal(src, &a)
+
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, a)
+	}
+}
+
+// Value implements sql.Valuer
+func (a Annotations) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, _ := json.Marshal(a)
+	return string(b), nil
+}
@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotationsValidate(t *testing.T) {
+	ctx := context.Background()
+
+	a := Annotations{"owner": "team-a", "cost.center": "1234"}
+	assert.NoError(t, a.Validate(ctx))
+
+	a = Annotations{"!owner": "team-a"}
+	assert.Regexp(t, "FF10131.*annotations.!owner", a.Validate(ctx))
+
+	a = Annotations{"owner": strings.Repeat("a", 1025)}
+	assert.Regexp(t, "FF10188.*annotations.owner", a.Validate(ctx))
+
+	a = Annotations{}
+	for i := 0; i <= AnnotationsMax; i++ {
+		a[fmt.Sprintf("key%d", i)] = "value"
+	}
+	assert.Regexp(t, "FF10227", a.Validate(ctx))
+}
+
+func TestAnnotationsScanValue(t *testing.T) {
+	a := Annotations{"owner": "team-a"}
+	v, err := a.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"owner":"team-a"}`, v)
+
+	var a1 Annotations
+	err = a1.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", a1["owner"])
+
+	var a2 Annotations
+	err = a2.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", a2["owner"])
+
+	var a3 Annotations
+	assert.NoError(t, a3.Scan(nil))
+	assert.NoError(t, a3.Scan(""))
+	assert.Nil(t, a3)
+	assert.Regexp(t, "FF10125", a3.Scan(12345))
+
+	v, err = a3.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
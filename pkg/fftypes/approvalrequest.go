@@ -0,0 +1,127 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// ApprovalRequestType is the kind of request that is held for approval
+type ApprovalRequestType = FFEnum
+
+var (
+	// ApprovalRequestTypeTokenMint is a token mint of an amount above the configured threshold
+	ApprovalRequestTypeTokenMint = ffEnum("approvalrequesttype", "token_mint")
+)
+
+// ApprovalRequestState is the progress of an approval request
+type ApprovalRequestState = FFEnum
+
+var (
+	// ApprovalRequestStatePending is waiting for decisions from the approvers
+	ApprovalRequestStatePending = ffEnum("approvalrequeststate", "pending")
+	// ApprovalRequestStateApproved has received the required approvals, and is waiting to be submitted
+	ApprovalRequestStateApproved = ffEnum("approvalrequeststate", "approved")
+	// ApprovalRequestStateSubmitted has received the required approvals, and has been submitted
+	ApprovalRequestStateSubmitted = ffEnum("approvalrequeststate", "submitted")
+	// ApprovalRequestStateRejected was rejected by an approver, and will not be submitted
+	ApprovalRequestStateRejected = ffEnum("approvalrequeststate", "rejected")
+	// ApprovalRequestStateFailed received the required approvals, but could not be submitted
+	ApprovalRequestStateFailed = ffEnum("approvalrequeststate", "failed")
+)
+
+// ApprovalRequest is a request for a sensitive operation, held until the required number of the configured
+// approvers have approved it
+type ApprovalRequest struct {
+	ID        *UUID                `json:"id"`
+	Namespace string               `json:"namespace"`
+	Type      ApprovalRequestType  `json:"type" ffenum:"approvalrequesttype"`
+	State     ApprovalRequestState `json:"state" ffenum:"approvalrequeststate"`
+	Reference *UUID                `json:"reference,omitempty"`
+	Request   *JSONAny             `json:"request"`
+	Required  int                  `json:"required"`
+	Decisions ApprovalDecisions    `json:"decisions"`
+	Error     string               `json:"error,omitempty"`
+	Created   *FFTime              `json:"created"`
+	Updated   *FFTime              `json:"updated,omitempty"`
+}
+
+// ApprovalDecision is the approval or rejection of a request by one of the approvers
+type ApprovalDecision struct {
+	Approver string  `json:"approver"`
+	Approved bool    `json:"approved"`
+	Comment  string  `json:"comment,omitempty"`
+	Created  *FFTime `json:"created"`
+}
+
+// ApprovalDecisionInput is the body of a request to approve or reject an approval request
+type ApprovalDecisionInput struct {
+	Approver string `json:"approver"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// ApprovalDecisions is the list of decisions made on an approval request, in the order they were made
+type ApprovalDecisions []*ApprovalDecision
+
+// Approvals is the number of approvers who have approved the request
+func (ar *ApprovalRequest) Approvals() int {
+	count := 0
+	for _, d := range ar.Decisions {
+		if d.Approved {
+			count++
+		}
+	}
+	return count
+}
+
+// DecisionBy returns the decision made by an approver, or nil if they have not yet decided
+func (ar *ApprovalRequest) DecisionBy(approver string) *ApprovalDecision {
+	for _, d := range ar.Decisions {
+		if d.Approver == approver {
+			return d
+		}
+	}
+	return nil
+}
+
+// Scan implements sql.Scanner
+func (ad *ApprovalDecisions) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*ad = ApprovalDecisions{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), ad)
+	case []byte:
+		return json.Unmarshal(src, ad)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, ad)
+	}
+}
+
+// Value implements sql.Valuer
+func (ad ApprovalDecisions) Value() (driver.Value, error) {
+	if ad == nil {
+		ad = ApprovalDecisions{}
+	}
+	bytes, _ := json.Marshal(ad)
+	return bytes, nil
+}
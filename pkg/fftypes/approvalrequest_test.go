@@ -0,0 +1,67 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalRequestDecisions(t *testing.T) {
+	ar := &ApprovalRequest{
+		Decisions: ApprovalDecisions{
+			{Approver: "did:firefly:org/org1", Approved: true},
+			{Approver: "did:firefly:org/org2", Approved: false},
+			{Approver: "did:firefly:org/org3", Approved: true},
+		},
+	}
+	assert.Equal(t, 2, ar.Approvals())
+	assert.False(t, ar.DecisionBy("did:firefly:org/org2").Approved)
+	assert.Nil(t, ar.DecisionBy("did:firefly:org/org4"))
+}
+
+func TestApprovalDecisionsScanValue(t *testing.T) {
+	ad := ApprovalDecisions{
+		{Approver: "did:firefly:org/org1", Approved: true, Comment: "ok"},
+	}
+	v, err := ad.Value()
+	assert.NoError(t, err)
+
+	var ad2 ApprovalDecisions
+	err = ad2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", ad2[0].Comment)
+
+	var ad3 ApprovalDecisions
+	err = ad3.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/org1", ad3[0].Approver)
+
+	var ad4 ApprovalDecisions
+	err = ad4.Scan(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, ad4)
+
+	var empty ApprovalDecisions
+	v, err = empty.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(v.([]byte)))
+
+	err = ad4.Scan(12345)
+	assert.Regexp(t, "FF10125", err)
+}
@@ -0,0 +1,31 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// AsyncRequest is a request to a mutating API route that is processed in the background, because the client
+// asked for an asynchronous response with a "Prefer: respond-async" header or the async query parameter
+type AsyncRequest struct {
+	ID         *UUID    `json:"id"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Status     OpStatus `json:"status"`
+	StatusCode int      `json:"statusCode,omitempty"`
+	Output     *JSONAny `json:"output,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Created    *FFTime  `json:"created"`
+	Completed  *FFTime  `json:"completed,omitempty"`
+}
@@ -17,8 +17,11 @@
 package fftypes
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
 )
 
 // BatchType is the type of a batch
@@ -34,6 +37,21 @@ var (
 const (
 	ManifestVersionUnset uint = 0
 	ManifestVersion1     uint = 1
+	ManifestVersion2     uint = 2
+)
+
+// The network protocol version is negotiated between the members exchanging a batch,
+// and determines the manifest version and the encoding of the payload in transit.
+const (
+	// ProtocolVersion1 exchanges uncompressed JSON batch payloads, with a v1 manifest
+	ProtocolVersion1 uint = 1
+	// ProtocolVersion2 exchanges gzip compressed JSON batch payloads, with a v2 manifest
+	ProtocolVersion2 uint = 2
+	// ProtocolVersion3 exchanges the same payloads and manifests as ProtocolVersion2, but the batch can be hashed with the algorithm set
+	// in its header. Receivers cannot tell it apart from ProtocolVersion2, so it must not change the manifest or encoding
+	ProtocolVersion3 uint = 3
+	// ProtocolVersionLatest is the newest protocol version supported by this node, which it advertises in its node identity
+	ProtocolVersionLatest = ProtocolVersion3
 )
 
 // BatchHeader is the common fields between the serialized batch, and the batch manifest
@@ -43,8 +61,9 @@ type BatchHeader struct {
 	Namespace string    `json:"namespace"`
 	Node      *UUID     `json:"node,omitempty"`
 	SignerRef
-	Group   *Bytes32 `jdon:"group,omitempty"`
-	Created *FFTime  `json:"created"`
+	Group         *Bytes32      `jdon:"group,omitempty"`
+	Created       *FFTime       `json:"created"`
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm,omitempty" ffenum:"hashalgorithm"`
 }
 
 type MessageManifestEntry struct {
@@ -69,18 +88,27 @@ type BatchManifest struct {
 // Batch is the full payload object used in-flight.
 type Batch struct {
 	BatchHeader
-	Hash    *Bytes32     `json:"hash"`
-	Payload BatchPayload `json:"payload"`
+	Hash            *Bytes32     `json:"hash"`
+	Payload         BatchPayload `json:"payload"`
+	ProtocolVersion uint         `json:"-"` // negotiated network protocol version, determined by the transport encoding on receipt
+	PayloadRef      string       `json:"-"` // shared storage reference the batch was downloaded from, for broadcast batches
 }
 
 // BatchPersisted is the structure written to the database
 type BatchPersisted struct {
 	BatchHeader
-	Hash       *Bytes32       `json:"hash"`
-	Manifest   *JSONAny       `json:"manifest"`
-	TX         TransactionRef `json:"tx"`
-	PayloadRef string         `json:"payloadRef,omitempty"`
-	Confirmed  *FFTime        `json:"confirmed"`
+	Hash       *Bytes32                `json:"hash"`
+	Manifest   *JSONAny                `json:"manifest"`
+	TX         TransactionRef          `json:"tx"`
+	PayloadRef string                  `json:"payloadRef,omitempty"`
+	Confirmed  *FFTime                 `json:"confirmed"`
+	Recipients []*BatchRecipientStatus `json:"recipients,omitempty"` // not persisted - populated for a private batch when it is retrieved by ID
+}
+
+// BatchRecipientStatus is the status of the data exchange send of a private batch to one node in the group
+type BatchRecipientStatus struct {
+	Node *UUID                     `json:"node"`
+	Send *TransactionStatusDetails `json:"send"`
 }
 
 // BatchPayload contains the full JSON of the messages and data, but
@@ -108,9 +136,15 @@ func (ma *BatchPayload) Hash() *Bytes32 {
 	return &b32
 }
 
-func (ma *BatchPayload) Manifest(id *UUID) *BatchManifest {
+// Manifest generates the manifest for the payload, for the given network protocol version.
+// The version of the manifest is part of the hashed content.
+func (ma *BatchPayload) Manifest(id *UUID, protocolVersion uint) *BatchManifest {
+	version := ManifestVersion1
+	if protocolVersion > ProtocolVersion1 {
+		version = ManifestVersion2
+	}
 	tm := &BatchManifest{
-		Version:  ManifestVersion1,
+		Version:  version,
 		ID:       id,
 		TX:       ma.TX,
 		Messages: make([]*MessageManifestEntry, 0, len(ma.Messages)),
@@ -138,12 +172,46 @@ func (ma *BatchPayload) Manifest(id *UUID) *BatchManifest {
 	return tm
 }
 
-func (b *BatchPersisted) GenManifest(messages []*Message, data DataArray) *BatchManifest {
+func (b *BatchPersisted) GenManifest(messages []*Message, data DataArray, protocolVersion uint) *BatchManifest {
 	return (&BatchPayload{
 		TX:       b.TX,
 		Messages: messages,
 		Data:     data,
-	}).Manifest(b.ID)
+	}).Manifest(b.ID, protocolVersion)
+}
+
+// MigrateManifest converts a batch persisted by v0.13.x or earlier, which stored the full batch payload in
+// place of the manifest, to a v1 manifest. It returns nil if the batch already has a manifest.
+// The payload, and each message in it, is verified against its hash before it is converted.
+func (b *BatchPersisted) MigrateManifest(ctx context.Context) (*BatchManifest, error) {
+	var manifest BatchManifest
+	if err := b.Manifest.Unmarshal(ctx, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != ManifestVersionUnset {
+		return nil, nil
+	}
+
+	var payload BatchPayload
+	if err := b.Manifest.Unmarshal(ctx, &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.Messages) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchMigrationNoPayload, b.ID)
+	}
+	// The hash was calculated over the payload as it was serialized at the time, so check the persisted
+	// bytes first - as newer fields on the messages and data change the serialization
+	var rawHash Bytes32 = sha256.Sum256(b.Manifest.Bytes())
+	if !b.Hash.Equals(&rawHash) && !b.Hash.Equals(payload.Hash()) {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchMigrationHashMismatch, b.ID, b.Hash)
+	}
+	for _, m := range payload.Messages {
+		if m == nil || !m.Hash.Equals(m.Header.Hash()) {
+			return nil, i18n.NewError(ctx, i18n.MsgBatchMigrationMessageHash, b.ID)
+		}
+	}
+
+	return b.GenManifest(payload.Messages, payload.Data, ProtocolVersion1), nil
 }
 
 func (b *BatchPersisted) GenInflight(messages []*Message, data DataArray) *Batch {
@@ -160,13 +228,14 @@ func (b *BatchPersisted) GenInflight(messages []*Message, data DataArray) *Batch
 
 // Confirmed generates a newly confirmed persisted batch, including (re-)generating the manifest
 func (b *Batch) Confirmed() (*BatchPersisted, *BatchManifest) {
-	manifest := b.Payload.Manifest(b.ID)
+	manifest := b.Payload.Manifest(b.ID, b.ProtocolVersion)
 	manifestString := manifest.String()
 	return &BatchPersisted{
 		BatchHeader: b.BatchHeader,
 		Hash:        b.Hash,
 		TX:          b.Payload.TX,
 		Manifest:    JSONAnyPtr(manifestString),
+		PayloadRef:  b.PayloadRef,
 		Confirmed:   Now(),
 	}, manifest
 }
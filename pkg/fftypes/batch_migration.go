@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// BatchMigrationStatus is the progress of rewriting the batches persisted by v0.13.x and earlier to the manifest format
+type BatchMigrationStatus struct {
+	Running   bool                     `json:"running"`
+	Started   *FFTime                  `json:"started,omitempty"`
+	Completed *FFTime                  `json:"completed,omitempty"`
+	Scanned   int64                    `json:"scanned"`
+	Migrated  int64                    `json:"migrated"`
+	Failed    int64                    `json:"failed"`
+	Error     string                   `json:"error,omitempty"`
+	Failures  []*BatchMigrationFailure `json:"failures,omitempty"`
+}
+
+// BatchMigrationFailure records a legacy batch that could not be migrated, which is left to be converted when it is read
+type BatchMigrationFailure struct {
+	Batch *UUID  `json:"batch"`
+	Error string `json:"error"`
+}
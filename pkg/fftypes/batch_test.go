@@ -17,6 +17,7 @@
 package fftypes
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -57,10 +58,142 @@ func TestSQLSerializedManifest(t *testing.T) {
 	assert.Equal(t, msgID1, mf.Messages[0].ID)
 	assert.Equal(t, msgID2, mf.Messages[1].ID)
 	mfHash := sha256.Sum256([]byte(mfString))
-	assert.Equal(t, HashString(bp.GenManifest(batch.Payload.Messages, batch.Payload.Data).String()).String(), hex.EncodeToString(mfHash[:]))
+	assert.Equal(t, HashString(bp.GenManifest(batch.Payload.Messages, batch.Payload.Data, ProtocolVersion1).String()).String(), hex.EncodeToString(mfHash[:]))
 
 	assert.Equal(t, batch, bp.GenInflight(batch.Payload.Messages, batch.Payload.Data))
 
 	assert.NotEqual(t, batch.Payload.Hash().String(), hex.EncodeToString(mfHash[:]))
 
 }
+
+func TestManifestV2(t *testing.T) {
+
+	batch := &Batch{
+		BatchHeader: BatchHeader{
+			ID: NewUUID(),
+		},
+		Payload: BatchPayload{
+			Messages: []*Message{
+				{Header: MessageHeader{ID: NewUUID()}},
+			},
+		},
+		ProtocolVersion: ProtocolVersion2,
+		PayloadRef:      "ref1",
+	}
+
+	bp, manifest := batch.Confirmed()
+	assert.Equal(t, ManifestVersion2, manifest.Version)
+	assert.Equal(t, "ref1", bp.PayloadRef)
+	assert.NotEqual(t, bp.GenManifest(batch.Payload.Messages, nil, ProtocolVersion1).String(), bp.Manifest.String())
+	assert.Equal(t, bp.GenManifest(batch.Payload.Messages, nil, ProtocolVersion2).String(), bp.Manifest.String())
+	assert.Equal(t, bp.GenManifest(batch.Payload.Messages, nil, ProtocolVersion3).String(), bp.Manifest.String())
+
+}
+
+func newLegacyTestBatch(t *testing.T, payload *BatchPayload) *BatchPersisted {
+	b, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	var hash Bytes32 = sha256.Sum256(b)
+	return &BatchPersisted{
+		BatchHeader: BatchHeader{
+			ID: NewUUID(),
+		},
+		Hash:     &hash,
+		TX:       payload.TX,
+		Manifest: JSONAnyPtr(string(b)),
+	}
+}
+
+func newLegacyTestPayload() *BatchPayload {
+	msg := &Message{
+		Header: MessageHeader{
+			ID:     NewUUID(),
+			Topics: FFStringArray{"topic1", "topic2"},
+		},
+	}
+	msg.Hash = msg.Header.Hash()
+	return &BatchPayload{
+		TX: TransactionRef{
+			Type: TransactionTypeBatchPin,
+			ID:   NewUUID(),
+		},
+		Messages: []*Message{msg},
+		Data: DataArray{
+			{ID: NewUUID(), Hash: NewRandB32()},
+		},
+	}
+}
+
+func TestMigrateManifestRawHash(t *testing.T) {
+	payload := newLegacyTestPayload()
+	batch := newLegacyTestBatch(t, payload)
+
+	manifest, err := batch.MigrateManifest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ManifestVersion1, manifest.Version)
+	assert.Equal(t, batch.ID, manifest.ID)
+	assert.Equal(t, payload.TX.ID, manifest.TX.ID)
+	assert.Len(t, manifest.Messages, 1)
+	assert.Equal(t, payload.Messages[0].Hash, manifest.Messages[0].Hash)
+	assert.Equal(t, 2, manifest.Messages[0].Topics)
+	assert.Len(t, manifest.Data, 1)
+	assert.Equal(t, payload.Data[0].Hash, manifest.Data[0].Hash)
+}
+
+func TestMigrateManifestReserializedHash(t *testing.T) {
+	payload := newLegacyTestPayload()
+	batch := newLegacyTestBatch(t, payload)
+	b, _ := json.MarshalIndent(payload, "", "  ")
+	batch.Manifest = JSONAnyPtr(string(b))
+	batch.Hash = payload.Hash()
+
+	manifest, err := batch.MigrateManifest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ManifestVersion1, manifest.Version)
+}
+
+func TestMigrateManifestNotLegacy(t *testing.T) {
+	batch := &BatchPersisted{
+		Manifest: JSONAnyPtr(`{"version":1}`),
+	}
+	manifest, err := batch.MigrateManifest(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, manifest)
+}
+
+func TestMigrateManifestBadJSON(t *testing.T) {
+	batch := &BatchPersisted{
+		Manifest: JSONAnyPtr(`!json`),
+	}
+	_, err := batch.MigrateManifest(context.Background())
+	assert.Regexp(t, "invalid character", err)
+}
+
+func TestMigrateManifestBadPayload(t *testing.T) {
+	batch := &BatchPersisted{
+		Manifest: JSONAnyPtr(`{"messages":[{"header":"wrong"}]}`),
+	}
+	_, err := batch.MigrateManifest(context.Background())
+	assert.Regexp(t, "cannot unmarshal", err)
+}
+
+func TestMigrateManifestNoMessages(t *testing.T) {
+	batch := newLegacyTestBatch(t, &BatchPayload{})
+	_, err := batch.MigrateManifest(context.Background())
+	assert.Regexp(t, "FF10440", err)
+}
+
+func TestMigrateManifestHashMismatch(t *testing.T) {
+	batch := newLegacyTestBatch(t, newLegacyTestPayload())
+	batch.Hash = NewRandB32()
+	_, err := batch.MigrateManifest(context.Background())
+	assert.Regexp(t, "FF10441", err)
+}
+
+func TestMigrateManifestMessageHashMismatch(t *testing.T) {
+	payload := newLegacyTestPayload()
+	payload.Messages[0].Hash = NewRandB32()
+	batch := newLegacyTestBatch(t, payload)
+	_, err := batch.MigrateManifest(context.Background())
+	assert.Regexp(t, "FF10442", err)
+}
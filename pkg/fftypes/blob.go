@@ -17,10 +17,11 @@
 package fftypes
 
 type Blob struct {
-	Hash       *Bytes32 `json:"hash"`
-	Size       int64    `json:"size"`
-	PayloadRef string   `json:"payloadRef,omitempty"`
-	Peer       string   `json:"peer,omitempty"`
-	Created    *FFTime  `json:"created,omitempty"`
-	Sequence   int64    `json:"-"`
+	Hash          *Bytes32      `json:"hash"`
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm,omitempty" ffenum:"hashalgorithm"`
+	Size          int64         `json:"size"`
+	PayloadRef    string        `json:"payloadRef,omitempty"`
+	Peer          string        `json:"peer,omitempty"`
+	Created       *FFTime       `json:"created,omitempty"`
+	Sequence      int64         `json:"-"`
 }
@@ -16,6 +16,16 @@
 
 package fftypes
 
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
 type BlockchainEvent struct {
 	ID         *UUID          `json:"id,omitempty"`
 	Sequence   int64          `json:"sequence"`
@@ -29,3 +39,54 @@ type BlockchainEvent struct {
 	Timestamp  *FFTime        `json:"timestamp,omitempty"`
 	TX         TransactionRef `json:"tx"`
 }
+
+// BlockchainEventRaw is the full raw information for a blockchain event. It is stored separately from
+// the event, and only for events received by a listener with the storeRaw option set
+type BlockchainEventRaw struct {
+	ID         *UUID   `json:"id,omitempty"`
+	Namespace  string  `json:"namespace,omitempty"`
+	Compressed bool    `json:"compressed"`
+	Data       []byte  `json:"-"`
+	Created    *FFTime `json:"created,omitempty"`
+}
+
+// NewBlockchainEventRaw serializes the raw information for an event, optionally compressing it with gzip
+func NewBlockchainEventRaw(event *BlockchainEvent, info JSONObject, compress bool) (*BlockchainEventRaw, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data) // writes to an in-memory buffer cannot fail
+		_ = zw.Close()
+		data = buf.Bytes()
+	}
+	return &BlockchainEventRaw{
+		ID:         event.ID,
+		Namespace:  event.Namespace,
+		Compressed: compress,
+		Data:       data,
+		Created:    Now(),
+	}, nil
+}
+
+// Info returns the raw information, decompressing it if required
+func (r *BlockchainEventRaw) Info(ctx context.Context) (JSONObject, error) {
+	data := r.Data
+	if r.Compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err == nil {
+			data, err = ioutil.ReadAll(zr)
+		}
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgBlockchainEventRawInvalid, r.ID)
+		}
+	}
+	var info JSONObject
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgBlockchainEventRawInvalid, r.ID)
+	}
+	return info, nil
+}
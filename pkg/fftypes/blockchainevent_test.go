@@ -0,0 +1,65 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockchainEventRawCompressed(t *testing.T) {
+	event := &BlockchainEvent{ID: NewUUID(), Namespace: "ns1"}
+	raw, err := NewBlockchainEventRaw(event, JSONObject{"logs": []string{"a", "b"}}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, raw.ID)
+	assert.Equal(t, "ns1", raw.Namespace)
+	assert.True(t, raw.Compressed)
+
+	info, err := raw.Info(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, info.GetStringArray("logs"))
+}
+
+func TestBlockchainEventRawUncompressed(t *testing.T) {
+	event := &BlockchainEvent{ID: NewUUID(), Namespace: "ns1"}
+	raw, err := NewBlockchainEventRaw(event, JSONObject{"blockNumber": "12345"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"blockNumber":"12345"}`, string(raw.Data))
+
+	info, err := raw.Info(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", info.GetString("blockNumber"))
+}
+
+func TestBlockchainEventRawMarshalFail(t *testing.T) {
+	_, err := NewBlockchainEventRaw(&BlockchainEvent{}, JSONObject{"bad": map[bool]bool{true: false}}, true)
+	assert.Error(t, err)
+}
+
+func TestBlockchainEventRawInfoBadCompressed(t *testing.T) {
+	raw := &BlockchainEventRaw{ID: NewUUID(), Compressed: true, Data: []byte("not gzip")}
+	_, err := raw.Info(context.Background())
+	assert.Regexp(t, "FF10467", err)
+}
+
+func TestBlockchainEventRawInfoBadJSON(t *testing.T) {
+	raw := &BlockchainEventRaw{ID: NewUUID(), Data: []byte("!json")}
+	_, err := raw.Info(context.Background())
+	assert.Regexp(t, "FF10467", err)
+}
@@ -0,0 +1,37 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import "context"
+
+// BusinessKeyMaxLength is the longest business key that can be supplied on a message, token transfer or contract invoke
+const BusinessKeyMaxLength = 256
+
+// BusinessTransaction is everything recorded on this node with the same client supplied business key,
+// such as the messages, token transfers and contract invokes that make up one order or settlement.
+type BusinessTransaction struct {
+	BusinessKey    string           `json:"businessKey"`
+	Namespaces     []string         `json:"namespaces"`
+	Messages       []*Message       `json:"messages"`
+	TokenTransfers []*TokenTransfer `json:"tokenTransfers"`
+	Operations     []*Operation     `json:"operations"`
+}
+
+// ValidateBusinessKey checks a business key is short enough to be indexed. An empty key is valid, as the key is optional
+func ValidateBusinessKey(ctx context.Context, key string) error {
+	return ValidateLength(ctx, key, "businessKey", BusinessKeyMaxLength)
+}
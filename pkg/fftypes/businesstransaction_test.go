@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBusinessKey(t *testing.T) {
+	assert.NoError(t, ValidateBusinessKey(context.Background(), ""))
+	assert.NoError(t, ValidateBusinessKey(context.Background(), strings.Repeat("x", BusinessKeyMaxLength)))
+	err := ValidateBusinessKey(context.Background(), strings.Repeat("x", BusinessKeyMaxLength+1))
+	assert.Regexp(t, "FF10188.*businessKey", err)
+}
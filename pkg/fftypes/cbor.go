@@ -0,0 +1,269 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+const cborMaxDepth = 256
+
+// cborDecoder is a minimal decoder for CBOR (RFC 8949), that produces the generic
+// values used to build the canonical JSON form of a CBOR payload.
+// - Integers of any size are preserved exactly as JSON numbers
+// - Byte strings are represented as base64 strings (the standard Go JSON encoding)
+// - Tags are ignored, and the tagged value used directly
+// - Only text string map keys are supported, as they must map to JSON object keys
+type cborDecoder struct {
+	buf []byte
+	pos int
+}
+
+func decodeCBOR(buf []byte) (interface{}, error) {
+	d := &cborDecoder{buf: buf}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.buf) {
+		return nil, fmt.Errorf("unexpected trailing data at offset %d", d.pos)
+	}
+	return v, nil
+}
+
+func (d *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.buf)-d.pos) {
+		return nil, fmt.Errorf("unexpected end of data at offset %d", d.pos)
+	}
+	b := d.buf[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// readHead reads the initial byte of a data item, and its argument
+func (d *cborDecoder) readHead() (major byte, info byte, arg uint64, err error) {
+	b, err := d.readBytes(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	var ab []byte
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		ab, err = d.readBytes(1)
+		if err == nil {
+			arg = uint64(ab[0])
+		}
+	case info == 25:
+		ab, err = d.readBytes(2)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint16(ab))
+		}
+	case info == 26:
+		ab, err = d.readBytes(4)
+		if err == nil {
+			arg = uint64(binary.BigEndian.Uint32(ab))
+		}
+	case info == 27:
+		ab, err = d.readBytes(8)
+		if err == nil {
+			arg = binary.BigEndian.Uint64(ab)
+		}
+	case info == 31:
+		// Indefinite length, or break
+	default:
+		err = fmt.Errorf("invalid additional information %d at offset %d", info, d.pos-1)
+	}
+	return major, info, arg, err
+}
+
+func (d *cborDecoder) isBreak() bool {
+	return d.pos < len(d.buf) && d.buf[d.pos] == 0xff
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, fmt.Errorf("maximum nesting depth %d exceeded", cborMaxDepth)
+	}
+	major, info, arg, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	indefinite := info == 31
+	if indefinite && major < 2 {
+		return nil, fmt.Errorf("invalid indefinite length integer at offset %d", d.pos-1)
+	}
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case 1:
+		// The value is -1 - arg, which can be outside of the range of an int64
+		n := new(big.Int).SetUint64(arg)
+		return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+	case 2:
+		return d.decodeString(major, indefinite, arg)
+	case 3:
+		b, err := d.decodeString(major, indefinite, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, fmt.Errorf("invalid UTF-8 text string ending at offset %d", d.pos)
+		}
+		return string(b), nil
+	case 4:
+		return d.decodeArray(depth, indefinite, arg)
+	case 5:
+		return d.decodeMap(depth, indefinite, arg)
+	case 6:
+		if indefinite {
+			return nil, fmt.Errorf("invalid tag at offset %d", d.pos-1)
+		}
+		return d.decode(depth + 1)
+	default:
+		return d.decodeSimple(info, arg)
+	}
+}
+
+func (d *cborDecoder) decodeString(major byte, indefinite bool, arg uint64) ([]byte, error) {
+	if !indefinite {
+		return d.readBytes(arg)
+	}
+	// An indefinite length string is a sequence of definite length chunks of the same type
+	b := []byte{}
+	for !d.isBreak() {
+		chunkMajor, chunkInfo, chunkLen, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, fmt.Errorf("invalid chunk in indefinite length string at offset %d", d.pos)
+		}
+		chunk, err := d.readBytes(chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+	d.pos++
+	return b, nil
+}
+
+func (d *cborDecoder) decodeArray(depth int, indefinite bool, arg uint64) ([]interface{}, error) {
+	if !indefinite && arg > uint64(len(d.buf)-d.pos) {
+		// Every entry is at least one byte
+		return nil, fmt.Errorf("unexpected end of data at offset %d", d.pos)
+	}
+	arr := []interface{}{}
+	for i := uint64(0); indefinite || i < arg; i++ {
+		if indefinite && d.isBreak() {
+			d.pos++
+			break
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *cborDecoder) decodeMap(depth int, indefinite bool, arg uint64) (map[string]interface{}, error) {
+	if !indefinite && arg > uint64(len(d.buf)-d.pos)/2 {
+		// Every key and value is at least one byte
+		return nil, fmt.Errorf("unexpected end of data at offset %d", d.pos)
+	}
+	m := make(map[string]interface{})
+	for i := uint64(0); indefinite || i < arg; i++ {
+		if indefinite && d.isBreak() {
+			d.pos++
+			break
+		}
+		keyPos := d.pos
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported non-text map key at offset %d", keyPos)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("duplicate map key '%s' at offset %d", key, keyPos)
+		}
+		if m[key], err = d.decode(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (d *cborDecoder) decodeSimple(info byte, arg uint64) (interface{}, error) {
+	var f float64
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		f = halfToFloat64(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	default:
+		return nil, fmt.Errorf("unsupported simple value %d at offset %d", arg, d.pos-1)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("unsupported non-finite float at offset %d", d.pos-1)
+	}
+	return f, nil
+}
+
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,132 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cborToJSON(t *testing.T, hexStr string) (string, error) {
+	b, err := hex.DecodeString(hexStr)
+	assert.NoError(t, err)
+	v, err := decodeCBOR(b)
+	if err != nil {
+		return "", err
+	}
+	j, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return string(j), nil
+}
+
+func TestDecodeCBORVectors(t *testing.T) {
+	// Examples from RFC 8949 Appendix A
+	vectors := map[string]string{
+		"00":                 `0`,
+		"17":                 `23`,
+		"1818":               `24`,
+		"1903e8":             `1000`,
+		"1a000f4240":         `1000000`,
+		"1bffffffffffffffff": `18446744073709551615`,
+		"20":                 `-1`,
+		"3903e7":             `-1000`,
+		"3bffffffffffffffff": `-18446744073709551616`,
+		"f90000":             `0`,
+		"f93c00":             `1`,
+		"f97bff":             `65504`,
+		"f90001":             `5.960464477539063e-8`,
+		"f9c400":             `-4`,
+		"fa47c35000":         `100000`,
+		"fb3ff199999999999a": `1.1`,
+		"f4":                 `false`,
+		"f5":                 `true`,
+		"f6":                 `null`,
+		"f7":                 `null`,
+		"c074323031332d30332d32315432303a30343a30305a": `"2013-03-21T20:04:00Z"`,
+		"4401020304":                 `"AQIDBA=="`,
+		"5f42010243030405ff":         `"AQIDBAU="`,
+		"6449455446":                 `"IETF"`,
+		"62c3bc":                     `"ü"`,
+		"7f657374726561646d696e67ff": `"streaming"`,
+		"80":                         `[]`,
+		"83010203":                   `[1,2,3]`,
+		"9f018202039f0405ffff":       `[1,[2,3],[4,5]]`,
+		"a26161016162820203":         `{"a":1,"b":[2,3]}`,
+		"bf61610161629f0203ffff":     `{"a":1,"b":[2,3]}`,
+	}
+	for in, expected := range vectors {
+		out, err := cborToJSON(t, in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, out, in)
+	}
+}
+
+func TestDecodeCBORErrors(t *testing.T) {
+	vectors := map[string]string{
+		"":                   "unexpected end",
+		"0001":               "trailing data",
+		"1c":                 "invalid additional information",
+		"19":                 "unexpected end",
+		"1a00":               "unexpected end",
+		"1b00":               "unexpected end",
+		"1f":                 "indefinite length integer",
+		"df00":               "invalid tag",
+		"c6":                 "unexpected end",
+		"5f6161ff":           "invalid chunk",
+		"5f5fff":             "invalid chunk",
+		"5f41":               "unexpected end",
+		"5f1c":               "invalid additional information",
+		"62c328":             "invalid UTF-8",
+		"6361":               "unexpected end",
+		"8201":               "unexpected end",
+		"9f1c":               "invalid additional information",
+		"a201":               "unexpected end",
+		"a10102":             "non-text map key",
+		"a161611c":           "invalid additional information",
+		"a1f6f6":             "non-text map key",
+		"a161611c02":         "invalid additional information",
+		"a2616101616102":     "duplicate map key 'a'",
+		"bf1c":               "invalid additional information",
+		"f8ff":               "unsupported simple value",
+		"ff":                 "unsupported simple value",
+		"f97c00":             "non-finite",
+		"f97e00":             "non-finite",
+		"fb7ff0000000000000": "non-finite",
+		"a1611c":             "unexpected end",
+	}
+	for in, expected := range vectors {
+		_, err := cborToJSON(t, in)
+		assert.Regexp(t, expected, err, in)
+	}
+}
+
+func TestDecodeCBORMaxDepth(t *testing.T) {
+	b := make([]byte, cborMaxDepth+2)
+	for i := range b {
+		b[i] = 0x81
+	}
+	_, err := decodeCBOR(b)
+	assert.Regexp(t, "maximum nesting depth", err)
+}
+
+func TestHalfToFloat64Negative(t *testing.T) {
+	assert.Equal(t, -2.0, halfToFloat64(0xc000))
+	assert.Equal(t, -5.960464477539063e-8, halfToFloat64(0x8001))
+}
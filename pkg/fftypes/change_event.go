@@ -40,3 +40,26 @@ type ChangeEvent struct {
 	// Sequence is set if there is a local ordered sequence associated with the changed resource
 	Sequence *int64 `json:"sequence,omitempty"`
 }
+
+// ChangeEventFilter restricts the change events delivered to a connection to one namespace,
+// and optionally to a set of collections and event types
+type ChangeEventFilter struct {
+	Namespace   string            `json:"namespace"`
+	Collections []string          `json:"collections,omitempty"`
+	Types       []ChangeEventType `json:"types,omitempty"`
+}
+
+func (cef *ChangeEventFilter) Matches(ce *ChangeEvent) bool {
+	if ce.Namespace != cef.Namespace {
+		return false
+	}
+	collectionMatch := len(cef.Collections) == 0
+	for _, c := range cef.Collections {
+		collectionMatch = collectionMatch || c == ce.Collection
+	}
+	typeMatch := len(cef.Types) == 0
+	for _, t := range cef.Types {
+		typeMatch = typeMatch || t == ce.Type
+	}
+	return collectionMatch && typeMatch
+}
@@ -0,0 +1,36 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeEventFilterMatches(t *testing.T) {
+	cef := &ChangeEventFilter{Namespace: "ns1"}
+	assert.True(t, cef.Matches(&ChangeEvent{Namespace: "ns1", Collection: "messages", Type: ChangeEventTypeCreated}))
+	assert.False(t, cef.Matches(&ChangeEvent{Namespace: "ns2", Collection: "messages", Type: ChangeEventTypeCreated}))
+	assert.False(t, cef.Matches(&ChangeEvent{Collection: "pins", Type: ChangeEventTypeCreated}))
+
+	cef.Collections = []string{"messages", "events"}
+	cef.Types = []ChangeEventType{ChangeEventTypeCreated}
+	assert.True(t, cef.Matches(&ChangeEvent{Namespace: "ns1", Collection: "events", Type: ChangeEventTypeCreated}))
+	assert.False(t, cef.Matches(&ChangeEvent{Namespace: "ns1", Collection: "data", Type: ChangeEventTypeCreated}))
+	assert.False(t, cef.Matches(&ChangeEvent{Namespace: "ns1", Collection: "messages", Type: ChangeEventTypeUpdated}))
+}
@@ -21,6 +21,8 @@ const (
 	ChartHistogramMaxBuckets = 100
 	// ChartHistogramMinBuckets min buckets that can be requested
 	ChartHistogramMinBuckets = 1
+	// ChartHistogramMaxGroups max distinct values of the groupBy field that can be counted in each bucket
+	ChartHistogramMaxGroups = 50
 )
 
 // ChartHistogram is a list of buckets with types
@@ -31,6 +33,8 @@ type ChartHistogram struct {
 	Timestamp *FFTime `json:"timestamp"`
 	// Types list of histogram types and their count
 	Types []*ChartHistogramType `json:"types"`
+	// Latencies list of latency percentiles of the items in the bucket
+	Latencies []*ChartHistogramLatency `json:"latencies,omitempty"`
 }
 
 // ChartHistogramType is a type and count
@@ -41,6 +45,14 @@ type ChartHistogramType struct {
 	Type string `json:"type"`
 }
 
+// ChartHistogramLatency is a percentile and latency
+type ChartHistogramLatency struct {
+	// Percentile of the items in the histogram bucket
+	Percentile float64 `json:"percentile"`
+	// Latency at or below which the percentile of items completed
+	Latency *FFDuration `json:"latency"`
+}
+
 // ChartHistogramInterval specifies lower and upper timestamps for histogram bucket
 type ChartHistogramInterval struct {
 	// StartTime start time of histogram interval
@@ -48,3 +60,13 @@ type ChartHistogramInterval struct {
 	// EndTime end time of histogram interval
 	EndTime *FFTime `json:"endTime"`
 }
+
+// ChartHistogramOptions specifies optional bucket sizes, grouping and latencies for a histogram
+type ChartHistogramOptions struct {
+	// Interval fixed length of each bucket, instead of a number of buckets
+	Interval FFDuration
+	// GroupBy field to count each bucket by, instead of the type
+	GroupBy string
+	// Percentiles latency percentiles to calculate for each bucket, instead of counting by type
+	Percentiles []float64
+}
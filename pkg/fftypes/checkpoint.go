@@ -0,0 +1,81 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import "crypto/sha256"
+
+// Checkpoint is a deterministic digest of the confirmed message stream of a namespace, up to a pin sequence.
+// Each batch contributes a merkle root over the hashes of its messages, in manifest order, and the root of
+// the checkpoint is the merkle root over the batch roots in the order the batches were pinned
+type Checkpoint struct {
+	Namespace string             `json:"namespace"`
+	Sequence  int64              `json:"sequence"`
+	Root      *Bytes32           `json:"root,omitempty"`
+	Messages  int                `json:"messages"`
+	Batches   []*CheckpointBatch `json:"batches"`
+}
+
+// CheckpointBatch is the digest of the messages in one pinned batch
+type CheckpointBatch struct {
+	ID       *UUID    `json:"id"`
+	Hash     *Bytes32 `json:"hash"`
+	Sequence int64    `json:"sequence"`
+	Messages int      `json:"messages"`
+	Root     *Bytes32 `json:"root"`
+}
+
+// CheckpointDiff is the result of comparing a checkpoint from another member, with the same number of batches
+// from the local message stream. Index is the position of the first batch that does not match, or -1
+type CheckpointDiff struct {
+	Match      bool             `json:"match"`
+	Batches    int              `json:"batches"`
+	LocalRoot  *Bytes32         `json:"localRoot,omitempty"`
+	RemoteRoot *Bytes32         `json:"remoteRoot,omitempty"`
+	Index      int              `json:"index"`
+	Local      *CheckpointBatch `json:"local,omitempty"`
+	Remote     *CheckpointBatch `json:"remote,omitempty"`
+}
+
+// MerkleRoot computes the root of a binary merkle tree over the supplied leaves, where each node is the
+// SHA256 hash of its two children. An odd node at the end of a level is promoted to the next level unchanged.
+// The root of a single leaf is the leaf itself. The root of no leaves, or of any set containing a nil leaf, is nil
+func MerkleRoot(leaves []*Bytes32) *Bytes32 {
+	if len(leaves) == 0 {
+		return nil
+	}
+	for _, leaf := range leaves {
+		if leaf == nil {
+			return nil
+		}
+	}
+	level := leaves
+	for len(level) > 1 {
+		next := make([]*Bytes32, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			hash := sha256.New()
+			hash.Write(level[i][:])
+			hash.Write(level[i+1][:])
+			next = append(next, HashResult(hash))
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,55 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hashPair(a, b *Bytes32) *Bytes32 {
+	var h Bytes32 = sha256.Sum256(append(append([]byte{}, a[:]...), b[:]...))
+	return &h
+}
+
+func TestMerkleRootEmpty(t *testing.T) {
+	assert.Nil(t, MerkleRoot(nil))
+}
+
+func TestMerkleRootSingle(t *testing.T) {
+	leaf := NewRandB32()
+	assert.Equal(t, leaf, MerkleRoot([]*Bytes32{leaf}))
+}
+
+func TestMerkleRootOdd(t *testing.T) {
+	l1, l2, l3 := NewRandB32(), NewRandB32(), NewRandB32()
+	expected := hashPair(hashPair(l1, l2), l3)
+	assert.Equal(t, expected, MerkleRoot([]*Bytes32{l1, l2, l3}))
+}
+
+func TestMerkleRootOrdered(t *testing.T) {
+	l1, l2, l3, l4 := NewRandB32(), NewRandB32(), NewRandB32(), NewRandB32()
+	expected := hashPair(hashPair(l1, l2), hashPair(l3, l4))
+	assert.Equal(t, expected, MerkleRoot([]*Bytes32{l1, l2, l3, l4}))
+	assert.NotEqual(t, expected, MerkleRoot([]*Bytes32{l2, l1, l3, l4}))
+}
+
+func TestMerkleRootNilLeaf(t *testing.T) {
+	assert.Nil(t, MerkleRoot([]*Bytes32{NewRandB32(), nil, NewRandB32()}))
+}
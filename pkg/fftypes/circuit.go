@@ -0,0 +1,41 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// CircuitState is the state of a circuit breaker protecting the outbound calls to a plugin
+type CircuitState = FFEnum
+
+var (
+	// CircuitStateClosed is the normal state, where all calls are attempted
+	CircuitStateClosed = ffEnum("circuitstate", "closed")
+	// CircuitStateOpen is when calls are rejected without being attempted, after consecutive failures
+	CircuitStateOpen = ffEnum("circuitstate", "open")
+	// CircuitStateHalfOpen is when a single probe call is allowed through, to determine whether to close the circuit again
+	CircuitStateHalfOpen = ffEnum("circuitstate", "halfopen")
+)
+
+// CircuitStatus is the current status of a circuit breaker
+type CircuitStatus struct {
+	ID                  *UUID        `json:"id"`
+	Name                string       `json:"name"`
+	State               CircuitState `json:"state" ffenum:"circuitstate"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	FailureThreshold    int          `json:"failureThreshold"`
+	LastError           string       `json:"lastError,omitempty"`
+	LastFailure         *FFTime      `json:"lastFailure,omitempty"`
+	Opened              *FFTime      `json:"opened,omitempty"`
+}
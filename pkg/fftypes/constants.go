@@ -20,6 +20,9 @@ const (
 
 	// SystemNamespace is the system reserved namespace name
 	SystemNamespace = "ff_system"
+
+	// IdentityProfileDatatypeName is the name of the datatype that, once broadcast to a namespace, identity profiles must conform to
+	IdentityProfileDatatypeName = "ff_identity_profile"
 )
 
 const (
@@ -27,10 +30,15 @@ const (
 	SystemTopicDefinitions = "ff_definition"
 	// SystemBatchPinTopic is the FireFly event topic for events from the FireFly batch pin listener
 	SystemBatchPinTopic = "ff_batch_pin"
+	// SystemContractDeployTopic is the FireFly event topic for events recording the address of a newly deployed contract
+	SystemContractDeployTopic = "ff_contract_deploy"
 )
 
 const (
 
+	// SystemTagPrefix is the prefix reserved for system definition tags - custom definition tags must not use it
+	SystemTagPrefix = "ff_"
+
 	// SystemTagDefineDatatype is the tag for messages that broadcast data definitions
 	SystemTagDefineDatatype = "ff_define_datatype"
 
@@ -63,4 +71,7 @@ const (
 
 	// SystemTagIdentityUpdate is the tag for messages that broadcast an identity update
 	SystemTagIdentityUpdate = "ff_identity_update"
+
+	// SystemTagIdentityPrivateProfile is the tag for private messages that share profile fields of an identity with selected members
+	SystemTagIdentityPrivateProfile = "ff_identity_private_profile"
 )
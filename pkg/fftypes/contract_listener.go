@@ -24,6 +24,16 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 )
 
+// ContractListenerState is the state of a contract listener
+type ContractListenerState = FFEnum
+
+var (
+	// ContractListenerStateActive is a listener that is delivering events
+	ContractListenerStateActive = ffEnum("contractlistenerstate", "active")
+	// ContractListenerStatePaused is a listener that has been suspended in the blockchain connector, retaining its checkpoint
+	ContractListenerStatePaused = ffEnum("contractlistenerstate", "paused")
+)
+
 type ContractListener struct {
 	ID         *UUID                    `json:"id,omitempty"`
 	Interface  *FFIReference            `json:"interface,omitempty"`
@@ -35,10 +45,29 @@ type ContractListener struct {
 	Event      *FFISerializedEvent      `json:"event,omitempty"`
 	Topic      string                   `json:"topic,omitempty"`
 	Options    *ContractListenerOptions `json:"options,omitempty"`
+	State      ContractListenerState    `json:"state,omitempty" ffenum:"contractlistenerstate"`
 }
 
 type ContractListenerOptions struct {
-	FirstEvent string `json:"firstEvent,omitempty"`
+	FirstEvent    string                      `json:"firstEvent,omitempty"`
+	Stream        string                      `json:"stream,omitempty"`
+	StoreRaw      bool                        `json:"storeRaw,omitempty"`
+	Confirmations uint64                      `json:"confirmations,omitempty"`
+	Checkpoint    *ContractListenerCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// ContractListenerCheckpoint is the position a contract listener has reached. It is exported from one listener,
+// and imported when creating another, so a listener can be moved to a different FireFly node without replaying
+// or missing events. The new listener starts from FirstEvent, and ignores any events up to and including ProtocolID.
+type ContractListenerCheckpoint struct {
+	Listener   *UUID  `json:"listener,omitempty"`
+	FirstEvent string `json:"firstEvent"`
+	ProtocolID string `json:"protocolId,omitempty"`
+}
+
+// ContractListenerUpdateDTO is the input to pause or resume a contract listener
+type ContractListenerUpdateDTO struct {
+	State ContractListenerState `json:"state" ffenum:"contractlistenerstate"`
 }
 
 type ContractListenerInput struct {
@@ -16,7 +16,13 @@
 
 package fftypes
 
-import "context"
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
 
 type ContractCallType = FFEnum
 
@@ -28,19 +34,32 @@ var (
 )
 
 type ContractCallRequest struct {
-	Type      ContractCallType       `json:"type,omitempty" ffenum:"contractcalltype"`
-	Interface *UUID                  `json:"interface,omitempty"`
-	Ledger    *JSONAny               `json:"ledger,omitempty"`
-	Location  *JSONAny               `json:"location,omitempty"`
-	Key       string                 `json:"key,omitempty"`
-	Method    *FFIMethod             `json:"method,omitempty"`
-	Input     map[string]interface{} `json:"input"`
+	Type        ContractCallType       `json:"type,omitempty" ffenum:"contractcalltype"`
+	Interface   *UUID                  `json:"interface,omitempty"`
+	Ledger      *JSONAny               `json:"ledger,omitempty"`
+	Location    *JSONAny               `json:"location,omitempty"`
+	Key         string                 `json:"key,omitempty"`
+	Method      *FFIMethod             `json:"method,omitempty"`
+	Errors      []*FFIErrorDefinition  `json:"errors,omitempty"`
+	Input       map[string]interface{} `json:"input"`
+	CallbackURL string                 `json:"callbackUrl,omitempty"`
+	BusinessKey string                 `json:"businessKey,omitempty"`
 }
 
 type ContractCallResponse struct {
 	ID *UUID `json:"id"`
 }
 
+// ContractDeployRequest deploys a new smart contract. The constructor is described either by a blockchain
+// specific definition (such as an Ethereum ABI), or by a method named "constructor" in the referenced interface
+type ContractDeployRequest struct {
+	Key        string        `json:"key,omitempty"`
+	Interface  *UUID         `json:"interface,omitempty"`
+	Definition *JSONAny      `json:"definition,omitempty"`
+	Contract   *JSONAny      `json:"contract,omitempty"`
+	Input      []interface{} `json:"input"`
+}
+
 type ContractSubscribeRequest struct {
 	Interface *UUID     `json:"interface,omitempty"`
 	Location  *JSONAny  `json:"location,omitempty"`
@@ -53,14 +72,112 @@ type ContractURLs struct {
 }
 
 type ContractAPI struct {
-	ID        *UUID         `json:"id,omitempty"`
-	Namespace string        `json:"namespace,omitempty"`
-	Interface *FFIReference `json:"interface"`
-	Ledger    *JSONAny      `json:"ledger,omitempty"`
-	Location  *JSONAny      `json:"location,omitempty"`
-	Name      string        `json:"name"`
-	Message   *UUID         `json:"message,omitempty"`
-	URLs      ContractURLs  `json:"urls"`
+	ID          *UUID                  `json:"id,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Interface   *FFIReference          `json:"interface"`
+	Ledger      *JSONAny               `json:"ledger,omitempty"`
+	Location    *JSONAny               `json:"location,omitempty"`
+	Name        string                 `json:"name"`
+	Message     *UUID                  `json:"message,omitempty"`
+	URLs        ContractURLs           `json:"urls"`
+	QueryCache  *ContractAPIQueryCache `json:"queryCache,omitempty"`
+	Transforms  ContractAPITransforms  `json:"transforms,omitempty"`
+	Annotations Annotations            `json:"annotations,omitempty"`
+}
+
+// ContractAPIQueryCache configures read-through caching of query results for a contract API
+type ContractAPIQueryCache struct {
+	Enabled bool        `json:"enabled"`
+	TTL     *FFDuration `json:"ttl,omitempty"`
+}
+
+// Scan implements sql.Scanner
+func (qc *ContractAPIQueryCache) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &qc)
+	case []byte:
+		return json.Unmarshal(src, &qc)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, qc)
+	}
+}
+
+func (qc ContractAPIQueryCache) Value() (driver.Value, error) {
+	bytes, _ := json.Marshal(qc)
+	return bytes, nil
+}
+
+type ContractInputTransformType = FFEnum
+
+var (
+	// ContractInputTransformTypeDecimal converts a decimal amount into an integer, by shifting it the configured number of decimal places
+	ContractInputTransformTypeDecimal ContractInputTransformType = ffEnum("inputtransformtype", "decimal")
+	// ContractInputTransformTypeTimestamp converts an RFC3339 date/time string into unix seconds
+	ContractInputTransformTypeTimestamp ContractInputTransformType = ffEnum("inputtransformtype", "timestamp")
+	// ContractInputTransformTypeTemplate sets the input to the result of a Go template, executed against the request input
+	ContractInputTransformTypeTemplate ContractInputTransformType = ffEnum("inputtransformtype", "template")
+)
+
+// ContractInputTransform converts a friendly value supplied on a contract API request into the
+// chain-native value expected by the interface, before the input is validated against the FFI
+type ContractInputTransform struct {
+	Type     ContractInputTransformType `json:"type" ffenum:"inputtransformtype"`
+	Decimals int                        `json:"decimals,omitempty"`
+	Template string                     `json:"template,omitempty"`
+}
+
+// ContractAPITransforms maps method pathnames to the transforms applied to each named input of that method
+type ContractAPITransforms map[string]map[string]*ContractInputTransform
+
+// Scan implements sql.Scanner
+func (t *ContractAPITransforms) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &t)
+	case []byte:
+		return json.Unmarshal(src, &t)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, t)
+	}
+}
+
+func (t ContractAPITransforms) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	bytes, _ := json.Marshal(t)
+	return bytes, nil
+}
+
+// ContractAPIWithListeners is a contract API, along with listener templates that are created
+// locally on this node when the API is registered. The templates are not broadcast.
+type ContractAPIWithListeners struct {
+	ContractAPI
+	Listeners []*ContractAPIListenerTemplate `json:"listeners,omitempty"`
+}
+
+// ContractAPIListenerTemplate declares a contract listener on an event of the API's interface,
+// and optionally a durable subscription that delivers the events from that listener
+type ContractAPIListenerTemplate struct {
+	Name         string                           `json:"name,omitempty"`
+	Event        string                           `json:"event"`
+	Topic        string                           `json:"topic,omitempty"`
+	Options      *ContractListenerOptions         `json:"options,omitempty"`
+	Subscription *ContractAPISubscriptionTemplate `json:"subscription,omitempty"`
+	Listener     *UUID                            `json:"listener,omitempty"`
+}
+
+// ContractAPISubscriptionTemplate declares the subscription created for a listener template
+type ContractAPISubscriptionTemplate struct {
+	ID        *UUID               `json:"id,omitempty"`
+	Name      string              `json:"name"`
+	Transport string              `json:"transport"`
+	Options   SubscriptionOptions `json:"options"`
 }
 
 func (c *ContractAPI) Validate(ctx context.Context, existing bool) (err error) {
@@ -70,7 +187,7 @@ func (c *ContractAPI) Validate(ctx context.Context, existing bool) (err error) {
 	if err = ValidateFFNameField(ctx, c.Name, "name"); err != nil {
 		return err
 	}
-	return nil
+	return c.Annotations.Validate(ctx)
 }
 
 func (c *ContractAPI) Topic() string {
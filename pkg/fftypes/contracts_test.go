@@ -46,6 +46,14 @@ func TestValidateInvalidContractAPI(t *testing.T) {
 	}
 	err = api.Validate(context.Background(), false)
 	assert.Regexp(t, "FF10131", err)
+
+	api = &ContractAPI{
+		Namespace:   "ns1",
+		Name:        "banana",
+		Annotations: Annotations{"!wrong": "ok"},
+	}
+	err = api.Validate(context.Background(), false)
+	assert.Regexp(t, "FF10131.*annotations", err)
 }
 
 func TestContractAPITopic(t *testing.T) {
@@ -115,3 +123,82 @@ func TestLocationAndLedgerEquals(t *testing.T) {
 	}
 	assert.True(t, c1.LocationAndLedgerEquals(c2))
 }
+
+func TestContractAPIQueryCacheScan(t *testing.T) {
+	qc := &ContractAPIQueryCache{}
+	err := qc.Scan([]byte(`{"enabled":true,"ttl":"10s"}`))
+	assert.NoError(t, err)
+	assert.True(t, qc.Enabled)
+	assert.Equal(t, "10s", qc.TTL.String())
+}
+
+func TestContractAPIQueryCacheScanNil(t *testing.T) {
+	qc := &ContractAPIQueryCache{}
+	err := qc.Scan(nil)
+	assert.NoError(t, err)
+}
+
+func TestContractAPIQueryCacheScanString(t *testing.T) {
+	qc := &ContractAPIQueryCache{}
+	err := qc.Scan(`{"enabled":true}`)
+	assert.NoError(t, err)
+	assert.True(t, qc.Enabled)
+}
+
+func TestContractAPIQueryCacheScanError(t *testing.T) {
+	qc := &ContractAPIQueryCache{}
+	err := qc.Scan(false)
+	assert.Regexp(t, "FF10125", err)
+}
+
+func TestContractAPIQueryCacheValue(t *testing.T) {
+	qc := &ContractAPIQueryCache{
+		Enabled: true,
+	}
+	val, err := qc.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"enabled":true}`, string(val.([]byte)))
+}
+
+func TestContractAPITransformsScan(t *testing.T) {
+	var ts ContractAPITransforms
+	err := ts.Scan([]byte(`{"transfer":{"amount":{"type":"decimal","decimals":18}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, ContractInputTransformTypeDecimal, ts["transfer"]["amount"].Type)
+	assert.Equal(t, 18, ts["transfer"]["amount"].Decimals)
+}
+
+func TestContractAPITransformsScanNil(t *testing.T) {
+	var ts ContractAPITransforms
+	err := ts.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, ts)
+}
+
+func TestContractAPITransformsScanString(t *testing.T) {
+	var ts ContractAPITransforms
+	err := ts.Scan(`{"set":{"expiry":{"type":"timestamp"}}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ContractInputTransformTypeTimestamp, ts["set"]["expiry"].Type)
+}
+
+func TestContractAPITransformsScanError(t *testing.T) {
+	var ts ContractAPITransforms
+	err := ts.Scan(false)
+	assert.Regexp(t, "FF10125", err)
+}
+
+func TestContractAPITransformsValue(t *testing.T) {
+	ts := ContractAPITransforms{
+		"set": {
+			"name": {Type: ContractInputTransformTypeTemplate, Template: "{{.first}}"},
+		},
+	}
+	val, err := ts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"set":{"name":{"type":"template","template":"{{.first}}"}}}`, string(val.([]byte)))
+
+	val, err = ContractAPITransforms(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// CustomDefinition is a definition broadcast with an application specific tag, which is processed in-line in the
+// aggregator of every node, by the custom definition handler registered for that tag
+type CustomDefinition struct {
+	Message   *UUID    `json:"message,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Tag       string   `json:"tag"`
+	Value     *JSONAny `json:"value,omitempty"`
+}
+
+// ValidateCustomDefinitionTag checks a tag can be used for custom definitions - it must be valid in a message header,
+// and must not use the prefix reserved for system definitions
+func ValidateCustomDefinitionTag(ctx context.Context, tag string) error {
+	if err := ValidateTagField(ctx, tag, "tag"); err != nil {
+		return err
+	}
+	if strings.HasPrefix(tag, SystemTagPrefix) {
+		return i18n.NewError(ctx, i18n.MsgDefinitionTagReserved, tag, SystemTagPrefix)
+	}
+	return nil
+}
+
+func (cd *CustomDefinition) Validate(ctx context.Context) (err error) {
+	if err = ValidateFFNameField(ctx, cd.Namespace, "namespace"); err != nil {
+		return err
+	}
+	if err = ValidateCustomDefinitionTag(ctx, cd.Tag); err != nil {
+		return err
+	}
+	if cd.Value == nil || len(*cd.Value) == 0 {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "value")
+	}
+	return nil
+}
+
+// Topic orders all the definitions with the same tag in a namespace, so they are confirmed in the same order on every node
+func (cd *CustomDefinition) Topic() string {
+	return typeNamespaceNameTopicHash("custom", cd.Namespace, cd.Tag)
+}
+
+func (cd *CustomDefinition) SetBroadcastMessage(msgID *UUID) {
+	cd.Message = msgID
+}
@@ -0,0 +1,51 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomDefinitionValidation(t *testing.T) {
+	cd := &CustomDefinition{
+		Namespace: "!wrong",
+	}
+	assert.Regexp(t, "FF10131.*namespace", cd.Validate(context.Background()))
+
+	cd.Namespace = "ns1"
+	cd.Tag = "myapp:config:v1"
+	assert.Regexp(t, "FF10131.*tag", cd.Validate(context.Background()))
+
+	cd.Tag = SystemTagDefineDatatype
+	assert.Regexp(t, "FF10384", cd.Validate(context.Background()))
+
+	cd.Tag = "myapp:config"
+	assert.Regexp(t, "FF10140.*value", cd.Validate(context.Background()))
+
+	cd.Value = JSONAnyPtr(`{"some": "config"}`)
+	assert.NoError(t, cd.Validate(context.Background()))
+
+	var def Definition = cd
+	assert.Equal(t, cd.Topic(), def.Topic())
+	assert.NotEqual(t, cd.Topic(), (&CustomDefinition{Namespace: "ns1", Tag: "myapp:other"}).Topic())
+	id := NewUUID()
+	def.SetBroadcastMessage(id)
+	assert.Equal(t, id, cd.Message)
+}
@@ -33,10 +33,11 @@ type DataRef struct {
 }
 
 type BlobRef struct {
-	Hash   *Bytes32 `json:"hash"`
-	Size   int64    `json:"size"`
-	Name   string   `json:"name"`
-	Public string   `json:"public,omitempty"`
+	Hash          *Bytes32      `json:"hash"`
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm,omitempty" ffenum:"hashalgorithm"`
+	Size          int64         `json:"size"`
+	Name          string        `json:"name"`
+	Public        string        `json:"public,omitempty"`
 }
 
 type Data struct {
@@ -47,6 +48,8 @@ type Data struct {
 	Created   *FFTime       `json:"created,omitempty"`
 	Datatype  *DatatypeRef  `json:"datatype,omitempty"`
 	Value     *JSONAny      `json:"value"`
+	MediaType string        `json:"mediaType,omitempty"`
+	Original  []byte        `json:"original,omitempty"`
 	Blob      *BlobRef      `json:"blob,omitempty"`
 
 	ValueSize int64 `json:"-"` // Used internally for message size calcuation, without full payload retrieval
@@ -61,9 +64,10 @@ func (br *BlobRef) BatchBlobRef(batchType BatchType) *BlobRef {
 		// For private we omit the "public" ref in all cases, to avoid an potential for the batch pay to change due
 		// to the same data being allocated by the same data being sent in a broadcast batch (thus assigining a public ref).
 		return &BlobRef{
-			Hash: br.Hash,
-			Size: br.Size,
-			Name: br.Name,
+			Hash:          br.Hash,
+			HashAlgorithm: br.HashAlgorithm,
+			Size:          br.Size,
+			Name:          br.Name,
 		}
 	default:
 		// For broadcast data the blob reference contains the "public" (shared storage) reference, which
@@ -83,6 +87,8 @@ func (d *Data) BatchData(batchType BatchType) *Data {
 		Created:   d.Created,
 		Datatype:  d.Datatype,
 		Value:     d.Value,
+		MediaType: d.MediaType,
+		Original:  d.Original,
 		Blob:      d.Blob.BatchBlobRef(batchType),
 
 		ValueSize: d.ValueSize,
@@ -130,7 +136,7 @@ func (da DataArray) Refs() DataRefs {
 
 func CheckValidatorType(ctx context.Context, validator ValidatorType) error {
 	switch validator {
-	case ValidatorTypeJSON, ValidatorTypeNone, ValidatorTypeSystemDefinition:
+	case ValidatorTypeJSON, ValidatorTypeXSD, ValidatorTypeNone, ValidatorTypeSystemDefinition:
 		return nil
 	default:
 		return i18n.NewError(ctx, i18n.MsgUnknownValidatorType, validator)
@@ -142,13 +148,33 @@ const dataSizeEstimateBase = int64(256)
 func (d *Data) EstimateSize() int64 {
 	// For now we have a static estimate for the size of the serialized outer structure.
 	if d.ValueSize <= 0 {
-		d.ValueSize = d.Value.Length()
+		d.ValueSize = d.Value.Length() + int64(len(d.Original))
 	}
 	// As long as this has been persisted, the value size will represent the length
 	return dataSizeEstimateBase + d.ValueSize
 }
 
+// checkOriginal verifies the value is the canonical JSON form of the original payload, for data
+// supplied in another media type, as only the value contributes to the hash
+func (d *Data) checkOriginal(ctx context.Context) error {
+	hasOriginal, err := IsOriginalMediaType(ctx, d.MediaType)
+	if err != nil || (!hasOriginal && d.Original == nil) {
+		return err
+	}
+	canonical, err := CanonicalJSON(ctx, d.MediaType, d.Original)
+	if err != nil {
+		return err
+	}
+	if d.Value.String() != canonical.String() {
+		return i18n.NewError(ctx, i18n.MsgDataOriginalMismatch, d.MediaType)
+	}
+	return nil
+}
+
 func (d *Data) CalcHash(ctx context.Context) (*Bytes32, error) {
+	if err := d.checkOriginal(ctx); err != nil {
+		return nil, err
+	}
 	if d.Value == nil {
 		d.Value = JSONAnyPtr(NullString)
 	}
@@ -206,7 +232,7 @@ func (d *Data) Seal(ctx context.Context, blob *Blob) (err error) {
 		return i18n.NewError(ctx, i18n.MsgBlobMismatchSealingData)
 	}
 	if d.ValueSize <= 0 {
-		d.ValueSize = d.Value.Length()
+		d.ValueSize = d.Value.Length() + int64(len(d.Original))
 	}
 	d.Hash, err = d.CalcHash(ctx)
 	if err == nil {
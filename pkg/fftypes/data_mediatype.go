@@ -0,0 +1,179 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+const (
+	// DataMediaTypeJSON is the default media type of data values
+	DataMediaTypeJSON = "application/json"
+	// DataMediaTypeXML is an XML document, supplied on the API as a JSON string
+	DataMediaTypeXML = "application/xml"
+	// DataMediaTypeCBOR is a CBOR encoded value, supplied on the API as a base64 encoded JSON string
+	DataMediaTypeCBOR = "application/cbor"
+)
+
+// IsOriginalMediaType returns true for the media types where the original payload is stored
+// alongside the canonical JSON value, and false for JSON
+func IsOriginalMediaType(ctx context.Context, mediaType string) (bool, error) {
+	switch mediaType {
+	case "", DataMediaTypeJSON:
+		return false, nil
+	case DataMediaTypeXML, DataMediaTypeCBOR:
+		return true, nil
+	default:
+		return false, i18n.NewError(ctx, i18n.MsgDataMediaTypeUnsupported, mediaType)
+	}
+}
+
+// DecodeOriginalValue extracts the original payload bytes from the JSON string value supplied on the API
+func DecodeOriginalValue(ctx context.Context, mediaType string, value *JSONAny) ([]byte, error) {
+	var s string
+	if value == nil || json.Unmarshal(value.Bytes(), &s) != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDataEncodedValueNotString, mediaType)
+	}
+	if mediaType != DataMediaTypeCBOR {
+		return []byte(s), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDataDecodeFailed, mediaType, err)
+	}
+	return b, nil
+}
+
+// CanonicalJSON converts an original XML or CBOR payload to the canonical JSON form, which is used for
+// validation and hashing. The conversion is deterministic, so every member of the network can verify it.
+func CanonicalJSON(ctx context.Context, mediaType string, original []byte) (*JSONAny, error) {
+	var v interface{}
+	var err error
+	switch mediaType {
+	case DataMediaTypeXML:
+		v, err = decodeXML(original)
+	case DataMediaTypeCBOR:
+		v, err = decodeCBOR(original)
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgDataMediaTypeUnsupported, mediaType)
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgDataDecodeFailed, mediaType, err)
+	}
+	b, _ := json.Marshal(v)
+	return JSONAnyPtrBytes(b), nil
+}
+
+// xmlElement is built while parsing XML, to produce the JSON form. All values are strings,
+// as XML carries no type information without a schema.
+// An element with only text content is a string. Otherwise it is an object, with attributes as "@name" keys,
+// child elements keyed by name (with an array if the name is repeated), and any text as a "#text" key.
+type xmlElement struct {
+	name     string
+	attrs    map[string]interface{}
+	children map[string]interface{}
+	text     strings.Builder
+}
+
+func (e *xmlElement) addChild(name string, value interface{}) {
+	existing, ok := e.children[name]
+	if !ok {
+		e.children[name] = value
+	} else if arr, isArray := existing.([]interface{}); isArray {
+		e.children[name] = append(arr, value)
+	} else {
+		e.children[name] = []interface{}{existing, value}
+	}
+}
+
+func (e *xmlElement) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.attrs) == 0 && len(e.children) == 0 {
+		return text
+	}
+	obj := make(map[string]interface{}, len(e.attrs)+len(e.children)+1)
+	for k, v := range e.attrs {
+		obj[k] = v
+	}
+	for k, v := range e.children {
+		obj[k] = v
+	}
+	if text != "" {
+		obj["#text"] = text
+	}
+	return obj
+}
+
+func decodeXML(original []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(original))
+	var stack []*xmlElement
+	var root map[string]interface{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if root != nil {
+				return nil, fmt.Errorf("multiple root elements")
+			}
+			e := &xmlElement{
+				name:     t.Name.Local,
+				attrs:    make(map[string]interface{}),
+				children: make(map[string]interface{}),
+			}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				e.attrs["@"+a.Name.Local] = a.Value
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root = map[string]interface{}{e.name: e.value()}
+			} else {
+				stack[len(stack)-1].addChild(e.name, e.value())
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("text outside of root element")
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
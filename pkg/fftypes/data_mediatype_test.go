@@ -0,0 +1,117 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOriginalMediaType(t *testing.T) {
+	ctx := context.Background()
+	for _, mt := range []string{"", DataMediaTypeJSON} {
+		hasOriginal, err := IsOriginalMediaType(ctx, mt)
+		assert.NoError(t, err)
+		assert.False(t, hasOriginal)
+	}
+	for _, mt := range []string{DataMediaTypeXML, DataMediaTypeCBOR} {
+		hasOriginal, err := IsOriginalMediaType(ctx, mt)
+		assert.NoError(t, err)
+		assert.True(t, hasOriginal)
+	}
+	_, err := IsOriginalMediaType(ctx, "text/plain")
+	assert.Regexp(t, "FF10393", err)
+}
+
+func TestDecodeOriginalValue(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := DecodeOriginalValue(ctx, DataMediaTypeXML, JSONAnyPtr(`"<a>1</a>"`))
+	assert.NoError(t, err)
+	assert.Equal(t, "<a>1</a>", string(b))
+
+	b, err = DecodeOriginalValue(ctx, DataMediaTypeCBOR, JSONAnyPtr(`"gwECAw=="`))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x83, 0x01, 0x02, 0x03}, b)
+
+	_, err = DecodeOriginalValue(ctx, DataMediaTypeCBOR, JSONAnyPtr(`"!!!"`))
+	assert.Regexp(t, "FF10394", err)
+
+	_, err = DecodeOriginalValue(ctx, DataMediaTypeXML, JSONAnyPtr(`{"a":1}`))
+	assert.Regexp(t, "FF10396", err)
+
+	_, err = DecodeOriginalValue(ctx, DataMediaTypeXML, nil)
+	assert.Regexp(t, "FF10396", err)
+}
+
+func TestCanonicalJSONCBOR(t *testing.T) {
+	v, err := CanonicalJSON(context.Background(), DataMediaTypeCBOR, []byte{0xa2, 0x61, 0x62, 0x01, 0x61, 0x61, 0x82, 0x02, 0x03})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":[2,3],"b":1}`, v.String())
+
+	_, err = CanonicalJSON(context.Background(), DataMediaTypeCBOR, []byte{0xff})
+	assert.Regexp(t, "FF10394", err)
+}
+
+func TestCanonicalJSONXML(t *testing.T) {
+	v, err := CanonicalJSON(context.Background(), DataMediaTypeXML, []byte(`<?xml version="1.0"?>
+		<!-- an order -->
+		<order xmlns="urn:example" xmlns:x="urn:x" id="123">
+			<item sku="a1">widget</item>
+			<item sku="b2">gadget</item>
+			<note>hello <b>world</b></note>
+			<empty/>
+			<total>10.50</total>
+		</order>`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"order": {
+			"@id": "123",
+			"item": [
+				{"@sku": "a1", "#text": "widget"},
+				{"@sku": "b2", "#text": "gadget"}
+			],
+			"note": {"#text": "hello", "b": "world"},
+			"empty": "",
+			"total": "10.50"
+		}
+	}`, v.String())
+	assert.NotContains(t, v.String(), "\n")
+}
+
+func TestCanonicalJSONXMLThreeRepeats(t *testing.T) {
+	v, err := CanonicalJSON(context.Background(), DataMediaTypeXML, []byte(`<a><b>1</b><b>2</b><b>3</b></a>`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":{"b":["1","2","3"]}}`, v.String())
+}
+
+func TestCanonicalJSONXMLErrors(t *testing.T) {
+	ctx := context.Background()
+	for in, expected := range map[string]string{
+		`<a>`:         "FF10394.*EOF",
+		`<a></a><b/>`: "FF10394.*multiple root",
+		`text<a/>`:    "FF10394.*text outside",
+		``:            "FF10394.*no root",
+	} {
+		_, err := CanonicalJSON(ctx, DataMediaTypeXML, []byte(in))
+		assert.Regexp(t, expected, err, in)
+	}
+	_, err := CanonicalJSON(ctx, "text/plain", []byte("a"))
+	assert.Regexp(t, "FF10393", err)
+}
@@ -69,6 +69,56 @@ func TestSealValueOnly(t *testing.T) {
 	assert.Equal(t, d.Hash.String(), "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a")
 }
 
+func TestSealOriginalXML(t *testing.T) {
+	d := &Data{
+		Value:     JSONAnyPtr(`{"a":"1"}`),
+		MediaType: DataMediaTypeXML,
+		Original:  []byte("<a>1</a>"),
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, JSONAnyPtr(`{"a":"1"}`).Hash(), d.Hash)
+	assert.Equal(t, int64(len(`{"a":"1"}`)+len("<a>1</a>")), d.ValueSize)
+}
+
+func TestSealOriginalMismatch(t *testing.T) {
+	d := &Data{
+		Value:     JSONAnyPtr(`{"a":"2"}`),
+		MediaType: DataMediaTypeXML,
+		Original:  []byte("<a>1</a>"),
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.Regexp(t, "FF10395", err)
+}
+
+func TestSealOriginalBadPayload(t *testing.T) {
+	d := &Data{
+		Value:     JSONAnyPtr(`{"a":"1"}`),
+		MediaType: DataMediaTypeXML,
+		Original:  []byte("<a>"),
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.Regexp(t, "FF10394", err)
+}
+
+func TestSealOriginalWithoutMediaType(t *testing.T) {
+	d := &Data{
+		Value:    JSONAnyPtr(`{"a":"1"}`),
+		Original: []byte("<a>1</a>"),
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.Regexp(t, "FF10393", err)
+}
+
+func TestSealUnknownMediaType(t *testing.T) {
+	d := &Data{
+		Value:     JSONAnyPtr(`{"a":"1"}`),
+		MediaType: "text/plain",
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.Regexp(t, "FF10393", err)
+}
+
 func TestSealBlobOnly(t *testing.T) {
 	blobHash, _ := ParseBytes32(context.Background(), "22440fcf4ee9ac8c1a83de36c3a9ef39f838d960971dc79b274718392f1735f9")
 	d := &Data{
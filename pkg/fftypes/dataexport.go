@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// DataExportFormat is the file format written by a data export
+type DataExportFormat string
+
+const (
+	// DataExportFormatCSV is a header row naming the columns, followed by one record per row
+	DataExportFormatCSV DataExportFormat = "csv"
+	// DataExportFormatParquet is an uncompressed Apache Parquet file, with a nullable column for each field
+	DataExportFormatParquet DataExportFormat = "parquet"
+)
+
+// DataExportCollection is a type of record that can be exported
+type DataExportCollection string
+
+const (
+	// DataExportCollectionEvents is the events that were dispatched to applications
+	DataExportCollectionEvents DataExportCollection = "events"
+	// DataExportCollectionMessages is the headers and state of messages, without their data
+	DataExportCollectionMessages DataExportCollection = "messages"
+	// DataExportCollectionBlockchainEvents is the events received from the blockchain
+	DataExportCollectionBlockchainEvents DataExportCollection = "blockchainevents"
+	// DataExportCollectionTokenTransfers is the token mints, burns and transfers
+	DataExportCollectionTokenTransfers DataExportCollection = "tokentransfers"
+)
+
+// DataExportDestination is where the files written by a data export are stored
+type DataExportDestination string
+
+const (
+	// DataExportDestinationLocal writes files to the export directory on the local disk of the node
+	DataExportDestinationLocal DataExportDestination = "local"
+	// DataExportDestinationSharedStorage uploads files to the shared storage plugin
+	DataExportDestinationSharedStorage DataExportDestination = "sharedstorage"
+)
+
+// DataExportRequest selects the records to export, and how to write them
+type DataExportRequest struct {
+	Collections []DataExportCollection `json:"collections,omitempty"`
+	Format      DataExportFormat       `json:"format,omitempty"`
+	Destination DataExportDestination  `json:"destination,omitempty"`
+	Since       *FFTime                `json:"since,omitempty"`
+	Until       *FFTime                `json:"until,omitempty"`
+}
+
+// DataExportStatus is the progress of a data export, reported in the output of its operation
+type DataExportStatus struct {
+	Collections []*DataExportCollectionStatus `json:"collections"`
+}
+
+// DataExportCollectionStatus is the progress of exporting one collection to a file. The location is
+// the path of the file for a local export, or the payload reference for a shared storage export.
+type DataExportCollectionStatus struct {
+	Collection DataExportCollection `json:"collection"`
+	Rows       int64                `json:"rows"`
+	Complete   bool                 `json:"complete"`
+	Location   string               `json:"location,omitempty"`
+}
@@ -27,6 +27,8 @@ type ValidatorType = FFEnum
 var (
 	// ValidatorTypeJSON is the validator type for JSON Schema validation
 	ValidatorTypeJSON = ffEnum("validatortype", "json")
+	// ValidatorTypeXSD is the validator type for XML Schema validation of the original payload of XML data
+	ValidatorTypeXSD = ffEnum("validatortype", "xsd")
 	// ValidatorTypeNone explicitly disables validation, even when a datatype is set. Allowing categorization of datatype without validation.
 	ValidatorTypeNone = ffEnum("validatortype", "none")
 	// ValidatorTypeSystemDefinition is the validator type for system definitions
@@ -35,19 +37,21 @@ var (
 
 // Datatype is the structure defining a data definition, such as a JSON schema
 type Datatype struct {
-	ID        *UUID         `json:"id,omitempty"`
-	Message   *UUID         `json:"message,omitempty"`
-	Validator ValidatorType `json:"validator" ffenum:"validatortype"`
-	Namespace string        `json:"namespace,omitempty"`
-	Name      string        `json:"name,omitempty"`
-	Version   string        `json:"version,omitempty"`
-	Hash      *Bytes32      `json:"hash,omitempty"`
-	Created   *FFTime       `json:"created,omitempty"`
-	Value     *JSONAny      `json:"value,omitempty"`
+	ID          *UUID         `json:"id,omitempty"`
+	Message     *UUID         `json:"message,omitempty"`
+	Validator   ValidatorType `json:"validator" ffenum:"validatortype"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	Version     string        `json:"version,omitempty"`
+	Hash        *Bytes32      `json:"hash,omitempty"`
+	Created     *FFTime       `json:"created,omitempty"`
+	Value       *JSONAny      `json:"value,omitempty"`
+	Indexes     FFStringArray `json:"indexes,omitempty"`
+	Annotations Annotations   `json:"annotations,omitempty"`
 }
 
 func (dt *Datatype) Validate(ctx context.Context, existing bool) (err error) {
-	if dt.Validator != ValidatorTypeJSON {
+	if dt.Validator != ValidatorTypeJSON && dt.Validator != ValidatorTypeXSD {
 		return i18n.NewError(ctx, i18n.MsgUnknownFieldValue, "validator", dt.Validator)
 	}
 	if err = ValidateFFNameField(ctx, dt.Namespace, "namespace"); err != nil {
@@ -62,6 +66,12 @@ func (dt *Datatype) Validate(ctx context.Context, existing bool) (err error) {
 	if dt.Value == nil || len(*dt.Value) == 0 {
 		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "value")
 	}
+	if err = dt.Indexes.Validate(ctx, "indexes", true, FFStringNameItemsMax); err != nil {
+		return err
+	}
+	if err = dt.Annotations.Validate(ctx); err != nil {
+		return err
+	}
 	if existing {
 		if dt.ID == nil {
 			return i18n.NewError(ctx, i18n.MsgNilID)
@@ -68,6 +68,21 @@ func TestDatatypeValidation(t *testing.T) {
 	}
 	assert.NoError(t, dt.Validate(context.Background(), false))
 
+	dt.Validator = ValidatorTypeXSD
+	dt.Value = JSONAnyPtr(`"<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\"/>"`)
+	assert.NoError(t, dt.Validate(context.Background(), false))
+	dt.Validator = ValidatorTypeJSON
+
+	dt.Indexes = FFStringArray{"order.id", "!wrong"}
+	assert.Regexp(t, "FF10131.*indexes\\[1\\]", dt.Validate(context.Background(), false))
+	dt.Indexes = FFStringArray{"order.id", "customer"}
+	assert.NoError(t, dt.Validate(context.Background(), false))
+
+	dt.Annotations = Annotations{"!wrong": "ok"}
+	assert.Regexp(t, "FF10131.*annotations", dt.Validate(context.Background(), false))
+	dt.Annotations = Annotations{"owner": "team-a"}
+	assert.NoError(t, dt.Validate(context.Background(), false))
+
 	assert.Regexp(t, "FF10203", dt.Validate(context.Background(), true))
 
 	dt.ID = NewUUID()
@@ -51,24 +51,44 @@ var (
 	EventTypeContractAPIConfirmed = ffEnum("eventtype", "contract_api_confirmed")
 	// EventTypeBlockchainEventReceived occurs when a new event has been received from the blockchain
 	EventTypeBlockchainEventReceived = ffEnum("eventtype", "blockchain_event_received")
+	// EventTypeCircuitOpened occurs when calls to a plugin are suspended, after consecutive failures
+	EventTypeCircuitOpened = ffEnum("eventtype", "circuit_opened")
+	// EventTypeCircuitClosed occurs when calls to a plugin resume, after a successful probe of an open circuit
+	EventTypeCircuitClosed = ffEnum("eventtype", "circuit_closed")
+	// EventTypeEventLoopStalled occurs when an event loop, such as the aggregator, has made no progress with events waiting for longer than the watchdog stall timeout
+	EventTypeEventLoopStalled = ffEnum("eventtype", "event_loop_stalled")
+	// EventTypeApprovalRequestPending occurs when a request is held, until it has been approved by the configured approvers
+	EventTypeApprovalRequestPending = ffEnum("eventtype", "approval_request_pending")
+	// EventTypeApprovalRequestApproved occurs when a request has received the required approvals, and is about to be submitted
+	EventTypeApprovalRequestApproved = ffEnum("eventtype", "approval_request_approved")
+	// EventTypeApprovalRequestRejected occurs when a request is rejected by one of the approvers
+	EventTypeApprovalRequestRejected = ffEnum("eventtype", "approval_request_rejected")
+	// EventTypeApprovalRequestFailed occurs when an approved request could not be submitted
+	EventTypeApprovalRequestFailed = ffEnum("eventtype", "approval_request_failed")
+	// EventTypePrivateDataRefused occurs when private data is not sent to, or accepted from, an org that the namespace is not allowed to exchange private data with
+	EventTypePrivateDataRefused = ffEnum("eventtype", "private_data_refused")
+	// EventTypeProtocolIncompatible occurs when a batch is dispatched, for each node whose network protocol version cannot be determined
+	EventTypeProtocolIncompatible = ffEnum("eventtype", "protocol_incompatible")
 )
 
 // Event is an activity in the system, delivered reliably to applications, that indicates something has happened in the network
 type Event struct {
-	ID          *UUID     `json:"id"`
-	Sequence    int64     `json:"sequence"`
-	Type        EventType `json:"type" ffenum:"eventtype"`
-	Namespace   string    `json:"namespace"`
-	Reference   *UUID     `json:"reference"`
-	Correlator  *UUID     `json:"correlator,omitempty"`
-	Transaction *UUID     `json:"tx,omitempty"`
-	Topic       string    `json:"topic,omitempty"`
-	Created     *FFTime   `json:"created"`
+	ID            *UUID     `json:"id"`
+	Sequence      int64     `json:"sequence"`
+	Type          EventType `json:"type" ffenum:"eventtype"`
+	Namespace     string    `json:"namespace"`
+	Reference     *UUID     `json:"reference"`
+	Correlator    *UUID     `json:"correlator,omitempty"`
+	Transaction   *UUID     `json:"tx,omitempty"`
+	Topic         string    `json:"topic,omitempty"`
+	Created       *FFTime   `json:"created"`
+	CorrelationID string    `json:"correlationId,omitempty"`
 }
 
 // EnrichedEvent adds the referred object to an event
 type EnrichedEvent struct {
 	Event
+	ApprovalRequest   *ApprovalRequest `json:"approvalRequest,omitempty"`
 	BlockchainEvent   *BlockchainEvent `json:"blockchainevent,omitempty"`
 	ContractAPI       *ContractAPI     `json:"contractAPI,omitempty"`
 	ContractInterface *FFI             `json:"contractInterface,omitempty"`
@@ -87,6 +107,7 @@ type EnrichedEvent struct {
 type EventDelivery struct {
 	EnrichedEvent
 	Subscription SubscriptionRef `json:"subscription"`
+	Labels       []string        `json:"labels,omitempty"`
 }
 
 // EventDeliveryResponse is the payload an application sends back, to confirm it has accepted (or rejected) the event and as such
@@ -97,6 +118,16 @@ type EventDeliveryResponse struct {
 	Info         string          `json:"info,omitempty"`
 	Subscription SubscriptionRef `json:"subscription"`
 	Reply        *MessageInOut   `json:"reply,omitempty"`
+	ReplyTo      *ReplyRouting   `json:"replyTo,omitempty"`
+}
+
+// ReplyRouting overrides where the reply in an EventDeliveryResponse is sent. A reply is sent privately to an
+// existing group, or to a list of members, instead of to the recipients set on the reply itself.
+type ReplyRouting struct {
+	Group   *Bytes32      `json:"group,omitempty"`
+	Members []MemberInput `json:"members,omitempty"`
+	Tag     string        `json:"tag,omitempty"`
+	Topics  FFStringArray `json:"topics,omitempty"`
 }
 
 func NewEvent(t EventType, ns string, ref *UUID, tx *UUID, topic string) *Event {
@@ -0,0 +1,109 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"math/big"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// TransactionFee is the cost paid for one or more blockchain transactions, as reported in their receipts
+type TransactionFee struct {
+	GasUsed  *FFBigInt `json:"gasUsed,omitempty"`
+	GasPrice *FFBigInt `json:"gasPrice,omitempty"`
+	Total    *FFBigInt `json:"total,omitempty"`
+}
+
+// NewTransactionFee calculates the total fee from the gas used and the effective price paid per unit of gas
+func NewTransactionFee(gasUsed, gasPrice *big.Int) *TransactionFee {
+	return &TransactionFee{
+		GasUsed:  (*FFBigInt)(gasUsed),
+		GasPrice: (*FFBigInt)(gasPrice),
+		Total:    (*FFBigInt)(new(big.Int).Mul(gasUsed, gasPrice)),
+	}
+}
+
+func addBigInts(a, b *FFBigInt) *FFBigInt {
+	sum := new(big.Int)
+	if a != nil {
+		sum.Add(sum, a.Int())
+	}
+	if b != nil {
+		sum.Add(sum, b.Int())
+	}
+	return (*FFBigInt)(sum)
+}
+
+// Add returns the combined fee of two sets of blockchain transactions. The gas price is only
+// retained when it is the same for both, as otherwise it cannot be derived from the totals.
+func (f *TransactionFee) Add(f2 *TransactionFee) *TransactionFee {
+	switch {
+	case f == nil:
+		return f2
+	case f2 == nil:
+		return f
+	}
+	sum := &TransactionFee{
+		GasUsed: addBigInts(f.GasUsed, f2.GasUsed),
+		Total:   addBigInts(f.Total, f2.Total),
+	}
+	if f.GasPrice.Equals(f2.GasPrice) {
+		sum.GasPrice = f.GasPrice
+	}
+	return sum
+}
+
+// Scan implements sql.Scanner
+func (f *TransactionFee) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &f)
+	case []byte:
+		return json.Unmarshal(src, &f)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, f)
+	}
+}
+
+// Value implements sql.Valuer
+func (f *TransactionFee) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	b, _ := json.Marshal(f)
+	return string(b), nil
+}
+
+// FeeSummary is the total fees paid for blockchain transactions by a signing key in a namespace, on a given day (UTC)
+type FeeSummary struct {
+	Namespace    string    `json:"namespace"`
+	Key          string    `json:"key"`
+	Day          string    `json:"day"`
+	Transactions int64     `json:"transactions"`
+	GasUsed      *FFBigInt `json:"gasUsed"`
+	Total        *FFBigInt `json:"total"`
+	Updated      *FFTime   `json:"updated"`
+}
+
+// FeeSummaryDay is the format of the day a fee is accounted to
+const FeeSummaryDay = "2006-01-02"
@@ -0,0 +1,85 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransactionFee(t *testing.T) {
+	fee := NewTransactionFee(big.NewInt(21000), big.NewInt(1000000000))
+	assert.Equal(t, int64(21000), fee.GasUsed.Int().Int64())
+	assert.Equal(t, int64(1000000000), fee.GasPrice.Int().Int64())
+	assert.Equal(t, "21000000000000", fee.Total.Int().String())
+}
+
+func TestTransactionFeeAdd(t *testing.T) {
+	fee1 := NewTransactionFee(big.NewInt(100), big.NewInt(10))
+	fee2 := NewTransactionFee(big.NewInt(200), big.NewInt(10))
+	fee3 := NewTransactionFee(big.NewInt(300), big.NewInt(20))
+
+	var none *TransactionFee
+	assert.Equal(t, fee1, none.Add(fee1))
+	assert.Equal(t, fee1, fee1.Add(nil))
+
+	sum := fee1.Add(fee2)
+	assert.Equal(t, int64(300), sum.GasUsed.Int().Int64())
+	assert.Equal(t, int64(10), sum.GasPrice.Int().Int64())
+	assert.Equal(t, int64(3000), sum.Total.Int().Int64())
+
+	sum = sum.Add(fee3)
+	assert.Equal(t, int64(600), sum.GasUsed.Int().Int64())
+	assert.Nil(t, sum.GasPrice)
+	assert.Equal(t, int64(9000), sum.Total.Int().Int64())
+
+	sum = sum.Add(&TransactionFee{GasUsed: NewFFBigInt(50)})
+	assert.Equal(t, int64(650), sum.GasUsed.Int().Int64())
+	assert.Equal(t, int64(9000), sum.Total.Int().Int64())
+}
+
+func TestTransactionFeeDatabaseSerialization(t *testing.T) {
+	var none *TransactionFee
+	v, err := none.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	fee := NewTransactionFee(big.NewInt(21000), big.NewInt(10))
+	v, err = fee.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"gasUsed":"21000","gasPrice":"10","total":"210000"}`, v)
+
+	var fee1 TransactionFee
+	err = fee1.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(210000), fee1.Total.Int().Int64())
+
+	var fee2 TransactionFee
+	err = fee2.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(21000), fee2.GasUsed.Int().Int64())
+
+	var fee3 TransactionFee
+	err = fee3.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, fee3.Total)
+
+	err = fee3.Scan(12345)
+	assert.Regexp(t, "FF10125", err)
+}
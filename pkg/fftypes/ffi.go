@@ -46,6 +46,8 @@ type FFI struct {
 	Version     string       `json:"version"`
 	Methods     []*FFIMethod `json:"methods,omitempty"`
 	Events      []*FFIEvent  `json:"events,omitempty"`
+	Errors      []*FFIError  `json:"errors,omitempty"`
+	Annotations Annotations  `json:"annotations,omitempty"`
 }
 
 type FFIMethod struct {
@@ -65,6 +67,14 @@ type FFIEventDefinition struct {
 	Params      FFIParams `json:"params"`
 }
 
+// FFIErrorDefinition describes a custom error that a contract method can raise, so that
+// blockchain plugins can decode it into a readable message when a transaction fails
+type FFIErrorDefinition struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Params      FFIParams `json:"params"`
+}
+
 type FFIEvent struct {
 	ID        *UUID  `json:"id,omitempty"`
 	Contract  *UUID  `json:"contract,omitempty"`
@@ -73,6 +83,15 @@ type FFIEvent struct {
 	FFIEventDefinition
 }
 
+// FFIError is a custom error declared by an interface, which any method of the interface can raise
+type FFIError struct {
+	ID        *UUID  `json:"id,omitempty"`
+	Contract  *UUID  `json:"contract,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Pathname  string `json:"pathname,omitempty"`
+	FFIErrorDefinition
+}
+
 type FFIParam struct {
 	Name   string   `json:"name"`
 	Schema *JSONAny `json:"schema,omitempty"`
@@ -98,7 +117,7 @@ func (f *FFI) Validate(ctx context.Context, existing bool) (err error) {
 	if err = ValidateFFNameField(ctx, f.Version, "version"); err != nil {
 		return err
 	}
-	return nil
+	return f.Annotations.Validate(ctx)
 }
 
 func (f *FFI) Topic() string {
@@ -97,6 +97,17 @@ func TestValidateFFIBadNamespace(t *testing.T) {
 	assert.Regexp(t, "FF10131", err)
 }
 
+func TestValidateFFIBadAnnotations(t *testing.T) {
+	ffi := &FFI{
+		Name:        "math",
+		Namespace:   "default",
+		Version:     "v1.0.0",
+		Annotations: Annotations{"!wrong": "ok"},
+	}
+	err := ffi.Validate(context.Background(), true)
+	assert.Regexp(t, "FF10131.*annotations", err)
+}
+
 func TestFFIParamsScan(t *testing.T) {
 	params := &FFIParams{}
 	err := params.Scan([]byte(`[{"name": "x", "type": "integer", "internalType": "uint256"}]`))
@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// GroupAlias is a name within a namespace that refers to a private messaging group, so applications can send
+// to the alias rather than repeating the member list. The alias can be moved to a new group at any time
+type GroupAlias struct {
+	ID        *UUID    `json:"id"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Group     *Bytes32 `json:"group"`
+	Created   *FFTime  `json:"created"`
+	Updated   *FFTime  `json:"updated"`
+}
+
+// GroupAliasInput sets the group an alias refers to, either as the hash of an existing group, or as a member
+// list that is resolved (and if necessary created) in the same way as the group of a private message
+type GroupAliasInput struct {
+	SignerRef
+	Name    string        `json:"name"`
+	Group   *Bytes32      `json:"group,omitempty"`
+	Ledger  *UUID         `json:"ledger,omitempty"`
+	Members []MemberInput `json:"members,omitempty"`
+}
+
+func (ga *GroupAliasInput) Validate(ctx context.Context) (err error) {
+	if err = ValidateFFNameFieldNoUUID(ctx, ga.Name, "name"); err != nil {
+		return err
+	}
+	if (ga.Group == nil) == (len(ga.Members) == 0) {
+		return i18n.NewError(ctx, i18n.MsgGroupAliasTarget)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupAliasInputValidate(t *testing.T) {
+	ctx := context.Background()
+	ga := &GroupAliasInput{
+		Name:  "team1",
+		Group: NewRandB32(),
+	}
+	assert.NoError(t, ga.Validate(ctx))
+
+	ga.Name = "!bad"
+	assert.Regexp(t, "FF10131.*name", ga.Validate(ctx))
+	ga.Name = "team1"
+
+	ga.Members = []MemberInput{{Identity: "org1"}}
+	assert.Regexp(t, "FF10480", ga.Validate(ctx))
+
+	ga.Group = nil
+	assert.NoError(t, ga.Validate(ctx))
+
+	ga.Members = nil
+	assert.Regexp(t, "FF10480", ga.Validate(ctx))
+}
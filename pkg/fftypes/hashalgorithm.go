@@ -0,0 +1,71 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"crypto/sha256"
+	"hash"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// HashAlgorithm is the digest algorithm used to calculate the hash of a batch or a blob.
+// All supported algorithms produce a 32 byte digest. An empty algorithm means SHA-256.
+type HashAlgorithm = FFEnum
+
+var (
+	// HashAlgorithmSHA256 is SHA-256, which is the default
+	HashAlgorithmSHA256 = ffEnum("hashalgorithm", "sha256")
+	// HashAlgorithmSHA3256 is SHA3-256 as defined in FIPS 202
+	HashAlgorithmSHA3256 = ffEnum("hashalgorithm", "sha3-256")
+	// HashAlgorithmBLAKE2b256 is BLAKE2b with a 256 bit digest, as defined in RFC 7693
+	HashAlgorithmBLAKE2b256 = ffEnum("hashalgorithm", "blake2b-256")
+)
+
+// IsDefaultHashAlgorithm returns true for SHA-256, including when no algorithm is set
+func IsDefaultHashAlgorithm(algorithm HashAlgorithm) bool {
+	return algorithm == "" || algorithm.Equals(HashAlgorithmSHA256)
+}
+
+// NewHash returns a new hash for the specified algorithm
+func NewHash(ctx context.Context, algorithm HashAlgorithm) (hash.Hash, error) {
+	switch {
+	case IsDefaultHashAlgorithm(algorithm):
+		return sha256.New(), nil
+	case algorithm.Equals(HashAlgorithmSHA3256):
+		return sha3.New256(), nil
+	case algorithm.Equals(HashAlgorithmBLAKE2b256):
+		// Only fails for a key longer than 64 bytes
+		h, _ := blake2b.New256(nil)
+		return h, nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgUnsupportedHashAlgorithm, algorithm)
+	}
+}
+
+// HashStringWithAlgorithm is the equivalent of HashString, using the specified algorithm
+func HashStringWithAlgorithm(ctx context.Context, algorithm HashAlgorithm, s string) (*Bytes32, error) {
+	h, err := NewHash(ctx, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h.Write([]byte(s))
+	return HashResult(h), nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashStringWithAlgorithm(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := HashStringWithAlgorithm(ctx, "", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, HashString("abc"), h)
+
+	h, err = HashStringWithAlgorithm(ctx, HashAlgorithmSHA256, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", h.String())
+
+	h, err = HashStringWithAlgorithm(ctx, HashAlgorithmSHA3256, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532", h.String())
+
+	h, err = HashStringWithAlgorithm(ctx, "BLAKE2b-256", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319", h.String())
+}
+
+func TestHashStringWithAlgorithmUnsupported(t *testing.T) {
+	_, err := HashStringWithAlgorithm(context.Background(), "md5", "abc")
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestIsDefaultHashAlgorithm(t *testing.T) {
+	assert.True(t, IsDefaultHashAlgorithm(""))
+	assert.True(t, IsDefaultHashAlgorithm("SHA256"))
+	assert.False(t, IsDefaultHashAlgorithm(HashAlgorithmSHA3256))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"strconv"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -90,13 +91,34 @@ type IdentityCreateDTO struct {
 	Type   IdentityType `json:"type,omitempty"`
 	Parent string       `json:"parent,omitempty"` // can be a DID for resolution, or the UUID directly
 	Key    string       `json:"key,omitempty"`
+	// Pinning registers the key as authorized to pin batches for messages authored by the parent identity
+	Pinning bool `json:"pinning,omitempty"`
 	IdentityProfile
+	Private *IdentityPrivateProfileInput `json:"private,omitempty"`
 }
 
 // IdentityUpdateDTO is the input structure to submit to update an identityprofile.
 // The same key in the claim will be used for the update.
 type IdentityUpdateDTO struct {
 	IdentityProfile
+	Private *IdentityPrivateProfileInput `json:"private,omitempty"`
+}
+
+// IdentityPrivateProfileInput declares profile fields to share privately with a set of members, rather than broadcast
+type IdentityPrivateProfileInput struct {
+	Profile    JSONObject `json:"profile"`
+	Recipients []string   `json:"recipients"`
+}
+
+// IdentityPrivateProfile is the data payload used in a private message to share profile fields with selected members.
+// Received fields are stored against the identity, and merged over the broadcast profile when the identity is read -
+// as long as they were shared by the identity itself.
+type IdentityPrivateProfile struct {
+	Identity *UUID      `json:"identity"`
+	Author   string     `json:"author,omitempty"`
+	Message  *UUID      `json:"message,omitempty"`
+	Profile  JSONObject `json:"profile"`
+	Updated  *FFTime    `json:"updated,omitempty"`
 }
 
 // SignerRef is the nested structure representing the identity that signed a message.
@@ -112,6 +134,7 @@ type SignerRef struct {
 // and is stored as a confirmed identity.
 type IdentityClaim struct {
 	Identity *Identity `json:"identity"`
+	Pinning  bool      `json:"pinning,omitempty"`
 }
 
 // IdentityVerification is the data payload used in message to broadcast a verification of a child identity.
@@ -236,3 +259,43 @@ func (identity *Identity) Validate(ctx context.Context) (err error) {
 	}
 	return nil
 }
+
+// ProtocolVersion returns the network protocol version advertised in the profile of a node identity.
+// Nodes registered before protocol versions were advertised only support ProtocolVersion1.
+func (identity *Identity) ProtocolVersion(ctx context.Context) (uint, error) {
+	var version uint64
+	var err error
+	switch v := identity.Profile["protocolVersion"].(type) {
+	case nil:
+		return ProtocolVersion1, nil
+	case float64:
+		version = uint64(v)
+	case uint:
+		version = uint64(v)
+	case string:
+		version, err = strconv.ParseUint(v, 10, 32)
+	}
+	if err != nil || version < uint64(ProtocolVersion1) {
+		return 0, i18n.NewError(ctx, i18n.MsgInvalidProtocolVersion, identity.DID, identity.Profile["protocolVersion"], ProtocolVersion1, ProtocolVersionLatest)
+	}
+	if version > uint64(ProtocolVersionLatest) {
+		// The node is newer than us - we just need to know it is compatible with the latest version we support
+		return ProtocolVersionLatest, nil
+	}
+	return uint(version), nil
+}
+
+// NegotiateProtocolVersion returns the newest network protocol version supported by all the supplied nodes
+func NegotiateProtocolVersion(ctx context.Context, nodes []*Identity) (uint, error) {
+	version := ProtocolVersionLatest
+	for _, node := range nodes {
+		nodeVersion, err := node.ProtocolVersion(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if nodeVersion < version {
+			version = nodeVersion
+		}
+	}
+	return version, nil
+}
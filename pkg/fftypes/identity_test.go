@@ -235,3 +235,59 @@ func TestDefinitionObjects(t *testing.T) {
 	iu.SetBroadcastMessage(updateMsg)
 
 }
+
+func TestNodeProtocolVersion(t *testing.T) {
+
+	ctx := context.Background()
+	node := &Identity{}
+	v, err := node.ProtocolVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion1, v)
+
+	node.Profile = JSONObject{"protocolVersion": float64(2)}
+	v, err = node.ProtocolVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion2, v)
+
+	node.Profile = JSONObject{"protocolVersion": ProtocolVersion1}
+	v, err = node.ProtocolVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion1, v)
+
+	node.Profile = JSONObject{"protocolVersion": "999"}
+	v, err = node.ProtocolVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersionLatest, v)
+
+	node.Profile = JSONObject{"protocolVersion": "bad"}
+	_, err = node.ProtocolVersion(ctx)
+	assert.Regexp(t, "FF10381", err)
+
+	node.Profile = JSONObject{"protocolVersion": float64(0)}
+	_, err = node.ProtocolVersion(ctx)
+	assert.Regexp(t, "FF10381", err)
+
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+
+	ctx := context.Background()
+	v, err := NegotiateProtocolVersion(ctx, []*Identity{
+		{IdentityProfile: IdentityProfile{Profile: JSONObject{"protocolVersion": float64(2)}}},
+		{IdentityProfile: IdentityProfile{Profile: JSONObject{}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion1, v)
+
+	v, err = NegotiateProtocolVersion(ctx, []*Identity{
+		{IdentityProfile: IdentityProfile{Profile: JSONObject{"protocolVersion": float64(2)}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion2, v)
+
+	_, err = NegotiateProtocolVersion(ctx, []*Identity{
+		{IdentityProfile: IdentityProfile{Profile: JSONObject{"protocolVersion": true}}},
+	})
+	assert.Regexp(t, "FF10381", err)
+
+}
@@ -101,6 +101,32 @@ func (jd JSONObject) GetStringOk(key string) (string, bool) {
 	}
 }
 
+// GetPathStringOk walks a dot separated path of keys through nested objects, returning
+// the string representation of the scalar value found at the end of the path
+func (jd JSONObject) GetPathStringOk(path string) (string, bool) {
+	var v interface{} = jd
+	for _, key := range strings.Split(path, ".") {
+		switch vt := v.(type) {
+		case JSONObject:
+			v = vt[key]
+		case map[string]interface{}:
+			v = vt[key]
+		default:
+			return "", false
+		}
+	}
+	switch vt := v.(type) {
+	case string:
+		return vt, true
+	case bool:
+		return strconv.FormatBool(vt), true
+	case float64:
+		return strconv.FormatFloat(vt, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
 func (jd JSONObject) GetObject(key string) JSONObject {
 	ob, _ := jd.GetObjectOk(key)
 	return ob
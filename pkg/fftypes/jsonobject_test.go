@@ -217,3 +217,51 @@ func TestJSONNestedSafeGet(t *testing.T) {
 	)
 
 }
+
+func TestJSONObjectGetPathString(t *testing.T) {
+
+	var jd JSONObject
+	err := json.Unmarshal([]byte(`
+		{
+			"order": {
+				"id": "order1",
+				"total": 12.5,
+				"paid": true,
+				"items": ["a","b"]
+			},
+			"customer": "cust1"
+		}
+	`), &jd)
+	assert.NoError(t, err)
+	jd["typed"] = JSONObject{"value": "typed1"}
+
+	v, ok := jd.GetPathStringOk("order.id")
+	assert.True(t, ok)
+	assert.Equal(t, "order1", v)
+
+	v, ok = jd.GetPathStringOk("order.total")
+	assert.True(t, ok)
+	assert.Equal(t, "12.5", v)
+
+	v, ok = jd.GetPathStringOk("order.paid")
+	assert.True(t, ok)
+	assert.Equal(t, "true", v)
+
+	v, ok = jd.GetPathStringOk("customer")
+	assert.True(t, ok)
+	assert.Equal(t, "cust1", v)
+
+	v, ok = jd.GetPathStringOk("typed.value")
+	assert.True(t, ok)
+	assert.Equal(t, "typed1", v)
+
+	_, ok = jd.GetPathStringOk("order.items")
+	assert.False(t, ok)
+
+	_, ok = jd.GetPathStringOk("customer.id")
+	assert.False(t, ok)
+
+	_, ok = jd.GetPathStringOk("missing")
+	assert.False(t, ok)
+
+}
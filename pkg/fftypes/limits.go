@@ -0,0 +1,36 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// MessageLimits are the limits enforced on the messages and data submitted to a namespace. Zero means no limit
+type MessageLimits struct {
+	MaxMessageBytes int64 `json:"maxMessageBytes"`
+	MaxDataItems    int   `json:"maxDataItems"`
+	MaxBlobBytes    int64 `json:"maxBlobBytes"`
+}
+
+// NamespaceLimits are the limits in force for a namespace that overrides the defaults
+type NamespaceLimits struct {
+	Namespace string `json:"namespace"`
+	MessageLimits
+}
+
+// LimitsStatus advertises the limits in force, so clients can validate messages before they are submitted
+type LimitsStatus struct {
+	Defaults   MessageLimits      `json:"defaults"`
+	Namespaces []*NamespaceLimits `json:"namespaces"`
+}
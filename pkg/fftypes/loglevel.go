@@ -0,0 +1,29 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// LogLevel is the current log level of a component of FireFly
+type LogLevel struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	Override  bool   `json:"override"`
+}
+
+// LogLevelInput overrides the log level of a component at runtime
+type LogLevelInput struct {
+	Level string `json:"level"`
+}
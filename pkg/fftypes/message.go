@@ -55,6 +55,12 @@ var (
 	MessageStateStaged = ffEnum("messagestate", "staged")
 	// MessageStateReady is a message created locally which is ready to send
 	MessageStateReady = ffEnum("messagestate", "ready")
+	// MessageStatePendingData is a message created locally which refers to data that has not been uploaded yet. It is not sealed, or ready to send, until all the data has arrived
+	MessageStatePendingData = ffEnum("messagestate", "pending_data")
+	// MessageStateDraft is a message created locally, with its data attached, which is not sent until it is activated
+	MessageStateDraft = ffEnum("messagestate", "draft")
+	// MessageStateScheduled is a draft message that has been activated to be sent at a scheduled time
+	MessageStateScheduled = ffEnum("messagestate", "scheduled")
 	// MessageStateSent is a message created locally which has been sent in a batch
 	MessageStateSent = ffEnum("messagestate", "sent")
 	// MessageStatePending is a message that has been received but is awaiting aggregation/confirmation
@@ -85,14 +91,17 @@ type MessageHeader struct {
 // Data is passed by reference in these messages, and a chain of hashes covering the data and the
 // details of the message, provides a verification against tampering.
 type Message struct {
-	Header    MessageHeader `json:"header"`
-	Hash      *Bytes32      `json:"hash,omitempty"`
-	BatchID   *UUID         `json:"batch,omitempty"`
-	State     MessageState  `json:"state,omitempty" ffenum:"messagestate"`
-	Confirmed *FFTime       `json:"confirmed,omitempty"`
-	Data      DataRefs      `json:"data"`
-	Pins      FFStringArray `json:"pins,omitempty"`
-	Sequence  int64         `json:"-"` // Local database sequence used internally for batch assembly
+	Header        MessageHeader `json:"header"`
+	Hash          *Bytes32      `json:"hash,omitempty"`
+	BatchID       *UUID         `json:"batch,omitempty"`
+	State         MessageState  `json:"state,omitempty" ffenum:"messagestate"`
+	Confirmed     *FFTime       `json:"confirmed,omitempty"`
+	Data          DataRefs      `json:"data"`
+	Pins          FFStringArray `json:"pins,omitempty"`
+	Sequence      int64         `json:"-"` // Local database sequence used internally for batch assembly
+	CorrelationID string        `json:"correlationId,omitempty"`
+	BusinessKey   string        `json:"businessKey,omitempty"`
+	SendAt        *FFTime       `json:"sendAt,omitempty"`
 }
 
 // BatchMessage is the fields in a message record that are assured to be consistent on all parties.
@@ -111,15 +120,24 @@ func (m *Message) BatchMessage() *Message {
 // will be broken out and stored separately during the call.
 type MessageInOut struct {
 	Message
-	InlineData InlineData  `json:"data"`
-	Group      *InputGroup `json:"group,omitempty"`
+	InlineData       InlineData  `json:"data"`
+	Group            *InputGroup `json:"group,omitempty"`
+	FlushImmediately bool        `json:"flushImmediately,omitempty"`
+	DeferredData     bool        `json:"deferredData,omitempty"`
+	Draft            bool        `json:"draft,omitempty"`
 }
 
-// InputGroup declares a group in-line for auotmatic resolution, without having to define a group up-front
+// DraftActivation activates a draft message, to be sent immediately or at a scheduled time
+type DraftActivation struct {
+	SendAt *FFTime `json:"sendAt,omitempty"`
+}
+
+// InputGroup declares a group in-line for auotmatic resolution, without having to define a group up-front, or refers to a group alias
 type InputGroup struct {
 	Name    string        `json:"name,omitempty"`
 	Ledger  *UUID         `json:"ledger,omitempty"`
 	Members []MemberInput `json:"members"`
+	Alias   string        `json:"alias,omitempty"`
 }
 
 // InlineData is an array of data references or values
@@ -133,6 +151,7 @@ type DataRefOrValue struct {
 	Validator ValidatorType `json:"validator,omitempty"`
 	Datatype  *DatatypeRef  `json:"datatype,omitempty"`
 	Value     *JSONAny      `json:"value,omitempty"`
+	MediaType string        `json:"mediaType,omitempty"`
 	Blob      *BlobRef      `json:"blob,omitempty"`
 }
 
@@ -142,6 +161,26 @@ type MessageRef struct {
 	Hash *Bytes32 `json:"hash,omitempty"`
 }
 
+// MessageStatus is a roll-up of the processing of a message, across its batch, transaction
+// and (for private messages) the delivery to each member of the group
+type MessageStatus struct {
+	Message     *UUID                     `json:"message"`
+	State       MessageState              `json:"state" ffenum:"messagestate"`
+	Status      OpStatus                  `json:"status"`
+	Pins        FFStringArray             `json:"pins,omitempty"`
+	Batch       *TransactionStatusDetails `json:"batch,omitempty"`
+	Transaction *TransactionStatus        `json:"transaction,omitempty"`
+	Members     []*MessageMemberStatus    `json:"members,omitempty"`
+}
+
+// MessageMemberStatus is the delivery status of a private message to one member of the group
+type MessageMemberStatus struct {
+	Identity  string                    `json:"identity"`
+	Node      *UUID                     `json:"node"`
+	Send      *TransactionStatusDetails `json:"send,omitempty"`
+	Recipient *MessageRecipient         `json:"recipient,omitempty"`
+}
+
 func (h *MessageHeader) Hash() *Bytes32 {
 	b, _ := json.Marshal(&h)
 	var b32 Bytes32 = sha256.Sum256(b)
@@ -179,6 +218,9 @@ func (m *Message) EstimateSize(includeDataRefs bool) int64 {
 }
 
 func (m *Message) Seal(ctx context.Context) (err error) {
+	if err := ValidateBusinessKey(ctx, m.BusinessKey); err != nil {
+		return err
+	}
 	if len(m.Header.Topics) == 0 {
 		m.Header.Topics = []string{DefaultTopic}
 	}
@@ -195,7 +237,12 @@ func (m *Message) Seal(ctx context.Context) (err error) {
 	if m.Header.TxType == "" {
 		m.Header.TxType = TransactionTypeBatchPin
 	}
-	err = m.VerifyFields(ctx)
+	if m.State == MessageStatePendingData {
+		// Not all the data hashes are known until the data has arrived, so the message is sealed again when it is complete
+		err = m.verifyHeaderFields(ctx)
+	} else {
+		err = m.VerifyFields(ctx)
+	}
 	if err == nil {
 		m.Header.DataHash = m.Data.Hash()
 		m.Hash = m.Header.Hash()
@@ -219,6 +266,13 @@ func (m *Message) DupDataCheck(ctx context.Context) (err error) {
 }
 
 func (m *Message) VerifyFields(ctx context.Context) error {
+	if err := m.verifyHeaderFields(ctx); err != nil {
+		return err
+	}
+	return m.DupDataCheck(ctx)
+}
+
+func (m *Message) verifyHeaderFields(ctx context.Context) error {
 	switch m.Header.TxType {
 	case TransactionTypeBatchPin:
 	case TransactionTypeUnpinned:
@@ -229,11 +283,11 @@ func (m *Message) VerifyFields(ctx context.Context) error {
 		return err
 	}
 	if m.Header.Tag != "" {
-		if err := ValidateFFNameField(ctx, m.Header.Tag, "header.tag"); err != nil {
+		if err := ValidateTagField(ctx, m.Header.Tag, "header.tag"); err != nil {
 			return err
 		}
 	}
-	return m.DupDataCheck(ctx)
+	return nil
 }
 
 func (m *Message) Verify(ctx context.Context) error {
@@ -0,0 +1,60 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// MessageProofEncoding is the encoding of the input to a step of a message proof
+type MessageProofEncoding = FFEnum
+
+var (
+	// MessageProofEncodingUTF8 is an input that is the UTF-8 bytes of a string, such as a JSON document
+	MessageProofEncodingUTF8 = ffEnum("messageproofencoding", "utf8")
+	// MessageProofEncodingHex is a binary input, encoded as hex
+	MessageProofEncodingHex = ffEnum("messageproofencoding", "hex")
+)
+
+// MessageProofStep is a single hashing step in a message proof, which can be repeated by a third party.
+// The SHA-256 hash of the decoded input must match the output
+type MessageProofStep struct {
+	Description string               `json:"description"`
+	Encoding    MessageProofEncoding `json:"encoding" ffenum:"messageproofencoding"`
+	Input       string               `json:"input"`
+	Output      *Bytes32             `json:"output"`
+	Verified    bool                 `json:"verified"`
+}
+
+// MessageProofPin is the context (broadcast) or masked pin (private) written to the blockchain for one topic of a message
+type MessageProofPin struct {
+	Topic  string   `json:"topic"`
+	Index  int64    `json:"index"`
+	Hash   *Bytes32 `json:"hash"`
+	Masked bool     `json:"masked"`
+	Nonce  int64    `json:"nonce,omitempty"`
+	Pinned bool     `json:"pinned"`
+}
+
+// MessageProof is a portable bundle of everything needed to independently verify that a message was
+// anchored to the blockchain - from the hash of the message, through the hash of its batch, to the
+// batch pin transaction
+type MessageProof struct {
+	Message          *Message            `json:"message"`
+	Batch            *BatchPersisted     `json:"batch"`
+	Transaction      *Transaction        `json:"transaction,omitempty"`
+	BlockchainEvents []*BlockchainEvent  `json:"blockchainEvents"`
+	Pins             []*MessageProofPin  `json:"pins"`
+	Steps            []*MessageProofStep `json:"steps"`
+	Verified         bool                `json:"verified"`
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+// MessageRecipientStatus is the end-to-end delivery status of a private message to one recipient node
+type MessageRecipientStatus = FFEnum
+
+var (
+	// MessageRecipientStatusPending is a message that has been sent, but the recipient has not yet acknowledged processing it
+	MessageRecipientStatusPending = ffEnum("recipientstatus", "pending")
+	// MessageRecipientStatusConfirmed is a message the recipient has processed and confirmed
+	MessageRecipientStatusConfirmed = ffEnum("recipientstatus", "confirmed")
+	// MessageRecipientStatusRejected is a message the recipient has processed, but rejected
+	MessageRecipientStatusRejected = ffEnum("recipientstatus", "rejected")
+)
+
+// MessageRecipient records the delivery status of a private message sent by this node, to one of the nodes in the group
+type MessageRecipient struct {
+	Namespace string                 `json:"namespace"`
+	Message   *UUID                  `json:"message"`
+	Node      *UUID                  `json:"node"`
+	Status    MessageRecipientStatus `json:"status" ffenum:"recipientstatus"`
+	Created   *FFTime                `json:"created"`
+	Updated   *FFTime                `json:"updated"`
+}
+
+// DeliveryAck is sent by a recipient node back to the node that sent it a private message, once the
+// message has been processed. The acknowledging node is identified by the data exchange peer it came from,
+// and the acknowledgement is signed with the ed25519 key the node advertises in its profile.
+type DeliveryAck struct {
+	Namespace string       `json:"namespace"`
+	Message   *UUID        `json:"message"`
+	State     MessageState `json:"state"`
+	Signature string       `json:"signature,omitempty"`
+}
+
+// DeliveryAckKeyProfileKey is the entry in the profile of a node identity, that holds the base64 encoded
+// ed25519 public key used to verify delivery acknowledgements from that node
+const DeliveryAckKeyProfileKey = "deliveryAckKey"
+
+func (ack *DeliveryAck) signingPayload() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%s", ack.Namespace, ack.Message, ack.State))
+}
+
+// Sign sets the signature of the acknowledgement, over its namespace, message and state
+func (ack *DeliveryAck) Sign(key ed25519.PrivateKey) {
+	ack.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, ack.signingPayload()))
+}
+
+// VerifySignature checks the acknowledgement was signed by the private key matching the supplied base64 public key
+func (ack *DeliveryAck) VerifySignature(publicKey string) bool {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(ack.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(pub), ack.signingPayload(), sig)
+}
+
+// RecipientStatus maps the final state of a message at the recipient, to the delivery status recorded by the sender
+func (ack *DeliveryAck) RecipientStatus() (MessageRecipientStatus, bool) {
+	switch ack.State {
+	case MessageStateConfirmed:
+		return MessageRecipientStatusConfirmed, true
+	case MessageStateRejected:
+		return MessageRecipientStatusRejected, true
+	default:
+		return "", false
+	}
+}
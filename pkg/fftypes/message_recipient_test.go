@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryAckRecipientStatus(t *testing.T) {
+
+	status, ok := (&DeliveryAck{State: MessageStateConfirmed}).RecipientStatus()
+	assert.True(t, ok)
+	assert.Equal(t, MessageRecipientStatusConfirmed, status)
+
+	status, ok = (&DeliveryAck{State: MessageStateRejected}).RecipientStatus()
+	assert.True(t, ok)
+	assert.Equal(t, MessageRecipientStatusRejected, status)
+
+	_, ok = (&DeliveryAck{State: MessageStatePending}).RecipientStatus()
+	assert.False(t, ok)
+
+}
+
+func TestDeliveryAckSignature(t *testing.T) {
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	pubB64 := base64.StdEncoding.EncodeToString(pub)
+
+	ack := &DeliveryAck{Namespace: "ns1", Message: NewUUID(), State: MessageStateConfirmed}
+	assert.False(t, ack.VerifySignature(pubB64))
+
+	ack.Sign(priv)
+	assert.True(t, ack.VerifySignature(pubB64))
+
+	ack.State = MessageStateRejected
+	assert.False(t, ack.VerifySignature(pubB64))
+
+	assert.False(t, ack.VerifySignature("!bad"))
+	assert.False(t, ack.VerifySignature(base64.StdEncoding.EncodeToString([]byte("short"))))
+
+	ack.Signature = "!bad"
+	assert.False(t, ack.VerifySignature(pubB64))
+
+}
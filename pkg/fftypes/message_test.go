@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,6 +66,24 @@ func TestSealBadTagString(t *testing.T) {
 	assert.Regexp(t, `FF10131.*header.tag`, err)
 }
 
+func TestSealNamespacedTag(t *testing.T) {
+	msg := Message{
+		Header: MessageHeader{
+			Tag: "myapp:config",
+		},
+	}
+	err := msg.Seal(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestSealBusinessKeyTooLong(t *testing.T) {
+	msg := Message{
+		BusinessKey: strings.Repeat("x", BusinessKeyMaxLength+1),
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, `FF10188.*businessKey`, err)
+}
+
 func TestVerifyTXType(t *testing.T) {
 	msg := Message{
 		Header: MessageHeader{
@@ -123,6 +142,24 @@ func TestSealNilDataID(t *testing.T) {
 	assert.Regexp(t, "FF10144.*0", err)
 }
 
+func TestSealPendingData(t *testing.T) {
+	msg := Message{
+		State: MessageStatePendingData,
+		Data: DataRefs{
+			{ID: NewUUID()},
+		},
+	}
+	err := msg.Seal(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, msg.Header.ID)
+	assert.NotNil(t, msg.Header.DataHash)
+	assert.NotNil(t, msg.Hash)
+
+	msg.Header.Tag = "!wrong"
+	err = msg.Seal(context.Background())
+	assert.Regexp(t, `FF10131.*header.tag`, err)
+}
+
 func TestVerifyNilDataHash(t *testing.T) {
 	msg := Message{
 		Header: MessageHeader{
@@ -0,0 +1,50 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// MessageImportFormat is the encoding of a file of messages uploaded for bulk import
+type MessageImportFormat string
+
+const (
+	// MessageImportFormatNDJSON is one JSON message per line, in the same form as messages submitted to the broadcast and private APIs
+	MessageImportFormatNDJSON MessageImportFormat = "ndjson"
+	// MessageImportFormatCSV is a header row naming the columns, followed by one message per row
+	MessageImportFormatCSV MessageImportFormat = "csv"
+)
+
+// MessageImportStatus is the progress of a bulk import of messages, reported in the output of its operation
+type MessageImportStatus struct {
+	Total      int64                    `json:"total"`
+	Submitted  int64                    `json:"submitted"`
+	Failed     int64                    `json:"failed"`
+	Failures   []*MessageImportFailure  `json:"failures,omitempty"`
+	Checkpoint *MessageImportCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// MessageImportCheckpoint is the last page of a bulk import that was completed, which the import resumes after if the node restarts.
+// Every row up to and including Row had been submitted or had failed, with the counts at that point.
+type MessageImportCheckpoint struct {
+	Row       int64 `json:"row"`
+	Submitted int64 `json:"submitted"`
+	Failed    int64 `json:"failed"`
+}
+
+// MessageImportFailure is a message in a bulk import that could not be submitted, identified by its row in the import (starting from 1)
+type MessageImportFailure struct {
+	Row   int64  `json:"row"`
+	Error string `json:"error"`
+}
@@ -46,6 +46,13 @@ type Namespace struct {
 	Created     *FFTime       `json:"created"`
 }
 
+// NamespaceSigner is the default author and signing key for a namespace, used when a request does not specify a signer
+type NamespaceSigner struct {
+	Namespace string `json:"namespace"`
+	SignerRef
+	Updated *FFTime `json:"updated,omitempty"`
+}
+
 func (ns *Namespace) Validate(ctx context.Context, existing bool) (err error) {
 	if err = ValidateFFNameField(ctx, ns.Name, "name"); err != nil {
 		return err
@@ -21,5 +21,5 @@ type NextPin struct {
 	Identity string   `json:"identity"`
 	Hash     *Bytes32 `json:"hash"`
 	Nonce    int64    `json:"nonce"`
-	Sequence int64    `json:"_"` // Local database sequence used internally for update efficiency
+	Sequence int64    `json:"-"` // Local database sequence used internally for update efficiency
 }
@@ -18,9 +18,17 @@ package fftypes
 
 // NodeStatus is a set of information that represents the health, and identity of a node
 type NodeStatus struct {
-	Node     NodeStatusNode     `json:"node"`
-	Org      NodeStatusOrg      `json:"org"`
-	Defaults NodeStatusDefaults `json:"defaults"`
+	Node     NodeStatusNode       `json:"node"`
+	Org      NodeStatusOrg        `json:"org"`
+	Defaults NodeStatusDefaults   `json:"defaults"`
+	Warnings []*NodeStatusWarning `json:"warnings,omitempty"`
+}
+
+// NodeStatusWarning is a problem found with a plugin that does not stop the node running, but may cause some requests to fail
+type NodeStatusWarning struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Warning string `json:"warning"`
 }
 
 // NodeStatusNode is the information about the local node, returned in the node status
@@ -37,9 +45,51 @@ type NodeStatusOrg struct {
 	DID        string         `json:"did,omitempty"`
 	ID         *UUID          `json:"id,omitempty"`
 	Verifiers  []*VerifierRef `json:"verifiers,omitempty"`
+	PinAccess  *PinAccess     `json:"pinAccess,omitempty"`
+}
+
+// PinAccess is whether a signing key is able to pin batches, when the batch pin contract restricts pinning to registered keys
+type PinAccess struct {
+	Permissioned bool   `json:"permissioned"`
+	Key          string `json:"key,omitempty"`
+	Registered   bool   `json:"registered"`
 }
 
 // NodeStatusDefaults is information about core configuration th
 type NodeStatusDefaults struct {
 	Namespace string `json:"namespace"`
 }
+
+// ReadinessStatus is the overall readiness of a node to process requests
+type ReadinessStatus = FFEnum
+
+var (
+	// ReadinessStatusReady all plugins are healthy
+	ReadinessStatusReady = ffEnum("readinessstatus", "ready")
+	// ReadinessStatusDegraded the node can process requests, but an optional plugin (such as a token connector) is unavailable
+	ReadinessStatusDegraded = ffEnum("readinessstatus", "degraded")
+	// ReadinessStatusNotReady a plugin required to process requests is unavailable
+	ReadinessStatusNotReady = ffEnum("readinessstatus", "notready")
+	// ReadinessStatusStandby the node is attached to a replicated database in standby mode, and will not process requests until promoted
+	ReadinessStatusStandby = ffEnum("readinessstatus", "standby")
+)
+
+// NodeReadiness reports the health of each plugin, to determine whether the node should receive traffic
+type NodeReadiness struct {
+	Status  ReadinessStatus `json:"status" ffenum:"readinessstatus"`
+	Plugins []*PluginHealth `json:"plugins"`
+}
+
+// PluginHealth is the health of an individual plugin, returned in the node readiness
+type PluginHealth struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Healthy  bool   `json:"healthy"`
+	Required bool   `json:"required"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NodeLiveness reports whether the node is running, or has been shut down
+type NodeLiveness struct {
+	Live bool `json:"live"`
+}
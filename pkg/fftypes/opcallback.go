@@ -0,0 +1,43 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// OpCallbackStatus is the delivery status of an operation callback
+type OpCallbackStatus string
+
+const (
+	// OpCallbackStatusPending the callback is waiting to be delivered, or retried
+	OpCallbackStatusPending OpCallbackStatus = "Pending"
+	// OpCallbackStatusDelivered the callback URL accepted the delivery
+	OpCallbackStatusDelivered OpCallbackStatus = "Delivered"
+	// OpCallbackStatusFailed the callback could not be delivered, and will not be retried
+	OpCallbackStatusFailed OpCallbackStatus = "Failed"
+)
+
+// OpCallback records the delivery of the final state of an operation to its callback URL.
+// The record is written in the same database transaction that resolves the operation, so the delivery
+// survives a restart, and is kept once the delivery completes, so an operation that is resolved
+// more than once only has its callback delivered once.
+type OpCallback struct {
+	ID          *UUID            `json:"id"`
+	Namespace   string           `json:"namespace"`
+	Status      OpCallbackStatus `json:"status"`
+	Attempts    int              `json:"attempts"`
+	NextAttempt *FFTime          `json:"next"`
+	Error       string           `json:"error,omitempty"`
+	Created     *FFTime          `json:"created"`
+}
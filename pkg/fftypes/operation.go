@@ -26,6 +26,10 @@ var (
 	OpTypeBlockchainPinBatch = ffEnum("optype", "blockchain_pin_batch")
 	// OpTypeBlockchainInvoke is a smart contract invoke
 	OpTypeBlockchainInvoke = ffEnum("optype", "blockchain_invoke")
+	// OpTypeBlockchainContractDeploy is a smart contract deployment
+	OpTypeBlockchainContractDeploy = ffEnum("optype", "blockchain_deploy")
+	// OpTypeBlockchainRegisterKey is a registration of a signing key with a batch pin contract that restricts pinning to registered keys
+	OpTypeBlockchainRegisterKey = ffEnum("optype", "blockchain_register_key")
 	// OpTypeSharedStorageUploadBatch is a shared storage operation to upload broadcast data
 	OpTypeSharedStorageUploadBatch = ffEnum("optype", "sharedstorage_upload_batch")
 	// OpTypeSharedStorageUploadBlob is a shared storage operation to upload blob data
@@ -38,14 +42,24 @@ var (
 	OpTypeDataExchangeSendBatch = ffEnum("optype", "dataexchange_send_batch")
 	// OpTypeDataExchangeSendBlob is a private send of a blob
 	OpTypeDataExchangeSendBlob = ffEnum("optype", "dataexchange_send_blob")
+	// OpTypeDataExchangeSendBroadcastBatch is a send of a broadcast batch to another node, ahead of it being pinned, in hybrid broadcast mode
+	OpTypeDataExchangeSendBroadcastBatch = ffEnum("optype", "dataexchange_send_broadcast_batch")
+	// OpTypeDataExchangeSendAck is a private send of a delivery acknowledgement, back to the sender of a message
+	OpTypeDataExchangeSendAck = ffEnum("optype", "dataexchange_send_ack")
 	// OpTypeTokenCreatePool is a token pool creation
 	OpTypeTokenCreatePool = ffEnum("optype", "token_create_pool")
+	// OpTypeTokenDeployPool is a token pool creation, that first deploys a new token contract
+	OpTypeTokenDeployPool = ffEnum("optype", "token_deploy_pool")
 	// OpTypeTokenActivatePool is a token pool activation
 	OpTypeTokenActivatePool = ffEnum("optype", "token_activate_pool")
 	// OpTypeTokenTransfer is a token transfer
 	OpTypeTokenTransfer = ffEnum("optype", "token_transfer")
 	// OpTypeTokenApproval is a token approval
 	OpTypeTokenApproval = ffEnum("optype", "token_approval")
+	// OpTypeMessageImport is a bulk import of messages, with its progress reported in the output
+	OpTypeMessageImport = ffEnum("optype", "message_import")
+	// OpTypeDataExport is an export of historical records to files, with its progress reported in the output
+	OpTypeDataExport = ffEnum("optype", "data_export")
 )
 
 // OpStatus is the current status of an operation
@@ -81,18 +95,33 @@ func NewOperation(plugin Named, namespace string, tx *UUID, opType OpType) *Oper
 
 // Operation is a description of an action performed as part of a transaction submitted by this node
 type Operation struct {
-	ID          *UUID      `json:"id"`
-	Namespace   string     `json:"namespace"`
-	Transaction *UUID      `json:"tx"`
-	Type        OpType     `json:"type" ffenum:"optype"`
-	Status      OpStatus   `json:"status"`
-	Error       string     `json:"error,omitempty"`
-	Plugin      string     `json:"plugin"`
-	Input       JSONObject `json:"input,omitempty"`
-	Output      JSONObject `json:"output,omitempty"`
-	Created     *FFTime    `json:"created,omitempty"`
-	Updated     *FFTime    `json:"updated,omitempty"`
-	Retry       *UUID      `json:"retry,omitempty"`
+	ID            *UUID           `json:"id"`
+	Namespace     string          `json:"namespace"`
+	Transaction   *UUID           `json:"tx"`
+	Type          OpType          `json:"type" ffenum:"optype"`
+	Status        OpStatus        `json:"status"`
+	Error         string          `json:"error,omitempty"`
+	Plugin        string          `json:"plugin"`
+	Input         JSONObject      `json:"input,omitempty"`
+	Output        JSONObject      `json:"output,omitempty"`
+	Created       *FFTime         `json:"created,omitempty"`
+	Updated       *FFTime         `json:"updated,omitempty"`
+	Retry         *UUID           `json:"retry,omitempty"`
+	OutputRef     *UUID           `json:"outputRef,omitempty"`
+	Fee           *TransactionFee `json:"fee,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	CallbackURL   string          `json:"callbackUrl,omitempty"`
+	BusinessKey   string          `json:"businessKey,omitempty"`
+}
+
+// OperationOutput is the output of an operation that was too large to store inline. It is kept separately to the
+// data of the namespace, so it is not returned by the data APIs, or included in events and the search index.
+type OperationOutput struct {
+	ID        *UUID    `json:"id"`
+	Operation *UUID    `json:"operation"`
+	Namespace string   `json:"namespace"`
+	Value     *JSONAny `json:"value"`
+	Created   *FFTime  `json:"created"`
 }
 
 // PreparedOperation is an operation that has gathered all the raw data ready to send to a plugin
@@ -100,7 +129,10 @@ type Operation struct {
 // PreparedOperation from an Operation. Data is defined by the Manager, but should be JSON-serializable
 // to support inspection and debugging.
 type PreparedOperation struct {
-	ID   *UUID       `json:"id"`
-	Type OpType      `json:"type" ffenum:"optype"`
-	Data interface{} `json:"data"`
+	ID          *UUID       `json:"id"`
+	Namespace   string      `json:"namespace"`
+	Transaction *UUID       `json:"tx"`
+	Type        OpType      `json:"type" ffenum:"optype"`
+	Data        interface{} `json:"data"`
+	CallbackURL string      `json:"-"`
 }
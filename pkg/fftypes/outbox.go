@@ -0,0 +1,29 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// OutboxEntry records an operation that is waiting to be submitted to its plugin.
+// The entry is written in the same database transaction as the operation, so the intent to submit
+// survives a crash, and is removed once the plugin has accepted the submission.
+type OutboxEntry struct {
+	ID          *UUID   `json:"id"`
+	Namespace   string  `json:"namespace"`
+	Attempts    int     `json:"attempts"`
+	NextAttempt *FFTime `json:"next"`
+	Error       string  `json:"error,omitempty"`
+	Created     *FFTime `json:"created"`
+}
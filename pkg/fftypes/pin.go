@@ -16,6 +16,18 @@
 
 package fftypes
 
+// PinParkReason is recorded on a pin that cannot be processed yet, so remains parked (undispatched)
+type PinParkReason = FFEnum
+
+var (
+	// PinParkReasonBatchUnavailable the batch has not been received, or could not be matched to the pin
+	PinParkReasonBatchUnavailable = ffEnum("pinparkreason", "batch_unavailable")
+	// PinParkReasonOutOfRange the pin index is outside of the range of pins in the batch
+	PinParkReasonOutOfRange = ffEnum("pinparkreason", "pin_out_of_range")
+	// PinParkReasonPayloadRefMismatch the batch payload in shared storage does not match the on-chain batch hash
+	PinParkReasonPayloadRefMismatch = ffEnum("pinparkreason", "payload_ref_mismatch")
+)
+
 // Pin represents a ledger-pinning event that has been
 // detected from the blockchain, in the sequence that it was detected.
 //
@@ -37,15 +49,16 @@ package fftypes
 // This is because the sequence must be in the order the pins arrive.
 //
 type Pin struct {
-	Sequence   int64    `json:"sequence"`
-	Masked     bool     `json:"masked,omitempty"`
-	Hash       *Bytes32 `json:"hash,omitempty"`
-	Batch      *UUID    `json:"batch,omitempty"`
-	BatchHash  *Bytes32 `json:"batchHash,omitempty"`
-	Index      int64    `json:"index"`
-	Dispatched bool     `json:"dispatched,omitempty"`
-	Signer     string   `json:"signer,omitempty"`
-	Created    *FFTime  `json:"created,omitempty"`
+	Sequence   int64         `json:"sequence"`
+	Masked     bool          `json:"masked,omitempty"`
+	Hash       *Bytes32      `json:"hash,omitempty"`
+	Batch      *UUID         `json:"batch,omitempty"`
+	BatchHash  *Bytes32      `json:"batchHash,omitempty"`
+	Index      int64         `json:"index"`
+	Dispatched bool          `json:"dispatched,omitempty"`
+	ParkReason PinParkReason `json:"parkReason,omitempty"`
+	Signer     string        `json:"signer,omitempty"`
+	Created    *FFTime       `json:"created,omitempty"`
 }
 
 func (p *Pin) LocalSequence() int64 {
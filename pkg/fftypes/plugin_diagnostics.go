@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// PluginConnectionState is the state of the connection between a plugin and its connector
+type PluginConnectionState = FFEnum
+
+var (
+	// PluginConnectionStateConnected the plugin reports it is connected
+	PluginConnectionStateConnected = ffEnum("pluginconnectionstate", "connected")
+	// PluginConnectionStateDisconnected the plugin reports a problem with its connection
+	PluginConnectionStateDisconnected = ffEnum("pluginconnectionstate", "disconnected")
+	// PluginConnectionStateUnknown the plugin does not report the state of its connection
+	PluginConnectionStateUnknown = ffEnum("pluginconnectionstate", "unknown")
+)
+
+// PluginDiagnosticAction is an action that can be run against a plugin, to debug the environment of a node
+type PluginDiagnosticAction = FFEnum
+
+var (
+	// PluginDiagnosticActionTestConnection checks the plugin can reach its connector
+	PluginDiagnosticActionTestConnection = ffEnum("plugindiagnosticaction", "test-connection")
+)
+
+// PluginDiagnostics describes a loaded plugin, with its configuration (secrets redacted), capabilities and connection state
+type PluginDiagnostics struct {
+	Type         string                   `json:"type"`
+	Name         string                   `json:"name"`
+	Config       JSONObject               `json:"config"`
+	Capabilities JSONObject               `json:"capabilities"`
+	Connection   PluginConnectionState    `json:"connection" ffenum:"pluginconnectionstate"`
+	Error        string                   `json:"error,omitempty"`
+	Actions      []PluginDiagnosticAction `json:"actions"`
+}
+
+// PluginDiagnosticResult is the result of running a diagnostic action against a plugin
+type PluginDiagnosticResult struct {
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name"`
+	Action  PluginDiagnosticAction `json:"action" ffenum:"plugindiagnosticaction"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Elapsed *FFDuration            `json:"elapsed"`
+}
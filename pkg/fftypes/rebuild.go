@@ -0,0 +1,26 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// RebuildStatus reports the derived state cleared to rebuild the node, which is reconstructed by replaying the pins when it restarts
+type RebuildStatus struct {
+	Started      *FFTime `json:"started,omitempty"`
+	Completed    *FFTime `json:"completed,omitempty"`
+	Messages     int64   `json:"messages"`
+	NextPins     int64   `json:"nextPins"`
+	Redeliveries int64   `json:"redeliveries"`
+}
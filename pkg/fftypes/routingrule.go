@@ -0,0 +1,56 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// RoutingRule attaches labels to the events in a namespace whose message tag and topic match the rule's regular
+// expressions, so subscriptions can filter on the labels rather than each repeating the patterns
+type RoutingRule struct {
+	ID        *UUID         `json:"id"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Tag       string        `json:"tag,omitempty"`
+	Topic     string        `json:"topic,omitempty"`
+	Labels    FFStringArray `json:"labels"`
+	Created   *FFTime       `json:"created"`
+	Updated   *FFTime       `json:"updated"`
+}
+
+func (rr *RoutingRule) Validate(ctx context.Context) (err error) {
+	if err = ValidateFFNameFieldNoUUID(ctx, rr.Name, "name"); err != nil {
+		return err
+	}
+	if len(rr.Labels) == 0 {
+		return i18n.NewError(ctx, i18n.MsgRoutingRuleNoLabels)
+	}
+	if err = rr.Labels.Validate(ctx, "labels", true, FFStringNameItemsMax); err != nil {
+		return err
+	}
+	if _, err = regexp.Compile(rr.Tag); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgRoutingRuleInvalidPattern, "tag", rr.Tag)
+	}
+	if _, err = regexp.Compile(rr.Topic); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgRoutingRuleInvalidPattern, "topic", rr.Topic)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingRuleValidate(t *testing.T) {
+	ctx := context.Background()
+	rr := &RoutingRule{
+		Name:   "orders",
+		Tag:    "^order_.*",
+		Topic:  "payments|invoices",
+		Labels: FFStringArray{"finance", "orders"},
+	}
+	assert.NoError(t, rr.Validate(ctx))
+
+	rr.Name = "!bad"
+	assert.Regexp(t, "FF10131.*name", rr.Validate(ctx))
+	rr.Name = "orders"
+
+	rr.Labels = FFStringArray{}
+	assert.Regexp(t, "FF10473", rr.Validate(ctx))
+	rr.Labels = FFStringArray{"finance", "!bad"}
+	assert.Regexp(t, "FF10131.*labels\\[1\\]", rr.Validate(ctx))
+	rr.Labels = FFStringArray{"finance"}
+
+	rr.Tag = "["
+	assert.Regexp(t, "FF10474.*tag", rr.Validate(ctx))
+	rr.Tag = ""
+
+	rr.Topic = "["
+	assert.Regexp(t, "FF10474.*topic", rr.Validate(ctx))
+}
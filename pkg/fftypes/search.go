@@ -0,0 +1,37 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// SearchResultType is the type of object referred to by a full-text search hit
+type SearchResultType = FFEnum
+
+var (
+	// SearchResultTypeMessage is a hit on the tag or topics of a message
+	SearchResultTypeMessage = ffEnum("searchresulttype", "message")
+	// SearchResultTypeData is a hit on the value of a data item
+	SearchResultTypeData = ffEnum("searchresulttype", "data")
+	// SearchResultTypeBlockchainEvent is a hit on the name or output of a blockchain event
+	SearchResultTypeBlockchainEvent = ffEnum("searchresulttype", "blockchainevent")
+)
+
+// SearchResult is a single hit from a full-text search, referring to the object that matched
+type SearchResult struct {
+	Type    SearchResultType `json:"type" ffenum:"searchresulttype"`
+	ID      *UUID            `json:"id"`
+	Score   float64          `json:"score"`
+	Created *FFTime          `json:"created,omitempty"`
+}
@@ -0,0 +1,37 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// StandbyStatus is the state of a node that can be started in standby mode, attached to a replicated database
+type StandbyStatus struct {
+	Standby   bool             `json:"standby"`
+	Promoted  *FFTime          `json:"promoted,omitempty"`
+	Sequences StandbySequences `json:"sequences"`
+}
+
+// StandbySequences are the latest sequences in the database, which show how far replication has progressed
+type StandbySequences struct {
+	Messages int64 `json:"messages"`
+	Events   int64 `json:"events"`
+	Pins     int64 `json:"pins"`
+}
+
+// StandbyPromotion is a request to promote a standby node, so that it takes over processing
+type StandbyPromotion struct {
+	MinSequences *StandbySequences `json:"minSequences,omitempty"`
+	Force        bool              `json:"force,omitempty"`
+}
@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// StateSnapshotVersion is the version of the state snapshot format written by this node
+const StateSnapshotVersion = 1
+
+// StateSnapshot is a portable copy of the operational state a node derives as it processes events - the
+// offsets of its event processors, the private message ordering state, and the contract listener checkpoints.
+// It is restored on a rebuilt node, so that state does not need to be re-derived by replaying every event.
+type StateSnapshot struct {
+	Version             int                           `json:"version"`
+	Created             *FFTime                       `json:"created"`
+	Offsets             []*Offset                     `json:"offsets"`
+	NextPins            []*NextPin                    `json:"nextpins"`
+	Nonces              []*Nonce                      `json:"nonces"`
+	ListenerCheckpoints []*ContractListenerCheckpoint `json:"listenerCheckpoints"`
+}
+
+// StateSnapshotRestore is the result of restoring a state snapshot
+type StateSnapshotRestore struct {
+	Offsets             StateSnapshotRestoreCount `json:"offsets"`
+	NextPins            StateSnapshotRestoreCount `json:"nextpins"`
+	Nonces              StateSnapshotRestoreCount `json:"nonces"`
+	ListenerCheckpoints StateSnapshotRestoreCount `json:"listenerCheckpoints"`
+}
+
+// StateSnapshotRestoreCount counts the records of one type that were restored, and those that were skipped
+// because the node had already reached or passed the position in the snapshot
+type StateSnapshotRestoreCount struct {
+	Restored int `json:"restored"`
+	Skipped  int `json:"skipped"`
+}
@@ -32,6 +32,8 @@ type SubscriptionFilter struct {
 	Transaction      TransactionFilter     `json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `json:"blockchainevent,omitempty"`
 	Topic            string                `json:"topic,omitempty"`
+	TopicSet         *TopicSetFilter       `json:"topicset,omitempty"`
+	Labels           FFStringArray         `json:"labels,omitempty"`
 	DeprecatedTopics string                `json:"topics,omitempty"`
 	DeprecatedTag    string                `json:"tag,omitempty"`
 	DeprecatedGroup  string                `json:"group,omitempty"`
@@ -39,6 +41,13 @@ type SubscriptionFilter struct {
 }
 
 func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
+	var topicSet *TopicSetFilter
+	if len(query["filter.topicset.include"]) > 0 || len(query["filter.topicset.exclude"]) > 0 {
+		topicSet = &TopicSetFilter{
+			Include: query["filter.topicset.include"],
+			Exclude: query["filter.topicset.exclude"],
+		}
+	}
 	return SubscriptionFilter{
 		Events: query.Get("filter.events"),
 		Message: MessageFilter{
@@ -58,6 +67,8 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 		DeprecatedTopics: query.Get("filter.topics"),
 		DeprecatedGroup:  query.Get("filter.group"),
 		DeprecatedAuthor: query.Get("filter.author"),
+		TopicSet:         topicSet,
+		Labels:           query["filter.labels"],
 	}
 }
 
@@ -67,6 +78,13 @@ type MessageFilter struct {
 	Author string `json:"author,omitempty"`
 }
 
+// TopicSetFilter matches the topic of each event against explicit lists of topics, rather than a regular expression.
+// Each entry is a topic name, in which '*' matches any sequence of characters. Exclusions take precedence.
+type TopicSetFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
 type TransactionFilter struct {
 	Type string `json:"type,omitempty"`
 }
@@ -86,11 +104,46 @@ const (
 	SubOptsFirstEventNewest SubOptsFirstEvent = "newest"
 )
 
+// SubOptsRedeliveryPolicy is how long the dispatcher waits before redelivering an event that was rejected
+type SubOptsRedeliveryPolicy = FFEnum
+
+var (
+	// SubOptsRedeliveryImmediate redelivers a rejected event straight away
+	SubOptsRedeliveryImmediate = ffEnum("redeliverypolicy", "immediate")
+	// SubOptsRedeliveryFixed waits the same delay before every redelivery
+	SubOptsRedeliveryFixed = ffEnum("redeliverypolicy", "fixed")
+	// SubOptsRedeliveryExponential multiplies the delay by the factor on each redelivery, up to the maximum delay
+	SubOptsRedeliveryExponential = ffEnum("redeliverypolicy", "exponential")
+)
+
+// SubOptsOrderedBy is the key within which events are delivered in order, while events with different keys
+// can be delivered in parallel
+type SubOptsOrderedBy = FFEnum
+
+var (
+	// SubOptsOrderedByTopic delivers the events on each topic in order
+	SubOptsOrderedByTopic = ffEnum("orderedby", "topic")
+	// SubOptsOrderedByGroup delivers the messages sent to each private group in order. Events that are not for a group are delivered in order with each other
+	SubOptsOrderedByGroup = ffEnum("orderedby", "group")
+)
+
+// SubOptsRedelivery controls how events rejected by the consumer are redelivered.
+// When maxAttempts is set, an event rejected that many times is parked, and the subscription moves on
+type SubOptsRedelivery struct {
+	Policy      SubOptsRedeliveryPolicy `json:"policy,omitempty" ffenum:"redeliverypolicy"`
+	Delay       *FFDuration             `json:"delay,omitempty"`
+	MaxDelay    *FFDuration             `json:"maxDelay,omitempty"`
+	Factor      float64                 `json:"factor,omitempty"`
+	MaxAttempts int                     `json:"maxAttempts,omitempty"`
+}
+
 // SubscriptionCoreOptions are the core options that apply across all transports
 type SubscriptionCoreOptions struct {
 	FirstEvent *SubOptsFirstEvent `json:"firstEvent,omitempty"`
 	ReadAhead  *uint16            `json:"readAhead,omitempty"`
 	WithData   *bool              `json:"withData,omitempty"`
+	Redelivery *SubOptsRedelivery `json:"redelivery,omitempty"`
+	OrderedBy  SubOptsOrderedBy   `json:"orderedBy,omitempty" ffenum:"orderedby"`
 }
 
 // SubscriptionOptions cutomize the behavior of subscriptions
@@ -115,12 +168,80 @@ type SubscriptionRef struct {
 type Subscription struct {
 	SubscriptionRef
 
-	Transport string              `json:"transport"`
-	Filter    SubscriptionFilter  `json:"filter"`
-	Options   SubscriptionOptions `json:"options"`
-	Ephemeral bool                `json:"ephemeral,omitempty"`
-	Created   *FFTime             `json:"created"`
-	Updated   *FFTime             `json:"updated"`
+	Transport   string              `json:"transport"`
+	Filter      SubscriptionFilter  `json:"filter"`
+	Options     SubscriptionOptions `json:"options"`
+	Ephemeral   bool                `json:"ephemeral,omitempty"`
+	Annotations Annotations         `json:"annotations,omitempty"`
+	Created     *FFTime             `json:"created"`
+	Updated     *FFTime             `json:"updated"`
+}
+
+// SubscriptionStatus reports how far the consumers of a durable subscription are behind the latest event in its namespace.
+// Delivery counts and rate are since this node started, and include every connection the subscription has been delivered to
+type SubscriptionStatus struct {
+	SubscriptionRef
+
+	Connections    int     `json:"connections"`
+	LatestSequence int64   `json:"latestSequence"`
+	Offset         int64   `json:"offset"`
+	Lag            int64   `json:"lag"`
+	Delivered      int64   `json:"delivered"`
+	Redelivered    int64   `json:"redelivered"`
+	DeliveryRate   float64 `json:"deliveryRate"`
+	LastDelivery   *FFTime `json:"lastDelivery,omitempty"`
+}
+
+// SubscriptionRedelivery counts the rejected deliveries of an event on a subscription with a redelivery policy.
+// An event that reaches the maximum attempts of the policy is parked - skipped by the subscription, and kept here for inspection
+type SubscriptionRedelivery struct {
+	Subscription *UUID   `json:"subscription"`
+	Namespace    string  `json:"namespace"`
+	Event        *UUID   `json:"event"`
+	Sequence     int64   `json:"sequence"`
+	Attempts     int     `json:"attempts"`
+	Parked       bool    `json:"parked"`
+	Updated      *FFTime `json:"updated"`
+}
+
+// Validate checks the redelivery options are complete for the policy, and fills in defaults
+func (r *SubOptsRedelivery) Validate(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	if r.MaxAttempts < 0 {
+		return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "maxAttempts cannot be negative")
+	}
+	switch r.Policy {
+	case "", SubOptsRedeliveryImmediate:
+		r.Policy = SubOptsRedeliveryImmediate
+		if r.Delay != nil || r.MaxDelay != nil || r.Factor != 0 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "the immediate policy does not have a delay")
+		}
+	case SubOptsRedeliveryFixed:
+		if r.Delay == nil || *r.Delay <= 0 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "the fixed policy requires a delay")
+		}
+		if r.MaxDelay != nil || r.Factor != 0 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "maxDelay and factor only apply to the exponential policy")
+		}
+	case SubOptsRedeliveryExponential:
+		if r.Delay == nil || *r.Delay <= 0 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "the exponential policy requires a delay")
+		}
+		if r.MaxDelay == nil || *r.MaxDelay < *r.Delay {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "the exponential policy requires a maxDelay of at least the delay")
+		}
+		if r.Factor == 0 {
+			r.Factor = 2
+		}
+		if r.Factor <= 1 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "factor must be greater than 1")
+		}
+	default:
+		return i18n.NewError(ctx, i18n.MsgInvalidRedelivery, "unknown policy '"+r.Policy.String()+"'")
+	}
+	return nil
 }
 
 func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
@@ -135,6 +256,7 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "redelivery")
 	return nil
 }
 
@@ -151,6 +273,9 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.ReadAhead != nil {
 		so.additionalOptions["readAhead"] = float64(*so.ReadAhead)
 	}
+	if so.Redelivery != nil {
+		so.additionalOptions["redelivery"] = so.Redelivery
+	}
 	return json.Marshal(&so.additionalOptions)
 }
 
@@ -17,9 +17,11 @@
 package fftypes
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -94,6 +96,68 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Regexp(t, "FF10125", err)
 }
 
+func TestSubscriptionOptionsRedeliverySerialization(t *testing.T) {
+	delay := FFDuration(time.Second)
+	maxDelay := FFDuration(time.Minute)
+	opts := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			Redelivery: &SubOptsRedelivery{
+				Policy:      SubOptsRedeliveryExponential,
+				Delay:       &delay,
+				MaxDelay:    &maxDelay,
+				Factor:      1.5,
+				MaxAttempts: 5,
+			},
+		},
+	}
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"redelivery":{"policy":"exponential","delay":"1s","maxDelay":"1m0s","factor":1.5,"maxAttempts":5}}`, string(b.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	assert.Equal(t, opts.Redelivery, opts2.Redelivery)
+	assert.Nil(t, opts2.TransportOptions()["redelivery"])
+}
+
+func TestSubOptsRedeliveryValidate(t *testing.T) {
+	ctx := context.Background()
+	delay := FFDuration(time.Second)
+	maxDelay := FFDuration(time.Minute)
+	zero := FFDuration(0)
+
+	var noRedelivery *SubOptsRedelivery
+	assert.NoError(t, noRedelivery.Validate(ctx))
+
+	r := &SubOptsRedelivery{MaxAttempts: 3}
+	assert.NoError(t, r.Validate(ctx))
+	assert.Equal(t, SubOptsRedeliveryImmediate, r.Policy)
+
+	r = &SubOptsRedelivery{Policy: SubOptsRedeliveryExponential, Delay: &delay, MaxDelay: &maxDelay}
+	assert.NoError(t, r.Validate(ctx))
+	assert.Equal(t, float64(2), r.Factor)
+
+	assert.NoError(t, (&SubOptsRedelivery{Policy: SubOptsRedeliveryFixed, Delay: &delay}).Validate(ctx))
+
+	for _, tc := range []struct {
+		r   *SubOptsRedelivery
+		err string
+	}{
+		{&SubOptsRedelivery{MaxAttempts: -1}, "FF10502.*maxAttempts"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryImmediate, Delay: &delay}, "FF10502.*immediate"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryFixed}, "FF10502.*fixed"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryFixed, Delay: &zero}, "FF10502.*fixed"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryFixed, Delay: &delay, Factor: 2}, "FF10502.*exponential"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryExponential}, "FF10502.*delay"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryExponential, Delay: &maxDelay, MaxDelay: &delay}, "FF10502.*maxDelay"},
+		{&SubOptsRedelivery{Policy: SubOptsRedeliveryExponential, Delay: &delay, MaxDelay: &maxDelay, Factor: 0.5}, "FF10502.*factor"},
+		{&SubOptsRedelivery{Policy: "linear"}, "FF10502.*linear"},
+	} {
+		assert.Regexp(t, tc.err, tc.r.Validate(ctx))
+	}
+}
+
 func TestSubscriptionUnMarshalFail(t *testing.T) {
 
 	b, err := json.Marshal(&SubscriptionOptions{})
@@ -108,10 +172,14 @@ func TestSubscriptionUnMarshalFail(t *testing.T) {
 }
 
 func TestNewSubscriptionFilterFromQuery(t *testing.T) {
-	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated")
+	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated&filter.topicset.include=a*&filter.topicset.include=b&filter.topicset.exclude=ab&filter.labels=l1&filter.labels=l2")
 	expectedFilter := SubscriptionFilter{
 		Events: "message_confirmed",
 		Topic:  "topic1",
+		TopicSet: &TopicSetFilter{
+			Include: []string{"a*", "b"},
+			Exclude: []string{"ab"},
+		},
 		Message: MessageFilter{
 			Author: "did:firefly:org/author1",
 		},
@@ -121,6 +189,7 @@ func TestNewSubscriptionFilterFromQuery(t *testing.T) {
 		Transaction: TransactionFilter{
 			Type: "test",
 		},
+		Labels:          FFStringArray{"l1", "l2"},
 		DeprecatedGroup: "deprecated",
 	}
 	filter := NewSubscriptionFilterFromQuery(query)
@@ -0,0 +1,56 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// SyncChangeType is the type of object that has changed, in the incremental sync API
+type SyncChangeType = FFEnum
+
+var (
+	// SyncChangeTypeOperation is an operation that has been created or updated
+	SyncChangeTypeOperation = ffEnum("syncchangetype", "operation")
+	// SyncChangeTypeTransaction is a transaction that has been created or updated
+	SyncChangeTypeTransaction = ffEnum("syncchangetype", "transaction")
+	// SyncChangeTypeEvent is an event that has been created (events are immutable)
+	SyncChangeTypeEvent = ffEnum("syncchangetype", "event")
+)
+
+// SyncChangeRef is an entry in the change log of a namespace, recording that an object changed.
+// The sequence of the change log increases in the order the changes were committed
+type SyncChangeRef struct {
+	Sequence  int64          `json:"sequence"`
+	Namespace string         `json:"namespace"`
+	Type      SyncChangeType `json:"type" ffenum:"syncchangetype"`
+	Reference *UUID          `json:"reference"`
+	Changed   *FFTime        `json:"changed"`
+}
+
+// SyncChange is a single change to an object, with the latest state of the object embedded
+type SyncChange struct {
+	Sequence    int64          `json:"sequence"`
+	Type        SyncChangeType `json:"type" ffenum:"syncchangetype"`
+	Changed     *FFTime        `json:"changed"`
+	Operation   *Operation     `json:"operation,omitempty"`
+	Transaction *Transaction   `json:"transaction,omitempty"`
+	Event       *Event         `json:"event,omitempty"`
+}
+
+// SyncChanges is a page of changes in the order they were made. The Next sequence should be passed
+// as the "since" value of the next request, to continue where this page left off.
+type SyncChanges struct {
+	Changes []*SyncChange `json:"changes"`
+	Next    int64         `json:"next"`
+}
@@ -16,6 +16,20 @@
 
 package fftypes
 
+// TokenConnector describes a configured token connector, and what it reported when it was last queried
 type TokenConnector struct {
-	Name string `json:"name,omitempty"`
+	Name         string                      `json:"name,omitempty"`
+	Reachable    bool                        `json:"reachable"`
+	Error        string                      `json:"error,omitempty"`
+	Capabilities *TokenConnectorCapabilities `json:"capabilities,omitempty"`
+	Standards    []string                    `json:"standards,omitempty"`
+}
+
+// TokenConnectorCapabilities are the optional features a token connector reports that it supports
+type TokenConnectorCapabilities struct {
+	Version        string `json:"version,omitempty"`
+	Approvals      bool   `json:"approvals"`
+	URIs           bool   `json:"uris"`
+	Deploy         bool   `json:"deploy"`
+	BatchTransfers bool   `json:"batchTransfers"`
 }
@@ -18,6 +18,10 @@ package fftypes
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
 )
 
 type TokenType = FFEnum
@@ -40,22 +44,99 @@ var (
 	TokenPoolStateConfirmed = ffEnum("tokenpoolstate", "confirmed")
 )
 
+// TokenPoolBackfillState is the progress of replaying the historical transfers of a token pool
+type TokenPoolBackfillState = FFEnum
+
+var (
+	// TokenPoolBackfillStatePending is a backfill that has been requested, but the pool has not yet been activated
+	TokenPoolBackfillStatePending = ffEnum("tokenpoolbackfillstate", "pending")
+	// TokenPoolBackfillStateRunning is a backfill where the connector is replaying historical transfers
+	TokenPoolBackfillStateRunning = ffEnum("tokenpoolbackfillstate", "running")
+	// TokenPoolBackfillStateComplete is a backfill where the connector has replayed all historical transfers
+	TokenPoolBackfillStateComplete = ffEnum("tokenpoolbackfillstate", "complete")
+)
+
 type TokenPool struct {
-	ID         *UUID          `json:"id,omitempty"`
-	Type       TokenType      `json:"type" ffenum:"tokentype"`
-	Namespace  string         `json:"namespace,omitempty"`
-	Name       string         `json:"name,omitempty"`
-	Standard   string         `json:"standard,omitempty"`
-	ProtocolID string         `json:"protocolId,omitempty"`
-	Key        string         `json:"key,omitempty"`
-	Symbol     string         `json:"symbol,omitempty"`
-	Connector  string         `json:"connector,omitempty"`
-	Message    *UUID          `json:"message,omitempty"`
-	State      TokenPoolState `json:"state,omitempty" ffenum:"tokenpoolstate"`
-	Created    *FFTime        `json:"created,omitempty"`
-	Config     JSONObject     `json:"config,omitempty"` // for REST calls only (not stored)
-	Info       JSONObject     `json:"info,omitempty"`
-	TX         TransactionRef `json:"tx,omitempty"`
+	ID           *UUID                  `json:"id,omitempty"`
+	Type         TokenType              `json:"type" ffenum:"tokentype"`
+	Namespace    string                 `json:"namespace,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	Standard     string                 `json:"standard,omitempty"`
+	ProtocolID   string                 `json:"protocolId,omitempty"`
+	Key          string                 `json:"key,omitempty"`
+	Symbol       string                 `json:"symbol,omitempty"`
+	Connector    string                 `json:"connector,omitempty"`
+	Message      *UUID                  `json:"message,omitempty"`
+	State        TokenPoolState         `json:"state,omitempty" ffenum:"tokenpoolstate"`
+	Created      *FFTime                `json:"created,omitempty"`
+	Config       JSONObject             `json:"config,omitempty"` // for REST calls only (not stored)
+	Info         JSONObject             `json:"info,omitempty"`
+	TX           TransactionRef         `json:"tx,omitempty"`
+	Deploy       *TokenPoolDeploy       `json:"deploy,omitempty"` // for REST calls only (not stored)
+	Backfill     *TokenPoolBackfill     `json:"backfill,omitempty"`
+	IngestFilter *TokenPoolIngestFilter `json:"ingestFilter,omitempty"`
+	Annotations  Annotations            `json:"annotations,omitempty"`
+}
+
+// TokenPoolDeploy requests that the token connector deploys a new token contract for the pool,
+// rather than using an existing one. The address of the deployed contract is recorded in the pool info.
+type TokenPoolDeploy struct {
+	Params *JSONAny `json:"params,omitempty"` // constructor parameters, in the format required by the connector
+}
+
+// TokenPoolBackfill requests that the connector replays the historical transfers of an existing token contract,
+// from the given block, when the pool is activated. The progress of the replay is recorded as the transfers arrive.
+type TokenPoolBackfill struct {
+	FromBlock string                 `json:"fromBlock"`
+	State     TokenPoolBackfillState `json:"state,omitempty" ffenum:"tokenpoolbackfillstate"`
+	Block     string                 `json:"block,omitempty"`
+	Transfers int64                  `json:"transfers"`
+	Updated   *FFTime                `json:"updated,omitempty"`
+}
+
+// Scan implements sql.Scanner
+func (b *TokenPoolBackfill) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &b)
+	case []byte:
+		return json.Unmarshal(src, &b)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, b)
+	}
+}
+
+func (b TokenPoolBackfill) Value() (driver.Value, error) {
+	bytes, _ := json.Marshal(b)
+	return bytes, nil
+}
+
+// TokenPoolIngestFilter limits the transfers of a pool that are recorded, when they were not submitted through FireFly.
+// Transfers that do not pass the filter are dropped before they are stored, but still update the token balances.
+type TokenPoolIngestFilter struct {
+	Members   bool      `json:"members,omitempty"`
+	MinAmount *FFBigInt `json:"minAmount,omitempty"`
+}
+
+// Scan implements sql.Scanner
+func (f *TokenPoolIngestFilter) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &f)
+	case []byte:
+		return json.Unmarshal(src, &f)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, f)
+	}
+}
+
+func (f TokenPoolIngestFilter) Value() (driver.Value, error) {
+	bytes, _ := json.Marshal(f)
+	return bytes, nil
 }
 
 type TokenPoolAnnouncement struct {
@@ -70,7 +151,7 @@ func (t *TokenPool) Validate(ctx context.Context) (err error) {
 	if err = ValidateFFNameFieldNoUUID(ctx, t.Name, "name"); err != nil {
 		return err
 	}
-	return nil
+	return t.Annotations.Validate(ctx)
 }
 
 func (t *TokenPoolAnnouncement) Topic() string {
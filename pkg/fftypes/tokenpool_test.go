@@ -38,6 +38,14 @@ func TestTokenPoolValidation(t *testing.T) {
 	err = pool.Validate(context.Background())
 	assert.Regexp(t, "FF10131.*'name'", err)
 
+	pool = &TokenPool{
+		Namespace:   "ok",
+		Name:        "ok",
+		Annotations: Annotations{"!wrong": "ok"},
+	}
+	err = pool.Validate(context.Background())
+	assert.Regexp(t, "FF10131.*'annotations.!wrong'", err)
+
 	pool = &TokenPool{
 		Namespace: "ok",
 		Name:      "ok",
@@ -58,3 +66,77 @@ func TestTokenPoolDefinition(t *testing.T) {
 	def.SetBroadcastMessage(id)
 	assert.Equal(t, id, pool.Message)
 }
+
+func TestTokenPoolBackfillScan(t *testing.T) {
+	backfill := &TokenPoolBackfill{}
+	err := backfill.Scan([]byte(`{"fromBlock":"100","state":"running","block":"150","transfers":5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "100", backfill.FromBlock)
+	assert.Equal(t, TokenPoolBackfillStateRunning, backfill.State)
+	assert.Equal(t, int64(5), backfill.Transfers)
+}
+
+func TestTokenPoolBackfillScanNil(t *testing.T) {
+	backfill := &TokenPoolBackfill{}
+	err := backfill.Scan(nil)
+	assert.NoError(t, err)
+}
+
+func TestTokenPoolBackfillScanString(t *testing.T) {
+	backfill := &TokenPoolBackfill{}
+	err := backfill.Scan(`{"fromBlock":"100"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", backfill.FromBlock)
+}
+
+func TestTokenPoolBackfillScanError(t *testing.T) {
+	backfill := &TokenPoolBackfill{}
+	err := backfill.Scan(false)
+	assert.Regexp(t, "FF10125", err)
+}
+
+func TestTokenPoolBackfillValue(t *testing.T) {
+	backfill := &TokenPoolBackfill{
+		FromBlock: "100",
+		State:     TokenPoolBackfillStatePending,
+	}
+	val, err := backfill.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"fromBlock":"100","state":"pending","transfers":0}`, string(val.([]byte)))
+}
+
+func TestTokenPoolIngestFilterScan(t *testing.T) {
+	filter := &TokenPoolIngestFilter{}
+	err := filter.Scan([]byte(`{"members":true,"minAmount":"1000"}`))
+	assert.NoError(t, err)
+	assert.True(t, filter.Members)
+	assert.Equal(t, int64(1000), filter.MinAmount.Int().Int64())
+}
+
+func TestTokenPoolIngestFilterScanNil(t *testing.T) {
+	filter := &TokenPoolIngestFilter{}
+	err := filter.Scan(nil)
+	assert.NoError(t, err)
+}
+
+func TestTokenPoolIngestFilterScanString(t *testing.T) {
+	filter := &TokenPoolIngestFilter{}
+	err := filter.Scan(`{"members":true}`)
+	assert.NoError(t, err)
+	assert.True(t, filter.Members)
+}
+
+func TestTokenPoolIngestFilterScanError(t *testing.T) {
+	filter := &TokenPoolIngestFilter{}
+	err := filter.Scan(false)
+	assert.Regexp(t, "FF10125", err)
+}
+
+func TestTokenPoolIngestFilterValue(t *testing.T) {
+	filter := &TokenPoolIngestFilter{
+		MinAmount: NewFFBigInt(1000),
+	}
+	val, err := filter.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"minAmount":"1000"}`, string(val.([]byte)))
+}
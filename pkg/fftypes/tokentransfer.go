@@ -37,6 +37,7 @@ type TokenTransfer struct {
 	To              string            `json:"to,omitempty"`
 	Amount          FFBigInt          `json:"amount"`
 	ProtocolID      string            `json:"protocolId,omitempty"`
+	BusinessKey     string            `json:"businessKey,omitempty"`
 	Message         *UUID             `json:"message,omitempty"`
 	MessageHash     *Bytes32          `json:"messageHash,omitempty"`
 	Created         *FFTime           `json:"created,omitempty"`
@@ -46,6 +47,18 @@ type TokenTransfer struct {
 
 type TokenTransferInput struct {
 	TokenTransfer
-	Message *MessageInOut `json:"message,omitempty"`
-	Pool    string        `json:"pool,omitempty"`
+	Message     *MessageInOut `json:"message,omitempty"`
+	Pool        string        `json:"pool,omitempty"`
+	CallbackURL string        `json:"callbackUrl,omitempty"`
+}
+
+// TokenTransferBulkInput is a group of mints, burns and transfers that are validated together, and submitted under a single transaction
+type TokenTransferBulkInput struct {
+	Transfers []*TokenTransferInput `json:"transfers"`
+}
+
+// TokenTransferBulk is the outcome of submitting a TokenTransferBulkInput, with an operation for each transfer in the transaction
+type TokenTransferBulk struct {
+	TX        TransactionRef   `json:"tx"`
+	Transfers []*TokenTransfer `json:"transfers"`
 }
@@ -33,8 +33,16 @@ var (
 	TransactionTypeTokenTransfer = ffEnum("txtype", "token_transfer")
 	// TransactionTypeContractInvoke is a smart contract invoke
 	TransactionTypeContractInvoke = ffEnum("txtype", "contract_invoke")
+	// TransactionTypeContractDeploy is a smart contract deployment
+	TransactionTypeContractDeploy = ffEnum("txtype", "contract_deploy")
 	// TransactionTypeTokenTransfer represents a token approval
 	TransactionTypeTokenApproval = ffEnum("txtype", "token_approval")
+	// TransactionTypeMessageImport is a bulk import of messages, each of which is sent in its own transaction
+	TransactionTypeMessageImport = ffEnum("txtype", "message_import")
+	// TransactionTypeDataExport is an export of historical records to files, which does not submit anything to the blockchain
+	TransactionTypeDataExport = ffEnum("txtype", "data_export")
+	// TransactionTypeKeyRegistration is a registration of the org signing key with the batch pin contract
+	TransactionTypeKeyRegistration = ffEnum("txtype", "key_registration")
 )
 
 // TransactionRef refers to a transaction, in other types
@@ -50,7 +58,11 @@ type Transaction struct {
 	Namespace     string          `json:"namespace,omitempty"`
 	Type          TransactionType `json:"type" ffenum:"txtype"`
 	Created       *FFTime         `json:"created"`
+	Updated       *FFTime         `json:"updated,omitempty"`
 	BlockchainIDs FFStringArray   `json:"blockchainIds,omitempty"`
+	Fee           *TransactionFee `json:"fee,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	ChainID       int64           `json:"chainId,omitempty"`
 }
 
 type TransactionStatusType string
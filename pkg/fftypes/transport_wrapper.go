@@ -16,6 +16,17 @@
 
 package fftypes
 
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
 type TransportPayloadType = FFEnum
 
 var (
@@ -25,13 +36,60 @@ var (
 
 // TransportWrapper wraps paylaods over data exchange transfers, for easy deserialization at target
 type TransportWrapper struct {
-	Group *Group `json:"group,omitempty"`
-	Batch *Batch `json:"batch,omitempty"`
+	Group *Group       `json:"group,omitempty"`
+	Batch *Batch       `json:"batch,omitempty"`
+	Ack   *DeliveryAck `json:"ack,omitempty"`
 }
 
 type TransportStatusUpdate struct {
-	Error    string     `json:"error,omitempty"`
-	Manifest string     `json:"manifest,omitempty"`
-	Info     JSONObject `json:"info,omitempty"`
-	Hash     string     `json:"hash,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Manifest string            `json:"manifest,omitempty"`
+	Info     JSONObject        `json:"info,omitempty"`
+	Hash     string            `json:"hash,omitempty"`
+	Progress *TransferProgress `json:"progress,omitempty"`
+}
+
+// TransferProgress is reported by the data exchange for a large transfer that is still in progress
+type TransferProgress struct {
+	Transferred int64   `json:"transferred"`
+	Total       int64   `json:"total"`
+	Percent     float64 `json:"percent"`
+}
+
+// SerializeTransportPayload serializes a batch (or a transport wrapper containing one) for exchange
+// with other members of the network, using the payload encoding of the negotiated protocol version
+func SerializeTransportPayload(ctx context.Context, protocolVersion uint, payload interface{}) ([]byte, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgSerializationFailed)
+	}
+	if protocolVersion < ProtocolVersion2 {
+		return b, nil
+	}
+	var buff bytes.Buffer
+	zw := gzip.NewWriter(&buff)
+	_, _ = zw.Write(b) // writes to a bytes.Buffer cannot fail
+	_ = zw.Close()
+	return buff.Bytes(), nil
+}
+
+// DeserializeTransportPayload parses a batch (or a transport wrapper containing one) received from another
+// member of the network. The encoding is self describing, and determines the protocol version that is returned.
+// Compressed payloads are rejected if they decompress to more than maxLength bytes.
+func DeserializeTransportPayload(ctx context.Context, data []byte, maxLength int64, payload interface{}) (protocolVersion uint, err error) {
+	protocolVersion = ProtocolVersion1
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b /* gzip magic number */ {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err == nil {
+			data, err = ioutil.ReadAll(io.LimitReader(zr, maxLength+1))
+		}
+		if err != nil {
+			return 0, i18n.WrapError(ctx, err, i18n.MsgInvalidTransportEncoding)
+		}
+		if int64(len(data)) > maxLength {
+			return 0, i18n.NewError(ctx, i18n.MsgTransportPayloadTooLarge, maxLength)
+		}
+		protocolVersion = ProtocolVersion2
+	}
+	return protocolVersion, json.Unmarshal(data, payload)
 }
@@ -17,6 +17,8 @@
 package fftypes
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,7 +41,7 @@ func TestBatchManifest(t *testing.T) {
 		},
 	}
 	bp, _ := tw.Batch.Confirmed()
-	tm := bp.GenManifest(tw.Batch.Payload.Messages, tw.Batch.Payload.Data)
+	tm := bp.GenManifest(tw.Batch.Payload.Messages, tw.Batch.Payload.Data, ProtocolVersion1)
 	assert.Equal(t, 2, len(tm.Messages))
 	assert.Equal(t, tw.Batch.Payload.Messages[0].Header.ID.String(), tm.Messages[0].ID.String())
 	assert.Equal(t, tw.Batch.Payload.Messages[1].Header.ID.String(), tm.Messages[1].ID.String())
@@ -52,3 +54,63 @@ func TestBatchManifest(t *testing.T) {
 	assert.Equal(t, tw.Batch.Payload.Data[1].Hash.String(), tm.Data[1].Hash.String())
 
 }
+
+func TestTransportPayloadV1RoundTrip(t *testing.T) {
+
+	batch := &Batch{BatchHeader: BatchHeader{ID: NewUUID()}}
+	b, err := SerializeTransportPayload(context.Background(), ProtocolVersion1, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, byte('{'), b[0])
+
+	var batch2 *Batch
+	version, err := DeserializeTransportPayload(context.Background(), b, 1024, &batch2)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion1, version)
+	assert.Equal(t, batch.ID, batch2.ID)
+
+}
+
+func TestTransportPayloadV2RoundTrip(t *testing.T) {
+
+	batch := &Batch{BatchHeader: BatchHeader{ID: NewUUID()}}
+	b, err := SerializeTransportPayload(context.Background(), ProtocolVersion2, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x1f), b[0])
+
+	var batch2 *Batch
+	version, err := DeserializeTransportPayload(context.Background(), b, 1024, &batch2)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion2, version)
+	assert.Equal(t, batch.ID, batch2.ID)
+
+}
+
+func TestSerializeTransportPayloadFail(t *testing.T) {
+
+	_, err := SerializeTransportPayload(context.Background(), ProtocolVersion2, map[bool]bool{false: true})
+	assert.Regexp(t, "FF10137", err)
+
+}
+
+func TestDeserializeTransportPayloadBadGzip(t *testing.T) {
+
+	var batch *Batch
+	_, err := DeserializeTransportPayload(context.Background(), []byte{0x1f, 0x8b, 0x00}, 1024, &batch)
+	assert.Regexp(t, "FF10380", err)
+
+}
+
+func TestDeserializeTransportPayloadTooLarge(t *testing.T) {
+
+	b, err := SerializeTransportPayload(context.Background(), ProtocolVersion2, strings.Repeat("a", 10000))
+	assert.NoError(t, err)
+	assert.Less(t, len(b), 1024)
+
+	var s string
+	_, err = DeserializeTransportPayload(context.Background(), b, 1024, &s)
+	assert.Regexp(t, "FF10560", err)
+
+	_, err = DeserializeTransportPayload(context.Background(), b, 10002, &s)
+	assert.NoError(t, err)
+
+}
@@ -19,6 +19,7 @@ package fftypes
 import (
 	"context"
 	"regexp"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 )
@@ -42,6 +43,17 @@ func ValidateFFNameField(ctx context.Context, str string, fieldName string) erro
 	return nil
 }
 
+// ValidateTagField validates a message tag, which is either a name, or a name prefixed with the name of an
+// application in the form "<app>:<name>" - such as "myapp:config". The whole tag must fit in 64 characters.
+func ValidateTagField(ctx context.Context, str string, fieldName string) error {
+	for _, part := range strings.SplitN(str, ":", 2) {
+		if err := ValidateFFNameField(ctx, part, fieldName); err != nil {
+			return err
+		}
+	}
+	return ValidateLength(ctx, str, fieldName, 64)
+}
+
 func ValidateFFNameFieldNoUUID(ctx context.Context, str string, fieldName string) error {
 	if _, err := ParseUUID(ctx, str); err == nil {
 		// Name must not be a UUID
@@ -45,6 +45,28 @@ func TestValidateFFNameField(t *testing.T) {
 
 }
 
+func TestValidateTagField(t *testing.T) {
+
+	err := ValidateTagField(context.Background(), "myapp.config", "tag")
+	assert.NoError(t, err)
+
+	err = ValidateTagField(context.Background(), "myapp:config", "tag")
+	assert.NoError(t, err)
+
+	err = ValidateTagField(context.Background(), "myapp:", "tag")
+	assert.Regexp(t, "FF10131.*tag", err)
+
+	err = ValidateTagField(context.Background(), ":config", "tag")
+	assert.Regexp(t, "FF10131.*tag", err)
+
+	err = ValidateTagField(context.Background(), "myapp:config:v1", "tag")
+	assert.Regexp(t, "FF10131.*tag", err)
+
+	err = ValidateTagField(context.Background(), "0123456789_123456789-123456789:123456789-123456789_123456789012345", "tag")
+	assert.Regexp(t, "FF10188.*tag", err)
+
+}
+
 func TestValidateLength(t *testing.T) {
 
 	err := ValidateLength(context.Background(), "long string", "test", 5)
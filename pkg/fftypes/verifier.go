@@ -42,6 +42,7 @@ type Verifier struct {
 	Identity  *UUID    `json:"identity,omitempty"`
 	Namespace string   `json:"namespace,omitempty"`
 	VerifierRef
+	Pinning bool    `json:"pinning,omitempty"` // Authorized to sign batch pins on behalf of the identity, and its parent
 	Created *FFTime `json:"created,omitempty"`
 }
 
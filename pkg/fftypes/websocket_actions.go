@@ -48,6 +48,8 @@ type WSClientActionStartPayload struct {
 	Filter       SubscriptionFilter  `json:"filter"`
 	Options      SubscriptionOptions `json:"options"`
 	ChangeEvents string              `json:"changeEvents,omitempty"`
+	// Group joins a group of connections that share the offset of a durable subscription, with events load balanced between them
+	Group string `json:"group,omitempty"`
 }
 
 // WSClientActionAckPayload acknowldges a received event (not applicable in AutoAck mode)
@@ -56,6 +58,12 @@ type WSClientActionAckPayload struct {
 
 	ID           *UUID            `json:"id,omitempty"`
 	Subscription *SubscriptionRef `json:"subscription,omitempty"`
+	// Rejected requests redelivery of the event, and all events after it on the subscription
+	Rejected bool   `json:"rejected,omitempty"`
+	Info     string `json:"info,omitempty"`
+	// Reply is a message to send in reply to the event, routed by ReplyTo if it is set
+	Reply   *MessageInOut `json:"reply,omitempty"`
+	ReplyTo *ReplyRouting `json:"replyTo,omitempty"`
 }
 
 // WSProtocolErrorPayload is sent to the client by the server in the case of a protocol error
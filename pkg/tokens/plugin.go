@@ -41,9 +41,23 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// Health returns an error describing the problem, if the plugin is not currently connected to its connector
+	Health(ctx context.Context) error
+
+	// ConnectorCapabilities queries the connector for the optional features and token standards it supports.
+	// Returns nil capabilities (without error) if the connector predates the capabilities query
+	ConnectorCapabilities(ctx context.Context) (*fftypes.TokenConnectorCapabilities, []string, error)
+
+	// ConnectorWarnings returns any problems found with the protocol version the connector reported, when the
+	// plugin last negotiated the version with it
+	ConnectorWarnings() []string
+
 	// CreateTokenPool creates a new (fungible or non-fungible) pool of tokens
 	CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error)
 
+	// DeployTokenPool deploys a new token contract, using the constructor parameters in pool.Deploy, and creates a pool on it
+	DeployTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error)
+
 	// ActivateTokenPool activates a pool in order to begin receiving events
 	ActivateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool, blockchainInfo fftypes.JSONObject) (complete bool, err error)
 
@@ -56,6 +70,10 @@ type Plugin interface {
 	// TransferTokens transfers tokens within a pool from one account to another
 	TransferTokens(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, transfer *fftypes.TokenTransfer) error
 
+	// TransferTokensBatch submits a group of mints, burns and transfers to the connector in a single request.
+	// Only called if the connector reports the batchTransfers capability
+	TransferTokensBatch(ctx context.Context, requests []*TokenTransferRequest) error
+
 	// TokenApproval approves an operator to transfer tokens on the owner's behalf
 	TokensApproval(ctx context.Context, opID *fftypes.UUID, poolProtocolID string, approval *fftypes.TokenApproval) error
 }
@@ -90,12 +108,30 @@ type Callbacks interface {
 	//
 	// Error should will only be returned in shutdown scenarios
 	TokensApproved(plugin Plugin, approval *TokenApproval) error
+
+	// TokenPoolBackfillProgress notifies on the progress of replaying the historical transfers of a pool.
+	// The replayed transfers themselves are delivered to TokensTransferred, before the progress that covers them.
+	//
+	// Error should only be returned in shutdown scenarios
+	TokenPoolBackfillProgress(plugin Plugin, progress *TokenPoolBackfillProgress) error
 }
 
 // Capabilities is the supported featureset of the tokens interface implemented by the plugin, with the specified config
 type Capabilities struct {
 }
 
+// TokenTransferRequest is a single mint, burn or transfer within a batch submitted to the connector
+type TokenTransferRequest struct {
+	// OpID is the operation tracking this transfer, which the connector reports against in the receipt
+	OpID *fftypes.UUID
+
+	// PoolProtocolID is the ID assigned to the pool by the connector
+	PoolProtocolID string
+
+	// Transfer is the mint, burn or transfer to perform
+	Transfer *fftypes.TokenTransfer
+}
+
 // TokenPool is the set of data returned from the connector when a token pool is created.
 type TokenPool struct {
 	// Type is the type of tokens (fungible, non-fungible, etc) in this pool
@@ -123,6 +159,23 @@ type TokenPool struct {
 	Event blockchain.Event
 }
 
+type TokenPoolBackfillProgress struct {
+	// PoolProtocolID is the ID assigned to the pool by the connector
+	PoolProtocolID string
+
+	// Connector is the configured name of this connector
+	Connector string
+
+	// Block is the last block the connector has replayed transfers from
+	Block string
+
+	// Transfers is the number of historical transfers the connector has replayed so far
+	Transfers int64
+
+	// Complete is set when the connector has caught up, and all further transfers are delivered as they happen
+	Complete bool
+}
+
 type TokenTransfer struct {
 	// Although not every field will be filled in, embed fftypes.TokenTransfer to avoid duplicating lots of fields
 	// Notable fields NOT expected to be populated by plugins: Namespace, LocalID, Pool
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/retry"
@@ -45,8 +46,35 @@ type WSConfig struct {
 	AuthPassword           string             `json:"authPassword,omitempty"`
 	HTTPHeaders            fftypes.JSONObject `json:"headers,omitempty"`
 	HeartbeatInterval      time.Duration      `json:"heartbeatInterval,omitempty"`
+	HeartbeatTimeout       time.Duration      `json:"heartbeatTimeout,omitempty"`
+	FaultTarget            string             `json:"faultTarget,omitempty"`
+	AuthProvider           AuthProvider       `json:"-"`
 }
 
+// AuthProvider supplies the Authorization header for each connection attempt, so that credentials
+// rotated while the client is running are used when it reconnects
+type AuthProvider interface {
+	// Authorization returns the current value of the Authorization header, obtaining it if required
+	Authorization(ctx context.Context) (string, error)
+
+	// Refresh discards the current credentials, so that the next call to Authorization obtains new ones
+	Refresh(ctx context.Context)
+}
+
+// WSConnectionState is the state of the underlying websocket connection
+type WSConnectionState string
+
+const (
+	// WSStateConnecting is set while attempting to establish (or re-establish) the connection
+	WSStateConnecting WSConnectionState = "connecting"
+	// WSStateConnected is set once the connection has been established
+	WSStateConnected WSConnectionState = "connected"
+	// WSStateDisconnected is set when an established connection is lost, before reconnecting
+	WSStateDisconnected WSConnectionState = "disconnected"
+	// WSStateClosed is set when the client is closed, and will not reconnect
+	WSStateClosed WSConnectionState = "closed"
+)
+
 type WSClient interface {
 	Connect() error
 	Receive() <-chan []byte
@@ -54,6 +82,9 @@ type WSClient interface {
 	SetURL(url string)
 	Send(ctx context.Context, message []byte) error
 	Close()
+	State() WSConnectionState
+	OnStateChange(handler WSStateChangeHandler)
+	AddAfterReconnectHook(hook WSPostConnectHandler)
 }
 
 type wsClient struct {
@@ -72,9 +103,16 @@ type wsClient struct {
 	beforeConnect        WSPreConnectHandler
 	afterConnect         WSPostConnectHandler
 	heartbeatInterval    time.Duration
+	pongTimeout          time.Duration
 	heartbeatMux         sync.Mutex
 	activePingSent       *time.Time
 	lastPingCompleted    time.Time
+	stateMux             sync.Mutex
+	state                WSConnectionState
+	stateHandlers        []WSStateChangeHandler
+	reconnectHooks       []WSPostConnectHandler
+	faultTarget          string
+	authProvider         AuthProvider
 }
 
 // WSPreConnectHandler will be called before every connect/reconnect. Any error returned will prevent the websocket from connecting.
@@ -83,6 +121,9 @@ type WSPreConnectHandler func(ctx context.Context) error
 // WSPostConnectHandler will be called after every connect/reconnect. Can send data over ws, but must not block listening for data on the ws.
 type WSPostConnectHandler func(ctx context.Context, w WSClient) error
 
+// WSStateChangeHandler will be called each time the connection state changes. Must not block.
+type WSStateChangeHandler func(ctx context.Context, state WSConnectionState)
+
 func New(ctx context.Context, config *WSConfig, beforeConnect WSPreConnectHandler, afterConnect WSPostConnectHandler) (WSClient, error) {
 
 	wsURL, err := buildWSUrl(ctx, config)
@@ -109,6 +150,13 @@ func New(ctx context.Context, config *WSConfig, beforeConnect WSPreConnectHandle
 		beforeConnect:        beforeConnect,
 		afterConnect:         afterConnect,
 		heartbeatInterval:    config.HeartbeatInterval,
+		pongTimeout:          config.HeartbeatTimeout,
+		state:                WSStateDisconnected,
+		faultTarget:          config.FaultTarget,
+		authProvider:         config.AuthProvider,
+	}
+	if w.pongTimeout <= 0 {
+		w.pongTimeout = w.heartbeatInterval
 	}
 	for k, v := range config.HTTPHeaders {
 		if vs, ok := v.(string); ok {
@@ -138,6 +186,8 @@ func (w *wsClient) Connect() error {
 func (w *wsClient) Close() {
 	if !w.closed {
 		w.closed = true
+		// Set before closing the connection, so the reader exiting does not report a disconnect
+		w.setState(WSStateClosed)
 		close(w.closing)
 		c := w.wsconn
 		if c != nil {
@@ -146,6 +196,60 @@ func (w *wsClient) Close() {
 	}
 }
 
+func (w *wsClient) State() WSConnectionState {
+	w.stateMux.Lock()
+	defer w.stateMux.Unlock()
+	return w.state
+}
+
+// OnStateChange registers a handler to be notified of every connection state change
+func (w *wsClient) OnStateChange(handler WSStateChangeHandler) {
+	w.stateMux.Lock()
+	defer w.stateMux.Unlock()
+	w.stateHandlers = append(w.stateHandlers, handler)
+}
+
+// AddAfterReconnectHook registers a hook to be called, in registration order, each time the
+// connection is re-established after being lost. Hooks run after the afterConnect handler,
+// and a hook returning an error causes the connection to be retried.
+func (w *wsClient) AddAfterReconnectHook(hook WSPostConnectHandler) {
+	w.stateMux.Lock()
+	defer w.stateMux.Unlock()
+	w.reconnectHooks = append(w.reconnectHooks, hook)
+}
+
+func (w *wsClient) setState(state WSConnectionState) {
+	w.stateMux.Lock()
+	if w.state == state || w.state == WSStateClosed {
+		w.stateMux.Unlock()
+		return
+	}
+	w.state = state
+	handlers := make([]WSStateChangeHandler, len(w.stateHandlers))
+	copy(handlers, w.stateHandlers)
+	w.stateMux.Unlock()
+
+	log.L(w.ctx).Debugf("WS %s state: %s", w.url, state)
+	for _, handler := range handlers {
+		handler(w.ctx, state)
+	}
+}
+
+func (w *wsClient) runReconnectHooks() error {
+	w.stateMux.Lock()
+	hooks := make([]WSPostConnectHandler, len(w.reconnectHooks))
+	copy(hooks, w.reconnectHooks)
+	w.stateMux.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook(w.ctx, w); err != nil {
+			log.L(w.ctx).Errorf("WS %s reconnect hook failed: %s", w.url, err)
+			return err
+		}
+	}
+	return nil
+}
+
 // Receive returns
 func (w *wsClient) Receive() <-chan []byte {
 	return w.receive
@@ -175,11 +279,13 @@ func (w *wsClient) heartbeatTimeout(ctx context.Context) (context.Context, conte
 	if w.heartbeatInterval > 0 {
 		w.heartbeatMux.Lock()
 		baseTime := w.lastPingCompleted
+		interval := w.heartbeatInterval
 		if w.activePingSent != nil {
 			// We're waiting for a pong
 			baseTime = *w.activePingSent
+			interval = w.pongTimeout
 		}
-		waitTime := w.heartbeatInterval - time.Since(baseTime) // if negative, will pop immediately
+		waitTime := interval - time.Since(baseTime) // if negative, will pop immediately
 		w.heartbeatMux.Unlock()
 		return context.WithTimeout(ctx, waitTime)
 	}
@@ -205,6 +311,11 @@ func buildWSUrl(ctx context.Context, config *WSConfig) (string, error) {
 
 func (w *wsClient) connect(initial bool) error {
 	l := log.L(w.ctx)
+	w.setState(WSStateConnecting)
+	if !initial && w.authProvider != nil {
+		// The connection might have been dropped because the credentials expired
+		w.authProvider.Refresh(w.ctx)
+	}
 	return w.retry.DoCustomLog(w.ctx, func(attempt int) (retry bool, err error) {
 		if w.closed {
 			return false, i18n.NewError(w.ctx, i18n.MsgWSClosing)
@@ -218,8 +329,19 @@ func (w *wsClient) connect(initial bool) error {
 			}
 		}
 
+		headers := w.headers
+		if w.authProvider != nil {
+			var authorization string
+			if authorization, err = w.authProvider.Authorization(w.ctx); err != nil {
+				l.Warnf("WS %s connect attempt %d failed to obtain credentials", w.url, attempt)
+				return retry, err
+			}
+			headers = w.headers.Clone()
+			headers.Set("Authorization", authorization)
+		}
+
 		var res *http.Response
-		w.wsconn, res, err = w.wsdialer.Dial(w.url, w.headers)
+		w.wsconn, res, err = w.wsdialer.Dial(w.url, headers)
 		if err != nil {
 			var b []byte
 			var status = -1
@@ -228,6 +350,9 @@ func (w *wsClient) connect(initial bool) error {
 				res.Body.Close()
 				status = res.StatusCode
 			}
+			if status == http.StatusUnauthorized && w.authProvider != nil {
+				w.authProvider.Refresh(w.ctx)
+			}
 			l.Warnf("WS %s connect attempt %d failed [%d]: %s", w.url, attempt, status, string(b))
 			return retry, i18n.WrapError(w.ctx, err, i18n.MsgWSConnectFailed)
 		}
@@ -235,6 +360,7 @@ func (w *wsClient) connect(initial bool) error {
 		w.pongReceivedOrReset(false)
 		w.wsconn.SetPongHandler(w.pongHandler)
 		l.Infof("WS %s connected", w.url)
+		w.setState(WSStateConnected)
 		return false, nil
 	})
 }
@@ -299,7 +425,12 @@ func (w *wsClient) sendLoop(receiverDone chan struct{}) {
 		select {
 		case message := <-w.send:
 			l.Tracef("WS sending: %s", message)
-			if err := w.wsconn.WriteMessage(websocket.TextMessage, message); err != nil {
+			if err := faults.Inject(w.ctx, w.faultTarget, "send"); err != nil {
+				// An injected fault drops the connection, in the same way as a failed send
+				l.Errorf("WS %s send failed: %s", w.url, err)
+				_ = w.wsconn.Close()
+				disconnecting = true
+			} else if err := w.wsconn.WriteMessage(websocket.TextMessage, message); err != nil {
 				l.Errorf("WS %s send failed: %s", w.url, err)
 				disconnecting = true
 			}
@@ -326,6 +457,7 @@ func (w *wsClient) sendLoop(receiverDone chan struct{}) {
 func (w *wsClient) receiveReconnectLoop() {
 	l := log.L(w.ctx)
 	defer close(w.receive)
+	reconnected := false
 	for !w.closed {
 		// Start the sender, letting it close without blocking sending a notification on the sendDone
 		w.sendDone = make(chan []byte, 1)
@@ -337,6 +469,9 @@ func (w *wsClient) receiveReconnectLoop() {
 		if w.afterConnect != nil {
 			err = w.afterConnect(w.ctx, w)
 		}
+		if err == nil && reconnected {
+			err = w.runReconnectHooks()
+		}
 
 		if err == nil {
 			// Synchronously invoke the reader, as it's important we react immediately to any error there.
@@ -351,6 +486,7 @@ func (w *wsClient) receiveReconnectLoop() {
 			}
 			w.sendDone = nil
 			w.wsconn = nil
+			w.setState(WSStateDisconnected)
 		}
 
 		// Go into reconnect
@@ -360,6 +496,7 @@ func (w *wsClient) receiveReconnectLoop() {
 				l.Debugf("WS %s exiting: %s", w.url, err)
 				return
 			}
+			reconnected = true
 		}
 	}
 }
@@ -0,0 +1,61 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package wsclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/faults"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSSendInjectedFault(t *testing.T) {
+
+	ctx := context.Background()
+	scenario, err := faults.ParseScenario(ctx, "ut", []byte(`{"rules":[{"target":"^ut$","disconnect":true}]}`))
+	assert.NoError(t, err)
+	faults.Activate(ctx, scenario)
+	defer faults.Activate(ctx, nil)
+
+	toServer, _, url, done := NewTestWSServer(nil)
+	defer done()
+
+	wsconn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	w := &wsClient{
+		ctx:         ctx,
+		receive:     make(chan []byte),
+		send:        make(chan []byte, 1),
+		closing:     make(chan struct{}),
+		sendDone:    make(chan []byte, 1),
+		wsconn:      wsconn,
+		faultTarget: "ut",
+	}
+	w.send <- []byte(`dropped`)
+	w.sendLoop(make(chan struct{}))
+	<-w.sendDone
+
+	// The message was not sent, and the connection was dropped
+	assert.Empty(t, toServer)
+	_, _, err = wsconn.ReadMessage()
+	assert.Error(t, err)
+}
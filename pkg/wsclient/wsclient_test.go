@@ -21,10 +21,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -336,3 +338,234 @@ func TestHeartbeatSendFailed(t *testing.T) {
 	w.sendLoop(make(chan struct{}))
 
 }
+
+func TestWSReconnectHooksAndStateChanges(t *testing.T) {
+
+	upgrader := &websocket.Upgrader{}
+	var connections int32
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(res, req, http.Header{})
+		assert.NoError(t, err)
+		if atomic.AddInt32(&connections, 1) == 1 {
+			// Drop the first connection straight away, to force a reconnect
+			ws.Close()
+			return
+		}
+		go func() {
+			defer ws.Close()
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+	}))
+	defer svr.Close()
+
+	wsConfig := generateConfig()
+	wsConfig.HTTPURL = fmt.Sprintf("ws://%s", svr.Listener.Addr())
+	wsConfig.InitialDelay = 1
+	wsConfig.MaximumDelay = 1
+
+	afterConnectCount := 0
+	wsc, err := New(context.Background(), wsConfig, nil, func(ctx context.Context, w WSClient) error {
+		afterConnectCount++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, WSStateDisconnected, wsc.State())
+
+	states := make(chan WSConnectionState, 10)
+	wsc.OnStateChange(func(ctx context.Context, state WSConnectionState) {
+		states <- state
+	})
+	reconnected := make(chan struct{})
+	wsc.AddAfterReconnectHook(func(ctx context.Context, w WSClient) error {
+		close(reconnected)
+		return nil
+	})
+
+	err = wsc.Connect()
+	assert.NoError(t, err)
+	<-reconnected
+	assert.Equal(t, 2, afterConnectCount)
+	assert.Equal(t, WSStateConnected, wsc.State())
+
+	wsc.Close()
+	assert.Equal(t, WSStateClosed, wsc.State())
+
+	expected := []WSConnectionState{
+		WSStateConnecting,
+		WSStateConnected,
+		WSStateDisconnected,
+		WSStateConnecting,
+		WSStateConnected,
+		WSStateClosed,
+	}
+	for _, state := range expected {
+		assert.Equal(t, state, <-states)
+	}
+
+}
+
+func TestWSReconnectHookFails(t *testing.T) {
+
+	w := &wsClient{
+		ctx: context.Background(),
+	}
+	secondCalled := false
+	w.AddAfterReconnectHook(func(ctx context.Context, w WSClient) error {
+		return fmt.Errorf("pop")
+	})
+	w.AddAfterReconnectHook(func(ctx context.Context, w WSClient) error {
+		secondCalled = true
+		return nil
+	})
+
+	err := w.runReconnectHooks()
+	assert.Regexp(t, "pop", err)
+	assert.False(t, secondCalled)
+
+}
+
+func TestWSStateUnchangedAfterClose(t *testing.T) {
+
+	w := &wsClient{
+		ctx:   context.Background(),
+		state: WSStateClosed,
+	}
+	w.OnStateChange(func(ctx context.Context, state WSConnectionState) {
+		assert.Fail(t, "should not be notified")
+	})
+
+	w.setState(WSStateConnecting)
+	assert.Equal(t, WSStateClosed, w.State())
+
+}
+
+func TestHeartbeatTimeoutConfig(t *testing.T) {
+
+	wsConfig := generateConfig()
+	wsConfig.HeartbeatInterval = 10 * time.Second
+
+	wsc, err := New(context.Background(), wsConfig, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, wsc.(*wsClient).pongTimeout)
+
+	wsConfig.HeartbeatTimeout = 1 * time.Second
+	wsc, err = New(context.Background(), wsConfig, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1*time.Second, wsc.(*wsClient).pongTimeout)
+
+}
+
+func TestHeartbeatWaitsForPongTimeout(t *testing.T) {
+
+	now := time.Now()
+	w := &wsClient{
+		ctx:               context.Background(),
+		heartbeatInterval: 1 * time.Hour,
+		pongTimeout:       1 * time.Millisecond,
+		activePingSent:    &now,
+	}
+
+	ctx, cancel := w.heartbeatTimeout(context.Background())
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "pong timeout did not pop")
+	}
+
+}
+
+type testAuthProvider struct {
+	tokens    []string
+	refreshes int
+	err       error
+}
+
+func (p *testAuthProvider) Authorization(ctx context.Context) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	return "Bearer " + p.tokens[p.refreshes], nil
+}
+
+func (p *testAuthProvider) Refresh(ctx context.Context) {
+	p.refreshes++
+}
+
+func TestWSAuthProviderRefreshedOnReconnect(t *testing.T) {
+
+	upgrader := &websocket.Upgrader{}
+	received := make(chan string, 10)
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		authorization := req.Header.Get("Authorization")
+		received <- authorization
+		if authorization == "Bearer token2" {
+			res.WriteHeader(401)
+			return
+		}
+		ws, err := upgrader.Upgrade(res, req, http.Header{})
+		assert.NoError(t, err)
+		if authorization == "Bearer token1" {
+			// Drop the first connection straight away, to force a reconnect
+			ws.Close()
+			return
+		}
+		go func() {
+			defer ws.Close()
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+	}))
+	defer svr.Close()
+
+	provider := &testAuthProvider{tokens: []string{"token1", "token2", "token3"}}
+	wsConfig := generateConfig()
+	wsConfig.HTTPURL = fmt.Sprintf("ws://%s", svr.Listener.Addr())
+	wsConfig.HTTPHeaders = fftypes.JSONObject{"Authorization": "Basic dXNlcjpwYXNz"}
+	wsConfig.InitialDelay = 1
+	wsConfig.MaximumDelay = 1
+	wsConfig.AuthProvider = provider
+
+	wsc, err := New(context.Background(), wsConfig, nil, nil)
+	assert.NoError(t, err)
+	reconnected := make(chan struct{})
+	wsc.AddAfterReconnectHook(func(ctx context.Context, w WSClient) error {
+		close(reconnected)
+		return nil
+	})
+
+	err = wsc.Connect()
+	assert.NoError(t, err)
+	<-reconnected
+	wsc.Close()
+
+	assert.Equal(t, "Bearer token1", <-received)
+	assert.Equal(t, "Bearer token2", <-received)
+	assert.Equal(t, "Bearer token3", <-received)
+	assert.Equal(t, 2, provider.refreshes)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", wsc.(*wsClient).headers.Get("Authorization"))
+
+}
+
+func TestWSAuthProviderFails(t *testing.T) {
+
+	wsConfig := generateConfig()
+	wsConfig.HTTPURL = "ws://localhost:12345"
+	wsConfig.InitialDelay = 1
+	wsConfig.MaximumDelay = 1
+	wsConfig.InitialConnectAttempts = 1
+	wsConfig.AuthProvider = &testAuthProvider{err: fmt.Errorf("pop")}
+
+	wsc, err := New(context.Background(), wsConfig, nil, nil)
+	assert.NoError(t, err)
+	err = wsc.Connect()
+	assert.Regexp(t, "pop", err)
+
+}
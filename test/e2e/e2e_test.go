@@ -332,7 +332,7 @@ func wsReader(conn *websocket.Conn, dbChanges bool) (chan *fftypes.EventDelivery
 					panic(fmt.Errorf("Invalid JSON received on WebSocket: %s", err))
 				}
 				if err == nil {
-					fmt.Printf("Websocket %s event: %s/%s/%s -> %s (tx=%s)\n", conn.RemoteAddr(), ed.Namespace, ed.Type, ed.ID, ed.Reference, ed.Transaction)
+					fmt.Printf("Websocket %s event: %s/%s/%s -> %s (tx=%v)\n", conn.RemoteAddr(), ed.Namespace, ed.Type, ed.ID, ed.Reference, ed.Transaction)
 					events <- &ed
 				}
 			}
@@ -0,0 +1,95 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// FaultsTestSuite checks that messages are still confirmed by both members, while the stack is running a
+// build of FireFly with the "faults" tag and a fault injection scenario such as test/data/faults/resilience.yaml
+type FaultsTestSuite struct {
+	suite.Suite
+	testState *testState
+}
+
+func TestFaultsE2ESuite(t *testing.T) {
+	if os.Getenv("FAULTS_SCENARIO") == "" {
+		t.Skip("FAULTS_SCENARIO must be set to run the fault injection tests against a stack built with -tags faults")
+	}
+	suite.Run(t, new(FaultsTestSuite))
+}
+
+func (suite *FaultsTestSuite) BeforeTest(suiteName, testName string) {
+	suite.testState = beforeE2ETest(suite.T())
+}
+
+func (suite *FaultsTestSuite) TestBroadcastUnderFaults() {
+	defer suite.testState.done()
+
+	received1, _ := wsReader(suite.testState.ws1, false)
+	received2, _ := wsReader(suite.testState.ws2, false)
+
+	totalMessages := 10
+	for i := 0; i < totalMessages; i++ {
+		data := &fftypes.DataRefOrValue{
+			Value: fftypes.JSONAnyPtr(fmt.Sprintf(`"Faulty broadcast %d"`, i)),
+		}
+		resp, err := BroadcastMessage(suite.T(), suite.testState.client1, "faults", data, false)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 202, resp.StatusCode())
+	}
+
+	for i := 0; i < totalMessages; i++ {
+		waitForMessageConfirmed(suite.T(), received1, fftypes.MessageTypeBroadcast)
+		waitForMessageConfirmed(suite.T(), received2, fftypes.MessageTypeBroadcast)
+	}
+	validateReceivedMessages(suite.testState, suite.testState.client2, "faults", fftypes.MessageTypeBroadcast, fftypes.TransactionTypeBatchPin, totalMessages)
+}
+
+func (suite *FaultsTestSuite) TestPrivateUnderFaults() {
+	defer suite.testState.done()
+
+	received1, _ := wsReader(suite.testState.ws1, false)
+	received2, _ := wsReader(suite.testState.ws2, false)
+
+	totalMessages := 10
+	for i := 0; i < totalMessages; i++ {
+		data := &fftypes.DataRefOrValue{
+			Value: fftypes.JSONAnyPtr(fmt.Sprintf(`"Faulty private message %d"`, i)),
+		}
+		resp, err := PrivateMessage(suite.testState, suite.testState.client1, "faults", data, []string{
+			suite.testState.org1.Name,
+			suite.testState.org2.Name,
+		}, "", fftypes.TransactionTypeBatchPin, false)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 202, resp.StatusCode())
+	}
+
+	for i := 0; i < totalMessages; i++ {
+		waitForMessageConfirmed(suite.T(), received1, fftypes.MessageTypePrivate)
+		waitForMessageConfirmed(suite.T(), received2, fftypes.MessageTypePrivate)
+	}
+	validateReceivedMessages(suite.testState, suite.testState.client2, "faults", fftypes.MessageTypePrivate, fftypes.TransactionTypeBatchPin, totalMessages)
+}
@@ -0,0 +1,186 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// FFConnectorConformanceTestSuite checks a blockchain connector microservice directly against the
+// contract used by the ffconnector blockchain plugin, so connector authors can verify their
+// implementation without a full FireFly stack.
+type FFConnectorConformanceTestSuite struct {
+	suite.Suite
+	client *resty.Client
+	wsURL  string
+	signer string
+}
+
+type connectorEvent struct {
+	ID    string             `json:"id"`
+	Event string             `json:"event"`
+	Data  fftypes.JSONObject `json:"data"`
+}
+
+func TestFFConnectorConformanceSuite(t *testing.T) {
+	suite.Run(t, new(FFConnectorConformanceTestSuite))
+}
+
+func (suite *FFConnectorConformanceTestSuite) SetupSuite() {
+	connectorURL := os.Getenv("FFCONNECTOR_URL")
+	if connectorURL == "" {
+		suite.T().Skip("FFCONNECTOR_URL must be set to run the connector conformance tests")
+	}
+	suite.signer = os.Getenv("FFCONNECTOR_SIGNER")
+	if suite.signer == "" {
+		suite.T().Fatal("FFCONNECTOR_SIGNER must be set")
+	}
+	suite.client = resty.New().SetHostURL(connectorURL)
+
+	u, err := url.Parse(connectorURL)
+	require.NoError(suite.T(), err)
+	u.Scheme = "ws"
+	if os.Getenv("FFCONNECTOR_HTTPS") == "true" {
+		u.Scheme = "wss"
+	}
+	u.Path = "/api/ws"
+	suite.wsURL = u.String()
+}
+
+func (suite *FFConnectorConformanceTestSuite) connectWS() (*websocket.Conn, chan *connectorEvent) {
+	conn, _, err := websocket.DefaultDialer.Dial(suite.wsURL, nil)
+	require.NoError(suite.T(), err)
+	events := make(chan *connectorEvent, 100)
+	go func() {
+		defer close(events)
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event connectorEvent
+			if err := json.Unmarshal(b, &event); err == nil {
+				events <- &event
+			}
+		}
+	}()
+	return conn, events
+}
+
+func (suite *FFConnectorConformanceTestSuite) ack(conn *websocket.Conn, event *connectorEvent) {
+	if event.ID != "" && event.Event != "receipt" {
+		err := conn.WriteJSON(fftypes.JSONObject{
+			"event": "ack",
+			"data":  fftypes.JSONObject{"id": event.ID},
+		})
+		assert.NoError(suite.T(), err)
+	}
+}
+
+func (suite *FFConnectorConformanceTestSuite) TestResolveKey() {
+	var result fftypes.JSONObject
+	res, err := suite.client.R().
+		SetBody(fftypes.JSONObject{"key": suite.signer}).
+		SetResult(&result).
+		Post("/api/v1/resolvekey")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 200, res.StatusCode())
+	assert.NotEmpty(suite.T(), result.GetString("key"))
+}
+
+func (suite *FFConnectorConformanceTestSuite) TestResolveKeyInvalid() {
+	res, err := suite.client.R().
+		SetBody(fftypes.JSONObject{"key": "!not a key!"}).
+		Post("/api/v1/resolvekey")
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), res.IsSuccess())
+}
+
+func (suite *FFConnectorConformanceTestSuite) TestBatchPin() {
+	conn, events := suite.connectWS()
+	defer conn.Close()
+
+	requestID := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	batchHash := fftypes.NewRandB32()
+	pin := fftypes.NewRandB32()
+	res, err := suite.client.R().
+		SetBody(fftypes.JSONObject{
+			"requestId":     requestID.String(),
+			"signer":        suite.signer,
+			"namespace":     "conformance",
+			"transactionId": txID.String(),
+			"batchId":       batchID.String(),
+			"batchHash":     batchHash.String(),
+			"payloadRef":    "",
+			"contexts":      []string{pin.String()},
+		}).
+		Post("/api/v1/batchpin")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 202, res.StatusCode())
+
+	receiptOK := false
+	pinOK := false
+	timeout := time.After(2 * time.Minute)
+	for !receiptOK || !pinOK {
+		select {
+		case event, ok := <-events:
+			require.True(suite.T(), ok, "WebSocket closed")
+			suite.T().Logf("Received '%s' event: %s", event.Event, event.Data)
+			switch {
+			case event.Event == "receipt" && event.Data.GetString("id") == requestID.String():
+				assert.True(suite.T(), event.Data.GetBool("success"), event.Data.GetString("message"))
+				assert.NotEmpty(suite.T(), event.Data.GetString("transactionHash"))
+				receiptOK = true
+			case event.Event == "batchpin" && event.Data.GetString("batchId") == batchID.String():
+				assert.NotEmpty(suite.T(), event.ID)
+				assert.Equal(suite.T(), "conformance", event.Data.GetString("namespace"))
+				assert.Equal(suite.T(), txID.String(), event.Data.GetString("transactionId"))
+				assert.Equal(suite.T(), batchHash.String(), event.Data.GetString("batchHash"))
+				assert.Equal(suite.T(), []string{pin.String()}, event.Data.GetStringArray("contexts"))
+				assert.NotEmpty(suite.T(), event.Data.GetString("signer"))
+				blockchainEvent := event.Data.GetObject("blockchainEvent")
+				assert.NotEmpty(suite.T(), blockchainEvent.GetString("protocolId"))
+				_, err := fftypes.ParseTimeString(blockchainEvent.GetString("timestamp"))
+				assert.NoError(suite.T(), err)
+				pinOK = true
+			}
+			suite.ack(conn, event)
+		case <-timeout:
+			suite.T().Fatalf("Timed out waiting for receipt=%t batchpin=%t", receiptOK, pinOK)
+		}
+	}
+}
+
+func (suite *FFConnectorConformanceTestSuite) TestDeleteUnknownListener() {
+	res, err := suite.client.R().
+		Delete("/api/v1/listeners/" + fftypes.NewUUID().String())
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), res.IsSuccess())
+}